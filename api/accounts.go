@@ -15,7 +15,9 @@ import (
 	"go.vocdoni.io/dvote/log"
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain/indexer"
 	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/txaudit"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/proto"
 )
@@ -98,6 +100,46 @@ func (a *API) enableAccountHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/accounts/{address}/txaudit",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.accountTxAuditHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/accounts/{address}/kv",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.accountKVHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/accounts/{address}/recovery",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.accountRecoveryHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/accounts/{address}/feed",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.accountFeedHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/accounts/{address}/transactions",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.accountTransactionsHandler,
+	); err != nil {
+		return err
+	}
 	if err := a.Endpoint.RegisterMethod(
 		"/accounts/count",
 		"GET",
@@ -135,16 +177,21 @@ func (a *API) enableAccountHandlers() error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			address	path		string	true	"Account address"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200		{object}	Account
 //	@Success		200		{object}	AccountMetadata
 //	@Router			/accounts/{address} [get]
 //	@Router			/accounts/{address}/metadata [get]
 func (a *API) accountHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	if len(util.TrimHex(ctx.URLParam("address"))) != common.AddressLength*2 {
 		return ErrAddressMalformed
 	}
 	addr := common.HexToAddress(ctx.URLParam("address"))
-	acc, err := a.vocapp.State.GetAccount(addr, true)
+	acc, err := a.chainState(idx).GetAccount(addr, true)
 	if err != nil {
 		return ErrAccountNotFound.With(addr.Hex())
 	}
@@ -187,18 +234,18 @@ func (a *API) accountHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) er
 		return ctx.Send(data, apirest.HTTPstatusOK)
 	}
 
-	sik, err := a.vocapp.State.SIKFromAddress(addr)
+	sik, err := a.chainState(idx).SIKFromAddress(addr)
 	if err != nil && !errors.Is(err, state.ErrSIKNotFound) {
 		log.Warnf("unknown error getting SIK: %v", err)
 		return ErrGettingSIK.WithErr(err)
 	}
 
-	_, transfersCount, err := a.indexer.TokenTransfersList(1, 0, hex.EncodeToString(addr.Bytes()), "", "")
+	_, transfersCount, err := idx.TokenTransfersList(1, 0, hex.EncodeToString(addr.Bytes()), "", "")
 	if err != nil {
 		return ErrCantFetchTokenTransfers.WithErr(err)
 	}
 
-	_, feesCount, err := a.indexer.TokenFeesList(1, 0, "", "", hex.EncodeToString(addr.Bytes()))
+	_, feesCount, err := idx.TokenFeesList(1, 0, "", "", hex.EncodeToString(addr.Bytes()))
 	if err != nil {
 		return ErrCantFetchTokenFees.WithErr(err)
 	}
@@ -322,6 +369,244 @@ func (a *API) accountSetHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContex
 	return ctx.Send(data, apirest.HTTPstatusOK)
 }
 
+// accountKVHandler
+//
+//	@Summary		Account key-value store
+//	@Description	Returns the entries of the on-chain key-value store of the account, sorted by key, and the height
+//	@Description	of the block where each entry was last set. The entries are set with SET_ACCOUNT_KV transactions.
+//	@Tags			Accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			address	path		string	true	"Account address"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	AccountKVList
+//	@Router			/accounts/{address}/kv [get]
+func (a *API) accountKVHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	if len(util.TrimHex(ctx.URLParam("address"))) != common.AddressLength*2 {
+		return ErrAddressMalformed
+	}
+	addr := common.HexToAddress(ctx.URLParam("address"))
+	acc, err := a.chainState(idx).GetAccount(addr, true)
+	if err != nil || acc == nil {
+		return ErrAccountNotFound.With(addr.Hex())
+	}
+	entries, err := idx.AccountKV(addr.Bytes())
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, &AccountKVList{Entries: entries})
+}
+
+// accountRecoveryHandler
+//
+//	@Summary		Account recovery
+//	@Description	Returns the guardians of the account, the recovery they approved and not executed yet, the owner key
+//	@Description	that controls the account if they rotated it, and the history of its recovery, most recent first.
+//	@Description	A quorum of guardians can rotate the owner key of the account with RECOVER_ACCOUNT transactions, and
+//	@Description	the rotation is executed with an EXECUTE_ACCOUNT_RECOVERY transaction once the timelock has elapsed.
+//	@Description	The owner can cancel it meanwhile by setting its guardians again.
+//	@Tags			Accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			address	path		string	true	"Account address"
+//	@Param			page	query		number	false	"Page"
+//	@Param			limit	query		number	false	"Items per page"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	AccountRecovery
+//	@Router			/accounts/{address}/recovery [get]
+func (a *API) accountRecoveryHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	if len(util.TrimHex(ctx.URLParam("address"))) != common.AddressLength*2 {
+		return ErrAddressMalformed
+	}
+	addr := common.HexToAddress(ctx.URLParam("address"))
+	acc, err := a.chainState(idx).GetAccount(addr, true)
+	if err != nil || acc == nil {
+		return ErrAccountNotFound.With(addr.Hex())
+	}
+	params, err := parsePaginationParams(ctx.QueryParam(ParamPage), ctx.QueryParam(ParamLimit))
+	if err != nil {
+		return err
+	}
+	resp := &AccountRecovery{}
+	if resp.Guardians, err = a.chainState(idx).RecoveryGuardians(addr, true); err != nil {
+		return ErrCantFetchAccountRecovery.WithErr(err)
+	}
+	if resp.Pending, err = a.chainState(idx).AccountRecovery(addr, true); err != nil {
+		return ErrCantFetchAccountRecovery.WithErr(err)
+	}
+	owner, err := a.chainState(idx).AccountOwner(addr, true)
+	if err != nil {
+		return ErrCantFetchAccountRecovery.WithErr(err)
+	}
+	if owner != nil {
+		resp.Owner = owner.Bytes()
+	}
+	events, total, err := idx.AccountRecoveryEvents(addr.Bytes(), params.Limit, params.Page*params.Limit)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	resp.Events = events
+	if resp.Pagination, err = calculatePagination(params.Page, params.Limit, total); err != nil {
+		return err
+	}
+	return marshalAndSend(ctx, resp)
+}
+
+// accountFeedHandler
+//
+//	@Summary		Account activity feed
+//	@Description	Returns the activity of the account, most recent first: the tokens sent and received, the fees spent,
+//	@Description	the elections created, the votes cast and the account updates. Only the votes signed by the account
+//	@Description	are included, so the anonymous ones are not.
+//	@Tags			Accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			address	path		string	true	"Account address"
+//	@Param			page	query		number	false	"Page"
+//	@Param			limit	query		number	false	"Items per page"
+//	@Param			cursor	query		string	false	"Cursor returned as nextCursor by the previous page (page is ignored)"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	AccountFeed
+//	@Router			/accounts/{address}/feed [get]
+func (a *API) accountFeedHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	if len(util.TrimHex(ctx.URLParam("address"))) != common.AddressLength*2 {
+		return ErrAddressMalformed
+	}
+	addr := common.HexToAddress(ctx.URLParam("address"))
+	acc, err := a.chainState(idx).GetAccount(addr, true)
+	if err != nil || acc == nil {
+		return ErrAccountNotFound.With(addr.Hex())
+	}
+	params, err := parsePaginationParams(ctx.QueryParam(ParamPage), ctx.QueryParam(ParamLimit))
+	if err != nil {
+		return err
+	}
+	params.Cursor = ctx.QueryParam(ParamCursor)
+
+	items, nextCursor, total, err := idx.AccountFeed(
+		params.Limit,
+		cursorOffset(params),
+		params.Cursor,
+		addr.Bytes(),
+	)
+	if err != nil {
+		if errors.Is(err, indexer.ErrInvalidCursor) {
+			return ErrCantParseCursor.WithErr(err)
+		}
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	pagination, err := calculateCursorPagination(params, total, nextCursor)
+	if err != nil {
+		return err
+	}
+	return marshalAndSend(ctx, &AccountFeed{Items: items, Pagination: pagination})
+}
+
+// accountTransactionsHandler
+//
+//	@Summary		Transactions sent by an account
+//	@Description	Returns the transactions signed by the account, most recent first. The anonymous transactions, such as
+//	@Description	the anonymous votes, are not signed by any account. To get full transaction information use
+//	@Description	[/chain/transaction/{hash}](transaction-by-hash).
+//	@Tags			Accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			address	path		string				true	"Account address"
+//	@Param			page	query		number				false	"Page"
+//	@Param			limit	query		number				false	"Items per page"
+//	@Param			cursor	query		string				false	"Cursor returned as nextCursor by the previous page (page is ignored)"
+//	@Param			type	query		string				false	"Tx type"
+//	@Param			chainId	query		string				false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	TransactionsList	"List of transactions (metadata only)"
+//	@Router			/accounts/{address}/transactions [get]
+func (a *API) accountTransactionsHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	if len(util.TrimHex(ctx.URLParam("address"))) != common.AddressLength*2 {
+		return ErrAddressMalformed
+	}
+	addr := common.HexToAddress(ctx.URLParam("address"))
+	acc, err := a.chainState(idx).GetAccount(addr, true)
+	if err != nil || acc == nil {
+		return ErrAccountNotFound.With(addr.Hex())
+	}
+	params, err := parsePaginationParams(ctx.QueryParam(ParamPage), ctx.QueryParam(ParamLimit))
+	if err != nil {
+		return err
+	}
+	params.Cursor = ctx.QueryParam(ParamCursor)
+
+	etag, notModified := a.indexerETag(ctx, idx, indexer.DataTransactions)
+	if notModified {
+		return sendNotModified(ctx, etag)
+	}
+	txs, nextCursor, total, err := idx.TransactionListBySigner(
+		params.Limit,
+		cursorOffset(params),
+		params.Cursor,
+		addr.Bytes(),
+		ctx.QueryParam(ParamType),
+	)
+	if err != nil {
+		if errors.Is(err, indexer.ErrInvalidCursor) {
+			return ErrCantParseCursor.WithErr(err)
+		}
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	pagination, err := calculateCursorPagination(params, total, nextCursor)
+	if err != nil {
+		return err
+	}
+	return marshalAndSendWithETag(ctx, &TransactionsList{Transactions: txs, Pagination: pagination}, etag)
+}
+
+// accountTxAuditHandler
+//
+//	@Summary		Account transactions audit
+//	@Description	Returns the recent transactions from the account seen by the node mempool (including the ones rejected,
+//	@Description	such as replay attempts), and the nonce gaps that prevent pending transactions from being executed.
+//	@Description	The history is kept in memory, so it only contains the transactions received since the node started.
+//	@Tags			Accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			address	path		string	true	"Account address"
+//	@Success		200		{object}	txaudit.AccountAudit
+//	@Router			/accounts/{address}/txaudit [get]
+func (a *API) accountTxAuditHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	if len(util.TrimHex(ctx.URLParam("address"))) != common.AddressLength*2 {
+		return ErrAddressMalformed
+	}
+	addr := common.HexToAddress(ctx.URLParam("address"))
+	acc, err := a.vocapp.State.GetAccount(addr, true)
+	if err != nil || acc == nil {
+		return ErrAccountNotFound.With(addr.Hex())
+	}
+	audit := a.vocapp.TxAudit.Account(addr, acc.GetNonce())
+	if audit == nil {
+		audit = &txaudit.AccountAudit{
+			Address:   addr.Bytes(),
+			Nonce:     acc.GetNonce(),
+			Txs:       []*txaudit.TxRecord{},
+			NonceGaps: []uint32{},
+		}
+	}
+	return marshalAndSend(ctx, audit)
+}
+
 // accountCountHandler
 //
 //	@Summary		Total number of accounts
@@ -331,10 +616,15 @@ func (a *API) accountSetHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContex
 //	@Tags			Accounts
 //	@Accept			json
 //	@Produce		json
+//	@Param			chainId	query	string	false	"Chain to query (default the chain of the node)"
 //	@Success		200	{object}	CountResult
 //	@Router			/accounts/count [get]
 func (a *API) accountCountHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
-	count, err := a.indexer.CountTotalAccounts()
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	count, err := idx.CountTotalAccounts()
 	if err != nil {
 		return err
 	}
@@ -352,9 +642,14 @@ func (a *API) accountCountHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContex
 //	@Produce		json
 //	@Param			organizationId	path		string	true	"Specific organizationId"
 //	@Param			page			path		number	true	"Page"
+//	@Param			chainId			query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200				{object}	ElectionsList
 //	@Router			/accounts/{organizationId}/elections/page/{page} [get]
-func (a *API) accountElectionsListByPageHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+func (a *API) accountElectionsListByPageHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := electionParams(ctx.URLParam,
 		ParamPage,
 		ParamOrganizationId,
@@ -367,7 +662,7 @@ func (a *API) accountElectionsListByPageHandler(_ *apirest.APIdata, ctx *httprou
 		return ErrMissingParameter
 	}
 
-	list, err := a.electionList(params)
+	list, err := a.electionList(idx, params, msg.AuthToken)
 	if err != nil {
 		// keep the odd legacy behaviour of sending an empty json "{}"" rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -391,9 +686,14 @@ func (a *API) accountElectionsListByPageHandler(_ *apirest.APIdata, ctx *httprou
 //	@Param			organizationId	path		string	true	"Specific organizationId"
 //	@Param			status			path		string	true	"Election status"	Enums(ready, paused, canceled, ended, results)
 //	@Param			page			path		number	true	"Page"
+//	@Param			chainId			query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200				{object}	ElectionsList
 //	@Router			/accounts/{organizationId}/elections/status/{status}/page/{page} [get]
-func (a *API) accountElectionsListByStatusAndPageHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+func (a *API) accountElectionsListByStatusAndPageHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := electionParams(ctx.URLParam,
 		ParamPage,
 		ParamStatus,
@@ -407,7 +707,7 @@ func (a *API) accountElectionsListByStatusAndPageHandler(_ *apirest.APIdata, ctx
 		return ErrMissingParameter
 	}
 
-	list, err := a.electionList(params)
+	list, err := a.electionList(idx, params, msg.AuthToken)
 	if err != nil {
 		// keep the odd legacy behaviour of sending an empty json "{}"" rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -429,9 +729,14 @@ func (a *API) accountElectionsListByStatusAndPageHandler(_ *apirest.APIdata, ctx
 //	@Accept			json
 //	@Produce		json
 //	@Param			organizationId	path		string	true	"Specific organizationId"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200				{object}	CountResult
 //	@Router			/accounts/{organizationId}/elections/count [get]
 func (a *API) accountElectionsCountHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	if ctx.URLParam(ParamOrganizationId) == "" {
 		return ErrMissingParameter
 	}
@@ -441,7 +746,7 @@ func (a *API) accountElectionsCountHandler(_ *apirest.APIdata, ctx *httprouter.H
 		return err
 	}
 
-	acc, err := a.vocapp.State.GetAccount(common.BytesToAddress(organizationID), true)
+	acc, err := a.chainState(idx).GetAccount(common.BytesToAddress(organizationID), true)
 	if acc == nil {
 		return ErrOrgNotFound
 	}
@@ -462,9 +767,14 @@ func (a *API) accountElectionsCountHandler(_ *apirest.APIdata, ctx *httprouter.H
 //	@Produce		json
 //	@Param			accountId	path		string	true	"Specific accountId that sent or received the tokens"
 //	@Param			page		path		number	true	"Page"
+//	@Param			chainId		query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200			{object}	TransfersList
 //	@Router			/accounts/{accountId}/transfers/page/{page} [get]
 func (a *API) tokenTransfersListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := parseTransfersParams(
 		ctx.URLParam(ParamPage),
 		"",
@@ -476,7 +786,7 @@ func (a *API) tokenTransfersListHandler(_ *apirest.APIdata, ctx *httprouter.HTTP
 		return err
 	}
 
-	list, err := a.transfersList(params)
+	list, err := a.transfersList(idx, params)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -499,9 +809,14 @@ func (a *API) tokenTransfersListHandler(_ *apirest.APIdata, ctx *httprouter.HTTP
 //	@Produce		json
 //	@Param			accountId	path		string	true	"Specific accountId"
 //	@Param			page		path		number	true	"Page"
+//	@Param			chainId		query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200			{object}	FeesList
 //	@Router			/accounts/{accountId}/fees/page/{page} [get]
 func (a *API) tokenFeesHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := parseFeesParams(
 		ctx.URLParam(ParamPage),
 		"",
@@ -517,7 +832,7 @@ func (a *API) tokenFeesHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext)
 		return ErrMissingParameter
 	}
 
-	list, err := a.feesList(params)
+	list, err := a.feesList(idx, params)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -539,14 +854,19 @@ func (a *API) tokenFeesHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext)
 //	@Accept			json
 //	@Produce		json
 //	@Param			accountId	path		string		true	"Specific accountId"
+//	@Param			chainId	query		string		false	"Chain to query (default the chain of the node)"
 //	@Success		200			{object}	CountResult	"Number of transaction sent and received for the account"
 //	@Router			/accounts/{accountId}/transfers/count [get]
 func (a *API) tokenTransfersCountHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	accountID, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamAccountId)))
 	if err != nil || accountID == nil {
 		return ErrCantParseAccountID.Withf("%q", ctx.URLParam(ParamAccountId))
 	}
-	acc, err := a.vocapp.State.GetAccount(common.BytesToAddress(accountID), true)
+	acc, err := a.chainState(idx).GetAccount(common.BytesToAddress(accountID), true)
 	if acc == nil {
 		return ErrAccountNotFound
 	}
@@ -554,7 +874,7 @@ func (a *API) tokenTransfersCountHandler(_ *apirest.APIdata, ctx *httprouter.HTT
 		return err
 	}
 
-	count, err := a.indexer.CountTokenTransfersByAccount(accountID)
+	count, err := idx.CountTokenTransfersByAccount(accountID)
 	if err != nil {
 		return err
 	}
@@ -571,9 +891,14 @@ func (a *API) tokenTransfersCountHandler(_ *apirest.APIdata, ctx *httprouter.HTT
 //	@Accept			json
 //	@Produce		json
 //	@Param			page	path		number	true	"Page"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200		{object}	AccountsList
 //	@Router			/accounts/page/{page} [get]
 func (a *API) accountListByPageHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := parseAccountParams(
 		ctx.URLParam(ParamPage),
 		"",
@@ -583,7 +908,7 @@ func (a *API) accountListByPageHandler(_ *apirest.APIdata, ctx *httprouter.HTTPC
 		return err
 	}
 
-	list, err := a.accountList(params)
+	list, err := a.accountList(idx, params)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -605,9 +930,14 @@ func (a *API) accountListByPageHandler(_ *apirest.APIdata, ctx *httprouter.HTTPC
 //	@Param			page		query		number	false	"Page"
 //	@Param			limit		query		number	false	"Items per page"
 //	@Param			accountId	query		string	false	"Filter by partial accountId"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200			{object}	AccountsList
 //	@Router			/accounts [get]
 func (a *API) accountListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := parseAccountParams(
 		ctx.QueryParam(ParamPage),
 		ctx.QueryParam(ParamLimit),
@@ -617,7 +947,7 @@ func (a *API) accountListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext
 		return err
 	}
 
-	list, err := a.accountList(params)
+	list, err := a.accountList(idx, params)
 	if err != nil {
 		return err
 	}
@@ -625,11 +955,11 @@ func (a *API) accountListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext
 	return marshalAndSend(ctx, list)
 }
 
-// accountList produces a paginated AccountsList.
+// accountList produces a paginated AccountsList of the accounts indexed by idx.
 //
 // Errors returned are always of type APIerror.
-func (a *API) accountList(params *AccountParams) (*AccountsList, error) {
-	accounts, total, err := a.indexer.AccountList(
+func (a *API) accountList(idx *indexer.Indexer, params *AccountParams) (*AccountsList, error) {
+	accounts, total, err := idx.AccountList(
 		params.Limit,
 		params.Page*params.Limit,
 		params.AccountID,
@@ -60,31 +60,42 @@ const (
 //
 //nolint:revive
 const (
-	ParamAccountId       = "accountId"
-	ParamCensusId        = "censusId"
-	ParamElectionId      = "electionId"
-	ParamOrganizationId  = "organizationId"
-	ParamVoteId          = "voteId"
-	ParamPage            = "page"
-	ParamLimit           = "limit"
-	ParamStatus          = "status"
-	ParamWithResults     = "withResults"
-	ParamFinalResults    = "finalResults"
-	ParamManuallyEnded   = "manuallyEnded"
-	ParamChainId         = "chainId"
-	ParamHash            = "hash"
-	ParamProposerAddress = "proposerAddress"
-	ParamHeight          = "height"
-	ParamReference       = "reference"
-	ParamType            = "type"
-	ParamSubtype         = "subtype"
-	ParamSigner          = "signer"
-	ParamAccountIdFrom   = "accountIdFrom"
-	ParamAccountIdTo     = "accountIdTo"
-	ParamStartDateAfter  = "startDateAfter"
-	ParamStartDateBefore = "startDateBefore"
-	ParamEndDateAfter    = "endDateAfter"
-	ParamEndDateBefore   = "endDateBefore"
+	ParamAccountId        = "accountId"
+	ParamCensusId         = "censusId"
+	ParamElectionId       = "electionId"
+	ParamOrganizationId   = "organizationId"
+	ParamVoteId           = "voteId"
+	ParamPage             = "page"
+	ParamLimit            = "limit"
+	ParamCursor           = "cursor"
+	ParamCid              = "cid"
+	ParamStatus           = "status"
+	ParamWithResults      = "withResults"
+	ParamFinalResults     = "finalResults"
+	ParamManuallyEnded    = "manuallyEnded"
+	ParamChainId          = "chainId"
+	ParamHash             = "hash"
+	ParamProposerAddress  = "proposerAddress"
+	ParamHeight           = "height"
+	ParamReference        = "reference"
+	ParamType             = "type"
+	ParamSubtype          = "subtype"
+	ParamSigner           = "signer"
+	ParamAccountIdFrom    = "accountIdFrom"
+	ParamAccountIdTo      = "accountIdTo"
+	ParamStartDateAfter   = "startDateAfter"
+	ParamStartDateBefore  = "startDateBefore"
+	ParamEndDateAfter     = "endDateAfter"
+	ParamEndDateBefore    = "endDateBefore"
+	ParamValidatorAddress = "validatorAddress"
+	ParamWindow           = "window"
+	ParamFrom             = "from"
+	ParamTo               = "to"
+	ParamInterval         = "interval"
+	ParamKeyType          = "keyType"
+	ParamMinTurnout       = "minTurnout"
+	ParamDelegate         = "delegate"
+	ParamMaxLag           = "maxLag"
 )
 
 var (
@@ -105,6 +116,10 @@ type API struct {
 	censusdb *censusdb.CensusDB
 	db       db.Database // used for internal db operations
 
+	// enabledHandlers are the namespaces enabled with EnableHandlers, served
+	// by the capabilities endpoint.
+	enabledHandlers []string
+
 	censusPublishStatusMap sync.Map // used to store the status of the census publishing process when async
 }
 
@@ -134,6 +149,9 @@ func NewAPI(router *httprouter.HTTProuter, baseRoute, dataDir, dbType string) (*
 		return nil, err
 	}
 	api.db = prefixeddb.NewPrefixedDatabase(mdb, []byte("api/"))
+	if err := api.enableCapabilitiesHandlers(); err != nil {
+		return nil, err
+	}
 	return &api, nil
 }
 
@@ -202,10 +220,22 @@ func (a *API) EnableHandlers(handlers ...string) error {
 			if a.censusdb == nil {
 				return fmt.Errorf("%w %s", ErrMissingModulesForHandler, h)
 			}
+		case BatchHandler:
+			if err := a.enableBatchHandlers(); err != nil {
+				return err
+			}
+		case IndexerQueryHandler:
+			if a.indexer == nil {
+				return fmt.Errorf("%w %s", ErrMissingModulesForHandler, h)
+			}
+			if err := a.enableIndexerQueryHandlers(); err != nil {
+				return err
+			}
 
 		default:
 			return fmt.Errorf("%w: %s", ErrHandlerUnknown, h)
 		}
+		a.enabledHandlers = append(a.enabledHandlers, h)
 	}
 	return nil
 }
@@ -6,8 +6,10 @@ import (
 
 	comettypes "github.com/cometbft/cometbft/types"
 	"github.com/google/uuid"
+	"go.vocdoni.io/dvote/crypto/zk/prover"
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/state"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/encoding/protojson"
 )
@@ -16,8 +18,9 @@ import (
 
 // PaginationParams allows the client to request a specific page, and how many items per page
 type PaginationParams struct {
-	Page  int `json:"page,omitempty"`
-	Limit int `json:"limit,omitempty"`
+	Page   int    `json:"page,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // ElectionParams allows the client to filter elections
@@ -33,6 +36,7 @@ type ElectionParams struct {
 	StartDateBefore *time.Time `json:"startDateBefore,omitempty"`
 	EndDateAfter    *time.Time `json:"endDateAfter,omitempty"`
 	EndDateBefore   *time.Time `json:"endDateBefore,omitempty"`
+	MinTurnout      float64    `json:"minTurnout,omitempty"`
 }
 
 // OrganizationParams allows the client to filter organizations
@@ -81,10 +85,18 @@ type TransfersParams struct {
 	AccountIDTo   string `json:"accountIdTo,omitempty"`
 }
 
+// ValidatorSetChangesParams allows the client to filter validator set changes
+type ValidatorSetChangesParams struct {
+	PaginationParams
+	ValidatorAddress string `json:"validatorAddress,omitempty"`
+}
+
 // VoteParams allows the client to filter votes
 type VoteParams struct {
 	PaginationParams
-	ElectionID string `json:"electionId,omitempty"`
+	ElectionID string     `json:"electionId,omitempty"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
 }
 
 // ### Objects returned ###
@@ -101,6 +113,15 @@ type Pagination struct {
 	CurrentPage  uint64  `json:"currentPage"`
 	NextPage     *uint64 `json:"nextPage"`
 	LastPage     uint64  `json:"lastPage"`
+	// NextCursor is an opaque token to fetch the next page of a list that supports
+	// cursor pagination. Unlike page numbers, it is not affected by new items being
+	// added while paginating. Empty if there are no more items. The pages fetched by
+	// cursor only set NextCursor, as counting the items requires scanning all of them.
+	NextCursor string `json:"nextCursor,omitempty"`
+	// Estimated is true if TotalItems, and so LastPage, is an estimation, as
+	// the node does not count the items of large lists. The exact count is
+	// returned by the count endpoint of the list.
+	Estimated bool `json:"estimated,omitempty"`
 }
 
 type OrganizationSummary struct {
@@ -125,6 +146,13 @@ type ElectionSummary struct {
 	Results        [][]*types.BigInt `json:"result,omitempty"`
 	ManuallyEnded  bool              `json:"manuallyEnded"`
 	ChainID        string            `json:"chainId"`
+	Turnout        float64           `json:"turnout"`
+	WeightTurnout  float64           `json:"weightTurnout,omitempty"`
+	Unlisted       bool              `json:"unlisted,omitempty"`
+	NullifierGroup types.HexBytes    `json:"nullifierGroup,omitempty"`
+	VoteDeposit    uint64            `json:"voteDeposit,omitempty"`
+	ManualEnd      bool              `json:"manualEnd,omitempty"`
+	VoteDelegation bool              `json:"voteDelegation,omitempty"`
 }
 
 // ElectionsList is used to return a paginated list to the client
@@ -133,6 +161,13 @@ type ElectionsList struct {
 	Pagination *Pagination        `json:"pagination"`
 }
 
+// MetadataAvailabilityList is the paginated list of elections whose metadata or
+// census could not be retrieved from the storage on the last check.
+type MetadataAvailabilityList struct {
+	Elections  []*indexertypes.MetadataAvailability `json:"elections"`
+	Pagination *Pagination                          `json:"pagination"`
+}
+
 // ElectionResults is the struct used to wrap the results of an election
 type ElectionResults struct {
 	// ABIEncoded is the abi encoded election results
@@ -209,15 +244,17 @@ type Vote struct {
 	VoteID types.HexBytes `json:"voteID,omitempty"  extensions:"x-omitempty" `
 	// Sent only for encrypted elections (no results until the end)
 	EncryptionKeyIndexes []uint32 `json:"encryptionKeys,omitempty" extensions:"x-omitempty"`
-	// For encrypted elections this will be codified
-	VotePackage      json.RawMessage `json:"package,omitempty" extensions:"x-omitempty"`
-	VoteWeight       string          `json:"weight,omitempty" extensions:"x-omitempty"` // [math/big.Int.String]
-	VoteNumber       *uint32         `json:"number,omitempty" extensions:"x-omitempty"`
-	ElectionID       types.HexBytes  `json:"electionID,omitempty" extensions:"x-omitempty" `
-	VoterID          types.HexBytes  `json:"voterID,omitempty" extensions:"x-omitempty" `
-	BlockHeight      uint32          `json:"blockHeight,omitempty" extensions:"x-omitempty"`
-	TransactionIndex *int32          `json:"transactionIndex,omitempty" extensions:"x-omitempty"`
-	OverwriteCount   *uint32         `json:"overwriteCount,omitempty" extensions:"x-omitempty"`
+	// For encrypted elections this will be codified until the encryption keys are revealed
+	VotePackage json.RawMessage `json:"package,omitempty" extensions:"x-omitempty"`
+	// EncryptedVotePackage is the vote package as it was sent, only for encrypted elections
+	EncryptedVotePackage types.HexBytes `json:"encryptedPackage,omitempty" extensions:"x-omitempty"`
+	VoteWeight           string         `json:"weight,omitempty" extensions:"x-omitempty"` // [math/big.Int.String]
+	VoteNumber           *uint32        `json:"number,omitempty" extensions:"x-omitempty"`
+	ElectionID           types.HexBytes `json:"electionID,omitempty" extensions:"x-omitempty" `
+	VoterID              types.HexBytes `json:"voterID,omitempty" extensions:"x-omitempty" `
+	BlockHeight          uint32         `json:"blockHeight,omitempty" extensions:"x-omitempty"`
+	TransactionIndex     *int32         `json:"transactionIndex,omitempty" extensions:"x-omitempty"`
+	OverwriteCount       *uint32        `json:"overwriteCount,omitempty" extensions:"x-omitempty"`
 	// Date when the vote was emitted
 	Date *time.Time `json:"date,omitempty" extensions:"x-omitempty"`
 }
@@ -227,6 +264,22 @@ type VotesList struct {
 	Pagination *Pagination `json:"pagination"`
 }
 
+// ZkProofVerification is used to verify a zk-SNARK vote proof without casting the vote.
+// VotePackage must contain the exact bytes of the vote package (encrypted, if so)
+// whose hash is included in the proof public signals.
+type ZkProofVerification struct {
+	Proof       *prover.Proof  `json:"proof"`
+	VotePackage types.HexBytes `json:"votePackage"`
+}
+
+// ZkProofVerificationResult is the result of verifying a zk-SNARK vote proof.
+type ZkProofVerificationResult struct {
+	Valid     bool           `json:"valid"`
+	Error     string         `json:"error,omitempty"`
+	Weight    *types.BigInt  `json:"weight,omitempty"`
+	Nullifier types.HexBytes `json:"nullifier,omitempty"`
+}
+
 type CensusTypeDescription struct {
 	Type      string         `json:"type"`
 	Size      uint64         `json:"size"`
@@ -242,6 +295,9 @@ type CensusParticipants struct {
 type CensusParticipant struct {
 	Key    types.HexBytes `json:"key" `
 	Weight *types.BigInt  `json:"weight"`
+	// KeyType is the type of Key: address (default), secp256k1, ed25519 or p256.
+	// Public keys are enrolled by the address derived from them.
+	KeyType string `json:"keyType,omitempty"`
 }
 
 type VoteType struct {
@@ -259,6 +315,30 @@ type ElectionType struct {
 	DynamicCensus     bool `json:"dynamicCensus"`
 	SecretUntilTheEnd bool `json:"secretUntilTheEnd"`
 	Anonymous         bool `json:"anonymous"`
+	// Unlisted elections are excluded from the election lists, except the
+	// ones of their organization requested with its token (see
+	// UnlistedElectionsTokenKey).
+	Unlisted bool `json:"unlisted"`
+	// NullifierGroup, if set, makes the anonymous voters of the election share
+	// their nullifiers with the other elections of the organization with the
+	// same group, so that they can only vote in one of them. It is limited to
+	// 32 bytes and not supported by the elections with secret results.
+	NullifierGroup types.HexBytes `json:"nullifierGroup,omitempty"`
+	// VoteDeposit, if not zero, is the number of tokens each voter locks to
+	// cast a vote, refunded once the results are committed if the vote is
+	// counted, or if the election is canceled. It is a spam deterrent for
+	// open censuses, not supported by the anonymous elections.
+	VoteDeposit uint64 `json:"voteDeposit,omitempty"`
+	// ManualEnd opts out of the automatic end of the election once its
+	// duration elapses, so that its results are only computed once the
+	// organizer sets it as ended. The votes are not accepted after the end
+	// date in any case.
+	ManualEnd bool `json:"manualEnd,omitempty"`
+	// VoteDelegation lets the census members delegate their vote weight to
+	// another member until the election starts. The weight delegated is added
+	// to the vote of the delegate, and the delegators cannot vote. It is only
+	// supported by the signed elections with a census tree.
+	VoteDelegation bool `json:"voteDelegation,omitempty"`
 }
 
 type Transaction struct {
@@ -271,6 +351,19 @@ type Transaction struct {
 	ProcessID types.HexBytes    `json:"processId,omitempty" extensions:"x-omitempty" swaggerignore:"true" `
 }
 
+// TransactionSimulation is the expected outcome of a transaction, which is
+// not broadcasted.
+type TransactionSimulation struct {
+	Hash types.HexBytes `json:"hash,omitempty"`
+	Type string         `json:"type,omitempty"`
+	// Valid is true if the transaction would be accepted, else Error is the reason.
+	Valid    bool           `json:"valid"`
+	Error    string         `json:"error,omitempty"`
+	Response types.HexBytes `json:"response,omitempty"`
+	// Cost is the amount of tokens the transaction would burn.
+	Cost uint64 `json:"cost"`
+}
+
 type TransactionReference struct {
 	Height uint32 `json:"blockHeight"`
 	Index  uint32 `json:"transactionIndex"`
@@ -289,6 +382,15 @@ type FeesList struct {
 }
 
 // TransfersList is used to return a paginated list to the client
+// BlockDate relates a block height with its time.
+type BlockDate struct {
+	Height uint64    `json:"height"`
+	Date   time.Time `json:"date"`
+	// Estimated is true if the block is not produced yet, so its height or
+	// time is estimated from the average time of the last blocks.
+	Estimated bool `json:"estimated"`
+}
+
 type TransfersList struct {
 	Transfers  []*indexertypes.TokenTransferMeta `json:"transfers"`
 	Pagination *Pagination                       `json:"pagination"`
@@ -301,22 +403,55 @@ type GenericTransactionWithInfo struct {
 }
 
 type ChainInfo struct {
-	ID                string    `json:"chainId" example:"azeno"`
-	BlockTime         [5]uint64 `json:"blockTime" example:"12000,11580,11000,11100,11100"`
-	ElectionCount     uint64    `json:"electionCount" example:"120"`
-	OrganizationCount uint64    `json:"organizationCount" example:"20"`
-	GenesisTime       time.Time `json:"genesisTime"  format:"date-time" example:"2022-11-17T18:00:57.379551614Z"`
-	InitialHeight     uint32    `json:"initialHeight"  example:"5467"`
-	Height            uint32    `json:"height" example:"5467"`
-	BlockStoreBase    uint32    `json:"blockStoreBase" example:"5467"`
-	Syncing           bool      `json:"syncing" example:"true"`
-	Timestamp         int64     `json:"blockTimestamp" swaggertype:"string" format:"date-time" example:"2022-11-17T18:00:57.379551614Z"`
-	TransactionCount  uint64    `json:"transactionCount" example:"554"`
-	ValidatorCount    uint32    `json:"validatorCount" example:"5"`
-	VoteCount         uint64    `json:"voteCount" example:"432"`
-	CircuitVersion    string    `json:"circuitVersion" example:"v1.0.0"`
-	MaxCensusSize     uint64    `json:"maxCensusSize" example:"50000"`
-	NetworkCapacity   uint64    `json:"networkCapacity" example:"2000"`
+	ID                string         `json:"chainId" example:"azeno"`
+	BlockTime         [5]uint64      `json:"blockTime" example:"12000,11580,11000,11100,11100"`
+	ElectionCount     uint64         `json:"electionCount" example:"120"`
+	OrganizationCount uint64         `json:"organizationCount" example:"20"`
+	GenesisTime       time.Time      `json:"genesisTime"  format:"date-time" example:"2022-11-17T18:00:57.379551614Z"`
+	GenesisHash       types.HexBytes `json:"genesisHash" example:"5f8a8b9ac0b1e5a1c6d0e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3"`
+	InitialHeight     uint32         `json:"initialHeight"  example:"5467"`
+	Height            uint32         `json:"height" example:"5467"`
+	BlockStoreBase    uint32         `json:"blockStoreBase" example:"5467"`
+	Syncing           bool           `json:"syncing" example:"true"`
+	Timestamp         int64          `json:"blockTimestamp" swaggertype:"string" format:"date-time" example:"2022-11-17T18:00:57.379551614Z"`
+	TransactionCount  uint64         `json:"transactionCount" example:"554"`
+	ValidatorCount    uint32         `json:"validatorCount" example:"5"`
+	VoteCount         uint64         `json:"voteCount" example:"432"`
+	CircuitVersion    string         `json:"circuitVersion" example:"v1.0.0"`
+	MaxCensusSize     uint64         `json:"maxCensusSize" example:"50000"`
+	NetworkCapacity   uint64         `json:"networkCapacity" example:"2000"`
+}
+
+// IndexedChain is a chain whose indexed data is served by the API, see the
+// chainId query parameter of the chain endpoints.
+type IndexedChain struct {
+	ChainID string `json:"chainId" example:"vocdoni/LTS/1.2"`
+	// Height is the height of the last block indexed.
+	Height uint64 `json:"height" example:"5467"`
+	// Local is true for the chain of the node, the default one.
+	Local bool `json:"local"`
+}
+
+type IndexedChainsList struct {
+	Chains []*IndexedChain `json:"chains"`
+}
+
+// Capabilities describes the API surface served by a gateway.
+type Capabilities struct {
+	// Namespaces are the API namespaces enabled, such as "elections" or "votes".
+	Namespaces []string `json:"namespaces" example:"accounts,chain,elections,votes"`
+	// Indexer is true if the indexed data, such as the lists of elections and votes, is served.
+	Indexer bool `json:"indexer"`
+	// IndexedChains are the other chains whose indexed data is served, see IndexedChain.
+	IndexedChains []string `json:"indexedChains,omitempty" extensions:"x-omitempty"`
+	// Archive is true if all the blocks since the genesis are kept, so that the old blocks
+	// and transactions can be fetched.
+	Archive         bool   `json:"archive"`
+	ChainID         string `json:"chainId,omitempty" example:"azeno" extensions:"x-omitempty"`
+	CircuitVersion  string `json:"circuitVersion,omitempty" example:"v1.0.0" extensions:"x-omitempty"`
+	BlockTime       uint64 `json:"blockTime,omitempty" example:"12000" extensions:"x-omitempty"` // target, in milliseconds
+	MaxCensusSize   uint64 `json:"maxCensusSize,omitempty" example:"50000" extensions:"x-omitempty"`
+	NetworkCapacity uint64 `json:"networkCapacity,omitempty" example:"2000" extensions:"x-omitempty"`
 }
 
 type Account struct {
@@ -337,6 +472,48 @@ type AccountsList struct {
 	Pagination *Pagination             `json:"pagination"`
 }
 
+// ElectionStatusHistory is the list of status and duration changes of an election.
+type ElectionStatusHistory struct {
+	Changes []*indexertypes.ProcessStatusChange `json:"changes"`
+}
+
+// ElectionCensusHistory is the list of versions of the census of an election.
+type ElectionCensusHistory struct {
+	Versions []*indexertypes.CensusVersion `json:"versions"`
+}
+
+// ElectionDelegationsList is a paginated list of the vote weight delegations of
+// an election.
+type ElectionDelegationsList struct {
+	Delegations []*indexertypes.VoteDelegation `json:"delegations"`
+	Pagination  *Pagination                    `json:"pagination"`
+}
+
+// AccountKVList is the key-value store of an account.
+type AccountKVList struct {
+	Entries []*indexertypes.AccountKV `json:"entries"`
+}
+
+// AccountRecovery is the recovery state of an account and its history, most
+// recent first.
+type AccountRecovery struct {
+	// Owner is the key that controls the account since its guardians rotated
+	// it, unset if it was never rotated.
+	Owner types.HexBytes `json:"owner,omitempty"`
+	// Guardians are the guardians of the account, unset if it has none.
+	Guardians *state.RecoveryGuardians `json:"guardians,omitempty"`
+	// Pending is the recovery approved by some guardians but not executed yet.
+	Pending    *state.AccountRecovery               `json:"pending,omitempty"`
+	Events     []*indexertypes.AccountRecoveryEvent `json:"events"`
+	Pagination *Pagination                          `json:"pagination"`
+}
+
+// AccountFeed is the activity of an account, most recent first.
+type AccountFeed struct {
+	Items      []*indexertypes.AccountFeedItem `json:"items"`
+	Pagination *Pagination                     `json:"pagination"`
+}
+
 type AccountSet struct {
 	TxPayload   []byte         `json:"txPayload,omitempty" swaggerignore:"true"`
 	Metadata    []byte         `json:"metadata,omitempty" swaggerignore:"true"`
@@ -382,6 +559,18 @@ type Validator struct {
 	Score            uint32         `json:"score"`
 }
 
+// IndexedValidatorList is the list of all the validators seen by the indexer,
+// including the ones removed from the validator set.
+type IndexedValidatorList struct {
+	Validators []*indexertypes.Validator `json:"validators"`
+	Pagination *Pagination               `json:"pagination"`
+}
+
+type ValidatorSetChangesList struct {
+	Changes    []*indexertypes.ValidatorSetChange `json:"changes"`
+	Pagination *Pagination                        `json:"pagination"`
+}
+
 type BuildElectionID struct {
 	Delta          int32          `json:"delta"` // 0 means build next ElectionID
 	OrganizationID types.HexBytes `json:"organizationId"`
@@ -450,6 +639,20 @@ type Block struct {
 	comettypes.Header `json:"header"`
 	Hash              types.HexBytes `json:"hash" `
 	TxCount           int64          `json:"txCount"`
+	ProposerName      string         `json:"proposerName,omitempty"`
+	ProposerAccount   types.HexBytes `json:"proposerAccount,omitempty"`
+	ProposerPower     uint64         `json:"proposerPower,omitempty"`
+}
+
+// FeeMarket is the state of the dynamic transaction costs.
+type FeeMarket struct {
+	Enabled        bool   `json:"enabled"`
+	TargetBlockTxs uint32 `json:"targetBlockTxs,omitempty"`
+	MaxMultiplier  uint32 `json:"maxMultiplier,omitempty"`
+	// Multiplier is the factor applied to the base costs, in thousandths.
+	Multiplier uint64            `json:"multiplier"`
+	BaseCosts  map[string]uint64 `json:"baseCosts"`
+	Costs      map[string]uint64 `json:"costs"`
 }
 
 // BlockList is used to return a paginated list to the client
@@ -457,3 +660,71 @@ type BlockList struct {
 	Blocks     []*indexertypes.Block `json:"blocks"`
 	Pagination *Pagination           `json:"pagination"`
 }
+
+// BlockStatsList is used to return the aggregated block stats to the client
+type BlockStatsList struct {
+	Stats []*indexertypes.BlockStatsAggregate `json:"stats"`
+}
+
+// VoteStatsList is used to return the votes per hour to the client
+type VoteStatsList struct {
+	Stats []*indexertypes.HourlyVoteStats `json:"stats"`
+}
+
+// TxStatsList is used to return the transactions per day to the client
+type TxStatsList struct {
+	Stats []*indexertypes.DailyTxStats `json:"stats"`
+}
+
+// AccountStatsList is used to return the new accounts per day to the client
+type AccountStatsList struct {
+	Stats []*indexertypes.DailyAccountStats `json:"stats"`
+}
+
+// FeeStatsList is used to return the fees burned per day to the client
+type FeeStatsList struct {
+	Stats []*indexertypes.DailyFeeStats `json:"stats"`
+}
+
+// IndexerQuery is a read-only SQL query on the indexer database, with the
+// arguments of its placeholders.
+type IndexerQuery struct {
+	Query string `json:"query"`
+	Args  []any  `json:"args,omitempty"`
+}
+
+// BatchRequest is a list of API requests to be executed in a single round trip.
+type BatchRequest struct {
+	Requests []*BatchSubRequest `json:"requests"`
+}
+
+// BatchSubRequest is an API request of a batch. Path is relative to the base
+// route of the API, and may include a query string.
+type BatchSubRequest struct {
+	Method string          `json:"method" example:"GET"`
+	Path   string          `json:"path" example:"/elections/c5d2460186f760d51f4cc4d4ff3bbc68c37c5d8d7d4e0c8d5e2f100200000000"`
+	Body   json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+}
+
+// BatchResponse holds the responses to the requests of a batch, in the same order.
+type BatchResponse struct {
+	Responses []*BatchSubResponse `json:"responses"`
+}
+
+// BatchSubResponse is the response to an API request of a batch. Body is the
+// JSON response, or a JSON string if the response is not JSON.
+type BatchSubResponse struct {
+	Status int             `json:"status" example:"200"`
+	Body   json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+}
+
+// SIKValidity is the remaining validity of the SIK of an address.
+type SIKValidity struct {
+	Address types.HexBytes `json:"address"`
+	// Expires is false if the SIK stays in the state until it is replaced.
+	Expires bool `json:"expires"`
+	// ExpirationHeight is the height at which the SIK is removed from the state.
+	ExpirationHeight uint32 `json:"expirationHeight,omitempty"`
+	// RemainingBlocks is the number of blocks until the SIK expires.
+	RemainingBlocks uint32 `json:"remainingBlocks,omitempty"`
+}
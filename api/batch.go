@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"go.vocdoni.io/dvote/httprouter"
+	"go.vocdoni.io/dvote/httprouter/apirest"
+)
+
+const (
+	BatchHandler = "batch"
+
+	// MaxBatchRequests is the maximum number of requests of a batch.
+	MaxBatchRequests = 20
+)
+
+func (a *API) enableBatchHandlers() error {
+	if err := a.Endpoint.RegisterMethod(
+		"/batch",
+		"POST",
+		apirest.MethodAccessTypePublic,
+		a.batchHandler,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// batchHandler
+//
+//	@Summary		Execute a batch of requests
+//	@Description	Executes concurrently a list of API requests, returning their responses in the same order, so clients on
+//	@Description	high latency links can fetch several resources in a single round trip. Each request goes through the
+//	@Description	same authorization as if it were sent alone, using the bearer token of the batch. Batches cannot be nested.
+//	@Tags			Batch
+//	@Accept			json
+//	@Produce		json
+//	@Param			batch	body		BatchRequest	true	"List of requests, at most 20"
+//	@Success		200		{object}	BatchResponse
+//	@Router			/batch [post]
+func (a *API) batchHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	batch := &BatchRequest{}
+	if err := json.Unmarshal(msg.Data, batch); err != nil {
+		return ErrCantParseDataAsJSON.WithErr(err)
+	}
+	if len(batch.Requests) > MaxBatchRequests {
+		return ErrBatchTooLarge.Withf("%d requests, the maximum is %d", len(batch.Requests), MaxBatchRequests)
+	}
+	reqs := make([]*http.Request, len(batch.Requests))
+	for i, sub := range batch.Requests {
+		req, err := a.batchSubRequest(ctx.Request, msg.AuthToken, sub)
+		if err != nil {
+			return ErrBatchRequestInvalid.Withf("request %d: %v", i, err)
+		}
+		reqs[i] = req
+	}
+
+	resp := &BatchResponse{Responses: make([]*BatchSubResponse, len(reqs))}
+	wg := sync.WaitGroup{}
+	for i, req := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := &batchResponseWriter{header: http.Header{}}
+			a.Endpoint.Serve(w, req)
+			resp.Responses[i] = w.response()
+		}()
+	}
+	wg.Wait()
+	return marshalAndSend(ctx, resp)
+}
+
+// batchSubRequest builds the HTTP request of a request of a batch, which is
+// sent with the context, client address and bearer token of the batch.
+func (*API) batchSubRequest(batchReq *http.Request, authToken string, sub *BatchSubRequest) (*http.Request, error) {
+	if sub == nil {
+		return nil, fmt.Errorf("missing request")
+	}
+	u, err := url.Parse(sub.Path)
+	if err != nil {
+		return nil, err
+	}
+	if u.IsAbs() || !strings.HasPrefix(u.Path, "/") {
+		return nil, fmt.Errorf("path %q must be relative to the API base route", sub.Path)
+	}
+	if path.Clean(u.Path) == "/batch" {
+		return nil, fmt.Errorf("batches cannot be nested")
+	}
+	method := strings.ToUpper(sub.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(batchReq.Context(), method, u.String(), bytes.NewReader(sub.Body))
+	if err != nil {
+		return nil, err
+	}
+	req.RemoteAddr = batchReq.RemoteAddr
+	req.Header.Set("Content-Type", "application/json")
+	if authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+authToken)
+	}
+	return req, nil
+}
+
+// batchResponseWriter is an http.ResponseWriter which keeps the response to a
+// request of a batch in memory.
+type batchResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *batchResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *batchResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *batchResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// response returns the response written, with its body encoded as a JSON
+// string if it is not JSON.
+func (w *batchResponseWriter) response() *BatchSubResponse {
+	resp := &BatchSubResponse{Status: w.status}
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+	body := bytes.TrimSpace(w.body.Bytes())
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		resp.Body = body
+	default:
+		resp.Body, _ = json.Marshal(string(body))
+	}
+	return resp
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"slices"
+
+	"go.vocdoni.io/dvote/crypto/zk/circuit"
+	"go.vocdoni.io/dvote/httprouter"
+	"go.vocdoni.io/dvote/httprouter/apirest"
+)
+
+// CapabilitiesHandler is the namespace of the capabilities endpoint, which is
+// always enabled.
+const CapabilitiesHandler = "capabilities"
+
+func (a *API) enableCapabilitiesHandlers() error {
+	return a.Endpoint.RegisterMethod(
+		"/capabilities",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.capabilitiesHandler,
+	)
+}
+
+// capabilitiesHandler
+//
+//	@Summary		Gateway capabilities
+//	@Description	Returns the API namespaces enabled by the gateway, whether it serves the indexed data and
+//	@Description	keeps all the blocks since the genesis, and the chain parameters, so that the clients can
+//	@Description	tell the features that a gateway with a reduced API surface does not support.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	api.Capabilities
+//	@Router			/capabilities [get]
+func (a *API) capabilitiesHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	capabilities := &Capabilities{
+		Namespaces: slices.Sorted(slices.Values(a.enabledHandlers)),
+		Indexer:    a.indexer != nil,
+	}
+	if a.indexer != nil {
+		for _, idx := range a.indexer.Chains()[1:] {
+			capabilities.IndexedChains = append(capabilities.IndexedChains, idx.ChainID())
+		}
+	}
+	if a.vocapp != nil {
+		capabilities.ChainID = a.vocapp.ChainID()
+		capabilities.CircuitVersion = circuit.Version()
+		capabilities.BlockTime = uint64(a.vocapp.BlockTimeTarget().Milliseconds())
+		if a.vocapp.Node != nil && a.vocapp.Node.BlockStore() != nil {
+			capabilities.Archive = a.vocapp.Node.BlockStore().Base() <= a.vocapp.Genesis().InitialHeight
+		}
+		var err error
+		if capabilities.MaxCensusSize, err = a.vocapp.State.MaxProcessSize(); err != nil {
+			return err
+		}
+		if capabilities.NetworkCapacity, err = a.vocapp.State.NetworkCapacity(); err != nil {
+			return err
+		}
+	}
+	return marshalAndSend(ctx, capabilities)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 
 	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/censustree"
 	"go.vocdoni.io/dvote/util"
 	"go.vocdoni.io/proto/build/go/models"
 )
@@ -52,6 +53,24 @@ func censusKeyParse(key string) ([]byte, error) {
 	return hex.DecodeString(key)
 }
 
+// censusParticipantKey returns the key that identifies a participant in a census
+// of the given type, from its key and key type name (address by default).
+func censusParticipantKey(censusType int32, keyType string, key []byte) ([]byte, error) {
+	kt, err := censustree.KeyTypeFromString(keyType)
+	if err != nil {
+		return nil, ErrParamKeyTypeInvalid.WithErr(err)
+	}
+	// the keys of the zkweighted censuses must be zk-friendly addresses
+	if kt != censustree.KeyTypeAddress && censusType == int32(models.Census_ARBO_POSEIDON) {
+		return nil, ErrParamKeyTypeInvalid.Withf("%s keys are not supported by %s censuses", kt, CensusTypeZKWeighted)
+	}
+	participantKey, err := censustree.ParticipantKey(kt, key)
+	if err != nil {
+		return nil, ErrCensusKeyInvalid.WithErr(err)
+	}
+	return participantKey, nil
+}
+
 func addressParse(key string) common.Address {
 	return common.HexToAddress(util.TrimHex(key))
 }
@@ -14,6 +14,7 @@ import (
 	"go.vocdoni.io/dvote/util"
 
 	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/crypto/zk/circuit"
 	"go.vocdoni.io/dvote/data/ipfs"
 	"go.vocdoni.io/dvote/db"
 	"go.vocdoni.io/dvote/db/metadb"
@@ -262,6 +263,9 @@ func TestCensusZk(t *testing.T) {
 
 	storage := ipfs.MockIPFS(t)
 	app := vochain.TestBaseApplication(t)
+	// the keys of the zk census are addresses, which do not fit into the 10
+	// levels of the dev circuit
+	c.Assert(circuit.SetGlobal(circuit.V1_0_0), qt.IsNil)
 	testApi.Attach(app, nil, nil, storage, censusDB)
 	c.Assert(testApi.EnableHandlers(CensusHandler), qt.IsNil)
 
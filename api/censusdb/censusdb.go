@@ -2,16 +2,20 @@ package censusdb
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"go.vocdoni.io/dvote/censustree"
+	"go.vocdoni.io/dvote/data"
 	"go.vocdoni.io/dvote/data/compressor"
 	"go.vocdoni.io/dvote/data/ipfs"
 	"go.vocdoni.io/dvote/db"
@@ -25,6 +29,21 @@ const (
 	censusDBreferencePrefix = "cr_"
 )
 
+const (
+	// MaxCensusLayers is the maximum number of ancestors of a published census
+	// (see CensusDump.Parent). Once reached, the census is published in full.
+	MaxCensusLayers = 32
+	// ParentRetrieveTimeout is the maximum duration to retrieve each of the
+	// ancestors of a census being imported.
+	ParentRetrieveTimeout = 3 * time.Minute
+	// maxParentSize is the maximum size of each of the ancestors of a census.
+	maxParentSize = 100 * 1024 * 1024
+	// censusProofCacheSize is the number of proofs cached by each census tree,
+	// so that the proofs of the same voters requested repeatedly (such as when
+	// an election starts) are generated only once for each root.
+	censusProofCacheSize = 256
+)
+
 var (
 	// ErrCensusNotFound is returned when a census is not found in the database.
 	ErrCensusNotFound = fmt.Errorf("census not found in the local database")
@@ -34,6 +53,9 @@ var (
 	ErrWrongAuthenticationToken = fmt.Errorf("wrong authentication token")
 	// ErrCensusIsLocked is returned if the census does not allow write operations.
 	ErrCensusIsLocked = fmt.Errorf("census is locked")
+	// ErrCensusParentUnavailable is returned if an ancestor of a census being
+	// imported cannot be resolved.
+	ErrCensusParentUnavailable = fmt.Errorf("parent census unavailable")
 )
 
 // CensusRef is a reference to a census. It holds the merkle tree which can be acceded
@@ -46,6 +68,18 @@ type CensusRef struct {
 	// MaxLevels is required to load the census with the original size because
 	// it could be different according to the election (and census) type.
 	MaxLevels int
+	// Published is the last publication of the census, nil if it was never
+	// published.
+	Published *PublishedCensus
+}
+
+// PublishedCensus references a publication of a census, which the following one
+// can extend with the leaves added since then (see CensusDump.Parent).
+type PublishedCensus struct {
+	Root types.HexBytes
+	URI  string
+	// Layers is the number of ancestors of the published census.
+	Layers int
 }
 
 // CensusList is a struct that contains the summary of a census
@@ -80,6 +114,13 @@ type CensusDump struct {
 	Token     *uuid.UUID     `json:"token,omitempty"`
 	Size      uint64         `json:"size,omitempty"`
 	URI       string         `json:"uri,omitempty"`
+	// Parent is the URI of the published census extended by this one, if Data
+	// only holds the leaves added since then. The full census is the result of
+	// importing the Data of all its ancestors first.
+	Parent     string         `json:"parent,omitempty"`
+	ParentRoot types.HexBytes `json:"parentRoot,omitempty"`
+	// Layer is the number of ancestors of the census.
+	Layer int `json:"layer,omitempty"`
 }
 
 // CensusDB is a safe and persistent database of census trees.  It allows
@@ -87,6 +128,8 @@ type CensusDump struct {
 type CensusDB struct {
 	sync.Mutex
 	db db.Database
+	// storage is used to retrieve the ancestors of the censuses being imported.
+	storage data.Storage
 }
 
 // NewCensusDB creates a new CensusDB object.
@@ -94,6 +137,12 @@ func NewCensusDB(db db.Database) *CensusDB {
 	return &CensusDB{db: db}
 }
 
+// SetStorage sets the remote storage from which the ancestors of the censuses
+// being imported are retrieved, when they are not found in the local database.
+func (c *CensusDB) SetStorage(storage data.Storage) {
+	c.storage = storage
+}
+
 // New creates a new census and adds it to the database.
 func (c *CensusDB) New(censusID []byte, censusType models.Census_Type,
 	uri string, authToken *uuid.UUID, maxLevels int,
@@ -104,6 +153,7 @@ func (c *CensusDB) New(censusID []byte, censusType models.Census_Type,
 	tree, err := censustree.New(censustree.Options{
 		Name:     censusName(censusID),
 		ParentDB: c.db, MaxLevels: maxLevels, CensusType: censusType,
+		ProofCacheSize: censusProofCacheSize,
 	})
 	if err != nil {
 		return nil, err
@@ -143,10 +193,11 @@ func (c *CensusDB) Load(censusID []byte, authToken *uuid.UUID) (*CensusRef, erro
 	}
 	ref.tree, err = censustree.New(
 		censustree.Options{
-			Name:       censusName(censusID),
-			ParentDB:   c.db,
-			MaxLevels:  ref.MaxLevels,
-			CensusType: models.Census_Type(ref.CensusType),
+			Name:           censusName(censusID),
+			ParentDB:       c.db,
+			MaxLevels:      ref.MaxLevels,
+			CensusType:     models.Census_Type(ref.CensusType),
+			ProofCacheSize: censusProofCacheSize,
 		})
 	if err != nil {
 		return nil, err
@@ -206,6 +257,131 @@ func BuildExportDump(root, data []byte, typ models.Census_Type, maxLevels int) (
 	return exportData, nil
 }
 
+// BuildIncrementalExportDump builds a census serialization of ref, whose root is
+// given, holding only the leaves added since its last publication, which must be
+// in the local database. It returns censustree.ErrNotIncremental if the census
+// cannot be published as an extension of its last publication, in which case it
+// must be published in full with BuildExportDump.
+func (c *CensusDB) BuildIncrementalExportDump(ref *CensusRef, root []byte) ([]byte, error) {
+	parent := ref.Published
+	if parent == nil || parent.URI == "" || parent.Layers+1 > MaxCensusLayers {
+		return nil, censustree.ErrNotIncremental
+	}
+	parentTree, err := c.publishedTree(parent.Root)
+	if err != nil {
+		if errors.Is(err, ErrCensusNotFound) {
+			return nil, censustree.ErrNotIncremental
+		}
+		return nil, err
+	}
+	delta, err := ref.Tree().DumpDelta(parentTree)
+	if err != nil {
+		return nil, err
+	}
+	export := CensusDump{
+		Type:       models.Census_Type(ref.CensusType),
+		RootHash:   root,
+		Data:       compressor.NewCompressor().CompressBytes(delta),
+		MaxLevels:  ref.MaxLevels,
+		Parent:     parent.URI,
+		ParentRoot: parent.Root,
+		Layer:      parent.Layers + 1,
+	}
+	return json.Marshal(export)
+}
+
+// SetPublished sets the last publication of the census.
+func (c *CensusDB) SetPublished(censusID []byte, published *PublishedCensus) error {
+	ref, err := c.getCensusRefFromDB(censusID)
+	if err != nil {
+		return err
+	}
+	ref.Published = published
+	wtx := c.db.WriteTx()
+	defer wtx.Discard()
+	if err := setCensusRef(wtx, censusID, ref); err != nil {
+		return err
+	}
+	return wtx.Commit()
+}
+
+// ResolveDump returns the decompressed tree dump of the census, including the
+// leaves of all its ancestors. They are taken from the local database if the
+// census was already imported, or retrieved from the storage otherwise.
+func (c *CensusDB) ResolveDump(cdata *CensusDump) ([]byte, error) {
+	dump := compressor.NewCompressor().DecompressBytes(cdata.Data)
+	for layer := 0; cdata.Parent != ""; layer++ {
+		if layer == MaxCensusLayers {
+			return nil, fmt.Errorf("census has more than %d layers", MaxCensusLayers)
+		}
+		if parentDump, ok := c.localDump(cdata.ParentRoot); ok {
+			return append(parentDump, dump...), nil
+		}
+		parent, err := c.retrieveParent(cdata)
+		if err != nil {
+			return nil, err
+		}
+		dump = append(compressor.NewCompressor().DecompressBytes(parent.Data), dump...)
+		cdata = parent
+	}
+	return dump, nil
+}
+
+// retrieveParent retrieves the parent census of cdata from the storage.
+func (c *CensusDB) retrieveParent(cdata *CensusDump) (*CensusDump, error) {
+	if c.storage == nil {
+		return nil, fmt.Errorf("%w: no storage to retrieve %s", ErrCensusParentUnavailable, cdata.Parent)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ParentRetrieveTimeout)
+	defer cancel()
+	data, err := c.storage.Retrieve(ctx, strings.TrimPrefix(cdata.Parent, c.storage.URIprefix()), maxParentSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot retrieve %s: %v", ErrCensusParentUnavailable, cdata.Parent, err)
+	}
+	parent := &CensusDump{}
+	if err := json.Unmarshal(data, parent); err != nil {
+		return nil, fmt.Errorf("could not unmarshal parent census: %w", err)
+	}
+	if !bytes.Equal(parent.RootHash, cdata.ParentRoot) || parent.Type != cdata.Type {
+		return nil, fmt.Errorf("parent census %s does not match the census", cdata.Parent)
+	}
+	return parent, nil
+}
+
+// localDump returns the tree dump of the published census with the given root,
+// and whether it is in the local database.
+func (c *CensusDB) localDump(root []byte) ([]byte, bool) {
+	tree, err := c.publishedTree(root)
+	if err != nil {
+		return nil, false
+	}
+	if treeRoot, err := tree.Root(); err != nil || !bytes.Equal(treeRoot, root) {
+		return nil, false
+	}
+	dump, err := tree.Dump()
+	if err != nil {
+		log.Warnw("cannot dump local census", "root", hex.EncodeToString(root), "err", err)
+		return nil, false
+	}
+	return dump, true
+}
+
+// publishedTree opens the tree of a census without locking the database, which
+// is safe for the published censuses since they are not modified.
+func (c *CensusDB) publishedTree(censusID []byte) (*censustree.Tree, error) {
+	ref, err := c.getCensusRefFromDB(censusID)
+	if err != nil {
+		return nil, err
+	}
+	return censustree.New(censustree.Options{
+		Name:           censusName(censusID),
+		ParentDB:       c.db,
+		MaxLevels:      ref.MaxLevels,
+		CensusType:     models.Census_Type(ref.CensusType),
+		ProofCacheSize: censusProofCacheSize,
+	})
+}
+
 // ImportTree imports a census from a dump.
 func (c *CensusDB) ImportTree(censusID, data []byte) error {
 	return c.importTreeCommon(censusID, data)
@@ -225,6 +401,10 @@ func (c *CensusDB) importTreeCommon(censusID []byte, data []byte) error {
 	if cdata.Data == nil || cdata.RootHash == nil {
 		return fmt.Errorf("missing dump or root parameters")
 	}
+	dump, err := c.ResolveDump(&cdata)
+	if err != nil {
+		return err
+	}
 	// If the censusID is nil, it means that the census is imported as public.
 	isPublic := false
 	if censusID == nil {
@@ -256,7 +436,7 @@ func (c *CensusDB) importTreeCommon(censusID []byte, data []byte) error {
 		return err
 	}
 
-	if err := ref.Tree().ImportDump(compressor.NewCompressor().DecompressBytes(cdata.Data)); err != nil {
+	if err := ref.Tree().ImportDump(dump); err != nil {
 		return err
 	}
 
@@ -281,24 +461,27 @@ func (c *CensusDB) addCensusRefToDB(censusID []byte, authToken *uuid.UUID,
 ) (*CensusRef, error) {
 	wtx := c.db.WriteTx()
 	defer wtx.Discard()
-	refData := bytes.Buffer{}
-	enc := gob.NewEncoder(&refData)
 	ref := &CensusRef{
 		AuthToken:  authToken,
 		CensusType: int32(t),
 		URI:        uri,
 		MaxLevels:  maxLevels,
 	}
-	if err := enc.Encode(ref); err != nil {
-		return nil, err
-	}
-	if err := wtx.Set(append([]byte(censusDBreferencePrefix), censusID...),
-		refData.Bytes()); err != nil {
+	if err := setCensusRef(wtx, censusID, ref); err != nil {
 		return nil, err
 	}
 	return ref, wtx.Commit()
 }
 
+// setCensusRef stores the censusRef in the database.
+func setCensusRef(wtx db.WriteTx, censusID []byte, ref *CensusRef) error {
+	refData := bytes.Buffer{}
+	if err := gob.NewEncoder(&refData).Encode(ref); err != nil {
+		return err
+	}
+	return wtx.Set(append([]byte(censusDBreferencePrefix), censusID...), refData.Bytes())
+}
+
 // getCensusRefFromDB returns the censusRef from the database.
 func (c *CensusDB) getCensusRefFromDB(censusID []byte) (*CensusRef, error) {
 	b, err := c.db.Get(
@@ -2,6 +2,7 @@ package censusdb
 
 import (
 	"bytes"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/google/uuid" // This is a helper library for cleaner test assertions.
 	"go.vocdoni.io/dvote/db"
 	"go.vocdoni.io/dvote/db/metadb"
+	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/proto/build/go/models"
 )
 
@@ -171,3 +173,52 @@ func TestImportCensusDB(t *testing.T) {
 	}
 	newDB.UnLoad()
 }
+
+func TestImportLayeredCensus(t *testing.T) {
+	censusDB := NewCensusDB(newDatabase(t))
+	censusID := []byte("testCensus")
+	censusRef, err := censusDB.New(censusID, models.Census_ARBO_BLAKE2B, "", nil, 32)
+	qt.Assert(t, err, qt.IsNil)
+	for _, k := range []string{"key1", "key2", "key3"} {
+		qt.Assert(t, censusRef.Tree().Add([]byte(k), []byte("value")), qt.IsNil)
+	}
+
+	// Publish the census in full
+	root1, err := censusRef.Tree().Root()
+	qt.Assert(t, err, qt.IsNil)
+	dump, err := censusRef.Tree().Dump()
+	qt.Assert(t, err, qt.IsNil)
+	full, err := BuildExportDump(root1, dump, models.Census_ARBO_BLAKE2B, 32)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, censusDB.ImportTreeAsPublic(full), qt.IsNil)
+	published := &PublishedCensus{Root: root1, URI: "ipfs://parent"}
+	qt.Assert(t, censusDB.SetPublished(censusID, published), qt.IsNil)
+
+	// Publish the new leaves on top of the first publication
+	for _, k := range []string{"key4", "key5"} {
+		qt.Assert(t, censusRef.Tree().Add([]byte(k), []byte("value")), qt.IsNil)
+	}
+	root2, err := censusRef.Tree().Root()
+	qt.Assert(t, err, qt.IsNil)
+	censusRef.Published = published
+	incremental, err := censusDB.BuildIncrementalExportDump(censusRef, root2)
+	qt.Assert(t, err, qt.IsNil)
+	cdata := CensusDump{}
+	qt.Assert(t, json.Unmarshal(incremental, &cdata), qt.IsNil)
+	qt.Assert(t, cdata.Layer, qt.Equals, 1)
+	qt.Assert(t, cdata.Parent, qt.Equals, "ipfs://parent")
+	qt.Assert(t, cdata.ParentRoot, qt.DeepEquals, types.HexBytes(root1))
+
+	// The parent is found in the local database
+	qt.Assert(t, censusDB.ImportTreeAsPublic(incremental), qt.IsNil)
+	imported, err := censusDB.Load(root2, nil)
+	qt.Assert(t, err, qt.IsNil)
+	size, err := imported.Tree().Size()
+	censusDB.UnLoad()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, size, qt.Equals, uint64(5))
+
+	// Without the parent nor a storage to retrieve it, the import fails
+	err = NewCensusDB(newDatabase(t)).ImportTreeAsPublic(incremental)
+	qt.Assert(t, err, qt.ErrorIs, ErrCensusParentUnavailable)
+}
@@ -238,9 +238,14 @@ func (a *API) censusCreateHandler(msg *apirest.APIdata, ctx *httprouter.HTTPCont
 		return ErrCensusTypeUnknown
 	}
 
-	// census max levels is limited by global ZkCircuit Levels
+	// the max levels of the anonymous censuses are limited by the global
+	// ZkCircuit Levels, since its proofs must fit into the circuit
+	maxLevels := censustree.DefaultMaxLevels
+	if censusType == models.Census_ARBO_POSEIDON {
+		maxLevels = circuit.Global().Config.Levels
+	}
 	censusID := util.RandomBytes(32)
-	_, err = a.censusdb.New(censusID, censusType, "", &token, circuit.Global().Config.Levels)
+	_, err = a.censusdb.New(censusID, censusType, "", &token, maxLevels)
 	if err != nil {
 		return err
 	}
@@ -313,14 +318,19 @@ func (a *API) censusAddHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext
 			p.Weight = new(types.BigInt).SetUint64(1)
 		}
 
-		leafKey := p.Key
+		// public keys are enrolled by the address derived from them
+		participantKey, err := censusParticipantKey(ref.CensusType, p.KeyType, p.Key)
+		if err != nil {
+			return err
+		}
+		leafKey := participantKey
 		if len(leafKey) > censustree.DefaultMaxKeyLen {
 			return ErrInvalidCensusKeyLength.Withf("the census key cannot be longer than %d bytes", censustree.DefaultMaxKeyLen)
 		}
 
 		if ref.CensusType != int32(models.Census_ARBO_POSEIDON) {
 			// compute the hash, we use it as key for the merkle tree
-			leafKey, err = ref.Tree().Hash(p.Key)
+			leafKey, err = ref.Tree().Hash(participantKey)
 			if err != nil {
 				return ErrCantComputeKeyHash.WithErr(err)
 			}
@@ -506,7 +516,11 @@ func (a *API) censusImportHandler(msg *apirest.APIdata, ctx *httprouter.HTTPCont
 		return ErrCensusTypeMismatch
 	}
 
-	if err := ref.Tree().ImportDump(compressor.NewCompressor().DecompressBytes(cdata.Data)); err != nil {
+	dump, err := a.censusdb.ResolveDump(&cdata)
+	if err != nil {
+		return err
+	}
+	if err := ref.Tree().ImportDump(dump); err != nil {
 		return err
 	}
 
@@ -567,7 +581,7 @@ func (a *API) censusWeightHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContex
 //	@Accept			json
 //	@Produce		json
 //	@Param			censusId	path		string				true	"Census id"
-//	@Success		200			{object}	object{size=string}	"Size as integer"
+//	@Success		200			{object}	object{size=number}	"Size as integer"
 //	@Router			/censuses/{censusId}/size [get]
 func (a *API) censusSizeHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
 	censusID, err := censusIDparse(ctx.URLParam(ParamCensusId))
@@ -669,6 +683,7 @@ func (a *API) censusPublishHandler(msg *apirest.APIdata, ctx *httprouter.HTTPCon
 		return err
 	}
 
+	fromRootParam := ctx.URLParam("root") != ""
 	if root := ctx.URLParam("root"); root != "" {
 		fromRoot, err := hex.DecodeString(root)
 		if err != nil {
@@ -699,6 +714,12 @@ func (a *API) censusPublishHandler(msg *apirest.APIdata, ctx *httprouter.HTTPCon
 			}
 			return err
 		}
+		// the next publication of the census can extend the existing one
+		if ref.Published != nil && !fromRootParam {
+			if err := a.censusdb.SetPublished(censusID, ref.Published); err != nil {
+				log.Warnw("cannot set published census", "censusID", hex.EncodeToString(censusID), "err", err)
+			}
+		}
 		// if async, store the URI in the map for the check endpoint
 		if async {
 			a.censusPublishStatusMap.Store(hex.EncodeToString(root), ref.URI)
@@ -721,13 +742,24 @@ func (a *API) censusPublishHandler(msg *apirest.APIdata, ctx *httprouter.HTTPCon
 			return "", err
 		}
 
-		// export the tree to the remote storage (IPFS)
+		// export the tree to the remote storage (IPFS), only with the leaves
+		// added since its last publication if possible
 		uri := ""
+		var published *censusdb.PublishedCensus
 		if a.storage != nil {
-			exportData, err := censusdb.BuildExportDump(root, dump,
-				models.Census_Type(ref.CensusType), ref.MaxLevels)
-			if err != nil {
-				return "", err
+			layers := 0
+			exportData, err := a.censusdb.BuildIncrementalExportDump(ref, root)
+			if err == nil {
+				layers = ref.Published.Layers + 1
+			} else {
+				if !errors.Is(err, censustree.ErrNotIncremental) {
+					log.Warnw("cannot build incremental census dump", "root", hex.EncodeToString(root), "err", err)
+				}
+				exportData, err = censusdb.BuildExportDump(root, dump,
+					models.Census_Type(ref.CensusType), ref.MaxLevels)
+				if err != nil {
+					return "", err
+				}
 			}
 			sctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
@@ -736,6 +768,7 @@ func (a *API) censusPublishHandler(msg *apirest.APIdata, ctx *httprouter.HTTPCon
 				log.Errorf("could not export tree to storage: %v", err)
 			} else {
 				uri = a.storage.URIprefix() + cid
+				published = &censusdb.PublishedCensus{Root: root, URI: uri, Layers: layers}
 			}
 		}
 
@@ -744,7 +777,22 @@ func (a *API) censusPublishHandler(msg *apirest.APIdata, ctx *httprouter.HTTPCon
 		if err != nil {
 			return "", err
 		}
-		return uri, newRef.Tree().ImportDump(dump)
+		if err := newRef.Tree().ImportDump(dump); err != nil {
+			return "", err
+		}
+		if published != nil {
+			// the next publications of the census can extend this one
+			censusIDs := [][]byte{root}
+			if !fromRootParam {
+				censusIDs = append(censusIDs, censusID)
+			}
+			for _, id := range censusIDs {
+				if err := a.censusdb.SetPublished(id, published); err != nil {
+					return "", err
+				}
+			}
+		}
+		return uri, nil
 	}
 
 	if async {
@@ -832,6 +880,7 @@ func (a *API) censusPublishCheckHandler(_ *apirest.APIdata, ctx *httprouter.HTTP
 //	@Security				BasicAuth
 //	@Param					censusId	path		string											true	"Census id"
 //	@Param					key			path		string											true	"Key to proof"
+//	@Param					keyType		query		string											false	"Type of the key: address (default), secp256k1, ed25519 or p256"
 //	@Success				200			{object}	object{weight=number,proof=string,value=string}	"where proof is Merkle tree siblings and value is Merkle tree leaf value"
 //	@Router					/censuses/{censusId}/proof/{key} [get]
 func (a *API) censusProofHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
@@ -851,6 +900,10 @@ func (a *API) censusProofHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext
 		}
 		return err
 	}
+	address, err = censusParticipantKey(ref.CensusType, ctx.QueryParam(ParamKeyType), address)
+	if err != nil {
+		return err
+	}
 	// Get the census type to return it into the response to prevent to perform
 	// two api calls.
 	censusType := encodeCensusType(models.Census_Type(ref.CensusType))
@@ -21,8 +21,33 @@ import (
 
 const (
 	ChainHandler = "chain"
+
+	// DefaultValidatorUptimeWindow is the number of blocks used to compute the uptime
+	// of a validator, when the client doesn't specify a `window` param
+	DefaultValidatorUptimeWindow = 1000
+	// MaxValidatorUptimeWindow defines a ceiling for the `window` param
+	MaxValidatorUptimeWindow = 100000
+	// DefaultBlockStatsRange is the time range aggregated by the block stats
+	// endpoint, when the client doesn't specify a `from` param
+	DefaultBlockStatsRange = 24 * time.Hour
+	// MaxBlockStatsBuckets defines a ceiling for the number of intervals returned
+	// by the block stats endpoint
+	MaxBlockStatsBuckets = 1000
+	// DefaultHourlyStatsRange is the time range returned by the hourly chain stats
+	// endpoints, when the client doesn't specify a `from` param
+	DefaultHourlyStatsRange = 24 * time.Hour
+	// DefaultDailyStatsRange is the time range returned by the daily chain stats
+	// endpoints, when the client doesn't specify a `from` param
+	DefaultDailyStatsRange = 30 * 24 * time.Hour
 )
 
+// blockStatsIntervals are the accepted values of the block stats `interval` param
+var blockStatsIntervals = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
 func (a *API) enableChainHandlers() error {
 	if err := a.Endpoint.RegisterMethod(
 		"/chain/organizations",
@@ -56,6 +81,14 @@ func (a *API) enableChainHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/indexed",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainIndexedListHandler,
+	); err != nil {
+		return err
+	}
 	if err := a.Endpoint.RegisterMethod(
 		"/chain/info/circuit",
 		"GET",
@@ -88,6 +121,22 @@ func (a *API) enableChainHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/date-to-block/{timestamp}",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainDateToBlockHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/block-to-date/{height}",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainBlockToDateHandler,
+	); err != nil {
+		return err
+	}
 	if err := a.Endpoint.RegisterMethod(
 		"/chain/transactions/cost",
 		"GET",
@@ -128,6 +177,14 @@ func (a *API) enableChainHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/transactions/simulate",
+		"POST",
+		apirest.MethodAccessTypePublic,
+		a.chainSimulateTxHandler,
+	); err != nil {
+		return err
+	}
 	if err := a.Endpoint.RegisterMethod(
 		"/chain/transactions",
 		"GET",
@@ -144,6 +201,14 @@ func (a *API) enableChainHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/transactions/{hash}/receipt",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainTxReceiptHandler,
+	); err != nil {
+		return err
+	}
 	if err := a.Endpoint.RegisterMethod(
 		"/chain/transactions/page/{page}",
 		"GET",
@@ -160,6 +225,30 @@ func (a *API) enableChainHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/validators/all",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainValidatorsAllHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/validators/history",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainValidatorsHistoryHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/validators/{validatorAddress}/uptime",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainValidatorUptimeHandler,
+	); err != nil {
+		return err
+	}
 	if err := a.Endpoint.RegisterMethod(
 		"/chain/blocks/{height}",
 		"GET",
@@ -168,6 +257,54 @@ func (a *API) enableChainHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/blocks/{height}/stats",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainBlockStatsHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/blocks/stats",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainBlockStatsAggregateHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/stats/votes",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainVoteStatsHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/stats/transactions",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainTxStatsHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/stats/accounts",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainAccountStatsHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/stats/fees",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainFeeStatsHandler,
+	); err != nil {
+		return err
+	}
 	if err := a.Endpoint.RegisterMethod(
 		"/chain/blocks/hash/{hash}",
 		"GET",
@@ -208,6 +345,14 @@ func (a *API) enableChainHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/fees/market",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainFeeMarketHandler,
+	); err != nil {
+		return err
+	}
 	if err := a.Endpoint.RegisterMethod(
 		"/chain/fees/page/{page}",
 		"GET",
@@ -248,6 +393,14 @@ func (a *API) enableChainHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/indexer/status",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.chainIndexerStatusHandler,
+	); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -262,9 +415,14 @@ func (a *API) enableChainHandlers() error {
 //	@Param					page			query		number	false	"Page"
 //	@Param					limit			query		number	false	"Items per page"
 //	@Param					organizationId	query		string	false	"Filter by partial organizationId"
+//	@Param					chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success				200				{object}	OrganizationsList
 //	@Router					/chain/organizations [get]
 func (a *API) organizationListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := parseOrganizationParams(
 		ctx.QueryParam(ParamPage),
 		ctx.QueryParam(ParamLimit),
@@ -274,7 +432,7 @@ func (a *API) organizationListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPCo
 		return err
 	}
 
-	list, err := a.organizationList(params)
+	list, err := a.organizationList(idx, params)
 	if err != nil {
 		return err
 	}
@@ -304,7 +462,7 @@ func (a *API) organizationListByPageHandler(_ *apirest.APIdata, ctx *httprouter.
 		return err
 	}
 
-	list, err := a.organizationList(params)
+	list, err := a.organizationList(a.indexer, params)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -347,7 +505,7 @@ func (a *API) organizationListByFilterAndPageHandler(msg *apirest.APIdata, ctx *
 		return ErrMissingParameter
 	}
 
-	list, err := a.organizationList(params)
+	list, err := a.organizationList(a.indexer, params)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -362,8 +520,8 @@ func (a *API) organizationListByFilterAndPageHandler(msg *apirest.APIdata, ctx *
 // organizationList produces a filtered, paginated OrganizationsList.
 //
 // Errors returned are always of type APIerror.
-func (a *API) organizationList(params *OrganizationParams) (*OrganizationsList, error) {
-	orgs, total, err := a.indexer.EntityList(
+func (a *API) organizationList(idx *indexer.Indexer, params *OrganizationParams) (*OrganizationsList, error) {
+	orgs, total, err := idx.EntityList(
 		params.Limit,
 		params.Page*params.Limit,
 		params.OrganizationID,
@@ -463,6 +621,7 @@ func (a *API) chainInfoHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext)
 		Timestamp:         a.vocapp.Timestamp(),
 		VoteCount:         voteCount,
 		GenesisTime:       a.vocapp.Genesis().GenesisTime,
+		GenesisHash:       a.vocapp.Genesis().Hash(),
 		InitialHeight:     uint32(a.vocapp.Genesis().InitialHeight),
 		BlockStoreBase:    blockStoreBase,
 		CircuitVersion:    circuit.Version(),
@@ -475,6 +634,29 @@ func (a *API) chainInfoHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext)
 	return ctx.Send(data, apirest.HTTPstatusOK)
 }
 
+// chainIndexedListHandler
+//
+//	@Summary		Indexed chains
+//	@Description	Returns the chains whose indexed data is served by the API, such as other networks of the
+//	@Description	same explorer. The chain endpoints served from the indexer select one of them with the
+//	@Description	`chainId` query parameter, which defaults to the chain of the node.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	api.IndexedChainsList
+//	@Router			/chain/indexed [get]
+func (a *API) chainIndexedListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	list := &IndexedChainsList{Chains: []*IndexedChain{}}
+	for _, idx := range a.indexer.Chains() {
+		list.Chains = append(list.Chains, &IndexedChain{
+			ChainID: idx.ChainID(),
+			Height:  uint64(idx.Status().Height),
+			Local:   idx == a.indexer,
+		})
+	}
+	return marshalAndSend(ctx, list)
+}
+
 // chainCircuitInfoHandler
 //
 //	@Summary		Circuit info
@@ -574,6 +756,70 @@ func (a *API) chainEstimateDateHandler(_ *apirest.APIdata, ctx *httprouter.HTTPC
 	return ctx.Send(data, apirest.HTTPstatusOK)
 }
 
+// chainDateToBlockHandler
+//
+//	@Summary		Date to block
+//	@Description	Returns the height of the last block produced at or before the timestamp, from the indexed block
+//	@Description	times. For timestamps after the last indexed block, the height is estimated from the average time of
+//	@Description	the last indexed blocks.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			timestamp	path		string	true	"Timestamp on unix format"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200			{object}	BlockDate
+//	@Router			/chain/date-to-block/{timestamp} [get]
+func (a *API) chainDateToBlockHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	timestamp, err := strconv.ParseInt(ctx.URLParam("timestamp"), 10, 64)
+	if err != nil {
+		return ErrCantParseNumber.WithErr(err)
+	}
+	date := time.Unix(timestamp, 0)
+	height, estimated, err := idx.HeightAtTime(date)
+	if err != nil {
+		if errors.Is(err, indexer.ErrBlockNotFound) {
+			return ErrBlockNotFound
+		}
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, &BlockDate{Height: uint64(height), Date: date, Estimated: estimated})
+}
+
+// chainBlockToDateHandler
+//
+//	@Summary		Block to date
+//	@Description	Returns the time of the block at the height, from the indexed block times. For blocks after the last
+//	@Description	indexed block, the time is estimated from the average time of the last indexed blocks.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			height	path		number	true	"Block height"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	BlockDate
+//	@Router			/chain/block-to-date/{height} [get]
+func (a *API) chainBlockToDateHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	height, err := strconv.ParseUint(ctx.URLParam(ParamHeight), 10, 64)
+	if err != nil {
+		return ErrCantParseNumber.WithErr(err)
+	}
+	date, estimated, err := idx.TimeAtHeight(int64(height))
+	if err != nil {
+		if errors.Is(err, indexer.ErrBlockNotFound) {
+			return ErrBlockNotFound
+		}
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, &BlockDate{Height: height, Date: date, Estimated: estimated})
+}
+
 // chainSendTxHandler
 //
 //	@Summary				Submit transaction
@@ -604,10 +850,45 @@ func (a *API) chainSendTxHandler(msg *apirest.APIdata, ctx *httprouter.HTTPConte
 	return ctx.Send(data, apirest.HTTPstatusOK)
 }
 
+// chainSimulateTxHandler
+//
+//	@Summary		Simulate transaction
+//	@Description	Runs a signed transaction through the same checks of the mempool and the block execution, on a copy of
+//	@Description	the last committed state, without broadcasting it. Returns whether it would be accepted, the reason if
+//	@Description	not, and the tokens it would cost. The transactions not yet included in a block are not taken into account.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			transaction	body		object{payload=string}	true	"Base64 payload string containing transaction data and signature"
+//	@Success		200			{object}	api.TransactionSimulation
+//	@Router			/chain/transactions/simulate [post]
+func (a *API) chainSimulateTxHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	req := &Transaction{}
+	if err := json.Unmarshal(msg.Data, req); err != nil {
+		return ErrCantParseDataAsJSON.WithErr(err)
+	}
+	sim, err := a.vocapp.SimulateTx(req.Payload)
+	if err != nil {
+		return ErrCantSimulateTx.WithErr(err)
+	}
+	resp := &TransactionSimulation{
+		Hash:     sim.TxHash,
+		Type:     sim.TxType,
+		Valid:    sim.Err == nil,
+		Response: sim.Data,
+		Cost:     sim.Cost,
+	}
+	if sim.Err != nil {
+		resp.Error = sim.Err.Error()
+	}
+	return marshalAndSend(ctx, resp)
+}
+
 // chainTxCostHandler
 //
 //	@Summary		Transaction costs
-//	@Description	Returns the list of transactions and its cost
+//	@Description	Returns the list of transactions and their current cost, which changes with the block utilization
+//	@Description	if the fee market is enabled (see /chain/fees/market).
 //	@Tags			Chain
 //	@Accept			json
 //	@Produce		json
@@ -619,7 +900,7 @@ func (a *API) chainTxCostHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext
 	}
 	var err error
 	for k, v := range genesis.TxCostNameToTxTypeMap {
-		txCosts.Costs[k], err = a.vocapp.State.TxBaseCost(v, true)
+		txCosts.Costs[k], err = a.vocapp.State.TxCost(v, true)
 		if err != nil {
 			if errors.Is(err, state.ErrTxCostNotFound) {
 				txCosts.Costs[k] = 0
@@ -643,15 +924,20 @@ func (a *API) chainTxCostHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext
 //	@Produce				json
 //	@Tags					Chain
 //	@Param					hash	path		string	true	"Transaction hash"
+//	@Param					chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success				200		{object}	indexertypes.Transaction
 //	@Success				204		"See [errors](vocdoni-api#errors) section"
 //	@Router					/chain/transactions/reference/{hash} [get]
 func (a *API) chainTxRefByHashHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	hash, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamHash)))
 	if err != nil {
 		return err
 	}
-	ref, err := a.indexer.GetTxMetadataByHash(hash)
+	ref, err := idx.GetTxMetadataByHash(hash)
 	if err != nil {
 		if errors.Is(err, indexer.ErrTransactionNotFound) {
 			return ErrTransactionNotFound
@@ -716,15 +1002,20 @@ func (a *API) chainTxByHeightAndIndexHandler(_ *apirest.APIdata, ctx *httprouter
 //	@Accept			json
 //	@Produce		json
 //	@Param			hash	path		string	true	"Transaction hash"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200		{object}	GenericTransactionWithInfo
 //	@Success		204		"See [errors](vocdoni-api#errors) section"
 //	@Router			/chain/transactions/{hash} [get]
 func (a *API) chainTxByHashHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	hash, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamHash)))
 	if err != nil {
 		return ErrCantParseHexString.WithErr(err)
 	}
-	itx, err := a.indexer.GetTransactionByHash(hash)
+	itx, err := idx.GetTransactionByHash(hash)
 	if err != nil {
 		if errors.Is(err, indexer.ErrTransactionNotFound) {
 			return ErrTransactionNotFound
@@ -743,6 +1034,40 @@ func (a *API) chainTxByHashHandler(_ *apirest.APIdata, ctx *httprouter.HTTPConte
 	return ctx.Send(data, apirest.HTTPstatusOK)
 }
 
+// chainTxReceiptHandler
+//
+//	@Summary		Transaction receipt
+//	@Description	Get the receipt of a transaction by hash: its status, the tokens spent by its fees (gasUsed) and the
+//	@Description	events it emitted, such as process_created, vote_accepted (with the nullifier) or tokens_transferred.
+//	@Description	Only the transactions executed successfully are indexed. The events are null for the transactions
+//	@Description	indexed before the receipts were recorded.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			hash	path		string	true	"Transaction hash"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	indexertypes.TransactionReceipt
+//	@Success		204		"See [errors](vocdoni-api#errors) section"
+//	@Router			/chain/transactions/{hash}/receipt [get]
+func (a *API) chainTxReceiptHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	hash, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamHash)))
+	if err != nil {
+		return ErrCantParseHexString.WithErr(err)
+	}
+	receipt, err := idx.GetTransactionReceipt(hash)
+	if err != nil {
+		if errors.Is(err, indexer.ErrTransactionNotFound) {
+			return ErrTransactionNotFound
+		}
+		return ErrVochainGetTxFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, receipt)
+}
+
 // chainTxListHandler
 //
 //	@Summary		List transactions
@@ -752,14 +1077,20 @@ func (a *API) chainTxByHashHandler(_ *apirest.APIdata, ctx *httprouter.HTTPConte
 //	@Produce		json
 //	@Param			page	query		number				false	"Page"
 //	@Param			limit	query		number				false	"Items per page"
+//	@Param			cursor	query		string				false	"Cursor returned as nextCursor by the previous page (page is ignored)"
 //	@Param			hash	query		string				false	"Tx hash"
 //	@Param			height	query		number				false	"Block height"
 //	@Param			type	query		string				false	"Tx type"
 //	@Param			subtype	query		string				false	"Tx subtype"
 //	@Param			signer	query		string				false	"Tx signer"
+//	@Param			chainId	query		string				false	"Chain to query (default the chain of the node)"
 //	@Success		200		{object}	TransactionsList	"List of transactions (metadata only)"
 //	@Router			/chain/transactions [get]
 func (a *API) chainTxListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := parseTransactionParams(
 		ctx.QueryParam(ParamPage),
 		ctx.QueryParam(ParamLimit),
@@ -772,13 +1103,18 @@ func (a *API) chainTxListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext
 	if err != nil {
 		return err
 	}
+	params.Cursor = ctx.QueryParam(ParamCursor)
 
-	list, err := a.transactionList(params)
+	etag, notModified := a.indexerETag(ctx, idx, indexer.DataTransactions)
+	if notModified {
+		return sendNotModified(ctx, etag)
+	}
+	list, err := a.transactionList(idx, params)
 	if err != nil {
 		return err
 	}
 
-	return marshalAndSend(ctx, list)
+	return marshalAndSendWithETag(ctx, list, etag)
 }
 
 // chainTxListByPageHandler
@@ -807,7 +1143,11 @@ func (a *API) chainTxListByPageHandler(_ *apirest.APIdata, ctx *httprouter.HTTPC
 		return err
 	}
 
-	list, err := a.transactionList(params)
+	etag, notModified := a.indexerETag(ctx, a.indexer, indexer.DataTransactions)
+	if notModified {
+		return sendNotModified(ctx, etag)
+	}
+	list, err := a.transactionList(a.indexer, params)
 	if err != nil {
 		// keep the odd legacy behaviour of sending a 204 rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -816,7 +1156,7 @@ func (a *API) chainTxListByPageHandler(_ *apirest.APIdata, ctx *httprouter.HTTPC
 		return err
 	}
 
-	return marshalAndSend(ctx, list)
+	return marshalAndSendWithETag(ctx, list, etag)
 }
 
 // chainTxListByHeightAndPageHandler
@@ -846,25 +1186,30 @@ func (a *API) chainTxListByHeightAndPageHandler(_ *apirest.APIdata, ctx *httprou
 		return err
 	}
 
-	list, err := a.transactionList(params)
+	etag, notModified := a.indexerETag(ctx, a.indexer, indexer.DataTransactions)
+	if notModified {
+		return sendNotModified(ctx, etag)
+	}
+	list, err := a.transactionList(a.indexer, params)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
-			return marshalAndSend(ctx, emptyTransactionsList())
+			return marshalAndSendWithETag(ctx, emptyTransactionsList(), etag)
 		}
 		return err
 	}
 
-	return marshalAndSend(ctx, list)
+	return marshalAndSendWithETag(ctx, list, etag)
 }
 
 // transactionList produces a filtered, paginated TransactionList.
 //
 // Errors returned are always of type APIerror.
-func (a *API) transactionList(params *TransactionParams) (*TransactionsList, error) {
-	txs, total, err := a.indexer.SearchTransactions(
+func (a *API) transactionList(idx *indexer.Indexer, params *TransactionParams) (*TransactionsList, error) {
+	txs, nextCursor, total, err := idx.SearchTransactionsWithCursor(
 		params.Limit,
-		params.Page*params.Limit,
+		cursorOffset(params.PaginationParams),
+		params.Cursor,
 		params.Height,
 		params.Hash,
 		params.Type,
@@ -872,13 +1217,17 @@ func (a *API) transactionList(params *TransactionParams) (*TransactionsList, err
 		params.Signer,
 	)
 	if err != nil {
+		if errors.Is(err, indexer.ErrInvalidCursor) {
+			return nil, ErrCantParseCursor.WithErr(err)
+		}
 		return nil, ErrIndexerQueryFailed.WithErr(err)
 	}
 
-	pagination, err := calculatePagination(params.Page, params.Limit, total)
+	pagination, err := calculateCursorPagination(params.PaginationParams, total, nextCursor)
 	if err != nil {
 		return nil, err
 	}
+	pagination.Estimated = idx.CountsEstimated()
 
 	list := &TransactionsList{
 		Transactions: txs,
@@ -922,29 +1271,170 @@ func (a *API) chainValidatorsHandler(_ *apirest.APIdata, ctx *httprouter.HTTPCon
 	return ctx.Send(data, apirest.HTTPstatusOK)
 }
 
+// chainValidatorsAllHandler
+//
+//	@Summary		List all validators
+//	@Description	Returns the list of all the validators seen by the indexer, ordered by descending power.
+//	@Description	Validators removed from the validator set are included with power 0.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			page	query		number	false	"Page"
+//	@Param			limit	query		number	false	"Items per page"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	IndexedValidatorList
+//	@Router			/chain/validators/all [get]
+func (a *API) chainValidatorsAllHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	params, err := parsePaginationParams(
+		ctx.QueryParam(ParamPage),
+		ctx.QueryParam(ParamLimit),
+	)
+	if err != nil {
+		return err
+	}
+
+	validators, total, err := idx.ValidatorList(params.Limit, params.Page*params.Limit)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+
+	pagination, err := calculatePagination(params.Page, params.Limit, total)
+	if err != nil {
+		return err
+	}
+
+	return marshalAndSend(ctx, &IndexedValidatorList{
+		Validators: validators,
+		Pagination: pagination,
+	})
+}
+
+// chainValidatorsHistoryHandler
+//
+//	@Summary		Validator set history
+//	@Description	Returns the changes of the validator set (validators joining, leaving or changing their power),
+//	@Description	newest first. A power of 0 means the validator was removed.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			page				query		number	false	"Page"
+//	@Param			limit				query		number	false	"Items per page"
+//	@Param			validatorAddress	query		string	false	"Filter by exact validatorAddress"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200					{object}	ValidatorSetChangesList
+//	@Router			/chain/validators/history [get]
+func (a *API) chainValidatorsHistoryHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	pagination, err := parsePaginationParams(
+		ctx.QueryParam(ParamPage),
+		ctx.QueryParam(ParamLimit),
+	)
+	if err != nil {
+		return err
+	}
+	params := &ValidatorSetChangesParams{
+		PaginationParams: pagination,
+		ValidatorAddress: util.TrimHex(ctx.QueryParam(ParamValidatorAddress)),
+	}
+
+	changes, total, err := idx.ValidatorSetChanges(
+		params.Limit,
+		params.Page*params.Limit,
+		params.ValidatorAddress,
+	)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+
+	list := &ValidatorSetChangesList{
+		Changes: changes,
+	}
+	list.Pagination, err = calculatePagination(params.Page, params.Limit, total)
+	if err != nil {
+		return err
+	}
+	return marshalAndSend(ctx, list)
+}
+
+// chainValidatorUptimeHandler
+//
+//	@Summary		Validator uptime
+//	@Description	Returns the number of blocks signed and proposed by the validator in the last `window` blocks
+//	@Description	(or since it joined, if later), and the ratio of signed blocks as uptime.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			validatorAddress	path		string	true	"Validator address"
+//	@Param			window				query		number	false	"Number of blocks (default 1000, max 100000)"
+//	@Param			chainId				query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200					{object}	indexertypes.ValidatorUptime
+//	@Router			/chain/validators/{validatorAddress}/uptime [get]
+func (a *API) chainValidatorUptimeHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	address, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamValidatorAddress)))
+	if err != nil {
+		return ErrAddressMalformed.WithErr(err)
+	}
+	window, err := parseNumber(ctx.QueryParam(ParamWindow))
+	if err != nil {
+		return err
+	}
+	if window == 0 {
+		window = DefaultValidatorUptimeWindow
+	}
+	if window < 0 || window > MaxValidatorUptimeWindow {
+		return ErrWindowOutOfRange.Withf("window must be between 1 and %d", MaxValidatorUptimeWindow)
+	}
+
+	uptime, err := idx.ValidatorUptime(address, uint64(window))
+	if err != nil {
+		if errors.Is(err, indexer.ErrValidatorNotFound) {
+			return ErrValidatorNotFound
+		}
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, uptime)
+}
+
 // chainBlockByHeightHandler
 //
 //	@Summary		Get block (by height)
-//	@Description	Returns the full block information at the given height
+//	@Description	Returns the full block information at the given height, including the name,
+//	@Description	account and voting power at that height of the validator that proposed it.
 //	@Tags			Chain
 //	@Accept			json
 //	@Produce		json
 //	@Param			height	path		int	true	"Block height"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200		{object}	api.Block
 //	@Router			/chain/blocks/{height} [get]
 func (a *API) chainBlockByHeightHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	height, err := strconv.ParseUint(ctx.URLParam(ParamHeight), 10, 64)
 	if err != nil {
 		return err
 	}
-	idxblock, err := a.indexer.BlockByHeight(int64(height))
+	idxblock, err := idx.BlockByHeight(int64(height))
 	if err != nil {
 		if errors.Is(err, indexer.ErrBlockNotFound) {
 			return ErrBlockNotFound
 		}
 		return ErrBlockNotFound.WithErr(err)
 	}
-	txcount, err := a.indexer.CountTransactionsByHeight(int64(height))
+	txcount, err := idx.CountTransactionsByHeight(int64(height))
 	if err != nil {
 		return ErrIndexerQueryFailed.WithErr(err)
 	}
@@ -958,8 +1448,11 @@ func (a *API) chainBlockByHeightHandler(_ *apirest.APIdata, ctx *httprouter.HTTP
 				Hash: []byte(idxblock.LastBlockHash),
 			},
 		},
-		Hash:    idxblock.Hash,
-		TxCount: txcount,
+		Hash:            idxblock.Hash,
+		TxCount:         txcount,
+		ProposerName:    idxblock.ProposerName,
+		ProposerAccount: idxblock.ProposerAccount,
+		ProposerPower:   idxblock.ProposerPower,
 	}
 	data, err := json.Marshal(block)
 	if err != nil {
@@ -968,29 +1461,269 @@ func (a *API) chainBlockByHeightHandler(_ *apirest.APIdata, ctx *httprouter.HTTP
 	return ctx.Send(convertKeysToCamel(data), apirest.HTTPstatusOK)
 }
 
+// chainBlockStatsHandler
+//
+//	@Summary		Get block stats (by height)
+//	@Description	Returns the statistics of the block at the given height: the number of transactions
+//	@Description	(in total and by type), the fees burned and the number of votes.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			height	path		int	true	"Block height"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	indexertypes.BlockStats
+//	@Router			/chain/blocks/{height}/stats [get]
+func (a *API) chainBlockStatsHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	height, err := strconv.ParseUint(ctx.URLParam(ParamHeight), 10, 64)
+	if err != nil {
+		return err
+	}
+	stats, err := idx.BlockStats(int64(height))
+	if err != nil {
+		if errors.Is(err, indexer.ErrBlockNotFound) {
+			return ErrBlockNotFound
+		}
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, stats)
+}
+
+// chainBlockStatsAggregateHandler
+//
+//	@Summary		Aggregated block stats
+//	@Description	Returns the block statistics aggregated by time intervals between `from` and `to`.
+//	@Description	By default the last 24 hours are aggregated in a single interval.
+//	@Description	Intervals without blocks are omitted.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			from		query		string	false	"Start of the time range (RFC3339 or YYYY-MM-DD)"
+//	@Param			to			query		string	false	"End of the time range (RFC3339 or YYYY-MM-DD, default now)"
+//	@Param			interval	query		string	false	"Aggregation interval: hour, day or week (default the whole range)"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200			{object}	api.BlockStatsList
+//	@Router			/chain/blocks/stats [get]
+func (a *API) chainBlockStatsAggregateHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	from, err := parseDate(ctx.QueryParam(ParamFrom))
+	if err != nil {
+		return err
+	}
+	to, err := parseDate(ctx.QueryParam(ParamTo))
+	if err != nil {
+		return err
+	}
+	if to == nil {
+		now := time.Now()
+		to = &now
+	}
+	if from == nil {
+		start := to.Add(-DefaultBlockStatsRange)
+		from = &start
+	}
+	if !from.Before(*to) {
+		return ErrTimeRangeInvalid.Withf("from (%s) must be before to (%s)", from, to)
+	}
+	interval := to.Sub(*from)
+	if param := ctx.QueryParam(ParamInterval); param != "" {
+		var ok bool
+		if interval, ok = blockStatsIntervals[param]; !ok {
+			return ErrParamIntervalInvalid.Withf("must be hour, day or week, got %q", param)
+		}
+	}
+	if to.Sub(*from)/interval >= MaxBlockStatsBuckets {
+		return ErrTimeRangeInvalid.Withf("the range cannot have more than %d intervals", MaxBlockStatsBuckets)
+	}
+
+	stats, err := idx.BlockStatsAggregate(*from, *to, interval)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, &BlockStatsList{Stats: stats})
+}
+
+// chainVoteStatsHandler
+//
+//	@Summary		Votes per hour
+//	@Description	Returns the number of votes cast in each hour (UTC) between `from` and `to`.
+//	@Description	By default the last 24 hours are returned. Hours without votes are omitted.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			from	query		string	false	"Start of the time range (RFC3339 or YYYY-MM-DD)"
+//	@Param			to		query		string	false	"End of the time range (RFC3339 or YYYY-MM-DD, default now)"
+//	@Param			chainId		query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	api.VoteStatsList
+//	@Router			/chain/stats/votes [get]
+func (a *API) chainVoteStatsHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	from, to, err := parseStatsRange(ctx, DefaultHourlyStatsRange, time.Hour)
+	if err != nil {
+		return err
+	}
+	stats, err := idx.VoteStatsHourly(from, to)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, &VoteStatsList{Stats: stats})
+}
+
+// chainTxStatsHandler
+//
+//	@Summary		Transactions per day
+//	@Description	Returns the number of transactions included in each day (UTC) between `from` and `to`,
+//	@Description	in total and by type. By default the last 30 days are returned.
+//	@Description	Days without transactions are omitted.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			from	query		string	false	"Start of the time range (RFC3339 or YYYY-MM-DD)"
+//	@Param			to		query		string	false	"End of the time range (RFC3339 or YYYY-MM-DD, default now)"
+//	@Param			chainId		query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	api.TxStatsList
+//	@Router			/chain/stats/transactions [get]
+func (a *API) chainTxStatsHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	from, to, err := parseStatsRange(ctx, DefaultDailyStatsRange, 24*time.Hour)
+	if err != nil {
+		return err
+	}
+	stats, err := idx.TxStatsDaily(from, to)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, &TxStatsList{Stats: stats})
+}
+
+// chainAccountStatsHandler
+//
+//	@Summary		New accounts per day
+//	@Description	Returns the number of accounts created in each day (UTC) between `from` and `to`.
+//	@Description	By default the last 30 days are returned. Days without new accounts are omitted.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			from	query		string	false	"Start of the time range (RFC3339 or YYYY-MM-DD)"
+//	@Param			to		query		string	false	"End of the time range (RFC3339 or YYYY-MM-DD, default now)"
+//	@Param			chainId		query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	api.AccountStatsList
+//	@Router			/chain/stats/accounts [get]
+func (a *API) chainAccountStatsHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	from, to, err := parseStatsRange(ctx, DefaultDailyStatsRange, 24*time.Hour)
+	if err != nil {
+		return err
+	}
+	stats, err := idx.AccountStatsDaily(from, to)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, &AccountStatsList{Stats: stats})
+}
+
+// chainFeeStatsHandler
+//
+//	@Summary		Fees per day
+//	@Description	Returns the amount of token fees burned in each day (UTC) between `from` and `to`.
+//	@Description	By default the last 30 days are returned. Days without fees are omitted.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Param			from	query		string	false	"Start of the time range (RFC3339 or YYYY-MM-DD)"
+//	@Param			to		query		string	false	"End of the time range (RFC3339 or YYYY-MM-DD, default now)"
+//	@Param			chainId		query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	api.FeeStatsList
+//	@Router			/chain/stats/fees [get]
+func (a *API) chainFeeStatsHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	from, to, err := parseStatsRange(ctx, DefaultDailyStatsRange, 24*time.Hour)
+	if err != nil {
+		return err
+	}
+	stats, err := idx.FeeStatsDaily(from, to)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, &FeeStatsList{Stats: stats})
+}
+
+// parseStatsRange returns the `from` and `to` params of the chain stats endpoints,
+// which default to the defaultRange until now. The range cannot span more than
+// MaxBlockStatsBuckets intervals of the rollup.
+func parseStatsRange(ctx *httprouter.HTTPContext, defaultRange, interval time.Duration) (time.Time, time.Time, error) {
+	from, err := parseDate(ctx.QueryParam(ParamFrom))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseDate(ctx.QueryParam(ParamTo))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if to == nil {
+		now := time.Now()
+		to = &now
+	}
+	if from == nil {
+		start := to.Add(-defaultRange)
+		from = &start
+	}
+	if !from.Before(*to) {
+		return time.Time{}, time.Time{}, ErrTimeRangeInvalid.Withf("from (%s) must be before to (%s)", from, to)
+	}
+	if to.Sub(*from)/interval >= MaxBlockStatsBuckets {
+		return time.Time{}, time.Time{}, ErrTimeRangeInvalid.Withf("the range cannot have more than %d intervals", MaxBlockStatsBuckets)
+	}
+	return *from, *to, nil
+}
+
 // chainBlockByHashHandler
 //
 //	@Summary		Get block (by hash)
-//	@Description	Returns the block from the given hash
+//	@Description	Returns the block from the given hash, including the name, account and voting
+//	@Description	power at that height of the validator that proposed it.
 //	@Tags			Chain
 //	@Accept			json
 //	@Produce		json
 //	@Param			hash	path		string	true	"Block hash"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200		{object}	api.Block
 //	@Router			/chain/blocks/hash/{hash} [get]
 func (a *API) chainBlockByHashHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	hash, err := hex.DecodeString(util.TrimHex(ctx.URLParam("hash")))
 	if err != nil {
 		return err
 	}
-	idxblock, err := a.indexer.BlockByHash(hash)
+	idxblock, err := idx.BlockByHash(hash)
 	if err != nil {
 		if errors.Is(err, indexer.ErrBlockNotFound) {
 			return ErrBlockNotFound
 		}
 		return ErrBlockNotFound.WithErr(err)
 	}
-	txcount, err := a.indexer.CountTransactionsByHeight(idxblock.Height)
+	txcount, err := idx.CountTransactionsByHeight(idxblock.Height)
 	if err != nil {
 		return ErrIndexerQueryFailed.WithErr(err)
 	}
@@ -1004,8 +1737,11 @@ func (a *API) chainBlockByHashHandler(_ *apirest.APIdata, ctx *httprouter.HTTPCo
 				Hash: []byte(idxblock.LastBlockHash),
 			},
 		},
-		Hash:    idxblock.Hash,
-		TxCount: txcount,
+		Hash:            idxblock.Hash,
+		TxCount:         txcount,
+		ProposerName:    idxblock.ProposerName,
+		ProposerAccount: idxblock.ProposerAccount,
+		ProposerPower:   idxblock.ProposerPower,
 	}
 	data, err := json.Marshal(block)
 	if err != nil {
@@ -1017,18 +1753,23 @@ func (a *API) chainBlockByHashHandler(_ *apirest.APIdata, ctx *httprouter.HTTPCo
 // chainBlockListHandler
 //
 //	@Summary		List all blocks
-//	@Description	Returns the list of blocks, ordered by descending height.
+//	@Description	Returns the list of blocks, ordered by descending height, with the name, account and
+//	@Description	voting power at each height of the validators that proposed them.
 //	@Tags			Chain
 //	@Accept			json
 //	@Produce		json
 //	@Param			page			query		number	false	"Page"
 //	@Param			limit			query		number	false	"Items per page"
-//	@Param			chainId			query		string	false	"Filter by exact chainId"
+//	@Param			chainId			query		string	false	"Chain to query (default the chain of the node)"
 //	@Param			hash			query		string	false	"Filter by partial hash"
 //	@Param			proposerAddress	query		string	false	"Filter by exact proposerAddress"
 //	@Success		200				{object}	BlockList
 //	@Router			/chain/blocks [get]
 func (a *API) chainBlockListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := parseBlockParams(
 		ctx.QueryParam(ParamPage),
 		ctx.QueryParam(ParamLimit),
@@ -1040,15 +1781,19 @@ func (a *API) chainBlockListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPCont
 		return err
 	}
 
-	return a.sendBlockList(ctx, params)
+	return a.sendBlockList(ctx, idx, params)
 }
 
 // sendBlockList produces a filtered, paginated BlockList,
 // and sends it marshalled over ctx.Send
 //
 // Errors returned are always of type APIerror.
-func (a *API) sendBlockList(ctx *httprouter.HTTPContext, params *BlockParams) error {
-	blocks, total, err := a.indexer.BlockList(
+func (a *API) sendBlockList(ctx *httprouter.HTTPContext, idx *indexer.Indexer, params *BlockParams) error {
+	etag, notModified := a.indexerETag(ctx, idx, indexer.DataBlocks)
+	if notModified {
+		return sendNotModified(ctx, etag)
+	}
+	blocks, total, err := idx.BlockList(
 		params.Limit,
 		params.Page*params.Limit,
 		params.ChainID,
@@ -1068,26 +1813,51 @@ func (a *API) sendBlockList(ctx *httprouter.HTTPContext, params *BlockParams) er
 		Blocks:     blocks,
 		Pagination: pagination,
 	}
-	return marshalAndSend(ctx, list)
+	return marshalAndSendWithETag(ctx, list, etag)
 }
 
 // chainTransactionCountHandler
 //
 //	@Summary		Transactions count
-//	@Description	Returns the number of transactions
-//	@Deprecated
-//	@Description	(deprecated, in favor of /chain/transactions which reports totalItems)
+//	@Description	Returns the exact number of transactions matching the filters, which the list of transactions may only estimate
 //	@Tags			Chain
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	CountResult
+//	@Param			hash	query		string	false	"Tx hash"
+//	@Param			height	query		number	false	"Block height"
+//	@Param			type	query		string	false	"Tx type"
+//	@Param			subtype	query		string	false	"Tx subtype"
+//	@Param			signer	query		string	false	"Tx signer"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	CountResult
 //	@Router			/chain/transactions/count [get]
 func (a *API) chainTxCountHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
-	count, err := a.indexer.CountTotalTransactions()
+	idx, err := a.chainIndexer(ctx)
 	if err != nil {
 		return err
 	}
-	return marshalAndSend(ctx, &CountResult{Count: count})
+	params, err := parseTransactionParams(
+		"",
+		"",
+		ctx.QueryParam(ParamHash),
+		ctx.QueryParam(ParamHeight),
+		ctx.QueryParam(ParamType),
+		ctx.QueryParam(ParamSubtype),
+		ctx.QueryParam(ParamSigner),
+	)
+	if err != nil {
+		return err
+	}
+
+	etag, notModified := a.indexerETag(ctx, idx, indexer.DataTransactions)
+	if notModified {
+		return sendNotModified(ctx, etag)
+	}
+	count, err := idx.CountTransactions(params.Height, params.Hash, params.Type, params.Subtype, params.Signer)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSendWithETag(ctx, &CountResult{Count: count}, etag)
 }
 
 // chainFeesListHandler
@@ -1102,9 +1872,14 @@ func (a *API) chainTxCountHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContex
 //	@Param			reference	query		string	false	"Reference filter"
 //	@Param			type		query		string	false	"Type filter"
 //	@Param			accountId	query		string	false	"Specific accountId"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200			{object}	FeesList
 //	@Router			/chain/fees [get]
 func (a *API) chainFeesListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := parseFeesParams(
 		ctx.QueryParam(ParamPage),
 		ctx.QueryParam(ParamLimit),
@@ -1116,7 +1891,7 @@ func (a *API) chainFeesListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPConte
 		return err
 	}
 
-	list, err := a.feesList(params)
+	list, err := a.feesList(idx, params)
 	if err != nil {
 		return err
 	}
@@ -1124,6 +1899,55 @@ func (a *API) chainFeesListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPConte
 	return marshalAndSend(ctx, list)
 }
 
+// chainFeeMarketHandler
+//
+//	@Summary		Fee market
+//	@Description	Returns the state of the dynamic transaction costs. If enabled, the base cost of each transaction
+//	@Description	type is multiplied by a multiplier (in thousandths), which grows up to maxMultiplier while the blocks
+//	@Description	carry more than targetBlockTxs transactions, and decreases back to one while they carry less.
+//	@Description	The costs are the ones charged to the transactions of the next block.
+//	@Tags			Chain
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	FeeMarket
+//	@Router			/chain/fees/market [get]
+func (a *API) chainFeeMarketHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	fm, err := a.vocapp.State.FeeMarket(true)
+	if err != nil {
+		return ErrVochainGetFeeMarketFailed.WithErr(err)
+	}
+	multiplier, err := a.vocapp.State.FeeMultiplier(true)
+	if err != nil {
+		return ErrVochainGetFeeMarketFailed.WithErr(err)
+	}
+	market := &FeeMarket{
+		Enabled:    fm != nil,
+		Multiplier: multiplier,
+		BaseCosts:  make(map[string]uint64),
+		Costs:      make(map[string]uint64),
+	}
+	if fm != nil {
+		market.TargetBlockTxs = fm.TargetBlockTxs
+		market.MaxMultiplier = fm.MaxMultiplier
+	}
+	for name, txType := range genesis.TxCostNameToTxTypeMap {
+		baseCost, err := a.vocapp.State.TxBaseCost(txType, true)
+		if errors.Is(err, state.ErrTxCostNotFound) {
+			continue
+		}
+		if err != nil {
+			return ErrVochainGetFeeMarketFailed.WithErr(err)
+		}
+		cost, err := a.vocapp.State.TxCost(txType, true)
+		if err != nil {
+			return ErrVochainGetFeeMarketFailed.WithErr(err)
+		}
+		market.BaseCosts[name] = baseCost
+		market.Costs[name] = cost
+	}
+	return marshalAndSend(ctx, market)
+}
+
 // chainFeesListByPageHandler
 //
 //	@Summary		List all token fees
@@ -1148,7 +1972,7 @@ func (a *API) chainFeesListByPageHandler(_ *apirest.APIdata, ctx *httprouter.HTT
 		return err
 	}
 
-	list, err := a.feesList(params)
+	list, err := a.feesList(a.indexer, params)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -1189,7 +2013,7 @@ func (a *API) chainFeesListByReferenceAndPageHandler(_ *apirest.APIdata, ctx *ht
 		return ErrMissingParameter
 	}
 
-	list, err := a.feesList(params)
+	list, err := a.feesList(a.indexer, params)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -1230,7 +2054,7 @@ func (a *API) chainFeesListByTypeAndPageHandler(_ *apirest.APIdata, ctx *httprou
 		return ErrMissingParameter
 	}
 
-	list, err := a.feesList(params)
+	list, err := a.feesList(a.indexer, params)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -1245,12 +2069,12 @@ func (a *API) chainFeesListByTypeAndPageHandler(_ *apirest.APIdata, ctx *httprou
 // feesList produces a filtered, paginated FeesList.
 //
 // Errors returned are always of type APIerror.
-func (a *API) feesList(params *FeesParams) (*FeesList, error) {
-	if params.AccountID != "" && !a.indexer.AccountExists(params.AccountID) {
+func (a *API) feesList(idx *indexer.Indexer, params *FeesParams) (*FeesList, error) {
+	if params.AccountID != "" && !idx.AccountExists(params.AccountID) {
 		return nil, ErrAccountNotFound
 	}
 
-	fees, total, err := a.indexer.TokenFeesList(
+	fees, total, err := idx.TokenFeesList(
 		params.Limit,
 		params.Page*params.Limit,
 		params.Type,
@@ -1282,12 +2106,18 @@ func (a *API) feesList(params *FeesParams) (*FeesList, error) {
 //	@Produce		json
 //	@Param			page			query		number	false	"Page"
 //	@Param			limit			query		number	false	"Items per page"
+//	@Param			cursor			query		string	false	"Cursor returned as nextCursor by the previous page (page is ignored)"
 //	@Param			accountId		query		string	false	"Specific accountId that sent or received the tokens"
 //	@Param			accountIdFrom	query		string	false	"Specific accountId that sent the tokens"
 //	@Param			accountIdTo		query		string	false	"Specific accountId that received the tokens"
+//	@Param			chainId		query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200				{object}	TransfersList
 //	@Router			/chain/transfers [get]
 func (a *API) chainTransfersListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := parseTransfersParams(
 		ctx.QueryParam(ParamPage),
 		ctx.QueryParam(ParamLimit),
@@ -1298,8 +2128,9 @@ func (a *API) chainTransfersListHandler(_ *apirest.APIdata, ctx *httprouter.HTTP
 	if err != nil {
 		return err
 	}
+	params.Cursor = ctx.QueryParam(ParamCursor)
 
-	list, err := a.transfersList(params)
+	list, err := a.transfersList(idx, params)
 	if err != nil {
 		return err
 	}
@@ -1310,25 +2141,29 @@ func (a *API) chainTransfersListHandler(_ *apirest.APIdata, ctx *httprouter.HTTP
 // transfersList produces a filtered, paginated TransfersList.
 //
 // Errors returned are always of type APIerror.
-func (a *API) transfersList(params *TransfersParams) (*TransfersList, error) {
+func (a *API) transfersList(idx *indexer.Indexer, params *TransfersParams) (*TransfersList, error) {
 	for _, param := range []string{params.AccountID, params.AccountIDFrom, params.AccountIDTo} {
-		if param != "" && !a.indexer.AccountExists(param) {
+		if param != "" && !idx.AccountExists(param) {
 			return nil, ErrAccountNotFound.With(param)
 		}
 	}
 
-	transfers, total, err := a.indexer.TokenTransfersList(
+	transfers, nextCursor, total, err := idx.TokenTransfersListWithCursor(
 		params.Limit,
-		params.Page*params.Limit,
+		cursorOffset(params.PaginationParams),
+		params.Cursor,
 		params.AccountID,
 		params.AccountIDFrom,
 		params.AccountIDTo,
 	)
 	if err != nil {
+		if errors.Is(err, indexer.ErrInvalidCursor) {
+			return nil, ErrCantParseCursor.WithErr(err)
+		}
 		return nil, ErrIndexerQueryFailed.WithErr(err)
 	}
 
-	pagination, err := calculatePagination(params.Page, params.Limit, total)
+	pagination, err := calculateCursorPagination(params.PaginationParams, total, nextCursor)
 	if err != nil {
 		return nil, err
 	}
@@ -1358,6 +2193,38 @@ func (a *API) chainIndexerExportHandler(_ *apirest.APIdata, ctx *httprouter.HTTP
 	return ctx.Send(data, apirest.HTTPstatusOK)
 }
 
+// chainIndexerStatusHandler
+//
+//	@Summary		Indexer status
+//	@Description	Returns the health and sync status of the indexer: the last block height indexed and how far
+//	@Description	it lags behind the chain, the writes pending to be committed with the current block, the number
+//	@Description	of processes with live results and the size of its database.
+//	@Description	If `maxLag` is given and the indexer lags behind the chain by more blocks, it fails with
+//	@Description	503 Service Unavailable, so that load balancers can stop routing requests to the gateway.
+//	@Tags			Indexer
+//	@Produce		json
+//	@Param			maxLag	query		number	false	"Maximum number of blocks the indexer can lag behind the chain"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	indexertypes.IndexerStatus
+//	@Router			/chain/indexer/status [get]
+func (a *API) chainIndexerStatusHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	status := idx.Status()
+	if param := ctx.QueryParam(ParamMaxLag); param != "" {
+		maxLag, err := strconv.ParseUint(param, 10, 32)
+		if err != nil {
+			return ErrCantParseNumber.With(param)
+		}
+		if uint64(status.Lag) > maxLag {
+			return ErrIndexerLagging.Withf("%d blocks behind the chain", status.Lag)
+		}
+	}
+	return marshalAndSend(ctx, status)
+}
+
 // parseOrganizationParams returns an OrganizationParams filled with the passed params
 func parseOrganizationParams(paramPage, paramLimit, paramOrganizationID string) (*OrganizationParams, error) {
 	pagination, err := parsePaginationParams(paramPage, paramLimit)
@@ -2,9 +2,12 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"io"
 	"strings"
 	"time"
 
@@ -14,11 +17,15 @@ import (
 	"go.vocdoni.io/dvote/httprouter/apirest"
 	"go.vocdoni.io/dvote/log"
 	"go.vocdoni.io/dvote/statedb"
+	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/util"
 	"go.vocdoni.io/dvote/vochain/indexer"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
 	"go.vocdoni.io/dvote/vochain/processid"
+	"go.vocdoni.io/dvote/vochain/results/resultsverify"
 	"go.vocdoni.io/dvote/vochain/state"
 	"go.vocdoni.io/dvote/vochain/state/electionprice"
+	"go.vocdoni.io/dvote/vochain/transaction/proofs/zkproof"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/proto"
 )
@@ -26,8 +33,24 @@ import (
 const (
 	ElectionHandler     = "elections"
 	MaxOffchainFileSize = 1024 * 1024 * 1 // 1MB
+	// FileFetchTimeoutSeconds is the timeout for retrieving a file from the storage.
+	FileFetchTimeoutSeconds = 10
 )
 
+// UnlistedElectionsTokenKey is the key of the account key-value store entry
+// where an organization sets the hash of its token for the unlisted elections
+// (see UnlistedElectionsTokenHash). The election lists filtered by the
+// organization include its unlisted elections if the request carries the
+// token as its Bearer token.
+const UnlistedElectionsTokenKey = "unlistedElectionsToken"
+
+// UnlistedElectionsTokenHash returns the hash of the token for the unlisted
+// elections, which is the value of the UnlistedElectionsTokenKey entry.
+func UnlistedElectionsTokenHash(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
+
 func (a *API) enableElectionHandlers() error {
 	if err := a.Endpoint.RegisterMethod(
 		"/elections/page/{page}",
@@ -77,6 +100,30 @@ func (a *API) enableElectionHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/elections/{electionId}/votes/filter",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.electionNullifierFilterHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/elections/{electionId}/votes/stream",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.electionVotesStreamHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/elections/{electionId}/votes/verify-proof",
+		"POST",
+		apirest.MethodAccessTypePublic,
+		a.electionVerifyZkProofHandler,
+	); err != nil {
+		return err
+	}
 	if err := a.Endpoint.RegisterMethod(
 		"/elections/{electionId}/scrutiny",
 		"GET",
@@ -85,6 +132,38 @@ func (a *API) enableElectionHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/elections/{electionId}/results/proof",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.electionResultsProofHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/elections/{electionId}/history",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.electionStatusHistoryHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/elections/{electionId}/census/history",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.electionCensusHistoryHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/elections/{electionId}/delegations",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.electionDelegationsHandler,
+	); err != nil {
+		return err
+	}
 	if err := a.Endpoint.RegisterMethod(
 		"/elections",
 		"POST",
@@ -109,6 +188,22 @@ func (a *API) enableElectionHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/files",
+		"POST",
+		apirest.MethodAccessTypePublic,
+		a.uploadFileHandler,
+	); err != nil {
+		return err
+	}
+	if err := a.Endpoint.RegisterMethod(
+		"/files/{cid}",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.fileHandler,
+	); err != nil {
+		return err
+	}
 
 	if err := a.Endpoint.RegisterMethod(
 		"/elections/filter/page/{page}",
@@ -135,6 +230,14 @@ func (a *API) enableElectionHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/elections/metadata/unavailable",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.electionMetadataUnavailableListHandler,
+	); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -149,9 +252,14 @@ func (a *API) enableElectionHandlers() error {
 //	@Produce		json
 //	@Param			page	path		number			true	"Page"
 //	@Param			body	body		ElectionParams	true	"Filtered by exact organizationId, partial electionId, election status, results available or not, etc"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200		{object}	ElectionsList
 //	@Router			/elections/filter/page/{page} [post]
 func (a *API) electionListByFilterAndPageHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	// get params from the request body
 	params := &ElectionParams{}
 
@@ -169,7 +277,7 @@ func (a *API) electionListByFilterAndPageHandler(msg *apirest.APIdata, ctx *http
 		return ErrMissingParameter
 	}
 
-	list, err := a.electionList(params)
+	list, err := a.electionList(idx, params, msg.AuthToken)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -191,16 +299,21 @@ func (a *API) electionListByFilterAndPageHandler(msg *apirest.APIdata, ctx *http
 //	@Produce				json
 //	@Param					page	query		number			false	"Page"
 //	@Param					body	body		ElectionParams	true	"Filtered by partial organizationId, partial electionId, election status and with results available or not"
+//	@Param					chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success				200		{object}	ElectionsList
 //	@Router					/elections/filter [post]
 func (a *API) electionListByFilterHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	// get params from the request body
 	params := &ElectionParams{}
 	if err := json.Unmarshal(msg.Data, &params); err != nil {
 		return ErrCantParseDataAsJSON.WithErr(err)
 	}
 
-	list, err := a.electionList(params)
+	list, err := a.electionList(idx, params, msg.AuthToken)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -222,9 +335,14 @@ func (a *API) electionListByFilterHandler(msg *apirest.APIdata, ctx *httprouter.
 //	@Accept			json
 //	@Produce		json
 //	@Param			page	path		number	true	"Page"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200		{object}	ElectionsList
 //	@Router			/elections/page/{page} [get]
-func (a *API) electionListByPageHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+func (a *API) electionListByPageHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := electionParams(ctx.URLParam,
 		ParamPage,
 	)
@@ -232,7 +350,7 @@ func (a *API) electionListByPageHandler(_ *apirest.APIdata, ctx *httprouter.HTTP
 		return err
 	}
 
-	list, err := a.electionList(params)
+	list, err := a.electionList(idx, params, msg.AuthToken)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
@@ -248,23 +366,34 @@ func (a *API) electionListByPageHandler(_ *apirest.APIdata, ctx *httprouter.HTTP
 //
 //	@Summary		List elections
 //	@Description	Get a list of elections summaries.
+//	@Description	The unlisted elections are only included when filtering by the full organizationId, with the token
+//	@Description	set by the organization for its unlisted elections as Bearer token.
 //	@Tags			Elections
 //	@Accept			json
 //	@Produce		json
+//	@Security		BasicAuth
 //	@Param			page			query		number	false	"Page"
 //	@Param			limit			query		number	false	"Items per page"
+//	@Param			cursor			query		string	false	"Cursor returned as nextCursor by the previous page (page is ignored)"
 //	@Param			organizationId	query		string	false	"Filter by partial organizationId"
 //	@Param			status			query		string	false	"Election status"	Enums(ready, paused, canceled, ended, results)
 //	@Param			electionId		query		string	false	"Filter by partial electionId"
 //	@Param			withResults		query		boolean	false	"Filter by (partial or final) results available or not"
 //	@Param			finalResults	query		boolean	false	"Filter by final results available or not"
 //	@Param			manuallyEnded	query		boolean	false	"Filter by whether the election was manually ended or not"
+//	@Param			minTurnout		query		number	false	"Filter by minimum turnout percentage"
+//	@Param			chainId		query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200				{object}	ElectionsList
 //	@Router			/elections [get]
-func (a *API) electionListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+func (a *API) electionListHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := electionParams(ctx.QueryParam,
 		ParamPage,
 		ParamLimit,
+		ParamCursor,
 		ParamStatus,
 		ParamOrganizationId,
 		ParamElectionId,
@@ -275,12 +404,13 @@ func (a *API) electionListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContex
 		ParamStartDateBefore,
 		ParamEndDateAfter,
 		ParamEndDateBefore,
+		ParamMinTurnout,
 	)
 	if err != nil {
 		return err
 	}
 
-	list, err := a.electionList(params)
+	list, err := a.electionList(idx, params, msg.AuthToken)
 	if err != nil {
 		return err
 	}
@@ -288,11 +418,38 @@ func (a *API) electionListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContex
 	return marshalAndSend(ctx, list)
 }
 
-// electionList produces a filtered, paginated ElectionsList.
+// unlistedElectionsOf returns the organization whose unlisted elections can be
+// listed with authToken, or nil if the token is not the one set by the
+// organization in the UnlistedElectionsTokenKey entry of its key-value store.
+// A wrong token is not an error, since the clients can send other tokens, such
+// as the ones of the private endpoints, along with all their requests.
+func (a *API) unlistedElectionsOf(idx *indexer.Indexer, organizationID, authToken string) types.EntityID {
+	if authToken == "" || len(organizationID) != common.AddressLength*2 {
+		return nil
+	}
+	addr := common.HexToAddress(organizationID)
+	entries, err := a.chainState(idx).AccountKV(addr, true)
+	if err != nil {
+		log.Warnw("cannot get account key-value store", "address", addr.Hex(), "err", err)
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.Key == UnlistedElectionsTokenKey &&
+			subtle.ConstantTimeCompare(entry.Value, UnlistedElectionsTokenHash(authToken)) == 1 {
+			return addr.Bytes()
+		}
+	}
+	return nil
+}
+
+// electionList produces a filtered, paginated ElectionsList of the elections
+// indexed by idx. The unlisted
+// elections are only included if the list is filtered by their organization,
+// and authToken is its token for the unlisted elections.
 //
 // Errors returned are always of type APIerror.
-func (a *API) electionList(params *ElectionParams) (*ElectionsList, error) {
-	if params.OrganizationID != "" && !a.indexer.AccountExists(params.OrganizationID) {
+func (a *API) electionList(idx *indexer.Indexer, params *ElectionParams, authToken string) (*ElectionsList, error) {
+	if params.OrganizationID != "" && !idx.AccountExists(params.OrganizationID) {
 		return nil, ErrOrgNotFound
 	}
 
@@ -300,10 +457,14 @@ func (a *API) electionList(params *ElectionParams) (*ElectionsList, error) {
 	if err != nil {
 		return nil, err
 	}
+	if params.MinTurnout < 0 || params.MinTurnout > 100 {
+		return nil, ErrParamMinTurnoutInvalid.Withf("(%v)", params.MinTurnout)
+	}
 
-	eids, total, err := a.indexer.ProcessList(
+	eids, nextCursor, total, err := idx.ProcessListWithCursor(
 		params.Limit,
-		params.Page*params.Limit,
+		cursorOffset(params.PaginationParams),
+		params.Cursor,
 		params.OrganizationID,
 		params.ElectionID,
 		0,
@@ -316,12 +477,17 @@ func (a *API) electionList(params *ElectionParams) (*ElectionsList, error) {
 		params.StartDateBefore,
 		params.EndDateAfter,
 		params.EndDateBefore,
+		params.MinTurnout,
+		a.unlistedElectionsOf(idx, params.OrganizationID, authToken),
 	)
 	if err != nil {
+		if errors.Is(err, indexer.ErrInvalidCursor) {
+			return nil, ErrCantParseCursor.WithErr(err)
+		}
 		return nil, ErrIndexerQueryFailed.WithErr(err)
 	}
 
-	pagination, err := calculatePagination(params.Page, params.Limit, total)
+	pagination, err := calculateCursorPagination(params.PaginationParams, total, nextCursor)
 	if err != nil {
 		return nil, err
 	}
@@ -331,7 +497,7 @@ func (a *API) electionList(params *ElectionParams) (*ElectionsList, error) {
 		Pagination: pagination,
 	}
 	for _, eid := range eids {
-		e, err := a.indexer.ProcessInfo(eid)
+		e, err := idx.ProcessInfo(eid)
 		if err != nil {
 			return nil, ErrCantFetchElection.Withf("(%x): %v", eid, err)
 		}
@@ -348,14 +514,19 @@ func (a *API) electionList(params *ElectionParams) (*ElectionsList, error) {
 //	@Accept			json
 //	@Produce		json
 //	@Param			electionId	path		string	true	"Election id"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200			{object}	Election
 //	@Router			/elections/{electionId} [get]
 func (a *API) electionHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	electionID, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamElectionId)))
 	if err != nil {
 		return ErrCantParseElectionID.Withf("(%s): %v", ctx.URLParam(ParamElectionId), err)
 	}
-	proc, err := a.indexer.ProcessInfo(electionID)
+	proc, err := idx.ProcessInfo(electionID)
 	if err != nil {
 		if errors.Is(err, indexer.ErrProcessNotFound) {
 			return ErrElectionNotFound
@@ -443,6 +614,40 @@ func (a *API) electionVotesCountHandler(_ *apirest.APIdata, ctx *httprouter.HTTP
 	return marshalAndSend(ctx, &CountResult{Count: count})
 }
 
+// electionNullifierFilterHandler
+//
+//	@Summary		Election nullifier filter
+//	@Description	Get a bloom filter of the nullifiers of the election votes, to check whether a voter has already voted
+//	@Description	without querying the vote. If the filter does not contain the nullifier, there was no vote with it as of
+//	@Description	the filter height; otherwise there might be one (about 1% false positives), to be checked with an exact query.
+//	@Description	A nullifier is added to the filter by setting the bits (h1 + i*h2) % len(bits) for i in [0, hashes), where
+//	@Description	h1 and h2 are the first and second big-endian uint64 of sha256(nullifier), and h2 has its lowest bit set.
+//	@Tags			Elections
+//	@Accept			json
+//	@Produce		json
+//	@Param			electionId	path		string	true	"Election id"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200			{object}	indexertypes.NullifierFilter
+//	@Router			/elections/{electionId}/votes/filter [get]
+func (a *API) electionNullifierFilterHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	electionID, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamElectionId)))
+	if err != nil || electionID == nil {
+		return ErrCantParseElectionID.Withf("(%s): %v", ctx.URLParam(ParamElectionId), err)
+	}
+	filter, err := idx.NullifierFilter(electionID)
+	if err != nil {
+		if errors.Is(err, indexer.ErrProcessNotFound) {
+			return ErrElectionNotFound
+		}
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, filter)
+}
+
 // electionKeysHandler
 //
 //	@Summary		List encryption keys
@@ -505,28 +710,86 @@ func (a *API) electionKeysHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContex
 //	@Produce		json
 //	@Param			electionId	path		string	true	"Election id"
 //	@Param			page		path		number	true	"Page"
+//	@Param			chainId		query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200			{object}	VotesList
 //	@Router			/elections/{electionId}/votes/page/{page} [get]
 func (a *API) electionVotesListByPageHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := parseVoteParams(
 		ctx.URLParam(ParamPage),
 		"",
 		ctx.URLParam(ParamElectionId),
+		"",
+		"",
 	)
 	if err != nil {
 		return err
 	}
 
-	list, err := a.votesList(params)
+	etag, notModified := a.indexerETag(ctx, idx, indexer.DataVotes)
+	if notModified {
+		return sendNotModified(ctx, etag)
+	}
+	list, err := a.votesList(idx, params)
 	if err != nil {
 		// keep legacy behaviour of sending an empty list rather than a 404
 		if errors.Is(err, ErrPageNotFound) {
-			return marshalAndSend(ctx, emptyVotesList())
+			return marshalAndSendWithETag(ctx, emptyVotesList(), etag)
 		}
 		return err
 	}
 
-	return marshalAndSend(ctx, list)
+	return marshalAndSendWithETag(ctx, list, etag)
+}
+
+// electionVotesStreamHandler
+//
+//	@Summary		Stream election votes
+//	@Description	Returns all the votes of an election, sorted by voteID, as newline-delimited JSON with one vote per line,
+//	@Description	in the same format as /votes/{voteId}. The votes are sent as they are read, at the pace the client receives
+//	@Description	them, so the whole list can be downloaded with a single request, without pagination.
+//	@Description	If the response ends without a last newline, it was interrupted and the last vote is incomplete.
+//	@Tags			Elections
+//	@Accept			json
+//	@Produce		x-ndjson
+//	@Param			electionId	path		string	true	"Election id"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200			{string}	ndjson	"Votes, one per line"
+//	@Router			/elections/{electionId}/votes/stream [get]
+func (a *API) electionVotesStreamHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	electionID, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamElectionId)))
+	if err != nil || electionID == nil {
+		return ErrCantParseElectionID.Withf("(%s): %v", ctx.URLParam(ParamElectionId), err)
+	}
+	process, err := getElection(electionID, a.chainState(idx))
+	if err != nil {
+		return err
+	}
+
+	// Once streaming, the status is sent, so the errors can only be logged.
+	if err := ctx.Stream(httprouter.ContentTypeNDJSON, func(sctx context.Context, w io.Writer) error {
+		enc := json.NewEncoder(w)
+		return idx.ProcessEnvelopes(sctx, electionID, func(voteData *indexertypes.EnvelopePackage) error {
+			vote := envelopeVote(voteData)
+			if vote.VotePackage == nil {
+				var err error
+				if vote.VotePackage, err = encryptedVotePackage(voteData, process.EncryptionPrivateKeys); err != nil {
+					return err
+				}
+			}
+			return enc.Encode(vote)
+		})
+	}); err != nil {
+		log.Warnw("votes stream interrupted", "electionID", hex.EncodeToString(electionID), "error", err)
+	}
+	return nil
 }
 
 // electionScrutinyHandler
@@ -585,6 +848,162 @@ func (a *API) electionScrutinyHandler(_ *apirest.APIdata, ctx *httprouter.HTTPCo
 	return ctx.Send(data, apirest.HTTPstatusOK)
 }
 
+// electionResultsProofHandler
+//
+//	@Summary				Election results proof
+//	@Description.markdown	electionResultsProofHandler
+//	@Tags					Elections
+//	@Accept					json
+//	@Produce				json
+//	@Param					electionId	path		string					true	"Election id"
+//	@Param					chainId	query		string					false	"Chain to query (default the chain of the node)"
+//	@Success				200			{object}	resultsverify.Bundle
+//	@Router					/elections/{electionId}/results/proof [get]
+func (a *API) electionResultsProofHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	electionID, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamElectionId)))
+	if err != nil || electionID == nil {
+		return ErrCantParseElectionID.Withf("(%s): %v", ctx.URLParam(ParamElectionId), err)
+	}
+	process, err := getElection(electionID, a.chainState(idx))
+	if err != nil {
+		return err
+	}
+	if process.Status != models.ProcessStatus_RESULTS {
+		return ErrElectionResultsNotYetAvailable
+	}
+	var bundle *resultsverify.Bundle
+	if bundle, err = idx.ResultsProof(electionID); err != nil {
+		if errors.Is(err, indexer.ErrResultsProofNotFound) || errors.Is(err, indexer.ErrBlockNotFound) {
+			return ErrResultsProofNotFound.WithErr(err)
+		}
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, bundle)
+}
+
+// electionStatusHistoryHandler
+//
+//	@Summary		Election status history
+//	@Description	Returns every status and duration change of an election, such as when it was paused, resumed or
+//	@Description	extended, along with the height and the hash of the transaction which made it.
+//	@Tags			Elections
+//	@Accept			json
+//	@Produce		json
+//	@Param			electionId	path		string	true	"Election id"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200			{object}	ElectionStatusHistory
+//	@Router			/elections/{electionId}/history [get]
+func (a *API) electionStatusHistoryHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	electionID, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamElectionId)))
+	if err != nil || electionID == nil {
+		return ErrCantParseElectionID.Withf("(%s): %v", ctx.URLParam(ParamElectionId), err)
+	}
+	if _, err := idx.ProcessInfo(electionID); err != nil {
+		if errors.Is(err, indexer.ErrProcessNotFound) {
+			return ErrElectionNotFound
+		}
+		return ErrCantFetchElection.WithErr(err)
+	}
+	changes, err := idx.ProcessStatusHistory(electionID)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, &ElectionStatusHistory{Changes: changes})
+}
+
+// electionCensusHistoryHandler
+//
+//	@Summary		Election census history
+//	@Description	Returns every version of the census of an election, from the one it was created with to the current one.
+//	@Description	Elections with dynamic census can update their census while ongoing, and each version is used to validate
+//	@Description	the votes from its height on. The census the election was created with has height zero.
+//	@Tags			Elections
+//	@Accept			json
+//	@Produce		json
+//	@Param			electionId	path		string	true	"Election id"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200			{object}	ElectionCensusHistory
+//	@Router			/elections/{electionId}/census/history [get]
+func (a *API) electionCensusHistoryHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	electionID, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamElectionId)))
+	if err != nil || electionID == nil {
+		return ErrCantParseElectionID.Withf("(%s): %v", ctx.URLParam(ParamElectionId), err)
+	}
+	versions, err := idx.ProcessCensusHistory(electionID)
+	if err != nil {
+		if errors.Is(err, indexer.ErrProcessNotFound) {
+			return ErrElectionNotFound
+		}
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSend(ctx, &ElectionCensusHistory{Versions: versions})
+}
+
+// electionDelegationsHandler
+//
+//	@Summary		Election vote delegations
+//	@Description	Returns the vote weight delegations of the census members of an election, ordered by delegate.
+//	@Description	Each delegation adds the census weight of the delegator, as it was when the delegation was sent,
+//	@Description	to the vote of the delegate. The delegators cannot vote, and the weight is lost if the delegate
+//	@Description	does not vote.
+//	@Tags			Elections
+//	@Accept			json
+//	@Produce		json
+//	@Param			electionId	path		string	true	"Election id"
+//	@Param			page		query		number	false	"Page"
+//	@Param			limit		query		number	false	"Items per page"
+//	@Param			delegate	query		string	false	"Only the delegations to this address"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200			{object}	ElectionDelegationsList
+//	@Router			/elections/{electionId}/delegations [get]
+func (a *API) electionDelegationsHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	electionID, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamElectionId)))
+	if err != nil || electionID == nil {
+		return ErrCantParseElectionID.Withf("(%s): %v", ctx.URLParam(ParamElectionId), err)
+	}
+	params, err := parsePaginationParams(ctx.QueryParam(ParamPage), ctx.QueryParam(ParamLimit))
+	if err != nil {
+		return err
+	}
+	delegate := util.TrimHex(ctx.QueryParam(ParamDelegate))
+	if delegate != "" {
+		if b, err := hex.DecodeString(delegate); err != nil || len(b) != common.AddressLength {
+			return ErrAddressMalformed.Withf("(%s)", ctx.QueryParam(ParamDelegate))
+		}
+	}
+	if !idx.ProcessExists(hex.EncodeToString(electionID)) {
+		return ErrElectionNotFound
+	}
+	delegations, total, err := idx.VoteDelegations(electionID, delegate, params.Limit, params.Page*params.Limit)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	pagination, err := calculatePagination(params.Page, params.Limit, total)
+	if err != nil {
+		return err
+	}
+	return marshalAndSend(ctx, &ElectionDelegationsList{
+		Delegations: delegations,
+		Pagination:  pagination,
+	})
+}
+
 // electionCreateHandler
 //
 //	@Summary				Create election
@@ -717,6 +1136,69 @@ func (*API) computeCidHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext)
 	return ctx.Send(data, apirest.HTTPstatusOK)
 }
 
+// uploadFileHandler
+//
+//	@Summary		Upload a file to storage
+//	@Description	Publishes a file (such as custom metadata or an attachment) on the storage (IPFS), so it can be
+//	@Description	retrieved later by its CID. The file contents must be base64 encoded in the `payload` field, and
+//	@Description	cannot be bigger than 1MB. Clients should verify that the returned CID matches the contents.
+//	@Tags			Elections
+//	@Accept			json
+//	@Produce		json
+//	@Param			transaction	body		object{payload=string}	true	"File bytes base64 encoded"
+//	@Success		200			{object}	File
+//	@Router			/files [post]
+func (a *API) uploadFileHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	if a.storage == nil {
+		return ErrStorageNotAvailable
+	}
+	req := &File{}
+	if err := json.Unmarshal(msg.Data, req); err != nil {
+		return ErrCantParseDataAsJSON.WithErr(err)
+	}
+	if len(req.Payload) > MaxOffchainFileSize {
+		return ErrFileSizeTooBig.Withf("%d vs %d bytes", len(req.Payload), MaxOffchainFileSize)
+	}
+	stgCtx, cancel := context.WithTimeout(context.Background(), FileFetchTimeoutSeconds*time.Second)
+	defer cancel()
+	cid, err := a.storage.Publish(stgCtx, req.Payload)
+	if err != nil {
+		return ErrCantPublishFile.WithErr(err)
+	}
+	return marshalAndSend(ctx, &File{
+		CID: a.storage.URIprefix() + strings.TrimPrefix(cid, a.storage.URIprefix()),
+	})
+}
+
+// fileHandler
+//
+//	@Summary		Get a file from storage
+//	@Description	Retrieves a file (such as election or account metadata) from the storage (IPFS) given its CID.
+//	@Description	The file contents are returned base64 encoded in the `payload` field. Clients should verify that the
+//	@Description	CID matches the contents, since the gateway is not trusted.
+//	@Tags			Elections
+//	@Accept			json
+//	@Produce		json
+//	@Param			cid	path		string	true	"IPFS CID of the file"
+//	@Success		200	{object}	File
+//	@Router			/files/{cid} [get]
+func (a *API) fileHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	if a.storage == nil {
+		return ErrStorageNotAvailable
+	}
+	cid := ctx.URLParam(ParamCid)
+	stgCtx, cancel := context.WithTimeout(context.Background(), FileFetchTimeoutSeconds*time.Second)
+	defer cancel()
+	data, err := a.storage.Retrieve(stgCtx, "ipfs://"+cid, MaxOffchainFileSize)
+	if err != nil {
+		return ErrFileNotFound.WithErr(err)
+	}
+	return marshalAndSend(ctx, &File{
+		Payload: data,
+		CID:     "ipfs://" + cid,
+	})
+}
+
 // electionPriceHandler
 //
 //	@Summary		Compute election price
@@ -743,6 +1225,56 @@ func (a *API) electionPriceHandler(msg *apirest.APIdata, ctx *httprouter.HTTPCon
 	return ctx.Send(data, apirest.HTTPstatusOK)
 }
 
+// electionVerifyZkProofHandler
+//
+//	@Summary		Verify a zk-SNARK vote proof
+//	@Description	Verifies a zk-SNARK proof and its public signals against an anonymous election and the circuit
+//	@Description	verification key, without casting the vote. It allows to check a proof before submitting the vote.
+//	@Description	The nullifier is not checked, so a valid proof could still be rejected if the voter already voted.
+//	@Tags			Elections
+//	@Accept			json
+//	@Produce		json
+//	@Param			electionId	path		string				true	"Election id"
+//	@Param			proof		body		ZkProofVerification	true	"Proof, public signals and vote package"
+//	@Success		200			{object}	ZkProofVerificationResult
+//	@Router			/elections/{electionId}/votes/verify-proof [post]
+func (a *API) electionVerifyZkProofHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	electionID, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamElectionId)))
+	if err != nil || electionID == nil {
+		return ErrCantParseElectionID.Withf("(%s): %v", ctx.URLParam(ParamElectionId), err)
+	}
+	req := &ZkProofVerification{}
+	if err := json.Unmarshal(msg.Data, req); err != nil {
+		return ErrCantParseDataAsJSON.WithErr(err)
+	}
+	if req.Proof == nil {
+		return ErrParamKeyOrProofMissing
+	}
+	process, err := getElection(electionID, a.vocapp.State)
+	if err != nil {
+		return err
+	}
+	if !process.GetEnvelopeType().GetAnonymous() {
+		return ErrElectionNotAnonymous
+	}
+
+	result := &ZkProofVerificationResult{}
+	weight, err := zkproof.VerifyProof(process, req.Proof, req.VotePackage)
+	if err != nil {
+		result.Error = err.Error()
+		return marshalAndSend(ctx, result)
+	}
+	nullifier, err := req.Proof.Nullifier()
+	if err != nil {
+		result.Error = err.Error()
+		return marshalAndSend(ctx, result)
+	}
+	result.Valid = true
+	result.Weight = (*types.BigInt)(weight)
+	result.Nullifier = nullifier
+	return marshalAndSend(ctx, result)
+}
+
 // getElection retrieves an election from the vochain state.
 // If not found or nil, returns an apirest.APIerror
 func getElection(electionID []byte, vs *state.State) (*models.Process, error) {
@@ -759,6 +1291,49 @@ func getElection(electionID []byte, vs *state.State) (*models.Process, error) {
 	return process, nil
 }
 
+// electionMetadataUnavailableListHandler
+//
+//	@Summary		List elections with unavailable metadata
+//	@Description	Get the list of elections whose metadata or census could not be retrieved from the storage (IPFS)
+//	@Description	on the last periodic check, so they can be pinned again. The `metadataLastSeen` and `censusLastSeen`
+//	@Description	fields hold the last time the files were retrieved, if ever.
+//	@Tags			Elections
+//	@Accept			json
+//	@Produce		json
+//	@Param			page	query		number	false	"Page"
+//	@Param			limit	query		number	false	"Items per page"
+//	@Param			chainId	query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200		{object}	MetadataAvailabilityList
+//	@Router			/elections/metadata/unavailable [get]
+func (a *API) electionMetadataUnavailableListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	params, err := parsePaginationParams(
+		ctx.QueryParam(ParamPage),
+		ctx.QueryParam(ParamLimit),
+	)
+	if err != nil {
+		return err
+	}
+
+	list, total, err := idx.UnavailableMetadataList(params.Limit, params.Page*params.Limit)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+
+	pagination, err := calculatePagination(params.Page, params.Limit, total)
+	if err != nil {
+		return err
+	}
+
+	return marshalAndSend(ctx, &MetadataAvailabilityList{
+		Elections:  list,
+		Pagination: pagination,
+	})
+}
+
 // buildElectionIDHandler
 //
 //	@Summary		Build an election ID
@@ -810,6 +1385,7 @@ func electionParams(f func(key string) string, keys ...string) (*ElectionParams,
 	if err != nil {
 		return nil, err
 	}
+	pagination.Cursor = strings[ParamCursor]
 
 	bools := make(map[string]*bool)
 	for _, v := range []string{ParamWithResults, ParamFinalResults, ParamManuallyEnded} {
@@ -827,6 +1403,11 @@ func electionParams(f func(key string) string, keys ...string) (*ElectionParams,
 		}
 	}
 
+	minTurnout, err := parseFloat(strings[ParamMinTurnout])
+	if err != nil {
+		return nil, err
+	}
+
 	return &ElectionParams{
 		PaginationParams: pagination,
 		OrganizationID:   util.TrimHex(strings[ParamOrganizationId]),
@@ -839,5 +1420,6 @@ func electionParams(f func(key string) string, keys ...string) (*ElectionParams,
 		StartDateBefore:  dates[ParamStartDateBefore],
 		EndDateAfter:     dates[ParamEndDateAfter],
 		EndDateBefore:    dates[ParamEndDateBefore],
+		MinTurnout:       minTurnout,
 	}, nil
 }
@@ -84,6 +84,22 @@ var (
 	ErrCantParseHexString               = apirest.APIerror{Code: 4056, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("cannot parse string into hex bytes")}
 	ErrPageNotFound                     = apirest.APIerror{Code: 4057, HTTPstatus: apirest.HTTPstatusNotFound, Err: fmt.Errorf("page not found")}
 	ErrCantParseDate                    = apirest.APIerror{Code: 4058, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("cannot parse date")}
+	ErrElectionNotAnonymous             = apirest.APIerror{Code: 4059, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("election is not anonymous")}
+	ErrCantParseCursor                  = apirest.APIerror{Code: 4060, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("cannot parse pagination cursor")}
+	ErrFileNotFound                     = apirest.APIerror{Code: 4061, HTTPstatus: apirest.HTTPstatusNotFound, Err: fmt.Errorf("file not found")}
+	ErrValidatorNotFound                = apirest.APIerror{Code: 4062, HTTPstatus: apirest.HTTPstatusNotFound, Err: fmt.Errorf("validator not found")}
+	ErrWindowOutOfRange                 = apirest.APIerror{Code: 4063, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("window out of range")}
+	ErrResultsProofNotFound             = apirest.APIerror{Code: 4064, HTTPstatus: apirest.HTTPstatusNotFound, Err: fmt.Errorf("results proof not found")}
+	ErrTimeRangeInvalid                 = apirest.APIerror{Code: 4065, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("invalid time range")}
+	ErrParamIntervalInvalid             = apirest.APIerror{Code: 4066, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("parameter (interval) invalid")}
+	ErrParamKeyTypeInvalid              = apirest.APIerror{Code: 4067, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("parameter (keyType) invalid")}
+	ErrCensusKeyInvalid                 = apirest.APIerror{Code: 4068, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("invalid census key")}
+	ErrParamMinTurnoutInvalid           = apirest.APIerror{Code: 4069, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("parameter (minTurnout) invalid, must be between 0 and 100")}
+	ErrIndexerQueryNotAllowed           = apirest.APIerror{Code: 4070, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("indexer query not allowed, only SELECT queries on the allowed tables are")}
+	ErrIndexerQueryInvalid              = apirest.APIerror{Code: 4071, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("indexer query failed")}
+	ErrBatchTooLarge                    = apirest.APIerror{Code: 4072, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("too many requests in the batch")}
+	ErrBatchRequestInvalid              = apirest.APIerror{Code: 4073, HTTPstatus: apirest.HTTPstatusBadRequest, Err: fmt.Errorf("invalid batch request")}
+	ErrChainNotFound                    = apirest.APIerror{Code: 4074, HTTPstatus: apirest.HTTPstatusNotFound, Err: fmt.Errorf("chain not found")}
 	ErrVochainEmptyReply                = apirest.APIerror{Code: 5000, HTTPstatus: apirest.HTTPstatusInternalErr, Err: fmt.Errorf("vochain returned an empty reply")}
 	ErrVochainSendTxFailed              = apirest.APIerror{Code: 5001, HTTPstatus: apirest.HTTPstatusInternalErr, Err: fmt.Errorf("vochain SendTx failed")}
 	ErrVochainGetTxFailed               = apirest.APIerror{Code: 5002, HTTPstatus: apirest.HTTPstatusInternalErr, Err: fmt.Errorf("vochain GetTx failed")}
@@ -117,4 +133,10 @@ var (
 	ErrCensusBuild                      = apirest.APIerror{Code: 5032, HTTPstatus: apirest.HTTPstatusInternalErr, Err: fmt.Errorf("error building census")}
 	ErrIndexerQueryFailed               = apirest.APIerror{Code: 5033, HTTPstatus: apirest.HTTPstatusInternalErr, Err: fmt.Errorf("indexer query failed")}
 	ErrCantFetchTokenFees               = apirest.APIerror{Code: 5034, HTTPstatus: apirest.HTTPstatusInternalErr, Err: fmt.Errorf("cannot fetch token fees")}
+	ErrStorageNotAvailable              = apirest.APIerror{Code: 5035, HTTPstatus: apirest.HTTPstatusInternalErr, Err: fmt.Errorf("storage not available")}
+	ErrCantPublishFile                  = apirest.APIerror{Code: 5036, HTTPstatus: apirest.HTTPstatusInternalErr, Err: fmt.Errorf("cannot publish file to the storage")}
+	ErrCantSimulateTx                   = apirest.APIerror{Code: 5037, HTTPstatus: apirest.HTTPstatusInternalErr, Err: fmt.Errorf("cannot simulate transaction")}
+	ErrVochainGetFeeMarketFailed        = apirest.APIerror{Code: 5038, HTTPstatus: apirest.HTTPstatusInternalErr, Err: fmt.Errorf("cannot get the fee market")}
+	ErrCantFetchAccountRecovery         = apirest.APIerror{Code: 5039, HTTPstatus: apirest.HTTPstatusInternalErr, Err: fmt.Errorf("cannot fetch the account recovery")}
+	ErrIndexerLagging                   = apirest.APIerror{Code: 5040, HTTPstatus: apirest.HTTPstatusServiceUnavailable, Err: fmt.Errorf("indexer lagging behind the chain")}
 )
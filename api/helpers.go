@@ -25,7 +25,10 @@ import (
 	"go.vocdoni.io/dvote/httprouter/apirest"
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain/indexer"
 	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
@@ -42,6 +45,13 @@ func (a *API) electionSummary(pi *indexertypes.Process) *ElectionSummary {
 		VoteCount:      pi.VoteCount,
 		ManuallyEnded:  pi.ManuallyEnded,
 		ChainID:        pi.ChainID,
+		Turnout:        pi.Turnout,
+		WeightTurnout:  pi.WeightTurnout,
+		Unlisted:       pi.Unlisted,
+		NullifierGroup: pi.NullifierGroup,
+		VoteDeposit:    vochaintx.ProcessModeVoteDeposit(pi.Mode),
+		ManualEnd:      vochaintx.ProcessModeManualEnd(pi.Mode),
+		VoteDelegation: vochaintx.ProcessModeVoteDelegation(pi.Mode),
 	}
 }
 
@@ -232,6 +242,60 @@ func marshalAndSend(ctx *httprouter.HTTPContext, v any) error {
 	return ctx.Send(data, apirest.HTTPstatusOK)
 }
 
+// chainIndexer returns the indexer of the chain selected by the chainId query
+// parameter of the request, which defaults to the indexer of the node. The
+// other chains are attached to it with indexer.Indexer.AttachApp.
+func (a *API) chainIndexer(ctx *httprouter.HTTPContext) (*indexer.Indexer, error) {
+	chainID := ctx.QueryParam(ParamChainId)
+	if chainID == "" {
+		return a.indexer, nil
+	}
+	idx := a.indexer.Chain(chainID)
+	if idx == nil {
+		return nil, ErrChainNotFound.Withf("%q", chainID)
+	}
+	return idx, nil
+}
+
+// chainState returns the state of the chain indexed by idx, as returned by
+// chainIndexer, for the endpoints which serve the data of both.
+func (a *API) chainState(idx *indexer.Indexer) *state.State {
+	if idx == a.indexer {
+		return a.vocapp.State
+	}
+	return idx.App.State
+}
+
+// indexerETag returns the entity tag of a response built from the data of the
+// given kinds indexed by idx, which must be called before the data is queried,
+// and whether the client already has that response, as told by the
+// If-None-Match header of the request, so that sendNotModified can be used
+// instead.
+func (a *API) indexerETag(ctx *httprouter.HTTPContext, idx *indexer.Indexer, kinds ...indexer.DataKind) (string, bool) {
+	// weak, since the response compression changes its bytes
+	etag := `W/"` + idx.DataVersion(kinds...) + `"`
+	for _, tag := range strings.Split(ctx.Request.Header.Get("If-None-Match"), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return etag, true
+		}
+	}
+	return etag, false
+}
+
+// sendNotModified replies the request with 304 Not Modified and the etag.
+func sendNotModified(ctx *httprouter.HTTPContext, etag string) error {
+	ctx.SetHeader("ETag", etag)
+	return ctx.Send(nil, apirest.HTTPstatusNotModified)
+}
+
+// marshalAndSendWithETag is like marshalAndSend, but it also sets the etag of
+// the response, returned by indexerETag.
+func marshalAndSendWithETag(ctx *httprouter.HTTPContext, v any, etag string) error {
+	ctx.SetHeader("ETag", etag)
+	return marshalAndSend(ctx, v)
+}
+
 // parseNumber parses a string into an int.
 //
 // If the string is not parseable, returns an APIerror.
@@ -329,6 +393,20 @@ func parseBool(s string) (*bool, error) {
 	return &b, nil
 }
 
+// parseFloat parses a string into a float64.
+//
+// The empty string "" is treated specially, returns zero with no error.
+func parseFloat(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, ErrCantParseNumber.With(s)
+	}
+	return f, nil
+}
+
 // parseDate parses an RFC3339 string into a time.Time value.
 // As a convenience, accepts also time.DateOnly format (i.e. 2006-01-02).
 //
@@ -398,6 +476,32 @@ func calculatePagination(page int, limit int, totalItems uint64) (*Pagination, e
 	}, nil
 }
 
+// calculateCursorPagination returns the Pagination of a list that supports cursor pagination.
+// If a cursor was passed, page numbers are meaningless and the total is not counted, so only
+// NextCursor is set, otherwise it behaves like calculatePagination.
+func calculateCursorPagination(params PaginationParams, totalItems uint64, nextCursor string) (*Pagination, error) {
+	if params.Cursor != "" {
+		return &Pagination{
+			NextCursor: nextCursor,
+		}, nil
+	}
+	pagination, err := calculatePagination(params.Page, params.Limit, totalItems)
+	if err != nil {
+		return nil, err
+	}
+	pagination.NextCursor = nextCursor
+	return pagination, nil
+}
+
+// cursorOffset returns the offset to use for a list query. When a cursor is passed,
+// the list starts right after it, so the page number is ignored.
+func cursorOffset(params PaginationParams) int {
+	if params.Cursor != "" {
+		return 0
+	}
+	return params.Page * params.Limit
+}
+
 // paramsFromCtxFunc calls f(key) for each key passed, and the resulting value is saved in map[key] of the returned map
 func paramsFromCtxFunc(f func(key string) string, keys ...string) map[string]string {
 	m := make(map[string]string)
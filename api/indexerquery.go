@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+
+	"go.vocdoni.io/dvote/httprouter"
+	"go.vocdoni.io/dvote/httprouter/apirest"
+	"go.vocdoni.io/dvote/vochain/indexer"
+)
+
+// IndexerQueryHandler enables the admin endpoint to run read-only SQL queries
+// on the indexer database. It is not enabled by default.
+const IndexerQueryHandler = "indexerquery"
+
+func (a *API) enableIndexerQueryHandlers() error {
+	if err := a.Endpoint.RegisterMethod(
+		"/chain/indexer/query",
+		"POST",
+		apirest.MethodAccessTypeAdmin,
+		a.indexerQueryHandler,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// indexerQueryHandler
+//
+//	@Summary		Query the indexer database
+//	@Description	Runs a parameterized read-only SQL query on the indexer database, for ad-hoc analytics.
+//	@Description	Only SELECT queries on the allowed tables are accepted, returning at most 1000 rows within 10 seconds.
+//	@Tags			Indexer
+//	@Accept			json
+//	@Produce		json
+//	@Param			query	body		IndexerQuery	true	"SQL query and its arguments"
+//	@Success		200		{object}	indexertypes.QueryResult
+//	@Router			/chain/indexer/query [post]
+func (a *API) indexerQueryHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	req := &IndexerQuery{}
+	if err := json.Unmarshal(msg.Data, req); err != nil {
+		return ErrCantParseDataAsJSON.WithErr(err)
+	}
+	result, err := a.indexer.QueryReadOnly(req.Query, req.Args...)
+	if errors.Is(err, indexer.ErrQueryNotAllowed) {
+		return ErrIndexerQueryNotAllowed.WithErr(err)
+	} else if err != nil {
+		return ErrIndexerQueryInvalid.WithErr(err)
+	}
+	return marshalAndSend(ctx, result)
+}
@@ -25,6 +25,14 @@ func (a *API) enableSIKHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/siks/{address}/validity",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.sikValidityHandler,
+	); err != nil {
+		return err
+	}
 	if err := a.Endpoint.RegisterMethod(
 		"/siks/roots",
 		"GET",
@@ -73,6 +81,36 @@ func (a *API) sikValidHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) e
 	return ctx.Send(response, apirest.HTTPstatusOK)
 }
 
+// sikValidityHandler
+//
+//	@Summary		Returns the remaining validity of a SIK
+//	@Description	Returns the height at which the SIK of the address expires and is removed from the state, and the
+//	@Description	number of blocks until then. The SIKs do not expire if the chain has no SIK validity configured.
+//	@Tags			SIK
+//	@Produce		json
+//	@Param			address	path		string	true	"Address of the SIK"
+//	@Success		200		{object}	SIKValidity
+//	@Router			/siks/{address}/validity [get]
+func (a *API) sikValidityHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	address := addressParse(ctx.URLParam("address"))
+	expiration, err := a.vocapp.State.SIKExpiration(address)
+	if err != nil {
+		if errors.Is(err, state.ErrSIKNotFound) {
+			return ErrSIKNotFound
+		}
+		return ErrGettingSIK.WithErr(err)
+	}
+	validity := &SIKValidity{
+		Address:          address.Bytes(),
+		Expires:          expiration > 0,
+		ExpirationHeight: expiration,
+	}
+	if height := a.vocapp.State.CurrentHeight(); expiration > height {
+		validity.RemainingBlocks = expiration - height
+	}
+	return marshalAndSend(ctx, validity)
+}
+
 // sikValidRootsHandler
 //
 //	@Summary		List of valid SIK roots
@@ -11,6 +11,7 @@ import (
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/util"
 	"go.vocdoni.io/dvote/vochain/indexer"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
 	"go.vocdoni.io/proto/build/go/models"
 )
 
@@ -33,6 +34,14 @@ func (a *API) enableVoteHandlers() error {
 	); err != nil {
 		return err
 	}
+	if err := a.Endpoint.RegisterMethod(
+		"/votes/count",
+		"GET",
+		apirest.MethodAccessTypePublic,
+		a.votesCountHandler,
+	); err != nil {
+		return err
+	}
 	if err := a.Endpoint.RegisterMethod(
 		"/votes/{voteId}",
 		"GET",
@@ -97,9 +106,14 @@ func (a *API) submitVoteHandler(msg *apirest.APIdata, ctx *httprouter.HTTPContex
 //	@Accept					json
 //	@Produce				json
 //	@Param					voteID	path		string	true	"Nullifier of the vote"
+//	@Param					chainId	query		string	false	"Chain to query (default the chain of the node)"
 //	@Success				200		{object}	Vote
 //	@Router					/votes/{voteId} [get]
 func (a *API) getVoteHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	voteID, err := hex.DecodeString(util.TrimHex(ctx.URLParam(ParamVoteId)))
 	if err != nil {
 		return ErrCantParseVoteID.WithErr(err)
@@ -108,7 +122,7 @@ func (a *API) getVoteHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) er
 		return ErrVoteIDMalformed.Withf("%x", voteID)
 	}
 
-	voteData, err := a.indexer.GetEnvelope(voteID)
+	voteData, err := idx.GetEnvelope(voteID)
 	if err != nil {
 		if errors.Is(err, indexer.ErrVoteNotFound) {
 			return ErrVoteNotFound
@@ -116,6 +130,29 @@ func (a *API) getVoteHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) er
 		return ErrCantFetchEnvelope.WithErr(err)
 	}
 
+	vote := envelopeVote(voteData)
+	if vote.VotePackage == nil {
+		// The vote is encrypted, so we need to decrypt it or include the encrypted version.
+		process, err := a.chainState(idx).Process(voteData.Meta.ProcessId, true)
+		if err != nil {
+			return ErrCantFetchElection.WithErr(err)
+		}
+		if vote.VotePackage, err = encryptedVotePackage(voteData, process.EncryptionPrivateKeys); err != nil {
+			return err
+		}
+	}
+
+	var data []byte
+	if data, err = json.Marshal(vote); err != nil {
+		return err
+	}
+	return ctx.Send(data, apirest.HTTPstatusOK)
+}
+
+// envelopeVote returns the vote of an envelope. Its vote package is only set if
+// it is not encrypted or the indexer already decrypted it, see
+// encryptedVotePackage.
+func envelopeVote(voteData *indexertypes.EnvelopePackage) *Vote {
 	vote := &Vote{
 		TxHash:               voteData.Meta.TxHash,
 		VoteID:               voteData.Meta.Nullifier,
@@ -129,38 +166,41 @@ func (a *API) getVoteHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) er
 		Date:                 &voteData.Date,
 	}
 
+	// Encrypted votes are also included as they were sent, for transparency.
+	if !json.Valid(voteData.VotePackage) {
+		vote.EncryptedVotePackage = voteData.VotePackage
+	}
 	// If VotePackage is valid JSON, it's not encrypted, so we can include it direcectly.
 	if json.Valid(voteData.VotePackage) {
 		vote.VotePackage = voteData.VotePackage
-	} else {
-		// Otherwise, we need to decrypt it or include the encrypted version.
-		process, err := a.vocapp.State.Process(voteData.Meta.ProcessId, true)
+	} else if json.Valid(voteData.DecryptedVotePackage) {
+		// The indexer decrypts the votes once the encryption keys are revealed.
+		vote.VotePackage = voteData.DecryptedVotePackage
+	}
+	return vote
+}
+
+// encryptedVotePackage returns the package of an encrypted vote which the
+// indexer did not decrypt, decrypted with the private keys of the election if
+// they are revealed, or the encrypted version otherwise.
+func encryptedVotePackage(voteData *indexertypes.EnvelopePackage, privKeys []string) ([]byte, error) {
+	// Try to decrypt the vote package
+	var evp []byte
+	if len(privKeys) >= len(voteData.EncryptionKeyIndexes) {
+		var err error
+		evp, err = decryptVotePackage(voteData.VotePackage, privKeys, voteData.EncryptionKeyIndexes)
 		if err != nil {
-			return ErrCantFetchElection.WithErr(err)
+			log.Warnw("failed to decrypt vote package, skipping", "err", err)
 		}
-		// Try to decrypt the vote package
-		var evp []byte
-		if len(process.EncryptionPrivateKeys) >= len(voteData.EncryptionKeyIndexes) {
-			evp, err = decryptVotePackage(voteData.VotePackage, process.EncryptionPrivateKeys, voteData.EncryptionKeyIndexes)
-			if err != nil {
-				log.Warnw("failed to decrypt vote package, skipping", "err", err)
-			}
-		}
-		// If decryption failed, include the encrypted version
-		if evp == nil {
-			evp, err = json.Marshal(map[string][]byte{"encrypted": voteData.VotePackage})
-			if err != nil {
-				return ErrMarshalingJSONFailed
-			}
-		}
-		vote.VotePackage = evp
 	}
-
-	var data []byte
-	if data, err = json.Marshal(vote); err != nil {
-		return err
+	// If decryption failed, include the encrypted version
+	if evp == nil {
+		var err error
+		if evp, err = json.Marshal(map[string][]byte{"encrypted": voteData.VotePackage}); err != nil {
+			return nil, ErrMarshalingJSONFailed
+		}
 	}
-	return ctx.Send(data, apirest.HTTPstatusOK)
+	return evp, nil
 }
 
 // verifyVoteHandler
@@ -206,49 +246,72 @@ func (a *API) verifyVoteHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext)
 //	@Produce		json
 //	@Param			page		query		number	false	"Page"
 //	@Param			limit		query		number	false	"Items per page"
+//	@Param			cursor		query		string	false	"Cursor returned as nextCursor by the previous page (page is ignored)"
 //	@Param			electionId	query		string	false	"Election id"
+//	@Param			from		query		string	false	"Only the votes in blocks from this time on (RFC3339 or YYYY-MM-DD)"
+//	@Param			to			query		string	false	"Only the votes in blocks before this time (RFC3339 or YYYY-MM-DD)"
+//	@Param			chainId			query		string	false	"Chain to query (default the chain of the node)"
 //	@Success		200			{object}	VotesList
 //	@Router			/votes [get]
 func (a *API) votesListHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
 	params, err := parseVoteParams(
 		ctx.QueryParam(ParamPage),
 		ctx.QueryParam(ParamLimit),
 		ctx.QueryParam(ParamElectionId),
+		ctx.QueryParam(ParamFrom),
+		ctx.QueryParam(ParamTo),
 	)
 	if err != nil {
 		return err
 	}
+	params.Cursor = ctx.QueryParam(ParamCursor)
 
-	list, err := a.votesList(params)
+	etag, notModified := a.indexerETag(ctx, idx, indexer.DataVotes)
+	if notModified {
+		return sendNotModified(ctx, etag)
+	}
+	list, err := a.votesList(idx, params)
 	if err != nil {
 		return err
 	}
 
-	return marshalAndSend(ctx, list)
+	return marshalAndSendWithETag(ctx, list, etag)
 }
 
-// votesList produces a filtered, paginated VotesList.
+// votesList produces a filtered, paginated VotesList of the votes indexed by
+// idx.
 //
 // Errors returned are always of type APIerror.
-func (a *API) votesList(params *VoteParams) (*VotesList, error) {
-	if params.ElectionID != "" && !a.indexer.ProcessExists(params.ElectionID) {
+func (a *API) votesList(idx *indexer.Indexer, params *VoteParams) (*VotesList, error) {
+	if params.ElectionID != "" && !idx.ProcessExists(params.ElectionID) {
 		return nil, ErrElectionNotFound
 	}
 
-	votes, total, err := a.indexer.VoteList(
+	votes, nextCursor, total, err := idx.VoteListWithCursor(
 		params.Limit,
-		params.Page*params.Limit,
+		cursorOffset(params.PaginationParams),
+		params.Cursor,
 		params.ElectionID,
 		"",
+		params.From,
+		params.To,
 	)
 	if err != nil {
+		if errors.Is(err, indexer.ErrInvalidCursor) {
+			return nil, ErrCantParseCursor.WithErr(err)
+		}
 		return nil, ErrIndexerQueryFailed.WithErr(err)
 	}
 
-	pagination, err := calculatePagination(params.Page, params.Limit, total)
+	pagination, err := calculateCursorPagination(params.PaginationParams, total, nextCursor)
 	if err != nil {
 		return nil, err
 	}
+	pagination.Estimated = idx.CountsEstimated()
 
 	list := &VotesList{
 		Votes:      []*Vote{},
@@ -262,20 +325,77 @@ func (a *API) votesList(params *VoteParams) (*VotesList, error) {
 			TxHash:           vote.TxHash,
 			BlockHeight:      vote.Height,
 			TransactionIndex: &vote.TxIndex,
+			Date:             vote.Date,
 		})
 	}
 	return list, nil
 }
 
+// votesCountHandler
+//
+//	@Summary		Count votes
+//	@Description	Returns the exact number of votes, which the list of votes may only estimate
+//	@Tags			Votes
+//	@Accept			json
+//	@Produce		json
+//	@Param			electionId	query		string	false	"Election id"
+//	@Param			from		query		string	false	"Only the votes in blocks from this time on (RFC3339 or YYYY-MM-DD)"
+//	@Param			to			query		string	false	"Only the votes in blocks before this time (RFC3339 or YYYY-MM-DD)"
+//	@Param			chainId			query		string	false	"Chain to query (default the chain of the node)"
+//	@Success		200			{object}	CountResult
+//	@Router			/votes/count [get]
+func (a *API) votesCountHandler(_ *apirest.APIdata, ctx *httprouter.HTTPContext) error {
+	idx, err := a.chainIndexer(ctx)
+	if err != nil {
+		return err
+	}
+	params, err := parseVoteParams(
+		"",
+		"",
+		ctx.QueryParam(ParamElectionId),
+		ctx.QueryParam(ParamFrom),
+		ctx.QueryParam(ParamTo),
+	)
+	if err != nil {
+		return err
+	}
+	if params.ElectionID != "" && !idx.ProcessExists(params.ElectionID) {
+		return ErrElectionNotFound
+	}
+
+	etag, notModified := a.indexerETag(ctx, idx, indexer.DataVotes)
+	if notModified {
+		return sendNotModified(ctx, etag)
+	}
+	count, err := idx.CountVotes(params.ElectionID, "", params.From, params.To)
+	if err != nil {
+		return ErrIndexerQueryFailed.WithErr(err)
+	}
+	return marshalAndSendWithETag(ctx, &CountResult{Count: count}, etag)
+}
+
 // parseVoteParams returns an VoteParams filled with the passed params
-func parseVoteParams(paramPage, paramLimit, paramElectionID string) (*VoteParams, error) {
+func parseVoteParams(paramPage, paramLimit, paramElectionID, paramFrom, paramTo string) (*VoteParams, error) {
 	pagination, err := parsePaginationParams(paramPage, paramLimit)
 	if err != nil {
 		return nil, err
 	}
+	from, err := parseDate(paramFrom)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseDate(paramTo)
+	if err != nil {
+		return nil, err
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, ErrTimeRangeInvalid.Withf("from (%s) must be before to (%s)", from, to)
+	}
 
 	return &VoteParams{
 		PaginationParams: pagination,
 		ElectionID:       util.TrimHex(paramElectionID),
+		From:             from,
+		To:               to,
 	}, nil
 }
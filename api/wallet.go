@@ -17,6 +17,7 @@ import (
 	"go.vocdoni.io/dvote/httprouter/apirest"
 	"go.vocdoni.io/dvote/log"
 	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/proto"
 )
@@ -366,6 +367,11 @@ func (a *API) walletElectionHandler(msg *apirest.APIdata, ctx *httprouter.HTTPCo
 		Interruptible: description.ElectionType.Interruptible,
 		DynamicCensus: description.ElectionType.DynamicCensus,
 	}
+	vochaintx.SetProcessModeUnlisted(processMode, description.ElectionType.Unlisted)
+	vochaintx.SetProcessModeNullifierGroup(processMode, description.ElectionType.NullifierGroup)
+	vochaintx.SetProcessModeVoteDeposit(processMode, description.ElectionType.VoteDeposit)
+	vochaintx.SetProcessModeManualEnd(processMode, description.ElectionType.ManualEnd)
+	vochaintx.SetProcessModeVoteDelegation(processMode, description.ElectionType.VoteDelegation)
 
 	// Prepare the election metadata information
 	metadata := ElectionMetadata{
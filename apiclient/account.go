@@ -6,11 +6,14 @@ import (
 	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
 	"go.vocdoni.io/dvote/api"
 	"go.vocdoni.io/dvote/data/ipfs"
 	"go.vocdoni.io/dvote/httprouter/apirest"
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/dvote/vochain/txaudit"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/proto"
 )
@@ -37,19 +40,7 @@ func (c *HTTPclient) Account(address string) (*api.Account, error) {
 		}
 		address = c.account.AddressString()
 	}
-	resp, code, err := c.Request(HTTPGET, nil, "accounts", address)
-	if err != nil {
-		return nil, err
-	}
-	if code != apirest.HTTPstatusOK {
-		return nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	acc := &api.Account{}
-	err = json.Unmarshal(resp, acc)
-	if err != nil {
-		return nil, err
-	}
-	return acc, nil
+	return c.Endpoints().Account(address, nil)
 }
 
 // AccountMetadata returns the metadata associated with a Vocdoni account. If address is empty, it returns the information
@@ -61,19 +52,96 @@ func (c *HTTPclient) AccountMetadata(address string) (*api.AccountMetadata, erro
 		}
 		address = c.account.AddressString()
 	}
-	resp, code, err := c.Request(HTTPGET, nil, "accounts", address, "metadata")
+	return c.Endpoints().AccountMetadata(address, nil)
+}
+
+// AccountTxAudit returns the recent transactions of a Vocdoni account seen by the node mempool and
+// its nonce gaps. If address is empty, it returns the information about the account associated with the client.
+func (c *HTTPclient) AccountTxAudit(address string) (*txaudit.AccountAudit, error) {
+	if address == "" {
+		if c.account == nil {
+			return nil, ErrAccountNotConfigured
+		}
+		address = c.account.AddressString()
+	}
+	return c.Endpoints().AccountTxAudit(address)
+}
+
+// AccountKV returns the entries of the key-value store of an account. If address is empty,
+// it returns the entries of the account associated with the client.
+func (c *HTTPclient) AccountKV(address string) ([]*indexertypes.AccountKV, error) {
+	if address == "" {
+		if c.account == nil {
+			return nil, ErrAccountNotConfigured
+		}
+		address = c.account.AddressString()
+	}
+	list, err := c.Endpoints().AccountKV(address, nil)
 	if err != nil {
 		return nil, err
 	}
-	if code != apirest.HTTPstatusOK {
-		return nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
+	return list.Entries, nil
+}
+
+// AccountFeed returns a page of the activity of an account, most recent first, starting at
+// cursor (the nextCursor of the previous page, or empty for the first one). If address is
+// empty, it returns the activity of the account associated with the client.
+func (c *HTTPclient) AccountFeed(address string, limit int, cursor string) (*api.AccountFeed, error) {
+	if address == "" {
+		if c.account == nil {
+			return nil, ErrAccountNotConfigured
+		}
+		address = c.account.AddressString()
 	}
-	acc := &api.AccountMetadata{}
-	err = json.Unmarshal(resp, acc)
+	return c.Endpoints().AccountFeed(address, &AccountFeedParams{Limit: int64(limit), Cursor: cursor})
+}
+
+// AccountTransactions returns a page of the transactions signed by an account, most recent
+// first, starting at cursor (the nextCursor of the previous page, or empty for the first one),
+// optionally filtered by type. If address is empty, it returns the transactions of the account
+// associated with the client.
+func (c *HTTPclient) AccountTransactions(address string, txType string, limit int, cursor string) (*api.TransactionsList, error) {
+	if address == "" {
+		if c.account == nil {
+			return nil, ErrAccountNotConfigured
+		}
+		address = c.account.AddressString()
+	}
+	return c.Endpoints().AccountTransactions(address, &AccountTransactionsParams{
+		Limit:  int64(limit),
+		Cursor: cursor,
+		Type:   txType,
+	})
+}
+
+// SetAccountKV sets the entry key of the key-value store of the account associated with the
+// client to value. An empty value deletes the entry. Returns the transaction hash.
+func (c *HTTPclient) SetAccountKV(key string, value []byte) (types.HexBytes, error) {
+	acc, err := c.Account("")
+	if err != nil {
+		return nil, fmt.Errorf("account not configured: %w", err)
+	}
+	tx, err := proto.Marshal(&models.Tx{
+		Payload: &models.Tx_SetAccount{
+			SetAccount: vochaintx.NewSetAccountKVTx(acc.Nonce, nil, key, value),
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
-	return acc, nil
+	txHash, _, err := c.SignAndSendTx(tx)
+	return txHash, err
+}
+
+// SetUnlistedElectionsToken sets the token that lists the unlisted elections of
+// the account associated with the client (see OrganizationElections), or unsets
+// it if token is nil. Returns the transaction hash.
+func (c *HTTPclient) SetUnlistedElectionsToken(token *uuid.UUID) (types.HexBytes, error) {
+	var value []byte
+	if token != nil {
+		value = api.UnlistedElectionsTokenHash(token.String())
+	}
+	return c.SetAccountKV(api.UnlistedElectionsTokenKey, value)
 }
 
 // Transfer sends tokens from the account associated with the client to the given address.
@@ -147,7 +215,7 @@ func (c *HTTPclient) AccountBootstrap(faucetPkg *models.FaucetPackage, metadata
 	}
 
 	// Sign and send the transaction
-	stx.Signature, err = c.account.SignVocdoniTx(stx.Tx, c.ChainID())
+	stx.Signature, err = c.signVocdoniTx(stx.Tx)
 	if err != nil {
 		return nil, err
 	}
@@ -201,7 +269,7 @@ func (c *HTTPclient) AccountSetValidator(pubKey []byte, name string) (types.HexB
 	if err != nil {
 		return nil, fmt.Errorf("could not marshal transaction: %w", err)
 	}
-	stx.Signature, err = c.account.SignVocdoniTx(stx.Tx, c.ChainID())
+	stx.Signature, err = c.signVocdoniTx(stx.Tx)
 	if err != nil {
 		return nil, err
 	}
@@ -209,21 +277,8 @@ func (c *HTTPclient) AccountSetValidator(pubKey []byte, name string) (types.HexB
 	if err != nil {
 		return nil, err
 	}
-	resp, code, err := c.Request(HTTPPOST, &api.Transaction{
-		Payload: stxb,
-	}, "chain", "transactions")
-	if err != nil {
-		return nil, err
-	}
-	if code != apirest.HTTPstatusOK {
-		return nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	accv := &api.Transaction{}
-	err = json.Unmarshal(resp, accv)
-	if err != nil {
-		return nil, err
-	}
-	return accv.Hash, nil
+	hash, _, err := c.SendTx(stxb)
+	return hash, err
 }
 
 // AccountSetMetadata updates the metadata associated with the account associated with the client.
@@ -262,7 +317,7 @@ func (c *HTTPclient) AccountSetMetadata(metadata *api.AccountMetadata) (types.He
 	}
 
 	// Sign and send the transaction
-	stx.Signature, err = c.account.SignVocdoniTx(stx.Tx, c.ChainID())
+	stx.Signature, err = c.signVocdoniTx(stx.Tx)
 	if err != nil {
 		return nil, err
 	}
@@ -291,18 +346,7 @@ func (c *HTTPclient) AccountSetMetadata(metadata *api.AccountMetadata) (types.He
 
 // ListTokenTransfers returns the list of sent and received token transfers associated with an account
 func (c *HTTPclient) ListTokenTransfers(account common.Address, page int) (*api.TransfersList, error) {
-	resp, code, err := c.Request(HTTPGET, nil, "accounts", account.Hex(), "transfers", "page", strconv.Itoa(page))
-	if err != nil {
-		return nil, err
-	}
-	if code != apirest.HTTPstatusOK {
-		return nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	tokenTxs := &api.TransfersList{}
-	if err := json.Unmarshal(resp, tokenTxs); err != nil {
-		return nil, err
-	}
-	return tokenTxs, nil
+	return c.Endpoints().TokenTransfersList(account.Hex(), int64(page), nil)
 }
 
 // SetSIK function allows to update the Secret Identity Key for the current
@@ -327,7 +371,7 @@ func (c *HTTPclient) SetSIK(secret []byte) (types.HexBytes, error) {
 		return nil, err
 	}
 	// Sign and send the transaction
-	stx.Signature, err = c.account.SignVocdoniTx(stx.Tx, c.ChainID())
+	stx.Signature, err = c.signVocdoniTx(stx.Tx)
 	if err != nil {
 		return nil, err
 	}
@@ -335,21 +379,8 @@ func (c *HTTPclient) SetSIK(secret []byte) (types.HexBytes, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, code, err := c.Request(HTTPPOST, &api.Transaction{
-		Payload: stxb,
-	}, "chain", "transactions")
-	if err != nil {
-		return nil, err
-	}
-	if code != apirest.HTTPstatusOK {
-		return nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	accv := &api.Transaction{}
-	err = json.Unmarshal(resp, accv)
-	if err != nil {
-		return nil, err
-	}
-	return accv.Hash, nil
+	hash, _, err := c.SendTx(stxb)
+	return hash, err
 }
 
 // DelSIK function allows to delete the Secret Identity Key for the current
@@ -369,7 +400,7 @@ func (c *HTTPclient) DelSIK() (types.HexBytes, error) {
 		return nil, err
 	}
 	// Sign and send the transaction
-	stx.Signature, err = c.account.SignVocdoniTx(stx.Tx, c.ChainID())
+	stx.Signature, err = c.signVocdoniTx(stx.Tx)
 	if err != nil {
 		return nil, err
 	}
@@ -469,38 +500,18 @@ func (c *HTTPclient) ListAccounts(page int) ([]indexertypes.Account, error) {
 
 // CountAccounts returns the total count of exiting accounts
 func (c *HTTPclient) CountAccounts() (uint64, error) {
-	resp, code, err := c.Request(HTTPGET, nil, "accounts", "count")
+	count, err := c.Endpoints().AccountCount(nil)
 	if err != nil {
 		return 0, err
 	}
-	if code != apirest.HTTPstatusOK {
-		return 0, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	accts := new(struct {
-		Count uint64 `json:"count"`
-	})
-
-	if err := json.Unmarshal(resp, accts); err != nil {
-		return 0, err
-	}
-	return accts.Count, nil
+	return count.Count, nil
 }
 
 // CountTokenTransfers returns the total count of transfers sent and received for an account
 func (c *HTTPclient) CountTokenTransfers(accountID common.Address) (uint64, error) {
-	resp, code, err := c.Request(HTTPGET, nil, "accounts", accountID.Hex(), "transfers", "count")
+	count, err := c.Endpoints().TokenTransfersCount(accountID.Hex(), nil)
 	if err != nil {
 		return 0, err
 	}
-	if code != apirest.HTTPstatusOK {
-		return 0, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	tokentxs := new(struct {
-		Count uint64 `json:"count"`
-	})
-
-	if err := json.Unmarshal(resp, tokentxs); err != nil {
-		return 0, err
-	}
-	return tokentxs.Count, nil
+	return count.Count, nil
 }
@@ -0,0 +1,97 @@
+package apiclienttest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// account is an account of the fake chain.
+type account struct {
+	address       common.Address
+	nonce         uint32
+	balance       uint64
+	electionIndex uint32
+	infoURI       string
+	metadata      *api.AccountMetadata
+	sik           types.HexBytes
+}
+
+func (g *Gateway) registerAccounts(mux *http.ServeMux) {
+	mux.HandleFunc("POST /accounts", g.createAccountHandler)
+	mux.HandleFunc("GET /accounts/{address}", g.accountHandler)
+}
+
+// createAccountHandler creates the account of the signer of a CREATE_ACCOUNT
+// transaction, with DefaultBalance tokens. The faucet package is ignored.
+func (g *Gateway) createAccountHandler(w http.ResponseWriter, r *http.Request) {
+	req := &api.AccountSet{}
+	if !decode(w, r, req) {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	signer, tx, err := g.verifyTx(req.TxPayload)
+	if err != nil {
+		sendError(w, api.ErrVochainSendTxFailed.WithErr(err))
+		return
+	}
+	setAccount := tx.GetSetAccount()
+	if setAccount == nil || setAccount.Txtype != models.TxType_CREATE_ACCOUNT {
+		sendError(w, api.ErrVochainReturnedErrorCode.With("only CREATE_ACCOUNT transactions are supported"))
+		return
+	}
+	if common.BytesToAddress(setAccount.Account) != signer {
+		sendError(w, api.ErrVochainReturnedErrorCode.Withf("account %x is not the signer %s",
+			setAccount.Account, signer.Hex()))
+		return
+	}
+	if _, ok := g.accounts[signer]; ok {
+		sendError(w, api.ErrAccountAlreadyExists)
+		return
+	}
+	acc := &account{
+		address: signer,
+		balance: DefaultBalance,
+		infoURI: setAccount.GetInfoURI(),
+		sik:     setAccount.SIK,
+	}
+	if len(req.Metadata) > 0 {
+		acc.metadata = &api.AccountMetadata{}
+		if err := json.Unmarshal(req.Metadata, acc.metadata); err != nil {
+			sendError(w, api.ErrCantParseMetadataAsJSON.WithErr(err))
+			return
+		}
+	}
+	g.accounts[signer] = acc
+	send(w, &api.AccountSet{TxHash: g.commit(req.TxPayload), MetadataURL: acc.infoURI})
+}
+
+func (g *Gateway) accountHandler(w http.ResponseWriter, r *http.Request) {
+	address, err := pathHex(r, "address")
+	if err != nil || len(address) != common.AddressLength {
+		sendError(w, api.ErrCantParseAccountID.Withf("%q", r.PathValue("address")))
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	acc, ok := g.accounts[common.BytesToAddress(address)]
+	if !ok {
+		sendError(w, api.ErrAccountNotFound.With(util.TrimHex(r.PathValue("address"))))
+		return
+	}
+	send(w, &api.Account{
+		Address:       acc.address.Bytes(),
+		Nonce:         acc.nonce,
+		Balance:       acc.balance,
+		ElectionIndex: acc.electionIndex,
+		InfoURL:       acc.infoURI,
+		Metadata:      acc.metadata,
+		SIK:           acc.sik,
+	})
+}
@@ -0,0 +1,227 @@
+package apiclienttest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"maps"
+	"math/big"
+	"net/http"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/data/ipfs"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+)
+
+// census is a weighted census, keyed by the address of the participants.
+type census struct {
+	id      types.HexBytes
+	weights map[common.Address]*big.Int
+	// root and uri are set while the census is published, publishing keeps a
+	// snapshot of the participants under the root
+	root types.HexBytes
+	uri  string
+}
+
+func (g *Gateway) registerCensuses(mux *http.ServeMux) {
+	mux.HandleFunc("POST /censuses/{type}", g.newCensusHandler)
+	mux.HandleFunc("POST /censuses/{censusId}/participants", g.censusAddHandler)
+	mux.HandleFunc("GET /censuses/{censusId}/size", g.censusSizeHandler)
+	mux.HandleFunc("POST /censuses/{censusId}/publish", g.censusPublishHandler)
+	mux.HandleFunc("POST /censuses/{censusId}/publish/async", g.censusPublishAsyncHandler)
+	mux.HandleFunc("GET /censuses/{censusId}/check", g.censusPublishHandler)
+	mux.HandleFunc("GET /censuses/{censusId}/proof/{key}", g.censusProofHandler)
+}
+
+// newCensusHandler creates a census. Only the weighted censuses are supported.
+func (g *Gateway) newCensusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("type") != api.CensusTypeWeighted {
+		sendError(w, api.ErrCensusTypeUnknown.Withf("%q, only %q is supported",
+			r.PathValue("type"), api.CensusTypeWeighted))
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c := &census{
+		id:      util.RandomBytes(32),
+		weights: make(map[common.Address]*big.Int),
+	}
+	g.censuses[string(c.id)] = c
+	send(w, &api.Census{CensusID: c.id})
+}
+
+// census returns the census of the censusId path value, which can be the
+// census ID or the root of a published census. Must be called with the lock
+// held.
+func (g *Gateway) census(r *http.Request) (*census, error) {
+	id, err := pathHex(r, "censusId")
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := g.censuses[string(id)]; ok {
+		return c, nil
+	}
+	if c, ok := g.published[string(id)]; ok {
+		return c, nil
+	}
+	return nil, api.ErrCensusNotFound
+}
+
+// censusAddHandler adds the participants to the census. The participants
+// must be keyed by address.
+func (g *Gateway) censusAddHandler(w http.ResponseWriter, r *http.Request) {
+	req := &api.CensusParticipants{}
+	if !decode(w, r, req) {
+		return
+	}
+	if len(req.Participants) == 0 {
+		sendError(w, api.ErrParamParticipantsMissing)
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, err := g.census(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	if _, ok := g.censuses[string(c.id)]; !ok {
+		sendError(w, api.ErrCensusNotFound.With("published censuses cannot be modified"))
+		return
+	}
+	// the census must be published again to include the new participants
+	c.root, c.uri = nil, ""
+	for _, p := range req.Participants {
+		if p.KeyType != "" || len(p.Key) != common.AddressLength {
+			sendError(w, api.ErrParamParticipantsMissing.Withf("key %x is not an address", p.Key))
+			return
+		}
+		weight := big.NewInt(1)
+		if p.Weight != nil {
+			weight = p.Weight.MathBigInt()
+		}
+		c.weights[common.BytesToAddress(p.Key)] = weight
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (g *Gateway) censusSizeHandler(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, err := g.census(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	send(w, &api.Census{Size: uint64(len(c.weights))})
+}
+
+// censusPublishAsyncHandler publishes the census. The fake publishes it
+// immediately, so the check endpoint always finds it ready.
+func (g *Gateway) censusPublishAsyncHandler(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, err := g.census(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	if err := g.publish(c); err != nil {
+		sendError(w, err)
+		return
+	}
+	send(w, &api.Census{CensusID: c.id})
+}
+
+// censusPublishHandler publishes the census, if it was not, and returns its
+// root and URI.
+func (g *Gateway) censusPublishHandler(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, err := g.census(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	if err := g.publish(c); err != nil {
+		sendError(w, err)
+		return
+	}
+	send(w, &api.Census{CensusID: c.root, URI: c.uri})
+}
+
+// publish stores a snapshot of the census under its root, and sets its root
+// and URI. The root is the hash of the participants sorted by address. Must be
+// called with the lock held.
+func (g *Gateway) publish(c *census) error {
+	if c.root != nil {
+		return nil
+	}
+	type participant struct {
+		Key    types.HexBytes `json:"key"`
+		Weight string         `json:"weight"`
+	}
+	dump := []participant{}
+	for _, addr := range slices.SortedFunc(maps.Keys(c.weights), func(a, b common.Address) int {
+		return bytes.Compare(a.Bytes(), b.Bytes())
+	}) {
+		dump = append(dump, participant{Key: addr.Bytes(), Weight: c.weights[addr].String()})
+	}
+	data, err := json.Marshal(dump)
+	if err != nil {
+		return api.ErrMarshalingServerJSONFailed.WithErr(err)
+	}
+	root := sha256.Sum256(data)
+	c.root = root[:]
+	c.uri = "ipfs://" + ipfs.CalculateCIDv1json(data)
+	g.published[string(c.root)] = &census{
+		id:      c.root,
+		weights: maps.Clone(c.weights),
+		root:    c.root,
+		uri:     c.uri,
+	}
+	return nil
+}
+
+// censusProofHandler returns the weight of the participant. The proof is not
+// a Merkle proof, it only binds the participant to the census root.
+func (g *Gateway) censusProofHandler(w http.ResponseWriter, r *http.Request) {
+	key, err := pathHex(r, "key")
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, err := g.census(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	if c.root == nil {
+		sendError(w, api.ErrCensusNotFound.With("census not published"))
+		return
+	}
+	c = g.published[string(c.root)]
+	weight, ok := c.weights[common.BytesToAddress(key)]
+	if len(key) != common.AddressLength || !ok {
+		sendError(w, api.ErrKeyNotFoundInCensus.Withf("(%x)", key))
+		return
+	}
+	send(w, &api.Census{
+		CensusRoot:  c.root,
+		CensusProof: censusProof(c.root, common.BytesToAddress(key)),
+		Key:         key,
+		Value:       weight.Bytes(),
+		Weight:      (*types.BigInt)(weight),
+	})
+}
+
+// censusProof returns the fake proof of the address in the census.
+func censusProof(root types.HexBytes, addr common.Address) types.HexBytes {
+	proof := sha256.Sum256(append(bytes.Clone(root), addr.Bytes()...))
+	return proof[:]
+}
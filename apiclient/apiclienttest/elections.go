@@ -0,0 +1,313 @@
+package apiclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/processid"
+	"go.vocdoni.io/dvote/vochain/results"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// election is an election of the fake chain.
+type election struct {
+	id           types.HexBytes
+	organizer    common.Address
+	process      *models.Process
+	creationTime time.Time
+	startDate    time.Time
+	endDate      time.Time
+	metadata     *api.ElectionMetadata
+	// results are set once the election reaches the RESULTS status
+	results       *results.Results
+	manuallyEnded bool
+}
+
+func (g *Gateway) registerElections(mux *http.ServeMux) {
+	mux.HandleFunc("POST /elections", g.newElectionHandler)
+	mux.HandleFunc("GET /elections/{electionId}", g.electionHandler)
+	mux.HandleFunc("GET /elections/{electionId}/votes/count", g.electionVotesCountHandler)
+	mux.HandleFunc("GET /elections/{electionId}/scrutiny", g.electionScrutinyHandler)
+}
+
+// newElectionHandler creates the election of a NEW_PROCESS transaction.
+func (g *Gateway) newElectionHandler(w http.ResponseWriter, r *http.Request) {
+	req := &api.ElectionCreate{}
+	if !decode(w, r, req) {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	signer, tx, err := g.verifyTx(req.TxPayload)
+	if err != nil {
+		sendError(w, api.ErrVochainSendTxFailed.WithErr(err))
+		return
+	}
+	newProcess := tx.GetNewProcess()
+	if newProcess == nil || newProcess.Process == nil {
+		sendError(w, api.ErrVochainReturnedErrorCode.With("only NEW_PROCESS transactions are supported"))
+		return
+	}
+	e, err := g.newElection(signer, newProcess.Process)
+	if err != nil {
+		sendError(w, api.ErrVochainReturnedErrorCode.WithErr(err))
+		return
+	}
+	if len(req.Metadata) > 0 {
+		e.metadata = &api.ElectionMetadata{}
+		if err := json.Unmarshal(req.Metadata, e.metadata); err != nil {
+			sendError(w, api.ErrCantParseMetadataAsJSON.WithErr(err))
+			return
+		}
+	}
+	acc, err := g.checkNonce(signer, newProcess.Nonce)
+	if err != nil {
+		sendError(w, api.ErrVochainReturnedErrorCode.WithErr(err))
+		return
+	}
+	acc.electionIndex++
+	g.elections[string(e.id)] = e
+	send(w, &api.ElectionCreate{
+		TxHash:      g.commit(req.TxPayload),
+		ElectionID:  e.id,
+		MetadataURL: e.process.GetMetadata(),
+	})
+}
+
+// newElection validates the process and returns its election, with the ID
+// the Vochain would assign to it. Must be called with the lock held.
+func (g *Gateway) newElection(organizer common.Address, process *models.Process) (*election, error) {
+	acc, ok := g.accounts[organizer]
+	if !ok {
+		return nil, fmt.Errorf("account %s does not exist", organizer.Hex())
+	}
+	if !bytes.Equal(process.EntityId, organizer.Bytes()) {
+		return nil, fmt.Errorf("organization %x is not the signer %s", process.EntityId, organizer.Hex())
+	}
+	switch {
+	case process.EnvelopeType == nil || process.VoteOptions == nil || process.Mode == nil:
+		return nil, fmt.Errorf("envelope type, vote options and mode are required")
+	case process.EnvelopeType.Anonymous:
+		return nil, fmt.Errorf("anonymous elections are not supported")
+	case process.EnvelopeType.EncryptedVotes:
+		return nil, fmt.Errorf("encrypted elections are not supported")
+	case process.CensusOrigin != models.CensusOrigin_OFF_CHAIN_TREE_WEIGHTED:
+		return nil, fmt.Errorf("census origin %s is not supported", process.CensusOrigin)
+	case len(vochaintx.ProcessModeNullifierGroup(process.Mode)) > 0:
+		return nil, fmt.Errorf("nullifier groups are not supported")
+	case vochaintx.ProcessModeVoteDeposit(process.Mode) > 0:
+		return nil, fmt.Errorf("vote deposits are not supported")
+	case process.Status != models.ProcessStatus_READY && process.Status != models.ProcessStatus_PAUSED:
+		return nil, fmt.Errorf("invalid initial status %s", process.Status)
+	case process.Duration == 0:
+		return nil, fmt.Errorf("duration is required")
+	}
+	if _, ok := g.published[string(process.CensusRoot)]; !ok {
+		return nil, fmt.Errorf("census %x is not published", process.CensusRoot)
+	}
+
+	pid := processid.ProcessID{}
+	pid.SetChainID(g.chainID)
+	pid.SetAddr(organizer)
+	pid.SetNonce(acc.electionIndex)
+	if err := pid.SetCensusOrigin(process.CensusOrigin); err != nil {
+		return nil, err
+	}
+	if err := pid.SetEnvelopeType(process.EnvelopeType); err != nil {
+		return nil, err
+	}
+
+	process = proto.Clone(process).(*models.Process)
+	process.ProcessId = pid.Marshal()
+	e := &election{
+		id:           process.ProcessId,
+		organizer:    organizer,
+		process:      process,
+		creationTime: time.Now(),
+	}
+	e.startDate = e.creationTime
+	if process.StartTime > 0 {
+		e.startDate = time.Unix(int64(process.StartTime), 0)
+	}
+	e.endDate = e.startDate.Add(time.Duration(process.Duration) * time.Second)
+	return e, nil
+}
+
+// election returns the election of the electionId path value, ending it if
+// its end date elapsed. Must be called with the lock held.
+func (g *Gateway) election(r *http.Request) (*election, error) {
+	id, err := pathHex(r, "electionId")
+	if err != nil {
+		return nil, api.ErrCantParseElectionID.WithErr(err)
+	}
+	e, ok := g.elections[string(id)]
+	if !ok {
+		return nil, api.ErrElectionNotFound
+	}
+	g.expire(e)
+	return e, nil
+}
+
+// expire ends the election if its end date elapsed, unless it must be ended
+// manually. Must be called with the lock held.
+func (g *Gateway) expire(e *election) {
+	if e.process.Status == models.ProcessStatus_READY && time.Now().After(e.endDate) &&
+		!vochaintx.ProcessModeManualEnd(e.process.Mode) {
+		g.endElection(e)
+	}
+}
+
+// endElection computes the results of the election and sets its status to
+// RESULTS. Must be called with the lock held.
+func (g *Gateway) endElection(e *election) {
+	e.results = newResults(e)
+	for _, v := range g.votes {
+		if bytes.Equal(v.electionID, e.id) {
+			// the votes were validated when they were cast
+			_ = e.results.AddVote(v.choices, v.weight, nil)
+		}
+	}
+	e.results.BlockHeight = uint32(len(g.blocks))
+	e.process.Status = models.ProcessStatus_RESULTS
+}
+
+// newResults returns the empty results of the election.
+func newResults(e *election) *results.Results {
+	return &results.Results{
+		ProcessID:    e.id,
+		Votes:        results.NewEmptyVotes(e.process.VoteOptions),
+		Weight:       new(types.BigInt).SetUint64(0),
+		EnvelopeType: e.process.EnvelopeType,
+		VoteOpts:     e.process.VoteOptions,
+	}
+}
+
+// setElectionStatus executes a SET_PROCESS_STATUS transaction. Since the fake
+// has no scrutiny, ending an election publishes its results immediately. Must
+// be called with the lock held.
+func (g *Gateway) setElectionStatus(signer common.Address, tx *models.SetProcessTx) error {
+	e, ok := g.elections[string(tx.ProcessId)]
+	if !ok {
+		return fmt.Errorf("election %x not found", tx.ProcessId)
+	}
+	g.expire(e)
+	if signer != e.organizer {
+		return fmt.Errorf("%s is not the organizer of the election", signer.Hex())
+	}
+	current, status := e.process.Status, tx.GetStatus()
+	interruptible := e.process.Mode.Interruptible
+	switch {
+	case current != models.ProcessStatus_READY && current != models.ProcessStatus_PAUSED:
+		return fmt.Errorf("election status %s cannot be changed", current)
+	case status == models.ProcessStatus_ENDED:
+	case status == models.ProcessStatus_PAUSED && current == models.ProcessStatus_READY && interruptible:
+	case status == models.ProcessStatus_READY && current == models.ProcessStatus_PAUSED && interruptible:
+	case status == models.ProcessStatus_CANCELED && interruptible:
+	default:
+		return fmt.Errorf("invalid status change from %s to %s", current, status)
+	}
+	if _, err := g.checkNonce(signer, tx.Nonce); err != nil {
+		return err
+	}
+	if status == models.ProcessStatus_ENDED {
+		e.manuallyEnded = time.Now().Before(e.endDate)
+		g.endElection(e)
+		return nil
+	}
+	e.process.Status = status
+	return nil
+}
+
+func (g *Gateway) electionHandler(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	e, err := g.election(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	election := &api.Election{
+		ElectionSummary: api.ElectionSummary{
+			ElectionID:     e.id,
+			OrganizationID: e.organizer.Bytes(),
+			Status:         models.ProcessStatus_name[int32(e.process.Status)],
+			StartDate:      e.startDate,
+			EndDate:        e.endDate,
+			VoteCount:      g.voteCount(e),
+			FinalResults:   e.results != nil,
+			ManuallyEnded:  e.manuallyEnded,
+			ChainID:        g.chainID,
+			Unlisted:       vochaintx.ProcessModeUnlisted(e.process.Mode),
+			ManualEnd:      vochaintx.ProcessModeManualEnd(e.process.Mode),
+		},
+		MetadataURL:  e.process.GetMetadata(),
+		CreationTime: e.creationTime,
+		VoteMode:     api.VoteMode{EnvelopeType: e.process.EnvelopeType},
+		ElectionMode: api.ElectionMode{ProcessMode: e.process.Mode},
+		TallyMode:    api.TallyMode{ProcessVoteOptions: e.process.VoteOptions},
+		Census: &api.ElectionCensus{
+			CensusOrigin:  models.CensusOrigin_name[int32(e.process.CensusOrigin)],
+			CensusRoot:    e.process.CensusRoot,
+			CensusURL:     e.process.GetCensusURI(),
+			MaxCensusSize: e.process.GetMaxCensusSize(),
+		},
+	}
+	if e.results != nil {
+		election.Results = e.results.Votes
+	}
+	if e.metadata != nil {
+		election.Metadata = e.metadata
+	}
+	send(w, election)
+}
+
+func (g *Gateway) electionVotesCountHandler(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	e, err := g.election(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	send(w, &api.CountResult{Count: g.voteCount(e)})
+}
+
+func (g *Gateway) electionScrutinyHandler(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	e, err := g.election(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	if e.results == nil {
+		sendError(w, api.ErrElectionResultsNotYetAvailable)
+		return
+	}
+	send(w, &api.ElectionResults{
+		CensusRoot:     e.process.CensusRoot,
+		ElectionID:     e.id,
+		OrganizationID: e.organizer.Bytes(),
+		Results:        e.results.Votes,
+	})
+}
+
+// voteCount returns the number of votes cast in the election. Must be called
+// with the lock held.
+func (g *Gateway) voteCount(e *election) uint64 {
+	count := uint64(0)
+	for _, v := range g.votes {
+		if bytes.Equal(v.electionID, e.id) {
+			count++
+		}
+	}
+	return count
+}
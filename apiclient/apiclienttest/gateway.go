@@ -0,0 +1,403 @@
+// Package apiclienttest provides an in-memory fake of the Vocdoni API gateway,
+// so the applications built on top of the apiclient package can unit test
+// their voting flows without running a node.
+//
+// The fake implements the endpoints used by the apiclient helpers to manage
+// accounts, weighted censuses, elections and signed votes. Transactions are
+// executed as soon as they are received, each one in its own block, and their
+// signatures and nonces are verified like the Vochain does. Census proofs are
+// not cryptographic, the fake checks the census membership of the voters by
+// looking them up in the published census.
+//
+// Anonymous, encrypted and CSP elections are not supported, and the requests
+// to any other endpoint reply with 404. The capabilities of the gateway only
+// report the API namespaces it implements, so the clients reject the requests
+// to the other ones with apiclient.ErrNotSupported once they know them.
+package apiclienttest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	comettypes "github.com/cometbft/cometbft/types"
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/httprouter"
+	"go.vocdoni.io/dvote/httprouter/apirest"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultChainID is the chain ID reported by the gateways created with
+// NewGateway.
+const DefaultChainID = "vocdoni/TEST/1"
+
+// DefaultBalance is the balance of the accounts created in the gateway.
+const DefaultBalance = 1000
+
+// txRef locates a transaction in the fake chain.
+type txRef struct {
+	height uint32
+	index  uint32
+}
+
+// block is a block of the fake chain, holding a single transaction.
+type block struct {
+	hash   types.HexBytes
+	txHash types.HexBytes
+	time   time.Time
+}
+
+// Gateway is an in-memory fake of the Vocdoni API gateway, served by an
+// httptest.Server. It is safe for concurrent use.
+type Gateway struct {
+	srv     *httptest.Server
+	chainID string
+	genesis time.Time
+
+	mu        sync.Mutex
+	blocks    []*block
+	txs       map[string]txRef
+	accounts  map[common.Address]*account
+	censuses  map[string]*census
+	published map[string]*census
+	elections map[string]*election
+	votes     map[string]*vote
+	// throttled is the number of next requests replied with 429 Too Many
+	// Requests, asking to retry after retryAfter, see Throttle.
+	throttled  int
+	retryAfter time.Duration
+}
+
+// NewGateway starts a fake gateway, closed when the test finishes.
+func NewGateway(tb testing.TB) *Gateway {
+	g := &Gateway{
+		chainID:   DefaultChainID,
+		genesis:   time.Now(),
+		txs:       make(map[string]txRef),
+		accounts:  make(map[common.Address]*account),
+		censuses:  make(map[string]*census),
+		published: make(map[string]*census),
+		elections: make(map[string]*election),
+		votes:     make(map[string]*vote),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /capabilities", g.capabilitiesHandler)
+	g.registerChain(mux)
+	g.registerAccounts(mux)
+	g.registerCensuses(mux)
+	g.registerElections(mux)
+	g.registerVotes(mux)
+	g.srv = httptest.NewServer(http.StripPrefix("/v2", negotiate(g.throttle(mux))))
+	tb.Cleanup(g.srv.Close)
+	return g
+}
+
+// URL returns the URL of the gateway, to be used as the host of the clients.
+func (g *Gateway) URL() string {
+	return g.srv.URL + "/v2"
+}
+
+// ChainID returns the chain ID of the gateway.
+func (g *Gateway) ChainID() string {
+	return g.chainID
+}
+
+// NewClient returns a client of the gateway with the account of the private
+// key, or without account if it is empty.
+func (g *Gateway) NewClient(tb testing.TB, privKey string) *apiclient.HTTPclient {
+	tb.Helper()
+	c, err := apiclient.New(g.URL())
+	if err != nil {
+		tb.Fatalf("cannot create the client: %v", err)
+	}
+	if privKey != "" {
+		if err := c.SetAccount(privKey); err != nil {
+			tb.Fatalf("cannot set the account: %v", err)
+		}
+	}
+	return c
+}
+
+// Throttle makes the gateway reply the next n requests with 429 Too Many
+// Requests, asking the clients to retry after the given wait, rounded up to
+// seconds.
+func (g *Gateway) Throttle(n int, retryAfter time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.throttled, g.retryAfter = n, retryAfter
+}
+
+// throttle replies with 429 Too Many Requests while the gateway is throttled.
+func (g *Gateway) throttle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.mu.Lock()
+		throttled := g.throttled > 0
+		if throttled {
+			g.throttled--
+		}
+		retryAfter := (g.retryAfter + time.Second - 1) / time.Second
+		g.mu.Unlock()
+		if !throttled {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter)))
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+}
+
+// negotiate encodes the JSON responses in CBOR for the clients which prefer
+// it, like the httprouter of the API does.
+func negotiate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		if !httprouter.AcceptsCBOR(r.Header.Get("Accept")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := httptest.NewRecorder()
+		next.ServeHTTP(rec, r)
+		body := rec.Body.Bytes()
+		if rec.Header().Get("Content-Type") == httprouter.DefaultContentType && len(body) > 0 {
+			if data, err := httprouter.JSONToCBOR(body); err == nil {
+				body = data
+				rec.Header().Set("Content-Type", httprouter.ContentTypeCBOR)
+			}
+		}
+		maps.Copy(w.Header(), rec.Header())
+		w.WriteHeader(rec.Code)
+		_, _ = w.Write(body)
+	})
+}
+
+// Height returns the height of the last block of the gateway.
+func (g *Gateway) Height() uint32 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return uint32(len(g.blocks))
+}
+
+func (g *Gateway) capabilitiesHandler(w http.ResponseWriter, _ *http.Request) {
+	send(w, &api.Capabilities{
+		Namespaces: []string{
+			api.AccountHandler,
+			api.CensusHandler,
+			api.ChainHandler,
+			api.ElectionHandler,
+			api.VoteHandler,
+		},
+		Indexer: true,
+		Archive: true,
+		ChainID: g.chainID,
+	})
+}
+
+func (g *Gateway) registerChain(mux *http.ServeMux) {
+	mux.HandleFunc("GET /chain/info", g.chainInfoHandler)
+	mux.HandleFunc("GET /chain/blocks/{height}", g.blockHandler)
+	mux.HandleFunc("POST /chain/transactions", g.submitTxHandler)
+	mux.HandleFunc("GET /chain/transactions/reference/{hash}", g.txReferenceHandler)
+}
+
+func (g *Gateway) chainInfoHandler(w http.ResponseWriter, _ *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	organizations := make(map[common.Address]bool)
+	for _, e := range g.elections {
+		organizations[e.organizer] = true
+	}
+	send(w, &api.ChainInfo{
+		ID:                g.chainID,
+		ElectionCount:     uint64(len(g.elections)),
+		OrganizationCount: uint64(len(organizations)),
+		GenesisTime:       g.genesis,
+		Height:            uint32(len(g.blocks)),
+		Timestamp:         time.Now().Unix(),
+		TransactionCount:  uint64(len(g.txs)),
+		VoteCount:         uint64(len(g.votes)),
+	})
+}
+
+func (g *Gateway) blockHandler(w http.ResponseWriter, r *http.Request) {
+	height, err := strconv.ParseUint(r.PathValue("height"), 10, 32)
+	if err != nil {
+		sendError(w, api.ErrCantParseNumber.WithErr(err))
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if height == 0 || height > uint64(len(g.blocks)) {
+		sendError(w, api.ErrBlockNotFound)
+		return
+	}
+	b := g.blocks[height-1]
+	send(w, &api.Block{
+		Header: comettypes.Header{
+			ChainID: g.chainID,
+			Height:  int64(height),
+			Time:    b.time,
+		},
+		Hash:    b.hash,
+		TxCount: 1,
+	})
+}
+
+func (g *Gateway) txReferenceHandler(w http.ResponseWriter, r *http.Request) {
+	hash, err := hex.DecodeString(util.TrimHex(r.PathValue("hash")))
+	if err != nil {
+		sendError(w, api.ErrCantParseHexString.WithErr(err))
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ref, ok := g.txs[string(hash)]
+	if !ok {
+		sendError(w, api.ErrTransactionNotFound)
+		return
+	}
+	send(w, &api.TransactionReference{Height: ref.height, Index: ref.index})
+}
+
+// submitTxHandler executes the transactions sent to the chain. Only the
+// transactions that change the status of an election are supported.
+func (g *Gateway) submitTxHandler(w http.ResponseWriter, r *http.Request) {
+	req := &api.Transaction{}
+	if !decode(w, r, req) {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	signer, tx, err := g.verifyTx(req.Payload)
+	if err != nil {
+		sendError(w, api.ErrVochainSendTxFailed.WithErr(err))
+		return
+	}
+	switch payload := tx.Payload.(type) {
+	case *models.Tx_SetProcess:
+		if payload.SetProcess.Txtype != models.TxType_SET_PROCESS_STATUS {
+			err = fmt.Errorf("transaction type %s not supported", payload.SetProcess.Txtype)
+			break
+		}
+		err = g.setElectionStatus(signer, payload.SetProcess)
+	default:
+		err = fmt.Errorf("transaction %T not supported", payload)
+	}
+	if err != nil {
+		sendError(w, api.ErrVochainReturnedErrorCode.WithErr(err))
+		return
+	}
+	send(w, &api.Transaction{Hash: g.commit(req.Payload)})
+}
+
+// verifyTx decodes the signed transaction and returns it with its signer. An
+// unsigned transaction has the zero address as signer.
+func (g *Gateway) verifyTx(payload []byte) (common.Address, *models.Tx, error) {
+	stx := &models.SignedTx{}
+	if err := proto.Unmarshal(payload, stx); err != nil {
+		return common.Address{}, nil, fmt.Errorf("cannot decode the signed transaction: %w", err)
+	}
+	msg, tx, err := ethereum.BuildVocdoniTransaction(stx.Tx, g.chainID)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	if stx.Signature == nil {
+		return common.Address{}, tx, nil
+	}
+	signer, err := ethereum.AddrFromSignature(msg, stx.Signature)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("cannot recover the signer: %w", err)
+	}
+	return signer, tx, nil
+}
+
+// checkNonce returns the account of the signer if the nonce is the next one,
+// and increments it. Must be called with the lock held.
+func (g *Gateway) checkNonce(signer common.Address, nonce uint32) (*account, error) {
+	acc, ok := g.accounts[signer]
+	if !ok {
+		return nil, fmt.Errorf("account %s does not exist", signer.Hex())
+	}
+	if acc.nonce != nonce {
+		return nil, fmt.Errorf("invalid nonce %d, expected %d", nonce, acc.nonce)
+	}
+	acc.nonce++
+	return acc, nil
+}
+
+// commit adds a block with the transaction and returns its hash. Must be
+// called with the lock held.
+func (g *Gateway) commit(payload []byte) types.HexBytes {
+	txHash := sha256.Sum256(payload)
+	height := uint32(len(g.blocks)) + 1
+	blockHash := sha256.Sum256(append(txHash[:], strconv.Itoa(int(height))...))
+	g.blocks = append(g.blocks, &block{
+		hash:   blockHash[:],
+		txHash: txHash[:],
+		time:   time.Now(),
+	})
+	g.txs[string(txHash[:])] = txRef{height: height}
+	return txHash[:]
+}
+
+// decode unmarshals the JSON body of the request into v, replying with an
+// error if it fails.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		sendError(w, api.ErrCantParseDataAsJSON.WithErr(err))
+		return false
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		sendError(w, api.ErrCantParseDataAsJSON.WithErr(err))
+		return false
+	}
+	return true
+}
+
+// pathHex decodes the hex path value of the request.
+func pathHex(r *http.Request, name string) (types.HexBytes, error) {
+	b, err := hex.DecodeString(util.TrimHex(r.PathValue(name)))
+	if err != nil || len(b) == 0 {
+		return nil, api.ErrCantParseHexString.Withf("(%s): %v", r.PathValue(name), err)
+	}
+	return b, nil
+}
+
+func send(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		sendError(w, api.ErrMarshalingServerJSONFailed.WithErr(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apirest.HTTPstatusOK)
+	_, _ = w.Write(data)
+}
+
+// sendError replies with the API error, like the httprouter does.
+func sendError(w http.ResponseWriter, err error) {
+	apiErr := apirest.APIerror{}
+	if !errors.As(err, &apiErr) {
+		apiErr = apirest.APIerror{Err: err, Code: 500, HTTPstatus: apirest.HTTPstatusInternalErr}
+	}
+	data, _ := json.Marshal(apiErr)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.HTTPstatus)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,451 @@
+package apiclienttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+)
+
+func TestGatewayVotingFlow(t *testing.T) {
+	c := qt.New(t)
+	gw := NewGateway(t)
+
+	organizer := ethereum.NewSignKeys()
+	c.Assert(organizer.Generate(), qt.IsNil)
+	cli := gw.NewClient(t, hex.EncodeToString(organizer.PrivateKey()))
+	c.Assert(cli.ChainID(), qt.Equals, DefaultChainID)
+	_, err := cli.AccountBootstrap(nil, nil, nil)
+	c.Assert(err, qt.IsNil)
+	_, err = cli.AccountBootstrap(nil, nil, nil)
+	c.Assert(err, qt.ErrorMatches, ".*account already exists.*")
+
+	// census of three voters, the first one with weight 3
+	voters := ethereum.NewSignKeysBatch(3)
+	participants := &api.CensusParticipants{}
+	for i, voter := range voters {
+		participants.Participants = append(participants.Participants, api.CensusParticipant{
+			Key:    voter.Address().Bytes(),
+			Weight: new(types.BigInt).SetUint64(uint64(max(1, 3-2*i))),
+		})
+	}
+	censusID, err := cli.NewCensus(api.CensusTypeWeighted)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cli.CensusAddParticipants(censusID, participants), qt.IsNil)
+	size, err := cli.CensusSize(censusID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(size, qt.Equals, uint64(3))
+	root, uri, err := cli.CensusPublish(censusID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(uri, qt.Not(qt.Equals), "")
+
+	electionID, err := cli.NewElection(&api.ElectionDescription{
+		Title:    api.LanguageString{"default": "test"},
+		EndDate:  time.Now().Add(time.Hour),
+		VoteType: api.VoteType{MaxVoteOverwrites: 1},
+		Questions: []api.Question{{
+			Title: api.LanguageString{"default": "question"},
+			Choices: []api.ChoiceMetadata{
+				{Title: api.LanguageString{"default": "yes"}, Value: 0},
+				{Title: api.LanguageString{"default": "no"}, Value: 1},
+			},
+		}},
+		Census: api.CensusTypeDescription{Type: api.CensusTypeWeighted, RootHash: root, URL: uri, Size: 3},
+	}, true)
+	c.Assert(err, qt.IsNil)
+	election, err := cli.Election(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(election.Status, qt.Equals, "READY")
+	c.Assert(election.OrganizationID, qt.DeepEquals, types.HexBytes(organizer.Address().Bytes()))
+
+	// the first voter votes no and then yes, the others vote no
+	vote := func(voter *ethereum.SignKeys, choice int) (types.HexBytes, error) {
+		voterCli := cli.Clone(hex.EncodeToString(voter.PrivateKey()))
+		proof, err := voterCli.CensusGenProof(root, voter.Address().Bytes())
+		if err != nil {
+			return nil, err
+		}
+		return voterCli.Vote(&apiclient.VoteData{
+			Choices:     []int{choice},
+			Election:    election,
+			ProofMkTree: proof,
+		})
+	}
+	for _, voter := range voters {
+		_, err := vote(voter, 1)
+		c.Assert(err, qt.IsNil)
+	}
+	_, err = vote(voters[0], 0)
+	c.Assert(err, qt.IsNil)
+	_, err = vote(voters[0], 0)
+	c.Assert(err, qt.ErrorMatches, ".*overwritten 1 times.*")
+	_, err = vote(ethereum.NewSignKeysBatch(1)[0], 0)
+	c.Assert(err, qt.ErrorMatches, ".*key not found in census.*")
+	count, err := cli.ElectionVoteCount(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(count, qt.Equals, uint32(3))
+
+	// the votes are listed one per page
+	page, err := cli.ElectionVotes(electionID, 1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(page.Items, qt.HasLen, 1)
+	c.Assert(page.Pagination.TotalItems, qt.Equals, uint64(3))
+	c.Assert(page.HasNext(), qt.IsTrue)
+	page, err = page.NextPage()
+	c.Assert(err, qt.IsNil)
+	c.Assert(page.Items, qt.HasLen, 1)
+	page, err = page.NextPage()
+	c.Assert(err, qt.IsNil)
+	c.Assert(page.HasNext(), qt.IsFalse)
+	_, err = page.NextPage()
+	c.Assert(err, qt.ErrorIs, apiclient.ErrNoMorePages)
+	page, err = cli.ElectionVotes(electionID, 1)
+	c.Assert(err, qt.IsNil)
+	voted := make(map[string]bool)
+	for v, err := range page.All() {
+		c.Assert(err, qt.IsNil)
+		c.Assert(v.ElectionID, qt.DeepEquals, electionID)
+		voted[v.VoterID.String()] = true
+	}
+	c.Assert(voted, qt.HasLen, 3)
+	page, err = cli.ElectionVotes(util.RandomBytes(32), 0)
+	c.Assert(err, qt.IsNil)
+	c.Assert(page.Items, qt.HasLen, 0)
+	c.Assert(page.HasNext(), qt.IsFalse)
+
+	voterCli := cli.Clone(hex.EncodeToString(voters[0].PrivateKey()))
+	receipt, err := voterCli.VerifyMyVote(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(receipt.Valid(), qt.IsTrue, qt.Commentf("%v", receipt.Issues))
+	c.Assert(receipt.OverwriteCount, qt.Equals, uint32(1))
+	c.Assert(receipt.Choices, qt.DeepEquals, []int{0})
+
+	// only the organizer can end the election, which publishes the results
+	_, err = voterCli.AccountBootstrap(nil, nil, nil)
+	c.Assert(err, qt.IsNil)
+	_, err = voterCli.SetElectionStatus(electionID, "ENDED")
+	c.Assert(err, qt.ErrorMatches, ".*not the organizer.*")
+	_, err = cli.ElectionResults(electionID)
+	c.Assert(err, qt.ErrorMatches, ".*5024.*")
+	_, err = cli.SetElectionStatus(electionID, "ENDED")
+	c.Assert(err, qt.IsNil)
+	_, err = vote(voters[1], 0)
+	c.Assert(err, qt.ErrorMatches, ".*election is RESULTS.*")
+	results, err := cli.ElectionResults(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(results.Results[0][0].String(), qt.Equals, "3")
+	c.Assert(results.Results[0][1].String(), qt.Equals, "2")
+	election, err = cli.Election(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(election.Status, qt.Equals, "RESULTS")
+	c.Assert(election.ManuallyEnded, qt.IsTrue)
+
+	// accounts, elections and the status change are one transaction each
+	c.Assert(gw.Height(), qt.Equals, uint32(8))
+}
+
+func TestGatewayCapabilities(t *testing.T) {
+	c := qt.New(t)
+	gw := NewGateway(t)
+
+	// the capabilities are fetched once a request replies with 404
+	cli := gw.NewClient(t, "")
+	_, err := cli.ValidSIKRoots()
+	c.Assert(err, qt.ErrorIs, apiclient.ErrNotSupported)
+
+	capabilities, err := cli.Capabilities()
+	c.Assert(err, qt.IsNil)
+	c.Assert(capabilities.ChainID, qt.Equals, DefaultChainID)
+	c.Assert(capabilities.Indexer, qt.IsTrue)
+	supported, err := cli.Supports(api.ElectionHandler)
+	c.Assert(err, qt.IsNil)
+	c.Assert(supported, qt.IsTrue)
+	supported, err = cli.Supports(api.WalletHandler)
+	c.Assert(err, qt.IsNil)
+	c.Assert(supported, qt.IsFalse)
+
+	// and then the requests to the namespaces not enabled fail early,
+	// while the missing resources of the enabled ones still reply with 404
+	_, err = cli.Batch(&api.BatchSubRequest{})
+	c.Assert(err, qt.ErrorIs, apiclient.ErrNotSupported)
+	_, err = cli.Election(util.RandomBytes(32))
+	c.Assert(err, qt.Not(qt.ErrorIs), apiclient.ErrNotSupported)
+	c.Assert(err, qt.ErrorMatches, ".*404.*")
+}
+
+func TestGatewayRateLimit(t *testing.T) {
+	c := qt.New(t)
+	gw := NewGateway(t)
+
+	// the requests rejected with 429 are retried after the requested wait,
+	// which the other requests of the client honor as well
+	cli := gw.NewClient(t, "")
+	gw.Throttle(1, time.Second)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cli.ChainInfo()
+			c.Check(err, qt.IsNil)
+		}()
+	}
+	wg.Wait()
+	c.Assert(time.Since(start) >= time.Second, qt.IsTrue)
+
+	// the rate limit is shared by the clones of the client
+	cli, err := apiclient.New(gw.URL(), apiclient.WithClientOptions(apiclient.ClientOptions{
+		RateLimit: &apiclient.RateLimitPolicy{Rate: 20, Burst: 1},
+	}))
+	c.Assert(err, qt.IsNil)
+	start = time.Now()
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := ethereum.NewSignKeys()
+			c.Check(key.Generate(), qt.IsNil)
+			_, err := cli.Clone(hex.EncodeToString(key.PrivateKey())).ChainInfo()
+			c.Check(err, qt.IsNil, qt.Commentf("request %d", i))
+		}()
+	}
+	wg.Wait()
+	c.Assert(time.Since(start) >= 400*time.Millisecond, qt.IsTrue)
+
+	// the interactive requests are served in turns with the queued bulk uploads
+	censusID, err := cli.NewCensus(api.CensusTypeWeighted)
+	c.Assert(err, qt.IsNil)
+	var mu sync.Mutex
+	var done []string
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			participants := &api.CensusParticipants{Participants: []api.CensusParticipant{{
+				Key:    util.RandomBytes(20),
+				Weight: new(types.BigInt).SetUint64(1),
+			}}}
+			_, _, err := cli.Request(apiclient.HTTPPOST, participants, "censuses", censusID.String(), "participants")
+			c.Check(err, qt.IsNil)
+			mu.Lock()
+			done = append(done, "bulk")
+			mu.Unlock()
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	_, err = cli.ChainInfo()
+	c.Assert(err, qt.IsNil)
+	mu.Lock()
+	c.Assert(len(done) < 4, qt.IsTrue, qt.Commentf("%d bulk requests before the interactive one", len(done)))
+	mu.Unlock()
+	wg.Wait()
+}
+
+// headerRecorder is a middleware recording the traceparent header of the
+// requests, once set by the tracing middleware before it.
+type headerRecorder struct {
+	mu      sync.Mutex
+	headers []string
+}
+
+func (r *headerRecorder) StartOperation(ctx context.Context, _ string, _ ...any) (context.Context, func(error)) {
+	return ctx, func(error) {}
+}
+
+func (r *headerRecorder) OnRequest(req *http.Request) func(*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.headers = append(r.headers, req.Header.Get("traceparent"))
+	return func(*http.Response, error) {}
+}
+
+func TestGatewayTracing(t *testing.T) {
+	c := qt.New(t)
+	gw := NewGateway(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	recorder := &headerRecorder{}
+	cli, err := apiclient.New(gw.URL(), apiclient.WithMiddleware(
+		apiclient.NewTracingMiddleware(tp), recorder, apiclient.LogMiddleware{}))
+	c.Assert(err, qt.IsNil)
+
+	// the requests made with the context of the caller are part of its
+	// trace, and the failed operations are recorded as such
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "caller")
+	exporter.Reset()
+	_, _, err = cli.WithContext(ctx).SendTx([]byte("not a transaction"))
+	c.Assert(err, qt.IsNotNil)
+	parent.End()
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		c.Assert(span.SpanContext.TraceID(), qt.Equals, parent.SpanContext().TraceID())
+		spans[span.Name] = span
+	}
+	sendTx, ok := spans[apiclient.OperationSendTx]
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(sendTx.Parent.SpanID(), qt.Equals, parent.SpanContext().SpanID())
+	c.Assert(sendTx.Status.Code, qt.Equals, codes.Error)
+	request, ok := spans["POST /v2/chain/transactions"]
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(request.Parent.SpanID(), qt.Equals, sendTx.SpanContext.SpanID())
+	c.Assert(request.SpanKind, qt.Equals, trace.SpanKindClient)
+
+	// the trace context is sent to the API server
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	c.Assert(recorder.headers, qt.Not(qt.HasLen), 0)
+	c.Assert(recorder.headers[len(recorder.headers)-1], qt.Matches,
+		"00-"+parent.SpanContext().TraceID().String()+"-[0-9a-f]{16}-01")
+}
+
+// contentTypeRecorder records the content types of the responses.
+type contentTypeRecorder struct {
+	mu           sync.Mutex
+	contentTypes []string
+}
+
+func (r *contentTypeRecorder) StartOperation(ctx context.Context, _ string, _ ...any) (context.Context, func(error)) {
+	return ctx, func(error) {}
+}
+
+func (r *contentTypeRecorder) OnRequest(*http.Request) func(*http.Response, error) {
+	return func(resp *http.Response, _ error) {
+		if resp == nil {
+			return
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.contentTypes = append(r.contentTypes, resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestGatewayBinaryTransport(t *testing.T) {
+	c := qt.New(t)
+	gw := NewGateway(t)
+
+	recorder := &contentTypeRecorder{}
+	cli, err := apiclient.New(gw.URL(), apiclient.WithBinaryTransport(), apiclient.WithMiddleware(recorder))
+	c.Assert(err, qt.IsNil)
+	c.Assert(cli.ChainID(), qt.Equals, DefaultChainID)
+
+	// the responses are sent in CBOR, and decoded like the JSON ones
+	want, err := gw.NewClient(t, "").Capabilities()
+	c.Assert(err, qt.IsNil)
+	capabilities, err := cli.Capabilities()
+	c.Assert(err, qt.IsNil)
+	c.Assert(capabilities, qt.DeepEquals, want)
+	_, err = cli.Election(util.RandomBytes(32))
+	c.Assert(err, qt.ErrorMatches, ".*404.*")
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	c.Assert(recorder.contentTypes, qt.HasLen, 3)
+	for _, contentType := range recorder.contentTypes {
+		c.Assert(contentType, qt.Equals, "application/cbor")
+	}
+}
+
+func TestGatewayProofBundle(t *testing.T) {
+	c := qt.New(t)
+	gw := NewGateway(t)
+
+	organizer := ethereum.NewSignKeys()
+	c.Assert(organizer.Generate(), qt.IsNil)
+	cli := gw.NewClient(t, hex.EncodeToString(organizer.PrivateKey()))
+	_, err := cli.AccountBootstrap(nil, nil, nil)
+	c.Assert(err, qt.IsNil)
+
+	voters := ethereum.NewSignKeysBatch(5)
+	participants := &api.CensusParticipants{}
+	var keys [][]byte
+	for _, voter := range voters {
+		participants.Participants = append(participants.Participants, api.CensusParticipant{
+			Key:    voter.Address().Bytes(),
+			Weight: new(types.BigInt).SetUint64(1),
+		})
+		keys = append(keys, voter.Address().Bytes())
+	}
+	censusID, err := cli.NewCensus(api.CensusTypeWeighted)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cli.CensusAddParticipants(censusID, participants), qt.IsNil)
+	root, uri, err := cli.CensusPublish(censusID)
+	c.Assert(err, qt.IsNil)
+
+	// the proofs of the voters are generated, but not the one of the stranger
+	stranger := ethereum.NewSignKeysBatch(1)[0]
+	bundle, err := cli.PreGenerateProofs(root, append(keys, stranger.Address().Bytes()), 2)
+	var genErr *apiclient.ProofGenError
+	c.Assert(errors.As(err, &genErr), qt.IsTrue)
+	c.Assert(genErr.Keys, qt.DeepEquals, []int{5})
+	c.Assert(genErr.Total, qt.Equals, 6)
+	c.Assert(bundle.Len(), qt.Equals, 5)
+
+	// the bundle is distributed as a file
+	var buf bytes.Buffer
+	_, err = bundle.WriteTo(&buf)
+	c.Assert(err, qt.IsNil)
+	encoded := buf.String()
+	bundle, err = apiclient.ReadProofBundle(&buf)
+	c.Assert(err, qt.IsNil)
+	c.Assert(bundle.Len(), qt.Equals, 5)
+	c.Assert(bundle.CensusRoot, qt.DeepEquals, root)
+	buf.Reset()
+	_, err = bundle.WriteTo(&buf)
+	c.Assert(err, qt.IsNil)
+	c.Assert(buf.String(), qt.Equals, encoded)
+	_, err = apiclient.ReadProofBundle(strings.NewReader(encoded[:strings.LastIndex(encoded[:len(encoded)-1], "\n")+1]))
+	c.Assert(err, qt.ErrorIs, apiclient.ErrProofBundleMalformed)
+
+	// the voters vote with the proofs of the bundle
+	electionID, err := cli.NewElection(&api.ElectionDescription{
+		Title:   api.LanguageString{"default": "test"},
+		EndDate: time.Now().Add(time.Hour),
+		Questions: []api.Question{{
+			Title: api.LanguageString{"default": "question"},
+			Choices: []api.ChoiceMetadata{
+				{Title: api.LanguageString{"default": "yes"}, Value: 0},
+				{Title: api.LanguageString{"default": "no"}, Value: 1},
+			},
+		}},
+		Census: api.CensusTypeDescription{Type: api.CensusTypeWeighted, RootHash: root, URL: uri, Size: 5},
+	}, true)
+	c.Assert(err, qt.IsNil)
+	election, err := cli.Election(electionID)
+	c.Assert(err, qt.IsNil)
+	for _, voter := range voters {
+		_, err := cli.Clone(hex.EncodeToString(voter.PrivateKey())).Vote(&apiclient.VoteData{
+			Choices:     []int{0},
+			Election:    election,
+			ProofBundle: bundle,
+		})
+		c.Assert(err, qt.IsNil)
+	}
+	_, err = cli.Clone(hex.EncodeToString(stranger.PrivateKey())).Vote(&apiclient.VoteData{
+		Choices:     []int{0},
+		Election:    election,
+		ProofBundle: bundle,
+	})
+	c.Assert(err, qt.ErrorIs, apiclient.ErrProofNotInBundle)
+	count, err := cli.ElectionVoteCount(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(count, qt.Equals, uint32(5))
+}
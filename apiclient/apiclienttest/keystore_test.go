@@ -0,0 +1,57 @@
+package apiclienttest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+)
+
+// testMnemonic is the mnemonic of the development accounts of Hardhat.
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func TestMnemonicPrivateKey(t *testing.T) {
+	c := qt.New(t)
+	address := func(privateKey string) string {
+		keys := ethereum.NewSignKeys()
+		c.Assert(keys.AddHexKey(privateKey), qt.IsNil)
+		return keys.Address().Hex()
+	}
+
+	privateKey, err := apiclient.MnemonicPrivateKey(testMnemonic, "", "")
+	c.Assert(err, qt.IsNil)
+	c.Assert(privateKey, qt.Equals, "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	c.Assert(address(privateKey), qt.Equals, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	privateKey, err = apiclient.MnemonicPrivateKey(testMnemonic, "", "m/44'/60'/0'/0/1")
+	c.Assert(err, qt.IsNil)
+	c.Assert(address(privateKey), qt.Equals, "0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+
+	_, err = apiclient.MnemonicPrivateKey(strings.Replace(testMnemonic, "junk", "test", 1), "", "")
+	c.Assert(err, qt.ErrorMatches, "invalid mnemonic.*")
+	_, err = apiclient.MnemonicPrivateKey(testMnemonic, "", "m/foo")
+	c.Assert(err, qt.IsNotNil)
+
+	mnemonic, err := apiclient.NewMnemonic()
+	c.Assert(err, qt.IsNil)
+	c.Assert(strings.Fields(mnemonic), qt.HasLen, 24)
+	_, err = apiclient.MnemonicPrivateKey(mnemonic, "passphrase", "")
+	c.Assert(err, qt.IsNil)
+}
+
+func TestKeystore(t *testing.T) {
+	c := qt.New(t)
+	privateKey, err := apiclient.MnemonicPrivateKey(testMnemonic, "", "")
+	c.Assert(err, qt.IsNil)
+
+	keyJSON, err := apiclient.EncryptKeystore(privateKey, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(keyJSON), qt.Contains, `"address":"f39fd6e51aad88f6f4ce6ab8827279cfffb92266"`)
+	decrypted, err := apiclient.DecryptKeystore(keyJSON, "secret")
+	c.Assert(err, qt.IsNil)
+	c.Assert(decrypted, qt.Equals, privateKey)
+	_, err = apiclient.DecryptKeystore(keyJSON, "wrong")
+	c.Assert(err, qt.ErrorIs, keystore.ErrDecrypt)
+}
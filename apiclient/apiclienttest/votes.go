@@ -0,0 +1,254 @@
+package apiclienttest
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// vote is a vote of the fake chain, replaced by its overwrites.
+type vote struct {
+	nullifier   types.HexBytes
+	electionID  types.HexBytes
+	voter       common.Address
+	txHash      types.HexBytes
+	height      uint32
+	votePackage []byte
+	choices     []int
+	weight      *big.Int
+	overwrites  uint32
+	date        time.Time
+}
+
+func (g *Gateway) registerVotes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /votes", g.submitVoteHandler)
+	mux.HandleFunc("GET /votes", g.votesListHandler)
+	mux.HandleFunc("GET /votes/{voteId}", g.voteHandler)
+	mux.HandleFunc("GET /votes/verify/{electionId}/{voteId}", g.verifyVoteHandler)
+}
+
+// submitVoteHandler casts the signed vote of a VoteEnvelope transaction, or
+// overwrites the previous vote of the voter.
+func (g *Gateway) submitVoteHandler(w http.ResponseWriter, r *http.Request) {
+	req := &api.Vote{}
+	if !decode(w, r, req) {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	signer, tx, err := g.verifyTx(req.TxPayload)
+	if err != nil {
+		sendError(w, api.ErrVochainSendTxFailed.WithErr(err))
+		return
+	}
+	v, err := g.newVote(signer, tx.GetVote())
+	if err != nil {
+		sendError(w, api.ErrVochainReturnedErrorCode.WithErr(err))
+		return
+	}
+	v.txHash = g.commit(req.TxPayload)
+	v.height = uint32(len(g.blocks))
+	g.votes[string(v.nullifier)] = v
+	send(w, &api.Vote{VoteID: v.nullifier, TxHash: v.txHash})
+}
+
+// newVote validates the vote envelope signed by the voter and returns its
+// vote, counting the overwrites of the previous vote. Must be called with the
+// lock held.
+func (g *Gateway) newVote(voter common.Address, envelope *models.VoteEnvelope) (*vote, error) {
+	if envelope == nil {
+		return nil, fmt.Errorf("only vote transactions are supported")
+	}
+	if voter == (common.Address{}) {
+		return nil, fmt.Errorf("anonymous votes are not supported")
+	}
+	e, ok := g.elections[string(envelope.ProcessId)]
+	if !ok {
+		return nil, fmt.Errorf("election %x not found", envelope.ProcessId)
+	}
+	g.expire(e)
+	if e.process.Status != models.ProcessStatus_READY {
+		return nil, fmt.Errorf("election is %s", e.process.Status)
+	}
+	if time.Now().Before(e.startDate) {
+		return nil, fmt.Errorf("election starts at %s", e.startDate)
+	}
+
+	// census membership
+	proof := envelope.GetProof().GetArbo()
+	if proof == nil {
+		return nil, fmt.Errorf("an arbo census proof is required")
+	}
+	c := g.published[string(e.process.CensusRoot)]
+	available, ok := c.weights[voter]
+	if !ok || !bytes.Equal(proof.Siblings, censusProof(c.root, voter)) {
+		return nil, fmt.Errorf("%s is not in the census", voter.Hex())
+	}
+	weight := available
+	if proof.VoteWeight != nil {
+		weight = new(big.Int).SetBytes(proof.VoteWeight)
+		if weight.Cmp(available) > 0 {
+			return nil, fmt.Errorf("vote weight %s exceeds the census weight %s", weight, available)
+		}
+	}
+
+	// vote package
+	vp := &state.VotePackage{}
+	if err := json.Unmarshal(envelope.VotePackage, vp); err != nil {
+		return nil, fmt.Errorf("cannot decode the vote package: %w", err)
+	}
+	if err := newResults(e).AddVote(vp.Votes, weight, nil); err != nil {
+		return nil, err
+	}
+
+	v := &vote{
+		nullifier:   state.GenerateNullifier(voter, e.id),
+		electionID:  e.id,
+		voter:       voter,
+		votePackage: envelope.VotePackage,
+		choices:     vp.Votes,
+		weight:      weight,
+		date:        time.Now(),
+	}
+	if prior, ok := g.votes[string(v.nullifier)]; ok {
+		if prior.overwrites >= e.process.VoteOptions.MaxVoteOverwrites {
+			return nil, fmt.Errorf("vote %x overwritten %d times, the election allows %d",
+				v.nullifier, prior.overwrites, e.process.VoteOptions.MaxVoteOverwrites)
+		}
+		v.overwrites = prior.overwrites + 1
+	}
+	return v, nil
+}
+
+// vote returns the vote of the voteId path value. Must be called with the
+// lock held.
+func (g *Gateway) vote(r *http.Request) (*vote, error) {
+	id, err := pathHex(r, "voteId")
+	if err != nil {
+		return nil, api.ErrCantParseVoteID.WithErr(err)
+	}
+	v, ok := g.votes[string(id)]
+	if !ok {
+		return nil, api.ErrVoteNotFound
+	}
+	return v, nil
+}
+
+func (g *Gateway) voteHandler(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	v, err := g.vote(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	send(w, v.apiVote())
+}
+
+// apiVote returns the vote as replied by the API.
+func (v *vote) apiVote() *api.Vote {
+	index := int32(0)
+	overwrites := v.overwrites
+	return &api.Vote{
+		TxHash:           v.txHash,
+		VoteID:           v.nullifier,
+		VotePackage:      v.votePackage,
+		VoteWeight:       v.weight.String(),
+		ElectionID:       v.electionID,
+		VoterID:          v.voter.Bytes(),
+		BlockHeight:      v.height,
+		TransactionIndex: &index,
+		OverwriteCount:   &overwrites,
+		Date:             &v.date,
+	}
+}
+
+// votesListHandler lists the votes, most recent first, optionally of a single
+// election. The cursor of the next page is the position of its first vote.
+func (g *Gateway) votesListHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	electionID, err := hex.DecodeString(util.TrimHex(query.Get("electionId")))
+	if err != nil {
+		sendError(w, api.ErrCantParseElectionID.WithErr(err))
+		return
+	}
+	limit, page, offset := 10, 0, 0
+	for name, dst := range map[string]*int{"limit": &limit, "page": &page, "cursor": &offset} {
+		if value := query.Get(name); value != "" {
+			if *dst, err = strconv.Atoi(value); err != nil || *dst < 0 {
+				sendError(w, api.ErrCantParseNumber.Withf("invalid %s %q", name, value))
+				return
+			}
+		}
+	}
+	if query.Get("cursor") == "" {
+		offset = page * limit
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var votes []*vote
+	for _, v := range g.votes {
+		if len(electionID) == 0 || bytes.Equal(v.electionID, electionID) {
+			votes = append(votes, v)
+		}
+	}
+	if offset >= len(votes) {
+		sendError(w, api.ErrPageNotFound)
+		return
+	}
+	slices.SortFunc(votes, func(a, b *vote) int {
+		if c := cmp.Compare(b.height, a.height); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.nullifier, b.nullifier)
+	})
+	list := &api.VotesList{Pagination: &api.Pagination{
+		TotalItems:  uint64(len(votes)),
+		CurrentPage: uint64(offset / limit),
+		LastPage:    uint64(max(0, len(votes)-1) / limit),
+	}}
+	for _, v := range votes[offset:min(offset+limit, len(votes))] {
+		list.Votes = append(list.Votes, v.apiVote())
+	}
+	if next := offset + limit; next < len(votes) {
+		nextPage := uint64(next / limit)
+		list.Pagination.NextPage = &nextPage
+		list.Pagination.NextCursor = strconv.Itoa(next)
+	}
+	send(w, list)
+}
+
+func (g *Gateway) verifyVoteHandler(w http.ResponseWriter, r *http.Request) {
+	electionID, err := pathHex(r, "electionId")
+	if err != nil {
+		sendError(w, api.ErrCantParseElectionID.WithErr(err))
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	v, err := g.vote(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	if !bytes.Equal(v.electionID, electionID) {
+		sendError(w, api.ErrVoteNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,700 @@
+// apigen generates the typed bindings of the apiclient package from the api
+// package sources. It finds the RegisterMethod calls of the api package, and
+// then reads the swag annotations (@Summary, @Param and @Success) of the
+// registered handlers to build a Go method for each endpoint, with the path
+// parameters as arguments, the query parameters as a struct and the documented
+// body and response types.
+//
+// The annotations are the same ones used to build the OpenAPI specification,
+// so keeping them accurate keeps both the documentation and the client in sync.
+//
+// Usage (from the apiclient directory): go run ./apigen -api ../api -out endpoints_gen.go
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const apiImportPath = "go.vocdoni.io/dvote/api"
+
+func main() {
+	apiDir := flag.String("api", "../api", "directory of the api package")
+	out := flag.String("out", "endpoints_gen.go", "output file")
+	flag.Parse()
+
+	src, err := Generate(*apiDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// param is a parameter declared with a @Param annotation.
+type param struct {
+	name     string
+	in       string
+	typ      string
+	required bool
+	desc     string
+}
+
+// handlerDoc holds the swag annotations of a handler func.
+type handlerDoc struct {
+	summary string
+	params  []param
+	success []string
+	routers []string
+}
+
+// endpoint is a registered API method.
+type endpoint struct {
+	name    string
+	handler string
+	path    string
+	method  string
+	doc     *handlerDoc
+}
+
+// apiPackage holds the information parsed from the api package sources.
+type apiPackage struct {
+	handlers  map[string]*handlerDoc
+	types     map[string]bool
+	imports   map[string]string
+	endpoints []*endpoint
+}
+
+// Generate parses the api package in apiDir and returns the formatted source
+// of the generated bindings.
+func Generate(apiDir string) ([]byte, error) {
+	pkg, err := parseAPI(apiDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := pkg.nameEndpoints(); err != nil {
+		return nil, err
+	}
+	g := &generator{pkg: pkg, imports: map[string]bool{}}
+	return g.generate()
+}
+
+// parseAPI parses the non test files of the api package.
+func parseAPI(dir string) (*apiPackage, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	pkg := &apiPackage{
+		handlers: make(map[string]*handlerDoc),
+		types:    make(map[string]bool),
+		imports:  make(map[string]string),
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, imp := range f.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			name := filepath.Base(path)
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			pkg.imports[name] = path
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				if d.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range d.Specs {
+					if ts := spec.(*ast.TypeSpec); ts.Name.IsExported() {
+						pkg.types[ts.Name.Name] = true
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv != nil && d.Doc != nil {
+					pkg.handlers[d.Name.Name] = parseHandlerDoc(d.Doc)
+				}
+				if d.Body != nil {
+					pkg.endpoints = append(pkg.endpoints, parseRegisterMethodCalls(d.Body)...)
+				}
+			}
+		}
+	}
+	for _, e := range pkg.endpoints {
+		e.doc = pkg.handlers[e.handler]
+		if e.doc == nil {
+			e.doc = &handlerDoc{}
+		}
+	}
+	return pkg, nil
+}
+
+// parseRegisterMethodCalls returns the endpoints registered in the given block
+// with calls like RegisterMethod("/path", "GET", access, a.handler)
+func parseRegisterMethodCalls(body *ast.BlockStmt) []*endpoint {
+	var endpoints []*endpoint
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 4 {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "RegisterMethod" {
+			return true
+		}
+		path, ok1 := stringLit(call.Args[0])
+		method, ok2 := stringLit(call.Args[1])
+		handler, ok3 := call.Args[3].(*ast.SelectorExpr)
+		if !ok1 || !ok2 || !ok3 {
+			return true
+		}
+		endpoints = append(endpoints, &endpoint{
+			handler: handler.Sel.Name,
+			path:    path,
+			method:  method,
+		})
+		return true
+	})
+	return endpoints
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+var paramRe = regexp.MustCompile(`^(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s*(?:"(.*?)")?`)
+
+// parseHandlerDoc reads the swag annotations of a handler doc comment.
+func parseHandlerDoc(doc *ast.CommentGroup) *handlerDoc {
+	hd := &handlerDoc{}
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		tag, value := cutSpace(line)
+		switch tag {
+		case "@Summary":
+			hd.summary = value
+		case "@Param":
+			m := paramRe.FindStringSubmatch(value)
+			if m == nil {
+				continue
+			}
+			hd.params = append(hd.params, param{
+				name:     m[1],
+				in:       m[2],
+				typ:      m[3],
+				required: m[4] == "true",
+				desc:     m[5],
+			})
+		case "@Success":
+			code, rest := cutSpace(value)
+			if !strings.HasPrefix(code, "2") {
+				continue
+			}
+			if code == "204" || !strings.HasPrefix(rest, "{") {
+				hd.success = append(hd.success, "")
+				continue
+			}
+			kind, typ, _ := strings.Cut(rest, "}")
+			typ = strings.TrimSpace(typ)
+			// the type ends at the first space out of braces
+			depth := 0
+			for i, r := range typ {
+				if r == '{' {
+					depth++
+				} else if r == '}' {
+					depth--
+				} else if unicode.IsSpace(r) && depth == 0 && !strings.HasSuffix(strings.TrimSpace(typ[:i]), ",") {
+					typ = typ[:i]
+					break
+				}
+			}
+			switch strings.TrimPrefix(kind, "{") {
+			case "string":
+				hd.success = append(hd.success, "file")
+			case "array":
+				hd.success = append(hd.success, "[]"+typ)
+			default:
+				hd.success = append(hd.success, typ)
+			}
+		case "@Router":
+			path, _ := cutSpace(value)
+			hd.routers = append(hd.routers, path)
+		}
+	}
+	return hd
+}
+
+// cutSpace slices s around the first run of white space, trimming both parts.
+func cutSpace(s string) (before, after string) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
+
+// responseType returns the swag type of the response of the endpoint. Handlers
+// registered on several paths may declare one @Success for each @Router,
+// otherwise the last one is used.
+func (e *endpoint) responseType() string {
+	if len(e.doc.success) == 0 {
+		return "object"
+	}
+	if len(e.doc.success) == len(e.doc.routers) {
+		if i := slices.Index(e.doc.routers, e.path); i >= 0 {
+			return e.doc.success[i]
+		}
+	}
+	return e.doc.success[len(e.doc.success)-1]
+}
+
+// pathParams returns the path parameters of the endpoint, in order.
+func (e *endpoint) pathParams() []param {
+	var params []param
+	for _, segment := range strings.Split(e.path, "/") {
+		if !strings.HasPrefix(segment, "{") {
+			continue
+		}
+		p := param{name: strings.Trim(segment, "{}"), in: "path", typ: "string", required: true}
+		for _, dp := range e.doc.params {
+			if dp.in == "path" && strings.EqualFold(dp.name, p.name) {
+				p.typ, p.desc = dp.typ, dp.desc
+			}
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// queryParams returns the query parameters documented for the endpoint.
+func (e *endpoint) queryParams() []param {
+	var params []param
+	for _, p := range e.doc.params {
+		if p.in == "query" {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// bodyParam returns the body parameter documented for the endpoint, if any.
+func (e *endpoint) bodyParam() *param {
+	for _, p := range e.doc.params {
+		if p.in == "body" {
+			return &p
+		}
+	}
+	return nil
+}
+
+// nameEndpoints sets the Go method name of each endpoint, from its handler name.
+// Handlers registered on several paths use the plain name for the shortest path,
+// and a suffix from the path segments not found in it for the others.
+func (pkg *apiPackage) nameEndpoints() error {
+	main := make(map[string]*endpoint)
+	for _, e := range pkg.endpoints {
+		if m, ok := main[e.handler]; !ok || pathLen(e.path) < pathLen(m.path) {
+			main[e.handler] = e
+		}
+	}
+	seen := make(map[string]*endpoint)
+	for _, e := range pkg.endpoints {
+		name := exportedName(strings.TrimSuffix(e.handler, "Handler"))
+		if m := main[e.handler]; m != e {
+			mainSegments := strings.Split(m.path, "/")
+			for _, segment := range strings.Split(e.path, "/") {
+				switch {
+				case slices.Contains(mainSegments, segment):
+				case strings.HasPrefix(segment, "{"):
+					name += "By" + exportedName(strings.Trim(segment, "{}"))
+				default:
+					name += exportedName(segment)
+				}
+			}
+			if m.path == e.path {
+				name += exportedName(strings.ToLower(e.method))
+			}
+		}
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("duplicated method name %s for %s %s", name, e.method, e.path)
+		}
+		seen[name] = e
+		e.name = name
+	}
+	return nil
+}
+
+func pathLen(path string) int {
+	return strings.Count(strings.Trim(path, "/"), "/")
+}
+
+var initialismRe = regexp.MustCompile(`(Id|Ipfs|Cid|Uri|Url)([A-Z]|$)`)
+
+// exportedName returns the exported Go name of a camelCase identifier.
+func exportedName(s string) string {
+	if s == "" {
+		return s
+	}
+	s = strings.ToUpper(s[:1]) + s[1:]
+	return initialismRe.ReplaceAllStringFunc(s, strings.ToUpper)
+}
+
+// argName returns the name of a Go argument for the given parameter.
+func argName(s string) string {
+	s = exportedName(s)
+	s = strings.ToLower(s[:1]) + s[1:]
+	if token.IsKeyword(s) {
+		return s + "Param"
+	}
+	return s
+}
+
+// generator writes the Go source of the bindings.
+type generator struct {
+	pkg     *apiPackage
+	imports map[string]bool
+	// types holds the types declared for the endpoint being written
+	types   bytes.Buffer
+	methods bytes.Buffer
+}
+
+func (g *generator) generate() ([]byte, error) {
+	for _, e := range g.pkg.endpoints {
+		if err := g.writeEndpoint(e); err != nil {
+			return nil, fmt.Errorf("%s %s: %w", e.method, e.path, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by apigen. DO NOT EDIT.\n\n")
+	buf.WriteString("package apiclient\n\n")
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	// standard library first, as goimports does
+	sort.Slice(imports, func(i, j int) bool {
+		si, sj := strings.Contains(imports[i], "."), strings.Contains(imports[j], ".")
+		if si != sj {
+			return sj
+		}
+		return imports[i] < imports[j]
+	})
+	buf.WriteString("import (\n")
+	for i, imp := range imports {
+		if i > 0 && strings.Contains(imp, ".") && !strings.Contains(imports[i-1], ".") {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "\t%q\n", imp)
+	}
+	buf.WriteString(")\n\n")
+	buf.Write(g.methods.Bytes())
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+func (g *generator) writeEndpoint(e *endpoint) error {
+	w := &g.methods
+	pathParams := e.pathParams()
+	queryParams := e.queryParams()
+	body := e.bodyParam()
+
+	// arguments
+	var args []string
+	for _, p := range pathParams {
+		args = append(args, argName(p.name)+" "+g.scalarType(p.typ))
+	}
+	bodyArg := "nil"
+	if body != nil {
+		typ, err := g.goType(body.typ, e.name+"Request")
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "json.") {
+			typ = "*" + typ
+		}
+		args = append(args, "body "+typ)
+		bodyArg = "body"
+	}
+	queryArg := "nil"
+	if len(queryParams) > 0 {
+		g.writeParams(e.name+"Params", queryParams)
+		args = append(args, "params *"+e.name+"Params")
+		queryArg = "params.values()"
+	}
+
+	// response
+	var result, newResult, resultRef string
+	switch typ := e.responseType(); typ {
+	case "":
+	case "file":
+		result, newResult, resultRef = "[]byte", "var resp []byte", "&resp"
+	default:
+		goTyp, err := g.goType(typ, e.name+"Response")
+		if err != nil {
+			return err
+		}
+		switch {
+		case strings.HasPrefix(goTyp, "[]"), strings.HasPrefix(goTyp, "json."), isScalar(goTyp):
+			result, newResult, resultRef = goTyp, "var resp "+goTyp, "&resp"
+		default:
+			result, newResult, resultRef = "*"+goTyp, "resp := &"+goTyp+"{}", "resp"
+		}
+	}
+
+	// url path
+	urlPath := []string{}
+	for _, segment := range strings.Split(strings.Trim(e.path, "/"), "/") {
+		if !strings.HasPrefix(segment, "{") {
+			urlPath = append(urlPath, strconv.Quote(segment))
+			continue
+		}
+		name := strings.Trim(segment, "{}")
+		for _, p := range pathParams {
+			if p.name == name {
+				urlPath = append(urlPath, g.formatScalar(argName(name), p.typ))
+			}
+		}
+	}
+
+	// the types of the endpoint are declared right before its method
+	w.Write(g.types.Bytes())
+	g.types.Reset()
+
+	fmt.Fprintf(w, "// %s calls %s %s", e.name, e.method, e.path)
+	if e.doc.summary != "" {
+		fmt.Fprintf(w, "\n//\n// %s", e.doc.summary)
+		if !strings.HasSuffix(e.doc.summary, ".") {
+			w.WriteString(".")
+		}
+	}
+	w.WriteString("\n")
+	method := "HTTP" + strings.ToUpper(e.method)
+	if result == "" {
+		fmt.Fprintf(w, "func (e *Endpoints) %s(%s) error {\n", e.name, strings.Join(args, ", "))
+		fmt.Fprintf(w, "\treturn e.do(%s, %s, %s, nil, %s)\n}\n\n", method, bodyArg, queryArg, strings.Join(urlPath, ", "))
+		return nil
+	}
+	fmt.Fprintf(w, "func (e *Endpoints) %s(%s) (%s, error) {\n", e.name, strings.Join(args, ", "), result)
+	fmt.Fprintf(w, "\t%s\n", newResult)
+	fmt.Fprintf(w, "\tif err := e.do(%s, %s, %s, %s, %s); err != nil {\n", method, bodyArg, queryArg, resultRef, strings.Join(urlPath, ", "))
+	fmt.Fprintf(w, "\t\treturn %s, err\n\t}\n", zeroValue(result))
+	fmt.Fprintf(w, "\treturn resp, nil\n}\n\n")
+	return nil
+}
+
+// writeParams writes the struct holding the query parameters of an endpoint,
+// and its method to encode them.
+func (g *generator) writeParams(name string, params []param) {
+	g.imports["net/url"] = true
+	w := &g.types
+	fmt.Fprintf(w, "// %s holds the query parameters of %s.\n", name, strings.TrimSuffix(name, "Params"))
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	for _, p := range params {
+		if p.desc != "" {
+			fmt.Fprintf(w, "\t// %s\n", p.desc)
+		}
+		typ := g.scalarType(p.typ)
+		if typ == "bool" {
+			typ = "*bool"
+		}
+		fmt.Fprintf(w, "\t%s %s\n", exportedName(p.name), typ)
+	}
+	w.WriteString("}\n\n")
+
+	fmt.Fprintf(w, "func (p *%s) values() url.Values {\n", name)
+	w.WriteString("\tif p == nil {\n\t\treturn nil\n\t}\n")
+	w.WriteString("\tv := url.Values{}\n")
+	for _, p := range params {
+		field := "p." + exportedName(p.name)
+		switch g.scalarType(p.typ) {
+		case "bool":
+			g.imports["strconv"] = true
+			fmt.Fprintf(w, "\tif %s != nil {\n\t\tv.Set(%q, strconv.FormatBool(*%s))\n\t}\n", field, p.name, field)
+		case "int64":
+			g.imports["strconv"] = true
+			fmt.Fprintf(w, "\tif %s != 0 {\n\t\tv.Set(%q, strconv.FormatInt(%s, 10))\n\t}\n", field, p.name, field)
+		default:
+			fmt.Fprintf(w, "\tif %s != \"\" {\n\t\tv.Set(%q, %s)\n\t}\n", field, p.name, field)
+		}
+	}
+	w.WriteString("\treturn v\n}\n\n")
+}
+
+// scalarType returns the Go type of a path or query parameter.
+func (g *generator) scalarType(typ string) string {
+	switch typ {
+	case "int", "integer", "number":
+		return "int64"
+	case "bool", "boolean":
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+// formatScalar returns the expression to format a path parameter as string.
+func (g *generator) formatScalar(name, typ string) string {
+	switch g.scalarType(typ) {
+	case "int64":
+		g.imports["strconv"] = true
+		return "strconv.FormatInt(" + name + ", 10)"
+	case "bool":
+		g.imports["strconv"] = true
+		return "strconv.FormatBool(" + name + ")"
+	default:
+		return name
+	}
+}
+
+func isScalar(typ string) bool {
+	switch typ {
+	case "string", "bool", "int64", "uint64":
+		return true
+	}
+	return false
+}
+
+func zeroValue(typ string) string {
+	switch {
+	case typ == "string":
+		return `""`
+	case typ == "bool":
+		return "false"
+	case typ == "int64" || typ == "uint64":
+		return "0"
+	}
+	return "nil"
+}
+
+// goType returns the Go type of a swag type. Inline objects are declared as a
+// new struct type with the given name.
+func (g *generator) goType(typ, name string) (string, error) {
+	if strings.HasPrefix(typ, "object{") {
+		fields, err := g.structFields(typ)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&g.types, "// %s is the %s of %s.\n", name,
+			map[bool]string{true: "request body", false: "response"}[strings.HasSuffix(name, "Request")],
+			strings.TrimSuffix(strings.TrimSuffix(name, "Request"), "Response"))
+		fmt.Fprintf(&g.types, "type %s %s\n\n", name, fields)
+		return name, nil
+	}
+	return g.fieldType(typ)
+}
+
+// fieldType returns the Go type of a swag type, declaring inline objects as
+// anonymous structs.
+func (g *generator) fieldType(typ string) (string, error) {
+	switch {
+	case strings.HasPrefix(typ, "[]"):
+		elem, err := g.fieldType(typ[2:])
+		return "[]" + elem, err
+	case strings.HasPrefix(typ, "object{"):
+		return g.structFields(typ)
+	}
+	switch typ {
+	case "string", "bool", "uint64":
+		return typ, nil
+	case "boolean":
+		return "bool", nil
+	case "int", "integer":
+		return "int64", nil
+	case "number":
+		g.imports["encoding/json"] = true
+		return "json.Number", nil
+	case "file":
+		return "[]byte", nil
+	}
+	if pkgName, name, ok := strings.Cut(typ, "."); ok {
+		if pkgName == "api" {
+			typ = name
+		} else {
+			path, ok := g.pkg.imports[pkgName]
+			if !ok {
+				return "", fmt.Errorf("unknown package %s", pkgName)
+			}
+			g.imports[path] = true
+			return typ, nil
+		}
+	}
+	if g.pkg.types[typ] {
+		g.imports[apiImportPath] = true
+		return "api." + typ, nil
+	}
+	// undocumented or unknown types are returned as raw JSON
+	g.imports["encoding/json"] = true
+	return "json.RawMessage", nil
+}
+
+// structFields returns the struct type of an inline object like
+// object{name=string,list=[]string}
+func (g *generator) structFields(typ string) (string, error) {
+	inner := strings.TrimSuffix(strings.TrimPrefix(typ, "object{"), "}")
+	var fields []string
+	for _, field := range splitFields(inner) {
+		name, ftyp, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return "", fmt.Errorf("invalid inline object %s", typ)
+		}
+		goTyp, err := g.fieldType(strings.TrimSpace(ftyp))
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, fmt.Sprintf("%s %s `json:\"%s\"`", exportedName(name), goTyp, name))
+	}
+	return "struct {\n" + strings.Join(fields, "\n") + "\n}", nil
+}
+
+// splitFields splits the fields of an inline object by the commas out of braces.
+func splitFields(s string) []string {
+	var fields []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				fields = append(fields, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(fields, s[start:])
+}
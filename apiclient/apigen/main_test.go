@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// TestGeneratedUpToDate checks that the bindings of the apiclient package match
+// the current api package. If it fails, run go generate ./apiclient/
+func TestGeneratedUpToDate(t *testing.T) {
+	c := qt.New(t)
+	src, err := Generate("../../api")
+	c.Assert(err, qt.IsNil)
+	current, err := os.ReadFile("../endpoints_gen.go")
+	c.Assert(err, qt.IsNil)
+	c.Assert(bytes.Equal(src, current), qt.IsTrue,
+		qt.Commentf("apiclient/endpoints_gen.go is outdated, run go generate ./apiclient/"))
+}
+
+func TestFieldType(t *testing.T) {
+	c := qt.New(t)
+	g := &generator{
+		pkg: &apiPackage{
+			types:   map[string]bool{"Election": true},
+			imports: map[string]string{"indexertypes": "go.vocdoni.io/dvote/vochain/indexer/indexertypes"},
+		},
+		imports: map[string]bool{},
+	}
+	for _, tc := range []struct {
+		swag, goType string
+	}{
+		{"string", "string"},
+		{"boolean", "bool"},
+		{"number", "json.Number"},
+		{"[]string", "[]string"},
+		{"Election", "api.Election"},
+		{"api.Election", "api.Election"},
+		{"indexertypes.Account", "indexertypes.Account"},
+		{"Undocumented", "json.RawMessage"},
+		{"object{censusId=string,uris=[]string}", "struct {\nCensusID string `json:\"censusId\"`\nUris []string `json:\"uris\"`\n}"},
+	} {
+		typ, err := g.fieldType(tc.swag)
+		c.Assert(err, qt.IsNil)
+		c.Assert(typ, qt.Equals, tc.goType, qt.Commentf("%s", tc.swag))
+	}
+	_, err := g.fieldType("unknown.Type")
+	c.Assert(err, qt.IsNotNil)
+}
+
+func TestExportedName(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(exportedName("electionId"), qt.Equals, "ElectionID")
+	c.Assert(exportedName("accountIdFrom"), qt.Equals, "AccountIDFrom")
+	c.Assert(exportedName("identity"), qt.Equals, "Identity")
+	c.Assert(exportedName("ipfs"), qt.Equals, "IPFS")
+	c.Assert(argName("type"), qt.Equals, "typeParam")
+	c.Assert(argName("voteId"), qt.Equals, "voteID")
+}
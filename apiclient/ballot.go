@@ -0,0 +1,125 @@
+package apiclient
+
+import (
+	"fmt"
+	"math/big"
+
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/results"
+)
+
+// The ballot helpers convert between the structures a voter works with and the
+// Choices of VoteData, whose meaning depends on the ballot protocol of the
+// election, see ElectionBuilder.
+
+// EncodeRankedBallot returns the choices of a ranked election for a ranking of
+// all its choices, from the most to the least preferred. The vote has one
+// field per choice, with its position in the ranking.
+func EncodeRankedBallot(election *api.Election, ranking []int) ([]int, error) {
+	choices := make([]int, len(ranking))
+	seen := make(map[int]bool, len(ranking))
+	for position, choice := range ranking {
+		if choice < 0 || choice >= len(ranking) {
+			return nil, fmt.Errorf("invalid choice %d, there are %d", choice, len(ranking))
+		}
+		if seen[choice] {
+			return nil, fmt.Errorf("choice %d is ranked twice", choice)
+		}
+		seen[choice] = true
+		choices[choice] = position
+	}
+	if err := ValidateBallot(election, choices, nil); err != nil {
+		return nil, err
+	}
+	return choices, nil
+}
+
+// DecodeRankedBallot returns the ranking of the choices of a vote of a ranked
+// election, from the most to the least preferred. It is the reverse of
+// EncodeRankedBallot.
+func DecodeRankedBallot(election *api.Election, choices []int) ([]int, error) {
+	if err := ValidateBallot(election, choices, nil); err != nil {
+		return nil, err
+	}
+	ranking := make([]int, len(choices))
+	seen := make(map[int]bool, len(choices))
+	for choice, position := range choices {
+		if position < 0 || position >= len(choices) {
+			return nil, fmt.Errorf("invalid position %d of choice %d", position, choice)
+		}
+		if seen[position] {
+			return nil, fmt.Errorf("position %d is repeated", position)
+		}
+		seen[position] = true
+		ranking[position] = choice
+	}
+	return ranking, nil
+}
+
+// EncodeQuadraticBallot returns the choices of a quadratic or budget election
+// for the number of votes given to each choice, checking that their cost fits
+// in the budget of the voter. The budget is the weight of the voter if the
+// cost is taken from the weight, which can be nil otherwise.
+func EncodeQuadraticBallot(election *api.Election, votes []int, weight *big.Int) ([]int, error) {
+	choices := append([]int(nil), votes...)
+	if err := ValidateBallot(election, choices, weight); err != nil {
+		return nil, err
+	}
+	return choices, nil
+}
+
+// DecodeQuadraticBallot returns the number of votes given to each choice by a
+// vote of a quadratic or budget election, and the credits they cost.
+func DecodeQuadraticBallot(election *api.Election, choices []int) ([]int, *big.Int, error) {
+	if election.TallyMode.ProcessVoteOptions == nil {
+		return nil, nil, fmt.Errorf("the election has no vote options")
+	}
+	for choice, votes := range choices {
+		if votes < 0 {
+			return nil, nil, fmt.Errorf("invalid number of votes %d of choice %d", votes, choice)
+		}
+	}
+	return append([]int(nil), choices...), BallotCost(election, choices), nil
+}
+
+// BallotCost returns the credits the choices cost in the election, which is
+// the sum of each value raised to the cost exponent.
+func BallotCost(election *api.Election, choices []int) *big.Int {
+	exponent := big.NewInt(1)
+	if opts := election.TallyMode.ProcessVoteOptions; opts != nil {
+		exponent.SetUint64(uint64(opts.CostExponent))
+	}
+	cost := new(big.Int)
+	for _, v := range choices {
+		cost.Add(cost, new(big.Int).Exp(big.NewInt(int64(v)), exponent, nil))
+	}
+	return cost
+}
+
+// ValidateBallot checks the choices of a vote against the ballot protocol of
+// the election, with the same rules used by the chain to count it. The weight
+// is the budget of the voter if the cost is taken from the weight, and can be
+// nil otherwise.
+func ValidateBallot(election *api.Election, choices []int, weight *big.Int) error {
+	if election.TallyMode.ProcessVoteOptions == nil || election.VoteMode.EnvelopeType == nil {
+		return fmt.Errorf("the election has no ballot protocol")
+	}
+	for i, v := range choices {
+		if v < 0 {
+			return fmt.Errorf("invalid negative value %d of field %d", v, i)
+		}
+	}
+	if election.VoteMode.CostFromWeight && weight == nil {
+		return fmt.Errorf("the weight of the voter is required, since it is the budget")
+	}
+	r := &results.Results{
+		VoteOpts:     election.TallyMode.ProcessVoteOptions,
+		EnvelopeType: election.VoteMode.EnvelopeType,
+		Weight:       new(types.BigInt),
+	}
+	if err := r.AddVote(choices, weight, nil); err != nil {
+		return fmt.Errorf("invalid ballot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package apiclient_test
+
+import (
+	"math/big"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// testBallotElection returns an election with the given envelope type and vote
+// options, as configured by the presets of ElectionBuilder.
+func testBallotElection(envelope *models.EnvelopeType, options *models.ProcessVoteOptions) *api.Election {
+	return &api.Election{
+		VoteMode:  api.VoteMode{EnvelopeType: envelope},
+		TallyMode: api.TallyMode{ProcessVoteOptions: options},
+	}
+}
+
+func TestRankedBallot(t *testing.T) {
+	c := qt.New(t)
+	election := testBallotElection(&models.EnvelopeType{UniqueValues: true},
+		&models.ProcessVoteOptions{MaxCount: 3, MaxValue: 2, CostExponent: 1})
+
+	// the third choice is the preferred one, then the first one
+	choices, err := apiclient.EncodeRankedBallot(election, []int{2, 0, 1})
+	c.Assert(err, qt.IsNil)
+	c.Assert(choices, qt.DeepEquals, []int{1, 2, 0})
+	ranking, err := apiclient.DecodeRankedBallot(election, choices)
+	c.Assert(err, qt.IsNil)
+	c.Assert(ranking, qt.DeepEquals, []int{2, 0, 1})
+
+	_, err = apiclient.EncodeRankedBallot(election, []int{2, 0, 3})
+	c.Assert(err, qt.ErrorMatches, "invalid choice 3, there are 3")
+	_, err = apiclient.EncodeRankedBallot(election, []int{2, 0, 2})
+	c.Assert(err, qt.ErrorMatches, "choice 2 is ranked twice")
+	_, err = apiclient.EncodeRankedBallot(election, []int{1, 0, 3, 2})
+	c.Assert(err, qt.ErrorMatches, "invalid ballot: .*")
+	_, err = apiclient.DecodeRankedBallot(election, []int{1, 1, 0})
+	c.Assert(err, qt.ErrorMatches, "invalid ballot: .*")
+	_, err = apiclient.EncodeRankedBallot(election, []int{-1, 0, 1})
+	c.Assert(err, qt.ErrorMatches, "invalid choice -1, there are 3")
+	_, err = apiclient.DecodeRankedBallot(election, []int{1, 1, 0})
+	c.Assert(err, qt.ErrorMatches, "invalid ballot: .*")
+	_, err = apiclient.DecodeRankedBallot(&api.Election{}, choices)
+	c.Assert(err, qt.ErrorMatches, "the election has no ballot protocol")
+
+	// the positions must be the ones of the choices ranked, even if the
+	// election allows greater values
+	_, err = apiclient.DecodeRankedBallot(election, []int{0, 2})
+	c.Assert(err, qt.ErrorMatches, "invalid position 2 of choice 1")
+
+	// every ranking is decoded back
+	for _, ranking := range [][]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}} {
+		choices, err := apiclient.EncodeRankedBallot(election, ranking)
+		c.Assert(err, qt.IsNil)
+		decoded, err := apiclient.DecodeRankedBallot(election, choices)
+		c.Assert(err, qt.IsNil)
+		c.Assert(decoded, qt.DeepEquals, ranking)
+	}
+}
+
+func TestQuadraticBallot(t *testing.T) {
+	c := qt.New(t)
+	election := testBallotElection(&models.EnvelopeType{},
+		&models.ProcessVoteOptions{MaxCount: 3, MaxTotalCost: 10, CostExponent: 2})
+
+	// 3 votes cost 9 credits, and 1 vote costs 1 credit
+	choices, err := apiclient.EncodeQuadraticBallot(election, []int{3, 0, 1}, nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(choices, qt.DeepEquals, []int{3, 0, 1})
+	votes, cost, err := apiclient.DecodeQuadraticBallot(election, choices)
+	c.Assert(err, qt.IsNil)
+	c.Assert(votes, qt.DeepEquals, []int{3, 0, 1})
+	c.Assert(cost.Int64(), qt.Equals, int64(10))
+
+	_, err = apiclient.EncodeQuadraticBallot(election, []int{3, 1, 1}, nil)
+	c.Assert(err, qt.ErrorMatches, "invalid ballot: .*")
+	_, err = apiclient.EncodeQuadraticBallot(election, []int{-1, 0, 0}, nil)
+	c.Assert(err, qt.ErrorMatches, "invalid negative value -1 of field 0")
+	_, _, err = apiclient.DecodeQuadraticBallot(election, []int{0, -1, 0})
+	c.Assert(err, qt.ErrorMatches, "invalid number of votes -1 of choice 1")
+	_, err = apiclient.EncodeQuadraticBallot(election, []int{1, 1, 1, 1}, nil)
+	c.Assert(err, qt.ErrorMatches, "invalid ballot: max count overflow 4")
+
+	// the weight is not the budget unless the cost is taken from it, and
+	// abstaining costs nothing
+	_, err = apiclient.EncodeQuadraticBallot(election, []int{3, 0, 1}, big.NewInt(1))
+	c.Assert(err, qt.IsNil)
+	choices, err = apiclient.EncodeQuadraticBallot(election, []int{0, 0, 0}, nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(apiclient.BallotCost(election, choices).Int64(), qt.Equals, int64(0))
+
+	// the cost is linear without vote options, which cannot be decoded
+	c.Assert(apiclient.BallotCost(&api.Election{}, []int{3, 0, 1}).Int64(), qt.Equals, int64(4))
+	_, _, err = apiclient.DecodeQuadraticBallot(&api.Election{}, []int{3, 0, 1})
+	c.Assert(err, qt.ErrorMatches, "the election has no vote options")
+}
+
+func TestWeightedBudgetBallot(t *testing.T) {
+	c := qt.New(t)
+	election := testBallotElection(&models.EnvelopeType{CostFromWeight: true},
+		&models.ProcessVoteOptions{MaxCount: 3, CostExponent: 1})
+
+	// the budget of the voter is its weight
+	_, err := apiclient.EncodeQuadraticBallot(election, []int{2, 1, 0}, nil)
+	c.Assert(err, qt.ErrorMatches, "the weight of the voter is required, since it is the budget")
+	choices, err := apiclient.EncodeQuadraticBallot(election, []int{2, 1, 0}, big.NewInt(3))
+	c.Assert(err, qt.IsNil)
+	c.Assert(apiclient.BallotCost(election, choices).Int64(), qt.Equals, int64(3))
+	_, err = apiclient.EncodeQuadraticBallot(election, []int{2, 1, 0}, big.NewInt(2))
+	c.Assert(err, qt.ErrorMatches, "invalid ballot: max total cost overflow: 3")
+
+	// a voter without weight can only abstain
+	_, err = apiclient.EncodeQuadraticBallot(election, []int{0, 0, 0}, big.NewInt(0))
+	c.Assert(err, qt.IsNil)
+	_, err = apiclient.EncodeQuadraticBallot(election, []int{0, 1, 0}, big.NewInt(0))
+	c.Assert(err, qt.ErrorMatches, "invalid ballot: max total cost overflow: 1")
+}
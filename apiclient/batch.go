@@ -0,0 +1,57 @@
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"go.vocdoni.io/dvote/api"
+)
+
+// NewBatchRequest returns a request to be sent in a batch with Batch. The
+// arguments are the same than the ones of Request.
+func NewBatchRequest(method string, jsonBody any, urlPath ...string) (*api.BatchSubRequest, error) {
+	req := &api.BatchSubRequest{
+		Method: method,
+		Path:   "/" + path.Join(urlPath...),
+	}
+	if jsonBody != nil {
+		body, err := json.Marshal(jsonBody)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+	return req, nil
+}
+
+// Batch sends the requests to the API server in a single round trip, which
+// executes them concurrently, and returns their responses in the same order.
+// The requests are authorized with the bearer token of the client. Use
+// DecodeBatchResponse to decode the responses.
+func (c *HTTPclient) Batch(reqs ...*api.BatchSubRequest) ([]*api.BatchSubResponse, error) {
+	if len(reqs) > api.MaxBatchRequests {
+		return nil, fmt.Errorf("too many requests in the batch: %d, the maximum is %d",
+			len(reqs), api.MaxBatchRequests)
+	}
+	resp, err := c.Endpoints().Batch(&api.BatchRequest{Requests: reqs})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Responses) != len(reqs) {
+		return nil, fmt.Errorf("got %d responses to %d requests", len(resp.Responses), len(reqs))
+	}
+	return resp.Responses, nil
+}
+
+// DecodeBatchResponse decodes the response to a request of a batch into v, or
+// returns an *APIError if the request failed.
+func DecodeBatchResponse(resp *api.BatchSubResponse, v any) error {
+	if resp.Status < 200 || resp.Status >= 300 {
+		return &APIError{StatusCode: resp.Status, Body: resp.Body}
+	}
+	if v == nil || len(resp.Body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Body, v)
+}
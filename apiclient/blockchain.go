@@ -8,6 +8,7 @@ import (
 	"go.vocdoni.io/dvote/httprouter/apirest"
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/vochain/genesis"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/encoding/protojson"
 )
@@ -17,36 +18,12 @@ var ErrTransactionDoesNotExist = fmt.Errorf("transaction does not exist")
 
 // ChainInfo returns some information about the chain, such as block height.
 func (c *HTTPclient) ChainInfo() (*api.ChainInfo, error) {
-	resp, code, err := c.Request(HTTPGET, nil, "chain", "info")
-	if err != nil {
-		return nil, err
-	}
-	if code != apirest.HTTPstatusOK {
-		return nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	chainInfo := &api.ChainInfo{}
-	err = json.Unmarshal(resp, chainInfo)
-	if err != nil {
-		return nil, err
-	}
-	return chainInfo, nil
+	return c.Endpoints().ChainInfo()
 }
 
 // Block returns information about a block, given a height.
 func (c *HTTPclient) Block(height uint32) (*api.Block, error) {
-	resp, code, err := c.Request(HTTPGET, nil, "chain", "blocks", fmt.Sprintf("%d", height))
-	if err != nil {
-		return nil, err
-	}
-	if code != apirest.HTTPstatusOK {
-		return nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	block := &api.Block{}
-	err = json.Unmarshal(resp, block)
-	if err != nil {
-		return nil, err
-	}
-	return block, nil
+	return c.Endpoints().ChainBlockByHeight(int64(height), nil)
 }
 
 // TransactionsCost returns a map with the current cost for all transactions
@@ -97,6 +74,23 @@ func (c *HTTPclient) TransactionReference(txHash types.HexBytes) (*api.Transacti
 	return txRef, nil
 }
 
+// TransactionReceipt returns the receipt of a transaction given its hash, with
+// the tokens spent by its fees and the events it emitted.
+func (c *HTTPclient) TransactionReceipt(txHash types.HexBytes) (*indexertypes.TransactionReceipt, error) {
+	resp, code, err := c.Request(HTTPGET, nil, "chain", "transactions", txHash.String(), "receipt")
+	if err != nil {
+		return nil, err
+	}
+	if code != apirest.HTTPstatusOK {
+		return nil, ErrTransactionDoesNotExist
+	}
+	receipt := &indexertypes.TransactionReceipt{}
+	if err := json.Unmarshal(resp, receipt); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
 // TransactionByHash returns the full transaction given its hash.
 // For querying if a transaction is included in a block, it is recommended to
 // use TransactionReference which is much faster.
@@ -148,16 +142,9 @@ func (c *HTTPclient) OrganizationsBySearchTermPaginated(
 
 // TransactionCount returns the count of transactions
 func (c *HTTPclient) TransactionCount() (uint64, error) {
-	resp, code, err := c.Request(HTTPGET, nil, "chain", "transactions", "count")
+	count, err := c.Endpoints().ChainTxCount(nil)
 	if err != nil {
 		return 0, err
 	}
-	if code != apirest.HTTPstatusOK {
-		return 0, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	txsCount := new(struct {
-		Count uint64 `json:"count"`
-	})
-
-	return txsCount.Count, json.Unmarshal(resp, txsCount)
+	return count.Count, nil
 }
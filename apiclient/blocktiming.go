@@ -0,0 +1,31 @@
+package apiclient
+
+import (
+	"fmt"
+
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// SetBlockTiming sets the block time and the time the validators wait for new
+// transactions before proposing an empty block (zero to always create them),
+// both in seconds. The account associated with the client must be the one of a
+// validator. Returns the transaction hash.
+func (c *HTTPclient) SetBlockTiming(blockTime, emptyBlocksInterval uint32) (types.HexBytes, error) {
+	acc, err := c.Account("")
+	if err != nil {
+		return nil, fmt.Errorf("account not configured: %w", err)
+	}
+	stx, err := proto.Marshal(&models.Tx{
+		Payload: &models.Tx_SetAccount{
+			SetAccount: vochaintx.NewSetBlockTimingTx(acc.Nonce, blockTime, emptyBlocksInterval),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	txHash, _, err := c.SignAndSendTx(stx)
+	return txHash, err
+}
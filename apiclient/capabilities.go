@@ -0,0 +1,135 @@
+package apiclient
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/httprouter/apirest"
+)
+
+// ErrNotSupported is returned by the requests to the API namespaces which the
+// gateway does not enable, see Capabilities.
+var ErrNotSupported = errors.New("not supported by the gateway")
+
+// namespaces are the API namespaces which a gateway can enable or not.
+var namespaces = []string{
+	api.AccountHandler,
+	api.BatchHandler,
+	api.CensusHandler,
+	api.ChainHandler,
+	api.ElectionHandler,
+	api.IndexerQueryHandler,
+	api.SIKHandler,
+	api.VoteHandler,
+	api.WalletHandler,
+}
+
+// capabilitiesCache holds the capabilities of the gateway once known. It is
+// shared by the clones of the client.
+type capabilitiesCache struct {
+	mu           sync.RWMutex
+	capabilities *api.Capabilities
+	// unavailable is true if the gateway does not serve its capabilities,
+	// such as the gateways running an older version.
+	unavailable bool
+}
+
+// Capabilities returns the capabilities of the gateway: the API namespaces it
+// enables, whether it serves the indexed data and keeps all the blocks, and the
+// chain parameters. Once they are known, the requests to the namespaces which
+// the gateway does not enable fail early with ErrNotSupported. They are also
+// fetched the first time a request replies with 404, so that its error tells
+// whether the endpoint is not supported by the gateway. Returns ErrNotSupported
+// if the gateway does not serve its capabilities.
+func (c *HTTPclient) Capabilities() (*api.Capabilities, error) {
+	c.capabilities.mu.RLock()
+	capabilities, unavailable := c.capabilities.capabilities, c.capabilities.unavailable
+	c.capabilities.mu.RUnlock()
+	if capabilities != nil {
+		return capabilities, nil
+	}
+	if unavailable {
+		return nil, fmt.Errorf("%w: capabilities", ErrNotSupported)
+	}
+	capabilities, err := c.Endpoints().Capabilities()
+	if apiErr := (*APIError)(nil); errors.As(err, &apiErr) && apiErr.StatusCode == apirest.HTTPstatusNotFound {
+		c.capabilities.mu.Lock()
+		c.capabilities.unavailable = true
+		c.capabilities.mu.Unlock()
+		return nil, fmt.Errorf("%w: capabilities", ErrNotSupported)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.capabilities.mu.Lock()
+	c.capabilities.capabilities = capabilities
+	c.capabilities.mu.Unlock()
+	return capabilities, nil
+}
+
+// Supports returns whether the gateway enables the API namespace, such as
+// api.ElectionHandler, fetching its capabilities if they are not known yet.
+// The gateways which do not serve their capabilities are assumed to enable all
+// the namespaces.
+func (c *HTTPclient) Supports(namespace string) (bool, error) {
+	capabilities, err := c.Capabilities()
+	if errors.Is(err, ErrNotSupported) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(capabilities.Namespaces, namespace), nil
+}
+
+// checkSupported returns ErrNotSupported if the capabilities of the gateway are
+// known and the namespace of the request path is not enabled. If fetch is true,
+// the capabilities are fetched if they are not known yet.
+func (c *HTTPclient) checkSupported(urlPath []string, fetch bool) error {
+	namespace := requestNamespace(urlPath)
+	if namespace == "" {
+		return nil
+	}
+	c.capabilities.mu.RLock()
+	capabilities := c.capabilities.capabilities
+	c.capabilities.mu.RUnlock()
+	if capabilities == nil {
+		if !fetch {
+			return nil
+		}
+		var err error
+		if capabilities, err = c.Capabilities(); err != nil {
+			// the capabilities are only used to tell the unsupported requests
+			return nil
+		}
+	}
+	if !slices.Contains(capabilities.Namespaces, namespace) {
+		return fmt.Errorf("%w: the %s API is not enabled", ErrNotSupported, namespace)
+	}
+	return nil
+}
+
+// requestNamespace returns the API namespace of the request path, or an empty
+// string if it is not one of the namespaces which a gateway can disable.
+func requestNamespace(urlPath []string) string {
+	p := strings.TrimPrefix(path.Join(urlPath...), "/")
+	if strings.HasPrefix(p, "chain/indexer/query") {
+		return api.IndexerQueryHandler
+	}
+	namespace, _, _ := strings.Cut(p, "/")
+	switch namespace {
+	case "siks":
+		return api.SIKHandler
+	case "files":
+		return api.ElectionHandler
+	}
+	if !slices.Contains(namespaces, namespace) {
+		return ""
+	}
+	return namespace
+}
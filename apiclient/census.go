@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"net/url"
 	"time"
 
 	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/censustree"
 	"go.vocdoni.io/dvote/httprouter/apirest"
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/proto/build/go/models"
@@ -48,19 +50,6 @@ func (c *HTTPclient) NewCensus(censusType string) (types.HexBytes, error) {
 	return censusData.CensusID, nil
 }
 
-// CensusAddParticipants adds one or several participants to an existing census.
-// The Key can be either the public key or address of the voter.
-func (c *HTTPclient) CensusAddParticipants(censusID types.HexBytes, participants *api.CensusParticipants) error {
-	resp, code, err := c.Request(HTTPPOST, &participants, "censuses", censusID.String(), "participants")
-	if err != nil {
-		return err
-	}
-	if code != apirest.HTTPstatusOK {
-		return fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	return nil
-}
-
 // CensusSize returns the number of participants in a census.
 func (c *HTTPclient) CensusSize(censusID types.HexBytes) (uint64, error) {
 	resp, code, err := c.Request(HTTPGET, nil, "censuses", censusID.String(), "size")
@@ -116,7 +105,22 @@ func (c *HTTPclient) CensusPublish(censusID types.HexBytes) (types.HexBytes, str
 
 // CensusGenProof generates a proof for a voter in a census. The voterKey is the public key or address of the voter.
 func (c *HTTPclient) CensusGenProof(censusID, voterKey types.HexBytes) (*CensusProof, error) {
-	resp, code, err := c.Request(HTTPGET, nil, "censuses", censusID.String(), "proof", voterKey.String())
+	return c.CensusGenProofWithKeyType(censusID, voterKey, censustree.KeyTypeAddress)
+}
+
+// CensusGenProofWithKeyType generates a proof for a voter enrolled in a census
+// with a key of the given type, such as an Ed25519 or P-256 public key. Public
+// keys are enrolled by the address derived from them, so the KeyType of the
+// resulting proof is models.ProofArbo_ADDRESS.
+func (c *HTTPclient) CensusGenProofWithKeyType(censusID, voterKey types.HexBytes, keyType censustree.KeyType) (*CensusProof, error) {
+	if cp := c.proofCache.censusProof(censusID, voterKey, keyType); cp != nil {
+		return cp, nil
+	}
+	var query url.Values
+	if keyType != censustree.KeyTypeAddress {
+		query = url.Values{api.ParamKeyType: []string{keyType.String()}}
+	}
+	resp, code, err := c.RequestWithQuery(HTTPGET, nil, query, "censuses", censusID.String(), "proof", voterKey.String())
 	if err != nil {
 		return nil, err
 	}
@@ -133,10 +137,14 @@ func (c *HTTPclient) CensusGenProof(censusID, voterKey types.HexBytes) (*CensusP
 		LeafValue: censusData.Value,
 		Siblings:  censusData.CensusSiblings,
 	}
+	if keyType != censustree.KeyTypeAddress {
+		cp.KeyType = models.ProofArbo_ADDRESS
+	}
 	if censusData.Weight != nil {
 		cp.LeafWeight = censusData.Weight.MathBigInt()
 	} else {
 		cp.LeafWeight = new(big.Int).SetUint64(1)
 	}
+	c.proofCache.putCensusProof(censusID, voterKey, keyType, &cp)
 	return &cp, nil
 }
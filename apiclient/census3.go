@@ -0,0 +1,233 @@
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
+)
+
+const (
+	// Census3TokenTypeERC20 is the census3 type of the ERC-20 tokens.
+	Census3TokenTypeERC20 = "erc20"
+	// Census3TokenTypeERC721 is the census3 type of the ERC-721 tokens.
+	Census3TokenTypeERC721 = "erc721"
+
+	// DefaultCensus3PollInterval is the default interval between the queries of
+	// the state of a census being created by the census3 service.
+	DefaultCensus3PollInterval = 2 * time.Second
+)
+
+// Census3Client is a client of the token-based census service (census3), which
+// builds the census of the holders of a token at a given block, weighted by
+// their balance and published so that it can be used by an election.
+type Census3Client struct {
+	c            *http.Client
+	addr         *url.URL
+	pollInterval time.Duration
+}
+
+// Census3Token is a token tracked by the census3 service.
+type Census3Token struct {
+	Address         common.Address `json:"ID"`
+	Type            string         `json:"type"`
+	ChainID         uint64         `json:"chainID"`
+	Name            string         `json:"name"`
+	Symbol          string         `json:"symbol"`
+	Decimals        uint64         `json:"decimals"`
+	StartBlock      uint64         `json:"startBlock"`
+	DefaultStrategy uint64         `json:"defaultStrategy"`
+	Status          struct {
+		AtBlock  uint64 `json:"atBlock"`
+		Synced   bool   `json:"synced"`
+		Progress int    `json:"progress"`
+	} `json:"status"`
+}
+
+// Census3Census is a census created by the census3 service.
+type Census3Census struct {
+	ID         uint64         `json:"ID"`
+	StrategyID uint64         `json:"strategyID"`
+	MerkleRoot types.HexBytes `json:"merkleRoot"`
+	URI        string         `json:"uri"`
+	Size       uint64         `json:"size"`
+	Weight     string         `json:"weight"`
+	Anonymous  bool           `json:"anonymous"`
+}
+
+// Description returns the census description to use in the ElectionDescription
+// of NewElection.
+func (cc *Census3Census) Description() api.CensusTypeDescription {
+	censusType := api.CensusTypeWeighted
+	if cc.Anonymous {
+		censusType = api.CensusTypeZKWeighted
+	}
+	return api.CensusTypeDescription{
+		Type:     censusType,
+		Size:     cc.Size,
+		URL:      cc.URI,
+		RootHash: cc.MerkleRoot,
+	}
+}
+
+// census3QueueResponse is the state of a census being created.
+type census3QueueResponse struct {
+	Done  bool `json:"done"`
+	Error *struct {
+		Code  int    `json:"code"`
+		Error string `json:"error"`
+	} `json:"error"`
+	Census   *Census3Census `json:"census"`
+	Progress int            `json:"progress"`
+}
+
+// NewCensus3Client returns a client of the census3 service at host, whose
+// address includes the API version path, e.g. https://census3.vocdoni.io/api.
+func NewCensus3Client(host string) (*Census3Client, error) {
+	addr, err := url.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	return &Census3Client{
+		c:            &http.Client{Timeout: DefaultTimeout},
+		addr:         addr,
+		pollInterval: DefaultCensus3PollInterval,
+	}, nil
+}
+
+// SetPollInterval configures the interval between the queries of the state of
+// a census being created.
+func (c *Census3Client) SetPollInterval(d time.Duration) {
+	c.pollInterval = d
+}
+
+// Token returns the token with the given address and chain ID, which must be
+// tracked by the census3 service.
+func (c *Census3Client) Token(address common.Address, chainID uint64) (*Census3Token, error) {
+	token := &Census3Token{}
+	query := url.Values{"chainID": []string{strconv.FormatUint(chainID, 10)}}
+	if err := c.request(http.MethodGet, nil, query, token, "tokens", address.Hex()); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// AddToken requests the census3 service to track the token with the given type,
+// address and chain ID, from startBlock. The token cannot be used until the
+// service scans its holders up to the block of the census.
+func (c *Census3Client) AddToken(tokenType string, address common.Address, chainID, startBlock uint64) error {
+	body := map[string]any{
+		"ID":         address.Hex(),
+		"type":       tokenType,
+		"chainID":    chainID,
+		"startBlock": startBlock,
+	}
+	return c.request(http.MethodPost, body, nil, nil, "tokens")
+}
+
+// NewTokenCensus creates the census of the holders of the token with the given
+// address and chain ID at blockNumber, using the default strategy of the token,
+// and waits until the census3 service publishes it or ctx is done. An anonymous
+// census can be used by anonymous elections. The token must be tracked by the
+// service (see AddToken) and scanned up to blockNumber.
+func (c *Census3Client) NewTokenCensus(ctx context.Context, address common.Address, chainID,
+	blockNumber uint64, anonymous bool,
+) (*Census3Census, error) {
+	token, err := c.Token(address, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if token.Status.AtBlock < blockNumber {
+		return nil, fmt.Errorf("token %s is scanned up to block %d, not %d yet",
+			address.Hex(), token.Status.AtBlock, blockNumber)
+	}
+	var queued struct {
+		QueueID string `json:"queueID"`
+	}
+	body := map[string]any{
+		"strategyID":  token.DefaultStrategy,
+		"blockNumber": blockNumber,
+		"anonymous":   anonymous,
+	}
+	if err := c.request(http.MethodPost, body, nil, &queued, "censuses"); err != nil {
+		return nil, err
+	}
+	if queued.QueueID == "" {
+		return nil, fmt.Errorf("census3 response is missing the queue ID")
+	}
+	log.Infow("census3 census requested", "token", address.Hex(), "chainID", chainID,
+		"block", blockNumber, "queueID", queued.QueueID)
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		queue := &census3QueueResponse{}
+		if err := c.request(http.MethodGet, nil, nil, queue, "censuses", "queue", queued.QueueID); err != nil {
+			return nil, err
+		}
+		if queue.Error != nil && queue.Error.Error != "" {
+			return nil, fmt.Errorf("census3 census creation failed: %s (%d)", queue.Error.Error, queue.Error.Code)
+		}
+		if queue.Done {
+			if queue.Census == nil || len(queue.Census.MerkleRoot) == 0 {
+				return nil, fmt.Errorf("census3 response is missing the census")
+			}
+			return queue.Census, nil
+		}
+		log.Debugw("waiting for census3 census", "queueID", queued.QueueID, "progress", queue.Progress)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// request sends a request to the census3 service and decodes its JSON response
+// into result, unless nil.
+func (c *Census3Client) request(method string, jsonBody any, query url.Values, result any, urlPath ...string) error {
+	u := *c.addr
+	u.Path = path.Join(u.Path, path.Join(urlPath...))
+	u.RawQuery = query.Encode()
+	var body io.Reader
+	if jsonBody != nil {
+		data, err := json.Marshal(jsonBody)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "Vocdoni API client / 1.0")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("census3 %s: %d (%s)", errCodeNot200, resp.StatusCode, data)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
@@ -0,0 +1,127 @@
+package apiclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/util"
+)
+
+func TestCensus3Client(t *testing.T) {
+	c := qt.New(t)
+	token := common.BytesToAddress(util.RandomBytes(20))
+	root := util.RandomBytes(32)
+	var requested map[string]any // the body of the last census request
+	polls := 0                   // the queries of the queue before the census is done
+	queueReply := ""             // the reply of the queue once done, the census if empty
+	var addedToken map[string]any
+	reply := func(w http.ResponseWriter, v any) {
+		c.Assert(json.NewEncoder(w).Encode(v), qt.IsNil)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/tokens/{address}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("address") != token.Hex() || r.URL.Query().Get("chainID") != "1" {
+			http.NotFound(w, r)
+			return
+		}
+		reply(w, map[string]any{
+			"ID": token.Hex(), "type": apiclient.Census3TokenTypeERC20, "chainID": 1,
+			"defaultStrategy": 7, "status": map[string]any{"atBlock": 100, "synced": true},
+		})
+	})
+	mux.HandleFunc("POST /api/censuses", func(w http.ResponseWriter, r *http.Request) {
+		requested = nil
+		if err := json.NewDecoder(r.Body).Decode(&requested); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		polls = 2
+		if queueReply == "no queue" {
+			reply(w, map[string]any{})
+			return
+		}
+		reply(w, map[string]any{"queueID": "queue"})
+	})
+	mux.HandleFunc("POST /api/tokens", func(w http.ResponseWriter, r *http.Request) {
+		c.Assert(json.NewDecoder(r.Body).Decode(&addedToken), qt.IsNil)
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("GET /api/censuses/queue/queue", func(w http.ResponseWriter, _ *http.Request) {
+		switch {
+		case queueReply == "error":
+			reply(w, map[string]any{"done": true, "error": map[string]any{"code": 6000, "error": "no holders"}})
+		case queueReply == "lost":
+			http.Error(w, "queue item not found", http.StatusInternalServerError)
+		case polls > 0:
+			polls--
+			reply(w, map[string]any{"done": false, "progress": 50})
+		case queueReply == "no census":
+			reply(w, map[string]any{"done": true, "census": &apiclient.Census3Census{ID: 1}})
+		default:
+			reply(w, map[string]any{"done": true, "census": &apiclient.Census3Census{
+				ID: 1, StrategyID: 7, MerkleRoot: root, URI: "ipfs://census", Size: 10,
+				Anonymous: requested["anonymous"].(bool),
+			}})
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	cli, err := apiclient.NewCensus3Client(srv.URL + "/api")
+	c.Assert(err, qt.IsNil)
+	cli.SetPollInterval(time.Millisecond)
+	ctx := context.Background()
+
+	// the census is polled until it is published
+	census, err := cli.NewTokenCensus(ctx, token, 1, 100, true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(requested, qt.DeepEquals, map[string]any{"strategyID": 7.0, "blockNumber": 100.0, "anonymous": true})
+	c.Assert(polls, qt.Equals, 0)
+	c.Assert(census.Description(), qt.DeepEquals, api.CensusTypeDescription{
+		Type: api.CensusTypeZKWeighted, Size: 10, URL: "ipfs://census", RootHash: root,
+	})
+	census, err = cli.NewTokenCensus(ctx, token, 1, 90, false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(census.Description().Type, qt.Equals, api.CensusTypeWeighted)
+
+	// the token must be scanned up to the block of the census
+	_, err = cli.NewTokenCensus(ctx, token, 1, 101, false)
+	c.Assert(err, qt.ErrorMatches, "token .* is scanned up to block 100, not 101 yet")
+	_, err = cli.NewTokenCensus(ctx, token, 2, 100, false)
+	c.Assert(err, qt.ErrorMatches, "(?s)census3 .*: 404 .*")
+
+	// the failures of the queue are reported, not waited for
+	for _, tc := range []struct{ queueReply, err string }{
+		{"error", `census3 census creation failed: no holders \(6000\)`},
+		{"lost", "(?s)census3 .*: 500 .*queue item not found.*"},
+		{"no queue", "census3 response is missing the queue ID"},
+		{"no census", "census3 response is missing the census"},
+	} {
+		queueReply = tc.queueReply
+		_, err = cli.NewTokenCensus(ctx, token, 1, 100, false)
+		c.Assert(err, qt.ErrorMatches, tc.err)
+	}
+
+	// the census is not waited for longer than the context
+	queueReply = ""
+	cli.SetPollInterval(time.Hour)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = cli.NewTokenCensus(ctx, token, 1, 100, false)
+	c.Assert(err, qt.ErrorIs, context.DeadlineExceeded)
+
+	// the tokens are added from a block, to be scanned by the service
+	c.Assert(cli.AddToken(apiclient.Census3TokenTypeERC721, token, 5, 1000), qt.IsNil)
+	c.Assert(addedToken, qt.DeepEquals, map[string]any{
+		"ID": token.Hex(), "type": apiclient.Census3TokenTypeERC721, "chainID": 5.0, "startBlock": 1000.0,
+	})
+	_, err = apiclient.NewCensus3Client("://census3")
+	c.Assert(err, qt.Not(qt.IsNil))
+}
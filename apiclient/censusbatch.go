@@ -0,0 +1,186 @@
+package apiclient
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/httprouter/apirest"
+	"go.vocdoni.io/dvote/types"
+)
+
+// DefaultCensusAddConcurrency is the default number of chunks of participants
+// submitted at once by CensusAddParticipants.
+const DefaultCensusAddConcurrency = 4
+
+// CensusAddOptions configures how CensusAddParticipantsWithOptions splits the
+// participants into chunks and submits them. The zero value of each field
+// keeps the default behavior.
+type CensusAddOptions struct {
+	// ChunkSize is the maximum number of participants of each request, at
+	// most api.MaxCensusAddBatchSize, which is also the default.
+	ChunkSize int
+	// Concurrency is the maximum number of chunks submitted at once, by
+	// default DefaultCensusAddConcurrency.
+	Concurrency int
+	// Retry is the retry policy of each chunk, by default DefaultRetryPolicy.
+	// The chunks are retried when the request fails or the API server returns
+	// a 5xx status, since adding a participant twice is harmless.
+	Retry *RetryPolicy
+	// Progress, if not nil, is called after each chunk is added with the
+	// number of participants added so far and the total. It is called from
+	// the goroutines submitting the chunks, one call at a time.
+	Progress func(added, total int)
+}
+
+// CensusChunkError is the error of a chunk of participants which could not be
+// added to the census, after all its attempts.
+type CensusChunkError struct {
+	// Index is the index of the chunk, starting at zero.
+	Index int
+	// From and To delimit the participants of the chunk, as in
+	// Participants[From:To].
+	From, To int
+	// Err is the error of the last attempt.
+	Err error
+}
+
+func (e *CensusChunkError) Error() string {
+	return fmt.Sprintf("chunk %d (participants %d to %d): %v", e.Index, e.From, e.To-1, e.Err)
+}
+
+func (e *CensusChunkError) Unwrap() error {
+	return e.Err
+}
+
+// CensusAddError is returned by CensusAddParticipants when some chunks of
+// participants could not be added. The rest of the chunks were added, so only
+// the failed ones need to be submitted again.
+type CensusAddError struct {
+	// Chunks are the failed chunks, sorted by index.
+	Chunks []*CensusChunkError
+	// Total is the number of chunks the participants were split into.
+	Total int
+}
+
+func (e *CensusAddError) Error() string {
+	errs := make([]string, len(e.Chunks))
+	for i, chunk := range e.Chunks {
+		errs[i] = chunk.Error()
+	}
+	return fmt.Sprintf("could not add %d of %d chunks of participants: %s",
+		len(e.Chunks), e.Total, strings.Join(errs, "; "))
+}
+
+func (e *CensusAddError) Unwrap() []error {
+	errs := make([]error, len(e.Chunks))
+	for i, chunk := range e.Chunks {
+		errs[i] = chunk
+	}
+	return errs
+}
+
+// CensusAddParticipants adds one or several participants to an existing census.
+// The Key can be either the public key or address of the voter.
+// Large lists of participants are split into chunks of api.MaxCensusAddBatchSize
+// participants, submitted concurrently and retried with the default options,
+// see CensusAddParticipantsWithOptions.
+func (c *HTTPclient) CensusAddParticipants(censusID types.HexBytes, participants *api.CensusParticipants) error {
+	return c.CensusAddParticipantsWithOptions(censusID, participants, CensusAddOptions{})
+}
+
+// CensusAddParticipantsWithOptions adds the participants to an existing census
+// as CensusAddParticipants, splitting them into chunks submitted with bounded
+// concurrency and retries as configured by opts. If some chunks fail, the
+// returned error is a *CensusAddError with the error of each of them.
+func (c *HTTPclient) CensusAddParticipantsWithOptions(censusID types.HexBytes,
+	participants *api.CensusParticipants, opts CensusAddOptions,
+) error {
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 || chunkSize > api.MaxCensusAddBatchSize {
+		chunkSize = api.MaxCensusAddBatchSize
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultCensusAddConcurrency
+	}
+	retry := DefaultRetryPolicy
+	if opts.Retry != nil {
+		retry = *opts.Retry
+	}
+
+	total := len(participants.Participants)
+	if total == 0 {
+		// let the API server report the missing participants
+		return c.censusAddChunk(censusID, nil, &retry)
+	}
+
+	chunks := make(chan *CensusChunkError)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		added  int
+		failed []*CensusChunkError
+	)
+	for range min(concurrency, (total+chunkSize-1)/chunkSize) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunks {
+				err := c.censusAddChunk(censusID, participants.Participants[chunk.From:chunk.To], &retry)
+				mu.Lock()
+				if err != nil {
+					chunk.Err = err
+					failed = append(failed, chunk)
+				} else {
+					added += chunk.To - chunk.From
+					if opts.Progress != nil {
+						opts.Progress(added, total)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	count := 0
+	for from := 0; from < total; from += chunkSize {
+		chunks <- &CensusChunkError{Index: count, From: from, To: min(from+chunkSize, total)}
+		count++
+	}
+	close(chunks)
+	wg.Wait()
+
+	if len(failed) > 0 {
+		slices.SortFunc(failed, func(a, b *CensusChunkError) int { return a.Index - b.Index })
+		return &CensusAddError{Chunks: failed, Total: count}
+	}
+	return nil
+}
+
+// censusAddChunk adds a chunk of participants to the census, retrying it as
+// configured by the retry policy while the request fails or the API server
+// returns a 5xx status.
+func (c *HTTPclient) censusAddChunk(censusID types.HexBytes, participants []api.CensusParticipant,
+	retry *RetryPolicy,
+) error {
+	body := &api.CensusParticipants{Participants: participants}
+	for attempt := 1; ; attempt++ {
+		resp, code, err := c.Request(HTTPPOST, body, "censuses", censusID.String(), "participants")
+		if err == nil && code == apirest.HTTPstatusOK {
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
+			if code < 500 {
+				return err
+			}
+		}
+		if attempt >= retry.MaxAttempts {
+			return err
+		}
+		time.Sleep(retry.backoff(attempt))
+	}
+}
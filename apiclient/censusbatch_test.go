@@ -0,0 +1,237 @@
+package apiclient_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+)
+
+// participantsServer is an API server which adds the participants to the
+// censuses, and fails the chunks whose first participant is set to fail.
+type participantsServer struct {
+	mu       sync.Mutex
+	added    map[string]bool
+	requests int
+	sizes    []int
+	// inFlight and maxInFlight are the chunks being added, now and at most
+	inFlight, maxInFlight int
+	// fail maps the first participant of a chunk to the status code of its
+	// failures, and failures to the number of them, or -1 to always fail
+	fail     map[string]int
+	failures map[string]int
+	url      string
+}
+
+func newParticipantsServer(t *testing.T) *participantsServer {
+	s := &participantsServer{
+		added:    make(map[string]bool),
+		fail:     make(map[string]int),
+		failures: make(map[string]int),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /censuses/{censusId}/participants", func(w http.ResponseWriter, r *http.Request) {
+		participants := &api.CensusParticipants{}
+		if err := json.NewDecoder(r.Body).Decode(participants); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.requests++
+		s.inFlight++
+		s.maxInFlight = max(s.maxInFlight, s.inFlight)
+		s.mu.Unlock()
+		// give the other chunks the time to be sent
+		time.Sleep(5 * time.Millisecond)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.inFlight--
+		if len(participants.Participants) == 0 {
+			http.Error(w, "no participants", http.StatusBadRequest)
+			return
+		}
+		first := participants.Participants[0].Key.String()
+		if code, ok := s.fail[first]; ok && s.failures[first] != 0 {
+			s.failures[first]--
+			http.Error(w, "cannot add "+first, code)
+			return
+		}
+		s.sizes = append(s.sizes, len(participants.Participants))
+		for _, p := range participants.Participants {
+			s.added[p.Key.String()] = true
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	s.url = srv.URL + "/"
+	return s
+}
+
+// failChunk makes the chunk starting with key fail the given times with the
+// status code, or always if times is -1.
+func (s *participantsServer) failChunk(key types.HexBytes, code, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail[key.String()] = code
+	s.failures[key.String()] = times
+}
+
+// reset forgets the participants added, the requests and the failures.
+func (s *participantsServer) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.added)
+	clear(s.fail)
+	clear(s.failures)
+	s.requests, s.sizes, s.maxInFlight = 0, nil, 0
+}
+
+func newParticipants(n int) *api.CensusParticipants {
+	participants := &api.CensusParticipants{}
+	for range n {
+		participants.Participants = append(participants.Participants, api.CensusParticipant{Key: util.RandomBytes(20)})
+	}
+	return participants
+}
+
+func TestCensusAddParticipants(t *testing.T) {
+	c := qt.New(t)
+	s := newParticipantsServer(t)
+	cli, err := apiclient.New(s.url)
+	c.Assert(err, qt.IsNil)
+	censusID := types.HexBytes(util.RandomBytes(32))
+	retry := &apiclient.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	participants := newParticipants(10)
+
+	// the participants are split into chunks of the given size, the last one
+	// with the rest, and submitted at most concurrency at once
+	var progress []int
+	err = cli.CensusAddParticipantsWithOptions(censusID, participants, apiclient.CensusAddOptions{
+		ChunkSize:   3,
+		Concurrency: 2,
+		Progress: func(added, total int) {
+			c.Check(total, qt.Equals, 10)
+			progress = append(progress, added)
+		},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(s.added, qt.HasLen, 10)
+	c.Assert(s.requests, qt.Equals, 4)
+	c.Assert(s.maxInFlight, qt.Equals, 2)
+	c.Assert(progress, qt.HasLen, 4)
+	for i := 1; i < len(progress); i++ {
+		c.Assert(progress[i] > progress[i-1], qt.IsTrue)
+	}
+	c.Assert(progress[3], qt.Equals, 10)
+	sizes := map[int]int{}
+	for _, size := range s.sizes {
+		sizes[size]++
+	}
+	c.Assert(sizes, qt.DeepEquals, map[int]int{3: 3, 1: 1})
+
+	// the chunks which fail with a server error are retried, whatever its status
+	for _, code := range []int{http.StatusInternalServerError, http.StatusBadGateway} {
+		s.reset()
+		s.failChunk(participants.Participants[3].Key, code, 1)
+		err = cli.CensusAddParticipantsWithOptions(censusID, participants, apiclient.CensusAddOptions{
+			ChunkSize: 3, Retry: retry,
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(s.added, qt.HasLen, 10)
+		c.Assert(s.requests, qt.Equals, 5)
+	}
+
+	// the chunks which keep failing are reported sorted, and the rest are added
+	s.reset()
+	s.failChunk(participants.Participants[9].Key, http.StatusInternalServerError, -1)
+	s.failChunk(participants.Participants[3].Key, http.StatusInternalServerError, -1)
+	progress = nil
+	err = cli.CensusAddParticipantsWithOptions(censusID, participants, apiclient.CensusAddOptions{
+		ChunkSize: 3, Retry: retry,
+		Progress: func(added, _ int) { progress = append(progress, added) },
+	})
+	var addErr *apiclient.CensusAddError
+	c.Assert(errors.As(err, &addErr), qt.IsTrue, qt.Commentf("error: %v", err))
+	c.Assert(addErr.Total, qt.Equals, 4)
+	c.Assert(addErr.Chunks, qt.HasLen, 2)
+	c.Assert([]int{addErr.Chunks[0].Index, addErr.Chunks[1].Index}, qt.DeepEquals, []int{1, 3})
+	c.Assert([]int{addErr.Chunks[1].From, addErr.Chunks[1].To}, qt.DeepEquals, []int{9, 10})
+	c.Assert(err, qt.ErrorMatches, `(?s)could not add 2 of 4 chunks of participants: `+
+		`chunk 1 \(participants 3 to 5\): .*500.*; chunk 3 \(participants 9 to 9\): .*500.*`)
+	var chunkErr *apiclient.CensusChunkError
+	c.Assert(errors.As(err, &chunkErr), qt.IsTrue)
+	c.Assert(chunkErr.Index, qt.Equals, 1)
+	c.Assert(s.requests, qt.Equals, 6)
+	c.Assert(s.added, qt.HasLen, 6)
+	c.Assert(progress[len(progress)-1], qt.Equals, 6)
+
+	// the chunks rejected by the API server are not retried
+	s.reset()
+	s.failChunk(participants.Participants[0].Key, http.StatusBadRequest, -1)
+	err = cli.CensusAddParticipantsWithOptions(censusID, participants, apiclient.CensusAddOptions{
+		ChunkSize: 5, Retry: retry,
+	})
+	c.Assert(err, qt.ErrorMatches, `(?s)could not add 1 of 2 chunks of participants: chunk 0 \(participants 0 to 4\): .*400.*`)
+	c.Assert(s.requests, qt.Equals, 2)
+	c.Assert(s.added, qt.HasLen, 5)
+
+	// nor the request without participants
+	s.reset()
+	err = cli.CensusAddParticipants(censusID, &api.CensusParticipants{})
+	c.Assert(err, qt.ErrorMatches, "(?s).*400.*no participants.*")
+	c.Assert(s.requests, qt.Equals, 1)
+}
+
+func TestCensusAddParticipantsChunkSize(t *testing.T) {
+	c := qt.New(t)
+	s := newParticipantsServer(t)
+	cli, err := apiclient.New(s.url)
+	c.Assert(err, qt.IsNil)
+	censusID := types.HexBytes(util.RandomBytes(32))
+
+	// the chunks are not larger than the API server accepts
+	participants := newParticipants(api.MaxCensusAddBatchSize + 1)
+	for _, size := range []int{0, -1, api.MaxCensusAddBatchSize * 2} {
+		s.reset()
+		err = cli.CensusAddParticipantsWithOptions(censusID, participants, apiclient.CensusAddOptions{ChunkSize: size})
+		c.Assert(err, qt.IsNil)
+		c.Assert(s.requests, qt.Equals, 2)
+		c.Assert(s.added, qt.HasLen, api.MaxCensusAddBatchSize+1)
+	}
+
+	// and a census smaller than a chunk is added at once
+	s.reset()
+	c.Assert(cli.CensusAddParticipants(censusID, newParticipants(7)), qt.IsNil)
+	c.Assert(s.requests, qt.Equals, 1)
+	c.Assert(s.sizes, qt.DeepEquals, []int{7})
+}
+
+func TestCensusAddParticipantsUnreachable(t *testing.T) {
+	c := qt.New(t)
+	srv := httptest.NewServer(http.NotFoundHandler())
+	cli, err := apiclient.New(srv.URL + "/")
+	c.Assert(err, qt.IsNil)
+	srv.Close()
+
+	// the chunks which cannot be sent are retried, and reported
+	var called bool
+	err = cli.CensusAddParticipantsWithOptions(util.RandomBytes(32), newParticipants(4), apiclient.CensusAddOptions{
+		ChunkSize: 2,
+		Retry:     &apiclient.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		Progress:  func(int, int) { called = true },
+	})
+	var addErr *apiclient.CensusAddError
+	c.Assert(errors.As(err, &addErr), qt.IsTrue, qt.Commentf("error: %v", err))
+	c.Assert(addErr.Chunks, qt.HasLen, 2)
+	c.Assert(err, qt.ErrorMatches, "(?s).*connection refused.*")
+	c.Assert(called, qt.IsFalse)
+}
@@ -0,0 +1,204 @@
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
+)
+
+// DefaultChainCheckInterval is the default interval between the verifications
+// of the chain started by StartChainCheck.
+const DefaultChainCheckInterval = time.Minute
+
+// ErrChainMismatch is returned when the chain the API server is connected to is
+// not the expected one. Once detected, the client refuses to sign transactions
+// until a later verification succeeds.
+var ErrChainMismatch = errors.New("chain mismatch")
+
+// ChainCheckpoint is a block known to be part of the expected chain.
+type ChainCheckpoint struct {
+	Height uint32
+	Hash   types.HexBytes
+}
+
+// ChainCheck describes the chain the API server must be connected to. Empty
+// fields are not verified.
+type ChainCheck struct {
+	// ChainID is the expected chain identifier. If empty, the one reported by
+	// the API server when the client was created is expected.
+	ChainID string
+	// GenesisHash is the expected hash of the genesis document.
+	GenesisHash types.HexBytes
+	// Checkpoints are blocks that must be part of the chain. The ones not yet
+	// reached, or already pruned by the API server, are skipped.
+	Checkpoints []ChainCheckpoint
+	// Reference is a client of a second API server of the same network, which
+	// must report the same chain ID and genesis hash, and the same block hash at
+	// the latest height known by both servers.
+	Reference *HTTPclient
+	// Interval is the time between verifications, DefaultChainCheckInterval
+	// if zero.
+	Interval time.Duration
+}
+
+// chainChecker holds the result of the last chain verification. It is shared
+// by the clones of the client.
+type chainChecker struct {
+	mu     sync.RWMutex
+	cancel context.CancelFunc
+	err    error
+}
+
+// StartChainCheck verifies the chain the API server is connected to and keeps
+// verifying it periodically in the background, until ctx is done or the check is
+// stopped with StopChainCheck. While the last verification reports
+// ErrChainMismatch, the client refuses to sign transactions. It returns the
+// result of the first verification.
+func (c *HTTPclient) StartChainCheck(ctx context.Context, check *ChainCheck) error {
+	if check == nil {
+		return fmt.Errorf("missing chain check")
+	}
+	interval := check.Interval
+	if interval <= 0 {
+		interval = DefaultChainCheckInterval
+	}
+	c.StopChainCheck()
+	ctx, cancel := context.WithCancel(ctx)
+	c.chainCheck.mu.Lock()
+	c.chainCheck.cancel = cancel
+	c.chainCheck.mu.Unlock()
+
+	err := c.runChainCheck(check)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.runChainCheck(check); err != nil {
+					log.Warnw("chain check failed", "host", c.addr.String(), "err", err)
+				}
+			}
+		}
+	}()
+	return err
+}
+
+// StopChainCheck stops the periodic verification of the chain, and forgets the
+// result of the last one.
+func (c *HTTPclient) StopChainCheck() {
+	c.chainCheck.mu.Lock()
+	defer c.chainCheck.mu.Unlock()
+	if c.chainCheck.cancel != nil {
+		c.chainCheck.cancel()
+		c.chainCheck.cancel = nil
+	}
+	c.chainCheck.err = nil
+}
+
+// ChainCheckError returns the ErrChainMismatch found by the last verification
+// of the chain, or nil if it succeeded or no verification was done.
+func (c *HTTPclient) ChainCheckError() error {
+	c.chainCheck.mu.RLock()
+	defer c.chainCheck.mu.RUnlock()
+	return c.chainCheck.err
+}
+
+// runChainCheck runs VerifyChain and records its result. Errors which are not a
+// chain mismatch, such as the API server being unreachable, are returned but do
+// not change the recorded result.
+func (c *HTTPclient) runChainCheck(check *ChainCheck) error {
+	err := c.VerifyChain(check)
+	if err == nil || errors.Is(err, ErrChainMismatch) {
+		c.chainCheck.mu.Lock()
+		c.chainCheck.err = err
+		c.chainCheck.mu.Unlock()
+	}
+	return err
+}
+
+// VerifyChain verifies once that the API server is connected to the chain
+// described by check, returning ErrChainMismatch if it is not.
+func (c *HTTPclient) VerifyChain(check *ChainCheck) error {
+	info, err := c.ChainInfo()
+	if err != nil {
+		return fmt.Errorf("cannot get chain info: %w", err)
+	}
+	chainID := check.ChainID
+	if chainID == "" {
+		chainID = c.ChainID()
+	}
+	if info.ID != chainID {
+		return fmt.Errorf("%w: chain ID is %q, expected %q", ErrChainMismatch, info.ID, chainID)
+	}
+	if check.GenesisHash != nil && !bytes.Equal(info.GenesisHash, check.GenesisHash) {
+		return fmt.Errorf("%w: genesis hash is %x, expected %x", ErrChainMismatch, info.GenesisHash, check.GenesisHash)
+	}
+	for _, cp := range check.Checkpoints {
+		if cp.Height > info.Height || cp.Height < info.BlockStoreBase {
+			continue
+		}
+		if err := c.verifyBlockHash(cp.Height, cp.Hash); err != nil {
+			return err
+		}
+	}
+	if check.Reference != nil {
+		return c.verifyReference(check.Reference, info)
+	}
+	return nil
+}
+
+// verifyReference compares the chain of the API server, described by info,
+// with the one of the reference API server.
+func (c *HTTPclient) verifyReference(ref *HTTPclient, info *api.ChainInfo) error {
+	refInfo, err := ref.ChainInfo()
+	if err != nil {
+		return fmt.Errorf("cannot get reference chain info: %w", err)
+	}
+	if info.ID != refInfo.ID {
+		return fmt.Errorf("%w: chain ID is %q, the reference is %q", ErrChainMismatch, info.ID, refInfo.ID)
+	}
+	if !bytes.Equal(info.GenesisHash, refInfo.GenesisHash) {
+		return fmt.Errorf("%w: genesis hash is %x, the reference is %x",
+			ErrChainMismatch, info.GenesisHash, refInfo.GenesisHash)
+	}
+	height := min(info.Height, refInfo.Height)
+	if height == 0 {
+		return nil
+	}
+	refBlock, err := ref.Block(height)
+	if err != nil {
+		return fmt.Errorf("cannot get reference block %d: %w", height, err)
+	}
+	return c.verifyBlockHash(height, refBlock.Hash)
+}
+
+// verifyBlockHash checks that the block at height has the given hash.
+func (c *HTTPclient) verifyBlockHash(height uint32, hash types.HexBytes) error {
+	block, err := c.Block(height)
+	if err != nil {
+		return fmt.Errorf("cannot get block %d: %w", height, err)
+	}
+	if !bytes.Equal(block.Hash, hash) {
+		return fmt.Errorf("%w: block %d hash is %x, expected %x", ErrChainMismatch, height, block.Hash, hash)
+	}
+	return nil
+}
+
+// signVocdoniTx signs the transaction for the chain ID of the client, unless
+// the last verification of the chain found a mismatch.
+func (c *HTTPclient) signVocdoniTx(marshaledTx []byte) ([]byte, error) {
+	if err := c.ChainCheckError(); err != nil {
+		return nil, fmt.Errorf("refusing to sign: %w", err)
+	}
+	return c.account.SignVocdoniTx(marshaledTx, c.ChainID())
+}
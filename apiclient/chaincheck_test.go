@@ -0,0 +1,271 @@
+package apiclient_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// testChain is an API server of a chain whose info and blocks can be changed
+// by the test, to fake forks, pruned blocks and outages.
+type testChain struct {
+	mu     sync.Mutex
+	info   api.ChainInfo
+	blocks map[uint32]types.HexBytes
+	down   bool
+	txs    int
+	url    string
+}
+
+// newTestChain starts the API server of a chain with the given ID and height,
+// whose blocks are stored since the first one.
+func newTestChain(t *testing.T, chainID string, height uint32) *testChain {
+	tc := &testChain{
+		info:   api.ChainInfo{ID: chainID, GenesisHash: util.RandomBytes(32), Height: height, BlockStoreBase: 1},
+		blocks: make(map[uint32]types.HexBytes),
+	}
+	for h := uint32(1); h <= height; h++ {
+		tc.blocks[h] = util.RandomBytes(32)
+	}
+	reply := func(w http.ResponseWriter, v any) {
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Error(err)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /chain/info", func(w http.ResponseWriter, _ *http.Request) {
+		reply(w, tc.chainInfo())
+	})
+	mux.HandleFunc("GET /chain/blocks/{height}", func(w http.ResponseWriter, r *http.Request) {
+		height, err := strconv.ParseUint(r.PathValue("height"), 10, 32)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tc.mu.Lock()
+		hash, ok := tc.blocks[uint32(height)]
+		tc.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		reply(w, &api.Block{Hash: hash})
+	})
+	mux.HandleFunc("POST /chain/transactions", func(w http.ResponseWriter, _ *http.Request) {
+		tc.mu.Lock()
+		tc.txs++
+		tc.mu.Unlock()
+		reply(w, &api.Transaction{Hash: util.RandomBytes(32)})
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc.mu.Lock()
+		down := tc.down
+		tc.mu.Unlock()
+		if down {
+			http.Error(w, "unavailable", http.StatusBadGateway)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	tc.url = srv.URL + "/"
+	return tc
+}
+
+func (tc *testChain) chainInfo() *api.ChainInfo {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	info := tc.info
+	return &info
+}
+
+// client returns a client of the chain, with the given private key if any.
+func (tc *testChain) client(t *testing.T, privKey string) *apiclient.HTTPclient {
+	cli, err := apiclient.New(tc.url)
+	qt.Assert(t, err, qt.IsNil)
+	if privKey != "" {
+		qt.Assert(t, cli.SetAccount(privKey), qt.IsNil)
+	}
+	return cli
+}
+
+func TestVerifyChain(t *testing.T) {
+	c := qt.New(t)
+	chain := newTestChain(t, "test", 10)
+	cli := chain.client(t, "")
+
+	// the chain ID reported when the client was created is expected by default
+	c.Assert(cli.VerifyChain(&apiclient.ChainCheck{}), qt.IsNil)
+	c.Assert(cli.VerifyChain(&apiclient.ChainCheck{ChainID: "test"}), qt.IsNil)
+	c.Assert(cli.VerifyChain(&apiclient.ChainCheck{ChainID: "other"}), qt.ErrorIs, apiclient.ErrChainMismatch)
+	c.Assert(cli.VerifyChain(&apiclient.ChainCheck{GenesisHash: chain.info.GenesisHash}), qt.IsNil)
+	c.Assert(cli.VerifyChain(&apiclient.ChainCheck{GenesisHash: util.RandomBytes(32)}),
+		qt.ErrorIs, apiclient.ErrChainMismatch)
+
+	// the checkpoints not reached yet, or pruned, are skipped
+	c.Assert(cli.VerifyChain(&apiclient.ChainCheck{Checkpoints: []apiclient.ChainCheckpoint{
+		{Height: 10, Hash: chain.blocks[10]},
+		{Height: 11, Hash: util.RandomBytes(32)},
+	}}), qt.IsNil)
+	err := cli.VerifyChain(&apiclient.ChainCheck{Checkpoints: []apiclient.ChainCheckpoint{
+		{Height: 3, Hash: chain.blocks[3]},
+		{Height: 4, Hash: chain.blocks[3]},
+	}})
+	c.Assert(err, qt.ErrorIs, apiclient.ErrChainMismatch)
+	c.Assert(err, qt.ErrorMatches, "chain mismatch: block 4 hash is .*")
+	chain.mu.Lock()
+	chain.info.BlockStoreBase = 5
+	chain.mu.Unlock()
+	c.Assert(cli.VerifyChain(&apiclient.ChainCheck{Checkpoints: []apiclient.ChainCheckpoint{
+		{Height: 4, Hash: util.RandomBytes(32)},
+	}}), qt.IsNil)
+
+	// the errors reaching the server are not a mismatch
+	chain.mu.Lock()
+	chain.down = true
+	chain.mu.Unlock()
+	err = cli.VerifyChain(&apiclient.ChainCheck{})
+	c.Assert(err, qt.ErrorMatches, "(?s)cannot get chain info: .*502.*")
+	c.Assert(err, qt.Not(qt.ErrorIs), apiclient.ErrChainMismatch)
+	chain.mu.Lock()
+	chain.down = false
+	chain.mu.Unlock()
+}
+
+func TestVerifyChainReference(t *testing.T) {
+	c := qt.New(t)
+	chain := newTestChain(t, "test", 10)
+	cli := chain.client(t, "")
+
+	// a reference behind the server is compared at its own height
+	behind := newTestChain(t, "test", 6)
+	behind.info.GenesisHash = chain.info.GenesisHash
+	for h := range behind.blocks {
+		behind.blocks[h] = chain.blocks[h]
+	}
+	c.Assert(cli.VerifyChain(&apiclient.ChainCheck{Reference: behind.client(t, "")}), qt.IsNil)
+	behind.mu.Lock()
+	behind.blocks[6] = util.RandomBytes(32)
+	behind.mu.Unlock()
+	err := cli.VerifyChain(&apiclient.ChainCheck{Reference: behind.client(t, "")})
+	c.Assert(err, qt.ErrorMatches, "chain mismatch: block 6 hash is .*")
+
+	// a reference without blocks cannot tell, but one of other chain or genesis is a mismatch
+	empty := newTestChain(t, "test", 0)
+	empty.info.GenesisHash = chain.info.GenesisHash
+	c.Assert(cli.VerifyChain(&apiclient.ChainCheck{Reference: empty.client(t, "")}), qt.IsNil)
+	err = cli.VerifyChain(&apiclient.ChainCheck{Reference: newTestChain(t, "other", 10).client(t, "")})
+	c.Assert(err, qt.ErrorMatches, `chain mismatch: chain ID is "test", the reference is "other"`)
+	err = cli.VerifyChain(&apiclient.ChainCheck{Reference: newTestChain(t, "test", 10).client(t, "")})
+	c.Assert(err, qt.ErrorMatches, "chain mismatch: genesis hash is .*, the reference is .*")
+
+	// an unreachable reference is not a mismatch
+	down := newTestChain(t, "test", 10)
+	ref := down.client(t, "")
+	down.mu.Lock()
+	down.down = true
+	down.mu.Unlock()
+	err = cli.VerifyChain(&apiclient.ChainCheck{Reference: ref})
+	c.Assert(err, qt.ErrorMatches, "(?s)cannot get reference chain info: .*502.*")
+	c.Assert(err, qt.Not(qt.ErrorIs), apiclient.ErrChainMismatch)
+}
+
+func TestChainCheckRefusesToSign(t *testing.T) {
+	c := qt.New(t)
+	chain := newTestChain(t, "test", 10)
+	key := ethereum.NewSignKeys()
+	c.Assert(key.Generate(), qt.IsNil)
+	cli := chain.client(t, hex.EncodeToString(key.PrivateKey()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.Assert(cli.StartChainCheck(ctx, nil), qt.ErrorMatches, "missing chain check")
+	tx, err := proto.Marshal(&models.Tx{Payload: &models.Tx_SetProcess{SetProcess: &models.SetProcessTx{
+		Txtype: models.TxType_SET_PROCESS_STATUS, ProcessId: util.RandomBytes(32),
+	}}})
+	c.Assert(err, qt.IsNil)
+
+	// the transactions are not signed while the chain is not the expected one,
+	// by the client nor its clones
+	err = cli.StartChainCheck(ctx, &apiclient.ChainCheck{ChainID: "other"})
+	c.Assert(err, qt.ErrorIs, apiclient.ErrChainMismatch)
+	c.Assert(cli.ChainCheckError(), qt.ErrorIs, apiclient.ErrChainMismatch)
+	_, _, err = cli.SignAndSendTx(tx)
+	c.Assert(err, qt.ErrorMatches, "refusing to sign: chain mismatch: .*")
+	clone := cli.Clone(hex.EncodeToString(key.PrivateKey()))
+	_, _, err = clone.SignAndSendTx(tx)
+	c.Assert(err, qt.ErrorIs, apiclient.ErrChainMismatch)
+	c.Assert(chain.txs, qt.Equals, 0)
+
+	// stopping the check forgets its result
+	cli.StopChainCheck()
+	c.Assert(cli.ChainCheckError(), qt.IsNil)
+	_, _, err = cli.SignAndSendTx(tx)
+	c.Assert(err, qt.IsNil)
+	c.Assert(chain.txs, qt.Equals, 1)
+}
+
+func TestChainCheckPeriodic(t *testing.T) {
+	c := qt.New(t)
+	chain := newTestChain(t, "test", 10)
+	cli := chain.client(t, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	waitFor := func(cond func(error) bool) {
+		c.Helper()
+		for start := time.Now(); !cond(cli.ChainCheckError()); time.Sleep(10 * time.Millisecond) {
+			if time.Since(start) > 5*time.Second {
+				c.Fatalf("chain check error is still %v", cli.ChainCheckError())
+			}
+		}
+	}
+
+	check := &apiclient.ChainCheck{
+		Checkpoints: []apiclient.ChainCheckpoint{{Height: 5, Hash: chain.blocks[5]}},
+		Interval:    20 * time.Millisecond,
+	}
+	c.Assert(cli.StartChainCheck(ctx, check), qt.IsNil)
+
+	// a reorganization of the checkpoint is found by a later verification
+	chain.mu.Lock()
+	hash := chain.blocks[5]
+	chain.blocks[5] = util.RandomBytes(32)
+	chain.mu.Unlock()
+	waitFor(func(err error) bool { return err != nil })
+	c.Assert(cli.ChainCheckError(), qt.ErrorIs, apiclient.ErrChainMismatch)
+
+	// an outage keeps the last result, which is cleared once the chain is back
+	chain.mu.Lock()
+	chain.down = true
+	chain.mu.Unlock()
+	time.Sleep(3 * check.Interval)
+	c.Assert(cli.ChainCheckError(), qt.ErrorIs, apiclient.ErrChainMismatch)
+	chain.mu.Lock()
+	chain.down = false
+	chain.blocks[5] = hash
+	chain.mu.Unlock()
+	waitFor(func(err error) bool { return err == nil })
+
+	// once the context is done, the chain is not verified anymore
+	cancel()
+	time.Sleep(3 * check.Interval)
+	chain.mu.Lock()
+	chain.blocks[5] = util.RandomBytes(32)
+	chain.mu.Unlock()
+	time.Sleep(3 * check.Interval)
+	c.Assert(cli.ChainCheckError(), qt.IsNil)
+}
@@ -0,0 +1,144 @@
+package apiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"time"
+
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/crypto/zk/circuit"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
+)
+
+// Option configures the HTTPclient created by New, NewWithBearer and
+// NewWithURLAndBearer.
+type Option func(*HTTPclient)
+
+// WithPinnedChain pins the client to the chain stored in file. If the file does
+// not exist, the chain of the API server is stored in it on the first
+// connection. Otherwise, the client cannot be created, nor its host changed
+// with SetHostAddr, if the API server is connected to a different chain, so
+// that tools fail instead of signing transactions for another network.
+func WithPinnedChain(file string) Option {
+	return func(c *HTTPclient) {
+		c.pinFile = file
+	}
+}
+
+// ChainPin is the information of a chain stored in the pin file, see
+// WithPinnedChain. The chain ID, genesis and circuit identify the chain, while
+// the transaction costs are updated when they change, since the chain can
+// change them, but the change is logged.
+type ChainPin struct {
+	ChainID         string            `json:"chainId"`
+	GenesisTime     time.Time         `json:"genesisTime"`
+	GenesisHash     types.HexBytes    `json:"genesisHash,omitempty"`
+	CircuitVersion  string            `json:"circuitVersion"`
+	CircuitLevels   int               `json:"circuitLevels"`
+	CircuitVKeyHash types.HexBytes    `json:"circuitVKeyHash"`
+	TxCosts         map[string]uint64 `json:"txCosts"`
+	PinnedAt        time.Time         `json:"pinnedAt"`
+}
+
+// ChainPin returns the information of the chain the API server is connected
+// to, as it would be stored in the pin file.
+func (c *HTTPclient) ChainPin() (*ChainPin, error) {
+	info, err := c.ChainInfo()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get chain info: %w", err)
+	}
+	return c.chainPin(info)
+}
+
+// chainPin builds the ChainPin of the chain described by info.
+func (c *HTTPclient) chainPin(info *api.ChainInfo) (*ChainPin, error) {
+	// the base costs do not depend on the fee multiplier, fall back to the
+	// current costs for the API servers without fee market
+	var costs map[string]uint64
+	if market, err := c.FeeMarket(); err == nil {
+		costs = market.BaseCosts
+	} else if costs, err = c.TransactionsCost(); err != nil {
+		return nil, fmt.Errorf("cannot get transaction costs: %w", err)
+	}
+	conf := circuit.GetCircuitConfiguration(info.CircuitVersion)
+	return &ChainPin{
+		ChainID:         info.ID,
+		GenesisTime:     info.GenesisTime,
+		GenesisHash:     info.GenesisHash,
+		CircuitVersion:  info.CircuitVersion,
+		CircuitLevels:   conf.Levels,
+		CircuitVKeyHash: conf.VerificationKeyHash,
+		TxCosts:         costs,
+	}, nil
+}
+
+// verifyPinnedChain compares the chain described by info with the one stored in
+// the pin file, returning ErrChainMismatch if they differ. If the pin file does
+// not exist yet, it is created.
+func (c *HTTPclient) verifyPinnedChain(info *api.ChainInfo) error {
+	current, err := c.chainPin(info)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(c.pinFile)
+	if errors.Is(err, os.ErrNotExist) {
+		current.PinnedAt = time.Now()
+		log.Infow("pinning chain", "chainId", current.ChainID, "file", c.pinFile)
+		return writeChainPin(c.pinFile, current)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read chain pin: %w", err)
+	}
+	pinned := &ChainPin{}
+	if err := json.Unmarshal(data, pinned); err != nil {
+		return fmt.Errorf("cannot decode chain pin %s: %w", c.pinFile, err)
+	}
+	if err := pinned.compare(current); err != nil {
+		return fmt.Errorf("%w (pinned in %s): %w", ErrChainMismatch, c.pinFile, err)
+	}
+	if maps.Equal(pinned.TxCosts, current.TxCosts) {
+		return nil
+	}
+	for name, cost := range current.TxCosts {
+		if pinned.TxCosts[name] != cost {
+			log.Warnw("pinned transaction cost changed", "tx", name, "pinned", pinned.TxCosts[name], "cost", cost)
+		}
+	}
+	pinned.TxCosts = current.TxCosts
+	return writeChainPin(c.pinFile, pinned)
+}
+
+// compare returns an error describing the first identity field of the chain
+// which differs from the pinned one.
+func (p *ChainPin) compare(current *ChainPin) error {
+	switch {
+	case p.ChainID != current.ChainID:
+		return fmt.Errorf("chain ID is %q, expected %q", current.ChainID, p.ChainID)
+	case !p.GenesisTime.Equal(current.GenesisTime):
+		return fmt.Errorf("genesis time is %s, expected %s", current.GenesisTime, p.GenesisTime)
+	case len(p.GenesisHash) > 0 && len(current.GenesisHash) > 0 && !bytes.Equal(p.GenesisHash, current.GenesisHash):
+		return fmt.Errorf("genesis hash is %x, expected %x", current.GenesisHash, p.GenesisHash)
+	case p.CircuitVersion != current.CircuitVersion:
+		return fmt.Errorf("circuit version is %q, expected %q", current.CircuitVersion, p.CircuitVersion)
+	case p.CircuitLevels != current.CircuitLevels || !bytes.Equal(p.CircuitVKeyHash, current.CircuitVKeyHash):
+		return fmt.Errorf("circuit %s configuration changed", current.CircuitVersion)
+	}
+	return nil
+}
+
+// writeChainPin stores the chain pin in file.
+func writeChainPin(file string, pin *ChainPin) error {
+	data, err := json.MarshalIndent(pin, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return fmt.Errorf("cannot write chain pin: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package apiclient_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+)
+
+// newPinServer starts an API server of the chain described by info, with the
+// given transaction costs, and returns its URL. The chain info fails if info is
+// nil, and the fee market is not served if market is nil.
+func newPinServer(t *testing.T, info *api.ChainInfo, costs map[string]uint64, market *api.FeeMarket) string {
+	reply := func(w http.ResponseWriter, v any) {
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Error(err)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /chain/info", func(w http.ResponseWriter, _ *http.Request) {
+		if info == nil {
+			http.Error(w, "node is syncing", http.StatusInternalServerError)
+			return
+		}
+		reply(w, info)
+	})
+	mux.HandleFunc("GET /chain/transactions/cost", func(w http.ResponseWriter, _ *http.Request) {
+		reply(w, &api.Transaction{Costs: costs})
+	})
+	mux.HandleFunc("GET /chain/fees/market", func(w http.ResponseWriter, r *http.Request) {
+		if market == nil {
+			http.NotFound(w, r)
+			return
+		}
+		reply(w, market)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL + "/"
+}
+
+func TestPinnedChain(t *testing.T) {
+	c := qt.New(t)
+	genesis := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	info := &api.ChainInfo{ID: "test", GenesisTime: genesis, GenesisHash: []byte{1, 2, 3}}
+	costs := map[string]uint64{"NewProcess": 10}
+	host := newPinServer(t, info, costs, nil)
+	pinFile := filepath.Join(t.TempDir(), "chain.json")
+	readPin := func() *apiclient.ChainPin {
+		data, err := os.ReadFile(pinFile)
+		c.Assert(err, qt.IsNil)
+		pin := &apiclient.ChainPin{}
+		c.Assert(json.Unmarshal(data, pin), qt.IsNil)
+		return pin
+	}
+
+	// the chain is pinned on the first connection
+	cli, err := apiclient.New(host, apiclient.WithPinnedChain(pinFile))
+	c.Assert(err, qt.IsNil)
+	pin := readPin()
+	c.Assert(pin.ChainID, qt.Equals, "test")
+	c.Assert(pin.GenesisTime.Equal(genesis), qt.IsTrue)
+	c.Assert(pin.TxCosts, qt.DeepEquals, costs)
+	c.Assert(pin.PinnedAt.IsZero(), qt.IsFalse)
+	current, err := cli.ChainPin()
+	c.Assert(err, qt.IsNil)
+	c.Assert(current.ChainID, qt.Equals, pin.ChainID)
+
+	// the transaction costs can change
+	_, err = apiclient.New(newPinServer(t, info, map[string]uint64{"NewProcess": 20}, nil), apiclient.WithPinnedChain(pinFile))
+	c.Assert(err, qt.IsNil)
+	c.Assert(readPin().TxCosts, qt.DeepEquals, map[string]uint64{"NewProcess": 20})
+	c.Assert(readPin().PinnedAt.Equal(pin.PinnedAt), qt.IsTrue)
+
+	// but not the identity of the chain
+	for _, other := range []*api.ChainInfo{
+		{ID: "other", GenesisTime: genesis, GenesisHash: info.GenesisHash},
+		{ID: "test", GenesisTime: genesis.Add(time.Second), GenesisHash: info.GenesisHash},
+		{ID: "test", GenesisTime: genesis, GenesisHash: []byte{3, 2, 1}},
+	} {
+		otherHost := newPinServer(t, other, costs, nil)
+		_, err = apiclient.New(otherHost, apiclient.WithPinnedChain(pinFile))
+		c.Assert(err, qt.ErrorIs, apiclient.ErrChainMismatch)
+
+		// the host is kept if the new one is connected to another chain
+		otherURL, err := url.Parse(otherHost)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cli.SetHostAddr(otherURL), qt.ErrorIs, apiclient.ErrChainMismatch)
+		c.Assert(cli.ChainID(), qt.Equals, "test")
+		_, err = cli.ChainInfo()
+		c.Assert(err, qt.IsNil)
+	}
+	sameURL, err := url.Parse(newPinServer(t, info, costs, nil))
+	c.Assert(err, qt.IsNil)
+	c.Assert(cli.SetHostAddr(sameURL), qt.IsNil)
+
+	// the clients without pin can connect to any chain
+	_, err = apiclient.New(newPinServer(t, &api.ChainInfo{ID: "other"}, costs, nil))
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(os.WriteFile(pinFile, []byte("corrupt"), 0o600), qt.IsNil)
+	_, err = apiclient.New(host, apiclient.WithPinnedChain(pinFile))
+	c.Assert(err, qt.ErrorMatches, "cannot decode chain pin .*")
+}
+
+func TestPinnedChainEdgeCases(t *testing.T) {
+	c := qt.New(t)
+	genesis := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	info := &api.ChainInfo{ID: "test", GenesisTime: genesis, GenesisHash: []byte{1, 2, 3}}
+	costs := map[string]uint64{"NewProcess": 10}
+	dir := t.TempDir()
+
+	// the base costs of the fee market are pinned instead of the current ones,
+	// which depend on how busy the chain is
+	pinFile := filepath.Join(dir, "market.json")
+	market := &api.FeeMarket{Enabled: true, Multiplier: 3000,
+		BaseCosts: map[string]uint64{"NewProcess": 5}, Costs: map[string]uint64{"NewProcess": 15}}
+	cli, err := apiclient.New(newPinServer(t, info, costs, market), apiclient.WithPinnedChain(pinFile))
+	c.Assert(err, qt.IsNil)
+	pin, err := cli.ChainPin()
+	c.Assert(err, qt.IsNil)
+	c.Assert(pin.TxCosts, qt.DeepEquals, map[string]uint64{"NewProcess": 5})
+	market.Multiplier, market.Costs = 1000, map[string]uint64{"NewProcess": 5}
+	_, err = apiclient.New(newPinServer(t, info, costs, market), apiclient.WithPinnedChain(pinFile))
+	c.Assert(err, qt.IsNil)
+
+	// the servers which do not report the genesis hash are not told apart by it
+	pinFile = filepath.Join(dir, "nohash.json")
+	noHash := &api.ChainInfo{ID: "test", GenesisTime: genesis}
+	_, err = apiclient.New(newPinServer(t, noHash, costs, nil), apiclient.WithPinnedChain(pinFile))
+	c.Assert(err, qt.IsNil)
+	_, err = apiclient.New(newPinServer(t, info, costs, nil), apiclient.WithPinnedChain(pinFile))
+	c.Assert(err, qt.IsNil)
+
+	// the circuit of the anonymous votes is part of the identity of the chain
+	pinFile = filepath.Join(dir, "circuit.json")
+	_, err = apiclient.New(newPinServer(t, info, costs, nil), apiclient.WithPinnedChain(pinFile))
+	c.Assert(err, qt.IsNil)
+	withCircuit := &api.ChainInfo{ID: "test", GenesisTime: genesis, GenesisHash: info.GenesisHash, CircuitVersion: "dev"}
+	_, err = apiclient.New(newPinServer(t, withCircuit, costs, nil), apiclient.WithPinnedChain(pinFile))
+	c.Assert(err, qt.ErrorIs, apiclient.ErrChainMismatch)
+	c.Assert(err, qt.ErrorMatches, `chain mismatch \(pinned in .*circuit.json\): circuit version is "dev", expected ""`)
+
+	// the chain cannot be pinned nor verified without its info
+	pinFile = filepath.Join(dir, "syncing.json")
+	_, err = apiclient.New(newPinServer(t, nil, costs, nil), apiclient.WithPinnedChain(pinFile))
+	c.Assert(err, qt.ErrorMatches, "(?s)cannot verify the pinned chain: .*500.*node is syncing.*")
+	_, err = os.Stat(pinFile)
+	c.Assert(err, qt.ErrorIs, os.ErrNotExist)
+
+	// nor stored where the file cannot be written
+	_, err = apiclient.New(newPinServer(t, info, costs, nil),
+		apiclient.WithPinnedChain(filepath.Join(dir, "missing", "chain.json")))
+	c.Assert(err, qt.ErrorMatches, "cannot write chain pin: .*")
+}
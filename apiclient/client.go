@@ -2,7 +2,9 @@ package apiclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -45,25 +47,65 @@ type HTTPclient struct {
 	chainID string
 	circuit *circuit.ZkCircuit
 	retries int
+	// nullifierFilters caches the nullifier filters used by HasVoted.
+	nullifierFilters *nullifierFilterCache
+	// ballots holds the choices cast by the client, checked by VerifyMyVote.
+	ballots *ballotStore
+	// chainCheck holds the result of the last verification of the chain
+	// started by StartChainCheck.
+	chainCheck *chainChecker
+	// metrics are the Prometheus metrics of the client, nil unless enabled
+	// with EnableMetrics.
+	metrics *clientMetrics
+	// proofCache caches the census and zk proofs, nil unless enabled with
+	// SetProofCache.
+	proofCache *ProofCache
+	// maxFeeMultiplier is the maximum fee multiplier the client accepts to pay,
+	// zero if not limited, see SetMaxFeeMultiplier.
+	maxFeeMultiplier uint64
+	// pinFile is the file of the chain the client is pinned to, empty unless
+	// configured with WithPinnedChain.
+	pinFile string
+	// retryPolicy is the retry policy of the GET requests, nil unless
+	// configured with WithClientOptions.
+	retryPolicy *RetryPolicy
+	// breaker is the circuit breaker of the requests, nil unless configured
+	// with WithClientOptions.
+	breaker *circuitBreaker
+	// capabilities holds the capabilities of the API server once known, see
+	// Capabilities.
+	capabilities *capabilitiesCache
+	// limiter schedules the requests of the client and its clones, see
+	// RateLimitPolicy.
+	limiter *rateLimiter
+	// middlewares intercept the requests and operations of the client, see
+	// WithMiddleware.
+	middlewares []Middleware
+	// ctx is the context of the requests, nil for context.Background, see
+	// WithContext.
+	ctx context.Context
+	// binaryTransport asks the API server for CBOR responses, see
+	// WithBinaryTransport.
+	binaryTransport bool
 }
 
 // New connects to the API host with a random bearer token and returns the handle
-func New(host string) (*HTTPclient, error) {
+func New(host string, opts ...Option) (*HTTPclient, error) {
 	token := uuid.New()
-	return NewWithBearer(host, &token)
+	return NewWithBearer(host, &token, opts...)
 }
 
 // NewWithBearer connects to the API host with a random bearer token and returns the handle
-func NewWithBearer(host string, bearerToken *uuid.UUID) (*HTTPclient, error) {
+func NewWithBearer(host string, bearerToken *uuid.UUID, opts ...Option) (*HTTPclient, error) {
 	hostURL, err := url.Parse(host)
 	if err != nil {
 		return nil, err
 	}
-	return NewWithURLAndBearer(hostURL, bearerToken)
+	return NewWithURLAndBearer(hostURL, bearerToken, opts...)
 }
 
 // NewWithURLAndBearer creates a new HTTP(s) API Vocdoni client.
-func NewWithURLAndBearer(addr *url.URL, bearerToken *uuid.UUID) (*HTTPclient, error) {
+func NewWithURLAndBearer(addr *url.URL, bearerToken *uuid.UUID, opts ...Option) (*HTTPclient, error) {
 	tr := &http.Transport{
 		IdleConnTimeout:    DefaultTimeout,
 		DisableCompression: false,
@@ -75,12 +117,29 @@ func NewWithURLAndBearer(addr *url.URL, bearerToken *uuid.UUID) (*HTTPclient, er
 		token:   bearerToken,
 		addr:    addr,
 		retries: DefaultRetries,
+		nullifierFilters: &nullifierFilterCache{
+			filters: make(map[string]cachedNullifierFilter),
+		},
+		ballots:      &ballotStore{ballots: make(map[string]ballot)},
+		chainCheck:   &chainChecker{},
+		capabilities: &capabilitiesCache{},
+		limiter:      newRateLimiter(RateLimitPolicy{}),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
 	data, status, err := c.Request(HTTPGET, nil, "chain", "info")
+	if errors.Is(err, ErrNotSupported) && c.pinFile == "" {
+		log.Warnw("cannot get chain info from API server", "error", err)
+		return c, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 	if status != apirest.HTTPstatusOK {
+		if c.pinFile != "" {
+			return nil, fmt.Errorf("cannot verify the pinned chain: %s: %d (%s)", errCodeNot200, status, data)
+		}
 		log.Warnw("cannot get chain info from API server", "status", status, "data", data)
 		return c, nil
 	}
@@ -90,9 +149,19 @@ func NewWithURLAndBearer(addr *url.URL, bearerToken *uuid.UUID) (*HTTPclient, er
 	}
 	c.chainID = info.ID
 
-	c.circuit, err = circuit.LoadVersion(info.CircuitVersion)
-	if err != nil {
-		return nil, fmt.Errorf("error loading circuit: %w", err)
+	if c.pinFile != "" {
+		if err := c.verifyPinnedChain(info); err != nil {
+			return nil, err
+		}
+	}
+
+	// the API servers without a circuit, such as the fake gateway of the
+	// apiclienttest package, cannot be used for anonymous votes
+	if info.CircuitVersion != "" {
+		c.circuit, err = circuit.LoadVersion(info.CircuitVersion)
+		if err != nil {
+			return nil, fmt.Errorf("error loading circuit: %w", err)
+		}
 	}
 	return c, nil
 }
@@ -139,8 +208,11 @@ func (c *HTTPclient) BearerToken() *uuid.UUID {
 	return c.token
 }
 
-// SetHostAddr configures the host address of the API server.
+// SetHostAddr configures the host address of the API server. If the client is
+// pinned to a chain (see WithPinnedChain), the API server must be connected to
+// it.
 func (c *HTTPclient) SetHostAddr(addr *url.URL) error {
+	prevAddr := c.addr
 	c.addr = addr
 	data, status, err := c.Request(HTTPGET, nil, "chain", "info")
 	if err != nil {
@@ -153,7 +225,15 @@ func (c *HTTPclient) SetHostAddr(addr *url.URL) error {
 	if err := json.Unmarshal(data, info); err != nil {
 		return fmt.Errorf("cannot get chain ID from API server")
 	}
+	if c.pinFile != "" {
+		if err := c.verifyPinnedChain(info); err != nil {
+			c.addr = prevAddr
+			return err
+		}
+	}
 	c.chainID = info.ID
+	// the new API server may enable other namespaces
+	c.capabilities = &capabilitiesCache{}
 	return nil
 }
 
@@ -174,8 +254,17 @@ func (c *HTTPclient) SetTimeout(d time.Duration) {
 
 // Request performs a `method` type raw request to the endpoint specified in urlPath parameter.
 // Method is either GET or POST. If POST, a JSON struct should be attached.  Returns the response,
-// the status code and an error.
+// the status code and an error, which is ErrNotSupported if the API server does not enable the
+// namespace of the endpoint (see Capabilities).
 func (c *HTTPclient) Request(method string, jsonBody any, urlPath ...string) ([]byte, int, error) {
+	return c.RequestWithQuery(method, jsonBody, nil, urlPath...)
+}
+
+// RequestWithQuery is like Request, but also sends the given query parameters.
+func (c *HTTPclient) RequestWithQuery(method string, jsonBody any, query url.Values, urlPath ...string) ([]byte, int, error) {
+	if err := c.checkSupported(urlPath, false); err != nil {
+		return nil, 0, err
+	}
 	body, err := json.Marshal(jsonBody)
 	if err != nil {
 		return nil, 0, err
@@ -185,6 +274,9 @@ func (c *HTTPclient) Request(method string, jsonBody any, urlPath ...string) ([]
 		return nil, 0, err
 	}
 	u.Path = path.Join(u.Path, path.Join(urlPath...))
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
 	headers := http.Header{}
 	if c.token != nil {
 		headers = http.Header{
@@ -193,41 +285,106 @@ func (c *HTTPclient) Request(method string, jsonBody any, urlPath ...string) ([]
 			"Content-Type":  []string{"application/json"},
 		}
 	}
+	if c.binaryTransport {
+		headers.Set("Accept", binaryAccept)
+	}
 
-	log.Debugw("http request", "type", method, "path", u.Path, "body", func() string {
+	log.Debugw("http request", "type", method, "path", u.Path, "query", u.RawQuery, "body", func() string {
 		if len(body) > 512 {
 			return string(body[:512]) + "..."
 		}
 		return string(body)
 	}())
 	var resp *http.Response
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		if err := c.breaker.allow(); err != nil {
+			c.metrics.observeRequest(method, urlPath, start, 0, nil, err)
+			return nil, 0, err
+		}
+		resp, err = c.send(method, u, headers, body, jsonBody != nil, urlPath)
+		failed := isGatewayFailure(resp, err)
+		c.breaker.record(failed)
+		if !failed || method != HTTPGET || c.retryPolicy == nil || attempt >= c.retryPolicy.MaxAttempts {
+			break
+		}
+		backoff := c.retryPolicy.backoff(attempt)
+		log.Warnw("request failed, will retry", "path", u.Path, "attempt", attempt,
+			"backoff", backoff, "error", func() any {
+				if err != nil {
+					return err
+				}
+				return resp.Status
+			}())
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		time.Sleep(backoff)
+	}
+	if err != nil {
+		c.metrics.observeRequest(method, urlPath, start, 0, nil, err)
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	c.metrics.observeRequest(method, urlPath, start, resp.StatusCode, data, err)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode == apirest.HTTPstatusNotFound {
+		if err := c.checkSupported(urlPath, true); err != nil {
+			return nil, 0, err
+		}
+	}
+	if data, err = decodeResponse(resp.Header, data); err != nil {
+		return nil, 0, err
+	}
+	return data, resp.StatusCode, nil
+}
+
+// send performs the HTTP request once the rate limiter of the client lets it
+// through, retrying it while the mempool of the API server is full or the
+// gateway replies 429 Too Many Requests.
+func (c *HTTPclient) send(method string, u *url.URL, headers http.Header, body []byte, hasBody bool,
+	urlPath []string,
+) (resp *http.Response, err error) {
+	class := classOf(method, urlPath)
 	for i := 1; i <= c.retries; i++ {
-		resp, err = c.c.Do(&http.Request{
+		c.limiter.wait(class)
+		req := (&http.Request{
 			Method: method,
 			URL:    u,
-			Header: headers,
+			Header: headers.Clone(),
 			Body: func() io.ReadCloser {
-				if jsonBody == nil {
+				if !hasBody {
 					return nil
 				}
 				return io.NopCloser(bytes.NewBuffer(body))
 			}(),
-		})
+		}).WithContext(c.context())
+		done := c.onRequest(req)
+		resp, err = c.c.Do(req)
+		done(resp, err)
 		if resp != nil && resp.StatusCode == apirest.HTTPstatusServiceUnavailable { // mempool is full
 			log.Warnf("mempool is full, will wait and retry (%d/%d)", i, c.retries)
+			c.metrics.observeRetry(urlPath)
 			_ = resp.Body.Close()
 			_ = c.WaitUntilNextBlock()
 			continue
 		}
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			// the other requests of the client wait as well, even if this
+			// one is not retried
+			wait := retryAfter(resp.Header)
+			if !c.limiter.pause(wait) || i == c.retries {
+				break
+			}
+			log.Warnf("rate limited by the gateway, will wait %s and retry (%d/%d)", wait, i, c.retries)
+			c.metrics.observeRetry(urlPath)
+			_ = resp.Body.Close()
+			continue
+		}
 		break
 	}
-	if err != nil {
-		return nil, 0, err
-	}
-	defer resp.Body.Close()
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, 0, err
-	}
-	return data, resp.StatusCode, nil
+	return resp, err
 }
@@ -0,0 +1,55 @@
+package apiclient
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// The delegates of an account can create and manage elections on its behalf,
+// and update its metadata, signing the transactions with their own keys. Note
+// that the chain does not support delegating the votes: a vote is always
+// signed by the key of the voter in the census of the election.
+
+// AddAccountDelegates appoints the given addresses as delegates of the account
+// associated with the client. Returns the transaction hash.
+func (c *HTTPclient) AddAccountDelegates(delegates ...common.Address) (types.HexBytes, error) {
+	return c.sendAccountDelegatesTx(models.TxType_ADD_DELEGATE_FOR_ACCOUNT, delegates)
+}
+
+// DelAccountDelegates revokes the given delegates of the account associated
+// with the client. Returns the transaction hash.
+func (c *HTTPclient) DelAccountDelegates(delegates ...common.Address) (types.HexBytes, error) {
+	return c.sendAccountDelegatesTx(models.TxType_DEL_DELEGATE_FOR_ACCOUNT, delegates)
+}
+
+// sendAccountDelegatesTx signs and sends a SetAccountTx of the given type,
+// adding or removing the delegates of the account associated with the client.
+func (c *HTTPclient) sendAccountDelegatesTx(txType models.TxType, delegates []common.Address) (types.HexBytes, error) {
+	if len(delegates) == 0 {
+		return nil, fmt.Errorf("no delegates")
+	}
+	acc, err := c.Account("")
+	if err != nil {
+		return nil, fmt.Errorf("account not configured: %w", err)
+	}
+	tx := &models.SetAccountTx{
+		Txtype:  txType,
+		Nonce:   &acc.Nonce,
+		Account: c.account.Address().Bytes(),
+	}
+	for _, delegate := range delegates {
+		tx.Delegates = append(tx.Delegates, delegate.Bytes())
+	}
+	stx, err := proto.Marshal(&models.Tx{
+		Payload: &models.Tx_SetAccount{SetAccount: tx},
+	})
+	if err != nil {
+		return nil, err
+	}
+	txHash, _, err := c.SignAndSendTx(stx)
+	return txHash, err
+}
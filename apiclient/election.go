@@ -4,16 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strconv"
 	"time"
 
+	"github.com/google/uuid"
 	"go.vocdoni.io/dvote/api"
 	"go.vocdoni.io/dvote/data/ipfs"
 	"go.vocdoni.io/dvote/httprouter/apirest"
 	"go.vocdoni.io/dvote/log"
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/vochain/processid"
+	"go.vocdoni.io/dvote/vochain/results/resultsverify"
 	"go.vocdoni.io/dvote/vochain/state/electionprice"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/proto"
 )
@@ -23,18 +27,7 @@ func (c *HTTPclient) Election(electionID types.HexBytes) (*api.Election, error)
 	if electionID == nil {
 		return nil, fmt.Errorf("passed electionID is nil")
 	}
-	resp, code, err := c.Request(HTTPGET, nil, "elections", electionID.String())
-	if err != nil {
-		return nil, err
-	}
-	if code != apirest.HTTPstatusOK {
-		return nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	election := &api.Election{}
-	if err = json.Unmarshal(resp, election); err != nil {
-		return nil, fmt.Errorf("could not unmarshal response: %w", err)
-	}
-	return election, nil
+	return c.Endpoints().Election(electionID.String(), nil)
 }
 
 // NewElectionRaw creates a new election given the protobuf Process message
@@ -59,7 +52,7 @@ func (c *HTTPclient) NewElectionRaw(process *models.Process) (types.HexBytes, er
 	if err != nil {
 		return nil, err
 	}
-	signedTxb, err := c.account.SignVocdoniTx(txb, c.chainID)
+	signedTxb, err := c.signVocdoniTx(txb)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +86,15 @@ func (c *HTTPclient) NewElectionRaw(process *models.Process) (types.HexBytes, er
 // NewElection creates a new election given the election details
 // and returns the ElectionID. If wait is true, it will wait until the election is created.
 func (c *HTTPclient) NewElection(description *api.ElectionDescription, wait bool) (types.HexBytes, error) {
+	return c.newElection(description, nil, api.ElectionProperties{Name: ElectionResultsSingleChoice}, wait)
+}
+
+// newElection creates a new election given the election details, the vote options
+// and the metadata results type. If voteOptions is nil, they are computed from the
+// questions of the election as single choice questions.
+func (c *HTTPclient) newElection(description *api.ElectionDescription, voteOptions *models.ProcessVoteOptions,
+	resultsType api.ElectionProperties, wait bool,
+) (types.HexBytes, error) {
 	if c.account == nil {
 		return nil, fmt.Errorf("no account configured")
 	}
@@ -125,6 +127,11 @@ func (c *HTTPclient) NewElection(description *api.ElectionDescription, wait bool
 		Interruptible: description.ElectionType.Interruptible,
 		DynamicCensus: description.ElectionType.DynamicCensus,
 	}
+	vochaintx.SetProcessModeUnlisted(processMode, description.ElectionType.Unlisted)
+	vochaintx.SetProcessModeNullifierGroup(processMode, description.ElectionType.NullifierGroup)
+	vochaintx.SetProcessModeVoteDeposit(processMode, description.ElectionType.VoteDeposit)
+	vochaintx.SetProcessModeManualEnd(processMode, description.ElectionType.ManualEnd)
+	vochaintx.SetProcessModeVoteDelegation(processMode, description.ElectionType.VoteDelegation)
 
 	// Prepare the election metadata information
 	metadata := api.ElectionMetadata{
@@ -135,11 +142,9 @@ func (c *HTTPclient) NewElection(description *api.ElectionDescription, wait bool
 		},
 		Meta:      nil,
 		Questions: []api.Question{},
-		Type: api.ElectionProperties{
-			Name: "single-choice-multiquestion",
-		},
-		Title:   description.Title,
-		Version: "1.0",
+		Type:      resultsType,
+		Title:     description.Title,
+		Version:   "1.0",
 	}
 
 	maxChoiceValue := 0
@@ -159,13 +164,15 @@ func (c *HTTPclient) NewElection(description *api.ElectionDescription, wait bool
 		metadata.Questions = append(metadata.Questions, metaQuestion)
 	}
 
-	// TODO: respect maxCount and maxValue if specified
-	voteOptions := &models.ProcessVoteOptions{
-		MaxCount:          uint32(len(description.Questions)),
-		MaxValue:          uint32(maxChoiceValue),
-		MaxVoteOverwrites: uint32(description.VoteType.MaxVoteOverwrites),
-		MaxTotalCost:      uint32(len(description.Questions) * maxChoiceValue),
-		CostExponent:      1,
+	if voteOptions == nil {
+		// TODO: respect maxCount and maxValue if specified
+		voteOptions = &models.ProcessVoteOptions{
+			MaxCount:          uint32(len(description.Questions)),
+			MaxValue:          uint32(maxChoiceValue),
+			MaxVoteOverwrites: uint32(description.VoteType.MaxVoteOverwrites),
+			MaxTotalCost:      uint32(len(description.Questions) * maxChoiceValue),
+			CostExponent:      1,
+		}
 	}
 
 	// Census Origin
@@ -220,7 +227,7 @@ func (c *HTTPclient) NewElection(description *api.ElectionDescription, wait bool
 	if err != nil {
 		return nil, err
 	}
-	signedTxb, err := c.account.SignVocdoniTx(txb, c.chainID)
+	signedTxb, err := c.signVocdoniTx(txb)
 	if err != nil {
 		return nil, err
 	}
@@ -283,7 +290,7 @@ func (c *HTTPclient) SetElectionStatus(electionID types.HexBytes, status string)
 	// get the own account details
 	acc, err := c.Account("")
 	if err != nil {
-		return nil, fmt.Errorf("could not fetch account info: %s", acc.Address)
+		return nil, fmt.Errorf("could not fetch account info: %w", err)
 	}
 
 	// build the set process transaction
@@ -313,7 +320,7 @@ func (c *HTTPclient) SetElectionCensusSize(electionID types.HexBytes, newSize ui
 	// get the own account details
 	acc, err := c.Account("")
 	if err != nil {
-		return nil, fmt.Errorf("could not fetch account info: %s", acc.Address)
+		return nil, fmt.Errorf("could not fetch account info: %w", err)
 	}
 
 	// build the set process transaction
@@ -343,7 +350,7 @@ func (c *HTTPclient) SetElectionDuration(electionID types.HexBytes, newDuration
 	// get the own account details
 	acc, err := c.Account("")
 	if err != nil {
-		return nil, fmt.Errorf("could not fetch account info: %s", acc.Address)
+		return nil, fmt.Errorf("could not fetch account info: %w", err)
 	}
 
 	// build the set process transaction
@@ -378,7 +385,7 @@ func (c *HTTPclient) SetElectionCensus(electionID types.HexBytes, census api.Ele
 	// get the own account details
 	acc, err := c.Account("")
 	if err != nil {
-		return nil, fmt.Errorf("could not fetch account info: %s", acc.Address)
+		return nil, fmt.Errorf("could not fetch account info: %w", err)
 	}
 
 	tx := &models.SetProcessTx{
@@ -408,85 +415,91 @@ func (c *HTTPclient) SetElectionCensus(electionID types.HexBytes, census api.Ele
 
 // ElectionVoteCount returns the number of registered votes for a given election.
 func (c *HTTPclient) ElectionVoteCount(electionID types.HexBytes) (uint32, error) {
-	resp, code, err := c.Request(HTTPGET, nil, "elections", electionID.String(), "votes", "count")
+	count, err := c.Endpoints().ElectionVotesCount(electionID.String())
 	if err != nil {
 		return 0, err
 	}
-	if code != apirest.HTTPstatusOK {
-		return 0, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	votes := new(struct {
-		Count uint32 `json:"count"`
-	})
-
-	if err := json.Unmarshal(resp, votes); err != nil {
-		return 0, err
-	}
-	return votes.Count, nil
+	return uint32(count.Count), nil
 }
 
 // ElectionResults returns the election results given its ID.
 func (c *HTTPclient) ElectionResults(electionID types.HexBytes) (*api.ElectionResults, error) {
-	resp, code, err := c.Request(HTTPGET, nil, "elections", electionID.String(), "scrutiny")
+	return c.Endpoints().ElectionScrutiny(electionID.String())
+}
+
+// ElectionResultsProof returns the verification bundle of the election results,
+// checked against the hash of the block at the bundle height as reported by
+// the API. Callers that do not trust the API should verify the bundle again
+// with a block hash obtained from a trusted source.
+func (c *HTTPclient) ElectionResultsProof(electionID types.HexBytes) (*resultsverify.Bundle, error) {
+	bundle, err := c.Endpoints().ElectionResultsProof(electionID.String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	if code != apirest.HTTPstatusOK {
-		return nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
+	block, err := c.Endpoints().ChainBlockByHeight(bundle.Height, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get block %d: %w", bundle.Height, err)
 	}
-	electionResults := &api.ElectionResults{}
-	if err = json.Unmarshal(resp, &electionResults); err != nil {
-		return nil, fmt.Errorf("could not unmarshal response: %w", err)
+	if _, err := bundle.Verify(block.Hash); err != nil {
+		return nil, fmt.Errorf("invalid results proof: %w", err)
 	}
-	return electionResults, nil
+	return bundle, nil
 }
 
-// ElectionFilterPaginated returns a list of elections filtered by the given parameters.
+// ElectionFilterPaginated returns the page of the elections filtered by the
+// given parameters, from which the following pages can be fetched.
 // POST /elections/filter/page/<page>
-// Returns a list of elections filtered by the given parameters.
 func (c *HTTPclient) ElectionFilterPaginated(organizationID types.HexBytes, electionID types.HexBytes,
 	status models.ProcessStatus, withResults bool, page int,
-) (*[]api.ElectionSummary, error) {
-	body := struct {
-		OrganizationID types.HexBytes `json:"organizationId,omitempty"`
-		ElectionID     types.HexBytes `json:"electionId,omitempty"`
-		WithResults    bool           `json:"withResults,omitempty"`
-		Status         string         `json:"status,omitempty"`
-	}{
-		OrganizationID: organizationID,
-		ElectionID:     electionID,
-		WithResults:    withResults,
-		Status:         status.String(),
+) (*Page[*api.ElectionSummary], error) {
+	params := &api.ElectionParams{
+		OrganizationID: organizationID.String(),
+		ElectionID:     electionID.String(),
 	}
-	resp, code, err := c.Request(HTTPPOST, body, "elections", "filter", "page", strconv.Itoa(page))
+	if status != models.ProcessStatus_PROCESS_UNKNOWN {
+		params.Status = status.String()
+	}
+	if withResults {
+		params.WithResults = &withResults
+	}
+	return fetchPage(func(page int64, cursor string) ([]*api.ElectionSummary, *api.Pagination, error) {
+		params.Cursor = cursor
+		list, err := c.Endpoints().ElectionListByFilterAndPage(page, params, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return list.Elections, list.Pagination, nil
+	}, int64(page), "")
+}
+
+// OrganizationElections returns a page of the elections of the organization.
+// If token is the one set by the organization with SetUnlistedElectionsToken,
+// the list includes its unlisted elections.
+// GET /elections?organizationId=<organizationID>&page=<page>
+func (c *HTTPclient) OrganizationElections(organizationID types.HexBytes, token *uuid.UUID, page int) (*api.ElectionsList, error) {
+	clone := *c
+	clone.token = token
+	query := url.Values{}
+	query.Set("organizationId", organizationID.String())
+	query.Set("page", strconv.Itoa(page))
+	resp, code, err := clone.RequestWithQuery(HTTPGET, nil, query, "elections")
 	if err != nil {
 		return nil, err
 	}
 	if code != apirest.HTTPstatusOK {
 		return nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
 	}
-	var elections []api.ElectionSummary
-	if err := json.Unmarshal(resp, &elections); err != nil {
+	list := &api.ElectionsList{}
+	if err := json.Unmarshal(resp, list); err != nil {
 		return nil, err
 	}
-	return &elections, nil
+	return list, nil
 }
 
 // ElectionKeys fetches the encryption keys for an election.
 // Note that only elections that are SecretUntilTheEnd will return keys
 func (c *HTTPclient) ElectionKeys(electionID types.HexBytes) (*api.ElectionKeys, error) {
-	resp, code, err := c.Request(HTTPGET, nil, "elections", electionID.String(), "keys")
-	if err != nil {
-		return nil, err
-	}
-	if code != apirest.HTTPstatusOK {
-		return nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	electionKeys := &api.ElectionKeys{}
-	if err = json.Unmarshal(resp, &electionKeys); err != nil {
-		return nil, fmt.Errorf("could not unmarshal response: %w", err)
-	}
-	return electionKeys, nil
+	return c.Endpoints().ElectionKeys(electionID.String())
 }
 
 // ElectionPrice returns the price of an election.
@@ -0,0 +1,287 @@
+package apiclient
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/results"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// Names of the election results types of the metadata, which tell the clients
+// how to aggregate and display the results of each ballot protocol preset.
+const (
+	ElectionResultsSingleChoice   = "single-choice-multiquestion"
+	ElectionResultsMultipleChoice = "multiple-choice"
+	ElectionResultsQuadratic      = "quadratic"
+	ElectionResultsRanked         = "ranked"
+	ElectionResultsBudget         = "budget-based"
+)
+
+// ElectionBuilder builds the description of an election with one of the ballot
+// protocol presets, which configure the envelope type, the vote options and the
+// results type of the metadata consistently. The errors are reported by Build.
+//
+//	b := NewElectionBuilder("Budget 2025", endDate).
+//		Census(census).
+//		Question("Projects", "Park", "Library", "School").
+//		Quadratic(10)
+//	electionID, err := client.NewElectionFromBuilder(b, true)
+type ElectionBuilder struct {
+	description api.ElectionDescription
+	preset      string
+	// numChoices is the number of choices of the multiple choice preset.
+	numChoices int
+	// budget is the number of credits of the quadratic preset.
+	budget uint32
+	err    error
+}
+
+// NewElectionBuilder returns an ElectionBuilder of a single choice election,
+// which starts as soon as it is created and ends at endDate.
+func NewElectionBuilder(title string, endDate time.Time) *ElectionBuilder {
+	return &ElectionBuilder{
+		description: api.ElectionDescription{
+			Title:        api.LanguageString{"default": title},
+			EndDate:      endDate,
+			ElectionType: api.ElectionType{Autostart: true, Interruptible: true},
+		},
+		preset: ElectionResultsSingleChoice,
+	}
+}
+
+// Description sets the description of the election.
+func (b *ElectionBuilder) Description(description string) *ElectionBuilder {
+	b.description.Description = api.LanguageString{"default": description}
+	return b
+}
+
+// StartDate sets the start date of the election, instead of starting it as soon
+// as it is created.
+func (b *ElectionBuilder) StartDate(startDate time.Time) *ElectionBuilder {
+	b.description.StartDate = startDate
+	return b
+}
+
+// Census sets the census of the election.
+func (b *ElectionBuilder) Census(census api.CensusTypeDescription) *ElectionBuilder {
+	b.description.Census = census
+	return b
+}
+
+// Anonymous makes the votes of the election anonymous.
+func (b *ElectionBuilder) Anonymous() *ElectionBuilder {
+	b.description.ElectionType.Anonymous = true
+	return b
+}
+
+// SecretUntilTheEnd encrypts the votes of the election until it ends.
+func (b *ElectionBuilder) SecretUntilTheEnd() *ElectionBuilder {
+	b.description.ElectionType.SecretUntilTheEnd = true
+	return b
+}
+
+// DynamicCensus allows to update the census of the election once created.
+func (b *ElectionBuilder) DynamicCensus() *ElectionBuilder {
+	b.description.ElectionType.DynamicCensus = true
+	return b
+}
+
+// VoteDeposit sets the number of tokens each voter must lock to vote, refunded
+// once the results are committed if the vote is counted. It is not supported
+// by the anonymous elections.
+func (b *ElectionBuilder) VoteDeposit(amount uint64) *ElectionBuilder {
+	b.description.ElectionType.VoteDeposit = amount
+	return b
+}
+
+// ManualEnd opts the election out of the automatic end once its duration
+// elapses, so that its results are only computed once the organizer sets it as
+// ended.
+func (b *ElectionBuilder) ManualEnd() *ElectionBuilder {
+	b.description.ElectionType.ManualEnd = true
+	return b
+}
+
+// VoteDelegation lets the census members delegate their vote weight to another
+// member until the election starts. It is only supported by the signed
+// elections with a census tree.
+func (b *ElectionBuilder) VoteDelegation() *ElectionBuilder {
+	b.description.ElectionType.VoteDelegation = true
+	return b
+}
+
+// MaxVoteOverwrites sets the number of times a voter can overwrite the vote.
+func (b *ElectionBuilder) MaxVoteOverwrites(n int) *ElectionBuilder {
+	b.description.VoteType.MaxVoteOverwrites = n
+	return b
+}
+
+// Question adds a question with the given choices, whose values are their
+// positions.
+func (b *ElectionBuilder) Question(title string, choices ...string) *ElectionBuilder {
+	question := api.Question{Title: api.LanguageString{"default": title}}
+	for i, choice := range choices {
+		question.Choices = append(question.Choices, api.ChoiceMetadata{
+			Title: api.LanguageString{"default": choice},
+			Value: uint32(i),
+		})
+	}
+	b.description.Questions = append(b.description.Questions, question)
+	return b
+}
+
+// SingleChoice sets the single choice preset, the default: the voters choose one
+// of the choices of each question. The vote has one field per question, with
+// the value of the choice.
+func (b *ElectionBuilder) SingleChoice() *ElectionBuilder {
+	b.preset = ElectionResultsSingleChoice
+	return b
+}
+
+// MultiChoice sets the multiple choice preset: the voters choose up to n
+// different choices of the only question. The vote has one field per choice
+// made, with its value.
+func (b *ElectionBuilder) MultiChoice(n int) *ElectionBuilder {
+	b.preset, b.numChoices = ElectionResultsMultipleChoice, n
+	if n < 1 {
+		b.err = errors.Join(b.err, fmt.Errorf("the number of choices must be positive, got %d", n))
+	}
+	return b
+}
+
+// Ranked sets the ranked preset: the voters rank all the choices of the only
+// question. The vote has one field per choice, with its position in the ranking.
+func (b *ElectionBuilder) Ranked() *ElectionBuilder {
+	b.preset = ElectionResultsRanked
+	return b
+}
+
+// Quadratic sets the quadratic voting preset: the voters spread budget credits
+// among the choices of the only question, and giving n votes to a choice costs
+// n² credits. The vote has one field per choice, with its number of votes.
+func (b *ElectionBuilder) Quadratic(budget uint32) *ElectionBuilder {
+	b.preset, b.budget = ElectionResultsQuadratic, budget
+	if budget == 0 {
+		b.err = errors.Join(b.err, fmt.Errorf("the quadratic budget must be positive"))
+	}
+	return b
+}
+
+// WeightedBudget sets the budget preset where the budget of each voter is its
+// census weight, which is spread among the choices of the only question. The
+// vote has one field per choice, with the part of the budget given to it.
+func (b *ElectionBuilder) WeightedBudget() *ElectionBuilder {
+	b.preset = ElectionResultsBudget
+	return b
+}
+
+// Build returns the description of the election. Its VoteType reflects the
+// vote options of the preset, but note that NewElection does not use them, so
+// it must be created with NewElectionFromBuilder.
+func (b *ElectionBuilder) Build() (*api.ElectionDescription, error) {
+	description, _, _, err := b.build()
+	return description, err
+}
+
+// build returns the description of the election, its vote options and the
+// results type of its metadata.
+func (b *ElectionBuilder) build() (*api.ElectionDescription, *models.ProcessVoteOptions, api.ElectionProperties, error) {
+	if b.err != nil {
+		return nil, nil, api.ElectionProperties{}, b.err
+	}
+	if b.description.ElectionType.VoteDeposit > 0 && b.description.ElectionType.Anonymous {
+		return nil, nil, api.ElectionProperties{}, fmt.Errorf("vote deposit not supported for anonymous elections")
+	}
+	if b.description.ElectionType.VoteDelegation && b.description.ElectionType.Anonymous {
+		return nil, nil, api.ElectionProperties{}, fmt.Errorf("vote delegation not supported for anonymous elections")
+	}
+	questions := b.description.Questions
+	if len(questions) == 0 {
+		return nil, nil, api.ElectionProperties{}, fmt.Errorf("the election has no questions")
+	}
+	maxChoices := 0
+	for _, question := range questions {
+		if len(question.Choices) < 2 {
+			return nil, nil, api.ElectionProperties{}, fmt.Errorf("every question must have at least two choices")
+		}
+		maxChoices = max(maxChoices, len(question.Choices))
+	}
+	if b.preset != ElectionResultsSingleChoice && len(questions) > 1 {
+		return nil, nil, api.ElectionProperties{}, fmt.Errorf("the %s preset supports a single question", b.preset)
+	}
+	description := b.description
+	resultsType := api.ElectionProperties{Name: b.preset}
+	voteOptions := &models.ProcessVoteOptions{
+		MaxVoteOverwrites: uint32(description.VoteType.MaxVoteOverwrites),
+		CostExponent:      1,
+	}
+	uniqueValues, costFromWeight := false, false
+	switch b.preset {
+	case ElectionResultsSingleChoice:
+		voteOptions.MaxCount = uint32(len(questions))
+		voteOptions.MaxValue = uint32(maxChoices - 1)
+	case ElectionResultsMultipleChoice:
+		if b.numChoices > maxChoices {
+			return nil, nil, api.ElectionProperties{}, fmt.Errorf("cannot choose %d of %d choices", b.numChoices, maxChoices)
+		}
+		voteOptions.MaxCount = uint32(b.numChoices)
+		voteOptions.MaxValue = uint32(maxChoices - 1)
+		uniqueValues = true
+		resultsType.Properties = map[string]any{
+			"canAbstain":   false,
+			"repeatChoice": false,
+			"numChoices":   map[string]int{"min": 1, "max": b.numChoices},
+		}
+	case ElectionResultsRanked:
+		voteOptions.MaxCount = uint32(maxChoices)
+		voteOptions.MaxValue = uint32(maxChoices - 1)
+		uniqueValues = true
+	case ElectionResultsQuadratic:
+		// a zero MaxValue aggregates the votes of each choice, see results.Results.AddVote
+		voteOptions.MaxCount = uint32(maxChoices)
+		voteOptions.MaxTotalCost = b.budget
+		voteOptions.CostExponent = 2
+		resultsType.Properties = map[string]any{
+			"useCensusWeightAsBudget": false,
+			"maxBudget":               b.budget,
+			"minStep":                 1,
+			"forceFullBudget":         false,
+			"quadraticCost":           2,
+		}
+	case ElectionResultsBudget:
+		voteOptions.MaxCount = uint32(maxChoices)
+		costFromWeight = true
+		resultsType.Properties = map[string]any{
+			"useCensusWeightAsBudget": true,
+			"minStep":                 1,
+			"forceFullBudget":         false,
+		}
+	}
+	if voteOptions.MaxCount > results.MaxQuestions {
+		return nil, nil, api.ElectionProperties{}, fmt.Errorf("the vote cannot have more than %d fields, got %d",
+			results.MaxQuestions, voteOptions.MaxCount)
+	}
+	description.VoteType = api.VoteType{
+		UniqueChoices:     uniqueValues,
+		MaxVoteOverwrites: description.VoteType.MaxVoteOverwrites,
+		CostFromWeight:    costFromWeight,
+		CostExponent:      int(voteOptions.CostExponent),
+		MaxCount:          int(voteOptions.MaxCount),
+		MaxValue:          int(voteOptions.MaxValue),
+	}
+	return &description, voteOptions, resultsType, nil
+}
+
+// NewElectionFromBuilder creates the election built by the ElectionBuilder and
+// returns the ElectionID. If wait is true, it will wait until the election is created.
+func (c *HTTPclient) NewElectionFromBuilder(b *ElectionBuilder, wait bool) (types.HexBytes, error) {
+	description, voteOptions, resultsType, err := b.build()
+	if err != nil {
+		return nil, err
+	}
+	return c.newElection(description, voteOptions, resultsType, wait)
+}
@@ -0,0 +1,233 @@
+package apiclient_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/apiclient/apiclienttest"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/types"
+)
+
+func TestElectionBuilder(t *testing.T) {
+	endDate := time.Now().Add(time.Hour)
+	newBuilder := func() *apiclient.ElectionBuilder {
+		return apiclient.NewElectionBuilder("test", endDate).Question("question", "a", "b", "c")
+	}
+	choices := func(n int) []string {
+		var choices []string
+		for i := range n {
+			choices = append(choices, fmt.Sprint(i))
+		}
+		return choices
+	}
+	questions := func(n int) *apiclient.ElectionBuilder {
+		b := apiclient.NewElectionBuilder("test", endDate)
+		for i := range n {
+			b.Question(fmt.Sprint(i), "yes", "no")
+		}
+		return b
+	}
+	for _, tc := range []struct {
+		name     string
+		builder  *apiclient.ElectionBuilder
+		voteType api.VoteType
+		err      string
+	}{
+		{
+			name:     "single choice",
+			builder:  newBuilder().Question("other", "yes", "no"),
+			voteType: api.VoteType{CostExponent: 1, MaxCount: 2, MaxValue: 2},
+		},
+		{
+			name:     "multiple choice",
+			builder:  newBuilder().MultiChoice(2).MaxVoteOverwrites(3),
+			voteType: api.VoteType{UniqueChoices: true, MaxVoteOverwrites: 3, CostExponent: 1, MaxCount: 2, MaxValue: 2},
+		},
+		{
+			name:     "ranked",
+			builder:  newBuilder().Ranked(),
+			voteType: api.VoteType{UniqueChoices: true, CostExponent: 1, MaxCount: 3, MaxValue: 2},
+		},
+		{
+			name:     "quadratic",
+			builder:  newBuilder().Quadratic(10),
+			voteType: api.VoteType{CostExponent: 2, MaxCount: 3},
+		},
+		{
+			name:     "weighted budget",
+			builder:  newBuilder().WeightedBudget(),
+			voteType: api.VoteType{CostFromWeight: true, CostExponent: 1, MaxCount: 3},
+		},
+		{
+			name:     "questions of different sizes",
+			builder:  newBuilder().Question("other", "a", "b", "c", "d", "e"),
+			voteType: api.VoteType{CostExponent: 1, MaxCount: 2, MaxValue: 4},
+		},
+		{
+			name:     "all the choices",
+			builder:  newBuilder().MultiChoice(3),
+			voteType: api.VoteType{UniqueChoices: true, CostExponent: 1, MaxCount: 3, MaxValue: 2},
+		},
+		{
+			name:     "last preset",
+			builder:  newBuilder().Ranked().SingleChoice(),
+			voteType: api.VoteType{CostExponent: 1, MaxCount: 1, MaxValue: 2},
+		},
+		{
+			name:    "no questions",
+			builder: apiclient.NewElectionBuilder("test", endDate),
+			err:     "the election has no questions",
+		},
+		{
+			name:    "one choice",
+			builder: newBuilder().Question("other", "yes"),
+			err:     "every question must have at least two choices",
+		},
+		{
+			name:    "several questions",
+			builder: newBuilder().Question("other", "yes", "no").Ranked(),
+			err:     "the ranked preset supports a single question",
+		},
+		{
+			name:    "too many choices",
+			builder: newBuilder().MultiChoice(4),
+			err:     "cannot choose 4 of 3 choices",
+		},
+		{
+			name:    "no choices",
+			builder: newBuilder().MultiChoice(0),
+			err:     "the number of choices must be positive, got 0",
+		},
+		{
+			name:    "no budget",
+			builder: newBuilder().Quadratic(0),
+			err:     "the quadratic budget must be positive",
+		},
+		{
+			name:    "too many choices to rank",
+			builder: apiclient.NewElectionBuilder("test", endDate).Question("many", choices(65)...).Ranked(),
+			err:     "the vote cannot have more than 64 fields, got 65",
+		},
+		{
+			name:    "too many questions",
+			builder: questions(65),
+			err:     "the vote cannot have more than 64 fields, got 65",
+		},
+		{
+			name:    "errors of several presets",
+			builder: newBuilder().MultiChoice(0).Quadratic(0),
+			err:     "the number of choices must be positive, got 0\nthe quadratic budget must be positive",
+		},
+		{
+			name:    "error of a replaced preset",
+			builder: newBuilder().MultiChoice(-1).SingleChoice(),
+			err:     "the number of choices must be positive, got -1",
+		},
+		{
+			name:    "anonymous deposit",
+			builder: newBuilder().Anonymous().VoteDeposit(1),
+			err:     "vote deposit not supported for anonymous elections",
+		},
+		{
+			name:    "anonymous delegation",
+			builder: newBuilder().Anonymous().VoteDelegation(),
+			err:     "vote delegation not supported for anonymous elections",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			description, err := tc.builder.Build()
+			if tc.err != "" {
+				qt.Assert(t, err, qt.ErrorMatches, tc.err)
+				return
+			}
+			qt.Assert(t, err, qt.IsNil)
+			qt.Assert(t, description.VoteType, qt.DeepEquals, tc.voteType)
+			qt.Assert(t, description.EndDate, qt.Equals, endDate)
+		})
+	}
+}
+
+func TestNewElectionFromBuilder(t *testing.T) {
+	c := qt.New(t)
+	gw := apiclienttest.NewGateway(t)
+	organizer := ethereum.NewSignKeys()
+	c.Assert(organizer.Generate(), qt.IsNil)
+	cli := gw.NewClient(t, hex.EncodeToString(organizer.PrivateKey()))
+	_, err := cli.AccountBootstrap(nil, nil, nil)
+	c.Assert(err, qt.IsNil)
+	censusID, err := cli.NewCensus(api.CensusTypeWeighted)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cli.CensusAddParticipants(censusID, &api.CensusParticipants{Participants: []api.CensusParticipant{{
+		Key: organizer.Address().Bytes(), Weight: new(types.BigInt).SetUint64(1),
+	}}}), qt.IsNil)
+	root, uri, err := cli.CensusPublish(censusID)
+	c.Assert(err, qt.IsNil)
+	census := api.CensusTypeDescription{Type: api.CensusTypeWeighted, RootHash: root, URL: uri, Size: 1}
+	create := func(b *apiclient.ElectionBuilder) (*api.Election, *api.ElectionMetadata) {
+		electionID, err := cli.NewElectionFromBuilder(b.Census(census), true)
+		c.Assert(err, qt.IsNil)
+		election, err := cli.Election(electionID)
+		c.Assert(err, qt.IsNil)
+		data, err := json.Marshal(election.Metadata)
+		c.Assert(err, qt.IsNil)
+		metadata := &api.ElectionMetadata{}
+		c.Assert(json.Unmarshal(data, metadata), qt.IsNil)
+		return election, metadata
+	}
+
+	// the vote options of the preset are the ones of the election, and its
+	// results type the one of the metadata
+	election, metadata := create(apiclient.NewElectionBuilder("quadratic", time.Now().Add(time.Hour)).
+		Question("projects", "park", "library", "school").
+		Quadratic(10))
+	c.Assert(election.TallyMode.MaxCount, qt.Equals, uint32(3))
+	c.Assert(election.TallyMode.MaxValue, qt.Equals, uint32(0))
+	c.Assert(election.TallyMode.MaxTotalCost, qt.Equals, uint32(10))
+	c.Assert(election.TallyMode.CostExponent, qt.Equals, uint32(2))
+	c.Assert(metadata.Type.Name, qt.Equals, apiclient.ElectionResultsQuadratic)
+	c.Assert(metadata.Type.Properties, qt.DeepEquals, map[string]any{
+		"useCensusWeightAsBudget": false,
+		"maxBudget":               float64(10),
+		"minStep":                 float64(1),
+		"forceFullBudget":         false,
+		"quadraticCost":           float64(2),
+	})
+
+	election, metadata = create(apiclient.NewElectionBuilder("ranked", time.Now().Add(time.Hour)).
+		Question("projects", "park", "library", "school").
+		Ranked().
+		MaxVoteOverwrites(2))
+	c.Assert(election.VoteMode.UniqueValues, qt.IsTrue)
+	c.Assert(election.TallyMode.MaxCount, qt.Equals, uint32(3))
+	c.Assert(election.TallyMode.MaxValue, qt.Equals, uint32(2))
+	c.Assert(election.TallyMode.MaxVoteOverwrites, qt.Equals, uint32(2))
+	c.Assert(metadata.Type.Name, qt.Equals, apiclient.ElectionResultsRanked)
+
+	// the weighted budget is the census weight, not a fixed total cost
+	election, metadata = create(apiclient.NewElectionBuilder("budget", time.Now().Add(time.Hour)).
+		Question("projects", "park", "library").
+		WeightedBudget())
+	c.Assert(election.VoteMode.CostFromWeight, qt.IsTrue)
+	c.Assert(election.TallyMode.MaxTotalCost, qt.Equals, uint32(0))
+	c.Assert(metadata.Type.Properties, qt.DeepEquals, map[string]any{
+		"useCensusWeightAsBudget": true,
+		"minStep":                 float64(1),
+		"forceFullBudget":         false,
+	})
+
+	// the errors of the builder are returned before sending the election
+	height := gw.Height()
+	_, err = cli.NewElectionFromBuilder(apiclient.NewElectionBuilder("empty", time.Now().Add(time.Hour)).Census(census), true)
+	c.Assert(err, qt.ErrorMatches, "the election has no questions")
+	_, err = cli.NewElectionFromBuilder(apiclient.NewElectionBuilder("past", time.Now().Add(-time.Hour)).
+		Census(census).Question("q", "yes", "no"), true)
+	c.Assert(err, qt.ErrorMatches, "election end date cannot be in the past")
+	c.Assert(gw.Height(), qt.Equals, height)
+}
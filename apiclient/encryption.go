@@ -0,0 +1,156 @@
+package apiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/crypto/nacl"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain/state"
+)
+
+var (
+	// ErrNoEncryptionKeys is returned when an encrypted vote package cannot be
+	// built because the election has no encryption public keys.
+	ErrNoEncryptionKeys = fmt.Errorf("no encryption keys")
+	// ErrElectionKeysNotRevealed is returned by ElectionPrivateKeys when the
+	// election has not published its encryption private keys yet, which
+	// happens once it has ended.
+	ErrElectionKeysNotRevealed = fmt.Errorf("election encryption private keys not revealed yet")
+)
+
+// EncryptVotePackage encrypts the vote package for the encryption public keys
+// of an election, as returned by EncryptionKeys, in the format expected by the
+// vochain: the JSON encoded package is sealed with each key in turn, in the
+// order of the keys. It returns the encrypted vote package and the indexes of
+// the keys used, to be set as the EncryptionKeyIndexes of the vote envelope.
+// The Nonce of vp is set to a random value, so that equal choices do not
+// produce comparable packages once decrypted.
+func EncryptVotePackage(vp *state.VotePackage, keys []api.Key) ([]byte, []uint32, error) {
+	var keyIndexes []uint32
+	for _, k := range keys {
+		if len(k.Key) > 0 {
+			keyIndexes = append(keyIndexes, uint32(k.Index))
+		}
+	}
+	if len(keyIndexes) == 0 {
+		return nil, nil, ErrNoEncryptionKeys
+	}
+
+	vp.Nonce = fmt.Sprintf("%x", util.RandomHex(32))
+	vpb, err := json.Marshal(vp)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, k := range keys {
+		if len(k.Key) == 0 {
+			continue
+		}
+		log.Debugw("encrypting vote", "nonce", vp.Nonce, "key", k.Key)
+		pub, err := nacl.DecodePublic(k.Key.String())
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot decode encryption key with index %d: (%s)", k.Index, err)
+		}
+		if vpb, err = nacl.Anonymous.Encrypt(vpb, pub); err != nil {
+			return nil, nil, fmt.Errorf("cannot encrypt: (%s)", err)
+		}
+	}
+	return vpb, keyIndexes, nil
+}
+
+// DecryptVotePackage decrypts a vote package encrypted by EncryptVotePackage
+// with the public keys of the given indexes, using the private keys the
+// election publishes once it has ended, as returned by ElectionPrivateKeys.
+func DecryptVotePackage(votePackage []byte, keyIndexes []uint32, privateKeys []api.Key) (*state.VotePackage, error) {
+	vpb := bytes.Clone(votePackage)
+	for _, index := range slices.Backward(keyIndexes) {
+		i := slices.IndexFunc(privateKeys, func(k api.Key) bool { return k.Index == int(index) })
+		if i < 0 || len(privateKeys[i].Key) == 0 {
+			return nil, fmt.Errorf("missing private key with index %d", index)
+		}
+		priv, err := nacl.DecodePrivate(privateKeys[i].Key.String())
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode private key with index %d: (%s)", index, err)
+		}
+		if vpb, err = priv.Decrypt(vpb); err != nil {
+			return nil, fmt.Errorf("cannot decrypt vote package with key index %d: %w", index, err)
+		}
+	}
+	vp := &state.VotePackage{}
+	if err := vp.Decode(vpb); err != nil {
+		return nil, fmt.Errorf("cannot decode vote package: %w", err)
+	}
+	return vp, nil
+}
+
+// VerifyElectionKeys checks that each private key of the election keys is the
+// one of the public key with the same index, so that the published private keys
+// decrypt the votes encrypted with the public keys.
+func VerifyElectionKeys(keys *api.ElectionKeys) error {
+	for _, priv := range keys.PrivateKeys {
+		if len(priv.Key) == 0 {
+			continue
+		}
+		i := slices.IndexFunc(keys.PublicKeys, func(k api.Key) bool { return k.Index == priv.Index })
+		if i < 0 {
+			return fmt.Errorf("private key with index %d has no public key", priv.Index)
+		}
+		cipher, err := nacl.DecodePrivate(priv.Key.String())
+		if err != nil {
+			return fmt.Errorf("cannot decode private key with index %d: (%s)", priv.Index, err)
+		}
+		if !bytes.Equal(cipher.Public().Bytes(), keys.PublicKeys[i].Key) {
+			return fmt.Errorf("private key with index %d does not match its public key", priv.Index)
+		}
+	}
+	return nil
+}
+
+// ElectionPrivateKeys returns the encryption private keys published by the
+// election once it has ended, after checking them with VerifyElectionKeys. If
+// they are not published yet, ErrElectionKeysNotRevealed is returned.
+func (c *HTTPclient) ElectionPrivateKeys(electionID types.HexBytes) ([]api.Key, error) {
+	keys, err := c.ElectionKeys(electionID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(keys.PrivateKeys, func(k api.Key) bool { return len(k.Key) > 0 }) {
+		return nil, ErrElectionKeysNotRevealed
+	}
+	if err := VerifyElectionKeys(keys); err != nil {
+		return nil, err
+	}
+	return keys.PrivateKeys, nil
+}
+
+// DecryptVote returns the vote package of an encrypted vote, given its ID (the
+// nullifier), decrypting it with the private keys published by its election.
+// Unencrypted votes, or the ones already decrypted by the API server, are
+// returned as they are.
+func (c *HTTPclient) DecryptVote(voteID types.HexBytes) (*state.VotePackage, error) {
+	vote, err := c.Endpoints().GetVote(voteID.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// the API server sends the packages it cannot decrypt as {"encrypted": <base64>}
+	var encrypted struct {
+		Encrypted []byte `json:"encrypted"`
+	}
+	if err := json.Unmarshal(vote.VotePackage, &encrypted); err != nil || encrypted.Encrypted == nil {
+		vp := &state.VotePackage{}
+		if err := vp.Decode(vote.VotePackage); err != nil {
+			return nil, fmt.Errorf("cannot decode vote package: %w", err)
+		}
+		return vp, nil
+	}
+	privateKeys, err := c.ElectionPrivateKeys(vote.ElectionID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt vote %x: %w", voteID, err)
+	}
+	return DecryptVotePackage(encrypted.Encrypted, vote.EncryptionKeyIndexes, privateKeys)
+}
@@ -0,0 +1,195 @@
+package apiclient_test
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/crypto/nacl"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain/state"
+)
+
+// testElectionKeys returns the keys of an election with n encryption keys, at
+// the odd indexes.
+func testElectionKeys(t *testing.T, n int) *api.ElectionKeys {
+	keys := &api.ElectionKeys{}
+	for i := range n {
+		cipher, err := nacl.Generate(rand.Reader)
+		qt.Assert(t, err, qt.IsNil)
+		keys.PublicKeys = append(keys.PublicKeys, api.Key{Index: 2*i + 1, Key: cipher.Public().Bytes()})
+		keys.PrivateKeys = append(keys.PrivateKeys, api.Key{Index: 2*i + 1, Key: cipher.Bytes()})
+	}
+	return keys
+}
+
+func TestEncryptVotePackage(t *testing.T) {
+	c := qt.New(t)
+	keys := testElectionKeys(t, 2)
+	c.Assert(apiclient.VerifyElectionKeys(keys), qt.IsNil)
+
+	// the missing keys are skipped
+	publicKeys := append([]api.Key{{Index: 0}}, keys.PublicKeys...)
+	vp := state.NewVotePackage([]int{1, 0, 2})
+	encrypted, indexes, err := apiclient.EncryptVotePackage(vp, publicKeys)
+	c.Assert(err, qt.IsNil)
+	c.Assert(indexes, qt.DeepEquals, []uint32{1, 3})
+	c.Assert(vp.Nonce, qt.Not(qt.Equals), "")
+	again, _, err := apiclient.EncryptVotePackage(state.NewVotePackage([]int{1, 0, 2}), publicKeys)
+	c.Assert(err, qt.IsNil)
+	c.Assert(again, qt.Not(qt.DeepEquals), encrypted)
+
+	// the keys are removed in the reverse order, so all of them are needed
+	decrypted, err := apiclient.DecryptVotePackage(encrypted, indexes, keys.PrivateKeys)
+	c.Assert(err, qt.IsNil)
+	c.Assert(decrypted.Votes, qt.DeepEquals, []int{1, 0, 2})
+	c.Assert(decrypted.Nonce, qt.Equals, vp.Nonce)
+	_, err = apiclient.DecryptVotePackage(encrypted, indexes, keys.PrivateKeys[:1])
+	c.Assert(err, qt.ErrorMatches, "missing private key with index 3")
+	_, err = apiclient.DecryptVotePackage(encrypted, indexes, []api.Key{keys.PrivateKeys[0], {Index: 3}})
+	c.Assert(err, qt.ErrorMatches, "missing private key with index 3")
+	_, err = apiclient.DecryptVotePackage(encrypted, []uint32{3, 1}, keys.PrivateKeys)
+	c.Assert(err, qt.ErrorMatches, "cannot decrypt vote package with key index 1: .*")
+	_, err = apiclient.DecryptVotePackage(encrypted, indexes[1:], keys.PrivateKeys)
+	c.Assert(err, qt.ErrorMatches, "cannot decode vote package: .*")
+	_, err = apiclient.DecryptVotePackage(encrypted[:len(encrypted)-1], indexes, keys.PrivateKeys)
+	c.Assert(err, qt.ErrorMatches, "cannot decrypt vote package with key index 3: .*")
+	_, err = apiclient.DecryptVotePackage(encrypted, indexes, []api.Key{keys.PrivateKeys[0], {Index: 3, Key: []byte{1}}})
+	c.Assert(err, qt.ErrorMatches, `cannot decode private key with index 3: \(.*\)`)
+
+	// a package encrypted with a single key, or with none, is decrypted too
+	single, indexes, err := apiclient.EncryptVotePackage(state.NewVotePackage([]int{4}), keys.PublicKeys[1:])
+	c.Assert(err, qt.IsNil)
+	c.Assert(indexes, qt.DeepEquals, []uint32{3})
+	decrypted, err = apiclient.DecryptVotePackage(single, indexes, keys.PrivateKeys)
+	c.Assert(err, qt.IsNil)
+	c.Assert(decrypted.Votes, qt.DeepEquals, []int{4})
+	plain, err := state.NewVotePackage([]int{5}).Encode()
+	c.Assert(err, qt.IsNil)
+	decrypted, err = apiclient.DecryptVotePackage(plain, nil, nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(decrypted.Votes, qt.DeepEquals, []int{5})
+
+	// the package cannot be encrypted without valid keys
+	for _, publicKeys := range [][]api.Key{nil, {{Index: 0}, {Index: 1, Key: []byte{}}}} {
+		_, _, err = apiclient.EncryptVotePackage(vp, publicKeys)
+		c.Assert(err, qt.ErrorIs, apiclient.ErrNoEncryptionKeys)
+	}
+	_, _, err = apiclient.EncryptVotePackage(vp, []api.Key{keys.PublicKeys[0], {Index: 5, Key: []byte{1, 2}}})
+	c.Assert(err, qt.ErrorMatches, `cannot decode encryption key with index 5: \(.*\)`)
+}
+
+func TestVerifyElectionKeys(t *testing.T) {
+	c := qt.New(t)
+	keys := testElectionKeys(t, 2)
+
+	// the keys not published yet are not checked
+	c.Assert(apiclient.VerifyElectionKeys(&api.ElectionKeys{PublicKeys: keys.PublicKeys}), qt.IsNil)
+	c.Assert(apiclient.VerifyElectionKeys(&api.ElectionKeys{
+		PublicKeys:  keys.PublicKeys,
+		PrivateKeys: []api.Key{{Index: 1}, keys.PrivateKeys[1]},
+	}), qt.IsNil)
+
+	// but the published ones must match the public keys
+	other := testElectionKeys(t, 1)
+	mismatched := &api.ElectionKeys{PublicKeys: keys.PublicKeys, PrivateKeys: other.PrivateKeys}
+	c.Assert(apiclient.VerifyElectionKeys(mismatched), qt.ErrorMatches,
+		"private key with index 1 does not match its public key")
+	swapped := &api.ElectionKeys{
+		PublicKeys:  keys.PublicKeys,
+		PrivateKeys: []api.Key{{Index: 1, Key: keys.PrivateKeys[1].Key}, {Index: 3, Key: keys.PrivateKeys[0].Key}},
+	}
+	c.Assert(apiclient.VerifyElectionKeys(swapped), qt.ErrorMatches,
+		"private key with index 1 does not match its public key")
+	orphan := &api.ElectionKeys{PrivateKeys: []api.Key{{Index: 7, Key: keys.PrivateKeys[0].Key}}}
+	c.Assert(apiclient.VerifyElectionKeys(orphan), qt.ErrorMatches, "private key with index 7 has no public key")
+	invalid := &api.ElectionKeys{PublicKeys: keys.PublicKeys, PrivateKeys: []api.Key{{Index: 1, Key: []byte{1}}}}
+	c.Assert(apiclient.VerifyElectionKeys(invalid), qt.ErrorMatches, `cannot decode private key with index 1: \(.*\)`)
+}
+
+func TestDecryptVote(t *testing.T) {
+	c := qt.New(t)
+	keys := testElectionKeys(t, 1)
+	encrypted, indexes, err := apiclient.EncryptVotePackage(state.NewVotePackage([]int{2, 1}), keys.PublicKeys)
+	c.Assert(err, qt.IsNil)
+	encryptedPackage, err := json.Marshal(map[string][]byte{"encrypted": encrypted})
+	c.Assert(err, qt.IsNil)
+	plainPackage, err := state.NewVotePackage([]int{0, 1}).Encode()
+	c.Assert(err, qt.IsNil)
+	electionID := types.HexBytes(util.RandomBytes(32))
+	encryptedID, plainID := types.HexBytes(util.RandomBytes(32)), types.HexBytes(util.RandomBytes(32))
+	corruptID := types.HexBytes(util.RandomBytes(32))
+	// the private keys published by the election, none until it ends
+	var published []api.Key
+	keysAvailable := true
+
+	reply := func(w http.ResponseWriter, v any) {
+		c.Assert(json.NewEncoder(w).Encode(v), qt.IsNil)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /votes/{voteId}", func(w http.ResponseWriter, r *http.Request) {
+		vote := &api.Vote{ElectionID: electionID}
+		switch r.PathValue("voteId") {
+		case plainID.String():
+			vote.VotePackage = plainPackage
+		case encryptedID.String():
+			vote.VotePackage, vote.EncryptionKeyIndexes = encryptedPackage, indexes
+		case corruptID.String():
+			vote.VotePackage = json.RawMessage(`"votes"`)
+		default:
+			http.Error(w, "vote not found", http.StatusNotFound)
+			return
+		}
+		reply(w, vote)
+	})
+	mux.HandleFunc("GET /elections/{electionId}/keys", func(w http.ResponseWriter, _ *http.Request) {
+		if !keysAvailable {
+			http.Error(w, "election not found", http.StatusNotFound)
+			return
+		}
+		reply(w, &api.ElectionKeys{PublicKeys: keys.PublicKeys, PrivateKeys: published})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	cli, err := apiclient.New(srv.URL + "/")
+	c.Assert(err, qt.IsNil)
+
+	// the votes which are not encrypted, or were decrypted by the API server,
+	// are returned as they are, without the keys of the election
+	keysAvailable = false
+	vp, err := cli.DecryptVote(plainID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(vp.Votes, qt.DeepEquals, []int{0, 1})
+	_, err = cli.DecryptVote(corruptID)
+	c.Assert(err, qt.ErrorMatches, "cannot decode vote package: .*")
+	_, err = cli.DecryptVote(util.RandomBytes(32))
+	c.Assert(err, qt.ErrorMatches, "(?s).*vote not found.*")
+
+	// the encrypted votes need the keys of the election
+	_, err = cli.DecryptVote(encryptedID)
+	c.Assert(err, qt.ErrorMatches, "(?s)cannot decrypt vote "+encryptedID.String()+": .*election not found.*")
+	keysAvailable = true
+	_, err = cli.DecryptVote(encryptedID)
+	c.Assert(err, qt.ErrorIs, apiclient.ErrElectionKeysNotRevealed)
+	published = []api.Key{{Index: 1}}
+	_, err = cli.DecryptVote(encryptedID)
+	c.Assert(err, qt.ErrorIs, apiclient.ErrElectionKeysNotRevealed)
+
+	// which are not trusted unless they match the public keys
+	published = testElectionKeys(t, 1).PrivateKeys
+	_, err = cli.DecryptVote(encryptedID)
+	c.Assert(err, qt.ErrorMatches, "cannot decrypt vote .*: private key with index 1 does not match its public key")
+	published = keys.PrivateKeys
+	privateKeys, err := cli.ElectionPrivateKeys(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(privateKeys, qt.DeepEquals, keys.PrivateKeys)
+	vp, err = cli.DecryptVote(encryptedID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(vp.Votes, qt.DeepEquals, []int{2, 1})
+}
@@ -0,0 +1,53 @@
+package apiclient
+
+//go:generate go run ./apigen -api ../api -out endpoints_gen.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// APIError is returned by the Endpoints methods when the API replies with a
+// non successful status code.
+type APIError struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %d (%s)", errCodeNot200, e.StatusCode, e.Body)
+}
+
+// Endpoints holds the typed bindings of the API endpoints, generated from the
+// api package annotations (see endpoints_gen.go). They are a thin layer over
+// Request, so the HTTPclient helpers are recommended when available.
+type Endpoints struct {
+	c *HTTPclient
+}
+
+// Endpoints returns the typed bindings of the API endpoints.
+func (c *HTTPclient) Endpoints() *Endpoints {
+	return &Endpoints{c: c}
+}
+
+// do performs the request and decodes the response into resp, if not nil.
+// A *[]byte resp gets the raw response.
+func (e *Endpoints) do(method string, body any, query url.Values, resp any, urlPath ...string) error {
+	data, code, err := e.c.RequestWithQuery(method, body, query, urlPath...)
+	if err != nil {
+		return err
+	}
+	if code < 200 || code >= 300 {
+		return &APIError{StatusCode: code, Body: data}
+	}
+	switch r := resp.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*r = data
+		return nil
+	}
+	return json.Unmarshal(data, resp)
+}
@@ -0,0 +1,2862 @@
+// Code generated by apigen. DO NOT EDIT.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/api/censusdb"
+	"go.vocdoni.io/dvote/crypto/zk/circuit"
+	"go.vocdoni.io/dvote/vochain/genesis"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/results/resultsverify"
+	"go.vocdoni.io/dvote/vochain/state/electionprice"
+	"go.vocdoni.io/dvote/vochain/txaudit"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// AccountParams holds the query parameters of Account.
+type AccountParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *AccountParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// Account calls GET /accounts/{address}
+//
+// Get account.
+func (e *Endpoints) Account(address string, params *AccountParams) (*api.Account, error) {
+	resp := &api.Account{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", address); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountMetadataParams holds the query parameters of AccountMetadata.
+type AccountMetadataParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *AccountMetadataParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// AccountMetadata calls GET /accounts/{address}/metadata
+//
+// Get account.
+func (e *Endpoints) AccountMetadata(address string, params *AccountMetadataParams) (*api.AccountMetadata, error) {
+	resp := &api.AccountMetadata{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", address, "metadata"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountSetRequest is the request body of AccountSet.
+type AccountSetRequest struct {
+	TxPayload string `json:"txPayload"`
+	Metadata  string `json:"metadata"`
+}
+
+// AccountSet calls POST /accounts
+//
+// Set account.
+func (e *Endpoints) AccountSet(body *AccountSetRequest) (*api.AccountSet, error) {
+	resp := &api.AccountSet{}
+	if err := e.do(HTTPPOST, body, nil, resp, "accounts"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountElectionsCountParams holds the query parameters of AccountElectionsCount.
+type AccountElectionsCountParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *AccountElectionsCountParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// AccountElectionsCount calls GET /accounts/{organizationId}/elections/count
+//
+// Count organization elections.
+func (e *Endpoints) AccountElectionsCount(organizationID string, params *AccountElectionsCountParams) (*api.CountResult, error) {
+	resp := &api.CountResult{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", organizationID, "elections", "count"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountElectionsListByStatusAndPageParams holds the query parameters of AccountElectionsListByStatusAndPage.
+type AccountElectionsListByStatusAndPageParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *AccountElectionsListByStatusAndPageParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// AccountElectionsListByStatusAndPage calls GET /accounts/{organizationId}/elections/status/{status}/page/{page}
+//
+// List organization elections by status.
+func (e *Endpoints) AccountElectionsListByStatusAndPage(organizationID string, status string, page int64, params *AccountElectionsListByStatusAndPageParams) (*api.ElectionsList, error) {
+	resp := &api.ElectionsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", organizationID, "elections", "status", status, "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountElectionsListByPageParams holds the query parameters of AccountElectionsListByPage.
+type AccountElectionsListByPageParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *AccountElectionsListByPageParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// AccountElectionsListByPage calls GET /accounts/{organizationId}/elections/page/{page}
+//
+// List organization elections.
+func (e *Endpoints) AccountElectionsListByPage(organizationID string, page int64, params *AccountElectionsListByPageParams) (*api.ElectionsList, error) {
+	resp := &api.ElectionsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", organizationID, "elections", "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// TokenTransfersListParams holds the query parameters of TokenTransfersList.
+type TokenTransfersListParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *TokenTransfersListParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// TokenTransfersList calls GET /accounts/{accountId}/transfers/page/{page}
+//
+// List account received and sent token transfers.
+func (e *Endpoints) TokenTransfersList(accountID string, page int64, params *TokenTransfersListParams) (*api.TransfersList, error) {
+	resp := &api.TransfersList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", accountID, "transfers", "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// TokenFeesParams holds the query parameters of TokenFees.
+type TokenFeesParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *TokenFeesParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// TokenFees calls GET /accounts/{accountId}/fees/page/{page}
+//
+// List account token fees.
+func (e *Endpoints) TokenFees(accountID string, page int64, params *TokenFeesParams) (*api.FeesList, error) {
+	resp := &api.FeesList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", accountID, "fees", "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// TokenTransfersCountParams holds the query parameters of TokenTransfersCount.
+type TokenTransfersCountParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *TokenTransfersCountParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// TokenTransfersCount calls GET /accounts/{accountId}/transfers/count
+//
+// Total number of sent and received transactions.
+func (e *Endpoints) TokenTransfersCount(accountID string, params *TokenTransfersCountParams) (*api.CountResult, error) {
+	resp := &api.CountResult{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", accountID, "transfers", "count"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountTxAudit calls GET /accounts/{address}/txaudit
+//
+// Account transactions audit.
+func (e *Endpoints) AccountTxAudit(address string) (*txaudit.AccountAudit, error) {
+	resp := &txaudit.AccountAudit{}
+	if err := e.do(HTTPGET, nil, nil, resp, "accounts", address, "txaudit"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountKVParams holds the query parameters of AccountKV.
+type AccountKVParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *AccountKVParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// AccountKV calls GET /accounts/{address}/kv
+//
+// Account key-value store.
+func (e *Endpoints) AccountKV(address string, params *AccountKVParams) (*api.AccountKVList, error) {
+	resp := &api.AccountKVList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", address, "kv"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountRecoveryParams holds the query parameters of AccountRecovery.
+type AccountRecoveryParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *AccountRecoveryParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// AccountRecovery calls GET /accounts/{address}/recovery
+//
+// Account recovery.
+func (e *Endpoints) AccountRecovery(address string, params *AccountRecoveryParams) (*api.AccountRecovery, error) {
+	resp := &api.AccountRecovery{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", address, "recovery"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountFeedParams holds the query parameters of AccountFeed.
+type AccountFeedParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Cursor returned as nextCursor by the previous page (page is ignored)
+	Cursor string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *AccountFeedParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.Cursor != "" {
+		v.Set("cursor", p.Cursor)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// AccountFeed calls GET /accounts/{address}/feed
+//
+// Account activity feed.
+func (e *Endpoints) AccountFeed(address string, params *AccountFeedParams) (*api.AccountFeed, error) {
+	resp := &api.AccountFeed{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", address, "feed"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountTransactionsParams holds the query parameters of AccountTransactions.
+type AccountTransactionsParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Cursor returned as nextCursor by the previous page (page is ignored)
+	Cursor string
+	// Tx type
+	Type string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *AccountTransactionsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.Cursor != "" {
+		v.Set("cursor", p.Cursor)
+	}
+	if p.Type != "" {
+		v.Set("type", p.Type)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// AccountTransactions calls GET /accounts/{address}/transactions
+//
+// Transactions sent by an account.
+func (e *Endpoints) AccountTransactions(address string, params *AccountTransactionsParams) (*api.TransactionsList, error) {
+	resp := &api.TransactionsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", address, "transactions"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountCountParams holds the query parameters of AccountCount.
+type AccountCountParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *AccountCountParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// AccountCount calls GET /accounts/count
+//
+// Total number of accounts.
+func (e *Endpoints) AccountCount(params *AccountCountParams) (*api.CountResult, error) {
+	resp := &api.CountResult{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", "count"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountListByPageParams holds the query parameters of AccountListByPage.
+type AccountListByPageParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *AccountListByPageParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// AccountListByPage calls GET /accounts/page/{page}
+//
+// List of the existing accounts.
+func (e *Endpoints) AccountListByPage(page int64, params *AccountListByPageParams) (*api.AccountsList, error) {
+	resp := &api.AccountsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts", "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccountListParams holds the query parameters of AccountList.
+type AccountListParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Filter by partial accountId
+	AccountID string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *AccountListParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.AccountID != "" {
+		v.Set("accountId", p.AccountID)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// AccountList calls GET /accounts
+//
+// List of the existing accounts.
+func (e *Endpoints) AccountList(params *AccountListParams) (*api.AccountsList, error) {
+	resp := &api.AccountsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "accounts"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Batch calls POST /batch
+//
+// Execute a batch of requests.
+func (e *Endpoints) Batch(body *api.BatchRequest) (*api.BatchResponse, error) {
+	resp := &api.BatchResponse{}
+	if err := e.do(HTTPPOST, body, nil, resp, "batch"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Capabilities calls GET /capabilities
+//
+// Gateway capabilities.
+func (e *Endpoints) Capabilities() (*api.Capabilities, error) {
+	resp := &api.Capabilities{}
+	if err := e.do(HTTPGET, nil, nil, resp, "capabilities"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusCreateResponse is the response of CensusCreate.
+type CensusCreateResponse struct {
+	CensusID string `json:"censusId"`
+}
+
+// CensusCreate calls POST /censuses/{type}
+//
+// Create a new census.
+func (e *Endpoints) CensusCreate(typeParam string) (*CensusCreateResponse, error) {
+	resp := &CensusCreateResponse{}
+	if err := e.do(HTTPPOST, nil, nil, resp, "censuses", typeParam); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusAdd calls POST /censuses/{censusId}/participants
+//
+// Add participants to census.
+func (e *Endpoints) CensusAdd(censusID string, body *api.CensusParticipants) error {
+	return e.do(HTTPPOST, body, nil, nil, "censuses", censusID, "participants")
+}
+
+// CensusTypeResponse is the response of CensusType.
+type CensusTypeResponse struct {
+	Census string `json:"census"`
+}
+
+// CensusType calls GET /censuses/{censusId}/type
+//
+// Get type of census.
+func (e *Endpoints) CensusType(censusID string) (*CensusTypeResponse, error) {
+	resp := &CensusTypeResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "censuses", censusID, "type"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusRootResponse is the response of CensusRoot.
+type CensusRootResponse struct {
+	Root string `json:"root"`
+}
+
+// CensusRoot calls GET /censuses/{censusId}/root
+//
+// Census Merkle Root.
+func (e *Endpoints) CensusRoot(censusID string) (*CensusRootResponse, error) {
+	resp := &CensusRootResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "censuses", censusID, "root"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusDump calls GET /censuses/{censusId}/export
+//
+// Export census.
+func (e *Endpoints) CensusDump(censusID string) (*censusdb.CensusDump, error) {
+	resp := &censusdb.CensusDump{}
+	if err := e.do(HTTPGET, nil, nil, resp, "censuses", censusID, "export"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusImport calls POST /censuses/{censusId}/import
+//
+// Import census.
+func (e *Endpoints) CensusImport(censusID string) error {
+	return e.do(HTTPPOST, nil, nil, nil, "censuses", censusID, "import")
+}
+
+// CensusWeightResponse is the response of CensusWeight.
+type CensusWeightResponse struct {
+	Weight string `json:"weight"`
+}
+
+// CensusWeight calls GET /censuses/{censusId}/weight
+//
+// Census total weight.
+func (e *Endpoints) CensusWeight(censusID string) (*CensusWeightResponse, error) {
+	resp := &CensusWeightResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "censuses", censusID, "weight"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusSizeResponse is the response of CensusSize.
+type CensusSizeResponse struct {
+	Size json.Number `json:"size"`
+}
+
+// CensusSize calls GET /censuses/{censusId}/size
+//
+// Census size.
+func (e *Endpoints) CensusSize(censusID string) (*CensusSizeResponse, error) {
+	resp := &CensusSizeResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "censuses", censusID, "size"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusPublishResponse is the response of CensusPublish.
+type CensusPublishResponse struct {
+	Census struct {
+		CensusID string `json:"censusID"`
+		URI      string `json:"uri"`
+	} `json:"census"`
+}
+
+// CensusPublish calls POST /censuses/{censusId}/publish
+//
+// Publish census.
+func (e *Endpoints) CensusPublish(censusID string) (*CensusPublishResponse, error) {
+	resp := &CensusPublishResponse{}
+	if err := e.do(HTTPPOST, nil, nil, resp, "censuses", censusID, "publish"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusPublishAsyncResponse is the response of CensusPublishAsync.
+type CensusPublishAsyncResponse struct {
+	Census struct {
+		CensusID string `json:"censusID"`
+		URI      string `json:"uri"`
+	} `json:"census"`
+}
+
+// CensusPublishAsync calls POST /censuses/{censusId}/publish/async
+//
+// Publish census.
+func (e *Endpoints) CensusPublishAsync(censusID string) (*CensusPublishAsyncResponse, error) {
+	resp := &CensusPublishAsyncResponse{}
+	if err := e.do(HTTPPOST, nil, nil, resp, "censuses", censusID, "publish", "async"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusPublishCheckResponse is the response of CensusPublishCheck.
+type CensusPublishCheckResponse struct {
+	Census struct {
+		CensusID string `json:"censusID"`
+		URI      string `json:"uri"`
+	} `json:"census"`
+}
+
+// CensusPublishCheck calls GET /censuses/{censusId}/check
+//
+// Check census publish status.
+func (e *Endpoints) CensusPublishCheck(censusID string) (*CensusPublishCheckResponse, error) {
+	resp := &CensusPublishCheckResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "censuses", censusID, "check"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusPublishByRootResponse is the response of CensusPublishByRoot.
+type CensusPublishByRootResponse struct {
+	Census struct {
+		CensusID string `json:"censusID"`
+		URI      string `json:"uri"`
+	} `json:"census"`
+}
+
+// CensusPublishByRoot calls POST /censuses/{censusId}/publish/{root}
+//
+// Publish census.
+func (e *Endpoints) CensusPublishByRoot(censusID string, root string) (*CensusPublishByRootResponse, error) {
+	resp := &CensusPublishByRootResponse{}
+	if err := e.do(HTTPPOST, nil, nil, resp, "censuses", censusID, "publish", root); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusDelete calls DELETE /censuses/{censusId}
+//
+// Delete census.
+func (e *Endpoints) CensusDelete(censusID string) error {
+	return e.do(HTTPDELETE, nil, nil, nil, "censuses", censusID)
+}
+
+// CensusProofParams holds the query parameters of CensusProof.
+type CensusProofParams struct {
+	// Type of the key: address (default), secp256k1, ed25519 or p256
+	KeyType string
+}
+
+func (p *CensusProofParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.KeyType != "" {
+		v.Set("keyType", p.KeyType)
+	}
+	return v
+}
+
+// CensusProofResponse is the response of CensusProof.
+type CensusProofResponse struct {
+	Weight json.Number `json:"weight"`
+	Proof  string      `json:"proof"`
+	Value  string      `json:"value"`
+}
+
+// CensusProof calls GET /censuses/{censusId}/proof/{key}
+//
+// Prove key to census.
+func (e *Endpoints) CensusProof(censusID string, key string, params *CensusProofParams) (*CensusProofResponse, error) {
+	resp := &CensusProofResponse{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "censuses", censusID, "proof", key); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusVerifyResponse is the response of CensusVerify.
+type CensusVerifyResponse struct {
+	Valid bool `json:"valid"`
+}
+
+// CensusVerify calls POST /censuses/{censusId}/verify
+//
+// Verify merkle proof.
+func (e *Endpoints) CensusVerify(censusID string) (*CensusVerifyResponse, error) {
+	resp := &CensusVerifyResponse{}
+	if err := e.do(HTTPPOST, nil, nil, resp, "censuses", censusID, "verify"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusExportDBIPFSResponse is the response of CensusExportDBIPFS.
+type CensusExportDBIPFSResponse struct {
+	Valid bool `json:"valid"`
+}
+
+// CensusExportDBIPFS calls GET /censuses/export/ipfs
+//
+// Export census database.
+func (e *Endpoints) CensusExportDBIPFS() (*CensusExportDBIPFSResponse, error) {
+	resp := &CensusExportDBIPFSResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "censuses", "export", "ipfs"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusExportIPFSListDBResponse is the response of CensusExportIPFSListDB.
+type CensusExportIPFSListDBResponse struct {
+	Valid bool `json:"valid"`
+}
+
+// CensusExportIPFSListDB calls GET /censuses/export/ipfs/list
+//
+// List export census database to IPFS.
+func (e *Endpoints) CensusExportIPFSListDB() (*CensusExportIPFSListDBResponse, error) {
+	resp := &CensusExportIPFSListDBResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "censuses", "export", "ipfs", "list"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusExportDBResponse is the response of CensusExportDB.
+type CensusExportDBResponse struct {
+	Valid bool `json:"valid"`
+}
+
+// CensusExportDB calls GET /censuses/export
+//
+// Export census database.
+func (e *Endpoints) CensusExportDB() (*CensusExportDBResponse, error) {
+	resp := &CensusExportDBResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "censuses", "export"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusImportDBByIpfscidResponse is the response of CensusImportDBByIpfscid.
+type CensusImportDBByIpfscidResponse struct {
+	Valid bool `json:"valid"`
+}
+
+// CensusImportDBByIpfscid calls GET /censuses/import/{ipfscid}
+//
+// Import census database.
+func (e *Endpoints) CensusImportDBByIpfscid(ipfscid string) (*CensusImportDBByIpfscidResponse, error) {
+	resp := &CensusImportDBByIpfscidResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "censuses", "import", ipfscid); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusImportDBResponse is the response of CensusImportDB.
+type CensusImportDBResponse struct {
+	Valid bool `json:"valid"`
+}
+
+// CensusImportDB calls POST /censuses/import
+//
+// Import census database.
+func (e *Endpoints) CensusImportDB() (*CensusImportDBResponse, error) {
+	resp := &CensusImportDBResponse{}
+	if err := e.do(HTTPPOST, nil, nil, resp, "censuses", "import"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CensusListResponse is the response of CensusList.
+type CensusListResponse struct {
+	Valid bool `json:"valid"`
+}
+
+// CensusList calls GET /censuses/list
+//
+// List all census references.
+func (e *Endpoints) CensusList() (*CensusListResponse, error) {
+	resp := &CensusListResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "censuses", "list"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// OrganizationListParams holds the query parameters of OrganizationList.
+type OrganizationListParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Filter by partial organizationId
+	OrganizationID string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *OrganizationListParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.OrganizationID != "" {
+		v.Set("organizationId", p.OrganizationID)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// OrganizationList calls GET /chain/organizations
+//
+// List organizations.
+func (e *Endpoints) OrganizationList(params *OrganizationListParams) (*api.OrganizationsList, error) {
+	resp := &api.OrganizationsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "organizations"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// OrganizationListByPage calls GET /chain/organizations/page/{page}
+//
+// List organizations.
+func (e *Endpoints) OrganizationListByPage(page int64) (*api.OrganizationsList, error) {
+	resp := &api.OrganizationsList{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "organizations", "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// OrganizationCount calls GET /chain/organizations/count
+//
+// Count organizations.
+func (e *Endpoints) OrganizationCount() (*api.CountResult, error) {
+	resp := &api.CountResult{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "organizations", "count"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainInfo calls GET /chain/info
+//
+// Vochain information.
+func (e *Endpoints) ChainInfo() (*api.ChainInfo, error) {
+	resp := &api.ChainInfo{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "info"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainIndexedList calls GET /chain/indexed
+//
+// Indexed chains.
+func (e *Endpoints) ChainIndexedList() (*api.IndexedChainsList, error) {
+	resp := &api.IndexedChainsList{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "indexed"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainCircuitInfo calls GET /chain/info/circuit
+//
+// Circuit info.
+func (e *Endpoints) ChainCircuitInfo() (*circuit.Config, error) {
+	resp := &circuit.Config{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "info", "circuit"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainInfoPriceFactors calls GET /chain/info/electionPriceFactors
+//
+// Price factors information.
+func (e *Endpoints) ChainInfoPriceFactors() (*electionprice.Calculator, error) {
+	resp := &electionprice.Calculator{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "info", "electionPriceFactors"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainEstimateHeightResponse is the response of ChainEstimateHeight.
+type ChainEstimateHeightResponse struct {
+	Height json.Number `json:"height"`
+}
+
+// ChainEstimateHeight calls GET /chain/dateToBlock/{timestamp}
+//
+// Estimate date to block.
+func (e *Endpoints) ChainEstimateHeight(timestamp string) (*ChainEstimateHeightResponse, error) {
+	resp := &ChainEstimateHeightResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "dateToBlock", timestamp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainEstimateDateResponse is the response of ChainEstimateDate.
+type ChainEstimateDateResponse struct {
+	Date string `json:"date"`
+}
+
+// ChainEstimateDate calls GET /chain/blockToDate/{height}
+//
+// Estimate block to date.
+func (e *Endpoints) ChainEstimateDate(height int64) (*ChainEstimateDateResponse, error) {
+	resp := &ChainEstimateDateResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "blockToDate", strconv.FormatInt(height, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainDateToBlockParams holds the query parameters of ChainDateToBlock.
+type ChainDateToBlockParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainDateToBlockParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainDateToBlock calls GET /chain/date-to-block/{timestamp}
+//
+// Date to block.
+func (e *Endpoints) ChainDateToBlock(timestamp string, params *ChainDateToBlockParams) (*api.BlockDate, error) {
+	resp := &api.BlockDate{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "date-to-block", timestamp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainBlockToDateParams holds the query parameters of ChainBlockToDate.
+type ChainBlockToDateParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainBlockToDateParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainBlockToDate calls GET /chain/block-to-date/{height}
+//
+// Block to date.
+func (e *Endpoints) ChainBlockToDate(height int64, params *ChainBlockToDateParams) (*api.BlockDate, error) {
+	resp := &api.BlockDate{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "block-to-date", strconv.FormatInt(height, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainTxCost calls GET /chain/transactions/cost
+//
+// Transaction costs.
+func (e *Endpoints) ChainTxCost() (*genesis.TransactionCosts, error) {
+	resp := &genesis.TransactionCosts{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "transactions", "cost"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainTxRefByHashParams holds the query parameters of ChainTxRefByHash.
+type ChainTxRefByHashParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainTxRefByHashParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainTxRefByHash calls GET /chain/transactions/reference/{hash}
+//
+// Transaction by hash.
+func (e *Endpoints) ChainTxRefByHash(hash string, params *ChainTxRefByHashParams) error {
+	return e.do(HTTPGET, nil, params.values(), nil, "chain", "transactions", "reference", hash)
+}
+
+// ChainTxListByHeightAndPage calls GET /chain/blocks/{height}/transactions/page/{page}
+//
+// Transactions in a block.
+func (e *Endpoints) ChainTxListByHeightAndPage(height int64, page int64) (*api.TransactionsList, error) {
+	resp := &api.TransactionsList{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "blocks", strconv.FormatInt(height, 10), "transactions", "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainTxByHeightAndIndex calls GET /chain/transactions/{height}/{index}
+//
+// Transaction by block height and index.
+func (e *Endpoints) ChainTxByHeightAndIndex(height int64, index int64) error {
+	return e.do(HTTPGET, nil, nil, nil, "chain", "transactions", strconv.FormatInt(height, 10), strconv.FormatInt(index, 10))
+}
+
+// ChainSendTxRequest is the request body of ChainSendTx.
+type ChainSendTxRequest struct {
+	Payload string `json:"payload"`
+}
+
+// ChainSendTx calls POST /chain/transactions
+//
+// Submit transaction.
+func (e *Endpoints) ChainSendTx(body *ChainSendTxRequest) (*api.Transaction, error) {
+	resp := &api.Transaction{}
+	if err := e.do(HTTPPOST, body, nil, resp, "chain", "transactions"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainSimulateTxRequest is the request body of ChainSimulateTx.
+type ChainSimulateTxRequest struct {
+	Payload string `json:"payload"`
+}
+
+// ChainSimulateTx calls POST /chain/transactions/simulate
+//
+// Simulate transaction.
+func (e *Endpoints) ChainSimulateTx(body *ChainSimulateTxRequest) (*api.TransactionSimulation, error) {
+	resp := &api.TransactionSimulation{}
+	if err := e.do(HTTPPOST, body, nil, resp, "chain", "transactions", "simulate"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainTxListParams holds the query parameters of ChainTxList.
+type ChainTxListParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Cursor returned as nextCursor by the previous page (page is ignored)
+	Cursor string
+	// Tx hash
+	Hash string
+	// Block height
+	Height int64
+	// Tx type
+	Type string
+	// Tx subtype
+	Subtype string
+	// Tx signer
+	Signer string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainTxListParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.Cursor != "" {
+		v.Set("cursor", p.Cursor)
+	}
+	if p.Hash != "" {
+		v.Set("hash", p.Hash)
+	}
+	if p.Height != 0 {
+		v.Set("height", strconv.FormatInt(p.Height, 10))
+	}
+	if p.Type != "" {
+		v.Set("type", p.Type)
+	}
+	if p.Subtype != "" {
+		v.Set("subtype", p.Subtype)
+	}
+	if p.Signer != "" {
+		v.Set("signer", p.Signer)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainTxList calls GET /chain/transactions
+//
+// List transactions.
+func (e *Endpoints) ChainTxList(params *ChainTxListParams) (*api.TransactionsList, error) {
+	resp := &api.TransactionsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "transactions"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainTxByHashParams holds the query parameters of ChainTxByHash.
+type ChainTxByHashParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainTxByHashParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainTxByHash calls GET /chain/transactions/{hash}
+//
+// Transaction by hash.
+func (e *Endpoints) ChainTxByHash(hash string, params *ChainTxByHashParams) error {
+	return e.do(HTTPGET, nil, params.values(), nil, "chain", "transactions", hash)
+}
+
+// ChainTxReceiptParams holds the query parameters of ChainTxReceipt.
+type ChainTxReceiptParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainTxReceiptParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainTxReceipt calls GET /chain/transactions/{hash}/receipt
+//
+// Transaction receipt.
+func (e *Endpoints) ChainTxReceipt(hash string, params *ChainTxReceiptParams) error {
+	return e.do(HTTPGET, nil, params.values(), nil, "chain", "transactions", hash, "receipt")
+}
+
+// ChainTxListByPage calls GET /chain/transactions/page/{page}
+//
+// List transactions (legacy).
+func (e *Endpoints) ChainTxListByPage(page int64) (*api.TransactionsList, error) {
+	resp := &api.TransactionsList{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "transactions", "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainValidators calls GET /chain/validators
+//
+// List validators.
+func (e *Endpoints) ChainValidators() (*api.ValidatorList, error) {
+	resp := &api.ValidatorList{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "validators"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainValidatorsAllParams holds the query parameters of ChainValidatorsAll.
+type ChainValidatorsAllParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainValidatorsAllParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainValidatorsAll calls GET /chain/validators/all
+//
+// List all validators.
+func (e *Endpoints) ChainValidatorsAll(params *ChainValidatorsAllParams) (*api.IndexedValidatorList, error) {
+	resp := &api.IndexedValidatorList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "validators", "all"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainValidatorsHistoryParams holds the query parameters of ChainValidatorsHistory.
+type ChainValidatorsHistoryParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Filter by exact validatorAddress
+	ValidatorAddress string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainValidatorsHistoryParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.ValidatorAddress != "" {
+		v.Set("validatorAddress", p.ValidatorAddress)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainValidatorsHistory calls GET /chain/validators/history
+//
+// Validator set history.
+func (e *Endpoints) ChainValidatorsHistory(params *ChainValidatorsHistoryParams) (*api.ValidatorSetChangesList, error) {
+	resp := &api.ValidatorSetChangesList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "validators", "history"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainValidatorUptimeParams holds the query parameters of ChainValidatorUptime.
+type ChainValidatorUptimeParams struct {
+	// Number of blocks (default 1000, max 100000)
+	Window int64
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainValidatorUptimeParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Window != 0 {
+		v.Set("window", strconv.FormatInt(p.Window, 10))
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainValidatorUptime calls GET /chain/validators/{validatorAddress}/uptime
+//
+// Validator uptime.
+func (e *Endpoints) ChainValidatorUptime(validatorAddress string, params *ChainValidatorUptimeParams) (*indexertypes.ValidatorUptime, error) {
+	resp := &indexertypes.ValidatorUptime{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "validators", validatorAddress, "uptime"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainBlockByHeightParams holds the query parameters of ChainBlockByHeight.
+type ChainBlockByHeightParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainBlockByHeightParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainBlockByHeight calls GET /chain/blocks/{height}
+//
+// Get block (by height).
+func (e *Endpoints) ChainBlockByHeight(height int64, params *ChainBlockByHeightParams) (*api.Block, error) {
+	resp := &api.Block{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "blocks", strconv.FormatInt(height, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainBlockStatsParams holds the query parameters of ChainBlockStats.
+type ChainBlockStatsParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainBlockStatsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainBlockStats calls GET /chain/blocks/{height}/stats
+//
+// Get block stats (by height).
+func (e *Endpoints) ChainBlockStats(height int64, params *ChainBlockStatsParams) (*indexertypes.BlockStats, error) {
+	resp := &indexertypes.BlockStats{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "blocks", strconv.FormatInt(height, 10), "stats"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainBlockStatsAggregateParams holds the query parameters of ChainBlockStatsAggregate.
+type ChainBlockStatsAggregateParams struct {
+	// Start of the time range (RFC3339 or YYYY-MM-DD)
+	From string
+	// End of the time range (RFC3339 or YYYY-MM-DD, default now)
+	To string
+	// Aggregation interval: hour, day or week (default the whole range)
+	Interval string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainBlockStatsAggregateParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.From != "" {
+		v.Set("from", p.From)
+	}
+	if p.To != "" {
+		v.Set("to", p.To)
+	}
+	if p.Interval != "" {
+		v.Set("interval", p.Interval)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainBlockStatsAggregate calls GET /chain/blocks/stats
+//
+// Aggregated block stats.
+func (e *Endpoints) ChainBlockStatsAggregate(params *ChainBlockStatsAggregateParams) (*api.BlockStatsList, error) {
+	resp := &api.BlockStatsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "blocks", "stats"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainVoteStatsParams holds the query parameters of ChainVoteStats.
+type ChainVoteStatsParams struct {
+	// Start of the time range (RFC3339 or YYYY-MM-DD)
+	From string
+	// End of the time range (RFC3339 or YYYY-MM-DD, default now)
+	To string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainVoteStatsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.From != "" {
+		v.Set("from", p.From)
+	}
+	if p.To != "" {
+		v.Set("to", p.To)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainVoteStats calls GET /chain/stats/votes
+//
+// Votes per hour.
+func (e *Endpoints) ChainVoteStats(params *ChainVoteStatsParams) (*api.VoteStatsList, error) {
+	resp := &api.VoteStatsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "stats", "votes"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainTxStatsParams holds the query parameters of ChainTxStats.
+type ChainTxStatsParams struct {
+	// Start of the time range (RFC3339 or YYYY-MM-DD)
+	From string
+	// End of the time range (RFC3339 or YYYY-MM-DD, default now)
+	To string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainTxStatsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.From != "" {
+		v.Set("from", p.From)
+	}
+	if p.To != "" {
+		v.Set("to", p.To)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainTxStats calls GET /chain/stats/transactions
+//
+// Transactions per day.
+func (e *Endpoints) ChainTxStats(params *ChainTxStatsParams) (*api.TxStatsList, error) {
+	resp := &api.TxStatsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "stats", "transactions"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainAccountStatsParams holds the query parameters of ChainAccountStats.
+type ChainAccountStatsParams struct {
+	// Start of the time range (RFC3339 or YYYY-MM-DD)
+	From string
+	// End of the time range (RFC3339 or YYYY-MM-DD, default now)
+	To string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainAccountStatsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.From != "" {
+		v.Set("from", p.From)
+	}
+	if p.To != "" {
+		v.Set("to", p.To)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainAccountStats calls GET /chain/stats/accounts
+//
+// New accounts per day.
+func (e *Endpoints) ChainAccountStats(params *ChainAccountStatsParams) (*api.AccountStatsList, error) {
+	resp := &api.AccountStatsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "stats", "accounts"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainFeeStatsParams holds the query parameters of ChainFeeStats.
+type ChainFeeStatsParams struct {
+	// Start of the time range (RFC3339 or YYYY-MM-DD)
+	From string
+	// End of the time range (RFC3339 or YYYY-MM-DD, default now)
+	To string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainFeeStatsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.From != "" {
+		v.Set("from", p.From)
+	}
+	if p.To != "" {
+		v.Set("to", p.To)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainFeeStats calls GET /chain/stats/fees
+//
+// Fees per day.
+func (e *Endpoints) ChainFeeStats(params *ChainFeeStatsParams) (*api.FeeStatsList, error) {
+	resp := &api.FeeStatsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "stats", "fees"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainBlockByHashParams holds the query parameters of ChainBlockByHash.
+type ChainBlockByHashParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainBlockByHashParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainBlockByHash calls GET /chain/blocks/hash/{hash}
+//
+// Get block (by hash).
+func (e *Endpoints) ChainBlockByHash(hash string, params *ChainBlockByHashParams) (*api.Block, error) {
+	resp := &api.Block{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "blocks", "hash", hash); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainBlockListParams holds the query parameters of ChainBlockList.
+type ChainBlockListParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Chain to query (default the chain of the node)
+	ChainID string
+	// Filter by partial hash
+	Hash string
+	// Filter by exact proposerAddress
+	ProposerAddress string
+}
+
+func (p *ChainBlockListParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	if p.Hash != "" {
+		v.Set("hash", p.Hash)
+	}
+	if p.ProposerAddress != "" {
+		v.Set("proposerAddress", p.ProposerAddress)
+	}
+	return v
+}
+
+// ChainBlockList calls GET /chain/blocks
+//
+// List all blocks.
+func (e *Endpoints) ChainBlockList(params *ChainBlockListParams) (*api.BlockList, error) {
+	resp := &api.BlockList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "blocks"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// OrganizationListByFilterAndPage calls POST /chain/organizations/filter/page/{page}
+//
+// List organizations (filtered).
+func (e *Endpoints) OrganizationListByFilterAndPage(page int64, body *api.OrganizationParams) (*api.OrganizationsList, error) {
+	resp := &api.OrganizationsList{}
+	if err := e.do(HTTPPOST, body, nil, resp, "chain", "organizations", "filter", "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainTxCountParams holds the query parameters of ChainTxCount.
+type ChainTxCountParams struct {
+	// Tx hash
+	Hash string
+	// Block height
+	Height int64
+	// Tx type
+	Type string
+	// Tx subtype
+	Subtype string
+	// Tx signer
+	Signer string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainTxCountParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Hash != "" {
+		v.Set("hash", p.Hash)
+	}
+	if p.Height != 0 {
+		v.Set("height", strconv.FormatInt(p.Height, 10))
+	}
+	if p.Type != "" {
+		v.Set("type", p.Type)
+	}
+	if p.Subtype != "" {
+		v.Set("subtype", p.Subtype)
+	}
+	if p.Signer != "" {
+		v.Set("signer", p.Signer)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainTxCount calls GET /chain/transactions/count
+//
+// Transactions count.
+func (e *Endpoints) ChainTxCount(params *ChainTxCountParams) (*api.CountResult, error) {
+	resp := &api.CountResult{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "transactions", "count"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainFeesListParams holds the query parameters of ChainFeesList.
+type ChainFeesListParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Reference filter
+	Reference string
+	// Type filter
+	Type string
+	// Specific accountId
+	AccountID string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainFeesListParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.Reference != "" {
+		v.Set("reference", p.Reference)
+	}
+	if p.Type != "" {
+		v.Set("type", p.Type)
+	}
+	if p.AccountID != "" {
+		v.Set("accountId", p.AccountID)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainFeesList calls GET /chain/fees
+//
+// List all token fees.
+func (e *Endpoints) ChainFeesList(params *ChainFeesListParams) (*api.FeesList, error) {
+	resp := &api.FeesList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "fees"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainFeeMarket calls GET /chain/fees/market
+//
+// Fee market.
+func (e *Endpoints) ChainFeeMarket() (*api.FeeMarket, error) {
+	resp := &api.FeeMarket{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "fees", "market"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainFeesListByPage calls GET /chain/fees/page/{page}
+//
+// List all token fees.
+func (e *Endpoints) ChainFeesListByPage(page int64) (*api.FeesList, error) {
+	resp := &api.FeesList{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "fees", "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainFeesListByReferenceAndPage calls GET /chain/fees/reference/{reference}/page/{page}
+//
+// List all token fees by reference.
+func (e *Endpoints) ChainFeesListByReferenceAndPage(reference string, page int64) (*api.FeesList, error) {
+	resp := &api.FeesList{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "fees", "reference", reference, "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainFeesListByTypeAndPage calls GET /chain/fees/type/{type}/page/{page}
+//
+// List all token fees by type.
+func (e *Endpoints) ChainFeesListByTypeAndPage(typeParam string, page int64) (*api.FeesList, error) {
+	resp := &api.FeesList{}
+	if err := e.do(HTTPGET, nil, nil, resp, "chain", "fees", "type", typeParam, "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainTransfersListParams holds the query parameters of ChainTransfersList.
+type ChainTransfersListParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Cursor returned as nextCursor by the previous page (page is ignored)
+	Cursor string
+	// Specific accountId that sent or received the tokens
+	AccountID string
+	// Specific accountId that sent the tokens
+	AccountIDFrom string
+	// Specific accountId that received the tokens
+	AccountIDTo string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainTransfersListParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.Cursor != "" {
+		v.Set("cursor", p.Cursor)
+	}
+	if p.AccountID != "" {
+		v.Set("accountId", p.AccountID)
+	}
+	if p.AccountIDFrom != "" {
+		v.Set("accountIdFrom", p.AccountIDFrom)
+	}
+	if p.AccountIDTo != "" {
+		v.Set("accountIdTo", p.AccountIDTo)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainTransfersList calls GET /chain/transfers
+//
+// List all token transfers.
+func (e *Endpoints) ChainTransfersList(params *ChainTransfersListParams) (*api.TransfersList, error) {
+	resp := &api.TransfersList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "transfers"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainIndexerExport calls GET /chain/export/indexer
+//
+// Exports the indexer database.
+func (e *Endpoints) ChainIndexerExport() ([]byte, error) {
+	var resp []byte
+	if err := e.do(HTTPGET, nil, nil, &resp, "chain", "export", "indexer"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChainIndexerStatusParams holds the query parameters of ChainIndexerStatus.
+type ChainIndexerStatusParams struct {
+	// Maximum number of blocks the indexer can lag behind the chain
+	MaxLag int64
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ChainIndexerStatusParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.MaxLag != 0 {
+		v.Set("maxLag", strconv.FormatInt(p.MaxLag, 10))
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ChainIndexerStatus calls GET /chain/indexer/status
+//
+// Indexer status.
+func (e *Endpoints) ChainIndexerStatus(params *ChainIndexerStatusParams) (*indexertypes.IndexerStatus, error) {
+	resp := &indexertypes.IndexerStatus{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "chain", "indexer", "status"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionListByPageParams holds the query parameters of ElectionListByPage.
+type ElectionListByPageParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionListByPageParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ElectionListByPage calls GET /elections/page/{page}
+//
+// List elections.
+func (e *Endpoints) ElectionListByPage(page int64, params *ElectionListByPageParams) (*api.ElectionsList, error) {
+	resp := &api.ElectionsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "elections", "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionListParams holds the query parameters of ElectionList.
+type ElectionListParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Cursor returned as nextCursor by the previous page (page is ignored)
+	Cursor string
+	// Filter by partial organizationId
+	OrganizationID string
+	// Election status
+	Status string
+	// Filter by partial electionId
+	ElectionID string
+	// Filter by (partial or final) results available or not
+	WithResults *bool
+	// Filter by final results available or not
+	FinalResults *bool
+	// Filter by whether the election was manually ended or not
+	ManuallyEnded *bool
+	// Filter by minimum turnout percentage
+	MinTurnout int64
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionListParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.Cursor != "" {
+		v.Set("cursor", p.Cursor)
+	}
+	if p.OrganizationID != "" {
+		v.Set("organizationId", p.OrganizationID)
+	}
+	if p.Status != "" {
+		v.Set("status", p.Status)
+	}
+	if p.ElectionID != "" {
+		v.Set("electionId", p.ElectionID)
+	}
+	if p.WithResults != nil {
+		v.Set("withResults", strconv.FormatBool(*p.WithResults))
+	}
+	if p.FinalResults != nil {
+		v.Set("finalResults", strconv.FormatBool(*p.FinalResults))
+	}
+	if p.ManuallyEnded != nil {
+		v.Set("manuallyEnded", strconv.FormatBool(*p.ManuallyEnded))
+	}
+	if p.MinTurnout != 0 {
+		v.Set("minTurnout", strconv.FormatInt(p.MinTurnout, 10))
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ElectionList calls GET /elections
+//
+// List elections.
+func (e *Endpoints) ElectionList(params *ElectionListParams) (*api.ElectionsList, error) {
+	resp := &api.ElectionsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "elections"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionParams holds the query parameters of Election.
+type ElectionParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// Election calls GET /elections/{electionId}
+//
+// Election information.
+func (e *Endpoints) Election(electionID string, params *ElectionParams) (*api.Election, error) {
+	resp := &api.Election{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "elections", electionID); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionKeys calls GET /elections/{electionId}/keys
+//
+// List encryption keys.
+func (e *Endpoints) ElectionKeys(electionID string) (*api.ElectionKeys, error) {
+	resp := &api.ElectionKeys{}
+	if err := e.do(HTTPGET, nil, nil, resp, "elections", electionID, "keys"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionVotesCount calls GET /elections/{electionId}/votes/count
+//
+// Count election votes.
+func (e *Endpoints) ElectionVotesCount(electionID string) (*api.CountResult, error) {
+	resp := &api.CountResult{}
+	if err := e.do(HTTPGET, nil, nil, resp, "elections", electionID, "votes", "count"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionVotesListByPageParams holds the query parameters of ElectionVotesListByPage.
+type ElectionVotesListByPageParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionVotesListByPageParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ElectionVotesListByPage calls GET /elections/{electionId}/votes/page/{page}
+//
+// List election votes.
+func (e *Endpoints) ElectionVotesListByPage(electionID string, page int64, params *ElectionVotesListByPageParams) (*api.VotesList, error) {
+	resp := &api.VotesList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "elections", electionID, "votes", "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionNullifierFilterParams holds the query parameters of ElectionNullifierFilter.
+type ElectionNullifierFilterParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionNullifierFilterParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ElectionNullifierFilter calls GET /elections/{electionId}/votes/filter
+//
+// Election nullifier filter.
+func (e *Endpoints) ElectionNullifierFilter(electionID string, params *ElectionNullifierFilterParams) (*indexertypes.NullifierFilter, error) {
+	resp := &indexertypes.NullifierFilter{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "elections", electionID, "votes", "filter"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionVotesStreamParams holds the query parameters of ElectionVotesStream.
+type ElectionVotesStreamParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionVotesStreamParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ElectionVotesStream calls GET /elections/{electionId}/votes/stream
+//
+// Stream election votes.
+func (e *Endpoints) ElectionVotesStream(electionID string, params *ElectionVotesStreamParams) ([]byte, error) {
+	var resp []byte
+	if err := e.do(HTTPGET, nil, params.values(), &resp, "elections", electionID, "votes", "stream"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionVerifyZkProof calls POST /elections/{electionId}/votes/verify-proof
+//
+// Verify a zk-SNARK vote proof.
+func (e *Endpoints) ElectionVerifyZkProof(electionID string, body *api.ZkProofVerification) (*api.ZkProofVerificationResult, error) {
+	resp := &api.ZkProofVerificationResult{}
+	if err := e.do(HTTPPOST, body, nil, resp, "elections", electionID, "votes", "verify-proof"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionScrutiny calls GET /elections/{electionId}/scrutiny
+//
+// Election results.
+func (e *Endpoints) ElectionScrutiny(electionID string) (*api.ElectionResults, error) {
+	resp := &api.ElectionResults{}
+	if err := e.do(HTTPGET, nil, nil, resp, "elections", electionID, "scrutiny"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionResultsProofParams holds the query parameters of ElectionResultsProof.
+type ElectionResultsProofParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionResultsProofParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ElectionResultsProof calls GET /elections/{electionId}/results/proof
+//
+// Election results proof.
+func (e *Endpoints) ElectionResultsProof(electionID string, params *ElectionResultsProofParams) (*resultsverify.Bundle, error) {
+	resp := &resultsverify.Bundle{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "elections", electionID, "results", "proof"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionStatusHistoryParams holds the query parameters of ElectionStatusHistory.
+type ElectionStatusHistoryParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionStatusHistoryParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ElectionStatusHistory calls GET /elections/{electionId}/history
+//
+// Election status history.
+func (e *Endpoints) ElectionStatusHistory(electionID string, params *ElectionStatusHistoryParams) (*api.ElectionStatusHistory, error) {
+	resp := &api.ElectionStatusHistory{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "elections", electionID, "history"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionCensusHistoryParams holds the query parameters of ElectionCensusHistory.
+type ElectionCensusHistoryParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionCensusHistoryParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ElectionCensusHistory calls GET /elections/{electionId}/census/history
+//
+// Election census history.
+func (e *Endpoints) ElectionCensusHistory(electionID string, params *ElectionCensusHistoryParams) (*api.ElectionCensusHistory, error) {
+	resp := &api.ElectionCensusHistory{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "elections", electionID, "census", "history"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionDelegationsParams holds the query parameters of ElectionDelegations.
+type ElectionDelegationsParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Only the delegations to this address
+	Delegate string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionDelegationsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.Delegate != "" {
+		v.Set("delegate", p.Delegate)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ElectionDelegations calls GET /elections/{electionId}/delegations
+//
+// Election vote delegations.
+func (e *Endpoints) ElectionDelegations(electionID string, params *ElectionDelegationsParams) (*api.ElectionDelegationsList, error) {
+	resp := &api.ElectionDelegationsList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "elections", electionID, "delegations"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionCreate calls POST /elections
+//
+// Create election.
+func (e *Endpoints) ElectionCreate(body *models.SignedTx) (*api.ElectionCreate, error) {
+	resp := &api.ElectionCreate{}
+	if err := e.do(HTTPPOST, body, nil, resp, "elections"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionPriceResponse is the response of ElectionPrice.
+type ElectionPriceResponse struct {
+	Price json.Number `json:"price"`
+}
+
+// ElectionPrice calls POST /elections/price
+//
+// Compute election price.
+func (e *Endpoints) ElectionPrice(body *electionprice.ElectionParameters) (*ElectionPriceResponse, error) {
+	resp := &ElectionPriceResponse{}
+	if err := e.do(HTTPPOST, body, nil, resp, "elections", "price"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ComputeCIDRequest is the request body of ComputeCID.
+type ComputeCIDRequest struct {
+	Payload string `json:"payload"`
+}
+
+// ComputeCID calls POST /files/cid
+//
+// Compute IPFS CIDv1 of file.
+func (e *Endpoints) ComputeCID(body *ComputeCIDRequest) (*api.File, error) {
+	resp := &api.File{}
+	if err := e.do(HTTPPOST, body, nil, resp, "files", "cid"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UploadFileRequest is the request body of UploadFile.
+type UploadFileRequest struct {
+	Payload string `json:"payload"`
+}
+
+// UploadFile calls POST /files
+//
+// Upload a file to storage.
+func (e *Endpoints) UploadFile(body *UploadFileRequest) (*api.File, error) {
+	resp := &api.File{}
+	if err := e.do(HTTPPOST, body, nil, resp, "files"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// File calls GET /files/{cid}
+//
+// Get a file from storage.
+func (e *Endpoints) File(cID string) (*api.File, error) {
+	resp := &api.File{}
+	if err := e.do(HTTPGET, nil, nil, resp, "files", cID); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionListByFilterAndPageParams holds the query parameters of ElectionListByFilterAndPage.
+type ElectionListByFilterAndPageParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionListByFilterAndPageParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ElectionListByFilterAndPage calls POST /elections/filter/page/{page}
+//
+// List elections (filtered).
+func (e *Endpoints) ElectionListByFilterAndPage(page int64, body *api.ElectionParams, params *ElectionListByFilterAndPageParams) (*api.ElectionsList, error) {
+	resp := &api.ElectionsList{}
+	if err := e.do(HTTPPOST, body, params.values(), resp, "elections", "filter", "page", strconv.FormatInt(page, 10)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionListByFilterParams holds the query parameters of ElectionListByFilter.
+type ElectionListByFilterParams struct {
+	// Page
+	Page int64
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionListByFilterParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ElectionListByFilter calls POST /elections/filter
+//
+// List elections (filtered).
+func (e *Endpoints) ElectionListByFilter(body *api.ElectionParams, params *ElectionListByFilterParams) (*api.ElectionsList, error) {
+	resp := &api.ElectionsList{}
+	if err := e.do(HTTPPOST, body, params.values(), resp, "elections", "filter"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// BuildElectionIDResponse is the response of BuildElectionID.
+type BuildElectionIDResponse struct {
+	ElectionID string `json:"electionID"`
+}
+
+// BuildElectionID calls POST /elections/id
+//
+// Build an election ID.
+func (e *Endpoints) BuildElectionID(body *api.BuildElectionID) (*BuildElectionIDResponse, error) {
+	resp := &BuildElectionIDResponse{}
+	if err := e.do(HTTPPOST, body, nil, resp, "elections", "id"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ElectionMetadataUnavailableListParams holds the query parameters of ElectionMetadataUnavailableList.
+type ElectionMetadataUnavailableListParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *ElectionMetadataUnavailableListParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// ElectionMetadataUnavailableList calls GET /elections/metadata/unavailable
+//
+// List elections with unavailable metadata.
+func (e *Endpoints) ElectionMetadataUnavailableList(params *ElectionMetadataUnavailableListParams) (*api.MetadataAvailabilityList, error) {
+	resp := &api.MetadataAvailabilityList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "elections", "metadata", "unavailable"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// IndexerQuery calls POST /chain/indexer/query
+//
+// Query the indexer database.
+func (e *Endpoints) IndexerQuery(body *api.IndexerQuery) (*indexertypes.QueryResult, error) {
+	resp := &indexertypes.QueryResult{}
+	if err := e.do(HTTPPOST, body, nil, resp, "chain", "indexer", "query"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SikValidResponse is the response of SikValid.
+type SikValidResponse struct {
+	Sik string `json:"sik"`
+}
+
+// SikValid calls GET /siks/{address}
+//
+// Returns if the address provided has a valid SIK.
+func (e *Endpoints) SikValid(address string) (*SikValidResponse, error) {
+	resp := &SikValidResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "siks", address); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SikValidity calls GET /siks/{address}/validity
+//
+// Returns the remaining validity of a SIK.
+func (e *Endpoints) SikValidity(address string) (*api.SIKValidity, error) {
+	resp := &api.SIKValidity{}
+	if err := e.do(HTTPGET, nil, nil, resp, "siks", address, "validity"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SikValidRootsResponse is the response of SikValidRoots.
+type SikValidRootsResponse struct {
+	Sikroots []string `json:"sikroots"`
+}
+
+// SikValidRoots calls GET /siks/roots
+//
+// List of valid SIK roots.
+func (e *Endpoints) SikValidRoots() (*SikValidRootsResponse, error) {
+	resp := &SikValidRootsResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "siks", "roots"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SikProofResponse is the response of SikProof.
+type SikProofResponse struct {
+	Sikproof    string   `json:"sikproof"`
+	Sikroot     string   `json:"sikroot"`
+	Siksiblings []string `json:"siksiblings"`
+}
+
+// SikProof calls GET /siks/proof/{address}
+//
+// List of valid SIK roots.
+func (e *Endpoints) SikProof(address string) (*SikProofResponse, error) {
+	resp := &SikProofResponse{}
+	if err := e.do(HTTPGET, nil, nil, resp, "siks", "proof", address); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SubmitVoteRequest is the request body of SubmitVote.
+type SubmitVoteRequest struct {
+	TxPayload string `json:"txPayload"`
+}
+
+// SubmitVoteResponse is the response of SubmitVote.
+type SubmitVoteResponse struct {
+	TxHash string `json:"txHash"`
+	VoteID string `json:"voteID"`
+}
+
+// SubmitVote calls POST /votes
+//
+// Submit a vote.
+func (e *Endpoints) SubmitVote(body *SubmitVoteRequest) (*SubmitVoteResponse, error) {
+	resp := &SubmitVoteResponse{}
+	if err := e.do(HTTPPOST, body, nil, resp, "votes"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VotesListParams holds the query parameters of VotesList.
+type VotesListParams struct {
+	// Page
+	Page int64
+	// Items per page
+	Limit int64
+	// Cursor returned as nextCursor by the previous page (page is ignored)
+	Cursor string
+	// Election id
+	ElectionID string
+	// Only the votes in blocks from this time on (RFC3339 or YYYY-MM-DD)
+	From string
+	// Only the votes in blocks before this time (RFC3339 or YYYY-MM-DD)
+	To string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *VotesListParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Page != 0 {
+		v.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.Cursor != "" {
+		v.Set("cursor", p.Cursor)
+	}
+	if p.ElectionID != "" {
+		v.Set("electionId", p.ElectionID)
+	}
+	if p.From != "" {
+		v.Set("from", p.From)
+	}
+	if p.To != "" {
+		v.Set("to", p.To)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// VotesList calls GET /votes
+//
+// List votes.
+func (e *Endpoints) VotesList(params *VotesListParams) (*api.VotesList, error) {
+	resp := &api.VotesList{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "votes"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VotesCountParams holds the query parameters of VotesCount.
+type VotesCountParams struct {
+	// Election id
+	ElectionID string
+	// Only the votes in blocks from this time on (RFC3339 or YYYY-MM-DD)
+	From string
+	// Only the votes in blocks before this time (RFC3339 or YYYY-MM-DD)
+	To string
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *VotesCountParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ElectionID != "" {
+		v.Set("electionId", p.ElectionID)
+	}
+	if p.From != "" {
+		v.Set("from", p.From)
+	}
+	if p.To != "" {
+		v.Set("to", p.To)
+	}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// VotesCount calls GET /votes/count
+//
+// Count votes.
+func (e *Endpoints) VotesCount(params *VotesCountParams) (*api.CountResult, error) {
+	resp := &api.CountResult{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "votes", "count"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetVoteParams holds the query parameters of GetVote.
+type GetVoteParams struct {
+	// Chain to query (default the chain of the node)
+	ChainID string
+}
+
+func (p *GetVoteParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.ChainID != "" {
+		v.Set("chainId", p.ChainID)
+	}
+	return v
+}
+
+// GetVote calls GET /votes/{voteId}
+//
+// Get vote.
+func (e *Endpoints) GetVote(voteID string, params *GetVoteParams) (*api.Vote, error) {
+	resp := &api.Vote{}
+	if err := e.do(HTTPGET, nil, params.values(), resp, "votes", voteID); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VerifyVote calls GET /votes/verify/{electionId}/{voteId}
+//
+// Verify vote.
+func (e *Endpoints) VerifyVote(electionID string, voteID string) error {
+	return e.do(HTTPGET, nil, nil, nil, "votes", "verify", electionID, voteID)
+}
+
+// WalletAddResponse is the response of WalletAdd.
+type WalletAddResponse struct {
+	Token   string `json:"token"`
+	Address string `json:"address"`
+}
+
+// WalletAdd calls POST /wallet/add/{privateKey}
+//
+// Add account.
+func (e *Endpoints) WalletAdd(privateKey string) (*WalletAddResponse, error) {
+	resp := &WalletAddResponse{}
+	if err := e.do(HTTPPOST, nil, nil, resp, "wallet", "add", privateKey); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// WalletCreate calls GET /wallet/bootstrap
+//
+// Set wallet account.
+func (e *Endpoints) WalletCreate() (*api.Transaction, error) {
+	resp := &api.Transaction{}
+	if err := e.do(HTTPGET, nil, nil, resp, "wallet", "bootstrap"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// WalletTransfer calls GET /wallet/transfer/{dstAddress}/{amount}
+//
+// Transfer tokens.
+func (e *Endpoints) WalletTransfer(dstAddress string, amount string) (*api.Transaction, error) {
+	resp := &api.Transaction{}
+	if err := e.do(HTTPGET, nil, nil, resp, "wallet", "transfer", dstAddress, amount); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// WalletElection calls POST /wallet/election
+//
+// Create election for wallet.
+func (e *Endpoints) WalletElection(body *api.ElectionDescription) (*api.Transaction, error) {
+	resp := &api.Transaction{}
+	if err := e.do(HTTPPOST, body, nil, resp, "wallet", "election"); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,43 @@
+package apiclient
+
+import (
+	"errors"
+	"fmt"
+
+	"go.vocdoni.io/dvote/api"
+)
+
+// ErrFeeTooHigh is returned by SignAndSendTx when the fee multiplier of the
+// chain exceeds the maximum set with SetMaxFeeMultiplier.
+var ErrFeeTooHigh = errors.New("fee multiplier too high")
+
+// FeeMarket returns the state of the dynamic transaction costs, including the
+// current cost of each transaction type.
+func (c *HTTPclient) FeeMarket() (*api.FeeMarket, error) {
+	return c.Endpoints().ChainFeeMarket()
+}
+
+// SetMaxFeeMultiplier sets the maximum fee multiplier, in thousandths (see
+// state.FeeMultiplierUnit), the client accepts to pay for its transactions.
+// While the multiplier is higher, SignAndSendTx fails with ErrFeeTooHigh
+// instead of sending the transaction, so that it can be retried once the
+// chain is less busy. Zero, the default, removes the limit.
+func (c *HTTPclient) SetMaxFeeMultiplier(multiplier uint64) {
+	c.maxFeeMultiplier = multiplier
+}
+
+// checkFeeMultiplier returns ErrFeeTooHigh if the fee multiplier of the chain
+// exceeds the maximum set with SetMaxFeeMultiplier.
+func (c *HTTPclient) checkFeeMultiplier() error {
+	if c.maxFeeMultiplier == 0 {
+		return nil
+	}
+	market, err := c.FeeMarket()
+	if err != nil {
+		return fmt.Errorf("cannot get the fee market: %w", err)
+	}
+	if market.Multiplier > c.maxFeeMultiplier {
+		return fmt.Errorf("%w: %d > %d", ErrFeeTooHigh, market.Multiplier, c.maxFeeMultiplier)
+	}
+	return nil
+}
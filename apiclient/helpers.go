@@ -2,6 +2,7 @@ package apiclient
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -17,6 +18,7 @@ import (
 	"go.vocdoni.io/dvote/log"
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/proto"
 )
@@ -45,10 +47,15 @@ func (c *HTTPclient) DateToHeight(date time.Time) (uint32, error) {
 
 // SignAndSendTx signs the given transaction and sends it to the blockchain.
 // It returns the transaction hash and the blockchain response (if any).
+// It fails with ErrFeeTooHigh if the fee multiplier exceeds the maximum set with
+// SetMaxFeeMultiplier.
 // Takes a protobuf marshaled transaction as input of type models.Tx
 func (c *HTTPclient) SignAndSendTx(marshaledTx []byte) (types.HexBytes, []byte, error) {
+	if err := c.checkFeeMultiplier(); err != nil {
+		return nil, nil, err
+	}
 	// Sign the transaction
-	sitnature, err := c.account.SignVocdoniTx(marshaledTx, c.ChainID())
+	sitnature, err := c.signVocdoniTx(marshaledTx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -61,25 +68,28 @@ func (c *HTTPclient) SignAndSendTx(marshaledTx []byte) (types.HexBytes, []byte,
 	if err != nil {
 		return nil, nil, err
 	}
-	// Send the signed transaction and fetch the response
-	tx := &api.Transaction{Payload: stx}
-	resp, code, err := c.Request(HTTPPOST, tx, "chain", "transactions")
+	return c.SendTx(stx)
+}
+
+// SignAndSendScheduledTx is like SignAndSendTx, but the transaction can only be
+// executed from the block validFrom to the block validUntil, both included (zero
+// leaves that end open). A transaction scheduled for a future block waits in the
+// mempool until then, so its nonce (if any) must be the one the account will
+// have at that point.
+func (c *HTTPclient) SignAndSendScheduledTx(marshaledTx []byte, validFrom, validUntil uint32) (types.HexBytes, []byte, error) {
+	scheduledTx, err := vochaintx.WithValidHeights(marshaledTx, validFrom, validUntil)
 	if err != nil {
 		return nil, nil, err
 	}
-	if code != apirest.HTTPstatusOK {
-		return nil, nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
-	}
-	if err := json.Unmarshal(resp, tx); err != nil {
-		return nil, nil, fmt.Errorf("could not decode response: %w", err)
-	}
-	return tx.Hash, tx.Response, nil
+	return c.SignAndSendTx(scheduledTx)
 }
 
 // SendTx sends a transaction to the blockchain.
 // It returns the transaction hash and the blockchain response (if any).
 // Takes a protobuf marshaled transaction as input of type models.SignedTx
-func (c *HTTPclient) SendTx(marshaledSignedTx []byte) (types.HexBytes, []byte, error) {
+func (c *HTTPclient) SendTx(marshaledSignedTx []byte) (_ types.HexBytes, _ []byte, err error) {
+	c, end := c.startOperation(OperationSendTx)
+	defer func() { end(err) }()
 	// Send the signed transaction and fetch the response
 	tx := &api.Transaction{Payload: marshaledSignedTx}
 	resp, code, err := c.Request(HTTPPOST, tx, "chain", "transactions")
@@ -95,6 +105,16 @@ func (c *HTTPclient) SendTx(marshaledSignedTx []byte) (types.HexBytes, []byte, e
 	return tx.Hash, tx.Response, nil
 }
 
+// SimulateTx runs a transaction against the current state of the blockchain
+// without sending it, and returns whether it would be accepted, the reason if
+// not and its cost. Takes a protobuf marshaled transaction as input of type
+// models.SignedTx
+func (c *HTTPclient) SimulateTx(marshaledSignedTx []byte) (*api.TransactionSimulation, error) {
+	return c.Endpoints().ChainSimulateTx(&ChainSimulateTxRequest{
+		Payload: base64.StdEncoding.EncodeToString(marshaledSignedTx),
+	})
+}
+
 // WaitUntilNextBlock waits until next block, and returns nil
 //
 // It uses a context.WithTimeout(24s) before giving up and returning ctx.Err()
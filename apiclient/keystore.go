@@ -0,0 +1,153 @@
+package apiclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/math"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// DefaultDerivationPath is the HD derivation path of the first Ethereum account
+// of a BIP-39 mnemonic, as used by most wallets.
+const DefaultDerivationPath = "m/44'/60'/0'/0/0"
+
+// hardenedOffset is the first index of the BIP-32 hardened child keys.
+const hardenedOffset = 0x80000000
+
+// NewMnemonic returns a new random BIP-39 mnemonic of 24 words.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// MnemonicPrivateKey returns the hex encoded private key derived from the
+// BIP-39 mnemonic and its optional passphrase, following the BIP-32 derivation
+// path (DefaultDerivationPath if empty).
+func MnemonicPrivateKey(mnemonic, passphrase, path string) (string, error) {
+	if path == "" {
+		path = DefaultDerivationPath
+	}
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return "", err
+	}
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return "", fmt.Errorf("invalid mnemonic: %w", err)
+	}
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+	if key.Sign() == 0 || key.Cmp(ethcrypto.S256().Params().N) >= 0 {
+		return "", fmt.Errorf("invalid master key")
+	}
+	for _, index := range derivationPath {
+		if key, chainCode, err = deriveChildKey(key, chainCode, index); err != nil {
+			return "", fmt.Errorf("cannot derive %s: %w", path, err)
+		}
+	}
+	return hex.EncodeToString(math.PaddedBigBytes(key, 32)), nil
+}
+
+// deriveChildKey returns the BIP-32 child private key and chain code of the
+// given index.
+func deriveChildKey(key *big.Int, chainCode []byte, index uint32) (*big.Int, []byte, error) {
+	n := ethcrypto.S256().Params().N
+	var data []byte
+	if index >= hardenedOffset {
+		data = append([]byte{0}, math.PaddedBigBytes(key, 32)...)
+	} else {
+		privateKey, err := ethcrypto.ToECDSA(math.PaddedBigBytes(key, 32))
+		if err != nil {
+			return nil, nil, err
+		}
+		data = ethcrypto.CompressPubkey(&privateKey.PublicKey)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	tweak := new(big.Int).SetBytes(sum[:32])
+	if tweak.Cmp(n) >= 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	child := new(big.Int).Add(key, tweak)
+	child.Mod(child, n)
+	if child.Sign() == 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	return child, sum[32:], nil
+}
+
+// DecryptKeystore returns the hex encoded private key of the Ethereum keystore
+// JSON (V3, or the legacy V1) encrypted with passphrase.
+func DecryptKeystore(keyJSON []byte, passphrase string) (string, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt keystore: %w", err)
+	}
+	return hex.EncodeToString(ethcrypto.FromECDSA(key.PrivateKey)), nil
+}
+
+// EncryptKeystore returns the Ethereum V3 keystore JSON of the hex encoded
+// private key, encrypted with passphrase using scrypt with the given
+// parameters, such as keystore.StandardScryptN and keystore.StandardScryptP.
+func EncryptKeystore(privateKey, passphrase string, scryptN, scryptP int) ([]byte, error) {
+	key, err := ethcrypto.HexToECDSA(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	return keystore.EncryptKey(&keystore.Key{
+		Id:         id,
+		Address:    ethcrypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey: key,
+	}, passphrase, scryptN, scryptP)
+}
+
+// SetAccountFromKeystore sets the account of the client from an Ethereum
+// keystore JSON encrypted with passphrase, see DecryptKeystore.
+func (c *HTTPclient) SetAccountFromKeystore(keyJSON []byte, passphrase string) error {
+	privateKey, err := DecryptKeystore(keyJSON, passphrase)
+	if err != nil {
+		return err
+	}
+	return c.SetAccount(privateKey)
+}
+
+// SetAccountFromMnemonic sets the account of the client from a BIP-39
+// mnemonic, see MnemonicPrivateKey.
+func (c *HTTPclient) SetAccountFromMnemonic(mnemonic, passphrase, path string) error {
+	privateKey, err := MnemonicPrivateKey(mnemonic, passphrase, path)
+	if err != nil {
+		return err
+	}
+	return c.SetAccount(privateKey)
+}
+
+// ExportAccountKeystore returns the account of the client as an Ethereum V3
+// keystore JSON encrypted with passphrase, using the standard scrypt
+// parameters of the Ethereum wallets.
+func (c *HTTPclient) ExportAccountKeystore(passphrase string) ([]byte, error) {
+	if c.account == nil {
+		return nil, ErrAccountNotConfigured
+	}
+	return EncryptKeystore(hex.EncodeToString(c.account.PrivateKey()), passphrase,
+		keystore.StandardScryptN, keystore.StandardScryptP)
+}
@@ -0,0 +1,132 @@
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/data/ipfs"
+	"go.vocdoni.io/dvote/httprouter/apirest"
+	"go.vocdoni.io/dvote/types"
+)
+
+var (
+	// ErrCIDMismatch is returned when the contents of a file do not match its CID.
+	ErrCIDMismatch = fmt.Errorf("file contents do not match the CID")
+	// ErrNoMetadata is returned when an election or entity has no metadata URI.
+	ErrNoMetadata = fmt.Errorf("no metadata URI")
+	// ErrEncryptedMetadata is returned when the election metadata is encrypted and cannot be decoded.
+	ErrEncryptedMetadata = fmt.Errorf("election metadata is encrypted")
+)
+
+// File retrieves a file from the storage through the gateway, given its URI (ipfs://<cid>,
+// /ipfs/<cid> or just the CID). The contents are verified against the CID, since the
+// gateway is not trusted.
+func (c *HTTPclient) File(uri string) ([]byte, error) {
+	cid := strings.TrimPrefix(strings.TrimPrefix(uri, "ipfs://"), "/ipfs/")
+	if cid == "" {
+		return nil, fmt.Errorf("invalid file URI %q", uri)
+	}
+	resp, code, err := c.Request(HTTPGET, nil, "files", cid)
+	if err != nil {
+		return nil, err
+	}
+	if code != apirest.HTTPstatusOK {
+		return nil, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
+	}
+	file := &api.File{}
+	if err := json.Unmarshal(resp, file); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response: %w", err)
+	}
+	if !ipfs.CIDequals(ipfs.CalculateCIDv1json(file.Payload), cid) {
+		return nil, fmt.Errorf("%w: %s", ErrCIDMismatch, cid)
+	}
+	return file.Payload, nil
+}
+
+// FetchFile retrieves a file from the storage through the gateway, like File, and
+// writes its contents to w once they are verified against the CID.
+func (c *HTTPclient) FetchFile(cid string, w io.Writer) error {
+	data, err := c.File(cid)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// UploadFile publishes the contents read from r on the storage through the gateway,
+// and returns its URI (ipfs://<cid>). The contents are read until EOF, and cannot be
+// bigger than api.MaxOffchainFileSize. The returned CID is verified against the
+// contents, since the gateway is not trusted.
+func (c *HTTPclient) UploadFile(r io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, api.MaxOffchainFileSize+1))
+	if err != nil {
+		return "", fmt.Errorf("could not read file: %w", err)
+	}
+	if len(data) > api.MaxOffchainFileSize {
+		return "", fmt.Errorf("file size exceeds the maximum of %d bytes", api.MaxOffchainFileSize)
+	}
+	resp, code, err := c.Request(HTTPPOST, &api.File{Payload: data}, "files")
+	if err != nil {
+		return "", err
+	}
+	if code != apirest.HTTPstatusOK {
+		return "", fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
+	}
+	file := &api.File{}
+	if err := json.Unmarshal(resp, file); err != nil {
+		return "", fmt.Errorf("could not unmarshal response: %w", err)
+	}
+	if !ipfs.CIDequals(ipfs.CalculateCIDv1json(data), file.CID) {
+		return "", fmt.Errorf("%w: %s", ErrCIDMismatch, file.CID)
+	}
+	return file.CID, nil
+}
+
+// ElectionMetadata fetches the metadata of an election through the gateway, verifies
+// it matches the metadata URI of the election and decodes it.
+func (c *HTTPclient) ElectionMetadata(electionID types.HexBytes) (*api.ElectionMetadata, error) {
+	election, err := c.Election(electionID)
+	if err != nil {
+		return nil, err
+	}
+	if election.MetadataURL == "" {
+		return nil, ErrNoMetadata
+	}
+	if election.ElectionMode.ProcessMode != nil && election.ElectionMode.EncryptedMetaData {
+		return nil, ErrEncryptedMetadata
+	}
+	data, err := c.File(election.MetadataURL)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &api.ElectionMetadata{}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, fmt.Errorf("could not decode election metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// EntityMetadata fetches the metadata of an entity (organization account) through the
+// gateway, verifies it matches the info URI of the account and decodes it.
+func (c *HTTPclient) EntityMetadata(entityID types.HexBytes) (*api.AccountMetadata, error) {
+	account, err := c.Account(entityID.String())
+	if err != nil {
+		return nil, err
+	}
+	if account.InfoURL == "" {
+		return nil, ErrNoMetadata
+	}
+	data, err := c.File(account.InfoURL)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &api.AccountMetadata{}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, fmt.Errorf("could not decode entity metadata: %w", err)
+	}
+	return metadata, nil
+}
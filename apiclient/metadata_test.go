@@ -0,0 +1,203 @@
+package apiclient_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/data/ipfs"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+func TestMetadata(t *testing.T) {
+	c := qt.New(t)
+	electionMetadata, err := json.Marshal(&api.ElectionMetadata{Title: api.LanguageString{"default": "election"}})
+	c.Assert(err, qt.IsNil)
+	entityMetadata, err := json.Marshal(&api.AccountMetadata{Name: api.LanguageString{"default": "entity"}})
+	c.Assert(err, qt.IsNil)
+	electionCID, entityCID := ipfs.CalculateCIDv1json(electionMetadata), ipfs.CalculateCIDv1json(entityMetadata)
+	tamperedCID, notJSONCID := ipfs.CalculateCIDv1json([]byte("original")), ipfs.CalculateCIDv1json([]byte("not json"))
+	files := map[string][]byte{
+		electionCID: electionMetadata,
+		entityCID:   entityMetadata,
+		tamperedCID: []byte("tampered"),
+		notJSONCID:  []byte("not json"),
+	}
+
+	// the elections and accounts are keyed by their metadata URI
+	ids := map[string]types.HexBytes{}
+	for _, name := range []string{"valid", "tampered", "missing", "encrypted", "noMode", "notJSON", "unavailable", "entity"} {
+		ids[name] = util.RandomBytes(20)
+	}
+	hexID := func(name string) string { return hex.EncodeToString(ids[name]) }
+	uris := map[string]string{
+		hexID("valid"):       "ipfs://" + electionCID,
+		hexID("tampered"):    "ipfs://" + tamperedCID,
+		hexID("encrypted"):   "ipfs://" + electionCID,
+		hexID("noMode"):      "ipfs://" + electionCID,
+		hexID("notJSON"):     "ipfs://" + notJSONCID,
+		hexID("unavailable"): "ipfs://" + ipfs.CalculateCIDv1json([]byte("unpinned")),
+		hexID("entity"):      "/ipfs/" + entityCID,
+	}
+
+	// the gateway replies with the files it has, whatever their CID is
+	reply := func(w http.ResponseWriter, v any) {
+		c.Assert(json.NewEncoder(w).Encode(v), qt.IsNil)
+	}
+	var requested []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /files/{cid}", func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.PathValue("cid"))
+		data, ok := files[r.PathValue("cid")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		reply(w, &api.File{Payload: data, CID: "ipfs://" + r.PathValue("cid")})
+	})
+	mux.HandleFunc("GET /elections/{electionId}", func(w http.ResponseWriter, r *http.Request) {
+		election := &api.Election{MetadataURL: uris[r.PathValue("electionId")]}
+		if r.PathValue("electionId") != hexID("noMode") {
+			election.ElectionMode.ProcessMode = &models.ProcessMode{
+				EncryptedMetaData: r.PathValue("electionId") == hexID("encrypted"),
+			}
+		}
+		reply(w, election)
+	})
+	mux.HandleFunc("GET /accounts/{address}", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, &api.Account{InfoURL: uris[r.PathValue("address")]})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	cli, err := apiclient.New(srv.URL + "/")
+	c.Assert(err, qt.IsNil)
+
+	// the files are requested by their CID, whatever the form of the URI,
+	// and verified against it
+	for _, uri := range []string{electionCID, "ipfs://" + electionCID, "/ipfs/" + electionCID} {
+		data, err := cli.File(uri)
+		c.Assert(err, qt.IsNil)
+		c.Assert(data, qt.DeepEquals, electionMetadata)
+	}
+	c.Assert(requested, qt.DeepEquals, []string{electionCID, electionCID, electionCID})
+	_, err = cli.File("ipfs://" + tamperedCID)
+	c.Assert(err, qt.ErrorIs, apiclient.ErrCIDMismatch)
+	for _, uri := range []string{"", "ipfs://", "/ipfs/"} {
+		_, err = cli.File(uri)
+		c.Assert(err, qt.ErrorMatches, fmt.Sprintf("invalid file URI %q", uri))
+	}
+	var buf bytes.Buffer
+	c.Assert(cli.FetchFile("ipfs://"+tamperedCID, &buf), qt.ErrorIs, apiclient.ErrCIDMismatch)
+	c.Assert(buf.Len(), qt.Equals, 0)
+
+	metadata, err := cli.ElectionMetadata(ids["valid"])
+	c.Assert(err, qt.IsNil)
+	c.Assert(metadata.Title, qt.DeepEquals, api.LanguageString{"default": "election"})
+	// the elections without process mode are not encrypted
+	metadata, err = cli.ElectionMetadata(ids["noMode"])
+	c.Assert(err, qt.IsNil)
+	c.Assert(metadata.Title, qt.DeepEquals, api.LanguageString{"default": "election"})
+	_, err = cli.ElectionMetadata(ids["tampered"])
+	c.Assert(err, qt.ErrorIs, apiclient.ErrCIDMismatch)
+	_, err = cli.ElectionMetadata(ids["missing"])
+	c.Assert(err, qt.ErrorIs, apiclient.ErrNoMetadata)
+	_, err = cli.ElectionMetadata(ids["notJSON"])
+	c.Assert(err, qt.ErrorMatches, "could not decode election metadata: .*")
+	_, err = cli.ElectionMetadata(ids["unavailable"])
+	c.Assert(err, qt.ErrorMatches, "(?s).*404.*")
+
+	// the encrypted metadata is not even fetched
+	requested = nil
+	_, err = cli.ElectionMetadata(ids["encrypted"])
+	c.Assert(err, qt.ErrorIs, apiclient.ErrEncryptedMetadata)
+	c.Assert(requested, qt.HasLen, 0)
+
+	entity, err := cli.EntityMetadata(ids["entity"])
+	c.Assert(err, qt.IsNil)
+	c.Assert(entity.Name, qt.DeepEquals, api.LanguageString{"default": "entity"})
+	_, err = cli.EntityMetadata(ids["missing"])
+	c.Assert(err, qt.ErrorIs, apiclient.ErrNoMetadata)
+	_, err = cli.EntityMetadata(ids["notJSON"])
+	c.Assert(err, qt.ErrorMatches, "could not decode entity metadata: .*")
+}
+
+func TestUploadFile(t *testing.T) {
+	c := qt.New(t)
+	var uploaded [][]byte
+	reply := "" // the CID replied by the gateway, the right one if empty
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /files", func(w http.ResponseWriter, r *http.Request) {
+		file := &api.File{}
+		if err := json.NewDecoder(r.Body).Decode(file); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		uploaded = append(uploaded, file.Payload)
+		switch reply {
+		case "":
+			file.CID = "ipfs://" + ipfs.CalculateCIDv1json(file.Payload)
+		case "full":
+			http.Error(w, "storage is full", http.StatusInternalServerError)
+			return
+		default:
+			file.CID = reply
+		}
+		file.Payload = nil
+		c.Assert(json.NewEncoder(w).Encode(file), qt.IsNil)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	cli, err := apiclient.New(srv.URL + "/")
+	c.Assert(err, qt.IsNil)
+
+	// the contents are sent as read, and the URI of the CID is returned
+	uri, err := cli.UploadFile(strings.NewReader("attachment"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(uri, qt.Equals, "ipfs://"+ipfs.CalculateCIDv1json([]byte("attachment")))
+	c.Assert(uploaded, qt.DeepEquals, [][]byte{[]byte("attachment")})
+
+	// the CID replied in other forms is accepted if it is the one of the contents
+	for _, form := range []string{"", "/ipfs/"} {
+		reply = form + ipfs.CalculateCIDv1json([]byte("attachment"))
+		uri, err = cli.UploadFile(strings.NewReader("attachment"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(uri, qt.Equals, reply)
+	}
+
+	// the CID of other contents, or not a CID at all, is not trusted
+	for _, cid := range []string{"ipfs://" + ipfs.CalculateCIDv1json([]byte("other")), "ipfs://not-a-cid"} {
+		reply = cid
+		_, err = cli.UploadFile(strings.NewReader("attachment"))
+		c.Assert(err, qt.ErrorIs, apiclient.ErrCIDMismatch)
+	}
+
+	// the gateway errors are returned with the status code
+	reply = "full"
+	_, err = cli.UploadFile(strings.NewReader("attachment"))
+	c.Assert(err, qt.ErrorMatches, "(?s).*500.*storage is full.*")
+
+	// a file of the maximum size is sent, but not one byte more
+	reply, uploaded = "", nil
+	_, err = cli.UploadFile(bytes.NewReader(make([]byte, api.MaxOffchainFileSize)))
+	c.Assert(err, qt.IsNil)
+	c.Assert(uploaded, qt.HasLen, 1)
+	_, err = cli.UploadFile(bytes.NewReader(make([]byte, api.MaxOffchainFileSize+1)))
+	c.Assert(err, qt.ErrorMatches, "file size exceeds the maximum of .*")
+	c.Assert(uploaded, qt.HasLen, 1)
+
+	// the read errors stop the upload
+	_, err = cli.UploadFile(iotest.ErrReader(fmt.Errorf("disk failure")))
+	c.Assert(err, qt.ErrorMatches, "could not read file: disk failure")
+	c.Assert(uploaded, qt.HasLen, 1)
+}
@@ -0,0 +1,136 @@
+package apiclient
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.vocdoni.io/dvote/util"
+)
+
+// clientMetrics holds the Prometheus metrics of the client operations, see
+// EnableMetrics.
+type clientMetrics struct {
+	requestDuration *prometheus.HistogramVec
+	retries         *prometheus.CounterVec
+	failedTxs       *prometheus.CounterVec
+	zkProofDuration prometheus.Histogram
+}
+
+// EnableMetrics instruments the client, and its clones made afterwards, with
+// Prometheus metrics registered in reg:
+//
+//   - apiclient_request_duration_seconds: the latency of the requests by method,
+//     endpoint and status code, where the endpoint has its ids and numbers
+//     replaced by placeholders.
+//   - apiclient_request_retries_total: the requests retried because the mempool
+//     of the API server was full, by endpoint.
+//   - apiclient_failed_txs_total: the transactions rejected by the API server,
+//     by reason, which is the API error code, the HTTP status code if there is
+//     none, or "network" if the API server could not be reached.
+//   - apiclient_zk_proof_duration_seconds: the time to generate the zk proofs
+//     of the anonymous votes.
+//
+// It fails if the metrics are already registered in reg.
+func (c *HTTPclient) EnableMetrics(reg prometheus.Registerer) error {
+	m := &clientMetrics{
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "apiclient_request_duration_seconds",
+			Help:    "Latency of the API requests.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "endpoint", "code"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "apiclient_request_retries_total",
+			Help: "API requests retried because the mempool was full.",
+		}, []string{"endpoint"}),
+		failedTxs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "apiclient_failed_txs_total",
+			Help: "Transactions rejected by the API server.",
+		}, []string{"reason"}),
+		zkProofDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "apiclient_zk_proof_duration_seconds",
+			Help:    "Time to generate the zk proofs of the anonymous votes.",
+			Buckets: []float64{0.5, 1, 2, 5, 10, 20, 40, 80},
+		}),
+	}
+	for _, collector := range []prometheus.Collector{
+		m.requestDuration, m.retries, m.failedTxs, m.zkProofDuration,
+	} {
+		if err := reg.Register(collector); err != nil {
+			return err
+		}
+	}
+	c.metrics = m
+	return nil
+}
+
+// observeRequest records a request which took the time since start, and whose
+// response has the given status code and data, or err if it failed.
+func (m *clientMetrics) observeRequest(method string, urlPath []string, start time.Time,
+	code int, data []byte, err error,
+) {
+	if m == nil {
+		return
+	}
+	endpoint := metricsEndpoint(urlPath)
+	codeLabel := "error"
+	if err == nil {
+		codeLabel = strconv.Itoa(code)
+	}
+	m.requestDuration.WithLabelValues(method, endpoint, codeLabel).Observe(time.Since(start).Seconds())
+	if method != HTTPPOST || endpoint != "/chain/transactions" {
+		return
+	}
+	switch {
+	case err != nil:
+		m.failedTxs.WithLabelValues("network").Inc()
+	case code != 200:
+		reason := strconv.Itoa(code)
+		apiErr := struct {
+			Code int `json:"code"`
+		}{}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Code != 0 {
+			reason = strconv.Itoa(apiErr.Code)
+		}
+		m.failedTxs.WithLabelValues(reason).Inc()
+	}
+}
+
+// observeRetry records a retried request.
+func (m *clientMetrics) observeRetry(urlPath []string) {
+	if m == nil {
+		return
+	}
+	m.retries.WithLabelValues(metricsEndpoint(urlPath)).Inc()
+}
+
+// observeZkProof records a zk proof generation which took the time since start.
+func (m *clientMetrics) observeZkProof(start time.Time) {
+	if m == nil {
+		return
+	}
+	m.zkProofDuration.Observe(time.Since(start).Seconds())
+}
+
+// metricsEndpoint returns the endpoint of the request path, with its elements
+// which are numbers or hex ids replaced by placeholders, to bound the number of
+// label values.
+func metricsEndpoint(urlPath []string) string {
+	elems := strings.Split(strings.Trim(path.Join(urlPath...), "/"), "/")
+	for i, elem := range elems {
+		if _, err := strconv.ParseUint(elem, 10, 64); err == nil {
+			elems[i] = "{n}"
+			continue
+		}
+		if trimmed := util.TrimHex(elem); len(trimmed) >= 16 {
+			if _, err := hex.DecodeString(trimmed); err == nil {
+				elems[i] = "{id}"
+			}
+		}
+	}
+	return "/" + strings.Join(elems, "/")
+}
@@ -0,0 +1,144 @@
+package apiclient_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/util"
+)
+
+func TestMetrics(t *testing.T) {
+	c := qt.New(t)
+	throttled := 1 // the number of next election requests replied with 429
+	txReply := ""  // the reply to the next transaction, accepted if empty
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /elections/{electionId}", func(w http.ResponseWriter, r *http.Request) {
+		if throttled > 0 {
+			throttled--
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("GET /chain/blocks/{height}", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("GET /accounts/{address}/metadata", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("POST /chain/transactions", func(w http.ResponseWriter, _ *http.Request) {
+		switch txReply {
+		case "":
+			_, _ = w.Write([]byte(`{"hash":"00"}`))
+		case "no code":
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(txReply))
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	cli, err := apiclient.New(srv.URL + "/")
+	c.Assert(err, qt.IsNil)
+
+	// the clones made before the metrics are enabled are not instrumented
+	account := ethereum.NewSignKeys()
+	c.Assert(account.Generate(), qt.IsNil)
+	before := cli.Clone(hex.EncodeToString(account.PrivateKey()))
+	reg := prometheus.NewRegistry()
+	c.Assert(cli.EnableMetrics(reg), qt.IsNil)
+	c.Assert(cli.EnableMetrics(reg), qt.Not(qt.IsNil))
+	_, _, err = before.Request(apiclient.HTTPGET, nil, "chain", "blocks", "1")
+	c.Assert(err, qt.IsNil)
+
+	// the ids and numbers are replaced by placeholders, but not the short hex
+	// words, and the clones made afterwards are instrumented
+	clone := cli.Clone(hex.EncodeToString(account.PrivateKey()))
+	_, code, err := clone.Request(apiclient.HTTPGET, nil, "elections", hex.EncodeToString(util.RandomBytes(32)))
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, http.StatusNotFound)
+	_, _, err = clone.Request(apiclient.HTTPGET, nil, "chain", "blocks", "12")
+	c.Assert(err, qt.IsNil)
+	_, _, err = clone.Request(apiclient.HTTPGET, nil, "accounts", "0x"+hex.EncodeToString(util.RandomBytes(20)), "metadata")
+	c.Assert(err, qt.IsNil)
+	_, _, err = clone.Request(apiclient.HTTPGET, nil, "accounts", "beef", "metadata")
+	c.Assert(err, qt.IsNil)
+
+	// the rejected transactions are counted by API error code, or HTTP status
+	// code if there is none, but not the accepted ones
+	for _, reply := range []string{"", `{"code":4015,"error":"rejected"}`, `{"code":4015,"error":"rejected"}`, "no code", "not json"} {
+		txReply = reply
+		_, _, err = cli.Request(apiclient.HTTPPOST, struct{}{}, "chain", "transactions")
+		c.Assert(err, qt.IsNil)
+	}
+
+	// the capabilities of the API server were checked once, when the chain info
+	// was not found on the creation of the client
+	c.Assert(gatherMetrics(t, reg), qt.DeepEquals, map[string]float64{
+		`apiclient_failed_txs_total{reason="400"}`:                                                       1,
+		`apiclient_failed_txs_total{reason="4015"}`:                                                      2,
+		`apiclient_failed_txs_total{reason="502"}`:                                                       1,
+		`apiclient_request_retries_total{endpoint="/elections/{id}"}`:                                    1,
+		`apiclient_request_duration_seconds{code="200",endpoint="/chain/transactions",method="POST"}`:    1,
+		`apiclient_request_duration_seconds{code="400",endpoint="/chain/transactions",method="POST"}`:    3,
+		`apiclient_request_duration_seconds{code="502",endpoint="/chain/transactions",method="POST"}`:    1,
+		`apiclient_request_duration_seconds{code="404",endpoint="/elections/{id}",method="GET"}`:         1,
+		`apiclient_request_duration_seconds{code="200",endpoint="/chain/blocks/{n}",method="GET"}`:       1,
+		`apiclient_request_duration_seconds{code="200",endpoint="/accounts/{id}/metadata",method="GET"}`: 1,
+		`apiclient_request_duration_seconds{code="200",endpoint="/accounts/beef/metadata",method="GET"}`: 1,
+		`apiclient_zk_proof_duration_seconds{}`:                                                          0,
+	})
+}
+
+func TestMetricsNetworkError(t *testing.T) {
+	c := qt.New(t)
+	srv := httptest.NewServer(http.NotFoundHandler())
+	cli, err := apiclient.New(srv.URL + "/")
+	c.Assert(err, qt.IsNil)
+	reg := prometheus.NewRegistry()
+	c.Assert(cli.EnableMetrics(reg), qt.IsNil)
+	srv.Close()
+
+	// the requests which cannot reach the server have no status code, and the
+	// transactions are counted as failed by the network
+	_, _, err = cli.Request(apiclient.HTTPPOST, struct{}{}, "chain", "transactions")
+	c.Assert(err, qt.Not(qt.IsNil))
+	c.Assert(gatherMetrics(t, reg), qt.DeepEquals, map[string]float64{
+		`apiclient_failed_txs_total{reason="network"}`:                                                  1,
+		`apiclient_request_duration_seconds{code="error",endpoint="/chain/transactions",method="POST"}`: 1,
+		`apiclient_zk_proof_duration_seconds{}`:                                                         0,
+	})
+}
+
+// gatherMetrics returns the value of the counters, and the number of
+// observations of the histograms, gathered from reg by name and labels.
+func gatherMetrics(t *testing.T, reg prometheus.Gatherer) map[string]float64 {
+	families, err := reg.Gather()
+	qt.Assert(t, err, qt.IsNil)
+	values := make(map[string]float64)
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			var labels []string
+			for _, label := range m.GetLabel() {
+				labels = append(labels, fmt.Sprintf("%s=%q", label.GetName(), label.GetValue()))
+			}
+			key := f.GetName() + "{" + strings.Join(labels, ",") + "}"
+			if m.GetHistogram() != nil {
+				values[key] = float64(m.GetHistogram().GetSampleCount())
+			} else {
+				values[key] = m.GetCounter().GetValue()
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,133 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.vocdoni.io/dvote/log"
+)
+
+// The names of the operations of the client notified to the middlewares, see
+// Middleware.StartOperation.
+const (
+	// OperationSendTx is the submission of a transaction, see SendTx.
+	OperationSendTx = "apiclient.SendTx"
+	// OperationVote is the casting of a vote, with the attribute electionId,
+	// see Vote.
+	OperationVote = "apiclient.Vote"
+	// OperationZkProof is the generation of the zk proof of an anonymous
+	// vote, with the attribute electionId.
+	OperationZkProof = "apiclient.ZkProof"
+)
+
+// Middleware intercepts the HTTP requests sent by the client, and the
+// operations made of several requests or local work, such as to log or trace
+// them. A middleware is shared by the clones of the client, so it must be
+// safe for concurrent use.
+type Middleware interface {
+	// StartOperation is called when the client starts an operation, with its
+	// attributes as alternating keys and values. It returns the context of
+	// the operation, which is the one of its requests and inner operations,
+	// and the function called once the operation ends, with its error if it
+	// failed.
+	StartOperation(ctx context.Context, name string, keyvals ...any) (context.Context, func(err error))
+	// OnRequest is called before each HTTP request is sent, including the
+	// retries, and can modify it, such as to add headers. It returns the
+	// function called with the response, whose body must not be read, or the
+	// error if the request failed.
+	OnRequest(req *http.Request) func(resp *http.Response, err error)
+}
+
+// WithMiddleware adds the middlewares to the client and its clones. They are
+// called in order when an operation or a request starts, and in reverse order
+// once it ends.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *HTTPclient) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// WithContext returns a copy of the client whose requests are made with ctx,
+// so that they are canceled along with it and the middlewares can relate them
+// to the caller, such as to the span of a trace.
+func (c *HTTPclient) WithContext(ctx context.Context) *HTTPclient {
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
+
+// context returns the context of the requests of the client.
+func (c *HTTPclient) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// startOperation notifies the middlewares that an operation starts. It returns
+// a copy of the client to make the requests of the operation, and the function
+// to call once it ends.
+func (c *HTTPclient) startOperation(name string, keyvals ...any) (*HTTPclient, func(error)) {
+	if len(c.middlewares) == 0 {
+		return c, func(error) {}
+	}
+	ctx := c.context()
+	ends := make([]func(error), len(c.middlewares))
+	for i, m := range c.middlewares {
+		ctx, ends[i] = m.StartOperation(ctx, name, keyvals...)
+	}
+	return c.WithContext(ctx), func(err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](err)
+		}
+	}
+}
+
+// onRequest notifies the middlewares that the request is about to be sent,
+// and returns the function to call with its outcome.
+func (c *HTTPclient) onRequest(req *http.Request) func(*http.Response, error) {
+	if len(c.middlewares) == 0 {
+		return func(*http.Response, error) {}
+	}
+	ends := make([]func(*http.Response, error), len(c.middlewares))
+	for i, m := range c.middlewares {
+		ends[i] = m.OnRequest(req)
+	}
+	return func(resp *http.Response, err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](resp, err)
+		}
+	}
+}
+
+// LogMiddleware is a Middleware which logs the requests and the operations of
+// the client, with their duration, at debug level, or at warning level if
+// they fail.
+type LogMiddleware struct{}
+
+// StartOperation implements Middleware.
+func (LogMiddleware) StartOperation(ctx context.Context, name string, keyvals ...any) (context.Context, func(error)) {
+	start := time.Now()
+	return ctx, func(err error) {
+		keyvals := append([]any{"operation", name, "elapsed", time.Since(start)}, keyvals...)
+		if err != nil {
+			log.Warnw("apiclient operation failed", append(keyvals, "error", err)...)
+			return
+		}
+		log.Debugw("apiclient operation", keyvals...)
+	}
+}
+
+// OnRequest implements Middleware.
+func (LogMiddleware) OnRequest(req *http.Request) func(*http.Response, error) {
+	start := time.Now()
+	return func(resp *http.Response, err error) {
+		keyvals := []any{"method", req.Method, "path", req.URL.Path, "elapsed", time.Since(start)}
+		if err != nil {
+			log.Warnw("apiclient request failed", append(keyvals, "error", err)...)
+			return
+		}
+		log.Debugw("apiclient request", append(keyvals, "status", resp.StatusCode)...)
+	}
+}
@@ -0,0 +1,86 @@
+package apiclient
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// MultisigAddress returns the address of the multisig account of the signers
+// (in any order) which requires the signatures of threshold of them.
+func MultisigAddress(signers []common.Address, threshold uint32) common.Address {
+	return (&state.MultisigAccount{Signers: sortedSigners(signers), Threshold: threshold}).Address()
+}
+
+// CreateMultisigAccount creates a multisig account of the signers which requires
+// the signatures of threshold of them. The transaction is paid by the account
+// associated with the client, which does not need to be one of the signers.
+// Returns the address of the multisig account and the transaction hash.
+func (c *HTTPclient) CreateMultisigAccount(signers []common.Address, threshold uint32) (common.Address, types.HexBytes, error) {
+	acc, err := c.Account("")
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("account not configured: %w", err)
+	}
+	signers = sortedSigners(signers)
+	multisig := &state.MultisigAccount{Signers: signers, Threshold: threshold}
+	if err := multisig.Check(); err != nil {
+		return common.Address{}, nil, err
+	}
+	tx, err := proto.Marshal(&models.Tx{
+		Payload: &models.Tx_SetAccount{
+			SetAccount: vochaintx.NewCreateMultisigAccountTx(acc.Nonce, signers, threshold),
+		},
+	})
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	txHash, _, err := c.SignAndSendTx(tx)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	return multisig.Address(), txHash, nil
+}
+
+// SignMultisigTx signs the given transaction (a protobuf marshaled models.Tx) with
+// the account associated with the client, as one of the signers of a multisig
+// account. The signatures can be collected offline, and once there are enough
+// of them, the transaction is sent with SendMultisigTx.
+func (c *HTTPclient) SignMultisigTx(marshaledTx []byte) ([]byte, error) {
+	if c.account == nil {
+		return nil, ErrAccountNotConfigured
+	}
+	return c.signVocdoniTx(marshaledTx)
+}
+
+// SendMultisigTx sends the given transaction (a protobuf marshaled models.Tx) on
+// behalf of the multisig account, with the signatures of its signers made by
+// SignMultisigTx. Only the transactions of the processes organized by the
+// multisig account are supported, and their nonce must be the one of the
+// multisig account. It returns the transaction hash and the blockchain response (if any).
+func (c *HTTPclient) SendMultisigTx(marshaledTx []byte, account common.Address, signatures [][]byte) (types.HexBytes, []byte, error) {
+	stx, err := vochaintx.NewMultisigSignedTx(marshaledTx, account, signatures)
+	if err != nil {
+		return nil, nil, err
+	}
+	stxb, err := proto.Marshal(stx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.SendTx(stxb)
+}
+
+// sortedSigners returns a sorted copy of the signers, as the multisig accounts require.
+func sortedSigners(signers []common.Address) []common.Address {
+	signers = slices.Clone(signers)
+	slices.SortFunc(signers, func(a, b common.Address) int {
+		return bytes.Compare(a.Bytes(), b.Bytes())
+	})
+	return signers
+}
@@ -0,0 +1,60 @@
+package apiclient
+
+import (
+	"sync"
+	"time"
+
+	"go.vocdoni.io/dvote/config"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+)
+
+// NullifierFilterTTL is the time a nullifier filter is reused by HasVoted
+// before fetching it again, since it only includes the votes up to its block.
+var NullifierFilterTTL = config.DefaultMinerTargetBlockTime
+
+// nullifierFilterCache holds the last nullifier filter fetched for each election.
+// It is shared between the clones of a client.
+type nullifierFilterCache struct {
+	mu      sync.Mutex
+	filters map[string]cachedNullifierFilter
+}
+
+type cachedNullifierFilter struct {
+	filter    *indexertypes.NullifierFilter
+	fetchedAt time.Time
+}
+
+// HasVoted returns whether there is a vote with the nullifier in the election.
+// The nullifier filter of the election is checked locally first, and only if
+// it might contain the nullifier, the vote is queried. A vote cast after the
+// filter was fetched might not be found, see NullifierFilterTTL.
+func (c *HTTPclient) HasVoted(electionID, nullifier types.HexBytes) (bool, error) {
+	filter, err := c.nullifierFilter(electionID)
+	if err != nil {
+		return false, err
+	}
+	if !filter.MayContain(nullifier) {
+		return false, nil
+	}
+	return c.Verify(electionID, nullifier)
+}
+
+// nullifierFilter returns the cached nullifier filter of the election, fetching
+// it if it is missing or too old.
+func (c *HTTPclient) nullifierFilter(electionID types.HexBytes) (*indexertypes.NullifierFilter, error) {
+	if c.nullifierFilters == nil {
+		return c.Endpoints().ElectionNullifierFilter(electionID.String(), nil)
+	}
+	c.nullifierFilters.mu.Lock()
+	defer c.nullifierFilters.mu.Unlock()
+	if cached, ok := c.nullifierFilters.filters[string(electionID)]; ok && time.Since(cached.fetchedAt) < NullifierFilterTTL {
+		return cached.filter, nil
+	}
+	filter, err := c.Endpoints().ElectionNullifierFilter(electionID.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.nullifierFilters.filters[string(electionID)] = cachedNullifierFilter{filter: filter, fetchedAt: time.Now()}
+	return filter, nil
+}
@@ -0,0 +1,146 @@
+package apiclient
+
+import (
+	"encoding/json"
+	"errors"
+	"iter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+)
+
+// ErrNoMorePages is returned by Page.NextPage on the last page of a list.
+var ErrNoMorePages = errors.New("no more pages")
+
+// Page is a page of a paginated list of the API, with its items and the
+// pagination returned by the node. The following pages are fetched with
+// NextPage, or all the items iterated with All, so the callers do not need to
+// know the page sizes nor the total count of the list.
+type Page[T any] struct {
+	Items      []T
+	Pagination *api.Pagination
+
+	// fetch requests the page with the given number, or the one after
+	// cursor if not empty.
+	fetch func(page int64, cursor string) ([]T, *api.Pagination, error)
+}
+
+// fetchPage returns the page with the given number, or the one after cursor if
+// not empty. A list without items is returned as an empty page.
+func fetchPage[T any](fetch func(page int64, cursor string) ([]T, *api.Pagination, error),
+	page int64, cursor string,
+) (*Page[T], error) {
+	items, pagination, err := fetch(page, cursor)
+	if err != nil {
+		if !isPageNotFound(err) {
+			return nil, err
+		}
+		items, pagination = nil, nil
+	}
+	return &Page[T]{Items: items, Pagination: pagination, fetch: fetch}, nil
+}
+
+// HasNext returns whether there are more pages after this one.
+func (p *Page[T]) HasNext() bool {
+	return p.Pagination != nil && (p.Pagination.NextCursor != "" || p.Pagination.NextPage != nil)
+}
+
+// NextPage fetches the page after this one, or returns ErrNoMorePages. The
+// cursor of the page is used when the list supports it, so that no items are
+// skipped or repeated if the list changes while it is paginated.
+func (p *Page[T]) NextPage() (*Page[T], error) {
+	switch {
+	case p.Pagination == nil:
+	case p.Pagination.NextCursor != "":
+		return fetchPage(p.fetch, 0, p.Pagination.NextCursor)
+	case p.Pagination.NextPage != nil:
+		return fetchPage(p.fetch, int64(*p.Pagination.NextPage), "")
+	}
+	return nil, ErrNoMorePages
+}
+
+// All returns an iterator over the items of this page and the following ones,
+// which are fetched as needed. It stops after yielding the first error.
+func (p *Page[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for page := p; ; {
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if !page.HasNext() {
+				return
+			}
+			var err error
+			if page, err = page.NextPage(); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+		}
+	}
+}
+
+// isPageNotFound returns whether err is the error returned by the API for the
+// lists without items.
+func isPageNotFound(err error) bool {
+	apiErr := (*APIError)(nil)
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	var body struct {
+		Code int `json:"code"`
+	}
+	return json.Unmarshal(apiErr.Body, &body) == nil && body.Code == api.ErrPageNotFound.Code
+}
+
+// Transfers returns the first page of the token transfers sent or received by
+// the account, most recent first, with up to limit items per page (the API
+// default if zero).
+func (c *HTTPclient) Transfers(account common.Address, limit int) (*Page[*indexertypes.TokenTransferMeta], error) {
+	return fetchPage(func(page int64, cursor string) ([]*indexertypes.TokenTransferMeta, *api.Pagination, error) {
+		list, err := c.Endpoints().ChainTransfersList(&ChainTransfersListParams{
+			Page:      page,
+			Limit:     int64(limit),
+			Cursor:    cursor,
+			AccountID: account.Hex(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return list.Transfers, list.Pagination, nil
+	}, 0, "")
+}
+
+// Elections returns the first page of the elections matching the filters of
+// params, whose Page and Cursor are ignored.
+func (c *HTTPclient) Elections(params ElectionListParams) (*Page[*api.ElectionSummary], error) {
+	return fetchPage(func(page int64, cursor string) ([]*api.ElectionSummary, *api.Pagination, error) {
+		params.Page, params.Cursor = page, cursor
+		list, err := c.Endpoints().ElectionList(&params)
+		if err != nil {
+			return nil, nil, err
+		}
+		return list.Elections, list.Pagination, nil
+	}, 0, "")
+}
+
+// ElectionVotes returns the first page of the votes of the election, with up
+// to limit items per page (the API default if zero).
+func (c *HTTPclient) ElectionVotes(electionID types.HexBytes, limit int) (*Page[*api.Vote], error) {
+	return fetchPage(func(page int64, cursor string) ([]*api.Vote, *api.Pagination, error) {
+		list, err := c.Endpoints().VotesList(&VotesListParams{
+			Page:       page,
+			Limit:      int64(limit),
+			Cursor:     cursor,
+			ElectionID: electionID.String(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return list.Votes, list.Pagination, nil
+	}, 0, "")
+}
@@ -0,0 +1,226 @@
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/crypto/zk/circuit"
+)
+
+// Names of the checks performed by PreflightElection.
+const (
+	PreflightCheckSchedule = "schedule"
+	PreflightCheckMetadata = "metadata"
+	PreflightCheckBalance  = "balance"
+	PreflightCheckCensus   = "census"
+	PreflightCheckCircuit  = "circuit"
+)
+
+// preflightCircuitTimeout is the timeout for checking the availability of the
+// circuit artifacts.
+const preflightCircuitTimeout = 30 * time.Second
+
+// PreflightCheck is the result of one of the checks of PreflightElection.
+type PreflightCheck struct {
+	Name string `json:"name"`
+	// Error is the reason why the check failed, empty if it passed.
+	Error string `json:"error,omitempty"`
+}
+
+// Passed returns true if the check passed.
+func (pc PreflightCheck) Passed() bool {
+	return pc.Error == ""
+}
+
+// PreflightReport is the result of PreflightElection.
+type PreflightReport struct {
+	// Cost is the price of the election and Balance the balance of the account.
+	Cost    uint64 `json:"cost"`
+	Balance uint64 `json:"balance"`
+	// CensusSize is the number of participants of the published census.
+	CensusSize uint64           `json:"censusSize"`
+	Checks     []PreflightCheck `json:"checks"`
+}
+
+// OK returns true if all the checks passed.
+func (r *PreflightReport) OK() bool {
+	return r.Err() == nil
+}
+
+// Err returns an error joining the failed checks, or nil if all of them passed.
+func (r *PreflightReport) Err() error {
+	var errs []error
+	for _, check := range r.Checks {
+		if !check.Passed() {
+			errs = append(errs, fmt.Errorf("%s: %s", check.Name, check.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// add appends the result of a check to the report.
+func (r *PreflightReport) add(name string, err error) {
+	check := PreflightCheck{Name: name}
+	if err != nil {
+		check.Error = err.Error()
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// PreflightElection checks that an election can be created with the given
+// description, without sending any transaction: the dates and metadata are
+// valid, the account balance covers the election price, the census root is
+// published and fits the census size, and for anonymous elections, the circuit
+// artifacts are available. The failed checks are reported in the returned
+// report, the error is only returned if the checks cannot be performed.
+func (c *HTTPclient) PreflightElection(description *api.ElectionDescription) (*PreflightReport, error) {
+	if description == nil {
+		return nil, fmt.Errorf("election description is nil")
+	}
+	info, err := c.ChainInfo()
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch chain info: %w", err)
+	}
+	report := &PreflightReport{}
+	report.add(PreflightCheckSchedule, preflightSchedule(description))
+	report.add(PreflightCheckMetadata, preflightMetadata(description))
+	report.add(PreflightCheckBalance, c.preflightBalance(description, report))
+	report.add(PreflightCheckCensus, c.preflightCensus(description, info, report))
+	if description.ElectionType.Anonymous {
+		report.add(PreflightCheckCircuit, preflightCircuit(description, info))
+	}
+	return report, nil
+}
+
+// preflightSchedule checks the start and end dates of the election.
+func preflightSchedule(description *api.ElectionDescription) error {
+	now := time.Now()
+	if description.EndDate.Before(now) {
+		return fmt.Errorf("end date cannot be in the past")
+	}
+	if !description.StartDate.IsZero() {
+		if description.StartDate.Before(now) {
+			return fmt.Errorf("start date cannot be in the past")
+		}
+		if !description.StartDate.Before(description.EndDate) {
+			return fmt.Errorf("start date must be before end date")
+		}
+	}
+	return nil
+}
+
+// preflightMetadata checks the title, questions and choices of the election.
+func preflightMetadata(description *api.ElectionDescription) error {
+	if !hasText(description.Title) {
+		return fmt.Errorf("title is empty")
+	}
+	if len(description.Questions) == 0 {
+		return fmt.Errorf("election has no questions")
+	}
+	for i, question := range description.Questions {
+		if !hasText(question.Title) {
+			return fmt.Errorf("question %d has no title", i)
+		}
+		if len(question.Choices) < 2 {
+			return fmt.Errorf("question %d has %d choices, at least 2 are required", i, len(question.Choices))
+		}
+		values := make(map[uint32]bool, len(question.Choices))
+		for j, choice := range question.Choices {
+			if !hasText(choice.Title) {
+				return fmt.Errorf("question %d choice %d has no title", i, j)
+			}
+			if values[choice.Value] {
+				return fmt.Errorf("question %d has duplicated choice value %d", i, choice.Value)
+			}
+			values[choice.Value] = true
+		}
+	}
+	return nil
+}
+
+// hasText returns true if the language string has a non-empty text.
+func hasText(s api.LanguageString) bool {
+	for _, text := range s {
+		if text != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// preflightBalance checks that the account balance covers the election price.
+func (c *HTTPclient) preflightBalance(description *api.ElectionDescription, report *PreflightReport) error {
+	if c.account == nil {
+		return ErrAccountNotConfigured
+	}
+	acc, err := c.Account("")
+	if err != nil {
+		return fmt.Errorf("could not fetch account info: %w", err)
+	}
+	report.Balance = acc.Balance
+	if report.Cost, err = c.ElectionPrice(description); err != nil {
+		return fmt.Errorf("could not fetch election price: %w", err)
+	}
+	if report.Balance < report.Cost {
+		return fmt.Errorf("balance %d is lower than the election price %d", report.Balance, report.Cost)
+	}
+	return nil
+}
+
+// preflightCensus checks that the census is published and fits the census size.
+func (c *HTTPclient) preflightCensus(description *api.ElectionDescription, info *api.ChainInfo, report *PreflightReport) error {
+	if _, _, err := api.CensusTypeToOrigin(description.Census); err != nil {
+		return err
+	}
+	size := description.Census.Size
+	if size == 0 {
+		return fmt.Errorf("census size is not set")
+	}
+	if info.MaxCensusSize > 0 && size > info.MaxCensusSize {
+		return fmt.Errorf("census size %d exceeds the max census size %d", size, info.MaxCensusSize)
+	}
+	switch description.Census.Type {
+	case api.CensusTypeWeighted, api.CensusTypeZKWeighted:
+		root := description.Census.RootHash
+		if len(root) == 0 {
+			return fmt.Errorf("census root is not set")
+		}
+		published, err := c.CensusSize(root)
+		if err != nil {
+			return fmt.Errorf("census root %s is not published: %w", root, err)
+		}
+		report.CensusSize = published
+		if published > size {
+			return fmt.Errorf("published census has %d participants, more than the census size %d", published, size)
+		}
+	case api.CensusTypeCSP:
+		if len(description.Census.PublicKey) == 0 {
+			return fmt.Errorf("census public key is not set")
+		}
+	}
+	return nil
+}
+
+// preflightCircuit checks that the census of an anonymous election is
+// supported by the circuit used by the chain, and its artifacts are available.
+func preflightCircuit(description *api.ElectionDescription, info *api.ChainInfo) error {
+	if description.Census.Type != api.CensusTypeZKWeighted {
+		return fmt.Errorf("anonymous elections require a %s census", api.CensusTypeZKWeighted)
+	}
+	zkCircuit := &circuit.ZkCircuit{Config: circuit.GetCircuitConfiguration(info.CircuitVersion)}
+	if !zkCircuit.Config.SupportsAnonymousVotes() {
+		return fmt.Errorf("circuit %s does not support anonymous votes", zkCircuit.Version())
+	}
+	if !zkCircuit.Config.SupportsCensusSize(description.Census.Size) {
+		return fmt.Errorf("census size %d is not supported by circuit %s", description.Census.Size, zkCircuit.Version())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), preflightCircuitTimeout)
+	defer cancel()
+	if err := zkCircuit.CheckAvailability(ctx); err != nil {
+		return fmt.Errorf("circuit %s: %w", zkCircuit.Version(), err)
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package apiclient
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"go.vocdoni.io/dvote/types"
+)
+
+// DefaultProofConcurrency is the default number of census proofs requested at
+// once by PreGenerateProofs.
+const DefaultProofConcurrency = 8
+
+// proofBundleVersion is the version of the proof bundle file format.
+const proofBundleVersion = 1
+
+var (
+	// ErrProofBundleMalformed is returned when a proof bundle cannot be read.
+	ErrProofBundleMalformed = fmt.Errorf("malformed proof bundle")
+	// ErrProofNotInBundle is returned when a proof bundle has no proof for
+	// the voter, or it is for another census.
+	ErrProofNotInBundle = fmt.Errorf("proof not found in bundle")
+)
+
+// ProofBundle holds the census proofs of a list of voters, generated in bulk by
+// PreGenerateProofs, so that they can be distributed to the voting stations
+// which cannot reach an API server, such as kiosks or offline voting stations,
+// and used later by Vote through VoteData.ProofBundle.
+//
+// It is stored with WriteTo as newline-delimited JSON, with a header line
+// followed by a line for each proof, so that a bundle with the proofs of a
+// large census can be split or filtered with the usual line based tools.
+type ProofBundle struct {
+	// CensusRoot is the root of the census of the proofs.
+	CensusRoot types.HexBytes
+	// Created is the time the proofs were generated.
+	Created time.Time
+	// proofs are the proofs of the voters, keyed by their voter key.
+	proofs map[string]*CensusProof
+}
+
+// proofBundleHeader is the first line of a proof bundle file.
+type proofBundleHeader struct {
+	Version    int            `json:"version"`
+	CensusRoot types.HexBytes `json:"censusRoot"`
+	Created    time.Time      `json:"created"`
+	Count      int            `json:"count"`
+}
+
+// proofBundleEntry is a line of a proof bundle file with the proof of a voter.
+type proofBundleEntry struct {
+	VoterKey types.HexBytes `json:"voterKey"`
+	Proof    *CensusProof   `json:"proof"`
+}
+
+// ProofGenError is the error of the voter keys whose proofs could not be
+// generated by PreGenerateProofs.
+type ProofGenError struct {
+	// Keys are the indexes of the failed keys, sorted, and Errs their errors.
+	Keys []int
+	Errs []error
+	// Total is the number of keys.
+	Total int
+}
+
+func (e *ProofGenError) Error() string {
+	return fmt.Sprintf("could not generate %d of %d census proofs, key %d: %v", len(e.Keys), e.Total, e.Keys[0], e.Errs[0])
+}
+
+func (e *ProofGenError) Unwrap() []error {
+	return e.Errs
+}
+
+// PreGenerateProofs fetches the census proofs of the voter keys in the census
+// with the given root, with up to concurrency requests at once, or
+// DefaultProofConcurrency if not positive. The keys are the addresses of the
+// voters, as in CensusGenProof. If some proofs cannot be fetched, the bundle with
+// the rest of them is returned along with a *ProofGenError, so that only the
+// failed keys need to be retried.
+func (c *HTTPclient) PreGenerateProofs(censusRoot types.HexBytes, keys [][]byte, concurrency int) (*ProofBundle, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultProofConcurrency
+	}
+	bundle := NewProofBundle(censusRoot)
+	if len(keys) == 0 {
+		return bundle, nil
+	}
+
+	indexes := make(chan int)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed []int
+		errs   = make(map[int]error)
+	)
+	for range min(concurrency, len(keys)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				proof, err := c.CensusGenProof(censusRoot, keys[i])
+				if err == nil && !bytes.Equal(proof.Root, censusRoot) {
+					err = fmt.Errorf("proof for census root %s", proof.Root)
+				}
+				mu.Lock()
+				if err != nil {
+					failed = append(failed, i)
+					errs[i] = err
+				} else {
+					bundle.Add(keys[i], proof)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range keys {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if len(failed) > 0 {
+		slices.Sort(failed)
+		genErr := &ProofGenError{Keys: failed, Total: len(keys)}
+		for _, i := range failed {
+			genErr.Errs = append(genErr.Errs, errs[i])
+		}
+		return bundle, genErr
+	}
+	return bundle, nil
+}
+
+// NewProofBundle returns an empty bundle for the proofs of the census with the
+// given root.
+func NewProofBundle(censusRoot types.HexBytes) *ProofBundle {
+	return &ProofBundle{
+		CensusRoot: censusRoot,
+		Created:    time.Now().UTC().Truncate(time.Second),
+		proofs:     make(map[string]*CensusProof),
+	}
+}
+
+// Add adds the proof of the voter key to the bundle, replacing the previous
+// one if any.
+func (b *ProofBundle) Add(voterKey []byte, proof *CensusProof) {
+	b.proofs[string(voterKey)] = proof
+}
+
+// Len returns the number of proofs in the bundle.
+func (b *ProofBundle) Len() int {
+	return len(b.proofs)
+}
+
+// Proof returns the proof of the voter key in the census with the given root,
+// or ErrProofNotInBundle if the bundle has none.
+func (b *ProofBundle) Proof(censusRoot types.HexBytes, voterKey []byte) (*CensusProof, error) {
+	if !bytes.Equal(censusRoot, b.CensusRoot) {
+		return nil, fmt.Errorf("%w: the bundle is for census %s, not %s", ErrProofNotInBundle, b.CensusRoot, censusRoot)
+	}
+	proof, ok := b.proofs[string(voterKey)]
+	if !ok {
+		return nil, fmt.Errorf("%w: voter %x", ErrProofNotInBundle, voterKey)
+	}
+	return proof, nil
+}
+
+// WriteTo writes the bundle to w, with the proofs sorted by voter key so that
+// the same proofs are always written the same way.
+func (b *ProofBundle) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	cw := &countingWriter{w: bw}
+	enc := json.NewEncoder(cw)
+	if err := enc.Encode(&proofBundleHeader{
+		Version:    proofBundleVersion,
+		CensusRoot: b.CensusRoot,
+		Created:    b.Created,
+		Count:      len(b.proofs),
+	}); err != nil {
+		return cw.n, err
+	}
+	keys := make([]string, 0, len(b.proofs))
+	for key := range b.proofs {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if err := enc.Encode(&proofBundleEntry{VoterKey: []byte(key), Proof: b.proofs[key]}); err != nil {
+			return cw.n, err
+		}
+	}
+	return cw.n, bw.Flush()
+}
+
+// WriteFile writes the bundle to the file at path, replacing it if it exists.
+func (b *ProofBundle) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := b.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadProofBundle reads a bundle written by WriteTo. It returns an error
+// wrapping ErrProofBundleMalformed if the bundle is not valid or incomplete.
+func ReadProofBundle(r io.Reader) (*ProofBundle, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var header proofBundleHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProofBundleMalformed, err)
+	}
+	if header.Version != proofBundleVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrProofBundleMalformed, header.Version)
+	}
+	b := &ProofBundle{
+		CensusRoot: header.CensusRoot,
+		Created:    header.Created,
+		proofs:     make(map[string]*CensusProof, header.Count),
+	}
+	for {
+		var entry proofBundleEntry
+		if err := dec.Decode(&entry); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrProofBundleMalformed, err)
+		}
+		if entry.Proof == nil || !bytes.Equal(entry.Proof.Root, b.CensusRoot) {
+			return nil, fmt.Errorf("%w: invalid proof of voter %s", ErrProofBundleMalformed, entry.VoterKey)
+		}
+		b.Add(entry.VoterKey, entry.Proof)
+	}
+	if len(b.proofs) != header.Count {
+		return nil, fmt.Errorf("%w: %d proofs, expected %d", ErrProofBundleMalformed, len(b.proofs), header.Count)
+	}
+	return b, nil
+}
+
+// LoadProofBundle reads the bundle in the file at path, see ReadProofBundle.
+func LoadProofBundle(path string) (*ProofBundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadProofBundle(f)
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
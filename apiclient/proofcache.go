@@ -0,0 +1,223 @@
+package apiclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.vocdoni.io/dvote/censustree"
+	"go.vocdoni.io/dvote/crypto/zk/prover"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
+)
+
+// DefaultProofCacheTTL is the time a cached proof is reused, if no other TTL
+// is given to NewProofCache.
+const DefaultProofCacheTTL = 24 * time.Hour
+
+const (
+	proofCacheCensus = "census"
+	proofCacheZk     = "zk"
+	proofCacheExt    = ".json"
+)
+
+// ProofCache caches on disk the census proofs, keyed by census and voter key,
+// and the zk proofs of the anonymous votes, keyed also by election and by the
+// circuit inputs (which include the vote package), so that the retries, the
+// vote overwrites with the same choices and the elections sharing a census do
+// not request the proofs to the API again nor run the prover. The entries
+// expire after the TTL. A ProofCache can be shared between clients.
+type ProofCache struct {
+	dir    string
+	ttl    time.Duration
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// ProofCacheStats are the statistics of a ProofCache.
+type ProofCacheStats struct {
+	// Hits and Misses are counted since the cache was created.
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	// Entries is the number of proofs stored, including the expired ones
+	// which were not read since they expired.
+	Entries int `json:"entries"`
+}
+
+// cachedProof is a proof stored by ProofCache.
+type cachedProof struct {
+	Expires time.Time       `json:"expires"`
+	Proof   json.RawMessage `json:"proof"`
+}
+
+// NewProofCache returns a ProofCache which stores the proofs in dir, creating
+// it if needed. A zero ttl means DefaultProofCacheTTL.
+func NewProofCache(dir string, ttl time.Duration) (*ProofCache, error) {
+	if ttl == 0 {
+		ttl = DefaultProofCacheTTL
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("cannot create proof cache directory: %w", err)
+	}
+	return &ProofCache{dir: dir, ttl: ttl}, nil
+}
+
+// SetProofCache enables the cache of the census and zk proofs generated by the
+// client, or disables it if pc is nil. The clones of the client share it.
+func (c *HTTPclient) SetProofCache(pc *ProofCache) {
+	c.proofCache = pc
+}
+
+// ProofCache returns the proof cache of the client, nil if not enabled.
+func (c *HTTPclient) ProofCache() *ProofCache {
+	return c.proofCache
+}
+
+// Stats returns the statistics of the cache.
+func (pc *ProofCache) Stats() (*ProofCacheStats, error) {
+	files, err := pc.files()
+	if err != nil {
+		return nil, err
+	}
+	return &ProofCacheStats{
+		Hits:    pc.hits.Load(),
+		Misses:  pc.misses.Load(),
+		Entries: len(files),
+	}, nil
+}
+
+// Purge removes all the proofs from the cache.
+func (pc *ProofCache) Purge() error {
+	files, err := pc.files()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// files returns the paths of the entries of the cache.
+func (pc *ProofCache) files() ([]string, error) {
+	entries, err := os.ReadDir(pc.dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), proofCacheExt) {
+			files = append(files, filepath.Join(pc.dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// path returns the path of the entry of the given kind and key parts.
+func (pc *ProofCache) path(kind string, parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// prefix each part with its length, so that the key is unambiguous
+		fmt.Fprintf(h, "%d:", len(part))
+		h.Write(part)
+	}
+	return filepath.Join(pc.dir, kind+"-"+hex.EncodeToString(h.Sum(nil))+proofCacheExt)
+}
+
+// get decodes into proof the entry of the given kind and key parts, returning
+// false if it is missing, expired or cannot be read. A nil cache always misses.
+func (pc *ProofCache) get(proof any, kind string, parts ...[]byte) bool {
+	if pc == nil {
+		return false
+	}
+	path := pc.path(kind, parts...)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		pc.misses.Add(1)
+		return false
+	}
+	var entry cachedProof
+	if err := json.Unmarshal(data, &entry); err != nil || time.Now().After(entry.Expires) ||
+		json.Unmarshal(entry.Proof, proof) != nil {
+		// a corrupt or expired entry is removed so that it is not read again
+		_ = os.Remove(path)
+		pc.misses.Add(1)
+		return false
+	}
+	pc.hits.Add(1)
+	return true
+}
+
+// put stores the proof as the entry of the given kind and key parts. A nil
+// cache does nothing.
+func (pc *ProofCache) put(proof any, kind string, parts ...[]byte) error {
+	if pc == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(proof)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(&cachedProof{Expires: time.Now().Add(pc.ttl), Proof: encoded})
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first, so that a concurrent get never reads
+	// a partially written entry
+	tmp, err := os.CreateTemp(pc.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), pc.path(kind, parts...))
+}
+
+// censusProof returns the cached census proof of the voter key in the census.
+func (pc *ProofCache) censusProof(censusID, voterKey types.HexBytes, keyType censustree.KeyType) *CensusProof {
+	proof := &CensusProof{}
+	if !pc.get(proof, proofCacheCensus, censusID, voterKey, []byte(keyType.String())) {
+		return nil
+	}
+	return proof
+}
+
+// putCensusProof caches the census proof of the voter key in the census.
+func (pc *ProofCache) putCensusProof(censusID, voterKey types.HexBytes, keyType censustree.KeyType, proof *CensusProof) {
+	if err := pc.put(proof, proofCacheCensus, censusID, voterKey, []byte(keyType.String())); err != nil {
+		log.Warnw("cannot cache census proof", "err", err)
+	}
+}
+
+// zkProof returns the cached zk proof of the voter key in the election, for
+// the given circuit inputs.
+func (pc *ProofCache) zkProof(censusRoot, voterKey, electionID types.HexBytes, inputs []byte) *prover.Proof {
+	proof := &prover.Proof{}
+	if !pc.get(proof, proofCacheZk, censusRoot, voterKey, electionID, inputs) {
+		return nil
+	}
+	return proof
+}
+
+// putZkProof caches the zk proof of the voter key in the election, for the
+// given circuit inputs.
+func (pc *ProofCache) putZkProof(censusRoot, voterKey, electionID types.HexBytes, inputs []byte, proof *prover.Proof) {
+	if err := pc.put(proof, proofCacheZk, censusRoot, voterKey, electionID, inputs); err != nil {
+		log.Warnw("cannot cache zk proof", "err", err)
+	}
+}
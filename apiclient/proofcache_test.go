@@ -0,0 +1,149 @@
+package apiclient_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/censustree"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+)
+
+func TestProofCache(t *testing.T) {
+	c := qt.New(t)
+	requests := 0        // the census proofs requested to the API server
+	unavailable := false // the API server fails to generate the proofs
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /censuses/{censusId}/proof/{key}", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if unavailable {
+			http.Error(w, "census not loaded", http.StatusInternalServerError)
+			return
+		}
+		c.Assert(json.NewEncoder(w).Encode(&api.Census{
+			CensusRoot:  types.HexStringToHexBytes(r.PathValue("censusId")),
+			CensusProof: types.HexStringToHexBytes(r.PathValue("key")),
+			Weight:      new(types.BigInt).SetUint64(3),
+		}), qt.IsNil)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	cli, err := apiclient.New(srv.URL + "/")
+	c.Assert(err, qt.IsNil)
+	dir := t.TempDir()
+	pc, err := apiclient.NewProofCache(dir, 0)
+	c.Assert(err, qt.IsNil)
+	cli.SetProofCache(pc)
+	c.Assert(cli.ProofCache(), qt.Equals, pc)
+	censusID, voterKey := types.HexBytes(util.RandomBytes(32)), types.HexBytes(util.RandomBytes(20))
+
+	// the failed requests are not cached
+	unavailable = true
+	_, err = cli.CensusGenProof(censusID, voterKey)
+	c.Assert(err, qt.ErrorMatches, "(?s).*500.*census not loaded.*")
+	unavailable = false
+	proof, err := cli.CensusGenProof(censusID, voterKey)
+	c.Assert(err, qt.IsNil)
+	c.Assert(requests, qt.Equals, 2)
+
+	// the clones share the cache
+	account := ethereum.NewSignKeys()
+	c.Assert(account.Generate(), qt.IsNil)
+	clone := cli.Clone(hex.EncodeToString(account.PrivateKey()))
+	cached, err := clone.CensusGenProof(censusID, voterKey)
+	c.Assert(err, qt.IsNil)
+	c.Assert(requests, qt.Equals, 2)
+	c.Assert(cached.Root, qt.DeepEquals, proof.Root)
+	c.Assert(cached.Proof, qt.DeepEquals, proof.Proof)
+	c.Assert(cached.LeafWeight.Uint64(), qt.Equals, uint64(3))
+
+	// the proofs are keyed by census, voter key and key type
+	_, err = cli.CensusGenProof(util.RandomBytes(32), voterKey)
+	c.Assert(err, qt.IsNil)
+	_, err = cli.CensusGenProofWithKeyType(censusID, voterKey, censustree.KeyTypeEd25519)
+	c.Assert(err, qt.IsNil)
+	c.Assert(requests, qt.Equals, 4)
+	stats, err := pc.Stats()
+	c.Assert(err, qt.IsNil)
+	c.Assert(stats, qt.DeepEquals, &apiclient.ProofCacheStats{Hits: 1, Misses: 4, Entries: 3})
+
+	// the proofs outlive the cache, and are read by other caches of the same
+	// directory
+	reopened, err := apiclient.NewProofCache(dir, 0)
+	c.Assert(err, qt.IsNil)
+	cli.SetProofCache(reopened)
+	_, err = cli.CensusGenProof(censusID, voterKey)
+	c.Assert(err, qt.IsNil)
+	c.Assert(requests, qt.Equals, 4)
+
+	// the corrupt entries are requested again, and replaced
+	files, err := filepath.Glob(filepath.Join(dir, "census-*.json"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(files, qt.HasLen, 3)
+	for _, file := range files {
+		c.Assert(os.WriteFile(file, []byte("{"), 0o600), qt.IsNil)
+	}
+	_, err = cli.CensusGenProof(censusID, voterKey)
+	c.Assert(err, qt.IsNil)
+	c.Assert(requests, qt.Equals, 5)
+	_, err = cli.CensusGenProof(censusID, voterKey)
+	c.Assert(err, qt.IsNil)
+	c.Assert(requests, qt.Equals, 5)
+
+	// the files which are not entries are not counted nor purged
+	c.Assert(os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0o600), qt.IsNil)
+	c.Assert(reopened.Purge(), qt.IsNil)
+	stats, err = reopened.Stats()
+	c.Assert(err, qt.IsNil)
+	c.Assert(stats.Entries, qt.Equals, 0)
+	_, err = os.Stat(filepath.Join(dir, "notes.txt"))
+	c.Assert(err, qt.IsNil)
+	_, err = cli.CensusGenProof(censusID, voterKey)
+	c.Assert(err, qt.IsNil)
+	c.Assert(requests, qt.Equals, 6)
+
+	// the expired proofs are requested again
+	expiring, err := apiclient.NewProofCache(t.TempDir(), time.Nanosecond)
+	c.Assert(err, qt.IsNil)
+	cli.SetProofCache(expiring)
+	for range 2 {
+		_, err = cli.CensusGenProof(censusID, voterKey)
+		c.Assert(err, qt.IsNil)
+	}
+	c.Assert(requests, qt.Equals, 8)
+	stats, err = expiring.Stats()
+	c.Assert(err, qt.IsNil)
+	c.Assert(stats, qt.DeepEquals, &apiclient.ProofCacheStats{Misses: 2, Entries: 1})
+
+	cli.SetProofCache(nil)
+	_, err = cli.CensusGenProof(censusID, voterKey)
+	c.Assert(err, qt.IsNil)
+	c.Assert(requests, qt.Equals, 9)
+}
+
+func TestNewProofCache(t *testing.T) {
+	c := qt.New(t)
+	// the missing directories are created
+	dir := filepath.Join(t.TempDir(), "cache", "proofs")
+	_, err := apiclient.NewProofCache(dir, 0)
+	c.Assert(err, qt.IsNil)
+	info, err := os.Stat(dir)
+	c.Assert(err, qt.IsNil)
+	c.Assert(info.IsDir(), qt.IsTrue)
+
+	// but a file is not a directory
+	file := filepath.Join(t.TempDir(), "file")
+	c.Assert(os.WriteFile(file, nil, 0o600), qt.IsNil)
+	_, err = apiclient.NewProofCache(file, 0)
+	c.Assert(err, qt.ErrorMatches, "cannot create proof cache directory: .*")
+}
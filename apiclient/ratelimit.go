@@ -0,0 +1,198 @@
+package apiclient
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxRetryAfter is the default maximum wait requested by a gateway
+// replying 429 Too Many Requests that the client honors, see RateLimitPolicy.
+const DefaultMaxRetryAfter = time.Minute
+
+// defaultRetryAfter is the wait after a 429 response without a valid
+// Retry-After header.
+const defaultRetryAfter = time.Second
+
+// RateLimitPolicy limits the rate of the requests sent by the client, shared
+// by all the goroutines using the client and its clones, with a token bucket.
+// The requests waiting for a token are served in turns between the bulk ones,
+// such as the census participants uploaded by CensusAddParticipants, and the
+// rest, so that a bulk upload does not starve the interactive requests.
+//
+// Regardless of the policy, the requests rejected by the gateway with 429 Too
+// Many Requests are retried as configured by SetRetries, and all the requests
+// of the client wait for the time requested by its Retry-After header.
+type RateLimitPolicy struct {
+	// Rate is the maximum number of requests per second, not limited if it
+	// is not positive.
+	Rate float64
+	// Burst is the number of requests which can be sent at once after the
+	// client is idle, at least one.
+	Burst int
+	// MaxRetryAfter is the maximum wait requested by a 429 response that the
+	// client honors, by default DefaultMaxRetryAfter. The responses requesting
+	// a longer wait are returned as they are.
+	MaxRetryAfter time.Duration
+}
+
+// requestClass is the class of a request, whose waiting requests are served in
+// turns with the ones of the other class.
+type requestClass int
+
+const (
+	classInteractive requestClass = iota
+	classBulk
+
+	requestClasses
+)
+
+// classOf returns the class of the request with the method and the path.
+func classOf(method string, urlPath []string) requestClass {
+	p := strings.Split(strings.Trim(path.Join(urlPath...), "/"), "/")
+	if method == HTTPPOST && len(p) == 3 && p[0] == "censuses" && p[2] == "participants" {
+		return classBulk
+	}
+	return classInteractive
+}
+
+// rateLimiter is the token bucket of a client, see RateLimitPolicy.
+type rateLimiter struct {
+	policy RateLimitPolicy
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	// pausedUntil is the end of the wait requested by the last 429 response.
+	pausedUntil time.Time
+	// queues are the requests waiting for a token, by class.
+	queues [requestClasses][]chan struct{}
+	// turn is the class served first when several are waiting.
+	turn requestClass
+	// dispatching is true while a dispatch of the waiting requests is
+	// scheduled.
+	dispatching bool
+}
+
+func newRateLimiter(policy RateLimitPolicy) *rateLimiter {
+	policy.Burst = max(policy.Burst, 1)
+	if policy.MaxRetryAfter <= 0 {
+		policy.MaxRetryAfter = DefaultMaxRetryAfter
+	}
+	return &rateLimiter{policy: policy, tokens: float64(policy.Burst), last: time.Now()}
+}
+
+// wait blocks until a request of the class can be sent.
+func (l *rateLimiter) wait(class requestClass) {
+	l.mu.Lock()
+	l.refill(time.Now())
+	if !l.waiting() && l.available(time.Now()) {
+		l.take()
+		l.mu.Unlock()
+		return
+	}
+	ready := make(chan struct{})
+	l.queues[class] = append(l.queues[class], ready)
+	l.schedule(0)
+	l.mu.Unlock()
+	<-ready
+}
+
+// pause makes the requests of the client wait for d, as requested by a 429
+// response. It returns false if d exceeds MaxRetryAfter.
+func (l *rateLimiter) pause(d time.Duration) bool {
+	if d > l.policy.MaxRetryAfter {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	return true
+}
+
+// dispatch lets the waiting requests through, in turns between their classes,
+// while there are tokens, and schedules the next dispatch if any is left.
+func (l *rateLimiter) dispatch() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.dispatching = false
+	now := time.Now()
+	l.refill(now)
+	for l.waiting() && l.available(now) {
+		class := l.turn
+		if len(l.queues[class]) == 0 {
+			class = (class + 1) % requestClasses
+		}
+		close(l.queues[class][0])
+		l.queues[class] = l.queues[class][1:]
+		l.turn = (class + 1) % requestClasses
+		l.take()
+	}
+	if !l.waiting() {
+		return
+	}
+	var delay time.Duration
+	if now.Before(l.pausedUntil) {
+		delay = l.pausedUntil.Sub(now)
+	} else {
+		delay = time.Duration((1 - l.tokens) / l.policy.Rate * float64(time.Second))
+	}
+	l.schedule(delay)
+}
+
+// schedule schedules a dispatch after delay, unless one is already scheduled.
+func (l *rateLimiter) schedule(delay time.Duration) {
+	if l.dispatching {
+		return
+	}
+	l.dispatching = true
+	time.AfterFunc(delay, l.dispatch)
+}
+
+// refill adds the tokens accrued since the last refill.
+func (l *rateLimiter) refill(now time.Time) {
+	if l.policy.Rate > 0 {
+		l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.policy.Rate, float64(l.policy.Burst))
+	}
+	l.last = now
+}
+
+// available returns whether a request can be sent now.
+func (l *rateLimiter) available(now time.Time) bool {
+	return !now.Before(l.pausedUntil) && (l.policy.Rate <= 0 || l.tokens >= 1)
+}
+
+// take consumes a token.
+func (l *rateLimiter) take() {
+	if l.policy.Rate > 0 {
+		l.tokens--
+	}
+}
+
+// waiting returns whether there are requests waiting.
+func (l *rateLimiter) waiting() bool {
+	for _, queue := range l.queues {
+		if len(queue) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfter returns the wait requested by the Retry-After header of a 429
+// response, either in seconds or as an HTTP date.
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return defaultRetryAfter
+}
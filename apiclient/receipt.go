@@ -0,0 +1,208 @@
+package apiclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/httprouter/apirest"
+	"go.vocdoni.io/dvote/types"
+)
+
+// VoteChoicesStatus is the result of comparing the vote package of a vote with
+// the choices stored by the client when casting it.
+type VoteChoicesStatus string
+
+const (
+	// VoteChoicesMatch means the vote package holds the stored choices.
+	VoteChoicesMatch VoteChoicesStatus = "match"
+	// VoteChoicesMismatch means the vote package holds other choices.
+	VoteChoicesMismatch VoteChoicesStatus = "mismatch"
+	// VoteChoicesEncrypted means the vote package is encrypted and the
+	// election has not published its private keys yet, so it cannot be checked.
+	VoteChoicesEncrypted VoteChoicesStatus = "encrypted"
+	// VoteChoicesUnknown means the client has no choices stored for the vote,
+	// see StoreBallot.
+	VoteChoicesUnknown VoteChoicesStatus = "unknown"
+)
+
+// VoteReceipt is the report returned by VerifyMyVote, meant to be archived by
+// the voter as a proof of the verification.
+type VoteReceipt struct {
+	ElectionID types.HexBytes `json:"electionId"`
+	// VoteID is the nullifier recomputed from the account of the client, or
+	// from its SIK for the anonymous elections.
+	VoteID    types.HexBytes `json:"voteId"`
+	Anonymous bool           `json:"anonymous"`
+	// Voter is the account of the client, empty for the anonymous elections.
+	Voter types.HexBytes `json:"voter,omitempty"`
+	// Found is true if the chain has a vote with the VoteID in the election.
+	Found bool `json:"found"`
+	// TxHash, BlockHeight, TransactionIndex and BlockHash locate the
+	// transaction of the vote, or of its last overwrite.
+	TxHash           types.HexBytes `json:"txHash,omitempty"`
+	BlockHeight      uint32         `json:"blockHeight,omitempty"`
+	TransactionIndex int32          `json:"transactionIndex,omitempty"`
+	BlockHash        types.HexBytes `json:"blockHash,omitempty"`
+	OverwriteCount   uint32         `json:"overwriteCount,omitempty"`
+	// Included is true if the transaction of the vote is in the block the vote
+	// reports.
+	Included bool `json:"included"`
+	// Choices are the choices of the vote package, if it could be decoded.
+	Choices      []int             `json:"choices,omitempty"`
+	ChoicesCheck VoteChoicesStatus `json:"choicesCheck"`
+	// Issues lists the reasons why the verification failed, if any.
+	Issues     []string  `json:"issues,omitempty"`
+	VerifiedAt time.Time `json:"verifiedAt"`
+}
+
+// Valid returns true if the vote was found, is included in a block and its
+// choices do not differ from the stored ones.
+func (r *VoteReceipt) Valid() bool {
+	return r.Found && r.Included && len(r.Issues) == 0 && r.ChoicesCheck != VoteChoicesMismatch
+}
+
+// ballot holds the choices cast by an account in an election.
+type ballot struct {
+	choices []int
+	secret  []byte
+}
+
+// ballotStore holds the ballots cast by the client, keyed by election ID and
+// voter address. It is shared by the clones of a client.
+type ballotStore struct {
+	mu      sync.Mutex
+	ballots map[string]ballot
+}
+
+func ballotKey(electionID types.HexBytes, voter common.Address) string {
+	return string(electionID) + string(voter.Bytes())
+}
+
+func (s *ballotStore) put(electionID types.HexBytes, voter common.Address, choices []int, secret []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ballots[ballotKey(electionID, voter)] = ballot{
+		choices: slices.Clone(choices),
+		secret:  bytes.Clone(secret),
+	}
+}
+
+func (s *ballotStore) get(electionID types.HexBytes, voter common.Address) (ballot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.ballots[ballotKey(electionID, voter)]
+	return b, ok
+}
+
+// StoreBallot stores the choices cast by the account of the client in the
+// election, and the secret of its SIK for the anonymous elections, to be
+// checked by VerifyMyVote. Vote stores them automatically, so it is only
+// needed to restore the ballots cast by a previous client.
+func (c *HTTPclient) StoreBallot(electionID types.HexBytes, choices []int, secret []byte) error {
+	if c.account == nil {
+		return ErrAccountNotConfigured
+	}
+	c.ballots.put(electionID, c.account.Address(), choices, secret)
+	return nil
+}
+
+// VerifyMyVote verifies the vote cast by the account of the client in the
+// election: it recomputes the nullifier of the vote from the account (or from
+// its SIK, using the secret stored with the ballot, for the anonymous
+// elections), fetches the vote, checks that its vote package holds the choices
+// stored by the client, and that its transaction is included in the block it
+// reports. The returned receipt describes the result even if the verification
+// fails, use VoteReceipt.Valid to check it. An error is only returned if the
+// verification could not be done.
+func (c *HTTPclient) VerifyMyVote(electionID types.HexBytes) (*VoteReceipt, error) {
+	if c.account == nil {
+		return nil, ErrAccountNotConfigured
+	}
+	election, err := c.Election(electionID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get election: %w", err)
+	}
+	stored, hasBallot := c.ballots.get(electionID, c.account.Address())
+	voteID, err := c.voteNullifier(election, stored.secret)
+	if err != nil {
+		return nil, fmt.Errorf("could not compute the nullifier: %w", err)
+	}
+	receipt := &VoteReceipt{
+		ElectionID:   electionID,
+		VoteID:       voteID,
+		Anonymous:    election.VoteMode.Anonymous,
+		ChoicesCheck: VoteChoicesUnknown,
+		VerifiedAt:   time.Now().UTC(),
+	}
+	if !receipt.Anonymous {
+		receipt.Voter = c.account.Address().Bytes()
+	}
+
+	vote, err := c.Endpoints().GetVote(voteID.String(), nil)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == apirest.HTTPstatusNotFound {
+			receipt.Issues = append(receipt.Issues, "vote not found")
+			return receipt, nil
+		}
+		return nil, fmt.Errorf("could not get vote: %w", err)
+	}
+	receipt.Found = true
+	receipt.TxHash = vote.TxHash
+	receipt.BlockHeight = vote.BlockHeight
+	if vote.TransactionIndex != nil {
+		receipt.TransactionIndex = *vote.TransactionIndex
+	}
+	if vote.OverwriteCount != nil {
+		receipt.OverwriteCount = *vote.OverwriteCount
+	}
+	if !bytes.Equal(vote.ElectionID, electionID) {
+		receipt.Issues = append(receipt.Issues, fmt.Sprintf("vote is in election %x", vote.ElectionID))
+	}
+	if !receipt.Anonymous && !bytes.Equal(vote.VoterID, receipt.Voter) {
+		receipt.Issues = append(receipt.Issues, fmt.Sprintf("vote is signed by %x", vote.VoterID))
+	}
+
+	// the transaction must be in the block the vote reports
+	ref, err := c.TransactionReference(vote.TxHash)
+	switch {
+	case errors.Is(err, ErrTransactionDoesNotExist):
+		receipt.Issues = append(receipt.Issues, fmt.Sprintf("transaction %x not found", vote.TxHash))
+	case err != nil:
+		return nil, fmt.Errorf("could not get vote transaction: %w", err)
+	case ref.Height != vote.BlockHeight || int32(ref.Index) != receipt.TransactionIndex:
+		receipt.Issues = append(receipt.Issues, fmt.Sprintf("transaction is at %d/%d, the vote reports %d/%d",
+			ref.Height, ref.Index, vote.BlockHeight, receipt.TransactionIndex))
+	default:
+		block, err := c.Block(ref.Height)
+		if err != nil {
+			return nil, fmt.Errorf("could not get block %d: %w", ref.Height, err)
+		}
+		receipt.BlockHash = block.Hash
+		receipt.Included = true
+	}
+
+	// the vote package must hold the stored choices
+	vp, err := c.DecryptVote(voteID)
+	switch {
+	case errors.Is(err, ErrElectionKeysNotRevealed):
+		receipt.ChoicesCheck = VoteChoicesEncrypted
+		return receipt, nil
+	case err != nil:
+		receipt.Issues = append(receipt.Issues, fmt.Sprintf("cannot decode vote package: %v", err))
+		return receipt, nil
+	}
+	receipt.Choices = vp.Votes
+	if hasBallot {
+		receipt.ChoicesCheck = VoteChoicesMatch
+		if !slices.Equal(vp.Votes, stored.choices) {
+			receipt.ChoicesCheck = VoteChoicesMismatch
+		}
+	}
+	return receipt, nil
+}
@@ -0,0 +1,279 @@
+package apiclient_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/apiclient/apiclienttest"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain/state"
+)
+
+func TestVerifyMyVote(t *testing.T) {
+	c := qt.New(t)
+	gw := apiclienttest.NewGateway(t)
+	organizer := ethereum.NewSignKeys()
+	c.Assert(organizer.Generate(), qt.IsNil)
+	cli := gw.NewClient(t, hex.EncodeToString(organizer.PrivateKey()))
+	_, err := cli.AccountBootstrap(nil, nil, nil)
+	c.Assert(err, qt.IsNil)
+
+	// an election of two voters, whose votes can be overwritten once
+	voters := ethereum.NewSignKeysBatch(2)
+	voter := func(i int) *apiclient.HTTPclient {
+		return gw.NewClient(t, hex.EncodeToString(voters[i].PrivateKey()))
+	}
+	censusID, err := cli.NewCensus(api.CensusTypeWeighted)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cli.CensusAddParticipants(censusID, &api.CensusParticipants{Participants: []api.CensusParticipant{
+		{Key: voters[0].Address().Bytes(), Weight: new(types.BigInt).SetUint64(1)},
+		{Key: voters[1].Address().Bytes(), Weight: new(types.BigInt).SetUint64(1)},
+	}}), qt.IsNil)
+	root, uri, err := cli.CensusPublish(censusID)
+	c.Assert(err, qt.IsNil)
+	electionID, err := cli.NewElection(&api.ElectionDescription{
+		Title:    api.LanguageString{"default": "receipts"},
+		EndDate:  time.Now().Add(time.Hour),
+		VoteType: api.VoteType{MaxVoteOverwrites: 1},
+		Questions: []api.Question{{
+			Title: api.LanguageString{"default": "question"},
+			Choices: []api.ChoiceMetadata{
+				{Title: api.LanguageString{"default": "yes"}, Value: 0},
+				{Title: api.LanguageString{"default": "no"}, Value: 1},
+			},
+		}},
+		Census: api.CensusTypeDescription{Type: api.CensusTypeWeighted, RootHash: root, URL: uri, Size: 2},
+	}, true)
+	c.Assert(err, qt.IsNil)
+	election, err := cli.Election(electionID)
+	c.Assert(err, qt.IsNil)
+
+	_, err = gw.NewClient(t, "").VerifyMyVote(electionID)
+	c.Assert(err, qt.ErrorIs, apiclient.ErrAccountNotConfigured)
+	_, err = voter(0).VerifyMyVote(util.RandomBytes(types.ProcessIDsize))
+	c.Assert(err, qt.ErrorMatches, "(?s)could not get election: .*404.*")
+
+	// the vote is found in its block, with the choices stored by Vote
+	first := voter(0)
+	proof, err := first.CensusGenProof(root, voters[0].Address().Bytes())
+	c.Assert(err, qt.IsNil)
+	voteID, err := first.Vote(&apiclient.VoteData{Choices: []int{1}, Election: election, ProofMkTree: proof})
+	c.Assert(err, qt.IsNil)
+	receipt, err := first.VerifyMyVote(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(receipt.Valid(), qt.IsTrue, qt.Commentf("issues: %v", receipt.Issues))
+	c.Assert(receipt.VoteID, qt.DeepEquals, voteID)
+	c.Assert(receipt.Voter, qt.DeepEquals, types.HexBytes(voters[0].Address().Bytes()))
+	c.Assert(receipt.Anonymous, qt.IsFalse)
+	c.Assert(receipt.Included, qt.IsTrue)
+	c.Assert(receipt.BlockHash, qt.Not(qt.HasLen), 0)
+	c.Assert(receipt.OverwriteCount, qt.Equals, uint32(0))
+	c.Assert(receipt.Choices, qt.DeepEquals, []int{1})
+	c.Assert(receipt.ChoicesCheck, qt.Equals, apiclient.VoteChoicesMatch)
+
+	// the overwrite replaces the stored choices, and is the one verified
+	_, err = first.Vote(&apiclient.VoteData{Choices: []int{0}, Election: election, ProofMkTree: proof})
+	c.Assert(err, qt.IsNil)
+	overwritten, err := first.VerifyMyVote(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(overwritten.Valid(), qt.IsTrue, qt.Commentf("issues: %v", overwritten.Issues))
+	c.Assert(overwritten.VoteID, qt.DeepEquals, voteID)
+	c.Assert(overwritten.OverwriteCount, qt.Equals, uint32(1))
+	c.Assert(overwritten.TxHash, qt.Not(qt.DeepEquals), receipt.TxHash)
+	c.Assert(overwritten.Choices, qt.DeepEquals, []int{0})
+	c.Assert(overwritten.ChoicesCheck, qt.Equals, apiclient.VoteChoicesMatch)
+
+	// the clones share the stored ballots
+	receipt, err = first.Clone(hex.EncodeToString(voters[0].PrivateKey())).VerifyMyVote(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(receipt.ChoicesCheck, qt.Equals, apiclient.VoteChoicesMatch)
+
+	// a new client has no ballot stored, until it is restored, and the stored
+	// choices are copied
+	restored := voter(0)
+	receipt, err = restored.VerifyMyVote(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(receipt.ChoicesCheck, qt.Equals, apiclient.VoteChoicesUnknown)
+	c.Assert(receipt.Valid(), qt.IsTrue)
+	choices := []int{1}
+	c.Assert(restored.StoreBallot(electionID, choices, nil), qt.IsNil)
+	choices[0] = 0
+	receipt, err = restored.VerifyMyVote(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(receipt.ChoicesCheck, qt.Equals, apiclient.VoteChoicesMismatch)
+	c.Assert(receipt.Valid(), qt.IsFalse)
+	// the ballots are stored by election
+	c.Assert(restored.StoreBallot(util.RandomBytes(types.ProcessIDsize), []int{0}, nil), qt.IsNil)
+	receipt, err = restored.VerifyMyVote(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(receipt.ChoicesCheck, qt.Equals, apiclient.VoteChoicesMismatch)
+
+	// the second voter has not voted
+	receipt, err = voter(1).VerifyMyVote(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(receipt.Found, qt.IsFalse)
+	c.Assert(receipt.Issues, qt.DeepEquals, []string{"vote not found"})
+	c.Assert(receipt.Valid(), qt.IsFalse)
+
+	c.Assert(gw.NewClient(t, "").StoreBallot(electionID, []int{0}, nil), qt.ErrorIs, apiclient.ErrAccountNotConfigured)
+}
+
+func TestVerifyMyVoteIssues(t *testing.T) {
+	c := qt.New(t)
+	account := ethereum.NewSignKeys()
+	c.Assert(account.Generate(), qt.IsNil)
+	electionID := types.HexBytes(util.RandomBytes(types.ProcessIDsize))
+	votePackage, err := state.NewVotePackage([]int{1}).Encode()
+	c.Assert(err, qt.IsNil)
+	nullifier := types.HexBytes(state.GenerateNullifier(account.Address(), electionID))
+	index := int32(0)
+	// the vote, the transaction reference and the block replied by the API
+	// server, which the test tampers with; a nil one is not found, and a nil
+	// block fails
+	var (
+		mu    sync.Mutex
+		vote  *api.Vote
+		ref   *api.TransactionReference
+		block *api.Block
+	)
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		vote = &api.Vote{
+			TxHash:           util.RandomBytes(32),
+			ElectionID:       electionID,
+			VoterID:          account.Address().Bytes(),
+			VotePackage:      votePackage,
+			BlockHeight:      4,
+			TransactionIndex: &index,
+		}
+		ref = &api.TransactionReference{Height: 4}
+		block = &api.Block{Hash: util.RandomBytes(32)}
+	}
+	reset()
+
+	reply := func(w http.ResponseWriter, v any) {
+		c.Assert(json.NewEncoder(w).Encode(v), qt.IsNil)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /elections/{electionId}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("electionId") != electionID.String() {
+			http.Error(w, "election not found", http.StatusNotFound)
+			return
+		}
+		reply(w, &api.Election{ElectionSummary: api.ElectionSummary{ElectionID: electionID}})
+	})
+	mux.HandleFunc("GET /elections/{electionId}/keys", func(w http.ResponseWriter, _ *http.Request) {
+		reply(w, &api.ElectionKeys{})
+	})
+	mux.HandleFunc("GET /votes/{voteId}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.PathValue("voteId") != nullifier.String() {
+			http.Error(w, "unexpected nullifier", http.StatusBadRequest)
+			return
+		}
+		if vote == nil {
+			http.Error(w, "database locked", http.StatusInternalServerError)
+			return
+		}
+		reply(w, vote)
+	})
+	mux.HandleFunc("GET /chain/transactions/reference/{hash}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ref == nil {
+			http.NotFound(w, r)
+			return
+		}
+		reply(w, ref)
+	})
+	mux.HandleFunc("GET /chain/blocks/{height}", func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if block == nil {
+			http.Error(w, "block store unavailable", http.StatusInternalServerError)
+			return
+		}
+		reply(w, block)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	cli, err := apiclient.New(srv.URL + "/")
+	c.Assert(err, qt.IsNil)
+	c.Assert(cli.SetAccount(hex.EncodeToString(account.PrivateKey())), qt.IsNil)
+	c.Assert(cli.StoreBallot(electionID, []int{1}, nil), qt.IsNil)
+	tamper := func(f func()) *apiclient.VoteReceipt {
+		c.Helper()
+		reset()
+		mu.Lock()
+		f()
+		mu.Unlock()
+		receipt, err := cli.VerifyMyVote(electionID)
+		c.Assert(err, qt.IsNil)
+		return receipt
+	}
+
+	receipt := tamper(func() {})
+	c.Assert(receipt.Valid(), qt.IsTrue, qt.Commentf("issues: %v", receipt.Issues))
+	c.Assert(receipt.BlockHash, qt.DeepEquals, block.Hash)
+
+	// the vote must be the one of the election and the account
+	otherElection, otherVoter := types.HexBytes(util.RandomBytes(32)), types.HexBytes(util.RandomBytes(20))
+	receipt = tamper(func() { vote.ElectionID, vote.VoterID = otherElection, otherVoter })
+	c.Assert(receipt.Issues, qt.DeepEquals, []string{
+		"vote is in election " + otherElection.String(),
+		"vote is signed by " + otherVoter.String(),
+	})
+	c.Assert(receipt.Found, qt.IsTrue)
+	c.Assert(receipt.Included, qt.IsTrue)
+	c.Assert(receipt.Valid(), qt.IsFalse)
+
+	// its transaction must be in the block it reports
+	receipt = tamper(func() { ref = nil })
+	c.Assert(receipt.Issues, qt.DeepEquals, []string{"transaction " + receipt.TxHash.String() + " not found"})
+	c.Assert(receipt.Included, qt.IsFalse)
+	c.Assert(receipt.BlockHash, qt.IsNil)
+	c.Assert(receipt.ChoicesCheck, qt.Equals, apiclient.VoteChoicesMatch)
+	receipt = tamper(func() { ref = &api.TransactionReference{Height: 5, Index: 1} })
+	c.Assert(receipt.Issues, qt.DeepEquals, []string{"transaction is at 5/1, the vote reports 4/0"})
+	c.Assert(receipt.Valid(), qt.IsFalse)
+
+	// the vote package must be decoded, unless it is encrypted and the keys
+	// are not revealed yet
+	receipt = tamper(func() { vote.VotePackage = json.RawMessage(`"votes"`) })
+	c.Assert(receipt.Issues, qt.HasLen, 1)
+	c.Assert(receipt.Issues[0], qt.Matches, "cannot decode vote package: .*")
+	c.Assert(receipt.Choices, qt.IsNil)
+	c.Assert(receipt.ChoicesCheck, qt.Equals, apiclient.VoteChoicesUnknown)
+	receipt = tamper(func() {
+		vote.VotePackage = json.RawMessage(`{"encrypted":"AQID"}`)
+		vote.EncryptionKeyIndexes = []uint32{1}
+	})
+	c.Assert(receipt.ChoicesCheck, qt.Equals, apiclient.VoteChoicesEncrypted)
+	c.Assert(receipt.Valid(), qt.IsTrue, qt.Commentf("issues: %v", receipt.Issues))
+
+	// and the verification fails if the API server cannot tell
+	reset()
+	mu.Lock()
+	vote = nil
+	mu.Unlock()
+	_, err = cli.VerifyMyVote(electionID)
+	c.Assert(err, qt.ErrorMatches, "(?s)could not get vote: .*database locked.*")
+	reset()
+	mu.Lock()
+	block = nil
+	mu.Unlock()
+	_, err = cli.VerifyMyVote(electionID)
+	c.Assert(err, qt.ErrorMatches, "(?s)could not get block 4: .*block store unavailable.*")
+}
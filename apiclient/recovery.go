@@ -0,0 +1,112 @@
+package apiclient
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// SetRecoveryGuardians sets the guardians (in any order) of the account
+// associated with the client, quorum of which can rotate its owner key once
+// timelock blocks have elapsed since they approved it, so that the account is
+// not lost with its key. It also cancels the pending recovery of the account,
+// if any. Returns the transaction hash.
+func (c *HTTPclient) SetRecoveryGuardians(guardians []common.Address, quorum, timelock uint32) (types.HexBytes, error) {
+	acc, err := c.Account("")
+	if err != nil {
+		return nil, fmt.Errorf("account not configured: %w", err)
+	}
+	guardians = sortedSigners(guardians)
+	recoveryGuardians := &state.RecoveryGuardians{Guardians: guardians, Quorum: quorum, Timelock: timelock}
+	if err := recoveryGuardians.Check(c.account.Address()); err != nil {
+		return nil, err
+	}
+	return c.sendSetAccountTx(vochaintx.NewSetRecoveryGuardiansTx(acc.Nonce, nil, guardians, quorum, timelock))
+}
+
+// RemoveRecoveryGuardians removes the guardians of the account associated with
+// the client, cancelling its pending recovery if any. Returns the transaction
+// hash.
+func (c *HTTPclient) RemoveRecoveryGuardians() (types.HexBytes, error) {
+	acc, err := c.Account("")
+	if err != nil {
+		return nil, fmt.Errorf("account not configured: %w", err)
+	}
+	return c.sendSetAccountTx(vochaintx.NewSetRecoveryGuardiansTx(acc.Nonce, nil, nil, 0, 0))
+}
+
+// ApproveAccountRecovery approves, as a guardian of the account, the rotation
+// of its owner key to newOwner. Once a quorum of guardians approve the same new
+// owner, the recovery can be executed with ExecuteAccountRecovery after the
+// timelock of the account. Returns the transaction hash.
+func (c *HTTPclient) ApproveAccountRecovery(account, newOwner common.Address) (types.HexBytes, error) {
+	acc, err := c.Account("")
+	if err != nil {
+		return nil, fmt.Errorf("account not configured: %w", err)
+	}
+	return c.sendSetAccountTx(vochaintx.NewRecoverAccountTx(acc.Nonce, account, newOwner))
+}
+
+// ExecuteAccountRecovery rotates the owner key of the account to the new owner
+// approved by its guardians, once the timelock has elapsed. The transaction is
+// paid by the account associated with the client, which can be anyone. From
+// then on, the transactions of the account must be sent by its new owner with
+// SendRecoveredAccountTx. Returns the transaction hash.
+func (c *HTTPclient) ExecuteAccountRecovery(account common.Address) (types.HexBytes, error) {
+	acc, err := c.Account("")
+	if err != nil {
+		return nil, fmt.Errorf("account not configured: %w", err)
+	}
+	return c.sendSetAccountTx(vochaintx.NewExecuteAccountRecoveryTx(acc.Nonce, account))
+}
+
+// AccountRecovery returns the guardians of the account, its pending recovery,
+// its owner if its key was rotated, and the latest events of its recovery.
+func (c *HTTPclient) AccountRecovery(account common.Address) (*api.AccountRecovery, error) {
+	return c.Endpoints().AccountRecovery(account.Hex(), nil)
+}
+
+// SendRecoveredAccountTx signs the given transaction (a protobuf marshaled
+// models.Tx) with the account associated with the client, which must be the
+// owner of the account since its guardians rotated its key, and sends it on
+// behalf of the account. As for the multisig accounts, only the transactions of
+// the processes organized by the account and the ones setting its guardians are
+// supported, and their nonce must be the one of the account. It returns the
+// transaction hash and the blockchain response (if any).
+func (c *HTTPclient) SendRecoveredAccountTx(marshaledTx []byte, account common.Address) (types.HexBytes, []byte, error) {
+	if c.account == nil {
+		return nil, nil, ErrAccountNotConfigured
+	}
+	signature, err := c.signVocdoniTx(marshaledTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	stx, err := vochaintx.NewRecoveredAccountSignedTx(marshaledTx, account, signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	stxb, err := proto.Marshal(stx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.SendTx(stxb)
+}
+
+// sendSetAccountTx signs and sends the SetAccountTx with the account associated
+// with the client.
+func (c *HTTPclient) sendSetAccountTx(setAccountTx *models.SetAccountTx) (types.HexBytes, error) {
+	tx, err := proto.Marshal(&models.Tx{
+		Payload: &models.Tx_SetAccount{SetAccount: setAccountTx},
+	})
+	if err != nil {
+		return nil, err
+	}
+	txHash, _, err := c.SignAndSendTx(tx)
+	return txHash, err
+}
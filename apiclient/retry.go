@@ -0,0 +1,154 @@
+package apiclient
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by the requests made while the circuit breaker of
+// the client is open, see BreakerPolicy.
+var ErrCircuitOpen = fmt.Errorf("circuit breaker open")
+
+// ClientOptions configures how the client deals with an unreliable API server,
+// see WithClientOptions. The zero value of each field keeps the default
+// behavior.
+type ClientOptions struct {
+	// Retry is the retry policy of the GET requests which fail because the
+	// API server, or the gateway in front of it, could not be reached.
+	Retry *RetryPolicy
+	// Timeout is the timeout of each HTTP request.
+	Timeout time.Duration
+	// Breaker is the circuit breaker policy of the requests, ignored if
+	// its Failures is not positive.
+	Breaker *BreakerPolicy
+	// RateLimit is the rate limit policy of the requests.
+	RateLimit *RateLimitPolicy
+}
+
+// RetryPolicy retries the idempotent (GET) requests which fail with a network
+// error or a gateway error (502 or 504), waiting an exponential backoff with
+// jitter between the attempts. The requests rejected because the mempool is
+// full are retried regardless, as configured by SetRetries.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a request, including
+	// the first one.
+	MaxAttempts int
+	// InitialBackoff is the wait before the second attempt, which is doubled
+	// on each attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum wait between two attempts.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is a retry policy suitable for most of the clients.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 250 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// backoff returns the wait after the given failed attempt, starting at one,
+// which is a random duration between the half and the whole of the exponential
+// backoff, so that the clients failing at once do not retry at once.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MaxBackoff
+	if attempt < 32 && p.InitialBackoff<<(attempt-1) < p.MaxBackoff {
+		d = p.InitialBackoff << (attempt - 1)
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// BreakerPolicy trips the circuit breaker of the client after a number of
+// consecutive requests fail with a network error or a gateway error (502 or
+// 504). While it is open, the requests fail immediately with ErrCircuitOpen.
+// Once the cooldown is over, a single request is let through to probe the API
+// server, which closes the breaker if it succeeds or opens it again otherwise.
+type BreakerPolicy struct {
+	// Failures is the number of consecutive failures which trip the breaker.
+	Failures int
+	// Cooldown is the time the breaker stays open before probing the API
+	// server again.
+	Cooldown time.Duration
+}
+
+// WithClientOptions configures the retry policy, the timeout, the circuit
+// breaker and the rate limit of the client. The clones of the client share its
+// circuit breaker and its rate limit, since they use the same API server.
+func WithClientOptions(opts ClientOptions) Option {
+	return func(c *HTTPclient) {
+		if opts.Retry != nil {
+			retry := *opts.Retry
+			c.retryPolicy = &retry
+		}
+		if opts.Timeout > 0 {
+			c.SetTimeout(opts.Timeout)
+		}
+		if opts.Breaker != nil && opts.Breaker.Failures > 0 {
+			c.breaker = &circuitBreaker{policy: *opts.Breaker}
+		}
+		if opts.RateLimit != nil {
+			c.limiter = newRateLimiter(*opts.RateLimit)
+		}
+	}
+}
+
+// circuitBreaker is the state of the circuit breaker of a client, see
+// BreakerPolicy. A nil circuitBreaker never trips.
+type circuitBreaker struct {
+	policy BreakerPolicy
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow returns ErrCircuitOpen if the breaker does not let a request through.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.policy.Failures {
+		return nil
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return fmt.Errorf("%w after %d consecutive failures", ErrCircuitOpen, b.failures)
+	}
+	b.probing = true
+	return nil
+}
+
+// record updates the breaker with the result of a request let through.
+func (b *circuitBreaker) record(failed bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.policy.Failures {
+		b.openUntil = time.Now().Add(b.policy.Cooldown)
+	}
+}
+
+// isGatewayFailure returns whether the request failed because the API server,
+// or the gateway in front of it, could not be reached.
+func isGatewayFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusGatewayTimeout
+}
@@ -0,0 +1,210 @@
+package apiclient_test
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+)
+
+// flakyServer is an API server whose elections endpoint fails with the status
+// code the next failures requests, 502 Bad Gateway unless set.
+type flakyServer struct {
+	url      string
+	status   atomic.Int32
+	failures atomic.Int32
+	requests atomic.Int32
+	// delay is the time the requests take, in milliseconds.
+	delay atomic.Int32
+}
+
+func newFlakyServer(t *testing.T) *flakyServer {
+	s := &flakyServer{}
+	s.status.Store(http.StatusBadGateway)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/elections" {
+			http.NotFound(w, r)
+			return
+		}
+		s.requests.Add(1)
+		time.Sleep(time.Duration(s.delay.Load()) * time.Millisecond)
+		if s.failures.Add(-1) >= 0 {
+			w.WriteHeader(int(s.status.Load()))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	s.url = srv.URL + "/"
+	return s
+}
+
+// request sends a request to the elections endpoint and returns its status
+// code, and the number of attempts made.
+func (s *flakyServer) request(cli *apiclient.HTTPclient, method string, failures int32) (int, int32, error) {
+	s.failures.Store(failures)
+	s.requests.Store(0)
+	_, code, err := cli.Request(method, nil, "elections")
+	return code, s.requests.Load(), err
+}
+
+func TestRetryPolicy(t *testing.T) {
+	c := qt.New(t)
+	s := newFlakyServer(t)
+	cli, err := apiclient.New(s.url, apiclient.WithClientOptions(apiclient.ClientOptions{
+		Retry: &apiclient.RetryPolicy{MaxAttempts: 3, InitialBackoff: 20 * time.Millisecond, MaxBackoff: time.Second},
+	}))
+	c.Assert(err, qt.IsNil)
+
+	// the attempts wait at least half of the exponential backoff
+	start := time.Now()
+	code, attempts, err := s.request(cli, apiclient.HTTPGET, 2)
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, http.StatusOK)
+	c.Assert(attempts, qt.Equals, int32(3))
+	c.Assert(time.Since(start) >= 30*time.Millisecond, qt.IsTrue)
+
+	// the last failure is returned once the attempts are exhausted
+	code, attempts, err = s.request(cli, apiclient.HTTPGET, 5)
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, http.StatusBadGateway)
+	c.Assert(attempts, qt.Equals, int32(3))
+
+	// the gateway timeouts are retried, but not the errors of the API server
+	s.status.Store(http.StatusGatewayTimeout)
+	code, attempts, err = s.request(cli, apiclient.HTTPGET, 1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, http.StatusOK)
+	c.Assert(attempts, qt.Equals, int32(2))
+	for _, status := range []int32{http.StatusInternalServerError, http.StatusNotFound} {
+		s.status.Store(status)
+		code, attempts, err = s.request(cli, apiclient.HTTPGET, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(code, qt.Equals, int(status))
+		c.Assert(attempts, qt.Equals, int32(1))
+	}
+	s.status.Store(http.StatusBadGateway)
+
+	// the requests which are not idempotent are not retried
+	code, attempts, err = s.request(cli, apiclient.HTTPPOST, 1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, http.StatusBadGateway)
+	c.Assert(attempts, qt.Equals, int32(1))
+
+	// without a policy, a failure is not retried
+	noRetry, err := apiclient.New(s.url)
+	c.Assert(err, qt.IsNil)
+	code, attempts, err = s.request(noRetry, apiclient.HTTPGET, 1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, http.StatusBadGateway)
+	c.Assert(attempts, qt.Equals, int32(1))
+}
+
+func TestRetryPolicyTimeout(t *testing.T) {
+	c := qt.New(t)
+	s := newFlakyServer(t)
+	cli, err := apiclient.New(s.url, apiclient.WithClientOptions(apiclient.ClientOptions{
+		Retry:   &apiclient.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		Timeout: 50 * time.Millisecond,
+	}))
+	c.Assert(err, qt.IsNil)
+
+	// the requests which time out are retried, and the error of the last
+	// attempt is returned
+	s.delay.Store(200)
+	_, attempts, err := s.request(cli, apiclient.HTTPGET, 0)
+	c.Assert(err, qt.ErrorMatches, ".*(Timeout|deadline).*")
+	c.Assert(attempts, qt.Equals, int32(2))
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	c := qt.New(t)
+	s := newFlakyServer(t)
+	cooldown := 50 * time.Millisecond
+	cli, err := apiclient.New(s.url, apiclient.WithClientOptions(apiclient.ClientOptions{
+		Breaker: &apiclient.BreakerPolicy{Failures: 2, Cooldown: cooldown},
+	}))
+	c.Assert(err, qt.IsNil)
+
+	// the failures must be consecutive, and the errors of the API server are
+	// not failures of the gateway
+	for _, status := range []int32{http.StatusBadGateway, http.StatusInternalServerError, http.StatusBadGateway} {
+		s.status.Store(status)
+		code, _, err := s.request(cli, apiclient.HTTPGET, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(code, qt.Equals, int(status))
+		_, _, err = s.request(cli, apiclient.HTTPGET, 0)
+		c.Assert(err, qt.IsNil)
+	}
+
+	// the breaker trips after two consecutive failures, for the clones too
+	for range 2 {
+		code, _, err := s.request(cli, apiclient.HTTPGET, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(code, qt.Equals, http.StatusBadGateway)
+	}
+	_, attempts, err := s.request(cli, apiclient.HTTPGET, 0)
+	c.Assert(err, qt.ErrorIs, apiclient.ErrCircuitOpen)
+	c.Assert(err, qt.ErrorMatches, ".* after 2 consecutive failures")
+	c.Assert(attempts, qt.Equals, int32(0))
+	key := ethereum.NewSignKeys()
+	c.Assert(key.Generate(), qt.IsNil)
+	_, attempts, err = s.request(cli.Clone(hex.EncodeToString(key.PrivateKey())), apiclient.HTTPPOST, 0)
+	c.Assert(err, qt.ErrorIs, apiclient.ErrCircuitOpen)
+	c.Assert(attempts, qt.Equals, int32(0))
+
+	// once the cooldown is over, a failed probe opens it again
+	time.Sleep(cooldown)
+	code, attempts, err := s.request(cli, apiclient.HTTPGET, 1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, http.StatusBadGateway)
+	c.Assert(attempts, qt.Equals, int32(1))
+	_, _, err = s.request(cli, apiclient.HTTPGET, 0)
+	c.Assert(err, qt.ErrorIs, apiclient.ErrCircuitOpen)
+
+	// only one probe is let through at a time
+	time.Sleep(cooldown)
+	s.delay.Store(100)
+	probed := make(chan error, 1)
+	go func() {
+		_, _, err := cli.Request(apiclient.HTTPGET, nil, "elections")
+		probed <- err
+	}()
+	for s.requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	_, _, err = cli.Request(apiclient.HTTPGET, nil, "elections")
+	c.Assert(err, qt.ErrorIs, apiclient.ErrCircuitOpen)
+	c.Assert(<-probed, qt.IsNil)
+	s.delay.Store(0)
+
+	// and a successful one closes it
+	for range 3 {
+		code, _, err = s.request(cli, apiclient.HTTPGET, 0)
+		c.Assert(err, qt.IsNil)
+		c.Assert(code, qt.Equals, http.StatusOK)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	c := qt.New(t)
+	s := newFlakyServer(t)
+	cli, err := apiclient.New(s.url, apiclient.WithClientOptions(apiclient.ClientOptions{
+		Breaker: &apiclient.BreakerPolicy{Cooldown: time.Hour},
+	}))
+	c.Assert(err, qt.IsNil)
+
+	// a breaker without failures never trips
+	for range 5 {
+		code, attempts, err := s.request(cli, apiclient.HTTPGET, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(code, qt.Equals, http.StatusBadGateway)
+		c.Assert(attempts, qt.Equals, int32(1))
+	}
+}
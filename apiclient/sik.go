@@ -23,6 +23,12 @@ func (c *HTTPclient) ValidSIK() (bool, error) {
 	return true, nil
 }
 
+// SIKValidity returns the remaining validity of the SIK of the current client
+// account.
+func (c *HTTPclient) SIKValidity() (*api.SIKValidity, error) {
+	return c.Endpoints().SikValidity(c.account.AddressString())
+}
+
 // ValidSIKRoots returns the currently valid roots of SIK merkle tree from the
 // API.
 func (c *HTTPclient) ValidSIKRoots() (SIKRoots, error) {
@@ -0,0 +1,82 @@
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the OpenTelemetry tracer of the client.
+const tracerName = "go.vocdoni.io/dvote/apiclient"
+
+// TracingMiddleware is a Middleware which traces the requests and operations
+// of the client with OpenTelemetry spans. The requests carry the context of
+// their span in the W3C traceparent header, so that the trace can continue in
+// the API server and the services behind it.
+type TracingMiddleware struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewTracingMiddleware returns a TracingMiddleware creating the spans with the
+// tracer provider tp, or with the global one if tp is nil.
+func NewTracingMiddleware(tp trace.TracerProvider) *TracingMiddleware {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &TracingMiddleware{
+		tracer:     tp.Tracer(tracerName),
+		propagator: propagation.TraceContext{},
+	}
+}
+
+// StartOperation implements Middleware.
+func (t *TracingMiddleware) StartOperation(ctx context.Context, name string, keyvals ...any) (context.Context, func(error)) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(spanAttributes(keyvals)...))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// OnRequest implements Middleware.
+func (t *TracingMiddleware) OnRequest(req *http.Request) func(*http.Response, error) {
+	ctx, span := t.tracer.Start(req.Context(), req.Method+" "+metricsEndpoint([]string{req.URL.Path}),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.String()),
+		))
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return func(resp *http.Response, err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else {
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+			if resp.StatusCode >= http.StatusBadRequest {
+				span.SetStatus(codes.Error, resp.Status)
+			}
+		}
+		span.End()
+	}
+}
+
+// spanAttributes returns the span attributes of the alternating keys and
+// values, formatted as strings.
+func spanAttributes(keyvals []any) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(keyvals)/2)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		attrs = append(attrs, attribute.String(fmt.Sprint(keyvals[i]), fmt.Sprint(keyvals[i+1])))
+	}
+	return attrs
+}
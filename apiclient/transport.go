@@ -0,0 +1,39 @@
+package apiclient
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+
+	"go.vocdoni.io/dvote/httprouter"
+)
+
+// binaryAccept is the Accept header of the clients with the binary transport,
+// which prefer CBOR but accept JSON from the API servers which do not support
+// it.
+var binaryAccept = httprouter.ContentTypeCBOR + ", " + httprouter.DefaultContentType + ";q=0.9"
+
+// WithBinaryTransport makes the client ask the API server for its responses
+// encoded in CBOR, which are smaller than JSON, mostly for the large ones such
+// as the census proofs and the lists of votes. The API servers which do not
+// support it reply in JSON, so it is safe to enable it with any of them. The
+// responses are returned in JSON by Request, whatever their encoding, so the
+// callers are not affected.
+func WithBinaryTransport() Option {
+	return func(c *HTTPclient) {
+		c.binaryTransport = true
+	}
+}
+
+// decodeResponse returns the body of a response in JSON, decoding it if the
+// API server sent it in CBOR.
+func decodeResponse(header http.Header, data []byte) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil || mediaType != httprouter.ContentTypeCBOR {
+		return data, nil
+	}
+	if data, err = httprouter.CBORToJSON(data); err != nil {
+		return nil, fmt.Errorf("cannot decode CBOR response: %w", err)
+	}
+	return data, nil
+}
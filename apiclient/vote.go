@@ -1,14 +1,16 @@
 package apiclient
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	"go.vocdoni.io/dvote/api"
 	"go.vocdoni.io/dvote/crypto/ethereum"
-	"go.vocdoni.io/dvote/crypto/nacl"
 	"go.vocdoni.io/dvote/crypto/zk"
 	"go.vocdoni.io/dvote/crypto/zk/circuit"
 	"go.vocdoni.io/dvote/crypto/zk/prover"
@@ -21,6 +23,40 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+var (
+	// ErrNoVoteToOverwrite is returned by OverwriteVote when the voter has not voted in the election.
+	ErrNoVoteToOverwrite = fmt.Errorf("no vote to overwrite")
+	// ErrVoteOverwritesExhausted is returned by OverwriteVote when the vote was already
+	// overwritten the maximum number of times allowed by the election.
+	ErrVoteOverwritesExhausted = fmt.Errorf("vote overwrites exhausted")
+	// ErrSIKMismatch is returned by VoteAnonymous when the account has a SIK
+	// registered that was not derived from the secret provided.
+	ErrSIKMismatch = fmt.Errorf("registered SIK does not match the secret")
+)
+
+// AnonymousVoteStep identifies each step of VoteAnonymous, reported to its
+// progress function before the step starts.
+type AnonymousVoteStep string
+
+const (
+	// AnonymousVoteStepElection fetches the election.
+	AnonymousVoteStepElection AnonymousVoteStep = "election"
+	// AnonymousVoteStepSIK derives the SIK of the account and checks if it is
+	// already registered.
+	AnonymousVoteStepSIK AnonymousVoteStep = "sik"
+	// AnonymousVoteStepRegisterSIK registers the SIK for the election and waits
+	// until the SIK tree includes it. It is skipped if the SIK is registered.
+	AnonymousVoteStepRegisterSIK AnonymousVoteStep = "registerSIK"
+	// AnonymousVoteStepProofs fetches the census and SIK proofs of the account.
+	AnonymousVoteStepProofs AnonymousVoteStep = "proofs"
+	// AnonymousVoteStepCircuit loads the zk circuit used by the chain.
+	AnonymousVoteStepCircuit AnonymousVoteStep = "circuit"
+	// AnonymousVoteStepVote generates the zk proof and casts the vote.
+	AnonymousVoteStepVote AnonymousVoteStep = "vote"
+	// AnonymousVoteStepDone is reported once the vote is accepted.
+	AnonymousVoteStepDone AnonymousVoteStep = "done"
+)
+
 // VoteData contains the data needed to create a vote.
 //
 // Choices is a list of choices, where each position represents a question.
@@ -29,6 +65,7 @@ import (
 // to the  weight registered in the census. If is defined as nil, it will be
 // equal to the registered one.
 // ProofMkTree is the proof of the vote for an off chain tree, weighted election.
+// If it is nil, the proof of the voter is taken from ProofBundle, if set.
 // ProofCSP is the proof of the vote for a CSP election.
 //
 // KeyType is the type of the key used when the census was created. It can be
@@ -42,11 +79,16 @@ type VoteData struct {
 	ProofMkTree  *CensusProof
 	ProofSIKTree *CensusProof
 	ProofCSP     types.HexBytes
+	ProofBundle  *ProofBundle
 	Keys         []api.Key
 
 	// if VoterAccount is set, it will be used to sign the vote
 	// instead of the keys found in HTTPclient.account
 	VoterAccount *ethereum.SignKeys
+
+	// SIKSecret is the secret used to derive the SIK of the voter in
+	// anonymous elections, nil if the SIK was derived without secret.
+	SIKSecret []byte
 }
 
 // Vote sends a vote to the Vochain. The vote is a VoteData struct,
@@ -54,14 +96,21 @@ type VoteData struct {
 // if VoterAccount is set, it's used to sign the vote, else it defaults
 // to signing with the account set in HTTPclient.
 // The return value is the voteID (nullifier).
-func (cl *HTTPclient) Vote(v *VoteData) (types.HexBytes, error) {
+func (cl *HTTPclient) Vote(v *VoteData) (_ types.HexBytes, err error) {
+	cl, end := cl.startOperation(OperationVote, "electionId", v.Election.ElectionID.String())
+	defer func() { end(err) }()
 	c := cl
 	if v.VoterAccount != nil {
 		c = cl.Clone(hex.EncodeToString(v.VoterAccount.PrivateKey()))
 	}
 
+	if v.ProofMkTree == nil && v.ProofBundle != nil {
+		if v.ProofMkTree, err = v.ProofBundle.Proof(v.Election.Census.CensusRoot, c.account.Address().Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
 	var vote *models.VoteEnvelope
-	var err error
 
 	if v.Keys != nil {
 		vote, err = c.voteEnvelopeWithKeys(v.Choices, v.Keys, v.Election)
@@ -86,7 +135,8 @@ func (cl *HTTPclient) Vote(v *VoteData) (types.HexBytes, error) {
 		// information and encode it into a json
 		rawInputs, err := circuit.GenerateCircuitInput(circuit.CircuitInputsParameters{
 			Account:         c.account,
-			ElectionId:      v.Election.ElectionID,
+			Password:        v.SIKSecret,
+			ElectionId:      nullifierScope(v.Election),
 			CensusRoot:      v.Election.Census.CensusRoot,
 			SIKRoot:         v.ProofSIKTree.Root,
 			CensusSiblings:  v.ProofMkTree.Siblings,
@@ -103,10 +153,22 @@ func (cl *HTTPclient) Vote(v *VoteData) (types.HexBytes, error) {
 			return nil, fmt.Errorf("error encoding inputs: %w", err)
 		}
 		// instance the prover with the circuit config loaded and generate the
-		// proof for the calculated inputs
-		proof, err := prover.Prove(c.circuit.ProvingKey, c.circuit.Wasm, inputs)
-		if err != nil {
-			return nil, fmt.Errorf("could not generate anonymous proof: %w", err)
+		// proof for the calculated inputs, unless it is cached
+		voterKey := c.account.Address().Bytes()
+		proof := c.proofCache.zkProof(v.Election.Census.CensusRoot, voterKey, v.Election.ElectionID, inputs)
+		if proof == nil {
+			if err := c.loadCircuit(); err != nil {
+				return nil, err
+			}
+			_, endProof := c.startOperation(OperationZkProof, "electionId", v.Election.ElectionID.String())
+			start := time.Now()
+			proof, err = prover.Prove(c.circuit.ProvingKey, c.circuit.Wasm, inputs)
+			c.metrics.observeZkProof(start)
+			endProof(err)
+			if err != nil {
+				return nil, fmt.Errorf("could not generate anonymous proof: %w", err)
+			}
+			c.proofCache.putZkProof(v.Election.Census.CensusRoot, voterKey, v.Election.ElectionID, inputs, proof)
 		}
 		// encode the proof into a protobuf
 		protoProof, err := zk.ProverProofToProtobufZKProof(proof, nil, nil, nil, nil, nil)
@@ -178,23 +240,222 @@ func (cl *HTTPclient) Vote(v *VoteData) (types.HexBytes, error) {
 	if err := json.Unmarshal(resp, &voteAPI); err != nil {
 		return nil, fmt.Errorf("could not unmarshal response: %v", err)
 	}
+	c.ballots.put(v.Election.ElectionID, c.account.Address(), v.Choices, v.SIKSecret)
 	// return the voteID received from the API as result of success vote
 	return voteAPI.VoteID, nil
 }
 
-// Verify verifies a vote. The voteID is the nullifier of the vote.
-func (c *HTTPclient) Verify(electionID, voteID types.HexBytes) (bool, error) {
-	resp, code, err := c.Request(HTTPGET, nil, "votes", "verify", electionID.String(), voteID.String())
+// OverwriteVote replaces the vote previously cast by the voter (VoterAccount if
+// set, else the account of the HTTPclient) in the election of the VoteData.
+// Before sending the new vote, it checks that a vote exists and that the
+// election still allows to overwrite it, returning ErrNoVoteToOverwrite or
+// ErrVoteOverwritesExhausted otherwise. The census proofs of the VoteData are
+// reused if set, else they are requested to the API (except for CSP elections).
+// Note that the overwrite count is taken from the indexed vote, so overwrites
+// not yet included in a block are not accounted.
+func (cl *HTTPclient) OverwriteVote(v *VoteData) (types.HexBytes, error) {
+	if v.Election == nil {
+		return nil, fmt.Errorf("election is required")
+	}
+	c := cl
+	if v.VoterAccount != nil {
+		c = cl.Clone(hex.EncodeToString(v.VoterAccount.PrivateKey()))
+	}
+	if c.account == nil {
+		return nil, ErrAccountNotConfigured
+	}
+	voteID, err := c.voteNullifier(v.Election, v.SIKSecret)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	if code == 200 {
-		return true, nil
+	prior, err := c.Endpoints().GetVote(voteID.String(), nil)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == apirest.HTTPstatusNotFound {
+			return nil, ErrNoVoteToOverwrite
+		}
+		return nil, err
+	}
+	overwrites := uint32(0)
+	if prior.OverwriteCount != nil {
+		overwrites = *prior.OverwriteCount
+	}
+	if maxOverwrites := v.Election.TallyMode.GetMaxVoteOverwrites(); overwrites >= maxOverwrites {
+		return nil, fmt.Errorf("%w: vote %x overwritten %d times, the election allows %d",
+			ErrVoteOverwritesExhausted, voteID, overwrites, maxOverwrites)
+	}
+	if err := c.fillVoteProofs(v); err != nil {
+		return nil, err
+	}
+	log.Debugw("overwriting vote", "voteID", voteID, "overwrites", overwrites)
+	return cl.Vote(v)
+}
+
+// VoteAnonymous casts the choices of the client account in the anonymous
+// election electionID. It derives the SIK of the account from the secret
+// (which can be nil), registers it for the election if the account has none,
+// fetches the census and SIK proofs, loads the circuit, and generates the zk
+// proof of the vote. The optional progress function is called before each
+// step. If the account has a SIK that was derived from a different secret,
+// ErrSIKMismatch is returned. The return value is the voteID (nullifier).
+func (c *HTTPclient) VoteAnonymous(electionID types.HexBytes, choices []int, secret []byte,
+	progress func(AnonymousVoteStep),
+) (types.HexBytes, error) {
+	if c.account == nil {
+		return nil, ErrAccountNotConfigured
+	}
+	if progress == nil {
+		progress = func(AnonymousVoteStep) {}
+	}
+	progress(AnonymousVoteStepElection)
+	election, err := c.Election(electionID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get election: %w", err)
+	}
+	if !election.VoteMode.Anonymous {
+		return nil, fmt.Errorf("election %s is not anonymous", electionID)
+	}
+
+	progress(AnonymousVoteStepSIK)
+	sik, err := c.account.AccountSIK(secret)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate the sik: %w", err)
+	}
+	registered, err := c.Endpoints().SikValid(c.account.AddressString())
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode == apirest.HTTPstatusNotFound:
+		progress(AnonymousVoteStepRegisterSIK)
+		if err := c.registerSIK(election, secret); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, fmt.Errorf("could not get the registered SIK: %w", err)
+	case registered.Sik != hex.EncodeToString(sik):
+		return nil, ErrSIKMismatch
+	}
+
+	progress(AnonymousVoteStepProofs)
+	v := &VoteData{
+		Choices:   choices,
+		Election:  election,
+		SIKSecret: secret,
+	}
+	if err := c.fillVoteProofs(v); err != nil {
+		return nil, err
+	}
+
+	progress(AnonymousVoteStepCircuit)
+	if err := c.loadCircuit(); err != nil {
+		return nil, err
+	}
+
+	progress(AnonymousVoteStepVote)
+	voteID, err := c.Vote(v)
+	if err != nil {
+		return nil, err
+	}
+	progress(AnonymousVoteStepDone)
+	return voteID, nil
+}
+
+// registerSIK registers the SIK derived from secret for the election, and
+// waits until it is included in the SIK tree, so a proof can be generated.
+func (c *HTTPclient) registerSIK(election *api.Election, secret []byte) error {
+	hash, err := c.RegisterSIKForVote(election.ElectionID, nil, secret)
+	if err != nil {
+		return fmt.Errorf("could not register SIK: %w", err)
 	}
-	if code == 404 {
+	ctx, cancel := context.WithTimeout(context.Background(), WaitTimeout)
+	defer cancel()
+	if _, err := c.WaitUntilTxIsMined(ctx, hash); err != nil {
+		return err
+	}
+	// the SIK root is updated with the next block
+	return c.WaitUntilNextBlock()
+}
+
+// loadCircuit loads the circuit of the chain, if it was not loaded yet.
+func (c *HTTPclient) loadCircuit() error {
+	if c.circuit != nil {
+		return nil
+	}
+	info, err := c.ChainInfo()
+	if err != nil {
+		return fmt.Errorf("could not get chain info: %w", err)
+	}
+	if info.CircuitVersion == "" {
+		return fmt.Errorf("the API server has no circuit for the anonymous votes")
+	}
+	if c.circuit, err = circuit.LoadVersion(info.CircuitVersion); err != nil {
+		return fmt.Errorf("error loading circuit: %w", err)
+	}
+	return nil
+}
+
+// voteNullifier returns the nullifier (voteID) of the vote of the client
+// account in the given election. The secret is only used by anonymous elections.
+func (c *HTTPclient) voteNullifier(election *api.Election, secret []byte) (types.HexBytes, error) {
+	if election.VoteMode.Anonymous {
+		return c.account.AccountSIKnullifier(nullifierScope(election), secret)
+	}
+	return state.GenerateNullifier(c.account.Address(), election.ElectionID), nil
+}
+
+// nullifierScope returns the scope of the nullifiers of the anonymous votes of
+// the election, which is shared by the elections of its nullifier group.
+func nullifierScope(election *api.Election) types.HexBytes {
+	if len(election.NullifierGroup) == 0 {
+		return election.ElectionID
+	}
+	return state.NullifierGroupScope(election.OrganizationID, election.NullifierGroup)
+}
+
+// fillVoteProofs requests to the API the census proofs required to vote in
+// the election of v, if they are not set.
+func (c *HTTPclient) fillVoteProofs(v *VoteData) error {
+	censusOriginCSP := models.CensusOrigin_name[int32(models.CensusOrigin_OFF_CHAIN_CA)]
+	if v.Election.Census.CensusOrigin == censusOriginCSP {
+		if v.ProofCSP == nil {
+			return fmt.Errorf("CSP proof is required")
+		}
+		return nil
+	}
+	var err error
+	if v.ProofMkTree == nil {
+		v.ProofMkTree, err = c.CensusGenProof(v.Election.Census.CensusRoot, c.account.Address().Bytes())
+		if err != nil {
+			return fmt.Errorf("could not get census proof: %w", err)
+		}
+	}
+	if v.Election.VoteMode.Anonymous && v.ProofSIKTree == nil {
+		v.ProofSIKTree, err = c.GenSIKProof()
+		if err != nil {
+			return fmt.Errorf("could not get SIK proof: %w", err)
+		}
+	}
+	return nil
+}
+
+// Verify verifies a vote. The voteID is the nullifier of the vote.
+func (c *HTTPclient) Verify(electionID, voteID types.HexBytes) (bool, error) {
+	err := c.Endpoints().VerifyVote(electionID.String(), voteID.String())
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == apirest.HTTPstatusNotFound {
 		return false, nil
 	}
-	return false, fmt.Errorf("%s: %d (%s)", errCodeNot200, code, resp)
+	return err == nil, err
+}
+
+// VerifyZkProof asks the API to verify a zk-SNARK vote proof for the given election,
+// without casting the vote. The votePackage must be the exact vote package bytes
+// (encrypted, if so) included in the vote envelope. A proof that fails the
+// verification is not considered an error, the reason is returned in the result.
+func (c *HTTPclient) VerifyZkProof(electionID types.HexBytes, proof *prover.Proof, votePackage []byte) (*api.ZkProofVerificationResult, error) {
+	return c.Endpoints().ElectionVerifyZkProof(electionID.String(), &api.ZkProofVerification{
+		Proof:       proof,
+		VotePackage: votePackage,
+	})
 }
 
 // prepareVoteEnvelope returns a models.VoteEnvelope struct with
@@ -216,25 +477,22 @@ func (c *HTTPclient) prepareVoteEnvelope(choices []int, election *api.Election)
 	return c.voteEnvelopeWithKeys(choices, keysEnc, election)
 }
 
-func (c *HTTPclient) voteEnvelopeWithKeys(choices []int, keysEnc []api.Key, election *api.Election) (*models.VoteEnvelope, error) {
-	var keys []types.HexBytes
-	var keyIndexes []uint32
+func (*HTTPclient) voteEnvelopeWithKeys(choices []int, keysEnc []api.Key, election *api.Election) (*models.VoteEnvelope, error) {
+	vp := &state.VotePackage{Votes: choices}
+	if len(election.NullifierGroup) > 0 {
+		// binds the vote to the election, since the proof is bound to the group
+		vp.Nonce = election.ElectionID.String()
+	}
 
+	var vpb []byte
+	var keyIndexes []uint32
+	var err error
 	if election.VoteMode.EncryptedVotes {
-		for _, k := range keysEnc {
-			if len(k.Key) > 0 {
-				keys = append(keys, k.Key)
-				keyIndexes = append(keyIndexes, uint32(k.Index))
-			}
-		}
-		if len(keys) == 0 {
-			return nil, fmt.Errorf("no keys for election %x", election.ElectionID)
+		vpb, keyIndexes, err = EncryptVotePackage(vp, keysEnc)
+		if err != nil {
+			return nil, fmt.Errorf("election %x: %w", election.ElectionID, err)
 		}
-	}
-
-	// if EncryptedVotes is false, keys will be nil and prepareVotePackageBytes returns plaintext
-	vpb, err := c.prepareVotePackageBytes(&state.VotePackage{Votes: choices}, keys)
-	if err != nil {
+	} else if vpb, err = json.Marshal(vp); err != nil {
 		return nil, err
 	}
 
@@ -246,37 +504,6 @@ func (c *HTTPclient) voteEnvelopeWithKeys(choices []int, keysEnc []api.Key, elec
 	}, nil
 }
 
-// prepareVotePackageBytes returns a plaintext json.Marshal(vp) if keys is nil,
-// else assigns a random hex string to vp.Nonce
-// and encrypts the vp bytes for each given key as recipient
-func (*HTTPclient) prepareVotePackageBytes(vp *state.VotePackage, keys []types.HexBytes) ([]byte, error) {
-	if len(keys) > 0 {
-		vp.Nonce = fmt.Sprintf("%x", util.RandomHex(32))
-	}
-
-	vpb, err := json.Marshal(vp)
-	if err != nil {
-		return nil, err
-	}
-
-	for i, k := range keys { // skipped if len(keys) == 0
-		if len(k) == 0 {
-			continue
-		}
-		log.Debugw("encrypting vote", "nonce", vp.Nonce, "key", k)
-		pub, err := nacl.DecodePublic(k.String())
-		if err != nil {
-			return nil, fmt.Errorf("cannot decode encryption key with index %d: (%s)", i, err)
-		}
-		if vpb, err = nacl.Anonymous.Encrypt(vpb, pub); err != nil {
-			return nil, fmt.Errorf("cannot encrypt: (%s)", err)
-		}
-
-	}
-
-	return vpb, nil
-}
-
 // prepareVoteTx prepare an api.Vote struct with the inner transactions encoded
 // based on the vote provided and if it is signed or not.
 func (c *HTTPclient) prepareVoteTx(vote *models.VoteEnvelope, signed bool) (*api.Vote, error) {
@@ -293,7 +520,7 @@ func (c *HTTPclient) prepareVoteTx(vote *models.VoteEnvelope, signed bool) (*api
 
 	// If it needs to be signed, sign the vote transaction
 	if signed {
-		stx.Signature, err = c.account.SignVocdoniTx(stx.Tx, c.ChainID())
+		stx.Signature, err = c.signVocdoniTx(stx.Tx)
 	}
 	if err != nil {
 		return nil, err
@@ -0,0 +1,265 @@
+package apiclient_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+func TestOverwriteVote(t *testing.T) {
+	c := qt.New(t)
+	voter, other := ethereum.NewSignKeys(), ethereum.NewSignKeys()
+	c.Assert(voter.Generate(), qt.IsNil)
+	c.Assert(other.Generate(), qt.IsNil)
+	election := &api.Election{
+		ElectionSummary: api.ElectionSummary{ElectionID: util.RandomBytes(32)},
+		Census: &api.ElectionCensus{
+			CensusRoot:   util.RandomBytes(32),
+			CensusOrigin: models.CensusOrigin_OFF_CHAIN_TREE_WEIGHTED.String(),
+		},
+		VoteMode:  api.VoteMode{EnvelopeType: &models.EnvelopeType{}},
+		TallyMode: api.TallyMode{ProcessVoteOptions: &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1, MaxVoteOverwrites: 2}},
+	}
+
+	// the gateway knows the votes by their ID, with their overwrite count
+	overwrites := map[string]*uint32{}
+	lookupFails := false
+	var looked []string
+	proofs, posted := 0, 0
+	reply := func(w http.ResponseWriter, v any) {
+		c.Assert(json.NewEncoder(w).Encode(v), qt.IsNil)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /votes/{voteId}", func(w http.ResponseWriter, r *http.Request) {
+		looked = append(looked, r.PathValue("voteId"))
+		count, ok := overwrites[r.PathValue("voteId")]
+		switch {
+		case lookupFails:
+			http.Error(w, "indexer unavailable", http.StatusInternalServerError)
+		case !ok:
+			http.NotFound(w, r)
+		default:
+			reply(w, &api.Vote{OverwriteCount: count})
+		}
+	})
+	mux.HandleFunc("GET /censuses/{root}/proof/{key}", func(w http.ResponseWriter, _ *http.Request) {
+		proofs++
+		reply(w, &api.Census{CensusProof: util.RandomBytes(32)})
+	})
+	mux.HandleFunc("POST /votes", func(w http.ResponseWriter, _ *http.Request) {
+		posted++
+		reply(w, &api.Vote{VoteID: util.RandomBytes(32)})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	cli, err := apiclient.New(srv.URL + "/")
+	c.Assert(err, qt.IsNil)
+
+	// an account and an election are required
+	_, err = cli.OverwriteVote(&apiclient.VoteData{Choices: []int{0}, Election: election})
+	c.Assert(err, qt.ErrorIs, apiclient.ErrAccountNotConfigured)
+	c.Assert(cli.SetAccount(hex.EncodeToString(voter.PrivateKey())), qt.IsNil)
+	_, err = cli.OverwriteVote(&apiclient.VoteData{Choices: []int{0}})
+	c.Assert(err, qt.ErrorMatches, "election is required")
+
+	// the prior vote is looked up by the nullifier of the voter, and there is
+	// nothing to overwrite until the gateway knows it
+	voteID := hex.EncodeToString(state.GenerateNullifier(voter.Address(), election.ElectionID))
+	_, err = cli.OverwriteVote(&apiclient.VoteData{Choices: []int{0}, Election: election})
+	c.Assert(err, qt.ErrorIs, apiclient.ErrNoVoteToOverwrite)
+	c.Assert(looked, qt.DeepEquals, []string{voteID})
+
+	// the lookup errors other than not found are not taken as no vote
+	lookupFails = true
+	_, err = cli.OverwriteVote(&apiclient.VoteData{Choices: []int{0}, Election: election})
+	c.Assert(err, qt.ErrorMatches, "(?s).*500.*indexer unavailable.*")
+	c.Assert(err, qt.Not(qt.ErrorIs), apiclient.ErrNoVoteToOverwrite)
+	lookupFails = false
+	c.Assert(posted, qt.Equals, 0)
+
+	// a vote never overwritten has no overwrite count, and the census proof is
+	// requested unless it is given
+	overwrites[voteID] = nil
+	_, err = cli.OverwriteVote(&apiclient.VoteData{Choices: []int{0}, Election: election})
+	c.Assert(err, qt.IsNil)
+	c.Assert(proofs, qt.Equals, 1)
+	_, err = cli.OverwriteVote(&apiclient.VoteData{
+		Choices: []int{1}, Election: election, ProofMkTree: &apiclient.CensusProof{Proof: util.RandomBytes(32)},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(proofs, qt.Equals, 1)
+	c.Assert(posted, qt.Equals, 2)
+
+	// the last overwrite allowed by the election is still sent, but no more
+	one, two := uint32(1), uint32(2)
+	overwrites[voteID] = &one
+	_, err = cli.OverwriteVote(&apiclient.VoteData{Choices: []int{0}, Election: election})
+	c.Assert(err, qt.IsNil)
+	overwrites[voteID] = &two
+	_, err = cli.OverwriteVote(&apiclient.VoteData{Choices: []int{0}, Election: election})
+	c.Assert(err, qt.ErrorIs, apiclient.ErrVoteOverwritesExhausted)
+	c.Assert(err, qt.ErrorMatches, ".*overwritten 2 times, the election allows 2")
+	c.Assert(posted, qt.Equals, 3)
+	c.Assert(proofs, qt.Equals, 2)
+
+	// the elections without overwrites reject them from the first vote
+	noOverwrites := *election
+	noOverwrites.TallyMode = api.TallyMode{ProcessVoteOptions: &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1}}
+	overwrites[voteID] = nil
+	_, err = cli.OverwriteVote(&apiclient.VoteData{Choices: []int{0}, Election: &noOverwrites})
+	c.Assert(err, qt.ErrorIs, apiclient.ErrVoteOverwritesExhausted)
+
+	// the vote of VoterAccount is looked up instead of the one of the client
+	looked = nil
+	_, err = cli.OverwriteVote(&apiclient.VoteData{Choices: []int{0}, Election: election, VoterAccount: other})
+	c.Assert(err, qt.ErrorIs, apiclient.ErrNoVoteToOverwrite)
+	c.Assert(looked, qt.DeepEquals, []string{hex.EncodeToString(state.GenerateNullifier(other.Address(), election.ElectionID))})
+
+	// the CSP elections need the proof of the vote, which is not requested
+	csp := *election
+	csp.Census = &api.ElectionCensus{CensusOrigin: models.CensusOrigin_OFF_CHAIN_CA.String()}
+	_, err = cli.OverwriteVote(&apiclient.VoteData{Choices: []int{0}, Election: &csp})
+	c.Assert(err, qt.ErrorMatches, "CSP proof is required")
+	c.Assert(proofs, qt.Equals, 2)
+}
+
+func TestVoteAnonymous(t *testing.T) {
+	c := qt.New(t)
+	voter := ethereum.NewSignKeys()
+	c.Assert(voter.Generate(), qt.IsNil)
+	anonymousID, signedID := types.HexBytes(util.RandomBytes(32)), types.HexBytes(util.RandomBytes(32))
+	registered := ""    // the SIK registered for the voter, if any
+	sikFails := false   // the SIK lookup fails with an error other than not found
+	proofFails := false // the census proofs cannot be generated
+	var requested []string
+	reply := func(w http.ResponseWriter, v any) {
+		c.Assert(json.NewEncoder(w).Encode(v), qt.IsNil)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /elections/{electionId}", func(w http.ResponseWriter, r *http.Request) {
+		election := &api.Election{
+			Census:   &api.ElectionCensus{CensusRoot: util.RandomBytes(32)},
+			VoteMode: api.VoteMode{EnvelopeType: &models.EnvelopeType{}},
+		}
+		switch r.PathValue("electionId") {
+		case anonymousID.String():
+			election.ElectionID = anonymousID
+			election.VoteMode.Anonymous = true
+		case signedID.String():
+			election.ElectionID = signedID
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		reply(w, election)
+	})
+	mux.HandleFunc("GET /siks/{address}", func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, "sik")
+		switch {
+		case sikFails:
+			http.Error(w, "database locked", http.StatusInternalServerError)
+		case registered == "":
+			http.NotFound(w, r)
+		default:
+			reply(w, &apiclient.SikValidResponse{Sik: registered})
+		}
+	})
+	mux.HandleFunc("GET /censuses/{root}/proof/{key}", func(w http.ResponseWriter, _ *http.Request) {
+		requested = append(requested, "census proof")
+		if proofFails {
+			http.Error(w, "census not found", http.StatusInternalServerError)
+			return
+		}
+		reply(w, &api.Census{CensusProof: util.RandomBytes(32)})
+	})
+	mux.HandleFunc("GET /siks/proof/{address}", func(w http.ResponseWriter, _ *http.Request) {
+		requested = append(requested, "sik proof")
+		reply(w, &api.Census{CensusRoot: util.RandomBytes(32)})
+	})
+	mux.HandleFunc("GET /chain/info", func(w http.ResponseWriter, _ *http.Request) {
+		reply(w, &api.ChainInfo{})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	cli, err := apiclient.New(srv.URL + "/")
+	c.Assert(err, qt.IsNil)
+	var steps []apiclient.AnonymousVoteStep
+	progress := func(step apiclient.AnonymousVoteStep) { steps = append(steps, step) }
+
+	// an account is required, before any step
+	_, err = cli.VoteAnonymous(anonymousID, []int{0}, nil, progress)
+	c.Assert(err, qt.ErrorIs, apiclient.ErrAccountNotConfigured)
+	c.Assert(steps, qt.IsNil)
+	c.Assert(cli.SetAccount(hex.EncodeToString(voter.PrivateKey())), qt.IsNil)
+
+	// only the existing anonymous elections are supported
+	_, err = cli.VoteAnonymous(types.HexBytes(util.RandomBytes(32)), []int{0}, nil, progress)
+	c.Assert(err, qt.ErrorMatches, "(?s)could not get election: .*404.*")
+	_, err = cli.VoteAnonymous(signedID, []int{0}, nil, progress)
+	c.Assert(err, qt.ErrorMatches, ".*is not anonymous")
+	c.Assert(steps, qt.DeepEquals, []apiclient.AnonymousVoteStep{
+		apiclient.AnonymousVoteStepElection, apiclient.AnonymousVoteStepElection,
+	})
+	c.Assert(requested, qt.IsNil)
+
+	// the lookup errors other than not found do not register a new SIK
+	sikFails, steps = true, nil
+	_, err = cli.VoteAnonymous(anonymousID, []int{0}, nil, progress)
+	c.Assert(err, qt.ErrorMatches, "(?s)could not get the registered SIK: .*database locked.*")
+	c.Assert(steps, qt.DeepEquals, []apiclient.AnonymousVoteStep{
+		apiclient.AnonymousVoteStepElection, apiclient.AnonymousVoteStepSIK,
+	})
+	sikFails = false
+
+	// without a registered SIK it is registered, which needs the census proof
+	proofFails, steps, requested = true, nil, nil
+	_, err = cli.VoteAnonymous(anonymousID, []int{0}, nil, progress)
+	c.Assert(err, qt.ErrorMatches, "(?s)could not register SIK: .*census not found.*")
+	c.Assert(steps, qt.DeepEquals, []apiclient.AnonymousVoteStep{
+		apiclient.AnonymousVoteStepElection, apiclient.AnonymousVoteStepSIK, apiclient.AnonymousVoteStepRegisterSIK,
+	})
+	c.Assert(requested, qt.DeepEquals, []string{"sik", "census proof"})
+
+	// the registered SIK must be derived from the secret, and no secret is
+	// not the same as an empty one
+	sik, err := voter.AccountSIK(nil)
+	c.Assert(err, qt.IsNil)
+	registered, steps = hex.EncodeToString(sik), nil
+	_, err = cli.VoteAnonymous(anonymousID, []int{0}, []byte("secret"), progress)
+	c.Assert(err, qt.ErrorIs, apiclient.ErrSIKMismatch)
+	c.Assert(steps, qt.DeepEquals, []apiclient.AnonymousVoteStep{
+		apiclient.AnonymousVoteStepElection, apiclient.AnonymousVoteStepSIK,
+	})
+
+	// with the registered SIK, the proof errors stop the vote
+	steps, requested = nil, nil
+	_, err = cli.VoteAnonymous(anonymousID, []int{0}, nil, progress)
+	c.Assert(err, qt.ErrorMatches, "(?s)could not get census proof: .*census not found.*")
+	c.Assert(requested, qt.DeepEquals, []string{"sik", "census proof"})
+
+	// and with both proofs, the circuit of the chain is loaded, which the
+	// server does not have
+	proofFails, steps, requested = false, nil, nil
+	_, err = cli.VoteAnonymous(anonymousID, []int{0}, nil, progress)
+	c.Assert(err, qt.ErrorMatches, "the API server has no circuit for the anonymous votes")
+	c.Assert(steps, qt.DeepEquals, []apiclient.AnonymousVoteStep{
+		apiclient.AnonymousVoteStepElection, apiclient.AnonymousVoteStepSIK,
+		apiclient.AnonymousVoteStepProofs, apiclient.AnonymousVoteStepCircuit,
+	})
+	c.Assert(requested, qt.DeepEquals, []string{"sik", "census proof", "sik proof"})
+
+	// the progress function is optional
+	_, err = cli.VoteAnonymous(anonymousID, []int{0}, nil, nil)
+	c.Assert(err, qt.ErrorMatches, "the API server has no circuit for the anonymous votes")
+}
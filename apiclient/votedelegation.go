@@ -0,0 +1,103 @@
+package apiclient
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// DelegateVoteWeight delegates the vote weight of the account associated with
+// the client in the election to the delegate, replacing its previous delegation
+// if any. The election must accept delegations and not be started yet. If
+// proof is nil, the census proof of the account is generated by the API.
+// Once delegated, the account cannot vote and its census weight is added to the
+// vote of the delegate. Returns the transaction hash.
+func (c *HTTPclient) DelegateVoteWeight(electionID types.HexBytes, delegate common.Address,
+	proof *CensusProof,
+) (types.HexBytes, error) {
+	if c.account == nil {
+		return nil, ErrAccountNotConfigured
+	}
+	election, err := c.Election(electionID)
+	if err != nil {
+		return nil, err
+	}
+	if !election.VoteDelegation {
+		return nil, fmt.Errorf("the election does not accept vote delegations")
+	}
+	if proof == nil {
+		if proof, err = c.CensusGenProof(election.Census.CensusRoot, c.account.Address().Bytes()); err != nil {
+			return nil, fmt.Errorf("could not generate the census proof: %w", err)
+		}
+	}
+	return c.sendVoteDelegationTx(func(nonce uint32) (*models.SetAccountTx, error) {
+		return vochaintx.NewDelegateVoteWeightTx(nonce, electionID, delegate, &models.Proof{
+			Payload: &models.Proof_Arbo{
+				Arbo: &models.ProofArbo{
+					Type:            models.ProofArbo_BLAKE2B,
+					Siblings:        proof.Proof,
+					AvailableWeight: proof.LeafValue,
+					KeyType:         proof.KeyType,
+				},
+			},
+		})
+	})
+}
+
+// RevokeVoteDelegation revokes the delegation of the vote weight of the account
+// associated with the client in the election, which must not be started yet.
+// Returns the transaction hash.
+func (c *HTTPclient) RevokeVoteDelegation(electionID types.HexBytes) (types.HexBytes, error) {
+	return c.sendVoteDelegationTx(func(nonce uint32) (*models.SetAccountTx, error) {
+		return vochaintx.NewRevokeVoteDelegationTx(nonce, electionID)
+	})
+}
+
+// ElectionVoteDelegations returns all the vote weight delegations of the
+// election, or only the ones to the delegate if it is not nil.
+func (c *HTTPclient) ElectionVoteDelegations(electionID types.HexBytes, delegate *common.Address) (
+	[]*indexertypes.VoteDelegation, error,
+) {
+	params := &ElectionDelegationsParams{}
+	if delegate != nil {
+		params.Delegate = delegate.Hex()
+	}
+	var delegations []*indexertypes.VoteDelegation
+	for {
+		list, err := c.Endpoints().ElectionDelegations(electionID.String(), params)
+		if err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, list.Delegations...)
+		if list.Pagination == nil || list.Pagination.NextPage == nil {
+			return delegations, nil
+		}
+		params.Page = int64(*list.Pagination.NextPage)
+	}
+}
+
+// sendVoteDelegationTx signs and sends the SetAccountTx returned by newTx with
+// the nonce of the account associated with the client.
+func (c *HTTPclient) sendVoteDelegationTx(newTx func(nonce uint32) (*models.SetAccountTx, error)) (types.HexBytes, error) {
+	acc, err := c.Account("")
+	if err != nil {
+		return nil, fmt.Errorf("account not configured: %w", err)
+	}
+	setAccountTx, err := newTx(acc.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := proto.Marshal(&models.Tx{
+		Payload: &models.Tx_SetAccount{SetAccount: setAccountTx},
+	})
+	if err != nil {
+		return nil, err
+	}
+	txHash, _, err := c.SignAndSendTx(tx)
+	return txHash, err
+}
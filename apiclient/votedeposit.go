@@ -0,0 +1,73 @@
+package apiclient
+
+import (
+	"fmt"
+	"strings"
+
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+)
+
+// ErrVoteDepositBalance is returned by CheckVoteDeposit when the account cannot
+// lock the deposit required to vote in the election.
+var ErrVoteDepositBalance = fmt.Errorf("not enough balance for the vote deposit")
+
+// CheckVoteDeposit checks that the account associated with the client can lock
+// the deposit required to vote in the election, if any. The deposit is locked
+// when the first vote of the account is included in a block, and refunded once
+// the results are committed if the vote is counted, or if the election is
+// canceled. The overwrites of a vote do not lock it again, but they are not
+// told apart here, so an account which already voted should not call it.
+func (c *HTTPclient) CheckVoteDeposit(election *api.Election) error {
+	if election.VoteDeposit == 0 {
+		return nil
+	}
+	acc, err := c.Account("")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrVoteDepositBalance, err)
+	}
+	if acc.Balance < election.VoteDeposit {
+		return fmt.Errorf("%w: the deposit is %d tokens, the balance is %d",
+			ErrVoteDepositBalance, election.VoteDeposit, acc.Balance)
+	}
+	return nil
+}
+
+// ElectionVoteDeposits returns the number of tokens locked by the voters of the
+// election, which is the balance of the account of state.ProcessDepositAddress.
+// It fails if no deposit was ever locked, since the account does not exist.
+func (c *HTTPclient) ElectionVoteDeposits(electionID types.HexBytes) (uint64, error) {
+	acc, err := c.Account(state.ProcessDepositAddress(electionID).Hex())
+	if err != nil {
+		return 0, err
+	}
+	return acc.Balance, nil
+}
+
+// ElectionBurnedVoteDeposits returns the number of tokens burned from the vote
+// deposits of the election, which are the deposits of the votes not counted in
+// its results. They are listed by the API as token fees of the deposit account
+// with the txType vochaintx.TxTypeVoteDepositName and the election as reference.
+func (c *HTTPclient) ElectionBurnedVoteDeposits(electionID types.HexBytes) (uint64, error) {
+	params := &ChainFeesListParams{
+		Reference: electionID.String(),
+		Type:      strings.ToLower(vochaintx.TxTypeVoteDepositName),
+		AccountID: state.ProcessDepositAddress(electionID).Hex(),
+	}
+	burned := uint64(0)
+	for {
+		fees, err := c.Endpoints().ChainFeesList(params)
+		if err != nil {
+			return 0, err
+		}
+		for _, fee := range fees.Fees {
+			burned += fee.Cost
+		}
+		if fees.Pagination == nil || fees.Pagination.NextPage == nil {
+			return burned, nil
+		}
+		params.Page = int64(*fees.Pagination.NextPage)
+	}
+}
@@ -0,0 +1,230 @@
+package apiclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// voteLinkVersion is the version of the vote link payload encoding.
+const voteLinkVersion = 1
+
+var (
+	// ErrVoteLinkMalformed is returned when a vote link cannot be decoded.
+	ErrVoteLinkMalformed = fmt.Errorf("malformed vote link")
+	// ErrVoteLinkExpired is returned when a vote link is redeemed after its expiry.
+	ErrVoteLinkExpired = fmt.Errorf("vote link expired")
+	// ErrVoteLinkIssuer is returned by VoteWithLink when the vote link was not
+	// signed by the organization of the election.
+	ErrVoteLinkIssuer = fmt.Errorf("vote link not issued by the election organization")
+)
+
+// VoteLink holds the credentials to vote in an election, to be distributed
+// offline, for example as a QR code or a deep link, and later redeemed by a
+// light client with VoteWithLink. It is encoded by EncodeVoteLink as a compact,
+// signed and URL-safe token.
+type VoteLink struct {
+	// ElectionID is the election to vote in.
+	ElectionID types.HexBytes
+	// Voter, if not zero, is the only account the link can be redeemed by,
+	// which is the one the census proof belongs to.
+	Voter common.Address
+	// Proof is the census proof of the voter, for the elections with a
+	// census tree. Only the fields required to vote are encoded.
+	Proof *CensusProof
+	// ProofCSP is the proof of the voter, for the elections with a CSP census.
+	ProofCSP types.HexBytes
+	// Expiry is the time after which the link cannot be redeemed.
+	Expiry time.Time
+	// Issuer is the address that signed the link, set by DecodeVoteLink.
+	Issuer common.Address
+}
+
+// voteLinkPayload is the JSON encoding of a VoteLink, with short keys to keep
+// the QR codes small.
+type voteLinkPayload struct {
+	Version    int                      `json:"v"`
+	ElectionID []byte                   `json:"e"`
+	Voter      []byte                   `json:"a,omitempty"`
+	Expiry     int64                    `json:"x"`
+	Root       []byte                   `json:"r,omitempty"`
+	Proof      []byte                   `json:"p,omitempty"`
+	LeafValue  []byte                   `json:"l,omitempty"`
+	LeafWeight string                   `json:"w,omitempty"`
+	KeyType    models.ProofArbo_KeyType `json:"k,omitempty"`
+	ProofCSP   []byte                   `json:"c,omitempty"`
+}
+
+// EncodeVoteLink encodes the vote link as a URL-safe token signed by issuer,
+// which must be the organization of the election for VoteWithLink to accept it.
+// The token is the base64url payload and signature, separated by a dot.
+func EncodeVoteLink(link *VoteLink, issuer *ethereum.SignKeys) (string, error) {
+	if len(link.ElectionID) != types.ProcessIDsize {
+		return "", fmt.Errorf("invalid election ID %x", link.ElectionID)
+	}
+	if (link.Proof == nil) == (link.ProofCSP == nil) {
+		return "", fmt.Errorf("either a census proof or a CSP proof is required")
+	}
+	if link.Expiry.IsZero() {
+		return "", fmt.Errorf("expiry is required")
+	}
+	payload := voteLinkPayload{
+		Version:    voteLinkVersion,
+		ElectionID: link.ElectionID,
+		Expiry:     link.Expiry.Unix(),
+		ProofCSP:   link.ProofCSP,
+	}
+	if link.Voter != (common.Address{}) {
+		payload.Voter = link.Voter.Bytes()
+	}
+	if p := link.Proof; p != nil {
+		payload.Root = p.Root
+		payload.Proof = p.Proof
+		payload.LeafValue = p.LeafValue
+		payload.KeyType = p.KeyType
+		if p.LeafWeight != nil {
+			payload.LeafWeight = p.LeafWeight.String()
+		}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	signature, err := issuer.SignEthereum(data)
+	if err != nil {
+		return "", fmt.Errorf("cannot sign vote link: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data) + "." +
+		base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// DecodeVoteLink decodes a token produced by EncodeVoteLink, recovering the
+// Issuer from its signature. It returns ErrVoteLinkMalformed if the token is
+// not valid, and ErrVoteLinkExpired if it expired.
+func DecodeVoteLink(token string) (*VoteLink, error) {
+	encPayload, encSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing signature", ErrVoteLinkMalformed)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrVoteLinkMalformed, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encSignature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrVoteLinkMalformed, err)
+	}
+	issuer, err := ethereum.AddrFromSignature(data, signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrVoteLinkMalformed, err)
+	}
+	var payload voteLinkPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrVoteLinkMalformed, err)
+	}
+	if payload.Version != voteLinkVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrVoteLinkMalformed, payload.Version)
+	}
+	if len(payload.ElectionID) != types.ProcessIDsize {
+		return nil, fmt.Errorf("%w: invalid election ID", ErrVoteLinkMalformed)
+	}
+	link := &VoteLink{
+		ElectionID: payload.ElectionID,
+		Voter:      common.BytesToAddress(payload.Voter),
+		Expiry:     time.Unix(payload.Expiry, 0),
+		Issuer:     issuer,
+	}
+	switch {
+	case payload.ProofCSP != nil:
+		link.ProofCSP = payload.ProofCSP
+	case payload.Proof != nil:
+		link.Proof = &CensusProof{
+			Root:       payload.Root,
+			Proof:      payload.Proof,
+			LeafValue:  payload.LeafValue,
+			LeafWeight: big.NewInt(1),
+			KeyType:    payload.KeyType,
+		}
+		if payload.LeafWeight != "" {
+			if _, ok := link.Proof.LeafWeight.SetString(payload.LeafWeight, 10); !ok {
+				return nil, fmt.Errorf("%w: invalid weight", ErrVoteLinkMalformed)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: missing proof", ErrVoteLinkMalformed)
+	}
+	if time.Now().After(link.Expiry) {
+		return link, ErrVoteLinkExpired
+	}
+	return link, nil
+}
+
+// NewVoteLink returns a vote link for the voter in the election, with the proof
+// of the voter in the census of the election, signed by the account of the
+// client, which must be the organization of the election. Only the elections
+// with a census tree which are not anonymous are supported, since the anonymous
+// votes also require the secret of the voter.
+func (c *HTTPclient) NewVoteLink(electionID types.HexBytes, voter common.Address, expiry time.Time) (string, error) {
+	if c.account == nil {
+		return "", ErrAccountNotConfigured
+	}
+	election, err := c.Election(electionID)
+	if err != nil {
+		return "", err
+	}
+	if election.VoteMode.Anonymous {
+		return "", fmt.Errorf("vote links are not supported by anonymous elections")
+	}
+	proof, err := c.CensusGenProof(election.Census.CensusRoot, voter.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("could not get census proof: %w", err)
+	}
+	return EncodeVoteLink(&VoteLink{
+		ElectionID: electionID,
+		Voter:      voter,
+		Proof:      proof,
+		Expiry:     expiry,
+	}, c.account)
+}
+
+// VoteWithLink decodes and validates a vote link, and votes with its proof
+// using the account of the client. The link must not be expired, it must be
+// signed by the organization of the election, and if it is bound to a voter it
+// must be the account of the client. The return value is the voteID (nullifier).
+func (c *HTTPclient) VoteWithLink(token string, choices []int) (types.HexBytes, error) {
+	link, err := DecodeVoteLink(token)
+	if err != nil {
+		return nil, err
+	}
+	if c.account == nil {
+		return nil, ErrAccountNotConfigured
+	}
+	if link.Voter != (common.Address{}) && link.Voter != c.account.Address() {
+		return nil, fmt.Errorf("vote link is for voter %s, not %s", link.Voter.Hex(), c.account.AddressString())
+	}
+	election, err := c.Election(link.ElectionID)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(election.OrganizationID, link.Issuer.Bytes()) {
+		return nil, fmt.Errorf("%w: signed by %s", ErrVoteLinkIssuer, link.Issuer.Hex())
+	}
+	if election.VoteMode.Anonymous {
+		return nil, fmt.Errorf("vote links are not supported by anonymous elections")
+	}
+	return c.Vote(&VoteData{
+		Choices:     choices,
+		Election:    election,
+		ProofMkTree: link.Proof,
+		ProofCSP:    link.ProofCSP,
+	})
+}
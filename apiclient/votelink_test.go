@@ -0,0 +1,245 @@
+package apiclient_test
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/apiclient/apiclienttest"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// signVoteLink returns the token of a raw vote link payload, signed by issuer,
+// to build the links EncodeVoteLink refuses to.
+func signVoteLink(t *testing.T, payload map[string]any, issuer *ethereum.SignKeys) string {
+	data, err := json.Marshal(payload)
+	qt.Assert(t, err, qt.IsNil)
+	signature, err := issuer.SignEthereum(data)
+	qt.Assert(t, err, qt.IsNil)
+	return base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVoteLinkEncoding(t *testing.T) {
+	c := qt.New(t)
+	issuer := ethereum.NewSignKeys()
+	c.Assert(issuer.Generate(), qt.IsNil)
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	link := &apiclient.VoteLink{
+		ElectionID: util.RandomBytes(types.ProcessIDsize),
+		Voter:      common.BytesToAddress(util.RandomBytes(20)),
+		ProofCSP:   util.RandomBytes(64),
+		Expiry:     expiry.Add(time.Millisecond),
+	}
+	token, err := apiclient.EncodeVoteLink(link, issuer)
+	c.Assert(err, qt.IsNil)
+	c.Assert(strings.Count(token, "."), qt.Equals, 1)
+	c.Assert(token, qt.Equals, strings.TrimRight(token, "="))
+	decoded, err := apiclient.DecodeVoteLink(token)
+	c.Assert(err, qt.IsNil)
+	c.Assert(decoded.ElectionID, qt.DeepEquals, link.ElectionID)
+	c.Assert(decoded.Voter, qt.Equals, link.Voter)
+	c.Assert(decoded.ProofCSP, qt.DeepEquals, link.ProofCSP)
+	c.Assert(decoded.Proof, qt.IsNil)
+	c.Assert(decoded.Issuer, qt.Equals, issuer.Address())
+	// the expiry is kept in seconds
+	c.Assert(decoded.Expiry.Equal(expiry), qt.IsTrue)
+
+	// the census proofs keep the fields required to vote, and the links not
+	// bound to a voter have no voter
+	proof := &apiclient.CensusProof{
+		Root:       util.RandomBytes(32),
+		Proof:      util.RandomBytes(40),
+		LeafValue:  util.RandomBytes(8),
+		LeafWeight: big.NewInt(7),
+		KeyType:    models.ProofArbo_ADDRESS,
+	}
+	token, err = apiclient.EncodeVoteLink(&apiclient.VoteLink{ElectionID: link.ElectionID, Proof: proof, Expiry: expiry}, issuer)
+	c.Assert(err, qt.IsNil)
+	decoded, err = apiclient.DecodeVoteLink(token)
+	c.Assert(err, qt.IsNil)
+	c.Assert(decoded.Voter, qt.Equals, common.Address{})
+	c.Assert(decoded.ProofCSP, qt.IsNil)
+	c.Assert(decoded.Proof.Root, qt.DeepEquals, proof.Root)
+	c.Assert(decoded.Proof.Proof, qt.DeepEquals, proof.Proof)
+	c.Assert(decoded.Proof.LeafValue, qt.DeepEquals, proof.LeafValue)
+	c.Assert(decoded.Proof.LeafWeight.Int64(), qt.Equals, int64(7))
+	c.Assert(decoded.Proof.KeyType, qt.Equals, models.ProofArbo_ADDRESS)
+
+	// the payload cannot be changed without changing the issuer
+	payload, signature, _ := strings.Cut(token, ".")
+	other, err := apiclient.EncodeVoteLink(&apiclient.VoteLink{
+		ElectionID: link.ElectionID, ProofCSP: link.ProofCSP, Expiry: expiry,
+	}, issuer)
+	c.Assert(err, qt.IsNil)
+	otherPayload, _, _ := strings.Cut(other, ".")
+	decoded, err = apiclient.DecodeVoteLink(otherPayload + "." + signature)
+	c.Assert(err, qt.IsNil)
+	c.Assert(decoded.Issuer, qt.Not(qt.Equals), issuer.Address())
+
+	for _, malformed := range []string{payload, payload + ".!", "!." + signature, "e30." + signature, payload + "."} {
+		_, err = apiclient.DecodeVoteLink(malformed)
+		c.Assert(err, qt.ErrorIs, apiclient.ErrVoteLinkMalformed, qt.Commentf("token %q", malformed))
+	}
+
+	// the payloads signed by the issuer are validated too, with the bytes
+	// encoded in base64 as in the payloads of EncodeVoteLink
+	electionID, proofCSP := []byte(link.ElectionID), []byte(link.ProofCSP)
+	for _, tc := range []struct {
+		payload map[string]any
+		err     string
+	}{
+		{map[string]any{"v": 2, "e": electionID, "x": expiry.Unix(), "c": proofCSP}, ".*: unsupported version 2"},
+		{map[string]any{"e": electionID, "x": expiry.Unix(), "c": proofCSP}, ".*: unsupported version 0"},
+		{map[string]any{"v": 1, "e": util.RandomBytes(4), "x": expiry.Unix(), "c": proofCSP}, ".*: invalid election ID"},
+		{map[string]any{"v": 1, "e": electionID, "x": expiry.Unix()}, ".*: missing proof"},
+		{map[string]any{"v": 1, "e": electionID, "x": expiry.Unix(), "p": proof.Proof, "w": "seven"}, ".*: invalid weight"},
+		{map[string]any{"v": "1", "e": electionID, "x": expiry.Unix(), "c": proofCSP}, ".*: json: .*"},
+	} {
+		_, err = apiclient.DecodeVoteLink(signVoteLink(t, tc.payload, issuer))
+		c.Assert(err, qt.ErrorIs, apiclient.ErrVoteLinkMalformed)
+		c.Assert(err, qt.ErrorMatches, tc.err)
+	}
+	// a census proof without weight has weight one
+	decoded, err = apiclient.DecodeVoteLink(signVoteLink(t, map[string]any{
+		"v": 1, "e": electionID, "x": expiry.Unix(), "p": proof.Proof,
+	}, issuer))
+	c.Assert(err, qt.IsNil)
+	c.Assert(decoded.Proof.LeafWeight.Int64(), qt.Equals, int64(1))
+
+	// the expired links are decoded, to tell which election they were for
+	expired := *link
+	expired.Expiry = time.Now().Add(-time.Minute)
+	token, err = apiclient.EncodeVoteLink(&expired, issuer)
+	c.Assert(err, qt.IsNil)
+	decoded, err = apiclient.DecodeVoteLink(token)
+	c.Assert(err, qt.ErrorIs, apiclient.ErrVoteLinkExpired)
+	c.Assert(decoded.ElectionID, qt.DeepEquals, link.ElectionID)
+
+	_, err = apiclient.EncodeVoteLink(&apiclient.VoteLink{ElectionID: link.ElectionID, Expiry: expiry}, issuer)
+	c.Assert(err, qt.ErrorMatches, "either a census proof or a CSP proof is required")
+	_, err = apiclient.EncodeVoteLink(&apiclient.VoteLink{
+		ElectionID: link.ElectionID, Proof: proof, ProofCSP: link.ProofCSP, Expiry: expiry,
+	}, issuer)
+	c.Assert(err, qt.ErrorMatches, "either a census proof or a CSP proof is required")
+	_, err = apiclient.EncodeVoteLink(&apiclient.VoteLink{ElectionID: link.ElectionID, ProofCSP: link.ProofCSP}, issuer)
+	c.Assert(err, qt.ErrorMatches, "expiry is required")
+	_, err = apiclient.EncodeVoteLink(&apiclient.VoteLink{ElectionID: util.RandomBytes(4), ProofCSP: link.ProofCSP, Expiry: expiry}, issuer)
+	c.Assert(err, qt.ErrorMatches, "invalid election ID .*")
+}
+
+func TestVoteWithLink(t *testing.T) {
+	c := qt.New(t)
+	gw := apiclienttest.NewGateway(t)
+	organizer := ethereum.NewSignKeys()
+	c.Assert(organizer.Generate(), qt.IsNil)
+	cli := gw.NewClient(t, hex.EncodeToString(organizer.PrivateKey()))
+	_, err := cli.AccountBootstrap(nil, nil, nil)
+	c.Assert(err, qt.IsNil)
+
+	// an election whose census has the first two voters, but not the third
+	voters := ethereum.NewSignKeysBatch(3)
+	voter := func(i int) *apiclient.HTTPclient {
+		return gw.NewClient(t, hex.EncodeToString(voters[i].PrivateKey()))
+	}
+	censusID, err := cli.NewCensus(api.CensusTypeWeighted)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cli.CensusAddParticipants(censusID, &api.CensusParticipants{Participants: []api.CensusParticipant{
+		{Key: voters[0].Address().Bytes(), Weight: new(types.BigInt).SetUint64(1)},
+		{Key: voters[1].Address().Bytes(), Weight: new(types.BigInt).SetUint64(1)},
+	}}), qt.IsNil)
+	root, uri, err := cli.CensusPublish(censusID)
+	c.Assert(err, qt.IsNil)
+	electionID, err := cli.NewElection(&api.ElectionDescription{
+		Title:   api.LanguageString{"default": "vote links"},
+		EndDate: time.Now().Add(time.Hour),
+		Questions: []api.Question{{
+			Title: api.LanguageString{"default": "question"},
+			Choices: []api.ChoiceMetadata{
+				{Title: api.LanguageString{"default": "yes"}, Value: 0},
+				{Title: api.LanguageString{"default": "no"}, Value: 1},
+			},
+		}},
+		Census: api.CensusTypeDescription{Type: api.CensusTypeWeighted, RootHash: root, URL: uri, Size: 2},
+	}, true)
+	c.Assert(err, qt.IsNil)
+	expiry := time.Now().Add(time.Hour)
+
+	// the links are issued by the organization, to the voters of the census
+	_, err = gw.NewClient(t, "").NewVoteLink(electionID, voters[0].Address(), expiry)
+	c.Assert(err, qt.ErrorIs, apiclient.ErrAccountNotConfigured)
+	_, err = cli.NewVoteLink(electionID, voters[2].Address(), expiry)
+	c.Assert(err, qt.ErrorMatches, "(?s)could not get census proof: .*")
+	_, err = cli.NewVoteLink(util.RandomBytes(types.ProcessIDsize), voters[0].Address(), expiry)
+	c.Assert(err, qt.ErrorMatches, "(?s).*404.*")
+
+	// the link is bound to its voter, and redeemed once
+	token, err := cli.NewVoteLink(electionID, voters[0].Address(), expiry)
+	c.Assert(err, qt.IsNil)
+	height := gw.Height()
+	_, err = voter(1).VoteWithLink(token, []int{1})
+	c.Assert(err, qt.ErrorMatches, "vote link is for voter "+voters[0].Address().Hex()+", not .*")
+	_, err = gw.NewClient(t, "").VoteWithLink(token, []int{1})
+	c.Assert(err, qt.ErrorIs, apiclient.ErrAccountNotConfigured)
+	c.Assert(gw.Height(), qt.Equals, height)
+	voteID, err := voter(0).VoteWithLink(token, []int{1})
+	c.Assert(err, qt.IsNil)
+	receipt, err := voter(0).VerifyMyVote(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(receipt.VoteID, qt.DeepEquals, voteID)
+	c.Assert(receipt.Choices, qt.DeepEquals, []int{1})
+	_, err = voter(0).VoteWithLink(token, []int{0})
+	c.Assert(err, qt.ErrorMatches, "(?s).*overwritten 0 times, the election allows 0.*")
+
+	// a link not bound to a voter can be redeemed by anyone, but its proof is
+	// only valid for the voter it belongs to
+	proof, err := cli.CensusGenProof(root, voters[1].Address().Bytes())
+	c.Assert(err, qt.IsNil)
+	unbound, err := apiclient.EncodeVoteLink(&apiclient.VoteLink{
+		ElectionID: electionID, Proof: proof, Expiry: expiry,
+	}, organizer)
+	c.Assert(err, qt.IsNil)
+	_, err = voter(2).VoteWithLink(unbound, []int{0})
+	c.Assert(err, qt.ErrorMatches, "(?s).*"+voters[2].Address().Hex()+" is not in the census.*")
+
+	// the expired links, and the ones not signed by the organization, are
+	// rejected before voting
+	expired, err := apiclient.EncodeVoteLink(&apiclient.VoteLink{
+		ElectionID: electionID, Proof: proof, Expiry: time.Now().Add(-time.Second),
+	}, organizer)
+	c.Assert(err, qt.IsNil)
+	_, err = voter(1).VoteWithLink(expired, []int{0})
+	c.Assert(err, qt.ErrorIs, apiclient.ErrVoteLinkExpired)
+	impostor := ethereum.NewSignKeys()
+	c.Assert(impostor.Generate(), qt.IsNil)
+	forged, err := apiclient.EncodeVoteLink(&apiclient.VoteLink{
+		ElectionID: electionID, Proof: proof, Expiry: expiry,
+	}, impostor)
+	c.Assert(err, qt.IsNil)
+	_, err = voter(1).VoteWithLink(forged, []int{0})
+	c.Assert(err, qt.ErrorIs, apiclient.ErrVoteLinkIssuer)
+	c.Assert(err, qt.ErrorMatches, ".*: signed by "+impostor.Address().Hex())
+	unknown, err := apiclient.EncodeVoteLink(&apiclient.VoteLink{
+		ElectionID: util.RandomBytes(types.ProcessIDsize), Proof: proof, Expiry: expiry,
+	}, organizer)
+	c.Assert(err, qt.IsNil)
+	_, err = voter(1).VoteWithLink(unknown, []int{0})
+	c.Assert(err, qt.ErrorMatches, "(?s).*404.*")
+
+	// and the voter the proof belongs to votes with it
+	_, err = voter(1).VoteWithLink(unbound, []int{0})
+	c.Assert(err, qt.IsNil)
+	receipt, err = voter(1).VerifyMyVote(electionID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(receipt.Choices, qt.DeepEquals, []int{0})
+}
@@ -27,7 +27,7 @@ import (
 	"go.vocdoni.io/dvote/vochain"
 )
 
-var zkCircuitTest = circuit.CircuitsConfigurations["dev"]
+var zkCircuitTest = circuit.CircuitsConfigurations[circuit.V1_0_0]
 
 // go test -v -run=- -bench=BenchmarkZkCensus -benchmem -count=100 .
 func BenchmarkZkCensus(b *testing.B) {
@@ -1,6 +1,7 @@
 package censustree
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -17,6 +18,10 @@ import (
 
 var censusWeightKey = []byte("censusWeight")
 
+// ErrNotIncremental is returned by DumpDelta if the tree is not an extension of
+// the given one.
+var ErrNotIncremental = errors.New("the census is not an extension of the given root")
+
 // Tree implements the Merkle Tree used for census
 // Concurrent updates to the tree.Tree can lead to losing some of the updates,
 // so we don't expose the tree.Tree directly, and lock it in every method that
@@ -36,6 +41,9 @@ type Options struct {
 	Name       string
 	MaxLevels  int
 	CensusType models.Census_Type
+	// ProofCacheSize is the number of proofs cached by the tree, keyed by
+	// root and key. If it is 0, the proofs are not cached.
+	ProofCacheSize int
 }
 
 // DefaultMaxLevels is by default, the maximum number of levels will be 160, which allows to add
@@ -95,7 +103,12 @@ func New(opts Options) (*Tree, error) {
 	}
 
 	kv := prefixeddb.NewPrefixedDatabase(opts.ParentDB, []byte(opts.Name))
-	t, err := tree.New(nil, tree.Options{DB: kv, MaxLevels: maxLevels, HashFunc: hashFunc})
+	t, err := tree.New(nil, tree.Options{
+		DB:             kv,
+		MaxLevels:      maxLevels,
+		HashFunc:       hashFunc,
+		ProofCacheSize: opts.ProofCacheSize,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -197,11 +210,56 @@ func (t *Tree) Size() (uint64, error) {
 	return t.tree.Size(nil)
 }
 
+// Stats wraps tree.Tree.Stats.
+func (t *Tree) Stats() (*arbo.Stats, error) {
+	return t.tree.Stats(nil)
+}
+
 // Dump wraps t.tree.Dump.
 func (t *Tree) Dump() ([]byte, error) {
 	return t.tree.Dump()
 }
 
+// DumpDelta returns the dump of the leaves of the tree which are not in the
+// tree from, in the format of Dump. If some leaf of from was removed or updated
+// in the tree, ErrNotIncremental is returned.
+func (t *Tree) DumpDelta(from *Tree) ([]byte, error) {
+	fromSize, err := from.Size()
+	if err != nil {
+		return nil, err
+	}
+	var keys, values [][]byte
+	kept := uint64(0)
+	var iterErr error
+	if err := t.tree.IterateLeaves(nil, func(key, value []byte) bool {
+		fromValue, err := from.tree.Get(nil, key)
+		if errors.Is(err, arbo.ErrKeyNotFound) {
+			keys = append(keys, bytes.Clone(key))
+			values = append(values, bytes.Clone(value))
+			return false
+		}
+		if err != nil {
+			iterErr = err
+			return true
+		}
+		if !bytes.Equal(fromValue, value) {
+			iterErr = ErrNotIncremental
+			return true
+		}
+		kept++
+		return false
+	}); err != nil {
+		return nil, err
+	}
+	if iterErr != nil {
+		return nil, iterErr
+	}
+	if kept != fromSize {
+		return nil, ErrNotIncremental
+	}
+	return arbo.DumpKeyValues(keys, values)
+}
+
 // IterateLeaves wraps t.tree.IterateLeaves.
 func (t *Tree) IterateLeaves(callback func(key, value []byte) bool) error {
 	return t.tree.IterateLeaves(nil, callback)
@@ -220,3 +220,62 @@ func TestGetCensusWeight(t *testing.T) {
 	qt.Assert(t, err, qt.IsNil)
 	qt.Assert(t, w.String(), qt.Equals, "11457") // same than in the original tree
 }
+
+func TestDumpDelta(t *testing.T) {
+	db := metadb.NewTest(t)
+	newTree := func(name string) *Tree {
+		tr, err := New(Options{
+			Name: name, ParentDB: db, MaxLevels: DefaultMaxLevels,
+			CensusType: models.Census_ARBO_BLAKE2B,
+		})
+		qt.Assert(t, err, qt.IsNil)
+		return tr
+	}
+	censusTree := newTree("test")
+	rnd := testutil.NewRandom(0)
+	addKeys := func(n int) {
+		for i := 0; i < n; i++ {
+			qt.Assert(t, censusTree.Add(rnd.RandomBytes(DefaultMaxKeyLen),
+				censusTree.BigIntToBytes(big.NewInt(int64(i+1)))), qt.IsNil)
+		}
+	}
+	addKeys(10)
+	fromDump, err := censusTree.Dump()
+	qt.Assert(t, err, qt.IsNil)
+	// the published copy of the tree
+	fromTree := newTree("published")
+	qt.Assert(t, fromTree.ImportDump(fromDump), qt.IsNil)
+	addKeys(5)
+	root, err := censusTree.Root()
+	qt.Assert(t, err, qt.IsNil)
+
+	// the delta only holds the new leaves
+	delta, err := censusTree.DumpDelta(fromTree)
+	qt.Assert(t, err, qt.IsNil)
+	emptyDelta, err := fromTree.DumpDelta(fromTree)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, emptyDelta, qt.HasLen, 0)
+
+	// the previous dump and the delta give the same tree
+	censusTree2 := newTree("test2")
+	qt.Assert(t, censusTree2.ImportDump(append(fromDump, delta...)), qt.IsNil)
+	root2, err := censusTree2.Root()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, root2, qt.DeepEquals, root)
+	size, err := censusTree2.Size()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, size, qt.Equals, uint64(15))
+
+	// a tree with an updated leaf is not an extension
+	var key []byte
+	qt.Assert(t, censusTree.IterateLeaves(func(k, _ []byte) bool {
+		key = k
+		return true
+	}), qt.IsNil)
+	qt.Assert(t, censusTree.tree.Set(nil, key, censusTree.BigIntToBytes(big.NewInt(100))), qt.IsNil)
+	_, err = censusTree.DumpDelta(fromTree)
+	qt.Assert(t, err, qt.ErrorIs, ErrNotIncremental)
+	// neither is a tree with less leaves
+	_, err = fromTree.DumpDelta(censusTree2)
+	qt.Assert(t, err, qt.ErrorIs, ErrNotIncremental)
+}
@@ -0,0 +1,111 @@
+package censustree
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+)
+
+// KeyType is the type of the key a participant is enrolled with in a census.
+type KeyType int
+
+const (
+	// KeyTypeAddress keys are used as provided, usually an Ethereum address
+	// (or a secp256k1 public key on legacy censuses).
+	KeyTypeAddress KeyType = iota
+	// KeyTypeSecp256k1 keys are secp256k1 public keys (compressed or not),
+	// enrolled by their Ethereum address.
+	KeyTypeSecp256k1
+	// KeyTypeEd25519 keys are 32 bytes Ed25519 public keys.
+	KeyTypeEd25519
+	// KeyTypeP256 keys are NIST P-256 (secp256r1) public keys, compressed or
+	// not, such as the ones used by passkeys and WebAuthn authenticators.
+	KeyTypeP256
+)
+
+var keyTypeName = map[KeyType]string{
+	KeyTypeAddress:   "address",
+	KeyTypeSecp256k1: "secp256k1",
+	KeyTypeEd25519:   "ed25519",
+	KeyTypeP256:      "p256",
+}
+
+// String returns the name of the key type.
+func (k KeyType) String() string {
+	if name, ok := keyTypeName[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("KeyType(%d)", int(k))
+}
+
+// KeyTypeFromString returns the key type with the given name. An empty name
+// is KeyTypeAddress.
+func KeyTypeFromString(name string) (KeyType, error) {
+	if name == "" {
+		return KeyTypeAddress, nil
+	}
+	for k, n := range keyTypeName {
+		if n == name {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown key type %q", name)
+}
+
+// ParticipantKey returns the key that identifies the participant with the
+// given key in a census, before being hashed with the tree hash function.
+// Public keys are converted to a 20 bytes address, the same way the Vochain
+// derives the address of a voter (see state.VoterID.Address): secp256k1 keys
+// to their Ethereum address, and Ed25519 and P-256 keys (P-256 in compressed
+// form) to the last 20 bytes of their keccak256 hash.
+func ParticipantKey(keyType KeyType, key []byte) ([]byte, error) {
+	switch keyType {
+	case KeyTypeAddress:
+		return key, nil
+	case KeyTypeSecp256k1:
+		addr, err := ethereum.AddrFromPublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secp256k1 public key: %w", err)
+		}
+		return addr.Bytes(), nil
+	case KeyTypeEd25519:
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 public key length %d, expected %d", len(key), ed25519.PublicKeySize)
+		}
+		return common.BytesToAddress(ethereum.HashRaw(key)).Bytes(), nil
+	case KeyTypeP256:
+		compressed, err := CompressP256PubKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return common.BytesToAddress(ethereum.HashRaw(compressed)).Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", keyType)
+	}
+}
+
+// CompressP256PubKey validates a P-256 public key, compressed (33 bytes) or
+// uncompressed (65 bytes), and returns it in compressed form.
+func CompressP256PubKey(key []byte) ([]byte, error) {
+	switch len(key) {
+	case 33:
+		if x, _ := elliptic.UnmarshalCompressed(elliptic.P256(), key); x == nil {
+			return nil, fmt.Errorf("invalid p256 public key")
+		}
+		return key, nil
+	case 65:
+		if _, err := ecdh.P256().NewPublicKey(key); err != nil {
+			return nil, fmt.Errorf("invalid p256 public key: %w", err)
+		}
+		compressed := make([]byte, 33)
+		compressed[0] = 0x02 | key[64]&1
+		copy(compressed[1:], key[1:33])
+		return compressed, nil
+	default:
+		return nil, fmt.Errorf("invalid p256 public key length %d", len(key))
+	}
+}
@@ -0,0 +1,72 @@
+package censustree
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+)
+
+func TestParticipantKey(t *testing.T) {
+	c := qt.New(t)
+
+	// address keys are used as they are
+	addr := []byte{1, 2, 3}
+	key, err := ParticipantKey(KeyTypeAddress, addr)
+	c.Assert(err, qt.IsNil)
+	c.Assert(key, qt.DeepEquals, addr)
+
+	// secp256k1 keys are enrolled by their ethereum address
+	signKey := &ethereum.SignKeys{}
+	c.Assert(signKey.Generate(), qt.IsNil)
+	key, err = ParticipantKey(KeyTypeSecp256k1, signKey.PublicKey())
+	c.Assert(err, qt.IsNil)
+	c.Assert(key, qt.DeepEquals, signKey.Address().Bytes())
+
+	// ed25519 keys
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	c.Assert(err, qt.IsNil)
+	key, err = ParticipantKey(KeyTypeEd25519, edPub)
+	c.Assert(err, qt.IsNil)
+	c.Assert(key, qt.HasLen, DefaultMaxKeyLen)
+	_, err = ParticipantKey(KeyTypeEd25519, edPub[1:])
+	c.Assert(err, qt.IsNotNil)
+
+	// p256 keys get the same key compressed or not
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, qt.IsNil)
+	compressed := elliptic.MarshalCompressed(elliptic.P256(), p256Key.X, p256Key.Y)
+	uncompressed, err := p256Key.PublicKey.ECDH()
+	c.Assert(err, qt.IsNil)
+	key, err = ParticipantKey(KeyTypeP256, compressed)
+	c.Assert(err, qt.IsNil)
+	c.Assert(key, qt.HasLen, DefaultMaxKeyLen)
+	key2, err := ParticipantKey(KeyTypeP256, uncompressed.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(key2, qt.DeepEquals, key)
+	c.Assert(key, qt.Not(qt.DeepEquals), ethereum.HashRaw(compressed)[:DefaultMaxKeyLen])
+
+	// invalid p256 keys are rejected
+	invalid := append([]byte{}, compressed...)
+	invalid[0] = 0x05
+	_, err = ParticipantKey(KeyTypeP256, invalid)
+	c.Assert(err, qt.IsNotNil)
+	_, err = ParticipantKey(KeyTypeP256, addr)
+	c.Assert(err, qt.IsNotNil)
+
+	// key type names
+	for _, kt := range []KeyType{KeyTypeAddress, KeyTypeSecp256k1, KeyTypeEd25519, KeyTypeP256} {
+		parsed, err := KeyTypeFromString(kt.String())
+		c.Assert(err, qt.IsNil)
+		c.Assert(parsed, qt.Equals, kt)
+	}
+	parsed, err := KeyTypeFromString("")
+	c.Assert(err, qt.IsNil)
+	c.Assert(parsed, qt.Equals, KeyTypeAddress)
+	_, err = KeyTypeFromString("rsa")
+	c.Assert(err, qt.IsNotNil)
+}
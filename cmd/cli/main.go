@@ -46,11 +46,12 @@ func main() {
 	host := flag.String("host", "", "API host endpoint to connect with (such as http://localhost:9090/v2)")
 	logLevel := flag.String("logLevel", "error", "log level")
 	cfgFile := flag.String("config", filepath.Join(home, ".vocdoni-cli.json"), "config file")
+	pinFile := flag.String("pinChain", "", "chain pin file, created on the first run, to refuse to work with another chain")
 	flag.Parse()
 	log.Init(*logLevel, "stdout", nil)
 	log.Infow("starting "+filepath.Base(os.Args[0]), "version", internal.Version)
 
-	cli, err := NewVocdoniCLI(*cfgFile, *host)
+	cli, err := NewVocdoniCLI(*cfgFile, *host, *pinFile)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -63,7 +63,7 @@ type VocdoniCLI struct {
 	currentAccount int
 }
 
-func NewVocdoniCLI(configFile, host string) (*VocdoniCLI, error) {
+func NewVocdoniCLI(configFile, host, pinFile string) (*VocdoniCLI, error) {
 	cfg := Config{}
 	if err := cfg.Load(configFile); err != nil {
 		return nil, err
@@ -87,7 +87,11 @@ func NewVocdoniCLI(configFile, host string) (*VocdoniCLI, error) {
 		return nil, fmt.Errorf("no API server host configured")
 	}
 
-	api, err := apiclient.NewWithBearer(host, cfg.Token)
+	var opts []apiclient.Option
+	if pinFile != "" {
+		opts = append(opts, apiclient.WithPinnedChain(pinFile))
+	}
+	api, err := apiclient.NewWithBearer(host, cfg.Token, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	flag "github.com/spf13/pflag"
+	"go.vocdoni.io/dvote/crypto/zk/circuit"
+	"go.vocdoni.io/dvote/vochain/genesis"
+)
+
+// circuitDownloadTimeout is the default timeout to download all the artifacts.
+const circuitDownloadTimeout = 30 * time.Minute
+
+// circuitCmd implements the circuit command. Its only subcommand, download,
+// stores the artifacts of the zk circuits used by a chain in the node data
+// directory, so they are ready when the first anonymous election arrives.
+func circuitCmd(args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("circuit download", flag.ContinueOnError)
+	dataDir := fs.StringP("dataDir", "d", filepath.Join(home, ".vocdoni"), "directory where data is stored")
+	chain := fs.StringP("chain", "c", "dev",
+		fmt.Sprintf("vocdoni network whose circuits are downloaded: %q", genesis.AvailableNetworks()))
+	all := fs.Bool("all", false, "download all the known circuit versions, not only the one used by the chain")
+	timeout := fs.Duration("timeout", circuitDownloadTimeout, "timeout to download all the artifacts")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s circuit download [flags]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "download" {
+		fs.Usage()
+		return fmt.Errorf("unknown circuit subcommand, expected download")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if !slices.Contains(genesis.AvailableNetworks(), *chain) {
+		return fmt.Errorf("unknown chain %q, available: %q", *chain, genesis.AvailableNetworks())
+	}
+
+	// same location used by the node, see main
+	circuit.BaseDir = filepath.Join(*dataDir, *chain, "zkCircuits")
+	// the vochain loads the default circuit version on every chain
+	configs := []*circuit.Config{circuit.GetCircuitConfiguration(circuit.DefaultZkCircuitVersion)}
+	if *all {
+		configs = configs[:0]
+		for _, version := range slices.Sorted(maps.Keys(circuit.CircuitsConfigurations)) {
+			configs = append(configs, circuit.CircuitsConfigurations[version])
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	fmt.Printf("downloading circuit artifacts into %s\n", circuit.BaseDir)
+	// the last reported percentage of the artifact being downloaded
+	lastPercent := -1
+	return circuit.DownloadAll(ctx, configs, func(p circuit.Progress) {
+		switch {
+		case p.Cached:
+			fmt.Printf("%s %s: already downloaded\n", p.Version, p.Filename)
+		case p.Done:
+			fmt.Printf("%s %s: done (%s)\n", p.Version, p.Filename, formatBytes(p.Downloaded))
+			lastPercent = -1
+		case p.Total > 0:
+			// report every 10%
+			if percent := int(p.Downloaded * 100 / p.Total); lastPercent < 0 || percent >= lastPercent+10 {
+				lastPercent = percent - percent%10
+				fmt.Printf("%s %s: %d%% (%s of %s)\n", p.Version, p.Filename, percent,
+					formatBytes(p.Downloaded), formatBytes(p.Total))
+			}
+		case p.Downloaded == 0:
+			fmt.Printf("%s %s: downloading\n", p.Version, p.Filename)
+		}
+	})
+}
+
+// formatBytes returns a human readable size.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/ethereum/go-ethereum/common"
+	flag "github.com/spf13/pflag"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain/genesis"
+)
+
+// genesisCmd implements the genesis command. Its only subcommand, new, builds
+// the genesis of a new network with genesis.Builder and writes it to a file or
+// the standard output.
+func genesisCmd(args []string) error {
+	fs := flag.NewFlagSet("genesis new", flag.ContinueOnError)
+	chainID := fs.String("chainId", "", "chain ID of the new network")
+	genesisTime := fs.String("genesisTime", "", "genesis time in RFC3339 format (default now)")
+	initialHeight := fs.Int64("initialHeight", 1, "height of the first block")
+	validators := fs.StringArray("validator", nil,
+		"validator with syntax <hexPubKey>:<power>:<name>, may be repeated")
+	keyKeepers := fs.StringArray("keykeeper", nil,
+		"validator which is also a key keeper, with the same syntax as --validator, may be repeated")
+	accounts := fs.StringArray("account", nil, "account with syntax <address>:<balance>, may be repeated")
+	txCostsFile := fs.String("txCosts", "", "JSON file with the transaction costs (default the genesis defaults)")
+	maxElectionSize := fs.Uint64("maxElectionSize", 100000, "maximum census size of the elections")
+	networkCapacity := fs.Uint64("networkCapacity", 10000, "network capacity used to compute the election price")
+	sikValidity := fs.Uint32("sikValidity", 0, "blocks the SIKs stay in the state, 0 if they do not expire")
+	blockTime := fs.Duration("blockTime", 0, "target time between blocks, 0 to use the local configuration of the nodes")
+	emptyBlocksInterval := fs.Duration("emptyBlocksInterval", 0,
+		"time to wait for transactions before proposing an empty block, 0 to always create them")
+	feeMarketTarget := fs.Uint32("feeMarketTargetBlockTxs", 0,
+		"transactions per block from which the costs grow, 0 to disable the fee market")
+	feeMarketMax := fs.Uint32("feeMarketMaxMultiplier", 0, "maximum multiplier of the transaction costs")
+	output := fs.StringP("output", "o", "", "file where the genesis is written (default the standard output)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s genesis new --chainId <id> --validator <validator> [flags]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "new" {
+		fs.Usage()
+		return fmt.Errorf("unknown genesis subcommand, expected new")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	start := time.Now().Truncate(time.Second)
+	if *genesisTime != "" {
+		var err error
+		if start, err = time.Parse(time.RFC3339, *genesisTime); err != nil {
+			return fmt.Errorf("invalid genesis time: %w", err)
+		}
+	}
+	b := genesis.NewBuilder(*chainID, start).
+		InitialHeight(*initialHeight).
+		MaxElectionSize(*maxElectionSize).
+		NetworkCapacity(*networkCapacity).
+		SIKValidity(*sikValidity).
+		BlockTiming(*blockTime, *emptyBlocksInterval).
+		FeeMarket(*feeMarketTarget, *feeMarketMax)
+	for _, v := range *keyKeepers {
+		pubKey, power, name, err := parseGenesisValidator(v)
+		if err != nil {
+			return err
+		}
+		b.AddKeyKeeper(pubKey, power, name)
+	}
+	for _, v := range *validators {
+		pubKey, power, name, err := parseGenesisValidator(v)
+		if err != nil {
+			return err
+		}
+		b.AddValidator(pubKey, power, name)
+	}
+	for _, acc := range *accounts {
+		addr, balance, found := strings.Cut(acc, ":")
+		if !found || !common.IsHexAddress(addr) {
+			return fmt.Errorf("invalid account %q, expected <address>:<balance>", acc)
+		}
+		n, err := strconv.ParseUint(balance, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid balance of account %q: %w", acc, err)
+		}
+		b.AddAccount(common.HexToAddress(addr), n)
+	}
+	if *txCostsFile != "" {
+		data, err := os.ReadFile(*txCostsFile)
+		if err != nil {
+			return err
+		}
+		costs := genesis.TransactionCosts{}
+		if err := json.Unmarshal(data, &costs); err != nil {
+			return fmt.Errorf("cannot decode the transaction costs: %w", err)
+		}
+		b.TxCosts(costs)
+	}
+
+	doc, err := b.Build()
+	if err != nil {
+		return fmt.Errorf("invalid genesis: %w", err)
+	}
+	if *output != "" {
+		return doc.SaveAs(*output)
+	}
+	data, err := cmtjson.MarshalIndent(doc.GenesisDoc, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// parseGenesisValidator parses a validator with syntax <hexPubKey>:<power>:<name>.
+func parseGenesisValidator(v string) (types.HexBytes, uint64, string, error) {
+	parts := strings.SplitN(v, ":", 3)
+	if len(parts) != 3 {
+		return nil, 0, "", fmt.Errorf("invalid validator %q, expected <hexPubKey>:<power>:<name>", v)
+	}
+	pubKey, err := hex.DecodeString(util.TrimHex(parts[0]))
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("invalid public key of validator %q: %w", v, err)
+	}
+	power, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("invalid power of validator %q: %w", v, err)
+	}
+	return types.HexBytes(pubKey), power, parts[2], nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	flag "github.com/spf13/pflag"
+	"go.vocdoni.io/dvote/db"
+)
+
+// importLegacyCmd implements the importlegacy command. It backfills the indexer
+// database of a stopped node with the data of the legacy badger indexer
+// (scrutinizer) used by old versions, so that upgraded nodes keep their
+// historical elections without replaying the chain.
+func importLegacyCmd(args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("importlegacy", flag.ContinueOnError)
+	dataDir := fs.StringP("dataDir", "d", filepath.Join(home, ".vocdoni"), "directory where data is stored")
+	chain := fs.StringP("chain", "c", "dev", "vocdoni network whose data is used")
+	dbType := fs.StringP("dbType", "t", db.TypePebble,
+		fmt.Sprintf("key-value db type [%s,%s,%s]", db.TypePebble, db.TypeLevelDB, db.TypeMongo))
+	legacyDir := fs.String("legacyDir", "",
+		"directory of the legacy indexer database (default <dataDir>/<chain>/vochain/scrutinizer)")
+	encryptionKey := fs.String("vochainIndexerEncryptionKey", "", "key the indexer database is encrypted with, if any")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s importlegacy [flags]\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "The node must be stopped, since its databases are opened.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *legacyDir == "" {
+		*legacyDir = filepath.Join(*dataDir, *chain, "vochain", "scrutinizer")
+	}
+	if _, err := os.Stat(*legacyDir); err != nil {
+		return fmt.Errorf("cannot find the legacy indexer database: %w", err)
+	}
+
+	idx, closeIndexer, err := openIndexer(*dataDir, *chain, *dbType, *encryptionKey)
+	if err != nil {
+		return err
+	}
+	defer closeIndexer()
+
+	summary, err := idx.ImportLegacy(*legacyDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("imported %d processes, %d results and %d votes from %s, skipped %d records\n",
+		summary.Processes, summary.Results, summary.Votes, *legacyDir, summary.Skipped)
+	return nil
+}
@@ -36,6 +36,7 @@ import (
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/vochain"
 	"go.vocdoni.io/dvote/vochain/genesis"
+	"go.vocdoni.io/dvote/vochain/indexer"
 	"go.vocdoni.io/dvote/vochain/keykeeper"
 )
 
@@ -175,6 +176,10 @@ func loadConfig() *config.Config {
 		"override AppHash in genesis for the vochain")
 	flag.Int64("vochainGenesisEndOfChain", 0,
 		"height at which this node will refuse adding new blocks to the chain")
+	flag.StringSlice("vochainGenesisForks", []string{},
+		"comma-separated list of fork heights to apply, as name=height (e.g. processEnd=1234)")
+	flag.String("vochainZkCircuitVersion", "",
+		"zk circuit version to load, the default one if empty (dev requires the zkdevcircuit build tag)")
 	flag.String("vochainLogLevel", "disabled",
 		"tendermint node log level (debug, info, error, disabled)")
 	flag.StringSlice("vochainPeers", []string{},
@@ -206,6 +211,9 @@ func loadConfig() *config.Config {
 
 	flag.Int("vochainMinerTargetBlockTimeSeconds", int(config.DefaultMinerTargetBlockTime.Seconds()),
 		"vochain consensus block time target (in seconds)")
+	flag.Int("vochainMinerEmptyBlocksIntervalSeconds", 0,
+		"time to wait for new transactions before proposing an empty block (in seconds, 0 to always create them),"+
+			" the block timing set on the network takes precedence")
 	flag.Bool("vochainSkipPreviousOffchainData", false,
 		"if enabled the census downloader will import all existing census")
 	flag.Bool("vochainOffChainDataDownload", true,
@@ -215,6 +223,43 @@ func loadConfig() *config.Config {
 			" (syntax <dir>:<numValidators>)")
 	flag.Bool("vochainIndexerDisabled", false,
 		"disables the vochain indexer component")
+	flag.StringSlice("vochainIndexerWebhookURLs", []string{},
+		"comma-separated list of URLs the indexer events are posted to,"+
+			" the placeholders {event} and {processId} are replaced by the event values")
+	flag.String("vochainIndexerWebhookSecret", "",
+		"secret used to sign the webhook payloads with HMAC-SHA256")
+	flag.Uint64("vochainIndexerWebhookMinTransfer", 0,
+		"minimum amount of the token transfers posted to the webhooks (0 to disable)")
+	flag.String("vochainIndexerEncryptionKey", "",
+		"key used to encrypt the indexer database at rest, requires a build linked against SQLCipher"+
+			" (prefer the VOCDONI_VOCHAININDEXERENCRYPTIONKEY env var)")
+	flag.String("vochainIndexerEncryptionNewKey", "",
+		"re-encrypts the indexer database with this key on startup, which must then be used as vochainIndexerEncryptionKey")
+	flag.Bool("vochainIndexerQueryAPI", false,
+		"enables the admin API endpoint to run read-only SQL queries on the indexer database")
+	flag.String("vochainIndexerReplicaDir", "",
+		"directory the replicas of the indexer database are shipped to, for the indexer read replicas (empty to disable)")
+	flag.Uint32("vochainIndexerReplicaInterval", indexer.DefaultReplicaInterval,
+		"number of blocks between two replicas of the indexer database, each shipping the WAL frames committed since the previous one")
+	flag.Bool("vochainIndexerEstimateCounts", false,
+		"estimate the total count of the lists of votes and transactions instead of counting them, for large databases")
+	flag.String("vochainIndexerBackupTo", "",
+		"directory, or s3://bucket/prefix URL, the scheduled backups of the indexer database are saved to (empty to disable),"+
+			" the S3 credentials are read from the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY env vars")
+	flag.String("vochainIndexerBackupS3Endpoint", "https://s3.amazonaws.com",
+		"endpoint of the S3-compatible service of vochainIndexerBackupTo")
+	flag.String("vochainIndexerBackupS3Region", "us-east-1",
+		"region of the bucket of vochainIndexerBackupTo")
+	flag.Duration("vochainIndexerBackupInterval", indexer.DefaultBackupInterval,
+		"time between two scheduled backups of the indexer database")
+	flag.Int("vochainIndexerBackupsKept", indexer.DefaultBackupsKept,
+		"number of scheduled backups of the indexer database kept")
+	flag.Duration("vochainIndexerBackupMaxAge", 0,
+		"age at which the scheduled backups of the indexer database are removed, the latest one is always kept (0 to disable)")
+	flag.Bool("vochainIndexerVotePatterns", false,
+		"detect anomalous voting patterns, such as ballot stuffing, which are logged, counted in the metrics and posted to the webhooks")
+	flag.Float64("vochainIndexerVotePatternMaxVotesPerMinute", indexer.DefaultMaxVotesPerMinute,
+		"rate of votes of a process considered anomalous by vochainIndexerVotePatterns")
 
 	// metrics
 	flag.Bool("metricsEnabled", false, "enable prometheus metrics")
@@ -292,6 +337,23 @@ func loadConfig() *config.Config {
 	// Note that these Config.Vochain fields aren't bound via viper.
 	// We could do that if we rename the flags.
 	conf.Vochain.Indexer.Enabled = !viper.GetBool("vochainIndexerDisabled")
+	conf.Vochain.Indexer.WebhookURLs = viper.GetStringSlice("vochainIndexerWebhookURLs")
+	conf.Vochain.Indexer.WebhookSecret = viper.GetString("vochainIndexerWebhookSecret")
+	conf.Vochain.Indexer.WebhookMinTransfer = viper.GetUint64("vochainIndexerWebhookMinTransfer")
+	conf.Vochain.Indexer.EncryptionKey = viper.GetString("vochainIndexerEncryptionKey")
+	conf.Vochain.Indexer.EncryptionNewKey = viper.GetString("vochainIndexerEncryptionNewKey")
+	conf.Vochain.Indexer.QueryAPI = viper.GetBool("vochainIndexerQueryAPI")
+	conf.Vochain.Indexer.ReplicaDir = viper.GetString("vochainIndexerReplicaDir")
+	conf.Vochain.Indexer.ReplicaInterval = viper.GetUint32("vochainIndexerReplicaInterval")
+	conf.Vochain.Indexer.EstimateCounts = viper.GetBool("vochainIndexerEstimateCounts")
+	conf.Vochain.Indexer.BackupTo = viper.GetString("vochainIndexerBackupTo")
+	conf.Vochain.Indexer.BackupS3Endpoint = viper.GetString("vochainIndexerBackupS3Endpoint")
+	conf.Vochain.Indexer.BackupS3Region = viper.GetString("vochainIndexerBackupS3Region")
+	conf.Vochain.Indexer.BackupInterval = viper.GetDuration("vochainIndexerBackupInterval")
+	conf.Vochain.Indexer.BackupsKept = viper.GetInt("vochainIndexerBackupsKept")
+	conf.Vochain.Indexer.BackupMaxAge = viper.GetDuration("vochainIndexerBackupMaxAge")
+	conf.Vochain.Indexer.VotePatterns = viper.GetBool("vochainIndexerVotePatterns")
+	conf.Vochain.Indexer.VotePatternMaxVotesPerMinute = viper.GetFloat64("vochainIndexerVotePatternMaxVotesPerMinute")
 	conf.Vochain.Network = viper.GetString("chain")
 
 	if conf.SigningKey == "" {
@@ -330,6 +392,60 @@ func main() {
 	// For the sake of including the version in the log, it's also included in a log line later on.
 	fmt.Fprintf(os.Stderr, "vocdoni version %q\n", internal.Version)
 
+	// The txaudit command queries a running node, so it does not need the node config.
+	if len(os.Args) > 1 && os.Args[1] == "txaudit" {
+		if err := txAudit(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The circuit command only downloads the circuit artifacts, so it does not need the node config.
+	if len(os.Args) > 1 && os.Args[1] == "circuit" {
+		if err := circuitCmd(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The recount command only opens the databases of a stopped node, so it does not need the node config.
+	if len(os.Args) > 1 && os.Args[1] == "recount" {
+		if err := recountCmd(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The replay command only opens the block store of a stopped node, so it does not need the node config.
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if err := replayCmd(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The importlegacy command migrates the legacy indexer database of a stopped node.
+	if len(os.Args) > 1 && os.Args[1] == "importlegacy" {
+		if err := importLegacyCmd(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The genesis command only builds a genesis document, so it does not need the node config.
+	if len(os.Args) > 1 && os.Args[1] == "genesis" {
+		if err := genesisCmd(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// creating config and init logger
 	conf := loadConfig()
 
@@ -554,9 +670,15 @@ func main() {
 			urlapi.AccountHandler,
 			urlapi.CensusHandler,
 			urlapi.SIKHandler,
+			urlapi.BatchHandler,
 		); err != nil {
 			log.Fatal(err)
 		}
+		if conf.Vochain.Indexer.QueryAPI {
+			if err := uAPI.EnableHandlers(urlapi.IndexerQueryHandler); err != nil {
+				log.Fatal(err)
+			}
+		}
 		// attach faucet to the API if enabled
 		if conf.EnableFaucetWithAmount > 0 {
 			if err := faucet.AttachFaucetAPI(srv.Signer,
@@ -580,6 +702,7 @@ func main() {
 			log.Fatal(err)
 		}
 		censusDB := censusdb.NewCensusDB(db)
+		censusDB.SetStorage(srv.Storage)
 		uAPI.Attach(
 			nil,
 			nil,
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	flag "github.com/spf13/pflag"
+	"go.vocdoni.io/dvote/db"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain"
+	"go.vocdoni.io/dvote/vochain/indexer"
+	"go.vocdoni.io/dvote/vochain/state"
+)
+
+// recountCmd implements the recount command. It opens the state and the indexer
+// database of a stopped node, recomputes the results of a finalized process from
+// the votes in the state and compares them with the results stored by the
+// indexer. Returns an error if they do not match.
+func recountCmd(args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("recount", flag.ContinueOnError)
+	dataDir := fs.StringP("dataDir", "d", filepath.Join(home, ".vocdoni"), "directory where data is stored")
+	chain := fs.StringP("chain", "c", "dev", "vocdoni network whose data is used")
+	dbType := fs.StringP("dbType", "t", db.TypePebble,
+		fmt.Sprintf("key-value db type [%s,%s,%s]", db.TypePebble, db.TypeLevelDB, db.TypeMongo))
+	processID := fs.String("process", "", "hex ID of the finalized process to recount")
+	encryptionKey := fs.String("vochainIndexerEncryptionKey", "", "key the indexer database is encrypted with, if any")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s recount --process <pid> [flags]\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "The node must be stopped, since its databases are opened.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	pid, err := hex.DecodeString(util.TrimHex(*processID))
+	if err != nil || len(pid) == 0 {
+		fs.Usage()
+		return fmt.Errorf("invalid process ID %q", *processID)
+	}
+
+	if _, err := os.Stat(filepath.Join(*dataDir, *chain, "vochain", "indexer")); err != nil {
+		return fmt.Errorf("cannot find the indexer database: %w", err)
+	}
+	idx, closeIndexer, err := openIndexer(*dataDir, *chain, *dbType, *encryptionKey)
+	if err != nil {
+		return err
+	}
+	defer closeIndexer()
+
+	recount, err := idx.Recount(pid)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("process %s recounted at height %d\n", recount.ProcessID, recount.Height)
+	for q, options := range recount.Votes {
+		fmt.Printf("  question %d: %v\n", q, options)
+	}
+	if len(recount.Discrepancies) == 0 {
+		fmt.Println("  the stored results match")
+		return nil
+	}
+	for _, d := range recount.Discrepancies {
+		fmt.Printf("  mismatch %s\n", d)
+	}
+	return fmt.Errorf("found %d discrepancies with the stored results", len(recount.Discrepancies))
+}
+
+// openIndexer opens the state and the indexer database of a stopped node,
+// creating the latter if needed, in the same locations used by the node, see main and service.VochainIndexer.
+// The returned function closes both.
+func openIndexer(dataDir, chain, dbType, encryptionKey string) (*indexer.Indexer, func(), error) {
+	vochainDir := filepath.Join(dataDir, chain, "vochain")
+	st, err := state.New(dbType, filepath.Join(vochainDir, vochain.StateDataDir))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot open state: %w", err)
+	}
+	idx, err := indexer.New(&vochain.BaseApplication{State: st}, indexer.Options{
+		DataDir:           filepath.Join(vochainDir, "indexer"),
+		IgnoreLiveResults: true,
+		EncryptionKey:     encryptionKey,
+	})
+	if err != nil {
+		st.Close()
+		return nil, nil, fmt.Errorf("cannot open indexer: %w", err)
+	}
+	return idx, func() {
+		idx.Close()
+		st.Close()
+	}, nil
+}
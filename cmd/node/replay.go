@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	flag "github.com/spf13/pflag"
+	"go.vocdoni.io/dvote/config"
+	"go.vocdoni.io/dvote/db"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/vochain"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+)
+
+// replayCmd implements the replay command. It re-executes the blocks in the
+// block store of a stopped node against a fresh state, comparing the app hash
+// and the transaction results of each block with the ones of the chain, and
+// dumps the first block which does not match. Returns an error if any does not.
+func replayCmd(args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	dataDir := fs.StringP("dataDir", "d", filepath.Join(home, ".vocdoni"), "directory where data is stored")
+	chain := fs.StringP("chain", "c", "dev", "vocdoni network whose data is used")
+	dbType := fs.StringP("dbType", "t", db.TypePebble,
+		fmt.Sprintf("key-value db type of the replayed state [%s,%s,%s]", db.TypePebble, db.TypeLevelDB, db.TypeMongo))
+	genesisFile := fs.String("genesis", "", "genesis file of the chain, the one of the node if empty")
+	fromHeight := fs.Uint32("from-height", 1, "first height whose app hash is compared")
+	toHeight := fs.Uint32("to-height", 0, "last height replayed, the last one in the block store if 0")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s replay [--from-height <height>] [--to-height <height>] [flags]\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "The node must be stopped, since its block store is opened.")
+		fmt.Fprintln(os.Stderr, "The state is always built from the genesis, so the blocks before --from-height are executed too.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	vochainDir := filepath.Join(*dataDir, *chain, "vochain")
+	stored, err := vochain.OpenStoredChain(vochainDir, *genesisFile)
+	if err != nil {
+		return err
+	}
+	defer stored.Close()
+	if *toHeight == 0 || *toHeight > stored.Height() {
+		*toHeight = stored.Height()
+	}
+	if *fromHeight > *toHeight {
+		return fmt.Errorf("--from-height %d is after --to-height %d", *fromHeight, *toHeight)
+	}
+
+	replayDir, err := os.MkdirTemp("", "vocdoni-replay")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(replayDir)
+	app, err := vochain.NewBaseApplication(&config.VochainCfg{
+		DBType:  *dbType,
+		DataDir: replayDir,
+	})
+	if err != nil {
+		return err
+	}
+	defer app.State.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	log.Infow("replaying chain", "chainID", stored.Genesis().ChainID, "from", *fromHeight, "to", *toHeight)
+	div, err := app.ReplayChain(ctx, stored, *fromHeight, *toHeight)
+	if err != nil {
+		return err
+	}
+	if div == nil {
+		fmt.Printf("blocks up to height %d replayed, the app hashes from height %d match\n", *toHeight, *fromHeight)
+		return nil
+	}
+
+	fmt.Printf("divergence at height %d\n", div.Height)
+	fmt.Printf("  app hash %x, expected %x\n", div.AppHash, div.ExpectedAppHash)
+	if div.TxIndex < 0 {
+		fmt.Println("  the results of the transactions match or are unknown")
+		return fmt.Errorf("block %d diverges", div.Height)
+	}
+	fmt.Printf("  first divergent transaction %d: %X\n", div.TxIndex, vochaintx.TxKey(div.Tx))
+	vtx := new(vochaintx.Tx)
+	if err := vtx.Unmarshal(div.Tx, stored.Genesis().ChainID); err != nil {
+		fmt.Printf("  cannot decode the transaction: %v\n", err)
+	} else {
+		fmt.Printf("  %s %s\n", vtx.TxModelType, log.FormatProto(vtx.Tx))
+	}
+	fmt.Printf("  result: code %d, data %q, log %q\n", div.Result.Code, div.Result.Data, div.Result.Log)
+	if expected := div.ExpectedResult; expected != nil {
+		fmt.Printf("  expected: code %d, data %q, log %q\n", expected.Code, expected.Data, expected.Log)
+	}
+	return fmt.Errorf("block %d diverges at transaction %d", div.Height, div.TxIndex)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+	"go.vocdoni.io/dvote/apiclient"
+	"go.vocdoni.io/dvote/vochain/txaudit"
+)
+
+// txAuditDefaultTTL is the number of blocks a transaction can stay in the mempool
+// before being pruned. It matches the TTL used by the vochain application.
+const txAuditDefaultTTL = 6 * 10
+
+// Transaction audit verdicts.
+const (
+	txAuditIncluded = "included"
+	txAuditPending  = "pending"
+	txAuditReplayed = "replayed"
+	txAuditDropped  = "dropped"
+)
+
+// txAudit implements the txaudit command. It fetches the mempool history of the given
+// accounts from a gateway and compares it with the indexed transactions, in order to
+// detect replayed transactions (received again after being included in a block) and
+// dropped transactions (never included, either expired or superseded by another
+// transaction with the same nonce). Returns an error if any of them is found.
+func txAudit(args []string) error {
+	fs := flag.NewFlagSet("txaudit", flag.ContinueOnError)
+	host := fs.String("host", "http://localhost:9090/v2", "API endpoint of the gateway to audit")
+	accounts := fs.StringSlice("account", nil, "account address to audit (can be repeated)")
+	ttl := fs.Uint32("ttl", txAuditDefaultTTL, "number of blocks after which a pending transaction is considered dropped")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s txaudit --account <address> [flags]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(*accounts) == 0 {
+		fs.Usage()
+		return fmt.Errorf("at least one account is required")
+	}
+
+	cli, err := apiclient.New(*host)
+	if err != nil {
+		return fmt.Errorf("cannot connect to %s: %w", *host, err)
+	}
+	info, err := cli.ChainInfo()
+	if err != nil {
+		return err
+	}
+
+	issues := 0
+	for _, addr := range *accounts {
+		audit, err := cli.AccountTxAudit(addr)
+		if err != nil {
+			return fmt.Errorf("cannot fetch audit for %s: %w", addr, err)
+		}
+		fmt.Printf("account %s nonce %d\n", audit.Address, audit.Nonce)
+		for _, tx := range audit.Txs {
+			verdict, detail, err := txAuditVerdict(cli, audit, tx, info.Height, *ttl)
+			if err != nil {
+				return err
+			}
+			if verdict == txAuditReplayed || verdict == txAuditDropped {
+				issues++
+			}
+			fmt.Printf("  %-8s hash %s nonce %d type %s seen %d times (heights %d-%d) %s\n",
+				verdict, tx.Hash, tx.Nonce, tx.TxType, tx.SeenCount, tx.FirstSeenHeight, tx.LastSeenHeight, detail)
+		}
+		if len(audit.NonceGaps) > 0 {
+			gaps := make([]string, len(audit.NonceGaps))
+			for i, n := range audit.NonceGaps {
+				gaps[i] = fmt.Sprintf("%d", n)
+			}
+			fmt.Printf("  nonce gaps: %s\n", strings.Join(gaps, ","))
+		}
+	}
+	if issues > 0 {
+		return fmt.Errorf("found %d replayed or dropped transactions", issues)
+	}
+	return nil
+}
+
+// txAuditVerdict compares a transaction seen by the mempool with the indexed transactions.
+func txAuditVerdict(cli *apiclient.HTTPclient, audit *txaudit.AccountAudit, tx *txaudit.TxRecord,
+	height, ttl uint32,
+) (string, string, error) {
+	ref, err := cli.TransactionReference(tx.Hash)
+	if err != nil && !errors.Is(err, apiclient.ErrTransactionDoesNotExist) {
+		return "", "", err
+	}
+	if ref != nil {
+		if tx.LastSeenHeight > ref.Height {
+			return txAuditReplayed, fmt.Sprintf("(received again after inclusion at height %d)", ref.Height), nil
+		}
+		return txAuditIncluded, fmt.Sprintf("(height %d index %d)", ref.Height, ref.Index), nil
+	}
+	if tx.Nonce < audit.Nonce {
+		return txAuditDropped, "(nonce already used by another transaction)", nil
+	}
+	if height > tx.LastSeenHeight+ttl {
+		return txAuditDropped, "(expired from mempool)", nil
+	}
+	return txAuditPending, "", nil
+}
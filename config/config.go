@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"go.vocdoni.io/dvote/types"
 )
 
@@ -90,6 +92,9 @@ type VochainCfg struct {
 	DataDir string
 	// DBType is the type of key-value db to be used
 	DBType string
+	// ZkCircuitVersion is the version of the zk circuit loaded on start, or
+	// the default one if empty (see circuit.CircuitsConfigurations)
+	ZkCircuitVersion string
 	// Genesis path where the genesis file is stored
 	Genesis string
 	// GenesisChainID overrides ChainID in hardcoded genesis
@@ -100,6 +105,8 @@ type VochainCfg struct {
 	GenesisAppHash string
 	// GenesisEndOfChain is the height at which this node will refuse adding new blocks to the chain
 	GenesisEndOfChain int64
+	// GenesisForks overrides the heights of the forks in hardcoded genesis, as name=height
+	GenesisForks []string
 	// Peers peers with which the node tries to connect
 	Peers []string
 	// Seeds seeds with which the node tries to connect
@@ -121,6 +128,9 @@ type VochainCfg struct {
 	TendermintMetrics bool
 	// Target block time in seconds (only for miners)
 	MinerTargetBlockTimeSeconds int
+	// MinerEmptyBlocksIntervalSeconds is the time miners wait for new transactions before
+	// proposing an empty block (0 to always create them)
+	MinerEmptyBlocksIntervalSeconds int
 	// Indexer holds the configuration regarding the indexer component
 	Indexer IndexerCfg
 	// IsSeedNode specifies if the node is configured to act as a seed node
@@ -152,6 +162,41 @@ type IndexerCfg struct {
 	Enabled bool
 	// Disables live results computation on indexer
 	IgnoreLiveResults bool
+	// WebhookURLs are the URL templates the indexer events are posted to (empty to disable)
+	WebhookURLs []string
+	// WebhookSecret is the HMAC-SHA256 key used to sign the webhook payloads
+	WebhookSecret string
+	// WebhookMinTransfer is the minimum amount of the token transfers posted to the webhooks (0 to disable)
+	WebhookMinTransfer uint64
+	// EncryptionKey is the SQLCipher key used to encrypt the indexer database at rest (empty to disable)
+	EncryptionKey string
+	// EncryptionNewKey, if set, is the key the indexer database is re-encrypted with on startup
+	EncryptionNewKey string
+	// QueryAPI enables the admin API endpoint to run read-only SQL queries on the indexer database
+	QueryAPI bool
+	// ReplicaDir is the directory the replicas of the indexer database are shipped to (empty to disable)
+	ReplicaDir string
+	// ReplicaInterval is the number of blocks between two replicas of the indexer database
+	ReplicaInterval uint32
+	// EstimateCounts makes the lists of votes and transactions estimate their total count instead of counting them
+	EstimateCounts bool
+	// BackupTo is the directory, or the s3://bucket/prefix URL, the scheduled backups of the indexer database
+	// are saved to (empty to disable)
+	BackupTo string
+	// BackupS3Endpoint and BackupS3Region are the endpoint and region of the S3-compatible service of BackupTo
+	BackupS3Endpoint string
+	BackupS3Region   string
+	// BackupInterval is the time between two scheduled backups of the indexer database
+	BackupInterval time.Duration
+	// BackupsKept is the number of scheduled backups kept, and BackupMaxAge the age the older ones are removed at
+	// (0 to keep them until BackupsKept is reached)
+	BackupsKept  int
+	BackupMaxAge time.Duration
+	// VotePatterns enables the detection of anomalous voting patterns, such as ballot stuffing, which are logged,
+	// counted in the metrics and posted to the webhooks
+	VotePatterns bool
+	// VotePatternMaxVotesPerMinute is the rate of votes of a process considered anomalous (0 for the default)
+	VotePatternMaxVotesPerMinute float64
 }
 
 // MetricsCfg initializes the metrics config
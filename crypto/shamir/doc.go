@@ -0,0 +1,10 @@
+// Package shamir implements Shamir's secret sharing over GF(2^8), which splits a
+// secret into a number of shares so that any threshold of them recovers it,
+// while fewer reveal nothing about it.
+//
+// It is a verbatim copy of the shamir package of github.com/hashicorp/vault
+// v1.21.4, under its own MPL-2.0 LICENSE, since requiring the vault module
+// would pull in its whole dependency graph. Each share is one byte longer than
+// the secret, since its last byte is the point where the polynomials are
+// evaluated.
+package shamir
@@ -0,0 +1,55 @@
+package shamir
+
+import (
+	"encoding/hex"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// TestKnownAnswers checks the field arithmetic and the recovery of the
+// secrets against vectors computed independently of this package.
+func TestKnownAnswers(t *testing.T) {
+	c := qt.New(t)
+
+	// the multiplications of FIPS-197, with the AES polynomial x^8+x^4+x^3+x+1
+	c.Assert(mult(0x57, 0x83), qt.Equals, uint8(0xc1))
+	c.Assert(mult(0x53, 0xca), qt.Equals, uint8(0x01))
+	c.Assert(inverse(0x53), qt.Equals, uint8(0xca))
+	c.Assert(div(0xc1, 0x83), qt.Equals, uint8(0x57))
+
+	for _, v := range []struct {
+		secret    string
+		threshold int
+		shares    []string
+	}{
+		// evaluated at 1, 2 and 3
+		{"766f63646f6e69", 2, []string{"223a9f5bd6229801", "dec5801a06f69002", "8a907c25bfba6103"}},
+		// evaluated at 0x05, 0x9a, 0xff and 0x10
+		{"00ff10e5a1", 3, []string{"5911fd32a005", "f52598f5c89a", "e90da24f5dff", "a17dffe4a510"}},
+	} {
+		var shares [][]byte
+		for _, s := range v.shares {
+			b, err := hex.DecodeString(s)
+			c.Assert(err, qt.IsNil)
+			shares = append(shares, b)
+		}
+		secret, err := hex.DecodeString(v.secret)
+		c.Assert(err, qt.IsNil)
+
+		// all the shares, and any threshold of them, recover the secret
+		got, err := Combine(shares)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.DeepEquals, secret)
+		got, err = Combine(shares[len(shares)-v.threshold:])
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.DeepEquals, secret)
+
+		// but fewer do not
+		if v.threshold > 2 {
+			got, err = Combine(shares[:v.threshold-1])
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Not(qt.DeepEquals), secret)
+		}
+	}
+}
@@ -106,9 +106,19 @@ func LoadVersion(version string) (*ZkCircuit, error) {
 // First, tries to load the artifacts from local storage, if they are not
 // available, tries to download from their remote location.
 //
+// The artifacts of the embedded circuits, see DevCircuitURI, are read from the
+// binary instead.
+//
 // Stores the loaded circuit in the global variable, and returns it as well
 func LoadConfig(ctx context.Context, config *Config) (*ZkCircuit, error) {
 	circuit := &ZkCircuit{Config: config}
+	if config.IsEmbedded() {
+		if err := circuit.LoadEmbedded(); err != nil {
+			return nil, err
+		}
+		globalCircuit = circuit
+		return circuit, nil
+	}
 	// load the artifacts of the provided circuit from the local storage
 	if err := circuit.LoadLocal(); err == nil {
 		// tries to verify the loaded artifacts, if it success, returns the
@@ -214,6 +224,37 @@ func (circuit *ZkCircuit) LoadRemote(ctx context.Context) error {
 	return nil
 }
 
+// CheckAvailability checks that the circuit artifacts can be loaded, either
+// from the local storage (with the expected hashes) or from their remote
+// location, without downloading them. It returns an error describing the first
+// artifact that is not available.
+func (circuit *ZkCircuit) CheckAvailability(ctx context.Context) error {
+	if circuit.Config.IsEmbedded() {
+		return (&ZkCircuit{Config: circuit.Config}).LoadEmbedded()
+	}
+	local := &ZkCircuit{Config: circuit.Config}
+	if err := local.LoadLocal(); err == nil {
+		if correct, err := local.VerifiedCircuitArtifacts(); err == nil && correct {
+			return nil
+		}
+	}
+	baseUri, err := url.Parse(circuit.Config.URI)
+	if err != nil {
+		return err
+	}
+	remoteUri := baseUri.JoinPath(circuit.Config.CircuitPath)
+	for _, filename := range []string{
+		circuit.Config.ProvingKeyFilename,
+		circuit.Config.VerificationKeyFilename,
+		circuit.Config.WasmFilename,
+	} {
+		if err := checkRemoteFile(ctx, remoteUri.JoinPath(filename).String()); err != nil {
+			return fmt.Errorf("'%s' artifact not available: %w", filename, err)
+		}
+	}
+	return nil
+}
+
 // VerifiedCircuitArtifacts checks that the computed hash of every circuit
 // artifact matches with the expected hash, from the circuit config.
 func (circuit *ZkCircuit) VerifiedCircuitArtifacts() (bool, error) {
@@ -291,6 +332,26 @@ func downloadFile(ctx context.Context, fileUrl string) ([]byte, error) {
 	return content, nil
 }
 
+// checkRemoteFile performs a HEAD request to the URL provided and returns an
+// error if the file cannot be retrieved.
+func checkRemoteFile(ctx context.Context, fileUrl string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, fileUrl, nil)
+	if err != nil {
+		return fmt.Errorf("error creating the file request: %w", err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	if err := res.Body.Close(); err != nil {
+		log.Warnf("error closing body response %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("error on check file %s: http status: %d", fileUrl, res.StatusCode)
+	}
+	return nil
+}
+
 // storeFile helper function allows to write the file content provided into a
 // new file created at the path provided.
 func storeFile(content []byte, dstPath string) error {
@@ -11,6 +11,7 @@ import (
 	"path"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	qt "github.com/frankban/quicktest"
@@ -308,3 +309,83 @@ func Test_storeFile(t *testing.T) {
 	c.Assert(err, qt.IsNotNil)
 	defer os.RemoveAll("./test.txt")
 }
+
+func TestCheckAvailability(t *testing.T) {
+	c := qt.New(t)
+
+	server := testFileServer(testFiles)
+	defer server.Close()
+
+	circuit := &ZkCircuit{
+		Config: &Config{
+			URI:                     server.URL,
+			CircuitPath:             "/test-availability/",
+			ProvingKeyFilename:      testProvingKey,
+			VerificationKeyFilename: testVerificationKey,
+			WasmFilename:            testWasm,
+		},
+	}
+	ctx := context.Background()
+	c.Assert(circuit.CheckAvailability(ctx), qt.IsNil)
+	// nothing is downloaded
+	_, err := os.Stat(filepath.Join(BaseDir, circuit.Config.CircuitPath))
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+
+	// Not found file error
+	newFiles := make(map[string][]byte)
+	for k, v := range testFiles {
+		newFiles[k] = v
+	}
+	delete(newFiles, testWasm)
+	server2 := testFileServer(newFiles)
+	defer server2.Close()
+	circuit.Config.URI = server2.URL
+	c.Assert(circuit.CheckAvailability(ctx), qt.ErrorMatches, fmt.Sprintf("'%s' artifact not available: .*", testWasm))
+
+	// Server closed error
+	server2.Close()
+	c.Assert(circuit.CheckAvailability(ctx), qt.IsNotNil)
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	c := qt.New(t)
+
+	defaultFS := embeddedFS
+	defer func() { embeddedFS = defaultFS }()
+
+	config := &Config{
+		URI:                     DevCircuitURI,
+		ProvingKeyFilename:      testProvingKey,
+		VerificationKeyFilename: testVerificationKey,
+		WasmFilename:            testWasm,
+	}
+	c.Assert(config.IsEmbedded(), qt.IsTrue)
+
+	// Built without the zkdevcircuit tag
+	embeddedFS = nil
+	_, err := LoadConfig(context.Background(), config)
+	c.Assert(err, qt.ErrorMatches, ".*built without the zkdevcircuit tag")
+
+	embeddedFS = fstest.MapFS{
+		"dev/" + testProvingKey:      {Data: testFiles[testProvingKey]},
+		"dev/" + testVerificationKey: {Data: testFiles[testVerificationKey]},
+		"dev/" + testWasm:            {Data: testFiles[testWasm]},
+	}
+	circuit, err := LoadConfig(context.Background(), config)
+	c.Assert(err, qt.IsNil)
+	c.Assert(circuit.ProvingKey, qt.DeepEquals, testFiles[testProvingKey])
+	c.Assert(circuit.VerificationKey, qt.DeepEquals, testFiles[testVerificationKey])
+	c.Assert(circuit.Wasm, qt.DeepEquals, testFiles[testWasm])
+	c.Assert(circuit.CheckAvailability(context.Background()), qt.IsNil)
+
+	// Wrong hash error
+	config.WasmHash = []byte("wrong")
+	_, err = LoadConfig(context.Background(), config)
+	c.Assert(err, qt.ErrorMatches, fmt.Sprintf("hash of the '%s' embedded artifact .*", testWasm))
+
+	// Not found file error
+	config.WasmHash = nil
+	config.URI = EmbeddedScheme + "://other"
+	_, err = LoadConfig(context.Background(), config)
+	c.Assert(err, qt.IsNotNil)
+}
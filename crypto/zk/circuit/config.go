@@ -79,6 +79,9 @@ const DefaultZkCircuitVersion = V1_0_0
 // Version strings
 const (
 	V1_0_0 = "v1.0.0"
+	// VDev is the development circuit, whose artifacts are embedded into the
+	// binary, see DevCircuitURI.
+	VDev = "dev"
 )
 
 // CircuitsConfigurations stores the relation between the different vochain nets
@@ -111,6 +114,42 @@ var CircuitsConfigurations = map[string]*Config{
 			"censusRoot":    7,
 		},
 	},
+	// The dev circuit is the small census circuit of the prover tests, which
+	// has neither the SIK root nor the vote weight as public signals, so it
+	// is only meant to run the dev chains and the tests without downloading
+	// the artifacts of v1.0.0, which is still needed to generate and verify
+	// the proofs of the anonymous votes (see SupportsAnonymousVotes).
+	VDev: {
+		Version:                 VDev,
+		URI:                     DevCircuitURI,
+		Levels:                  10, // ZkCircuit number of levels
+		ProvingKeyHash:          hexToBytes("0xee020cdc4ea2b3fe138a7f87596361cd3c12e40a42c528d36bd5edef35d2e2f8"),
+		ProvingKeyFilename:      "census_proving_key.zkey",
+		VerificationKeyHash:     hexToBytes("0xc65110462852bacd917cccef86bd75dc7bd1becae74b4b07c09f05852e40d15d"),
+		VerificationKeyFilename: "census_verification_key.json",
+		WasmHash:                hexToBytes("0xd6e173db1db0af3cbd5782a69be0332a8af910754b86cfa7284c6088b9742597"),
+		WasmFilename:            "census.wasm",
+		PublicSignals: map[string]int{
+			"electionId[0]": 0,
+			"electionId[1]": 1,
+			"censusRoot":    2,
+			"nullifier":     3,
+			"voteHash[0]":   4,
+			"voteHash[1]":   5,
+		},
+	},
+}
+
+// SupportsAnonymousVotes returns if the proofs of the circuit include the
+// public signals required to verify the anonymous votes, which are the vote
+// weight and the SIK root besides the ones of any census proof.
+func (conf *Config) SupportsAnonymousVotes() bool {
+	for _, signal := range []string{"voteWeight", "sikRoot"} {
+		if _, ok := conf.PublicSignals[signal]; !ok {
+			return false
+		}
+	}
+	return true
 }
 
 // GetCircuitConfiguration returns the circuit configuration associated with the
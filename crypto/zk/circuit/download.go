@@ -0,0 +1,151 @@
+package circuit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"go.vocdoni.io/dvote/log"
+)
+
+// Progress reports the state of the download of a circuit artifact, see
+// DownloadAll.
+type Progress struct {
+	// Version is the version of the circuit and Filename the artifact name.
+	Version  string
+	Filename string
+	// Downloaded is the number of bytes downloaded so far, and Total the size of
+	// the artifact, or -1 if the server does not report it.
+	Downloaded int64
+	Total      int64
+	// Cached is true if the artifact was already stored locally with the
+	// expected hash, so it is not downloaded again.
+	Cached bool
+	// Done is true once the artifact is stored and verified.
+	Done bool
+}
+
+// DownloadAll downloads the artifacts of the circuits provided into BaseDir,
+// skipping the ones already stored with the expected hash, so that they can be
+// loaded later on without downloading them. The optional progress function is
+// called as the artifacts are downloaded. The artifacts are only stored once
+// their hash is verified. The embedded circuits, see DevCircuitURI, are skipped.
+func DownloadAll(ctx context.Context, configs []*Config, progress func(Progress)) error {
+	if progress == nil {
+		progress = func(Progress) {}
+	}
+	for _, config := range configs {
+		if config.IsEmbedded() {
+			continue
+		}
+		baseUri, err := url.Parse(config.URI)
+		if err != nil {
+			return fmt.Errorf("invalid URI of circuit %s: %w", config.Version, err)
+		}
+		remoteUri := baseUri.JoinPath(config.CircuitPath)
+		localPath := filepath.Join(BaseDir, config.CircuitPath)
+		if err := os.MkdirAll(localPath, os.ModePerm); err != nil {
+			return err
+		}
+		for _, artifact := range []struct {
+			filename string
+			hash     []byte
+		}{
+			{config.ProvingKeyFilename, config.ProvingKeyHash},
+			{config.VerificationKeyFilename, config.VerificationKeyHash},
+			{config.WasmFilename, config.WasmHash},
+		} {
+			p := Progress{Version: config.Version, Filename: artifact.filename, Total: -1}
+			dstPath := filepath.Join(localPath, artifact.filename)
+			if content, err := os.ReadFile(dstPath); err == nil {
+				if ok, err := checkHash(content, artifact.hash); err == nil && ok {
+					p.Downloaded, p.Total = int64(len(content)), int64(len(content))
+					p.Cached, p.Done = true, true
+					progress(p)
+					continue
+				}
+			}
+			log.Debugw("downloading circuit artifact", "version", config.Version, "filename", artifact.filename)
+			if err := downloadArtifact(ctx, remoteUri.JoinPath(artifact.filename).String(),
+				dstPath, artifact.hash, p, progress); err != nil {
+				return fmt.Errorf("error downloading '%s' artifact of circuit %s: %w",
+					artifact.filename, config.Version, err)
+			}
+		}
+	}
+	return nil
+}
+
+// downloadArtifact streams the file at fileUrl into dstPath, reporting the
+// progress, and only replaces dstPath once the content matches the expected hash.
+func downloadArtifact(ctx context.Context, fileUrl, dstPath string, expectedHash []byte,
+	p Progress, progress func(Progress),
+) error {
+	if expectedHash == nil {
+		return fmt.Errorf("no hash provided to compare")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileUrl, nil)
+	if err != nil {
+		return fmt.Errorf("error creating the file request: %w", err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			log.Warnf("error closing body response %v", err)
+		}
+	}()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("error on download file %s: http status: %d", fileUrl, res.StatusCode)
+	}
+	p.Total = res.ContentLength
+	progress(p)
+
+	tmp, err := os.CreateTemp(filepath.Dir(dstPath), filepath.Base(dstPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating the artifact file: %w", err)
+	}
+	defer func() {
+		// the temporary file no longer exists if it was renamed
+		_ = os.Remove(tmp.Name())
+	}()
+	hash := sha256.New()
+	counter := &progressWriter{progress: p, fn: progress}
+	if _, err := io.Copy(io.MultiWriter(tmp, hash, counter), res.Body); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing the artifact file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing the artifact file: %w", err)
+	}
+	if !bytes.Equal(hash.Sum(nil), expectedHash) {
+		return fmt.Errorf("hash of the downloaded artifact does not match the expected one")
+	}
+	if err := os.Rename(tmp.Name(), dstPath); err != nil {
+		return fmt.Errorf("error storing the artifact file: %w", err)
+	}
+	p = counter.progress
+	p.Done = true
+	progress(p)
+	return nil
+}
+
+// progressWriter counts the bytes written and reports them as progress.
+type progressWriter struct {
+	progress Progress
+	fn       func(Progress)
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	w.progress.Downloaded += int64(len(b))
+	w.fn(w.progress)
+	return len(b), nil
+}
@@ -0,0 +1,74 @@
+package circuit
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDownloadAll(t *testing.T) {
+	c := qt.New(t)
+
+	server := testFileServer(testFiles)
+	defer server.Close()
+
+	hash := func(content []byte) []byte {
+		h := sha256.Sum256(content)
+		return h[:]
+	}
+	config := &Config{
+		Version:                 "test",
+		URI:                     server.URL,
+		CircuitPath:             "/test-download/",
+		ProvingKeyFilename:      testProvingKey,
+		ProvingKeyHash:          hash(testFiles[testProvingKey]),
+		VerificationKeyFilename: testVerificationKey,
+		VerificationKeyHash:     hash(testFiles[testVerificationKey]),
+		WasmFilename:            testWasm,
+		WasmHash:                hash(testFiles[testWasm]),
+	}
+	testCircuits := filepath.Join(BaseDir, config.CircuitPath)
+	defer os.RemoveAll(testCircuits)
+
+	done := map[string]Progress{}
+	err := DownloadAll(context.Background(), []*Config{config}, func(p Progress) {
+		c.Assert(p.Version, qt.Equals, config.Version)
+		if p.Done {
+			done[p.Filename] = p
+		}
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(done, qt.HasLen, 3)
+	for filename, content := range testFiles {
+		c.Assert(done[filename].Cached, qt.IsFalse)
+		c.Assert(done[filename].Downloaded, qt.Equals, int64(len(content)))
+		c.Assert(done[filename].Total, qt.Equals, int64(len(content)))
+		local, err := os.ReadFile(filepath.Join(testCircuits, filename))
+		c.Assert(err, qt.IsNil)
+		c.Assert(local, qt.DeepEquals, content)
+	}
+
+	// the artifacts already stored are not downloaded again
+	clear(done)
+	err = DownloadAll(context.Background(), []*Config{config}, func(p Progress) {
+		c.Assert(p.Cached, qt.IsTrue)
+		done[p.Filename] = p
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(done, qt.HasLen, 3)
+
+	// an artifact with an unexpected hash is not stored
+	c.Assert(os.Remove(filepath.Join(testCircuits, testWasm)), qt.IsNil)
+	config.WasmHash = hash([]byte("other_content"))
+	err = DownloadAll(context.Background(), []*Config{config}, nil)
+	c.Assert(err, qt.ErrorMatches, ".*hash of the downloaded artifact does not match.*")
+	_, err = os.Stat(filepath.Join(testCircuits, testWasm))
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+	entries, err := os.ReadDir(testCircuits)
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.HasLen, 2)
+}
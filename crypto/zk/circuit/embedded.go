@@ -0,0 +1,83 @@
+package circuit
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+)
+
+// EmbeddedScheme is the URI scheme of the circuits whose artifacts are embedded
+// into the binary, such as DevCircuitURI, instead of downloaded.
+const EmbeddedScheme = "embedded"
+
+// DevCircuitURI is the URI of the development circuit, whose artifacts are only
+// embedded into the binaries built with the zkdevcircuit tag:
+//
+//	go build -tags zkdevcircuit ./cmd/node
+//
+// It allows the dev chains and the CI to generate and verify the zk proofs
+// without depending on the artifact servers.
+const DevCircuitURI = EmbeddedScheme + "://dev"
+
+// embeddedFS contains the embedded circuit artifacts, under a directory named
+// after the host of their URI. It is nil if the binary is built without the
+// zkdevcircuit tag.
+var embeddedFS fs.FS
+
+// DevCircuitAvailable returns true if the binary is built with the
+// zkdevcircuit tag, so the artifacts of the dev circuit are embedded into it.
+func DevCircuitAvailable() bool {
+	return embeddedFS != nil
+}
+
+// IsEmbedded returns true if the artifacts of the circuit are embedded into the
+// binary, see DevCircuitURI.
+func (conf *Config) IsEmbedded() bool {
+	u, err := url.Parse(conf.URI)
+	return err == nil && u.Scheme == EmbeddedScheme
+}
+
+// LoadEmbedded reads the circuit artifacts embedded into the binary. Since they
+// are part of the binary, their hashes are only checked if the circuit config
+// defines them.
+func (circuit *ZkCircuit) LoadEmbedded() error {
+	u, err := url.Parse(circuit.Config.URI)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != EmbeddedScheme {
+		return fmt.Errorf("circuit URI %q is not embedded", circuit.Config.URI)
+	}
+	if embeddedFS == nil {
+		return fmt.Errorf("circuit %q is not available, the binary is built without the zkdevcircuit tag",
+			circuit.Config.URI)
+	}
+	files := map[string][]byte{
+		circuit.Config.ProvingKeyFilename:      nil,
+		circuit.Config.VerificationKeyFilename: nil,
+		circuit.Config.WasmFilename:            nil,
+	}
+	for filename := range files {
+		files[filename], err = fs.ReadFile(embeddedFS, path.Join(u.Host, u.Path, filename))
+		if err != nil {
+			return fmt.Errorf("error reading '%s' embedded artifact: %w", filename, err)
+		}
+	}
+	for filename, hash := range map[string][]byte{
+		circuit.Config.ProvingKeyFilename:      circuit.Config.ProvingKeyHash,
+		circuit.Config.VerificationKeyFilename: circuit.Config.VerificationKeyHash,
+		circuit.Config.WasmFilename:            circuit.Config.WasmHash,
+	} {
+		if hash == nil {
+			continue
+		}
+		if ok, err := checkHash(files[filename], hash); err != nil || !ok {
+			return fmt.Errorf("hash of the '%s' embedded artifact does not match the expected one", filename)
+		}
+	}
+	circuit.ProvingKey = files[circuit.Config.ProvingKeyFilename]
+	circuit.VerificationKey = files[circuit.Config.VerificationKeyFilename]
+	circuit.Wasm = files[circuit.Config.WasmFilename]
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build zkdevcircuit
+
+package circuit
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// devArtifacts contains the artifacts of the development circuit, see
+// embedded/dev/README.md.
+//
+//go:embed embedded/dev
+var devArtifacts embed.FS
+
+func init() {
+	sub, err := fs.Sub(devArtifacts, "embedded")
+	if err != nil {
+		panic(err)
+	}
+	embeddedFS = sub
+}
@@ -0,0 +1,35 @@
+//go:build zkdevcircuit
+
+package prover
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/crypto/zk/circuit"
+)
+
+func TestDevCircuit(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(circuit.DevCircuitAvailable(), qt.IsTrue)
+	dev, err := circuit.LoadVersion(circuit.VDev)
+	c.Assert(err, qt.IsNil)
+
+	proof, err := Prove(dev.ProvingKey, dev.Wasm, inputs)
+	c.Assert(err, qt.IsNil)
+	c.Assert(proof.Verify(dev.VerificationKey), qt.IsNil)
+
+	// the public signals are extracted as defined by the dev configuration
+	var expected []string
+	c.Assert(json.Unmarshal(pubSignals, &expected), qt.IsNil)
+	c.Assert(proof.PubSignals, qt.DeepEquals, expected)
+	c.Assert(dev.Config.PublicSignals, qt.HasLen, len(expected))
+	c.Assert(expected[dev.Config.PublicSignals["censusRoot"]], qt.Equals, "10880001835876045062663123179921953501658252221549048512484733871519188473352")
+	c.Assert(expected[dev.Config.PublicSignals["nullifier"]], qt.Equals, "4295509861249984880361571032347194270863089509149412623993065795982837479793")
+
+	// the proof has no SIK root nor vote weight, so it cannot be an anonymous vote
+	c.Assert(dev.Config.Levels, qt.Equals, 10)
+	c.Assert(dev.Config.SupportsAnonymousVotes(), qt.IsFalse)
+	c.Assert(circuit.GetCircuitConfiguration(circuit.V1_0_0).SupportsAnonymousVotes(), qt.IsTrue)
+}
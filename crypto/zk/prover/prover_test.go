@@ -10,15 +10,15 @@ import (
 )
 
 var (
-	wasm        = getExampleFile("./test_files/circuit.wasm")
+	wasm        = getExampleFile("../circuit/embedded/dev/census.wasm")
 	wasm2       = getExampleFile("./test_files/circuit2.wasm")
 	inputs      = getExampleFile("./test_files/inputs.json")
 	inputs2     = getExampleFile("./test_files/inputs2.json")
-	zkey        = getExampleFile("./test_files/proving_key.zkey")
+	zkey        = getExampleFile("../circuit/embedded/dev/census_proving_key.zkey")
 	zkey2       = getExampleFile("./test_files/proving_key2.zkey")
 	pubSignals  = getExampleFile("./test_files/public_signals.json")
 	pubSignals2 = getExampleFile("./test_files/public_signals2.json")
-	vkey        = getExampleFile("./test_files/verification_key.json")
+	vkey        = getExampleFile("../circuit/embedded/dev/census_verification_key.json")
 	vkey2       = getExampleFile("./test_files/verification_key2.json")
 )
 
@@ -33,4 +33,7 @@ var (
 	ErrNotFound = fmt.Errorf("storage file not found")
 	// ErrTimeout is returned when the storage context times out.
 	ErrTimeout = fmt.Errorf("storage context timeout")
+	// ErrFileTooLarge is returned when the file is larger than the maximum
+	// size requested, which is known before the file content is fetched.
+	ErrFileTooLarge = fmt.Errorf("storage file is too large")
 )
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"maps"
+	"strings"
 	"sync"
 	"time"
 
@@ -37,8 +38,8 @@ func (d *DataMockTest) PublishReader(_ context.Context, r io.Reader) (string, er
 }
 
 func (d *DataMockTest) Publish(_ context.Context, o []byte) (string, error) {
-	d.filesMu.RLock()
-	defer d.filesMu.RUnlock()
+	d.filesMu.Lock()
+	defer d.filesMu.Unlock()
 	cid := ipfs.CalculateCIDv1json(o)
 	d.files[cid] = string(o)
 	return d.prefix + cid, nil
@@ -47,6 +48,8 @@ func (d *DataMockTest) Publish(_ context.Context, o []byte) (string, error) {
 func (d *DataMockTest) Retrieve(_ context.Context, id string, _ int64) ([]byte, error) {
 	d.filesMu.RLock()
 	defer d.filesMu.RUnlock()
+	// the files are published without the prefix, as the paths of the IPFS handler
+	id = strings.TrimPrefix(strings.TrimPrefix(id, d.prefix), "/ipfs/")
 	if data, ok := d.files[id]; ok {
 		return []byte(data), nil
 	}
@@ -292,7 +292,7 @@ func (i *Handler) RetrieveDir(ctx context.Context, path string, maxSize int64) (
 	if dir := files.ToDir(f); dir != nil {
 		if err := files.Walk(dir, func(path string, node files.Node) error {
 			if file := files.ToFile(node); file != nil {
-				content, err := fetchFileContent(file)
+				content, err := fetchFileContent(file, maxSize)
 				if err != nil {
 					log.Warnw("could not retrieve file from directory", "path", path, "error", err)
 					return nil
@@ -309,11 +309,15 @@ func (i *Handler) RetrieveDir(ctx context.Context, path string, maxSize int64) (
 
 // Retrieve gets an IPFS file (either from the p2p network or from the local cache).
 // If maxSize is 0, it is set to the hardcoded maximum of MaxFileSizeBytes.
+// If the file is larger, data.ErrFileTooLarge is returned.
 func (i *Handler) Retrieve(ctx context.Context, path string, maxSize int64) ([]byte, error) {
 	path = sanitizePath(path)
 	// check if we have the file in the local cache
 	ccontent, _ := i.retrieveCache.Get(path)
 	if ccontent != nil {
+		if maxSize > 0 && int64(len(ccontent)) > maxSize {
+			return nil, fmt.Errorf("%w: %d bytes", data.ErrFileTooLarge, len(ccontent))
+		}
 		return ccontent, nil
 	}
 
@@ -338,7 +342,7 @@ func (i *Handler) Retrieve(ctx context.Context, path string, maxSize int64) ([]b
 		return nil, data.ErrNotFound
 	}
 
-	content, err := fetchFileContent(f)
+	content, err := fetchFileContent(f, maxSize)
 	if err != nil {
 		return nil, err
 	}
@@ -362,22 +366,28 @@ func (i *Handler) Retrieve(ctx context.Context, path string, maxSize int64) ([]b
 	return content, nil
 }
 
-func fetchFileContent(node files.Node) ([]byte, error) {
+// fetchFileContent reads the content of the file node. If the file is larger
+// than maxSize, or MaxFileSizeBytes if maxSize is 0, it returns
+// data.ErrFileTooLarge without reading it.
+func fetchFileContent(node files.Node, maxSize int64) ([]byte, error) {
 	file := files.ToFile(node)
 	if file == nil {
 		return nil, fmt.Errorf("object is not a file")
 	}
 	defer file.Close()
 
+	if maxSize <= 0 || maxSize > MaxFileSizeBytes {
+		maxSize = MaxFileSizeBytes
+	}
 	fsize, err := file.Size()
 	if err != nil {
 		return nil, err
 	}
 
-	if fsize > MaxFileSizeBytes {
-		return nil, fmt.Errorf("file too big: %d", fsize)
+	if fsize > maxSize {
+		return nil, fmt.Errorf("%w: %d bytes", data.ErrFileTooLarge, fsize)
 	}
-	return io.ReadAll(io.LimitReader(file, MaxFileSizeBytes))
+	return io.ReadAll(io.LimitReader(file, maxSize))
 }
 
 // PublishIPNSpath creates or updates an IPNS record with the content of a
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"errors"
+)
+
+const (
+	// DefaultBatchMaxBytes is the default size of the keys and values written
+	// by a BatchWriteTx before its pending writes are committed.
+	DefaultBatchMaxBytes = 32 << 20 // 32 MiB
+	// DefaultBatchMaxCount is the default number of writes of a BatchWriteTx
+	// before its pending writes are committed.
+	DefaultBatchMaxCount = 100_000
+)
+
+// BatchWriteTx is a WriteTx for bulk writes, such as importing a census or
+// rebuilding the state, which commits the pending writes into the Database
+// once they exceed a size or a number of writes, and continues with a new
+// WriteTx transparently. The pending writes are also committed when the
+// underlying WriteTx returns ErrTxnTooBig, retrying the write in the new one.
+//
+// Since the writes are committed in several batches, a BatchWriteTx is not
+// atomic: Discard only discards the writes since the last automatic commit.
+// Reads see all the writes, the committed and the pending ones.
+type BatchWriteTx struct {
+	database Database
+	tx       WriteTx
+	maxBytes int
+	maxCount int
+	bytes    int
+	count    int
+	commits  int
+}
+
+// check that BatchWriteTx implements the WriteTx interface
+var _ WriteTx = (*BatchWriteTx)(nil)
+
+// NewBatchWriteTx returns a BatchWriteTx writing into database, which commits
+// its pending writes once their keys and values exceed maxBytes, or once they
+// are more than maxCount writes. A zero or negative maxBytes or maxCount uses
+// DefaultBatchMaxBytes or DefaultBatchMaxCount respectively.
+func NewBatchWriteTx(database Database, maxBytes, maxCount int) *BatchWriteTx {
+	if maxBytes <= 0 {
+		maxBytes = DefaultBatchMaxBytes
+	}
+	if maxCount <= 0 {
+		maxCount = DefaultBatchMaxCount
+	}
+	return &BatchWriteTx{
+		database: database,
+		tx:       database.WriteTx(),
+		maxBytes: maxBytes,
+		maxCount: maxCount,
+	}
+}
+
+// Get implements the WriteTx.Get interface method
+func (tx *BatchWriteTx) Get(key []byte) ([]byte, error) {
+	return tx.tx.Get(key)
+}
+
+// Iterate implements the WriteTx.Iterate interface method
+func (tx *BatchWriteTx) Iterate(prefix []byte, callback func(key, value []byte) bool) error {
+	return tx.tx.Iterate(prefix, callback)
+}
+
+// Set implements the WriteTx.Set interface method
+func (tx *BatchWriteTx) Set(key, value []byte) error {
+	return tx.write(len(key)+len(value), func() error {
+		return tx.tx.Set(key, value)
+	})
+}
+
+// Delete implements the WriteTx.Delete interface method
+func (tx *BatchWriteTx) Delete(key []byte) error {
+	return tx.write(len(key), func() error {
+		return tx.tx.Delete(key)
+	})
+}
+
+// Apply implements the WriteTx.Apply interface method. The writes of other are
+// not counted in the size of the pending writes, but the thresholds are
+// checked afterwards.
+func (tx *BatchWriteTx) Apply(other WriteTx) error {
+	return tx.write(0, func() error {
+		return tx.tx.Apply(other)
+	})
+}
+
+// Commit implements the WriteTx.Commit interface method, committing the
+// pending writes.
+func (tx *BatchWriteTx) Commit() error {
+	return tx.tx.Commit()
+}
+
+// Discard implements the WriteTx.Discard interface method, discarding the
+// pending writes. The writes already committed are kept.
+func (tx *BatchWriteTx) Discard() {
+	tx.tx.Discard()
+}
+
+// Unwrap returns the current WriteTx, which holds the pending writes.
+func (tx *BatchWriteTx) Unwrap() WriteTx {
+	return tx.tx
+}
+
+// Commits returns the number of times the pending writes have been committed
+// automatically.
+func (tx *BatchWriteTx) Commits() int {
+	return tx.commits
+}
+
+// write runs fn, which writes size bytes into the current WriteTx. If it
+// returns ErrTxnTooBig, the pending writes are committed and fn is retried.
+// Then, if the thresholds are exceeded, the pending writes are committed.
+func (tx *BatchWriteTx) write(size int, fn func() error) error {
+	err := fn()
+	if errors.Is(err, ErrTxnTooBig) && tx.count > 0 {
+		if err := tx.flush(); err != nil {
+			return err
+		}
+		err = fn()
+	}
+	if err != nil {
+		return err
+	}
+	tx.bytes += size
+	tx.count++
+	if tx.bytes >= tx.maxBytes || tx.count >= tx.maxCount {
+		return tx.flush()
+	}
+	return nil
+}
+
+// flush commits the pending writes and continues with a new WriteTx.
+func (tx *BatchWriteTx) flush() error {
+	if err := tx.tx.Commit(); err != nil {
+		return err
+	}
+	tx.tx = tx.database.WriteTx()
+	tx.bytes, tx.count = 0, 0
+	tx.commits++
+	return nil
+}
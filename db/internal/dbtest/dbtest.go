@@ -181,3 +181,84 @@ func TestWriteTxApplyPrefixed(t *testing.T, d, dbWithPrefix db.Database) {
 	err = wTx.Apply(wTxWithPrefix)
 	qt.Assert(t, err, qt.IsNil)
 }
+
+func TestBatchWriteTx(t *testing.T, d db.Database) {
+	key := func(i int) []byte { return []byte("batch" + strconv.Itoa(i)) }
+
+	// commit every 10 writes
+	wTx := db.NewBatchWriteTx(d, 0, 10)
+	defer wTx.Discard()
+	for i := 0; i < 25; i++ {
+		qt.Assert(t, wTx.Set(key(i), key(i)), qt.IsNil)
+		// the writes are readable from the tx, committed or not
+		v, err := wTx.Get(key(i))
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, v, qt.DeepEquals, key(i))
+	}
+	qt.Assert(t, wTx.Commits(), qt.Equals, 2)
+	// the first 20 writes are committed, the rest are pending
+	_, err := d.Get(key(19))
+	qt.Assert(t, err, qt.IsNil)
+	_, err = d.Get(key(20))
+	qt.Assert(t, err, qt.Equals, db.ErrKeyNotFound)
+	qt.Assert(t, wTx.Delete(key(0)), qt.IsNil)
+	qt.Assert(t, wTx.Commit(), qt.IsNil)
+	_, err = d.Get(key(0))
+	qt.Assert(t, err, qt.Equals, db.ErrKeyNotFound)
+	v, err := d.Get(key(24))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, v, qt.DeepEquals, key(24))
+
+	// commit once the keys and values exceed 100 bytes
+	wTx = db.NewBatchWriteTx(d, 100, 0)
+	for i := 0; i < 10; i++ {
+		qt.Assert(t, wTx.Set(key(i), make([]byte, 40)), qt.IsNil)
+	}
+	// 46 bytes per write, so committed every 3 writes
+	qt.Assert(t, wTx.Commits(), qt.Equals, 3)
+	// discarding only drops the last write
+	wTx.Discard()
+	v, err = d.Get(key(8))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, v, qt.DeepEquals, make([]byte, 40))
+	v, err = d.Get(key(9))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, v, qt.DeepEquals, key(9))
+
+	// commit when the underlying tx is too big, retrying the write
+	wTx = db.NewBatchWriteTx(&limitedDatabase{Database: d, limit: 3}, 0, 0)
+	for i := 0; i < 10; i++ {
+		qt.Assert(t, wTx.Set(key(i), []byte("limited")), qt.IsNil)
+	}
+	qt.Assert(t, wTx.Commit(), qt.IsNil)
+	qt.Assert(t, wTx.Commits(), qt.Equals, 3)
+	for i := 0; i < 10; i++ {
+		v, err := d.Get(key(i))
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, v, qt.DeepEquals, []byte("limited"))
+	}
+}
+
+// limitedDatabase is a db.Database whose write transactions return
+// db.ErrTxnTooBig after limit writes.
+type limitedDatabase struct {
+	db.Database
+	limit int
+}
+
+func (d *limitedDatabase) WriteTx() db.WriteTx {
+	return &limitedWriteTx{WriteTx: d.Database.WriteTx(), limit: d.limit}
+}
+
+type limitedWriteTx struct {
+	db.WriteTx
+	limit int
+}
+
+func (tx *limitedWriteTx) Set(key, value []byte) error {
+	if tx.limit == 0 {
+		return db.ErrTxnTooBig
+	}
+	tx.limit--
+	return tx.WriteTx.Set(key, value)
+}
@@ -1,6 +1,7 @@
 package apirest
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"go.vocdoni.io/dvote/httprouter"
 	"go.vocdoni.io/dvote/log"
 )
@@ -35,6 +37,7 @@ const (
 const (
 	HTTPstatusOK                 = http.StatusOK
 	HTTPstatusNoContent          = http.StatusNoContent
+	HTTPstatusNotModified        = http.StatusNotModified
 	HTTPstatusBadRequest         = http.StatusBadRequest
 	HTTPstatusInternalErr        = http.StatusInternalServerError
 	HTTPstatusNotFound           = http.StatusNotFound
@@ -262,6 +265,17 @@ func (a *API) RegisterMethod(pattern, HTTPmethod string, accessType string, hand
 	return nil
 }
 
+// Serve dispatches req through the router as if it had been received by the
+// HTTP server, so it goes through the same middlewares and authorization. The
+// URL path of req is relative to the base route of the API.
+func (a *API) Serve(w http.ResponseWriter, req *http.Request) {
+	req.URL.Path = path.Join(a.basePath, req.URL.Path)
+	// req may inherit the routing context of the request being served, which
+	// would route it with the method and path of that one
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, nil))
+	a.router.Mux.ServeHTTP(w, req)
+}
+
 // SetAdminToken sets the bearer admin token capable to execute admin handlers
 func (a *API) SetAdminToken(bearerToken string) {
 	a.adminToken.Store(&bearerToken)
@@ -1,7 +1,9 @@
 package apirest
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -48,6 +50,25 @@ func TestRouterWithAPI(t *testing.T) {
 			return ctx.Send([]byte(fmt.Sprintf("hello %s!", ctx.URLParam("name"))), 200)
 		})
 
+	// Add a public handler replying JSON
+	stdAPI.RegisterMethod("/json", "GET", MethodAccessTypePublic,
+		func(msg *APIdata, ctx *httprouter.HTTPContext) error {
+			return ctx.Send([]byte(`{"hello":"json","count":3}`), 200)
+		})
+
+	// Add a public handler streaming many lines
+	stdAPI.RegisterMethod("/stream", "GET", MethodAccessTypePublic,
+		func(msg *APIdata, ctx *httprouter.HTTPContext) error {
+			return ctx.Stream(httprouter.ContentTypeNDJSON, func(_ context.Context, w io.Writer) error {
+				for i := range 10000 {
+					if _, err := fmt.Fprintf(w, "{\"line\":%d}\n", i); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+
 	// Set the bearer admin token
 	stdAPI.SetAdminToken("abcd")
 
@@ -88,6 +109,40 @@ func TestRouterWithAPI(t *testing.T) {
 	qt.Check(t, resp, qt.DeepEquals, []byte("hello admin!\n"))
 	resp = doRequest(t, url+"/admin/do", "abcde", "POST", []byte("hello"))
 	qt.Check(t, string(resp), qt.Contains, "admin token not valid\n")
+
+	// Test the JSON responses are sent in CBOR if preferred
+	for accept, contentType := range map[string]string{
+		"":                 httprouter.DefaultContentType,
+		"application/json": httprouter.DefaultContentType,
+		"application/cbor, application/json;q=0.9": httprouter.ContentTypeCBOR,
+	} {
+		req, err := http.NewRequest("GET", url+"/json", nil)
+		qt.Assert(t, err, qt.IsNil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		httpResp, err := http.DefaultClient.Do(req)
+		qt.Assert(t, err, qt.IsNil)
+		body, err := io.ReadAll(httpResp.Body)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, httpResp.Header.Get("Content-Type"), qt.Equals, contentType)
+		qt.Check(t, httpResp.Header.Values("Vary"), qt.Contains, "Accept")
+		if contentType == httprouter.ContentTypeCBOR {
+			body, err = httprouter.CBORToJSON(body)
+			qt.Assert(t, err, qt.IsNil)
+		}
+		qt.Check(t, string(bytes.TrimSpace(body)), qt.Equals, `{"hello":"json","count":3}`)
+	}
+
+	// Test the streamed responses, longer than a chunk
+	httpResp, err := http.Get(url + "/stream")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, httpResp.Header.Get("Content-Type"), qt.Equals, httprouter.ContentTypeNDJSON)
+	lines := 0
+	for scanner := bufio.NewScanner(httpResp.Body); scanner.Scan(); lines++ {
+		qt.Check(t, scanner.Text(), qt.Equals, fmt.Sprintf(`{"line":%d}`, lines))
+	}
+	qt.Check(t, lines, qt.Equals, 10000)
 }
 
 func doRequest(t *testing.T, url, authToken, method string, body []byte) []byte {
@@ -0,0 +1,448 @@
+package httprouter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// ContentTypeCBOR is the content type of the responses encoded in CBOR (RFC
+// 8949), sent instead of DefaultContentType to the clients which prefer it in
+// their Accept header.
+const ContentTypeCBOR = "application/cbor"
+
+// maxCBORDepth is the maximum nesting of the arrays and maps decoded by
+// CBORToJSON, like the one of encoding/json.
+const maxCBORDepth = 10000
+
+// CBOR major types.
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+
+	cborIndefinite = 31
+	cborBreak      = 0xff
+
+	cborTagPosBignum = 2
+	cborTagNegBignum = 3
+)
+
+// AcceptsCBOR returns whether the Accept header of a request prefers CBOR over
+// JSON, that is, whether the quality of ContentTypeCBOR is higher than the one
+// of DefaultContentType. Without Accept header, JSON is preferred.
+func AcceptsCBOR(accept string) bool {
+	cborQ, cborRank := 0.0, -1
+	jsonQ, jsonRank := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		// the quality of each content type is the one of its most specific
+		// media range
+		if r := mediaRangeRank(mediaType, ContentTypeCBOR); r > cborRank {
+			cborQ, cborRank = q, r
+		}
+		if r := mediaRangeRank(mediaType, DefaultContentType); r > jsonRank {
+			jsonQ, jsonRank = q, r
+		}
+	}
+	return cborQ > jsonQ
+}
+
+// mediaRangeRank returns how specific the media range of an Accept header is
+// for the content type: 2 if it is the content type, 1 for application/*, 0
+// for */* and -1 if it does not match.
+func mediaRangeRank(mediaRange, contentType string) int {
+	switch mediaRange {
+	case contentType:
+		return 2
+	case "application/*":
+		return 1
+	case "*/*":
+		return 0
+	default:
+		return -1
+	}
+}
+
+// JSONToCBOR encodes the JSON document in CBOR, keeping its data model: the
+// objects are encoded as maps with text keys in the same order, the integers
+// as integers (or bignums if they do not fit in 64 bits), the other numbers as
+// double precision floats, and the strings as text, so CBORToJSON returns an
+// equivalent document. The arrays and maps have indefinite length, so the
+// document is encoded in a single pass.
+func JSONToCBOR(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	out := make([]byte, 0, len(data)/2)
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch v := tok.(type) {
+		case json.Delim:
+			switch v {
+			case '[':
+				out = append(out, cborArray<<5|cborIndefinite)
+				depth++
+			case '{':
+				out = append(out, cborMap<<5|cborIndefinite)
+				depth++
+			default:
+				out = append(out, cborBreak)
+				depth--
+			}
+		case bool:
+			if v {
+				out = append(out, cborSimple<<5|21)
+			} else {
+				out = append(out, cborSimple<<5|20)
+			}
+		case nil:
+			out = append(out, cborSimple<<5|22)
+		case string:
+			out = appendCBORHead(out, cborText, uint64(len(v)))
+			out = append(out, v...)
+		case json.Number:
+			if out, err = appendCBORNumber(out, v); err != nil {
+				return nil, err
+			}
+		}
+		if depth == 0 && dec.More() {
+			return nil, fmt.Errorf("more than one JSON value")
+		}
+	}
+	return out, nil
+}
+
+// appendCBORHead appends the head of a data item of the major type with the
+// argument n.
+func appendCBORHead(out []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(out, major<<5|byte(n))
+	case n <= math.MaxUint8:
+		return append(out, major<<5|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(out, major<<5|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(out, major<<5|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(out, major<<5|27), n)
+	}
+}
+
+// appendCBORNumber appends the JSON number, as an integer if it has no fraction
+// nor exponent, or as a float otherwise.
+func appendCBORNumber(out []byte, n json.Number) ([]byte, error) {
+	s := n.String()
+	if strings.ContainsAny(s, ".eE") {
+		f, err := n.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return binary.BigEndian.AppendUint64(append(out, cborSimple<<5|27), math.Float64bits(f)), nil
+	}
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %s", s)
+	}
+	major, tag := byte(cborUint), uint64(cborTagPosBignum)
+	if i.Sign() < 0 {
+		// a negative integer n is encoded as -1-n
+		major, tag = cborNegInt, cborTagNegBignum
+		i.Not(i)
+	}
+	if i.IsUint64() {
+		return appendCBORHead(out, major, i.Uint64()), nil
+	}
+	b := i.Bytes()
+	out = appendCBORHead(out, cborTag, tag)
+	out = appendCBORHead(out, cborBytes, uint64(len(b)))
+	return append(out, b...), nil
+}
+
+// CBORToJSON decodes a CBOR data item into JSON. Besides the items encoded by
+// JSONToCBOR, it accepts definite length arrays and maps, half and single
+// precision floats, and byte strings, which are encoded in base64 like
+// encoding/json does. The keys of the maps must be text, and the tags other
+// than the bignums are ignored.
+func CBORToJSON(data []byte) ([]byte, error) {
+	d := &cborDecoder{data: data}
+	var out bytes.Buffer
+	out.Grow(len(data) * 2)
+	if err := d.decode(&out, 0); err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, fmt.Errorf("%d trailing bytes after the CBOR item", len(data)-d.pos)
+	}
+	return out.Bytes(), nil
+}
+
+// cborDecoder reads the CBOR items of data, from pos on.
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// head reads the head of the next item, returning its major type, its
+// additional information and its argument.
+func (d *cborDecoder) head() (major, info byte, arg uint64, err error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, 0, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.pos]
+	d.pos++
+	major, info = b>>5, b&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		size := 1 << (info - 24)
+		if d.pos+size > len(d.data) {
+			return 0, 0, 0, io.ErrUnexpectedEOF
+		}
+		for _, b := range d.data[d.pos : d.pos+size] {
+			arg = arg<<8 | uint64(b)
+		}
+		d.pos += size
+		return major, info, arg, nil
+	case info == cborIndefinite && major != cborUint && major != cborNegInt && major != cborTag:
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, fmt.Errorf("invalid additional information %d of major type %d", info, major)
+	}
+}
+
+// bytes reads n bytes of a string.
+func (d *cborDecoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// atBreak returns whether the next byte ends an indefinite length item, and
+// consumes it if so.
+func (d *cborDecoder) atBreak() (bool, error) {
+	if d.pos >= len(d.data) {
+		return false, io.ErrUnexpectedEOF
+	}
+	if d.data[d.pos] == cborBreak {
+		d.pos++
+		return true, nil
+	}
+	return false, nil
+}
+
+// str reads a byte or text string of the major type, concatenating the chunks
+// of the indefinite length ones.
+func (d *cborDecoder) str(major, info byte, n uint64) ([]byte, error) {
+	if info != cborIndefinite {
+		return d.bytes(n)
+	}
+	var s []byte
+	for {
+		end, err := d.atBreak()
+		if err != nil {
+			return nil, err
+		}
+		if end {
+			return s, nil
+		}
+		chunkMajor, chunkInfo, chunkLen, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == cborIndefinite {
+			return nil, fmt.Errorf("invalid chunk of major type %d in a string of major type %d", chunkMajor, major)
+		}
+		chunk, err := d.bytes(chunkLen)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, chunk...)
+	}
+}
+
+// decode reads the next item and writes it to out as JSON.
+func (d *cborDecoder) decode(out *bytes.Buffer, depth int) error {
+	if depth > maxCBORDepth {
+		return fmt.Errorf("exceeded max depth of %d", maxCBORDepth)
+	}
+	major, info, arg, err := d.head()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case cborUint:
+		out.WriteString(strconv.FormatUint(arg, 10))
+	case cborNegInt:
+		n := new(big.Int).SetUint64(arg)
+		out.WriteString(n.Not(n).String())
+	case cborBytes:
+		b, err := d.str(major, info, arg)
+		if err != nil {
+			return err
+		}
+		out.WriteByte('"')
+		out.WriteString(base64.StdEncoding.EncodeToString(b))
+		out.WriteByte('"')
+	case cborText:
+		s, err := d.str(major, info, arg)
+		if err != nil {
+			return err
+		}
+		quoted, err := json.Marshal(string(s))
+		if err != nil {
+			return err
+		}
+		out.Write(quoted)
+	case cborArray, cborMap:
+		return d.decodeContainer(out, depth, major, info, arg)
+	case cborTag:
+		if arg != cborTagPosBignum && arg != cborTagNegBignum {
+			return d.decode(out, depth+1)
+		}
+		bMajor, bInfo, bLen, err := d.head()
+		if err != nil {
+			return err
+		}
+		if bMajor != cborBytes {
+			return fmt.Errorf("invalid bignum of major type %d", bMajor)
+		}
+		b, err := d.str(bMajor, bInfo, bLen)
+		if err != nil {
+			return err
+		}
+		n := new(big.Int).SetBytes(b)
+		if arg == cborTagNegBignum {
+			n.Not(n)
+		}
+		out.WriteString(n.String())
+	case cborSimple:
+		return d.decodeSimple(out, info, arg)
+	}
+	return nil
+}
+
+// decodeContainer reads the elements of an array, or the pairs of a map, of
+// length n unless it is indefinite.
+func (d *cborDecoder) decodeContainer(out *bytes.Buffer, depth int, major, info byte, n uint64) error {
+	open, closing := byte('['), byte(']')
+	if major == cborMap {
+		open, closing = '{', '}'
+	}
+	out.WriteByte(open)
+	for i := uint64(0); info == cborIndefinite || i < n; i++ {
+		if info == cborIndefinite {
+			end, err := d.atBreak()
+			if err != nil {
+				return err
+			}
+			if end {
+				break
+			}
+		}
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		if major == cborMap {
+			if d.pos < len(d.data) && d.data[d.pos]>>5 != cborText {
+				return fmt.Errorf("invalid map key of major type %d", d.data[d.pos]>>5)
+			}
+			if err := d.decode(out, depth+1); err != nil {
+				return err
+			}
+			out.WriteByte(':')
+		}
+		if err := d.decode(out, depth+1); err != nil {
+			return err
+		}
+	}
+	out.WriteByte(closing)
+	return nil
+}
+
+// decodeSimple writes the simple value or float with the additional
+// information and argument.
+func (d *cborDecoder) decodeSimple(out *bytes.Buffer, info byte, arg uint64) error {
+	var f float64
+	switch info {
+	case 20:
+		out.WriteString("false")
+		return nil
+	case 21:
+		out.WriteString("true")
+		return nil
+	case 22, 23: // null and undefined
+		out.WriteString("null")
+		return nil
+	case 25:
+		f = float16ToFloat64(uint16(arg))
+	case 26:
+		f = float64(math.Float32frombits(uint32(arg)))
+	case 27:
+		f = math.Float64frombits(arg)
+	default:
+		return fmt.Errorf("unsupported simple value %d", arg)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("unsupported float %v", f)
+	}
+	out.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+	return nil
+}
+
+// float16ToFloat64 decodes a half precision float.
+func float16ToFloat64(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
@@ -0,0 +1,103 @@
+package httprouter
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestAcceptsCBOR(t *testing.T) {
+	for accept, want := range map[string]bool{
+		"":                 false,
+		"*/*":              false,
+		"application/json": false,
+		"application/cbor": true,
+		"application/cbor, application/json;q=0.9": true,
+		"application/json, application/cbor;q=0.9": false,
+		"application/cbor;q=0.5, */*;q=0.1":        true,
+		"application/cbor;q=0.5, application/*":    false,
+		"application/cbor;q=invalid":               false,
+	} {
+		qt.Check(t, AcceptsCBOR(accept), qt.Equals, want, qt.Commentf("accept %q", accept))
+	}
+}
+
+func TestJSONToCBOR(t *testing.T) {
+	c := qt.New(t)
+	// the examples of RFC 8949, appendix A
+	for doc, want := range map[string]string{
+		`0`:                     "00",
+		`23`:                    "17",
+		`1000000`:               "1a000f4240",
+		`18446744073709551615`:  "1bffffffffffffffff",
+		`18446744073709551616`:  "c249010000000000000000",
+		`-18446744073709551617`: "c349010000000000000000",
+		`-1000`:                 "3903e7",
+		`1.1`:                   "fb3ff199999999999a",
+		`true`:                  "f5",
+		`null`:                  "f6",
+		`"ü"`:                   "62c3bc",
+		`[1,[2,3]]`:             "9f019f0203ffff",
+		`{"a":1,"b":[2,3]}`:     "bf61610161629f0203ffff",
+	} {
+		b, err := JSONToCBOR([]byte(doc))
+		c.Assert(err, qt.IsNil)
+		c.Assert(hex.EncodeToString(b), qt.Equals, want, qt.Commentf("json %s", doc))
+		back, err := CBORToJSON(b)
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(back), qt.Equals, doc)
+	}
+
+	// an API response keeps its content and the order of its keys
+	doc := `{"votes":[{"voteID":"0a1b","blockHeight":12,"date":"2024-01-02T03:04:05Z",` +
+		`"weight":"1000000000000000000000"}],"pagination":{"totalItems":1,"previousPage":null,"ratio":0.25}}`
+	b, err := JSONToCBOR([]byte(doc))
+	c.Assert(err, qt.IsNil)
+	c.Assert(len(b) < len(doc), qt.IsTrue)
+	back, err := CBORToJSON(b)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(back), qt.Equals, doc)
+
+	_, err = JSONToCBOR([]byte(`{"a":1} {}`))
+	c.Assert(err, qt.ErrorMatches, "more than one JSON value")
+	_, err = JSONToCBOR([]byte(`hello`))
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
+func TestCBORToJSON(t *testing.T) {
+	c := qt.New(t)
+	// the items not encoded by JSONToCBOR, from RFC 8949, appendix A
+	for item, want := range map[string]any{
+		"f93c00":                     1.0,
+		"f97bff":                     65504.0,
+		"fa47c35000":                 100000.0,
+		"4401020304":                 []byte{1, 2, 3, 4},
+		"7f657374726561646d696e67ff": "streaming",
+		"83010203":                   []any{1.0, 2.0, 3.0},
+		"a201020304":                 nil, // non-text keys
+		"a26161016162820203":         map[string]any{"a": 1.0, "b": []any{2.0, 3.0}},
+		"c074323031332d30332d32315432303a30343a30305a": "2013-03-21T20:04:00Z",
+		"f7": nil,
+	} {
+		b, err := hex.DecodeString(item)
+		c.Assert(err, qt.IsNil)
+		doc, err := CBORToJSON(b)
+		if item == "a201020304" {
+			c.Assert(err, qt.ErrorMatches, "invalid map key.*")
+			continue
+		}
+		c.Assert(err, qt.IsNil, qt.Commentf("cbor %s", item))
+		wantJSON, err := json.Marshal(want)
+		c.Assert(err, qt.IsNil)
+		c.Assert(doc, qt.JSONEquals, json.RawMessage(wantJSON), qt.Commentf("cbor %s", item))
+	}
+
+	for _, item := range []string{"", "1a000f", "9f01", "f97c00", "0000", "1f"} {
+		b, err := hex.DecodeString(item)
+		c.Assert(err, qt.IsNil)
+		_, err = CBORToJSON(b)
+		c.Assert(err, qt.Not(qt.IsNil), qt.Commentf("cbor %s", item))
+	}
+}
@@ -3,6 +3,7 @@ package httprouter
 import (
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
@@ -17,6 +18,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/klauspost/compress/zstd"
 	reuse "github.com/libp2p/go-reuseport"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
@@ -30,6 +32,10 @@ const (
 	desiredSoMaxConn = 4096
 	// DefaultContentType is the default content type for the HTTP response.
 	DefaultContentType = "application/json"
+	// compressionLevel is the gzip and deflate compression level of the responses.
+	compressionLevel = 5
+	// zstdWindowSize is the window size of the zstd compressed responses.
+	zstdWindowSize = 1 << 20
 )
 
 // HTTProuter is a thread-safe multiplexer http(s) router using go-chi and autocert with a set of
@@ -68,6 +74,22 @@ type RouterNamespace interface {
 // RouterHandlerFn is the function signature for adding handlers to the HTTProuter.
 type RouterHandlerFn = func(msg Message)
 
+// newCompressor returns the middleware that compresses the responses with the
+// encoding accepted by the client, preferring zstd over gzip and deflate.
+func newCompressor() *middleware.Compressor {
+	c := middleware.NewCompressor(compressionLevel)
+	c.SetEncoder("zstd", func(w io.Writer, _ int) io.Writer {
+		// the window is limited so that browsers can decode the responses
+		enc, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1),
+			zstd.WithWindowSize(zstdWindowSize), zstd.WithLowerEncoderMem(true))
+		if err != nil {
+			return nil
+		}
+		return enc
+	})
+	return c
+}
+
 // Init initializes the router
 func (r *HTTProuter) Init(host string, port int) error {
 	r.namespaces = make(map[string]RouterNamespace, 32)
@@ -94,7 +116,7 @@ func (r *HTTProuter) Init(host string, port int) error {
 	r.Mux.Use(middleware.Heartbeat("/ping"))
 	r.Mux.Use(middleware.ThrottleBacklog(5000, 40000, 30*time.Second))
 	r.Mux.Use(middleware.Timeout(30 * time.Second))
-	r.Mux.Use(middleware.Compress(5))
+	r.Mux.Use(newCompressor().Handler)
 
 	// Cors handler
 	cors := cors.New(cors.Options{
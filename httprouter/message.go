@@ -72,6 +72,19 @@ func (h *HTTPContext) Send(msg []byte, httpStatusCode int) error {
 	} else {
 		h.writer.Header().Set("Content-Type", h.contentType)
 	}
+	// JSON responses are sent in CBOR to the clients which prefer it
+	cbor := false
+	if h.contentType == "" || h.contentType == DefaultContentType {
+		h.writer.Header().Add("Vary", "Accept")
+		if len(msg) > 0 && AcceptsCBOR(h.Request.Header.Get("Accept")) {
+			if data, err := JSONToCBOR(msg); err != nil {
+				log.Debugw("cannot encode response in CBOR, sending JSON", "error", err)
+			} else {
+				msg, cbor = data, true
+				h.writer.Header().Set("Content-Type", ContentTypeCBOR)
+			}
+		}
+	}
 
 	// Special handling for no content, reset content, and not modified.
 	if httpStatusCode == http.StatusNoContent ||
@@ -84,8 +97,13 @@ func (h *HTTPContext) Send(msg []byte, httpStatusCode int) error {
 		return nil
 	}
 
-	// Content length will be message length plus newline character
-	h.writer.Header().Set("Content-Length", fmt.Sprintf("%d", len(msg)+1))
+	// Content length will be message length plus newline character, which
+	// is not appended to the binary CBOR responses
+	if cbor {
+		h.writer.Header().Set("Content-Length", fmt.Sprintf("%d", len(msg)))
+	} else {
+		h.writer.Header().Set("Content-Length", fmt.Sprintf("%d", len(msg)+1))
+	}
 	h.writer.WriteHeader(httpStatusCode)
 
 	// Log the response, but only if it's not binary data.
@@ -110,6 +128,9 @@ func (h *HTTPContext) Send(msg []byte, httpStatusCode int) error {
 	if _, err := h.writer.Write(msg); err != nil {
 		return err
 	}
+	if cbor {
+		return nil
+	}
 	// Ensure we end the response with a newline, to be nice.
 	_, err := h.writer.Write([]byte("\n"))
 	return err
@@ -0,0 +1,87 @@
+package httprouter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"go.vocdoni.io/dvote/log"
+)
+
+// ContentTypeNDJSON is the content type of the responses streamed as
+// newline-delimited JSON, one value per line.
+const ContentTypeNDJSON = "application/x-ndjson"
+
+const (
+	// streamBufferSize is the size of the chunks written to the client by
+	// the streamed responses.
+	streamBufferSize = 64 << 10
+	// streamWriteTimeout is the time given to the client to receive each
+	// chunk of a streamed response. It replaces the write timeout of the
+	// server, which would cut the long responses.
+	streamWriteTimeout = 30 * time.Second
+)
+
+// Stream replies the request with a response of unknown length, which is
+// written by fn as it is produced, such as the lines of a ContentTypeNDJSON
+// response. The writes of fn are buffered and sent to the client in chunks,
+// each of them blocking until the client receives it, so a slow client slows
+// fn down instead of making the server buffer the response. A client which does
+// not receive a chunk in streamWriteTimeout makes the writes fail.
+//
+// The context passed to fn is not canceled by the request timeout, since the
+// streamed responses can take longer, so fn must stop on the first write error.
+// Since the status is sent before calling fn, its error is only logged and
+// returned, without replying to the request.
+func (h *HTTPContext) Stream(contentType string, fn func(ctx context.Context, w io.Writer) error) error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Warnf("recovered http stream panic: %v", r)
+		}
+	}()
+	defer close(h.sent)
+
+	if h.Request.Context().Err() != nil {
+		// The connection was closed, so don't try to write to it.
+		return errors.New("connection is closed")
+	}
+	h.writer.Header().Set("Content-Type", contentType)
+	h.writer.WriteHeader(http.StatusOK)
+
+	start := time.Now()
+	sw := &streamWriter{w: h.writer, rc: http.NewResponseController(h.writer)}
+	bw := bufio.NewWriterSize(sw, streamBufferSize)
+	err := fn(context.WithoutCancel(h.Request.Context()), bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	log.Debugw("http stream response", "size", sw.size, "elapsed", time.Since(start), "error", err)
+	return err
+}
+
+// streamWriter writes the chunks of a streamed response, flushing each of them
+// to the client within streamWriteTimeout.
+type streamWriter struct {
+	w    http.ResponseWriter
+	rc   *http.ResponseController
+	size int
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if err := s.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil &&
+		!errors.Is(err, http.ErrNotSupported) {
+		return 0, err
+	}
+	n, err := s.w.Write(p)
+	s.size += n
+	if err != nil {
+		return n, err
+	}
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return n, err
+	}
+	return n, nil
+}
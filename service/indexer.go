@@ -4,30 +4,84 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math/big"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"go.vocdoni.io/dvote/log"
 	"go.vocdoni.io/dvote/snapshot"
 	"go.vocdoni.io/dvote/vochain/indexer"
+	"go.vocdoni.io/dvote/vochain/indexer/webhook"
 )
 
 // VochainIndexer creates the vochain indexer service.
 func (vs *VocdoniService) VochainIndexer() error {
 	log.Info("creating vochain indexer service")
 	var err error
-	vs.Indexer, err = indexer.New(vs.App, indexer.Options{
+	opts := indexer.Options{
 		DataDir:           filepath.Join(vs.Config.DataDir, "indexer"),
 		IgnoreLiveResults: vs.Config.Indexer.IgnoreLiveResults,
 		// During StateSync, IndexerDB will be restored, so enable ExpectBackupRestore in that case
 		ExpectBackupRestore: vs.Config.StateSyncEnabled,
-	})
+		EncryptionKey:       vs.Config.Indexer.EncryptionKey,
+		ReplicaInterval:     vs.Config.Indexer.ReplicaInterval,
+		EstimateCounts:      vs.Config.Indexer.EstimateCounts,
+	}
+	if vs.Config.Indexer.VotePatterns {
+		opts.VotePatterns = &indexer.VotePatternOptions{
+			MaxVotesPerMinute: vs.Config.Indexer.VotePatternMaxVotesPerMinute,
+		}
+	}
+	// ship the replicas of the database to be served by the indexer read replicas
+	if dir := vs.Config.Indexer.ReplicaDir; dir != "" {
+		opts.ReplicateTo = indexer.DirReplicaStore(dir)
+	}
+	// save the scheduled backups of the database
+	if to := vs.Config.Indexer.BackupTo; to != "" {
+		if opts.BackupTo, err = vs.indexerBackupStore(to); err != nil {
+			return err
+		}
+		opts.BackupInterval = vs.Config.Indexer.BackupInterval
+		opts.BackupsKept = vs.Config.Indexer.BackupsKept
+		opts.BackupMaxAge = vs.Config.Indexer.BackupMaxAge
+	}
+	// the weight of the censuses downloaded by the offchain data handler is used
+	// to compute the weight turnout of the processes
+	if vs.CensusDB != nil {
+		opts.CensusWeight = vs.censusWeight
+	}
+	vs.Indexer, err = indexer.New(vs.App, opts)
 	if err != nil {
 		return err
 	}
+	if newKey := vs.Config.Indexer.EncryptionNewKey; newKey != "" && newKey != opts.EncryptionKey {
+		if err := vs.Indexer.RotateEncryptionKey(context.Background(), newKey); err != nil {
+			return fmt.Errorf("cannot rotate the indexer encryption key: %w", err)
+		}
+		log.Warn("indexer database re-encrypted, set the new key as vochainIndexerEncryptionKey for the next restarts")
+	}
+	// post the indexer events to the configured webhooks
+	if len(vs.Config.Indexer.WebhookURLs) > 0 {
+		vs.Webhooks, err = webhook.New(webhook.Config{
+			URLs:        vs.Config.Indexer.WebhookURLs,
+			Secret:      vs.Config.Indexer.WebhookSecret,
+			MinTransfer: vs.Config.Indexer.WebhookMinTransfer,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot create webhooks: %w", err)
+		}
+		vs.App.State.AddEventListener(vs.Webhooks)
+		vs.Indexer.AddEventListener(vs.Webhooks)
+	}
 	// launch the indexer after sync routine (executed when the blockchain is ready)
 	go vs.Indexer.AfterSyncBootstrap(false)
+	// check periodically that the processes metadata is still retrievable from the storage
+	if vs.Storage != nil {
+		go vs.Indexer.TrackMetadataAvailability(context.Background(), vs.Storage, indexer.MetadataCheckInterval)
+	}
 
 	snapshot.SetFnImportIndexer(func(r io.Reader) error {
 		log.Debugf("restoring indexer backup")
@@ -69,3 +123,37 @@ func (vs *VocdoniService) VochainIndexer() error {
 
 	return nil
 }
+
+// indexerBackupStore returns the store of the indexer backups, which is an
+// S3-compatible bucket if to is an s3://bucket/prefix URL, or a directory.
+func (vs *VocdoniService) indexerBackupStore(to string) (indexer.BackupStore, error) {
+	if !strings.HasPrefix(to, "s3://") {
+		return indexer.DirBackupStore(to), nil
+	}
+	u, err := url.Parse(to)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid indexer backup URL %q, expected s3://bucket/prefix", to)
+	}
+	prefix := strings.TrimPrefix(u.Path, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &indexer.S3BackupStore{
+		Endpoint:  vs.Config.Indexer.BackupS3Endpoint,
+		Region:    vs.Config.Indexer.BackupS3Region,
+		Bucket:    u.Host,
+		Prefix:    prefix,
+		AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	}, nil
+}
+
+// censusWeight returns the total weight of the census published with the given root.
+func (vs *VocdoniService) censusWeight(censusRoot []byte) (*big.Int, error) {
+	ref, err := vs.CensusDB.Load(censusRoot, nil)
+	defer vs.CensusDB.UnLoad()
+	if err != nil {
+		return nil, err
+	}
+	return ref.Tree().GetCensusWeight()
+}
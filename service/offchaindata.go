@@ -29,6 +29,7 @@ func (vs *VocdoniService) OffChainDataHandler() error {
 			return err
 		}
 		vs.CensusDB = censusdb.NewCensusDB(db)
+		vs.CensusDB.SetStorage(vs.Storage)
 	}
 	vs.OffChainData = offchaindatahandler.NewOffChainDataHandler(
 		vs.App,
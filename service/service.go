@@ -9,6 +9,7 @@ import (
 	"go.vocdoni.io/dvote/httprouter"
 	"go.vocdoni.io/dvote/vochain"
 	"go.vocdoni.io/dvote/vochain/indexer"
+	"go.vocdoni.io/dvote/vochain/indexer/webhook"
 	"go.vocdoni.io/dvote/vochain/keykeeper"
 	"go.vocdoni.io/dvote/vochain/offchaindatahandler"
 	"go.vocdoni.io/dvote/vochain/vochaininfo"
@@ -23,6 +24,7 @@ type VocdoniService struct {
 	DataDownloader *downloader.Downloader
 	CensusDB       *censusdb.CensusDB
 	Indexer        *indexer.Indexer
+	Webhooks       *webhook.Webhooks
 	Stats          *vochaininfo.VochainInfo
 	Storage        data.Storage
 	Signer         *ethereum.SignKeys
@@ -229,6 +229,28 @@ func (s *Snapshot) Finish() error {
 	return os.Remove(s.file.Name())
 }
 
+// VerifyHash checks that the hash of the blobs of a snapshot opened with Open
+// matches the one of its header, so that a corrupted snapshot is not restored.
+// The snapshot is left ready to read its first blob.
+func (s *Snapshot) VerifyHash() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	blobsStart := int64(snapshotHeaderLenSize) + int64(s.headerSize)
+	if _, err := s.file.Seek(blobsStart, io.SeekStart); err != nil {
+		return err
+	}
+	hasher := md5.New()
+	if _, err := io.Copy(hasher, s.file); err != nil {
+		return fmt.Errorf("cannot hash snapshot: %w", err)
+	}
+	if hash := hasher.Sum(nil); !bytes.Equal(hash, s.header.Hash) {
+		return fmt.Errorf("snapshot hash mismatch: header has %x, contents have %x", s.header.Hash, hash)
+	}
+	_, err := s.file.Seek(blobsStart, io.SeekStart)
+	return err
+}
+
 // Close closes the file descriptor used by the snapshot
 func (s *Snapshot) Close() error {
 	return s.file.Close()
@@ -625,7 +647,16 @@ func (sm *SnapshotManager) JoinChunks(chunks int32, height int64) (*Snapshot, er
 	}
 
 	log.Debugf("snapshot file %s has header.Hash=%x", snapFile.Name(), s.header.Hash)
-	// TODO: hash the contents and check it matches the s.header.Hash, confirming no corruption
+	if err := s.VerifyHash(); err != nil {
+		// remove it, so that it is not offered to other nodes
+		if err2 := s.Close(); err2 != nil {
+			log.Warnf("error closing the snapshot: %v", err2)
+		}
+		if err2 := os.Remove(snapshotFilename); err2 != nil {
+			log.Warnf("couldn't remove snapshot file %s: %v", snapshotFilename, err2)
+		}
+		return nil, err
+	}
 
 	return s, nil
 }
@@ -649,6 +680,16 @@ func (sm *SnapshotManager) WriteChunkToDisk(index uint32, chunk []byte) error {
 	return nil
 }
 
+// ClearChunks removes the chunks stored in the chunk storage dir, such as the
+// ones left by a snapshot which could not be restored.
+func (sm *SnapshotManager) ClearChunks() error {
+	dir := filepath.Join(sm.dataDir, chunksDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.MkdirAll(dir, 0o750)
+}
+
 // CountChunksInDisk counts how many files are present inside the chunk storage dir
 func (sm *SnapshotManager) CountChunksInDisk() int {
 	files, err := os.ReadDir(filepath.Join(sm.dataDir, chunksDir))
@@ -0,0 +1,70 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+func TestSnapshotChunks(t *testing.T) {
+	c := qt.New(t)
+	st, err := state.New(db.TypePebble, t.TempDir())
+	c.Assert(err, qt.IsNil)
+	st.SetChainID("test")
+	c.Assert(st.SetTxBaseCost(models.TxType_NEW_PROCESS, 100), qt.IsNil)
+	st.SetHeight(1)
+	_, err = st.PrepareCommit()
+	c.Assert(err, qt.IsNil)
+	_, err = st.Save()
+	c.Assert(err, qt.IsNil)
+
+	// a snapshot served in chunks by a node...
+	sm, err := NewManager(t.TempDir(), 100)
+	c.Assert(err, qt.IsNil)
+	_, err = sm.Do(st)
+	c.Assert(err, qt.IsNil)
+	snap := sm.List()[1]
+	c.Assert(snap, qt.IsNotNil)
+	c.Assert(snap.Header().Root, qt.DeepEquals, st.CommittedHash())
+	chunks := int32((snap.Size() + sm.ChunkSize - 1) / sm.ChunkSize)
+	c.Assert(chunks > 1, qt.IsTrue)
+
+	// ...is joined by the node receiving them
+	dst, err := NewManager(t.TempDir(), 100)
+	c.Assert(err, qt.IsNil)
+	writeChunks := func() {
+		for i := range chunks {
+			chunk, err := sm.SliceChunk(1, 0, uint32(i))
+			c.Assert(err, qt.IsNil)
+			c.Assert(dst.WriteChunkToDisk(uint32(i), chunk), qt.IsNil)
+		}
+		c.Assert(dst.CountChunksInDisk(), qt.Equals, int(chunks))
+	}
+	writeChunks()
+	joined, err := dst.JoinChunks(chunks, 1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(joined.Header().Hash, qt.DeepEquals, snap.Header().Hash)
+	c.Assert(joined.Close(), qt.IsNil)
+
+	// a corrupted snapshot is detected and removed
+	writeChunks()
+	last := filepath.Join(dst.dataDir, chunksDir, fmt.Sprint(chunks-1))
+	chunk, err := os.ReadFile(last)
+	c.Assert(err, qt.IsNil)
+	chunk[len(chunk)-1] ^= 0xff
+	c.Assert(os.WriteFile(last, chunk, 0o600), qt.IsNil)
+	_, err = dst.JoinChunks(chunks, 1)
+	c.Assert(err, qt.ErrorMatches, "snapshot hash mismatch.*")
+	c.Assert(dst.List(), qt.HasLen, 0)
+
+	// the chunks left by a failed snapshot are cleared
+	c.Assert(dst.WriteChunkToDisk(0, chunk), qt.IsNil)
+	c.Assert(dst.ClearChunks(), qt.IsNil)
+	c.Assert(dst.CountChunksInDisk(), qt.Equals, 0)
+}
@@ -1,23 +1,31 @@
 package test
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	qt "github.com/frankban/quicktest"
 	"github.com/google/uuid"
 	"go.vocdoni.io/dvote/api"
+	"go.vocdoni.io/dvote/apiclient"
 	"go.vocdoni.io/dvote/crypto/ethereum"
 	"go.vocdoni.io/dvote/crypto/nacl"
+	"go.vocdoni.io/dvote/crypto/zk/circuit"
+	"go.vocdoni.io/dvote/crypto/zk/prover"
 	"go.vocdoni.io/dvote/data/ipfs"
 	"go.vocdoni.io/dvote/test/testcommon"
 	"go.vocdoni.io/dvote/test/testcommon/testutil"
+	"go.vocdoni.io/dvote/tree/arbo"
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/util"
 	"go.vocdoni.io/dvote/vochain"
@@ -496,6 +504,58 @@ func TestAPIBlocks(t *testing.T) {
 	qt.Assert(t, block.TxCount, qt.Equals, int64(1))
 }
 
+func TestAPIBatch(t *testing.T) {
+	server := testcommon.APIserver{}
+	server.Start(t,
+		api.ChainHandler,
+		api.BatchHandler,
+	)
+	token1 := uuid.New()
+	c := testutil.NewTestHTTPclient(t, server.ListenAddr, &token1)
+
+	// Block 1
+	server.VochainAPP.AdvanceTestBlock()
+	waitUntilHeight(t, c, 1)
+
+	// Block 2, so the block 1 is committed
+	server.VochainAPP.AdvanceTestBlock()
+	waitUntilHeight(t, c, 2)
+
+	batch := api.BatchRequest{Requests: []*api.BatchSubRequest{
+		{Method: "GET", Path: "/chain/info"},
+		{Method: "GET", Path: "/chain/blocks/1"},
+		{Method: "GET", Path: "/chain/blocks/1000"},
+	}}
+	resp, code := c.Request("POST", &batch, "batch")
+	qt.Assert(t, code, qt.Equals, 200, qt.Commentf("response: %s", resp))
+	batchResp := api.BatchResponse{}
+	qt.Assert(t, json.Unmarshal(resp, &batchResp), qt.IsNil)
+	qt.Assert(t, batchResp.Responses, qt.HasLen, 3)
+
+	// the responses are in the same order as the requests
+	qt.Assert(t, batchResp.Responses[0].Status, qt.Equals, 200)
+	info := api.ChainInfo{}
+	qt.Assert(t, json.Unmarshal(batchResp.Responses[0].Body, &info), qt.IsNil)
+	qt.Assert(t, info.ID, qt.Equals, server.VochainAPP.ChainID())
+	qt.Assert(t, batchResp.Responses[1].Status, qt.Equals, 200)
+	block := api.Block{}
+	qt.Assert(t, json.Unmarshal(batchResp.Responses[1].Body, &block), qt.IsNil)
+	qt.Assert(t, block.Height, qt.Equals, int64(1))
+	// a failed request does not fail the batch
+	qt.Assert(t, batchResp.Responses[2].Status, qt.Equals, 404)
+	apiErr := struct {
+		Code int `json:"code"`
+	}{}
+	qt.Assert(t, json.Unmarshal(batchResp.Responses[2].Body, &apiErr), qt.IsNil)
+	qt.Assert(t, apiErr.Code, qt.Equals, api.ErrBlockNotFound.Code)
+
+	// batches cannot be nested
+	batch = api.BatchRequest{Requests: []*api.BatchSubRequest{{Method: "POST", Path: "/batch"}}}
+	resp, code = c.Request("POST", &batch, "batch")
+	qt.Assert(t, code, qt.Equals, 400, qt.Commentf("response: %s", resp))
+	qt.Assert(t, string(resp), qt.Contains, "batches cannot be nested")
+}
+
 func runAPIElectionCostWithParams(t *testing.T,
 	electionParams electionprice.ElectionParameters,
 	startBlock uint32, initialBalance,
@@ -521,6 +581,11 @@ func runAPIElectionCostWithParams(t *testing.T,
 	qt.Assert(t, err, qt.IsNil)
 	server.VochainAPP.State.ElectionPriceCalc.SetCapacity(networkCapacity)
 	server.VochainAPP.State.ElectionPriceCalc.SetBasePrice(1)
+	if electionParams.AnonymousVotes {
+		// the anonymous census size is limited by the levels of the circuit,
+		// and the dev one only has 10
+		qt.Assert(t, circuit.SetGlobal(circuit.V1_0_0), qt.IsNil)
+	}
 
 	// Block 1
 	server.VochainAPP.AdvanceTestBlock()
@@ -1039,3 +1104,274 @@ func TestAPIElectionsList(t *testing.T) {
 	el["me_true"] = fetchEL("GET", nil, "manuallyEnded=true", "elections")
 	qt.Assert(t, el["me_true"].Pagination.TotalItems, qt.Equals, uint64(0))
 }
+
+func TestAPIIndexedChains(t *testing.T) {
+	server := testcommon.APIserver{}
+	server.Start(t, api.ChainHandler, api.ElectionHandler, api.VoteHandler, api.AccountHandler)
+	c := testutil.NewTestHTTPclient(t, server.ListenAddr, nil)
+
+	// index another chain in the database of the indexer of the node
+	otherApp := vochain.TestBaseApplicationWithChainID(t, "other")
+	otherApp.SetChainID("other")
+	other, err := server.Indexer.AttachApp(otherApp)
+	qt.Assert(t, err, qt.IsNil)
+	pid := util.RandomBytes(32)
+	qt.Assert(t, otherApp.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EntityId:      util.RandomBytes(20),
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		BlockCount:    10,
+		MaxCensusSize: 10,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+	}), qt.IsNil)
+	otherApp.AdvanceTestBlock()
+	otherApp.AdvanceTestBlock()
+
+	resp, code := c.Request("GET", nil, "chain", "indexed")
+	qt.Assert(t, code, qt.Equals, 200, qt.Commentf("response: %s", resp))
+	var indexed api.IndexedChainsList
+	qt.Assert(t, json.Unmarshal(resp, &indexed), qt.IsNil)
+	qt.Assert(t, indexed.Chains, qt.HasLen, 2)
+	qt.Assert(t, indexed.Chains[0].Local, qt.IsTrue)
+	qt.Assert(t, indexed.Chains[1], qt.DeepEquals, &api.IndexedChain{
+		ChainID: "other",
+		Height:  uint64(other.Status().Height),
+	})
+	qt.Assert(t, indexed.Chains[1].Height, qt.Not(qt.Equals), uint64(0))
+
+	// the organizations of each chain are listed apart
+	organizations := func(query string) uint64 {
+		resp, code := c.RequestWithQuery("GET", nil, query, "chain", "organizations")
+		qt.Assert(t, code, qt.Equals, 200, qt.Commentf("response: %s", resp))
+		var list api.OrganizationsList
+		qt.Assert(t, json.Unmarshal(resp, &list), qt.IsNil)
+		return list.Pagination.TotalItems
+	}
+	qt.Assert(t, organizations(""), qt.Equals, uint64(0))
+	qt.Assert(t, organizations("chainId=other"), qt.Equals, uint64(1))
+
+	resp, code = c.RequestWithQuery("GET", nil, "chainId=unknown", "chain", "organizations")
+	qt.Assert(t, code, qt.Equals, api.ErrChainNotFound.HTTPstatus, qt.Commentf("response: %s", resp))
+
+	// and so are their elections, votes and accounts
+	resp, code = c.Request("GET", nil, "elections", hex.EncodeToString(pid))
+	qt.Assert(t, code, qt.Equals, api.ErrElectionNotFound.HTTPstatus, qt.Commentf("response: %s", resp))
+	resp, code = c.RequestWithQuery("GET", nil, "chainId=other", "elections", hex.EncodeToString(pid))
+	qt.Assert(t, code, qt.Equals, 200, qt.Commentf("response: %s", resp))
+	var election api.Election
+	qt.Assert(t, json.Unmarshal(resp, &election), qt.IsNil)
+	qt.Assert(t, election.ChainID, qt.Equals, "other")
+	resp, code = c.RequestWithQuery("GET", nil, "chainId=other", "elections")
+	qt.Assert(t, code, qt.Equals, 200, qt.Commentf("response: %s", resp))
+	var elections api.ElectionsList
+	qt.Assert(t, json.Unmarshal(resp, &elections), qt.IsNil)
+	qt.Assert(t, elections.Elections, qt.HasLen, 1)
+	resp, code = c.RequestWithQuery("GET", nil, "chainId=other&electionId="+hex.EncodeToString(pid), "votes", "count")
+	qt.Assert(t, code, qt.Equals, 200, qt.Commentf("response: %s", resp))
+	resp, code = c.RequestWithQuery("GET", nil, "electionId="+hex.EncodeToString(pid), "votes", "count")
+	qt.Assert(t, code, qt.Equals, api.ErrElectionNotFound.HTTPstatus, qt.Commentf("response: %s", resp))
+	resp, code = c.RequestWithQuery("GET", nil, "chainId=unknown", "accounts", "count")
+	qt.Assert(t, code, qt.Equals, api.ErrChainNotFound.HTTPstatus, qt.Commentf("response: %s", resp))
+}
+
+func TestAPIVerifyZkProof(t *testing.T) {
+	server := testcommon.APIserver{}
+	server.Start(t, api.ElectionHandler)
+	cli, err := apiclient.New(server.ListenAddr.String())
+	qt.Assert(t, err, qt.IsNil)
+
+	censusRoot := big.NewInt(1234)
+	addProcess := func(anonymous bool) types.HexBytes {
+		pid := util.RandomBytes(32)
+		qt.Assert(t, server.VochainAPP.State.AddProcess(&models.Process{
+			ProcessId:     pid,
+			EntityId:      server.Account.Address().Bytes(),
+			EnvelopeType:  &models.EnvelopeType{Anonymous: anonymous},
+			Status:        models.ProcessStatus_READY,
+			Mode:          &models.ProcessMode{AutoStart: true},
+			CensusRoot:    arbo.BigIntToBytesLE(arbo.HashFunctionPoseidon.Len(), censusRoot),
+			BlockCount:    10,
+			MaxCensusSize: 10,
+			VoteOptions:   &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+		}), qt.IsNil)
+		return pid
+	}
+	anonymous, signed := addProcess(true), addProcess(false)
+	server.VochainAPP.AdvanceTestBlock()
+
+	// newProof returns a proof with the public signals of the circuit of the
+	// chain, which are checked against the election before the proof itself
+	votePackage := []byte("vote package")
+	newProof := func(electionID types.HexBytes, censusRoot *big.Int, votePackage []byte) *prover.Proof {
+		signals := map[string]string{"nullifier": "1", "voteWeight": "1", "sikRoot": "1"}
+		electionIDs := util.BytesToArboSplitStr(electionID)
+		signals["electionId[0]"], signals["electionId[1]"] = electionIDs[0], electionIDs[1]
+		signals["censusRoot"] = censusRoot.String()
+		voteHashes := util.BytesToArboSplitStr(votePackage)
+		signals["voteHash[0]"], signals["voteHash[1]"] = voteHashes[0], voteHashes[1]
+		proof := &prover.Proof{PubSignals: make([]string, len(circuit.Global().Config.PublicSignals))}
+		for name, i := range circuit.Global().Config.PublicSignals {
+			proof.PubSignals[i] = signals[name]
+		}
+		return proof
+	}
+	verify := func(proof *prover.Proof, votePackage []byte) string {
+		result, err := cli.VerifyZkProof(anonymous, proof, votePackage)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, result.Valid, qt.IsFalse)
+		qt.Assert(t, result.Nullifier, qt.IsNil)
+		return result.Error
+	}
+
+	// the proofs which do not match the election are not valid, which is not
+	// an error of the request
+	qt.Assert(t, verify(&prover.Proof{PubSignals: []string{"1", "2", "3"}}, votePackage),
+		qt.Matches, ".*"+prover.ErrPublicSignalFormat.Error()+".*")
+	qt.Assert(t, verify(newProof(signed, censusRoot, votePackage), votePackage),
+		qt.Matches, "process id mismatch.*")
+	qt.Assert(t, verify(newProof(anonymous, big.NewInt(4321), votePackage), votePackage),
+		qt.Equals, "census root mismatch")
+	qt.Assert(t, verify(newProof(anonymous, censusRoot, votePackage), []byte("other vote package")),
+		qt.Equals, "vote hash mismatch")
+
+	// a proof matching the election is then verified by the circuit, if it
+	// supports the anonymous votes
+	qt.Assert(t, verify(newProof(anonymous, censusRoot, votePackage), votePackage),
+		qt.Matches, "(anonymous voting not supported by zk circuit|zkSNARK proof verification failed).*")
+
+	// only the proofs of the existing anonymous elections are verified
+	proof := newProof(anonymous, censusRoot, votePackage)
+	_, err = cli.VerifyZkProof(signed, proof, votePackage)
+	qt.Assert(t, err, qt.ErrorMatches, fmt.Sprintf("(?s).*%d.*", api.ErrElectionNotAnonymous.Code))
+	_, err = cli.VerifyZkProof(util.RandomBytes(32), proof, votePackage)
+	qt.Assert(t, err, qt.ErrorMatches, fmt.Sprintf("(?s).*%d.*", api.ErrElectionNotFound.Code))
+	_, err = cli.VerifyZkProof(anonymous, nil, votePackage)
+	qt.Assert(t, err, qt.ErrorMatches, fmt.Sprintf("(?s).*%d.*", api.ErrParamKeyOrProofMissing.Code))
+}
+func TestAPIFiles(t *testing.T) {
+	server := testcommon.APIserver{}
+	server.Start(t, api.ElectionHandler)
+	c := testutil.NewTestHTTPclient(t, server.ListenAddr, nil)
+	cli, err := apiclient.New(server.ListenAddr.String())
+	qt.Assert(t, err, qt.IsNil)
+
+	content := []byte(`{"title":{"default":"test"}}`)
+	uri, err := server.Storage.Publish(context.Background(), content)
+	qt.Assert(t, err, qt.IsNil)
+	cid := strings.TrimPrefix(uri, server.Storage.URIprefix())
+
+	resp, code := c.Request("GET", nil, "files", cid)
+	qt.Assert(t, code, qt.Equals, 200, qt.Commentf("response: %s", resp))
+	var file api.File
+	qt.Assert(t, json.Unmarshal(resp, &file), qt.IsNil)
+	qt.Assert(t, file.Payload, qt.DeepEquals, content)
+	qt.Assert(t, file.CID, qt.Equals, uri)
+
+	// the client verifies the contents against the CID
+	data, err := cli.File(uri)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, data, qt.DeepEquals, content)
+
+	// the uploaded files can be fetched by their CID
+	uploaded := []byte("attachment")
+	uri, err = cli.UploadFile(bytes.NewReader(uploaded))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, uri, qt.Equals, "ipfs://"+ipfs.CalculateCIDv1json(uploaded))
+	var buf bytes.Buffer
+	qt.Assert(t, cli.FetchFile(uri, &buf), qt.IsNil)
+	qt.Assert(t, buf.Bytes(), qt.DeepEquals, uploaded)
+
+	resp, code = c.Request("POST", &api.File{Payload: make([]byte, api.MaxOffchainFileSize+1)}, "files")
+	qt.Assert(t, code, qt.Equals, api.ErrFileSizeTooBig.HTTPstatus, qt.Commentf("response: %s", resp))
+	_, err = cli.UploadFile(bytes.NewReader(make([]byte, api.MaxOffchainFileSize+1)))
+	qt.Assert(t, err, qt.ErrorMatches, "file size exceeds the maximum of .*")
+}
+
+func TestAPIChainInfoGenesisHash(t *testing.T) {
+	server := testcommon.APIserver{}
+	server.Start(t, api.ChainHandler)
+	cli, err := apiclient.New(server.ListenAddr.String())
+	qt.Assert(t, err, qt.IsNil)
+
+	info, err := cli.ChainInfo()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, info.GenesisHash, qt.Not(qt.HasLen), 0)
+	qt.Assert(t, info.GenesisHash, qt.DeepEquals, types.HexBytes(server.VochainAPP.Genesis().Hash()))
+
+	// the clients can verify the node is connected to the expected chain
+	qt.Assert(t, cli.VerifyChain(&apiclient.ChainCheck{GenesisHash: info.GenesisHash}), qt.IsNil)
+	qt.Assert(t, cli.VerifyChain(&apiclient.ChainCheck{GenesisHash: util.RandomBytes(32)}),
+		qt.ErrorIs, apiclient.ErrChainMismatch)
+}
+
+func TestAPIAccountDelegates(t *testing.T) {
+	server := testcommon.APIserver{}
+	server.Start(t,
+		api.ChainHandler,
+		api.AccountHandler,
+	)
+	c := testutil.NewTestHTTPclient(t, server.ListenAddr, nil)
+	server.VochainAPP.AdvanceTestBlock()
+	waitUntilHeight(t, c, 1)
+	signer := createAccount(t, c, server, 100)
+	server.VochainAPP.AdvanceTestBlock()
+	waitUntilHeight(t, c, 2)
+
+	cli, err := apiclient.New(server.ListenAddr.String())
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, cli.SetAccount(hex.EncodeToString(signer.PrivateKey())), qt.IsNil)
+	delegates := ethereum.NewSignKeysBatch(2)
+	delegated := func() []common.Address {
+		acc, err := server.VochainAPP.State.GetAccount(signer.Address(), true)
+		qt.Assert(t, err, qt.IsNil)
+		var addrs []common.Address
+		for _, addr := range acc.DelegateAddrs {
+			addrs = append(addrs, common.BytesToAddress(addr))
+		}
+		return addrs
+	}
+
+	// the account and the delegates are checked before sending the transaction
+	_, err = cli.AddAccountDelegates()
+	qt.Assert(t, err, qt.ErrorMatches, "no delegates")
+	noAccount, err := apiclient.New(server.ListenAddr.String())
+	qt.Assert(t, err, qt.IsNil)
+	_, err = noAccount.AddAccountDelegates(delegates[0].Address())
+	qt.Assert(t, err, qt.ErrorIs, apiclient.ErrAccountNotConfigured)
+
+	// the chain rejects the account itself and the same delegate twice
+	_, err = cli.AddAccountDelegates(delegates[0].Address(), signer.Address())
+	qt.Assert(t, err, qt.ErrorMatches, "(?s).*delegate cannot be the same as the sender.*")
+	_, err = cli.AddAccountDelegates(delegates[0].Address(), delegates[0].Address())
+	qt.Assert(t, err, qt.ErrorMatches, "(?s).*duplicate delegate address.*")
+	_, err = cli.DelAccountDelegates(delegates[0].Address())
+	qt.Assert(t, err, qt.ErrorMatches, "(?s).*delegate .* does not exist.*")
+	qt.Assert(t, delegated(), qt.HasLen, 0)
+
+	_, err = cli.AddAccountDelegates(delegates[0].Address(), delegates[1].Address())
+	qt.Assert(t, err, qt.IsNil)
+	server.VochainAPP.AdvanceTestBlock()
+	waitUntilHeight(t, c, 3)
+	qt.Assert(t, delegated(), qt.DeepEquals, []common.Address{delegates[0].Address(), delegates[1].Address()})
+
+	// a delegate cannot be appointed again, even along with a new one
+	_, err = cli.AddAccountDelegates(delegates[1].Address(), signer.Address())
+	qt.Assert(t, err, qt.ErrorMatches, "(?s).*delegate cannot be the same as the sender.*")
+	_, err = cli.AddAccountDelegates(ethereum.NewSignKeysBatch(1)[0].Address(), delegates[1].Address())
+	qt.Assert(t, err, qt.ErrorMatches, "(?s).*delegate .* already exists.*")
+
+	_, err = cli.DelAccountDelegates(delegates[1].Address())
+	qt.Assert(t, err, qt.IsNil)
+	server.VochainAPP.AdvanceTestBlock()
+	waitUntilHeight(t, c, 4)
+	qt.Assert(t, delegated(), qt.DeepEquals, []common.Address{delegates[0].Address()})
+
+	// a revoked delegate cannot be revoked again, and the others are kept
+	_, err = cli.DelAccountDelegates(delegates[0].Address(), delegates[1].Address())
+	qt.Assert(t, err, qt.ErrorMatches, "(?s).*delegate .* does not exist.*")
+	server.VochainAPP.AdvanceTestBlock()
+	waitUntilHeight(t, c, 5)
+	qt.Assert(t, delegated(), qt.DeepEquals, []common.Address{delegates[0].Address()})
+}
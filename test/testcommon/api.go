@@ -78,6 +78,7 @@ func (d *APIserver) Start(t testing.TB, apis ...string) {
 	db, err := metadb.New(db.TypePebble, t.TempDir())
 	qt.Assert(t, err, qt.IsNil)
 	censusDB := censusdb.NewCensusDB(db)
+	censusDB.SetStorage(d.Storage)
 
 	// attach all the pieces to the API
 	api.Attach(d.VochainAPP, d.VochainInfo, d.Indexer, d.Storage, censusDB)
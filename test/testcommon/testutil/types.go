@@ -32,13 +32,15 @@ func (b *MockBlockStore) AddTxToBlock(tx []byte) {
 	block.Txs = append(block.Txs, tx)
 }
 
-func (b *MockBlockStore) NewBlock(height int64, timestamp time.Time) {
+// NewBlock starts a new block. As in cometbft, appHash should be the state root
+// after executing the previous block.
+func (b *MockBlockStore) NewBlock(height int64, timestamp time.Time, appHash []byte) {
 	if count := b.height.Load(); height != count {
 		panic(fmt.Sprintf("height is not the expected one (got:%d expected:%d)", height, count))
 	}
 	log.Infow("new block", "height", height, "timestamp", timestamp)
 	b.set(height, &comettypes.Block{
-		Header: comettypes.Header{Height: height, Time: time.Now(), ChainID: "test"},
+		Header: comettypes.Header{Height: height, Time: time.Now(), ChainID: "test", AppHash: appHash},
 		Data:   comettypes.Data{Txs: make([]comettypes.Tx, 0)},
 	},
 	)
@@ -74,7 +74,8 @@ func TestAddBatchTreeEmpty(t *testing.T) {
 	nLeafs := 1024
 
 	database := metadb.NewTest(t)
-	tree, err := NewTree(Config{database, 256, DefaultThresholdNLeafs, HashFunctionPoseidon})
+	tree, err := NewTree(Config{Database: database, MaxLevels: 256,
+		ThresholdNLeafs: DefaultThresholdNLeafs, HashFunction: HashFunctionPoseidon})
 	c.Assert(err, qt.IsNil)
 
 	bLen := 32
@@ -95,7 +96,8 @@ func TestAddBatchTreeEmpty(t *testing.T) {
 	time1 := time.Since(start)
 
 	database2 := metadb.NewTest(t)
-	tree2, err := NewTree(Config{database2, 256, DefaultThresholdNLeafs, HashFunctionPoseidon})
+	tree2, err := NewTree(Config{Database: database2, MaxLevels: 256,
+		ThresholdNLeafs: DefaultThresholdNLeafs, HashFunction: HashFunctionPoseidon})
 	c.Assert(err, qt.IsNil)
 	tree2.dbgInit()
 
@@ -121,8 +123,8 @@ func TestAddBatchTreeEmptyNotPowerOf2(t *testing.T) {
 
 	database := metadb.NewTest(t)
 	tree, err := NewTree(Config{
-		database, 256, DefaultThresholdNLeafs,
-		HashFunctionPoseidon,
+		Database: database, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionPoseidon,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -137,8 +139,8 @@ func TestAddBatchTreeEmptyNotPowerOf2(t *testing.T) {
 
 	database2 := metadb.NewTest(t)
 	tree2, err := NewTree(Config{
-		database2, 256, DefaultThresholdNLeafs,
-		HashFunctionPoseidon,
+		Database: database2, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionPoseidon,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -170,15 +172,15 @@ func TestAddBatchTestVector1(t *testing.T) {
 	c := qt.New(t)
 	database1 := metadb.NewTest(t)
 	tree1, err := NewTree(Config{
-		database1, 256, DefaultThresholdNLeafs,
-		HashFunctionBlake2b,
+		Database: database1, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionBlake2b,
 	})
 	c.Assert(err, qt.IsNil)
 
 	database2 := metadb.NewTest(t)
 	tree2, err := NewTree(Config{
-		database2, 256, DefaultThresholdNLeafs,
-		HashFunctionBlake2b,
+		Database: database2, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionBlake2b,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -212,15 +214,15 @@ func TestAddBatchTestVector1(t *testing.T) {
 	// 2nd test vectors
 	database1 = metadb.NewTest(t)
 	tree1, err = NewTree(Config{
-		database1, 256, DefaultThresholdNLeafs,
-		HashFunctionBlake2b,
+		Database: database1, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionBlake2b,
 	})
 	c.Assert(err, qt.IsNil)
 
 	database2 = metadb.NewTest(t)
 	tree2, err = NewTree(Config{
-		database2, 256, DefaultThresholdNLeafs,
-		HashFunctionBlake2b,
+		Database: database2, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionBlake2b,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -262,15 +264,15 @@ func TestAddBatchTestVector2(t *testing.T) {
 
 	database := metadb.NewTest(t)
 	tree1, err := NewTree(Config{
-		database, 256, DefaultThresholdNLeafs,
-		HashFunctionPoseidon,
+		Database: database, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionPoseidon,
 	})
 	c.Assert(err, qt.IsNil)
 
 	database2 := metadb.NewTest(t)
 	tree2, err := NewTree(Config{
-		database2, 256, DefaultThresholdNLeafs,
-		HashFunctionPoseidon,
+		Database: database2, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionPoseidon,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -309,15 +311,15 @@ func TestAddBatchTestVector3(t *testing.T) {
 
 	database := metadb.NewTest(t)
 	tree1, err := NewTree(Config{
-		database, 256, DefaultThresholdNLeafs,
-		HashFunctionPoseidon,
+		Database: database, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionPoseidon,
 	})
 	c.Assert(err, qt.IsNil)
 
 	database2 := metadb.NewTest(t)
 	tree2, err := NewTree(Config{
-		database2, 256, DefaultThresholdNLeafs,
-		HashFunctionPoseidon,
+		Database: database2, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionPoseidon,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -360,15 +362,15 @@ func TestAddBatchTreeEmptyRandomKeys(t *testing.T) {
 
 	database1 := metadb.NewTest(t)
 	tree1, err := NewTree(Config{
-		database1, 256, DefaultThresholdNLeafs,
-		HashFunctionBlake2b,
+		Database: database1, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionBlake2b,
 	})
 	c.Assert(err, qt.IsNil)
 
 	database2 := metadb.NewTest(t)
 	tree2, err := NewTree(Config{
-		database2, 256, DefaultThresholdNLeafs,
-		HashFunctionBlake2b,
+		Database: database2, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionBlake2b,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -712,8 +714,8 @@ func TestAddBatchNotEmptyUnbalanced(t *testing.T) {
 
 	database2 := metadb.NewTest(t)
 	tree2, err := NewTree(Config{
-		database2, 256, DefaultThresholdNLeafs,
-		HashFunctionPoseidon,
+		Database: database2, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionPoseidon,
 	})
 	c.Assert(err, qt.IsNil)
 	tree2.dbgInit()
@@ -757,6 +759,87 @@ func TestAddBatchNotEmptyUnbalanced(t *testing.T) {
 	checkRoots(c, tree1, tree2)
 }
 
+// TestAddBatchConcurrentSubtrees forces AddBatch to split the tree into
+// subtrees built by several workers, both in memory and in disk, and checks
+// that the resulting root matches the one obtained adding the leafs one by one.
+// The initial leafs of the tree are placed above the level at which the tree
+// is split, so they have to be moved into the subtrees.
+func TestAddBatchConcurrentSubtrees(t *testing.T) {
+	testCases := []struct {
+		name            string
+		workers         int
+		initialNLeafs   int
+		nLeafs          int
+		thresholdNLeafs int
+	}{
+		{"memory, empty tree", 4, 0, 1000, DefaultThresholdNLeafs},
+		{"memory, one leaf", 4, 1, 1000, DefaultThresholdNLeafs},
+		{"memory, few leafs", 4, 3, 1000, DefaultThresholdNLeafs},
+		{"memory, many leafs", 4, 100, 1000, DefaultThresholdNLeafs},
+		{"memory, few keys", 8, 1, 5, DefaultThresholdNLeafs},
+		{"disk, few leafs", 4, 3, 1000, 1},
+		{"disk, many leafs", 4, 100, 1000, 1},
+		{"disk, few keys", 8, 2, 5, 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := qt.New(t)
+
+			tree1, err := NewTree(Config{
+				Database: metadb.NewTest(t), MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+				HashFunction: HashFunctionPoseidon,
+			})
+			c.Assert(err, qt.IsNil)
+			tree2, err := NewTree(Config{
+				Database: metadb.NewTest(t), MaxLevels: 256, ThresholdNLeafs: tc.thresholdNLeafs,
+				HashFunction: HashFunctionPoseidon,
+			})
+			c.Assert(err, qt.IsNil)
+			tree2.batchWorkers = tc.workers
+
+			bLen := tree1.HashFunction().Len()
+			var keys, values [][]byte
+			for i := 0; i < tc.initialNLeafs+tc.nLeafs; i++ {
+				k := BigIntToBytesLE(bLen, big.NewInt(int64(i)))
+				v := BigIntToBytesLE(bLen, big.NewInt(int64(i*2)))
+				c.Assert(tree1.Add(k, v), qt.IsNil)
+				if i < tc.initialNLeafs {
+					c.Assert(tree2.Add(k, v), qt.IsNil)
+					continue
+				}
+				keys = append(keys, k)
+				values = append(values, v)
+			}
+
+			invalids, err := tree2.AddBatch(keys, values)
+			c.Assert(err, qt.IsNil)
+			c.Check(len(invalids), qt.Equals, 0)
+
+			checkRoots(c, tree1, tree2)
+			nLeafs, err := tree2.GetNLeafs()
+			c.Assert(err, qt.IsNil)
+			c.Check(nLeafs, qt.Equals, tc.initialNLeafs+tc.nLeafs)
+
+			// adding the same keys again must fail for all of them
+			invalids, err = tree2.AddBatch(keys, values)
+			c.Assert(err, qt.IsNil)
+			c.Check(len(invalids), qt.Equals, len(keys))
+			checkRoots(c, tree1, tree2)
+		})
+	}
+}
+
+func TestBatchSubtreeLevel(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(batchSubtreeLevel(256, 1, 1000), qt.Equals, 0)
+	c.Assert(batchSubtreeLevel(256, 4, 1000), qt.Equals, 4)
+	c.Assert(batchSubtreeLevel(256, 6, 1000), qt.Equals, 4)
+	c.Assert(batchSubtreeLevel(256, 8, 1000), qt.Equals, 5)
+	c.Assert(batchSubtreeLevel(256, 8, 5), qt.Equals, 2)
+	c.Assert(batchSubtreeLevel(256, 8, 1), qt.Equals, 0)
+	c.Assert(batchSubtreeLevel(3, 8, 1000), qt.Equals, 2)
+}
+
 func TestFlp2(t *testing.T) {
 	c := qt.New(t)
 	c.Assert(flp2(31), qt.Equals, 16)
@@ -789,7 +872,8 @@ func benchAdd(t *testing.T, ks, vs [][]byte) {
 	c := qt.New(t)
 
 	database := metadb.NewTest(t)
-	tree, err := NewTree(Config{database, 256, DefaultThresholdNLeafs, HashFunctionBlake2b})
+	tree, err := NewTree(Config{Database: database, MaxLevels: 256,
+		ThresholdNLeafs: DefaultThresholdNLeafs, HashFunction: HashFunctionBlake2b})
 	c.Assert(err, qt.IsNil)
 
 	start := time.Now()
@@ -808,8 +892,8 @@ func benchAddBatch(t *testing.T, ks, vs [][]byte) {
 
 	database := metadb.NewTest(t)
 	tree, err := NewTree(Config{
-		database, 256, DefaultThresholdNLeafs,
-		HashFunctionBlake2b,
+		Database: database, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionBlake2b,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -842,8 +926,8 @@ func TestDbgStats(t *testing.T) {
 	// 1
 	database1 := metadb.NewTest(t)
 	tree1, err := NewTree(Config{
-		database1, 256, DefaultThresholdNLeafs,
-		HashFunctionBlake2b,
+		Database: database1, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionBlake2b,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -857,8 +941,8 @@ func TestDbgStats(t *testing.T) {
 	// 2
 	database2 := metadb.NewTest(t)
 	tree2, err := NewTree(Config{
-		database2, 256, DefaultThresholdNLeafs,
-		HashFunctionBlake2b,
+		Database: database2, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionBlake2b,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -871,8 +955,8 @@ func TestDbgStats(t *testing.T) {
 	// 3
 	database3 := metadb.NewTest(t)
 	tree3, err := NewTree(Config{
-		database3, 256, DefaultThresholdNLeafs,
-		HashFunctionBlake2b,
+		Database: database3, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionBlake2b,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -907,8 +991,8 @@ func TestLoadVT(t *testing.T) {
 
 	database := metadb.NewTest(t)
 	tree, err := NewTree(Config{
-		database, 256, DefaultThresholdNLeafs,
-		HashFunctionPoseidon,
+		Database: database, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionPoseidon,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -942,8 +1026,8 @@ func TestAddKeysWithEmptyValues(t *testing.T) {
 
 	database := metadb.NewTest(t)
 	tree, err := NewTree(Config{
-		database, 256, DefaultThresholdNLeafs,
-		HashFunctionPoseidon,
+		Database: database, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionPoseidon,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -964,8 +1048,8 @@ func TestAddKeysWithEmptyValues(t *testing.T) {
 
 	database2 := metadb.NewTest(t)
 	tree2, err := NewTree(Config{
-		database2, 256, DefaultThresholdNLeafs,
-		HashFunctionPoseidon,
+		Database: database2, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionPoseidon,
 	})
 	c.Assert(err, qt.IsNil)
 	tree2.dbgInit()
@@ -979,8 +1063,8 @@ func TestAddKeysWithEmptyValues(t *testing.T) {
 	// use tree3 to add nil value array
 	database3 := metadb.NewTest(t)
 	tree3, err := NewTree(Config{
-		database3, 256, DefaultThresholdNLeafs,
-		HashFunctionPoseidon,
+		Database: database3, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionPoseidon,
 	})
 	c.Assert(err, qt.IsNil)
 
@@ -2,9 +2,8 @@ package arbo
 
 import (
 	"bytes"
-	"fmt"
-	"math"
-	"runtime"
+	"math/bits"
+	"slices"
 	"sync"
 
 	"go.vocdoni.io/dvote/db"
@@ -12,7 +11,9 @@ import (
 
 // AddBatch adds a batch of key-values to the Tree. Returns an array containing
 // the indexes of the keys failed to add. Supports empty values as input
-// parameters, which is equivalent to 0 valued byte array.
+// parameters, which is equivalent to 0 valued byte array. The key space is split
+// in 2^n subtrees, which are built concurrently using the available CPUs and
+// then merged into the new root.
 func (t *Tree) AddBatch(keys, values [][]byte) ([]Invalid, error) {
 	wTx := t.db.WriteTx()
 	defer wTx.Discard()
@@ -47,19 +48,50 @@ func (t *Tree) AddBatchWithTx(wTx db.WriteTx, keys, values [][]byte) ([]Invalid,
 		values = values[:len(keys)]
 	}
 
+	// the values longer than the max value length are rejected before adding
+	// the batch, whose invalids are then mapped back to the given indexes
+	var tooLong []Invalid
+	var indexes []int
+	if t.maxValueLen > 0 {
+		var validKeys, validValues [][]byte
+		for i := range keys {
+			if err := t.checkValueLen(values[i]); err != nil {
+				tooLong = append(tooLong, Invalid{i, err})
+				continue
+			}
+			indexes = append(indexes, i)
+			validKeys = append(validKeys, keys[i])
+			validValues = append(validValues, values[i])
+		}
+		if len(tooLong) > 0 {
+			keys, values = validKeys, validValues
+		}
+	}
+
 	nLeafs, err := t.GetNLeafsWithTx(wTx)
 	if err != nil {
 		return nil, err
 	}
+	var invalids []Invalid
 	if nLeafs > t.thresholdNLeafs {
-		return t.addBatchInDisk(wTx, keys, values)
+		invalids, err = t.addBatchInDisk(wTx, keys, values)
+	} else {
+		invalids, err = t.addBatchInMemory(wTx, keys, values)
+	}
+	if err != nil || len(tooLong) == 0 {
+		return invalids, err
+	}
+	for i := range invalids {
+		invalids[i].Index = indexes[invalids[i].Index]
 	}
-	return t.addBatchInMemory(wTx, keys, values)
+	invalids = append(invalids, tooLong...)
+	slices.SortFunc(invalids, func(a, b Invalid) int { return a.Index - b.Index })
+	return invalids, nil
 }
 
 func (t *Tree) addBatchInDisk(wTx db.WriteTx, keys, values [][]byte) ([]Invalid, error) {
-	nCPU := flp2(runtime.NumCPU())
-	if nCPU == 1 || len(keys) < nCPU {
+	l := batchSubtreeLevel(t.maxLevels, t.batchWorkers, len(keys))
+	if l == 0 {
 		var invalids []Invalid
 		for i := 0; i < len(keys); i++ {
 			if err := t.addWithTx(wTx, keys[i], values[i]); err != nil {
@@ -74,88 +106,48 @@ func (t *Tree) addBatchInDisk(wTx db.WriteTx, keys, values [][]byte) ([]Invalid,
 		return nil, err
 	}
 
-	buckets := splitInBuckets(kvs, nCPU)
+	buckets := splitInBuckets(kvs, 1<<l)
 
 	root, err := t.RootWithTx(wTx)
 	if err != nil {
 		return nil, err
 	}
-
-	l := int(math.Log2(float64(nCPU)))
-	subRoots, err := t.getSubRootsAtLevel(wTx, root, l+1)
+	// the changes of the stats above level l are recorded in changes, and
+	// the ones of each subtree in bucketChanges
+	changes := &treeStats{}
+	subRoots, err := t.getSubRootsAtLevel(wTx, root, l, changes)
 	if err != nil {
 		return nil, err
 	}
-	if len(subRoots) != nCPU {
-		// Already populated Tree but Unbalanced.
-
-		// add one key at each bucket, and then continue with the flow
-		for i := 0; i < len(buckets); i++ {
-			// add one leaf of the bucket, if there is an error when
-			// adding the k-v, try to add the next one of the bucket
-			// (until one is added)
-			inserted := -1
-			for j := 0; j < len(buckets[i]); j++ {
-				if newRoot, err := t.add(wTx, root, 0,
-					buckets[i][j].k, buckets[i][j].v); err == nil {
-					inserted = j
-					root = newRoot
-					break
-				}
-			}
-
-			// remove the inserted element from buckets[i]
-			if inserted != -1 {
-				buckets[i] = append(buckets[i][:inserted], buckets[i][inserted+1:]...)
-			}
-		}
-		subRoots, err = t.getSubRootsAtLevel(wTx, root, l+1)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if len(subRoots) != nCPU {
-		return nil, fmt.Errorf("this error should not be reached."+
-			" len(subRoots) != nCPU, len(subRoots)=%d, nCPU=%d."+
-			" Please report it in a new issue:"+
-			" https://go.vocdoni.io/dvote/tree/arbo/issues/new", len(subRoots), nCPU)
-	}
 
-	invalidsInBucket := make([][]Invalid, nCPU)
-	txs := make([]db.WriteTx, nCPU)
-	for i := 0; i < nCPU; i++ {
+	// each worker uses its own wTx, once all are done, their content is
+	// copied into the main wTx
+	workers := min(t.batchWorkers, len(buckets))
+	txs := make([]db.WriteTx, workers)
+	for i := 0; i < workers; i++ {
 		txs[i] = t.db.WriteTx()
-		err := txs[i].Apply(wTx)
-		if err != nil {
+		if err := txs[i].Apply(wTx); err != nil {
 			return nil, err
 		}
 	}
 
-	var wg sync.WaitGroup
-	wg.Add(nCPU)
-	for i := 0; i < nCPU; i++ {
-		go func(cpu int) {
-			// use different wTx for each cpu, after once all
-			// are done, iter over the cpuWTxs and copy their
-			// content into the main wTx
-			for j := 0; j < len(buckets[cpu]); j++ {
-				newSubRoot, err := t.add(txs[cpu], subRoots[cpu],
-					l, buckets[cpu][j].k, buckets[cpu][j].v)
-				if err != nil {
-					invalidsInBucket[cpu] = append(invalidsInBucket[cpu],
-						Invalid{buckets[cpu][j].pos, err})
-					continue
-				}
-				// if there has not been errors, set the new subRoots[cpu]
-				subRoots[cpu] = newSubRoot
+	invalidsInBucket := make([][]Invalid, len(buckets))
+	bucketChanges := make([]treeStats, len(buckets))
+	runWorkers(workers, len(buckets), func(worker, bucket int) {
+		for j := 0; j < len(buckets[bucket]); j++ {
+			newSubRoot, err := t.add(txs[worker], subRoots[bucket],
+				l, buckets[bucket][j].k, buckets[bucket][j].v, &bucketChanges[bucket])
+			if err != nil {
+				invalidsInBucket[bucket] = append(invalidsInBucket[bucket],
+					Invalid{buckets[bucket][j].pos, err})
+				continue
 			}
-			wg.Done()
-		}(i)
-	}
-	wg.Wait()
+			// if there has not been errors, set the new subRoots[bucket]
+			subRoots[bucket] = newSubRoot
+		}
+	})
 
-	for i := 0; i < nCPU; i++ {
+	for i := 0; i < workers; i++ {
 		if err := wTx.Apply(txs[i]); err != nil {
 			return nil, err
 		}
@@ -166,13 +158,20 @@ func (t *Tree) addBatchInDisk(wTx db.WriteTx, keys, values [][]byte) ([]Invalid,
 		invalids = append(invalids, invalidsInBucket[i]...)
 	}
 
-	newRoot, err := t.upFromSubRoots(wTx, subRoots)
+	newRoot, err := t.upFromSubRoots(wTx, subRoots, changes)
 	if err != nil {
 		return nil, err
 	}
 
-	// update dbKeyNLeafs
-	if err := t.SetRootWithTx(wTx, newRoot); err != nil {
+	// update dbKeyRoot, the Tree is already locked by AddBatchWithTx
+	if err := t.setRoot(wTx, newRoot); err != nil {
+		return nil, err
+	}
+
+	for i := range bucketChanges {
+		changes.add(&bucketChanges[i])
+	}
+	if err := t.updateStats(wTx, root, newRoot, changes); err != nil {
 		return nil, err
 	}
 
@@ -184,7 +183,10 @@ func (t *Tree) addBatchInDisk(wTx db.WriteTx, keys, values [][]byte) ([]Invalid,
 	return invalids, nil
 }
 
-func (t *Tree) upFromSubRoots(wTx db.WriteTx, subRoots [][]byte) ([]byte, error) {
+// upFromSubRoots computes the root from the given roots of the subtrees at the
+// same level, recording the changes of the stats in stats: the intermediate
+// nodes created, and the leafs moved up when they have no sibling.
+func (t *Tree) upFromSubRoots(wTx db.WriteTx, subRoots [][]byte, stats *treeStats) ([]byte, error) {
 	// is a method of Tree just to get access to t.hashFunction and
 	// t.emptyHash.
 
@@ -207,6 +209,8 @@ func (t *Tree) upFromSubRoots(wTx db.WriteTx, subRoots [][]byte) ([]byte, error)
 		nodeTypes[i] = v[0]
 	}
 
+	// level of the subRoots
+	lvl := bits.Len(uint(len(subRoots))) - 1
 	var newSubRoots [][]byte
 	for i := 0; i < len(subRoots); i += 2 {
 		if (bytes.Equal(subRoots[i], t.emptyHash) && bytes.Equal(subRoots[i+1], t.emptyHash)) ||
@@ -215,6 +219,9 @@ func (t *Tree) upFromSubRoots(wTx db.WriteTx, subRoots [][]byte) ([]byte, error)
 			// or
 			// when 1st sub node is a leaf but the 2nd is empty, the
 			// leaf is used as 'parent'
+			if nodeTypes[i] == PrefixValueLeaf {
+				stats.moveLeaf(lvl, lvl-1)
+			}
 
 			newSubRoots = append(newSubRoots, subRoots[i])
 			continue
@@ -222,6 +229,7 @@ func (t *Tree) upFromSubRoots(wTx db.WriteTx, subRoots [][]byte) ([]byte, error)
 		if bytes.Equal(subRoots[i], t.emptyHash) && nodeTypes[i+1] == PrefixValueLeaf {
 			// when 2nd sub node is a leaf but the 1st is empty,
 			// the leaf is used as 'parent'
+			stats.moveLeaf(lvl, lvl-1)
 			newSubRoots = append(newSubRoots, subRoots[i+1])
 			continue
 		}
@@ -234,28 +242,76 @@ func (t *Tree) upFromSubRoots(wTx db.WriteTx, subRoots [][]byte) ([]byte, error)
 		if err = wTx.Set(k, v); err != nil {
 			return nil, err
 		}
+		stats.addIntermediates(1)
 		newSubRoots = append(newSubRoots, k)
 	}
 
-	return t.upFromSubRoots(wTx, newSubRoots)
+	return t.upFromSubRoots(wTx, newSubRoots, stats)
 }
 
-func (t *Tree) getSubRootsAtLevel(rTx db.Reader, root []byte, l int) ([][]byte, error) {
-	// go at level l and return each node key, where each node key is the
-	// subRoot of the subTree that starts there
+// getSubRootsAtLevel returns the keys of the 2^l nodes at level l, which are the
+// roots of the subtrees that start there, ordered from left to right. Empty
+// subtrees are returned as the empty hash. A leaf placed above level l (because
+// it has no siblings down its path) is returned as the root of the subtree that
+// contains its path, as the leaf will end up in that position once other leafs
+// are added to the subtree.
+//
+// The intermediate nodes above level l are removed from stats, as they are
+// recreated by upFromSubRoots, and the leafs placed above level l are moved to
+// it, as they are moved up again by upFromSubRoots if they still have no
+// siblings.
+func (t *Tree) getSubRootsAtLevel(rTx db.Reader, root []byte, l int, stats *treeStats) ([][]byte, error) {
+	subRoots := make([][]byte, 1<<l)
+	for i := range subRoots {
+		subRoots[i] = t.emptyHash
+	}
+	if err := t.fillSubRoots(rTx, root, 0, 0, l, subRoots, stats); err != nil {
+		return nil, err
+	}
+	return subRoots, nil
+}
 
-	var subRoots [][]byte
-	err := t.iterWithStop(rTx, root, 0, func(currLvl int, k, v []byte) bool {
-		if currLvl == l && !bytes.Equal(k, t.emptyHash) {
-			subRoots = append(subRoots, k)
+// fillSubRoots goes down from the node k, placed at level currLvl and position
+// pos (from left to right) of its level, filling the subRoots at level l.
+func (t *Tree) fillSubRoots(rTx db.Reader, k []byte, currLvl, pos, l int, subRoots [][]byte, stats *treeStats) error {
+	if bytes.Equal(k, t.emptyHash) {
+		return nil
+	}
+	if currLvl == l {
+		subRoots[pos] = k
+		return nil
+	}
+	v, err := rTx.Get(k)
+	if err != nil {
+		return err
+	}
+	switch v[0] {
+	case PrefixValueLeaf:
+		leafK, _ := ReadLeafValue(v)
+		keyPath, err := keyPathFromKey(t.maxLevels, leafK)
+		if err != nil {
+			return err
 		}
-		if currLvl >= l {
-			return true // to stop the iter from going down
+		path := getPath(t.maxLevels, keyPath)
+		for lvl := currLvl; lvl < l; lvl++ {
+			pos *= 2
+			if path[lvl] {
+				pos++
+			}
 		}
-		return false
-	})
-
-	return subRoots, err
+		subRoots[pos] = k
+		stats.moveLeaf(currLvl, l)
+		return nil
+	case PrefixValueIntermediate:
+		stats.addIntermediates(-1)
+		lChild, rChild := ReadIntermediateChilds(v)
+		if err := t.fillSubRoots(rTx, lChild, currLvl+1, pos*2, l, subRoots, stats); err != nil {
+			return err
+		}
+		return t.fillSubRoots(rTx, rChild, currLvl+1, pos*2+1, l, subRoots, stats)
+	default:
+		return ErrInvalidValuePrefix
+	}
 }
 
 func (t *Tree) addBatchInMemory(wTx db.WriteTx, keys, values [][]byte) ([]Invalid, error) {
@@ -285,11 +341,17 @@ func (t *Tree) addBatchInMemory(wTx db.WriteTx, keys, values [][]byte) ([]Invali
 		}
 	}
 
-	// store root (from the vt) to db
+	// store root (from the vt) and its stats to db, the vt contains the
+	// whole tree so the stats are computed from it
 	if vt.root != nil {
 		if err := wTx.Set(dbKeyRoot, vt.root.h); err != nil {
 			return nil, err
 		}
+		stats := &treeStats{}
+		vt.root.computeStats(0, stats)
+		if err := t.setStats(wTx, vt.root.h, stats); err != nil {
+			return nil, err
+		}
 	}
 
 	// update nLeafs
@@ -305,6 +367,7 @@ func (t *Tree) addBatchInMemory(wTx db.WriteTx, keys, values [][]byte) ([]Invali
 func (t *Tree) loadVT(rTx db.Reader) (vt, error) {
 	vt := newVT(t.maxLevels, t.hashFunction)
 	vt.params.dbg = t.dbg
+	vt.params.workers = t.batchWorkers
 	var callbackErr error
 	err := t.IterateWithStopWithTx(rTx, nil, func(_ int, k, v []byte) bool {
 		if v[0] != PrefixValueLeaf {
@@ -323,3 +386,46 @@ func (t *Tree) loadVT(rTx db.Reader) (vt, error) {
 
 	return vt, err
 }
+
+// subtreesPerWorker is the number of subtrees assigned on average to each worker
+// by AddBatch. Using more subtrees than workers balances the load when the keys
+// are not evenly distributed.
+const subtreesPerWorker = 4
+
+// batchSubtreeLevel returns the level l at which AddBatch splits the tree into
+// 2^l subtrees, that are built concurrently by the given number of workers.
+// Returns 0 when the tree should not be split, either because there is a single
+// worker or because there are too few keys.
+func batchSubtreeLevel(maxLevels, workers, nKeys int) int {
+	if workers <= 1 {
+		return 0
+	}
+	nSubtrees := flp2(workers * subtreesPerWorker)
+	// keep at least one key per subtree on average
+	for nSubtrees > 1 && nKeys < nSubtrees {
+		nSubtrees /= 2
+	}
+	return min(bits.Len(uint(nSubtrees))-1, maxLevels-1)
+}
+
+// runWorkers calls f for each job in [0, nJobs), distributing the jobs among the
+// given number of concurrent workers. f receives the index of the worker that
+// runs it, in [0, workers), and the job index.
+func runWorkers(workers, nJobs int, f func(worker, job int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, nJobs); w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for job := range jobs {
+				f(worker, job)
+			}
+		}(w)
+	}
+	for job := 0; job < nJobs; job++ {
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+}
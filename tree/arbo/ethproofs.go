@@ -0,0 +1,124 @@
+package arbo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// EthereumVerifySignature is the signature of the function of the Solidity
+// verifier contract (arbo/testvectors/solidity) which checks an
+// EthereumProof.
+const EthereumVerifySignature = "verify(bytes32,bytes,bytes,bytes32[],uint256)"
+
+// EthereumProof contains an arbo proof of existence in the layout expected by
+// the Solidity verifier contracts. It can only be built for trees that use
+// the Keccak256 hash function, which is the one available in the EVM.
+//
+// The contract computes the leaf as keccak256(key ++ value ++ 0x01) and then
+// hashes it with each sibling, from the deepest one to the root. If the bit i
+// of Directions is set, the node is the right child at level i, so it is
+// hashed as keccak256(siblings[i] ++ node), else as keccak256(node ++
+// siblings[i]).
+type EthereumProof struct {
+	Root       [32]byte
+	Key        []byte
+	Value      []byte
+	Siblings   [][32]byte
+	Directions *big.Int
+}
+
+// GenerateEthereumProof generates an EthereumProof of the existence of the
+// given key in the Tree.
+func (t *Tree) GenerateEthereumProof(k []byte) (*EthereumProof, error) {
+	kAux, v, siblings, existence, err := t.GenProof(k)
+	if err != nil {
+		return nil, err
+	}
+	if !existence {
+		return nil, fmt.Errorf("%w: %x", ErrKeyNotFound, kAux)
+	}
+	root, err := t.Root()
+	if err != nil {
+		return nil, err
+	}
+	return NewEthereumProof(t.hashFunction, root, k, v, siblings)
+}
+
+// NewEthereumProof builds an EthereumProof from a proof of existence, with
+// its siblings packed as returned by GenProof.
+func NewEthereumProof(hashFunc HashFunction, root, k, v, packedSiblings []byte) (*EthereumProof, error) {
+	if !bytes.Equal(hashFunc.Type(), TypeHashKeccak256) {
+		return nil, fmt.Errorf("ethereum proofs require the %s hash function, got %s",
+			TypeHashKeccak256, hashFunc.Type())
+	}
+	if len(root) != 32 {
+		return nil, fmt.Errorf("invalid root length %d", len(root))
+	}
+	siblings, err := UnpackSiblings(hashFunc, packedSiblings)
+	if err != nil {
+		return nil, err
+	}
+	if len(siblings) > 256 {
+		return nil, fmt.Errorf("too many siblings %d, the maximum is 256", len(siblings))
+	}
+	p := &EthereumProof{
+		Key:        bytes.Clone(k),
+		Value:      bytes.Clone(v),
+		Siblings:   make([][32]byte, len(siblings)),
+		Directions: new(big.Int),
+	}
+	copy(p.Root[:], root)
+	for i, sibling := range siblings {
+		copy(p.Siblings[i][:], sibling)
+	}
+	for i, right := range getPath(len(siblings), p.Key) {
+		if right {
+			p.Directions.SetBit(p.Directions, i, 1)
+		}
+	}
+	return p, nil
+}
+
+// EncodeABI returns the arguments of the verify function of the Solidity
+// verifier contract, ABI encoded.
+func (p *EthereumProof) EncodeABI() []byte {
+	// the head has five words, the dynamic arguments are appended after it
+	const headLen = 5 * 32
+	key := abiEncodeBytes(p.Key)
+	value := abiEncodeBytes(p.Value)
+	var b []byte
+	b = append(b, p.Root[:]...)
+	b = append(b, abiEncodeUint(uint64(headLen))...)
+	b = append(b, abiEncodeUint(uint64(headLen+len(key)))...)
+	b = append(b, abiEncodeUint(uint64(headLen+len(key)+len(value)))...)
+	b = append(b, p.Directions.FillBytes(make([]byte, 32))...)
+	b = append(b, key...)
+	b = append(b, value...)
+	b = append(b, abiEncodeUint(uint64(len(p.Siblings)))...)
+	for _, sibling := range p.Siblings {
+		b = append(b, sibling[:]...)
+	}
+	return b
+}
+
+// Calldata returns the calldata of a call to the verify function of the
+// Solidity verifier contract with the proof.
+func (p *EthereumProof) Calldata() []byte {
+	selector, _ := HashFunctionKeccak256.Hash([]byte(EthereumVerifySignature))
+	return append(selector[:4], p.EncodeABI()...)
+}
+
+// abiEncodeUint encodes an integer as an ABI word.
+func abiEncodeUint(n uint64) []byte {
+	return binary.BigEndian.AppendUint64(make([]byte, 24), n)
+}
+
+// abiEncodeBytes encodes a dynamic bytes argument: its length followed by its
+// content, right padded to a multiple of the word size.
+func abiEncodeBytes(b []byte) []byte {
+	padded := make([]byte, (len(b)+31)/32*32)
+	copy(padded, b)
+	return append(abiEncodeUint(uint64(len(b))), padded...)
+}
@@ -0,0 +1,126 @@
+package arbo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db/metadb"
+)
+
+var updateEthereumProofs = flag.Bool("update", false, "update the Solidity verifier test vectors")
+
+// ethereumProofsFile contains the proofs checked by the Solidity verifier
+// tests, see testvectors/solidity.
+const ethereumProofsFile = "testvectors/solidity/proofs.json"
+
+type ethereumProofVector struct {
+	Root       string   `json:"root"`
+	Key        string   `json:"key"`
+	Value      string   `json:"value"`
+	Siblings   []string `json:"siblings"`
+	Directions string   `json:"directions"`
+	Calldata   string   `json:"calldata"`
+}
+
+func TestEthereumProof(t *testing.T) {
+	c := qt.New(t)
+	tree, err := NewTree(Config{
+		Database: metadb.NewTest(t), MaxLevels: 160,
+		HashFunction: HashFunctionKeccak256,
+	})
+	c.Assert(err, qt.IsNil)
+
+	// addresses as keys and 32 byte values, like the SIK and census trees
+	var keys [][]byte
+	for i := range 10 {
+		k, err := HashFunctionKeccak256.Hash([]byte{byte(i)})
+		c.Assert(err, qt.IsNil)
+		v, err := HashFunctionKeccak256.Hash(k)
+		c.Assert(err, qt.IsNil)
+		c.Assert(tree.Add(k[:20], v), qt.IsNil)
+		keys = append(keys, k[:20])
+	}
+
+	uint256Type, _ := abi.NewType("uint256", "", nil)
+	bytes32Type, _ := abi.NewType("bytes32", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	bytes32ArrayType, _ := abi.NewType("bytes32[]", "", nil)
+	verifyArgs := abi.Arguments{
+		{Type: bytes32Type}, {Type: bytesType}, {Type: bytesType},
+		{Type: bytes32ArrayType}, {Type: uint256Type},
+	}
+	verifyMethod := abi.NewMethod("verify", "verify", abi.Function, "pure", false, false, verifyArgs, nil)
+
+	vectors := struct {
+		Count  int                   `json:"count"`
+		Proofs []ethereumProofVector `json:"proofs"`
+	}{}
+	for _, k := range keys {
+		p, err := tree.GenerateEthereumProof(k)
+		c.Assert(err, qt.IsNil)
+		c.Assert(verifyEthereumProof(t, p), qt.IsTrue)
+
+		// the encoding matches the one of the go-ethereum ABI package
+		expected, err := verifyArgs.Pack(p.Root, p.Key, p.Value, p.Siblings, p.Directions)
+		c.Assert(err, qt.IsNil)
+		c.Assert(p.EncodeABI(), qt.DeepEquals, expected)
+		calldata := p.Calldata()
+		c.Assert(calldata[:4], qt.DeepEquals, verifyMethod.ID)
+
+		vector := ethereumProofVector{
+			Root:       hex.EncodeToString(p.Root[:]),
+			Key:        hex.EncodeToString(p.Key),
+			Value:      hex.EncodeToString(p.Value),
+			Directions: p.Directions.String(),
+			Calldata:   "0x" + hex.EncodeToString(calldata),
+		}
+		for _, sibling := range p.Siblings {
+			vector.Siblings = append(vector.Siblings, hex.EncodeToString(sibling[:]))
+		}
+		vectors.Proofs = append(vectors.Proofs, vector)
+	}
+	vectors.Count = len(vectors.Proofs)
+
+	// a tampered value does not verify
+	p, err := tree.GenerateEthereumProof(keys[0])
+	c.Assert(err, qt.IsNil)
+	p.Value[0] ^= 1
+	c.Assert(verifyEthereumProof(t, p), qt.IsFalse)
+
+	// the proofs of non existence and of other hash functions are not supported
+	_, err = tree.GenerateEthereumProof(make([]byte, 20))
+	c.Assert(err, qt.ErrorIs, ErrKeyNotFound)
+	_, err = NewEthereumProof(HashFunctionPoseidon, make([]byte, 32), nil, nil, []byte{4, 0, 0, 0})
+	c.Assert(err, qt.IsNotNil)
+
+	got, err := json.MarshalIndent(vectors, "", "  ")
+	c.Assert(err, qt.IsNil)
+	got = append(got, '\n')
+	if *updateEthereumProofs {
+		c.Assert(os.WriteFile(ethereumProofsFile, got, 0o644), qt.IsNil)
+	}
+	want, err := os.ReadFile(ethereumProofsFile)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(got), qt.Equals, string(want))
+}
+
+// verifyEthereumProof checks the proof as the Solidity verifier contract does.
+func verifyEthereumProof(t *testing.T, p *EthereumProof) bool {
+	node, err := HashFunctionKeccak256.Hash(p.Key, p.Value, []byte{1})
+	qt.Assert(t, err, qt.IsNil)
+	for i := len(p.Siblings) - 1; i >= 0; i-- {
+		if p.Directions.Bit(i) == 1 {
+			node, err = HashFunctionKeccak256.Hash(p.Siblings[i][:], node)
+		} else {
+			node, err = HashFunctionKeccak256.Hash(node, p.Siblings[i][:])
+		}
+		qt.Assert(t, err, qt.IsNil)
+	}
+	return bytes.Equal(node, p.Root[:])
+}
@@ -0,0 +1,160 @@
+package arbo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"go.vocdoni.io/dvote/db"
+)
+
+// GraphFormat is the output format of Tree.ExportGraph.
+type GraphFormat string
+
+const (
+	// GraphFormatDOT renders the tree as a Graphviz DOT digraph.
+	GraphFormatDOT GraphFormat = "dot"
+	// GraphFormatJSON renders the tree as a JSON encoded GraphNode.
+	GraphFormatJSON GraphFormat = "json"
+)
+
+// Graph node types, see GraphNode.Type.
+const (
+	GraphNodeEmpty        = "empty"
+	GraphNodeLeaf         = "leaf"
+	GraphNodeIntermediate = "intermediate"
+)
+
+// GraphNode is a node of the view of the tree exported by Tree.ExportGraph.
+// Leaves is the number of leaves of the subtree under the node, which is also
+// computed for the intermediate nodes whose children are not exported because
+// they are at the maximum depth (Truncated).
+type GraphNode struct {
+	Hash      string       `json:"hash"`
+	Type      string       `json:"type"`
+	Level     int          `json:"level"`
+	Leaves    uint64       `json:"leaves"`
+	Key       string       `json:"key,omitempty"`
+	Value     string       `json:"value,omitempty"`
+	Truncated bool         `json:"truncated,omitempty"`
+	Children  []*GraphNode `json:"children,omitempty"`
+}
+
+// ExportGraph writes to w a view of the first maxDepth levels of the tree in
+// the given format, including the number of leaves of each subtree. If
+// maxDepth is 0, the whole tree is exported. Note that the whole tree is
+// iterated to count the leaves, even if the view is truncated.
+func (t *Tree) ExportGraph(w io.Writer, format GraphFormat, maxDepth int) error {
+	return t.ExportGraphWithTx(t.db, w, nil, format, maxDepth)
+}
+
+// ExportGraphWithTx does the same as ExportGraph, but from the given root (or
+// the current one if nil) and using the given db.Reader.
+func (t *Tree) ExportGraphWithTx(rTx db.Reader, w io.Writer, fromRoot []byte,
+	format GraphFormat, maxDepth int,
+) error {
+	if format != GraphFormatDOT && format != GraphFormatJSON {
+		return fmt.Errorf("unknown graph format %q", format)
+	}
+	if fromRoot == nil {
+		var err error
+		if fromRoot, err = t.RootWithTx(rTx); err != nil {
+			return err
+		}
+	}
+	if maxDepth <= 0 {
+		maxDepth = t.maxLevels
+	}
+	root, err := t.graphNode(rTx, fromRoot, 0, maxDepth)
+	if err != nil {
+		return err
+	}
+	if format == GraphFormatJSON {
+		return json.NewEncoder(w).Encode(root)
+	}
+	return writeGraphDOT(w, root)
+}
+
+// graphNode returns the GraphNode of the node k at the level lvl, with its
+// children up to maxDepth.
+func (t *Tree) graphNode(rTx db.Reader, k []byte, lvl, maxDepth int) (*GraphNode, error) {
+	node := &GraphNode{Hash: hex.EncodeToString(k), Type: GraphNodeEmpty, Level: lvl}
+	if bytes.Equal(k, t.emptyHash) {
+		return node, nil
+	}
+	v, err := rTx.Get(k)
+	if err != nil {
+		return nil, err
+	}
+	switch v[0] {
+	case PrefixValueEmpty:
+	case PrefixValueLeaf:
+		kB, vB := ReadLeafValue(v)
+		node.Type, node.Leaves = GraphNodeLeaf, 1
+		node.Key, node.Value = hex.EncodeToString(kB), hex.EncodeToString(vB)
+	case PrefixValueIntermediate:
+		node.Type = GraphNodeIntermediate
+		if lvl >= maxDepth {
+			node.Truncated = true
+			err := t.iter(rTx, k, func(_, v []byte) {
+				if v[0] == PrefixValueLeaf {
+					node.Leaves++
+				}
+			})
+			return node, err
+		}
+		l, r := ReadIntermediateChilds(v)
+		for _, child := range [][]byte{l, r} {
+			childNode, err := t.graphNode(rTx, child, lvl+1, maxDepth)
+			if err != nil {
+				return nil, err
+			}
+			node.Leaves += childNode.Leaves
+			node.Children = append(node.Children, childNode)
+		}
+	default:
+		return nil, ErrInvalidValuePrefix
+	}
+	return node, nil
+}
+
+// writeGraphDOT writes the GraphNode tree as a Graphviz DOT digraph. The nodes
+// are identified by their position, since the empty nodes share the same hash.
+func writeGraphDOT(w io.Writer, root *GraphNode) error {
+	firstChars := func(s string) string {
+		if len(s) > nChars*2 {
+			return s[:nChars*2]
+		}
+		return s
+	}
+	b := bytes.NewBufferString("digraph hierarchy {\nnode [fontname=Monospace,fontsize=10,shape=box]\n")
+	id := 0
+	var write func(node *GraphNode) int
+	write = func(node *GraphNode) int {
+		nodeID := id
+		id++
+		switch {
+		case node.Type == GraphNodeEmpty:
+			fmt.Fprintf(b, "n%d [style=dashed,label=0];\n", nodeID)
+		case node.Type == GraphNodeLeaf:
+			fmt.Fprintf(b, "n%d [style=filled,label=\"%s\\nk:%s\\nv:%s\"];\n",
+				nodeID, firstChars(node.Hash), firstChars(node.Key), firstChars(node.Value))
+		case node.Truncated:
+			fmt.Fprintf(b, "n%d [style=dotted,label=\"%s\\nlvl %d\\nleaves %d\\n...\"];\n",
+				nodeID, firstChars(node.Hash), node.Level, node.Leaves)
+		default:
+			fmt.Fprintf(b, "n%d [label=\"%s\\nlvl %d\\nleaves %d\"];\n",
+				nodeID, firstChars(node.Hash), node.Level, node.Leaves)
+		}
+		for _, child := range node.Children {
+			fmt.Fprintf(b, "n%d -> n%d;\n", nodeID, write(child))
+		}
+		return nodeID
+	}
+	write(root)
+	b.WriteString("}\n")
+	_, err := b.WriteTo(w)
+	return err
+}
@@ -0,0 +1,78 @@
+package arbo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db/metadb"
+)
+
+func TestExportGraph(t *testing.T) {
+	c := qt.New(t)
+	tree, err := NewTree(Config{
+		Database: metadb.NewTest(t), MaxLevels: 256,
+		HashFunction: HashFunctionBlake2b,
+	})
+	c.Assert(err, qt.IsNil)
+
+	bLen := 32
+	for i := 0; i < 10; i++ {
+		k := BigIntToBytesLE(bLen, big.NewInt(int64(i)))
+		v := BigIntToBytesLE(bLen, big.NewInt(int64(i*2)))
+		c.Assert(tree.Add(k, v), qt.IsNil)
+	}
+
+	// countLeaves returns the leaf nodes exported under node
+	var countLeaves func(node *GraphNode) uint64
+	countLeaves = func(node *GraphNode) uint64 {
+		if node.Type == GraphNodeLeaf {
+			return 1
+		}
+		n := uint64(0)
+		for _, child := range node.Children {
+			n += countLeaves(child)
+		}
+		return n
+	}
+
+	// the whole tree
+	b := new(bytes.Buffer)
+	c.Assert(tree.ExportGraph(b, GraphFormatJSON, 0), qt.IsNil)
+	root := &GraphNode{}
+	c.Assert(json.Unmarshal(b.Bytes(), root), qt.IsNil)
+	rootHash, err := tree.Root()
+	c.Assert(err, qt.IsNil)
+	c.Assert(root.Hash, qt.Equals, hex.EncodeToString(rootHash))
+	c.Assert(root.Type, qt.Equals, GraphNodeIntermediate)
+	c.Assert(root.Leaves, qt.Equals, uint64(10))
+	c.Assert(countLeaves(root), qt.Equals, uint64(10))
+
+	// the first level, with the leaves of the truncated subtrees counted
+	b.Reset()
+	c.Assert(tree.ExportGraph(b, GraphFormatJSON, 1), qt.IsNil)
+	root = &GraphNode{}
+	c.Assert(json.Unmarshal(b.Bytes(), root), qt.IsNil)
+	c.Assert(root.Leaves, qt.Equals, uint64(10))
+	c.Assert(root.Children, qt.HasLen, 2)
+	leaves := uint64(0)
+	for _, child := range root.Children {
+		c.Assert(child.Level, qt.Equals, 1)
+		c.Assert(child.Children, qt.HasLen, 0)
+		c.Assert(child.Truncated, qt.Equals, child.Type == GraphNodeIntermediate)
+		leaves += child.Leaves
+	}
+	c.Assert(leaves, qt.Equals, uint64(10))
+
+	b.Reset()
+	c.Assert(tree.ExportGraph(b, GraphFormatDOT, 1), qt.IsNil)
+	c.Assert(strings.HasPrefix(b.String(), "digraph hierarchy {"), qt.IsTrue)
+	c.Assert(b.String(), qt.Contains, `lvl 0\nleaves 10"`)
+	c.Assert(b.String(), qt.Contains, "n0 -> n1;")
+
+	c.Assert(tree.ExportGraph(b, "svg", 1), qt.ErrorMatches, `unknown graph format "svg"`)
+}
@@ -2,10 +2,13 @@ package arbo
 
 import (
 	"crypto/sha256"
+	"fmt"
 	"math/big"
+	"sync"
 
 	"github.com/iden3/go-iden3-crypto/poseidon"
 	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
 	"lukechampine.com/blake3"
 )
 
@@ -19,6 +22,9 @@ var (
 	TypeHashBlake2b = []byte("blake2b")
 	// TypeHashBlake3 represents the label for the HashFunction of Blake3
 	TypeHashBlake3 = []byte("blake3")
+	// TypeHashKeccak256 represents the label for the HashFunction of
+	// Keccak256
+	TypeHashKeccak256 = []byte("keccak256")
 
 	// HashFunctionSha256 contains the HashSha256 struct which implements
 	// the HashFunction interface
@@ -32,6 +38,23 @@ var (
 	// HashFunctionBlake3 contains the HashBlake3 struct which implements
 	// the HashFunction interface
 	HashFunctionBlake3 HashBlake3
+	// HashFunctionKeccak256 contains the HashKeccak256 struct which
+	// implements the HashFunction interface
+	HashFunctionKeccak256 HashKeccak256
+
+	// ErrHashFunctionNotFound is used when a hash function type is not
+	// found in the registry.
+	ErrHashFunctionNotFound = fmt.Errorf("hash function not found")
+
+	hashFunctionsLock sync.RWMutex
+	// hashFunctions is the registry of the hash functions by their type
+	hashFunctions = map[string]HashFunction{
+		string(TypeHashSha256):    HashFunctionSha256,
+		string(TypeHashPoseidon):  HashFunctionPoseidon,
+		string(TypeHashBlake2b):   HashFunctionBlake2b,
+		string(TypeHashBlake3):    HashFunctionBlake3,
+		string(TypeHashKeccak256): HashFunctionKeccak256,
+	}
 )
 
 // Once Generics are at Go, this will be updated (August 2021
@@ -150,3 +173,57 @@ func (HashBlake3) Hash(b ...[]byte) ([]byte, error) {
 	}
 	return hasher.Sum(nil), nil
 }
+
+// HashKeccak256 implements the HashFunction interface for the Keccak256 hash,
+// as used by the EVM, so the proofs can be verified by smart contracts.
+type HashKeccak256 struct{}
+
+// Type returns the type of HashFunction for the HashKeccak256
+func (HashKeccak256) Type() []byte {
+	return TypeHashKeccak256
+}
+
+// Len returns the length of the Hash output
+func (HashKeccak256) Len() int {
+	return 32
+}
+
+// Hash implements the hash method for the HashFunction HashKeccak256
+func (HashKeccak256) Hash(b ...[]byte) ([]byte, error) {
+	hasher := sha3.NewLegacyKeccak256()
+	for i := 0; i < len(b); i++ {
+		if _, err := hasher.Write(b[i]); err != nil {
+			return nil, err
+		}
+	}
+	return hasher.Sum(nil), nil
+}
+
+// RegisterHashFunction adds a HashFunction to the registry, identified by its
+// Type, so it can be selected with HashFunctionByType and the trees that use
+// it can be loaded without specifying it. The builtin hash functions are
+// already registered. It returns an error if the type is already registered.
+func RegisterHashFunction(hashFunction HashFunction) error {
+	if hashFunction == nil || len(hashFunction.Type()) == 0 {
+		return fmt.Errorf("hash function type cannot be empty")
+	}
+	hashFunctionsLock.Lock()
+	defer hashFunctionsLock.Unlock()
+	typ := string(hashFunction.Type())
+	if _, ok := hashFunctions[typ]; ok {
+		return fmt.Errorf("hash function %q already registered", typ)
+	}
+	hashFunctions[typ] = hashFunction
+	return nil
+}
+
+// HashFunctionByType returns the registered HashFunction with the given type.
+func HashFunctionByType(typ []byte) (HashFunction, error) {
+	hashFunctionsLock.RLock()
+	defer hashFunctionsLock.RUnlock()
+	hashFunction, ok := hashFunctions[string(typ)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrHashFunctionNotFound, typ)
+	}
+	return hashFunction, nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db/metadb"
 )
 
 func TestHashSha256(t *testing.T) {
@@ -53,3 +54,70 @@ func TestHashBlake2b(t *testing.T) {
 		qt.Equals,
 		"928b20366943e2afd11ebc0eae2e53a93bf177a4fcf35bcc64d503704e65e202")
 }
+
+func TestHashKeccak256(t *testing.T) {
+	// Keccak256 hash
+	hashFunc := &HashKeccak256{}
+	h, err := hashFunc.Hash([]byte("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := qt.New(t)
+	// value checked with the EVM keccak256
+	c.Assert(hex.EncodeToString(h),
+		qt.Equals,
+		"9c22ff5f21f0b81b113e63f7db6da94fedef11b2119b4088b89664fb9a3cb658")
+}
+
+// hashTest is a custom hash function registered by TestHashFunctionRegistry
+type hashTest struct {
+	HashSha256
+}
+
+func (hashTest) Type() []byte {
+	return []byte("test")
+}
+
+func TestHashFunctionRegistry(t *testing.T) {
+	c := qt.New(t)
+
+	// builtin hash functions are registered
+	for _, hashFunc := range []HashFunction{HashFunctionSha256, HashFunctionPoseidon,
+		HashFunctionBlake2b, HashFunctionBlake3, HashFunctionKeccak256} {
+		registered, err := HashFunctionByType(hashFunc.Type())
+		c.Assert(err, qt.IsNil)
+		c.Assert(registered, qt.Equals, hashFunc)
+	}
+	c.Assert(RegisterHashFunction(HashFunctionKeccak256), qt.IsNotNil)
+
+	// register a custom hash function
+	_, err := HashFunctionByType([]byte("test"))
+	c.Assert(err, qt.ErrorIs, ErrHashFunctionNotFound)
+	c.Assert(RegisterHashFunction(hashTest{}), qt.IsNil)
+	registered, err := HashFunctionByType([]byte("test"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(registered, qt.Equals, HashFunction(hashTest{}))
+
+	// the tree stores its hash function type, so it can be loaded without it
+	database := metadb.NewTest(t)
+	tree, err := NewTree(Config{Database: database, MaxLevels: 256, HashFunction: hashTest{}})
+	c.Assert(err, qt.IsNil)
+	c.Assert(tree.Add([]byte{1}, []byte{2}), qt.IsNil)
+	root, err := tree.Root()
+	c.Assert(err, qt.IsNil)
+
+	tree, err = NewTree(Config{Database: database, MaxLevels: 256})
+	c.Assert(err, qt.IsNil)
+	c.Assert(tree.HashFunction(), qt.Equals, HashFunction(hashTest{}))
+	root2, err := tree.Root()
+	c.Assert(err, qt.IsNil)
+	c.Assert(root2, qt.DeepEquals, root)
+
+	// a different hash function cannot be used
+	_, err = NewTree(Config{Database: database, MaxLevels: 256, HashFunction: HashFunctionKeccak256})
+	c.Assert(err, qt.ErrorMatches, `tree hash function is "test", not "keccak256"`)
+
+	// a new tree needs a hash function
+	_, err = NewTree(Config{Database: metadb.NewTest(t), MaxLevels: 256})
+	c.Assert(err, qt.IsNotNil)
+}
@@ -0,0 +1,482 @@
+package arbo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.vocdoni.io/dvote/db"
+)
+
+// InterchangeVersion is the version of the interchange format written by
+// Interchange.
+const InterchangeVersion = 1
+
+// ErrInterchangeRootMismatch is used when the root of the leaves imported from
+// an Interchange does not match its root.
+var ErrInterchangeRootMismatch = fmt.Errorf("interchange root mismatch")
+
+// InterchangeFormat is the encoding of an Interchange.
+type InterchangeFormat int
+
+const (
+	// InterchangeJSON encodes the Interchange as a JSON object, with the byte
+	// fields as 0x-prefixed hex strings:
+	//
+	//	{
+	//	  "version": 1,
+	//	  "hashFunction": "poseidon",
+	//	  "levels": 160,
+	//	  "root": "0x...",
+	//	  "leaves": [{"key": "0x...", "value": "0x..."}, ...]
+	//	}
+	InterchangeJSON InterchangeFormat = iota
+	// InterchangeCBOR encodes the Interchange as a CBOR (RFC 8949) map with
+	// the same fields, using deterministic encoding: definite lengths, byte
+	// strings for the byte fields and the map keys sorted by their length and
+	// then bytewise ("root", "leaves", "levels", "version", "hashFunction";
+	// "key", "value").
+	InterchangeCBOR
+)
+
+// Interchange is the content of a Tree in a portable format, so that the tree
+// can be verified or reconstructed by other implementations of the sparse
+// merkle tree from https://docs.iden3.io/publications/pdfs/Merkle-Tree.pdf,
+// such as the iden3 and circomlib ones.
+//
+// HashFunction is the type of the hash function of the Tree (see
+// HashFunctionByType), and Levels its maximum number of levels. The keys,
+// values and root are the bytes used by the Tree: the path of a leaf is given
+// by the bits of its key in little-endian order, and its hash is
+// H(key|value|1). For the Poseidon hash function, they are the little-endian
+// encoding of the field elements, as in iden3 (see BytesLEToBigInt). The leaves
+// are listed in the order of their paths.
+type Interchange struct {
+	Version      int               `json:"version"`
+	HashFunction string            `json:"hashFunction"`
+	Levels       int               `json:"levels"`
+	Root         InterchangeBytes  `json:"root"`
+	Leaves       []InterchangeLeaf `json:"leaves"`
+}
+
+// InterchangeLeaf is a leaf of an Interchange.
+type InterchangeLeaf struct {
+	Key   InterchangeBytes `json:"key"`
+	Value InterchangeBytes `json:"value"`
+}
+
+// InterchangeBytes is a byte array encoded in JSON as a 0x-prefixed hex
+// string. The prefix is optional when decoding.
+type InterchangeBytes []byte
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (b InterchangeBytes) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(b)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (b *InterchangeBytes) UnmarshalText(text []byte) error {
+	s := strings.TrimPrefix(string(text), "0x")
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid hex %q: %w", text, err)
+	}
+	*b = decoded
+	return nil
+}
+
+// Interchange exports all the Tree leafs in the interchange format. The
+// provided root must be a valid existing intermediate node in the tree. Or nil
+// to use the current root.
+func (t *Tree) Interchange(fromRoot []byte) (*Interchange, error) {
+	if fromRoot == nil {
+		var err error
+		fromRoot, err = t.Root()
+		if err != nil {
+			return nil, err
+		}
+	}
+	ic := &Interchange{
+		Version:      InterchangeVersion,
+		HashFunction: string(t.hashFunction.Type()),
+		Levels:       t.maxLevels,
+		Root:         fromRoot,
+		Leaves:       []InterchangeLeaf{},
+	}
+	err := t.IterateWithStop(fromRoot, func(_ int, _, v []byte) bool {
+		if v[0] != PrefixValueLeaf {
+			return false
+		}
+		leafK, leafV := ReadLeafValue(v)
+		ic.Leaves = append(ic.Leaves, InterchangeLeaf{Key: leafK, Value: leafV})
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ic, nil
+}
+
+// ExportInterchangeWriter exports all the Tree leafs in the interchange format,
+// writing them in the given Writer with the given encoding. The provided root
+// must be a valid existing intermediate node in the tree. Or nil to use the
+// current root.
+func (t *Tree) ExportInterchangeWriter(fromRoot []byte, w io.Writer, format InterchangeFormat) error {
+	ic, err := t.Interchange(fromRoot)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case InterchangeJSON:
+		return json.NewEncoder(w).Encode(ic)
+	case InterchangeCBOR:
+		b, err := ic.MarshalCBOR()
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	default:
+		return fmt.Errorf("unknown interchange format %d", format)
+	}
+}
+
+// ImportInterchangeReader imports the leafs of an Interchange in the Tree,
+// reading it from the given reader with the given encoding, see
+// ImportInterchange.
+func (t *Tree) ImportInterchangeReader(r io.Reader, format InterchangeFormat) error {
+	ic := &Interchange{}
+	switch format {
+	case InterchangeJSON:
+		if err := json.NewDecoder(r).Decode(ic); err != nil {
+			return fmt.Errorf("cannot decode interchange: %w", err)
+		}
+	case InterchangeCBOR:
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if err := ic.UnmarshalCBOR(b); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown interchange format %d", format)
+	}
+	return t.ImportInterchange(ic)
+}
+
+// ImportInterchange imports the leafs of an Interchange in the Tree, which must
+// be empty and have the same hash function and levels. The root of the Tree is
+// checked against the root of the Interchange before committing the last
+// writes, returning ErrInterchangeRootMismatch if they differ. As in
+// ImportDumpReader, the writes are committed in batches, so the Tree must be
+// discarded if the import fails.
+func (t *Tree) ImportInterchange(ic *Interchange) error {
+	if !t.editable() {
+		return ErrSnapshotNotEditable
+	}
+	if ic.Version != InterchangeVersion {
+		return fmt.Errorf("unsupported interchange version %d", ic.Version)
+	}
+	if ic.HashFunction != string(t.hashFunction.Type()) {
+		return fmt.Errorf("interchange hash function %q does not match the tree one %q",
+			ic.HashFunction, t.hashFunction.Type())
+	}
+	if ic.Levels != t.maxLevels {
+		return fmt.Errorf("interchange levels %d do not match the tree max levels %d", ic.Levels, t.maxLevels)
+	}
+
+	wTx := db.NewBatchWriteTx(t.db, 0, 0)
+	defer wTx.Discard()
+
+	root, err := wTx.Get(dbKeyRoot)
+	if err == db.ErrKeyNotFound {
+		if err := t.setToEmptyTree(wTx); err != nil {
+			return fmt.Errorf("could not set empty tree: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("unknown database error: %w", err)
+	} else if !bytes.Equal(root, t.emptyHash) {
+		return ErrTreeNotEmpty
+	}
+
+	keys := make([][]byte, len(ic.Leaves))
+	values := make([][]byte, len(ic.Leaves))
+	for i, leaf := range ic.Leaves {
+		keys[i] = leaf.Key
+		values[i] = leaf.Value
+	}
+	invalid, err := t.AddBatchWithTx(wTx, keys, values)
+	if err != nil {
+		return fmt.Errorf("error adding batch: %w", err)
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%d invalid keys found in interchange", len(invalid))
+	}
+	if root, err = t.RootWithTx(wTx); err != nil {
+		return err
+	}
+	if !bytes.Equal(root, ic.Root) {
+		return fmt.Errorf("%w: expected %x, got %x", ErrInterchangeRootMismatch, []byte(ic.Root), root)
+	}
+	return wTx.Commit()
+}
+
+// CBOR major types used by the interchange format.
+const (
+	cborUint  = 0
+	cborBytes = 2
+	cborText  = 3
+	cborArray = 4
+	cborMap   = 5
+	cborTag   = 6
+)
+
+// MarshalCBOR encodes the Interchange in the CBOR format, see InterchangeCBOR.
+func (ic *Interchange) MarshalCBOR() ([]byte, error) {
+	if ic.Version < 0 || ic.Levels < 0 {
+		return nil, fmt.Errorf("invalid interchange version %d or levels %d", ic.Version, ic.Levels)
+	}
+	var b []byte
+	b = cborAppendHead(b, cborMap, 5)
+	b = cborAppendString(b, cborText, []byte("root"))
+	b = cborAppendString(b, cborBytes, ic.Root)
+	b = cborAppendString(b, cborText, []byte("leaves"))
+	b = cborAppendHead(b, cborArray, uint64(len(ic.Leaves)))
+	for _, leaf := range ic.Leaves {
+		b = cborAppendHead(b, cborMap, 2)
+		b = cborAppendString(b, cborText, []byte("key"))
+		b = cborAppendString(b, cborBytes, leaf.Key)
+		b = cborAppendString(b, cborText, []byte("value"))
+		b = cborAppendString(b, cborBytes, leaf.Value)
+	}
+	b = cborAppendString(b, cborText, []byte("levels"))
+	b = cborAppendHead(b, cborUint, uint64(ic.Levels))
+	b = cborAppendString(b, cborText, []byte("version"))
+	b = cborAppendHead(b, cborUint, uint64(ic.Version))
+	b = cborAppendString(b, cborText, []byte("hashFunction"))
+	b = cborAppendString(b, cborText, []byte(ic.HashFunction))
+	return b, nil
+}
+
+// UnmarshalCBOR decodes an Interchange encoded in the CBOR format, see
+// InterchangeCBOR. The map keys may be in any order, and the unknown ones are
+// ignored.
+func (ic *Interchange) UnmarshalCBOR(data []byte) error {
+	d := &cborDecoder{data: data}
+	if err := d.decodeInterchange(ic); err != nil {
+		return fmt.Errorf("cannot decode interchange: %w", err)
+	}
+	if d.pos != len(d.data) {
+		return fmt.Errorf("cannot decode interchange: %d trailing bytes", len(d.data)-d.pos)
+	}
+	return nil
+}
+
+// cborAppendHead appends the head of a CBOR data item with the major type and
+// argument, in its shortest form.
+func cborAppendHead(b []byte, major byte, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return append(b, major|byte(arg))
+	case arg <= uint64(maxUint8):
+		return append(b, major|24, byte(arg))
+	case arg <= uint64(maxUint16):
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(arg))
+	case arg <= uint64(^uint32(0)):
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), arg)
+	}
+}
+
+// cborAppendString appends a CBOR byte or text string.
+func cborAppendString(b []byte, major byte, s []byte) []byte {
+	return append(cborAppendHead(b, major, uint64(len(s))), s...)
+}
+
+// cborDecoder decodes the subset of CBOR used by the interchange format.
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// head reads the head of a data item, returning its major type and argument.
+// Indefinite lengths are not supported.
+func (d *cborDecoder) head() (byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	major, info := d.data[d.pos]>>5, d.data[d.pos]&0x1f
+	d.pos++
+	if info < 24 {
+		return major, uint64(info), nil
+	}
+	if info > 27 {
+		return 0, 0, fmt.Errorf("unsupported additional information %d", info)
+	}
+	n := 1 << (info - 24)
+	if len(d.data)-d.pos < n {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	var arg uint64
+	for _, c := range d.data[d.pos : d.pos+n] {
+		arg = arg<<8 | uint64(c)
+	}
+	d.pos += n
+	return major, arg, nil
+}
+
+// expect reads the head of a data item of the given major type, returning its
+// argument.
+func (d *cborDecoder) expect(major byte) (uint64, error) {
+	m, arg, err := d.head()
+	if err != nil {
+		return 0, err
+	}
+	if m != major {
+		return 0, fmt.Errorf("unexpected major type %d, expected %d", m, major)
+	}
+	return arg, nil
+}
+
+// length reads the head of a data item of the given major type, returning its
+// length, which is checked against the remaining data as every element takes
+// at least one byte.
+func (d *cborDecoder) length(major byte) (int, error) {
+	arg, err := d.expect(major)
+	if err != nil {
+		return 0, err
+	}
+	if arg > uint64(len(d.data)-d.pos) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return int(arg), nil
+}
+
+// string reads a byte or text string.
+func (d *cborDecoder) string(major byte) ([]byte, error) {
+	n, err := d.length(major)
+	if err != nil {
+		return nil, err
+	}
+	s := bytes.Clone(d.data[d.pos : d.pos+n])
+	d.pos += n
+	return s, nil
+}
+
+// int reads an unsigned integer which fits in an int.
+func (d *cborDecoder) int() (int, error) {
+	arg, err := d.expect(cborUint)
+	if err != nil {
+		return 0, err
+	}
+	if arg > uint64(int(^uint(0)>>1)) {
+		return 0, fmt.Errorf("integer %d overflows", arg)
+	}
+	return int(arg), nil
+}
+
+// skip skips a data item.
+func (d *cborDecoder) skip() error {
+	major, arg, err := d.head()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case cborBytes, cborText:
+		if arg > uint64(len(d.data)-d.pos) {
+			return io.ErrUnexpectedEOF
+		}
+		d.pos += int(arg)
+	case cborArray, cborMap:
+		items := arg
+		if major == cborMap {
+			items *= 2
+		}
+		if items > uint64(len(d.data)-d.pos) {
+			return io.ErrUnexpectedEOF
+		}
+		for i := uint64(0); i < items; i++ {
+			if err := d.skip(); err != nil {
+				return err
+			}
+		}
+	case cborTag:
+		// the tag is followed by the tagged item
+		return d.skip()
+	}
+	return nil
+}
+
+// decodeInterchange decodes the map of an Interchange.
+func (d *cborDecoder) decodeInterchange(ic *Interchange) error {
+	n, err := d.length(cborMap)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		key, err := d.string(cborText)
+		if err != nil {
+			return err
+		}
+		switch string(key) {
+		case "version":
+			ic.Version, err = d.int()
+		case "hashFunction":
+			var s []byte
+			s, err = d.string(cborText)
+			ic.HashFunction = string(s)
+		case "levels":
+			ic.Levels, err = d.int()
+		case "root":
+			ic.Root, err = d.string(cborBytes)
+		case "leaves":
+			ic.Leaves, err = d.decodeLeaves()
+		default:
+			err = d.skip()
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// decodeLeaves decodes the array of leaves of an Interchange.
+func (d *cborDecoder) decodeLeaves() ([]InterchangeLeaf, error) {
+	n, err := d.length(cborArray)
+	if err != nil {
+		return nil, err
+	}
+	leaves := make([]InterchangeLeaf, n)
+	for i := range leaves {
+		fields, err := d.length(cborMap)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j < fields; j++ {
+			key, err := d.string(cborText)
+			if err != nil {
+				return nil, err
+			}
+			switch string(key) {
+			case "key":
+				leaves[i].Key, err = d.string(cborBytes)
+			case "value":
+				leaves[i].Value, err = d.string(cborBytes)
+			default:
+				err = d.skip()
+			}
+			if err != nil {
+				return nil, fmt.Errorf("leaf %d %s: %w", i, key, err)
+			}
+		}
+	}
+	return leaves, nil
+}
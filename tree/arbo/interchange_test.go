@@ -0,0 +1,116 @@
+package arbo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db/metadb"
+)
+
+func TestInterchange(t *testing.T) {
+	c := qt.New(t)
+	tree1, err := NewTree(Config{
+		Database: metadb.NewTest(t), MaxLevels: 256,
+		HashFunction: HashFunctionPoseidon,
+	})
+	c.Assert(err, qt.IsNil)
+
+	bLen := 32
+	for i := 0; i < 16; i++ {
+		k := BigIntToBytesLE(bLen, big.NewInt(int64(i)))
+		v := BigIntToBytesLE(bLen, big.NewInt(int64(i*2)))
+		c.Assert(tree1.Add(k, v), qt.IsNil)
+	}
+	ic, err := tree1.Interchange(nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(ic.HashFunction, qt.Equals, "poseidon")
+	c.Assert(ic.Levels, qt.Equals, 256)
+	c.Assert(ic.Leaves, qt.HasLen, 16)
+	c.Assert(hex.EncodeToString(ic.Root), qt.Equals,
+		"0d93aaa3362b2f999f15e15728f123087c2eee716f01c01f56e23aae07f09f08")
+
+	for _, format := range []InterchangeFormat{InterchangeJSON, InterchangeCBOR} {
+		var buf bytes.Buffer
+		c.Assert(tree1.ExportInterchangeWriter(nil, &buf, format), qt.IsNil)
+
+		tree2, err := NewTree(Config{
+			Database: metadb.NewTest(t), MaxLevels: 256,
+			HashFunction: HashFunctionPoseidon,
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(tree2.ImportInterchangeReader(&buf, format), qt.IsNil)
+		root2, err := tree2.Root()
+		c.Assert(err, qt.IsNil)
+		c.Assert(root2, qt.DeepEquals, []byte(ic.Root))
+
+		// the tree is not empty anymore
+		c.Assert(tree2.ImportInterchange(ic), qt.ErrorIs, ErrTreeNotEmpty)
+	}
+
+	// a leaf which does not match the root is detected
+	tampered := *ic
+	tampered.Leaves = append([]InterchangeLeaf{}, ic.Leaves...)
+	tampered.Leaves[3].Value = BigIntToBytesLE(bLen, big.NewInt(1000))
+	tree3, err := NewTree(Config{
+		Database: metadb.NewTest(t), MaxLevels: 256,
+		HashFunction: HashFunctionPoseidon,
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(tree3.ImportInterchange(&tampered), qt.ErrorIs, ErrInterchangeRootMismatch)
+
+	// the hash function and levels must match
+	tree4, err := NewTree(Config{
+		Database: metadb.NewTest(t), MaxLevels: 256,
+		HashFunction: HashFunctionBlake2b,
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(tree4.ImportInterchange(ic), qt.ErrorMatches, "interchange hash function .*")
+	tree5, err := NewTree(Config{
+		Database: metadb.NewTest(t), MaxLevels: 64,
+		HashFunction: HashFunctionPoseidon,
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(tree5.ImportInterchange(ic), qt.ErrorMatches, "interchange levels .*")
+}
+
+func TestInterchangeEncoding(t *testing.T) {
+	c := qt.New(t)
+	ic := &Interchange{
+		Version:      InterchangeVersion,
+		HashFunction: "sha256",
+		Levels:       4,
+		Root:         []byte{0x01},
+		Leaves:       []InterchangeLeaf{{Key: []byte{0x02}, Value: []byte{0x03}}},
+	}
+
+	b, err := json.Marshal(ic)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"version":1,"hashFunction":"sha256","levels":4,`+
+		`"root":"0x01","leaves":[{"key":"0x02","value":"0x03"}]}`)
+
+	b, err = ic.MarshalCBOR()
+	c.Assert(err, qt.IsNil)
+	c.Assert(hex.EncodeToString(b), qt.Equals, "a5"+
+		"64726f6f74"+"4101"+ // "root": h'01'
+		"666c6561766573"+"81"+ // "leaves": [
+		"a2"+"636b6579"+"4102"+"6576616c7565"+"4103"+ // {"key": h'02', "value": h'03'}
+		"666c6576656c73"+"04"+ // "levels": 4
+		"6776657273696f6e"+"01"+ // "version": 1
+		"6c6861736846756e6374696f6e"+"66736861323536") // "hashFunction": "sha256"
+
+	decoded := &Interchange{}
+	c.Assert(decoded.UnmarshalCBOR(b), qt.IsNil)
+	c.Assert(decoded, qt.DeepEquals, ic)
+
+	// unknown fields are ignored, truncated data is rejected
+	unknown := append([]byte{0xa6, 0x63, 'f', 'o', 'o', 0x82, 0x01, 0x41, 0xff}, b[1:]...)
+	decoded = &Interchange{}
+	c.Assert(decoded.UnmarshalCBOR(unknown), qt.IsNil)
+	c.Assert(decoded, qt.DeepEquals, ic)
+	c.Assert(decoded.UnmarshalCBOR(b[:len(b)-1]), qt.IsNotNil)
+	c.Assert(decoded.UnmarshalCBOR(append(b, 0x00)), qt.ErrorMatches, ".*trailing bytes")
+}
@@ -0,0 +1,200 @@
+package arbo
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// LeafField is a field of the values defined by a LeafLayout.
+type LeafField struct {
+	Name string
+	// Len is the length in bytes of the field, which is fixed.
+	Len int
+}
+
+// LeafLayout defines the fields of structured leaf values, such as the weight
+// of a voter together with its registration time and some flags. The values
+// are encoded as the concatenation of their fields, in the order of the
+// layout, each one with its fixed length, so the consumers of the proofs (such
+// as the circuits or the smart contracts) can read any field at its offset
+// without parsing the value. The numbers are encoded in Little-Endian, like
+// BigIntLE does.
+//
+// A LeafLayout is a Codec of LeafValue, so it can be used as the values codec
+// of a TypedTree.
+type LeafLayout struct {
+	fields  []LeafField
+	offsets map[string]int
+	len     int
+}
+
+// NewLeafLayout returns the layout of the values with the given fields, whose
+// names must be unique. The encoded values cannot be longer than the max value
+// length of a leaf.
+func NewLeafLayout(fields ...LeafField) (*LeafLayout, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("the leaf layout has no fields")
+	}
+	l := &LeafLayout{
+		fields:  append([]LeafField(nil), fields...),
+		offsets: make(map[string]int, len(fields)),
+	}
+	for i, f := range fields {
+		if f.Name == "" {
+			return nil, fmt.Errorf("field %d has no name", i)
+		}
+		if f.Len <= 0 {
+			return nil, fmt.Errorf("field %s has invalid length %d", f.Name, f.Len)
+		}
+		if _, ok := l.offsets[f.Name]; ok {
+			return nil, fmt.Errorf("duplicated field %s", f.Name)
+		}
+		l.offsets[f.Name] = i
+		l.len += f.Len
+	}
+	if l.len > maxUint16 {
+		return nil, fmt.Errorf("leaf values of %d bytes, can not be bigger than %d", l.len, maxUint16)
+	}
+	return l, nil
+}
+
+// Len returns the length in bytes of the encoded values.
+func (l *LeafLayout) Len() int {
+	return l.len
+}
+
+// Fields returns the fields of the layout, in the order they are encoded.
+func (l *LeafLayout) Fields() []LeafField {
+	return append([]LeafField(nil), l.fields...)
+}
+
+// Offset returns the position of the field in the encoded values, and its
+// length.
+func (l *LeafLayout) Offset(name string) (int, int, error) {
+	i, ok := l.offsets[name]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown field %s", name)
+	}
+	offset := 0
+	for _, f := range l.fields[:i] {
+		offset += f.Len
+	}
+	return offset, l.fields[i].Len, nil
+}
+
+// NewValue returns a value of the layout, with all its fields set to zero.
+func (l *LeafLayout) NewValue() *LeafValue {
+	return &LeafValue{layout: l, b: make([]byte, l.len)}
+}
+
+// Encode implements Codec, the value must be of the layout.
+func (l *LeafLayout) Encode(v *LeafValue) ([]byte, error) {
+	if v == nil || v.layout != l {
+		return nil, fmt.Errorf("the value is not of the leaf layout")
+	}
+	return append([]byte(nil), v.b...), nil
+}
+
+// Decode implements Codec.
+func (l *LeafLayout) Decode(b []byte) (*LeafValue, error) {
+	if len(b) != l.len {
+		return nil, fmt.Errorf("invalid length %d, expected %d", len(b), l.len)
+	}
+	return &LeafValue{layout: l, b: append([]byte(nil), b...)}, nil
+}
+
+// LeafValue is a structured leaf value, with the fields of its LeafLayout.
+type LeafValue struct {
+	layout *LeafLayout
+	b      []byte
+}
+
+// field returns the bytes of the field, which alias the value.
+func (v *LeafValue) field(name string) ([]byte, error) {
+	offset, flen, err := v.layout.Offset(name)
+	if err != nil {
+		return nil, err
+	}
+	return v.b[offset : offset+flen], nil
+}
+
+// Field returns the bytes of the field.
+func (v *LeafValue) Field(name string) ([]byte, error) {
+	b, err := v.field(name)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), b...), nil
+}
+
+// SetField sets the bytes of the field, which must have its length.
+func (v *LeafValue) SetField(name string, b []byte) error {
+	dst, err := v.field(name)
+	if err != nil {
+		return err
+	}
+	if len(b) != len(dst) {
+		return fmt.Errorf("invalid length %d of field %s, expected %d", len(b), name, len(dst))
+	}
+	copy(dst, b)
+	return nil
+}
+
+// BigInt returns the field as a number.
+func (v *LeafValue) BigInt(name string) (*big.Int, error) {
+	b, err := v.field(name)
+	if err != nil {
+		return nil, err
+	}
+	return BytesLEToBigInt(b), nil
+}
+
+// SetBigInt sets the field to the non-negative number, which must fit in it.
+func (v *LeafValue) SetBigInt(name string, n *big.Int) error {
+	dst, err := v.field(name)
+	if err != nil {
+		return err
+	}
+	b, err := BigIntLE(len(dst)).Encode(n)
+	if err != nil {
+		return fmt.Errorf("field %s: %w", name, err)
+	}
+	copy(dst, b)
+	return nil
+}
+
+// Uint64 returns the field as a number, which must fit in a uint64.
+func (v *LeafValue) Uint64(name string) (uint64, error) {
+	n, err := v.BigInt(name)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsUint64() {
+		return 0, fmt.Errorf("field %s does not fit in a uint64", name)
+	}
+	return n.Uint64(), nil
+}
+
+// SetUint64 sets the field to the number, which must fit in it.
+func (v *LeafValue) SetUint64(name string, n uint64) error {
+	return v.SetBigInt(name, new(big.Int).SetUint64(n))
+}
+
+// Bool returns whether the field is not zero, which is how the flags are
+// encoded.
+func (v *LeafValue) Bool(name string) (bool, error) {
+	n, err := v.BigInt(name)
+	if err != nil {
+		return false, err
+	}
+	return n.Sign() != 0, nil
+}
+
+// SetBool sets the field to one if b is true, or to zero otherwise.
+func (v *LeafValue) SetBool(name string, b bool) error {
+	n := uint64(0)
+	if b {
+		n = 1
+	}
+	return v.SetUint64(name, n)
+}
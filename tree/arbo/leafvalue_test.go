@@ -0,0 +1,125 @@
+package arbo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db/metadb"
+)
+
+func TestLeafLayout(t *testing.T) {
+	c := qt.New(t)
+	layout, err := NewLeafLayout(
+		LeafField{Name: "weight", Len: 32},
+		LeafField{Name: "registered", Len: 8},
+		LeafField{Name: "delegated", Len: 1},
+	)
+	c.Assert(err, qt.IsNil)
+	c.Assert(layout.Len(), qt.Equals, 41)
+	offset, flen, err := layout.Offset("registered")
+	c.Assert(err, qt.IsNil)
+	c.Assert([]int{offset, flen}, qt.DeepEquals, []int{32, 8})
+
+	_, err = NewLeafLayout(LeafField{Name: "a", Len: 1}, LeafField{Name: "a", Len: 2})
+	c.Assert(err, qt.ErrorMatches, "duplicated field a")
+	_, err = NewLeafLayout(LeafField{Name: "a", Len: 0})
+	c.Assert(err, qt.ErrorMatches, ".*invalid length 0")
+	_, err = NewLeafLayout(LeafField{Name: "a", Len: maxUint16}, LeafField{Name: "b", Len: 1})
+	c.Assert(err, qt.ErrorMatches, ".*can not be bigger than 65535")
+
+	v := layout.NewValue()
+	c.Assert(v.SetBigInt("weight", big.NewInt(1000)), qt.IsNil)
+	c.Assert(v.SetUint64("registered", 1700000000), qt.IsNil)
+	c.Assert(v.SetBool("delegated", true), qt.IsNil)
+	c.Assert(v.SetUint64("delegated", 256), qt.ErrorMatches, "field delegated: cannot encode 256 in 1 bytes")
+	c.Assert(v.SetField("registered", []byte{1}), qt.ErrorMatches, ".*expected 8")
+	c.Assert(v.SetUint64("unknown", 1), qt.ErrorMatches, "unknown field unknown")
+
+	// the fields are encoded at their offsets
+	b, err := layout.Encode(v)
+	c.Assert(err, qt.IsNil)
+	c.Assert(b, qt.HasLen, 41)
+	c.Assert(b[:32], qt.DeepEquals, BigIntToBytesLE(32, big.NewInt(1000)))
+	c.Assert(b[40], qt.Equals, byte(1))
+
+	decoded, err := layout.Decode(b)
+	c.Assert(err, qt.IsNil)
+	registered, err := decoded.Uint64("registered")
+	c.Assert(err, qt.IsNil)
+	c.Assert(registered, qt.Equals, uint64(1700000000))
+	delegated, err := decoded.Bool("delegated")
+	c.Assert(err, qt.IsNil)
+	c.Assert(delegated, qt.IsTrue)
+	_, err = layout.Decode(b[:40])
+	c.Assert(err, qt.ErrorMatches, "invalid length 40, expected 41")
+
+	other, err := NewLeafLayout(LeafField{Name: "weight", Len: 32})
+	c.Assert(err, qt.IsNil)
+	_, err = other.Encode(v)
+	c.Assert(err, qt.ErrorMatches, ".*not of the leaf layout")
+}
+
+func TestLeafLayoutTree(t *testing.T) {
+	c := qt.New(t)
+	layout, err := NewLeafLayout(
+		LeafField{Name: "weight", Len: 8},
+		LeafField{Name: "registered", Len: 8},
+	)
+	c.Assert(err, qt.IsNil)
+	_, err = NewTree(Config{
+		Database: metadb.NewTest(t), MaxLevels: 160,
+		HashFunction: HashFunctionBlake2b, MaxValueLen: maxUint16 + 1,
+	})
+	c.Assert(err, qt.ErrorMatches, "max value length .* out of bounds.*")
+	tree, err := NewTree(Config{
+		Database: metadb.NewTest(t), MaxLevels: 160,
+		HashFunction: HashFunctionBlake2b, MaxValueLen: layout.Len(),
+	})
+	c.Assert(err, qt.IsNil)
+	census := NewTypedTree(tree, Address, Codec[*LeafValue](layout))
+
+	var addrs []common.Address
+	var values []*LeafValue
+	for i := range 4 {
+		addrs = append(addrs, common.BigToAddress(big.NewInt(int64(i+1))))
+		v := layout.NewValue()
+		c.Assert(v.SetUint64("weight", uint64(i+1)), qt.IsNil)
+		c.Assert(v.SetUint64("registered", uint64(1000+i)), qt.IsNil)
+		values = append(values, v)
+	}
+	invalids, err := census.AddBatch(addrs, values)
+	c.Assert(err, qt.IsNil)
+	c.Assert(invalids, qt.HasLen, 0)
+
+	v, siblings, exists, err := census.GenProof(addrs[2])
+	c.Assert(err, qt.IsNil)
+	c.Assert(exists, qt.IsTrue)
+	weight, err := v.Uint64("weight")
+	c.Assert(err, qt.IsNil)
+	c.Assert(weight, qt.Equals, uint64(3))
+	root, err := census.Root()
+	c.Assert(err, qt.IsNil)
+	valid, err := census.CheckProof(addrs[2], v, root, siblings)
+	c.Assert(err, qt.IsNil)
+	c.Assert(valid, qt.IsTrue)
+
+	// the values longer than the max value length are rejected
+	long := make([]byte, layout.Len()+1)
+	c.Assert(tree.Add([]byte{0xff}, long), qt.ErrorIs, ErrValueTooLong)
+	c.Assert(tree.Update(addrs[0].Bytes(), long), qt.ErrorIs, ErrValueTooLong)
+	invalids, err = tree.AddBatch(
+		[][]byte{{0xf0}, {0xf1}, {0xf2}, addrs[0].Bytes()},
+		[][]byte{{1}, long, {2}, {3}},
+	)
+	c.Assert(err, qt.IsNil)
+	c.Assert(invalids, qt.HasLen, 2)
+	c.Assert(invalids[0].Index, qt.Equals, 1)
+	c.Assert(invalids[0].Error, qt.ErrorIs, ErrValueTooLong)
+	c.Assert(invalids[1].Index, qt.Equals, 3)
+	c.Assert(invalids[1].Error, qt.ErrorMatches, "key already exists.*")
+	nLeafs, err := tree.GetNLeafs()
+	c.Assert(err, qt.IsNil)
+	c.Assert(nLeafs, qt.Equals, 6)
+}
@@ -0,0 +1,265 @@
+package arbo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"slices"
+
+	"go.vocdoni.io/dvote/db"
+)
+
+// GenMultiProof generates a single MerkleTree proof of existence for all the
+// given keys, which is smaller than their separate proofs since the siblings
+// shared by several keys, or computable from the leaves of other keys, are only
+// included once or not at all. The leaf values are returned in the same order
+// as the keys, together with the packed proof. All the keys must exist in the
+// tree.
+func (t *Tree) GenMultiProof(keys [][]byte) ([][]byte, []byte, error) {
+	return t.GenMultiProofWithTx(t.db, keys)
+}
+
+// GenMultiProofWithTx does the same than the GenMultiProof method, but allowing
+// to pass the db.ReadTx that is used.
+func (t *Tree) GenMultiProofWithTx(rTx db.Reader, keys [][]byte) ([][]byte, []byte, error) {
+	if len(keys) == 0 {
+		return nil, nil, fmt.Errorf("no keys provided")
+	}
+	root, err := t.RootWithTx(rTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make([][]byte, len(keys))
+	leaves := make([]multiProofLeaf, len(keys))
+	keySiblings := make([][][]byte, len(keys))
+	for i, k := range keys {
+		keyPath, err := keyPathFromKey(t.maxLevels, k)
+		if err != nil {
+			return nil, nil, err
+		}
+		path := getPath(t.maxLevels, keyPath)
+		var siblings, intermediates [][]byte
+		leafKey, value, siblings, err := t.down(rTx, k, root, siblings, &intermediates, path, 0, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		leafK, leafV := ReadLeafValue(value)
+		if !bytes.Equal(k, leafK) {
+			return nil, nil, fmt.Errorf("%w: %x", ErrKeyNotFound, k)
+		}
+		values[i] = leafV
+		keySiblings[i] = siblings
+		leaves[i] = multiProofLeaf{hash: leafKey, path: path[:len(siblings)]}
+	}
+
+	var proofSiblings [][]byte
+	if _, err := walkMultiProof(t.hashFunction, leaves, func(level, leaf int) ([]byte, error) {
+		sibling := keySiblings[leaf][level-1]
+		proofSiblings = append(proofSiblings, sibling)
+		return sibling, nil
+	}); err != nil {
+		return nil, nil, err
+	}
+	return values, packMultiProof(t.hashFunction, leaves, proofSiblings), nil
+}
+
+// CheckMultiProof verifies the given proof generated by GenMultiProof for the
+// keys and values. The proof verification depends on the HashFunction passed
+// as parameter.
+func CheckMultiProof(hashFunc HashFunction, keys, values [][]byte, root, proof []byte) (bool, error) {
+	if len(keys) == 0 || len(keys) != len(values) {
+		return false, fmt.Errorf("expected the same number of keys and values, got %d and %d",
+			len(keys), len(values))
+	}
+	depths, siblings, err := unpackMultiProof(hashFunc, proof)
+	if err != nil {
+		return false, err
+	}
+	if len(depths) != len(keys) {
+		return false, fmt.Errorf("the proof is for %d keys, got %d", len(depths), len(keys))
+	}
+	leaves := make([]multiProofLeaf, len(keys))
+	for i, k := range keys {
+		keyPath := make([]byte, (depths[i]+7)/8)
+		copy(keyPath, k)
+		leafKey, _, err := newLeafValue(hashFunc, k, values[i])
+		if err != nil {
+			return false, err
+		}
+		leaves[i] = multiProofLeaf{hash: leafKey, path: getPath(depths[i], keyPath)}
+	}
+	next := 0
+	computedRoot, err := walkMultiProof(hashFunc, leaves, func(_, _ int) ([]byte, error) {
+		if next == len(siblings) {
+			return nil, fmt.Errorf("not enough siblings in the proof")
+		}
+		next++
+		return siblings[next-1], nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if next != len(siblings) {
+		return false, fmt.Errorf("%d unused siblings in the proof", len(siblings)-next)
+	}
+	return bytes.Equal(computedRoot, root), nil
+}
+
+// multiProofLeaf is a leaf of a multiproof, with its path up to its depth in
+// the tree.
+type multiProofLeaf struct {
+	hash []byte
+	path []bool
+}
+
+// multiProofNode is a node computed while walking a multiproof. leaf is the
+// index of one of the leaves below it.
+type multiProofNode struct {
+	hash   []byte
+	leaf   int
+	paired bool
+}
+
+// walkMultiProof computes the root of the tree from the leaves, taking from the
+// sibling function the siblings which cannot be computed from other leaves. They
+// are requested in a deterministic order, from the deepest level up and, within
+// a level, by the path of the node, so that the generation and the verification
+// of a multiproof agree on it. The sibling function receives the level of the
+// node whose sibling is requested, and the index of one of the leaves below it.
+func walkMultiProof(hashFunc HashFunction, leaves []multiProofLeaf,
+	sibling func(level, leaf int) ([]byte, error),
+) ([]byte, error) {
+	maxDepth := 0
+	for _, l := range leaves {
+		maxDepth = max(maxDepth, len(l.path))
+	}
+	// the nodes of each level, keyed by their path encoded as a string
+	levels := make([]map[string]*multiProofNode, maxDepth+1)
+	for i := range levels {
+		levels[i] = make(map[string]*multiProofNode)
+	}
+	for i, l := range leaves {
+		p := pathString(l.path)
+		if _, ok := levels[len(l.path)][p]; ok {
+			return nil, fmt.Errorf("duplicated leaf at level %d", len(l.path))
+		}
+		levels[len(l.path)][p] = &multiProofNode{hash: l.hash, leaf: i}
+	}
+	for level := maxDepth; level > 0; level-- {
+		nodes := levels[level]
+		for _, p := range slices.Sorted(maps.Keys(nodes)) {
+			node := nodes[p]
+			if node.paired {
+				continue
+			}
+			var siblingHash []byte
+			siblingPath := p[:level-1] + string([]byte{p[level-1] ^ 1})
+			if s, ok := nodes[siblingPath]; ok {
+				s.paired = true
+				siblingHash = s.hash
+			} else {
+				var err error
+				if siblingHash, err = sibling(level, node.leaf); err != nil {
+					return nil, err
+				}
+			}
+			l, r := node.hash, siblingHash
+			if p[level-1] == 1 {
+				l, r = r, l
+			}
+			hash, _, err := newIntermediate(hashFunc, l, r)
+			if err != nil {
+				return nil, err
+			}
+			parentPath := p[:level-1]
+			if _, ok := levels[level-1][parentPath]; ok {
+				return nil, fmt.Errorf("leaf below another leaf at level %d", level-1)
+			}
+			levels[level-1][parentPath] = &multiProofNode{hash: hash, leaf: node.leaf}
+		}
+	}
+	return levels[0][""].hash, nil
+}
+
+// pathString encodes a path as a string with a byte per level, 0 or 1.
+func pathString(path []bool) string {
+	b := make([]byte, len(path))
+	for i, bit := range path {
+		if bit {
+			b[i] = 1
+		}
+	}
+	return string(b)
+}
+
+// packMultiProof packs the depths of the leaves and the siblings of a
+// multiproof into a byte array.
+// [ 4 bytes |   2 * N bytes    | 4 bytes | ceil(M/8) bytes |    S * Z bytes     ]
+// [    N    | depths of leaves |    M    |      bitmap     | Z non-zero siblings ]
+// Where M is the number of siblings, the bitmap indicates if each sibling is 0
+// or a value from the siblings array, as in PackSiblings, and S is the size of
+// the output of the hash function used for the Tree. All the integers are
+// encoded in little-endian.
+func packMultiProof(hashFunc HashFunction, leaves []multiProofLeaf, siblings [][]byte) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, uint32(len(leaves)))
+	for _, l := range leaves {
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(l.path)))
+	}
+	bitmap := make([]bool, len(siblings))
+	emptySibling := make([]byte, hashFunc.Len())
+	var nonEmpty []byte
+	for i, s := range siblings {
+		if !bytes.Equal(s, emptySibling) {
+			bitmap[i] = true
+			nonEmpty = append(nonEmpty, s...)
+		}
+	}
+	bitmapBytes := bitmapToBytes(bitmap)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(siblings)))
+	b = append(b, bitmapBytes...)
+	return append(b, nonEmpty...)
+}
+
+// unpackMultiProof unpacks the depths of the leaves and the siblings of a
+// multiproof from a byte array.
+func unpackMultiProof(hashFunc HashFunction, b []byte) ([]int, [][]byte, error) {
+	if len(b) < 4 {
+		return nil, nil, fmt.Errorf("no packed multiproof provided")
+	}
+	n := int(binary.LittleEndian.Uint32(b[0:4]))
+	b = b[4:]
+	if len(b) < 2*n+4 {
+		return nil, nil, fmt.Errorf("expected at least len: %d, current len: %d", 2*n+4, len(b))
+	}
+	depths := make([]int, n)
+	for i := range depths {
+		depths[i] = int(binary.LittleEndian.Uint16(b[2*i:]))
+	}
+	b = b[2*n:]
+	nSiblings := int(binary.LittleEndian.Uint32(b[0:4]))
+	b = b[4:]
+	bitmapLen := (nSiblings + 7) / 8
+	if len(b) < bitmapLen {
+		return nil, nil, fmt.Errorf("expected at least len: %d, current len: %d", bitmapLen, len(b))
+	}
+	bitmap := bytesToBitmap(b[:bitmapLen])
+	siblingsBytes := b[bitmapLen:]
+	emptySibling := make([]byte, hashFunc.Len())
+	siblings := make([][]byte, nSiblings)
+	for i := range siblings {
+		if !bitmap[i] {
+			siblings[i] = emptySibling
+			continue
+		}
+		if len(siblingsBytes) < hashFunc.Len() {
+			return nil, nil, fmt.Errorf("bad formated siblings")
+		}
+		siblings[i] = siblingsBytes[:hashFunc.Len()]
+		siblingsBytes = siblingsBytes[hashFunc.Len():]
+	}
+	if len(siblingsBytes) != 0 {
+		return nil, nil, fmt.Errorf("bad formated siblings")
+	}
+	return depths, siblings, nil
+}
@@ -185,10 +185,13 @@ func ReadIntermediateChilds(b []byte) ([]byte, []byte) {
 func getPath(numLevels int, k []byte) []bool {
 	requiredLen := (numLevels + 7) / 8 // Calculate the ceil value of numLevels/8
 
-	// If the provided key is shorter than expected, extend it with zero bytes
+	// If the provided key is shorter than expected, extend a copy of it with
+	// zero bytes, as appending to k could overwrite the bytes which follow it
+	// in its backing array (e.g. the value of a leaf read by ReadLeafValue)
 	if len(k) < requiredLen {
-		padding := make([]byte, requiredLen-len(k))
-		k = append(k, padding...)
+		padded := make([]byte, requiredLen)
+		copy(padded, k)
+		k = padded
 	}
 
 	path := make([]bool, numLevels)
@@ -30,6 +30,10 @@ func (t *Tree) GenProofWithTx(rTx db.Reader, k []byte) ([]byte, []byte, []byte,
 	if err != nil {
 		return nil, nil, nil, false, err
 	}
+	cache := t.proofCache.Load()
+	if proof, ok := cache.get(root, k); ok {
+		return bytes.Clone(proof.leafK), bytes.Clone(proof.leafV), bytes.Clone(proof.siblings), proof.existence, nil
+	}
 
 	// go down to the leaf
 	var siblings, intermediates [][]byte
@@ -45,12 +49,15 @@ func (t *Tree) GenProofWithTx(rTx db.Reader, k []byte) ([]byte, []byte, []byte,
 	}
 
 	leafK, leafV := ReadLeafValue(value)
-	if !bytes.Equal(k, leafK) {
-		// key not in tree, proof of non-existence
-		return leafK, leafV, s, false, nil
-	}
-
-	return leafK, leafV, s, true, nil
+	// if the key is not in tree, it is a proof of non-existence
+	existence := bytes.Equal(k, leafK)
+	cache.add(root, k, cachedProof{
+		leafK:     bytes.Clone(leafK),
+		leafV:     bytes.Clone(leafV),
+		siblings:  bytes.Clone(s),
+		existence: existence,
+	})
+	return leafK, leafV, s, existence, nil
 }
 
 // PackSiblings packs the siblings into a byte array.
@@ -0,0 +1,71 @@
+package arbo
+
+import (
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// cachedProof is a proof generated by GenProofWithTx, stored in the proof
+// cache of the Tree.
+type cachedProof struct {
+	leafK     []byte
+	leafV     []byte
+	siblings  []byte
+	existence bool
+}
+
+// proofCache caches the proofs generated by the Tree, keyed by root and key.
+// Since the root is the hash of the whole Tree, the proof of a key under a
+// root never changes, so the entries do not need to be invalidated when the
+// Tree is updated: the ones of the old roots are evicted as the new ones are
+// added.
+type proofCache struct {
+	cache *lru.Cache[string, cachedProof]
+}
+
+// newProofCache returns a proofCache holding up to size proofs, or nil if size
+// is zero or negative, which disables the cache.
+func newProofCache(size int) (*proofCache, error) {
+	if size <= 0 {
+		return nil, nil
+	}
+	cache, err := lru.New[string, cachedProof](size)
+	if err != nil {
+		return nil, err
+	}
+	return &proofCache{cache: cache}, nil
+}
+
+// SetProofCacheSize enables a cache of up to size proofs generated by GenProof,
+// keyed by root and key, so that the proofs of the hot keys under a root which
+// does not change (such as a published census) are generated only once. If
+// size is zero or negative the cache is disabled. The cached proofs are
+// discarded.
+func (t *Tree) SetProofCacheSize(size int) error {
+	cache, err := newProofCache(size)
+	if err != nil {
+		return err
+	}
+	t.proofCache.Store(cache)
+	return nil
+}
+
+func proofCacheKey(root, k []byte) string {
+	return string(root) + string(k)
+}
+
+// get returns the proof of k under root, if it is cached. A nil proofCache
+// has no proofs.
+func (c *proofCache) get(root, k []byte) (cachedProof, bool) {
+	if c == nil {
+		return cachedProof{}, false
+	}
+	return c.cache.Get(proofCacheKey(root, k))
+}
+
+// add stores the proof of k under root. A nil proofCache does nothing.
+func (c *proofCache) add(root, k []byte, proof cachedProof) {
+	if c == nil {
+		return
+	}
+	c.cache.Add(proofCacheKey(root, k), proof)
+}
@@ -0,0 +1,232 @@
+package arbo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"go.vocdoni.io/dvote/db"
+)
+
+// dbKeyStats is the db key of the stats of the tree, see treeStats.
+var dbKeyStats = []byte("arbo/stats/")
+
+// Stats contains the statistics of the shape of a Tree, which allow to detect
+// unbalanced trees, for example due to an adversarially crafted set of keys.
+type Stats struct {
+	// Leaves is the number of leaves of the tree.
+	Leaves int
+	// Nodes is the number of non empty nodes of the tree, including the
+	// leaves and the intermediate nodes.
+	Nodes int
+	// MaxDepth is the depth of the deepest leaf, the root being at depth 0.
+	MaxDepth int
+	// AvgDepth is the average depth of the leaves.
+	AvgDepth float64
+	// DepthHistogram is the number of leaves at each depth, up to MaxDepth.
+	DepthHistogram []int
+	// StorageBytes is the size of the keys and values of the nodes of the
+	// tree in the db, without the metadata nor the orphan nodes.
+	StorageBytes int
+}
+
+// Stats returns the statistics of the Tree.
+//
+// The statistics are maintained incrementally by the methods which modify the
+// Tree, so that they are obtained without iterating the tree. If they are not
+// available, because the tree is a snapshot of an old root, its root was set
+// with SetRoot or it was created before the statistics were maintained, they
+// are computed iterating the whole tree.
+func (t *Tree) Stats() (*Stats, error) {
+	return t.StatsWithTx(t.db)
+}
+
+// StatsWithTx does the same than the Stats method, but allowing to pass the
+// db.ReadTx that is used.
+func (t *Tree) StatsWithTx(rTx db.Reader) (*Stats, error) {
+	root, err := t.RootWithTx(rTx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := t.storedStats(rTx, root)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &treeStats{}
+		if err := t.computeStats(rTx, root, 0, s); err != nil {
+			return nil, err
+		}
+	}
+	return s.export(t.hashFunction.Len()), nil
+}
+
+// treeStats are the statistics of a tree from which Stats is computed, which
+// are stored in the db along with the root they belong to. The same type is
+// used for the changes of the statistics made by an operation, so its fields
+// can be negative. A nil *treeStats ignores the changes.
+type treeStats struct {
+	intermediates int
+	leafBytes     int
+	// depths is the number of leaves at each depth
+	depths []int
+}
+
+func (s *treeStats) addLeaf(depth, size int) {
+	if s == nil {
+		return
+	}
+	s.addDepth(depth, 1)
+	s.leafBytes += size
+}
+
+func (s *treeStats) removeLeaf(depth, size int) {
+	if s == nil {
+		return
+	}
+	s.addDepth(depth, -1)
+	s.leafBytes -= size
+}
+
+// moveLeaf records that a leaf has been moved to another depth.
+func (s *treeStats) moveLeaf(from, to int) {
+	if s == nil || from == to {
+		return
+	}
+	s.addDepth(from, -1)
+	s.addDepth(to, 1)
+}
+
+func (s *treeStats) addIntermediates(n int) {
+	if s == nil {
+		return
+	}
+	s.intermediates += n
+}
+
+func (s *treeStats) addDepth(depth, n int) {
+	for len(s.depths) <= depth {
+		s.depths = append(s.depths, 0)
+	}
+	s.depths[depth] += n
+}
+
+// add adds the changes of s2 to s.
+func (s *treeStats) add(s2 *treeStats) {
+	if s == nil || s2 == nil {
+		return
+	}
+	s.intermediates += s2.intermediates
+	s.leafBytes += s2.leafBytes
+	for depth, n := range s2.depths {
+		s.addDepth(depth, n)
+	}
+}
+
+// export returns the Stats, given the length of the hashes of the tree.
+func (s *treeStats) export(hashLen int) *Stats {
+	stats := &Stats{
+		Nodes: s.intermediates,
+		// an intermediate node is stored as its hash and the hashes of
+		// its children, prefixed by PrefixValueLen bytes
+		StorageBytes:   s.leafBytes + s.intermediates*(3*hashLen+PrefixValueLen),
+		DepthHistogram: []int{},
+	}
+	sumDepths := 0
+	for depth, n := range s.depths {
+		if n == 0 {
+			continue
+		}
+		stats.Leaves += n
+		stats.MaxDepth = depth
+		sumDepths += depth * n
+	}
+	if stats.Leaves > 0 {
+		stats.AvgDepth = float64(sumDepths) / float64(stats.Leaves)
+		stats.DepthHistogram = append(stats.DepthHistogram, s.depths[:stats.MaxDepth+1]...)
+	}
+	stats.Nodes += stats.Leaves
+	return stats
+}
+
+// computeStats adds to s the statistics of the subtree under the node k, which
+// is at the given depth, iterating all its nodes.
+func (t *Tree) computeStats(rTx db.Reader, k []byte, depth int, s *treeStats) error {
+	if bytes.Equal(k, t.emptyHash) {
+		return nil
+	}
+	v, err := rTx.Get(k)
+	if err != nil {
+		return err
+	}
+	switch v[0] {
+	case PrefixValueEmpty:
+		return nil
+	case PrefixValueLeaf:
+		s.addLeaf(depth, len(k)+len(v))
+		return nil
+	case PrefixValueIntermediate:
+		s.addIntermediates(1)
+		l, r := ReadIntermediateChilds(v)
+		if err := t.computeStats(rTx, l, depth+1, s); err != nil {
+			return err
+		}
+		return t.computeStats(rTx, r, depth+1, s)
+	default:
+		return ErrInvalidValuePrefix
+	}
+}
+
+// storedStats returns the statistics stored in the db if they belong to the
+// given root, or nil otherwise.
+func (*Tree) storedStats(rTx db.Reader, root []byte) (*treeStats, error) {
+	b, err := rTx.Get(dbKeyStats)
+	if err == db.ErrKeyNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 || len(b) < 1+int(b[0]) {
+		return nil, fmt.Errorf("invalid stored stats")
+	}
+	if !bytes.Equal(b[1:1+b[0]], root) {
+		return nil, nil
+	}
+	r := bytes.NewReader(b[1+b[0]:])
+	values := []int{}
+	for r.Len() > 0 {
+		n, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stored stats: %w", err)
+		}
+		values = append(values, int(n))
+	}
+	if len(values) < 2 {
+		return nil, fmt.Errorf("invalid stored stats")
+	}
+	return &treeStats{intermediates: values[0], leafBytes: values[1], depths: values[2:]}, nil
+}
+
+// setStats stores the statistics of the tree with the given root.
+func (*Tree) setStats(wTx db.WriteTx, root []byte, s *treeStats) error {
+	b := append([]byte{byte(len(root))}, root...)
+	b = binary.AppendVarint(b, int64(s.intermediates))
+	b = binary.AppendVarint(b, int64(s.leafBytes))
+	for _, n := range s.depths {
+		b = binary.AppendVarint(b, int64(n))
+	}
+	return wTx.Set(dbKeyStats, b)
+}
+
+// updateStats adds the changes of an operation, which modified the root of the
+// tree from oldRoot to newRoot, to the stored statistics. If the stored
+// statistics do not belong to oldRoot, they are left as they are, so they will
+// be computed by Stats.
+func (t *Tree) updateStats(wTx db.WriteTx, oldRoot, newRoot []byte, changes *treeStats) error {
+	s, err := t.storedStats(wTx, oldRoot)
+	if err != nil || s == nil {
+		return err
+	}
+	s.add(changes)
+	return t.setStats(wTx, newRoot, s)
+}
@@ -0,0 +1,99 @@
+package arbo
+
+import (
+	"math/big"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db/metadb"
+)
+
+func TestStats(t *testing.T) {
+	c := qt.New(t)
+	database := metadb.NewTest(t)
+	tree, err := NewTree(Config{
+		Database: database, MaxLevels: 256,
+		HashFunction: HashFunctionBlake2b,
+		// use the in-disk AddBatch once the tree has a few leaves
+		ThresholdNLeafs: 100,
+	})
+	c.Assert(err, qt.IsNil)
+	tree.batchWorkers = 4
+
+	// checkStats checks the stats maintained by the tree against the ones
+	// computed iterating it
+	checkStats := func(leaves int) *Stats {
+		root, err := tree.Root()
+		c.Assert(err, qt.IsNil)
+		stored, err := tree.storedStats(database, root)
+		c.Assert(err, qt.IsNil)
+		c.Assert(stored, qt.IsNotNil)
+		computed := &treeStats{}
+		c.Assert(tree.computeStats(database, root, 0, computed), qt.IsNil)
+		stats, err := tree.Stats()
+		c.Assert(err, qt.IsNil)
+		c.Assert(stats, qt.DeepEquals, computed.export(tree.hashFunction.Len()))
+		c.Assert(stats.Leaves, qt.Equals, leaves)
+		return stats
+	}
+
+	stats := checkStats(0)
+	c.Assert(stats.Nodes, qt.Equals, 0)
+	c.Assert(stats.StorageBytes, qt.Equals, 0)
+
+	bLen := 32
+	kv := func(i, j int) ([]byte, []byte) {
+		return BigIntToBytesLE(bLen, big.NewInt(int64(i))), BigIntToBytesLE(bLen, big.NewInt(int64(j)))
+	}
+	for i := 0; i < 50; i++ {
+		c.Assert(tree.Add(kv(i, i)), qt.IsNil)
+	}
+	stats = checkStats(50)
+	c.Assert(stats.Nodes > stats.Leaves, qt.IsTrue)
+	c.Assert(stats.MaxDepth > 0, qt.IsTrue)
+	c.Assert(stats.AvgDepth <= float64(stats.MaxDepth), qt.IsTrue)
+	c.Assert(stats.DepthHistogram, qt.HasLen, stats.MaxDepth+1)
+
+	// in memory and in disk batches
+	for _, n := range []int{100, 1000} {
+		var keys, values [][]byte
+		for i := 0; i < n; i++ {
+			k, v := kv(1000+n+i, i)
+			keys, values = append(keys, k), append(values, v)
+		}
+		invalids, err := tree.AddBatch(keys, values)
+		c.Assert(err, qt.IsNil)
+		c.Assert(invalids, qt.HasLen, 0)
+	}
+	checkStats(1150)
+
+	for i := 0; i < 50; i++ {
+		k, _ := kv(i, 0)
+		c.Assert(tree.Update(k, make([]byte, i)), qt.IsNil)
+	}
+	checkStats(1150)
+
+	for i := 0; i < 50; i++ {
+		k, _ := kv(i, 0)
+		c.Assert(tree.Delete(k), qt.IsNil)
+	}
+	checkStats(1100)
+
+	// without stored stats, as the trees created before they were
+	// maintained, they are computed iterating the tree
+	wTx := database.WriteTx()
+	c.Assert(wTx.Delete(dbKeyStats), qt.IsNil)
+	c.Assert(wTx.Commit(), qt.IsNil)
+	c.Assert(tree.Add(kv(1, 1)), qt.IsNil)
+	root, err := tree.Root()
+	c.Assert(err, qt.IsNil)
+	stored, err := tree.storedStats(database, root)
+	c.Assert(err, qt.IsNil)
+	c.Assert(stored, qt.IsNil)
+	computed := &treeStats{}
+	c.Assert(tree.computeStats(database, root, 0, computed), qt.IsNil)
+	stats, err = tree.Stats()
+	c.Assert(err, qt.IsNil)
+	c.Assert(stats, qt.DeepEquals, computed.export(tree.hashFunction.Len()))
+	c.Assert(stats.Leaves, qt.Equals, 1101)
+}
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: GPL-3.0
+pragma solidity ^0.8.20;
+
+/// @title ArboVerifier
+/// @notice Verifies the proofs of existence of arbo trees which use the
+/// Keccak256 hash function, encoded by arbo.EthereumProof.
+contract ArboVerifier {
+    /// @notice Returns whether the key and value are a leaf of the tree with
+    /// the given root.
+    /// @param siblings the siblings of the leaf, from the root level down.
+    /// @param directions bit i is set if the node at level i is a right child.
+    function verify(
+        bytes32 root,
+        bytes memory key,
+        bytes memory value,
+        bytes32[] memory siblings,
+        uint256 directions
+    ) public pure returns (bool) {
+        bytes32 node = keccak256(abi.encodePacked(key, value, bytes1(0x01)));
+        for (uint256 i = siblings.length; i > 0; i--) {
+            if ((directions >> (i - 1)) & 1 == 1) {
+                node = keccak256(abi.encodePacked(siblings[i - 1], node));
+            } else {
+                node = keccak256(abi.encodePacked(node, siblings[i - 1]));
+            }
+        }
+        return node == root;
+    }
+}
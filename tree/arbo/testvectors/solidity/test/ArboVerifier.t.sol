@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: GPL-3.0
+pragma solidity ^0.8.20;
+
+import {Test} from "forge-std/Test.sol";
+import {ArboVerifier} from "../src/ArboVerifier.sol";
+
+/// Checks the proofs generated by the go tests of arbo (TestEthereumProof),
+/// calling the verifier with their calldata as is.
+contract ArboVerifierTest is Test {
+    function testProofs() public {
+        ArboVerifier verifier = new ArboVerifier();
+        string memory json = vm.readFile("./proofs.json");
+        uint256 n = vm.parseJsonUint(json, ".count");
+        for (uint256 i = 0; i < n; i++) {
+            bytes memory callData = vm.parseJsonBytes(json, string.concat(".proofs[", vm.toString(i), "].calldata"));
+            (bool ok, bytes memory ret) = address(verifier).call(callData);
+            assertTrue(ok);
+            assertTrue(abi.decode(ret, (bool)));
+        }
+    }
+}
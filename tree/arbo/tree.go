@@ -17,7 +17,9 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"runtime"
 	"sync"
+	"sync/atomic"
 
 	"go.vocdoni.io/dvote/db"
 )
@@ -53,9 +55,10 @@ var (
 	// in disk.
 	DefaultThresholdNLeafs = 65536
 
-	dbKeyRoot   = []byte("arbo/root/")
-	dbKeyNLeafs = []byte("arbo/nleafs/")
-	emptyValue  = []byte{0}
+	dbKeyRoot         = []byte("arbo/root/")
+	dbKeyNLeafs       = []byte("arbo/nleafs/")
+	dbKeyHashFunction = []byte("arbo/hashfunction/")
+	emptyValue        = []byte{0}
 
 	// ErrKeyNotFound is used when a key is not found in the db neither in
 	// the current db Batch.
@@ -80,6 +83,9 @@ var (
 	// ErrTreeNotEmpty indicates when the tree was expected to be empty and
 	// it is not
 	ErrTreeNotEmpty = fmt.Errorf("tree is not empty")
+	// ErrValueTooLong is used when trying to set a leaf value longer than
+	// the max value length of the tree
+	ErrValueTooLong = fmt.Errorf("value too long")
 )
 
 // Tree defines the struct that implements the MerkleTree functionalities
@@ -93,7 +99,9 @@ type Tree struct {
 	// defined when calling NewTree, and if set to 0 it will work always in
 	// disk.
 	thresholdNLeafs int
-	snapshotRoot    []byte
+	// maxValueLen is the max length of the leaf values, see Config.MaxValueLen
+	maxValueLen  int
+	snapshotRoot []byte
 
 	hashFunction HashFunction
 	// TODO in the methods that use it, check if emptyHash param is len>0
@@ -103,6 +111,14 @@ type Tree struct {
 	// emptyNode is the hash of an empty node (with both childs empty)
 	emptyNode []byte
 
+	// batchWorkers is the number of goroutines used by AddBatch
+	batchWorkers int
+
+	// proofCache caches the proofs generated by GenProofWithTx, nil unless
+	// enabled with SetProofCacheSize. It is shared with the snapshots of the
+	// Tree taken after enabling it.
+	proofCache atomic.Pointer[proofCache]
+
 	dbg *dbgStats
 }
 
@@ -111,7 +127,14 @@ type Config struct {
 	Database        db.Database
 	MaxLevels       int
 	ThresholdNLeafs int
-	HashFunction    HashFunction
+	// HashFunction can be nil when loading an existing Tree, in that case
+	// the one stored in the Tree metadata is taken from the registry (see
+	// RegisterHashFunction).
+	HashFunction HashFunction
+	// MaxValueLen is the max length of the leaf values that can be added to
+	// the tree, if 0 it is the max length supported by the tree dumps (2^16-1
+	// bytes). The values of a LeafLayout have a length of LeafLayout.Len().
+	MaxValueLen int
 }
 
 // NewTree returns a new Tree, if there is a Tree still in the given database, it
@@ -140,15 +163,25 @@ func NewTreeWithTx(wTx db.WriteTx, cfg Config) (*Tree, error) {
 		cfg.ThresholdNLeafs = DefaultThresholdNLeafs
 	}
 
+	if cfg.MaxValueLen < 0 || cfg.MaxValueLen > maxUint16 {
+		return nil, fmt.Errorf("max value length %d out of bounds [0, %d]", cfg.MaxValueLen, maxUint16)
+	}
+
+	hashFunction, err := treeHashFunction(wTx, cfg.HashFunction)
+	if err != nil {
+		return nil, err
+	}
+
 	t := Tree{
 		db:              cfg.Database,
 		maxLevels:       cfg.MaxLevels,
 		thresholdNLeafs: cfg.ThresholdNLeafs,
-		hashFunction:    cfg.HashFunction,
+		maxValueLen:     cfg.MaxValueLen,
+		hashFunction:    hashFunction,
+		batchWorkers:    runtime.NumCPU(),
 	}
 
 	t.emptyHash = make([]byte, t.hashFunction.Len()) // empty
-	var err error
 	t.emptyNode, _, err = t.newIntermediate(t.emptyHash, t.emptyHash)
 	if err != nil {
 		return nil, err
@@ -156,7 +189,10 @@ func NewTreeWithTx(wTx db.WriteTx, cfg Config) (*Tree, error) {
 
 	_, err = wTx.Get(dbKeyRoot)
 	if err == db.ErrKeyNotFound {
-		// store new root 0 (empty)
+		// store the hash function type and new root 0 (empty)
+		if err := wTx.Set(dbKeyHashFunction, t.hashFunction.Type()); err != nil {
+			return nil, err
+		}
 		return &t, t.setToEmptyTree(wTx)
 	} else if err != nil {
 		return nil, fmt.Errorf("unknown database error: %w", err)
@@ -164,6 +200,28 @@ func NewTreeWithTx(wTx db.WriteTx, cfg Config) (*Tree, error) {
 	return &t, nil
 }
 
+// treeHashFunction returns the hash function of the tree stored in the given
+// db.WriteTx. If hashFunction is nil, it is taken from the registry by the type
+// stored in the tree metadata, otherwise it must match the stored one.
+// Trees created before the type was stored have no type, so hashFunction is
+// returned as is.
+func treeHashFunction(rTx db.Reader, hashFunction HashFunction) (HashFunction, error) {
+	typ, err := rTx.Get(dbKeyHashFunction)
+	if err != nil && err != db.ErrKeyNotFound {
+		return nil, fmt.Errorf("unknown database error: %w", err)
+	}
+	if hashFunction == nil {
+		if typ == nil {
+			return nil, fmt.Errorf("hash function not defined")
+		}
+		return HashFunctionByType(typ)
+	}
+	if typ != nil && !bytes.Equal(typ, hashFunction.Type()) {
+		return nil, fmt.Errorf("tree hash function is %q, not %q", typ, hashFunction.Type())
+	}
+	return hashFunction, nil
+}
+
 // Root returns the root of the Tree
 func (t *Tree) Root() ([]byte, error) {
 	return t.RootWithTx(t.db)
@@ -239,22 +297,31 @@ func (t *Tree) addWithTx(wTx db.WriteTx, k, v []byte) error {
 		return err
 	}
 
-	root, err = t.add(wTx, root, 0, k, v) // add from level 0
+	changes := &treeStats{}
+	newRoot, err := t.add(wTx, root, 0, k, v, changes) // add from level 0
 	if err != nil {
 		return err
 	}
 	// store root to db
-	if err := t.setRoot(wTx, root); err != nil {
+	if err := t.setRoot(wTx, newRoot); err != nil {
+		return err
+	}
+	if err := t.updateStats(wTx, root, newRoot, changes); err != nil {
 		return err
 	}
 	// update nLeafs
 	return t.incNLeafs(wTx, 1)
 }
 
-func (t *Tree) add(wTx db.WriteTx, root []byte, fromLvl int, k, v []byte) ([]byte, error) {
+// add adds the key-value to the subtree under root, which is at the level
+// fromLvl, and records the changes of the statistics of the tree in stats.
+func (t *Tree) add(wTx db.WriteTx, root []byte, fromLvl int, k, v []byte, stats *treeStats) ([]byte, error) {
 	if err := checkKeyValueLen(k, v); err != nil {
 		return nil, err
 	}
+	if err := t.checkValueLen(v); err != nil {
+		return nil, err
+	}
 
 	keyPath, err := keyPathFromKey(t.maxLevels, k)
 	if err != nil {
@@ -265,7 +332,7 @@ func (t *Tree) add(wTx db.WriteTx, root []byte, fromLvl int, k, v []byte) ([]byt
 	// go down to the leaf
 	var siblings, intermediates [][]byte
 
-	_, _, siblings, err = t.down(wTx, k, root, siblings, &intermediates, path, fromLvl, false)
+	_, oldValue, siblings, err := t.down(wTx, k, root, siblings, &intermediates, path, fromLvl, false)
 	if err != nil {
 		return nil, err
 	}
@@ -279,6 +346,16 @@ func (t *Tree) add(wTx db.WriteTx, root []byte, fromLvl int, k, v []byte) ([]byt
 		return nil, err
 	}
 
+	// the new leaf is at the bottom of the siblings, and if there was a
+	// leaf in its place it is moved down next to it, replacing the
+	// intermediate nodes of the path by one per sibling
+	depth := fromLvl + len(siblings)
+	stats.addLeaf(depth, len(leafKey)+len(leafValue))
+	if oldValue[0] == PrefixValueLeaf {
+		stats.moveLeaf(fromLvl+len(intermediates), depth)
+	}
+	stats.addIntermediates(len(siblings) - len(intermediates))
+
 	// go up to the root
 	if len(siblings) == 0 {
 		// if there are no siblings, means that the tree is empty.
@@ -298,6 +375,15 @@ func (t *Tree) add(wTx db.WriteTx, root []byte, fromLvl int, k, v []byte) ([]byt
 	return root, nil
 }
 
+// checkValueLen checks that the value is not longer than the max value length
+// of the tree.
+func (t *Tree) checkValueLen(v []byte) error {
+	if t.maxValueLen > 0 && len(v) > t.maxValueLen {
+		return fmt.Errorf("%w: len(v)=%d, can not be bigger than %d", ErrValueTooLong, len(v), t.maxValueLen)
+	}
+	return nil
+}
+
 func (t *Tree) newLeafValue(k, v []byte) ([]byte, []byte, error) {
 	t.dbg.incHash()
 	return newLeafValue(t.hashFunction, k, v)
@@ -329,6 +415,9 @@ func (t *Tree) UpdateWithTx(wTx db.WriteTx, k, v []byte) error {
 	if !t.editable() {
 		return ErrSnapshotNotEditable
 	}
+	if err := t.checkValueLen(v); err != nil {
+		return err
+	}
 
 	keyPath, err := keyPathFromKey(t.maxLevels, k)
 	if err != nil {
@@ -378,22 +467,24 @@ func (t *Tree) UpdateWithTx(wTx db.WriteTx, k, v []byte) error {
 	}
 
 	// go up to the root
-	if len(siblings) == 0 {
-		return t.setRoot(wTx, leafKey)
-	}
-
-	root, err = t.up(wTx, leafKey, siblings, path, len(siblings)-1, 0)
-	if err != nil {
-		return err
-	}
+	newRoot := leafKey
+	if len(siblings) > 0 {
+		newRoot, err = t.up(wTx, leafKey, siblings, path, len(siblings)-1, 0)
+		if err != nil {
+			return err
+		}
 
-	// delete the old intermediate nodes
-	if err := deleteNodes(wTx, intermediates); err != nil {
-		return fmt.Errorf("error deleting orphan intermediate nodes: %v", err)
+		// delete the old intermediate nodes
+		if err := deleteNodes(wTx, intermediates); err != nil {
+			return fmt.Errorf("error deleting orphan intermediate nodes: %v", err)
+		}
 	}
 
 	// store root to db
-	return t.setRoot(wTx, root)
+	if err := t.setRoot(wTx, newRoot); err != nil {
+		return err
+	}
+	return t.updateStats(wTx, root, newRoot, &treeStats{leafBytes: len(leafValue) - len(valueAtBottom)})
 }
 
 // Delete removes the key from the Tree.
@@ -426,6 +517,9 @@ func (t *Tree) setToEmptyTree(wTx db.WriteTx) error {
 	if err := wTx.Set(dbKeyRoot, t.emptyHash); err != nil {
 		return err
 	}
+	if err := t.setStats(wTx, t.emptyHash, &treeStats{}); err != nil {
+		return err
+	}
 	return t.setNLeafs(wTx, 0)
 }
 
@@ -440,7 +534,7 @@ func (t *Tree) deleteWithTx(wTx db.WriteTx, k []byte) error {
 	var siblings, intermediates [][]byte
 
 	path := getPath(t.maxLevels, k)
-	leafKey, _, siblings, err := t.down(wTx, k, root, siblings, &intermediates, path, 0, true)
+	leafKey, leafValue, siblings, err := t.down(wTx, k, root, siblings, &intermediates, path, 0, true)
 	if err != nil {
 		if err == ErrKeyNotFound {
 			// Key not found, nothing to delete.
@@ -448,6 +542,15 @@ func (t *Tree) deleteWithTx(wTx db.WriteTx, k []byte) error {
 		}
 		return err
 	}
+	// down returns the leaf found in the path of the key, which is empty or
+	// holds another key when the key is not in the tree, so there is nothing
+	// to delete either
+	if leafValue[0] != PrefixValueLeaf {
+		return nil
+	}
+	if storedKey, _ := ReadLeafValue(leafValue); !bytes.Equal(storedKey, k) {
+		return nil
+	}
 
 	if len(siblings) == 0 {
 		// if no siblings, means the tree is now empty,
@@ -494,6 +597,23 @@ func (t *Tree) deleteWithTx(wTx db.WriteTx, k []byte) error {
 		return err
 	}
 
+	// Update the stats before the neighbours are moved, which update them
+	// on their own. The leaf is replaced by an empty node, so the
+	// intermediate nodes of the path are recreated up to the first one with
+	// a non empty sibling.
+	changes := &treeStats{}
+	if leafValue[0] == PrefixValueLeaf {
+		changes.removeLeaf(len(siblings), len(leafKey)+len(leafValue))
+	}
+	created := len(siblings)
+	for created > 0 && bytes.Equal(siblings[created-1], t.emptyHash) {
+		created--
+	}
+	changes.addIntermediates(created - len(intermediates))
+	if err := t.updateStats(wTx, root, newRoot, changes); err != nil {
+		return err
+	}
+
 	// Delete the neighbour's childs and add them back to the tree in the right place.
 	for i, k := range neighbourKeys {
 		if err := t.deleteWithTx(wTx, k); err != nil {
@@ -646,14 +766,18 @@ func (t *Tree) Snapshot(fromRoot []byte) (*Tree, error) {
 		}
 	}
 
-	return &Tree{
+	snapshot := &Tree{
 		db:           t.db,
 		maxLevels:    t.maxLevels,
+		maxValueLen:  t.maxValueLen,
 		snapshotRoot: fromRoot,
 		emptyHash:    t.emptyHash,
 		hashFunction: t.hashFunction,
+		batchWorkers: t.batchWorkers,
 		dbg:          t.dbg,
-	}, nil
+	}
+	snapshot.proofCache.Store(t.proofCache.Load())
+	return snapshot, nil
 }
 
 // Iterate iterates through the full Tree, executing the given function on each
@@ -801,19 +925,11 @@ func (t *Tree) dump(fromRoot []byte, w io.Writer) ([]byte, error) {
 			return false
 		}
 		leafK, leafV := ReadLeafValue(v)
-		kv := make([]byte, 3+len(leafK)+len(leafV))
-		if len(leafK) > maxUint8 {
-			callbackErr = fmt.Errorf("len(leafK) > %v", maxUint8)
-			return true
-		}
-		kv[0] = byte(len(leafK))
-		if len(leafV) > maxUint16 {
-			callbackErr = fmt.Errorf("len(leafV) > %v", maxUint16)
+		kv, err := encodeDumpLeaf(leafK, leafV)
+		if err != nil {
+			callbackErr = err
 			return true
 		}
-		binary.LittleEndian.PutUint16(kv[1:3], uint16(len(leafV)))
-		copy(kv[3:3+len(leafK)], leafK)
-		copy(kv[3+len(leafK):], leafV)
 
 		if w == nil {
 			b = append(b, kv...)
@@ -836,6 +952,39 @@ func (t *Tree) dump(fromRoot []byte, w io.Writer) ([]byte, error) {
 	return b, err
 }
 
+// DumpKeyValues encodes the given key-values in the format of Dump, so that a
+// subset of the leafs of a Tree can be imported with ImportDump.
+func DumpKeyValues(keys, values [][]byte) ([]byte, error) {
+	if len(keys) != len(values) {
+		return nil, fmt.Errorf("len(keys)!=len(values) (%d!=%d)", len(keys), len(values))
+	}
+	var b []byte
+	for i := range keys {
+		kv, err := encodeDumpLeaf(keys[i], values[i])
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, kv...)
+	}
+	return b, nil
+}
+
+// encodeDumpLeaf encodes a leaf key and value in the format of Dump.
+func encodeDumpLeaf(leafK, leafV []byte) ([]byte, error) {
+	if len(leafK) > maxUint8 {
+		return nil, fmt.Errorf("len(leafK) > %v", maxUint8)
+	}
+	if len(leafV) > maxUint16 {
+		return nil, fmt.Errorf("len(leafV) > %v", maxUint16)
+	}
+	kv := make([]byte, 3+len(leafK)+len(leafV))
+	kv[0] = byte(len(leafK))
+	binary.LittleEndian.PutUint16(kv[1:3], uint16(len(leafV)))
+	copy(kv[3:3+len(leafK)], leafK)
+	copy(kv[3+len(leafK):], leafV)
+	return kv, nil
+}
+
 // ImportDump imports the leafs (that have been exported with the Dump method)
 // in the Tree, reading them from the given byte array.
 func (t *Tree) ImportDump(b []byte) error {
@@ -845,9 +994,11 @@ func (t *Tree) ImportDump(b []byte) error {
 }
 
 // ImportDumpReader imports the leafs (that have been exported with the Dump
-// method) in the Tree, reading them from the given reader.
+// method) in the Tree, reading them from the given reader. The writes are
+// committed in batches, so that large dumps do not exceed the size of a
+// single db.WriteTx.
 func (t *Tree) ImportDumpReader(r io.Reader) error {
-	wTx := t.db.WriteTx()
+	wTx := db.NewBatchWriteTx(t.db, 0, 0)
 	defer wTx.Discard()
 
 	if err := t.ImportDumpReaderWithTx(wTx, r); err != nil {
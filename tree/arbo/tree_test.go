@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"testing"
 	"time"
 
@@ -544,6 +545,72 @@ func TestGenProofAndVerify(t *testing.T) {
 	c.Check(verif, qt.IsTrue)
 }
 
+func TestGenProofCache(t *testing.T) {
+	c := qt.New(t)
+	database := metadb.NewTest(t)
+	tree, err := NewTree(Config{
+		Database: database, MaxLevels: 256,
+		HashFunction: HashFunctionPoseidon,
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(tree.SetProofCacheSize(2), qt.IsNil)
+
+	bLen := 32
+	for i := 0; i < 10; i++ {
+		k := BigIntToBytesLE(bLen, big.NewInt(int64(i)))
+		v := BigIntToBytesLE(bLen, big.NewInt(int64(i*2)))
+		c.Assert(tree.Add(k, v), qt.IsNil)
+	}
+	root, err := tree.Root()
+	c.Assert(err, qt.IsNil)
+
+	k := BigIntToBytesLE(bLen, big.NewInt(int64(7)))
+	_, v, siblings, existence, err := tree.GenProof(k)
+	c.Assert(err, qt.IsNil)
+	c.Assert(existence, qt.IsTrue)
+	_, ok := tree.proofCache.Load().get(root, k)
+	c.Assert(ok, qt.IsTrue)
+
+	// the cached proof is the same, and modifying it does not alter the cache
+	_, v2, siblings2, existence2, err := tree.GenProof(k)
+	c.Assert(err, qt.IsNil)
+	c.Assert(v2, qt.DeepEquals, v)
+	c.Assert(siblings2, qt.DeepEquals, siblings)
+	c.Assert(existence2, qt.IsTrue)
+	siblings2[len(siblings2)-1]++
+	_, _, siblings2, _, err = tree.GenProof(k)
+	c.Assert(err, qt.IsNil)
+	c.Assert(siblings2, qt.DeepEquals, siblings)
+
+	// a proof of non-existence is cached as well
+	missing := BigIntToBytesLE(bLen, big.NewInt(int64(100)))
+	_, _, _, existence, err = tree.GenProof(missing)
+	c.Assert(err, qt.IsNil)
+	c.Assert(existence, qt.IsFalse)
+	_, _, _, existence, err = tree.GenProof(missing)
+	c.Assert(err, qt.IsNil)
+	c.Assert(existence, qt.IsFalse)
+
+	// once the tree is updated, the proofs are generated under the new root
+	c.Assert(tree.Update(k, BigIntToBytesLE(bLen, big.NewInt(int64(1)))), qt.IsNil)
+	newRoot, err := tree.Root()
+	c.Assert(err, qt.IsNil)
+	_, v, siblings, _, err = tree.GenProof(k)
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.DeepEquals, BigIntToBytesLE(bLen, big.NewInt(int64(1))))
+	verif, err := CheckProof(tree.hashFunction, k, v, newRoot, siblings)
+	c.Assert(err, qt.IsNil)
+	c.Assert(verif, qt.IsTrue)
+
+	// the snapshots share the cache
+	snapshot, err := tree.Snapshot(nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(snapshot.proofCache.Load(), qt.Equals, tree.proofCache.Load())
+
+	// the cache is bounded
+	c.Assert(tree.proofCache.Load().cache.Len(), qt.Equals, 2)
+}
+
 func TestDumpAndImportDump(t *testing.T) {
 	testDumpAndImportDump(t, false)
 }
@@ -1033,6 +1100,39 @@ func TestDelete(t *testing.T) {
 	c.Check(afterAddRoot, qt.DeepEquals, originalRoot)
 }
 
+func TestDeleteMissingKey(t *testing.T) {
+	c := qt.New(t)
+	tree, err := NewTree(Config{
+		Database: metadb.NewTest(t), MaxLevels: 256,
+		HashFunction: HashFunctionSha256,
+	})
+	c.Assert(err, qt.IsNil)
+
+	// a single leaf is the root of the tree, so the path of any other key
+	// ends in it
+	c.Assert(tree.Add([]byte("fork/processEnd"), []byte{1}), qt.IsNil)
+	root, err := tree.Root()
+	c.Assert(err, qt.IsNil)
+	c.Assert(tree.Delete([]byte("rrec/missing")), qt.IsNil)
+	afterDeleteRoot, err := tree.Root()
+	c.Assert(err, qt.IsNil)
+	c.Check(afterDeleteRoot, qt.DeepEquals, root)
+
+	// the path of a missing key ending in an empty node or in another leaf
+	c.Assert(tree.Add([]byte("rgrd/guardians"), []byte{2}), qt.IsNil)
+	root, err = tree.Root()
+	c.Assert(err, qt.IsNil)
+	for i := 0; i < 16; i++ {
+		c.Assert(tree.Delete([]byte{byte(i)}), qt.IsNil)
+	}
+	afterDeleteRoot, err = tree.Root()
+	c.Assert(err, qt.IsNil)
+	c.Check(afterDeleteRoot, qt.DeepEquals, root)
+	_, v, err := tree.Get([]byte("fork/processEnd"))
+	c.Assert(err, qt.IsNil)
+	c.Check(v, qt.DeepEquals, []byte{1})
+}
+
 func BenchmarkAdd(b *testing.B) {
 	bLen := 32 // for both Poseidon & Sha256
 	// prepare inputs
@@ -1091,7 +1191,10 @@ func TestDiskSizeBench(t *testing.T) {
 	}
 	t.Cleanup(func() { _ = database.Close() })
 
-	tree, err := NewTree(Config{database, 256, DefaultThresholdNLeafs, HashFunctionBlake2b})
+	tree, err := NewTree(Config{
+		Database: database, MaxLevels: 256, ThresholdNLeafs: DefaultThresholdNLeafs,
+		HashFunction: HashFunctionBlake2b,
+	})
 	c.Assert(err, qt.IsNil)
 
 	countDBitems := func() int {
@@ -1569,3 +1672,65 @@ func TestAddVsAddBatch(t *testing.T) {
 	// Compare the root hashes of the two trees
 	c.Assert(addRoot, qt.DeepEquals, addBatchRoot)
 }
+
+func TestGenMultiProofAndVerify(t *testing.T) {
+	c := qt.New(t)
+	database := metadb.NewTest(t)
+	tree, err := NewTree(Config{
+		Database: database, MaxLevels: 256,
+		HashFunction: HashFunctionBlake2b,
+	})
+	c.Assert(err, qt.IsNil)
+
+	bLen := 32
+	var keys, values [][]byte
+	for i := 0; i < 100; i++ {
+		k := BigIntToBytesLE(bLen, big.NewInt(int64(i)))
+		v := BigIntToBytesLE(bLen, big.NewInt(int64(i*2)))
+		c.Assert(tree.Add(k, v), qt.IsNil)
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	root, err := tree.Root()
+	c.Assert(err, qt.IsNil)
+
+	proofKeys := [][]byte{keys[7], keys[3], keys[42], keys[43], keys[99]}
+	proofValues := [][]byte{values[7], values[3], values[42], values[43], values[99]}
+	v, proof, err := tree.GenMultiProof(proofKeys)
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.DeepEquals, proofValues)
+	verif, err := CheckMultiProof(tree.hashFunction, proofKeys, proofValues, root, proof)
+	c.Assert(err, qt.IsNil)
+	c.Check(verif, qt.IsTrue)
+
+	// the multiproof is smaller than the separate proofs
+	separateLen := 0
+	for _, k := range proofKeys {
+		_, _, siblings, _, err := tree.GenProof(k)
+		c.Assert(err, qt.IsNil)
+		separateLen += len(siblings)
+	}
+	c.Assert(len(proof) < separateLen, qt.IsTrue)
+
+	// a multiproof of all the leaves has no siblings
+	_, proof, err = tree.GenMultiProof(keys)
+	c.Assert(err, qt.IsNil)
+	verif, err = CheckMultiProof(tree.hashFunction, keys, values, root, proof)
+	c.Assert(err, qt.IsNil)
+	c.Check(verif, qt.IsTrue)
+
+	// a wrong value or a different set of keys is not verified
+	_, proof, err = tree.GenMultiProof(proofKeys)
+	c.Assert(err, qt.IsNil)
+	wrongValues := slices.Clone(proofValues)
+	wrongValues[2] = values[41]
+	verif, err = CheckMultiProof(tree.hashFunction, proofKeys, wrongValues, root, proof)
+	c.Assert(err, qt.IsNil)
+	c.Check(verif, qt.IsFalse)
+	verif, _ = CheckMultiProof(tree.hashFunction, proofKeys[:4], proofValues[:4], root, proof)
+	c.Check(verif, qt.IsFalse)
+
+	// all the keys must exist
+	_, _, err = tree.GenMultiProof([][]byte{keys[0], BigIntToBytesLE(bLen, big.NewInt(1000))})
+	c.Assert(err, qt.ErrorIs, ErrKeyNotFound)
+}
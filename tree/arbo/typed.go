@@ -0,0 +1,249 @@
+package arbo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Codec encodes and decodes the keys or the values of a TypedTree.
+type Codec[T any] interface {
+	Encode(T) ([]byte, error)
+	Decode([]byte) (T, error)
+}
+
+// BigIntLE returns a Codec for non-negative *big.Int, encoded in Little-Endian
+// with a fixed length of blen bytes, which is the encoding used by the circuits
+// (see BigIntToBytesLE). Use HashFunction.Len() as blen to encode field
+// elements.
+func BigIntLE(blen int) Codec[*big.Int] {
+	return bigIntCodec{blen: blen, le: true}
+}
+
+// BigIntBE returns a Codec for non-negative *big.Int, encoded in Big-Endian with
+// a fixed length of blen bytes. Unlike BigIntToBytesBE, the bytes are padded on
+// the left, so that they decode to the same number.
+func BigIntBE(blen int) Codec[*big.Int] {
+	return bigIntCodec{blen: blen}
+}
+
+type bigIntCodec struct {
+	blen int
+	le   bool
+}
+
+func (c bigIntCodec) Encode(bi *big.Int) ([]byte, error) {
+	if bi == nil || bi.Sign() < 0 {
+		return nil, fmt.Errorf("cannot encode %v, it must be a non-negative integer", bi)
+	}
+	// BigIntToBytesLE and BigIntToBytesBE would silently drop the bytes
+	// which do not fit
+	if (bi.BitLen()+7)/8 > c.blen {
+		return nil, fmt.Errorf("cannot encode %s in %d bytes", bi, c.blen)
+	}
+	if c.le {
+		return BigIntToBytesLE(c.blen, bi), nil
+	}
+	return bi.FillBytes(make([]byte, c.blen)), nil
+}
+
+func (c bigIntCodec) Decode(b []byte) (*big.Int, error) {
+	if len(b) != c.blen {
+		return nil, fmt.Errorf("invalid length %d, expected %d", len(b), c.blen)
+	}
+	if c.le {
+		return BytesLEToBigInt(b), nil
+	}
+	return BytesBEToBigInt(b), nil
+}
+
+// Uint64LE is a Codec for uint64, encoded in 8 bytes in Little-Endian, so that
+// the lowest bits, which are the ones used first for the path of the keys,
+// are the ones which change the most between consecutive numbers.
+var Uint64LE Codec[uint64] = uint64Codec{}
+
+type uint64Codec struct{}
+
+func (uint64Codec) Encode(n uint64) ([]byte, error) {
+	return binary.LittleEndian.AppendUint64(nil, n), nil
+}
+
+func (uint64Codec) Decode(b []byte) (uint64, error) {
+	if len(b) != 8 {
+		return 0, fmt.Errorf("invalid length %d, expected 8", len(b))
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+// Address is a Codec for Ethereum addresses, encoded as their 20 bytes.
+var Address Codec[common.Address] = addressCodec{}
+
+type addressCodec struct{}
+
+func (addressCodec) Encode(addr common.Address) ([]byte, error) {
+	return addr.Bytes(), nil
+}
+
+func (addressCodec) Decode(b []byte) (common.Address, error) {
+	if len(b) != common.AddressLength {
+		return common.Address{}, fmt.Errorf("invalid length %d, expected %d", len(b), common.AddressLength)
+	}
+	return common.BytesToAddress(b), nil
+}
+
+// Bytes is a Codec which leaves the byte slices as they are.
+var Bytes Codec[[]byte] = bytesCodec{}
+
+type bytesCodec struct{}
+
+func (bytesCodec) Encode(b []byte) ([]byte, error) { return b, nil }
+
+func (bytesCodec) Decode(b []byte) ([]byte, error) { return b, nil }
+
+// TypedTree wraps a Tree to add, get and prove typed keys and values, which are
+// converted with the given codecs. The underlying Tree can still be used for
+// the operations which are not typed, such as Root or Dump.
+type TypedTree[K, V any] struct {
+	*Tree
+	keys   Codec[K]
+	values Codec[V]
+}
+
+// NewTypedTree returns a TypedTree which wraps tree, encoding the keys and the
+// values with the given codecs.
+func NewTypedTree[K, V any](tree *Tree, keys Codec[K], values Codec[V]) *TypedTree[K, V] {
+	return &TypedTree[K, V]{Tree: tree, keys: keys, values: values}
+}
+
+// encode encodes the key and the value.
+func (t *TypedTree[K, V]) encode(k K, v V) ([]byte, []byte, error) {
+	kb, err := t.keys.Encode(k)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid key: %w", err)
+	}
+	vb, err := t.values.Encode(v)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value: %w", err)
+	}
+	return kb, vb, nil
+}
+
+// Add inserts the key and value into the tree.
+func (t *TypedTree[K, V]) Add(k K, v V) error {
+	kb, vb, err := t.encode(k, v)
+	if err != nil {
+		return err
+	}
+	return t.Tree.Add(kb, vb)
+}
+
+// Update sets the value of an existing key.
+func (t *TypedTree[K, V]) Update(k K, v V) error {
+	kb, vb, err := t.encode(k, v)
+	if err != nil {
+		return err
+	}
+	return t.Tree.Update(kb, vb)
+}
+
+// Delete removes the key from the tree.
+func (t *TypedTree[K, V]) Delete(k K) error {
+	kb, err := t.keys.Encode(k)
+	if err != nil {
+		return fmt.Errorf("invalid key: %w", err)
+	}
+	return t.Tree.Delete(kb)
+}
+
+// AddBatch adds the keys and values as Tree.AddBatch does, returning the
+// indexes of the ones which could not be added.
+func (t *TypedTree[K, V]) AddBatch(keys []K, values []V) ([]Invalid, error) {
+	if len(keys) != len(values) {
+		return nil, fmt.Errorf("len(keys)!=len(values) (%d!=%d)", len(keys), len(values))
+	}
+	kbs := make([][]byte, len(keys))
+	vbs := make([][]byte, len(values))
+	for i := range keys {
+		var err error
+		if kbs[i], vbs[i], err = t.encode(keys[i], values[i]); err != nil {
+			return nil, fmt.Errorf("leaf %d: %w", i, err)
+		}
+	}
+	return t.Tree.AddBatch(kbs, vbs)
+}
+
+// Get returns the value of the key. If the key is not found, it returns
+// ErrKeyNotFound.
+func (t *TypedTree[K, V]) Get(k K) (V, error) {
+	var v V
+	kb, err := t.keys.Encode(k)
+	if err != nil {
+		return v, fmt.Errorf("invalid key: %w", err)
+	}
+	_, vb, err := t.Tree.Get(kb)
+	if err != nil {
+		return v, err
+	}
+	return t.values.Decode(vb)
+}
+
+// GenProof generates a proof of the key. It returns the value of the key and
+// the packed siblings of the proof if it exists, or only the siblings of the
+// proof of non-existence if it does not, together with a boolean that
+// indicates if the proof is of existence.
+func (t *TypedTree[K, V]) GenProof(k K) (V, []byte, bool, error) {
+	var v V
+	kb, err := t.keys.Encode(k)
+	if err != nil {
+		return v, nil, false, fmt.Errorf("invalid key: %w", err)
+	}
+	_, vb, siblings, existence, err := t.Tree.GenProof(kb)
+	if err != nil || !existence {
+		return v, siblings, false, err
+	}
+	v, err = t.values.Decode(vb)
+	return v, siblings, true, err
+}
+
+// CheckProof verifies the proof of existence of the key and value for the
+// given root, as CheckProof does.
+func (t *TypedTree[K, V]) CheckProof(k K, v V, root, packedSiblings []byte) (bool, error) {
+	kb, vb, err := t.encode(k, v)
+	if err != nil {
+		return false, err
+	}
+	return CheckProof(t.hashFunction, kb, vb, root, packedSiblings)
+}
+
+// IterateLeaves calls f with the key and value of each leaf of the tree with
+// the given root, or the current one if nil, until f returns true. It fails if
+// a leaf cannot be decoded.
+func (t *TypedTree[K, V]) IterateLeaves(fromRoot []byte, f func(K, V) bool) error {
+	var decodeErr error
+	stop := false
+	if err := t.Tree.IterateWithStop(fromRoot, func(_ int, _, node []byte) bool {
+		if stop || len(node) == 0 || node[0] != PrefixValueLeaf {
+			return stop
+		}
+		kb, vb := ReadLeafValue(node)
+		k, err := t.keys.Decode(kb)
+		if err != nil {
+			decodeErr = fmt.Errorf("invalid key %x: %w", kb, err)
+			stop = true
+			return true
+		}
+		v, err := t.values.Decode(vb)
+		if err != nil {
+			decodeErr = fmt.Errorf("invalid value of key %x: %w", kb, err)
+			stop = true
+			return true
+		}
+		stop = f(k, v)
+		return stop
+	}); err != nil {
+		return err
+	}
+	return decodeErr
+}
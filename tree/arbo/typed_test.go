@@ -0,0 +1,116 @@
+package arbo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db/metadb"
+)
+
+func TestTypedTree(t *testing.T) {
+	c := qt.New(t)
+	tree, err := NewTree(Config{
+		Database: metadb.NewTest(t), MaxLevels: 160,
+		HashFunction: HashFunctionPoseidon,
+	})
+	c.Assert(err, qt.IsNil)
+	weights := NewTypedTree(tree, Address, BigIntLE(HashFunctionPoseidon.Len()))
+
+	var addrs []common.Address
+	var values []*big.Int
+	for i := range 10 {
+		addrs = append(addrs, common.BigToAddress(big.NewInt(int64(i+1))))
+		values = append(values, big.NewInt(int64(i*100)))
+	}
+	c.Assert(weights.Add(addrs[0], values[0]), qt.IsNil)
+	invalids, err := weights.AddBatch(addrs[1:], values[1:])
+	c.Assert(err, qt.IsNil)
+	c.Assert(invalids, qt.HasLen, 0)
+
+	// the typed tree has the same root as the one built with the raw encoding
+	raw, err := NewTree(Config{
+		Database: metadb.NewTest(t), MaxLevels: 160,
+		HashFunction: HashFunctionPoseidon,
+	})
+	c.Assert(err, qt.IsNil)
+	for i := range addrs {
+		c.Assert(raw.Add(addrs[i].Bytes(), BigIntToBytesLE(HashFunctionPoseidon.Len(), values[i])), qt.IsNil)
+	}
+	root, err := weights.Root()
+	c.Assert(err, qt.IsNil)
+	rawRoot, err := raw.Root()
+	c.Assert(err, qt.IsNil)
+	c.Assert(root, qt.DeepEquals, rawRoot)
+
+	v, err := weights.Get(addrs[3])
+	c.Assert(err, qt.IsNil)
+	c.Assert(v.Cmp(values[3]), qt.Equals, 0)
+	_, err = weights.Get(common.Address{0xff})
+	c.Assert(err, qt.ErrorIs, ErrKeyNotFound)
+
+	c.Assert(weights.Update(addrs[3], big.NewInt(7)), qt.IsNil)
+	v, siblings, exists, err := weights.GenProof(addrs[3])
+	c.Assert(err, qt.IsNil)
+	c.Assert(exists, qt.IsTrue)
+	c.Assert(v.Int64(), qt.Equals, int64(7))
+	root, err = weights.Root()
+	c.Assert(err, qt.IsNil)
+	valid, err := weights.CheckProof(addrs[3], v, root, siblings)
+	c.Assert(err, qt.IsNil)
+	c.Assert(valid, qt.IsTrue)
+	valid, err = weights.CheckProof(addrs[3], big.NewInt(8), root, siblings)
+	c.Assert(err, qt.IsNil)
+	c.Assert(valid, qt.IsFalse)
+
+	c.Assert(weights.Delete(addrs[0]), qt.IsNil)
+	leafs := make(map[common.Address]int64)
+	c.Assert(weights.IterateLeaves(nil, func(addr common.Address, v *big.Int) bool {
+		leafs[addr] = v.Int64()
+		return false
+	}), qt.IsNil)
+	c.Assert(leafs, qt.HasLen, 9)
+	c.Assert(leafs[addrs[3]], qt.Equals, int64(7))
+
+	// stops once the function returns true
+	n := 0
+	c.Assert(weights.IterateLeaves(nil, func(common.Address, *big.Int) bool {
+		n++
+		return n == 2
+	}), qt.IsNil)
+	c.Assert(n, qt.Equals, 2)
+
+	// the values which do not fit are rejected instead of truncated
+	tooBig := new(big.Int).Lsh(big.NewInt(1), uint(8*HashFunctionPoseidon.Len()))
+	c.Assert(weights.Add(common.Address{0xfe}, tooBig), qt.ErrorMatches, "invalid value: .*")
+	c.Assert(weights.Add(common.Address{0xfe}, big.NewInt(-1)), qt.ErrorMatches, "invalid value: .*")
+}
+
+func TestCodecs(t *testing.T) {
+	c := qt.New(t)
+
+	b, err := Uint64LE.Encode(0x0102)
+	c.Assert(err, qt.IsNil)
+	c.Assert(b, qt.DeepEquals, []byte{2, 1, 0, 0, 0, 0, 0, 0})
+	n, err := Uint64LE.Decode(b)
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, uint64(0x0102))
+	_, err = Uint64LE.Decode(b[:4])
+	c.Assert(err, qt.IsNotNil)
+
+	b, err = BigIntBE(4).Encode(big.NewInt(0x0102))
+	c.Assert(err, qt.IsNil)
+	c.Assert(b, qt.DeepEquals, []byte{0, 0, 1, 2})
+	bi, err := BigIntBE(4).Decode(b)
+	c.Assert(err, qt.IsNil)
+	c.Assert(bi.Int64(), qt.Equals, int64(0x0102))
+	b, err = BigIntLE(4).Encode(big.NewInt(0x0102))
+	c.Assert(err, qt.IsNil)
+	c.Assert(b, qt.DeepEquals, []byte{2, 1, 0, 0})
+	bi, err = BigIntLE(4).Decode(b)
+	c.Assert(err, qt.IsNil)
+	c.Assert(bi.Int64(), qt.Equals, int64(0x0102))
+	_, err = BigIntLE(4).Decode(b[:3])
+	c.Assert(err, qt.IsNotNil)
+}
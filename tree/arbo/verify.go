@@ -0,0 +1,189 @@
+package arbo
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"go.vocdoni.io/dvote/db"
+)
+
+// ErrCorruptedTree is returned by Verify and VerifySample when a node stored in
+// the db does not match its hash, is missing or is not where it should be.
+var ErrCorruptedTree = fmt.Errorf("corrupted tree")
+
+// Verify walks the whole tree from its current root, recomputing the hash of
+// each node and comparing it with the key it is stored at, checking that each
+// leaf is in the path of its key and that the number of leafs is the stored
+// one. It returns ErrCorruptedTree describing the first inconsistency found, or
+// the error of ctx if it is done before the walk finishes. The tree cannot be
+// updated meanwhile.
+func (t *Tree) Verify(ctx context.Context) error {
+	return t.VerifyWithTx(ctx, t.db)
+}
+
+// VerifyWithTx does the same as Verify, but using the given db.Reader.
+func (t *Tree) VerifyWithTx(ctx context.Context, rTx db.Reader) error {
+	t.Lock()
+	defer t.Unlock()
+	root, err := t.RootWithTx(rTx)
+	if err != nil {
+		return err
+	}
+	nLeafs := 0
+	if err := t.verifyNode(ctx, rTx, root, nil, &nLeafs); err != nil {
+		return err
+	}
+	if t.snapshotRoot != nil {
+		// the number of leafs is only stored for the current root
+		return nil
+	}
+	stored, err := t.GetNLeafsWithTx(rTx)
+	if err != nil {
+		return err
+	}
+	if stored != nLeafs {
+		return fmt.Errorf("%w: %d leafs found, %d expected", ErrCorruptedTree, nLeafs, stored)
+	}
+	return nil
+}
+
+// VerifySample verifies the nodes of the given number of random paths, from
+// the current root down to a leaf or an empty node, as Verify does for the
+// whole tree. It detects a corruption with a probability that grows with the
+// number of paths, at a fraction of the cost of Verify for big trees.
+func (t *Tree) VerifySample(ctx context.Context, paths int) error {
+	t.Lock()
+	defer t.Unlock()
+	root, err := t.RootWithTx(t.db)
+	if err != nil {
+		return err
+	}
+	randomKey := make([]byte, (t.maxLevels+7)/8)
+	for range paths {
+		if _, err := rand.Read(randomKey); err != nil {
+			return err
+		}
+		if err := t.verifyPath(ctx, t.db, root, getPath(t.maxLevels, randomKey)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyNode verifies the node with the given key, at the given path from the
+// root, and all its descendants, counting the leafs found.
+func (t *Tree) verifyNode(ctx context.Context, rTx db.Reader, k []byte, path []bool, nLeafs *int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	v, err := t.readVerifiedNode(rTx, k, path)
+	if err != nil || v == nil {
+		return err
+	}
+	if v[0] == PrefixValueLeaf {
+		*nLeafs++
+		return nil
+	}
+	l, r := ReadIntermediateChilds(v)
+	if err := t.verifyNode(ctx, rTx, l, append(path, false), nLeafs); err != nil {
+		return err
+	}
+	return t.verifyNode(ctx, rTx, r, append(path, true), nLeafs)
+}
+
+// verifyPath verifies the nodes from the one with the given key down to the
+// leaf or empty node at the end of the given path.
+func (t *Tree) verifyPath(ctx context.Context, rTx db.Reader, k []byte, path []bool) error {
+	for lvl := 0; ; lvl++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		v, err := t.readVerifiedNode(rTx, k, path[:lvl])
+		if err != nil || v == nil || v[0] == PrefixValueLeaf {
+			return err
+		}
+		l, r := ReadIntermediateChilds(v)
+		k = l
+		if path[lvl] {
+			k = r
+		}
+	}
+}
+
+// readVerifiedNode reads the node with the given key, at the given path from
+// the root, and checks that its hash is the key and, for the leafs, that the
+// path is a prefix of the path of the leaf key. It returns nil for the empty
+// node.
+func (t *Tree) readVerifiedNode(rTx db.Reader, k []byte, path []bool) ([]byte, error) {
+	if bytes.Equal(k, t.emptyHash) {
+		return nil, nil
+	}
+	v, err := rTx.Get(k)
+	if err == db.ErrKeyNotFound {
+		return nil, fmt.Errorf("%w: node %x not found", ErrCorruptedTree, k)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(v) < PrefixValueLen {
+		return nil, fmt.Errorf("%w: node %x too short", ErrCorruptedTree, k)
+	}
+	var hash []byte
+	switch v[0] {
+	case PrefixValueLeaf:
+		leafK, leafV := ReadLeafValue(v)
+		if hash, _, err = newLeafValue(t.hashFunction, leafK, leafV); err != nil {
+			return nil, fmt.Errorf("%w: leaf %x: %w", ErrCorruptedTree, k, err)
+		}
+		keyPath, err := keyPathFromKey(t.maxLevels, leafK)
+		if err != nil {
+			return nil, fmt.Errorf("%w: leaf %x: %w", ErrCorruptedTree, k, err)
+		}
+		for i, bit := range getPath(t.maxLevels, keyPath)[:len(path)] {
+			if bit != path[i] {
+				return nil, fmt.Errorf("%w: leaf %x out of its path", ErrCorruptedTree, k)
+			}
+		}
+	case PrefixValueIntermediate:
+		if len(path) >= t.maxLevels {
+			return nil, fmt.Errorf("%w: intermediate node %x at the max level", ErrCorruptedTree, k)
+		}
+		if len(v) != PrefixValueLen+t.hashFunction.Len()*2 {
+			return nil, fmt.Errorf("%w: intermediate node %x has length %d", ErrCorruptedTree, k, len(v))
+		}
+		if hash, err = t.hashFunction.Hash(ReadIntermediateChilds(v)); err != nil {
+			return nil, fmt.Errorf("%w: intermediate node %x: %w", ErrCorruptedTree, k, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: node %x: %w", ErrCorruptedTree, k, ErrInvalidValuePrefix)
+	}
+	if !bytes.Equal(hash, k) {
+		return nil, fmt.Errorf("%w: node %x has hash %x", ErrCorruptedTree, k, hash)
+	}
+	return v, nil
+}
+
+// RepairFromDump rebuilds the tree from the leafs of a dump (see Dump), which
+// rewrites all its nodes, and verifies the result. It is meant to recover a
+// tree found corrupted by Verify, from a dump taken while it was not.
+// The nodes that are no longer part of the tree are not removed from the db.
+func (t *Tree) RepairFromDump(r io.Reader) error {
+	if !t.editable() {
+		return ErrSnapshotNotEditable
+	}
+	wTx := t.db.WriteTx()
+	defer wTx.Discard()
+	if err := t.setToEmptyTree(wTx); err != nil {
+		return err
+	}
+	if err := t.ImportDumpReaderWithTx(wTx, r); err != nil {
+		return err
+	}
+	if err := t.VerifyWithTx(context.Background(), wTx); err != nil {
+		return fmt.Errorf("the repaired tree is not valid: %w", err)
+	}
+	return wTx.Commit()
+}
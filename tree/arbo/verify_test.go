@@ -0,0 +1,71 @@
+package arbo
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db/metadb"
+)
+
+func TestVerifyAndRepair(t *testing.T) {
+	c := qt.New(t)
+	database := metadb.NewTest(t)
+	tree, err := NewTree(Config{Database: database, MaxLevels: 256, HashFunction: HashFunctionBlake2b})
+	c.Assert(err, qt.IsNil)
+	ctx := context.Background()
+
+	// an empty tree is valid
+	c.Assert(tree.Verify(ctx), qt.IsNil)
+	c.Assert(tree.VerifySample(ctx, 10), qt.IsNil)
+
+	for i := 0; i < 100; i++ {
+		c.Assert(tree.Add(BigIntToBytesLE(32, big.NewInt(int64(i))), BigIntToBytesLE(32, big.NewInt(int64(i)))), qt.IsNil)
+	}
+	c.Assert(tree.Verify(ctx), qt.IsNil)
+	c.Assert(tree.VerifySample(ctx, 10), qt.IsNil)
+	root, err := tree.Root()
+	c.Assert(err, qt.IsNil)
+	dump, err := tree.Dump(nil)
+	c.Assert(err, qt.IsNil)
+
+	// corrupt the value of a leaf
+	var leafKey, leafValue []byte
+	c.Assert(tree.Iterate(nil, func(k, v []byte) {
+		if v[0] == PrefixValueLeaf && leafKey == nil {
+			leafKey, leafValue = bytes.Clone(k), bytes.Clone(v)
+		}
+	}), qt.IsNil)
+	corrupted := bytes.Clone(leafValue)
+	corrupted[len(corrupted)-1] ^= 0xff
+	wTx := database.WriteTx()
+	c.Assert(wTx.Set(leafKey, corrupted), qt.IsNil)
+	c.Assert(wTx.Commit(), qt.IsNil)
+	c.Assert(tree.Verify(ctx), qt.ErrorIs, ErrCorruptedTree)
+
+	// sampling enough paths finds it
+	c.Assert(tree.VerifySample(ctx, 1000), qt.ErrorIs, ErrCorruptedTree)
+
+	// and the repair from the dump restores the tree
+	c.Assert(tree.RepairFromDump(bytes.NewReader(dump)), qt.IsNil)
+	c.Assert(tree.Verify(ctx), qt.IsNil)
+	repairedRoot, err := tree.Root()
+	c.Assert(err, qt.IsNil)
+	c.Assert(repairedRoot, qt.DeepEquals, root)
+	nLeafs, err := tree.GetNLeafs()
+	c.Assert(err, qt.IsNil)
+	c.Assert(nLeafs, qt.Equals, 100)
+
+	// a missing node is also found
+	wTx = database.WriteTx()
+	c.Assert(wTx.Delete(leafKey), qt.IsNil)
+	c.Assert(wTx.Commit(), qt.IsNil)
+	c.Assert(tree.Verify(ctx), qt.ErrorIs, ErrCorruptedTree)
+
+	// the verification stops when the context is done
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	c.Assert(tree.Verify(canceled), qt.ErrorIs, context.Canceled)
+}
@@ -11,7 +11,6 @@ import (
 	"io"
 	"math"
 	"runtime"
-	"sync"
 )
 
 //lint:file-ignore U1000 this code is for debugging
@@ -30,6 +29,9 @@ type params struct {
 	hashFunction HashFunction
 	emptyHash    []byte
 	dbg          *dbgStats
+	// workers is the number of goroutines used by addBatch and
+	// computeHashes
+	workers int
 }
 
 type kv struct {
@@ -84,6 +86,7 @@ func newVT(maxLevels int, hash HashFunction) vt {
 			maxLevels:    maxLevels,
 			hashFunction: hash,
 			emptyHash:    make([]byte, hash.Len()), // empty
+			workers:      runtime.NumCPU(),
 		},
 	}
 }
@@ -94,8 +97,8 @@ func newVT(maxLevels int, hash HashFunction) vt {
 // the tree into the db. After addBatch, vt.computeHashes should be called to
 // compute the hashes of all the nodes of the tree.
 func (t *vt) addBatch(ks, vs [][]byte) ([]Invalid, error) {
-	nCPU := flp2(runtime.NumCPU())
-	if nCPU == 1 || len(ks) < nCPU {
+	l := batchSubtreeLevel(t.params.maxLevels, t.params.workers, len(ks))
+	if l == 0 {
 		var invalids []Invalid
 		for i := 0; i < len(ks); i++ {
 			if err := t.add(0, ks[i], vs[i]); err != nil {
@@ -105,101 +108,27 @@ func (t *vt) addBatch(ks, vs [][]byte) ([]Invalid, error) {
 		return invalids, nil
 	}
 
-	l := int(math.Log2(float64(nCPU)))
-
 	kvs, invalids, err := keysValuesToKvs(t.params.maxLevels, ks, vs)
-	if err != nil {
-		return invalids, err
-	}
-
-	buckets := splitInBuckets(kvs, nCPU)
-
-	nodesAtL, err := t.getNodesAtLevel(l)
 	if err != nil {
 		return nil, err
 	}
-	if len(nodesAtL) != nCPU && t.root != nil {
-		/*
-			Already populated Tree but Unbalanced
-			- Need to fill M1 and M2, and then will be able to continue with the flow
-				- Search for M1 & M2 in the inputed Keys
-				- Add M1 & M2 to the Tree
-				- From here can continue with the flow
-
-			              R
-			             /  \
-			            /    \
-			           /      \
-			          *        *
-			           |        \
-			           |         \
-			           |          \
-			L:    M1   *   M2      *        (where M1 and M2 are empty)
-			          / |         /
-			         /  |        /
-			        /   |       /
-			       A    *      *
-			           / \     | \
-			          /   \    |  \
-			         /     \   |   \
-			        B      *   *   C
-			              / \  |\
-			           ... ... | \
-			                   |  \
-			                   D  E
-		*/
-
-		// add one key at each bucket, and then continue with the flow
-		for i := 0; i < len(buckets); i++ {
-			// add one leaf of the bucket, if there is an error when
-			// adding the k-v, try to add the next one of the bucket
-			// (until one is added)
-			inserted := -1
-			for j := 0; j < len(buckets[i]); j++ {
-				if err := t.add(0, buckets[i][j].k, buckets[i][j].v); err == nil {
-					inserted = j
-					break
-				}
-			}
 
-			// remove the inserted element from buckets[i]
-			if inserted != -1 {
-				buckets[i] = append(buckets[i][:inserted], buckets[i][inserted+1:]...)
+	buckets := splitInBuckets(kvs, 1<<l)
+	subRoots := t.subtreesAtLevel(l)
+	invalidsInBucket := make([][]Invalid, len(buckets))
+
+	runWorkers(t.params.workers, len(buckets), func(_, bucket int) {
+		bucketVT := newVT(t.params.maxLevels, t.params.hashFunction)
+		bucketVT.root = subRoots[bucket]
+		for j := 0; j < len(buckets[bucket]); j++ {
+			if err := bucketVT.add(l, buckets[bucket][j].k,
+				buckets[bucket][j].v); err != nil {
+				invalidsInBucket[bucket] = append(invalidsInBucket[bucket],
+					Invalid{buckets[bucket][j].pos, err})
 			}
 		}
-		nodesAtL, err = t.getNodesAtLevel(l)
-		if err != nil {
-			return nil, err
-		}
-	}
-	if len(nodesAtL) != nCPU {
-		return nil, fmt.Errorf("this error should not be reached."+
-			" len(nodesAtL) != nCPU, len(nodesAtL)=%d, nCPU=%d."+
-			" Please report it in a new issue:"+
-			" https://go.vocdoni.io/dvote/tree/arbo/issues/new", len(nodesAtL), nCPU)
-	}
-
-	subRoots := make([]*node, nCPU)
-	invalidsInBucket := make([][]Invalid, nCPU)
-
-	var wg sync.WaitGroup
-	wg.Add(nCPU)
-	for i := 0; i < nCPU; i++ {
-		go func(cpu int) {
-			bucketVT := newVT(t.params.maxLevels, t.params.hashFunction)
-			bucketVT.root = nodesAtL[cpu]
-			for j := 0; j < len(buckets[cpu]); j++ {
-				if err := bucketVT.add(l, buckets[cpu][j].k,
-					buckets[cpu][j].v); err != nil {
-					invalidsInBucket[cpu] = append(invalidsInBucket[cpu],
-						Invalid{buckets[cpu][j].pos, err})
-				}
-			}
-			subRoots[cpu] = bucketVT.root
-			wg.Done()
-		}(i)
-	}
-	wg.Wait()
+		subRoots[bucket] = bucketVT.root
+	})
 
 	for i := 0; i < len(invalidsInBucket); i++ {
 		invalids = append(invalids, invalidsInBucket[i]...)
@@ -214,6 +143,37 @@ func (t *vt) addBatch(ks, vs [][]byte) ([]Invalid, error) {
 	return invalids, nil
 }
 
+// subtreesAtLevel returns the 2^l nodes at level l, which are the roots of the
+// subtrees that start there, ordered from left to right. Unlike getNodesAtLevel,
+// a leaf placed above level l is returned as the root of the subtree that
+// contains its path, so the subtrees can be extended independently and merged
+// back with upFromNodes.
+func (t *vt) subtreesAtLevel(l int) []*node {
+	nodes := make([]*node, 1<<l)
+	t.root.fillSubtrees(0, 0, l, nodes)
+	return nodes
+}
+
+func (n *node) fillSubtrees(currLvl, pos, l int, nodes []*node) {
+	switch {
+	case n.typ() == vtEmpty:
+		return
+	case currLvl == l:
+		nodes[pos] = n
+	case n.typ() == vtLeaf:
+		for lvl := currLvl; lvl < l; lvl++ {
+			pos *= 2
+			if n.path[lvl] {
+				pos++
+			}
+		}
+		nodes[pos] = n
+	default:
+		n.l.fillSubtrees(currLvl+1, pos*2, l, nodes)
+		n.r.fillSubtrees(currLvl+1, pos*2+1, l, nodes)
+	}
+}
+
 func (t *vt) getNodesAtLevel(l int) ([]*node, error) {
 	if t.root == nil {
 		var r []*node
@@ -318,39 +278,25 @@ func (t *vt) add(fromLvl int, k, v []byte) error {
 // leafs are in the tree. Computes the hashes of the tree, parallelizing in the
 // available CPUs.
 func (t *vt) computeHashes() ([][2][]byte, error) {
-	var err error
-
-	nCPU := flp2(runtime.NumCPU())
-	l := int(math.Log2(float64(nCPU)))
+	// the hashes below level l are computed concurrently for each subtree,
+	// and then the ones above l are computed from the subtree roots
+	l := batchSubtreeLevel(t.params.maxLevels, t.params.workers, math.MaxInt)
 	nodesAtL, err := t.getNodesAtLevel(l)
 	if err != nil {
 		return nil, err
 	}
-	subRoots := make([]*node, nCPU)
-	bucketPairs := make([][][2][]byte, nCPU)
-	dbgStatsPerBucket := make([]*dbgStats, nCPU)
-	errs := make([]error, nCPU)
-
-	var wg sync.WaitGroup
-	wg.Add(nCPU)
-	for i := 0; i < nCPU; i++ {
-		go func(cpu int) {
-			bucketVT := newVT(t.params.maxLevels, t.params.hashFunction)
-			bucketVT.params.dbg = newDbgStats()
-			bucketVT.root = nodesAtL[cpu]
-			var err error
-			bucketPairs[cpu], err = bucketVT.root.computeHashes(l-1,
-				t.params.maxLevels, bucketVT.params, bucketPairs[cpu])
-			if err != nil {
-				errs[cpu] = err
-			}
+	bucketPairs := make([][][2][]byte, len(nodesAtL))
+	dbgStatsPerBucket := make([]*dbgStats, len(nodesAtL))
+	errs := make([]error, len(nodesAtL))
 
-			subRoots[cpu] = bucketVT.root
-			dbgStatsPerBucket[cpu] = bucketVT.params.dbg
-			wg.Done()
-		}(i)
-	}
-	wg.Wait()
+	runWorkers(t.params.workers, len(nodesAtL), func(_, bucket int) {
+		bucketVT := newVT(t.params.maxLevels, t.params.hashFunction)
+		bucketVT.params.dbg = newDbgStats()
+		bucketVT.root = nodesAtL[bucket]
+		bucketPairs[bucket], errs[bucket] = bucketVT.root.computeHashes(l-1,
+			t.params.maxLevels, bucketVT.params, bucketPairs[bucket])
+		dbgStatsPerBucket[bucket] = bucketVT.params.dbg
+	})
 
 	for i := 0; i < len(errs); i++ {
 		if errs[i] != nil {
@@ -367,14 +313,6 @@ func (t *vt) computeHashes() ([][2][]byte, error) {
 		pairs = append(pairs, bucketPairs[i]...)
 	}
 
-	nodesAtL, err = t.getNodesAtLevel(l)
-	if err != nil {
-		return nil, err
-	}
-	for i := 0; i < len(nodesAtL); i++ {
-		nodesAtL = subRoots
-	}
-
 	pairs, err = t.root.computeHashes(0, l, t.params, pairs)
 	if err != nil {
 		return nil, err
@@ -628,6 +566,19 @@ func (n *node) computeHashes(currLvl, maxLvl int, p *params, pairs [][2][]byte)
 	return pairs, nil
 }
 
+// computeStats adds to s the statistics of the subtree under the node, which
+// is at the given depth. The hashes must have been computed.
+func (n *node) computeStats(depth int, s *treeStats) {
+	switch n.typ() {
+	case vtLeaf:
+		s.addLeaf(depth, len(n.h)+PrefixValueLen+len(n.k)+len(n.v))
+	case vtMid:
+		s.addIntermediates(1)
+		n.l.computeStats(depth+1, s)
+		n.r.computeStats(depth+1, s)
+	}
+}
+
 func (t *vt) graphviz(w io.Writer) error {
 	fmt.Fprintf(w, `digraph hierarchy {
 node [fontname=Monospace,fontsize=10,shape=box]
@@ -1,6 +1,7 @@
 package tree
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -24,6 +25,12 @@ type Options struct {
 	MaxLevels int
 	// HashFunc defines the hash function that the tree will use
 	HashFunc arbo.HashFunction
+	// ProofCacheSize is the number of proofs cached by the tree, see
+	// arbo.Tree.SetProofCacheSize. If it is 0, the proofs are not cached.
+	ProofCacheSize int
+	// MaxValueLen is the max length of the leaf values, see
+	// arbo.Config.MaxValueLen.
+	MaxValueLen int
 }
 
 // New returns a new Tree, if there already is a Tree in the database, it will
@@ -38,12 +45,16 @@ func New(wTx db.WriteTx, opts Options) (*Tree, error) {
 		Database:     opts.DB,
 		MaxLevels:    opts.MaxLevels,
 		HashFunction: opts.HashFunc,
+		MaxValueLen:  opts.MaxValueLen,
 		// ThresholdNLeafs: not specified, use the default
 	}
 	tree, err := arbo.NewTreeWithTx(wTx, arboConfig)
 	if err != nil {
 		return nil, err
 	}
+	if err := tree.SetProofCacheSize(opts.ProofCacheSize); err != nil {
+		return nil, err
+	}
 
 	if !givenTx {
 		if err := wTx.Commit(); err != nil {
@@ -210,6 +221,14 @@ func (t *Tree) Size(rTx db.Reader) (uint64, error) {
 	return uint64(n), err
 }
 
+// Stats returns the statistics of the shape of the tree, see arbo.Tree.Stats.
+func (t *Tree) Stats(rTx db.Reader) (*arbo.Stats, error) {
+	if rTx == nil {
+		rTx = t.db
+	}
+	return t.tree.StatsWithTx(rTx)
+}
+
 // GenProof returns a byte array with the necessary data to verify that the
 // key&value are in a leaf under the current root
 func (t *Tree) GenProof(rTx db.Reader, key []byte) ([]byte, []byte, error) {
@@ -297,9 +316,35 @@ func (t *Tree) ImportDumpReaderWithTx(wTx db.WriteTx, r io.Reader) error {
 	return t.tree.ImportDumpReaderWithTx(wTx, r)
 }
 
+// Verify checks the integrity of the whole tree, recomputing the hash of all
+// its nodes. It returns arbo.ErrCorruptedTree if the db is corrupted.
+func (t *Tree) Verify(ctx context.Context) error {
+	return t.tree.Verify(ctx)
+}
+
+// VerifySample checks the integrity of the nodes of the given number of random
+// paths of the tree, which is cheaper than Verify for big trees.
+func (t *Tree) VerifySample(ctx context.Context, paths int) error {
+	return t.tree.VerifySample(ctx, paths)
+}
+
+// RepairFromDump rebuilds the tree from the leafs of a dump (that has been
+// exported with the Dump method), in order to recover it from a corruption
+// found by Verify.
+func (t *Tree) RepairFromDump(r io.Reader) error {
+	return t.tree.RepairFromDump(r)
+}
+
 func (t *Tree) PrintGraphviz(rTx db.Reader) error {
 	if rTx == nil {
 		rTx = t.db
 	}
 	return t.tree.PrintGraphvizFirstNLevels(rTx, nil, 0)
 }
+
+// ExportGraph writes to w a view of the first maxDepth levels of the tree
+// (all of them if 0) in the given format, including the number of leaves of
+// each subtree, to debug its structure.
+func (t *Tree) ExportGraph(w io.Writer, format arbo.GraphFormat, maxDepth int) error {
+	return t.tree.ExportGraphWithTx(t.db, w, nil, format, maxDepth)
+}
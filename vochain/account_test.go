@@ -1,17 +1,22 @@
 package vochain
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math/rand/v2"
 	"regexp"
+	"slices"
 	"testing"
+	"time"
 
 	cometabcitypes "github.com/cometbft/cometbft/abci/types"
+	cometconfig "github.com/cometbft/cometbft/config"
 	"github.com/ethereum/go-ethereum/common"
 	qt "github.com/frankban/quicktest"
 
 	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/util"
 	"go.vocdoni.io/dvote/vochain/state"
 	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
@@ -739,6 +744,133 @@ func TestSendTokensTxToTheSameAccount(t *testing.T) {
 	qt.Assert(t, err, qt.IsNotNil)
 }
 
+func TestSimulateTx(t *testing.T) {
+	app := TestBaseApplication(t)
+
+	signer := ethereum.SignKeys{}
+	qt.Assert(t, signer.Generate(), qt.IsNil)
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_SEND_TOKENS, 10), qt.IsNil)
+	qt.Assert(t, app.State.CreateAccount(signer.Address(), "ipfs://", [][]byte{}, 0), qt.IsNil)
+	toAccAddr := common.HexToAddress(randomEthAccount)
+	qt.Assert(t, app.State.CreateAccount(toAccAddr, "ipfs://", [][]byte{}, 0), qt.IsNil)
+	qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+		ToAddress: signer.Address(),
+		Amount:    1000,
+	}), qt.IsNil)
+	testCommitState(t, app)
+
+	sendTokens := func(value uint64, nonce uint32) []byte {
+		stx := &models.SignedTx{}
+		var err error
+		stx.Tx, err = proto.Marshal(&models.Tx{Payload: &models.Tx_SendTokens{SendTokens: &models.SendTokensTx{
+			Txtype: models.TxType_SEND_TOKENS,
+			From:   signer.Address().Bytes(),
+			To:     toAccAddr.Bytes(),
+			Value:  value,
+			Nonce:  nonce,
+		}}})
+		qt.Assert(t, err, qt.IsNil)
+		stx.Signature, err = signer.SignVocdoniTx(stx.Tx, app.chainID)
+		qt.Assert(t, err, qt.IsNil)
+		stxBytes, err := proto.Marshal(stx)
+		qt.Assert(t, err, qt.IsNil)
+		return stxBytes
+	}
+
+	// a valid transaction reports its cost, without changing the state
+	sim, err := app.SimulateTx(sendTokens(100, 0))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, sim.Err, qt.IsNil)
+	qt.Assert(t, sim.TxType, qt.Equals, "sendTokens")
+	qt.Assert(t, sim.Cost, qt.Equals, uint64(10))
+	balance, err := app.State.AccountBalance(signer.Address(), false)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, balance, qt.Equals, uint64(1000))
+	acc, err := app.State.GetAccount(signer.Address(), false)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, acc.Nonce, qt.Equals, uint32(0))
+
+	// the reason of the rejections is reported
+	sim, err = app.SimulateTx(sendTokens(100, 1))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, sim.Err, qt.ErrorMatches, ".*nonce.*")
+	sim, err = app.SimulateTx(sendTokens(2000, 0))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, sim.Err, qt.IsNotNil)
+	sim, err = app.SimulateTx([]byte("invalid"))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, sim.Err, qt.IsNotNil)
+
+	// the transaction can still be executed
+	qt.Assert(t, testSendTokensTx(t, &signer, app, toAccAddr, 100, 0), qt.IsNil)
+}
+
+func TestTxReceipt(t *testing.T) {
+	app := TestBaseApplication(t)
+
+	signer := ethereum.SignKeys{}
+	qt.Assert(t, signer.Generate(), qt.IsNil)
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_SEND_TOKENS, 10), qt.IsNil)
+	qt.Assert(t, app.State.CreateAccount(signer.Address(), "ipfs://", [][]byte{}, 0), qt.IsNil)
+	toAccAddr := common.HexToAddress(randomEthAccount)
+	qt.Assert(t, app.State.CreateAccount(toAccAddr, "ipfs://", [][]byte{}, 0), qt.IsNil)
+	qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+		ToAddress: signer.Address(),
+		Amount:    1000,
+	}), qt.IsNil)
+	testCommitState(t, app)
+
+	sendTokens := func(value uint64, nonce uint32) []byte {
+		stx := &models.SignedTx{}
+		var err error
+		stx.Tx, err = proto.Marshal(&models.Tx{Payload: &models.Tx_SendTokens{SendTokens: &models.SendTokensTx{
+			Txtype: models.TxType_SEND_TOKENS,
+			From:   signer.Address().Bytes(),
+			To:     toAccAddr.Bytes(),
+			Value:  value,
+			Nonce:  nonce,
+		}}})
+		qt.Assert(t, err, qt.IsNil)
+		stx.Signature, err = signer.SignVocdoniTx(stx.Tx, app.chainID)
+		qt.Assert(t, err, qt.IsNil)
+		stxBytes, err := proto.Marshal(stx)
+		qt.Assert(t, err, qt.IsNil)
+		return stxBytes
+	}
+
+	// the receipt has the cost and the events of the transaction
+	resp := app.deliverTx(sendTokens(100, 0))
+	qt.Assert(t, resp.Code, qt.Equals, uint32(0))
+	qt.Assert(t, resp.Receipt, qt.IsNotNil)
+	qt.Assert(t, resp.Receipt.Cost, qt.Equals, uint64(10))
+	qt.Assert(t, resp.Receipt.Events, qt.DeepEquals, []vochaintx.Event{
+		vochaintx.NewTokensTransferredEvent(signer.Address(), toAccAddr, 100),
+	})
+	events := abciEvents(resp.Receipt.Events)
+	qt.Assert(t, events, qt.HasLen, 1)
+	qt.Assert(t, events[0].Type, qt.Equals, vochaintx.EventTokensTransferred)
+	qt.Assert(t, events[0].Attributes[2].Key, qt.Equals, "amount")
+	qt.Assert(t, events[0].Attributes[2].Value, qt.Equals, "100")
+
+	// a failed transaction has no receipt
+	resp = app.deliverTx(sendTokens(100, 0))
+	qt.Assert(t, resp.Code, qt.Not(qt.Equals), uint32(0))
+	qt.Assert(t, resp.Receipt, qt.IsNil)
+
+	// the events emitted out of the transactions are not recorded
+	qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+		ToAddress: signer.Address(),
+		Amount:    1,
+	}), qt.IsNil)
+	resp = app.deliverTx(sendTokens(50, 1))
+	qt.Assert(t, resp.Code, qt.Equals, uint32(0))
+	qt.Assert(t, resp.Receipt.Events, qt.DeepEquals, []vochaintx.Event{
+		vochaintx.NewTokensTransferredEvent(signer.Address(), toAccAddr, 50),
+	})
+}
+
 func testSendTokensTx(t *testing.T,
 	signer *ethereum.SignKeys,
 	app *BaseApplication,
@@ -875,6 +1007,116 @@ func testSetAccountDelegateTx(t *testing.T,
 	return nil
 }
 
+func TestSetAccountKVTx(t *testing.T) {
+	app := TestBaseApplication(t)
+
+	signer := ethereum.SignKeys{}
+	qt.Assert(t, signer.Generate(), qt.IsNil)
+	delegate := ethereum.SignKeys{}
+	qt.Assert(t, delegate.Generate(), qt.IsNil)
+	other := ethereum.SignKeys{}
+	qt.Assert(t, other.Generate(), qt.IsNil)
+
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	// the key-value entries cost the same as the info URI
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_SET_ACCOUNT_INFO_URI, 10), qt.IsNil)
+
+	qt.Assert(t, app.State.CreateAccount(signer.Address(), "ipfs://", [][]byte{delegate.Address().Bytes()}, 0), qt.IsNil)
+	for _, s := range []*ethereum.SignKeys{&signer, &delegate, &other} {
+		if s != &signer {
+			qt.Assert(t, app.State.CreateAccount(s.Address(), "ipfs://", [][]byte{}, 0), qt.IsNil)
+		}
+		qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+			ToAddress: s.Address(),
+			Amount:    1000,
+		}), qt.IsNil)
+	}
+	testCommitState(t, app)
+
+	// should set an entry of the own account
+	qt.Assert(t, testSetAccountKVTx(t, &signer, app, nil, "webhook", []byte("hash"), 0), qt.IsNil)
+	entries, err := app.State.AccountKV(signer.Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, entries, qt.DeepEquals, []state.AccountKV{{Key: "webhook", Value: []byte("hash")}})
+	acc, err := app.State.GetAccount(signer.Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, acc.Balance, qt.Equals, uint64(990))
+	qt.Assert(t, acc.Nonce, qt.Equals, uint32(1))
+
+	// should set an entry as a delegate of the account
+	qt.Assert(t, testSetAccountKVTx(t, &delegate, app, signer.Address().Bytes(), "delegate", []byte("x"), 0), qt.IsNil)
+	entries, err = app.State.AccountKV(signer.Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, entries, qt.HasLen, 2)
+
+	// should fail if not a delegate of the account
+	err = testSetAccountKVTx(t, &other, app, signer.Address().Bytes(), "delegate", []byte("y"), 0)
+	qt.Assert(t, err, qt.ErrorMatches, ".*tx sender is not a delegate.*")
+
+	// should fail with an invalid entry
+	err = testSetAccountKVTx(t, &signer, app, nil, "", []byte("y"), 1)
+	qt.Assert(t, err, qt.IsNotNil)
+	err = testSetAccountKVTx(t, &signer, app, nil, "big", make([]byte, state.MaxAccountKVValueSize+1), 1)
+	qt.Assert(t, err, qt.IsNotNil)
+
+	// should delete an entry with an empty value
+	qt.Assert(t, testSetAccountKVTx(t, &signer, app, nil, "webhook", nil, 1), qt.IsNil)
+	entries, err = app.State.AccountKV(signer.Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, entries, qt.DeepEquals, []state.AccountKV{{Key: "delegate", Value: []byte("x")}})
+}
+
+func TestSetAccountKVTxFork(t *testing.T) {
+	app := TestBaseApplicationWithForks(t, map[string]uint32{state.ForkAccountKV: 2})
+
+	signer := ethereum.SignKeys{}
+	qt.Assert(t, signer.Generate(), qt.IsNil)
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	qt.Assert(t, app.State.CreateAccount(signer.Address(), "ipfs://", [][]byte{}, 0), qt.IsNil)
+	qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+		ToAddress: signer.Address(),
+		Amount:    1000,
+	}), qt.IsNil)
+	testCommitState(t, app)
+
+	// the nodes without the account key-value store reject the tx before the fork
+	err := testSetAccountKVTx(t, &signer, app, nil, "webhook", []byte("hash"), 0)
+	qt.Assert(t, err, qt.ErrorMatches, ".*fork not active: accountKV")
+	entries, err := app.State.AccountKV(signer.Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, entries, qt.HasLen, 0)
+
+	app.AdvanceTestBlocksUntilHeight(2)
+	qt.Assert(t, testSetAccountKVTx(t, &signer, app, nil, "webhook", []byte("hash"), 0), qt.IsNil)
+	entries, err = app.State.AccountKV(signer.Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, entries, qt.HasLen, 1)
+}
+
+func testSetAccountKVTx(t *testing.T,
+	signer *ethereum.SignKeys,
+	app *BaseApplication,
+	account []byte,
+	key string,
+	value []byte,
+	nonce uint32,
+) error {
+	var err error
+
+	stx := &models.SignedTx{}
+	if stx.Tx, err = proto.Marshal(&models.Tx{Payload: &models.Tx_SetAccount{
+		SetAccount: vochaintx.NewSetAccountKVTx(nonce, account, key, value),
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sendTx(app, signer, stx); err != nil {
+		return err
+	}
+	testCommitState(t, app)
+	return nil
+}
+
 func TestCollectFaucetTx(t *testing.T) {
 	app := TestBaseApplication(t)
 
@@ -1033,3 +1275,611 @@ func sendTx(app *BaseApplication, signer *ethereum.SignKeys, stx *models.SignedT
 	}
 	return nil
 }
+
+func TestMultisigAccountTx(t *testing.T) {
+	app := TestBaseApplication(t)
+
+	signers := make([]*ethereum.SignKeys, 3)
+	for i := range signers {
+		signers[i] = &ethereum.SignKeys{}
+		qt.Assert(t, signers[i].Generate(), qt.IsNil)
+		qt.Assert(t, app.State.CreateAccount(signers[i].Address(), "ipfs://", [][]byte{}, 0), qt.IsNil)
+		qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+			ToAddress: signers[i].Address(),
+			Amount:    1000,
+		}), qt.IsNil)
+	}
+	slices.SortFunc(signers, func(a, b *ethereum.SignKeys) int {
+		return bytes.Compare(a.Address().Bytes(), b.Address().Bytes())
+	})
+	addrs := []common.Address{signers[0].Address(), signers[1].Address(), signers[2].Address()}
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_CREATE_ACCOUNT, 10), qt.IsNil)
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_NEW_PROCESS, 10), qt.IsNil)
+	app.State.ElectionPriceCalc.SetBasePrice(10)
+	app.State.ElectionPriceCalc.SetCapacity(2000)
+	testCommitState(t, app)
+
+	// should fail with unsorted signers
+	stx := &models.SignedTx{}
+	var err error
+	stx.Tx, err = proto.Marshal(&models.Tx{Payload: &models.Tx_SetAccount{
+		SetAccount: vochaintx.NewCreateMultisigAccountTx(0, []common.Address{addrs[1], addrs[0]}, 2),
+	}})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, sendTx(app, signers[0], stx), qt.ErrorMatches, ".*sorted and unique.*")
+
+	// should create the multisig account, paid by the tx sender
+	stx.Tx, err = proto.Marshal(&models.Tx{Payload: &models.Tx_SetAccount{
+		SetAccount: vochaintx.NewCreateMultisigAccountTx(0, addrs, 2),
+	}})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, sendTx(app, signers[0], stx), qt.IsNil)
+	testCommitState(t, app)
+	multisig, err := app.State.MultisigAccount((&state.MultisigAccount{Signers: addrs, Threshold: 2}).Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, multisig.Signers, qt.DeepEquals, addrs)
+	qt.Assert(t, multisig.Threshold, qt.Equals, uint32(2))
+	msigAddr := multisig.Address()
+	acc, err := app.State.GetAccount(signers[0].Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, acc.Balance, qt.Equals, uint64(990))
+	_, err = app.State.MultisigAccount(signers[0].Address(), true)
+	qt.Assert(t, err, qt.ErrorIs, state.ErrMultisigNotFound)
+
+	// should fail to create it twice
+	stx.Tx, err = proto.Marshal(&models.Tx{Payload: &models.Tx_SetAccount{
+		SetAccount: vochaintx.NewCreateMultisigAccountTx(1, addrs, 2),
+	}})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, sendTx(app, signers[0], stx), qt.ErrorMatches, ".*already exists.*")
+
+	qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+		ToAddress: msigAddr,
+		Amount:    10000,
+	}), qt.IsNil)
+	testCommitState(t, app)
+
+	censusURI := ipfsUrlTest
+	newProcessTx := func(entityID []byte) []byte {
+		txb, err := proto.Marshal(&models.Tx{Payload: &models.Tx_NewProcess{NewProcess: &models.NewProcessTx{
+			Txtype: models.TxType_NEW_PROCESS,
+			Nonce:  0,
+			Process: &models.Process{
+				StartBlock:    0,
+				EnvelopeType:  &models.EnvelopeType{},
+				Mode:          &models.ProcessMode{Interruptible: true},
+				VoteOptions:   &models.ProcessVoteOptions{MaxCount: 16, MaxValue: 16},
+				Status:        models.ProcessStatus_READY,
+				EntityId:      entityID,
+				CensusRoot:    util.RandomBytes(32),
+				CensusURI:     &censusURI,
+				CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+				BlockCount:    1024,
+				MaxCensusSize: 100,
+			},
+		}}})
+		qt.Assert(t, err, qt.IsNil)
+		return txb
+	}
+	sendMultisigTx := func(txb []byte, keys ...*ethereum.SignKeys) error {
+		signatures := [][]byte{}
+		for _, k := range keys {
+			signature, err := k.SignVocdoniTx(txb, app.chainID)
+			qt.Assert(t, err, qt.IsNil)
+			signatures = append(signatures, signature)
+		}
+		stx, err := vochaintx.NewMultisigSignedTx(txb, msigAddr, signatures)
+		qt.Assert(t, err, qt.IsNil)
+		_, err = testCheckTxDeliverTxCommit(t, app, stx)
+		return err
+	}
+
+	// should fail without enough signatures
+	txb := newProcessTx(msigAddr.Bytes())
+	qt.Assert(t, sendMultisigTx(txb, signers[0]), qt.ErrorMatches, ".*not enough signatures.*")
+	qt.Assert(t, sendMultisigTx(txb, signers[0], signers[0]), qt.ErrorMatches, ".*duplicated signature.*")
+
+	// should fail with a signature of someone who is not a signer
+	other := &ethereum.SignKeys{}
+	qt.Assert(t, other.Generate(), qt.IsNil)
+	qt.Assert(t, sendMultisigTx(txb, signers[0], other), qt.ErrorMatches, ".*is not a signer.*")
+
+	// should fail if the multisig account is not the organizer
+	qt.Assert(t, sendMultisigTx(newProcessTx(signers[0].Address().Bytes()), signers[0], signers[1]),
+		qt.ErrorMatches, ".*must be the organizer.*")
+
+	// should fail with an unsupported transaction
+	sendTokens, err := proto.Marshal(&models.Tx{Payload: &models.Tx_SendTokens{SendTokens: &models.SendTokensTx{
+		Txtype: models.TxType_SEND_TOKENS,
+		From:   msigAddr.Bytes(),
+		To:     signers[0].Address().Bytes(),
+		Value:  100,
+	}}})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, sendMultisigTx(sendTokens, signers[0], signers[1]), qt.ErrorMatches, ".*cannot send.*")
+
+	// should create the process with the signatures of the threshold
+	qt.Assert(t, sendMultisigTx(txb, signers[2], signers[0]), qt.IsNil)
+	acc, err = app.State.GetAccount(msigAddr, true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, acc.Nonce, qt.Equals, uint32(1))
+	qt.Assert(t, acc.ProcessIndex, qt.Equals, uint32(1))
+	qt.Assert(t, acc.Balance < 10000, qt.IsTrue)
+}
+
+func TestMultisigAccountTxFork(t *testing.T) {
+	app := TestBaseApplicationWithForks(t, map[string]uint32{state.ForkMultisig: 2})
+
+	signers := ethereum.NewSignKeysBatch(2)
+	for _, s := range signers {
+		qt.Assert(t, app.State.CreateAccount(s.Address(), "ipfs://", [][]byte{}, 0), qt.IsNil)
+		qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+			ToAddress: s.Address(),
+			Amount:    1000,
+		}), qt.IsNil)
+	}
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	testCommitState(t, app)
+
+	// the multisig accounts cannot be created before the fork
+	stx := &models.SignedTx{}
+	var err error
+	stx.Tx, err = proto.Marshal(&models.Tx{Payload: &models.Tx_SetAccount{
+		SetAccount: vochaintx.NewCreateMultisigAccountTx(0, []common.Address{signers[0].Address(), signers[1].Address()}, 2),
+	}})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, sendTx(app, signers[0], stx), qt.ErrorMatches, ".*fork not active: multisig")
+
+	// and the multisig signatures are ignored, as the nodes without multisig
+	// accounts do, so the transaction is sent by its first signer
+	censusURI := ipfsUrlTest
+	txb, err := proto.Marshal(&models.Tx{Payload: &models.Tx_NewProcess{NewProcess: &models.NewProcessTx{
+		Txtype: models.TxType_NEW_PROCESS,
+		Process: &models.Process{
+			EnvelopeType:  &models.EnvelopeType{},
+			Mode:          &models.ProcessMode{Interruptible: true},
+			VoteOptions:   &models.ProcessVoteOptions{MaxCount: 16, MaxValue: 16},
+			Status:        models.ProcessStatus_READY,
+			EntityId:      signers[0].Address().Bytes(),
+			CensusRoot:    util.RandomBytes(32),
+			CensusURI:     &censusURI,
+			CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+			BlockCount:    1024,
+			MaxCensusSize: 100,
+		},
+	}}})
+	qt.Assert(t, err, qt.IsNil)
+	signatures := [][]byte{}
+	for _, s := range signers {
+		signature, err := s.SignVocdoniTx(txb, app.chainID)
+		qt.Assert(t, err, qt.IsNil)
+		signatures = append(signatures, signature)
+	}
+	mstx, err := vochaintx.NewMultisigSignedTx(txb, common.Address{1}, signatures)
+	qt.Assert(t, err, qt.IsNil)
+	_, err = testCheckTxDeliverTxCommit(t, app, mstx)
+	qt.Assert(t, err, qt.IsNil)
+	acc, err := app.State.GetAccount(signers[0].Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, acc.ProcessIndex, qt.Equals, uint32(1))
+}
+
+func TestSetBlockTimingTx(t *testing.T) {
+	app := TestBaseApplication(t)
+
+	// the validators added hold most of the voting power, so that three of
+	// them are needed to reach the quorum
+	validators := make([]*ethereum.SignKeys, 3)
+	other := ethereum.SignKeys{}
+	qt.Assert(t, other.Generate(), qt.IsNil)
+
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	// the block timing costs the same as setting an account validator
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_SET_ACCOUNT_VALIDATOR, 10), qt.IsNil)
+	for i := range validators {
+		validators[i] = &ethereum.SignKeys{}
+		qt.Assert(t, validators[i].Generate(), qt.IsNil)
+		qt.Assert(t, app.State.AddValidator(&models.Validator{
+			Address: validators[i].Address().Bytes(),
+			PubKey:  validators[i].PublicKey(),
+			Power:   1000,
+		}), qt.IsNil)
+	}
+	for _, s := range append([]*ethereum.SignKeys{&other}, validators...) {
+		qt.Assert(t, app.State.CreateAccount(s.Address(), "ipfs://", [][]byte{}, 0), qt.IsNil)
+		qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+			ToAddress: s.Address(),
+			Amount:    1000,
+		}), qt.IsNil)
+	}
+	testCommitState(t, app)
+
+	blockTimingTx := func(nonce, blockTime, emptyBlocksInterval uint32) *models.SignedTx {
+		txb, err := proto.Marshal(&models.Tx{Payload: &models.Tx_SetAccount{
+			SetAccount: vochaintx.NewSetBlockTimingTx(nonce, blockTime, emptyBlocksInterval),
+		}})
+		qt.Assert(t, err, qt.IsNil)
+		return &models.SignedTx{Tx: txb}
+	}
+	wantTiming := func(want *state.BlockTiming) {
+		timing, err := app.State.BlockTiming(true)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, timing, qt.DeepEquals, want)
+	}
+
+	// the local configuration is used until the block timing is set
+	wantTiming(nil)
+	qt.Assert(t, app.BlockTimeTarget(), qt.Equals, types.DefaultBlockTime)
+
+	// should fail if the sender is not a validator
+	qt.Assert(t, sendTx(app, &other, blockTimingTx(0, 5, 60)),
+		qt.ErrorMatches, ".*only the validators.*")
+
+	// should fail with out of bounds parameters
+	qt.Assert(t, sendTx(app, validators[0], blockTimingTx(0, 0, 0)), qt.ErrorMatches, ".*block time.*")
+	qt.Assert(t, sendTx(app, validators[0], blockTimingTx(0, state.MaxBlockTime+1, 0)), qt.ErrorMatches, ".*block time.*")
+	qt.Assert(t, sendTx(app, validators[0], blockTimingTx(0, 10, 5)), qt.ErrorMatches, ".*empty blocks interval.*")
+	qt.Assert(t, sendTx(app, validators[0], blockTimingTx(0, 10, state.MaxEmptyBlocksInterval+1)),
+		qt.ErrorMatches, ".*empty blocks interval.*")
+
+	// a single validator cannot set the block timing, its tx is only a vote
+	qt.Assert(t, sendTx(app, validators[0], blockTimingTx(0, 5, 60)), qt.IsNil)
+	testCommitState(t, app)
+	wantTiming(nil)
+	votes, err := app.State.BlockTimingVotes(true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, votes, qt.HasLen, 1)
+	acc, err := app.State.GetAccount(validators[0].Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, acc.Balance, qt.Equals, uint64(990))
+	qt.Assert(t, acc.Nonce, qt.Equals, uint32(1))
+
+	// the votes for another block timing do not count towards the quorum
+	qt.Assert(t, sendTx(app, validators[1], blockTimingTx(0, 5, 60)), qt.IsNil)
+	qt.Assert(t, sendTx(app, validators[2], blockTimingTx(0, 10, 60)), qt.IsNil)
+	testCommitState(t, app)
+	wantTiming(nil)
+
+	// should set the block timing once the quorum votes for it, applied once committed
+	qt.Assert(t, sendTx(app, validators[2], blockTimingTx(1, 5, 60)), qt.IsNil)
+	testCommitState(t, app)
+	wantTiming(&state.BlockTiming{BlockTime: 5, EmptyBlocksInterval: 60})
+	votes, err = app.State.BlockTimingVotes(true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, votes, qt.HasLen, 0)
+
+	// the running node keeps its timing, the new one is applied once restarted
+	qt.Assert(t, app.BlockTimeTarget(), qt.Equals, types.DefaultBlockTime)
+	consensus := cometconfig.DefaultConsensusConfig()
+	app.updateBlockTiming(consensus)
+	qt.Assert(t, app.BlockTimeTarget(), qt.Equals, 5*time.Second)
+	qt.Assert(t, consensus.TimeoutCommit, qt.Equals, 5*time.Second)
+	qt.Assert(t, consensus.CreateEmptyBlocksInterval, qt.Equals, time.Minute)
+}
+
+func TestSetBlockTimingTxFork(t *testing.T) {
+	app := TestBaseApplicationWithForks(t, map[string]uint32{state.ForkBlockTiming: 2})
+
+	validator := ethereum.SignKeys{}
+	qt.Assert(t, validator.Generate(), qt.IsNil)
+	qt.Assert(t, app.State.AddValidator(&models.Validator{
+		Address: validator.Address().Bytes(),
+		PubKey:  validator.PublicKey(),
+		Power:   1000,
+	}), qt.IsNil)
+	qt.Assert(t, app.State.CreateAccount(validator.Address(), "ipfs://", [][]byte{}, 0), qt.IsNil)
+	qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+		ToAddress: validator.Address(),
+		Amount:    1000,
+	}), qt.IsNil)
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_SET_ACCOUNT_VALIDATOR, 10), qt.IsNil)
+	testCommitState(t, app)
+
+	txb, err := proto.Marshal(&models.Tx{Payload: &models.Tx_SetAccount{
+		SetAccount: vochaintx.NewSetBlockTimingTx(0, 5, 60),
+	}})
+	qt.Assert(t, err, qt.IsNil)
+
+	// the block timing cannot be voted before the fork
+	qt.Assert(t, sendTx(app, &validator, &models.SignedTx{Tx: txb}), qt.ErrorMatches, ".*fork not active: blockTiming")
+
+	app.AdvanceTestBlocksUntilHeight(2)
+	qt.Assert(t, sendTx(app, &validator, &models.SignedTx{Tx: txb}), qt.IsNil)
+	testCommitState(t, app)
+	timing, err := app.State.BlockTiming(true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, timing, qt.DeepEquals, &state.BlockTiming{BlockTime: 5, EmptyBlocksInterval: 60})
+}
+
+func TestAccountRecoveryTx(t *testing.T) {
+	app := TestBaseApplication(t)
+
+	// the owner, three guardians and someone else
+	keys := make([]*ethereum.SignKeys, 5)
+	for i := range keys {
+		keys[i] = &ethereum.SignKeys{}
+		qt.Assert(t, keys[i].Generate(), qt.IsNil)
+		qt.Assert(t, app.State.CreateAccount(keys[i].Address(), "ipfs://", [][]byte{}, 0), qt.IsNil)
+		qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+			ToAddress: keys[i].Address(),
+			Amount:    1000,
+		}), qt.IsNil)
+	}
+	owner, guardians, other := keys[0], keys[1:4], keys[4]
+	slices.SortFunc(guardians, func(a, b *ethereum.SignKeys) int {
+		return bytes.Compare(a.Address().Bytes(), b.Address().Bytes())
+	})
+	addrs := []common.Address{guardians[0].Address(), guardians[1].Address(), guardians[2].Address()}
+	newOwner := &ethereum.SignKeys{}
+	qt.Assert(t, newOwner.Generate(), qt.IsNil)
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_ADD_DELEGATE_FOR_ACCOUNT, 10), qt.IsNil)
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_NEW_PROCESS, 10), qt.IsNil)
+	app.State.ElectionPriceCalc.SetBasePrice(10)
+	app.State.ElectionPriceCalc.SetCapacity(2000)
+	app.AdvanceTestBlock()
+
+	nonces := map[common.Address]uint32{}
+	sendSetAccountTx := func(signer *ethereum.SignKeys, newTx func(nonce uint32) *models.SetAccountTx) error {
+		stx := &models.SignedTx{}
+		var err error
+		stx.Tx, err = proto.Marshal(&models.Tx{Payload: &models.Tx_SetAccount{
+			SetAccount: newTx(nonces[signer.Address()]),
+		}})
+		qt.Assert(t, err, qt.IsNil)
+		if err := sendTx(app, signer, stx); err != nil {
+			return err
+		}
+		nonces[signer.Address()]++
+		return nil
+	}
+	approve := func(guardian *ethereum.SignKeys, newOwner common.Address) error {
+		return sendSetAccountTx(guardian, func(nonce uint32) *models.SetAccountTx {
+			return vochaintx.NewRecoverAccountTx(nonce, owner.Address(), newOwner)
+		})
+	}
+	execute := func(sender *ethereum.SignKeys) error {
+		return sendSetAccountTx(sender, func(nonce uint32) *models.SetAccountTx {
+			return vochaintx.NewExecuteAccountRecoveryTx(nonce, owner.Address())
+		})
+	}
+
+	// should fail without guardians
+	qt.Assert(t, approve(guardians[0], newOwner.Address()), qt.ErrorMatches, ".*has no guardians.*")
+
+	// should fail with unsorted guardians or an unreachable quorum
+	qt.Assert(t, sendSetAccountTx(owner, func(nonce uint32) *models.SetAccountTx {
+		return vochaintx.NewSetRecoveryGuardiansTx(nonce, nil, []common.Address{addrs[1], addrs[0]}, 1, 2)
+	}), qt.ErrorMatches, ".*sorted and unique.*")
+	qt.Assert(t, sendSetAccountTx(owner, func(nonce uint32) *models.SetAccountTx {
+		return vochaintx.NewSetRecoveryGuardiansTx(nonce, nil, addrs, 4, 2)
+	}), qt.ErrorMatches, ".*quorum.*")
+
+	// should set the guardians, paid by the owner
+	qt.Assert(t, sendSetAccountTx(owner, func(nonce uint32) *models.SetAccountTx {
+		return vochaintx.NewSetRecoveryGuardiansTx(nonce, nil, addrs, 2, 2)
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+	recoveryGuardians, err := app.State.RecoveryGuardians(owner.Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, recoveryGuardians, qt.DeepEquals, &state.RecoveryGuardians{Guardians: addrs, Quorum: 2, Timelock: 2})
+	acc, err := app.State.GetAccount(owner.Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, acc.Balance, qt.Equals, uint64(990))
+
+	// should fail if the sender is not a guardian, or without the quorum
+	qt.Assert(t, approve(other, newOwner.Address()), qt.ErrorMatches, ".*is not a guardian.*")
+	qt.Assert(t, approve(guardians[0], newOwner.Address()), qt.IsNil)
+	qt.Assert(t, approve(guardians[1], other.Address()), qt.IsNil)
+	qt.Assert(t, execute(other), qt.ErrorMatches, ".*no new owner approved.*")
+
+	// should reach the quorum once the second guardian changes its approval,
+	// and fail until the timelock elapses
+	qt.Assert(t, approve(guardians[1], newOwner.Address()), qt.IsNil)
+	app.AdvanceTestBlock()
+	recovery, err := app.State.AccountRecovery(owner.Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, *recovery.NewOwner, qt.Equals, newOwner.Address())
+	qt.Assert(t, recovery.Approvals, qt.HasLen, 2)
+	qt.Assert(t, execute(other), qt.ErrorMatches, ".*cannot be executed until.*")
+
+	// should cancel the recovery when the owner sets its guardians again
+	qt.Assert(t, sendSetAccountTx(owner, func(nonce uint32) *models.SetAccountTx {
+		return vochaintx.NewSetRecoveryGuardiansTx(nonce, owner.Address().Bytes(), addrs, 2, 2)
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+	recovery, err = app.State.AccountRecovery(owner.Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, recovery, qt.IsNil)
+
+	// should rotate the owner key once the quorum approves it and the timelock elapses
+	qt.Assert(t, approve(guardians[2], newOwner.Address()), qt.IsNil)
+	qt.Assert(t, approve(guardians[0], newOwner.Address()), qt.IsNil)
+	app.AdvanceTestBlock()
+	app.AdvanceTestBlock()
+	qt.Assert(t, execute(other), qt.IsNil)
+	app.AdvanceTestBlock()
+	accOwner, err := app.State.AccountOwner(owner.Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, *accOwner, qt.Equals, newOwner.Address())
+
+	// should reject the transactions signed by the previous key
+	qt.Assert(t, sendSetAccountTx(owner, func(nonce uint32) *models.SetAccountTx {
+		return vochaintx.NewSetRecoveryGuardiansTx(nonce, nil, addrs, 1, 1)
+	}), qt.ErrorMatches, ".*owner key of the account was rotated.*")
+
+	// should accept the processes of the account signed by the new owner
+	censusURI := ipfsUrlTest
+	txb, err := proto.Marshal(&models.Tx{Payload: &models.Tx_NewProcess{NewProcess: &models.NewProcessTx{
+		Txtype: models.TxType_NEW_PROCESS,
+		Nonce:  nonces[owner.Address()],
+		Process: &models.Process{
+			StartBlock:    0,
+			EnvelopeType:  &models.EnvelopeType{},
+			Mode:          &models.ProcessMode{Interruptible: true},
+			VoteOptions:   &models.ProcessVoteOptions{MaxCount: 16, MaxValue: 16},
+			Status:        models.ProcessStatus_READY,
+			EntityId:      owner.Address().Bytes(),
+			CensusRoot:    util.RandomBytes(32),
+			CensusURI:     &censusURI,
+			CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+			BlockCount:    1024,
+			MaxCensusSize: 100,
+		},
+	}}})
+	qt.Assert(t, err, qt.IsNil)
+	sendRecoveredTx := func(signer *ethereum.SignKeys) error {
+		signature, err := signer.SignVocdoniTx(txb, app.chainID)
+		qt.Assert(t, err, qt.IsNil)
+		stx, err := vochaintx.NewRecoveredAccountSignedTx(txb, owner.Address(), signature)
+		qt.Assert(t, err, qt.IsNil)
+		_, err = testCheckTxDeliverTxCommit(t, app, stx)
+		return err
+	}
+	qt.Assert(t, sendRecoveredTx(other), qt.ErrorMatches, ".*is not the owner.*")
+	qt.Assert(t, sendRecoveredTx(newOwner), qt.IsNil)
+	acc, err = app.State.GetAccount(owner.Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, acc.ProcessIndex, qt.Equals, uint32(1))
+}
+
+func TestAccountRecoveryFork(t *testing.T) {
+	app := TestBaseApplicationWithForks(t, map[string]uint32{state.ForkRecovery: 3})
+	owner, guardian := &ethereum.SignKeys{}, &ethereum.SignKeys{}
+	for _, k := range []*ethereum.SignKeys{owner, guardian} {
+		qt.Assert(t, k.Generate(), qt.IsNil)
+		qt.Assert(t, app.State.CreateAccount(k.Address(), "ipfs://", [][]byte{}, 0), qt.IsNil)
+		qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+			ToAddress: k.Address(),
+			Amount:    1000,
+		}), qt.IsNil)
+	}
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_SEND_TOKENS, 10), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	nonce := uint32(0)
+	send := func(payload *models.Tx) error {
+		stx := &models.SignedTx{}
+		var err error
+		stx.Tx, err = proto.Marshal(payload)
+		qt.Assert(t, err, qt.IsNil)
+		if err := sendTx(app, owner, stx); err != nil {
+			return err
+		}
+		nonce++
+		return nil
+	}
+	setGuardians := func() error {
+		return send(&models.Tx{Payload: &models.Tx_SetAccount{
+			SetAccount: vochaintx.NewSetRecoveryGuardiansTx(nonce, nil, []common.Address{guardian.Address()}, 1, 0),
+		}})
+	}
+	sendTokens := func() error {
+		return send(&models.Tx{Payload: &models.Tx_SendTokens{SendTokens: &models.SendTokensTx{
+			Txtype: models.TxType_SEND_TOKENS,
+			From:   owner.Address().Bytes(),
+			To:     guardian.Address().Bytes(),
+			Value:  1,
+			Nonce:  nonce,
+		}}})
+	}
+
+	// the recovery transactions are rejected before the fork
+	qt.Assert(t, setGuardians(), qt.ErrorMatches, ".*fork not active: recovery")
+
+	// and the owner of an account is ignored, so its key still signs for it
+	qt.Assert(t, app.State.SetRecoveryGuardians(owner.Address(), &state.RecoveryGuardians{
+		Guardians: []common.Address{guardian.Address()},
+		Quorum:    1,
+		Timelock:  1,
+	}), qt.IsNil)
+	_, err := app.State.ApproveAccountRecovery(owner.Address(), guardian.Address(), guardian.Address())
+	qt.Assert(t, err, qt.IsNil)
+	app.AdvanceTestBlock()
+	_, err = app.State.ExecuteAccountRecovery(owner.Address())
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, sendTokens(), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// but not since the fork
+	app.AdvanceTestBlocksUntilHeight(3)
+	qt.Assert(t, sendTokens(), qt.ErrorMatches, ".*owner key of the account was rotated.*")
+}
+
+func TestFeeMultiplierBlockTxs(t *testing.T) {
+	app := TestBaseApplicationWithForks(t, map[string]uint32{state.ForkFeeMarket: 3})
+	signer := ethereum.SignKeys{}
+	qt.Assert(t, signer.Generate(), qt.IsNil)
+	qt.Assert(t, app.State.CreateAccount(signer.Address(), "ipfs://", [][]byte{}, 0), qt.IsNil)
+	qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+		ToAddress: signer.Address(),
+		Amount:    10000,
+	}), qt.IsNil)
+	qt.Assert(t, app.State.CreateAccount(common.Address{1}, "ipfs://", [][]byte{}, 0), qt.IsNil)
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_SEND_TOKENS, 10), qt.IsNil)
+	qt.Assert(t, app.State.SetFeeMarket(&state.FeeMarket{TargetBlockTxs: 2, MaxMultiplier: 3}), qt.IsNil)
+	app.AdvanceTestBlock()
+	multiplier := func() uint64 {
+		m, err := app.State.FeeMultiplier(false)
+		qt.Assert(t, err, qt.IsNil)
+		return m
+	}
+	nonce := uint32(0)
+	sendTokensTxs := func(n int) [][]byte {
+		var txs [][]byte
+		for range n {
+			stx := &models.SignedTx{}
+			var err error
+			stx.Tx, err = proto.Marshal(&models.Tx{Payload: &models.Tx_SendTokens{SendTokens: &models.SendTokensTx{
+				Txtype: models.TxType_SEND_TOKENS,
+				From:   signer.Address().Bytes(),
+				To:     common.Address{1}.Bytes(),
+				Value:  1,
+				Nonce:  nonce,
+			}}})
+			qt.Assert(t, err, qt.IsNil)
+			stx.Signature, err = signer.SignVocdoniTx(stx.Tx, app.chainID)
+			qt.Assert(t, err, qt.IsNil)
+			b, err := proto.Marshal(stx)
+			qt.Assert(t, err, qt.IsNil)
+			txs = append(txs, b)
+			nonce++
+		}
+		return txs
+	}
+	deliver := func(txs [][]byte) {
+		for _, tx := range txs {
+			qt.Assert(t, app.deliverTx(tx).Code, qt.Equals, uint32(0))
+		}
+	}
+
+	// the fee multiplier does not change before the fork
+	deliver(sendTokensTxs(4))
+	app.AdvanceTestBlock()
+	qt.Assert(t, multiplier(), qt.Equals, uint64(state.FeeMultiplierUnit))
+	app.AdvanceTestBlocksUntilHeight(3)
+
+	// the invalid transactions and the ones without cost do not count
+	for _, tx := range [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")} {
+		qt.Assert(t, app.deliverTx(tx).Code, qt.Not(qt.Equals), uint32(0))
+	}
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_SEND_TOKENS, 0), qt.IsNil)
+	deliver(sendTokensTxs(4))
+	app.AdvanceTestBlock()
+	qt.Assert(t, multiplier(), qt.Equals, uint64(state.FeeMultiplierUnit))
+
+	// the ones which pay a cost do, both in the test blocks and when the
+	// blocks are executed
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_SEND_TOKENS, 10), qt.IsNil)
+	deliver(sendTokensTxs(4))
+	app.AdvanceTestBlock()
+	qt.Assert(t, multiplier(), qt.Equals, uint64(1125))
+
+	_, err := app.ExecuteBlock(sendTokensTxs(4), app.Height(), time.Now())
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, multiplier(), qt.Equals, uint64(1265))
+}
@@ -25,6 +25,7 @@ import (
 	vstate "go.vocdoni.io/dvote/vochain/state"
 	"go.vocdoni.io/dvote/vochain/transaction"
 	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/dvote/vochain/txaudit"
 
 	"go.vocdoni.io/dvote/log"
 	"go.vocdoni.io/proto/build/go/models"
@@ -37,6 +38,12 @@ const (
 	// transactionBlocksTTL is the number of blocks after which a transaction is
 	// removed from the mempool.
 	transactionBlocksTTL = 6 * 10 // 10 minutes
+	// maxTxScheduleBlocks is the maximum number of blocks in the future a
+	// transaction can be scheduled for, with its validFromHeight.
+	maxTxScheduleBlocks = 6 * 60 * 24 * 7 // 1 week
+	// maxScheduledTxsPerSender is the maximum number of scheduled transactions
+	// of a sender waiting in the mempool for their validity window.
+	maxScheduledTxsPerSender = 16
 	// maxPendingTxAttempts is the number of times a transaction can be included in a block
 	// and fail before being removed from the mempool.
 	maxPendingTxAttempts = 3
@@ -63,7 +70,10 @@ type BaseApplication struct {
 	NodeAddress        ethcommon.Address
 	TransactionHandler *transaction.TransactionHandler
 	Snapshots          *snapshot.SnapshotManager
-	isSynchronizingFn  func() bool
+	TxAudit            *txaudit.Auditor
+	// receipts records the receipt of the transaction being delivered.
+	receipts          *receiptRecorder
+	isSynchronizingFn func() bool
 	// tendermint WaitSync() function is racy, we need to use a mutex in order to avoid
 	// data races when querying about the sync status of the blockchain.
 	isSynced atomic.Bool
@@ -80,9 +90,18 @@ type BaseApplication struct {
 	// txLReferences is a map indexed by hashed transactions and storing the height where the transaction
 	// was seen frist time and the number of attempts failed for including it into a block.
 	txReferences sync.Map
+	// scheduledTxs holds the scheduled transactions of each sender waiting in
+	// the mempool, with the height their validity window starts.
+	scheduledTxs    map[ethcommon.Address]map[[32]byte]uint32
+	scheduledTxsMtx sync.Mutex
 
 	// blockTime is the target block time that miners use
-	blockTime time.Duration
+	blockTime atomic.Int64
+	// blockTiming is the block timing of the state applied on start, see
+	// updateBlockTiming, and pendingBlockTiming the last one committed, see
+	// checkBlockTiming.
+	blockTiming        vstate.BlockTiming
+	pendingBlockTiming vstate.BlockTiming
 
 	// snapshotInterval create state snapshot every N blocks (0 to disable)
 	snapshotInterval int
@@ -114,6 +133,9 @@ type BaseApplication struct {
 type pendingTxReference struct {
 	height      uint32
 	failedCount int
+	// scheduledBy is the sender of the transaction if it was scheduled, see
+	// addScheduledTx.
+	scheduledBy *ethcommon.Address
 }
 
 // DeliverTxResponse is the response returned by DeliverTx after executing the transaction.
@@ -123,6 +145,8 @@ type DeliverTxResponse struct {
 	Info string
 	Data []byte
 	TxID [32]byte
+	// Receipt is the receipt of the transaction, nil if it failed.
+	Receipt *vochaintx.Receipt
 }
 
 // ExecuteBlockResponse is the response returned by ExecuteBlock after executing the block.
@@ -147,13 +171,21 @@ func NewBaseApplication(vochainCfg *config.VochainCfg) (*BaseApplication, error)
 	// Create the transaction handler for checking and processing transactions
 	transactionHandler := transaction.NewTransactionHandler(state, istc)
 
+	// Record the receipts of the delivered transactions
+	receipts := &receiptRecorder{}
+	state.AddEventListener(receipts)
+
 	snaps, err := snapshot.NewManager(filepath.Join(vochainCfg.DataDir, SnapshotsDataDir), vochainCfg.StateSyncChunkSize)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create snapshot manager: %w", err)
 	}
 
 	// Initialize the zk circuit
-	if err := circuit.Init(); err != nil {
+	zkCircuitVersion := vochainCfg.ZkCircuitVersion
+	if zkCircuitVersion == "" {
+		zkCircuitVersion = circuit.DefaultZkCircuitVersion
+	}
+	if err := circuit.SetGlobal(zkCircuitVersion); err != nil {
 		return nil, fmt.Errorf("cannot load zk circuit: %w", err)
 	}
 
@@ -161,11 +193,17 @@ func NewBaseApplication(vochainCfg *config.VochainCfg) (*BaseApplication, error)
 	if err != nil {
 		return nil, err
 	}
+	txAudit, err := txaudit.New(txaudit.DefaultMaxAccounts, txaudit.DefaultMaxTxsPerAccount)
+	if err != nil {
+		return nil, err
+	}
 	return &BaseApplication{
 		State:              state,
 		Istc:               istc,
 		TransactionHandler: transactionHandler,
 		Snapshots:          snaps,
+		TxAudit:            txAudit,
+		receipts:           receipts,
 		blockCache:         blockCache,
 		dataDir:            vochainCfg.DataDir,
 		dbType:             vochainCfg.DBType,
@@ -202,6 +240,16 @@ func (app *BaseApplication) ExecuteBlock(txs [][]byte, height uint32, blockTime
 	if err := app.Istc.Commit(height, timestamp); err != nil {
 		return nil, fmt.Errorf("cannot execute ISTC commit: %w", err)
 	}
+	// purge the SIKs that expire in this block
+	if err := app.State.PurgeExpiredSIKs(height); err != nil {
+		return nil, fmt.Errorf("cannot purge expired SIKs: %w", err)
+	}
+	// adjust the transaction costs of the next block to the utilization of this
+	// one, counting the delivered transactions which paid a cost as
+	// AdvanceTestBlock does
+	if err := app.State.UpdateFeeMultiplier(uint32(app.State.CostTxCounter())); err != nil {
+		return nil, fmt.Errorf("cannot update fee multiplier: %w", err)
+	}
 	app.endBlock(blockTime, height)
 	root, err := app.State.PrepareCommit()
 	if err != nil {
@@ -225,6 +273,7 @@ func (app *BaseApplication) CommitState() ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("cannot save state: %w", err)
 	}
+	app.checkBlockTiming()
 
 	// perform state snapshot
 	if app.snapshotInterval > 0 &&
@@ -240,6 +289,62 @@ func (app *BaseApplication) CommitState() ([]byte, error) {
 	return hash, err
 }
 
+// checkValidHeight returns the error of tx.CheckValidHeight at the given height,
+// once the ForkTxValidity fork is applied at it.
+func (app *BaseApplication) checkValidHeight(tx *vochaintx.Tx, height uint32) error {
+	active, err := app.State.ForkActiveAt(vstate.ForkTxValidity, height)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return nil
+	}
+	return tx.CheckValidHeight(height)
+}
+
+// addScheduledTx records a scheduled transaction of the sender waiting in the
+// mempool, unless the sender already has maxScheduledTxsPerSender of them. The
+// transactions whose window started more than transactionBlocksTTL blocks ago
+// are no longer counted, as they left the mempool.
+func (app *BaseApplication) addScheduledTx(sender ethcommon.Address, txID [32]byte, validFrom uint32) error {
+	app.scheduledTxsMtx.Lock()
+	defer app.scheduledTxsMtx.Unlock()
+	if app.scheduledTxs == nil {
+		app.scheduledTxs = make(map[ethcommon.Address]map[[32]byte]uint32)
+	}
+	txs := app.scheduledTxs[sender]
+	if txs == nil {
+		txs = make(map[[32]byte]uint32)
+		app.scheduledTxs[sender] = txs
+	}
+	for id, height := range txs {
+		if app.Height() > height+transactionBlocksTTL {
+			delete(txs, id)
+		}
+	}
+	if _, ok := txs[txID]; !ok && len(txs) >= maxScheduledTxsPerSender {
+		return fmt.Errorf("sender %s has too many scheduled transactions", sender.Hex())
+	}
+	txs[txID] = validFrom
+	return nil
+}
+
+// deleteTxReference removes the reference of a transaction that left the
+// mempool, and its scheduled transaction record if it was scheduled.
+func (app *BaseApplication) deleteTxReference(txID [32]byte) {
+	ref, ok := app.txReferences.LoadAndDelete(txID)
+	if !ok || ref.(*pendingTxReference).scheduledBy == nil {
+		return
+	}
+	sender := *ref.(*pendingTxReference).scheduledBy
+	app.scheduledTxsMtx.Lock()
+	defer app.scheduledTxsMtx.Unlock()
+	delete(app.scheduledTxs[sender], txID)
+	if len(app.scheduledTxs[sender]) == 0 {
+		delete(app.scheduledTxs, sender)
+	}
+}
+
 // deliverTx unmarshals req.Tx and adds it to the State if it is valid
 func (app *BaseApplication) deliverTx(rawTx []byte) *DeliverTxResponse {
 	// Increase Tx counter on return since the index 0 is valid
@@ -254,23 +359,34 @@ func (app *BaseApplication) deliverTx(rawTx []byte) *DeliverTxResponse {
 		"height", app.Height(),
 		"tx", tx.Tx,
 	)
-	// check tx is correct on the current state
+	if err := app.checkValidHeight(tx, app.Height()); err != nil {
+		log.Errorw(err, "rejected tx")
+		return &DeliverTxResponse{Code: 1, TxID: tx.TxID, Data: []byte(err.Error())}
+	}
+	// check tx is correct on the current state, recording its receipt
+	app.receipts.start()
 	response, err := app.TransactionHandler.CheckTx(tx, true)
+	receipt := app.receipts.stop()
 	if err != nil {
 		log.Errorw(err, "rejected tx")
 		return &DeliverTxResponse{Code: 1, TxID: tx.TxID, Data: []byte(err.Error())}
 	}
-	app.txReferences.Delete(tx.TxID)
+	app.deleteTxReference(tx.TxID)
+	if receipt.Cost > 0 {
+		app.State.CostTxCounterAdd()
+	}
 	// call event listeners
 	for _, e := range app.State.EventListeners() {
 		e.OnNewTx(tx, app.Height(), app.State.TxCounter())
+		e.OnTxReceipt(tx, receipt, app.State.TxCounter())
 	}
 	return &DeliverTxResponse{
-		Code: 0,
-		Data: response.Data,
-		Info: fmt.Sprintf("%x", response.TxHash),
-		Log:  response.Log,
-		TxID: tx.TxID,
+		Code:    0,
+		Data:    response.Data,
+		Info:    fmt.Sprintf("%x", response.TxHash),
+		Log:     response.Log,
+		TxID:    tx.TxID,
+		Receipt: receipt,
 	}
 }
 
@@ -454,13 +570,13 @@ func (app *BaseApplication) MempoolSize() int {
 
 // BlockTimeTarget returns the current block time target
 func (app *BaseApplication) BlockTimeTarget() time.Duration {
-	if app.blockTime == 0 {
-		return types.DefaultBlockTime
+	if d := app.blockTime.Load(); d != 0 {
+		return time.Duration(d)
 	}
-	return app.blockTime
+	return types.DefaultBlockTime
 }
 
 // SetBlockTimeTarget sets the current block time target
 func (app *BaseApplication) SetBlockTimeTarget(d time.Duration) {
-	app.blockTime = d
+	app.blockTime.Store(int64(d))
 }
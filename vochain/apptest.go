@@ -2,6 +2,7 @@ package vochain
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -10,7 +11,9 @@ import (
 	cometcoretypes "github.com/cometbft/cometbft/rpc/core/types"
 	comettypes "github.com/cometbft/cometbft/types"
 	"go.vocdoni.io/dvote/config"
+	"go.vocdoni.io/dvote/crypto/zk/circuit"
 	"go.vocdoni.io/dvote/db/metadb"
+	"go.vocdoni.io/dvote/vochain/genesis"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/proto"
 )
@@ -28,9 +31,24 @@ func TestBaseApplication(tb testing.TB) *BaseApplication {
 // Once the application is create, it is the caller's responsibility to call
 // app.AdvanceTestBlock() to advance the block height and commit the state.
 func TestBaseApplicationWithChainID(tb testing.TB, chainID string) *BaseApplication {
+	return testBaseApplication(tb, chainID, nil)
+}
+
+// TestBaseApplicationWithForks creates a new BaseApplication for testing
+// purposes as TestBaseApplication, whose genesis sets the given fork heights
+// instead of applying all the forks from the first block.
+func TestBaseApplicationWithForks(tb testing.TB, forks map[string]uint32) *BaseApplication {
+	if forks == nil {
+		forks = make(map[string]uint32)
+	}
+	return testBaseApplication(tb, "test", forks)
+}
+
+func testBaseApplication(tb testing.TB, chainID string, forks map[string]uint32) *BaseApplication {
 	app, err := NewBaseApplication(&config.VochainCfg{
-		DBType:  metadb.ForTest(),
-		DataDir: tb.TempDir(),
+		DBType:           metadb.ForTest(),
+		DataDir:          tb.TempDir(),
+		ZkCircuitVersion: testZkCircuitVersion(),
 	})
 	if err != nil {
 		tb.Fatal(err)
@@ -40,6 +58,16 @@ func TestBaseApplicationWithChainID(tb testing.TB, chainID string) *BaseApplicat
 	if err != nil {
 		tb.Fatal(err)
 	}
+	if forks != nil {
+		appState := &genesis.AppState{}
+		if err := json.Unmarshal(genesisDoc.AppState, appState); err != nil {
+			tb.Fatal(err)
+		}
+		appState.Forks = forks
+		if genesisDoc.AppState, err = json.Marshal(appState); err != nil {
+			tb.Fatal(err)
+		}
+	}
 	_, err = app.InitChain(context.TODO(), &cometabcitypes.InitChainRequest{
 		Time:          time.Unix(0, 0),
 		ChainId:       chainID,
@@ -64,6 +92,16 @@ func TestBaseApplicationWithChainID(tb testing.TB, chainID string) *BaseApplicat
 	return app
 }
 
+// testZkCircuitVersion returns the dev circuit if it is embedded, so the tests
+// do not download the circuit artifacts, or the default one otherwise. The
+// tests which generate zk proofs load v1.0.0 anyway.
+func testZkCircuitVersion() string {
+	if circuit.DevCircuitAvailable() {
+		return circuit.VDev
+	}
+	return ""
+}
+
 // SetTestingMethods assigns fnGetBlockByHash, fnGetBlockByHeight, fnSendTx to use mockBlockStore
 func (app *BaseApplication) SetTestingMethods() {
 	app.SetFnGetBlockByHash(app.testMockBlockStore.GetByHash)
@@ -121,13 +159,19 @@ func (app *BaseApplication) AdvanceTestBlock() {
 	if err := app.Istc.Commit(height, ts); err != nil {
 		panic(err)
 	}
+	if err := app.State.PurgeExpiredSIKs(height); err != nil {
+		panic(err)
+	}
+	if err := app.State.UpdateFeeMultiplier(uint32(app.State.CostTxCounter())); err != nil {
+		panic(err)
+	}
 	// finalize block
 	app.endBlock(time.Unix(int64(ts), 0), height)
 	// save the state
 	if _, err = app.State.PrepareCommit(); err != nil {
 		panic(err)
 	}
-	_, err = app.CommitState()
+	appHash, err := app.CommitState()
 	if err != nil {
 		panic(err)
 	}
@@ -136,7 +180,7 @@ func (app *BaseApplication) AdvanceTestBlock() {
 	time.Sleep(time.Microsecond * 50)
 	// nextStartTime is the previous block timestamp + second
 	nextStartTime := time.Unix(int64(ts+1), 0)
-	app.testMockBlockStore.NewBlock(newHeight, nextStartTime)
+	app.testMockBlockStore.NewBlock(newHeight, nextStartTime, appHash)
 	app.beginBlock(nextStartTime, uint32(newHeight))
 }
 
@@ -139,6 +139,7 @@ func NewTemplateGenesisFile(dir string, validators int) (*genesis.Doc, error) {
 			},
 		},
 		TxCost: genesis.DefaultTransactionCosts(),
+		Forks:  genesis.AllForks(),
 	}
 	appState.MaxElectionSize = 100000
 
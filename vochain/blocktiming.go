@@ -0,0 +1,67 @@
+package vochain
+
+import (
+	"time"
+
+	cometconfig "github.com/cometbft/cometbft/config"
+	"go.vocdoni.io/dvote/log"
+)
+
+// setConsensusTiming sets the consensus timeouts for the target block time,
+// and the time the node waits for new transactions before proposing an empty
+// block, zero to always create them.
+func setConsensusTiming(c *cometconfig.ConsensusConfig, blockTime, emptyBlocksInterval time.Duration) {
+	c.TimeoutProposeDelta = time.Millisecond * 200
+	c.TimeoutPropose = blockTime * 6 / 10
+	c.TimeoutPrevoteDelta = time.Millisecond * 200
+	c.TimeoutPrevote = time.Second * 1
+	c.TimeoutPrecommitDelta = time.Millisecond * 200
+	c.TimeoutPrecommit = time.Second * 1
+	c.TimeoutCommit = blockTime
+	// the empty blocks are never suppressed entirely, since the processes last
+	// a number of blocks and would never end on an idle network
+	c.CreateEmptyBlocks = true
+	c.CreateEmptyBlocksInterval = emptyBlocksInterval
+}
+
+// updateBlockTiming applies the block timing of the committed state, if it was
+// set, to the block time target and to the consensus config c. It is only
+// called before the node is started, since the consensus config cannot be
+// changed on a running node, so a new block timing is applied by each node once
+// restarted, see checkBlockTiming.
+func (app *BaseApplication) updateBlockTiming(c *cometconfig.ConsensusConfig) {
+	timing, err := app.State.BlockTiming(true)
+	if err != nil {
+		log.Warnw("cannot get the block timing", "err", err)
+		return
+	}
+	if timing == nil {
+		return
+	}
+	app.blockTiming = *timing
+	app.pendingBlockTiming = *timing
+	blockTime := time.Duration(timing.BlockTime) * time.Second
+	emptyBlocksInterval := time.Duration(timing.EmptyBlocksInterval) * time.Second
+	app.SetBlockTimeTarget(blockTime)
+	setConsensusTiming(c, blockTime, emptyBlocksInterval)
+	log.Infow("block timing applied", "blockTime", blockTime, "emptyBlocksInterval", emptyBlocksInterval)
+}
+
+// checkBlockTiming warns once when the block timing of the committed state
+// changes, since it is only applied by the node once restarted.
+func (app *BaseApplication) checkBlockTiming() {
+	timing, err := app.State.BlockTiming(true)
+	if err != nil {
+		log.Warnw("cannot get the block timing", "err", err)
+		return
+	}
+	if timing == nil || *timing == app.pendingBlockTiming {
+		return
+	}
+	app.pendingBlockTiming = *timing
+	if *timing == app.blockTiming {
+		return
+	}
+	log.Warnw("block timing changed, it is applied once the node is restarted",
+		"blockTime", timing.BlockTime, "emptyBlocksInterval", timing.EmptyBlocksInterval)
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"os"
 	"path/filepath"
@@ -185,6 +186,48 @@ func (app *BaseApplication) InitChain(_ context.Context,
 		return nil, fmt.Errorf("unable to set  network capacity")
 	}
 
+	// set the validity of the SIKs, only if they expire to keep the state of
+	// the existing chains
+	if genesisAppState.SIKValidity > 0 {
+		if err := app.State.SetSIKValidity(genesisAppState.SIKValidity); err != nil {
+			return nil, fmt.Errorf("cannot set SIK validity: %w", err)
+		}
+	}
+
+	// set the block timing, only if set to keep the state of the existing chains
+	if genesisAppState.BlockTime > 0 {
+		if err := app.State.SetBlockTiming(&state.BlockTiming{
+			BlockTime:           genesisAppState.BlockTime,
+			EmptyBlocksInterval: genesisAppState.EmptyBlocksInterval,
+		}); err != nil {
+			return nil, fmt.Errorf("cannot set block timing: %w", err)
+		}
+	}
+
+	// enable the dynamic transaction costs, only if set to keep the state of the existing chains
+	if genesisAppState.FeeMarketTargetBlockTxs > 0 {
+		if err := app.State.SetFeeMarket(&state.FeeMarket{
+			TargetBlockTxs: genesisAppState.FeeMarketTargetBlockTxs,
+			MaxMultiplier:  genesisAppState.FeeMarketMaxMultiplier,
+		}); err != nil {
+			return nil, fmt.Errorf("cannot set fee market: %w", err)
+		}
+	}
+
+	// enable the gas metering, only if set to keep the state of the existing chains
+	if genesisAppState.BlockGasLimit > 0 {
+		if err := app.State.SetGasSchedule(genesisAppState.GasSchedule()); err != nil {
+			return nil, fmt.Errorf("cannot set gas schedule: %w", err)
+		}
+	}
+
+	// set the heights of the forks, which are never applied unless set
+	for _, fork := range slices.Sorted(maps.Keys(genesisAppState.Forks)) {
+		if err := app.State.SetForkHeight(fork, genesisAppState.Forks[fork]); err != nil {
+			return nil, fmt.Errorf("cannot set fork height: %w", err)
+		}
+	}
+
 	// initialize election price calc
 	if err := app.State.SetElectionPriceCalc(); err != nil {
 		return nil, fmt.Errorf("cannot set election price calc: %w", err)
@@ -237,7 +280,7 @@ func (app *BaseApplication) CheckTx(_ context.Context, req *cometabcitypes.Check
 		if app.Height() > height+transactionBlocksTTL {
 			// remove tx reference and return checkTx error
 			log.Debugw("pruning expired tx from mempool", "height", app.Height(), "hash", fmt.Sprintf("%x", txReference))
-			app.txReferences.Delete(txReference)
+			app.deleteTxReference(txReference)
 			return &cometabcitypes.CheckTxResponse{Code: 1, Data: []byte(fmt.Sprintf("tx expired %x", txReference))}, nil
 		}
 	}
@@ -252,6 +295,50 @@ func (app *BaseApplication) CheckTx(_ context.Context, req *cometabcitypes.Check
 	if err := tx.Unmarshal(req.Tx, app.ChainID()); err != nil {
 		return &cometabcitypes.CheckTxResponse{Code: 1, Data: []byte(err.Error()), Log: err.Error()}, nil
 	}
+	// scheduled transactions wait in the mempool until their validity window
+	// starts, so they are checked as if they were executed now, and each sender
+	// can only have a few of them waiting
+	if err := app.checkValidHeight(tx, app.Height()); err != nil {
+		if !errors.Is(err, vochaintx.ErrTxNotYetValid) {
+			app.deleteTxReference(txReference)
+			return &cometabcitypes.CheckTxResponse{Code: 1, Data: []byte(err.Error()), Log: err.Error()}, nil
+		}
+		if tx.ValidFromHeight > app.Height()+maxTxScheduleBlocks {
+			err := fmt.Errorf("transaction cannot be scheduled more than %d blocks ahead", maxTxScheduleBlocks)
+			app.deleteTxReference(txReference)
+			return &cometabcitypes.CheckTxResponse{Code: 1, Data: []byte(err.Error()), Log: err.Error()}, nil
+		}
+		sender, err := ethereum.AddrFromSignature(tx.SignedBody, tx.Signature)
+		if err != nil {
+			err := fmt.Errorf("invalid signature: %w", err)
+			app.deleteTxReference(txReference)
+			return &cometabcitypes.CheckTxResponse{Code: 1, Data: []byte(err.Error()), Log: err.Error()}, nil
+		}
+		if _, err := app.TransactionHandler.CheckTx(tx, false); err != nil &&
+			!errors.Is(err, transaction.ErrorAlreadyExistInCache) {
+			app.deleteTxReference(txReference)
+			return &cometabcitypes.CheckTxResponse{Code: 1, Data: []byte(err.Error()), Log: err.Error()}, nil
+		}
+		if err := app.addScheduledTx(sender, txReference, tx.ValidFromHeight); err != nil {
+			app.deleteTxReference(txReference)
+			return &cometabcitypes.CheckTxResponse{Code: 1, Data: []byte(err.Error()), Log: err.Error()}, nil
+		}
+		// the mempool TTL starts counting once the transaction is valid
+		if !ok {
+			app.txReferences.Store(txReference, &pendingTxReference{
+				height:      tx.ValidFromHeight,
+				scheduledBy: &sender,
+			})
+		}
+		return &cometabcitypes.CheckTxResponse{Code: 0}, nil
+	}
+	// keep track of the nonce-bearing transactions received, before they are checked,
+	// so replay attempts (rejected due to the nonce) are recorded too
+	if req.Type == cometabcitypes.CHECK_TX_TYPE_CHECK && app.TxAudit != nil {
+		if addr, nonce, err := app.TransactionHandler.ExtractNonceAndSender(tx); err == nil && addr != nil {
+			app.TxAudit.AddTx(*addr, tx.TxID[:], nonce, tx.TxModelType, app.Height())
+		}
+	}
 	response, err := app.TransactionHandler.CheckTx(tx, false)
 	if err != nil {
 		if errors.Is(err, transaction.ErrorAlreadyExistInCache) {
@@ -260,11 +347,16 @@ func (app *BaseApplication) CheckTx(_ context.Context, req *cometabcitypes.Check
 		log.Errorw(err, "checkTx")
 		return &cometabcitypes.CheckTxResponse{Code: 1, Data: []byte(err.Error()), Log: err.Error()}, nil
 	}
+	var gasWanted int64
+	if gasSchedule, err := app.State.GasSchedule(true); err == nil && gasSchedule != nil {
+		gasWanted = int64(gasSchedule.Gas(tx))
+	}
 	return &cometabcitypes.CheckTxResponse{
-		Code: 0,
-		Data: response.Data,
-		Info: fmt.Sprintf("%x", response.TxHash),
-		Log:  response.Log,
+		Code:      0,
+		Data:      response.Data,
+		Info:      fmt.Sprintf("%x", response.TxHash),
+		Log:       response.Log,
+		GasWanted: gasWanted,
 	}, nil
 }
 
@@ -306,6 +398,10 @@ func (app *BaseApplication) FinalizeBlock(_ context.Context,
 			Log:  tx.Log,
 			Info: tx.Info,
 		}
+		if tx.Receipt != nil {
+			txResults[i].GasUsed = int64(tx.Receipt.Cost)
+			txResults[i].Events = abciEvents(tx.Receipt.Events)
+		}
 	}
 
 	if len(req.Txs) > 0 {
@@ -347,6 +443,34 @@ func (app *BaseApplication) FinalizeBlock(_ context.Context,
 	}, nil
 }
 
+// abciEvents returns the events of a transaction receipt as ABCI events, whose
+// attributes are indexed by the event sink of CometBFT.
+func abciEvents(events []vochaintx.Event) []cometabcitypes.Event {
+	abciEvents := make([]cometabcitypes.Event, 0, len(events))
+	for _, e := range events {
+		attributes := make([]cometabcitypes.EventAttribute, 0, len(e.Attributes))
+		for _, a := range e.Attributes {
+			attributes = append(attributes, cometabcitypes.EventAttribute{Key: a.Key, Value: a.Value, Index: true})
+		}
+		abciEvents = append(abciEvents, cometabcitypes.Event{Type: e.Type, Attributes: attributes})
+	}
+	return abciEvents
+}
+
+// blockGas returns the gas of the transactions of a block, skipping the ones
+// which cannot be decoded, since they are not executed.
+func (app *BaseApplication) blockGas(gasSchedule *state.GasSchedule, txs [][]byte) uint64 {
+	var gas uint64
+	for _, tx := range txs {
+		vtx := new(vochaintx.Tx)
+		if err := vtx.Unmarshal(tx, app.ChainID()); err != nil {
+			continue
+		}
+		gas += gasSchedule.Gas(vtx)
+	}
+	return gas
+}
+
 func validatorUpdate(validators map[string]*models.Validator) cometabcitypes.ValidatorUpdates {
 	validatorUpdate := []cometabcitypes.ValidatorUpdate{}
 	for _, v := range validators {
@@ -413,6 +537,13 @@ func (app *BaseApplication) PrepareProposal(ctx context.Context,
 			log.Warnw("could not unmarshal transaction", "err", err)
 			continue
 		}
+		// keep the scheduled transactions in the mempool until they are valid
+		if err := app.checkValidHeight(vtx, uint32(req.GetHeight())); err != nil {
+			if errors.Is(err, vochaintx.ErrTxExpired) {
+				app.MempoolDeleteTx(vtx.TxID)
+			}
+			continue
+		}
 		senderAddr, nonce, err := app.TransactionHandler.ExtractNonceAndSender(vtx)
 		if err != nil {
 			log.Warnw("could not extract nonce and/or sender from transaction", "err", err)
@@ -441,9 +572,32 @@ func (app *BaseApplication) PrepareProposal(ctx context.Context,
 		return cmp.Compare(a.Nonce, b.Nonce)
 	})
 
+	// Meter the gas of the block, so that its execution does not exceed the consensus timeouts
+	gasSchedule, err := app.State.GasSchedule(true)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get gas schedule: %w", err)
+	}
+	var blockGas uint64
+	deferredSenders := make(map[ethcommon.Address]bool)
+
 	// Check the validity of the transactions
 	validTxs := [][]byte{}
 	for _, txInfo := range validTxInfos {
+		var gas uint64
+		if gasSchedule != nil {
+			// the transactions over the gas limit wait in the mempool for the next blocks,
+			// and so do the next ones of their sender, since they depend on their nonce
+			if txInfo.Addr != nil && deferredSenders[*txInfo.Addr] {
+				continue
+			}
+			gas = gasSchedule.Gas(txInfo.DecodedTx)
+			if blockGas+gas > gasSchedule.BlockGasLimit {
+				if txInfo.Addr != nil {
+					deferredSenders[*txInfo.Addr] = true
+				}
+				continue
+			}
+		}
 		// Check the validity of the transaction using forCommit true
 		resp, err := app.TransactionHandler.CheckTx(txInfo.DecodedTx, true)
 		if err != nil {
@@ -463,7 +617,7 @@ func (app *BaseApplication) PrepareProposal(ctx context.Context,
 				if val.(*pendingTxReference).failedCount > maxPendingTxAttempts {
 					log.Debugf("transaction %x has reached max attempts, remove from mempool", txInfo.DecodedTx.TxID)
 					app.MempoolDeleteTx(txInfo.DecodedTx.TxID)
-					app.txReferences.Delete(txInfo.DecodedTx.TxID)
+					app.deleteTxReference(txInfo.DecodedTx.TxID)
 				} else {
 					app.txReferences.Store(txInfo.DecodedTx.TxID, val)
 				}
@@ -473,12 +627,13 @@ func (app *BaseApplication) PrepareProposal(ctx context.Context,
 			)
 			continue
 		}
+		blockGas += gas
 		validTxs = append(validTxs, txInfo.Data)
 	}
 
 	// Rollback the state to discard the changes made
 	app.State.Rollback()
-	log.Debugw("prepare proposal", "height", app.Height(), "txs", len(validTxs),
+	log.Debugw("prepare proposal", "height", app.Height(), "txs", len(validTxs), "gas", blockGas,
 		"milliSeconds", time.Since(startTime).Milliseconds())
 	return &cometabcitypes.PrepareProposalResponse{
 		Txs: validTxs,
@@ -524,6 +679,21 @@ func (app *BaseApplication) ProcessProposal(_ context.Context,
 		return &cometabcitypes.ProcessProposalResponse{Status: cometabcitypes.PROCESS_PROPOSAL_STATUS_ACCEPT}, nil
 	}
 
+	// reject the blocks over the gas limit, whose execution could exceed the consensus timeouts
+	gasSchedule, err := app.State.GasSchedule(true)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get gas schedule: %w", err)
+	}
+	if gasSchedule != nil {
+		if gas := app.blockGas(gasSchedule, req.Txs); gas > gasSchedule.BlockGasLimit {
+			log.Warnw("block over the gas limit on process proposal", "height", app.Height(), "gas", gas,
+				"limit", gasSchedule.BlockGasLimit, "proposer", hex.EncodeToString(req.ProposerAddress), "action", "reject")
+			return &cometabcitypes.ProcessProposalResponse{
+				Status: cometabcitypes.PROCESS_PROPOSAL_STATUS_REJECT,
+			}, nil
+		}
+	}
+
 	startTime := time.Now()
 	resp, err := app.ExecuteBlock(req.Txs, uint32(req.GetHeight()), req.GetTime())
 	if err != nil {
@@ -622,6 +792,31 @@ func (app *BaseApplication) OfferSnapshot(_ context.Context,
 		}, nil
 	}
 
+	if req.Snapshot.Chunks == 0 || uint64(metadata.Height) != req.Snapshot.Height ||
+		!bytes.Equal(metadata.Hash, req.Snapshot.Hash) {
+		log.Debugw("reject snapshot due to metadata not matching the snapshot",
+			"height", req.Snapshot.Height, "metadataHeight", metadata.Height, "chunks", req.Snapshot.Chunks)
+		return &cometabcitypes.OfferSnapshotResponse{
+			Result: cometabcitypes.OFFER_SNAPSHOT_RESULT_REJECT,
+		}, nil
+	}
+	// the root of the restored state must be the app hash verified by the light client,
+	// so don't download snapshots which would not pass that check once restored
+	if !bytes.Equal(metadata.Root, req.AppHash) {
+		log.Debugw("reject snapshot due to state root not matching the app hash",
+			"height", req.Snapshot.Height, "root", hex.EncodeToString(metadata.Root))
+		return &cometabcitypes.OfferSnapshotResponse{
+			Result: cometabcitypes.OFFER_SNAPSHOT_RESULT_REJECT,
+		}, nil
+	}
+	// discard the chunks of a previously offered snapshot which could not be restored
+	if err := app.Snapshots.ClearChunks(); err != nil {
+		log.Errorw(err, "couldn't remove the chunks of a previous snapshot")
+		return &cometabcitypes.OfferSnapshotResponse{
+			Result: cometabcitypes.OFFER_SNAPSHOT_RESULT_ABORT,
+		}, nil
+	}
+
 	snapshotFromComet.height.Store(int64(req.Snapshot.Height))
 	snapshotFromComet.chunks.Store(int32(req.Snapshot.Chunks))
 
@@ -659,6 +854,14 @@ func (app *BaseApplication) ApplySnapshotChunk(_ context.Context,
 	log.Debugw("cometbft provides us a chunk",
 		"index", req.Index, "size", len(req.Chunk))
 
+	if req.Index >= uint32(snapshotFromComet.chunks.Load()) {
+		log.Warnw("reject snapshot due to a chunk out of range", "index", req.Index,
+			"chunks", snapshotFromComet.chunks.Load(), "sender", req.Sender)
+		return &cometabcitypes.ApplySnapshotChunkResponse{
+			Result: cometabcitypes.APPLY_SNAPSHOT_CHUNK_RESULT_REJECT_SNAPSHOT,
+		}, nil
+	}
+
 	if err := app.Snapshots.WriteChunkToDisk(req.Index, req.Chunk); err != nil {
 		return &cometabcitypes.ApplySnapshotChunkResponse{
 			Result: cometabcitypes.APPLY_SNAPSHOT_CHUNK_RESULT_RETRY,
@@ -0,0 +1,273 @@
+package genesis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	crypto256k1 "github.com/cometbft/cometbft/crypto/secp256k1"
+	comettypes "github.com/cometbft/cometbft/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/state"
+)
+
+// Builder builds the genesis.Doc of a new network, validating it as a whole
+// and producing the same document for the same input, no matter the order in
+// which the accounts are added. The errors are reported by Build.
+//
+//	doc, err := genesis.NewBuilder("vocdoni/DEV/37", genesisTime).
+//		AddKeyKeeper(pubKey0, 10, "validator0").
+//		AddValidator(pubKey1, 10, "validator1").
+//		AddAccount(faucet, 100000).
+//		Build()
+type Builder struct {
+	doc      Doc
+	appState AppState
+	// forks are the fork heights of the chain, all the known forks applied
+	// from its first block if nil
+	forks map[string]uint32
+	errs  []error
+}
+
+// NewBuilder returns a Builder of the genesis of the chain, which starts at
+// genesisTime with the default consensus parameters and transaction costs, a
+// max election size of 100000 and a network capacity of 10000.
+func NewBuilder(chainID string, genesisTime time.Time) *Builder {
+	return &Builder{
+		doc: Doc{GenesisDoc: comettypes.GenesisDoc{
+			ChainID:         chainID,
+			GenesisTime:     genesisTime.UTC(),
+			InitialHeight:   1,
+			ConsensusParams: DefaultConsensusParams(),
+		}},
+		appState: AppState{
+			Validators: []AppStateValidators{},
+			Accounts:   []Account{},
+			TxCost:     DefaultTransactionCosts(),
+			// the same as the dev network
+			MaxElectionSize: 100000,
+			NetworkCapacity: 10000,
+		},
+	}
+}
+
+// InitialHeight sets the height of the first block of the chain.
+func (b *Builder) InitialHeight(height int64) *Builder {
+	b.doc.InitialHeight = height
+	return b
+}
+
+// ConsensusParams sets the consensus parameters of CometBFT.
+func (b *Builder) ConsensusParams(params *comettypes.ConsensusParams) *Builder {
+	b.doc.ConsensusParams = params
+	return b
+}
+
+// AddValidator adds a validator with the given secp256k1 public key, which is
+// also its signer key, voting power and name.
+func (b *Builder) AddValidator(pubKey types.HexBytes, power uint64, name string) *Builder {
+	return b.addValidator(pubKey, power, name, 0)
+}
+
+// AddKeyKeeper adds a validator as AddValidator, which is also a key keeper of
+// the encrypted elections, with the next free key index.
+func (b *Builder) AddKeyKeeper(pubKey types.HexBytes, power uint64, name string) *Builder {
+	keyIndex := uint8(1) // zero disables the key keeper
+	for _, v := range b.appState.Validators {
+		keyIndex = max(keyIndex, v.KeyIndex+1)
+	}
+	if keyIndex >= types.KeyKeeperMaxKeyIndex {
+		b.errs = append(b.errs, fmt.Errorf("validator %q: too many key keepers, the maximum is %d",
+			name, types.KeyKeeperMaxKeyIndex-1))
+		return b
+	}
+	return b.addValidator(pubKey, power, name, keyIndex)
+}
+
+func (b *Builder) addValidator(pubKey types.HexBytes, power uint64, name string, keyIndex uint8) *Builder {
+	if len(pubKey) != crypto256k1.PubKeySize {
+		b.errs = append(b.errs, fmt.Errorf("validator %q: public key must be a compressed secp256k1 key of %d bytes",
+			name, crypto256k1.PubKeySize))
+		return b
+	}
+	addr, err := ethereum.AddrFromPublicKey(pubKey)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("validator %q: %w", name, err))
+		return b
+	}
+	b.appState.Validators = append(b.appState.Validators, AppStateValidators{
+		Address:  addr.Bytes(),
+		PubKey:   pubKey,
+		Power:    power,
+		Name:     name,
+		KeyIndex: keyIndex,
+	})
+	return b
+}
+
+// AddAccount adds an account with the given balance.
+func (b *Builder) AddAccount(address common.Address, balance uint64) *Builder {
+	b.appState.Accounts = append(b.appState.Accounts, Account{Address: address.Bytes(), Balance: balance})
+	return b
+}
+
+// TxCosts sets the base costs of the transactions.
+func (b *Builder) TxCosts(costs TransactionCosts) *Builder {
+	b.appState.TxCost = costs
+	return b
+}
+
+// MaxElectionSize sets the maximum census size of the elections.
+func (b *Builder) MaxElectionSize(size uint64) *Builder {
+	b.appState.MaxElectionSize = size
+	return b
+}
+
+// NetworkCapacity sets the capacity of the network used to compute the
+// election price, see electionprice.Calculator.
+func (b *Builder) NetworkCapacity(capacity uint64) *Builder {
+	b.appState.NetworkCapacity = capacity
+	return b
+}
+
+// SIKValidity sets the number of blocks the SIKs stay in the state since they
+// are set, zero if they do not expire.
+func (b *Builder) SIKValidity(blocks uint32) *Builder {
+	b.appState.SIKValidity = blocks
+	return b
+}
+
+// BlockTiming sets the target time between blocks and the time the validators
+// wait for transactions before proposing an empty block, see state.BlockTiming.
+func (b *Builder) BlockTiming(blockTime, emptyBlocksInterval time.Duration) *Builder {
+	b.appState.BlockTime = uint32(blockTime / time.Second)
+	b.appState.EmptyBlocksInterval = uint32(emptyBlocksInterval / time.Second)
+	return b
+}
+
+// FeeMarket enables the dynamic transaction costs, see state.FeeMarket.
+func (b *Builder) FeeMarket(targetBlockTxs, maxMultiplier uint32) *Builder {
+	b.appState.FeeMarketTargetBlockTxs = targetBlockTxs
+	b.appState.FeeMarketMaxMultiplier = maxMultiplier
+	return b
+}
+
+// GasSchedule enables the gas metering of the blocks, see state.GasSchedule.
+func (b *Builder) GasSchedule(blockGasLimit, defaultTxGas uint64, txGas map[string]uint64) *Builder {
+	b.appState.BlockGasLimit = blockGasLimit
+	b.appState.DefaultTxGas = defaultTxGas
+	b.appState.TxGas = maps.Clone(txGas)
+	return b
+}
+
+// Forks sets the heights from which the consensus changes are applied, instead
+// of applying all of them from the first block. An empty map applies none.
+func (b *Builder) Forks(heights map[string]uint32) *Builder {
+	b.forks = maps.Clone(heights)
+	if b.forks == nil {
+		b.forks = make(map[string]uint32)
+	}
+	return b
+}
+
+// Build validates the genesis and returns it, or the errors found.
+func (b *Builder) Build() (*Doc, error) {
+	errs := append(slices.Clip(b.errs), b.validate()...)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	appState := b.appState
+	appState.Forks = b.forks
+	if appState.Forks == nil {
+		appState.Forks = AllForks()
+	}
+	appState.Accounts = slices.Clone(appState.Accounts)
+	slices.SortFunc(appState.Accounts, func(a, b Account) int {
+		return bytes.Compare(a.Address, b.Address)
+	})
+	doc := b.doc
+	doc.AppState = jsonRawMessage(appState)
+	if err := doc.ValidateAndComplete(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// validate returns the errors of the app state, and of the fields of the
+// CometBFT genesis which are not validated by ValidateAndComplete.
+func (b *Builder) validate() []error {
+	var errs []error
+	if b.doc.GenesisTime.IsZero() {
+		errs = append(errs, fmt.Errorf("missing genesis time"))
+	}
+	if len(b.appState.Validators) == 0 {
+		errs = append(errs, fmt.Errorf("the genesis needs at least one validator"))
+	}
+	for i, v := range b.appState.Validators {
+		if v.Power == 0 {
+			errs = append(errs, fmt.Errorf("validator %q: power must be positive", v.Name))
+		}
+		for _, prev := range b.appState.Validators[:i] {
+			if bytes.Equal(prev.PubKey, v.PubKey) {
+				errs = append(errs, fmt.Errorf("validator %q: duplicated public key of %q", v.Name, prev.Name))
+			}
+			if v.Name != "" && prev.Name == v.Name {
+				errs = append(errs, fmt.Errorf("validator %q: duplicated name", v.Name))
+			}
+		}
+	}
+	var supply uint64
+	for i, acc := range b.appState.Accounts {
+		for _, prev := range b.appState.Accounts[:i] {
+			if bytes.Equal(prev.Address, acc.Address) {
+				errs = append(errs, fmt.Errorf("account %s: duplicated", common.BytesToAddress(acc.Address)))
+			}
+		}
+		if supply > math.MaxUint64-acc.Balance {
+			errs = append(errs, fmt.Errorf("account %s: the total balance overflows", common.BytesToAddress(acc.Address)))
+		}
+		supply += acc.Balance
+	}
+	if b.appState.MaxElectionSize == 0 {
+		errs = append(errs, fmt.Errorf("max election size must be positive"))
+	}
+	if b.appState.NetworkCapacity == 0 {
+		errs = append(errs, fmt.Errorf("network capacity must be positive"))
+	}
+	if b.appState.BlockTime > 0 || b.appState.EmptyBlocksInterval > 0 {
+		bt := &state.BlockTiming{
+			BlockTime:           b.appState.BlockTime,
+			EmptyBlocksInterval: b.appState.EmptyBlocksInterval,
+		}
+		if err := bt.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, fork := range slices.Sorted(maps.Keys(b.forks)) {
+		if err := state.CheckFork(fork); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if b.appState.FeeMarketTargetBlockTxs > 0 || b.appState.FeeMarketMaxMultiplier > 0 {
+		fm := &state.FeeMarket{
+			TargetBlockTxs: b.appState.FeeMarketTargetBlockTxs,
+			MaxMultiplier:  b.appState.FeeMarketMaxMultiplier,
+		}
+		if err := fm.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if b.appState.BlockGasLimit > 0 || b.appState.DefaultTxGas > 0 || len(b.appState.TxGas) > 0 {
+		if err := b.appState.GasSchedule().Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,92 @@
+package genesis
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	crypto256k1 "github.com/cometbft/cometbft/crypto/secp256k1"
+	qt "github.com/frankban/quicktest"
+
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/state"
+)
+
+func TestBuilder(t *testing.T) {
+	c := qt.New(t)
+	genesisTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	pubKeys := make([]types.HexBytes, 3)
+	for i := range pubKeys {
+		pubKeys[i] = crypto256k1.GenPrivKey().PubKey().Bytes()
+	}
+	accounts := make([]*ethereum.SignKeys, 2)
+	for i := range accounts {
+		accounts[i] = ethereum.NewSignKeys()
+		c.Assert(accounts[i].Generate(), qt.IsNil)
+	}
+	build := func(reverse bool) *Doc {
+		b := NewBuilder("vocdoni/TEST/2", genesisTime).
+			AddKeyKeeper(pubKeys[0], 10, "validator0").
+			AddValidator(pubKeys[1], 10, "validator1").
+			AddKeyKeeper(pubKeys[2], 5, "validator2").
+			FeeMarket(100, 10).
+			GasSchedule(1000, 1, map[string]uint64{"ZK_VOTE": 50})
+		if reverse {
+			b.AddAccount(accounts[1].Address(), 20).AddAccount(accounts[0].Address(), 10)
+		} else {
+			b.AddAccount(accounts[0].Address(), 10).AddAccount(accounts[1].Address(), 20)
+		}
+		doc, err := b.Build()
+		c.Assert(err, qt.IsNil)
+		return doc
+	}
+	doc := build(false)
+	// the output does not depend on the order of the accounts
+	c.Assert(doc.Marshal(), qt.DeepEquals, build(true).Marshal())
+
+	var appState AppState
+	c.Assert(json.Unmarshal(doc.AppState, &appState), qt.IsNil)
+	c.Assert(doc.ChainID, qt.Equals, "vocdoni/TEST/2")
+	c.Assert(doc.GenesisTime, qt.Equals, genesisTime)
+	c.Assert(appState.Validators, qt.HasLen, 3)
+	for i, keyIndex := range []uint8{1, 0, 2} {
+		c.Assert(appState.Validators[i].KeyIndex, qt.Equals, keyIndex)
+		addr, err := ethereum.AddrFromPublicKey(pubKeys[i])
+		c.Assert(err, qt.IsNil)
+		c.Assert([]byte(appState.Validators[i].Address), qt.DeepEquals, addr.Bytes())
+	}
+	c.Assert(appState.Accounts, qt.HasLen, 2)
+	c.Assert(appState.TxCost, qt.Equals, DefaultTransactionCosts())
+	c.Assert(appState.FeeMarketTargetBlockTxs, qt.Equals, uint32(100))
+	c.Assert(appState.BlockGasLimit, qt.Equals, uint64(1000))
+	c.Assert(appState.TxGas, qt.DeepEquals, map[string]uint64{"ZK_VOTE": 50})
+	// the new chains apply all the forks from their first block
+	c.Assert(appState.Forks, qt.DeepEquals, AllForks())
+	forks := map[string]uint32{state.ForkProcessEnd: 100}
+	doc, err := NewBuilder("vocdoni/TEST/2", genesisTime).AddValidator(pubKeys[0], 10, "validator0").Forks(forks).Build()
+	c.Assert(err, qt.IsNil)
+	appState = AppState{}
+	c.Assert(json.Unmarshal(doc.AppState, &appState), qt.IsNil)
+	c.Assert(appState.Forks, qt.DeepEquals, forks)
+
+	// the errors are reported together
+	_, err = NewBuilder("", genesisTime).
+		AddValidator(pubKeys[0], 10, "validator0").
+		AddValidator(pubKeys[0], 0, "validator1").
+		AddValidator([]byte{1, 2, 3}, 10, "validator2").
+		AddAccount(accounts[0].Address(), 10).
+		AddAccount(accounts[0].Address(), 10).
+		FeeMarket(0, 10).
+		GasSchedule(10, 1, map[string]uint64{"ZK_VOTE": 20}).
+		Forks(map[string]uint32{"unknown": 1}).
+		Build()
+	c.Assert(err, qt.ErrorMatches, `(?s).*validator2.*public key.*validator1.*power.*duplicated public key.*`+
+		`account.*duplicated.*unknown fork unknown.*target block txs.*ZK_VOTE.*out of bounds.*`)
+	// without validators
+	_, err = NewBuilder("vocdoni/TEST/2", genesisTime).Build()
+	c.Assert(err, qt.ErrorMatches, ".*at least one validator.*")
+	// without chain ID
+	_, err = NewBuilder("", genesisTime).AddValidator(pubKeys[0], 10, "validator0").Build()
+	c.Assert(err, qt.ErrorMatches, ".*chain_id.*")
+}
@@ -60,6 +60,21 @@ import (
 	* make a commit with these 2 changes, on top of the consensus breaking commits, and deploy on `lts` (release-lts-1)
 	* nodes will keep current state (with AppHash fafafa...), start vocdoni/DEV/N+1
 		and discard cometbft blockstore from vocdoni/DEV/N
+
+### Example of how to deploy consensus breaking changes gated by a fork, without stopping the chain
+### (on dev, stage or LTS. will use LTS in this example)
+
+	* the changes must only be applied once state.ForkActive returns true for their fork
+
+	* set the fork height on the current chain "vocdoni/LTS/N", picking a height in the future
+		"vocdoni/LTS/N": {
+			...
+			Forks: map[string]uint32{state.ForkProcessEnd: 123},
+		},
+
+	* push that change together with the consensus breaking changes and deploy on `lts` (release-lts-1)
+	* all nodes must be upgraded before the chain reaches that height, else they will halt on an AppHash mismatch
+	* a node operator can also set the height with the flag --vochainGenesisForks=processEnd=123
 */
 
 // networks is a map containing the default chainID for each network
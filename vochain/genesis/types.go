@@ -7,6 +7,7 @@ import (
 	comettypes "github.com/cometbft/cometbft/types"
 
 	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/state"
 )
 
 // Doc is a wrapper around the CometBFT GenesisDoc,
@@ -14,6 +15,10 @@ import (
 type Doc struct {
 	comettypes.GenesisDoc
 	EndOfChain int64
+	// Forks are the heights from which the consensus changes are applied on
+	// a running chain, keyed by their name (see state.ScheduleForks). Every
+	// node of the chain must be upgraded before the lowest of them is reached.
+	Forks map[string]uint32 `json:",omitempty"`
 }
 
 // Marshal returns the JSON encoding of the genesis.Doc.
@@ -43,6 +48,54 @@ type AppState struct {
 	TxCost          TransactionCosts     `json:"tx_cost"`
 	MaxElectionSize uint64               `json:"max_election_size"`
 	NetworkCapacity uint64               `json:"network_capacity"`
+	// SIKValidity is the number of blocks the SIKs stay in the state since
+	// they are set, zero if they do not expire.
+	SIKValidity uint32 `json:"sik_validity,omitempty"`
+	// BlockTime is the target time between blocks, in seconds, and
+	// EmptyBlocksInterval the time the validators wait for new transactions
+	// before proposing an empty block (zero to always create them). If
+	// BlockTime is zero, the nodes use their local configuration.
+	BlockTime           uint32 `json:"block_time,omitempty"`
+	EmptyBlocksInterval uint32 `json:"empty_blocks_interval,omitempty"`
+	// FeeMarketTargetBlockTxs and FeeMarketMaxMultiplier enable the dynamic
+	// transaction costs, which are multiplied by up to FeeMarketMaxMultiplier
+	// while the blocks carry more than FeeMarketTargetBlockTxs transactions
+	// which paid a cost, from the feeMarket fork. If FeeMarketTargetBlockTxs is
+	// zero, the costs are fixed.
+	FeeMarketTargetBlockTxs uint32 `json:"fee_market_target_block_txs,omitempty"`
+	FeeMarketMaxMultiplier  uint32 `json:"fee_market_max_multiplier,omitempty"`
+	// BlockGasLimit is the maximum gas of the transactions of a block, where
+	// the gas of each transaction type is in TxGas (see state.GasSchedule),
+	// and DefaultTxGas the gas of the rest. If zero, the blocks are not
+	// metered.
+	BlockGasLimit uint64            `json:"block_gas_limit,omitempty"`
+	DefaultTxGas  uint64            `json:"default_tx_gas,omitempty"`
+	TxGas         map[string]uint64 `json:"tx_gas,omitempty"`
+	// Forks are the heights from which the consensus changes are applied,
+	// keyed by their name (see state.ForkProcessEnd), zero to apply them from
+	// the first block. The forks which are not set are never applied, unless
+	// scheduled by the node (see Doc.Forks).
+	Forks map[string]uint32 `json:"forks,omitempty"`
+}
+
+// AllForks returns all the known forks applied from the first block, so that
+// a new chain starts with all the consensus changes.
+func AllForks() map[string]uint32 {
+	forks := make(map[string]uint32)
+	for _, fork := range state.Forks() {
+		forks[fork] = 0
+	}
+	return forks
+}
+
+// GasSchedule returns the gas schedule of the app state, which is only set in
+// the state if BlockGasLimit is not zero.
+func (a *AppState) GasSchedule() *state.GasSchedule {
+	return &state.GasSchedule{
+		BlockGasLimit: a.BlockGasLimit,
+		DefaultTxGas:  a.DefaultTxGas,
+		TxGas:         a.TxGas,
+	}
 }
 
 // AppStateValidators represents a validator in the genesis app state.
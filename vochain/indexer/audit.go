@@ -0,0 +1,182 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// The kinds of the anomalies found by AuditProcess.
+const (
+	// AnomalyVoteCount means that the number of votes in the state and in the
+	// votes table differ.
+	AnomalyVoteCount = "vote_count"
+	// AnomalyProcessVoteCount means that the vote count of the process does not
+	// match the number of votes in the votes table.
+	AnomalyProcessVoteCount = "process_vote_count"
+	// AnomalyMissingVote means that a vote in the state was not indexed.
+	AnomalyMissingVote = "missing_vote"
+	// AnomalyUnknownVote means that an indexed vote is not in the state.
+	AnomalyUnknownVote = "unknown_vote"
+)
+
+// maxAuditNullifiers is the maximum number of missing or unknown votes stored
+// by kind for a process, the count anomaly already tells how many there are.
+const maxAuditNullifiers = 100
+
+// loadAnomalyCount seeds the anomalies gauge with the number of anomalies
+// stored in the database, once it is opened or restored.
+func (idx *Indexer) loadAnomalyCount(ctx context.Context) error {
+	count, err := idx.readOnlyQuery.CountAnomalies(ctx)
+	if err != nil {
+		return err
+	}
+	idx.anomalyCount.Store(count)
+	return nil
+}
+
+// AuditProcess cross-checks the votes of the process pid in the committed state
+// with the ones stored by the indexer, so that a silent divergence between them
+// does not go unnoticed. The anomalies found replace the ones of any previous
+// audit of the process, and are returned.
+//
+// It is called for each process once it ends, but it can also be called to
+// audit an ongoing process, in which case the votes of the current block may
+// be reported as missing.
+func (idx *Indexer) AuditProcess(pid []byte) ([]*indexertypes.Anomaly, error) {
+	if idx.readReplicaOf != nil {
+		return nil, ErrReadReplica
+	}
+	ctx := context.TODO()
+	height := idx.App.Height()
+	proc, err := idx.readOnlyQuery.GetProcess(ctx, pid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get process %x: %w", pid, err)
+	}
+	stateVotes := make(map[string]bool)
+	if err := idx.App.State.IterateVotes(pid, true, func(vote *models.StateDBVote) bool {
+		stateVotes[string(vote.Nullifier)] = true
+		return false
+	}); err != nil {
+		return nil, fmt.Errorf("cannot iterate the votes of process %x: %w", pid, err)
+	}
+	indexed, err := idx.readOnlyQuery.GetProcessNullifiers(ctx, indexerdb.GetProcessNullifiersParams{
+		ProcessID:   pid,
+		BlockHeight: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the indexed votes of process %x: %w", pid, err)
+	}
+
+	var anomalies []*indexertypes.Anomaly
+	add := func(kind string, nullifier []byte, details string) {
+		anomalies = append(anomalies, &indexertypes.Anomaly{
+			ProcessID: pid,
+			Height:    height,
+			Kind:      kind,
+			Nullifier: nullifier,
+			Details:   details,
+		})
+	}
+	if len(stateVotes) != len(indexed) {
+		add(AnomalyVoteCount, nil, fmt.Sprintf("state %d, indexed %d", len(stateVotes), len(indexed)))
+	}
+	if proc.VoteCount != int64(len(indexed)) {
+		add(AnomalyProcessVoteCount, nil, fmt.Sprintf("process %d, indexed %d", proc.VoteCount, len(indexed)))
+	}
+	unknown := 0
+	for _, row := range indexed {
+		if stateVotes[string(row.Nullifier)] {
+			delete(stateVotes, string(row.Nullifier))
+			continue
+		}
+		if unknown++; unknown <= maxAuditNullifiers {
+			add(AnomalyUnknownVote, row.Nullifier, fmt.Sprintf("indexed at height %d", row.BlockHeight))
+		}
+	}
+	// the remaining votes of the state were not indexed
+	missing := 0
+	for nullifier := range stateVotes {
+		if missing++; missing <= maxAuditNullifiers {
+			add(AnomalyMissingVote, []byte(nullifier), "not indexed")
+		}
+	}
+
+	if err := idx.storeAnomalies(ctx, pid, anomalies); err != nil {
+		return nil, err
+	}
+	if len(anomalies) > 0 {
+		log.Warnw("indexed votes do not match the state", "processID", fmt.Sprintf("%x", pid),
+			"anomalies", len(anomalies), "missing", missing, "unknown", unknown)
+	}
+	return anomalies, nil
+}
+
+// storeAnomalies replaces the anomalies of the process pid, and updates the
+// number of anomalies reported to the metrics.
+func (idx *Indexer) storeAnomalies(ctx context.Context, pid []byte, anomalies []*indexertypes.Anomaly) error {
+	tx, err := idx.readWriteDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := indexerdb.New(tx)
+	if _, err := queries.DeleteProcessAnomalies(ctx, pid); err != nil {
+		return fmt.Errorf("cannot delete anomalies: %w", err)
+	}
+	for _, a := range anomalies {
+		if _, err := queries.CreateAnomaly(ctx, indexerdb.CreateAnomalyParams{
+			ProcessID:   pid,
+			BlockHeight: int64(a.Height),
+			Kind:        a.Kind,
+			Nullifier:   nonNullBytes(a.Nullifier),
+			Details:     a.Details,
+		}); err != nil {
+			return fmt.Errorf("cannot create anomaly: %w", err)
+		}
+	}
+	count, err := queries.CountAnomalies(ctx)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	idx.anomalyCount.Store(count)
+	return nil
+}
+
+// ProcessAnomalies returns the anomalies found by the last audit of the process.
+func (idx *Indexer) ProcessAnomalies(pid []byte) ([]*indexertypes.Anomaly, error) {
+	results, err := idx.readOnlyQuery.GetProcessAnomalies(context.TODO(), pid)
+	if err != nil {
+		return nil, err
+	}
+	list := []*indexertypes.Anomaly{}
+	for _, row := range results {
+		list = append(list, &indexertypes.Anomaly{
+			ProcessID: row.ProcessID,
+			Height:    uint32(row.BlockHeight),
+			Kind:      row.Kind,
+			Nullifier: row.Nullifier,
+			Details:   row.Details,
+		})
+	}
+	return list, nil
+}
+
+// auditProcesses audits the given processes, logging the errors. It is run in
+// the background, so that large processes do not delay the block commits.
+func (idx *Indexer) auditProcesses(pids []types.ProcessID) {
+	defer idx.auditing.Done()
+	for _, pid := range pids {
+		if _, err := idx.AuditProcess(pid); err != nil {
+			log.Errorw(err, "cannot audit process")
+		}
+	}
+}
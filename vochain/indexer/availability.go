@@ -0,0 +1,157 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.vocdoni.io/dvote/data"
+	"go.vocdoni.io/dvote/log"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+)
+
+const (
+	// MetadataCheckInterval is the default time between two rounds of the
+	// metadata availability tracker.
+	MetadataCheckInterval = 10 * time.Minute
+	// metadataCheckBatchSize is the maximum number of processes checked on each round.
+	// The ones never checked, or checked the longest time ago, go first.
+	metadataCheckBatchSize = 100
+	// metadataCheckTimeout is the timeout for retrieving each URI.
+	metadataCheckTimeout = 30 * time.Second
+	// metadataMaxSize is the maximum size of the files retrieved by the tracker.
+	metadataMaxSize = 1024 * 1024 * 10
+)
+
+// TrackMetadataAvailability periodically checks whether the metadata and census
+// URIs of the indexed processes can still be retrieved from the storage, so the
+// operators can re-pin the content before it disappears. Only the URIs hosted
+// on the storage provider (with its URI prefix) are checked.
+// It is a blocking function that waits until the Vochain is synchronized, and
+// runs until ctx is done. It should be called on a goroutine.
+func (idx *Indexer) TrackMetadataAvailability(ctx context.Context, storage data.Storage, interval time.Duration) {
+	if idx.readReplicaOf != nil {
+		return
+	}
+	select {
+	case <-idx.App.WaitUntilSynced():
+	case <-ctx.Done():
+		return
+	}
+	log.Infow("starting metadata availability tracker", "interval", interval)
+	for {
+		if err := idx.checkMetadataAvailability(ctx, storage); err != nil {
+			log.Warnw("could not check metadata availability", "err", err)
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkMetadataAvailability runs a round of the metadata availability tracker.
+// The results are stored within the current block transaction.
+func (idx *Indexer) checkMetadataAvailability(ctx context.Context, storage data.Storage) error {
+	prefix := storage.URIprefix()
+	procs, err := idx.readOnlyQuery.GetProcessesToCheckAvailability(ctx,
+		indexerdb.GetProcessesToCheckAvailabilityParams{
+			UriPrefix: prefix,
+			Limit:     metadataCheckBatchSize,
+		})
+	if err != nil {
+		return err
+	}
+	if len(procs) == 0 {
+		return nil
+	}
+
+	results := make([]indexerdb.SetMetadataAvailabilityParams, 0, len(procs))
+	unavailable := 0
+	for _, p := range procs {
+		res := indexerdb.SetMetadataAvailabilityParams{
+			ProcessID: p.ID,
+		}
+		if strings.HasPrefix(p.Metadata, prefix) {
+			res.MetadataUri = p.Metadata
+			res.MetadataAvailable = isRetrievable(ctx, storage, p.Metadata)
+			if res.MetadataAvailable {
+				res.MetadataLastSeen = time.Now()
+			}
+		}
+		if strings.HasPrefix(p.CensusUri, prefix) {
+			res.CensusUri = p.CensusUri
+			res.CensusAvailable = isRetrievable(ctx, storage, p.CensusUri)
+			if res.CensusAvailable {
+				res.CensusLastSeen = time.Now()
+			}
+		}
+		// the checks were interrupted, so the results cannot be trusted
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		res.LastChecked = time.Now()
+		if (res.MetadataUri != "" && !res.MetadataAvailable) || (res.CensusUri != "" && !res.CensusAvailable) {
+			unavailable++
+		}
+		results = append(results, res)
+	}
+
+	idx.blockMu.Lock()
+	defer idx.blockMu.Unlock()
+	queries := idx.blockTxQueries()
+	for _, res := range results {
+		if _, err := queries.SetMetadataAvailability(ctx, res); err != nil {
+			return fmt.Errorf("cannot store metadata availability of process %x: %w", res.ProcessID, err)
+		}
+	}
+	log.Debugw("metadata availability checked", "processes", len(results), "unavailable", unavailable)
+	return nil
+}
+
+// isRetrievable returns true if the file at uri can be retrieved from the storage.
+// The files larger than metadataMaxSize are not fetched, but they are available,
+// as the storage found them to know their size.
+func isRetrievable(ctx context.Context, storage data.Storage, uri string) bool {
+	ctx, cancel := context.WithTimeout(ctx, metadataCheckTimeout)
+	defer cancel()
+	if _, err := storage.Retrieve(ctx, uri, metadataMaxSize); err != nil {
+		if errors.Is(err, data.ErrFileTooLarge) {
+			return true
+		}
+		log.Debugw("file not retrievable", "uri", uri, "err", err)
+		return false
+	}
+	return true
+}
+
+// UnavailableMetadataList returns the list of processes whose metadata or census
+// could not be retrieved from the storage on the last check of the metadata
+// availability tracker. The most recently checked go first.
+func (idx *Indexer) UnavailableMetadataList(limit, offset int) ([]*indexertypes.MetadataAvailability, uint64, error) {
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
+	}
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
+	}
+	results, err := idx.readOnlyQuery.SearchUnavailableMetadata(context.TODO(), indexerdb.SearchUnavailableMetadataParams{
+		Limit:  int64(limit),
+		Offset: int64(offset),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	list := []*indexertypes.MetadataAvailability{}
+	for _, row := range results {
+		list = append(list, indexertypes.MetadataAvailabilityFromDBRow(&row))
+	}
+	if len(results) == 0 {
+		return list, 0, nil
+	}
+	return list, uint64(results[0].TotalCount), nil
+}
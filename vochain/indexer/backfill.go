@@ -0,0 +1,316 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.vocdoni.io/dvote/log"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+)
+
+// The backfills run by the indexer once the database is migrated.
+const (
+	// BackfillBlocks reindexes the blocks and transactions of the block store,
+	// scheduled whenever a migration is applied, as the new columns and tables
+	// may need the data of the blocks.
+	BackfillBlocks = "blocks"
+	// BackfillTransactionSigners recovers the signers of the signed
+	// transactions indexed without them, from the block store.
+	BackfillTransactionSigners = "transaction_signers"
+	// BackfillProcessVoteCounts recomputes the vote counts of the processes
+	// from the votes table. Meanwhile, the vote count of a process is counted
+	// when it is queried.
+	BackfillProcessVoteCounts = "process_vote_counts"
+)
+
+// backfillBatchSize is the maximum number of rows or blocks processed by each
+// batch of a backfill, so that the indexing of new blocks and the queries do
+// not wait for long.
+const backfillBatchSize = 500
+
+// backfill is a long-running update of the indexed data required by the
+// migrations, run incrementally in the background once they are applied,
+// instead of blocking the startup of the indexer.
+type backfill struct {
+	name string
+	// version is the migration which requires the backfill, scheduled when the
+	// database is migrated from an older version. Zero means any migration.
+	version int64
+	// step processes at most limit rows or blocks after position, returning
+	// the new position, the number processed and whether the backfill is done.
+	step func(idx *Indexer, ctx context.Context, queries *indexerdb.Queries,
+		position int64, limit int) (int64, int, bool, error)
+}
+
+// backfills are the backfills of the indexer, run in this order.
+var backfills = []backfill{
+	{name: BackfillBlocks, step: (*Indexer).backfillBlocks},
+	{name: BackfillTransactionSigners, version: 30, step: (*Indexer).backfillTransactionSigners},
+	{name: BackfillProcessVoteCounts, version: 35, step: (*Indexer).backfillProcessVoteCounts},
+}
+
+// scheduleBackfills schedules the backfills required by the migrations applied
+// to a database at version fromVersion, restarting them if they were pending.
+// A new database, at version zero, only needs the blocks of the block store.
+func (idx *Indexer) scheduleBackfills(ctx context.Context, fromVersion int64) error {
+	queries := indexerdb.New(idx.readWriteDB)
+	for _, b := range backfills {
+		if b.version != 0 && (fromVersion == 0 || b.version <= fromVersion) {
+			continue
+		}
+		if _, err := queries.ScheduleBackfill(ctx, b.name); err != nil {
+			return fmt.Errorf("cannot schedule backfill %s: %w", b.name, err)
+		}
+		idx.pendingBackfills.Store(b.name, true)
+	}
+	return nil
+}
+
+// startBackfills loads the backfills which are not done yet, and runs them in
+// the background if any.
+func (idx *Indexer) startBackfills(ctx context.Context) error {
+	progress, err := idx.readOnlyQuery.GetBackfills(ctx)
+	if err != nil {
+		return err
+	}
+	pending := false
+	for _, p := range progress {
+		if !p.Done {
+			idx.pendingBackfills.Store(p.Name, true)
+			pending = true
+		}
+	}
+	if !pending {
+		return nil
+	}
+	idx.backfilling.Add(1)
+	go func() {
+		defer idx.backfilling.Done()
+		idx.RunBackfills(false)
+	}()
+	return nil
+}
+
+// backfillPending returns whether the backfill is scheduled and not done yet,
+// so that the queries depending on it can fall back to the data available.
+func (idx *Indexer) backfillPending(name string) bool {
+	_, pending := idx.pendingBackfills.Load(name)
+	return pending
+}
+
+// Backfills returns the progress of the backfills run since the database was
+// created, pending or done.
+func (idx *Indexer) Backfills() ([]*indexertypes.Backfill, error) {
+	progress, err := idx.readOnlyQuery.GetBackfills(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	list := []*indexertypes.Backfill{}
+	for _, p := range progress {
+		list = append(list, &indexertypes.Backfill{
+			Name:      p.Name,
+			Position:  uint64(p.Position),
+			Processed: uint64(p.Processed),
+			Done:      p.Done,
+		})
+	}
+	return list, nil
+}
+
+// RunBackfills runs the pending backfills in order, in batches which are
+// committed along with their progress, until they are done or the indexer is
+// closed. An interrupted backfill resumes from its last batch on the next run.
+// Unless inTest, it waits until the chain is synced. It is called in the
+// background once the database is migrated, so that the indexer keeps indexing
+// new blocks and serving queries meanwhile.
+func (idx *Indexer) RunBackfills(inTest bool) {
+	if idx.readReplicaOf != nil {
+		return
+	}
+	if !inTest {
+		select {
+		case <-idx.App.WaitUntilSynced():
+		case <-idx.stopBackfills:
+			return
+		}
+	}
+	idx.backfillMu.Lock()
+	defer idx.backfillMu.Unlock()
+
+	progress, err := idx.readOnlyQuery.GetBackfills(context.TODO())
+	if err != nil {
+		log.Errorw(err, "cannot get backfills")
+		return
+	}
+	positions := make(map[string]int64)
+	for _, p := range progress {
+		if !p.Done {
+			positions[p.Name] = p.Position
+		}
+	}
+	for _, b := range backfills {
+		position, ok := positions[b.name]
+		if !ok {
+			continue
+		}
+		if err := idx.runBackfill(b, position); err != nil {
+			log.Errorw(err, fmt.Sprintf("cannot run backfill %s", b.name))
+			return
+		}
+	}
+}
+
+// runBackfill runs the backfill b from position until it is done or the
+// indexer is closed.
+func (idx *Indexer) runBackfill(b backfill, position int64) error {
+	log.Infow("start backfill", "name", b.name, "position", position)
+	startTime := time.Now()
+	total := 0
+	for {
+		select {
+		case <-idx.stopBackfills:
+			log.Infow("backfill interrupted", "name", b.name, "position", position)
+			return nil
+		default:
+		}
+		var processed int
+		var done bool
+		var err error
+		if position, processed, done, err = idx.backfillBatch(b, position); err != nil {
+			return err
+		}
+		total += processed
+		if done {
+			break
+		}
+	}
+	idx.pendingBackfills.Delete(b.name)
+	if total > 0 {
+		idx.versions.bump()
+	}
+	log.Infow("finished backfill", "name", b.name, "position", position, "took", time.Since(startTime).String())
+	return nil
+}
+
+// backfillBatch runs a step of the backfill b from position, and stores its
+// progress. If a block is being indexed, the batch is committed along with it,
+// so that both are rolled back together. It returns the new position, the
+// number of rows or blocks processed and whether the backfill is done.
+func (idx *Indexer) backfillBatch(b backfill, position int64) (int64, int, bool, error) {
+	idx.blockMu.Lock()
+	defer idx.blockMu.Unlock()
+
+	ownTx := idx.blockTx == nil
+	queries := idx.blockTxQueries()
+	ctx := context.TODO()
+	next, processed, done, err := b.step(idx, ctx, queries, position, backfillBatchSize)
+	if err == nil {
+		_, err = queries.UpdateBackfill(ctx, indexerdb.UpdateBackfillParams{
+			Name:      b.name,
+			Position:  next,
+			Processed: int64(processed),
+			Done:      done,
+		})
+	}
+	if !ownTx {
+		return next, processed, done, err
+	}
+	if err != nil {
+		if err := idx.blockTx.Rollback(); err != nil {
+			log.Errorw(err, "could not rollback tx")
+		}
+		idx.blockTx = nil
+		return position, 0, false, err
+	}
+	err = idx.blockTx.Commit()
+	idx.blockTx = nil
+	return next, processed, done, err
+}
+
+// backfillBlocks reindexes the blocks of the block store after height position.
+func (idx *Indexer) backfillBlocks(ctx context.Context, queries *indexerdb.Queries,
+	position int64, limit int,
+) (int64, int, bool, error) {
+	if idx.App.Node == nil || idx.App.Node.BlockStore() == nil {
+		return position, 0, true, nil
+	}
+	store := idx.App.Node.BlockStore()
+	first := max(position+1, store.Base())
+	last := min(first+int64(limit)-1, store.Height())
+	for height := first; height <= last; height++ {
+		idx.reindexBlock(ctx, queries, height)
+	}
+	processed := int(max(last-first+1, 0))
+	return max(last, position), processed, last >= store.Height(), nil
+}
+
+// backfillTransactionSigners sets the signers of the signed transactions after
+// rowid position which were indexed without them, recovered from the blocks.
+// The transactions whose block is not available are left as they are.
+func (idx *Indexer) backfillTransactionSigners(ctx context.Context, queries *indexerdb.Queries,
+	position int64, limit int,
+) (int64, int, bool, error) {
+	rows, err := queries.GetTransactionsWithoutSigner(ctx, indexerdb.GetTransactionsWithoutSignerParams{
+		AfterRowid: position,
+		Limit:      int64(limit),
+	})
+	if err != nil {
+		return position, 0, false, err
+	}
+	for _, row := range rows {
+		position = row.Rowid
+		signer, err := idx.blockTxSigner(row.BlockHeight, row.BlockIndex)
+		if err != nil {
+			log.Warnw("cannot backfill transaction signer", "hash", fmt.Sprintf("%x", row.Hash), "err", err)
+			continue
+		}
+		if _, err := queries.SetTransactionSigner(ctx, indexerdb.SetTransactionSignerParams{
+			Hash:   row.Hash,
+			Signer: nonNullBytes(signer),
+		}); err != nil {
+			return position, 0, false, err
+		}
+	}
+	return position, len(rows), len(rows) < limit, nil
+}
+
+// blockTxSigner returns the signer of the transaction at index of the block at
+// height, signed for the chain of the indexer, see txSigner.
+func (idx *Indexer) blockTxSigner(height, index int64) ([]byte, error) {
+	b := idx.App.GetBlockByHeight(height)
+	if b == nil {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	if index >= int64(len(b.Data.Txs)) {
+		return nil, fmt.Errorf("transaction %d/%d not found", height, index)
+	}
+	vtx := new(vochaintx.Tx)
+	if err := vtx.Unmarshal(b.Data.Txs[index], idx.ChainID()); err != nil {
+		return nil, err
+	}
+	return txSigner(vtx)
+}
+
+// backfillProcessVoteCounts recomputes the vote counts of the processes after
+// rowid position.
+func (idx *Indexer) backfillProcessVoteCounts(ctx context.Context, queries *indexerdb.Queries,
+	position int64, limit int,
+) (int64, int, bool, error) {
+	rows, err := queries.GetProcessIDsAfterRowID(ctx, indexerdb.GetProcessIDsAfterRowIDParams{
+		AfterRowid: position,
+		Limit:      int64(limit),
+	})
+	if err != nil {
+		return position, 0, false, err
+	}
+	for _, row := range rows {
+		if _, err := queries.ComputeProcessVoteCount(ctx, row.ID); err != nil {
+			return position, 0, false, err
+		}
+		position = row.Rowid
+	}
+	return position, len(rows), len(rows) < limit, nil
+}
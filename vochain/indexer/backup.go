@@ -0,0 +1,315 @@
+package indexer
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.vocdoni.io/dvote/log"
+)
+
+const (
+	// DefaultBackupInterval is the time between two scheduled backups of the
+	// database, see Options.BackupTo.
+	DefaultBackupInterval = 6 * time.Hour
+	// DefaultBackupsKept is the number of scheduled backups kept in the
+	// store, see Options.BackupsKept.
+	DefaultBackupsKept = 7
+
+	backupTimeLayout = "20060102T150405Z"
+)
+
+// backupNameRegexp is the format of the names of the scheduled backups, with
+// the time they were taken, the height of the last block indexed, and the
+// SHA-256 of their contents, checked when they are verified.
+var backupNameRegexp = regexp.MustCompile(`^indexer-(\d{8}T\d{6}Z)-(\d+)-([0-9a-f]{64})\.sqlite3$`)
+
+// ErrBackupCorrupted is returned by VerifyBackup if the backup does not match
+// its checksum, or it is not a sound indexer database.
+var ErrBackupCorrupted = errors.New("indexer backup corrupted")
+
+// BackupStore is where the scheduled backups of the database are kept, such as
+// a local directory (DirBackupStore) or an S3-compatible bucket (S3BackupStore).
+// Each backup is a complete copy of the database, as created by SaveBackup, and
+// it can be restored with RestoreBackup once downloaded.
+type BackupStore interface {
+	// PutBackup stores the backup with the given name and size, read from r.
+	PutBackup(ctx context.Context, name string, size int64, r io.Reader) error
+	// GetBackup returns the contents of the backup, which the caller must close.
+	GetBackup(ctx context.Context, name string) (io.ReadCloser, error)
+	// ListBackups returns the names of the backups in the store, in any order.
+	ListBackups(ctx context.Context) ([]string, error)
+	// DeleteBackup removes the backup from the store.
+	DeleteBackup(ctx context.Context, name string) error
+}
+
+// DirBackupStore is a BackupStore which keeps the backups as files in a
+// directory.
+type DirBackupStore string
+
+// PutBackup implements BackupStore.
+func (d DirBackupStore) PutBackup(_ context.Context, name string, _ int64, r io.Reader) error {
+	if err := os.MkdirAll(string(d), os.ModePerm); err != nil {
+		return err
+	}
+	// write to a temporary file first, so that a partial backup is never listed
+	tmp, err := os.CreateTemp(string(d), "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, r)
+	if err2 := tmp.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(string(d), name))
+}
+
+// GetBackup implements BackupStore.
+func (d DirBackupStore) GetBackup(_ context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(d), name))
+}
+
+// ListBackups implements BackupStore.
+func (d DirBackupStore) ListBackups(context.Context) ([]string, error) {
+	entries, err := os.ReadDir(string(d))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && !strings.HasPrefix(e.Name(), "tmp-") {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// DeleteBackup implements BackupStore.
+func (d DirBackupStore) DeleteBackup(_ context.Context, name string) error {
+	return os.Remove(filepath.Join(string(d), name))
+}
+
+// backupInfo is a scheduled backup in the store, parsed from its name.
+type backupInfo struct {
+	name   string
+	time   time.Time
+	height uint32
+	sum    string
+}
+
+// parseBackupName returns the backup with the given name, or false if it is
+// not the name of a scheduled backup, such as the files put in the store by
+// the operators, which are never rotated.
+func parseBackupName(name string) (backupInfo, bool) {
+	m := backupNameRegexp.FindStringSubmatch(name)
+	if m == nil {
+		return backupInfo{}, false
+	}
+	t, err := time.Parse(backupTimeLayout, m[1])
+	if err != nil {
+		return backupInfo{}, false
+	}
+	height, err := strconv.ParseUint(m[2], 10, 32)
+	if err != nil {
+		return backupInfo{}, false
+	}
+	return backupInfo{name: name, time: t, height: uint32(height), sum: m[3]}, true
+}
+
+// listBackups returns the scheduled backups in the BackupTo store, sorted from
+// the oldest to the newest.
+func (idx *Indexer) listBackups(ctx context.Context) ([]backupInfo, error) {
+	names, err := idx.backupTo.ListBackups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var backups []backupInfo
+	for _, name := range names {
+		if b, ok := parseBackupName(name); ok {
+			backups = append(backups, b)
+		}
+	}
+	slices.SortFunc(backups, func(a, b backupInfo) int {
+		if c := a.time.Compare(b.time); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.height, b.height)
+	})
+	return backups, nil
+}
+
+// RunBackup saves a backup of the database to the BackupTo store, removes the
+// backups out of the retention policy (see Options.BackupsKept and
+// Options.BackupMaxAge), and verifies one of the remaining backups, sampled at
+// random, so that a corrupted store is noticed before a backup is needed.
+// It returns the name of the new backup.
+func (idx *Indexer) RunBackup(ctx context.Context) (string, error) {
+	if idx.backupTo == nil {
+		return "", fmt.Errorf("the indexer backups are not enabled")
+	}
+	idx.backupMu.Lock()
+	defer idx.backupMu.Unlock()
+	name, err := idx.putBackup(ctx)
+	if err != nil {
+		return "", fmt.Errorf("cannot save backup: %w", err)
+	}
+	backups, err := idx.rotateBackups(ctx)
+	if err != nil {
+		return name, fmt.Errorf("cannot rotate backups: %w", err)
+	}
+	if len(backups) > 0 {
+		sample := backups[rand.IntN(len(backups))].name
+		if err := idx.VerifyBackup(ctx, sample); err != nil {
+			return name, fmt.Errorf("cannot verify backup %s: %w", sample, err)
+		}
+	}
+	return name, nil
+}
+
+// putBackup saves a backup of the database and puts it to the BackupTo store.
+func (idx *Indexer) putBackup(ctx context.Context) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "indexer-backup")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+	// the height is read first, since the backup may include some later blocks
+	height := idx.indexedHeight.Load()
+	path := filepath.Join(tmpDir, dbFilename)
+	if err := idx.SaveBackup(ctx, path); err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("indexer-%s-%d-%x.sqlite3",
+		time.Now().UTC().Format(backupTimeLayout), height, hash.Sum(nil))
+	return name, idx.backupTo.PutBackup(ctx, name, size, f)
+}
+
+// rotateBackups deletes the scheduled backups beyond the BackupsKept most
+// recent ones, and those older than BackupMaxAge, if set. The most recent
+// backup is always kept. It returns the remaining backups.
+func (idx *Indexer) rotateBackups(ctx context.Context) ([]backupInfo, error) {
+	backups, err := idx.listBackups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var kept []backupInfo
+	for i, b := range backups {
+		newer := len(backups) - 1 - i
+		expired := idx.backupMaxAge > 0 && time.Since(b.time) > idx.backupMaxAge
+		if newer > 0 && (newer >= idx.backupsKept || expired) {
+			if err := idx.backupTo.DeleteBackup(ctx, b.name); err != nil {
+				return nil, err
+			}
+			log.Debugw("indexer backup removed", "name", b.name)
+			continue
+		}
+		kept = append(kept, b)
+	}
+	return kept, nil
+}
+
+// VerifyBackup downloads the scheduled backup of the BackupTo store, and checks
+// that it matches its checksum and that it is a sound indexer database, which
+// can be decrypted with Options.EncryptionKey. It returns ErrBackupCorrupted
+// if not.
+func (idx *Indexer) VerifyBackup(ctx context.Context, name string) error {
+	if idx.backupTo == nil {
+		return fmt.Errorf("the indexer backups are not enabled")
+	}
+	b, ok := parseBackupName(name)
+	if !ok {
+		return fmt.Errorf("invalid backup name %q", name)
+	}
+	r, err := idx.backupTo.GetBackup(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	tmp, err := os.CreateTemp("", "indexer-backup-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), r)
+	if err2 := tmp.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return fmt.Errorf("cannot download the backup: %w", err)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != b.sum {
+		return fmt.Errorf("%w: checksum %s, expected %s", ErrBackupCorrupted, sum, b.sum)
+	}
+
+	db := openDB(fmt.Sprintf("file:%s?mode=ro", tmp.Name()), idx.encryptionKey)
+	defer db.Close()
+	var result string
+	if err := db.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("%w: %v", ErrBackupCorrupted, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("%w: %s", ErrBackupCorrupted, result)
+	}
+	var version int64
+	if err := db.QueryRowContext(ctx, "SELECT MAX(version_id) FROM goose_db_version").Scan(&version); err != nil {
+		return fmt.Errorf("%w: %v", ErrBackupCorrupted, err)
+	}
+	if version == 0 {
+		return fmt.Errorf("%w: the database is not migrated", ErrBackupCorrupted)
+	}
+	return nil
+}
+
+// scheduleBackups runs RunBackup every interval, until stop is closed.
+func (idx *Indexer) scheduleBackups(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		startTime := time.Now()
+		if name, err := idx.RunBackup(ctx); err != nil {
+			log.Errorw(err, "indexer backup failed")
+		} else {
+			log.Infow("indexer backup saved", "name", name, "elapsed", time.Since(startTime))
+		}
+		cancel()
+	}
+}
@@ -23,6 +23,83 @@ func (idx *Indexer) BlockTimestamp(height int64) (time.Time, error) {
 	return block.Time, nil
 }
 
+// blockTimeEstimationWindow is the number of the last indexed blocks whose
+// average time is used to estimate the time of the blocks not produced yet.
+const blockTimeEstimationWindow = 1000
+
+// HeightAtTime returns the height of the last block produced at or before t,
+// from the indexed block times. If t is after the last indexed block, the
+// height is estimated from the average time of the last indexed blocks, and
+// estimated is true. Returns ErrBlockNotFound if t is before the first indexed
+// block.
+func (idx *Indexer) HeightAtTime(t time.Time) (height int64, estimated bool, err error) {
+	block, err := idx.readOnlyQuery.GetBlockAtTime(context.TODO(), t)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, ErrBlockNotFound
+		}
+		return 0, false, err
+	}
+	last, blockTime, err := idx.lastBlockAndAverageTime()
+	if err != nil {
+		return 0, false, err
+	}
+	if block.Height < last.Height {
+		return block.Height, false, nil
+	}
+	return last.Height + int64(t.Sub(last.Time)/blockTime), true, nil
+}
+
+// TimeAtHeight returns the time of the block at the given height, from the
+// indexed block times. If the block is after the last indexed block, its time
+// is estimated from the average time of the last indexed blocks, and estimated
+// is true. Returns ErrBlockNotFound if the block is not indexed otherwise.
+func (idx *Indexer) TimeAtHeight(height int64) (blockTime time.Time, estimated bool, err error) {
+	block, err := idx.readOnlyQuery.GetBlockByHeight(context.TODO(), height)
+	if err == nil {
+		return block.Time, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, err
+	}
+	last, avg, err := idx.lastBlockAndAverageTime()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if height <= last.Height {
+		return time.Time{}, false, ErrBlockNotFound
+	}
+	return last.Time.Add(time.Duration(height-last.Height) * avg), true, nil
+}
+
+// lastBlockAndAverageTime returns the last indexed block and the average time
+// between the last blockTimeEstimationWindow indexed blocks, which is the block
+// time target if there are not enough of them.
+func (idx *Indexer) lastBlockAndAverageTime() (*indexerdb.Block, time.Duration, error) {
+	lastHeight, err := idx.readOnlyQuery.LastBlockHeight(context.TODO())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, 0, ErrBlockNotFound
+		}
+		return nil, 0, err
+	}
+	last, err := idx.readOnlyQuery.GetBlockByHeight(context.TODO(), lastHeight)
+	if err != nil {
+		return nil, 0, err
+	}
+	first, err := idx.readOnlyQuery.GetFirstBlockFromHeight(context.TODO(),
+		max(lastHeight-blockTimeEstimationWindow, 0))
+	if err != nil {
+		return nil, 0, err
+	}
+	if first.Height < last.Height {
+		if avg := last.Time.Sub(first.Time) / time.Duration(last.Height-first.Height); avg > 0 {
+			return &last, avg, nil
+		}
+	}
+	return &last, idx.App.BlockTimeTarget(), nil
+}
+
 // BlockByHeight returns the available information of the block at the given height
 func (idx *Indexer) BlockByHeight(height int64) (*indexertypes.Block, error) {
 	block, err := idx.readOnlyQuery.GetBlockByHeight(context.TODO(), height)
@@ -32,7 +109,7 @@ func (idx *Indexer) BlockByHeight(height int64) (*indexertypes.Block, error) {
 		}
 		return nil, err
 	}
-	return indexertypes.BlockFromDB(&block), nil
+	return idx.blockWithProposer(&block)
 }
 
 // BlockByHash returns the available information of the block with the given hash
@@ -44,7 +121,29 @@ func (idx *Indexer) BlockByHash(hash []byte) (*indexertypes.Block, error) {
 		}
 		return nil, err
 	}
-	return indexertypes.BlockFromDB(&block), nil
+	return idx.blockWithProposer(&block)
+}
+
+// blockWithProposer converts the indexerdb.Block into a Block, including the
+// information of its proposer from the indexed validators.
+func (idx *Indexer) blockWithProposer(dbblock *indexerdb.Block) (*indexertypes.Block, error) {
+	block := indexertypes.BlockFromDB(dbblock)
+	proposer, err := idx.readOnlyQuery.GetBlockProposer(context.TODO(), indexerdb.GetBlockProposerParams{
+		Height:  dbblock.Height,
+		Address: dbblock.ProposerAddress,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return block, nil
+		}
+		return nil, err
+	}
+	block.ProposerName = proposer.Name
+	if len(proposer.AccountAddress) > 0 {
+		block.ProposerAccount = proposer.AccountAddress
+	}
+	block.ProposerPower = uint64(proposer.Power)
+	return block, nil
 }
 
 // BlockList returns the list of blocks indexed.
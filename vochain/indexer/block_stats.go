@@ -0,0 +1,317 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+)
+
+const (
+	secondsPerHour = int64(time.Hour / time.Second)
+	secondsPerDay  = int64(24 * time.Hour / time.Second)
+)
+
+// indexBlockStats aggregates the transactions, token fees and votes indexed at the
+// given height, storing them as the block stats and adding them to the hourly and
+// daily rollups. Assumes that blockMu is locked.
+func (idx *Indexer) indexBlockStats(ctx context.Context, queries *indexerdb.Queries, height int64, blockTime time.Time) error {
+	// the block might be indexed again, so its previous stats are taken out of the rollups
+	prev, err := queries.GetBlockStats(ctx, height)
+	switch {
+	case err == nil:
+		prevTxTypes, err := queries.GetBlockStatsTxTypes(ctx, height)
+		if err != nil {
+			return err
+		}
+		counts := make(map[string]int64, len(prevTxTypes))
+		for _, t := range prevTxTypes {
+			counts[t.Type] = -t.Count
+		}
+		if err := addStatsRollups(ctx, queries, prev.Timestamp, counts, -prev.FeesBurned, -prev.VoteCount); err != nil {
+			return err
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	txTypes, err := queries.CountTransactionsByTypeAndHeight(ctx, height)
+	if err != nil {
+		return err
+	}
+	fees, err := queries.SumTokenFeesByHeight(ctx, height)
+	if err != nil {
+		return err
+	}
+	votes, err := queries.CountVotesByHeight(ctx, height)
+	if err != nil {
+		return err
+	}
+	txCount := int64(0)
+	for _, t := range txTypes {
+		txCount += t.Count
+	}
+	if _, err := queries.CreateBlockStats(ctx, indexerdb.CreateBlockStatsParams{
+		Height:     height,
+		Timestamp:  blockTime.Unix(),
+		TxCount:    txCount,
+		FeesBurned: fees,
+		VoteCount:  votes,
+	}); err != nil {
+		return err
+	}
+	// the block might be indexed again, so clear the types that may not be there anymore
+	if _, err := queries.DeleteBlockStatsTxTypes(ctx, height); err != nil {
+		return err
+	}
+	for _, t := range txTypes {
+		if _, err := queries.CreateBlockStatsTxType(ctx, indexerdb.CreateBlockStatsTxTypeParams{
+			Height: height,
+			Type:   t.Type,
+			Count:  t.Count,
+		}); err != nil {
+			return err
+		}
+	}
+	counts := make(map[string]int64, len(txTypes))
+	for _, t := range txTypes {
+		counts[t.Type] = t.Count
+	}
+	return addStatsRollups(ctx, queries, blockTime.Unix(), counts, fees, votes)
+}
+
+// addStatsRollups adds the transactions by type, token fees and votes of a block
+// with the given timestamp to the hourly and daily rollups. The values are
+// negative to take out the ones of a block indexed again.
+func addStatsRollups(ctx context.Context, queries *indexerdb.Queries, timestamp int64,
+	txTypes map[string]int64, fees, votes int64,
+) error {
+	hour := timestamp - timestamp%secondsPerHour
+	day := timestamp - timestamp%secondsPerDay
+	if votes != 0 {
+		if _, err := queries.AddStatsVotesHourly(ctx, indexerdb.AddStatsVotesHourlyParams{
+			Hour:      hour,
+			VoteCount: votes,
+		}); err != nil {
+			return err
+		}
+	}
+	for _, txType := range slices.Sorted(maps.Keys(txTypes)) {
+		if _, err := queries.AddStatsTxsDaily(ctx, indexerdb.AddStatsTxsDailyParams{
+			Day:   day,
+			Type:  txType,
+			Count: txTypes[txType],
+		}); err != nil {
+			return err
+		}
+	}
+	if fees != 0 {
+		if _, err := queries.AddStatsFeesDaily(ctx, indexerdb.AddStatsFeesDailyParams{
+			Day:        day,
+			FeesBurned: fees,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexAccountStats adds the accounts created in the current block to the daily
+// rollup. Assumes that blockMu is locked.
+func (idx *Indexer) indexAccountStats(ctx context.Context, queries *indexerdb.Queries, blockTime time.Time) error {
+	if idx.blockNewAccounts == 0 {
+		return nil
+	}
+	timestamp := blockTime.Unix()
+	_, err := queries.AddStatsAccountsDaily(ctx, indexerdb.AddStatsAccountsDailyParams{
+		Day:         timestamp - timestamp%secondsPerDay,
+		NewAccounts: idx.blockNewAccounts,
+	})
+	idx.blockNewAccounts = 0
+	return err
+}
+
+// BlockStats returns the activity statistics of the block at the given height.
+func (idx *Indexer) BlockStats(height int64) (*indexertypes.BlockStats, error) {
+	stats, err := idx.readOnlyQuery.GetBlockStats(context.TODO(), height)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBlockNotFound
+		}
+		return nil, err
+	}
+	txTypes, err := idx.readOnlyQuery.GetBlockStatsTxTypes(context.TODO(), height)
+	if err != nil {
+		return nil, err
+	}
+	blockStats := &indexertypes.BlockStats{
+		Height:     stats.Height,
+		Time:       time.Unix(stats.Timestamp, 0).UTC(),
+		TxCount:    stats.TxCount,
+		TxTypes:    make(map[string]int64, len(txTypes)),
+		FeesBurned: uint64(stats.FeesBurned),
+		VoteCount:  stats.VoteCount,
+	}
+	for _, t := range txTypes {
+		blockStats.TxTypes[t.Type] = t.Count
+	}
+	return blockStats, nil
+}
+
+// BlockStatsAggregate returns the activity statistics of the blocks between from
+// (inclusive) and to (exclusive), aggregated by consecutive intervals starting at
+// from. The last interval is clipped to the range, and the intervals without
+// blocks are omitted.
+func (idx *Indexer) BlockStatsAggregate(from, to time.Time, interval time.Duration) ([]*indexertypes.BlockStatsAggregate, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid value: from (%s) must be before to (%s)", from, to)
+	}
+	if interval < time.Second {
+		return nil, fmt.Errorf("invalid value: interval cannot be %s", interval)
+	}
+	seconds := int64(interval / time.Second)
+	stats, err := idx.readOnlyQuery.AggregateBlockStats(context.TODO(), indexerdb.AggregateBlockStatsParams{
+		FromTimestamp: from.Unix(),
+		Interval:      seconds,
+		ToTimestamp:   to.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	txTypes, err := idx.readOnlyQuery.AggregateBlockStatsTxTypes(context.TODO(), indexerdb.AggregateBlockStatsTxTypesParams{
+		FromTimestamp: from.Unix(),
+		Interval:      seconds,
+		ToTimestamp:   to.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := []*indexertypes.BlockStatsAggregate{}
+	byBucket := make(map[int64]*indexertypes.BlockStatsAggregate, len(stats))
+	for _, row := range stats {
+		start := time.Unix(from.Unix()+row.Bucket*seconds, 0).UTC()
+		end := start.Add(interval)
+		if end.After(to) {
+			end = to.UTC()
+		}
+		aggregate := &indexertypes.BlockStatsAggregate{
+			Start:      start,
+			End:        end,
+			BlockCount: row.BlockCount,
+			TxCount:    row.TxCount,
+			TxTypes:    make(map[string]int64),
+			FeesBurned: uint64(row.FeesBurned),
+			VoteCount:  row.VoteCount,
+		}
+		byBucket[row.Bucket] = aggregate
+		list = append(list, aggregate)
+	}
+	for _, row := range txTypes {
+		if aggregate, ok := byBucket[row.Bucket]; ok {
+			aggregate.TxTypes[row.Type] = row.Count
+		}
+	}
+	return list, nil
+}
+
+// VoteStatsHourly returns the number of votes cast in each hour between from
+// (inclusive) and to (exclusive), in UTC. The hours without votes are omitted.
+func (idx *Indexer) VoteStatsHourly(from, to time.Time) ([]*indexertypes.HourlyVoteStats, error) {
+	rows, err := idx.readOnlyQuery.GetStatsVotesHourly(context.TODO(), indexerdb.GetStatsVotesHourlyParams{
+		FromTimestamp: from.Unix(),
+		ToTimestamp:   to.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := []*indexertypes.HourlyVoteStats{}
+	for _, row := range rows {
+		if row.VoteCount == 0 {
+			continue
+		}
+		list = append(list, &indexertypes.HourlyVoteStats{
+			Hour:      time.Unix(row.Hour, 0).UTC(),
+			VoteCount: row.VoteCount,
+		})
+	}
+	return list, nil
+}
+
+// TxStatsDaily returns the number of transactions included in each day between
+// from (inclusive) and to (exclusive), in UTC, in total and by type. The days
+// without transactions are omitted.
+func (idx *Indexer) TxStatsDaily(from, to time.Time) ([]*indexertypes.DailyTxStats, error) {
+	rows, err := idx.readOnlyQuery.GetStatsTxsDaily(context.TODO(), indexerdb.GetStatsTxsDailyParams{
+		FromTimestamp: from.Unix(),
+		ToTimestamp:   to.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := []*indexertypes.DailyTxStats{}
+	for _, row := range rows {
+		if row.Count == 0 {
+			continue
+		}
+		if len(list) == 0 || list[len(list)-1].Day.Unix() != row.Day {
+			list = append(list, &indexertypes.DailyTxStats{
+				Day:     time.Unix(row.Day, 0).UTC(),
+				TxTypes: make(map[string]int64),
+			})
+		}
+		stats := list[len(list)-1]
+		stats.TxCount += row.Count
+		stats.TxTypes[row.Type] = row.Count
+	}
+	return list, nil
+}
+
+// AccountStatsDaily returns the number of accounts created in each day between
+// from (inclusive) and to (exclusive), in UTC. The days without new accounts are
+// omitted, as well as the accounts indexed before the rollups existed.
+func (idx *Indexer) AccountStatsDaily(from, to time.Time) ([]*indexertypes.DailyAccountStats, error) {
+	rows, err := idx.readOnlyQuery.GetStatsAccountsDaily(context.TODO(), indexerdb.GetStatsAccountsDailyParams{
+		FromTimestamp: from.Unix(),
+		ToTimestamp:   to.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := []*indexertypes.DailyAccountStats{}
+	for _, row := range rows {
+		list = append(list, &indexertypes.DailyAccountStats{
+			Day:         time.Unix(row.Day, 0).UTC(),
+			NewAccounts: row.NewAccounts,
+		})
+	}
+	return list, nil
+}
+
+// FeeStatsDaily returns the amount of token fees burned in each day between from
+// (inclusive) and to (exclusive), in UTC. The days without fees are omitted.
+func (idx *Indexer) FeeStatsDaily(from, to time.Time) ([]*indexertypes.DailyFeeStats, error) {
+	rows, err := idx.readOnlyQuery.GetStatsFeesDaily(context.TODO(), indexerdb.GetStatsFeesDailyParams{
+		FromTimestamp: from.Unix(),
+		ToTimestamp:   to.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := []*indexertypes.DailyFeeStats{}
+	for _, row := range rows {
+		if row.FeesBurned == 0 {
+			continue
+		}
+		list = append(list, &indexertypes.DailyFeeStats{
+			Day:        time.Unix(row.Day, 0).UTC(),
+			FeesBurned: uint64(row.FeesBurned),
+		})
+	}
+	return list, nil
+}
@@ -0,0 +1,206 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/pressly/goose/v3"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/vochain"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+)
+
+// chainsDir is the directory, next to the database of an indexer, where the
+// databases of the chains attached to it are stored, see AttachApp.
+const chainsDir = "chains"
+
+// chainDBFilename returns the name of the database file of the chain with the
+// given ID when attached to another one, which is the chain ID with the
+// characters not allowed in a portable file name replaced.
+func chainDBFilename(chainID string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(chainID) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	b.WriteString(".sqlite")
+	return b.String()
+}
+
+// prepareQueries prepares the queries of the indexer on its database.
+func (idx *Indexer) prepareQueries(ctx context.Context) error {
+	var err error
+	if idx.readOnlyQuery, err = indexerdb.Prepare(ctx, idx.readOnlyDB); err != nil {
+		return err
+	}
+	idx.blockQueries, err = indexerdb.Prepare(ctx, idx.readWriteDB)
+	return err
+}
+
+// migrationsMu serializes the migrations of the indexers, since the goose
+// base filesystem is global.
+var migrationsMu sync.Mutex
+
+// migrate applies the pending migrations to the database of the indexer,
+// returning the version of the database before them and whether there were
+// any.
+func (idx *Indexer) migrate() (int64, bool, error) {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		return 0, false, err
+	}
+	goose.SetLogger(log.GooseLogger())
+	goose.SetBaseFS(embedMigrations)
+
+	fromVersion, pending := gooseMigrationsPending(idx.readWriteDB, "migrations")
+	if err := goose.Up(idx.readWriteDB, "migrations"); err != nil {
+		return 0, false, fmt.Errorf("goose up: %w", err)
+	}
+	return fromVersion, pending, nil
+}
+
+// AttachApp makes the indexer index the chain of app too, so that a single
+// process, such as an explorer, can serve several networks. The attached chain
+// is indexed in its own database, stored in the chains directory next to the
+// database of idx (see chainDBFilename), by the returned Indexer, which serves
+// its queries and must be used as the indexer of that chain, e.g. to run
+// AfterSyncBootstrap. It is also returned by Chain.
+//
+// The attached chains share the encryption key of idx, but they have neither
+// backups nor replicas, and they do not run the Extensions. They are closed
+// along with idx, and RotateEncryptionKey fails while any is attached.
+func (idx *Indexer) AttachApp(app *vochain.BaseApplication) (*Indexer, error) {
+	if idx.readReplicaOf != nil {
+		return nil, ErrReadReplica
+	}
+	if idx.owner != nil {
+		return nil, fmt.Errorf("cannot attach a chain to the attached chain %s", idx.ChainID())
+	}
+	if idx.readWriteDB == nil {
+		return nil, fmt.Errorf("indexer database is not initialized")
+	}
+	chainID := app.ChainID()
+	if chainID == "" {
+		return nil, fmt.Errorf("the chain ID of the app is not set")
+	}
+	dir := filepath.Join(filepath.Dir(idx.dbPath), chainsDir)
+	dbPath := filepath.Join(dir, chainDBFilename(chainID))
+
+	idx.chainsMu.Lock()
+	defer idx.chainsMu.Unlock()
+	if chainID == idx.ChainID() {
+		return nil, fmt.Errorf("chain %s is already indexed", chainID)
+	}
+	for _, chain := range idx.chains {
+		if chain.dbPath == dbPath {
+			return nil, fmt.Errorf("chain %s is already indexed as %s", chainID, chain.ChainID())
+		}
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	opts := Options{
+		IgnoreLiveResults: idx.ignoreLiveResults,
+		EstimateCounts:    idx.estimateCounts,
+		CensusWeight:      idx.censusWeight,
+		EncryptionKey:     idx.encryptionKey,
+	}
+	if idx.votePatterns != nil {
+		opts.VotePatterns = &idx.votePatterns.opts
+	}
+	chain, err := newIndexer(app, opts)
+	if err != nil {
+		return nil, err
+	}
+	chain.owner = idx
+	chain.dbPath = dbPath
+	chain.registerMetrics()
+	if err := chain.startDB(); err != nil {
+		metrics.UnregisterSet(chain.metrics)
+		if cerr := chain.closeDB(); cerr != nil {
+			log.Warnw("cannot close the indexer of chain", "chainID", chainID, "err", cerr)
+		}
+		return nil, fmt.Errorf("cannot start the indexer of chain %s: %w", chainID, err)
+	}
+	log.Infow("indexer chain attached", "chainID", chainID, "dbPath", dbPath)
+
+	if idx.chains == nil {
+		idx.chains = make(map[string]*Indexer)
+	}
+	idx.chains[chainID] = chain
+	app.State.AddEventListener(chain)
+	return chain, nil
+}
+
+// Chain returns the indexer of the chain with the given ID, which is either
+// idx itself or one of the chains attached to it with AttachApp, or nil if
+// the chain is not indexed.
+func (idx *Indexer) Chain(chainID string) *Indexer {
+	if chainID == idx.ChainID() {
+		return idx
+	}
+	idx.chainsMu.RLock()
+	defer idx.chainsMu.RUnlock()
+	return idx.chains[chainID]
+}
+
+// Chains returns the indexers of the chains served along with idx, starting with idx itself, followed by the chains attached with AttachApp
+// sorted by chain ID.
+func (idx *Indexer) Chains() []*Indexer {
+	idx.chainsMu.RLock()
+	defer idx.chainsMu.RUnlock()
+	chains := []*Indexer{idx}
+	for _, chainID := range slices.Sorted(maps.Keys(idx.chains)) {
+		chains = append(chains, idx.chains[chainID])
+	}
+	return chains
+}
+
+// detach removes an attached chain from its owner.
+func (idx *Indexer) detach() {
+	idx.owner.chainsMu.Lock()
+	defer idx.owner.chainsMu.Unlock()
+	if idx.owner.chains[idx.ChainID()] == idx {
+		delete(idx.owner.chains, idx.ChainID())
+	}
+}
+
+// checkNoChains returns an error if idx shares its encryption key with other
+// chains, which prevents rotating it.
+func (idx *Indexer) checkNoChains() error {
+	if idx.owner != nil {
+		return fmt.Errorf("the encryption key of chain %s belongs to chain %s", idx.ChainID(), idx.owner.ChainID())
+	}
+	idx.chainsMu.RLock()
+	defer idx.chainsMu.RUnlock()
+	if len(idx.chains) > 0 {
+		return fmt.Errorf("the encryption key is shared with %d attached chains", len(idx.chains))
+	}
+	return nil
+}
+
+// closeChains closes the indexers of the attached chains.
+func (idx *Indexer) closeChains() error {
+	idx.chainsMu.Lock()
+	chains := idx.chains
+	idx.chains = nil
+	idx.chainsMu.Unlock()
+	for _, chainID := range slices.Sorted(maps.Keys(chains)) {
+		if err := chains[chainID].Close(); err != nil {
+			return fmt.Errorf("cannot close the indexer of chain %s: %w", chainID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package indexer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// encodeCursor returns an opaque pagination cursor holding the sort keys of the last
+// row of a page. Passing it back to the same list query returns the rows that follow,
+// regardless of the rows inserted in the meantime.
+func encodeCursor(keys ...any) string {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		// keys are always plain values (integers, byte slices, times)
+		panic(fmt.Sprintf("cannot encode cursor: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes a cursor produced by encodeCursor into the passed keys,
+// which must be pointers of the same types, in the same order.
+func decodeCursor(cursor string, keys ...any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if len(raw) != len(keys) {
+		return fmt.Errorf("%w: expected %d keys, got %d", ErrInvalidCursor, len(keys), len(raw))
+	}
+	for i, key := range keys {
+		if err := json.Unmarshal(raw[i], key); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+	}
+	return nil
+}
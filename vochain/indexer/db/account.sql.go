@@ -12,6 +12,63 @@ import (
 	"go.vocdoni.io/dvote/types"
 )
 
+const countAccountFeed = `-- name: CountAccountFeed :one
+WITH feed AS (
+  SELECT 'transferSent' AS kind, unixepoch(transfer_time) AS time, block_height,
+    LOWER(HEX(tx_hash)) AS ref, tx_hash, to_account AS account,
+    x'' AS process_id, x'' AS nullifier, amount, '' AS tx_type, '' AS reference
+  FROM token_transfers
+  WHERE from_account = ?1
+  UNION ALL
+  SELECT 'transferReceived', unixepoch(transfer_time), block_height,
+    LOWER(HEX(tx_hash)), tx_hash, from_account,
+    x'', x'', amount, '', ''
+  FROM token_transfers
+  WHERE to_account = ?1
+  UNION ALL
+  SELECT 'fee', unixepoch(spend_time), block_height,
+    PRINTF('%020d', id), x'', x'',
+    x'', x'', cost, tx_type, reference
+  FROM token_fees
+  WHERE from_account = ?1
+  UNION ALL
+  SELECT 'election', unixepoch(creation_time), 0,
+    LOWER(HEX(id)), x'', x'',
+    id, x'', 0, '', ''
+  FROM processes
+  WHERE entity_id = ?1
+  UNION ALL
+  -- the votes are linked to the account by the signer of their transaction,
+  -- which is the address of their voter ID
+  SELECT 'vote', unixepoch(b.time), t.block_height,
+    LOWER(HEX(v.nullifier)), t.hash, x'',
+    v.process_id, v.nullifier, 0, '', ''
+  FROM transactions AS t
+  JOIN votes AS v
+    ON v.block_height = t.block_height
+    AND v.block_index = t.block_index
+  JOIN blocks AS b
+    ON b.height = t.block_height
+  WHERE t.signer = ?1 AND t.type = 'vote'
+  UNION ALL
+  SELECT 'accountUpdate', unixepoch(b.time), t.block_height,
+    LOWER(HEX(t.hash)), t.hash, x'',
+    x'', x'', 0, t.subtype, ''
+  FROM transactions AS t
+  JOIN blocks AS b
+    ON b.height = t.block_height
+  WHERE t.signer = ?1 AND t.type = 'setAccount'
+)
+SELECT COUNT(*) FROM feed
+`
+
+func (q *Queries) CountAccountFeed(ctx context.Context, account []byte) (int64, error) {
+	row := q.queryRow(ctx, q.countAccountFeedStmt, countAccountFeed, account)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countAccounts = `-- name: CountAccounts :one
 SELECT COUNT(*) FROM accounts
 `
@@ -39,6 +96,147 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (s
 	return q.exec(ctx, q.createAccountStmt, createAccount, arg.Account, arg.Balance, arg.Nonce)
 }
 
+const existsAccount = `-- name: ExistsAccount :one
+SELECT EXISTS(SELECT 1 FROM accounts WHERE account = ?)
+`
+
+func (q *Queries) ExistsAccount(ctx context.Context, account []byte) (int64, error) {
+	row := q.queryRow(ctx, q.existsAccountStmt, existsAccount, account)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const searchAccountFeed = `-- name: SearchAccountFeed :many
+WITH feed AS (
+  SELECT 'transferSent' AS kind, unixepoch(transfer_time) AS time, block_height,
+    LOWER(HEX(tx_hash)) AS ref, tx_hash, to_account AS account,
+    x'' AS process_id, x'' AS nullifier, amount, '' AS tx_type, '' AS reference
+  FROM token_transfers
+  WHERE from_account = ?3
+  UNION ALL
+  SELECT 'transferReceived', unixepoch(transfer_time), block_height,
+    LOWER(HEX(tx_hash)), tx_hash, from_account,
+    x'', x'', amount, '', ''
+  FROM token_transfers
+  WHERE to_account = ?3
+  UNION ALL
+  SELECT 'fee', unixepoch(spend_time), block_height,
+    PRINTF('%020d', id), x'', x'',
+    x'', x'', cost, tx_type, reference
+  FROM token_fees
+  WHERE from_account = ?3
+  UNION ALL
+  SELECT 'election', unixepoch(creation_time), 0,
+    LOWER(HEX(id)), x'', x'',
+    id, x'', 0, '', ''
+  FROM processes
+  WHERE entity_id = ?3
+  UNION ALL
+  -- the votes are linked to the account by the signer of their transaction,
+  -- which is the address of their voter ID
+  SELECT 'vote', unixepoch(b.time), t.block_height,
+    LOWER(HEX(v.nullifier)), t.hash, x'',
+    v.process_id, v.nullifier, 0, '', ''
+  FROM transactions AS t
+  JOIN votes AS v
+    ON v.block_height = t.block_height
+    AND v.block_index = t.block_index
+  JOIN blocks AS b
+    ON b.height = t.block_height
+  WHERE t.signer = ?3 AND t.type = 'vote'
+  UNION ALL
+  SELECT 'accountUpdate', unixepoch(b.time), t.block_height,
+    LOWER(HEX(t.hash)), t.hash, x'',
+    x'', x'', 0, t.subtype, ''
+  FROM transactions AS t
+  JOIN blocks AS b
+    ON b.height = t.block_height
+  WHERE t.signer = ?3 AND t.type = 'setAccount'
+)
+SELECT kind, time, block_height, ref, tx_hash, account, process_id, nullifier, amount, tx_type, reference
+FROM feed
+WHERE (
+  ?4 IS NULL
+  OR time < ?4
+  OR (time = ?4 AND block_height < ?5)
+  OR (time = ?4 AND block_height = ?5
+    AND (kind > ?6 OR (kind = ?6 AND ref > ?7)))
+)
+ORDER BY time DESC, block_height DESC, kind ASC, ref ASC
+LIMIT ?2
+OFFSET ?1
+`
+
+type SearchAccountFeedParams struct {
+	Offset            int64
+	Limit             int64
+	Account           []byte
+	CursorTime        interface{}
+	CursorBlockHeight interface{}
+	CursorKind        interface{}
+	CursorRef         interface{}
+}
+
+type SearchAccountFeedRow struct {
+	Kind        string
+	Time        int64
+	BlockHeight int64
+	Ref         string
+	TxHash      []byte
+	Account     []byte
+	ProcessID   []byte
+	Nullifier   []byte
+	Amount      int64
+	TxType      string
+	Reference   string
+}
+
+// The total count is not computed here, as it requires scanning all the
+// results, see CountAccountFeed.
+func (q *Queries) SearchAccountFeed(ctx context.Context, arg SearchAccountFeedParams) ([]SearchAccountFeedRow, error) {
+	rows, err := q.query(ctx, q.searchAccountFeedStmt, searchAccountFeed,
+		arg.Offset,
+		arg.Limit,
+		arg.Account,
+		arg.CursorTime,
+		arg.CursorBlockHeight,
+		arg.CursorKind,
+		arg.CursorRef,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchAccountFeedRow
+	for rows.Next() {
+		var i SearchAccountFeedRow
+		if err := rows.Scan(
+			&i.Kind,
+			&i.Time,
+			&i.BlockHeight,
+			&i.Ref,
+			&i.TxHash,
+			&i.Account,
+			&i.ProcessID,
+			&i.Nullifier,
+			&i.Amount,
+			&i.TxType,
+			&i.Reference,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchAccounts = `-- name: SearchAccounts :many
 WITH results AS (
   SELECT account, balance, nonce
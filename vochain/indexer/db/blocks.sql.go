@@ -74,6 +74,27 @@ func (q *Queries) CreateBlock(ctx context.Context, arg CreateBlockParams) (sql.R
 	)
 }
 
+const getBlockAtTime = `-- name: GetBlockAtTime :one
+SELECT height, time, chain_id, hash, proposer_address, last_block_hash FROM blocks
+WHERE time <= ?1
+ORDER BY time DESC, height DESC
+LIMIT 1
+`
+
+func (q *Queries) GetBlockAtTime(ctx context.Context, before time.Time) (Block, error) {
+	row := q.queryRow(ctx, q.getBlockAtTimeStmt, getBlockAtTime, before)
+	var i Block
+	err := row.Scan(
+		&i.Height,
+		&i.Time,
+		&i.ChainID,
+		&i.Hash,
+		&i.ProposerAddress,
+		&i.LastBlockHash,
+	)
+	return i, err
+}
+
 const getBlockByHash = `-- name: GetBlockByHash :one
 SELECT height, time, chain_id, hash, proposer_address, last_block_hash FROM blocks
 WHERE hash = ?
@@ -114,6 +135,60 @@ func (q *Queries) GetBlockByHeight(ctx context.Context, height int64) (Block, er
 	return i, err
 }
 
+const getBlockProposer = `-- name: GetBlockProposer :one
+SELECT
+    v.name,
+    v.account_address,
+    CAST(COALESCE((
+        SELECT c.power FROM validator_set_changes AS c
+        WHERE c.address = v.address AND c.height <= ?1
+        ORDER BY c.height DESC
+        LIMIT 1
+    ), 0) AS INTEGER) AS power
+FROM validators AS v
+WHERE v.address = ?2
+LIMIT 1
+`
+
+type GetBlockProposerParams struct {
+	Height  int64
+	Address []byte
+}
+
+type GetBlockProposerRow struct {
+	Name           string
+	AccountAddress []byte
+	Power          int64
+}
+
+func (q *Queries) GetBlockProposer(ctx context.Context, arg GetBlockProposerParams) (GetBlockProposerRow, error) {
+	row := q.queryRow(ctx, q.getBlockProposerStmt, getBlockProposer, arg.Height, arg.Address)
+	var i GetBlockProposerRow
+	err := row.Scan(&i.Name, &i.AccountAddress, &i.Power)
+	return i, err
+}
+
+const getFirstBlockFromHeight = `-- name: GetFirstBlockFromHeight :one
+SELECT height, time, chain_id, hash, proposer_address, last_block_hash FROM blocks
+WHERE height >= ?
+ORDER BY height ASC
+LIMIT 1
+`
+
+func (q *Queries) GetFirstBlockFromHeight(ctx context.Context, height int64) (Block, error) {
+	row := q.queryRow(ctx, q.getFirstBlockFromHeightStmt, getFirstBlockFromHeight, height)
+	var i Block
+	err := row.Scan(
+		&i.Height,
+		&i.Time,
+		&i.ChainID,
+		&i.Hash,
+		&i.ProposerAddress,
+		&i.LastBlockHash,
+	)
+	return i, err
+}
+
 const lastBlockHeight = `-- name: LastBlockHeight :one
 SELECT height FROM blocks
 ORDER BY height DESC
@@ -130,10 +205,20 @@ func (q *Queries) LastBlockHeight(ctx context.Context) (int64, error) {
 const searchBlocks = `-- name: SearchBlocks :many
 SELECT
     b.height, b.time, b.chain_id, b.hash, b.proposer_address, b.last_block_hash,
-    COUNT(t.block_index) AS tx_count
+    COUNT(t.block_index) AS tx_count,
+    CAST(COALESCE(v.name, '') AS TEXT) AS proposer_name,
+    CAST(COALESCE(v.account_address, x'') AS BLOB) AS proposer_account,
+    CAST(COALESCE((
+        SELECT c.power FROM validator_set_changes AS c
+        WHERE c.address = b.proposer_address AND c.height <= b.height
+        ORDER BY c.height DESC
+        LIMIT 1
+    ), 0) AS INTEGER) AS proposer_power
 FROM blocks AS b
 LEFT JOIN transactions AS t
     ON b.height = t.block_height
+LEFT JOIN validators AS v
+    ON b.proposer_address = v.address
 WHERE (
     (?1 = '' OR b.chain_id = ?1)
     AND LENGTH(?2) <= 64 -- if passed arg is longer, then just abort the query
@@ -167,6 +252,9 @@ type SearchBlocksRow struct {
 	ProposerAddress []byte
 	LastBlockHash   []byte
 	TxCount         int64
+	ProposerName    string
+	ProposerAccount []byte
+	ProposerPower   int64
 }
 
 func (q *Queries) SearchBlocks(ctx context.Context, arg SearchBlocksParams) ([]SearchBlocksRow, error) {
@@ -192,6 +280,9 @@ func (q *Queries) SearchBlocks(ctx context.Context, arg SearchBlocksParams) ([]S
 			&i.ProposerAddress,
 			&i.LastBlockHash,
 			&i.TxCount,
+			&i.ProposerName,
+			&i.ProposerAccount,
+			&i.ProposerPower,
 		); err != nil {
 			return nil, err
 		}
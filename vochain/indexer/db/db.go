@@ -24,15 +24,60 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.addStatsAccountsDailyStmt, err = db.PrepareContext(ctx, addStatsAccountsDaily); err != nil {
+		return nil, fmt.Errorf("error preparing query AddStatsAccountsDaily: %w", err)
+	}
+	if q.addStatsFeesDailyStmt, err = db.PrepareContext(ctx, addStatsFeesDaily); err != nil {
+		return nil, fmt.Errorf("error preparing query AddStatsFeesDaily: %w", err)
+	}
+	if q.addStatsTxsDailyStmt, err = db.PrepareContext(ctx, addStatsTxsDaily); err != nil {
+		return nil, fmt.Errorf("error preparing query AddStatsTxsDaily: %w", err)
+	}
+	if q.addStatsVotesHourlyStmt, err = db.PrepareContext(ctx, addStatsVotesHourly); err != nil {
+		return nil, fmt.Errorf("error preparing query AddStatsVotesHourly: %w", err)
+	}
+	if q.aggregateBlockStatsStmt, err = db.PrepareContext(ctx, aggregateBlockStats); err != nil {
+		return nil, fmt.Errorf("error preparing query AggregateBlockStats: %w", err)
+	}
+	if q.aggregateBlockStatsTxTypesStmt, err = db.PrepareContext(ctx, aggregateBlockStatsTxTypes); err != nil {
+		return nil, fmt.Errorf("error preparing query AggregateBlockStatsTxTypes: %w", err)
+	}
 	if q.computeProcessVoteCountStmt, err = db.PrepareContext(ctx, computeProcessVoteCount); err != nil {
 		return nil, fmt.Errorf("error preparing query ComputeProcessVoteCount: %w", err)
 	}
+	if q.countAccountFeedStmt, err = db.PrepareContext(ctx, countAccountFeed); err != nil {
+		return nil, fmt.Errorf("error preparing query CountAccountFeed: %w", err)
+	}
 	if q.countAccountsStmt, err = db.PrepareContext(ctx, countAccounts); err != nil {
 		return nil, fmt.Errorf("error preparing query CountAccounts: %w", err)
 	}
+	if q.countAnomaliesStmt, err = db.PrepareContext(ctx, countAnomalies); err != nil {
+		return nil, fmt.Errorf("error preparing query CountAnomalies: %w", err)
+	}
 	if q.countBlocksStmt, err = db.PrepareContext(ctx, countBlocks); err != nil {
 		return nil, fmt.Errorf("error preparing query CountBlocks: %w", err)
 	}
+	if q.countBlocksInRangeStmt, err = db.PrepareContext(ctx, countBlocksInRange); err != nil {
+		return nil, fmt.Errorf("error preparing query CountBlocksInRange: %w", err)
+	}
+	if q.countProcessCensusVersionsStmt, err = db.PrepareContext(ctx, countProcessCensusVersions); err != nil {
+		return nil, fmt.Errorf("error preparing query CountProcessCensusVersions: %w", err)
+	}
+	if q.countProcessVotesStmt, err = db.PrepareContext(ctx, countProcessVotes); err != nil {
+		return nil, fmt.Errorf("error preparing query CountProcessVotes: %w", err)
+	}
+	if q.countSearchProcessesStmt, err = db.PrepareContext(ctx, countSearchProcesses); err != nil {
+		return nil, fmt.Errorf("error preparing query CountSearchProcesses: %w", err)
+	}
+	if q.countSearchTokenTransfersStmt, err = db.PrepareContext(ctx, countSearchTokenTransfers); err != nil {
+		return nil, fmt.Errorf("error preparing query CountSearchTokenTransfers: %w", err)
+	}
+	if q.countSearchTransactionsStmt, err = db.PrepareContext(ctx, countSearchTransactions); err != nil {
+		return nil, fmt.Errorf("error preparing query CountSearchTransactions: %w", err)
+	}
+	if q.countSearchVotesStmt, err = db.PrepareContext(ctx, countSearchVotes); err != nil {
+		return nil, fmt.Errorf("error preparing query CountSearchVotes: %w", err)
+	}
 	if q.countTokenTransfersByAccountStmt, err = db.PrepareContext(ctx, countTokenTransfersByAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query CountTokenTransfersByAccount: %w", err)
 	}
@@ -42,18 +87,54 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.countTransactionsByHeightStmt, err = db.PrepareContext(ctx, countTransactionsByHeight); err != nil {
 		return nil, fmt.Errorf("error preparing query CountTransactionsByHeight: %w", err)
 	}
+	if q.countTransactionsBySignerStmt, err = db.PrepareContext(ctx, countTransactionsBySigner); err != nil {
+		return nil, fmt.Errorf("error preparing query CountTransactionsBySigner: %w", err)
+	}
+	if q.countTransactionsByTypeAndHeightStmt, err = db.PrepareContext(ctx, countTransactionsByTypeAndHeight); err != nil {
+		return nil, fmt.Errorf("error preparing query CountTransactionsByTypeAndHeight: %w", err)
+	}
+	if q.countValidatorProposalsStmt, err = db.PrepareContext(ctx, countValidatorProposals); err != nil {
+		return nil, fmt.Errorf("error preparing query CountValidatorProposals: %w", err)
+	}
+	if q.countValidatorSignaturesStmt, err = db.PrepareContext(ctx, countValidatorSignatures); err != nil {
+		return nil, fmt.Errorf("error preparing query CountValidatorSignatures: %w", err)
+	}
 	if q.countVotesStmt, err = db.PrepareContext(ctx, countVotes); err != nil {
 		return nil, fmt.Errorf("error preparing query CountVotes: %w", err)
 	}
+	if q.countVotesByHeightStmt, err = db.PrepareContext(ctx, countVotesByHeight); err != nil {
+		return nil, fmt.Errorf("error preparing query CountVotesByHeight: %w", err)
+	}
 	if q.createAccountStmt, err = db.PrepareContext(ctx, createAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateAccount: %w", err)
 	}
+	if q.createAccountRecoveryEventStmt, err = db.PrepareContext(ctx, createAccountRecoveryEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAccountRecoveryEvent: %w", err)
+	}
+	if q.createAnomalyStmt, err = db.PrepareContext(ctx, createAnomaly); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAnomaly: %w", err)
+	}
 	if q.createBlockStmt, err = db.PrepareContext(ctx, createBlock); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateBlock: %w", err)
 	}
+	if q.createBlockStatsStmt, err = db.PrepareContext(ctx, createBlockStats); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateBlockStats: %w", err)
+	}
+	if q.createBlockStatsTxTypeStmt, err = db.PrepareContext(ctx, createBlockStatsTxType); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateBlockStatsTxType: %w", err)
+	}
 	if q.createProcessStmt, err = db.PrepareContext(ctx, createProcess); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateProcess: %w", err)
 	}
+	if q.createProcessCensusVersionStmt, err = db.PrepareContext(ctx, createProcessCensusVersion); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateProcessCensusVersion: %w", err)
+	}
+	if q.createProcessStatusChangeStmt, err = db.PrepareContext(ctx, createProcessStatusChange); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateProcessStatusChange: %w", err)
+	}
+	if q.createResultsProofStmt, err = db.PrepareContext(ctx, createResultsProof); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateResultsProof: %w", err)
+	}
 	if q.createTokenFeeStmt, err = db.PrepareContext(ctx, createTokenFee); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateTokenFee: %w", err)
 	}
@@ -63,30 +144,123 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.createTransactionStmt, err = db.PrepareContext(ctx, createTransaction); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateTransaction: %w", err)
 	}
+	if q.createTransactionReceiptStmt, err = db.PrepareContext(ctx, createTransactionReceipt); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTransactionReceipt: %w", err)
+	}
+	if q.createValidatorStmt, err = db.PrepareContext(ctx, createValidator); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateValidator: %w", err)
+	}
+	if q.createValidatorSetChangeStmt, err = db.PrepareContext(ctx, createValidatorSetChange); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateValidatorSetChange: %w", err)
+	}
+	if q.createValidatorSignatureStmt, err = db.PrepareContext(ctx, createValidatorSignature); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateValidatorSignature: %w", err)
+	}
 	if q.createVoteStmt, err = db.PrepareContext(ctx, createVote); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateVote: %w", err)
 	}
+	if q.deleteAccountKVStmt, err = db.PrepareContext(ctx, deleteAccountKV); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAccountKV: %w", err)
+	}
+	if q.deleteBlockStatsTxTypesStmt, err = db.PrepareContext(ctx, deleteBlockStatsTxTypes); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteBlockStatsTxTypes: %w", err)
+	}
+	if q.deleteProcessAnomaliesStmt, err = db.PrepareContext(ctx, deleteProcessAnomalies); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteProcessAnomalies: %w", err)
+	}
+	if q.deleteVoteDelegationStmt, err = db.PrepareContext(ctx, deleteVoteDelegation); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteVoteDelegation: %w", err)
+	}
+	if q.existsAccountStmt, err = db.PrepareContext(ctx, existsAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query ExistsAccount: %w", err)
+	}
+	if q.getAccountKVStmt, err = db.PrepareContext(ctx, getAccountKV); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAccountKV: %w", err)
+	}
+	if q.getBackfillsStmt, err = db.PrepareContext(ctx, getBackfills); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBackfills: %w", err)
+	}
+	if q.getBlockAtTimeStmt, err = db.PrepareContext(ctx, getBlockAtTime); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBlockAtTime: %w", err)
+	}
 	if q.getBlockByHashStmt, err = db.PrepareContext(ctx, getBlockByHash); err != nil {
 		return nil, fmt.Errorf("error preparing query GetBlockByHash: %w", err)
 	}
 	if q.getBlockByHeightStmt, err = db.PrepareContext(ctx, getBlockByHeight); err != nil {
 		return nil, fmt.Errorf("error preparing query GetBlockByHeight: %w", err)
 	}
+	if q.getBlockProposerStmt, err = db.PrepareContext(ctx, getBlockProposer); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBlockProposer: %w", err)
+	}
+	if q.getBlockStatsStmt, err = db.PrepareContext(ctx, getBlockStats); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBlockStats: %w", err)
+	}
+	if q.getBlockStatsTxTypesStmt, err = db.PrepareContext(ctx, getBlockStatsTxTypes); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBlockStatsTxTypes: %w", err)
+	}
 	if q.getEntityCountStmt, err = db.PrepareContext(ctx, getEntityCount); err != nil {
 		return nil, fmt.Errorf("error preparing query GetEntityCount: %w", err)
 	}
+	if q.getFirstBlockFromHeightStmt, err = db.PrepareContext(ctx, getFirstBlockFromHeight); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFirstBlockFromHeight: %w", err)
+	}
+	if q.getNullifierGroupProcessesStmt, err = db.PrepareContext(ctx, getNullifierGroupProcesses); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNullifierGroupProcesses: %w", err)
+	}
 	if q.getProcessStmt, err = db.PrepareContext(ctx, getProcess); err != nil {
 		return nil, fmt.Errorf("error preparing query GetProcess: %w", err)
 	}
+	if q.getProcessAnomaliesStmt, err = db.PrepareContext(ctx, getProcessAnomalies); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProcessAnomalies: %w", err)
+	}
+	if q.getProcessCensusHistoryStmt, err = db.PrepareContext(ctx, getProcessCensusHistory); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProcessCensusHistory: %w", err)
+	}
 	if q.getProcessCountStmt, err = db.PrepareContext(ctx, getProcessCount); err != nil {
 		return nil, fmt.Errorf("error preparing query GetProcessCount: %w", err)
 	}
+	if q.getProcessIDsAfterRowIDStmt, err = db.PrepareContext(ctx, getProcessIDsAfterRowID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProcessIDsAfterRowID: %w", err)
+	}
 	if q.getProcessIDsByFinalResultsStmt, err = db.PrepareContext(ctx, getProcessIDsByFinalResults); err != nil {
 		return nil, fmt.Errorf("error preparing query GetProcessIDsByFinalResults: %w", err)
 	}
+	if q.getProcessEnvelopesStmt, err = db.PrepareContext(ctx, getProcessEnvelopes); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProcessEnvelopes: %w", err)
+	}
+	if q.getProcessNullifiersStmt, err = db.PrepareContext(ctx, getProcessNullifiers); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProcessNullifiers: %w", err)
+	}
 	if q.getProcessStatusStmt, err = db.PrepareContext(ctx, getProcessStatus); err != nil {
 		return nil, fmt.Errorf("error preparing query GetProcessStatus: %w", err)
 	}
+	if q.getProcessStatusHistoryStmt, err = db.PrepareContext(ctx, getProcessStatusHistory); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProcessStatusHistory: %w", err)
+	}
+	if q.getProcessVoteCountStmt, err = db.PrepareContext(ctx, getProcessVoteCount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProcessVoteCount: %w", err)
+	}
+	if q.getProcessVotePackagesStmt, err = db.PrepareContext(ctx, getProcessVotePackages); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProcessVotePackages: %w", err)
+	}
+	if q.getProcessesToCheckAvailabilityStmt, err = db.PrepareContext(ctx, getProcessesToCheckAvailability); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProcessesToCheckAvailability: %w", err)
+	}
+	if q.getResultsProofStmt, err = db.PrepareContext(ctx, getResultsProof); err != nil {
+		return nil, fmt.Errorf("error preparing query GetResultsProof: %w", err)
+	}
+	if q.getStatsAccountsDailyStmt, err = db.PrepareContext(ctx, getStatsAccountsDaily); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStatsAccountsDaily: %w", err)
+	}
+	if q.getStatsFeesDailyStmt, err = db.PrepareContext(ctx, getStatsFeesDaily); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStatsFeesDaily: %w", err)
+	}
+	if q.getStatsTxsDailyStmt, err = db.PrepareContext(ctx, getStatsTxsDaily); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStatsTxsDaily: %w", err)
+	}
+	if q.getStatsVotesHourlyStmt, err = db.PrepareContext(ctx, getStatsVotesHourly); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStatsVotesHourly: %w", err)
+	}
 	if q.getTokenTransferStmt, err = db.PrepareContext(ctx, getTokenTransfer); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTokenTransfer: %w", err)
 	}
@@ -96,12 +270,39 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getTransactionByHeightAndIndexStmt, err = db.PrepareContext(ctx, getTransactionByHeightAndIndex); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTransactionByHeightAndIndex: %w", err)
 	}
+	if q.getTransactionReceiptStmt, err = db.PrepareContext(ctx, getTransactionReceipt); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransactionReceipt: %w", err)
+	}
+	if q.getTransactionsMaxRowIDStmt, err = db.PrepareContext(ctx, getTransactionsMaxRowID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransactionsMaxRowID: %w", err)
+	}
+	if q.getTransactionsWithoutSignerStmt, err = db.PrepareContext(ctx, getTransactionsWithoutSigner); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransactionsWithoutSigner: %w", err)
+	}
+	if q.getValidatorStmt, err = db.PrepareContext(ctx, getValidator); err != nil {
+		return nil, fmt.Errorf("error preparing query GetValidator: %w", err)
+	}
+	if q.getValidatorPowersStmt, err = db.PrepareContext(ctx, getValidatorPowers); err != nil {
+		return nil, fmt.Errorf("error preparing query GetValidatorPowers: %w", err)
+	}
 	if q.getVoteStmt, err = db.PrepareContext(ctx, getVote); err != nil {
 		return nil, fmt.Errorf("error preparing query GetVote: %w", err)
 	}
+	if q.getVotesMaxRowIDStmt, err = db.PrepareContext(ctx, getVotesMaxRowID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetVotesMaxRowID: %w", err)
+	}
 	if q.lastBlockHeightStmt, err = db.PrepareContext(ctx, lastBlockHeight); err != nil {
 		return nil, fmt.Errorf("error preparing query LastBlockHeight: %w", err)
 	}
+	if q.scheduleBackfillStmt, err = db.PrepareContext(ctx, scheduleBackfill); err != nil {
+		return nil, fmt.Errorf("error preparing query ScheduleBackfill: %w", err)
+	}
+	if q.searchAccountFeedStmt, err = db.PrepareContext(ctx, searchAccountFeed); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchAccountFeed: %w", err)
+	}
+	if q.searchAccountRecoveryEventsStmt, err = db.PrepareContext(ctx, searchAccountRecoveryEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchAccountRecoveryEvents: %w", err)
+	}
 	if q.searchAccountsStmt, err = db.PrepareContext(ctx, searchAccounts); err != nil {
 		return nil, fmt.Errorf("error preparing query SearchAccounts: %w", err)
 	}
@@ -123,15 +324,54 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.searchTransactionsStmt, err = db.PrepareContext(ctx, searchTransactions); err != nil {
 		return nil, fmt.Errorf("error preparing query SearchTransactions: %w", err)
 	}
+	if q.searchTransactionsBySignerStmt, err = db.PrepareContext(ctx, searchTransactionsBySigner); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchTransactionsBySigner: %w", err)
+	}
+	if q.searchUnavailableMetadataStmt, err = db.PrepareContext(ctx, searchUnavailableMetadata); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchUnavailableMetadata: %w", err)
+	}
+	if q.searchValidatorSetChangesStmt, err = db.PrepareContext(ctx, searchValidatorSetChanges); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchValidatorSetChanges: %w", err)
+	}
+	if q.searchValidatorsStmt, err = db.PrepareContext(ctx, searchValidators); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchValidators: %w", err)
+	}
+	if q.searchVoteDelegationsStmt, err = db.PrepareContext(ctx, searchVoteDelegations); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchVoteDelegations: %w", err)
+	}
 	if q.searchVotesStmt, err = db.PrepareContext(ctx, searchVotes); err != nil {
 		return nil, fmt.Errorf("error preparing query SearchVotes: %w", err)
 	}
+	if q.setAccountKVStmt, err = db.PrepareContext(ctx, setAccountKV); err != nil {
+		return nil, fmt.Errorf("error preparing query SetAccountKV: %w", err)
+	}
+	if q.setMetadataAvailabilityStmt, err = db.PrepareContext(ctx, setMetadataAvailability); err != nil {
+		return nil, fmt.Errorf("error preparing query SetMetadataAvailability: %w", err)
+	}
 	if q.setProcessResultsCancelledStmt, err = db.PrepareContext(ctx, setProcessResultsCancelled); err != nil {
 		return nil, fmt.Errorf("error preparing query SetProcessResultsCancelled: %w", err)
 	}
 	if q.setProcessResultsReadyStmt, err = db.PrepareContext(ctx, setProcessResultsReady); err != nil {
 		return nil, fmt.Errorf("error preparing query SetProcessResultsReady: %w", err)
 	}
+	if q.setTransactionSignerStmt, err = db.PrepareContext(ctx, setTransactionSigner); err != nil {
+		return nil, fmt.Errorf("error preparing query SetTransactionSigner: %w", err)
+	}
+	if q.setVoteDecryptedPackageStmt, err = db.PrepareContext(ctx, setVoteDecryptedPackage); err != nil {
+		return nil, fmt.Errorf("error preparing query SetVoteDecryptedPackage: %w", err)
+	}
+	if q.setVotesBlockTimeStmt, err = db.PrepareContext(ctx, setVotesBlockTime); err != nil {
+		return nil, fmt.Errorf("error preparing query SetVotesBlockTime: %w", err)
+	}
+	if q.setVoteDelegationStmt, err = db.PrepareContext(ctx, setVoteDelegation); err != nil {
+		return nil, fmt.Errorf("error preparing query SetVoteDelegation: %w", err)
+	}
+	if q.sumTokenFeesByHeightStmt, err = db.PrepareContext(ctx, sumTokenFeesByHeight); err != nil {
+		return nil, fmt.Errorf("error preparing query SumTokenFeesByHeight: %w", err)
+	}
+	if q.updateBackfillStmt, err = db.PrepareContext(ctx, updateBackfill); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateBackfill: %w", err)
+	}
 	if q.updateProcessEndDateStmt, err = db.PrepareContext(ctx, updateProcessEndDate); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateProcessEndDate: %w", err)
 	}
@@ -144,16 +384,427 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.updateProcessResultsStmt, err = db.PrepareContext(ctx, updateProcessResults); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateProcessResults: %w", err)
 	}
+	if q.updateProcessTurnoutStmt, err = db.PrepareContext(ctx, updateProcessTurnout); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateProcessTurnout: %w", err)
+	}
+	if q.updateValidatorPowerStmt, err = db.PrepareContext(ctx, updateValidatorPower); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateValidatorPower: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.createTransactionReceiptStmt != nil {
+		if cerr := q.createTransactionReceiptStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTransactionReceiptStmt: %w", cerr)
+		}
+	}
+	if q.getTransactionReceiptStmt != nil {
+		if cerr := q.getTransactionReceiptStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransactionReceiptStmt: %w", cerr)
+		}
+	}
+	if q.countProcessVotesStmt != nil {
+		if cerr := q.countProcessVotesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countProcessVotesStmt: %w", cerr)
+		}
+	}
+	if q.countSearchProcessesStmt != nil {
+		if cerr := q.countSearchProcessesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countSearchProcessesStmt: %w", cerr)
+		}
+	}
+	if q.countSearchTokenTransfersStmt != nil {
+		if cerr := q.countSearchTokenTransfersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countSearchTokenTransfersStmt: %w", cerr)
+		}
+	}
+	if q.getBackfillsStmt != nil {
+		if cerr := q.getBackfillsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBackfillsStmt: %w", cerr)
+		}
+	}
+	if q.getProcessIDsAfterRowIDStmt != nil {
+		if cerr := q.getProcessIDsAfterRowIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProcessIDsAfterRowIDStmt: %w", cerr)
+		}
+	}
+	if q.getTransactionsWithoutSignerStmt != nil {
+		if cerr := q.getTransactionsWithoutSignerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransactionsWithoutSignerStmt: %w", cerr)
+		}
+	}
+	if q.scheduleBackfillStmt != nil {
+		if cerr := q.scheduleBackfillStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing scheduleBackfillStmt: %w", cerr)
+		}
+	}
+	if q.setTransactionSignerStmt != nil {
+		if cerr := q.setTransactionSignerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setTransactionSignerStmt: %w", cerr)
+		}
+	}
+	if q.updateBackfillStmt != nil {
+		if cerr := q.updateBackfillStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateBackfillStmt: %w", cerr)
+		}
+	}
+	if q.createAccountRecoveryEventStmt != nil {
+		if cerr := q.createAccountRecoveryEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAccountRecoveryEventStmt: %w", cerr)
+		}
+	}
+	if q.searchAccountRecoveryEventsStmt != nil {
+		if cerr := q.searchAccountRecoveryEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchAccountRecoveryEventsStmt: %w", cerr)
+		}
+	}
+	if q.getProcessVotePackagesStmt != nil {
+		if cerr := q.getProcessVotePackagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProcessVotePackagesStmt: %w", cerr)
+		}
+	}
+	if q.setVoteDecryptedPackageStmt != nil {
+		if cerr := q.setVoteDecryptedPackageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setVoteDecryptedPackageStmt: %w", cerr)
+		}
+	}
+	if q.setVotesBlockTimeStmt != nil {
+		if cerr := q.setVotesBlockTimeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setVotesBlockTimeStmt: %w", cerr)
+		}
+	}
+	if q.deleteVoteDelegationStmt != nil {
+		if cerr := q.deleteVoteDelegationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteVoteDelegationStmt: %w", cerr)
+		}
+	}
+	if q.searchVoteDelegationsStmt != nil {
+		if cerr := q.searchVoteDelegationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchVoteDelegationsStmt: %w", cerr)
+		}
+	}
+	if q.setVoteDelegationStmt != nil {
+		if cerr := q.setVoteDelegationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setVoteDelegationStmt: %w", cerr)
+		}
+	}
+	if q.addStatsAccountsDailyStmt != nil {
+		if cerr := q.addStatsAccountsDailyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addStatsAccountsDailyStmt: %w", cerr)
+		}
+	}
+	if q.addStatsFeesDailyStmt != nil {
+		if cerr := q.addStatsFeesDailyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addStatsFeesDailyStmt: %w", cerr)
+		}
+	}
+	if q.addStatsTxsDailyStmt != nil {
+		if cerr := q.addStatsTxsDailyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addStatsTxsDailyStmt: %w", cerr)
+		}
+	}
+	if q.addStatsVotesHourlyStmt != nil {
+		if cerr := q.addStatsVotesHourlyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addStatsVotesHourlyStmt: %w", cerr)
+		}
+	}
+	if q.existsAccountStmt != nil {
+		if cerr := q.existsAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing existsAccountStmt: %w", cerr)
+		}
+	}
+	if q.getStatsAccountsDailyStmt != nil {
+		if cerr := q.getStatsAccountsDailyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStatsAccountsDailyStmt: %w", cerr)
+		}
+	}
+	if q.getStatsFeesDailyStmt != nil {
+		if cerr := q.getStatsFeesDailyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStatsFeesDailyStmt: %w", cerr)
+		}
+	}
+	if q.getStatsTxsDailyStmt != nil {
+		if cerr := q.getStatsTxsDailyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStatsTxsDailyStmt: %w", cerr)
+		}
+	}
+	if q.getStatsVotesHourlyStmt != nil {
+		if cerr := q.getStatsVotesHourlyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStatsVotesHourlyStmt: %w", cerr)
+		}
+	}
+	if q.searchTransactionsBySignerStmt != nil {
+		if cerr := q.searchTransactionsBySignerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchTransactionsBySignerStmt: %w", cerr)
+		}
+	}
+	if q.countSearchTransactionsStmt != nil {
+		if cerr := q.countSearchTransactionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countSearchTransactionsStmt: %w", cerr)
+		}
+	}
+	if q.countSearchVotesStmt != nil {
+		if cerr := q.countSearchVotesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countSearchVotesStmt: %w", cerr)
+		}
+	}
+	if q.getProcessVoteCountStmt != nil {
+		if cerr := q.getProcessVoteCountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProcessVoteCountStmt: %w", cerr)
+		}
+	}
+	if q.getTransactionsMaxRowIDStmt != nil {
+		if cerr := q.getTransactionsMaxRowIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransactionsMaxRowIDStmt: %w", cerr)
+		}
+	}
+	if q.getVotesMaxRowIDStmt != nil {
+		if cerr := q.getVotesMaxRowIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getVotesMaxRowIDStmt: %w", cerr)
+		}
+	}
+	if q.getBlockAtTimeStmt != nil {
+		if cerr := q.getBlockAtTimeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBlockAtTimeStmt: %w", cerr)
+		}
+	}
+	if q.getFirstBlockFromHeightStmt != nil {
+		if cerr := q.getFirstBlockFromHeightStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFirstBlockFromHeightStmt: %w", cerr)
+		}
+	}
+	if q.searchAccountFeedStmt != nil {
+		if cerr := q.searchAccountFeedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchAccountFeedStmt: %w", cerr)
+		}
+	}
+	if q.getNullifierGroupProcessesStmt != nil {
+		if cerr := q.getNullifierGroupProcessesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNullifierGroupProcessesStmt: %w", cerr)
+		}
+	}
+	if q.getBlockProposerStmt != nil {
+		if cerr := q.getBlockProposerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBlockProposerStmt: %w", cerr)
+		}
+	}
+	if q.countAnomaliesStmt != nil {
+		if cerr := q.countAnomaliesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countAnomaliesStmt: %w", cerr)
+		}
+	}
+	if q.createAnomalyStmt != nil {
+		if cerr := q.createAnomalyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAnomalyStmt: %w", cerr)
+		}
+	}
+	if q.deleteProcessAnomaliesStmt != nil {
+		if cerr := q.deleteProcessAnomaliesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteProcessAnomaliesStmt: %w", cerr)
+		}
+	}
+	if q.getProcessAnomaliesStmt != nil {
+		if cerr := q.getProcessAnomaliesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProcessAnomaliesStmt: %w", cerr)
+		}
+	}
+	if q.countProcessCensusVersionsStmt != nil {
+		if cerr := q.countProcessCensusVersionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countProcessCensusVersionsStmt: %w", cerr)
+		}
+	}
+	if q.createProcessCensusVersionStmt != nil {
+		if cerr := q.createProcessCensusVersionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createProcessCensusVersionStmt: %w", cerr)
+		}
+	}
+	if q.getProcessCensusHistoryStmt != nil {
+		if cerr := q.getProcessCensusHistoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProcessCensusHistoryStmt: %w", cerr)
+		}
+	}
+	if q.createProcessStatusChangeStmt != nil {
+		if cerr := q.createProcessStatusChangeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createProcessStatusChangeStmt: %w", cerr)
+		}
+	}
+	if q.getProcessStatusHistoryStmt != nil {
+		if cerr := q.getProcessStatusHistoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProcessStatusHistoryStmt: %w", cerr)
+		}
+	}
+	if q.getProcessEnvelopesStmt != nil {
+		if cerr := q.getProcessEnvelopesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProcessEnvelopesStmt: %w", cerr)
+		}
+	}
+	if q.getProcessNullifiersStmt != nil {
+		if cerr := q.getProcessNullifiersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProcessNullifiersStmt: %w", cerr)
+		}
+	}
+	if q.deleteAccountKVStmt != nil {
+		if cerr := q.deleteAccountKVStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAccountKVStmt: %w", cerr)
+		}
+	}
+	if q.getAccountKVStmt != nil {
+		if cerr := q.getAccountKVStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAccountKVStmt: %w", cerr)
+		}
+	}
+	if q.setAccountKVStmt != nil {
+		if cerr := q.setAccountKVStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setAccountKVStmt: %w", cerr)
+		}
+	}
+	if q.updateProcessTurnoutStmt != nil {
+		if cerr := q.updateProcessTurnoutStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateProcessTurnoutStmt: %w", cerr)
+		}
+	}
+	if q.aggregateBlockStatsStmt != nil {
+		if cerr := q.aggregateBlockStatsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing aggregateBlockStatsStmt: %w", cerr)
+		}
+	}
+	if q.aggregateBlockStatsTxTypesStmt != nil {
+		if cerr := q.aggregateBlockStatsTxTypesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing aggregateBlockStatsTxTypesStmt: %w", cerr)
+		}
+	}
+	if q.countTransactionsByTypeAndHeightStmt != nil {
+		if cerr := q.countTransactionsByTypeAndHeightStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countTransactionsByTypeAndHeightStmt: %w", cerr)
+		}
+	}
+	if q.countVotesByHeightStmt != nil {
+		if cerr := q.countVotesByHeightStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countVotesByHeightStmt: %w", cerr)
+		}
+	}
+	if q.createBlockStatsStmt != nil {
+		if cerr := q.createBlockStatsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createBlockStatsStmt: %w", cerr)
+		}
+	}
+	if q.createBlockStatsTxTypeStmt != nil {
+		if cerr := q.createBlockStatsTxTypeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createBlockStatsTxTypeStmt: %w", cerr)
+		}
+	}
+	if q.deleteBlockStatsTxTypesStmt != nil {
+		if cerr := q.deleteBlockStatsTxTypesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteBlockStatsTxTypesStmt: %w", cerr)
+		}
+	}
+	if q.getBlockStatsStmt != nil {
+		if cerr := q.getBlockStatsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBlockStatsStmt: %w", cerr)
+		}
+	}
+	if q.getBlockStatsTxTypesStmt != nil {
+		if cerr := q.getBlockStatsTxTypesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBlockStatsTxTypesStmt: %w", cerr)
+		}
+	}
+	if q.sumTokenFeesByHeightStmt != nil {
+		if cerr := q.sumTokenFeesByHeightStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing sumTokenFeesByHeightStmt: %w", cerr)
+		}
+	}
+	if q.createResultsProofStmt != nil {
+		if cerr := q.createResultsProofStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createResultsProofStmt: %w", cerr)
+		}
+	}
+	if q.getResultsProofStmt != nil {
+		if cerr := q.getResultsProofStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getResultsProofStmt: %w", cerr)
+		}
+	}
+	if q.getProcessesToCheckAvailabilityStmt != nil {
+		if cerr := q.getProcessesToCheckAvailabilityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProcessesToCheckAvailabilityStmt: %w", cerr)
+		}
+	}
+	if q.searchUnavailableMetadataStmt != nil {
+		if cerr := q.searchUnavailableMetadataStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchUnavailableMetadataStmt: %w", cerr)
+		}
+	}
+	if q.setMetadataAvailabilityStmt != nil {
+		if cerr := q.setMetadataAvailabilityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setMetadataAvailabilityStmt: %w", cerr)
+		}
+	}
+	if q.countBlocksInRangeStmt != nil {
+		if cerr := q.countBlocksInRangeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countBlocksInRangeStmt: %w", cerr)
+		}
+	}
+	if q.countValidatorProposalsStmt != nil {
+		if cerr := q.countValidatorProposalsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countValidatorProposalsStmt: %w", cerr)
+		}
+	}
+	if q.countValidatorSignaturesStmt != nil {
+		if cerr := q.countValidatorSignaturesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countValidatorSignaturesStmt: %w", cerr)
+		}
+	}
+	if q.createValidatorStmt != nil {
+		if cerr := q.createValidatorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createValidatorStmt: %w", cerr)
+		}
+	}
+	if q.createValidatorSetChangeStmt != nil {
+		if cerr := q.createValidatorSetChangeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createValidatorSetChangeStmt: %w", cerr)
+		}
+	}
+	if q.createValidatorSignatureStmt != nil {
+		if cerr := q.createValidatorSignatureStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createValidatorSignatureStmt: %w", cerr)
+		}
+	}
+	if q.getValidatorStmt != nil {
+		if cerr := q.getValidatorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getValidatorStmt: %w", cerr)
+		}
+	}
+	if q.getValidatorPowersStmt != nil {
+		if cerr := q.getValidatorPowersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getValidatorPowersStmt: %w", cerr)
+		}
+	}
+	if q.searchValidatorSetChangesStmt != nil {
+		if cerr := q.searchValidatorSetChangesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchValidatorSetChangesStmt: %w", cerr)
+		}
+	}
+	if q.searchValidatorsStmt != nil {
+		if cerr := q.searchValidatorsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchValidatorsStmt: %w", cerr)
+		}
+	}
+	if q.updateValidatorPowerStmt != nil {
+		if cerr := q.updateValidatorPowerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateValidatorPowerStmt: %w", cerr)
+		}
+	}
 	if q.computeProcessVoteCountStmt != nil {
 		if cerr := q.computeProcessVoteCountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing computeProcessVoteCountStmt: %w", cerr)
 		}
 	}
+	if q.countAccountFeedStmt != nil {
+		if cerr := q.countAccountFeedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countAccountFeedStmt: %w", cerr)
+		}
+	}
 	if q.countAccountsStmt != nil {
 		if cerr := q.countAccountsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing countAccountsStmt: %w", cerr)
@@ -179,6 +830,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing countTransactionsByHeightStmt: %w", cerr)
 		}
 	}
+	if q.countTransactionsBySignerStmt != nil {
+		if cerr := q.countTransactionsBySignerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countTransactionsBySignerStmt: %w", cerr)
+		}
+	}
 	if q.countVotesStmt != nil {
 		if cerr := q.countVotesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing countVotesStmt: %w", cerr)
@@ -386,93 +1042,257 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                                 DBTX
-	tx                                 *sql.Tx
-	computeProcessVoteCountStmt        *sql.Stmt
-	countAccountsStmt                  *sql.Stmt
-	countBlocksStmt                    *sql.Stmt
-	countTokenTransfersByAccountStmt   *sql.Stmt
-	countTransactionsStmt              *sql.Stmt
-	countTransactionsByHeightStmt      *sql.Stmt
-	countVotesStmt                     *sql.Stmt
-	createAccountStmt                  *sql.Stmt
-	createBlockStmt                    *sql.Stmt
-	createProcessStmt                  *sql.Stmt
-	createTokenFeeStmt                 *sql.Stmt
-	createTokenTransferStmt            *sql.Stmt
-	createTransactionStmt              *sql.Stmt
-	createVoteStmt                     *sql.Stmt
-	getBlockByHashStmt                 *sql.Stmt
-	getBlockByHeightStmt               *sql.Stmt
-	getEntityCountStmt                 *sql.Stmt
-	getProcessStmt                     *sql.Stmt
-	getProcessCountStmt                *sql.Stmt
-	getProcessIDsByFinalResultsStmt    *sql.Stmt
-	getProcessStatusStmt               *sql.Stmt
-	getTokenTransferStmt               *sql.Stmt
-	getTransactionByHashStmt           *sql.Stmt
-	getTransactionByHeightAndIndexStmt *sql.Stmt
-	getVoteStmt                        *sql.Stmt
-	lastBlockHeightStmt                *sql.Stmt
-	searchAccountsStmt                 *sql.Stmt
-	searchBlocksStmt                   *sql.Stmt
-	searchEntitiesStmt                 *sql.Stmt
-	searchProcessesStmt                *sql.Stmt
-	searchTokenFeesStmt                *sql.Stmt
-	searchTokenTransfersStmt           *sql.Stmt
-	searchTransactionsStmt             *sql.Stmt
-	searchVotesStmt                    *sql.Stmt
-	setProcessResultsCancelledStmt     *sql.Stmt
-	setProcessResultsReadyStmt         *sql.Stmt
-	updateProcessEndDateStmt           *sql.Stmt
-	updateProcessFromStateStmt         *sql.Stmt
-	updateProcessResultByIDStmt        *sql.Stmt
-	updateProcessResultsStmt           *sql.Stmt
+	db                                   DBTX
+	tx                                   *sql.Tx
+	addStatsAccountsDailyStmt            *sql.Stmt
+	addStatsFeesDailyStmt                *sql.Stmt
+	addStatsTxsDailyStmt                 *sql.Stmt
+	addStatsVotesHourlyStmt              *sql.Stmt
+	aggregateBlockStatsStmt              *sql.Stmt
+	aggregateBlockStatsTxTypesStmt       *sql.Stmt
+	computeProcessVoteCountStmt          *sql.Stmt
+	countAccountFeedStmt                 *sql.Stmt
+	countAccountsStmt                    *sql.Stmt
+	countAnomaliesStmt                   *sql.Stmt
+	countBlocksStmt                      *sql.Stmt
+	countBlocksInRangeStmt               *sql.Stmt
+	countProcessCensusVersionsStmt       *sql.Stmt
+	countProcessVotesStmt                *sql.Stmt
+	countSearchProcessesStmt             *sql.Stmt
+	countSearchTokenTransfersStmt        *sql.Stmt
+	countSearchTransactionsStmt          *sql.Stmt
+	countSearchVotesStmt                 *sql.Stmt
+	countTokenTransfersByAccountStmt     *sql.Stmt
+	countTransactionsStmt                *sql.Stmt
+	countTransactionsByHeightStmt        *sql.Stmt
+	countTransactionsBySignerStmt        *sql.Stmt
+	countTransactionsByTypeAndHeightStmt *sql.Stmt
+	countValidatorProposalsStmt          *sql.Stmt
+	countValidatorSignaturesStmt         *sql.Stmt
+	countVotesStmt                       *sql.Stmt
+	countVotesByHeightStmt               *sql.Stmt
+	createAccountStmt                    *sql.Stmt
+	createAccountRecoveryEventStmt       *sql.Stmt
+	createAnomalyStmt                    *sql.Stmt
+	createBlockStmt                      *sql.Stmt
+	createBlockStatsStmt                 *sql.Stmt
+	createBlockStatsTxTypeStmt           *sql.Stmt
+	createProcessStmt                    *sql.Stmt
+	createProcessCensusVersionStmt       *sql.Stmt
+	createProcessStatusChangeStmt        *sql.Stmt
+	createResultsProofStmt               *sql.Stmt
+	createTokenFeeStmt                   *sql.Stmt
+	createTokenTransferStmt              *sql.Stmt
+	createTransactionStmt                *sql.Stmt
+	createTransactionReceiptStmt         *sql.Stmt
+	createValidatorStmt                  *sql.Stmt
+	createValidatorSetChangeStmt         *sql.Stmt
+	createValidatorSignatureStmt         *sql.Stmt
+	createVoteStmt                       *sql.Stmt
+	deleteAccountKVStmt                  *sql.Stmt
+	deleteBlockStatsTxTypesStmt          *sql.Stmt
+	deleteProcessAnomaliesStmt           *sql.Stmt
+	deleteVoteDelegationStmt             *sql.Stmt
+	existsAccountStmt                    *sql.Stmt
+	getAccountKVStmt                     *sql.Stmt
+	getBackfillsStmt                     *sql.Stmt
+	getBlockAtTimeStmt                   *sql.Stmt
+	getBlockByHashStmt                   *sql.Stmt
+	getBlockByHeightStmt                 *sql.Stmt
+	getBlockProposerStmt                 *sql.Stmt
+	getBlockStatsStmt                    *sql.Stmt
+	getBlockStatsTxTypesStmt             *sql.Stmt
+	getEntityCountStmt                   *sql.Stmt
+	getFirstBlockFromHeightStmt          *sql.Stmt
+	getNullifierGroupProcessesStmt       *sql.Stmt
+	getProcessStmt                       *sql.Stmt
+	getProcessAnomaliesStmt              *sql.Stmt
+	getProcessCensusHistoryStmt          *sql.Stmt
+	getProcessCountStmt                  *sql.Stmt
+	getProcessIDsAfterRowIDStmt          *sql.Stmt
+	getProcessIDsByFinalResultsStmt      *sql.Stmt
+	getProcessEnvelopesStmt              *sql.Stmt
+	getProcessNullifiersStmt             *sql.Stmt
+	getProcessStatusStmt                 *sql.Stmt
+	getProcessStatusHistoryStmt          *sql.Stmt
+	getProcessVoteCountStmt              *sql.Stmt
+	getProcessVotePackagesStmt           *sql.Stmt
+	getProcessesToCheckAvailabilityStmt  *sql.Stmt
+	getResultsProofStmt                  *sql.Stmt
+	getStatsAccountsDailyStmt            *sql.Stmt
+	getStatsFeesDailyStmt                *sql.Stmt
+	getStatsTxsDailyStmt                 *sql.Stmt
+	getStatsVotesHourlyStmt              *sql.Stmt
+	getTokenTransferStmt                 *sql.Stmt
+	getTransactionByHashStmt             *sql.Stmt
+	getTransactionByHeightAndIndexStmt   *sql.Stmt
+	getTransactionReceiptStmt            *sql.Stmt
+	getTransactionsMaxRowIDStmt          *sql.Stmt
+	getTransactionsWithoutSignerStmt     *sql.Stmt
+	getValidatorStmt                     *sql.Stmt
+	getValidatorPowersStmt               *sql.Stmt
+	getVoteStmt                          *sql.Stmt
+	getVotesMaxRowIDStmt                 *sql.Stmt
+	lastBlockHeightStmt                  *sql.Stmt
+	scheduleBackfillStmt                 *sql.Stmt
+	searchAccountFeedStmt                *sql.Stmt
+	searchAccountRecoveryEventsStmt      *sql.Stmt
+	searchAccountsStmt                   *sql.Stmt
+	searchBlocksStmt                     *sql.Stmt
+	searchEntitiesStmt                   *sql.Stmt
+	searchProcessesStmt                  *sql.Stmt
+	searchTokenFeesStmt                  *sql.Stmt
+	searchTokenTransfersStmt             *sql.Stmt
+	searchTransactionsStmt               *sql.Stmt
+	searchTransactionsBySignerStmt       *sql.Stmt
+	searchUnavailableMetadataStmt        *sql.Stmt
+	searchValidatorSetChangesStmt        *sql.Stmt
+	searchValidatorsStmt                 *sql.Stmt
+	searchVoteDelegationsStmt            *sql.Stmt
+	searchVotesStmt                      *sql.Stmt
+	setAccountKVStmt                     *sql.Stmt
+	setMetadataAvailabilityStmt          *sql.Stmt
+	setProcessResultsCancelledStmt       *sql.Stmt
+	setProcessResultsReadyStmt           *sql.Stmt
+	setTransactionSignerStmt             *sql.Stmt
+	setVoteDecryptedPackageStmt          *sql.Stmt
+	setVotesBlockTimeStmt                *sql.Stmt
+	setVoteDelegationStmt                *sql.Stmt
+	sumTokenFeesByHeightStmt             *sql.Stmt
+	updateBackfillStmt                   *sql.Stmt
+	updateProcessEndDateStmt             *sql.Stmt
+	updateProcessFromStateStmt           *sql.Stmt
+	updateProcessResultByIDStmt          *sql.Stmt
+	updateProcessResultsStmt             *sql.Stmt
+	updateProcessTurnoutStmt             *sql.Stmt
+	updateValidatorPowerStmt             *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                                 tx,
-		tx:                                 tx,
-		computeProcessVoteCountStmt:        q.computeProcessVoteCountStmt,
-		countAccountsStmt:                  q.countAccountsStmt,
-		countBlocksStmt:                    q.countBlocksStmt,
-		countTokenTransfersByAccountStmt:   q.countTokenTransfersByAccountStmt,
-		countTransactionsStmt:              q.countTransactionsStmt,
-		countTransactionsByHeightStmt:      q.countTransactionsByHeightStmt,
-		countVotesStmt:                     q.countVotesStmt,
-		createAccountStmt:                  q.createAccountStmt,
-		createBlockStmt:                    q.createBlockStmt,
-		createProcessStmt:                  q.createProcessStmt,
-		createTokenFeeStmt:                 q.createTokenFeeStmt,
-		createTokenTransferStmt:            q.createTokenTransferStmt,
-		createTransactionStmt:              q.createTransactionStmt,
-		createVoteStmt:                     q.createVoteStmt,
-		getBlockByHashStmt:                 q.getBlockByHashStmt,
-		getBlockByHeightStmt:               q.getBlockByHeightStmt,
-		getEntityCountStmt:                 q.getEntityCountStmt,
-		getProcessStmt:                     q.getProcessStmt,
-		getProcessCountStmt:                q.getProcessCountStmt,
-		getProcessIDsByFinalResultsStmt:    q.getProcessIDsByFinalResultsStmt,
-		getProcessStatusStmt:               q.getProcessStatusStmt,
-		getTokenTransferStmt:               q.getTokenTransferStmt,
-		getTransactionByHashStmt:           q.getTransactionByHashStmt,
-		getTransactionByHeightAndIndexStmt: q.getTransactionByHeightAndIndexStmt,
-		getVoteStmt:                        q.getVoteStmt,
-		lastBlockHeightStmt:                q.lastBlockHeightStmt,
-		searchAccountsStmt:                 q.searchAccountsStmt,
-		searchBlocksStmt:                   q.searchBlocksStmt,
-		searchEntitiesStmt:                 q.searchEntitiesStmt,
-		searchProcessesStmt:                q.searchProcessesStmt,
-		searchTokenFeesStmt:                q.searchTokenFeesStmt,
-		searchTokenTransfersStmt:           q.searchTokenTransfersStmt,
-		searchTransactionsStmt:             q.searchTransactionsStmt,
-		searchVotesStmt:                    q.searchVotesStmt,
-		setProcessResultsCancelledStmt:     q.setProcessResultsCancelledStmt,
-		setProcessResultsReadyStmt:         q.setProcessResultsReadyStmt,
-		updateProcessEndDateStmt:           q.updateProcessEndDateStmt,
-		updateProcessFromStateStmt:         q.updateProcessFromStateStmt,
-		updateProcessResultByIDStmt:        q.updateProcessResultByIDStmt,
-		updateProcessResultsStmt:           q.updateProcessResultsStmt,
+		db:                                   tx,
+		tx:                                   tx,
+		addStatsAccountsDailyStmt:            q.addStatsAccountsDailyStmt,
+		addStatsFeesDailyStmt:                q.addStatsFeesDailyStmt,
+		addStatsTxsDailyStmt:                 q.addStatsTxsDailyStmt,
+		addStatsVotesHourlyStmt:              q.addStatsVotesHourlyStmt,
+		aggregateBlockStatsStmt:              q.aggregateBlockStatsStmt,
+		aggregateBlockStatsTxTypesStmt:       q.aggregateBlockStatsTxTypesStmt,
+		computeProcessVoteCountStmt:          q.computeProcessVoteCountStmt,
+		countAccountFeedStmt:                 q.countAccountFeedStmt,
+		countAccountsStmt:                    q.countAccountsStmt,
+		countAnomaliesStmt:                   q.countAnomaliesStmt,
+		countBlocksStmt:                      q.countBlocksStmt,
+		countBlocksInRangeStmt:               q.countBlocksInRangeStmt,
+		countProcessCensusVersionsStmt:       q.countProcessCensusVersionsStmt,
+		countProcessVotesStmt:                q.countProcessVotesStmt,
+		countSearchProcessesStmt:             q.countSearchProcessesStmt,
+		countSearchTokenTransfersStmt:        q.countSearchTokenTransfersStmt,
+		countSearchTransactionsStmt:          q.countSearchTransactionsStmt,
+		countSearchVotesStmt:                 q.countSearchVotesStmt,
+		countTokenTransfersByAccountStmt:     q.countTokenTransfersByAccountStmt,
+		countTransactionsStmt:                q.countTransactionsStmt,
+		countTransactionsByHeightStmt:        q.countTransactionsByHeightStmt,
+		countTransactionsBySignerStmt:        q.countTransactionsBySignerStmt,
+		countTransactionsByTypeAndHeightStmt: q.countTransactionsByTypeAndHeightStmt,
+		countValidatorProposalsStmt:          q.countValidatorProposalsStmt,
+		countValidatorSignaturesStmt:         q.countValidatorSignaturesStmt,
+		countVotesStmt:                       q.countVotesStmt,
+		countVotesByHeightStmt:               q.countVotesByHeightStmt,
+		createAccountStmt:                    q.createAccountStmt,
+		createAccountRecoveryEventStmt:       q.createAccountRecoveryEventStmt,
+		createAnomalyStmt:                    q.createAnomalyStmt,
+		createBlockStmt:                      q.createBlockStmt,
+		createBlockStatsStmt:                 q.createBlockStatsStmt,
+		createBlockStatsTxTypeStmt:           q.createBlockStatsTxTypeStmt,
+		createProcessStmt:                    q.createProcessStmt,
+		createProcessCensusVersionStmt:       q.createProcessCensusVersionStmt,
+		createProcessStatusChangeStmt:        q.createProcessStatusChangeStmt,
+		createResultsProofStmt:               q.createResultsProofStmt,
+		createTokenFeeStmt:                   q.createTokenFeeStmt,
+		createTokenTransferStmt:              q.createTokenTransferStmt,
+		createTransactionStmt:                q.createTransactionStmt,
+		createTransactionReceiptStmt:         q.createTransactionReceiptStmt,
+		createValidatorStmt:                  q.createValidatorStmt,
+		createValidatorSetChangeStmt:         q.createValidatorSetChangeStmt,
+		createValidatorSignatureStmt:         q.createValidatorSignatureStmt,
+		createVoteStmt:                       q.createVoteStmt,
+		deleteAccountKVStmt:                  q.deleteAccountKVStmt,
+		deleteBlockStatsTxTypesStmt:          q.deleteBlockStatsTxTypesStmt,
+		deleteProcessAnomaliesStmt:           q.deleteProcessAnomaliesStmt,
+		deleteVoteDelegationStmt:             q.deleteVoteDelegationStmt,
+		existsAccountStmt:                    q.existsAccountStmt,
+		getAccountKVStmt:                     q.getAccountKVStmt,
+		getBackfillsStmt:                     q.getBackfillsStmt,
+		getBlockAtTimeStmt:                   q.getBlockAtTimeStmt,
+		getBlockByHashStmt:                   q.getBlockByHashStmt,
+		getBlockByHeightStmt:                 q.getBlockByHeightStmt,
+		getBlockProposerStmt:                 q.getBlockProposerStmt,
+		getBlockStatsStmt:                    q.getBlockStatsStmt,
+		getBlockStatsTxTypesStmt:             q.getBlockStatsTxTypesStmt,
+		getEntityCountStmt:                   q.getEntityCountStmt,
+		getFirstBlockFromHeightStmt:          q.getFirstBlockFromHeightStmt,
+		getNullifierGroupProcessesStmt:       q.getNullifierGroupProcessesStmt,
+		getProcessStmt:                       q.getProcessStmt,
+		getProcessAnomaliesStmt:              q.getProcessAnomaliesStmt,
+		getProcessCensusHistoryStmt:          q.getProcessCensusHistoryStmt,
+		getProcessCountStmt:                  q.getProcessCountStmt,
+		getProcessIDsAfterRowIDStmt:          q.getProcessIDsAfterRowIDStmt,
+		getProcessIDsByFinalResultsStmt:      q.getProcessIDsByFinalResultsStmt,
+		getProcessEnvelopesStmt:              q.getProcessEnvelopesStmt,
+		getProcessNullifiersStmt:             q.getProcessNullifiersStmt,
+		getProcessStatusStmt:                 q.getProcessStatusStmt,
+		getProcessStatusHistoryStmt:          q.getProcessStatusHistoryStmt,
+		getProcessVoteCountStmt:              q.getProcessVoteCountStmt,
+		getProcessVotePackagesStmt:           q.getProcessVotePackagesStmt,
+		getProcessesToCheckAvailabilityStmt:  q.getProcessesToCheckAvailabilityStmt,
+		getResultsProofStmt:                  q.getResultsProofStmt,
+		getStatsAccountsDailyStmt:            q.getStatsAccountsDailyStmt,
+		getStatsFeesDailyStmt:                q.getStatsFeesDailyStmt,
+		getStatsTxsDailyStmt:                 q.getStatsTxsDailyStmt,
+		getStatsVotesHourlyStmt:              q.getStatsVotesHourlyStmt,
+		getTokenTransferStmt:                 q.getTokenTransferStmt,
+		getTransactionByHashStmt:             q.getTransactionByHashStmt,
+		getTransactionByHeightAndIndexStmt:   q.getTransactionByHeightAndIndexStmt,
+		getTransactionReceiptStmt:            q.getTransactionReceiptStmt,
+		getTransactionsMaxRowIDStmt:          q.getTransactionsMaxRowIDStmt,
+		getTransactionsWithoutSignerStmt:     q.getTransactionsWithoutSignerStmt,
+		getValidatorStmt:                     q.getValidatorStmt,
+		getValidatorPowersStmt:               q.getValidatorPowersStmt,
+		getVoteStmt:                          q.getVoteStmt,
+		getVotesMaxRowIDStmt:                 q.getVotesMaxRowIDStmt,
+		lastBlockHeightStmt:                  q.lastBlockHeightStmt,
+		scheduleBackfillStmt:                 q.scheduleBackfillStmt,
+		searchAccountFeedStmt:                q.searchAccountFeedStmt,
+		searchAccountRecoveryEventsStmt:      q.searchAccountRecoveryEventsStmt,
+		searchAccountsStmt:                   q.searchAccountsStmt,
+		searchBlocksStmt:                     q.searchBlocksStmt,
+		searchEntitiesStmt:                   q.searchEntitiesStmt,
+		searchProcessesStmt:                  q.searchProcessesStmt,
+		searchTokenFeesStmt:                  q.searchTokenFeesStmt,
+		searchTokenTransfersStmt:             q.searchTokenTransfersStmt,
+		searchTransactionsStmt:               q.searchTransactionsStmt,
+		searchTransactionsBySignerStmt:       q.searchTransactionsBySignerStmt,
+		searchUnavailableMetadataStmt:        q.searchUnavailableMetadataStmt,
+		searchValidatorSetChangesStmt:        q.searchValidatorSetChangesStmt,
+		searchValidatorsStmt:                 q.searchValidatorsStmt,
+		searchVoteDelegationsStmt:            q.searchVoteDelegationsStmt,
+		searchVotesStmt:                      q.searchVotesStmt,
+		setAccountKVStmt:                     q.setAccountKVStmt,
+		setMetadataAvailabilityStmt:          q.setMetadataAvailabilityStmt,
+		setProcessResultsCancelledStmt:       q.setProcessResultsCancelledStmt,
+		setProcessResultsReadyStmt:           q.setProcessResultsReadyStmt,
+		setTransactionSignerStmt:             q.setTransactionSignerStmt,
+		setVoteDecryptedPackageStmt:          q.setVoteDecryptedPackageStmt,
+		setVotesBlockTimeStmt:                q.setVotesBlockTimeStmt,
+		setVoteDelegationStmt:                q.setVoteDelegationStmt,
+		sumTokenFeesByHeightStmt:             q.sumTokenFeesByHeightStmt,
+		updateBackfillStmt:                   q.updateBackfillStmt,
+		updateProcessEndDateStmt:             q.updateProcessEndDateStmt,
+		updateProcessFromStateStmt:           q.updateProcessFromStateStmt,
+		updateProcessResultByIDStmt:          q.updateProcessResultByIDStmt,
+		updateProcessResultsStmt:             q.updateProcessResultsStmt,
+		updateProcessTurnoutStmt:             q.updateProcessTurnoutStmt,
+		updateValidatorPowerStmt:             q.updateValidatorPowerStmt,
 	}
 }
@@ -10,6 +10,30 @@ import (
 	"go.vocdoni.io/dvote/types"
 )
 
+type AccountKv struct {
+	Account types.AccountID
+	Key     string
+	Value   []byte
+	Height  int64
+}
+
+type AccountRecoveryEvent struct {
+	ID        int64
+	Account   types.AccountID
+	Kind      string
+	Guardian  []byte
+	NewOwner  []byte
+	Guardians string
+	Height    int64
+}
+
+type Backfill struct {
+	Name      string
+	Position  int64
+	Processed int64
+	Done      bool
+}
+
 type Block struct {
 	Height          int64
 	Time            time.Time
@@ -19,6 +43,23 @@ type Block struct {
 	LastBlockHash   []byte
 }
 
+type BlockStat struct {
+	Height     int64
+	Timestamp  int64
+	TxCount    int64
+	FeesBurned int64
+	VoteCount  int64
+}
+
+type IndexerAnomaly struct {
+	ID          int64
+	ProcessID   types.ProcessID
+	BlockHeight int64
+	Kind        string
+	Nullifier   types.Nullifier
+	Details     string
+}
+
 type Process struct {
 	ID                 types.ProcessID
 	EntityID           types.EntityID
@@ -48,6 +89,60 @@ type Process struct {
 	SourceBlockHeight  int64
 	SourceNetworkID    int64
 	ManuallyEnded      bool
+	Turnout            float64
+	WeightTurnout      float64
+	Unlisted           bool
+	NullifierGroup     []byte
+}
+
+type ProcessCensusHistory struct {
+	ID          int64
+	ProcessID   types.ProcessID
+	BlockHeight int64
+	CensusRoot  types.CensusRoot
+	CensusUri   string
+	CensusSize  int64
+}
+
+type ProcessStatusHistory struct {
+	ID          int64
+	ProcessID   types.ProcessID
+	BlockHeight int64
+	BlockIndex  int64
+	TxHash      types.Hash
+	Status      int64
+	Duration    int64
+}
+
+type ResultsProof struct {
+	ProcessID      types.ProcessID
+	Height         int64
+	StateRoot      []byte
+	ProcessesRoot  []byte
+	ProcessesProof []byte
+	ProcessLeaf    []byte
+	ProcessProof   []byte
+}
+
+type StatsAccountsDaily struct {
+	Day         int64
+	NewAccounts int64
+}
+
+type StatsFeesDaily struct {
+	Day        int64
+	FeesBurned int64
+}
+
+type StatsTxsDaily struct {
+	Day   int64
+	Type  string
+	Count int64
+}
+
+type StatsVotesHourly struct {
+	Hour      int64
+	VoteCount int64
 }
 
 type TokenTransfer struct {
@@ -69,3 +164,19 @@ type Transaction struct {
 	Signature   []byte
 	Signer      []byte
 }
+
+type TransactionReceipt struct {
+	Hash   types.Hash
+	Cost   int64
+	Events string
+}
+
+type Validator struct {
+	Address          []byte
+	AccountAddress   []byte
+	PubKey           []byte
+	Name             string
+	Power            int64
+	JoinHeight       int64
+	LastChangeHeight int64
+}
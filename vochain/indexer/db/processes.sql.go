@@ -23,6 +23,90 @@ func (q *Queries) ComputeProcessVoteCount(ctx context.Context, id types.ProcessI
 	return q.exec(ctx, q.computeProcessVoteCountStmt, computeProcessVoteCount, id)
 }
 
+const countSearchProcesses = `-- name: CountSearchProcesses :one
+SELECT COUNT(*) FROM processes
+WHERE (
+	LENGTH(?1) <= 40 -- if passed arg is longer, then just abort the query
+	AND (
+		?1 = ''
+		OR (LENGTH(?1) = 40 AND LOWER(HEX(entity_id)) = LOWER(?1))
+		OR (LENGTH(?1) < 40 AND INSTR(LOWER(HEX(entity_id)), LOWER(?1)) > 0)
+		-- TODO: consider keeping an entity_id_hex column for faster searches
+	)
+	AND (?2 = 0 OR namespace = ?2)
+	AND (?3 = 0 OR status = ?3)
+	AND (?4 = 0 OR source_network_id = ?4)
+	AND LENGTH(?5) <= 64 -- if passed arg is longer, then just abort the query
+	AND (
+		?5 = ''
+		OR (LENGTH(?5) = 64 AND LOWER(HEX(id)) = LOWER(?5))
+		OR (LENGTH(?5) < 64 AND INSTR(LOWER(HEX(id)), LOWER(?5)) > 0)
+		-- TODO: consider keeping an id_hex column for faster searches
+	)
+	AND (
+		?6 = -1
+		OR (?6 = 1 AND have_results = TRUE)
+		OR (?6 = 0 AND have_results = FALSE)
+	)
+	AND (
+		?7 = -1
+		OR (?7 = 1 AND final_results = TRUE)
+		OR (?7 = 0 AND final_results = FALSE)
+	)
+	AND (
+		?8 = -1
+		OR (?8 = 1 AND manually_ended = TRUE)
+		OR (?8 = 0 AND manually_ended = FALSE)
+	)
+	AND (?9 IS NULL OR start_date >= ?9)
+	AND (?10 IS NULL OR start_date <= ?10)
+	AND (?11 IS NULL OR end_date >= ?11)
+	AND (?12 IS NULL OR end_date <= ?12)
+	AND (?13 = 0 OR turnout >= ?13)
+	-- the unlisted processes are only listed for the given entity
+	AND (unlisted = FALSE OR entity_id = ?14)
+)
+`
+
+type CountSearchProcessesParams struct {
+	EntityIDSubstr   interface{}
+	Namespace        interface{}
+	Status           interface{}
+	SourceNetworkID  interface{}
+	IDSubstr         interface{}
+	HaveResults      interface{}
+	FinalResults     interface{}
+	ManuallyEnded    interface{}
+	StartDateAfter   interface{}
+	StartDateBefore  interface{}
+	EndDateAfter     interface{}
+	EndDateBefore    interface{}
+	MinTurnout       interface{}
+	UnlistedEntityID types.EntityID
+}
+
+func (q *Queries) CountSearchProcesses(ctx context.Context, arg CountSearchProcessesParams) (int64, error) {
+	row := q.queryRow(ctx, q.countSearchProcessesStmt, countSearchProcesses,
+		arg.EntityIDSubstr,
+		arg.Namespace,
+		arg.Status,
+		arg.SourceNetworkID,
+		arg.IDSubstr,
+		arg.HaveResults,
+		arg.FinalResults,
+		arg.ManuallyEnded,
+		arg.StartDateAfter,
+		arg.StartDateBefore,
+		arg.EndDateAfter,
+		arg.EndDateBefore,
+		arg.MinTurnout,
+		arg.UnlistedEntityID,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createProcess = `-- name: CreateProcess :execresult
 INSERT INTO processes (
 	id, entity_id, start_date, end_date, manually_ended,
@@ -33,7 +117,7 @@ INSERT INTO processes (
 	private_keys, public_keys,
 	question_index, creation_time,
 	source_block_height, source_network_id,
-	chain_id,
+	chain_id, unlisted, nullifier_group,
 
 	results_votes, results_weight, results_block_height
 ) VALUES (
@@ -45,7 +129,7 @@ INSERT INTO processes (
 	?, ?,
 	?, ?,
 	?, ?,
-	?,
+	?, ?, ?,
 
 	?, '"0"', 0
 )
@@ -77,6 +161,8 @@ type CreateProcessParams struct {
 	SourceBlockHeight int64
 	SourceNetworkID   int64
 	ChainID           string
+	Unlisted          bool
+	NullifierGroup    []byte
 	ResultsVotes      string
 }
 
@@ -107,6 +193,8 @@ func (q *Queries) CreateProcess(ctx context.Context, arg CreateProcessParams) (s
 		arg.SourceBlockHeight,
 		arg.SourceNetworkID,
 		arg.ChainID,
+		arg.Unlisted,
+		arg.NullifierGroup,
 		arg.ResultsVotes,
 	)
 }
@@ -123,7 +211,7 @@ func (q *Queries) GetEntityCount(ctx context.Context) (int64, error) {
 }
 
 const getProcess = `-- name: GetProcess :one
-SELECT id, entity_id, start_date, end_date, vote_count, chain_id, have_results, final_results, results_votes, results_weight, results_block_height, census_root, max_census_size, census_uri, metadata, census_origin, status, namespace, envelope, mode, vote_opts, private_keys, public_keys, question_index, creation_time, source_block_height, source_network_id, manually_ended FROM processes
+SELECT id, entity_id, start_date, end_date, vote_count, chain_id, have_results, final_results, results_votes, results_weight, results_block_height, census_root, max_census_size, census_uri, metadata, census_origin, status, namespace, envelope, mode, vote_opts, private_keys, public_keys, question_index, creation_time, source_block_height, source_network_id, manually_ended, turnout, weight_turnout, unlisted, nullifier_group FROM processes
 WHERE id = ?
 LIMIT 1
 `
@@ -160,6 +248,10 @@ func (q *Queries) GetProcess(ctx context.Context, id types.ProcessID) (Process,
 		&i.SourceBlockHeight,
 		&i.SourceNetworkID,
 		&i.ManuallyEnded,
+		&i.Turnout,
+		&i.WeightTurnout,
+		&i.Unlisted,
+		&i.NullifierGroup,
 	)
 	return i, err
 }
@@ -175,6 +267,40 @@ func (q *Queries) GetProcessCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const getNullifierGroupProcesses = `-- name: GetNullifierGroupProcesses :many
+SELECT id FROM processes
+WHERE entity_id = ?1 AND nullifier_group = ?2
+ORDER BY creation_time ASC, id ASC
+`
+
+type GetNullifierGroupProcessesParams struct {
+	EntityID       types.EntityID
+	NullifierGroup []byte
+}
+
+func (q *Queries) GetNullifierGroupProcesses(ctx context.Context, arg GetNullifierGroupProcessesParams) ([]types.ProcessID, error) {
+	rows, err := q.query(ctx, q.getNullifierGroupProcessesStmt, getNullifierGroupProcesses, arg.EntityID, arg.NullifierGroup)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []types.ProcessID
+	for rows.Next() {
+		var id types.ProcessID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getProcessIDsByFinalResults = `-- name: GetProcessIDsByFinalResults :many
 SELECT id FROM processes
 WHERE final_results = ?
@@ -216,9 +342,21 @@ func (q *Queries) GetProcessStatus(ctx context.Context, id types.ProcessID) (int
 	return status, err
 }
 
+const getProcessVoteCount = `-- name: GetProcessVoteCount :one
+SELECT vote_count FROM processes
+WHERE id = ?
+`
+
+func (q *Queries) GetProcessVoteCount(ctx context.Context, id types.ProcessID) (int64, error) {
+	row := q.queryRow(ctx, q.getProcessVoteCountStmt, getProcessVoteCount, id)
+	var vote_count int64
+	err := row.Scan(&vote_count)
+	return vote_count, err
+}
+
 const searchEntities = `-- name: SearchEntities :many
 WITH results AS (
-    SELECT id, entity_id, start_date, end_date, vote_count, chain_id, have_results, final_results, results_votes, results_weight, results_block_height, census_root, max_census_size, census_uri, metadata, census_origin, status, namespace, envelope, mode, vote_opts, private_keys, public_keys, question_index, creation_time, source_block_height, source_network_id, manually_ended
+    SELECT id, entity_id, start_date, end_date, vote_count, chain_id, have_results, final_results, results_votes, results_weight, results_block_height, census_root, max_census_size, census_uri, metadata, census_origin, status, namespace, envelope, mode, vote_opts, private_keys, public_keys, question_index, creation_time, source_block_height, source_network_id, manually_ended, turnout, weight_turnout, unlisted, nullifier_group
     FROM processes
     WHERE (?3 = '' OR (INSTR(LOWER(HEX(entity_id)), ?3) > 0))
 )
@@ -269,8 +407,7 @@ func (q *Queries) SearchEntities(ctx context.Context, arg SearchEntitiesParams)
 
 const searchProcesses = `-- name: SearchProcesses :many
 WITH results AS (
-	SELECT id, entity_id, start_date, end_date, vote_count, chain_id, have_results, final_results, results_votes, results_weight, results_block_height, census_root, max_census_size, census_uri, metadata, census_origin, status, namespace, envelope, mode, vote_opts, private_keys, public_keys, question_index, creation_time, source_block_height, source_network_id, manually_ended,
-			COUNT(*) OVER() AS total_count
+	SELECT id, entity_id, start_date, end_date, vote_count, chain_id, have_results, final_results, results_votes, results_weight, results_block_height, census_root, max_census_size, census_uri, metadata, census_origin, status, namespace, envelope, mode, vote_opts, private_keys, public_keys, question_index, creation_time, source_block_height, source_network_id, manually_ended, turnout, weight_turnout, unlisted, nullifier_group
 	FROM processes
 	WHERE (
 		LENGTH(?3) <= 40 -- if passed arg is longer, then just abort the query
@@ -309,37 +446,51 @@ WITH results AS (
 		AND (?12 IS NULL OR start_date <= ?12)
 		AND (?13 IS NULL OR end_date >= ?13)
 		AND (?14 IS NULL OR end_date <= ?14)
+		AND (?15 = 0 OR turnout >= ?15)
+		-- the unlisted processes are only listed for the given entity
+		AND (unlisted = FALSE OR entity_id = ?18)
 	)
 )
-SELECT id, total_count
+SELECT id, creation_time
 FROM results
+WHERE (
+	?16 IS NULL
+	OR creation_time < ?16
+	OR (creation_time = ?16 AND id > ?17)
+)
 ORDER BY creation_time DESC, id ASC
 LIMIT ?2
 OFFSET ?1
 `
 
 type SearchProcessesParams struct {
-	Offset          int64
-	Limit           int64
-	EntityIDSubstr  interface{}
-	Namespace       interface{}
-	Status          interface{}
-	SourceNetworkID interface{}
-	IDSubstr        interface{}
-	HaveResults     interface{}
-	FinalResults    interface{}
-	ManuallyEnded   interface{}
-	StartDateAfter  interface{}
-	StartDateBefore interface{}
-	EndDateAfter    interface{}
-	EndDateBefore   interface{}
+	Offset             int64
+	Limit              int64
+	EntityIDSubstr     interface{}
+	Namespace          interface{}
+	Status             interface{}
+	SourceNetworkID    interface{}
+	IDSubstr           interface{}
+	HaveResults        interface{}
+	FinalResults       interface{}
+	ManuallyEnded      interface{}
+	StartDateAfter     interface{}
+	StartDateBefore    interface{}
+	EndDateAfter       interface{}
+	EndDateBefore      interface{}
+	MinTurnout         interface{}
+	CursorCreationTime interface{}
+	CursorID           interface{}
+	UnlistedEntityID   types.EntityID
 }
 
 type SearchProcessesRow struct {
-	ID         []byte
-	TotalCount int64
+	ID           []byte
+	CreationTime time.Time
 }
 
+// The total count is not computed here, as it requires scanning all the
+// results, see CountSearchProcesses.
 func (q *Queries) SearchProcesses(ctx context.Context, arg SearchProcessesParams) ([]SearchProcessesRow, error) {
 	rows, err := q.query(ctx, q.searchProcessesStmt, searchProcesses,
 		arg.Offset,
@@ -356,6 +507,10 @@ func (q *Queries) SearchProcesses(ctx context.Context, arg SearchProcessesParams
 		arg.StartDateBefore,
 		arg.EndDateAfter,
 		arg.EndDateBefore,
+		arg.MinTurnout,
+		arg.CursorCreationTime,
+		arg.CursorID,
+		arg.UnlistedEntityID,
 	)
 	if err != nil {
 		return nil, err
@@ -364,7 +519,7 @@ func (q *Queries) SearchProcesses(ctx context.Context, arg SearchProcessesParams
 	var items []SearchProcessesRow
 	for rows.Next() {
 		var i SearchProcessesRow
-		if err := rows.Scan(&i.ID, &i.TotalCount); err != nil {
+		if err := rows.Scan(&i.ID, &i.CreationTime); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -530,3 +685,20 @@ func (q *Queries) UpdateProcessResults(ctx context.Context, arg UpdateProcessRes
 		arg.ID,
 	)
 }
+
+const updateProcessTurnout = `-- name: UpdateProcessTurnout :execresult
+UPDATE processes
+SET turnout = ?1,
+	weight_turnout = ?2
+WHERE id = ?3
+`
+
+type UpdateProcessTurnoutParams struct {
+	Turnout       float64
+	WeightTurnout float64
+	ID            types.ProcessID
+}
+
+func (q *Queries) UpdateProcessTurnout(ctx context.Context, arg UpdateProcessTurnoutParams) (sql.Result, error) {
+	return q.exec(ctx, q.updateProcessTurnoutStmt, updateProcessTurnout, arg.Turnout, arg.WeightTurnout, arg.ID)
+}
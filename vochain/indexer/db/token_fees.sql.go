@@ -114,3 +114,15 @@ func (q *Queries) SearchTokenFees(ctx context.Context, arg SearchTokenFeesParams
 	}
 	return items, nil
 }
+
+const sumTokenFeesByHeight = `-- name: SumTokenFeesByHeight :one
+SELECT CAST(COALESCE(SUM(cost), 0) AS INTEGER) AS fees FROM token_fees
+WHERE block_height = ?
+`
+
+func (q *Queries) SumTokenFeesByHeight(ctx context.Context, blockHeight int64) (int64, error) {
+	row := q.queryRow(ctx, q.sumTokenFeesByHeightStmt, sumTokenFeesByHeight, blockHeight)
+	var fees int64
+	err := row.Scan(&fees)
+	return fees, err
+}
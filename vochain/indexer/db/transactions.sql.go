@@ -12,6 +12,41 @@ import (
 	"go.vocdoni.io/dvote/types"
 )
 
+const countSearchTransactions = `-- name: CountSearchTransactions :one
+SELECT COUNT(*) FROM transactions
+WHERE
+  (?1 = 0 OR block_height = ?1)
+  AND (?2 = '' OR LOWER(type) = LOWER(?2))
+  AND (?3 = '' OR LOWER(subtype) = LOWER(?3))
+  AND (?4 = '' OR LOWER(HEX(signer)) = LOWER(?4))
+  AND (
+    ?5 = ''
+    OR (LENGTH(?5) = 64 AND LOWER(HEX(hash)) = LOWER(?5))
+    OR (LENGTH(?5) < 64 AND INSTR(LOWER(HEX(hash)), LOWER(?5)) > 0)
+  )
+`
+
+type CountSearchTransactionsParams struct {
+	BlockHeight interface{}
+	TxType      interface{}
+	TxSubtype   interface{}
+	TxSigner    interface{}
+	HashSubstr  interface{}
+}
+
+func (q *Queries) CountSearchTransactions(ctx context.Context, arg CountSearchTransactionsParams) (int64, error) {
+	row := q.queryRow(ctx, q.countSearchTransactionsStmt, countSearchTransactions,
+		arg.BlockHeight,
+		arg.TxType,
+		arg.TxSubtype,
+		arg.TxSigner,
+		arg.HashSubstr,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countTransactions = `-- name: CountTransactions :one
 SELECT COUNT(*) FROM transactions
 `
@@ -35,6 +70,59 @@ func (q *Queries) CountTransactionsByHeight(ctx context.Context, blockHeight int
 	return count, err
 }
 
+const countTransactionsBySigner = `-- name: CountTransactionsBySigner :one
+SELECT COUNT(*) FROM transactions
+WHERE
+  signer = ?1
+  AND (?2 = '' OR LOWER(type) = LOWER(?2))
+`
+
+type CountTransactionsBySignerParams struct {
+	Signer []byte
+	TxType interface{}
+}
+
+func (q *Queries) CountTransactionsBySigner(ctx context.Context, arg CountTransactionsBySignerParams) (int64, error) {
+	row := q.queryRow(ctx, q.countTransactionsBySignerStmt, countTransactionsBySigner, arg.Signer, arg.TxType)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTransactionsByTypeAndHeight = `-- name: CountTransactionsByTypeAndHeight :many
+SELECT type, COUNT(*) AS count FROM transactions
+WHERE block_height = ?
+GROUP BY type
+`
+
+type CountTransactionsByTypeAndHeightRow struct {
+	Type  string
+	Count int64
+}
+
+func (q *Queries) CountTransactionsByTypeAndHeight(ctx context.Context, blockHeight int64) ([]CountTransactionsByTypeAndHeightRow, error) {
+	rows, err := q.query(ctx, q.countTransactionsByTypeAndHeightStmt, countTransactionsByTypeAndHeight, blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountTransactionsByTypeAndHeightRow
+	for rows.Next() {
+		var i CountTransactionsByTypeAndHeightRow
+		if err := rows.Scan(&i.Type, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createTransaction = `-- name: CreateTransaction :execresult
 INSERT INTO transactions (
 	hash, block_height, block_index, type, subtype, raw_tx, signature, signer
@@ -124,64 +212,141 @@ func (q *Queries) GetTransactionByHeightAndIndex(ctx context.Context, arg GetTra
 	return i, err
 }
 
+const getTransactionsMaxRowID = `-- name: GetTransactionsMaxRowID :one
+SELECT CAST(COALESCE(MAX(rowid), 0) AS INTEGER) FROM transactions
+`
+
+// The transactions are never deleted and keep their rowid when updated, so the
+// max rowid is the number of transactions.
+func (q *Queries) GetTransactionsMaxRowID(ctx context.Context) (int64, error) {
+	row := q.queryRow(ctx, q.getTransactionsMaxRowIDStmt, getTransactionsMaxRowID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const searchTransactions = `-- name: SearchTransactions :many
-SELECT hash, block_height, block_index, type, subtype, raw_tx, signature, signer, COUNT(*) OVER() AS total_count
+SELECT hash, block_height, block_index, type, subtype, raw_tx, signature, signer
 FROM transactions
 WHERE
-  (?1 = 0 OR block_height = ?1)
-  AND (?2 = '' OR LOWER(type) = LOWER(?2))
-  AND (?3 = '' OR LOWER(subtype) = LOWER(?3))
-  AND (?4 = '' OR LOWER(HEX(signer)) = LOWER(?4))
+  (?3 = 0 OR block_height = ?3)
+  AND (?4 = '' OR LOWER(type) = LOWER(?4))
+  AND (?5 = '' OR LOWER(subtype) = LOWER(?5))
+  AND (?6 = '' OR LOWER(HEX(signer)) = LOWER(?6))
   AND (
-    ?5 = ''
-    OR (LENGTH(?5) = 64 AND LOWER(HEX(hash)) = LOWER(?5))
-    OR (LENGTH(?5) < 64 AND INSTR(LOWER(HEX(hash)), LOWER(?5)) > 0)
-    -- TODO: consider keeping an hash_hex column for faster searches
+    ?7 = ''
+    OR (LENGTH(?7) = 64 AND LOWER(HEX(hash)) = LOWER(?7))
+    OR (LENGTH(?7) < 64 AND INSTR(LOWER(HEX(hash)), LOWER(?7)) > 0)
+  )
+  AND (
+    ?8 IS NULL
+    OR block_height < ?8
+    OR (block_height = ?8 AND block_index < ?9)
   )
 ORDER BY block_height DESC, block_index DESC
-LIMIT ?7
-OFFSET ?6
+LIMIT ?2
+OFFSET ?1
 `
 
 type SearchTransactionsParams struct {
-	BlockHeight interface{}
-	TxType      interface{}
-	TxSubtype   interface{}
-	TxSigner    interface{}
-	HashSubstr  interface{}
-	Offset      int64
-	Limit       int64
-}
-
-type SearchTransactionsRow struct {
-	Hash        types.Hash
-	BlockHeight int64
-	BlockIndex  int64
-	Type        string
-	Subtype     string
-	RawTx       []byte
-	Signature   []byte
-	Signer      []byte
-	TotalCount  int64
+	Offset            int64
+	Limit             int64
+	BlockHeight       interface{}
+	TxType            interface{}
+	TxSubtype         interface{}
+	TxSigner          interface{}
+	HashSubstr        interface{}
+	CursorBlockHeight interface{}
+	CursorBlockIndex  interface{}
 }
 
-func (q *Queries) SearchTransactions(ctx context.Context, arg SearchTransactionsParams) ([]SearchTransactionsRow, error) {
+// The total count is not computed here, as it requires scanning all the
+// results, see CountSearchTransactions.
+func (q *Queries) SearchTransactions(ctx context.Context, arg SearchTransactionsParams) ([]Transaction, error) {
 	rows, err := q.query(ctx, q.searchTransactionsStmt, searchTransactions,
+		arg.Offset,
+		arg.Limit,
 		arg.BlockHeight,
 		arg.TxType,
 		arg.TxSubtype,
 		arg.TxSigner,
 		arg.HashSubstr,
+		arg.CursorBlockHeight,
+		arg.CursorBlockIndex,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.Hash,
+			&i.BlockHeight,
+			&i.BlockIndex,
+			&i.Type,
+			&i.Subtype,
+			&i.RawTx,
+			&i.Signature,
+			&i.Signer,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransactionsBySigner = `-- name: SearchTransactionsBySigner :many
+SELECT hash, block_height, block_index, type, subtype, raw_tx, signature, signer
+FROM transactions
+WHERE
+  signer = ?3
+  AND (?4 = '' OR LOWER(type) = LOWER(?4))
+  AND (
+    ?5 IS NULL
+    OR block_height < ?5
+    OR (block_height = ?5 AND block_index < ?6)
+  )
+ORDER BY block_height DESC, block_index DESC
+LIMIT ?2
+OFFSET ?1
+`
+
+type SearchTransactionsBySignerParams struct {
+	Offset            int64
+	Limit             int64
+	Signer            []byte
+	TxType            interface{}
+	CursorBlockHeight interface{}
+	CursorBlockIndex  interface{}
+}
+
+// Like SearchTransactions filtering by signer, but comparing the signer as is,
+// so that index_transactions_signer_height is used.
+func (q *Queries) SearchTransactionsBySigner(ctx context.Context, arg SearchTransactionsBySignerParams) ([]Transaction, error) {
+	rows, err := q.query(ctx, q.searchTransactionsBySignerStmt, searchTransactionsBySigner,
 		arg.Offset,
 		arg.Limit,
+		arg.Signer,
+		arg.TxType,
+		arg.CursorBlockHeight,
+		arg.CursorBlockIndex,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []SearchTransactionsRow
+	var items []Transaction
 	for rows.Next() {
-		var i SearchTransactionsRow
+		var i Transaction
 		if err := rows.Scan(
 			&i.Hash,
 			&i.BlockHeight,
@@ -191,7 +356,6 @@ func (q *Queries) SearchTransactions(ctx context.Context, arg SearchTransactions
 			&i.RawTx,
 			&i.Signature,
 			&i.Signer,
-			&i.TotalCount,
 		); err != nil {
 			return nil, err
 		}
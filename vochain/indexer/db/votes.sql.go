@@ -13,6 +13,45 @@ import (
 	"go.vocdoni.io/dvote/vochain/state"
 )
 
+const countSearchVotes = `-- name: CountSearchVotes :one
+SELECT COUNT(*) FROM votes
+WHERE (
+	LENGTH(?1) <= 64 -- if passed arg is longer, then just abort the query
+	AND (
+		?1 = ''
+		OR (LENGTH(?1) = 64 AND LOWER(HEX(process_id)) = LOWER(?1))
+		OR (LENGTH(?1) < 64 AND INSTR(LOWER(HEX(process_id)), LOWER(?1)) > 0)
+	)
+	AND LENGTH(?2) <= 64 -- if passed arg is longer, then just abort the query
+	AND (
+		?2 = ''
+		OR (LENGTH(?2) = 64 AND LOWER(HEX(nullifier)) = LOWER(?2))
+		OR (LENGTH(?2) < 64 AND INSTR(LOWER(HEX(nullifier)), LOWER(?2)) > 0)
+	)
+	AND (?3 IS NULL OR block_time >= ?3)
+	AND (?4 IS NULL OR block_time < ?4)
+)
+`
+
+type CountSearchVotesParams struct {
+	ProcessIDSubstr interface{}
+	NullifierSubstr interface{}
+	FromTime        interface{}
+	ToTime          interface{}
+}
+
+func (q *Queries) CountSearchVotes(ctx context.Context, arg CountSearchVotesParams) (int64, error) {
+	row := q.queryRow(ctx, q.countSearchVotesStmt, countSearchVotes,
+		arg.ProcessIDSubstr,
+		arg.NullifierSubstr,
+		arg.FromTime,
+		arg.ToTime,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countVotes = `-- name: CountVotes :one
 SELECT COUNT(*) FROM votes
 `
@@ -24,15 +63,27 @@ func (q *Queries) CountVotes(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const countVotesByHeight = `-- name: CountVotesByHeight :one
+SELECT COUNT(*) FROM votes
+WHERE block_height = ?
+`
+
+func (q *Queries) CountVotesByHeight(ctx context.Context, blockHeight int64) (int64, error) {
+	row := q.queryRow(ctx, q.countVotesByHeightStmt, countVotesByHeight, blockHeight)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createVote = `-- name: CreateVote :execresult
 REPLACE INTO votes (
 	nullifier, process_id, block_height, block_index,
 	weight, voter_id, overwrite_count,
-	encryption_key_indexes, package
+	encryption_key_indexes, package, block_time
 ) VALUES (
 	?, ?, ?, ?,
 	?, ?, ?,
-	?, ?
+	?, ?, ?
 )
 `
 
@@ -46,6 +97,7 @@ type CreateVoteParams struct {
 	OverwriteCount       int64
 	EncryptionKeyIndexes string
 	Package              string
+	BlockTime            sql.NullTime
 }
 
 func (q *Queries) CreateVote(ctx context.Context, arg CreateVoteParams) (sql.Result, error) {
@@ -59,16 +111,178 @@ func (q *Queries) CreateVote(ctx context.Context, arg CreateVoteParams) (sql.Res
 		arg.OverwriteCount,
 		arg.EncryptionKeyIndexes,
 		arg.Package,
+		arg.BlockTime,
+	)
+}
+
+const getProcessEnvelopes = `-- name: GetProcessEnvelopes :many
+SELECT v.nullifier, v.process_id, v.block_height, v.block_index, v.weight, v.voter_id, v.overwrite_count, v.encryption_key_indexes, v.package, v.decrypted_package, v.block_time, t.hash AS tx_hash FROM votes AS v
+LEFT JOIN transactions AS t
+	ON v.block_height = t.block_height
+	AND v.block_index = t.block_index
+WHERE v.process_id = ?1 AND v.nullifier > ?2
+ORDER BY v.nullifier ASC
+LIMIT ?3
+`
+
+type GetProcessEnvelopesParams struct {
+	ProcessID      types.ProcessID
+	AfterNullifier types.Nullifier
+	Limit          int64
+}
+
+type GetProcessEnvelopesRow struct {
+	Nullifier            types.Nullifier
+	ProcessID            types.ProcessID
+	BlockHeight          int64
+	BlockIndex           int64
+	Weight               string
+	VoterID              state.VoterID
+	OverwriteCount       int64
+	EncryptionKeyIndexes string
+	Package              string
+	DecryptedPackage     string
+	BlockTime            sql.NullTime
+	TxHash               types.Hash
+}
+
+// Like GetProcessVotePackages, but with all the details of the votes.
+func (q *Queries) GetProcessEnvelopes(ctx context.Context, arg GetProcessEnvelopesParams) ([]GetProcessEnvelopesRow, error) {
+	rows, err := q.query(ctx, q.getProcessEnvelopesStmt, getProcessEnvelopes, arg.ProcessID, arg.AfterNullifier, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetProcessEnvelopesRow
+	for rows.Next() {
+		var i GetProcessEnvelopesRow
+		if err := rows.Scan(
+			&i.Nullifier,
+			&i.ProcessID,
+			&i.BlockHeight,
+			&i.BlockIndex,
+			&i.Weight,
+			&i.VoterID,
+			&i.OverwriteCount,
+			&i.EncryptionKeyIndexes,
+			&i.Package,
+			&i.DecryptedPackage,
+			&i.BlockTime,
+			&i.TxHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProcessNullifiers = `-- name: GetProcessNullifiers :many
+SELECT nullifier, block_height FROM votes
+WHERE process_id = ? AND block_height > ?
+`
+
+type GetProcessNullifiersParams struct {
+	ProcessID   types.ProcessID
+	BlockHeight int64
+}
+
+type GetProcessNullifiersRow struct {
+	Nullifier   types.Nullifier
+	BlockHeight int64
+}
+
+func (q *Queries) GetProcessNullifiers(ctx context.Context, arg GetProcessNullifiersParams) ([]GetProcessNullifiersRow, error) {
+	rows, err := q.query(ctx, q.getProcessNullifiersStmt, getProcessNullifiers,
+		arg.ProcessID,
+		arg.BlockHeight,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetProcessNullifiersRow
+	for rows.Next() {
+		var i GetProcessNullifiersRow
+		if err := rows.Scan(&i.Nullifier, &i.BlockHeight); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProcessVotePackages = `-- name: GetProcessVotePackages :many
+SELECT nullifier, weight, encryption_key_indexes, package FROM votes
+WHERE process_id = ?1 AND nullifier > ?2
+ORDER BY nullifier ASC
+LIMIT ?3
+`
+
+type GetProcessVotePackagesParams struct {
+	ProcessID      types.ProcessID
+	AfterNullifier types.Nullifier
+	Limit          int64
+}
+
+type GetProcessVotePackagesRow struct {
+	Nullifier            types.Nullifier
+	Weight               string
+	EncryptionKeyIndexes string
+	Package              string
+}
+
+// Returns the votes of a process in batches, ordered by nullifier and starting
+// after the given one.
+func (q *Queries) GetProcessVotePackages(ctx context.Context, arg GetProcessVotePackagesParams) ([]GetProcessVotePackagesRow, error) {
+	rows, err := q.query(ctx, q.getProcessVotePackagesStmt, getProcessVotePackages,
+		arg.ProcessID,
+		arg.AfterNullifier,
+		arg.Limit,
 	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetProcessVotePackagesRow
+	for rows.Next() {
+		var i GetProcessVotePackagesRow
+		if err := rows.Scan(
+			&i.Nullifier,
+			&i.Weight,
+			&i.EncryptionKeyIndexes,
+			&i.Package,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getVote = `-- name: GetVote :one
-SELECT v.nullifier, v.process_id, v.block_height, v.block_index, v.weight, v.voter_id, v.overwrite_count, v.encryption_key_indexes, v.package, t.hash AS tx_hash, b.time AS block_time FROM votes AS v
+SELECT v.nullifier, v.process_id, v.block_height, v.block_index, v.weight, v.voter_id, v.overwrite_count, v.encryption_key_indexes, v.package, v.decrypted_package, v.block_time, t.hash AS tx_hash FROM votes AS v
 LEFT JOIN transactions AS t
 	ON v.block_height = t.block_height
 	AND v.block_index = t.block_index
-LEFT JOIN blocks AS b
-	ON v.block_height = b.height
 WHERE v.nullifier = ?
 LIMIT 1
 `
@@ -83,8 +297,9 @@ type GetVoteRow struct {
 	OverwriteCount       int64
 	EncryptionKeyIndexes string
 	Package              string
-	TxHash               types.Hash
+	DecryptedPackage     string
 	BlockTime            sql.NullTime
+	TxHash               types.Hash
 }
 
 func (q *Queries) GetVote(ctx context.Context, nullifier types.Nullifier) (GetVoteRow, error) {
@@ -100,48 +315,67 @@ func (q *Queries) GetVote(ctx context.Context, nullifier types.Nullifier) (GetVo
 		&i.OverwriteCount,
 		&i.EncryptionKeyIndexes,
 		&i.Package,
-		&i.TxHash,
+		&i.DecryptedPackage,
 		&i.BlockTime,
+		&i.TxHash,
 	)
 	return i, err
 }
 
+const getVotesMaxRowID = `-- name: GetVotesMaxRowID :one
+SELECT CAST(COALESCE(MAX(rowid), 0) AS INTEGER) FROM votes
+`
+
+// The votes are never deleted, but an overwritten vote gets a new rowid, so the
+// max rowid is an upper bound of the number of votes.
+func (q *Queries) GetVotesMaxRowID(ctx context.Context) (int64, error) {
+	row := q.queryRow(ctx, q.getVotesMaxRowIDStmt, getVotesMaxRowID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const searchVotes = `-- name: SearchVotes :many
-WITH results AS (
-	SELECT v.nullifier, v.process_id, v.block_height, v.block_index, v.weight, v.voter_id, v.overwrite_count, v.encryption_key_indexes, v.package, t.hash
-	FROM votes AS v
-	LEFT JOIN transactions AS t
-		ON v.block_height = t.block_height
-		AND v.block_index = t.block_index
-	WHERE (
-		LENGTH(?3) <= 64 -- if passed arg is longer, then just abort the query
-		AND (
-			?3 = ''
-			OR (LENGTH(?3) = 64 AND LOWER(HEX(process_id)) = LOWER(?3))
-			OR (LENGTH(?3) < 64 AND INSTR(LOWER(HEX(process_id)), LOWER(?3)) > 0)
-			-- TODO: consider keeping an process_id_hex column for faster searches
-		)
-		AND LENGTH(?4) <= 64 -- if passed arg is longer, then just abort the query
-		AND (
-			?4 = ''
-			OR (LENGTH(?4) = 64 AND LOWER(HEX(nullifier)) = LOWER(?4))
-			OR (LENGTH(?4) < 64 AND INSTR(LOWER(HEX(nullifier)), LOWER(?4)) > 0)
-			-- TODO: consider keeping an nullifier_hex column for faster searches
-		)
+SELECT v.nullifier, v.process_id, v.block_height, v.block_index, v.weight, v.voter_id, v.overwrite_count, v.encryption_key_indexes, v.package, v.decrypted_package, v.block_time, t.hash
+FROM votes AS v
+LEFT JOIN transactions AS t
+	ON v.block_height = t.block_height
+	AND v.block_index = t.block_index
+WHERE (
+	LENGTH(?3) <= 64 -- if passed arg is longer, then just abort the query
+	AND (
+		?3 = ''
+		OR (LENGTH(?3) = 64 AND LOWER(HEX(v.process_id)) = LOWER(?3))
+		OR (LENGTH(?3) < 64 AND INSTR(LOWER(HEX(v.process_id)), LOWER(?3)) > 0)
+	)
+	AND LENGTH(?4) <= 64 -- if passed arg is longer, then just abort the query
+	AND (
+		?4 = ''
+		OR (LENGTH(?4) = 64 AND LOWER(HEX(v.nullifier)) = LOWER(?4))
+		OR (LENGTH(?4) < 64 AND INSTR(LOWER(HEX(v.nullifier)), LOWER(?4)) > 0)
 	)
+	AND (?7 IS NULL OR v.block_time >= ?7)
+	AND (?8 IS NULL OR v.block_time < ?8)
+)
+AND (
+	?5 IS NULL
+	OR v.block_height < ?5
+	OR (v.block_height = ?5 AND v.nullifier > ?6)
 )
-SELECT nullifier, process_id, block_height, block_index, weight, voter_id, overwrite_count, encryption_key_indexes, package, hash, COUNT(*) OVER() AS total_count
-FROM results
-ORDER BY block_height DESC, nullifier ASC
+ORDER BY v.block_height DESC, v.nullifier ASC
 LIMIT ?2
 OFFSET ?1
 `
 
 type SearchVotesParams struct {
-	Offset          int64
-	Limit           int64
-	ProcessIDSubstr interface{}
-	NullifierSubstr interface{}
+	Offset            int64
+	Limit             int64
+	ProcessIDSubstr   interface{}
+	NullifierSubstr   interface{}
+	CursorBlockHeight interface{}
+	CursorNullifier   interface{}
+	FromTime          interface{}
+	ToTime            interface{}
 }
 
 type SearchVotesRow struct {
@@ -154,16 +388,23 @@ type SearchVotesRow struct {
 	OverwriteCount       int64
 	EncryptionKeyIndexes string
 	Package              string
+	DecryptedPackage     string
+	BlockTime            sql.NullTime
 	Hash                 []byte
-	TotalCount           int64
 }
 
+// The total count is not computed here, as it requires scanning all the
+// results, see CountSearchVotes.
 func (q *Queries) SearchVotes(ctx context.Context, arg SearchVotesParams) ([]SearchVotesRow, error) {
 	rows, err := q.query(ctx, q.searchVotesStmt, searchVotes,
 		arg.Offset,
 		arg.Limit,
 		arg.ProcessIDSubstr,
 		arg.NullifierSubstr,
+		arg.CursorBlockHeight,
+		arg.CursorNullifier,
+		arg.FromTime,
+		arg.ToTime,
 	)
 	if err != nil {
 		return nil, err
@@ -182,8 +423,9 @@ func (q *Queries) SearchVotes(ctx context.Context, arg SearchVotesParams) ([]Sea
 			&i.OverwriteCount,
 			&i.EncryptionKeyIndexes,
 			&i.Package,
+			&i.DecryptedPackage,
+			&i.BlockTime,
 			&i.Hash,
-			&i.TotalCount,
 		); err != nil {
 			return nil, err
 		}
@@ -197,3 +439,35 @@ func (q *Queries) SearchVotes(ctx context.Context, arg SearchVotesParams) ([]Sea
 	}
 	return items, nil
 }
+
+const setVoteDecryptedPackage = `-- name: SetVoteDecryptedPackage :execresult
+UPDATE votes
+SET decrypted_package = ?1
+WHERE nullifier = ?2
+`
+
+type SetVoteDecryptedPackageParams struct {
+	DecryptedPackage string
+	Nullifier        types.Nullifier
+}
+
+func (q *Queries) SetVoteDecryptedPackage(ctx context.Context, arg SetVoteDecryptedPackageParams) (sql.Result, error) {
+	return q.exec(ctx, q.setVoteDecryptedPackageStmt, setVoteDecryptedPackage, arg.DecryptedPackage, arg.Nullifier)
+}
+
+const setVotesBlockTime = `-- name: SetVotesBlockTime :execresult
+UPDATE votes
+SET block_time = ?1
+WHERE block_height = ?2
+`
+
+type SetVotesBlockTimeParams struct {
+	BlockTime   sql.NullTime
+	BlockHeight int64
+}
+
+// Sets the time of the votes of the block once it is indexed, since the time
+// the votes are indexed with is the one of the application.
+func (q *Queries) SetVotesBlockTime(ctx context.Context, arg SetVotesBlockTimeParams) (sql.Result, error) {
+	return q.exec(ctx, q.setVotesBlockTimeStmt, setVotesBlockTime, arg.BlockTime, arg.BlockHeight)
+}
@@ -0,0 +1,166 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/results"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrKeysNotRevealed is returned when decrypting the votes of a process whose
+// encryption keys are not all revealed yet.
+var ErrKeysNotRevealed = fmt.Errorf("process encryption keys are not revealed")
+
+// decryptBatchSize is the number of votes decrypted by each database
+// transaction of DecryptVotes, so that the block commits are not delayed by
+// large processes.
+const decryptBatchSize = 1000
+
+// DecryptVotes decrypts the indexed votes of the encrypted process pid with its
+// encryption private keys, revealed once the process ends, and stores their
+// plaintext packages along with the encrypted ones, see
+// indexertypes.EnvelopePackage. The results of the process are then recomputed
+// from the decrypted votes, unless its final results are already stored, and
+// returned. The votes which cannot be decrypted, or whose values are not valid
+// for the process, are not counted.
+//
+// It is called for each encrypted process once all its keys are revealed.
+func (idx *Indexer) DecryptVotes(pid []byte) (*results.Results, error) {
+	if idx.readReplicaOf != nil {
+		return nil, ErrReadReplica
+	}
+	ctx := context.TODO()
+	process, err := idx.App.State.Process(pid, true)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get process %x: %w", pid, err)
+	}
+	if !process.EnvelopeType.GetEncryptedVotes() {
+		return nil, fmt.Errorf("the votes of process %x are not encrypted", pid)
+	}
+	if process.KeyIndex == nil || *process.KeyIndex > 0 {
+		return nil, fmt.Errorf("%w: %x", ErrKeysNotRevealed, pid)
+	}
+	voteOpts := proto.Clone(process.VoteOptions).(*models.ProcessVoteOptions)
+	if voteOpts.MaxCount == 0 {
+		voteOpts.MaxCount = results.MaxQuestions
+	}
+	if voteOpts.MaxCount > results.MaxQuestions {
+		return nil, fmt.Errorf("maxCount overflow %d", voteOpts.MaxCount)
+	}
+	r := &results.Results{
+		Votes:        results.NewEmptyVotes(voteOpts),
+		ProcessID:    pid,
+		Weight:       new(types.BigInt).SetUint64(0),
+		VoteOpts:     voteOpts,
+		EnvelopeType: process.EnvelopeType,
+		BlockHeight:  idx.App.Height(),
+	}
+
+	var total, invalid int
+	after := types.Nullifier(zeroBytes)
+	for {
+		rows, err := idx.readOnlyQuery.GetProcessVotePackages(ctx, indexerdb.GetProcessVotePackagesParams{
+			ProcessID:      pid,
+			AfterNullifier: after,
+			Limit:          decryptBatchSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot get the votes of process %x: %w", pid, err)
+		}
+		n, err := idx.storeDecryptedVotes(ctx, process, rows, r)
+		if err != nil {
+			return nil, err
+		}
+		total += len(rows)
+		invalid += n
+		if len(rows) < decryptBatchSize {
+			break
+		}
+		after = rows[len(rows)-1].Nullifier
+	}
+
+	if _, err := indexerdb.New(idx.readWriteDB).UpdateProcessResults(ctx, indexerdb.UpdateProcessResultsParams{
+		ID:          pid,
+		Votes:       indexertypes.EncodeJSON(r.Votes),
+		Weight:      indexertypes.EncodeJSON(r.Weight),
+		BlockHeight: int64(r.BlockHeight),
+	}); err != nil {
+		return nil, fmt.Errorf("cannot update the results of process %x: %w", pid, err)
+	}
+	idx.versions.bump(DataVotes)
+	log.Infow("decrypted process votes", "processID", fmt.Sprintf("%x", pid), "votes", total,
+		"invalid", invalid, "results", r.String())
+	return r, nil
+}
+
+// storeDecryptedVotes decrypts the votes of the process, stores their plaintext
+// packages and adds the valid ones to r. It returns the number of votes which
+// are not counted.
+func (idx *Indexer) storeDecryptedVotes(ctx context.Context, process *models.Process,
+	rows []indexerdb.GetProcessVotePackagesRow, r *results.Results,
+) (int, error) {
+	tx, err := idx.readWriteDB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	queries := indexerdb.New(tx)
+	invalid := 0
+	for _, row := range rows {
+		var keys []string
+		for _, i := range indexertypes.DecodeJSON[[]uint32](row.EncryptionKeyIndexes) {
+			if i >= uint32(len(process.EncryptionPrivateKeys)) {
+				keys = nil
+				break
+			}
+			keys = append(keys, process.EncryptionPrivateKeys[i])
+		}
+		if len(keys) == 0 {
+			log.Debugw("vote without valid encryption keys", "nullifier", fmt.Sprintf("%x", row.Nullifier))
+			invalid++
+			continue
+		}
+		votePackage, err := decryptVote([]byte(row.Package), keys)
+		if err != nil {
+			log.Debugw("cannot decrypt vote", "nullifier", fmt.Sprintf("%x", row.Nullifier), "err", err)
+			invalid++
+			continue
+		}
+		if _, err := queries.SetVoteDecryptedPackage(ctx, indexerdb.SetVoteDecryptedPackageParams{
+			DecryptedPackage: string(votePackage),
+			Nullifier:        row.Nullifier,
+		}); err != nil {
+			return 0, fmt.Errorf("cannot store decrypted vote: %w", err)
+		}
+		var vote state.VotePackage
+		weight, ok := new(big.Int).SetString(indexertypes.DecodeJSON[string](row.Weight), 10)
+		if err := vote.Decode(votePackage); err != nil || !ok {
+			invalid++
+			continue
+		}
+		if err := r.AddVote(vote.Votes, weight, nil); err != nil {
+			log.Debugw("decrypted vote not counted", "nullifier", fmt.Sprintf("%x", row.Nullifier), "err", err)
+			invalid++
+		}
+	}
+	return invalid, tx.Commit()
+}
+
+// decryptProcessesVotes decrypts the votes of the given processes, logging the
+// errors. It is run in the background, like auditProcesses.
+func (idx *Indexer) decryptProcessesVotes(pids []types.ProcessID) {
+	defer idx.decrypting.Done()
+	for _, pid := range pids {
+		if _, err := idx.DecryptVotes(pid); err != nil {
+			log.Errorw(err, "cannot decrypt process votes")
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ErrEncryptionUnsupported is returned when an encryption key is used but the
+// sqlite library the node is linked against is not SQLCipher.
+//
+// To link against SQLCipher, build with the libsqlite3 tag and point cgo to the
+// SQLCipher library, for instance:
+//
+//	CGO_CFLAGS="-DSQLITE_HAS_CODEC -I/usr/include/sqlcipher" CGO_LDFLAGS="-lsqlcipher" go build -tags libsqlite3
+var ErrEncryptionUnsupported = errors.New("indexer database encryption requires sqlite built with SQLCipher")
+
+// sqliteConnector opens connections to a sqlite database with its own driver,
+// so that each database can set its own encryption key on connect.
+type sqliteConnector struct {
+	dsn    string
+	driver *sqlite3.SQLiteDriver
+}
+
+func (c *sqliteConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+
+func (c *sqliteConnector) Driver() driver.Driver { return c.driver }
+
+// openDB opens the sqlite database with the dsn, and runs the pragmas on each new
+// connection. If key is not empty, the database is encrypted with SQLCipher.
+// Since SQLCipher requires the key to be set before the database is read, the
+// pragmas are run after it instead of being part of the dsn.
+func openDB(dsn, key string, pragmas ...string) *sql.DB {
+	return openDBWithHook(dsn, key, nil, pragmas...)
+}
+
+// openDBWithHook is like openDB, but it also runs hook on each new connection,
+// once the key and the pragmas are set.
+func openDBWithHook(dsn, key string, hook func(*sqlite3.SQLiteConn) error, pragmas ...string) *sql.DB {
+	return sql.OpenDB(&sqliteConnector{
+		dsn: dsn,
+		driver: &sqlite3.SQLiteDriver{ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			if key != "" {
+				if err := checkSQLCipher(conn); err != nil {
+					return err
+				}
+				if _, err := conn.Exec(fmt.Sprintf("PRAGMA key = %s", quoteSQL(key)), nil); err != nil {
+					return fmt.Errorf("cannot set the indexer database key: %w", err)
+				}
+				// the key is only checked once the database is read
+				if _, err := conn.Exec("SELECT count(*) FROM sqlite_master", nil); err != nil {
+					return fmt.Errorf("cannot decrypt the indexer database, invalid key?: %w", err)
+				}
+			}
+			for _, pragma := range pragmas {
+				if _, err := conn.Exec("PRAGMA "+pragma, nil); err != nil {
+					return err
+				}
+			}
+			if hook != nil {
+				return hook(conn)
+			}
+			return nil
+		}},
+	})
+}
+
+// checkSQLCipher returns ErrEncryptionUnsupported if the sqlite library is not SQLCipher.
+func checkSQLCipher(conn *sqlite3.SQLiteConn) error {
+	rows, err := conn.Query("PRAGMA cipher_version", nil)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	// the pragma is ignored, returning no rows, by other sqlite builds
+	if err := rows.Next(make([]driver.Value, len(rows.Columns()))); errors.Is(err, io.EOF) {
+		return ErrEncryptionUnsupported
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// quoteSQL returns s as a SQL string literal.
+func quoteSQL(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// SaveEncryptedBackup backs up the database to a file on disk, encrypted with
+// key, or in plain text if key is empty. It requires SQLCipher, see
+// ErrEncryptionUnsupported, even if the database itself is not encrypted.
+// As with SaveBackup, writes to the database are blocked until the backup finishes.
+func (idx *Indexer) SaveEncryptedBackup(ctx context.Context, path, key string) error {
+	// the read-only connections cannot attach a writable database
+	conn, err := idx.readWriteDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.Raw(func(c any) error { return checkSQLCipher(c.(*sqlite3.SQLiteConn)) }); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS backup KEY ?`, path, key); err != nil {
+		return fmt.Errorf("cannot create the backup database: %w", err)
+	}
+	_, err = conn.ExecContext(ctx, `SELECT sqlcipher_export('backup')`)
+	if _, err2 := conn.ExecContext(context.Background(), `DETACH DATABASE backup`); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// RotateEncryptionKey re-encrypts the database with newKey, or decrypts it if
+// newKey is empty, and reopens it with the new key. The database is re-encrypted
+// into a backup (see SaveEncryptedBackup) which then replaces it. Note that this
+// must be called before any indexing happens, as RestoreBackup.
+func (idx *Indexer) RotateEncryptionKey(ctx context.Context, newKey string) error {
+	if idx.readWriteDB == nil {
+		return fmt.Errorf("indexer database is not initialized")
+	}
+	if idx.readReplicaOf != nil {
+		return ErrReadReplica
+	}
+	if err := idx.checkNoChains(); err != nil {
+		return err
+	}
+	tmpPath := idx.dbPath + ".rekey"
+	if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := idx.SaveEncryptedBackup(ctx, tmpPath, newKey); err != nil {
+		return fmt.Errorf("cannot re-encrypt the indexer database: %w", err)
+	}
+	return idx.replaceDB(tmpPath, newKey)
+}
+
+// replaceDB replaces the database with the one at path, encrypted with key,
+// and starts it again along with its background workers.
+func (idx *Indexer) replaceDB(path, key string) error {
+	if err := idx.closeDB(); err != nil {
+		return err
+	}
+	// the WAL files belong to the old database, and cannot be applied to the new one
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(idx.dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(path, idx.dbPath); err != nil {
+		return fmt.Errorf("cannot replace the indexer database: %w", err)
+	}
+	idx.readWriteDB, idx.readOnlyDB, idx.queryDB = nil, nil, nil
+	idx.encryptionKey = key
+	return idx.startDB()
+}
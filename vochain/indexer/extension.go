@@ -0,0 +1,165 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mattn/go-sqlite3"
+	"go.vocdoni.io/dvote/log"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+)
+
+// ExtensionTablePrefix is the prefix of the tables, views, indexes and
+// triggers of the extensions, followed by the name of the extension and an
+// underscore, such as "ext_kpis_daily" for the table "daily" of the extension
+// "kpis". The extensions cannot write to any other table.
+const ExtensionTablePrefix = "ext_"
+
+// extensionNameRegexp is the format of the names of the extensions, which are
+// part of the name of their tables.
+var extensionNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// Extension is a plugin of the indexer which keeps its own tables and views,
+// such as an organization-specific KPI table, up to date with the indexed data.
+//
+// The extensions are isolated from the core tables: the statements they run
+// can read any table, but they can only create, write and drop the objects
+// named with their prefix (see ExtensionTablePrefix), and they cannot run
+// PRAGMA, ATTACH or transaction statements. Each extension runs in its own
+// savepoint of the block transaction, so if it fails, its changes are rolled
+// back, and the block is indexed anyway.
+type Extension interface {
+	// Name is the name of the extension, which must be unique, lowercase
+	// and alphanumeric.
+	Name() string
+	// Schema returns the statements creating the tables and views of the
+	// extension, run each time the database is opened, once it is migrated.
+	// So they must be idempotent, e.g. CREATE TABLE IF NOT EXISTS.
+	Schema() []string
+	// OnCommit is called on each Commit, once the block is indexed and
+	// before it is committed, with the transaction of the block. It should
+	// not block, since the indexing of the next block waits for it.
+	OnCommit(ctx context.Context, tx indexerdb.DBTX, height uint32) error
+}
+
+// checkExtensions checks that the names of the extensions are valid and unique.
+func checkExtensions(extensions []Extension) error {
+	names := make(map[string]bool)
+	for _, ext := range extensions {
+		name := ext.Name()
+		if !extensionNameRegexp.MatchString(name) {
+			return fmt.Errorf("invalid indexer extension name %q", name)
+		}
+		if names[name] {
+			return fmt.Errorf("duplicated indexer extension %q", name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// extensionScope is the name of the extension whose statements are being run
+// on the readWriteDB, or nil if none, see extensionAuthorizer.
+type extensionScope struct {
+	name atomic.Pointer[string]
+}
+
+// authorizer is the sqlite authorizer of the readWriteDB connections, which
+// allows any statement unless an extension is running. Then, it only allows
+// the statements documented in Extension.
+func (s *extensionScope) authorizer(op int, arg1, arg2, _ string) int {
+	name := s.name.Load()
+	if name == nil {
+		return sqlite3.SQLITE_OK
+	}
+	owned := func(object string) bool {
+		return strings.HasPrefix(object, ExtensionTablePrefix+*name+"_")
+	}
+	switch op {
+	case sqlite3.SQLITE_SELECT, sqlite3.SQLITE_READ, sqlite3.SQLITE_FUNCTION, sqliteRecursive:
+		return sqlite3.SQLITE_OK
+	case sqlite3.SQLITE_INSERT, sqlite3.SQLITE_UPDATE, sqlite3.SQLITE_DELETE:
+		// the creation and removal of the objects writes their schema,
+		// which is authorized by the codes below
+		if owned(arg1) || arg1 == "sqlite_master" {
+			return sqlite3.SQLITE_OK
+		}
+	case sqlite3.SQLITE_CREATE_TABLE, sqlite3.SQLITE_CREATE_VIEW,
+		sqlite3.SQLITE_DROP_TABLE, sqlite3.SQLITE_DROP_VIEW:
+		if owned(arg1) {
+			return sqlite3.SQLITE_OK
+		}
+	case sqlite3.SQLITE_CREATE_INDEX, sqlite3.SQLITE_CREATE_TRIGGER,
+		sqlite3.SQLITE_DROP_INDEX, sqlite3.SQLITE_DROP_TRIGGER:
+		// the index or trigger, and the table it belongs to
+		if owned(arg1) && owned(arg2) {
+			return sqlite3.SQLITE_OK
+		}
+	case sqlite3.SQLITE_ALTER_TABLE:
+		if arg1 == "main" && owned(arg2) {
+			return sqlite3.SQLITE_OK
+		}
+	}
+	return sqlite3.SQLITE_DENY
+}
+
+// createExtensionSchemas runs the Schema statements of the extensions.
+func (idx *Indexer) createExtensionSchemas(ctx context.Context) error {
+	for _, ext := range idx.extensions {
+		if err := idx.runExtension(ctx, idx.readWriteDB, ext, func(tx indexerdb.DBTX) error {
+			for _, stmt := range ext.Schema() {
+				if _, err := tx.ExecContext(ctx, stmt); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("cannot create the schema of indexer extension %s: %w", ext.Name(), err)
+		}
+	}
+	return nil
+}
+
+// commitExtensions runs the OnCommit of the extensions on the block
+// transaction. The failures are logged, since they do not affect the rest of
+// the block. Assumes that blockMu is locked.
+func (idx *Indexer) commitExtensions(ctx context.Context, height uint32) {
+	for _, ext := range idx.extensions {
+		if err := idx.runExtension(ctx, idx.blockTx, ext, func(tx indexerdb.DBTX) error {
+			return ext.OnCommit(ctx, tx, height)
+		}); err != nil {
+			log.Warnw("indexer extension failed, its changes are rolled back",
+				"extension", ext.Name(), "height", height, "err", err)
+		}
+	}
+}
+
+// runExtension runs fn in a savepoint of db, with the statements restricted to
+// those allowed to the extension. The savepoint is rolled back if fn fails or
+// panics.
+func (idx *Indexer) runExtension(ctx context.Context, db indexerdb.DBTX, ext Extension,
+	fn func(tx indexerdb.DBTX) error,
+) (err error) {
+	if _, err := db.ExecContext(ctx, "SAVEPOINT extension"); err != nil {
+		return err
+	}
+	name := ext.Name()
+	idx.extensionScope.name.Store(&name)
+	defer func() {
+		idx.extensionScope.name.Store(nil)
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+		if err != nil {
+			if _, err2 := db.ExecContext(ctx, "ROLLBACK TO extension; RELEASE extension"); err2 != nil {
+				err = fmt.Errorf("%w (cannot roll back: %v)", err, err2)
+			}
+			return
+		}
+		_, err = db.ExecContext(ctx, "RELEASE extension")
+	}()
+	return fn(db)
+}
@@ -16,6 +16,7 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.vocdoni.io/dvote/log"
@@ -29,12 +30,14 @@ import (
 	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
 
+	"github.com/VictoriaMetrics/metrics"
+	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/pressly/goose/v3"
 
 	// modernc is a pure-Go version, but its errors have less useful info.
 	// We use mattn while developing and testing, and we can swap them later.
 	// _ "modernc.org/sqlite"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 //go:generate go run github.com/sqlc-dev/sqlc/cmd/sqlc@v1.27.0 generate
@@ -45,13 +48,28 @@ var embedMigrations embed.FS
 const dbFilename = "db.sqlite"
 
 // EventListener is an interface used for executing custom functions during the
-// events of the tally of a process.
+// events of the tally of a process. OnComputeResults is called on Commit, once
+// the final results of a process are indexed, so it should not block.
 type EventListener interface {
 	OnComputeResults(results *results.Results, process *indexertypes.Process, height uint32)
 }
 
+// CensusSizeListener is an optional interface of the event listeners, to be
+// alerted once a process approaches its max census size, that is, when its
+// vote count reaches CensusSizeAlertThreshold percent of it. Once the max
+// census size is reached, the new voters are rejected by the Vochain.
+// OnCensusSizeAlert is called on Commit, at most once per process and census
+// size, so it should not block.
+type CensusSizeListener interface {
+	OnCensusSizeAlert(process *indexertypes.Process, height uint32)
+}
+
+// CensusSizeAlertThreshold is the turnout, as a percentage of the max census
+// size, at which the CensusSizeListener event listeners are alerted.
+const CensusSizeAlertThreshold = 90
+
 // AddEventListener adds a new event listener, to receive method calls on block
-// events as documented in EventListener.
+// events as documented in EventListener, CensusSizeListener and VotePatternListener.
 func (idx *Indexer) AddEventListener(l EventListener) {
 	idx.eventOnResults = append(idx.eventOnResults, l)
 }
@@ -71,6 +89,10 @@ type Indexer struct {
 	dbPath      string
 	readOnlyDB  *sql.DB
 	readWriteDB *sql.DB
+	// queryDB is the read-only database used by QueryReadOnly.
+	queryDB *sql.DB
+	// encryptionKey is the SQLCipher key of the database, see Options.EncryptionKey.
+	encryptionKey string
 
 	readOnlyQuery *indexerdb.Queries
 
@@ -86,6 +108,64 @@ type Indexer struct {
 	// The key is a types.ProcessID as a string, so that it can be used as a map key.
 	blockUpdateProcs          map[string]bool
 	blockUpdateProcVoteCounts map[string]bool
+	// blockResultsProcs is the list of process IDs whose results were set in the current block.
+	blockResultsProcs map[string]bool
+	// blockFinalizedProcs is the list of process IDs whose results were finalized
+	// on the current Commit, notified to the event listeners once committed.
+	// Protected by blockMu.
+	blockFinalizedProcs []types.ProcessID
+	// blockCensusSizeAlerts is the list of process IDs which reached the
+	// CensusSizeAlertThreshold in the current block. Protected by blockMu.
+	blockCensusSizeAlerts []types.ProcessID
+	// pendingResultsProofs is the list of process IDs whose results proof is generated
+	// on the next Commit, once their results are part of the committed state.
+	// Protected by blockMu.
+	pendingResultsProofs []string
+	// blockEndedProcs is the list of process IDs which ended in the current block.
+	// Protected by blockMu.
+	blockEndedProcs []types.ProcessID
+	// pendingAudits is the list of process IDs audited on the next Commit, once
+	// all their votes are part of the committed state. Protected by blockMu.
+	pendingAudits []types.ProcessID
+	// auditing tracks the process audits running in the background.
+	auditing sync.WaitGroup
+	// blockRevealedProcs is the list of encrypted process IDs whose keys were
+	// all revealed in the current block. Protected by blockMu.
+	blockRevealedProcs []types.ProcessID
+	// pendingDecryptions is the list of process IDs whose votes are decrypted
+	// on the next Commit, once their keys are part of the committed state.
+	// Protected by blockMu.
+	pendingDecryptions []types.ProcessID
+	// decrypting tracks the vote decryptions running in the background.
+	decrypting sync.WaitGroup
+	// validatorPowers is the voting power of each indexed validator, keyed by its
+	// address as a string, used to detect the validator set changes on Commit.
+	// It is (re)loaded from the database when nil. Protected by blockMu.
+	validatorPowers map[string]int64
+	// blockNullifiers is the list of nullifiers of the new votes of the current
+	// block, grouped by process ID as a string. Protected by blockMu.
+	blockNullifiers map[string][][]byte
+	// blockStatusChanges is the list of process status and duration changes
+	// made by the transaction being executed, which are indexed along with it
+	// once its hash is known. Protected by blockMu.
+	blockStatusChanges []indexerdb.CreateProcessStatusChangeParams
+	// blockHasTxs is true if transactions were indexed in the current block.
+	// Protected by blockMu.
+	blockHasTxs bool
+	// blockNewAccounts is the number of accounts created in the current block.
+	// Protected by blockMu.
+	blockNewAccounts int64
+	// blockWrites is the number of writes made to blockTx, see
+	// IndexerStatus.PendingBlockWrites.
+	blockWrites atomic.Int64
+	// indexedHeight is the last block height indexed.
+	indexedHeight atomic.Uint32
+	// metrics are the gauges of the indexer status, see Status.
+	metrics *metrics.Set
+	// anomalyCount is the number of anomalies stored in the database, see AuditProcess.
+	anomalyCount atomic.Int64
+	// versions are the versions of the indexed data returned by DataVersion.
+	versions *dataVersions
 
 	// list of live processes (those on which the votes will be computed on arrival)
 	// TODO: we could query the procs table, perhaps memoizing to avoid querying the same over and over again?
@@ -96,25 +176,186 @@ type Indexer struct {
 
 	// ignoreLiveResults if true, partial/live results won't be calculated (only final results)
 	ignoreLiveResults bool
+	// estimateCounts is Options.EstimateCounts.
+	estimateCounts bool
+	// votePatterns analyzes the voting patterns, nil if Options.VotePatterns
+	// is not set.
+	votePatterns *votePatterns
+
+	// censusWeight returns the total weight of a census, see Options.CensusWeight.
+	censusWeight func(censusRoot []byte) (*big.Int, error)
+	// censusWeights caches the total weight of the censuses, keyed by their root
+	// as a string. Protected by blockMu.
+	censusWeights map[string]*big.Int
+
+	// nullifierFilters caches the nullifier filters of the processes, keyed by
+	// process ID as a string. Each filter is replaced rather than modified.
+	nullifierFilters *lru.Cache[string, *indexertypes.NullifierFilter]
+	// nullifierFiltersMu serializes the updates of the cached nullifier filters.
+	nullifierFiltersMu sync.Mutex
+
+	// replicateTo, replicaInterval and replicaBaseInterval are
+	// Options.ReplicateTo, Options.ReplicaInterval and Options.ReplicaBaseInterval.
+	replicateTo         ReplicaStore
+	replicaInterval     uint32
+	replicaBaseInterval uint32
+	// replicating is true while a replica is being taken and shipped to
+	// replicateTo, and shipping tracks the replicas shipped in the background.
+	replicating atomic.Bool
+	shipping    sync.WaitGroup
+	// replicaWAL reads the WAL frames shipped as segments, and
+	// replicaSegments is the number of segments shipped since the base.
+	// They are only accessed while replicating is set.
+	replicaWAL      walCursor
+	replicaSegments uint32
+	// replicaBase is the height of the base replica the segments are shipped
+	// for, or applied to by a read replica, noReplicaBase if a base is
+	// needed. It is only accessed while replicating is set, or replicaMu is
+	// locked.
+	replicaBase uint32
+	// readReplicaOf is Options.ReadReplicaOf, nil if the indexer is not a read replica.
+	readReplicaOf ReplicaStore
+	// replicaHeight is the height of the replica served by a read replica.
+	replicaHeight atomic.Uint32
+	// replicaMu serializes the restores of the replicas of a read replica.
+	replicaMu sync.Mutex
+	// stopFollowing stops the refresh of the replica of a read replica.
+	stopFollowing chan struct{}
+
+	// backupTo, backupInterval, backupsKept and backupMaxAge are the
+	// Options of the scheduled backups.
+	backupTo       BackupStore
+	backupInterval time.Duration
+	backupsKept    int
+	backupMaxAge   time.Duration
+	// backupMu serializes the runs of RunBackup.
+	backupMu sync.Mutex
+	// backingUp tracks the scheduled backups, which are stopped by closing
+	// stopBackups.
+	backingUp   sync.WaitGroup
+	stopBackups chan struct{}
+
+	// chainID is Options.ChainID.
+	chainID string
+	// owner is the indexer this chain is attached to, see AttachApp.
+	owner *Indexer
+	// chains are the indexers of the chains attached with AttachApp, keyed by
+	// chain ID. Protected by chainsMu.
+	chains   map[string]*Indexer
+	chainsMu sync.RWMutex
+
+	// extensions is Options.Extensions.
+	extensions []Extension
+	// extensionScope restricts the statements run on readWriteDB while an
+	// extension runs.
+	extensionScope extensionScope
+
+	// pendingBackfills is the set of names of the backfills which are not
+	// done yet, see RunBackfills.
+	pendingBackfills sync.Map
+	// backfillMu serializes the runs of the backfills.
+	backfillMu sync.Mutex
+	// backfilling tracks the backfills running in the background, which are
+	// interrupted by closing stopBackfills. It is (re)created by startDB.
+	backfilling   sync.WaitGroup
+	stopBackfills chan struct{}
 }
 
 type Options struct {
 	DataDir string
 
+	// ChainID is the identifier of the chain indexed, which defaults to the
+	// chain ID of the app passed to New. It must be set for the read replicas
+	// without an app, see Indexer.ChainID.
+	ChainID string
+
 	// ExpectBackupRestore should be set to true if a call to Indexer.RestoreBackup
 	// will be made shortly after New is called, and before any indexing or queries happen.
 	// If the DB file on disk exists, this flag will be ignored and the existing DB will be loaded.
 	ExpectBackupRestore bool
 
 	IgnoreLiveResults bool
+
+	// CensusWeight, if set, returns the total weight of the census with the
+	// given root, used to compute the weight turnout of weighted censuses.
+	CensusWeight func(censusRoot []byte) (*big.Int, error)
+
+	// EncryptionKey, if set, is the key used to encrypt the database at rest
+	// with SQLCipher (see ErrEncryptionUnsupported). The backups restored via
+	// RestoreBackup must be encrypted with the same key.
+	EncryptionKey string
+
+	// ReplicateTo, if set, is the store a replica of the database is shipped
+	// to every ReplicaInterval blocks (DefaultReplicaInterval if zero), so that
+	// read replicas can serve the indexer queries, see ReadReplicaOf. Each
+	// replica is shipped as a segment with the WAL frames committed since the
+	// previous one, and a base replica, a full copy of the database, is only
+	// shipped on start and every ReplicaBaseInterval segments
+	// (DefaultReplicaBaseInterval if zero). The automatic WAL checkpoints are
+	// disabled meanwhile, so the WAL grows up to 64MiB between the checkpoints
+	// run by the replication.
+	ReplicateTo         ReplicaStore
+	ReplicaInterval     uint32
+	ReplicaBaseInterval uint32
+
+	// ReadReplicaOf, if set, makes the indexer a read replica of the indexer
+	// shipping its replicas to the store (see ReplicateTo). A read replica does
+	// not index the chain, and it is not subscribed to the state events. It
+	// restores the latest replica on New, failing if there is none, and then
+	// checks for a newer one every ReplicaRefresh (DefaultReplicaRefresh if zero).
+	// The methods which write to the database return ErrReadReplica. The app
+	// passed to New can be nil, as long as the methods which read the chain,
+	// such as ResultsProof, are not used.
+	ReadReplicaOf  ReplicaStore
+	ReplicaRefresh time.Duration
+
+	// BackupTo, if set, is the store a backup of the database is saved to
+	// every BackupInterval (DefaultBackupInterval if zero), see RunBackup.
+	// Only the last BackupsKept backups (DefaultBackupsKept if zero) are
+	// kept, and those older than BackupMaxAge are removed too, if set.
+	// The read replicas do not save backups.
+	BackupTo       BackupStore
+	BackupInterval time.Duration
+	BackupsKept    int
+	BackupMaxAge   time.Duration
+
+	// EstimateCounts, if true, makes the lists of votes and transactions
+	// return an estimation of their total count, which is exact only when it
+	// can be obtained without scanning the results (see CountsEstimated).
+	// Counting the results is the most expensive part of listing the pages
+	// of a big table. The exact counts are returned by CountVotes and
+	// CountTransactions.
+	EstimateCounts bool
+
+	// Extensions are the plugins which keep their own tables up to date on
+	// each Commit, see Extension. They are not run by the read replicas.
+	Extensions []Extension
+
+	// VotePatterns, if set, enables the analysis of the voting patterns of
+	// the processes, which alerts the VotePatternListener event listeners.
+	// The read replicas do not analyze the votes.
+	VotePatterns *VotePatternOptions
 }
 
-// New returns an instance of the Indexer
-// using the local storage database in DataDir and integrated into the state vochain instance.
-func New(app *vochain.BaseApplication, opts Options) (*Indexer, error) {
+// newIndexer returns an Indexer with the given options, without a database.
+func newIndexer(app *vochain.BaseApplication, opts Options) (*Indexer, error) {
 	idx := &Indexer{
-		App:               app,
-		ignoreLiveResults: opts.IgnoreLiveResults,
+		App:                 app,
+		chainID:             opts.ChainID,
+		ignoreLiveResults:   opts.IgnoreLiveResults,
+		estimateCounts:      opts.EstimateCounts,
+		censusWeight:        opts.CensusWeight,
+		encryptionKey:       opts.EncryptionKey,
+		replicateTo:         opts.ReplicateTo,
+		replicaInterval:     opts.ReplicaInterval,
+		replicaBaseInterval: opts.ReplicaBaseInterval,
+		replicaBase:         noReplicaBase,
+		readReplicaOf:       opts.ReadReplicaOf,
+		backupTo:            opts.BackupTo,
+		backupInterval:      opts.BackupInterval,
+		backupsKept:         opts.BackupsKept,
+		backupMaxAge:        opts.BackupMaxAge,
+		extensions:          opts.Extensions,
 
 		// TODO(mvdan): these three maps are all keyed by process ID,
 		// and each of them needs to query existing data from the DB.
@@ -123,8 +364,45 @@ func New(app *vochain.BaseApplication, opts Options) (*Indexer, error) {
 		votePool:                  make(map[string]map[string]*state.Vote),
 		blockUpdateProcs:          make(map[string]bool),
 		blockUpdateProcVoteCounts: make(map[string]bool),
+		blockResultsProcs:         make(map[string]bool),
+		blockNullifiers:           make(map[string][][]byte),
+		censusWeights:             make(map[string]*big.Int),
+		versions:                  newDataVersions(),
+	}
+	if err := checkExtensions(idx.extensions); err != nil {
+		return nil, err
+	}
+	var err error
+	if idx.nullifierFilters, err = lru.New[string, *indexertypes.NullifierFilter](maxNullifierFilters); err != nil {
+		return nil, err
+	}
+	if idx.replicaInterval == 0 {
+		idx.replicaInterval = DefaultReplicaInterval
+	}
+	if idx.replicaBaseInterval == 0 {
+		idx.replicaBaseInterval = DefaultReplicaBaseInterval
+	}
+	if idx.backupInterval == 0 {
+		idx.backupInterval = DefaultBackupInterval
+	}
+	if idx.backupsKept == 0 {
+		idx.backupsKept = DefaultBackupsKept
 	}
-	log.Infow("indexer initialization", "dataDir", opts.DataDir, "liveResults", !opts.IgnoreLiveResults)
+	if opts.VotePatterns != nil && opts.ReadReplicaOf == nil {
+		idx.votePatterns = newVotePatterns(*opts.VotePatterns)
+	}
+	return idx, nil
+}
+
+// New returns an instance of the Indexer
+// using the local storage database in DataDir and integrated into the state vochain instance.
+func New(app *vochain.BaseApplication, opts Options) (*Indexer, error) {
+	idx, err := newIndexer(app, opts)
+	if err != nil {
+		return nil, err
+	}
+	log.Infow("indexer initialization", "dataDir", opts.DataDir, "liveResults", !opts.IgnoreLiveResults,
+		"encrypted", opts.EncryptionKey != "", "readReplica", opts.ReadReplicaOf != nil)
 
 	// The DB itself is opened in "rwc" mode, so it is created if it does not yet exist.
 	// Create the parent directory as well if it doesn't exist.
@@ -132,6 +410,20 @@ func New(app *vochain.BaseApplication, opts Options) (*Indexer, error) {
 		return nil, err
 	}
 	idx.dbPath = filepath.Join(opts.DataDir, dbFilename)
+	idx.registerMetrics()
+
+	if idx.readReplicaOf != nil {
+		if err := idx.startReplicaDB(); err != nil {
+			return nil, err
+		}
+		refresh := opts.ReplicaRefresh
+		if refresh == 0 {
+			refresh = DefaultReplicaRefresh
+		}
+		idx.stopFollowing = make(chan struct{})
+		go idx.followReplicas(refresh, idx.stopFollowing)
+		return idx, nil
+	}
 
 	// if dbPath exists, always startDB (ExpectBackupRestore is ignored)
 	// if dbPath doesn't exist, and we're not expecting a BackupRestore, startDB
@@ -154,33 +446,50 @@ func (idx *Indexer) startDB() error {
 		panic("Indexer.startDB called twice")
 	}
 
-	var err error
-
 	// sqlite doesn't support multiple concurrent writers.
 	// For that reason, readWriteDB is limited to one open connection.
 	// Per https://github.com/mattn/go-sqlite3/issues/1022#issuecomment-1067353980,
 	// we use WAL to allow multiple concurrent readers at the same time.
-	idx.readWriteDB, err = sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=rwc&_journal_mode=wal&_txlock=immediate&_synchronous=normal&_foreign_keys=true", idx.dbPath))
-	if err != nil {
+	pragmas := []string{"journal_mode = wal", "foreign_keys = true"}
+	if idx.replicateTo != nil {
+		// the WAL frames are shipped to the replicas before being checkpointed
+		pragmas = append(pragmas, "wal_autocheckpoint = 0")
+	}
+	idx.readWriteDB = openDBWithHook(fmt.Sprintf("file:%s?mode=rwc&_txlock=immediate&_synchronous=normal", idx.dbPath),
+		idx.encryptionKey, func(conn *sqlite3.SQLiteConn) error {
+			conn.RegisterAuthorizer(idx.extensionScope.authorizer)
+			return nil
+		}, pragmas...)
+	// fail early if the database cannot be opened, e.g. due to a wrong key
+	if err := idx.readWriteDB.Ping(); err != nil {
 		return err
 	}
 	idx.readWriteDB.SetMaxOpenConns(1)
 	idx.readWriteDB.SetMaxIdleConns(1)
-	idx.readWriteDB.SetConnMaxIdleTime(10 * time.Minute)
+	if idx.replicateTo == nil {
+		// closing the last connection checkpoints the WAL, so it is kept open
+		// while replicating, until the frames are shipped
+		idx.readWriteDB.SetConnMaxIdleTime(10 * time.Minute)
+	}
+	// the WAL written before is not known to the replicas, see replicateUnsafe
+	idx.replicaWAL = walCursor{path: idx.dbPath + "-wal"}
+	idx.replicaBase = noReplicaBase
 
-	if err := goose.SetDialect("sqlite3"); err != nil {
+	idx.stopBackfills = make(chan struct{})
+	fromVersion, pending, err := idx.migrate()
+	if err != nil {
 		return err
 	}
-	goose.SetLogger(log.GooseLogger())
-	goose.SetBaseFS(embedMigrations)
-
-	if gooseMigrationsPending(idx.readWriteDB, "migrations") {
-		log.Info("indexer db needs migration, scheduling a reindex after sync")
-		defer func() { go idx.ReindexBlocks(false) }()
+	// the data required by the migrations is backfilled in the background,
+	// once the indexer is started
+	if pending {
+		log.Infow("indexer db migrated, scheduling the backfills", "fromVersion", fromVersion)
+		if err := idx.scheduleBackfills(context.TODO(), fromVersion); err != nil {
+			return err
+		}
 	}
-
-	if err := goose.Up(idx.readWriteDB, "migrations"); err != nil {
-		return fmt.Errorf("goose up: %w", err)
+	if err := idx.createExtensionSchemas(context.TODO()); err != nil {
+		return err
 	}
 
 	// Analyze the tables and indices and store information in internal tables
@@ -189,26 +498,33 @@ func (idx *Indexer) startDB() error {
 		return err
 	}
 
-	idx.readOnlyDB, err = sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro&_journal_mode=wal", idx.dbPath))
-	if err != nil {
-		return err
-	}
+	idx.readOnlyDB = openDB(fmt.Sprintf("file:%s?mode=ro", idx.dbPath), idx.encryptionKey, "journal_mode = wal")
 	// Increasing these numbers can allow for more queries to run concurrently,
 	// but it also increases the memory used by sqlite and our connection pool.
 	// Most read-only queries we run are quick enough, so a small number seems OK.
 	idx.readOnlyDB.SetMaxOpenConns(16)
 	idx.readOnlyDB.SetMaxIdleConns(4)
 	idx.readOnlyDB.SetConnMaxIdleTime(30 * time.Minute)
+	idx.openQueryDB()
 
-	idx.readOnlyQuery, err = indexerdb.Prepare(context.TODO(), idx.readOnlyDB)
-	if err != nil {
+	if err := idx.prepareQueries(context.TODO()); err != nil {
 		return err
 	}
-	idx.blockQueries, err = indexerdb.Prepare(context.TODO(), idx.readWriteDB)
-	if err != nil {
+	if err := idx.loadAnomalyCount(context.TODO()); err != nil {
 		return err
 	}
-	return nil
+	if err := idx.loadIndexedHeight(context.TODO()); err != nil {
+		return err
+	}
+	if idx.backupTo != nil {
+		idx.stopBackups = make(chan struct{})
+		idx.backingUp.Add(1)
+		go func() {
+			defer idx.backingUp.Done()
+			idx.scheduleBackups(idx.backupInterval, idx.stopBackups)
+		}()
+	}
+	return idx.startBackfills(context.TODO())
 }
 
 func copyFile(dst, src string) error {
@@ -231,15 +547,76 @@ func copyFile(dst, src string) error {
 }
 
 func (idx *Indexer) Close() error {
-	if err := idx.readOnlyDB.Close(); err != nil {
+	if err := idx.closeChains(); err != nil {
 		return err
 	}
-	if err := idx.readWriteDB.Close(); err != nil {
-		return err
+	idx.auditing.Wait()
+	idx.decrypting.Wait()
+	metrics.UnregisterSet(idx.metrics)
+	if idx.stopFollowing != nil {
+		close(idx.stopFollowing)
+		idx.stopFollowing = nil
+	}
+	return idx.closeDB()
+}
+
+// closeDB stops the backfills and the scheduled backups, which are started
+// again by startDB, and closes the database connections. Unlike Close, the
+// indexer can be started again with startDB, as RotateEncryptionKey does.
+func (idx *Indexer) closeDB() error {
+	if idx.stopBackfills != nil {
+		close(idx.stopBackfills)
+		idx.backfilling.Wait()
+		idx.stopBackfills = nil
+	}
+	if idx.stopBackups != nil {
+		close(idx.stopBackups)
+		idx.backingUp.Wait()
+		idx.stopBackups = nil
+	}
+	idx.shipping.Wait()
+	if idx.owner != nil {
+		idx.detach()
+	}
+	// the connections are opened in the reverse order by startDB, which may
+	// have failed before opening all of them
+	for _, db := range []*sql.DB{idx.queryDB, idx.readOnlyDB, idx.readWriteDB} {
+		if db == nil {
+			continue
+		}
+		if err := db.Close(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// ChainID returns the identifier of the chain indexed, as given by
+// Options.ChainID or else by the app, so that the indexers of several chains,
+// such as the ones attached with AttachApp, can be served by the same API.
+func (idx *Indexer) ChainID() string {
+	if idx.chainID == "" && idx.App != nil {
+		return idx.App.ChainID()
+	}
+	return idx.chainID
+}
+
+// CountsEstimated returns whether the total counts of the lists of votes and
+// transactions are estimations, see Options.EstimateCounts.
+func (idx *Indexer) CountsEstimated() bool {
+	return idx.estimateCounts
+}
+
+// estimatedCountLowerBound returns the minimum total count of a list given the
+// offset of its page, the number of results and whether there is a next page.
+func estimatedCountLowerBound(offset, results int, nextCursor string) uint64 {
+	lowerBound := uint64(offset + results)
+	if nextCursor != "" {
+		lowerBound++
+	}
+	return lowerBound
+}
+
 // RestoreBackup restores the database from a backup created via SaveBackup.
 // Note that this must be called with ExpectBackupRestore set to true,
 // and before any indexing or queries happen.
@@ -256,25 +633,27 @@ func (idx *Indexer) RestoreBackup(path string) error {
 	return nil
 }
 
-func gooseMigrationsPending(db *sql.DB, dir string) bool {
+// gooseMigrationsPending returns the current version of the database, and
+// whether there are migrations to apply to it.
+func gooseMigrationsPending(db *sql.DB, dir string) (int64, bool) {
 	// Get the latest applied migration version
 	currentVersion, err := goose.GetDBVersion(db)
 	if err != nil {
 		log.Errorf("failed to get current database version: %v", err)
-		return false
+		return 0, false
 	}
 
 	// Collect migrations after the current version
 	migrations, err := goose.CollectMigrations(dir, currentVersion, goose.MaxVersion)
 	if err != nil {
 		if errors.Is(err, goose.ErrNoMigrationFiles) {
-			return false
+			return currentVersion, false
 		}
 		log.Errorf("failed to collect migrations: %v", err)
-		return false
+		return currentVersion, false
 	}
 
-	return len(migrations) > 0
+	return currentVersion, len(migrations) > 0
 }
 
 // SaveBackup backs up the database to a file on disk.
@@ -322,6 +701,7 @@ func (idx *Indexer) blockTxQueries() *indexerdb.Queries {
 		idx.blockTx = tx
 		idx.blockQueries = idx.blockQueries.WithTx(tx)
 	}
+	idx.blockWrites.Add(1)
 	return idx.blockQueries
 }
 
@@ -332,7 +712,7 @@ func (idx *Indexer) blockTxQueries() *indexerdb.Queries {
 // TO-DO: refactor and use blockHeight for reusing existing live results
 func (idx *Indexer) AfterSyncBootstrap(inTest bool) {
 	// if no live results, we don't need the bootstraping
-	if idx.ignoreLiveResults {
+	if idx.ignoreLiveResults || idx.readReplicaOf != nil {
 		return
 	}
 
@@ -432,6 +812,9 @@ func (idx *Indexer) AfterSyncBootstrap(inTest bool) {
 
 // ReindexBlocks reindexes all blocks found in blockstore
 func (idx *Indexer) ReindexBlocks(inTest bool) {
+	if idx.readReplicaOf != nil {
+		return
+	}
 	if !inTest {
 		<-idx.App.WaitUntilSynced()
 	}
@@ -455,61 +838,22 @@ func (idx *Indexer) ReindexBlocks(inTest bool) {
 	)
 	queries := idx.blockTxQueries()
 	for height := idx.App.Node.BlockStore().Base(); height <= idx.App.Node.BlockStore().Height(); height++ {
-		if b := idx.App.GetBlockByHeight(int64(height)); b != nil {
-			// Blocks
-			func() {
-				idxBlock, err := idx.readOnlyQuery.GetBlockByHeight(context.TODO(), b.Height)
-				if height%10000 == 1 {
-					log.Infof("reindexing height %d, updating values (%s, %x, %x, %x) on current row %+v",
-						height, b.ChainID, b.Hash(), b.ProposerAddress, b.LastBlockID.Hash, idxBlock)
-					if err := idx.blockTx.Commit(); err != nil {
-						log.Errorw(err, "could not commit tx")
-					}
-					idx.blockTx = nil
-					queries = idx.blockTxQueries()
-				}
-				if err == nil && idxBlock.Time != b.Time {
-					log.Errorf("while reindexing blocks, block %d timestamp in db (%s) differs from blockstore (%s), leaving untouched", height, idxBlock.Time, b.Time)
-					return
-				}
-				if _, err := queries.CreateBlock(context.TODO(), indexerdb.CreateBlockParams{
-					ChainID:         b.ChainID,
-					Height:          b.Height,
-					Time:            b.Time,
-					Hash:            nonNullBytes(b.Hash()),
-					ProposerAddress: nonNullBytes(b.ProposerAddress),
-					LastBlockHash:   nonNullBytes(b.LastBlockID.Hash),
-				}); err != nil {
-					log.Errorw(err, "cannot index new block")
-				}
-			}()
-
-			// Transactions
-			func() {
-				for index, tx := range b.Data.Txs {
-					idxTx, err := idx.readOnlyQuery.GetTransactionByHeightAndIndex(context.TODO(), indexerdb.GetTransactionByHeightAndIndexParams{
-						BlockHeight: b.Height,
-						BlockIndex:  int64(index),
-					})
-					if err == nil && !bytes.Equal(idxTx.Hash, tx.Hash()) {
-						log.Errorf("while reindexing txs, tx %d/%d hash in db (%x) differs from blockstore (%x), leaving untouched", b.Height, index, idxTx.Hash, tx.Hash())
-						return
-					}
-					vtx := new(vochaintx.Tx)
-					if err := vtx.Unmarshal(tx, b.ChainID); err != nil {
-						log.Errorw(err, fmt.Sprintf("cannot unmarshal tx %d/%d", b.Height, index))
-						continue
-					}
-					idx.indexTx(vtx, uint32(b.Height), int32(index))
-				}
-			}()
+		if height%10000 == 1 {
+			log.Infof("reindexing height %d", height)
+			if err := idx.blockTx.Commit(); err != nil {
+				log.Errorw(err, "could not commit tx")
+			}
+			idx.blockTx = nil
+			queries = idx.blockTxQueries()
 		}
+		idx.reindexBlock(context.TODO(), queries, height)
 	}
 
 	if err := idx.blockTx.Commit(); err != nil {
 		log.Errorw(err, "could not commit tx")
 	}
 	idx.blockTx = nil
+	idx.versions.bump()
 
 	log.Infow("finished reindexing",
 		"blockStoreBase", idx.App.Node.BlockStore().Base(),
@@ -518,6 +862,60 @@ func (idx *Indexer) ReindexBlocks(inTest bool) {
 	)
 }
 
+// reindexBlock reindexes the block at height found in blockstore, along with its
+// transactions and stats, leaving untouched the ones indexed differently.
+func (idx *Indexer) reindexBlock(ctx context.Context, queries *indexerdb.Queries, height int64) {
+	b := idx.App.GetBlockByHeight(height)
+	if b == nil {
+		return
+	}
+	// Blocks
+	func() {
+		idxBlock, err := idx.readOnlyQuery.GetBlockByHeight(ctx, b.Height)
+		if err == nil && idxBlock.Time != b.Time {
+			log.Errorf("while reindexing blocks, block %d timestamp in db (%s) differs from blockstore (%s), leaving untouched", height, idxBlock.Time, b.Time)
+			return
+		}
+		if _, err := queries.CreateBlock(ctx, indexerdb.CreateBlockParams{
+			ChainID:         b.ChainID,
+			Height:          b.Height,
+			Time:            b.Time,
+			Hash:            nonNullBytes(b.Hash()),
+			ProposerAddress: nonNullBytes(b.ProposerAddress),
+			LastBlockHash:   nonNullBytes(b.LastBlockID.Hash),
+		}); err != nil {
+			log.Errorw(err, "cannot index new block")
+		}
+		idx.indexVotesBlockTime(ctx, queries, b.Height, b.Time)
+		idx.indexValidatorSignatures(ctx, queries, b)
+	}()
+
+	// Transactions
+	func() {
+		for index, tx := range b.Data.Txs {
+			idxTx, err := idx.readOnlyQuery.GetTransactionByHeightAndIndex(ctx, indexerdb.GetTransactionByHeightAndIndexParams{
+				BlockHeight: b.Height,
+				BlockIndex:  int64(index),
+			})
+			if err == nil && !bytes.Equal(idxTx.Hash, tx.Hash()) {
+				log.Errorf("while reindexing txs, tx %d/%d hash in db (%x) differs from blockstore (%x), leaving untouched", b.Height, index, idxTx.Hash, tx.Hash())
+				return
+			}
+			vtx := new(vochaintx.Tx)
+			if err := vtx.Unmarshal(tx, b.ChainID); err != nil {
+				log.Errorw(err, fmt.Sprintf("cannot unmarshal tx %d/%d", b.Height, index))
+				continue
+			}
+			idx.indexTx(vtx, uint32(b.Height), int32(index))
+		}
+	}()
+
+	// Stats, once the transactions of the block are indexed
+	if err := idx.indexBlockStats(ctx, queries, b.Height, b.Time); err != nil {
+		log.Errorw(err, fmt.Sprintf("cannot index block stats %d", b.Height))
+	}
+}
+
 // Commit is called by the APP when a block is confirmed and included into the chain
 func (idx *Indexer) Commit(height uint32) error {
 	idx.blockMu.Lock()
@@ -530,6 +928,7 @@ func (idx *Indexer) Commit(height uint32) error {
 	ctx := context.TODO()
 
 	// index the new block
+	blockTime := time.Unix(idx.App.Timestamp(), 0)
 	if b := idx.App.GetBlockByHeight(int64(height)); b != nil {
 		if _, err := queries.CreateBlock(context.TODO(), indexerdb.CreateBlockParams{
 			ChainID:         b.ChainID,
@@ -541,7 +940,21 @@ func (idx *Indexer) Commit(height uint32) error {
 		}); err != nil {
 			log.Errorw(err, "cannot index new block")
 		}
+		idx.indexVotesBlockTime(ctx, queries, b.Height, b.Time)
+		idx.indexValidatorSignatures(ctx, queries, b)
+		blockTime = b.Time
+	}
+	if err := idx.indexBlockStats(ctx, queries, int64(height), blockTime); err != nil {
+		log.Errorw(err, "cannot index block stats")
+	}
+	if err := idx.indexAccountStats(ctx, queries, blockTime); err != nil {
+		log.Errorw(err, "cannot index account stats")
+	}
+	if err := idx.indexValidatorSet(ctx, queries, height); err != nil {
+		log.Errorw(err, "cannot index validator set")
 	}
+	// the changes not made by a transaction, if any, are indexed without hash
+	idx.indexStatusChanges(ctx, queries, nil)
 
 	for _, pidStr := range updateProcs {
 		pid := types.ProcessID(pidStr)
@@ -553,6 +966,16 @@ func (idx *Indexer) Commit(height uint32) error {
 	}
 	clear(idx.blockUpdateProcs)
 
+	// The state committed on the previous block includes the results of the
+	// pending processes, and it is the one the header of this block commits to.
+	for _, pidStr := range idx.pendingResultsProofs {
+		if err := idx.indexResultsProof(ctx, queries, types.ProcessID(pidStr), height); err != nil {
+			log.Errorw(err, "commit: cannot index results proof")
+		}
+	}
+	idx.pendingResultsProofs = slices.Sorted(maps.Keys(idx.blockResultsProcs))
+	clear(idx.blockResultsProcs)
+
 	// Add votes collected by onVote (live results)
 	newVotes := 0
 	overwritedVotes := 0
@@ -641,12 +1064,81 @@ func (idx *Indexer) Commit(height uint32) error {
 			log.Errorw(err, "could not compute process vote count")
 		}
 	}
+
+	// Once the vote counts, results and census sizes are up to date, update the turnout
+	// of the processes that changed on this block.
+	turnoutProcs := append(updateProcs, slices.Collect(maps.Keys(idx.blockUpdateProcVoteCounts))...)
+	slices.Sort(turnoutProcs)
+	for _, pidStr := range slices.Compact(turnoutProcs) {
+		if err := idx.updateProcessTurnout(ctx, queries, types.ProcessID(pidStr)); err != nil {
+			log.Errorw(err, "commit: cannot update process turnout")
+		}
+	}
+	changed := []DataKind{DataBlocks}
+	if len(idx.blockUpdateProcVoteCounts) > 0 {
+		changed = append(changed, DataVotes)
+	}
+	if idx.blockHasTxs {
+		changed = append(changed, DataTransactions)
+	}
 	clear(idx.blockUpdateProcVoteCounts)
+	idx.blockHasTxs = false
+
+	// Once the block is indexed, the extensions update their own tables.
+	idx.commitExtensions(ctx, height)
 
 	if err := idx.blockTx.Commit(); err != nil {
 		log.Errorw(err, "could not commit tx")
 	}
 	idx.blockTx = nil
+	idx.blockWrites.Store(0)
+	idx.indexedHeight.Store(height)
+	idx.versions.bump(changed...)
+	idx.updateNullifierFilters(height)
+
+	// Once the final results are committed, notify the event listeners.
+	for _, pid := range idx.blockFinalizedProcs {
+		proc, err := idx.ProcessInfo(pid)
+		if err != nil {
+			log.Errorw(err, "commit: cannot fetch process with final results")
+			continue
+		}
+		for _, l := range idx.eventOnResults {
+			l.OnComputeResults(proc.Results(), proc, height)
+		}
+	}
+	idx.blockFinalizedProcs = nil
+	for _, pid := range idx.blockCensusSizeAlerts {
+		proc, err := idx.ProcessInfo(pid)
+		if err != nil {
+			log.Errorw(err, "commit: cannot fetch process approaching its census size")
+			continue
+		}
+		log.Warnw("process approaching its max census size",
+			"processID", hex.EncodeToString(pid), "votes", proc.VoteCount, "maxCensusSize", proc.MaxCensusSize)
+		for _, l := range idx.eventOnResults {
+			if l, ok := l.(CensusSizeListener); ok {
+				l.OnCensusSizeAlert(proc, height)
+			}
+		}
+	}
+	idx.blockCensusSizeAlerts = nil
+	if idx.votePatterns != nil {
+		idx.notifyVotePatternAlerts(idx.votePatterns.commit(blockTime), height)
+	}
+	if len(idx.pendingAudits) > 0 {
+		idx.auditing.Add(1)
+		go idx.auditProcesses(idx.pendingAudits)
+	}
+	idx.pendingAudits = idx.blockEndedProcs
+	idx.blockEndedProcs = nil
+	if len(idx.pendingDecryptions) > 0 {
+		idx.decrypting.Add(1)
+		go idx.decryptProcessesVotes(idx.pendingDecryptions)
+	}
+	idx.pendingDecryptions = idx.blockRevealedProcs
+	idx.blockRevealedProcs = nil
+	idx.replicateUnsafe(height)
 	if height%1000 == 0 {
 		// Regularly see if sqlite thinks another optimization analysis would be useful.
 		// Block times tend to be in the order of seconds like 10s,
@@ -672,12 +1164,27 @@ func (idx *Indexer) Rollback() {
 	clear(idx.votePool)
 	clear(idx.blockUpdateProcs)
 	clear(idx.blockUpdateProcVoteCounts)
+	clear(idx.blockResultsProcs)
+	clear(idx.blockNullifiers)
+	idx.blockStatusChanges = nil
+	idx.blockHasTxs = false
+	idx.blockNewAccounts = 0
+	idx.blockFinalizedProcs = nil
+	idx.blockCensusSizeAlerts = nil
+	if idx.votePatterns != nil {
+		idx.votePatterns.rollback()
+	}
+	idx.blockEndedProcs = nil
+	idx.blockRevealedProcs = nil
+	// the cached powers may include changes that are being rolled back
+	idx.validatorPowers = nil
 	if idx.blockTx != nil {
 		if err := idx.blockTx.Rollback(); err != nil {
 			log.Errorw(err, "could not rollback tx")
 		}
 		idx.blockTx = nil
 	}
+	idx.blockWrites.Store(0)
 }
 
 // OnProcess indexer stores the processID
@@ -732,10 +1239,30 @@ func (idx *Indexer) OnVote(vote *state.Vote, txIndex int32) {
 		VoterID:              nonNullBytes(vote.VoterID),
 		EncryptionKeyIndexes: keyIndexes,
 		Package:              string(vote.VotePackage),
+		// replaced by the time of the block header once the block is indexed
+		BlockTime: sql.NullTime{Time: time.Unix(idx.App.Timestamp(), 0).UTC(), Valid: true},
 	}); err != nil {
 		log.Errorw(err, "could not index vote")
 	}
 	idx.blockUpdateProcVoteCounts[pid] = true
+	if vote.Overwrites == 0 {
+		idx.blockNullifiers[pid] = append(idx.blockNullifiers[pid], vote.Nullifier)
+	}
+	// the past votes replayed while syncing would raise outdated alerts
+	if idx.votePatterns != nil && idx.App.IsSynced() {
+		idx.votePatterns.add(vote)
+	}
+}
+
+// indexVotesBlockTime sets the time of the votes of the block at height to the
+// one of its header.
+func (*Indexer) indexVotesBlockTime(ctx context.Context, queries *indexerdb.Queries, height int64, blockTime time.Time) {
+	if _, err := queries.SetVotesBlockTime(ctx, indexerdb.SetVotesBlockTimeParams{
+		BlockTime:   sql.NullTime{Time: blockTime.UTC(), Valid: true},
+		BlockHeight: height,
+	}); err != nil {
+		log.Errorw(err, "cannot index the block time of the votes")
+	}
 }
 
 // OnCancel indexer stores the processID and entityID
@@ -752,22 +1279,36 @@ func (idx *Indexer) OnProcessKeys(pid []byte, _ string, _ int32) {
 	idx.blockUpdateProcs[string(pid)] = true
 }
 
-// OnProcessStatusChange adds the process to blockUpdateProcs and, if ended, the resultsPool
-func (idx *Indexer) OnProcessStatusChange(pid []byte, _ models.ProcessStatus, _ int32) {
+// OnProcessStatusChange adds the process to blockUpdateProcs and records the
+// change in its status history
+func (idx *Indexer) OnProcessStatusChange(pid []byte, status models.ProcessStatus, txIndex int32) {
 	idx.blockMu.Lock()
 	defer idx.blockMu.Unlock()
 	idx.blockUpdateProcs[string(pid)] = true
+	idx.blockStatusChanges = append(idx.blockStatusChanges, indexerdb.CreateProcessStatusChangeParams{
+		ProcessID:   pid,
+		BlockHeight: int64(idx.App.Height()),
+		BlockIndex:  int64(txIndex),
+		Status:      int64(status),
+	})
 }
 
-// OnProcessDurationChange adds the process to blockUpdateProcs and, if ended, the resultsPool
-func (idx *Indexer) OnProcessDurationChange(pid []byte, _ uint32, _ int32) {
+// OnProcessDurationChange adds the process to blockUpdateProcs and records the
+// change in its status history
+func (idx *Indexer) OnProcessDurationChange(pid []byte, duration uint32, txIndex int32) {
 	idx.blockMu.Lock()
 	defer idx.blockMu.Unlock()
 	idx.blockUpdateProcs[string(pid)] = true
+	idx.blockStatusChanges = append(idx.blockStatusChanges, indexerdb.CreateProcessStatusChangeParams{
+		ProcessID:   pid,
+		BlockHeight: int64(idx.App.Height()),
+		BlockIndex:  int64(txIndex),
+		Duration:    int64(duration),
+	})
 }
 
 // OnRevealKeys checks if all keys have been revealed and in such case add the
-// process to the results queue
+// process to the results queue, and schedules the decryption of its votes.
 func (idx *Indexer) OnRevealKeys(pid []byte, _ string, _ int32) {
 	// TODO: can we get KeyIndex from ProcessInfo? perhaps len(PublicKeys), or adding a new sqlite column?
 	p, err := idx.App.State.Process(pid, false)
@@ -782,6 +1323,9 @@ func (idx *Indexer) OnRevealKeys(pid []byte, _ string, _ int32) {
 	idx.blockMu.Lock()
 	defer idx.blockMu.Unlock()
 	idx.blockUpdateProcs[string(pid)] = true
+	if *p.KeyIndex == 0 && p.EnvelopeType.GetEncryptedVotes() {
+		idx.blockRevealedProcs = append(idx.blockRevealedProcs, pid)
+	}
 }
 
 // OnProcessResults verifies the results for a process and appends it to blockUpdateProcs
@@ -789,6 +1333,7 @@ func (idx *Indexer) OnProcessResults(pid []byte, _ *models.ProcessResult, _ int3
 	idx.blockMu.Lock()
 	defer idx.blockMu.Unlock()
 	idx.blockUpdateProcs[string(pid)] = true
+	idx.blockResultsProcs[string(pid)] = true
 }
 
 // OnProcessesStart adds the processes to blockUpdateProcs.
@@ -805,6 +1350,13 @@ func (idx *Indexer) OnSetAccount(accountAddress []byte, account *state.Account)
 	idx.blockMu.Lock()
 	defer idx.blockMu.Unlock()
 	queries := idx.blockTxQueries()
+	exists, err := queries.ExistsAccount(context.TODO(), accountAddress)
+	if err != nil {
+		log.Errorw(err, "cannot check if account is indexed")
+	}
+	if err == nil && exists == 0 {
+		idx.blockNewAccounts++
+	}
 	if _, err := queries.CreateAccount(context.TODO(), indexerdb.CreateAccountParams{
 		Account: accountAddress,
 		Balance: int64(account.Balance),
@@ -830,12 +1382,98 @@ func (idx *Indexer) OnTransferTokens(tx *vochaintx.TokenTransfer) {
 	}
 }
 
-// OnCensusUpdate adds the process to blockUpdateProcs in order to update the census.
+// OnSetAccountKV indexes an update of the key-value store of an account, an
+// empty value deletes the entry.
+func (idx *Indexer) OnSetAccountKV(address []byte, key string, value []byte) {
+	idx.blockMu.Lock()
+	defer idx.blockMu.Unlock()
+	queries := idx.blockTxQueries()
+	if len(value) == 0 {
+		if _, err := queries.DeleteAccountKV(context.TODO(), indexerdb.DeleteAccountKVParams{
+			Account: address,
+			Key:     key,
+		}); err != nil {
+			log.Errorw(err, "cannot delete account key-value entry")
+		}
+		return
+	}
+	if _, err := queries.SetAccountKV(context.TODO(), indexerdb.SetAccountKVParams{
+		Account: address,
+		Key:     key,
+		Value:   value,
+		Height:  int64(idx.App.Height()),
+	}); err != nil {
+		log.Errorw(err, "cannot index account key-value entry")
+	}
+}
+
+// OnVoteDelegation indexes a delegation of the vote weight of a census member
+// of a process, a nil delegation revokes it.
+func (idx *Indexer) OnVoteDelegation(pid, delegator []byte, delegation *state.VoteDelegation) {
+	idx.blockMu.Lock()
+	defer idx.blockMu.Unlock()
+	queries := idx.blockTxQueries()
+	if delegation == nil {
+		if _, err := queries.DeleteVoteDelegation(context.TODO(), indexerdb.DeleteVoteDelegationParams{
+			ProcessID: pid,
+			Delegator: delegator,
+		}); err != nil {
+			log.Errorw(err, "cannot delete vote delegation")
+		}
+		return
+	}
+	if _, err := queries.SetVoteDelegation(context.TODO(), indexerdb.SetVoteDelegationParams{
+		ProcessID: pid,
+		Delegator: delegator,
+		Delegate:  delegation.Delegate.Bytes(),
+		Weight:    delegation.Weight.String(),
+		Height:    int64(idx.App.Height()),
+	}); err != nil {
+		log.Errorw(err, "cannot index vote delegation")
+	}
+}
+
+// OnAccountRecovery indexes a change of the recovery of an account.
+func (idx *Indexer) OnAccountRecovery(address []byte, event *state.AccountRecoveryEvent) {
+	idx.blockMu.Lock()
+	defer idx.blockMu.Unlock()
+	params := indexerdb.CreateAccountRecoveryEventParams{
+		Account:  address,
+		Kind:     event.Kind,
+		Guardian: []byte{},
+		NewOwner: []byte{},
+		Height:   int64(idx.App.Height()),
+	}
+	switch event.Kind {
+	case state.AccountRecoveryGuardiansSet:
+		if event.Guardians != nil {
+			params.Guardians = indexertypes.EncodeJSON(event.Guardians)
+		}
+	case state.AccountRecoveryApproved:
+		params.Guardian = event.Guardian.Bytes()
+		params.NewOwner = event.NewOwner.Bytes()
+	case state.AccountRecoveryExecuted:
+		params.NewOwner = event.NewOwner.Bytes()
+	}
+	if _, err := idx.blockTxQueries().CreateAccountRecoveryEvent(context.TODO(), params); err != nil {
+		log.Errorw(err, "cannot index account recovery event")
+	}
+}
+
+// OnCensusUpdate adds the process to blockUpdateProcs in order to update the census,
+// and indexes the new versions of its census history.
 // This function call is triggered by the SET_PROCESS_CENSUS tx.
 func (idx *Indexer) OnCensusUpdate(pid, _ []byte, _ string, _ uint64) {
+	history, err := idx.App.State.ProcessCensusHistory(pid, false)
+	if err != nil {
+		log.Errorw(err, "cannot get process census history")
+	}
 	idx.blockMu.Lock()
 	defer idx.blockMu.Unlock()
 	idx.blockUpdateProcs[string(pid)] = true
+	if err := idx.indexCensusHistory(context.TODO(), idx.blockTxQueries(), pid, history); err != nil {
+		log.Errorw(err, "cannot index process census history")
+	}
 }
 
 // OnSpendTokens indexes a token spending event.
@@ -845,7 +1483,7 @@ func (idx *Indexer) OnSpendTokens(address []byte, txType models.TxType, cost uin
 	queries := idx.blockTxQueries()
 	if _, err := queries.CreateTokenFee(context.TODO(), indexerdb.CreateTokenFeeParams{
 		FromAccount: address,
-		TxType:      strings.ToLower(txType.String()),
+		TxType:      strings.ToLower(vochaintx.TxTypeName(txType)),
 		Cost:        int64(cost),
 		Reference:   reference,
 		SpendTime:   time.Unix(idx.App.Timestamp(), 0),
@@ -897,22 +1535,51 @@ func (idx *Indexer) TokenFeesList(limit, offset int, txType, reference, fromAcco
 // (all optional filters), ordered by timestamp and paginated by limit and offset
 func (idx *Indexer) TokenTransfersList(limit, offset int, fromOrToAccount, fromAccount, toAccount string) (
 	[]*indexertypes.TokenTransferMeta, uint64, error,
+) {
+	list, _, total, err := idx.TokenTransfersListWithCursor(limit, offset, "", fromOrToAccount, fromAccount, toAccount)
+	return list, total, err
+}
+
+// TokenTransfersListWithCursor is like TokenTransfersList, but also accepts an opaque cursor
+// returned by a previous call. If not empty, the list starts right after the last transfer
+// returned by that call, and offset is applied from there. It also returns the cursor to fetch
+// the next page, which is empty if there are no more transfers. The total number of transfers
+// is only counted when cursor is empty, as it requires scanning all of them, and it is zero
+// otherwise.
+func (idx *Indexer) TokenTransfersListWithCursor(limit, offset int, cursor, fromOrToAccount, fromAccount, toAccount string) (
+	[]*indexertypes.TokenTransferMeta, string, uint64, error,
 ) {
 	if offset < 0 {
-		return nil, 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
+		return nil, "", 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
 	}
 	if limit <= 0 {
-		return nil, 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
+		return nil, "", 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
 	}
-	results, err := idx.readOnlyQuery.SearchTokenTransfers(context.TODO(), indexerdb.SearchTokenTransfersParams{
-		Limit:           int64(limit),
+	params := indexerdb.SearchTokenTransfersParams{
+		Limit:           int64(limit) + 1, // fetch one more to know if there is a next page
 		Offset:          int64(offset),
 		FromOrToAccount: fromOrToAccount,
 		FromAccount:     fromAccount,
 		ToAccount:       toAccount,
-	})
+	}
+	if cursor != "" {
+		var transferTime time.Time
+		var txHash []byte
+		if err := decodeCursor(cursor, &transferTime, &txHash); err != nil {
+			return nil, "", 0, err
+		}
+		params.CursorTransferTime = transferTime
+		params.CursorTxHash = txHash
+	}
+	results, err := idx.readOnlyQuery.SearchTokenTransfers(context.TODO(), params)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", 0, err
+	}
+	nextCursor := ""
+	if len(results) > limit {
+		results = results[:limit]
+		last := results[limit-1]
+		nextCursor = encodeCursor(last.TransferTime, last.TxHash)
 	}
 	list := []*indexertypes.TokenTransferMeta{}
 	for _, row := range results {
@@ -925,10 +1592,18 @@ func (idx *Indexer) TokenTransfersList(limit, offset int, fromOrToAccount, fromA
 			Timestamp: row.TransferTime,
 		})
 	}
-	if len(results) == 0 {
-		return list, 0, nil
+	if len(results) == 0 || cursor != "" {
+		return list, nextCursor, 0, nil
 	}
-	return list, uint64(results[0].TotalCount), nil
+	total, err := idx.readOnlyQuery.CountSearchTokenTransfers(context.TODO(), indexerdb.CountSearchTokenTransfersParams{
+		FromOrToAccount: fromOrToAccount,
+		FromAccount:     fromAccount,
+		ToAccount:       toAccount,
+	})
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return list, nextCursor, uint64(total), nil
 }
 
 // CountTokenTransfersByAccount returns the count all the token transfers made from a given account
@@ -974,6 +1649,176 @@ func (idx *Indexer) AccountList(limit, offset int, accountID string) ([]*indexer
 	return list, uint64(results[0].TotalCount), nil
 }
 
+// AccountKV returns the entries of the key-value store of an account, sorted by key.
+func (idx *Indexer) AccountKV(address []byte) ([]*indexertypes.AccountKV, error) {
+	results, err := idx.readOnlyQuery.GetAccountKV(context.TODO(), address)
+	if err != nil {
+		return nil, err
+	}
+	list := []*indexertypes.AccountKV{}
+	for _, row := range results {
+		list = append(list, &indexertypes.AccountKV{
+			Key:    row.Key,
+			Value:  row.Value,
+			Height: uint64(row.Height),
+		})
+	}
+	return list, nil
+}
+
+// AccountRecoveryEvents returns the history of the recovery of an account,
+// newest first and paginated by limit and offset, see OnAccountRecovery. It
+// also returns the total number of events.
+func (idx *Indexer) AccountRecoveryEvents(address []byte, limit, offset int) (
+	[]*indexertypes.AccountRecoveryEvent, uint64, error,
+) {
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
+	}
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
+	}
+	results, err := idx.readOnlyQuery.SearchAccountRecoveryEvents(context.TODO(), indexerdb.SearchAccountRecoveryEventsParams{
+		Account: address,
+		Limit:   int64(limit),
+		Offset:  int64(offset),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	list := []*indexertypes.AccountRecoveryEvent{}
+	for _, row := range results {
+		event := &indexertypes.AccountRecoveryEvent{
+			Kind:     row.Kind,
+			Guardian: nonEmptyBytes(row.Guardian),
+			NewOwner: nonEmptyBytes(row.NewOwner),
+			Height:   uint64(row.Height),
+		}
+		if row.Guardians != "" {
+			guardians := indexertypes.DecodeJSON[state.RecoveryGuardians](row.Guardians)
+			for _, guardian := range guardians.Guardians {
+				event.Guardians = append(event.Guardians, guardian.Bytes())
+			}
+			event.Quorum, event.Timelock = guardians.Quorum, guardians.Timelock
+		}
+		list = append(list, event)
+	}
+	if len(results) == 0 {
+		return list, 0, nil
+	}
+	return list, uint64(results[0].TotalCount), nil
+}
+
+// VoteDelegations returns the vote weight delegations of the census members of
+// a process, optionally only the ones to the given delegate (hex address),
+// ordered by delegate and delegator and paginated by limit and offset. It also
+// returns the total number of delegations.
+func (idx *Indexer) VoteDelegations(pid []byte, delegate string, limit, offset int) (
+	[]*indexertypes.VoteDelegation, uint64, error,
+) {
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
+	}
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
+	}
+	results, err := idx.readOnlyQuery.SearchVoteDelegations(context.TODO(), indexerdb.SearchVoteDelegationsParams{
+		Limit:     int64(limit),
+		Offset:    int64(offset),
+		ProcessID: pid,
+		Delegate:  delegate,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	list := []*indexertypes.VoteDelegation{}
+	for _, row := range results {
+		weight, ok := new(big.Int).SetString(row.Weight, 10)
+		if !ok {
+			return nil, 0, fmt.Errorf("invalid delegated weight %q", row.Weight)
+		}
+		list = append(list, &indexertypes.VoteDelegation{
+			Delegator: row.Delegator,
+			Delegate:  row.Delegate,
+			Weight:    (*types.BigInt)(weight),
+			Height:    uint64(row.Height),
+		})
+	}
+	if len(results) == 0 {
+		return list, 0, nil
+	}
+	return list, uint64(results[0].TotalCount), nil
+}
+
+// AccountFeed returns the activity of an account, newest first: the token
+// transfers it sent or received, the fees it paid, the elections it created,
+// the votes it cast and its SetAccount transactions. The votes are the ones
+// signed by the account, so the anonymous ones are not included. If not
+// empty, cursor is the one returned by a previous call, and the list starts
+// right after the last item returned by that call, then offset is applied
+// from there. It also returns the cursor to fetch the next page, which is
+// empty if there are no more items, and the total number of items, which is
+// only counted when cursor is empty, as it requires scanning all of them.
+func (idx *Indexer) AccountFeed(limit, offset int, cursor string, address []byte) (
+	[]*indexertypes.AccountFeedItem, string, uint64, error,
+) {
+	if offset < 0 {
+		return nil, "", 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
+	}
+	if limit <= 0 {
+		return nil, "", 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
+	}
+	params := indexerdb.SearchAccountFeedParams{
+		Limit:   int64(limit) + 1, // fetch one more to know if there is a next page
+		Offset:  int64(offset),
+		Account: address,
+	}
+	if cursor != "" {
+		var feedTime, blockHeight int64
+		var kind, ref string
+		if err := decodeCursor(cursor, &feedTime, &blockHeight, &kind, &ref); err != nil {
+			return nil, "", 0, err
+		}
+		params.CursorTime = feedTime
+		params.CursorBlockHeight = blockHeight
+		params.CursorKind = kind
+		params.CursorRef = ref
+	}
+	results, err := idx.readOnlyQuery.SearchAccountFeed(context.TODO(), params)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	nextCursor := ""
+	if len(results) > limit {
+		results = results[:limit]
+		last := results[limit-1]
+		nextCursor = encodeCursor(last.Time, last.BlockHeight, last.Kind, last.Ref)
+	}
+	list := []*indexertypes.AccountFeedItem{}
+	for _, row := range results {
+		list = append(list, &indexertypes.AccountFeedItem{
+			Kind:       row.Kind,
+			Height:     uint64(row.BlockHeight),
+			Timestamp:  time.Unix(row.Time, 0),
+			TxHash:     nonEmptyBytes(row.TxHash),
+			Account:    nonEmptyBytes(row.Account),
+			ElectionID: nonEmptyBytes(row.ProcessID),
+			VoteID:     nonEmptyBytes(row.Nullifier),
+			Amount:     uint64(row.Amount),
+			TxType:     row.TxType,
+			Reference:  row.Reference,
+		})
+	}
+	if len(results) == 0 || cursor != "" {
+		return list, nextCursor, 0, nil
+	}
+	total, err := idx.readOnlyQuery.CountAccountFeed(context.TODO(), address)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return list, nextCursor, uint64(total), nil
+}
+
 // AccountExists returns whether the passed accountID exists in the db.
 // If passed arg is not the full hex string, returns false (i.e. no substring matching)
 func (idx *Indexer) AccountExists(accountID string) bool {
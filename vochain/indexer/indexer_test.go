@@ -3,25 +3,39 @@ package indexer
 import (
 	"bytes"
 	"context"
+	"encoding/gob"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	stdlog "log"
 	"math/big"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	comettypes "github.com/cometbft/cometbft/types"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/ethereum/go-ethereum/common"
 	qt "github.com/frankban/quicktest"
 	"github.com/pressly/goose/v3"
+	"go.vocdoni.io/dvote/config"
 	"go.vocdoni.io/dvote/crypto/ethereum"
 	"go.vocdoni.io/dvote/crypto/nacl"
+	"go.vocdoni.io/dvote/data"
 	"go.vocdoni.io/dvote/log"
 	"go.vocdoni.io/dvote/test/testcommon/testvoteproof"
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/util"
 	"go.vocdoni.io/dvote/vochain"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
 	"go.vocdoni.io/dvote/vochain/results"
+	"go.vocdoni.io/dvote/vochain/results/resultsverify"
 	"go.vocdoni.io/dvote/vochain/state"
 	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
@@ -119,6 +133,78 @@ func TestBackup(t *testing.T) {
 	idx.Close()
 }
 
+func TestEncryption(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	dataDir := t.TempDir()
+
+	idx, err := New(app, Options{DataDir: dataDir, EncryptionKey: "key1"})
+	if errors.Is(err, ErrEncryptionUnsupported) {
+		// the unencrypted databases cannot be re-encrypted either
+		idx, err := New(app, Options{DataDir: t.TempDir()})
+		qt.Assert(t, err, qt.IsNil)
+		defer idx.Close()
+		err = idx.SaveEncryptedBackup(context.TODO(), filepath.Join(t.TempDir(), "backup"), "key1")
+		qt.Assert(t, err, qt.ErrorIs, ErrEncryptionUnsupported)
+		t.Skip("sqlite is not built with SQLCipher")
+	}
+	qt.Assert(t, err, qt.IsNil)
+	pid := util.RandomBytes(32)
+	qt.Assert(t, app.State.AddProcess(&models.Process{
+		ProcessId:    pid,
+		EnvelopeType: &models.EnvelopeType{},
+		Status:       models.ProcessStatus_READY,
+		Mode:         &models.ProcessMode{AutoStart: true},
+		BlockCount:   10,
+		VoteOptions:  &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+	qt.Assert(t, idx.CountTotalProcesses(), qt.Equals, uint64(1))
+
+	// the database can only be opened with the new key once rotated
+	qt.Assert(t, idx.RotateEncryptionKey(context.TODO(), "key2"), qt.IsNil)
+	qt.Assert(t, idx.CountTotalProcesses(), qt.Equals, uint64(1))
+	qt.Assert(t, idx.Close(), qt.IsNil)
+	_, err = New(app, Options{DataDir: dataDir, EncryptionKey: "key1"})
+	qt.Assert(t, err, qt.ErrorMatches, "cannot decrypt the indexer database.*")
+	idx, err = New(app, Options{DataDir: dataDir, EncryptionKey: "key2"})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, idx.CountTotalProcesses(), qt.Equals, uint64(1))
+
+	// and it can be decrypted back
+	qt.Assert(t, idx.RotateEncryptionKey(context.TODO(), ""), qt.IsNil)
+	qt.Assert(t, idx.Close(), qt.IsNil)
+	idx, err = New(app, Options{DataDir: dataDir})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, idx.CountTotalProcesses(), qt.Equals, uint64(1))
+	qt.Assert(t, idx.Close(), qt.IsNil)
+}
+
+func TestReplaceDB(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx, err := New(app, Options{DataDir: t.TempDir(), BackupTo: DirBackupStore(t.TempDir())})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, app.State.AddProcess(&models.Process{
+		ProcessId:    util.RandomBytes(32),
+		EnvelopeType: &models.EnvelopeType{EncryptedVotes: false},
+		Status:       models.ProcessStatus_READY,
+		Mode:         &models.ProcessMode{AutoStart: true},
+		BlockCount:   10,
+		VoteOptions:  &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// the database is replaced as RotateEncryptionKey does, without SQLCipher
+	backupPath := filepath.Join(t.TempDir(), "backup")
+	qt.Assert(t, idx.SaveBackup(context.TODO(), backupPath), qt.IsNil)
+	qt.Assert(t, idx.replaceDB(backupPath, ""), qt.IsNil)
+	qt.Assert(t, idx.CountTotalProcesses(), qt.Equals, uint64(1))
+
+	// the background workers are started again, and can be stopped by Close
+	qt.Assert(t, idx.stopBackfills, qt.IsNotNil)
+	qt.Assert(t, idx.stopBackups, qt.IsNotNil)
+	qt.Assert(t, idx.Close(), qt.IsNil)
+}
+
 func TestEntityList(t *testing.T) {
 	for _, count := range []int{2, 100, 155} {
 		t.Run(fmt.Sprintf("count=%03d", count), func(t *testing.T) {
@@ -332,7 +418,7 @@ func testProcessList(t *testing.T, procsCount int) {
 	for len(procs) < procsCount {
 		fmt.Printf("%x\n", eidProcsCount)
 		fmt.Printf("%s\n", hex.EncodeToString(eidProcsCount))
-		list, total, err := idx.ProcessList(10, last, hex.EncodeToString(eidProcsCount), "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+		list, total, err := idx.ProcessList(10, last, hex.EncodeToString(eidProcsCount), "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -351,7 +437,7 @@ func testProcessList(t *testing.T, procsCount int) {
 	}
 	qt.Assert(t, procs, qt.HasLen, procsCount)
 
-	_, total, err := idx.ProcessList(64, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	_, total, err := idx.ProcessList(64, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	qt.Assert(t, err, qt.IsNil)
 	qt.Assert(t, total, qt.Equals, uint64(10+procsCount))
 
@@ -369,7 +455,7 @@ func testProcessList(t *testing.T, procsCount int) {
 	qt.Assert(t, countEntityProcs([]byte("not an entity id that exists")), qt.Equals, int64(-1))
 
 	// Past the end (from=10000) should return an empty list
-	emptyList, _, err := idx.ProcessList(64, 10000, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	emptyList, _, err := idx.ProcessList(64, 10000, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	qt.Assert(t, err, qt.IsNil)
 	qt.Assert(t, emptyList, qt.DeepEquals, [][]byte{})
 }
@@ -459,7 +545,7 @@ func TestProcessSearch(t *testing.T) {
 	app.AdvanceTestBlock()
 
 	// Exact process search
-	list, _, err := idx.ProcessList(10, 0, hex.EncodeToString(eidTest), pidExact, 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	list, _, err := idx.ProcessList(10, 0, hex.EncodeToString(eidTest), pidExact, 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -468,7 +554,7 @@ func TestProcessSearch(t *testing.T) {
 	}
 	// Exact process search, with it being encrypted.
 	// This once caused a sqlite bug due to a mistake in the SQL query.
-	list, _, err = idx.ProcessList(10, 0, hex.EncodeToString(eidTest), pidExactEncrypted, 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	list, _, err = idx.ProcessList(10, 0, hex.EncodeToString(eidTest), pidExactEncrypted, 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -477,7 +563,7 @@ func TestProcessSearch(t *testing.T) {
 	}
 	// Search for nonexistent process
 	list, _, err = idx.ProcessList(10, 0, hex.EncodeToString(eidTest),
-		"4011d50537fa164b6fef261141797bbe4014526f", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+		"4011d50537fa164b6fef261141797bbe4014526f", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -486,7 +572,7 @@ func TestProcessSearch(t *testing.T) {
 	}
 	// Search containing part of all manually-defined processes
 	list, _, err = idx.ProcessList(10, 0, hex.EncodeToString(eidTest),
-		"011d50537fa164b6fef261141797bbe4014526e", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+		"011d50537fa164b6fef261141797bbe4014526e", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -495,7 +581,7 @@ func TestProcessSearch(t *testing.T) {
 	}
 
 	list, _, err = idx.ProcessList(100, 0, hex.EncodeToString(eidTest),
-		"0c6ca22d2c175a1fbdd15d7595ae532bb1094b5", 0, 0, models.ProcessStatus_ENDED, nil, nil, nil, nil, nil, nil, nil)
+		"0c6ca22d2c175a1fbdd15d7595ae532bb1094b5", 0, 0, models.ProcessStatus_ENDED, nil, nil, nil, nil, nil, nil, nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -504,17 +590,17 @@ func TestProcessSearch(t *testing.T) {
 	}
 
 	// Partial process search as uppercase hex
-	list, _, err = idx.ProcessList(10, 0, hex.EncodeToString(eidTest), "011D50537FA164B6FEF261141797BBE4014526E", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	list, _, err = idx.ProcessList(10, 0, hex.EncodeToString(eidTest), "011D50537FA164B6FEF261141797BBE4014526E", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	qt.Assert(t, err, qt.IsNil)
 	qt.Assert(t, list, qt.HasLen, len(processIds))
 	// Partial process search as mixed case hex
-	list, _, err = idx.ProcessList(10, 0, hex.EncodeToString(eidTest), "011D50537fA164B6FeF261141797BbE4014526E", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	list, _, err = idx.ProcessList(10, 0, hex.EncodeToString(eidTest), "011D50537fA164B6FeF261141797BbE4014526E", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	qt.Assert(t, err, qt.IsNil)
 	qt.Assert(t, list, qt.HasLen, len(processIds))
 
 	// Search with an exact Entity ID, but starting with a null byte.
 	// This can trip up sqlite, as it assumes TEXT strings are NUL-terminated.
-	list, _, err = idx.ProcessList(100, 0, "\x00foobar", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	list, _, err = idx.ProcessList(100, 0, "\x00foobar", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -523,12 +609,12 @@ func TestProcessSearch(t *testing.T) {
 	}
 
 	// list all processes, with a max of 10
-	list, _, err = idx.ProcessList(10, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	list, _, err = idx.ProcessList(10, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	qt.Assert(t, err, qt.IsNil)
 	qt.Assert(t, list, qt.HasLen, 10)
 
 	// list all processes, with a max of 1000
-	list, _, err = idx.ProcessList(1000, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	list, _, err = idx.ProcessList(1000, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	qt.Assert(t, err, qt.IsNil)
 	qt.Assert(t, list, qt.HasLen, 21)
 }
@@ -577,33 +663,126 @@ func TestProcessListWithNamespaceAndStatus(t *testing.T) {
 	app.AdvanceTestBlock()
 
 	// Get the process list for namespace 123
-	list, _, err := idx.ProcessList(100, 0, hex.EncodeToString(eid20), "", 123, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	list, _, err := idx.ProcessList(100, 0, hex.EncodeToString(eid20), "", 123, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	qt.Assert(t, err, qt.IsNil)
 	// Check there are exactly 10
 	qt.Assert(t, len(list), qt.CmpEquals(), 10)
 
 	// Get the process list for all namespaces
-	list, _, err = idx.ProcessList(100, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	list, _, err = idx.ProcessList(100, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	qt.Assert(t, err, qt.IsNil)
 	// Check there are exactly 10 + 10
 	qt.Assert(t, len(list), qt.CmpEquals(), 20)
 
 	// Get the process list for namespace 10
-	list, _, err = idx.ProcessList(100, 0, "", "", 10, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	list, _, err = idx.ProcessList(100, 0, "", "", 10, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	qt.Assert(t, err, qt.IsNil)
 	// Check there is exactly 1
 	qt.Assert(t, len(list), qt.CmpEquals(), 1)
 
 	// Get the process list for namespace 10
-	list, _, err = idx.ProcessList(100, 0, "", "", 0, 0, models.ProcessStatus_READY, nil, nil, nil, nil, nil, nil, nil)
+	list, _, err = idx.ProcessList(100, 0, "", "", 0, 0, models.ProcessStatus_READY, nil, nil, nil, nil, nil, nil, nil, 0)
 	qt.Assert(t, err, qt.IsNil)
 	// Check there is exactly 1
 	qt.Assert(t, len(list), qt.CmpEquals(), 10)
 }
 
+func TestProcessListUnlisted(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	// two entities, each one with a listed and an unlisted process
+	eids := [][]byte{util.RandomBytes(20), util.RandomBytes(20)}
+	var unlisted [][]byte
+	for _, eid := range eids {
+		for _, isUnlisted := range []bool{false, true} {
+			pid := util.RandomBytes(32)
+			mode := &models.ProcessMode{AutoStart: true}
+			vochaintx.SetProcessModeUnlisted(mode, isUnlisted)
+			qt.Assert(t, app.State.AddProcess(&models.Process{
+				ProcessId:     pid,
+				EntityId:      eid,
+				VoteOptions:   &models.ProcessVoteOptions{MaxCount: 8, MaxValue: 3},
+				EnvelopeType:  &models.EnvelopeType{},
+				Mode:          mode,
+				Status:        models.ProcessStatus_READY,
+				MaxCensusSize: 1000,
+			}), qt.IsNil)
+			if isUnlisted {
+				unlisted = append(unlisted, pid)
+			}
+		}
+	}
+	app.AdvanceTestBlock()
+
+	// the unlisted processes are not listed
+	list, _, err := idx.ProcessList(100, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, list, qt.HasLen, 2)
+	list, _, err = idx.ProcessList(100, 0, hex.EncodeToString(eids[0]), "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, list, qt.HasLen, 1)
+
+	// except the ones of the given entity
+	list, _, total, err := idx.ProcessListWithCursor(100, 0, "", "", "", 0, 0, 0,
+		nil, nil, nil, nil, nil, nil, nil, 0, eids[0])
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, list, qt.HasLen, 3)
+	qt.Assert(t, total, qt.Equals, uint64(3))
+	qt.Assert(t, list, qt.Any(qt.DeepEquals), unlisted[0])
+	qt.Assert(t, list, qt.Not(qt.Any(qt.DeepEquals)), unlisted[1])
+
+	// and they can be fetched by ID
+	proc, err := idx.ProcessInfo(unlisted[1])
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, proc.Unlisted, qt.IsTrue)
+	qt.Assert(t, vochaintx.ProcessModeUnlisted(proc.Mode), qt.IsTrue)
+}
+
+func TestNullifierGroupProcesses(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	// two processes of the group and one out of it
+	eid := util.RandomBytes(20)
+	group := []byte("primaries")
+	var grouped [][]byte
+	for i := range 3 {
+		pid := util.RandomBytes(32)
+		mode := &models.ProcessMode{AutoStart: true}
+		if i < 2 {
+			vochaintx.SetProcessModeNullifierGroup(mode, group)
+			grouped = append(grouped, pid)
+		}
+		qt.Assert(t, app.State.AddProcess(&models.Process{
+			ProcessId:     pid,
+			EntityId:      eid,
+			VoteOptions:   &models.ProcessVoteOptions{MaxCount: 8, MaxValue: 3},
+			EnvelopeType:  &models.EnvelopeType{Anonymous: true},
+			Mode:          mode,
+			Status:        models.ProcessStatus_READY,
+			MaxCensusSize: 1000,
+		}), qt.IsNil)
+		app.AdvanceTestBlock()
+	}
+
+	list, err := idx.NullifierGroupProcesses(eid, group)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, list, qt.ContentEquals, grouped)
+	list, err = idx.NullifierGroupProcesses(util.RandomBytes(20), group)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, list, qt.HasLen, 0)
+
+	proc, err := idx.ProcessInfo(grouped[0])
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, []byte(proc.NullifierGroup), qt.DeepEquals, group)
+}
+
 func TestResults(t *testing.T) {
 	app := vochain.TestBaseApplication(t)
 	idx := newTestIndexer(t, app)
+	listener := &resultsListener{}
+	idx.AddEventListener(listener)
 
 	keys, root, proofs := testvoteproof.CreateKeysAndBuildCensus(t, 30)
 	pid := util.RandomBytes(32)
@@ -697,6 +876,11 @@ func TestResults(t *testing.T) {
 	// Update the process
 	app.AdvanceTestBlock()
 
+	// the listeners are notified of the final results
+	qt.Assert(t, listener.processes, qt.HasLen, 1)
+	qt.Assert(t, []byte(listener.processes[0].ID), qt.DeepEquals, pid)
+	qt.Assert(t, listener.results[0].Votes, qt.DeepEquals, r.Votes)
+
 	// VoteList with a limit
 	envelopes, _, err := idx.VoteList(10, 0, hex.EncodeToString(pid), "")
 	qt.Assert(t, err, qt.IsNil)
@@ -783,6 +967,63 @@ func TestResults(t *testing.T) {
 			}
 		}
 	}
+
+	// The results proof is generated on the next block, once the results are committed
+	_, err = idx.ResultsProof(pid)
+	qt.Assert(t, err, qt.ErrorIs, ErrResultsProofNotFound)
+	app.AdvanceTestBlock()
+	bundle, err := idx.ResultsProof(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, bundle.Results[0][1].String(), qt.Equals, "30")
+	qt.Assert(t, bundle.EncryptionKeys, qt.HasLen, 1)
+	qt.Assert(t, bundle.EncryptionKeys[0].Index, qt.Equals, 1)
+
+	// The bundle is verified after a JSON round trip, as served by the API
+	bundleJSON, err := json.Marshal(bundle)
+	qt.Assert(t, err, qt.IsNil)
+	var received resultsverify.Bundle
+	qt.Assert(t, json.Unmarshal(bundleJSON, &received), qt.IsNil)
+	blockHash := app.GetBlockByHeight(bundle.Height).Hash()
+	process, err := received.Verify(blockHash)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, process.ProcessId, qt.DeepEquals, pid)
+
+	// Tampered bundles are rejected
+	received.Results[0][1] = new(types.BigInt).SetUint64(29)
+	_, err = received.Verify(blockHash)
+	qt.Assert(t, err, qt.ErrorMatches, "results question 0 option 1 do not match")
+	qt.Assert(t, json.Unmarshal(bundleJSON, &received), qt.IsNil)
+	received.CensusRoot = util.RandomBytes(32)
+	_, err = received.Verify(blockHash)
+	qt.Assert(t, err, qt.ErrorMatches, "census root .* does not match .*")
+	qt.Assert(t, json.Unmarshal(bundleJSON, &received), qt.IsNil)
+	received.Process[len(received.Process)-1] ^= 1
+	_, err = received.Verify(blockHash)
+	qt.Assert(t, err, qt.ErrorMatches, "invalid election proof: .*")
+	qt.Assert(t, json.Unmarshal(bundleJSON, &received), qt.IsNil)
+	_, err = received.Verify(util.RandomBytes(32))
+	qt.Assert(t, err, qt.ErrorMatches, "header hash .* does not match the trusted block hash .*")
+
+	// The recount from the state matches the stored results
+	recount, err := idx.Recount(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, recount.Discrepancies, qt.HasLen, 0)
+	qt.Assert(t, recount.Votes[0][1].String(), qt.Equals, "30")
+	qt.Assert(t, recount.Weight.String(), qt.Equals, "30")
+
+	// Until the stored results are altered
+	proc.ResultsVotes[2][1] = new(types.BigInt).SetUint64(29)
+	_, err = indexerdb.New(idx.readWriteDB).SetProcessResultsReady(context.Background(), indexerdb.SetProcessResultsReadyParams{
+		ID:          pid,
+		Votes:       indexertypes.EncodeJSON(proc.ResultsVotes),
+		Weight:      indexertypes.EncodeJSON(proc.ResultsWeight),
+		BlockHeight: int64(proc.ResultsBlockHeight),
+		EndDate:     proc.EndDate,
+	})
+	qt.Assert(t, err, qt.IsNil)
+	recount, err = idx.Recount(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, recount.Discrepancies, qt.DeepEquals, []string{"question 2 option 1: stored 29, recounted 30"})
 }
 
 func TestLiveResults(t *testing.T) {
@@ -1432,259 +1673,2391 @@ func TestTxIndexer(t *testing.T) {
 	txs, _, err = idx.SearchTransactions(1, 5, 0, "", "", "", "")
 	qt.Assert(t, err, qt.IsNil)
 	qt.Assert(t, txs, qt.HasLen, 1)
+
+	// walk all the transactions using the cursor, while new ones are being added
+	seen := make(map[string]bool)
+	cursor := ""
+	for page := 0; ; page++ {
+		txs, next, total, err := idx.SearchTransactionsWithCursor(7, 0, cursor, 0, "", "", "", "")
+		qt.Assert(t, err, qt.IsNil)
+		if cursor == "" {
+			qt.Assert(t, total, qt.Equals, uint64(totalTxs))
+		} else {
+			// the pages fetched by cursor do not count the transactions
+			qt.Assert(t, total, qt.Equals, uint64(0))
+		}
+		for _, tx := range txs {
+			qt.Assert(t, seen[tx.Hash.String()], qt.IsFalse)
+			seen[tx.Hash.String()] = true
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+		idx.OnNewTx(&vochaintx.Tx{
+			TxID:        getTxID(totalBlocks, page),
+			TxModelType: "setAccount",
+			Tx:          &models.Tx{Payload: &models.Tx_SetAccount{}},
+		}, uint32(totalBlocks), int32(page))
+		qt.Assert(t, idx.Commit(0), qt.IsNil)
+	}
+	qt.Assert(t, seen, qt.HasLen, totalTxs)
+
+	_, _, _, err = idx.SearchTransactionsWithCursor(7, 0, "invalid", 0, "", "", "", "")
+	qt.Assert(t, err, qt.ErrorIs, ErrInvalidCursor)
 }
 
-func TestCensusUpdate(t *testing.T) {
+func TestTransactionReceipt(t *testing.T) {
 	app := vochain.TestBaseApplication(t)
 	idx := newTestIndexer(t, app)
 
-	originalCensusRoot := util.RandomBytes(32)
-	originalCensusURI := new(string)
-	*originalCensusURI = "ipfs://1234"
+	from := common.BytesToAddress(util.RandomBytes(20))
+	to := common.BytesToAddress(util.RandomBytes(20))
 	pid := util.RandomBytes(32)
+	nullifier := util.RandomBytes(32)
+	events := []vochaintx.Event{
+		vochaintx.NewVoteAcceptedEvent(pid, nullifier, "1"),
+		vochaintx.NewTokensTransferredEvent(from, to, 5),
+	}
+	tx := &vochaintx.Tx{
+		TxID:        [32]byte{1},
+		TxModelType: "vote",
+		Tx:          &models.Tx{Payload: &models.Tx_Vote{}},
+	}
+	idx.OnNewTx(tx, 1, 0)
+	idx.OnTxReceipt(tx, &vochaintx.Receipt{Cost: 3, Events: events}, 0)
+	// a transaction indexed without receipt
+	oldTx := &vochaintx.Tx{
+		TxID:        [32]byte{2},
+		TxModelType: "setAccount",
+		Tx:          &models.Tx{Payload: &models.Tx_SetAccount{}},
+	}
+	idx.OnNewTx(oldTx, 1, 1)
+	qt.Assert(t, idx.Commit(1), qt.IsNil)
+
+	receipt, err := idx.GetTransactionReceipt(tx.TxID[:])
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, receipt.Status, qt.Equals, indexertypes.TransactionStatusSuccess)
+	qt.Assert(t, receipt.TxType, qt.Equals, "vote")
+	qt.Assert(t, receipt.BlockHeight, qt.Equals, uint32(1))
+	qt.Assert(t, receipt.GasUsed, qt.Equals, uint64(3))
+	qt.Assert(t, receipt.Events, qt.DeepEquals, events)
+	qt.Assert(t, receipt.Events[0].Attribute("nullifier"), qt.Equals, hex.EncodeToString(nullifier))
+
+	receipt, err = idx.GetTransactionReceipt(oldTx.TxID[:])
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, receipt.GasUsed, qt.Equals, uint64(0))
+	qt.Assert(t, receipt.Events, qt.IsNil)
+
+	_, err = idx.GetTransactionReceipt(util.RandomBytes(32))
+	qt.Assert(t, err, qt.ErrorIs, ErrTransactionNotFound)
+}
 
-	if err := app.State.AddProcess(&models.Process{
+func TestIndexerStatus(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	dataDir := t.TempDir()
+	idx, err := New(app, Options{DataDir: dataDir})
+	qt.Assert(t, err, qt.IsNil)
+
+	pid := util.RandomBytes(32)
+	err = app.State.AddProcess(&models.Process{
 		ProcessId:     pid,
-		EnvelopeType:  &models.EnvelopeType{EncryptedVotes: false},
+		EnvelopeType:  &models.EnvelopeType{},
 		Status:        models.ProcessStatus_READY,
+		Mode:          &models.ProcessMode{AutoStart: true},
 		BlockCount:    10,
-		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 100},
-		Mode:          &models.ProcessMode{AutoStart: true, DynamicCensus: true},
 		MaxCensusSize: 1000,
-		CensusRoot:    originalCensusRoot,
-		CensusURI:     originalCensusURI,
-		CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
-	}); err != nil {
-		t.Fatal(err)
-	}
-	app.AdvanceTestBlock()
-
-	// get created process
-	proc, err := idx.ProcessInfo(pid)
-	qt.Assert(t, err, qt.IsNil)
-	// check the census root is correct
-	qt.Assert(t, proc.CensusRoot, qt.DeepEquals, types.HexBytes(originalCensusRoot))
-	// check the census uri is correct
-	qt.Assert(t, proc.CensusURI, qt.DeepEquals, *originalCensusURI)
-
-	// send SET_PROCESS_CENSUS_UPDATE
-	newCensusRoot := util.RandomBytes(32)
-	newCensusURI := new(string)
-	*newCensusURI = "ipfs://5678"
-	if err := app.State.SetProcessCensus(pid, newCensusRoot, *newCensusURI, 0, true); err != nil {
-		t.Fatal(err)
-	}
-
-	// advance block
-	app.AdvanceTestBlock()
-	// get process again
-	proc, err = idx.ProcessInfo(pid)
+	})
 	qt.Assert(t, err, qt.IsNil)
-	// check the census root is correct
-	qt.Assert(t, proc.CensusRoot, qt.DeepEquals, types.HexBytes(newCensusRoot))
-	// check the census uri is correct
-	qt.Assert(t, proc.CensusURI, qt.DeepEquals, *newCensusURI)
+	app.AdvanceTestBlocksUntilHeight(3)
+
+	status := idx.Status()
+	qt.Assert(t, status.Height, qt.Equals, uint32(2))
+	qt.Assert(t, status.AppHeight, qt.Equals, app.Height())
+	qt.Assert(t, status.Lag, qt.Equals, app.Height()-2)
+	qt.Assert(t, status.LiveProcesses, qt.Equals, 1)
+	qt.Assert(t, status.PendingBlockWrites, qt.Equals, int64(0))
+	qt.Assert(t, status.DBSize > 0, qt.IsTrue)
+	qt.Assert(t, status.WALSize > 0, qt.IsTrue)
+
+	// the writes of the block being indexed are pending until its commit
+	idx.OnNewTx(&vochaintx.Tx{
+		TxID:        [32]byte{1},
+		TxModelType: "setAccount",
+		Tx:          &models.Tx{Payload: &models.Tx_SetAccount{}},
+	}, 3, 0)
+	qt.Assert(t, idx.Status().PendingBlockWrites > 0, qt.IsTrue)
+	idx.Rollback()
+	qt.Assert(t, idx.Status().PendingBlockWrites, qt.Equals, int64(0))
+
+	// the height indexed is kept after a restart
+	qt.Assert(t, idx.Close(), qt.IsNil)
+	idx, err = New(app, Options{DataDir: dataDir})
+	qt.Assert(t, err, qt.IsNil)
+	t.Cleanup(func() { qt.Check(t, idx.Close(), qt.IsNil) })
+	qt.Assert(t, idx.Status().Height, qt.Equals, uint32(2))
 }
 
-func TestEndProcess(t *testing.T) {
+func TestEstimateCounts(t *testing.T) {
 	app := vochain.TestBaseApplication(t)
-	idx := newTestIndexer(t, app)
+	idx, err := New(app, Options{DataDir: t.TempDir(), EstimateCounts: true})
+	qt.Assert(t, err, qt.IsNil)
+	t.Cleanup(func() { qt.Check(t, idx.Close(), qt.IsNil) })
+	qt.Assert(t, idx.CountsEstimated(), qt.IsTrue)
 
 	pid := util.RandomBytes(32)
-
-	if err := app.State.AddProcess(&models.Process{
+	err = app.State.AddProcess(&models.Process{
 		ProcessId:     pid,
-		EnvelopeType:  &models.EnvelopeType{EncryptedVotes: false},
+		EnvelopeType:  &models.EnvelopeType{},
 		Status:        models.ProcessStatus_READY,
-		StartTime:     0,
-		Duration:      10,
-		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 100},
-		Mode:          &models.ProcessMode{AutoStart: true, Interruptible: true},
+		Mode:          &models.ProcessMode{AutoStart: true},
+		BlockCount:    10,
 		MaxCensusSize: 1000,
-		CensusRoot:    util.RandomBytes(32),
-		CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
-	}); err != nil {
-		t.Fatal(err)
-	}
-	app.AdvanceTestBlocksUntilTimestamp(5)
-
-	// get created process
-	proc, err := idx.ProcessInfo(pid)
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 1},
+	})
 	qt.Assert(t, err, qt.IsNil)
-	// check the end date is correct
-	qt.Assert(t, proc.EndDate.Unix(), qt.Equals, int64(10))
-	// check the status is READY
-	qt.Assert(t, proc.Status, qt.Equals, int32(models.ProcessStatus_READY))
-
-	// advance block and finalize process
 	app.AdvanceTestBlock()
-	err = app.State.SetProcessStatus(pid, models.ProcessStatus_ENDED, true)
-	qt.Assert(t, err, qt.IsNil)
 
+	const votesCount = 30
+	vp, err := state.NewVotePackage([]int{1, 1, 1}).Encode()
+	qt.Assert(t, err, qt.IsNil)
+	for i := 0; i < votesCount; i++ {
+		v := &state.Vote{ProcessID: pid, VotePackage: vp, Nullifier: util.RandomBytes(32)}
+		qt.Assert(t, app.State.AddVote(v), qt.IsNil)
+	}
 	app.AdvanceTestBlock()
 
-	// check the status is ENDED
-	proc, err = idx.ProcessInfo(pid)
+	// filtering by process the count is exact, and without filters it is
+	// an upper bound
+	_, next, total, err := idx.VoteListWithCursor(10, 0, "", hex.EncodeToString(pid), "", nil, nil)
 	qt.Assert(t, err, qt.IsNil)
-	qt.Assert(t, proc.Status, qt.Equals, int32(models.ProcessStatus_ENDED))
+	qt.Assert(t, next, qt.Not(qt.Equals), "")
+	qt.Assert(t, total, qt.Equals, uint64(votesCount))
+	_, _, total, err = idx.VoteListWithCursor(10, 0, "", "", "", nil, nil)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total >= votesCount, qt.IsTrue)
+	// with other filters, it is the minimum count given the page
+	_, _, total, err = idx.VoteListWithCursor(10, 5, "", hex.EncodeToString(pid[:16]), "", nil, nil)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(16))
+	count, err := idx.CountVotes(hex.EncodeToString(pid[:16]), "", nil, nil)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, count, qt.Equals, uint64(votesCount))
 
-	// check manually ended is true
+	const totalBlocks = 5
+	const txsPerBlock = 4
+	for i := 0; i < totalBlocks; i++ {
+		for j := 0; j < txsPerBlock; j++ {
+			txType := "setAccount"
+			if j == 0 {
+				txType = "vote"
+			}
+			idx.OnNewTx(&vochaintx.Tx{
+				TxID:        [32]byte{byte(i), byte(j)},
+				TxModelType: txType,
+				Tx:          &models.Tx{Payload: &models.Tx_SetAccount{}},
+			}, uint32(i), int32(j))
+		}
+	}
+	qt.Assert(t, idx.Commit(0), qt.IsNil)
+
+	_, _, total, err = idx.SearchTransactionsWithCursor(3, 0, "", 0, "", "", "", "")
 	qt.Assert(t, err, qt.IsNil)
-	qt.Assert(t, proc.ManuallyEnded, qt.Equals, true)
+	qt.Assert(t, total, qt.Equals, uint64(totalBlocks*txsPerBlock))
+	_, _, total, err = idx.SearchTransactionsWithCursor(3, 0, "", 2, "", "", "", "")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(txsPerBlock))
+	_, _, total, err = idx.SearchTransactionsWithCursor(2, 0, "", 0, "", "vote", "", "")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(3))
+	_, next, total, err = idx.SearchTransactionsWithCursor(2, 4, "", 0, "", "vote", "", "")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, next, qt.Equals, "")
+	qt.Assert(t, total, qt.Equals, uint64(totalBlocks))
+	count, err = idx.CountTransactions(0, "", "vote", "", "")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, count, qt.Equals, uint64(totalBlocks))
 }
 
-func TestAccountsList(t *testing.T) {
+func TestVoteListTimeRange(t *testing.T) {
 	app := vochain.TestBaseApplication(t)
-	idx := newTestIndexer(t, app)
-
-	keys := make([]*ethereum.SignKeys, 0)
-	for i := 0; i < 25; i++ {
-		key := &ethereum.SignKeys{}
-		qt.Assert(t, key.Generate(), qt.IsNil)
-		keys = append(keys, key)
+	idx, err := New(app, Options{DataDir: t.TempDir()})
+	qt.Assert(t, err, qt.IsNil)
+	t.Cleanup(func() { qt.Check(t, idx.Close(), qt.IsNil) })
 
-		err := app.State.SetAccount(key.Address(), &state.Account{
-			Account: models.Account{
-				Balance: 500,
-				Nonce:   uint32(0),
-				InfoURI: "ipfs://vocdoni.io",
-			},
-		})
-		qt.Assert(t, err, qt.IsNil)
+	pid := util.RandomBytes(32)
+	err = app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		BlockCount:    10,
+		MaxCensusSize: 1000,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 1},
+	})
+	qt.Assert(t, err, qt.IsNil)
+	app.AdvanceTestBlock()
 
+	// three blocks with two votes each, one second apart
+	vp, err := state.NewVotePackage([]int{1, 1, 1}).Encode()
+	qt.Assert(t, err, qt.IsNil)
+	var blockTimes []time.Time
+	for range 3 {
+		for range 2 {
+			v := &state.Vote{ProcessID: pid, VotePackage: vp, Nullifier: util.RandomBytes(32)}
+			qt.Assert(t, app.State.AddVote(v), qt.IsNil)
+		}
+		blockTimes = append(blockTimes, app.GetBlockByHeight(int64(app.Height())).Time)
 		app.AdvanceTestBlock()
 	}
 
-	// Test total count accounts
-	totalAccs, err := idx.CountTotalAccounts()
+	// the votes are listed with the time of their blocks
+	list, _, total, err := idx.VoteListWithCursor(10, 0, "", hex.EncodeToString(pid), "", nil, nil)
 	qt.Assert(t, err, qt.IsNil)
-	qt.Assert(t, totalAccs, qt.CmpEquals(), uint64(25))
-
-	last := 0
-	for i := 0; i < int(totalAccs); i++ {
-		accts, _, err := idx.AccountList(10, last, "")
-		qt.Assert(t, err, qt.IsNil)
-
-		for j, acc := range accts {
-			qt.Assert(t, acc.Address.String(), qt.Equals, hex.EncodeToString(keys[j+last].Address().Bytes()))
-			qt.Assert(t, acc.Nonce, qt.Equals, uint32(0))
-			qt.Assert(t, acc.Balance, qt.Equals, uint64(500))
-		}
-		last += 10
+	qt.Assert(t, total, qt.Equals, uint64(6))
+	for i, v := range list {
+		qt.Assert(t, v.Date, qt.IsNotNil)
+		qt.Assert(t, v.Date.Equal(blockTimes[2-i/2]), qt.IsTrue)
 	}
+	envelope, err := idx.GetEnvelope(list[0].Nullifier)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, envelope.Date.Equal(blockTimes[2]), qt.IsTrue)
 
-	// update an existing account
-	err = app.State.SetAccount(keys[0].Address(), &state.Account{
-		Account: models.Account{
-			Balance: 600,
-			Nonce:   uint32(1),
-			InfoURI: "ipfs://vocdoni.io",
-		},
-	})
+	// the range includes its start, but not its end
+	list, _, total, err = idx.VoteListWithCursor(10, 0, "", hex.EncodeToString(pid), "",
+		&blockTimes[1], &blockTimes[2])
 	qt.Assert(t, err, qt.IsNil)
-	app.AdvanceTestBlock()
+	qt.Assert(t, total, qt.Equals, uint64(2))
+	qt.Assert(t, list, qt.HasLen, 2)
+	for _, v := range list {
+		qt.Assert(t, v.Date.Equal(blockTimes[1]), qt.IsTrue)
+	}
+	_, _, total, err = idx.VoteListWithCursor(10, 0, "", "", "", &blockTimes[1], nil)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(4))
+	count, err := idx.CountVotes(hex.EncodeToString(pid), "", nil, &blockTimes[1])
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, count, qt.Equals, uint64(2))
 
-	// verify the updated balance and nonce
-	accts, _, err := idx.AccountList(5, 0, "")
+	// the times are compared in UTC
+	from := blockTimes[2].In(time.FixedZone("UTC+2", 2*3600))
+	_, _, total, err = idx.VoteListWithCursor(10, 0, "", "", "", &from, nil)
 	qt.Assert(t, err, qt.IsNil)
-	// the account in the position 0 must be the updated account balance due it has the major balance
-	// indexer query has order BY balance DESC
-	qt.Assert(t, accts[0].Address.String(), qt.Equals, hex.EncodeToString(keys[0].Address().Bytes()))
-	qt.Assert(t, accts[0].Nonce, qt.Equals, uint32(1))
-	qt.Assert(t, accts[0].Balance, qt.Equals, uint64(600))
+	qt.Assert(t, total, qt.Equals, uint64(2))
 }
 
-func TestTokenTransfers(t *testing.T) {
+func TestProcessEnvelopes(t *testing.T) {
 	app := vochain.TestBaseApplication(t)
 	idx := newTestIndexer(t, app)
 
-	keys := make([]*ethereum.SignKeys, 0)
-	// create 3 accounts
-	for i := 0; i < 3; i++ {
-		key := &ethereum.SignKeys{}
-		qt.Assert(t, key.Generate(), qt.IsNil)
-		keys = append(keys, key)
+	pid := util.RandomBytes(32)
+	err := app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		BlockCount:    10,
+		MaxCensusSize: 10000,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 1},
+	})
+	qt.Assert(t, err, qt.IsNil)
+	app.AdvanceTestBlock()
 
-		err := app.State.SetAccount(key.Address(), &state.Account{
-			Account: models.Account{
-				Balance: 500,
-				Nonce:   uint32(0),
-				InfoURI: "ipfs://vocdoni.io",
-			},
-		})
+	// more votes than a batch, so that several are read
+	vp, err := state.NewVotePackage([]int{1, 1, 1}).Encode()
+	qt.Assert(t, err, qt.IsNil)
+	nvotes := envelopeStreamBatchSize*2 + 10
+	for range nvotes {
+		v := &state.Vote{ProcessID: pid, VotePackage: vp, Nullifier: util.RandomBytes(32)}
+		qt.Assert(t, app.State.AddVote(v), qt.IsNil)
+	}
+	app.AdvanceTestBlock()
+
+	var nullifiers []types.HexBytes
+	err = idx.ProcessEnvelopes(context.Background(), pid, func(env *indexertypes.EnvelopePackage) error {
+		qt.Assert(t, env.Meta.ProcessId, qt.DeepEquals, types.HexBytes(pid))
+		qt.Assert(t, env.VotePackage, qt.DeepEquals, vp)
+		nullifiers = append(nullifiers, env.Meta.Nullifier)
+		return nil
+	})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, nullifiers, qt.HasLen, nvotes)
+	qt.Assert(t, slices.IsSortedFunc(nullifiers, func(a, b types.HexBytes) int {
+		return bytes.Compare(a, b)
+	}), qt.IsTrue)
+
+	// the iteration stops at the first error
+	errStop := errors.New("stop")
+	count := 0
+	err = idx.ProcessEnvelopes(context.Background(), pid, func(*indexertypes.EnvelopePackage) error {
+		if count++; count == 5 {
+			return errStop
+		}
+		return nil
+	})
+	qt.Assert(t, err, qt.ErrorIs, errStop)
+	qt.Assert(t, count, qt.Equals, 5)
+}
+
+func TestTransactionsBySigner(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	keys := make([]*ethereum.SignKeys, 2)
+	for i := range keys {
+		keys[i] = &ethereum.SignKeys{}
+		qt.Assert(t, keys[i].Generate(), qt.IsNil)
+	}
+	// the first account signs three txs per block and the second one, one
+	const totalBlocks = 4
+	for i := 0; i < totalBlocks; i++ {
+		for j := 0; j < 4; j++ {
+			tx := &models.Tx{Payload: &models.Tx_SetAccount{}}
+			body, err := proto.Marshal(tx)
+			qt.Assert(t, err, qt.IsNil)
+			txType, signer := "setAccount", keys[0]
+			if j == 0 {
+				txType, signer = "vote", keys[1]
+			} else if j == 1 {
+				txType = "vote"
+			}
+			signature, err := signer.SignEthereum(body)
+			qt.Assert(t, err, qt.IsNil)
+			idx.OnNewTx(&vochaintx.Tx{
+				TxID:        [32]byte{byte(i), byte(j)},
+				TxModelType: txType,
+				Tx:          tx,
+				SignedBody:  body,
+				Signature:   signature,
+			}, uint32(i), int32(j))
+		}
+	}
+	qt.Assert(t, idx.Commit(0), qt.IsNil)
+
+	var got []*indexertypes.TransactionMetadata
+	cursor := ""
+	for {
+		txs, next, total, err := idx.TransactionListBySigner(5, 0, cursor, keys[0].Address().Bytes(), "")
 		qt.Assert(t, err, qt.IsNil)
+		if cursor == "" {
+			qt.Assert(t, total, qt.Equals, uint64(3*totalBlocks))
+		} else {
+			qt.Assert(t, total, qt.Equals, uint64(0))
+		}
+		got = append(got, txs...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	qt.Assert(t, got, qt.HasLen, 3*totalBlocks)
+	for i, tx := range got {
+		qt.Assert(t, tx.Signer, qt.DeepEquals, types.HexBytes(keys[0].Address().Bytes()))
+		if i > 0 {
+			qt.Assert(t, tx.BlockHeight*10+uint32(tx.TxBlockIndex) <
+				got[i-1].BlockHeight*10+uint32(got[i-1].TxBlockIndex), qt.IsTrue)
+		}
+	}
 
-		app.AdvanceTestBlock()
+	txs, _, total, err := idx.TransactionListBySigner(10, 0, "", keys[0].Address().Bytes(), "vote")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(totalBlocks))
+	qt.Assert(t, txs, qt.HasLen, totalBlocks)
+
+	// the signer filter of the search returns the same transactions
+	txs, _, total, err = idx.SearchTransactionsWithCursor(10, 0, "", 0, "", "", "", keys[1].Address().Hex()[2:])
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(totalBlocks))
+	for _, tx := range txs {
+		qt.Assert(t, tx.TxBlockIndex, qt.Equals, int32(0))
 	}
+}
 
-	err := app.State.TransferBalance(&vochaintx.TokenTransfer{
-		FromAddress: keys[0].Address(),
-		ToAddress:   keys[2].Address(),
-		Amount:      18,
-		TxHash:      util.RandomBytes(32),
-	}, false)
+func TestProcessListCursor(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	// several processes per block, so they share the same creation time
+	const procsCount = 25
+	for i := 0; i < procsCount; i++ {
+		err := app.State.AddProcess(&models.Process{
+			ProcessId:     util.RandomBytes(32),
+			EntityId:      util.RandomBytes(20),
+			VoteOptions:   &models.ProcessVoteOptions{MaxCount: 8, MaxValue: 3},
+			EnvelopeType:  &models.EnvelopeType{},
+			MaxCensusSize: 1000,
+		})
+		qt.Assert(t, err, qt.IsNil)
+		if i%10 == 9 {
+			app.AdvanceTestBlock()
+		}
+	}
+	app.AdvanceTestBlock()
+
+	expected, total, err := idx.ProcessList(procsCount, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(procsCount))
+
+	var got [][]byte
+	cursor := ""
+	for {
+		pids, next, total, err := idx.ProcessListWithCursor(4, 0, cursor, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0, nil)
+		qt.Assert(t, err, qt.IsNil)
+		if cursor == "" {
+			qt.Assert(t, total, qt.Equals, uint64(procsCount))
+		} else {
+			qt.Assert(t, total, qt.Equals, uint64(0))
+		}
+		got = append(got, pids...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	qt.Assert(t, got, qt.DeepEquals, expected)
+}
+
+func TestCensusUpdate(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	originalCensusRoot := util.RandomBytes(32)
+	originalCensusURI := new(string)
+	*originalCensusURI = "ipfs://1234"
+	pid := util.RandomBytes(32)
 
+	if err := app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{EncryptedVotes: false},
+		Status:        models.ProcessStatus_READY,
+		BlockCount:    10,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 100},
+		Mode:          &models.ProcessMode{AutoStart: true, DynamicCensus: true},
+		MaxCensusSize: 1000,
+		CensusRoot:    originalCensusRoot,
+		CensusURI:     originalCensusURI,
+		CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+	}); err != nil {
+		t.Fatal(err)
+	}
 	app.AdvanceTestBlock()
 
-	err = app.State.TransferBalance(&vochaintx.TokenTransfer{
-		FromAddress: keys[1].Address(),
-		ToAddress:   keys[2].Address(),
-		Amount:      95,
-		TxHash:      util.RandomBytes(32),
-	}, false)
+	// get created process
+	proc, err := idx.ProcessInfo(pid)
+	qt.Assert(t, err, qt.IsNil)
+	// check the census root is correct
+	qt.Assert(t, proc.CensusRoot, qt.DeepEquals, types.HexBytes(originalCensusRoot))
+	// check the census uri is correct
+	qt.Assert(t, proc.CensusURI, qt.DeepEquals, *originalCensusURI)
+
+	// the history holds the original census until it is updated
+	history, err := idx.ProcessCensusHistory(pid)
 	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, history, qt.HasLen, 1)
+	qt.Assert(t, history[0].Root, qt.DeepEquals, types.HexBytes(originalCensusRoot))
+
+	// send SET_PROCESS_CENSUS_UPDATE
+	newCensusRoot := util.RandomBytes(32)
+	newCensusURI := new(string)
+	*newCensusURI = "ipfs://5678"
+	if err := app.State.SetProcessCensus(pid, newCensusRoot, *newCensusURI, 0, true); err != nil {
+		t.Fatal(err)
+	}
 
+	// advance block
 	app.AdvanceTestBlock()
+	// get process again
+	proc, err = idx.ProcessInfo(pid)
+	qt.Assert(t, err, qt.IsNil)
+	// check the census root is correct
+	qt.Assert(t, proc.CensusRoot, qt.DeepEquals, types.HexBytes(newCensusRoot))
+	// check the census uri is correct
+	qt.Assert(t, proc.CensusURI, qt.DeepEquals, *newCensusURI)
 
-	err = app.State.TransferBalance(&vochaintx.TokenTransfer{
-		FromAddress: keys[2].Address(),
-		ToAddress:   keys[1].Address(),
-		Amount:      5,
-		TxHash:      util.RandomBytes(32),
-	}, false)
+	// check the census history keeps both versions
+	history, err = idx.ProcessCensusHistory(pid)
 	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, history, qt.HasLen, 2)
+	qt.Assert(t, history[0].Root, qt.DeepEquals, types.HexBytes(originalCensusRoot))
+	qt.Assert(t, history[0].URI, qt.Equals, *originalCensusURI)
+	qt.Assert(t, history[0].Size, qt.Equals, uint64(1000))
+	qt.Assert(t, history[0].Height, qt.Equals, uint32(0))
+	qt.Assert(t, history[1].Root, qt.DeepEquals, types.HexBytes(newCensusRoot))
+	qt.Assert(t, history[1].URI, qt.Equals, *newCensusURI)
+	qt.Assert(t, history[1].Height, qt.Equals, app.Height()-1)
+	stateHistory, err := app.State.ProcessCensusHistory(pid, true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, stateHistory, qt.HasLen, 2)
+	qt.Assert(t, []byte(stateHistory[1].Root), qt.DeepEquals, newCensusRoot)
 
+	// the census size updates are versions too
+	qt.Assert(t, app.State.SetProcessCensus(pid, nil, "", 2000, true), qt.IsNil)
 	app.AdvanceTestBlock()
+	history, err = idx.ProcessCensusHistory(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, history, qt.HasLen, 3)
+	qt.Assert(t, history[2].Root, qt.DeepEquals, types.HexBytes(newCensusRoot))
+	qt.Assert(t, history[2].Size, qt.Equals, uint64(2000))
+}
 
-	// acct 1 must have only one token transfer received
-	acc1Tokentx, _, err := idx.TokenTransfersList(10, 0, "", "", hex.EncodeToString(keys[1].Address().Bytes()))
+func TestEndProcess(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	pid := util.RandomBytes(32)
+
+	if err := app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{EncryptedVotes: false},
+		Status:        models.ProcessStatus_READY,
+		StartTime:     0,
+		Duration:      10,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 100},
+		Mode:          &models.ProcessMode{AutoStart: true, Interruptible: true},
+		MaxCensusSize: 1000,
+		CensusRoot:    util.RandomBytes(32),
+		CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	app.AdvanceTestBlocksUntilTimestamp(5)
+
+	// get created process
+	proc, err := idx.ProcessInfo(pid)
 	qt.Assert(t, err, qt.IsNil)
-	qt.Assert(t, len(acc1Tokentx), qt.Equals, 1)
-	qt.Assert(t, acc1Tokentx[0].Amount, qt.Equals, uint64(5))
+	// check the end date is correct
+	qt.Assert(t, proc.EndDate.Unix(), qt.Equals, int64(10))
+	// check the status is READY
+	qt.Assert(t, proc.Status, qt.Equals, int32(models.ProcessStatus_READY))
 
-	// acct 2 must two token transfers received
-	acc2Tokentx, _, err := idx.TokenTransfersList(10, 0, "", "", hex.EncodeToString(keys[2].Address().Bytes()))
+	// advance block and finalize process
+	app.AdvanceTestBlock()
+	err = app.State.SetProcessStatus(pid, models.ProcessStatus_ENDED, true)
 	qt.Assert(t, err, qt.IsNil)
-	qt.Assert(t, len(acc2Tokentx), qt.Equals, 2)
-	qt.Assert(t, acc2Tokentx[0].Amount, qt.Equals, uint64(95))
-	qt.Assert(t, acc2Tokentx[1].Amount, qt.Equals, uint64(18))
 
-	// acct 0 must zero token transfers received
-	acc0Tokentx, _, err := idx.TokenTransfersList(10, 0, "", "", hex.EncodeToString(keys[0].Address().Bytes()))
+	app.AdvanceTestBlock()
+
+	// check the status is ENDED
+	proc, err = idx.ProcessInfo(pid)
 	qt.Assert(t, err, qt.IsNil)
-	qt.Assert(t, len(acc0Tokentx), qt.Equals, 0)
+	qt.Assert(t, proc.Status, qt.Equals, int32(models.ProcessStatus_ENDED))
 
-	// acct 1 must have two token transfer received or sent
-	acc1TokentxFromOrTo, _, err := idx.TokenTransfersList(10, 0, hex.EncodeToString(keys[1].Address().Bytes()), "", "")
+	// check manually ended is true
 	qt.Assert(t, err, qt.IsNil)
-	qt.Assert(t, len(acc1TokentxFromOrTo), qt.Equals, 2)
-	qt.Assert(t, acc1TokentxFromOrTo[0].Amount, qt.Equals, uint64(5))
-	qt.Assert(t, acc1TokentxFromOrTo[1].Amount, qt.Equals, uint64(95))
+	qt.Assert(t, proc.ManuallyEnded, qt.Equals, true)
 }
 
-// friendlyResults translates votes into a matrix of strings
-func friendlyResults(votes [][]*types.BigInt) [][]string {
-	r := [][]string{}
-	for i := range votes {
-		r = append(r, []string{})
-		for j := range votes[i] {
-			n, err := votes[i][j].MarshalText()
-			if err != nil {
-				panic(err)
-			}
-			r[i] = append(r[i], string(n))
+func TestAuditProcess(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	pid := util.RandomBytes(32)
+	qt.Assert(t, app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{EncryptedVotes: false},
+		Status:        models.ProcessStatus_READY,
+		Mode:          &models.ProcessMode{AutoStart: true, Interruptible: true},
+		BlockCount:    100,
+		MaxCensusSize: 1000,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 1},
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	vp, err := state.NewVotePackage([]int{1, 1, 1}).Encode()
+	qt.Assert(t, err, qt.IsNil)
+	var nullifiers [][]byte
+	for range 10 {
+		nullifiers = append(nullifiers, util.RandomBytes(32))
+		qt.Assert(t, app.State.AddVote(&state.Vote{ProcessID: pid, VotePackage: vp, Nullifier: nullifiers[len(nullifiers)-1]}), qt.IsNil)
+	}
+	app.AdvanceTestBlock()
+	qt.Assert(t, app.State.SetProcessStatus(pid, models.ProcessStatus_ENDED, true), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	qt.Assert(t, idx.anomalyCount.Load(), qt.Equals, int64(0))
+
+	// the process is audited on the block after it ends, with a vote missing from the index
+	_, err = idx.readWriteDB.Exec("DELETE FROM votes WHERE nullifier = ?", nullifiers[0])
+	qt.Assert(t, err, qt.IsNil)
+	app.AdvanceTestBlock()
+	idx.auditing.Wait()
+	anomalies, err := idx.ProcessAnomalies(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, anomalies, qt.HasLen, 3)
+	qt.Assert(t, anomalies[2].Kind, qt.Equals, AnomalyMissingVote)
+	qt.Assert(t, anomalies[2].Nullifier, qt.DeepEquals, types.HexBytes(nullifiers[0]))
+
+	// and another one with a different nullifier
+	unknown := util.RandomBytes(32)
+	_, err = idx.readWriteDB.Exec("UPDATE votes SET nullifier = ? WHERE nullifier = ?", unknown, nullifiers[1])
+	qt.Assert(t, err, qt.IsNil)
+
+	anomalies, err = idx.AuditProcess(pid)
+	qt.Assert(t, err, qt.IsNil)
+	kinds := make(map[string]int)
+	for _, a := range anomalies {
+		kinds[a.Kind]++
+		qt.Assert(t, a.ProcessID, qt.DeepEquals, types.HexBytes(pid))
+		if a.Kind == AnomalyUnknownVote {
+			qt.Assert(t, a.Nullifier, qt.DeepEquals, types.HexBytes(unknown))
 		}
 	}
-	return r
+	qt.Assert(t, kinds, qt.DeepEquals, map[string]int{
+		AnomalyVoteCount:        1,
+		AnomalyProcessVoteCount: 1,
+		AnomalyMissingVote:      2,
+		AnomalyUnknownVote:      1,
+	})
+	stored, err := idx.ProcessAnomalies(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, stored, qt.HasLen, len(anomalies))
+	qt.Assert(t, idx.anomalyCount.Load(), qt.Equals, int64(len(anomalies)))
+
+	// auditing again replaces the previous anomalies
+	_, err = idx.readWriteDB.Exec("UPDATE votes SET nullifier = ? WHERE nullifier = ?", nullifiers[1], unknown)
+	qt.Assert(t, err, qt.IsNil)
+	anomalies, err = idx.AuditProcess(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, anomalies, qt.HasLen, 3)
+	qt.Assert(t, idx.anomalyCount.Load(), qt.Equals, int64(3))
+
+	// the gauge is seeded with the stored anomalies when the indexer starts
+	dir := t.TempDir()
+	qt.Assert(t, idx.SaveBackup(context.Background(), filepath.Join(dir, dbFilename)), qt.IsNil)
+	restarted, err := New(app, Options{DataDir: dir})
+	qt.Assert(t, err, qt.IsNil)
+	t.Cleanup(func() { _ = restarted.Close() })
+	qt.Assert(t, restarted.anomalyCount.Load(), qt.Equals, int64(3))
+}
+
+func TestDecryptVotes(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	pid := util.RandomBytes(32)
+	qt.Assert(t, app.State.AddProcess(&models.Process{
+		ProcessId:             pid,
+		EnvelopeType:          &models.EnvelopeType{EncryptedVotes: true},
+		Status:                models.ProcessStatus_READY,
+		Mode:                  &models.ProcessMode{AutoStart: true, Interruptible: true},
+		BlockCount:            100,
+		MaxCensusSize:         1000,
+		VoteOptions:           &models.ProcessVoteOptions{MaxCount: 2, MaxValue: 1},
+		EncryptionPrivateKeys: make([]string, 16),
+		EncryptionPublicKeys:  make([]string, 16),
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	priv, err := nacl.DecodePrivate(fmt.Sprintf("%x", ethereum.HashRaw(util.RandomBytes(32))))
+	qt.Assert(t, err, qt.IsNil)
+	other, err := nacl.DecodePrivate(fmt.Sprintf("%x", ethereum.HashRaw(util.RandomBytes(32))))
+	qt.Assert(t, err, qt.IsNil)
+	ki := uint32(1)
+	qt.Assert(t, app.State.AddProcessKeys(&models.AdminTx{
+		Txtype:              models.TxType_ADD_PROCESS_KEYS,
+		ProcessId:           pid,
+		EncryptionPublicKey: priv.Public().Bytes(),
+		KeyIndex:            &ki,
+	}), qt.IsNil)
+
+	// three votes for the second option of both questions, and one encrypted
+	// with another key which cannot be decrypted
+	var nullifiers [][]byte
+	for i := range 4 {
+		vp, err := state.NewVotePackage([]int{1, 1}).Encode()
+		qt.Assert(t, err, qt.IsNil)
+		key := priv
+		if i == 3 {
+			key = other
+		}
+		vp, err = key.Encrypt(vp, nil)
+		qt.Assert(t, err, qt.IsNil)
+		nullifiers = append(nullifiers, util.RandomBytes(32))
+		qt.Assert(t, app.State.AddVote(&state.Vote{
+			ProcessID:            pid,
+			VotePackage:          vp,
+			Nullifier:            nullifiers[i],
+			Weight:               big.NewInt(1),
+			EncryptionKeyIndexes: []uint32{ki},
+		}), qt.IsNil)
+	}
+	app.AdvanceTestBlock()
+	_, err = idx.DecryptVotes(pid)
+	qt.Assert(t, err, qt.ErrorIs, ErrKeysNotRevealed)
+
+	// the votes are decrypted on the block after the keys are revealed
+	qt.Assert(t, app.State.SetProcessStatus(pid, models.ProcessStatus_ENDED, true), qt.IsNil)
+	qt.Assert(t, app.State.RevealProcessKeys(&models.AdminTx{
+		Txtype:               models.TxType_REVEAL_PROCESS_KEYS,
+		ProcessId:            pid,
+		EncryptionPrivateKey: priv.Bytes(),
+		KeyIndex:             &ki,
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+	app.AdvanceTestBlock()
+	idx.decrypting.Wait()
+
+	proc, err := idx.ProcessInfo(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, proc.FinalResults, qt.IsFalse)
+	qt.Assert(t, friendlyResults(proc.ResultsVotes), qt.DeepEquals, [][]string{{"0", "3"}, {"0", "3"}})
+	qt.Assert(t, proc.ResultsWeight.String(), qt.Equals, "3")
+
+	envelope, err := idx.GetEnvelope(nullifiers[0])
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, json.Valid(envelope.VotePackage), qt.IsFalse)
+	var vote state.VotePackage
+	qt.Assert(t, vote.Decode(envelope.DecryptedVotePackage), qt.IsNil)
+	qt.Assert(t, vote.Votes, qt.DeepEquals, []int{1, 1})
+	envelope, err = idx.GetEnvelope(nullifiers[3])
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, envelope.DecryptedVotePackage, qt.IsNil)
+
+	// the final results are not replaced
+	qt.Assert(t, app.State.SetProcessResults(pid, results.ResultsToProto(&results.Results{
+		Votes: [][]*types.BigInt{
+			{new(types.BigInt).SetUint64(0), new(types.BigInt).SetUint64(4)},
+			{new(types.BigInt).SetUint64(0), new(types.BigInt).SetUint64(4)},
+		},
+	})), qt.IsNil)
+	app.AdvanceTestBlock()
+	r, err := idx.DecryptVotes(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, r.Votes[0][1].String(), qt.Equals, "3")
+	proc, err = idx.ProcessInfo(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, proc.FinalResults, qt.IsTrue)
+	qt.Assert(t, proc.ResultsVotes[0][1].String(), qt.Equals, "4")
+}
+
+func TestProcessStatusHistory(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	pid := util.RandomBytes(32)
+	qt.Assert(t, app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		Duration:      100,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+		Mode:          &models.ProcessMode{AutoStart: true, Interruptible: true},
+		MaxCensusSize: 1000,
+		CensusRoot:    util.RandomBytes(32),
+		CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// a change made by a transaction is indexed with its hash
+	qt.Assert(t, app.State.SetProcessStatus(pid, models.ProcessStatus_PAUSED, true), qt.IsNil)
+	txID := [32]byte{1, 2, 3}
+	idx.OnNewTx(&vochaintx.Tx{
+		TxID:        txID,
+		TxModelType: "setProcess",
+		Tx:          &models.Tx{Payload: &models.Tx_SetProcess{}},
+	}, app.Height(), app.State.TxCounter())
+	app.AdvanceTestBlock()
+	pausedHeight := app.Height() - 1
+
+	// the ones made without a transaction are indexed too
+	qt.Assert(t, app.State.SetProcessDuration(pid, 200, true), qt.IsNil)
+	qt.Assert(t, app.State.SetProcessStatus(pid, models.ProcessStatus_READY, true), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// the changes rolled back are not indexed
+	qt.Assert(t, app.State.SetProcessStatus(pid, models.ProcessStatus_CANCELED, true), qt.IsNil)
+	idx.Rollback()
+
+	history, err := idx.ProcessStatusHistory(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, history, qt.HasLen, 3)
+	qt.Assert(t, history[0].Status, qt.Equals, "PAUSED")
+	qt.Assert(t, history[0].Height, qt.Equals, pausedHeight)
+	qt.Assert(t, []byte(history[0].TxHash), qt.DeepEquals, txID[:])
+	qt.Assert(t, history[1].Status, qt.Equals, "")
+	qt.Assert(t, history[1].Duration, qt.Equals, uint32(200))
+	qt.Assert(t, history[1].TxHash, qt.HasLen, 0)
+	qt.Assert(t, history[2].Status, qt.Equals, "READY")
+
+	history, err = idx.ProcessStatusHistory(util.RandomBytes(32))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, history, qt.HasLen, 0)
+}
+
+func TestAccountsList(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	keys := make([]*ethereum.SignKeys, 0)
+	for i := 0; i < 25; i++ {
+		key := &ethereum.SignKeys{}
+		qt.Assert(t, key.Generate(), qt.IsNil)
+		keys = append(keys, key)
+
+		err := app.State.SetAccount(key.Address(), &state.Account{
+			Account: models.Account{
+				Balance: 500,
+				Nonce:   uint32(0),
+				InfoURI: "ipfs://vocdoni.io",
+			},
+		})
+		qt.Assert(t, err, qt.IsNil)
+
+		app.AdvanceTestBlock()
+	}
+
+	// Test total count accounts
+	totalAccs, err := idx.CountTotalAccounts()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, totalAccs, qt.CmpEquals(), uint64(25))
+
+	last := 0
+	for i := 0; i < int(totalAccs); i++ {
+		accts, _, err := idx.AccountList(10, last, "")
+		qt.Assert(t, err, qt.IsNil)
+
+		for j, acc := range accts {
+			qt.Assert(t, acc.Address.String(), qt.Equals, hex.EncodeToString(keys[j+last].Address().Bytes()))
+			qt.Assert(t, acc.Nonce, qt.Equals, uint32(0))
+			qt.Assert(t, acc.Balance, qt.Equals, uint64(500))
+		}
+		last += 10
+	}
+
+	// update an existing account
+	err = app.State.SetAccount(keys[0].Address(), &state.Account{
+		Account: models.Account{
+			Balance: 600,
+			Nonce:   uint32(1),
+			InfoURI: "ipfs://vocdoni.io",
+		},
+	})
+	qt.Assert(t, err, qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// verify the updated balance and nonce
+	accts, _, err := idx.AccountList(5, 0, "")
+	qt.Assert(t, err, qt.IsNil)
+	// the account in the position 0 must be the updated account balance due it has the major balance
+	// indexer query has order BY balance DESC
+	qt.Assert(t, accts[0].Address.String(), qt.Equals, hex.EncodeToString(keys[0].Address().Bytes()))
+	qt.Assert(t, accts[0].Nonce, qt.Equals, uint32(1))
+	qt.Assert(t, accts[0].Balance, qt.Equals, uint64(600))
+}
+
+func TestTokenTransfers(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	keys := make([]*ethereum.SignKeys, 0)
+	// create 3 accounts
+	for i := 0; i < 3; i++ {
+		key := &ethereum.SignKeys{}
+		qt.Assert(t, key.Generate(), qt.IsNil)
+		keys = append(keys, key)
+
+		err := app.State.SetAccount(key.Address(), &state.Account{
+			Account: models.Account{
+				Balance: 500,
+				Nonce:   uint32(0),
+				InfoURI: "ipfs://vocdoni.io",
+			},
+		})
+		qt.Assert(t, err, qt.IsNil)
+
+		app.AdvanceTestBlock()
+	}
+
+	err := app.State.TransferBalance(&vochaintx.TokenTransfer{
+		FromAddress: keys[0].Address(),
+		ToAddress:   keys[2].Address(),
+		Amount:      18,
+		TxHash:      util.RandomBytes(32),
+	}, false)
+	qt.Assert(t, err, qt.IsNil)
+
+	app.AdvanceTestBlock()
+
+	err = app.State.TransferBalance(&vochaintx.TokenTransfer{
+		FromAddress: keys[1].Address(),
+		ToAddress:   keys[2].Address(),
+		Amount:      95,
+		TxHash:      util.RandomBytes(32),
+	}, false)
+	qt.Assert(t, err, qt.IsNil)
+
+	app.AdvanceTestBlock()
+
+	err = app.State.TransferBalance(&vochaintx.TokenTransfer{
+		FromAddress: keys[2].Address(),
+		ToAddress:   keys[1].Address(),
+		Amount:      5,
+		TxHash:      util.RandomBytes(32),
+	}, false)
+	qt.Assert(t, err, qt.IsNil)
+
+	app.AdvanceTestBlock()
+
+	// acct 1 must have only one token transfer received
+	acc1Tokentx, _, err := idx.TokenTransfersList(10, 0, "", "", hex.EncodeToString(keys[1].Address().Bytes()))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, len(acc1Tokentx), qt.Equals, 1)
+	qt.Assert(t, acc1Tokentx[0].Amount, qt.Equals, uint64(5))
+
+	// acct 2 must two token transfers received
+	acc2Tokentx, _, err := idx.TokenTransfersList(10, 0, "", "", hex.EncodeToString(keys[2].Address().Bytes()))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, len(acc2Tokentx), qt.Equals, 2)
+	qt.Assert(t, acc2Tokentx[0].Amount, qt.Equals, uint64(95))
+	qt.Assert(t, acc2Tokentx[1].Amount, qt.Equals, uint64(18))
+
+	// acct 0 must zero token transfers received
+	acc0Tokentx, _, err := idx.TokenTransfersList(10, 0, "", "", hex.EncodeToString(keys[0].Address().Bytes()))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, len(acc0Tokentx), qt.Equals, 0)
+
+	// acct 1 must have two token transfer received or sent
+	acc1TokentxFromOrTo, _, err := idx.TokenTransfersList(10, 0, hex.EncodeToString(keys[1].Address().Bytes()), "", "")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, len(acc1TokentxFromOrTo), qt.Equals, 2)
+	qt.Assert(t, acc1TokentxFromOrTo[0].Amount, qt.Equals, uint64(5))
+	qt.Assert(t, acc1TokentxFromOrTo[1].Amount, qt.Equals, uint64(95))
+}
+
+func TestAccountFeed(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	keys := make([]*ethereum.SignKeys, 2)
+	for i := range keys {
+		keys[i] = &ethereum.SignKeys{}
+		qt.Assert(t, keys[i].Generate(), qt.IsNil)
+		qt.Assert(t, app.State.SetAccount(keys[i].Address(), &state.Account{
+			Account: models.Account{Balance: 500},
+		}), qt.IsNil)
+	}
+	app.AdvanceTestBlock()
+
+	// newSignedTx returns a tx of the given type signed by the first account
+	newSignedTx := func(txModelType string, tx *models.Tx) *vochaintx.Tx {
+		body, err := proto.Marshal(tx)
+		qt.Assert(t, err, qt.IsNil)
+		signature, err := keys[0].SignEthereum(body)
+		qt.Assert(t, err, qt.IsNil)
+		return &vochaintx.Tx{
+			TxID:        [32]byte(util.RandomBytes(32)),
+			TxModelType: txModelType,
+			Tx:          tx,
+			SignedBody:  body,
+			Signature:   signature,
+		}
+	}
+
+	// an account update
+	idx.OnNewTx(newSignedTx("setAccount", &models.Tx{Payload: &models.Tx_SetAccount{
+		SetAccount: &models.SetAccountTx{Txtype: models.TxType_SET_ACCOUNT_INFO_URI},
+	}}), app.Height(), 0)
+	app.AdvanceTestBlock()
+
+	// a transfer sent and its fee
+	qt.Assert(t, app.State.TransferBalance(&vochaintx.TokenTransfer{
+		FromAddress: keys[0].Address(),
+		ToAddress:   keys[1].Address(),
+		Amount:      10,
+		TxHash:      util.RandomBytes(32),
+	}, false), qt.IsNil)
+	idx.OnSpendTokens(keys[0].Address().Bytes(), models.TxType_SEND_TOKENS, 2, "ref")
+	app.AdvanceTestBlock()
+
+	// an election
+	pid := util.RandomBytes(32)
+	qt.Assert(t, app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EntityId:      keys[0].Address().Bytes(),
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		BlockCount:    10,
+		MaxCensusSize: 1000,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// a vote
+	nullifier := util.RandomBytes(32)
+	idx.OnNewTx(newSignedTx("vote", &models.Tx{Payload: &models.Tx_Vote{
+		Vote: &models.VoteEnvelope{ProcessId: pid, Nullifier: nullifier},
+	}}), app.Height(), 0)
+	qt.Assert(t, app.State.AddVote(&state.Vote{
+		ProcessID:   pid,
+		Nullifier:   nullifier,
+		VotePackage: []byte(`{"votes":[1]}`),
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// a transfer received
+	qt.Assert(t, app.State.TransferBalance(&vochaintx.TokenTransfer{
+		FromAddress: keys[1].Address(),
+		ToAddress:   keys[0].Address(),
+		Amount:      3,
+		TxHash:      util.RandomBytes(32),
+	}, false), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	feed, _, total, err := idx.AccountFeed(10, 0, "", keys[0].Address().Bytes())
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(6))
+	// the mock block store does not use the state timestamps as block times,
+	// so only check that the items are sorted, not their order by kind
+	items := make(map[string]*indexertypes.AccountFeedItem)
+	for i, item := range feed {
+		items[item.Kind] = item
+		if i > 0 {
+			qt.Assert(t, item.Timestamp.After(feed[i-1].Timestamp), qt.IsFalse)
+		}
+	}
+	qt.Assert(t, items, qt.HasLen, 6)
+	qt.Assert(t, items[indexertypes.AccountFeedTransferSent].Amount, qt.Equals, uint64(10))
+	qt.Assert(t, []byte(items[indexertypes.AccountFeedTransferSent].Account), qt.DeepEquals, keys[1].Address().Bytes())
+	qt.Assert(t, items[indexertypes.AccountFeedTransferReceived].Amount, qt.Equals, uint64(3))
+	qt.Assert(t, []byte(items[indexertypes.AccountFeedTransferReceived].Account), qt.DeepEquals, keys[1].Address().Bytes())
+	qt.Assert(t, items[indexertypes.AccountFeedFee].Amount, qt.Equals, uint64(2))
+	qt.Assert(t, items[indexertypes.AccountFeedFee].Reference, qt.Equals, "ref")
+	qt.Assert(t, []byte(items[indexertypes.AccountFeedElection].ElectionID), qt.DeepEquals, pid)
+	qt.Assert(t, []byte(items[indexertypes.AccountFeedVote].ElectionID), qt.DeepEquals, pid)
+	qt.Assert(t, []byte(items[indexertypes.AccountFeedVote].VoteID), qt.DeepEquals, nullifier)
+	qt.Assert(t, items[indexertypes.AccountFeedVote].TxHash, qt.HasLen, 32)
+	qt.Assert(t, items[indexertypes.AccountFeedAccountUpdate].TxType, qt.Equals, "set_account_info_uri")
+
+	// the other account only has its transfers
+	other, _, total, err := idx.AccountFeed(10, 0, "", keys[1].Address().Bytes())
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(2))
+	qt.Assert(t, other[0].Kind, qt.Equals, indexertypes.AccountFeedTransferSent)
+	qt.Assert(t, other[1].Kind, qt.Equals, indexertypes.AccountFeedTransferReceived)
+
+	// the pages of the cursor pagination add up to the whole feed
+	var paged []*indexertypes.AccountFeedItem
+	cursor := ""
+	for {
+		items, next, _, err := idx.AccountFeed(4, 0, cursor, keys[0].Address().Bytes())
+		qt.Assert(t, err, qt.IsNil)
+		paged = append(paged, items...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	qt.Assert(t, paged, qt.DeepEquals, feed)
+
+	_, _, _, err = idx.AccountFeed(4, 0, "invalid", keys[0].Address().Bytes())
+	qt.Assert(t, err, qt.ErrorIs, ErrInvalidCursor)
+}
+
+func TestBlockTimeEstimation(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	// blocks 1 to 11 every 10 seconds
+	genesis := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	idx.blockMu.Lock()
+	queries := idx.blockTxQueries()
+	for h := int64(1); h <= 11; h++ {
+		_, err := queries.CreateBlock(context.TODO(), indexerdb.CreateBlockParams{
+			ChainID:         "test",
+			Height:          h,
+			Time:            genesis.Add(time.Duration(h) * 10 * time.Second),
+			Hash:            nonNullBytes(util.RandomBytes(32)),
+			ProposerAddress: nonNullBytes(nil),
+			LastBlockHash:   nonNullBytes(nil),
+		})
+		qt.Assert(t, err, qt.IsNil)
+	}
+	qt.Assert(t, idx.blockTx.Commit(), qt.IsNil)
+	idx.blockTx = nil
+	idx.blockMu.Unlock()
+
+	for _, tc := range []struct {
+		after     time.Duration
+		height    int64
+		estimated bool
+	}{
+		{10 * time.Second, 1, false},
+		{35 * time.Second, 3, false},
+		{110 * time.Second, 11, true},
+		{155 * time.Second, 15, true},
+	} {
+		height, estimated, err := idx.HeightAtTime(genesis.Add(tc.after))
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, height, qt.Equals, tc.height, qt.Commentf("after %s", tc.after))
+		qt.Assert(t, estimated, qt.Equals, tc.estimated)
+	}
+	_, _, err := idx.HeightAtTime(genesis)
+	qt.Assert(t, err, qt.ErrorIs, ErrBlockNotFound)
+
+	blockTime, estimated, err := idx.TimeAtHeight(5)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, blockTime.Equal(genesis.Add(50*time.Second)), qt.IsTrue)
+	qt.Assert(t, estimated, qt.IsFalse)
+	blockTime, estimated, err = idx.TimeAtHeight(14)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, blockTime.Equal(genesis.Add(140*time.Second)), qt.IsTrue)
+	qt.Assert(t, estimated, qt.IsTrue)
+	_, _, err = idx.TimeAtHeight(0)
+	qt.Assert(t, err, qt.ErrorIs, ErrBlockNotFound)
+}
+
+func TestBlockStats(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	pid := util.RandomBytes(32)
+	err := app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		BlockCount:    10,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+		MaxCensusSize: 1000,
+	})
+	qt.Assert(t, err, qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// two votes, three transactions and two token fees in the same block
+	height := int64(app.Height())
+	addVote(t, app, pid, []int{1}, nil)
+	addVote(t, app, pid, []int{0}, nil)
+	for i, txType := range []string{"vote", "vote", "setAccount"} {
+		idx.OnNewTx(&vochaintx.Tx{
+			TxID:        [32]byte{byte(i)},
+			TxModelType: txType,
+			Tx:          &models.Tx{Payload: &models.Tx_SetAccount{}},
+		}, uint32(height), int32(i))
+	}
+	idx.OnSpendTokens(util.RandomBytes(20), models.TxType_SET_ACCOUNT_INFO_URI, 10, "")
+	idx.OnSpendTokens(util.RandomBytes(20), models.TxType_SET_ACCOUNT_INFO_URI, 5, "")
+	app.AdvanceTestBlock()
+	app.AdvanceTestBlock()
+
+	stats, err := idx.BlockStats(height)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, stats.TxCount, qt.Equals, int64(3))
+	qt.Assert(t, stats.TxTypes, qt.DeepEquals, map[string]int64{"vote": 2, "setAccount": 1})
+	qt.Assert(t, stats.FeesBurned, qt.Equals, uint64(15))
+	qt.Assert(t, stats.VoteCount, qt.Equals, int64(2))
+
+	// the next block has no activity
+	stats, err = idx.BlockStats(height + 1)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, stats.TxCount, qt.Equals, int64(0))
+	qt.Assert(t, stats.TxTypes, qt.HasLen, 0)
+	qt.Assert(t, stats.VoteCount, qt.Equals, int64(0))
+
+	_, err = idx.BlockStats(height + 100)
+	qt.Assert(t, err, qt.ErrorIs, ErrBlockNotFound)
+
+	// the aggregate of the last hour, by minute, includes all the activity
+	to := time.Now().Add(time.Minute)
+	from := to.Add(-time.Hour)
+	aggregates, err := idx.BlockStatsAggregate(from, to, time.Minute)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, len(aggregates) > 0, qt.IsTrue)
+	total := indexertypes.BlockStatsAggregate{TxTypes: make(map[string]int64)}
+	for _, a := range aggregates {
+		qt.Assert(t, a.Start.Before(from), qt.IsFalse)
+		qt.Assert(t, a.End.After(to), qt.IsFalse)
+		qt.Assert(t, a.End.Sub(a.Start) <= time.Minute, qt.IsTrue)
+		total.BlockCount += a.BlockCount
+		total.TxCount += a.TxCount
+		total.FeesBurned += a.FeesBurned
+		total.VoteCount += a.VoteCount
+		for txType, count := range a.TxTypes {
+			total.TxTypes[txType] += count
+		}
+	}
+	qt.Assert(t, total.BlockCount >= 2, qt.IsTrue)
+	qt.Assert(t, total.TxCount, qt.Equals, int64(3))
+	qt.Assert(t, total.TxTypes, qt.DeepEquals, map[string]int64{"vote": 2, "setAccount": 1})
+	qt.Assert(t, total.FeesBurned, qt.Equals, uint64(15))
+	qt.Assert(t, total.VoteCount, qt.Equals, int64(2))
+
+	// a single interval covering the whole range
+	aggregates, err = idx.BlockStatsAggregate(from, to, to.Sub(from))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, aggregates, qt.HasLen, 1)
+	qt.Assert(t, aggregates[0].Start.Equal(from.Truncate(time.Second)), qt.IsTrue)
+	qt.Assert(t, aggregates[0].BlockCount, qt.Equals, total.BlockCount)
+	qt.Assert(t, aggregates[0].TxTypes, qt.DeepEquals, total.TxTypes)
+
+	// nothing in the future
+	aggregates, err = idx.BlockStatsAggregate(to.Add(time.Hour), to.Add(2*time.Hour), time.Hour)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, aggregates, qt.HasLen, 0)
+
+	_, err = idx.BlockStatsAggregate(to, from, time.Minute)
+	qt.Assert(t, err, qt.ErrorMatches, "invalid value: from .* must be before to .*")
+}
+
+func TestChainStatsRollups(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	pid := util.RandomBytes(32)
+	err := app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		BlockCount:    10,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+		MaxCensusSize: 1000,
+	})
+	qt.Assert(t, err, qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// two votes, two transactions, a token fee and two new accounts in the same block
+	height := int64(app.Height())
+	addVote(t, app, pid, []int{1}, nil)
+	addVote(t, app, pid, []int{0}, nil)
+	for i, txType := range []string{"vote", "setAccount"} {
+		idx.OnNewTx(&vochaintx.Tx{
+			TxID:        [32]byte{byte(i)},
+			TxModelType: txType,
+			Tx:          &models.Tx{Payload: &models.Tx_SetAccount{}},
+		}, uint32(height), int32(i))
+	}
+	idx.OnSpendTokens(util.RandomBytes(20), models.TxType_SET_ACCOUNT_INFO_URI, 10, "")
+	account := ethereum.NewSignKeys()
+	qt.Assert(t, account.Generate(), qt.IsNil)
+	qt.Assert(t, app.State.CreateAccount(account.Address(), "ipfs://", nil, 100), qt.IsNil)
+	qt.Assert(t, app.State.CreateAccount(common.BytesToAddress(util.RandomBytes(20)), "", nil, 0), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// updating an account does not count as a new one
+	acc, err := app.State.GetAccount(account.Address(), false)
+	qt.Assert(t, err, qt.IsNil)
+	acc.Balance = 50
+	qt.Assert(t, app.State.SetAccount(account.Address(), acc), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// indexing the block again does not count its stats twice
+	stats, err := idx.BlockStats(height)
+	qt.Assert(t, err, qt.IsNil)
+	idx.blockMu.Lock()
+	err = idx.indexBlockStats(context.TODO(), idx.blockTxQueries(), height, stats.Time)
+	idx.blockMu.Unlock()
+	qt.Assert(t, err, qt.IsNil)
+	app.AdvanceTestBlock()
+
+	from := stats.Time.Add(-48 * time.Hour)
+	to := stats.Time.Add(48 * time.Hour)
+	votes, err := idx.VoteStatsHourly(from, to)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, votes, qt.HasLen, 1)
+	qt.Assert(t, votes[0].Hour.Equal(stats.Time.Truncate(time.Hour)), qt.IsTrue)
+	qt.Assert(t, votes[0].VoteCount, qt.Equals, int64(2))
+
+	txs, err := idx.TxStatsDaily(from, to)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, txs, qt.HasLen, 1)
+	qt.Assert(t, txs[0].Day.Equal(stats.Time.Truncate(24*time.Hour)), qt.IsTrue)
+	qt.Assert(t, txs[0].TxCount, qt.Equals, int64(2))
+	qt.Assert(t, txs[0].TxTypes, qt.DeepEquals, map[string]int64{"vote": 1, "setAccount": 1})
+
+	accounts, err := idx.AccountStatsDaily(from, to)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, accounts, qt.HasLen, 1)
+	qt.Assert(t, accounts[0].NewAccounts, qt.Equals, int64(2))
+
+	fees, err := idx.FeeStatsDaily(from, to)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, fees, qt.HasLen, 1)
+	qt.Assert(t, fees[0].FeesBurned, qt.Equals, uint64(10))
+
+	// nothing in the future
+	votes, err = idx.VoteStatsHourly(to, to.Add(time.Hour))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, votes, qt.HasLen, 0)
+}
+
+// friendlyResults translates votes into a matrix of strings
+func TestValidators(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	valA := &models.Validator{
+		Address:          util.RandomBytes(20),
+		ValidatorAddress: util.RandomBytes(20),
+		PubKey:           util.RandomBytes(32),
+		Name:             "validatorA",
+		Power:            10,
+	}
+	valB := &models.Validator{
+		Address:          util.RandomBytes(20),
+		ValidatorAddress: util.RandomBytes(20),
+		PubKey:           util.RandomBytes(32),
+		Name:             "validatorB",
+		Power:            5,
+	}
+	// validator A proposes the odd blocks and signs all of them, validator B
+	// proposes the even blocks and signs only the even ones
+	app.SetFnGetBlockByHeight(func(height int64) *comettypes.Block {
+		proposer := valB.ValidatorAddress
+		if height%2 == 1 {
+			proposer = valA.ValidatorAddress
+		}
+		b := &comettypes.Block{Header: comettypes.Header{
+			ChainID:         "test",
+			Height:          height,
+			Time:            time.Unix(height, 0),
+			ProposerAddress: proposer,
+		}}
+		if height > 1 {
+			flagB := comettypes.BlockIDFlagAbsent
+			if (height-1)%2 == 0 {
+				flagB = comettypes.BlockIDFlagCommit
+			}
+			b.LastCommit = &comettypes.Commit{
+				Height: height - 1,
+				Signatures: []comettypes.CommitSig{
+					{BlockIDFlag: comettypes.BlockIDFlagCommit, ValidatorAddress: valA.ValidatorAddress},
+					{BlockIDFlag: flagB, ValidatorAddress: valB.ValidatorAddress},
+				},
+			}
+		}
+		return b
+	})
+
+	// the genesis validators are also indexed
+	genesisValidators, err := app.State.Validators(true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, app.State.AddValidator(valA), qt.IsNil)
+	qt.Assert(t, app.State.AddValidator(valB), qt.IsNil)
+	app.AdvanceTestBlocksUntilHeight(10)
+
+	// change the power of B and then remove A
+	valB.Power = 7
+	qt.Assert(t, app.State.AddValidator(valB), qt.IsNil)
+	app.AdvanceTestBlock()
+	qt.Assert(t, app.State.RemoveValidator(valA), qt.IsNil)
+	app.AdvanceTestBlock()
+	app.AdvanceTestBlock()
+
+	validators, total, err := idx.ValidatorList(10, 0)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(len(genesisValidators)+2))
+	// removed validators are listed last, since they have no power
+	last := validators[len(validators)-1]
+	qt.Assert(t, last.Address, qt.DeepEquals, types.HexBytes(valA.ValidatorAddress))
+	qt.Assert(t, last.Power, qt.Equals, uint64(0))
+	foundB := false
+	for _, v := range validators {
+		if bytes.Equal(v.Address, valB.ValidatorAddress) {
+			foundB = true
+			qt.Assert(t, v.Power, qt.Equals, uint64(7))
+			qt.Assert(t, v.Name, qt.Equals, "validatorB")
+			qt.Assert(t, v.AccountAddress, qt.DeepEquals, types.HexBytes(valB.Address))
+		}
+	}
+	qt.Assert(t, foundB, qt.IsTrue)
+
+	changes, total, err := idx.ValidatorSetChanges(10, 0, "")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(len(genesisValidators)+4))
+	// newest first
+	qt.Assert(t, changes[0].Address, qt.DeepEquals, types.HexBytes(valA.ValidatorAddress))
+	qt.Assert(t, changes[0].Power, qt.Equals, uint64(0))
+	qt.Assert(t, changes[1].Address, qt.DeepEquals, types.HexBytes(valB.ValidatorAddress))
+	qt.Assert(t, changes[1].Power, qt.Equals, uint64(7))
+	qt.Assert(t, changes[0].Height > changes[1].Height, qt.IsTrue)
+
+	changes, total, err = idx.ValidatorSetChanges(10, 0, hex.EncodeToString(valB.ValidatorAddress))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(2))
+	qt.Assert(t, changes[0].Power, qt.Equals, uint64(7))
+	qt.Assert(t, changes[1].Power, qt.Equals, uint64(5))
+
+	lastHeight, err := idx.CountBlocks("", "", "")
+	qt.Assert(t, err, qt.IsNil)
+
+	uptime, err := idx.ValidatorUptime(valA.ValidatorAddress, 4)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, uptime.ToHeight, qt.Equals, lastHeight-1)
+	qt.Assert(t, uptime.FromHeight, qt.Equals, lastHeight-4)
+	qt.Assert(t, uptime.Blocks, qt.Equals, uint64(4))
+	qt.Assert(t, uptime.Signed, qt.Equals, uint64(4))
+	qt.Assert(t, uptime.Proposed, qt.Equals, uint64(2))
+	qt.Assert(t, uptime.Uptime, qt.Equals, 1.0)
+
+	uptime, err = idx.ValidatorUptime(valB.ValidatorAddress, 4)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, uptime.Signed, qt.Equals, uint64(2))
+	qt.Assert(t, uptime.Proposed, qt.Equals, uint64(2))
+	qt.Assert(t, uptime.Uptime, qt.Equals, 0.5)
+
+	_, err = idx.ValidatorUptime(util.RandomBytes(20), 4)
+	qt.Assert(t, err, qt.ErrorIs, ErrValidatorNotFound)
+
+	// the blocks include their proposer, with its power at their height
+	block, err := idx.BlockByHeight(2)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, block.ProposerName, qt.Equals, "validatorB")
+	qt.Assert(t, block.ProposerAccount, qt.DeepEquals, types.HexBytes(valB.Address))
+	qt.Assert(t, block.ProposerPower, qt.Equals, uint64(5))
+	blocks, _, err := idx.BlockList(100, 0, "", "", hex.EncodeToString(valB.ValidatorAddress))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, blocks[0].ProposerName, qt.Equals, "validatorB")
+	qt.Assert(t, blocks[0].ProposerPower, qt.Equals, uint64(7))
+	qt.Assert(t, blocks[len(blocks)-1].ProposerPower, qt.Equals, uint64(5))
+	blocks, _, err = idx.BlockList(1, 0, "", "", hex.EncodeToString(valA.ValidatorAddress))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, blocks[0].ProposerName, qt.Equals, "validatorA")
+	qt.Assert(t, blocks[0].ProposerPower, qt.Equals, uint64(0))
+}
+
+func friendlyResults(votes [][]*types.BigInt) [][]string {
+	r := [][]string{}
+	for i := range votes {
+		r = append(r, []string{})
+		for j := range votes[i] {
+			n, err := votes[i][j].MarshalText()
+			if err != nil {
+				panic(err)
+			}
+			r[i] = append(r[i], string(n))
+		}
+	}
+	return r
+}
+
+// testStorage is a data.Storage which can only retrieve the files set as available.
+type testStorage struct {
+	data.Storage
+	available map[string]bool
+	sizes     map[string]int64
+}
+
+func (*testStorage) URIprefix() string {
+	return "ipfs://"
+}
+
+func (s *testStorage) Retrieve(_ context.Context, id string, maxSize int64) ([]byte, error) {
+	if !s.available[id] {
+		return nil, data.ErrNotFound
+	}
+	if s.sizes[id] > maxSize {
+		return nil, data.ErrFileTooLarge
+	}
+	return []byte("{}"), nil
+}
+
+func TestMetadataAvailability(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	addProcess := func(metadata, censusURI string) types.HexBytes {
+		pid := util.RandomBytes(32)
+		qt.Assert(t, app.State.AddProcess(&models.Process{
+			ProcessId:     pid,
+			EntityId:      util.RandomBytes(20),
+			EnvelopeType:  &models.EnvelopeType{},
+			Status:        models.ProcessStatus_READY,
+			BlockCount:    10,
+			VoteOptions:   &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+			Mode:          &models.ProcessMode{AutoStart: true},
+			MaxCensusSize: 10,
+			CensusRoot:    util.RandomBytes(32),
+			CensusURI:     &censusURI,
+			Metadata:      &metadata,
+			CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+		}), qt.IsNil)
+		return pid
+	}
+	pid1 := addProcess("ipfs://meta1", "ipfs://census1")
+	addProcess("ipfs://meta2", "")
+	addProcess("https://example.com/meta3", "")
+	app.AdvanceTestBlock()
+
+	storage := &testStorage{available: map[string]bool{
+		"ipfs://meta1": true,
+		"ipfs://meta2": true,
+	}}
+	check := func() []*indexertypes.MetadataAvailability {
+		qt.Assert(t, idx.checkMetadataAvailability(context.Background(), storage), qt.IsNil)
+		app.AdvanceTestBlock()
+		list, total, err := idx.UnavailableMetadataList(10, 0)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, total, qt.Equals, uint64(len(list)))
+		return list
+	}
+
+	// the census of the first process is not available
+	list := check()
+	qt.Assert(t, list, qt.HasLen, 1)
+	qt.Assert(t, list[0].ProcessID, qt.DeepEquals, pid1)
+	qt.Assert(t, list[0].MetadataURI, qt.Equals, "ipfs://meta1")
+	qt.Assert(t, list[0].MetadataAvailable, qt.IsTrue)
+	qt.Assert(t, list[0].MetadataLastSeen, qt.IsNotNil)
+	qt.Assert(t, list[0].CensusURI, qt.Equals, "ipfs://census1")
+	qt.Assert(t, list[0].CensusAvailable, qt.IsFalse)
+	qt.Assert(t, list[0].CensusLastSeen, qt.IsNil)
+	metadataLastSeen := *list[0].MetadataLastSeen
+
+	// now the census is available, but the metadata is gone: last seen is kept
+	storage.available["ipfs://census1"] = true
+	storage.available["ipfs://meta1"] = false
+	list = check()
+	qt.Assert(t, list, qt.HasLen, 1)
+	qt.Assert(t, list[0].MetadataAvailable, qt.IsFalse)
+	qt.Assert(t, list[0].MetadataLastSeen.Equal(metadataLastSeen), qt.IsTrue)
+	qt.Assert(t, list[0].CensusAvailable, qt.IsTrue)
+	qt.Assert(t, list[0].CensusLastSeen, qt.IsNotNil)
+
+	// everything is available again
+	storage.available["ipfs://meta1"] = true
+	qt.Assert(t, check(), qt.HasLen, 0)
+
+	// a census too large to be fetched is still available
+	storage.sizes = map[string]int64{"ipfs://census1": metadataMaxSize + 1}
+	qt.Assert(t, check(), qt.HasLen, 0)
+}
+
+func TestProcessTurnout(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	censusRoot := util.RandomBytes(32)
+	idx, err := New(app, Options{
+		DataDir: t.TempDir(),
+		CensusWeight: func(root []byte) (*big.Int, error) {
+			if !bytes.Equal(root, censusRoot) {
+				return nil, fmt.Errorf("census not found")
+			}
+			return big.NewInt(200), nil
+		},
+	})
+	qt.Assert(t, err, qt.IsNil)
+	t.Cleanup(func() { qt.Assert(t, idx.Close(), qt.IsNil) })
+
+	addProcess := func(origin models.CensusOrigin) []byte {
+		pid := util.RandomBytes(32)
+		qt.Assert(t, app.State.AddProcess(&models.Process{
+			ProcessId:     pid,
+			EnvelopeType:  &models.EnvelopeType{EncryptedVotes: false},
+			Status:        models.ProcessStatus_READY,
+			Mode:          &models.ProcessMode{AutoStart: true},
+			BlockCount:    10,
+			CensusRoot:    censusRoot,
+			CensusOrigin:  origin,
+			MaxCensusSize: 10,
+			VoteOptions: &models.ProcessVoteOptions{
+				MaxCount:     1,
+				MaxValue:     1,
+				MaxTotalCost: 1,
+				CostExponent: 1,
+			},
+		}), qt.IsNil)
+		return pid
+	}
+	pidWeighted := addProcess(models.CensusOrigin_OFF_CHAIN_TREE_WEIGHTED)
+	pid := addProcess(models.CensusOrigin_OFF_CHAIN_TREE)
+	app.AdvanceTestBlock()
+
+	vp, err := state.NewVotePackage([]int{1}).Encode()
+	qt.Assert(t, err, qt.IsNil)
+	addVotes := func(pid []byte, n int) {
+		for i := 0; i < n; i++ {
+			qt.Assert(t, app.State.AddVote(&state.Vote{
+				ProcessID:   pid,
+				VotePackage: vp,
+				Nullifier:   util.RandomBytes(32),
+				Weight:      big.NewInt(10),
+			}), qt.IsNil)
+		}
+	}
+	addVotes(pidWeighted, 4)
+	addVotes(pid, 1)
+	app.AdvanceTestBlock()
+
+	proc, err := idx.ProcessInfo(pidWeighted)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, proc.Turnout, qt.Equals, float64(40))
+	qt.Assert(t, proc.WeightTurnout, qt.Equals, float64(20))
+
+	// the weight turnout is only computed for weighted censuses
+	proc, err = idx.ProcessInfo(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, proc.Turnout, qt.Equals, float64(10))
+	qt.Assert(t, proc.WeightTurnout, qt.Equals, float64(0))
+
+	// the turnout is updated with the new votes
+	addVotes(pid, 2)
+	app.AdvanceTestBlock()
+	proc, err = idx.ProcessInfo(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, proc.Turnout, qt.Equals, float64(30))
+
+	list, _, err := idx.ProcessList(10, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 30)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, list, qt.HasLen, 2)
+	list, _, err = idx.ProcessList(10, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 35)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, list, qt.DeepEquals, [][]byte{pidWeighted})
+	_, _, err = idx.ProcessList(10, 0, "", "", 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, -1)
+	qt.Assert(t, err, qt.IsNotNil)
+}
+
+type censusSizeAlerts struct {
+	pids [][]byte
+}
+
+func (*censusSizeAlerts) OnComputeResults(_ *results.Results, _ *indexertypes.Process, _ uint32) {}
+
+func (a *censusSizeAlerts) OnCensusSizeAlert(process *indexertypes.Process, _ uint32) {
+	a.pids = append(a.pids, process.ID)
+}
+
+func TestCensusSizeAlert(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+	alerts := &censusSizeAlerts{}
+	idx.AddEventListener(alerts)
+
+	pid := util.RandomBytes(32)
+	qt.Assert(t, app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{EncryptedVotes: false},
+		Status:        models.ProcessStatus_READY,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		BlockCount:    10,
+		CensusRoot:    util.RandomBytes(32),
+		CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+		MaxCensusSize: 10,
+		VoteOptions: &models.ProcessVoteOptions{
+			MaxCount:     1,
+			MaxValue:     1,
+			MaxTotalCost: 1,
+			CostExponent: 1,
+		},
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	vp, err := state.NewVotePackage([]int{1}).Encode()
+	qt.Assert(t, err, qt.IsNil)
+	addVotes := func(n int) {
+		for i := 0; i < n; i++ {
+			qt.Assert(t, app.State.AddVote(&state.Vote{
+				ProcessID:   pid,
+				VotePackage: vp,
+				Nullifier:   util.RandomBytes(32),
+				Weight:      big.NewInt(1),
+			}), qt.IsNil)
+		}
+		app.AdvanceTestBlock()
+	}
+	addVotes(8)
+	qt.Assert(t, alerts.pids, qt.HasLen, 0)
+
+	// the process is alerted once it reaches the threshold
+	addVotes(1)
+	qt.Assert(t, alerts.pids, qt.DeepEquals, [][]byte{pid})
+	addVotes(1)
+	qt.Assert(t, alerts.pids, qt.HasLen, 1)
+}
+
+func TestAccountKV(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	addr := common.BytesToAddress(util.RandomBytes(20))
+	qt.Assert(t, app.State.SetAccountKV(addr, "webhook", []byte("hash")), qt.IsNil)
+	qt.Assert(t, app.State.SetAccountKV(addr, "delegate", []byte("x")), qt.IsNil)
+	app.AdvanceTestBlock()
+	height := uint64(app.Height())
+
+	entries, err := idx.AccountKV(addr.Bytes())
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, entries, qt.HasLen, 2)
+	qt.Assert(t, entries[0].Key, qt.Equals, "delegate")
+	qt.Assert(t, entries[1].Key, qt.Equals, "webhook")
+	qt.Assert(t, []byte(entries[1].Value), qt.DeepEquals, []byte("hash"))
+
+	// updating an entry updates its height, and an empty value deletes it
+	app.AdvanceTestBlock()
+	qt.Assert(t, app.State.SetAccountKV(addr, "webhook", []byte("other")), qt.IsNil)
+	qt.Assert(t, app.State.SetAccountKV(addr, "delegate", nil), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	entries, err = idx.AccountKV(addr.Bytes())
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, entries, qt.HasLen, 1)
+	qt.Assert(t, entries[0].Key, qt.Equals, "webhook")
+	qt.Assert(t, []byte(entries[0].Value), qt.DeepEquals, []byte("other"))
+	qt.Assert(t, entries[0].Height > height, qt.IsTrue)
+
+	entries, err = idx.AccountKV(util.RandomBytes(20))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, entries, qt.HasLen, 0)
+}
+
+func TestVoteDelegations(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	pid := util.RandomBytes(32)
+	addrs := make([]common.Address, 3)
+	for i := range addrs {
+		addrs[i] = common.BytesToAddress(util.RandomBytes(20))
+	}
+	// the first two addresses delegate to the third one
+	for i, addr := range addrs[:2] {
+		qt.Assert(t, app.State.SetVoteDelegation(pid, addr, &state.VoteDelegation{
+			Delegate: addrs[2],
+			Weight:   big.NewInt(int64(i + 1)),
+		}), qt.IsNil)
+	}
+	app.AdvanceTestBlock()
+
+	list, total, err := idx.VoteDelegations(pid, "", 10, 0)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(2))
+	qt.Assert(t, list, qt.HasLen, 2)
+	list, total, err = idx.VoteDelegations(pid, hex.EncodeToString(addrs[2].Bytes()), 1, 1)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(2))
+	qt.Assert(t, list, qt.HasLen, 1)
+	qt.Assert(t, []byte(list[0].Delegate), qt.DeepEquals, addrs[2].Bytes())
+	list, total, err = idx.VoteDelegations(pid, hex.EncodeToString(addrs[0].Bytes()), 10, 0)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(0))
+	qt.Assert(t, list, qt.HasLen, 0)
+
+	// a revoked delegation is removed
+	qt.Assert(t, app.State.SetVoteDelegation(pid, addrs[1], nil), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	list, total, err = idx.VoteDelegations(pid, "", 10, 0)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(1))
+	qt.Assert(t, []byte(list[0].Delegator), qt.DeepEquals, addrs[0].Bytes())
+	qt.Assert(t, list[0].Weight.String(), qt.Equals, "1")
+}
+
+func TestAccountRecoveryEvents(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	account := common.BytesToAddress(util.RandomBytes(20))
+	newOwner := common.BytesToAddress(util.RandomBytes(20))
+	guardians := []common.Address{{1}, {2}}
+	qt.Assert(t, app.State.SetRecoveryGuardians(account, &state.RecoveryGuardians{
+		Guardians: guardians,
+		Quorum:    1,
+		Timelock:  1,
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+	recovery, err := app.State.ApproveAccountRecovery(account, guardians[1], newOwner)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, recovery.ExecutableHeight, qt.Equals, app.State.CurrentHeight()+1)
+	app.AdvanceTestBlock()
+	app.AdvanceTestBlock()
+	owner, err := app.State.ExecuteAccountRecovery(account)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, owner, qt.Equals, newOwner)
+	app.AdvanceTestBlock()
+
+	// the events are listed newest first
+	list, total, err := idx.AccountRecoveryEvents(account.Bytes(), 10, 0)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(3))
+	qt.Assert(t, list, qt.HasLen, 3)
+	qt.Assert(t, list[0].Kind, qt.Equals, state.AccountRecoveryExecuted)
+	qt.Assert(t, []byte(list[0].NewOwner), qt.DeepEquals, newOwner.Bytes())
+	qt.Assert(t, list[1].Kind, qt.Equals, state.AccountRecoveryApproved)
+	qt.Assert(t, []byte(list[1].Guardian), qt.DeepEquals, guardians[1].Bytes())
+	qt.Assert(t, list[2].Kind, qt.Equals, state.AccountRecoveryGuardiansSet)
+	qt.Assert(t, list[2].Guardians, qt.HasLen, 2)
+	qt.Assert(t, list[2].Quorum, qt.Equals, uint32(1))
+	qt.Assert(t, list[2].Height < list[0].Height, qt.IsTrue)
+
+	list, total, err = idx.AccountRecoveryEvents(account.Bytes(), 1, 2)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(3))
+	qt.Assert(t, list, qt.HasLen, 1)
+	qt.Assert(t, list[0].Kind, qt.Equals, state.AccountRecoveryGuardiansSet)
+
+	list, total, err = idx.AccountRecoveryEvents(util.RandomBytes(20), 10, 0)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(0))
+	qt.Assert(t, list, qt.HasLen, 0)
+}
+
+// resultsListener records the calls to OnComputeResults.
+type resultsListener struct {
+	results   []*results.Results
+	processes []*indexertypes.Process
+}
+
+func (l *resultsListener) OnComputeResults(r *results.Results, p *indexertypes.Process, _ uint32) {
+	l.results = append(l.results, r)
+	l.processes = append(l.processes, p)
+}
+
+func TestNullifierFilter(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+	pid := util.RandomBytes(32)
+
+	err := app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		BlockCount:    10,
+		MaxCensusSize: 1000,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+	})
+	qt.Assert(t, err, qt.IsNil)
+	app.AdvanceTestBlock()
+
+	vp, err := state.NewVotePackage([]int{1}).Encode()
+	qt.Assert(t, err, qt.IsNil)
+	addVotes := func(n int) [][]byte {
+		nullifiers := make([][]byte, n)
+		for i := range nullifiers {
+			nullifiers[i] = util.RandomBytes(32)
+			qt.Assert(t, app.State.AddVote(&state.Vote{ProcessID: pid, VotePackage: vp, Nullifier: nullifiers[i]}), qt.IsNil)
+		}
+		app.AdvanceTestBlock()
+		return nullifiers
+	}
+	voted := addVotes(100)
+
+	// the filter is built from the indexed votes
+	f, err := idx.NullifierFilter(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, f.Count, qt.Equals, uint64(100))
+	qt.Assert(t, f.Capacity, qt.Equals, uint64(1000))
+	for _, n := range voted {
+		qt.Assert(t, f.MayContain(n), qt.IsTrue)
+	}
+	falsePositives := 0
+	for range 1000 {
+		if f.MayContain(util.RandomBytes(32)) {
+			falsePositives++
+		}
+	}
+	qt.Assert(t, falsePositives < 50, qt.IsTrue, qt.Commentf("%d false positives", falsePositives))
+
+	// and updated on commit, without modifying the filters already returned
+	newVoted := addVotes(10)
+	f2, err := idx.NullifierFilter(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, f2.Count, qt.Equals, uint64(110))
+	qt.Assert(t, f2.Height, qt.Equals, app.Height()-1)
+	qt.Assert(t, f.Count, qt.Equals, uint64(100))
+	for _, n := range append(voted, newVoted...) {
+		qt.Assert(t, f2.MayContain(n), qt.IsTrue)
+	}
+
+	// overwrites do not add new nullifiers
+	qt.Assert(t, app.State.AddVote(&state.Vote{ProcessID: pid, VotePackage: vp, Nullifier: voted[0], Overwrites: 1}), qt.IsNil)
+	app.AdvanceTestBlock()
+	f3, err := idx.NullifierFilter(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, f3.Count, qt.Equals, uint64(110))
+
+	_, err = idx.NullifierFilter(util.RandomBytes(32))
+	qt.Assert(t, err, qt.ErrorIs, ErrProcessNotFound)
+}
+
+func TestQueryReadOnly(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	pid := util.RandomBytes(32)
+	qt.Assert(t, app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		BlockCount:    10,
+		MaxCensusSize: 10,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// parameterized queries on the allowed tables
+	result, err := idx.QueryReadOnly("SELECT id, max_census_size FROM processes WHERE id = ?", pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, result.Columns, qt.DeepEquals, []string{"id", "max_census_size"})
+	qt.Assert(t, result.Rows, qt.DeepEquals, [][]any{{types.HexBytes(pid), int64(10)}})
+	qt.Assert(t, result.Truncated, qt.IsFalse)
+
+	result, err = idx.QueryReadOnly(`SELECT COUNT(*) FROM votes AS v JOIN processes AS p ON v.process_id = p.id`)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, result.Rows, qt.DeepEquals, [][]any{{int64(0)}})
+
+	// the number of rows is limited
+	result, err = idx.QueryReadOnly(`WITH RECURSIVE n(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM n LIMIT ?) SELECT x FROM n`,
+		QueryMaxRows+10)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, result.Rows, qt.HasLen, QueryMaxRows)
+	qt.Assert(t, result.Truncated, qt.IsTrue)
+
+	// anything else is not allowed
+	for _, query := range []string{
+		"SELECT * FROM sqlite_master",
+		"SELECT * FROM goose_db_version",
+		"DELETE FROM processes",
+		"UPDATE processes SET max_census_size = 0",
+		"PRAGMA table_info(processes)",
+		"ATTACH DATABASE 'other.db' AS other",
+		"CREATE TABLE foo (id INTEGER)",
+	} {
+		_, err := idx.QueryReadOnly(query)
+		qt.Assert(t, err, qt.ErrorIs, ErrQueryNotAllowed, qt.Commentf("query %q", query))
+	}
+	_, err = idx.QueryReadOnly("SELECT * FROM")
+	qt.Assert(t, err, qt.Not(qt.ErrorIs), ErrQueryNotAllowed)
+}
+
+func TestAttachApp(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+	testApp := func(chainID string) *vochain.BaseApplication {
+		app := vochain.TestBaseApplicationWithChainID(t, chainID)
+		app.SetChainID(chainID)
+		return app
+	}
+	otherApp := testApp("other-chain")
+	other, err := idx.AttachApp(otherApp)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, idx.Chain("other-chain"), qt.Equals, other)
+	qt.Assert(t, idx.Chain(app.ChainID()), qt.Equals, idx)
+	qt.Assert(t, idx.Chain("unknown"), qt.IsNil)
+	chainIDs := func() []string {
+		var ids []string
+		for _, chain := range idx.Chains() {
+			ids = append(ids, chain.ChainID())
+		}
+		return ids
+	}
+	qt.Assert(t, chainIDs(), qt.DeepEquals, []string{app.ChainID(), "other-chain"})
+
+	// a chain cannot be attached twice
+	_, err = idx.AttachApp(testApp("other_chain"))
+	qt.Assert(t, err, qt.IsNotNil)
+	_, err = other.AttachApp(testApp("third"))
+	qt.Assert(t, err, qt.IsNotNil)
+
+	addProcess := func(app *vochain.BaseApplication, maxCensusSize uint64) []byte {
+		pid := util.RandomBytes(32)
+		qt.Assert(t, app.State.AddProcess(&models.Process{
+			ProcessId:     pid,
+			EnvelopeType:  &models.EnvelopeType{},
+			Status:        models.ProcessStatus_READY,
+			Mode:          &models.ProcessMode{AutoStart: true},
+			BlockCount:    10,
+			MaxCensusSize: maxCensusSize,
+			VoteOptions:   &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+		}), qt.IsNil)
+		app.AdvanceTestBlock()
+		return pid
+	}
+	pid := addProcess(app, 10)
+	otherPid := addProcess(otherApp, 20)
+	addProcess(otherApp, 30)
+
+	// each chain only sees its own data
+	qt.Assert(t, idx.CountTotalProcesses(), qt.Equals, uint64(1))
+	qt.Assert(t, other.CountTotalProcesses(), qt.Equals, uint64(2))
+	_, err = idx.ProcessInfo(otherPid)
+	qt.Assert(t, err, qt.IsNotNil)
+	proc, err := other.ProcessInfo(otherPid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, proc.MaxCensusSize, qt.Equals, uint64(20))
+	qt.Assert(t, other.Status().Height, qt.Equals, idx.Status().Height+1)
+
+	// in its own database, next to the one of idx
+	_, err = os.Stat(filepath.Join(filepath.Dir(idx.dbPath), chainsDir, "other_chain.sqlite"))
+	qt.Assert(t, err, qt.IsNil)
+	result, err := other.QueryReadOnly("SELECT max_census_size FROM processes ORDER BY max_census_size")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, result.Rows, qt.DeepEquals, [][]any{{int64(20)}, {int64(30)}})
+	result, err = idx.QueryReadOnly("SELECT COUNT(*) FROM processes WHERE id != ?", pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, result.Rows, qt.DeepEquals, [][]any{{int64(0)}})
+
+	// the encryption key cannot be rotated while shared
+	qt.Assert(t, idx.RotateEncryptionKey(context.TODO(), ""), qt.IsNotNil)
+	qt.Assert(t, other.RotateEncryptionKey(context.TODO(), ""), qt.IsNotNil)
+
+	// a closed chain is detached, and its database is kept
+	qt.Assert(t, other.Close(), qt.IsNil)
+	qt.Assert(t, chainIDs(), qt.DeepEquals, []string{app.ChainID()})
+	other, err = idx.AttachApp(testApp("other-chain"))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, other.CountTotalProcesses(), qt.Equals, uint64(2))
+}
+
+// testExtension counts the blocks in its own table, running the statements of
+// onCommit on each Commit.
+type testExtension struct {
+	onCommit []string
+}
+
+func (*testExtension) Name() string { return "test" }
+
+func (*testExtension) Schema() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS ext_test_blocks (height INTEGER PRIMARY KEY)`,
+		`CREATE VIEW IF NOT EXISTS ext_test_summary AS
+			SELECT (SELECT COUNT(*) FROM ext_test_blocks) AS blocks, (SELECT COUNT(*) FROM processes) AS processes`,
+	}
+}
+
+func (e *testExtension) OnCommit(ctx context.Context, tx indexerdb.DBTX, height uint32) error {
+	if _, err := tx.ExecContext(ctx, `INSERT INTO ext_test_blocks (height) VALUES (?)`, height); err != nil {
+		return err
+	}
+	for _, stmt := range e.onCommit {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExtensions(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	ext := &testExtension{}
+	idx, err := New(app, Options{DataDir: t.TempDir(), Extensions: []Extension{ext}})
+	qt.Assert(t, err, qt.IsNil)
+	t.Cleanup(func() { qt.Assert(t, idx.Close(), qt.IsNil) })
+
+	summary := func() []any {
+		result, err := idx.QueryReadOnly("SELECT blocks, processes FROM ext_test_summary")
+		qt.Assert(t, err, qt.IsNil)
+		return result.Rows[0]
+	}
+	qt.Assert(t, app.State.AddProcess(&models.Process{
+		ProcessId:     util.RandomBytes(32),
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		BlockCount:    10,
+		MaxCensusSize: 10,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+	qt.Assert(t, summary(), qt.DeepEquals, []any{int64(1), int64(1)})
+
+	// a faulty extension cannot write to the core tables, and its changes
+	// are rolled back, while the block is indexed anyway
+	for _, stmt := range []string{
+		"DELETE FROM processes",
+		"DROP TABLE processes",
+		"CREATE INDEX ext_test_index ON processes (id)",
+		"PRAGMA foreign_keys = off",
+		"COMMIT",
+	} {
+		ext.onCommit = []string{stmt}
+		height := idx.indexedHeight.Load()
+		app.AdvanceTestBlock()
+		qt.Assert(t, summary(), qt.DeepEquals, []any{int64(1), int64(1)}, qt.Commentf("statement %q", stmt))
+		qt.Assert(t, idx.indexedHeight.Load(), qt.Equals, height+1)
+	}
+
+	ext.onCommit = nil
+	app.AdvanceTestBlock()
+	qt.Assert(t, summary(), qt.DeepEquals, []any{int64(2), int64(1)})
+
+	_, err = New(app, Options{DataDir: t.TempDir(), Extensions: []Extension{ext, ext}})
+	qt.Assert(t, err, qt.ErrorMatches, ".*duplicated indexer extension.*")
+}
+
+func TestImportLegacy(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	// write a legacy badgerhold database with a finalized process
+	legacyDir := t.TempDir()
+	legacyDB, err := badger.Open(badger.DefaultOptions(legacyDir).WithLogger(nil))
+	qt.Assert(t, err, qt.IsNil)
+	pid := util.RandomBytes(32)
+	creation := time.Unix(1600000000, 0).UTC()
+	put := func(prefix, key []byte, record any) {
+		var buf bytes.Buffer
+		qt.Assert(t, gob.NewEncoder(&buf).Encode(record), qt.IsNil)
+		qt.Assert(t, legacyDB.Update(func(txn *badger.Txn) error {
+			return txn.Set(append(bytes.Clone(prefix), key...), buf.Bytes())
+		}), qt.IsNil)
+	}
+	put(legacyProcessPrefix, pid, &legacyProcess{
+		ID:           pid,
+		EntityID:     util.RandomBytes(20),
+		StartBlock:   10,
+		EndBlock:     70,
+		VoteCount:    2,
+		Status:       int32(models.ProcessStatus_RESULTS),
+		Envelope:     &models.EnvelopeType{},
+		VoteOpts:     &models.ProcessVoteOptions{MaxCount: 2, MaxValue: 1},
+		CreationTime: creation,
+		HaveResults:  true,
+		FinalResults: true,
+	})
+	put(legacyResultsPrefix, pid, &legacyResults{
+		ProcessID: pid,
+		Votes: [][]*types.BigInt{
+			{new(types.BigInt).SetUint64(1), new(types.BigInt).SetUint64(1)},
+			{new(types.BigInt).SetUint64(2), new(types.BigInt).SetUint64(0)},
+		},
+		BlockHeight: 70,
+		Final:       true,
+	})
+	for i := range 2 {
+		nullifier := util.RandomBytes(32)
+		put(legacyVotePrefix, nullifier, &legacyVoteReference{
+			Nullifier: nullifier,
+			ProcessID: pid,
+			Height:    uint32(20 + i),
+			Weight:    new(types.BigInt).SetUint64(1),
+		})
+	}
+	// undecodable records are skipped
+	put(legacyVotePrefix, []byte("bad"), "not a vote")
+	qt.Assert(t, legacyDB.Close(), qt.IsNil)
+
+	summary, err := idx.ImportLegacy(legacyDir)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, summary, qt.DeepEquals, &indexertypes.LegacyImport{
+		Processes: 1, Results: 1, Votes: 2, Skipped: 1,
+	})
+
+	proc, err := idx.ProcessInfo(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, proc.FinalResults, qt.IsTrue)
+	qt.Assert(t, proc.VoteCount, qt.Equals, uint64(2))
+	qt.Assert(t, proc.Status, qt.Equals, int32(models.ProcessStatus_RESULTS))
+	qt.Assert(t, proc.StartDate.Equal(creation), qt.IsTrue)
+	qt.Assert(t, proc.EndDate.Equal(creation.Add(60*config.DefaultMinerTargetBlockTime)), qt.IsTrue)
+	qt.Assert(t, proc.ResultsVotes[1][0].String(), qt.Equals, "2")
+	votes, err := idx.CountTotalVotes()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, votes, qt.Equals, uint64(2))
+
+	// importing again does not duplicate anything
+	summary, err = idx.ImportLegacy(legacyDir)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, summary.Processes, qt.Equals, 0)
+	qt.Assert(t, summary.Votes, qt.Equals, 0)
+	qt.Assert(t, summary.Skipped, qt.Equals, 5)
+}
+
+func TestReadReplica(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	store := DirReplicaStore(t.TempDir())
+
+	// no replica was shipped yet
+	_, err := New(nil, Options{DataDir: t.TempDir(), ReadReplicaOf: store})
+	qt.Assert(t, err, qt.ErrorIs, ErrReplicaNotFound)
+
+	idx, err := New(app, Options{DataDir: t.TempDir(), ReplicateTo: store, ReplicaInterval: 1, ReplicaBaseInterval: 3})
+	qt.Assert(t, err, qt.IsNil)
+	t.Cleanup(func() { _ = idx.Close() })
+	advance := func() {
+		app.AdvanceTestBlock()
+		for idx.replicating.Load() {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	vp, err := state.NewVotePackage([]int{1, 1, 1}).Encode()
+	qt.Assert(t, err, qt.IsNil)
+	pid := util.RandomBytes(32)
+	qt.Assert(t, app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		BlockCount:    100,
+		MaxCensusSize: 1000,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 5, MaxValue: 1, MaxTotalCost: 3, CostExponent: 1},
+	}), qt.IsNil)
+	addVotes := func(n int) {
+		for range n {
+			v := &state.Vote{ProcessID: pid, VotePackage: vp, Nullifier: util.RandomBytes(32)}
+			qt.Assert(t, app.State.AddVote(v), qt.IsNil)
+		}
+		advance()
+	}
+	addVotes(10)
+
+	// the replica serves the queries without the state
+	replica, err := New(nil, Options{DataDir: t.TempDir(), ChainID: "replica", ReadReplicaOf: store, ReplicaRefresh: time.Hour})
+	qt.Assert(t, err, qt.IsNil)
+	t.Cleanup(func() { _ = replica.Close() })
+	qt.Assert(t, idx.ChainID(), qt.Equals, app.ChainID())
+	qt.Assert(t, replica.ChainID(), qt.Equals, "replica")
+	qt.Assert(t, replica.ReplicaHeight(), qt.Equals, app.Height()-1)
+	wantVotes := func(want uint64) {
+		got, err := replica.CountTotalVotes()
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, got, qt.Equals, want)
+		proc, err := replica.ProcessInfo(pid)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, proc.VoteCount, qt.Equals, want)
+	}
+	wantVotes(10)
+
+	// a query in progress keeps reading the replica it started with
+	rows, err := replica.readOnlyDB.Query("SELECT nullifier FROM votes")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, rows.Next(), qt.IsTrue)
+
+	// the new votes are shipped as a segment of the base replica
+	addVotes(5)
+	bases, err := store.heights()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, bases, qt.HasLen, 1)
+	segments, err := store.Segments(context.Background(), bases[0], 0)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, segments, qt.Not(qt.HasLen), 0)
+	qt.Assert(t, segments[len(segments)-1], qt.Equals, app.Height()-1)
+	refreshed, err := replica.RefreshReplica(context.Background())
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, refreshed, qt.IsTrue)
+	n := 1
+	for rows.Next() {
+		n++
+	}
+	qt.Assert(t, rows.Err(), qt.IsNil)
+	qt.Assert(t, n, qt.Equals, 10)
+	qt.Assert(t, replica.ReplicaHeight(), qt.Equals, app.Height()-1)
+	wantVotes(15)
+
+	// nothing to do without a newer replica
+	refreshed, err = replica.RefreshReplica(context.Background())
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, refreshed, qt.IsFalse)
+
+	// the read replica is not written to
+	_, err = replica.ImportLegacy(t.TempDir())
+	qt.Assert(t, err, qt.ErrorIs, ErrReadReplica)
+
+	// a base replica is shipped every few segments, and only the last ones are kept
+	for range (replicasKept + 1) * 4 {
+		addVotes(1)
+	}
+	bases, err = store.heights()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, bases, qt.HasLen, replicasKept)
+	qt.Assert(t, bases[0] > segments[len(segments)-1], qt.IsTrue)
+	segments, err = store.Segments(context.Background(), bases[0], 0)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, segments, qt.HasLen, 3)
+
+	// the read replica moves on to the latest base replica
+	refreshed, err = replica.RefreshReplica(context.Background())
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, refreshed, qt.IsTrue)
+	qt.Assert(t, replica.ReplicaHeight(), qt.Equals, app.Height()-1)
+	wantVotes(15 + uint64(replicasKept+1)*4)
+}
+
+func TestBackups(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	store := DirBackupStore(t.TempDir())
+	idx, err := New(app, Options{DataDir: t.TempDir(), BackupTo: store, BackupsKept: 2})
+	qt.Assert(t, err, qt.IsNil)
+	t.Cleanup(func() { _ = idx.Close() })
+	ctx := context.Background()
+
+	// the files put in the store by the operators are not rotated
+	qt.Assert(t, os.WriteFile(filepath.Join(string(store), "manual.sqlite3"), []byte("manual"), 0o644), qt.IsNil)
+	var names []string
+	for range 3 {
+		app.AdvanceTestBlock()
+		name, err := idx.RunBackup(ctx)
+		qt.Assert(t, err, qt.IsNil)
+		names = append(names, name)
+	}
+	list, err := store.ListBackups(ctx)
+	qt.Assert(t, err, qt.IsNil)
+	slices.Sort(list)
+	qt.Assert(t, list, qt.DeepEquals, []string{names[1], names[2], "manual.sqlite3"})
+
+	// the backups can be restored
+	r, err := store.GetBackup(ctx, names[2])
+	qt.Assert(t, err, qt.IsNil)
+	path := filepath.Join(t.TempDir(), "backup.sqlite3")
+	f, err := os.Create(path)
+	qt.Assert(t, err, qt.IsNil)
+	_, err = io.Copy(f, r)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, f.Close(), qt.IsNil)
+	qt.Assert(t, r.Close(), qt.IsNil)
+	restored, err := New(app, Options{DataDir: t.TempDir(), ExpectBackupRestore: true})
+	qt.Assert(t, err, qt.IsNil)
+	t.Cleanup(func() { _ = restored.Close() })
+	qt.Assert(t, restored.RestoreBackup(path), qt.IsNil)
+	qt.Assert(t, restored.indexedHeight.Load(), qt.Equals, idx.indexedHeight.Load())
+
+	// and the corrupted ones are detected
+	qt.Assert(t, idx.VerifyBackup(ctx, names[2]), qt.IsNil)
+	f, err = os.OpenFile(filepath.Join(string(store), names[2]), os.O_WRONLY, 0)
+	qt.Assert(t, err, qt.IsNil)
+	_, err = f.WriteAt([]byte("corrupted"), 4096)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, f.Close(), qt.IsNil)
+	qt.Assert(t, idx.VerifyBackup(ctx, names[2]), qt.ErrorIs, ErrBackupCorrupted)
+}
+
+func TestDataVersion(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	blocks := idx.DataVersion(DataBlocks)
+	txs := idx.DataVersion(DataTransactions)
+	votes := idx.DataVersion(DataVotes)
+	all := idx.DataVersion(DataBlocks, DataTransactions, DataVotes)
+
+	// an empty block only changes the blocks
+	app.AdvanceTestBlock()
+	qt.Assert(t, idx.DataVersion(DataBlocks), qt.Not(qt.Equals), blocks)
+	qt.Assert(t, idx.DataVersion(DataTransactions), qt.Equals, txs)
+	qt.Assert(t, idx.DataVersion(DataVotes), qt.Equals, votes)
+	qt.Assert(t, idx.DataVersion(DataBlocks, DataTransactions, DataVotes), qt.Not(qt.Equals), all)
+
+	pid := util.RandomBytes(32)
+	qt.Assert(t, app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		BlockCount:    10,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+		MaxCensusSize: 1000,
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+	qt.Assert(t, idx.DataVersion(DataTransactions), qt.Equals, txs)
+	qt.Assert(t, idx.DataVersion(DataVotes), qt.Equals, votes)
+
+	idx.OnNewTx(&vochaintx.Tx{
+		TxID:        [32]byte{1},
+		TxModelType: "setAccount",
+		Tx:          &models.Tx{Payload: &models.Tx_SetAccount{}},
+	}, app.Height(), 0)
+	app.AdvanceTestBlock()
+	qt.Assert(t, idx.DataVersion(DataTransactions), qt.Not(qt.Equals), txs)
+	qt.Assert(t, idx.DataVersion(DataVotes), qt.Equals, votes)
+	txs = idx.DataVersion(DataTransactions)
+
+	addVote(t, app, pid, []int{1}, nil)
+	app.AdvanceTestBlock()
+	qt.Assert(t, idx.DataVersion(DataTransactions), qt.Equals, txs)
+	qt.Assert(t, idx.DataVersion(DataVotes), qt.Not(qt.Equals), votes)
+
+	// another indexer does not share the versions, even if its data matches
+	qt.Assert(t, newTestIndexer(t, app).DataVersion(DataVotes), qt.Not(qt.Equals), idx.DataVersion(DataVotes))
 }
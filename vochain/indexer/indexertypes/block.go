@@ -18,6 +18,12 @@ type Block struct {
 	ProposerAddress types.HexBytes `json:"proposer"`
 	LastBlockHash   types.HexBytes `json:"lastBlockHash"`
 	TxCount         int64          `json:"txCount"`
+	// ProposerName, ProposerAccount and ProposerPower describe the validator
+	// that proposed the block, if it is known by the indexer. The power is the
+	// one the validator had at the height of the block.
+	ProposerName    string         `json:"proposerName,omitempty"`
+	ProposerAccount types.HexBytes `json:"proposerAccount,omitempty"`
+	ProposerPower   uint64         `json:"proposerPower,omitempty"`
 }
 
 // BlockFromDB converts the indexerdb.Block into a Block
@@ -42,5 +48,54 @@ func BlockFromDBRow(row *indexerdb.SearchBlocksRow) *Block {
 		ProposerAddress: nonEmptyBytes(row.ProposerAddress),
 		LastBlockHash:   nonEmptyBytes(row.LastBlockHash),
 		TxCount:         row.TxCount,
+		ProposerName:    row.ProposerName,
+		ProposerAccount: nonEmptyBytes(row.ProposerAccount),
+		ProposerPower:   uint64(row.ProposerPower),
 	}
 }
+
+// BlockStats holds the activity statistics of a block.
+type BlockStats struct {
+	Height     int64            `json:"height"`
+	Time       time.Time        `json:"time"`
+	TxCount    int64            `json:"txCount"`
+	TxTypes    map[string]int64 `json:"txTypes"`
+	FeesBurned uint64           `json:"feesBurned"`
+	VoteCount  int64            `json:"voteCount"`
+}
+
+// BlockStatsAggregate holds the activity statistics of the blocks in a time range.
+type BlockStatsAggregate struct {
+	Start      time.Time        `json:"start"`
+	End        time.Time        `json:"end"`
+	BlockCount int64            `json:"blockCount"`
+	TxCount    int64            `json:"txCount"`
+	TxTypes    map[string]int64 `json:"txTypes"`
+	FeesBurned uint64           `json:"feesBurned"`
+	VoteCount  int64            `json:"voteCount"`
+}
+
+// HourlyVoteStats holds the number of votes cast in an hour.
+type HourlyVoteStats struct {
+	Hour      time.Time `json:"hour"`
+	VoteCount int64     `json:"voteCount"`
+}
+
+// DailyTxStats holds the number of transactions included in a day, in total and by type.
+type DailyTxStats struct {
+	Day     time.Time        `json:"day"`
+	TxCount int64            `json:"txCount"`
+	TxTypes map[string]int64 `json:"txTypes"`
+}
+
+// DailyAccountStats holds the number of accounts created in a day.
+type DailyAccountStats struct {
+	Day         time.Time `json:"day"`
+	NewAccounts int64     `json:"newAccounts"`
+}
+
+// DailyFeeStats holds the amount of token fees burned in a day.
+type DailyFeeStats struct {
+	Day        time.Time `json:"day"`
+	FeesBurned uint64    `json:"feesBurned"`
+}
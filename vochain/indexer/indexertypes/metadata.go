@@ -0,0 +1,48 @@
+package indexertypes
+
+import (
+	"time"
+
+	"go.vocdoni.io/dvote/types"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+)
+
+// MetadataAvailability is the result of the last check of the metadata and
+// census URIs of a process on the storage provider. The URIs not hosted on
+// the storage provider are not checked, so they are left empty.
+type MetadataAvailability struct {
+	ProcessID         types.HexBytes `json:"processId"`
+	EntityID          types.HexBytes `json:"entityId"`
+	MetadataURI       string         `json:"metadataURI,omitempty"`
+	MetadataAvailable bool           `json:"metadataAvailable"`
+	// MetadataLastSeen is nil if the metadata was never retrieved
+	MetadataLastSeen *time.Time `json:"metadataLastSeen,omitempty"`
+	CensusURI        string     `json:"censusURI,omitempty"`
+	CensusAvailable  bool       `json:"censusAvailable"`
+	// CensusLastSeen is nil if the census was never retrieved
+	CensusLastSeen *time.Time `json:"censusLastSeen,omitempty"`
+	LastChecked    time.Time  `json:"lastChecked"`
+}
+
+// MetadataAvailabilityFromDBRow converts the indexerdb.SearchUnavailableMetadataRow
+// into a MetadataAvailability
+func MetadataAvailabilityFromDBRow(row *indexerdb.SearchUnavailableMetadataRow) *MetadataAvailability {
+	return &MetadataAvailability{
+		ProcessID:         row.ProcessID,
+		EntityID:          row.EntityID,
+		MetadataURI:       row.MetadataUri,
+		MetadataAvailable: row.MetadataAvailable,
+		MetadataLastSeen:  nonZeroTime(row.MetadataLastSeen),
+		CensusURI:         row.CensusUri,
+		CensusAvailable:   row.CensusAvailable,
+		CensusLastSeen:    nonZeroTime(row.CensusLastSeen),
+		LastChecked:       row.LastChecked,
+	}
+}
+
+func nonZeroTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,94 @@
+package indexertypes
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+
+	"go.vocdoni.io/dvote/types"
+)
+
+const (
+	// nullifierFilterBitsPerItem and nullifierFilterHashes give a false positive
+	// rate of about 1% when the filter holds as many nullifiers as its capacity.
+	nullifierFilterBitsPerItem = 10
+	nullifierFilterHashes      = 7
+	nullifierFilterMinBits     = 1 << 10
+	// NullifierFilterMaxBits is the maximum size of a NullifierFilter, 8 MiB.
+	// Beyond the capacity it allows, the false positive rate grows.
+	NullifierFilterMaxBits = 1 << 26
+)
+
+// NullifierFilter is a bloom filter of the vote nullifiers of a process. If
+// MayContain returns false, there is no vote with the nullifier as of the block
+// Height; otherwise, there might be one, which must be checked with an exact query.
+type NullifierFilter struct {
+	// Height is the last block whose votes are included in the filter.
+	Height uint32 `json:"height"`
+	// Count is the number of nullifiers added to the filter.
+	Count uint64 `json:"count"`
+	// Capacity is the number of nullifiers the filter is sized for.
+	Capacity uint64 `json:"capacity"`
+	// Hashes is the number of bits set for each nullifier.
+	Hashes uint32 `json:"hashes"`
+	// Bits is the bit array of the filter, the bit i being the bit i%8 of the byte i/8.
+	Bits types.HexBytes `json:"bits"`
+}
+
+// NewNullifierFilter returns an empty NullifierFilter sized for capacity nullifiers.
+func NewNullifierFilter(capacity uint64) *NullifierFilter {
+	bits := uint64(nullifierFilterMinBits)
+	if capacity > NullifierFilterMaxBits/nullifierFilterBitsPerItem {
+		bits = NullifierFilterMaxBits
+	} else if capacity*nullifierFilterBitsPerItem > bits {
+		bits = capacity * nullifierFilterBitsPerItem
+	}
+	return &NullifierFilter{
+		Capacity: capacity,
+		Hashes:   nullifierFilterHashes,
+		Bits:     make([]byte, (bits+7)/8),
+	}
+}
+
+// Add adds the nullifier to the filter.
+func (f *NullifierFilter) Add(nullifier []byte) {
+	f.positions(nullifier, func(pos uint64) bool {
+		f.Bits[pos/8] |= 1 << (pos % 8)
+		return true
+	})
+	f.Count++
+}
+
+// MayContain returns false if the nullifier was not added to the filter, or
+// true if it might have been.
+func (f *NullifierFilter) MayContain(nullifier []byte) bool {
+	contains := true
+	f.positions(nullifier, func(pos uint64) bool {
+		contains = f.Bits[pos/8]&(1<<(pos%8)) != 0
+		return contains
+	})
+	return contains
+}
+
+// Clone returns a deep copy of the filter.
+func (f *NullifierFilter) Clone() *NullifierFilter {
+	clone := *f
+	clone.Bits = append([]byte{}, f.Bits...)
+	return &clone
+}
+
+// positions calls fn with each of the bits of the nullifier, using double
+// hashing on its sha256 hash, until fn returns false.
+func (f *NullifierFilter) positions(nullifier []byte, fn func(pos uint64) bool) {
+	if len(f.Bits) == 0 {
+		return
+	}
+	size := uint64(len(f.Bits)) * 8
+	h := sha256.Sum256(nullifier)
+	h1, h2 := binary.BigEndian.Uint64(h[:8]), binary.BigEndian.Uint64(h[8:16])|1
+	for i := range uint64(min(f.Hashes, math.MaxUint8)) {
+		if !fn((h1 + i*h2) % size) {
+			return
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"go.vocdoni.io/dvote/types"
 	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
 	"go.vocdoni.io/dvote/vochain/results"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/proto"
 )
@@ -39,6 +40,18 @@ type Process struct {
 	SourceNetworkId   string                     `json:"sourceNetworkId"` // string form of the enum to be user friendly
 	MaxCensusSize     uint64                     `json:"maxCensusSize"`
 	ChainID           string                     `json:"chainId,omitempty"`
+	// Turnout is the percentage of the max census size that has voted.
+	Turnout float64 `json:"turnout"`
+	// WeightTurnout is the percentage of the census weight that has voted,
+	// only set for weighted censuses whose total weight is known.
+	WeightTurnout float64 `json:"weightTurnout,omitempty"`
+	// Unlisted processes are excluded from the process lists, except the ones
+	// filtered by their entity with its token.
+	Unlisted bool `json:"unlisted,omitempty"`
+	// NullifierGroup is set for the anonymous processes whose voters share
+	// their nullifiers with the other processes of the entity with the same
+	// group, so that they can only vote in one of them.
+	NullifierGroup types.HexBytes `json:"nullifierGroup,omitempty"`
 
 	PrivateKeys json.RawMessage `json:"-"` // json array
 	PublicKeys  json.RawMessage `json:"-"` // json array
@@ -81,6 +94,10 @@ func ProcessFromDB(dbproc *indexerdb.Process) *Process {
 		SourceBlockHeight: uint64(dbproc.SourceBlockHeight),
 		Metadata:          dbproc.Metadata,
 		ChainID:           dbproc.ChainID,
+		Turnout:           dbproc.Turnout,
+		WeightTurnout:     dbproc.WeightTurnout,
+		Unlisted:          dbproc.Unlisted,
+		NullifierGroup:    nonEmptyBytes(dbproc.NullifierGroup),
 
 		PrivateKeys:        json.RawMessage(dbproc.PrivateKeys),
 		PublicKeys:         json.RawMessage(dbproc.PublicKeys),
@@ -152,6 +169,8 @@ type EnvelopeMetadata struct {
 	TxIndex   int32          `json:"txIndex"`
 	Height    uint32         `json:"height"`
 	TxHash    types.HexBytes `json:"txHash"`
+	// Date is the time of the block of the vote, nil if it is unknown.
+	Date *time.Time `json:"date,omitempty"`
 }
 
 // EnvelopePackage contains a VoteEnvelope and auxiliary information for the Envelope api
@@ -161,9 +180,12 @@ type EnvelopePackage struct {
 	Nonce                types.HexBytes   `json:"nonce"`
 	Signature            types.HexBytes   `json:"signature"`
 	VotePackage          []byte           `json:"votePackage"` // plaintext or encrypted JSON
-	Weight               string           `json:"weight"`      // [math/big.Int.String]
-	OverwriteCount       uint32           `json:"overwriteCount"`
-	Date                 time.Time        `json:"date"`
+	// DecryptedVotePackage is the plaintext JSON of an encrypted VotePackage,
+	// set once the encryption keys of the process are revealed.
+	DecryptedVotePackage []byte    `json:"decryptedVotePackage,omitempty"`
+	Weight               string    `json:"weight"` // [math/big.Int.String]
+	OverwriteCount       uint32    `json:"overwriteCount"`
+	Date                 time.Time `json:"date"`
 }
 
 // TxPackage contains a SignedTx and auxiliary information for the Transaction api
@@ -197,17 +219,6 @@ func TransactionMetadataFromDB(dbtx *indexerdb.Transaction) *TransactionMetadata
 	}
 }
 
-func TransactionMetadataFromDBRow(dbtx *indexerdb.SearchTransactionsRow) *TransactionMetadata {
-	return &TransactionMetadata{
-		Hash:         dbtx.Hash,
-		BlockHeight:  uint32(dbtx.BlockHeight),
-		TxBlockIndex: int32(dbtx.BlockIndex),
-		TxType:       dbtx.Type,
-		TxSubtype:    dbtx.Subtype,
-		Signer:       dbtx.Signer,
-	}
-}
-
 // Transaction holds a single transaction
 type Transaction struct {
 	*TransactionMetadata
@@ -224,6 +235,36 @@ func TransactionFromDB(dbtx *indexerdb.Transaction) *Transaction {
 	}
 }
 
+// TransactionStatusSuccess is the status of the transactions executed
+// successfully, the only ones indexed.
+const TransactionStatusSuccess = "success"
+
+// TransactionReceipt is the outcome of a transaction executed in a block, with
+// the events it emitted.
+type TransactionReceipt struct {
+	*TransactionMetadata
+	Status string `json:"status" example:"success"`
+	// GasUsed is the amount of tokens spent by the fees of the transaction.
+	GasUsed uint64 `json:"gasUsed" example:"10"`
+	// Events are the events emitted by the transaction, null if it was indexed
+	// before the receipts were recorded.
+	Events []vochaintx.Event `json:"events"`
+}
+
+// TransactionReceiptFromDB converts an indexerdb.Transaction and its
+// indexerdb.TransactionReceipt, if any, into a TransactionReceipt.
+func TransactionReceiptFromDB(dbtx *indexerdb.Transaction, dbreceipt *indexerdb.TransactionReceipt) *TransactionReceipt {
+	receipt := &TransactionReceipt{
+		TransactionMetadata: TransactionMetadataFromDB(dbtx),
+		Status:              TransactionStatusSuccess,
+	}
+	if dbreceipt != nil {
+		receipt.GasUsed = uint64(dbreceipt.Cost)
+		receipt.Events = DecodeJSON[[]vochaintx.Event](dbreceipt.Events)
+	}
+	return receipt
+}
+
 // TokenTransferMeta contains the information of a token transfer and some extra useful information.
 // The types are compatible with the SQL defined schema.
 type TokenTransferMeta struct {
@@ -252,6 +293,135 @@ type Account struct {
 	Nonce   uint32          `json:"nonce"`
 }
 
+// AccountKV is an entry of the key-value store of an account.
+type AccountKV struct {
+	Key    string         `json:"key"`
+	Value  types.HexBytes `json:"value"`
+	Height uint64         `json:"height"`
+}
+
+// AccountRecoveryEvent is a change of the recovery of an account: the update
+// of its guardians, the approval of a new owner by one of them, or the rotation
+// of its owner key.
+type AccountRecoveryEvent struct {
+	// Kind is one of the state.AccountRecovery kinds.
+	Kind string `json:"kind"`
+	// Guardians are the guardians set, and Quorum and Timelock their
+	// parameters. No guardians means they were removed.
+	Guardians []types.HexBytes `json:"guardians,omitempty"`
+	Quorum    uint32           `json:"quorum,omitempty"`
+	Timelock  uint32           `json:"timelock,omitempty"`
+	// Guardian is the guardian that approved the new owner.
+	Guardian types.HexBytes `json:"guardian,omitempty"`
+	// NewOwner is the new owner approved or executed.
+	NewOwner types.HexBytes `json:"newOwner,omitempty"`
+	Height   uint64         `json:"height"`
+}
+
+// VoteDelegation is the delegation of the vote weight of a census member of a
+// process to another member.
+type VoteDelegation struct {
+	Delegator types.HexBytes `json:"delegator"`
+	Delegate  types.HexBytes `json:"delegate"`
+	// Weight is the census weight of the delegator, added to the vote of the
+	// delegate.
+	Weight *types.BigInt `json:"weight"`
+	Height uint64        `json:"height"`
+}
+
+// The kinds of the items of the activity feed of an account.
+const (
+	// AccountFeedTransferSent is a token transfer sent by the account.
+	AccountFeedTransferSent = "transferSent"
+	// AccountFeedTransferReceived is a token transfer received by the account.
+	AccountFeedTransferReceived = "transferReceived"
+	// AccountFeedFee is a token fee paid by the account.
+	AccountFeedFee = "fee"
+	// AccountFeedElection is an election created by the account.
+	AccountFeedElection = "election"
+	// AccountFeedVote is a vote cast by the account.
+	AccountFeedVote = "vote"
+	// AccountFeedAccountUpdate is a SetAccount transaction of the account.
+	AccountFeedAccountUpdate = "accountUpdate"
+)
+
+// AccountFeedItem is an item of the activity feed of an account. The fields
+// set depend on its kind.
+type AccountFeedItem struct {
+	Kind      string    `json:"kind"`
+	Height    uint64    `json:"height,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	// TxHash is the transaction of the item, except for fees and elections.
+	TxHash types.HexBytes `json:"txHash,omitempty"`
+	// Account is the counterpart of a token transfer.
+	Account types.AccountID `json:"account,omitempty"`
+	// ElectionID is the election created or voted.
+	ElectionID types.HexBytes `json:"electionId,omitempty"`
+	// VoteID is the nullifier of a vote.
+	VoteID types.HexBytes `json:"voteId,omitempty"`
+	// Amount is the amount of a token transfer or the cost of a fee.
+	Amount uint64 `json:"amount,omitempty"`
+	// TxType is the transaction type of a fee or an account update.
+	TxType string `json:"txType,omitempty"`
+	// Reference is the reference of a fee.
+	Reference string `json:"reference,omitempty"`
+}
+
+// ProcessStatusChange is a change of the status or the duration of a process.
+type ProcessStatusChange struct {
+	Height  uint32         `json:"height"`
+	TxIndex int32          `json:"txIndex"`
+	TxHash  types.HexBytes `json:"txHash,omitempty"`
+	// Status is the new status of the process, empty if the duration changed.
+	Status string `json:"status,omitempty"`
+	// Duration is the new duration of the process in seconds, zero if the
+	// status changed.
+	Duration uint32 `json:"duration,omitempty"`
+}
+
+// CensusVersion is a version of the census of a process, used to validate the
+// votes from the block at Height on, until the next version.
+type CensusVersion struct {
+	Root types.HexBytes `json:"root"`
+	URI  string         `json:"uri,omitempty"`
+	Size uint64         `json:"size"`
+	// Height is zero for the census the process was created with.
+	Height uint32 `json:"height"`
+}
+
+// Anomaly is a divergence between the votes of a process in the state and the
+// ones stored by the indexer, found when auditing the process.
+type Anomaly struct {
+	ProcessID types.HexBytes `json:"processId"`
+	// Height is the block height at which the process was audited.
+	Height uint32 `json:"height"`
+	Kind   string `json:"kind"`
+	// Nullifier is only set if the anomaly is about a single vote.
+	Nullifier types.HexBytes `json:"nullifier,omitempty"`
+	Details   string         `json:"details"`
+}
+
+// IndexerStatus is the health and sync status of an indexer, see
+// Indexer.Status.
+type IndexerStatus struct {
+	// Height is the last block height indexed, or the height of the replica
+	// served by a read replica.
+	Height uint32 `json:"height"`
+	// AppHeight is the height of the chain, zero if the indexer has no app.
+	AppHeight uint32 `json:"appHeight"`
+	// Lag is the number of blocks the indexer is behind the chain.
+	Lag uint32 `json:"lag"`
+	// PendingBlockWrites is the number of writes to the database buffered in
+	// the SQL transaction of the current block, until it is committed.
+	PendingBlockWrites int64 `json:"pendingBlockWrites"`
+	// LiveProcesses is the number of processes with live results.
+	LiveProcesses int `json:"liveProcesses"`
+	// DBSize and WALSize are the sizes in bytes of the database file and of
+	// its write-ahead log.
+	DBSize  int64 `json:"dbSize"`
+	WALSize int64 `json:"walSize"`
+}
+
 // TokenTransfersAccount contains the tokes transfers received and sent information in an account
 type TokenTransfersAccount struct {
 	Received []*TokenTransferMeta `json:"received"`
@@ -262,3 +432,70 @@ type Entity struct {
 	EntityID     types.EntityID
 	ProcessCount int64
 }
+
+// QueryResult is the result of a read-only SQL query on the indexer database.
+type QueryResult struct {
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+	// Truncated is true if the query returned more rows than the limit.
+	Truncated bool `json:"truncated"`
+}
+
+// Recount is the result of recounting the votes of a finalized process from
+// the state, compared with the results stored by the indexer.
+type Recount struct {
+	ProcessID types.HexBytes `json:"processId"`
+	// Height is the height of the state the votes were recounted from.
+	Height uint32            `json:"height"`
+	Votes  [][]*types.BigInt `json:"votes"`
+	Weight *types.BigInt     `json:"weight"`
+	// Discrepancies describes each difference found with the stored results,
+	// empty if they match.
+	Discrepancies []string `json:"discrepancies,omitempty"`
+}
+
+// LegacyImport summarizes the data imported from a legacy badger indexer
+// database.
+type LegacyImport struct {
+	Processes int `json:"processes"`
+	Results   int `json:"results"`
+	Votes     int `json:"votes"`
+	// Skipped is the number of entries which already existed or could not be
+	// decoded.
+	Skipped int `json:"skipped"`
+}
+
+// Backfill is the progress of a backfill of the indexed data, run in the
+// background after the database migrations.
+type Backfill struct {
+	Name string `json:"name"`
+	// Position is the last rowid or block height processed.
+	Position  uint64 `json:"position"`
+	Processed uint64 `json:"processed"`
+	Done      bool   `json:"done"`
+}
+
+// VotePatternStats are the voting pattern metrics of a process over the recent
+// votes, see Indexer.VotePatterns.
+type VotePatternStats struct {
+	ProcessID types.HexBytes `json:"processId"`
+	// Votes is the number of votes in the window, including the overwrites.
+	Votes int `json:"votes"`
+	// VotesPerMinute is the average rate of votes over the window.
+	VotesPerMinute float64 `json:"votesPerMinute"`
+	// TopWeightShare is the share of the total weight of the votes held by
+	// the heaviest one, from 0 to 1.
+	TopWeightShare float64 `json:"topWeightShare"`
+	// DuplicateShare is the share of the votes whose vote package is
+	// identical to the one of a previous vote, from 0 to 1.
+	DuplicateShare float64 `json:"duplicateShare"`
+}
+
+// VotePatternAlert is raised when a voting pattern metric of a process exceeds
+// its threshold, see indexer.VotePatternListener.
+type VotePatternAlert struct {
+	Kind      string  `json:"kind"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	VotePatternStats
+}
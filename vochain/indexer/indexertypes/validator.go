@@ -0,0 +1,55 @@
+package indexertypes
+
+import (
+	"go.vocdoni.io/dvote/types"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+)
+
+// Validator represents a validator seen by the indexer. Validators removed from
+// the validator set are kept, with power set to zero.
+type Validator struct {
+	Address          types.HexBytes `json:"validatorAddress"`
+	AccountAddress   types.HexBytes `json:"address"`
+	PubKey           types.HexBytes `json:"pubKey"`
+	Name             string         `json:"name"`
+	Power            uint64         `json:"power"`
+	JoinHeight       uint64         `json:"joinHeight"`
+	LastChangeHeight uint64         `json:"lastChangeHeight"`
+}
+
+// ValidatorFromDBRow converts the indexerdb.SearchValidatorsRow into a Validator
+func ValidatorFromDBRow(row *indexerdb.SearchValidatorsRow) *Validator {
+	return &Validator{
+		Address:          row.Address,
+		AccountAddress:   nonEmptyBytes(row.AccountAddress),
+		PubKey:           nonEmptyBytes(row.PubKey),
+		Name:             row.Name,
+		Power:            uint64(row.Power),
+		JoinHeight:       uint64(row.JoinHeight),
+		LastChangeHeight: uint64(row.LastChangeHeight),
+	}
+}
+
+// ValidatorSetChange represents a change of the voting power of a validator at
+// the given height. A power of zero means the validator was removed.
+type ValidatorSetChange struct {
+	Height  uint64         `json:"height"`
+	Address types.HexBytes `json:"validatorAddress"`
+	Power   uint64         `json:"power"`
+}
+
+// ValidatorUptime summarizes the participation of a validator in the blocks of
+// the range [FromHeight, ToHeight].
+type ValidatorUptime struct {
+	Address    types.HexBytes `json:"validatorAddress"`
+	FromHeight uint64         `json:"fromHeight"`
+	ToHeight   uint64         `json:"toHeight"`
+	// Blocks is the number of indexed blocks in the range
+	Blocks uint64 `json:"blocks"`
+	// Signed is the number of blocks in the range signed by the validator
+	Signed uint64 `json:"signed"`
+	// Proposed is the number of blocks in the range proposed by the validator
+	Proposed uint64 `json:"proposed"`
+	// Uptime is the ratio of signed blocks, between 0 and 1
+	Uptime float64 `json:"uptime"`
+}
@@ -0,0 +1,275 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"go.vocdoni.io/dvote/config"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/results"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// The legacy indexer, also known as scrutinizer, stored its data with
+// badgerhold, which keeps each record under the "bh_<TypeName>:" key prefix,
+// gob encoded.
+var (
+	legacyProcessPrefix = []byte("bh_Process:")
+	legacyResultsPrefix = []byte("bh_Results:")
+	legacyVotePrefix    = []byte("bh_VoteReference:")
+)
+
+// legacyProcess holds the fields of the processes stored by the legacy
+// indexer which are imported. Gob matches the fields by name, so the rest are
+// ignored.
+type legacyProcess struct {
+	ID                []byte
+	EntityID          []byte
+	StartBlock        uint32
+	EndBlock          uint32
+	VoteCount         uint64
+	CensusRoot        []byte
+	MaxCensusSize     uint64
+	CensusURI         string
+	Metadata          string
+	CensusOrigin      int32
+	Status            int32
+	Namespace         uint32
+	Envelope          *models.EnvelopeType
+	Mode              *models.ProcessMode
+	VoteOpts          *models.ProcessVoteOptions
+	PrivateKeys       json.RawMessage
+	PublicKeys        json.RawMessage
+	QuestionIndex     uint32
+	CreationTime      time.Time
+	HaveResults       bool
+	FinalResults      bool
+	SourceBlockHeight uint64
+}
+
+// legacyResults holds the results of a process stored by the legacy indexer.
+type legacyResults struct {
+	ProcessID   []byte
+	Votes       [][]*types.BigInt
+	Weight      *types.BigInt
+	BlockHeight uint32
+	Final       bool
+}
+
+// legacyVoteReference holds a vote stored by the legacy indexer.
+type legacyVoteReference struct {
+	Nullifier []byte
+	ProcessID []byte
+	Height    uint32
+	Weight    *types.BigInt
+	TxIndex   int32
+}
+
+// ImportLegacy backfills the database with the processes, results and votes
+// stored by the legacy badger indexer in dir, so that the nodes upgrading from
+// old versions keep their historical election data without replaying the
+// chain. The processes already indexed are left untouched, so it is safe to
+// run it more than once. The transactions are not imported, since they can be
+// reindexed from the block store with ReindexBlocks.
+//
+// It must not be called while the indexer is processing blocks.
+func (idx *Indexer) ImportLegacy(dir string) (*indexertypes.LegacyImport, error) {
+	if idx.readReplicaOf != nil {
+		return nil, ErrReadReplica
+	}
+	legacyDB, err := badger.Open(badger.DefaultOptions(dir).WithReadOnly(true).WithLogger(nil))
+	if err != nil {
+		return nil, fmt.Errorf("cannot open legacy indexer database: %w", err)
+	}
+	defer legacyDB.Close()
+
+	idx.blockMu.Lock()
+	defer idx.blockMu.Unlock()
+	tx, err := idx.readWriteDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	queries := indexerdb.New(tx)
+	ctx := context.TODO()
+
+	summary := &indexertypes.LegacyImport{}
+	// only the results and votes of the processes imported now are imported,
+	// the others are already indexed
+	imported := make(map[string]bool)
+	if err := legacyDB.View(func(txn *badger.Txn) error {
+		if err := iterateLegacy(txn, legacyProcessPrefix, summary, func(p *legacyProcess) error {
+			if _, err := queries.GetProcess(ctx, p.ID); err == nil {
+				summary.Skipped++
+				return nil
+			}
+			if _, err := queries.CreateProcess(ctx, idx.legacyProcessParams(p)); err != nil {
+				return fmt.Errorf("cannot import process %x: %w", p.ID, err)
+			}
+			imported[string(p.ID)] = true
+			summary.Processes++
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := iterateLegacy(txn, legacyResultsPrefix, summary, func(r *legacyResults) error {
+			if !imported[string(r.ProcessID)] {
+				summary.Skipped++
+				return nil
+			}
+			if err := importLegacyResults(ctx, queries, r); err != nil {
+				return fmt.Errorf("cannot import results of process %x: %w", r.ProcessID, err)
+			}
+			summary.Results++
+			return nil
+		}); err != nil {
+			return err
+		}
+		return iterateLegacy(txn, legacyVotePrefix, summary, func(v *legacyVoteReference) error {
+			if !imported[string(v.ProcessID)] {
+				summary.Skipped++
+				return nil
+			}
+			weight := `"1"`
+			if v.Weight != nil {
+				weight = indexertypes.EncodeJSON(v.Weight)
+			}
+			if _, err := queries.CreateVote(ctx, indexerdb.CreateVoteParams{
+				Nullifier:            v.Nullifier,
+				ProcessID:            v.ProcessID,
+				BlockHeight:          int64(v.Height),
+				BlockIndex:           int64(v.TxIndex),
+				Weight:               weight,
+				VoterID:              zeroBytes,
+				EncryptionKeyIndexes: indexertypes.EncodeJSON([]uint32(nil)),
+			}); err != nil {
+				return fmt.Errorf("cannot import vote %x: %w", v.Nullifier, err)
+			}
+			summary.Votes++
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	// the cached nullifier filters would miss the imported votes
+	for pid := range imported {
+		idx.nullifierFilters.Remove(pid)
+	}
+	idx.versions.bump(DataVotes)
+	log.Infow("legacy indexer data imported", "dir", dir, "processes", summary.Processes,
+		"results", summary.Results, "votes", summary.Votes, "skipped", summary.Skipped)
+	return summary, nil
+}
+
+// iterateLegacy decodes and calls fn with each legacy record under prefix.
+// The records which cannot be decoded are logged and skipped.
+func iterateLegacy[T any](txn *badger.Txn, prefix []byte, summary *indexertypes.LegacyImport,
+	fn func(*T) error,
+) error {
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		record := new(T)
+		if err := it.Item().Value(func(val []byte) error {
+			return gob.NewDecoder(bytes.NewReader(val)).Decode(record)
+		}); err != nil {
+			log.Warnw("skipping legacy indexer record", "key", fmt.Sprintf("%x", it.Item().Key()), "err", err)
+			summary.Skipped++
+			continue
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// legacyProcessParams returns the parameters to create a legacy process. The
+// legacy indexer did not store the dates of the processes, so they are taken
+// from the state if possible, else estimated from the creation time and the
+// number of blocks.
+func (idx *Indexer) legacyProcessParams(p *legacyProcess) indexerdb.CreateProcessParams {
+	startDate := p.CreationTime
+	endDate := startDate.Add(time.Duration(p.EndBlock-min(p.StartBlock, p.EndBlock)) * config.DefaultMinerTargetBlockTime)
+	if sp, err := idx.App.State.Process(p.ID, true); err == nil && sp.StartTime > 0 {
+		startDate = time.Unix(int64(sp.StartTime), 0)
+		endDate = time.Unix(int64(sp.StartTime+sp.Duration), 0)
+	}
+	resultsVotes := indexertypes.EncodeJSON([][]*types.BigInt{})
+	if p.VoteOpts != nil && p.VoteOpts.MaxCount <= results.MaxQuestions {
+		resultsVotes = indexertypes.EncodeJSON(results.NewEmptyVotes(p.VoteOpts))
+	}
+	return indexerdb.CreateProcessParams{
+		ID:                p.ID,
+		EntityID:          nonNullBytes(p.EntityID),
+		StartDate:         startDate,
+		EndDate:           endDate,
+		VoteCount:         int64(p.VoteCount),
+		HaveResults:       p.HaveResults,
+		FinalResults:      p.FinalResults,
+		CensusRoot:        nonNullBytes(p.CensusRoot),
+		MaxCensusSize:     int64(p.MaxCensusSize),
+		CensusUri:         p.CensusURI,
+		Metadata:          p.Metadata,
+		CensusOrigin:      int64(p.CensusOrigin),
+		Status:            int64(p.Status),
+		Namespace:         int64(p.Namespace),
+		Envelope:          indexertypes.EncodeProto(p.Envelope),
+		Mode:              indexertypes.EncodeProto(p.Mode),
+		VoteOpts:          indexertypes.EncodeProto(p.VoteOpts),
+		PrivateKeys:       string(legacyKeys(p.PrivateKeys)),
+		PublicKeys:        string(legacyKeys(p.PublicKeys)),
+		QuestionIndex:     int64(p.QuestionIndex),
+		CreationTime:      p.CreationTime,
+		SourceBlockHeight: int64(p.SourceBlockHeight),
+		ChainID:           idx.App.ChainID(),
+		NullifierGroup:    zeroBytes,
+		ResultsVotes:      resultsVotes,
+	}
+}
+
+// legacyKeys returns the JSON encoded list of encryption keys of a legacy
+// process, which is null if there are none.
+func legacyKeys(keys json.RawMessage) json.RawMessage {
+	if len(keys) == 0 {
+		return json.RawMessage("null")
+	}
+	return keys
+}
+
+// importLegacyResults stores the results of an imported legacy process.
+func importLegacyResults(ctx context.Context, queries *indexerdb.Queries, r *legacyResults) error {
+	weight := indexertypes.EncodeJSON(r.Weight)
+	if r.Final {
+		proc, err := queries.GetProcess(ctx, r.ProcessID)
+		if err != nil {
+			return err
+		}
+		_, err = queries.SetProcessResultsReady(ctx, indexerdb.SetProcessResultsReadyParams{
+			ID:          r.ProcessID,
+			Votes:       indexertypes.EncodeJSON(r.Votes),
+			Weight:      weight,
+			BlockHeight: int64(r.BlockHeight),
+			EndDate:     proc.EndDate,
+		})
+		return err
+	}
+	_, err := queries.UpdateProcessResults(ctx, indexerdb.UpdateProcessResultsParams{
+		ID:          r.ProcessID,
+		Votes:       indexertypes.EncodeJSON(r.Votes),
+		Weight:      weight,
+		BlockHeight: int64(r.BlockHeight),
+	})
+	return err
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE INDEX index_processes_creation_time_id
+ON processes(creation_time DESC, id);
+
+CREATE INDEX index_token_transfers_transfer_time_tx_hash
+ON token_transfers(transfer_time DESC, tx_hash);
+
+-- +goose Down
+DROP INDEX index_processes_creation_time_id;
+DROP INDEX index_token_transfers_transfer_time_tx_hash;
@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE validators (
+  address            BLOB NOT NULL PRIMARY KEY,
+  account_address    BLOB NOT NULL,
+  pub_key            BLOB NOT NULL,
+  name               TEXT NOT NULL,
+  power              INTEGER NOT NULL,
+  join_height        INTEGER NOT NULL,
+  last_change_height INTEGER NOT NULL
+);
+
+CREATE TABLE validator_set_changes (
+  height  INTEGER NOT NULL,
+  address BLOB NOT NULL,
+  power   INTEGER NOT NULL,
+  PRIMARY KEY (height, address)
+);
+CREATE INDEX index_validator_set_changes_address
+ON validator_set_changes(address, height);
+
+CREATE TABLE validator_signatures (
+  address BLOB NOT NULL,
+  height  INTEGER NOT NULL,
+  PRIMARY KEY (address, height)
+);
+
+CREATE INDEX index_blocks_proposer_address
+ON blocks(proposer_address, height);
+
+-- +goose Down
+DROP INDEX index_blocks_proposer_address;
+DROP TABLE validator_signatures;
+DROP INDEX index_validator_set_changes_address;
+DROP TABLE validator_set_changes;
+DROP TABLE validators;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE metadata_availability (
+  process_id         BLOB NOT NULL PRIMARY KEY,
+  metadata_uri       TEXT NOT NULL,
+  metadata_available BOOLEAN NOT NULL,
+  metadata_last_seen DATETIME NOT NULL,
+  census_uri         TEXT NOT NULL,
+  census_available   BOOLEAN NOT NULL,
+  census_last_seen   DATETIME NOT NULL,
+  last_checked       DATETIME NOT NULL
+);
+CREATE INDEX index_metadata_availability_last_checked
+ON metadata_availability(last_checked);
+
+-- +goose Down
+DROP INDEX index_metadata_availability_last_checked;
+DROP TABLE metadata_availability;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE results_proofs (
+  process_id      BLOB NOT NULL PRIMARY KEY,
+  height          INTEGER NOT NULL,
+  state_root      BLOB NOT NULL,
+  processes_root  BLOB NOT NULL,
+  processes_proof BLOB NOT NULL,
+  process_leaf    BLOB NOT NULL,
+  process_proof   BLOB NOT NULL
+);
+
+-- +goose Down
+DROP TABLE results_proofs;
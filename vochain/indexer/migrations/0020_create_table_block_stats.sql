@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE block_stats (
+  height      INTEGER NOT NULL PRIMARY KEY,
+  timestamp   INTEGER NOT NULL, -- unix seconds, so it can be bucketed by time ranges
+  tx_count    INTEGER NOT NULL,
+  fees_burned INTEGER NOT NULL,
+  vote_count  INTEGER NOT NULL
+);
+CREATE INDEX index_block_stats_timestamp
+ON block_stats(timestamp);
+
+CREATE TABLE block_stats_tx_types (
+  height INTEGER NOT NULL,
+  type   TEXT NOT NULL,
+  count  INTEGER NOT NULL,
+
+  PRIMARY KEY(height, type),
+  FOREIGN KEY(height) REFERENCES block_stats(height)
+);
+
+-- +goose Down
+DROP TABLE block_stats_tx_types;
+DROP INDEX index_block_stats_timestamp;
+DROP TABLE block_stats;
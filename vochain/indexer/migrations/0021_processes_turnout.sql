@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE processes ADD COLUMN turnout REAL NOT NULL DEFAULT 0;
+ALTER TABLE processes ADD COLUMN weight_turnout REAL NOT NULL DEFAULT 0;
+
+UPDATE processes
+SET turnout = CAST(vote_count AS REAL) * 100 / max_census_size
+WHERE max_census_size > 0;
+
+CREATE INDEX index_processes_turnout
+ON processes(turnout);
+
+-- +goose Down
+DROP INDEX index_processes_turnout;
+ALTER TABLE processes DROP COLUMN weight_turnout;
+ALTER TABLE processes DROP COLUMN turnout;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE account_kv (
+  account BLOB NOT NULL,
+  key     TEXT NOT NULL,
+  value   BLOB NOT NULL,
+  height  INTEGER NOT NULL, -- block height of the last update of the entry
+
+  PRIMARY KEY(account, key)
+);
+
+-- +goose Down
+DROP TABLE account_kv;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE process_status_history (
+  id           INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  process_id   BLOB NOT NULL,
+  block_height INTEGER NOT NULL,
+  block_index  INTEGER NOT NULL,
+  tx_hash      BLOB NOT NULL, -- empty if the change was not made by a transaction
+  status       INTEGER NOT NULL, -- the new status, or zero if the duration changed
+  duration     INTEGER NOT NULL -- the new duration in seconds, or zero if the status changed
+);
+CREATE INDEX process_status_history_process_id_index
+ON process_status_history(process_id, block_height, block_index);
+
+-- +goose Down
+DROP TABLE process_status_history;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE process_census_history (
+  id           INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  process_id   BLOB NOT NULL,
+  block_height INTEGER NOT NULL, -- zero for the census the process was created with
+  census_root  BLOB NOT NULL,
+  census_uri   TEXT NOT NULL,
+  census_size  INTEGER NOT NULL
+);
+CREATE INDEX process_census_history_process_id_index
+ON process_census_history(process_id, id);
+
+-- +goose Down
+DROP TABLE process_census_history;
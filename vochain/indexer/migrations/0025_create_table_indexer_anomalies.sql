@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE indexer_anomalies (
+  id           INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  process_id   BLOB NOT NULL,
+  block_height INTEGER NOT NULL, -- height at which the process was audited
+  kind         TEXT NOT NULL,
+  nullifier    BLOB NOT NULL, -- empty unless the anomaly is about a single vote
+  details      TEXT NOT NULL
+);
+CREATE INDEX indexer_anomalies_process_id_index
+ON indexer_anomalies(process_id, id);
+
+-- +goose Down
+DROP INDEX indexer_anomalies_process_id_index;
+DROP TABLE indexer_anomalies;
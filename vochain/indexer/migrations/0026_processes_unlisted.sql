@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE processes ADD COLUMN unlisted BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE processes DROP COLUMN unlisted;
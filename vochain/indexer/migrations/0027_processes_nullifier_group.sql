@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE processes ADD COLUMN nullifier_group BLOB NOT NULL DEFAULT x'';
+CREATE INDEX idx_processes_nullifier_group ON processes(entity_id, nullifier_group) WHERE nullifier_group != x'';
+
+-- +goose Down
+DROP INDEX idx_processes_nullifier_group;
+ALTER TABLE processes DROP COLUMN nullifier_group;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE INDEX index_to_account_token_transfers
+ON token_transfers(to_account);
+
+CREATE INDEX index_transactions_signer
+ON transactions(signer, type);
+
+-- +goose Down
+DROP INDEX index_to_account_token_transfers;
+
+DROP INDEX index_transactions_signer;
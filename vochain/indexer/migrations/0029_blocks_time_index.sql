@@ -0,0 +1,6 @@
+-- +goose Up
+CREATE INDEX index_blocks_time
+ON blocks(time);
+
+-- +goose Down
+DROP INDEX index_blocks_time;
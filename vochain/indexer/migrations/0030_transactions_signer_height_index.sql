@@ -0,0 +1,6 @@
+-- +goose Up
+CREATE INDEX index_transactions_signer_height
+ON transactions(signer, block_height DESC, block_index DESC);
+
+-- +goose Down
+DROP INDEX index_transactions_signer_height;
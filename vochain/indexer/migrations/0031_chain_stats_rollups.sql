@@ -0,0 +1,48 @@
+-- +goose Up
+CREATE TABLE stats_votes_hourly (
+  hour       INTEGER NOT NULL PRIMARY KEY, -- unix seconds of the start of the hour (UTC)
+  vote_count INTEGER NOT NULL
+);
+
+CREATE TABLE stats_txs_daily (
+  day   INTEGER NOT NULL, -- unix seconds of the start of the day (UTC)
+  type  TEXT NOT NULL,
+  count INTEGER NOT NULL,
+
+  PRIMARY KEY(day, type)
+);
+
+CREATE TABLE stats_accounts_daily (
+  day          INTEGER NOT NULL PRIMARY KEY,
+  new_accounts INTEGER NOT NULL
+);
+
+CREATE TABLE stats_fees_daily (
+  day         INTEGER NOT NULL PRIMARY KEY,
+  fees_burned INTEGER NOT NULL
+);
+
+-- the blocks indexed so far are rolled up from their block stats,
+-- the accounts are not since their creation time is unknown
+INSERT INTO stats_votes_hourly (hour, vote_count)
+SELECT timestamp - timestamp % 3600, SUM(vote_count)
+FROM block_stats
+GROUP BY 1;
+
+INSERT INTO stats_txs_daily (day, type, count)
+SELECT s.timestamp - s.timestamp % 86400, t.type, SUM(t.count)
+FROM block_stats AS s
+JOIN block_stats_tx_types AS t
+  ON t.height = s.height
+GROUP BY 1, 2;
+
+INSERT INTO stats_fees_daily (day, fees_burned)
+SELECT timestamp - timestamp % 86400, SUM(fees_burned)
+FROM block_stats
+GROUP BY 1;
+
+-- +goose Down
+DROP TABLE stats_fees_daily;
+DROP TABLE stats_accounts_daily;
+DROP TABLE stats_txs_daily;
+DROP TABLE stats_votes_hourly;
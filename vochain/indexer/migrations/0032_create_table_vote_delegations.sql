@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE vote_delegations (
+  process_id BLOB NOT NULL,
+  delegator  BLOB NOT NULL,
+  delegate   BLOB NOT NULL,
+  weight     TEXT NOT NULL, -- math/big.Int.String of the delegated census weight
+  height     INTEGER NOT NULL, -- block height of the delegation
+
+  PRIMARY KEY(process_id, delegator)
+);
+
+CREATE INDEX vote_delegations_process_id_delegate ON vote_delegations(process_id, delegate);
+
+-- +goose Down
+DROP INDEX vote_delegations_process_id_delegate;
+DROP TABLE vote_delegations;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- empty until the vote is decrypted, once the encryption keys of its process are revealed
+ALTER TABLE votes ADD COLUMN decrypted_package TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE votes DROP COLUMN decrypted_package;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE account_recovery_events (
+  id        INTEGER PRIMARY KEY AUTOINCREMENT,
+  account   BLOB NOT NULL,
+  kind      TEXT NOT NULL, -- one of the state.AccountRecovery kinds
+  guardian  BLOB NOT NULL, -- the guardian that approved the new owner, empty otherwise
+  new_owner BLOB NOT NULL, -- the new owner approved or executed, empty otherwise
+  guardians TEXT NOT NULL, -- JSON of the guardians set, empty otherwise
+  height    INTEGER NOT NULL -- block height of the event
+);
+
+CREATE INDEX account_recovery_events_account ON account_recovery_events(account, id);
+
+-- +goose Down
+DROP INDEX account_recovery_events_account;
+DROP TABLE account_recovery_events;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE backfills (
+  name      TEXT NOT NULL PRIMARY KEY, -- one of the backfills registered by the indexer
+  position  INTEGER NOT NULL DEFAULT 0, -- last rowid or height processed
+  processed INTEGER NOT NULL DEFAULT 0, -- number of rows or blocks processed
+  done      BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- +goose Down
+DROP TABLE backfills;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE transaction_receipts (
+  hash   BLOB NOT NULL PRIMARY KEY, -- hash of the transaction
+  cost   INTEGER NOT NULL, -- tokens spent by the fees of the transaction
+  events TEXT NOT NULL -- JSON of the events emitted by the transaction
+);
+
+-- +goose Down
+DROP TABLE transaction_receipts;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- the time of the block of the vote, denormalized to filter the votes by time
+-- without joining the blocks; null until its block is indexed
+ALTER TABLE votes ADD COLUMN block_time DATETIME;
+
+-- the votes indexed so far get the time of their blocks
+UPDATE votes SET block_time = (SELECT time FROM blocks WHERE blocks.height = votes.block_height);
+
+CREATE INDEX index_votes_process_id_block_time
+ON votes(process_id, block_time);
+
+-- +goose Down
+DROP INDEX index_votes_process_id_block_time;
+ALTER TABLE votes DROP COLUMN block_time;
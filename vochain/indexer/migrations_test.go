@@ -1,6 +1,7 @@
 package indexer
 
 import (
+	"context"
 	"io"
 	"os"
 	"path/filepath"
@@ -8,7 +9,13 @@ import (
 
 	qt "github.com/frankban/quicktest"
 	"github.com/klauspost/compress/zstd"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/util"
 	"go.vocdoni.io/dvote/vochain"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestRestoreBackupAndMigrate(t *testing.T) {
@@ -54,4 +61,106 @@ func TestRestoreBackupAndMigrate(t *testing.T) {
 	qt.Assert(t, totalProcs, qt.Equals, uint64(445))
 	totalVotes, _ := idx.CountTotalVotes()
 	qt.Assert(t, totalVotes, qt.Equals, uint64(5159))
+
+	// The migrations schedule the backfills, run in the background.
+	backfillsStatus, err := idx.Backfills()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, backfillsStatus, qt.HasLen, len(backfills))
+	for _, b := range backfillsStatus {
+		qt.Assert(t, b.Done, qt.IsFalse)
+	}
+	idx.RunBackfills(true)
+	backfillsStatus, err = idx.Backfills()
+	qt.Assert(t, err, qt.IsNil)
+	for _, b := range backfillsStatus {
+		qt.Assert(t, b.Done, qt.IsTrue)
+		if b.Name == BackfillProcessVoteCounts {
+			qt.Assert(t, b.Processed, qt.Equals, uint64(445))
+		}
+	}
+}
+
+func TestBackfills(t *testing.T) {
+	app := vochain.TestBaseApplication(t)
+	idx := newTestIndexer(t, app)
+
+	// A new database only reindexes the blocks of the block store.
+	backfillsStatus, err := idx.Backfills()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, backfillsStatus, qt.DeepEquals, []*indexertypes.Backfill{{Name: BackfillBlocks}})
+	idx.RunBackfills(true)
+	qt.Assert(t, idx.backfillPending(BackfillBlocks), qt.IsFalse)
+
+	// Index some signed transactions and a process with votes.
+	qt.Assert(t, app.State.SetTxBaseCost(models.TxType_CREATE_ACCOUNT, 0), qt.IsNil)
+	app.AdvanceTestBlock()
+	keys := make([]*ethereum.SignKeys, 3)
+	for i := range keys {
+		keys[i] = &ethereum.SignKeys{}
+		qt.Assert(t, keys[i].Generate(), qt.IsNil)
+		tx, err := proto.Marshal(&models.Tx{Payload: &models.Tx_SetAccount{SetAccount: &models.SetAccountTx{
+			Txtype:  models.TxType_CREATE_ACCOUNT,
+			Account: keys[i].Address().Bytes(),
+		}}})
+		qt.Assert(t, err, qt.IsNil)
+		signature, err := keys[i].SignVocdoniTx(tx, app.ChainID())
+		qt.Assert(t, err, qt.IsNil)
+		stx, err := proto.Marshal(&models.SignedTx{Tx: tx, Signature: signature})
+		qt.Assert(t, err, qt.IsNil)
+		response, err := app.SendTx(stx)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, response.Code, qt.Equals, uint32(0))
+	}
+	pid := util.RandomBytes(32)
+	qt.Assert(t, app.State.AddProcess(&models.Process{
+		ProcessId:     pid,
+		EnvelopeType:  &models.EnvelopeType{},
+		Status:        models.ProcessStatus_READY,
+		Mode:          &models.ProcessMode{AutoStart: true},
+		BlockCount:    10,
+		MaxCensusSize: 1000,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 1},
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+	vp, err := state.NewVotePackage([]int{1, 1, 1}).Encode()
+	qt.Assert(t, err, qt.IsNil)
+	for i := 0; i < 4; i++ {
+		v := &state.Vote{ProcessID: pid, VotePackage: vp, Nullifier: util.RandomBytes(32)}
+		qt.Assert(t, app.State.AddVote(v), qt.IsNil)
+	}
+	app.AdvanceTestBlock()
+
+	// Simulate a database indexed before the signers and the vote counts.
+	_, err = idx.readWriteDB.Exec(`UPDATE transactions SET signer = x''`)
+	qt.Assert(t, err, qt.IsNil)
+	_, err = idx.readWriteDB.Exec(`UPDATE processes SET vote_count = 0`)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, idx.scheduleBackfills(context.TODO(), 29), qt.IsNil)
+	txs, _, total, err := idx.TransactionListBySigner(10, 0, "", keys[0].Address().Bytes(), "")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, total, qt.Equals, uint64(0))
+	qt.Assert(t, txs, qt.HasLen, 0)
+
+	// Meanwhile, the vote count of a process is counted when queried.
+	process, err := idx.ProcessInfo(pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, process.VoteCount, qt.Equals, uint64(4))
+
+	idx.RunBackfills(true)
+	backfillsStatus, err = idx.Backfills()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, backfillsStatus, qt.DeepEquals, []*indexertypes.Backfill{
+		{Name: BackfillBlocks, Done: true},
+		{Name: BackfillProcessVoteCounts, Position: 1, Processed: 1, Done: true},
+		{Name: BackfillTransactionSigners, Position: 3, Processed: 3, Done: true},
+	})
+	for _, key := range keys {
+		txs, _, total, err := idx.TransactionListBySigner(10, 0, "", key.Address().Bytes(), "")
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, total, qt.Equals, uint64(1))
+		qt.Assert(t, txs, qt.HasLen, 1)
+	}
+	count, err := idx.readOnlyQuery.GetProcessVoteCount(context.TODO(), pid)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, count, qt.Equals, int64(4))
 }
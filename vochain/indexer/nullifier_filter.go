@@ -0,0 +1,94 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+
+	"go.vocdoni.io/dvote/types"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+)
+
+// maxNullifierFilters is the number of processes whose nullifier filter is kept
+// in memory, and updated on Commit.
+const maxNullifierFilters = 32
+
+// NullifierFilter returns the bloom filter of the vote nullifiers of the process,
+// to check whether a nullifier has voted without querying the votes. The filter
+// is built from the database on the first call, and then updated on each Commit.
+// The returned filter must not be modified.
+func (idx *Indexer) NullifierFilter(pid types.ProcessID) (*indexertypes.NullifierFilter, error) {
+	if f, ok := idx.nullifierFilters.Get(string(pid)); ok {
+		return f, nil
+	}
+	proc, err := idx.ProcessInfo(pid)
+	if err != nil {
+		return nil, err
+	}
+	// leave room for the votes to come if the census size is unknown
+	f := indexertypes.NewNullifierFilter(max(proc.MaxCensusSize, 2*proc.VoteCount))
+	if err := idx.addNullifiers(f, pid); err != nil {
+		return nil, err
+	}
+	idx.nullifierFiltersMu.Lock()
+	defer idx.nullifierFiltersMu.Unlock()
+	if cached, ok := idx.nullifierFilters.Get(string(pid)); ok {
+		return cached, nil
+	}
+	// Catch up with the votes of the blocks committed while the filter was built,
+	// since Commit only updates the cached filters. Once the lock is held, Commit
+	// cannot update the filter before it is cached.
+	if err := idx.addNullifiers(f, pid); err != nil {
+		return nil, err
+	}
+	idx.nullifierFilters.Add(string(pid), f)
+	return f, nil
+}
+
+// addNullifiers adds to the filter the nullifiers of the votes of the process
+// indexed after the filter height.
+func (idx *Indexer) addNullifiers(f *indexertypes.NullifierFilter, pid types.ProcessID) error {
+	nullifiers, err := idx.readOnlyQuery.GetProcessNullifiers(context.TODO(), indexerdb.GetProcessNullifiersParams{
+		ProcessID:   pid,
+		BlockHeight: int64(f.Height),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot get nullifiers of process %x: %w", pid, err)
+	}
+	for _, n := range nullifiers {
+		f.Add(n.Nullifier)
+		f.Height = max(f.Height, uint32(n.BlockHeight))
+	}
+	return nil
+}
+
+// updateNullifierFilters adds the nullifiers of the new votes of the block to
+// the cached filters of their processes. Assumes that blockMu is locked, and
+// that the block votes are already committed to the database.
+func (idx *Indexer) updateNullifierFilters(height uint32) {
+	if len(idx.blockNullifiers) == 0 {
+		return
+	}
+	idx.nullifierFiltersMu.Lock()
+	defer idx.nullifierFiltersMu.Unlock()
+	for pid, nullifiers := range idx.blockNullifiers {
+		f, ok := idx.nullifierFilters.Peek(pid)
+		// the filter might have been built after the block was committed
+		if !ok || f.Height >= height {
+			continue
+		}
+		// the cached filters are shared with the callers of NullifierFilter
+		f = f.Clone()
+		for _, nullifier := range nullifiers {
+			f.Add(nullifier)
+		}
+		f.Height = height
+		if f.Count > f.Capacity && len(f.Bits)*8 < indexertypes.NullifierFilterMaxBits {
+			// too many false positives, rebuild it with a bigger capacity on the next call
+			idx.nullifierFilters.Remove(pid)
+			continue
+		}
+		idx.nullifierFilters.Add(pid, f)
+	}
+	clear(idx.blockNullifiers)
+}
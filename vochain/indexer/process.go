@@ -11,9 +11,12 @@ import (
 	"go.vocdoni.io/proto/build/go/models"
 
 	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
 	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
 	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
 	"go.vocdoni.io/dvote/vochain/results"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 )
 
 var (
@@ -30,6 +33,32 @@ func nonNullBytes(p []byte) []byte {
 	return p
 }
 
+// nonEmptyBytes is the opposite of nonNullBytes, returning nil for the empty
+// values of NOT NULL columns, so that they are omitted from the JSON.
+func nonEmptyBytes(p []byte) []byte {
+	if len(p) == 0 {
+		return nil
+	}
+	return p
+}
+
+// nullTimePtr returns the time of a nullable column, or nil if it is null.
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// utcTimeArg returns an optional time argument of a query in UTC, as the times
+// are stored, since the comparisons of the DATETIME columns are textual.
+func utcTimeArg(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
 // TODO(mvdan): funcs to safely convert integers
 
 // ProcessInfo returns the available information regarding an election process id
@@ -41,55 +70,147 @@ func (idx *Indexer) ProcessInfo(pid []byte) (*indexertypes.Process, error) {
 		}
 		return nil, err
 	}
-	return indexertypes.ProcessFromDB(&procInner), nil
+	process := indexertypes.ProcessFromDB(&procInner)
+	// the vote counts are being recomputed, so count the votes of the process
+	if idx.backfillPending(BackfillProcessVoteCounts) {
+		count, err := idx.readOnlyQuery.CountProcessVotes(context.TODO(), pid)
+		if err != nil {
+			return nil, err
+		}
+		process.VoteCount = uint64(count)
+	}
+	return process, nil
+}
+
+// NullifierGroupProcesses returns the IDs of the processes of the entity with
+// the given nullifier group, which share the nullifiers of their voters, in
+// creation order. The unlisted processes are included.
+func (idx *Indexer) NullifierGroupProcesses(entityID, group []byte) ([][]byte, error) {
+	if len(group) == 0 {
+		return nil, nil
+	}
+	ids, err := idx.readOnlyQuery.GetNullifierGroupProcesses(context.TODO(), indexerdb.GetNullifierGroupProcessesParams{
+		EntityID:       entityID,
+		NullifierGroup: group,
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := make([][]byte, len(ids))
+	for i, id := range ids {
+		list[i] = id
+	}
+	return list, nil
 }
 
 // ProcessList returns a list of process identifiers (PIDs) registered in the Vochain.
 // all args (entityID, processID, etc) are optional filters, if
 // declared as zero-values will be ignored. entityID and processID are partial or full hex strings.
 // Status is one of READY, CANCELED, ENDED, PAUSED, RESULTS
+// minTurnout is the minimum turnout percentage, zero to ignore it.
+// The unlisted processes are never returned.
 func (idx *Indexer) ProcessList(limit, offset int, entityID string, processID string,
 	namespace uint32, srcNetworkID int32, status models.ProcessStatus,
 	withResults, finalResults, manuallyEnded *bool,
 	startDateAfter, startDateBefore, endDateAfter, endDateBefore *time.Time,
+	minTurnout float64,
 ) ([][]byte, uint64, error) {
+	list, _, total, err := idx.ProcessListWithCursor(limit, offset, "", entityID, processID,
+		namespace, srcNetworkID, status, withResults, finalResults, manuallyEnded,
+		startDateAfter, startDateBefore, endDateAfter, endDateBefore, minTurnout, nil)
+	return list, total, err
+}
+
+// ProcessListWithCursor is like ProcessList, but also accepts an opaque cursor returned
+// by a previous call. If not empty, the list starts right after the last process returned
+// by that call, and offset is applied from there. It also returns the cursor to fetch the
+// next page, which is empty if there are no more processes. If unlistedOf is set, the
+// unlisted processes of that entity are returned too. The total number of processes is
+// only counted when cursor is empty, as it requires scanning all of them, and it is zero
+// otherwise.
+func (idx *Indexer) ProcessListWithCursor(limit, offset int, cursor string, entityID string, processID string,
+	namespace uint32, srcNetworkID int32, status models.ProcessStatus,
+	withResults, finalResults, manuallyEnded *bool,
+	startDateAfter, startDateBefore, endDateAfter, endDateBefore *time.Time,
+	minTurnout float64, unlistedOf types.EntityID,
+) ([][]byte, string, uint64, error) {
 	if offset < 0 {
-		return nil, 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
+		return nil, "", 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
 	}
 	if limit <= 0 {
-		return nil, 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
+		return nil, "", 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
+	}
+	if minTurnout < 0 {
+		return nil, "", 0, fmt.Errorf("invalid value: minTurnout cannot be %v", minTurnout)
 	}
 	// Filter match function for source network Id
 	if _, ok := models.SourceNetworkId_name[srcNetworkID]; !ok {
-		return nil, 0, fmt.Errorf("sourceNetworkId is unknown %d", srcNetworkID)
-	}
-	results, err := idx.readOnlyQuery.SearchProcesses(context.TODO(), indexerdb.SearchProcessesParams{
-		EntityIDSubstr:  entityID,
-		Namespace:       int64(namespace),
-		Status:          int64(status),
-		SourceNetworkID: int64(srcNetworkID),
-		IDSubstr:        strings.ToLower(processID), // we search in lowercase
-		Offset:          int64(offset),
-		Limit:           int64(limit),
-		HaveResults:     boolToInt(withResults),
-		FinalResults:    boolToInt(finalResults),
-		ManuallyEnded:   boolToInt(manuallyEnded),
-		StartDateAfter:  startDateAfter,
-		StartDateBefore: startDateBefore,
-		EndDateAfter:    endDateAfter,
-		EndDateBefore:   endDateBefore,
-	})
+		return nil, "", 0, fmt.Errorf("sourceNetworkId is unknown %d", srcNetworkID)
+	}
+	params := indexerdb.SearchProcessesParams{
+		EntityIDSubstr:   entityID,
+		Namespace:        int64(namespace),
+		Status:           int64(status),
+		SourceNetworkID:  int64(srcNetworkID),
+		IDSubstr:         strings.ToLower(processID), // we search in lowercase
+		Offset:           int64(offset),
+		Limit:            int64(limit) + 1, // fetch one more to know if there is a next page
+		HaveResults:      boolToInt(withResults),
+		FinalResults:     boolToInt(finalResults),
+		ManuallyEnded:    boolToInt(manuallyEnded),
+		StartDateAfter:   startDateAfter,
+		StartDateBefore:  startDateBefore,
+		EndDateAfter:     endDateAfter,
+		EndDateBefore:    endDateBefore,
+		MinTurnout:       minTurnout,
+		UnlistedEntityID: unlistedOf,
+	}
+	if cursor != "" {
+		var creationTime time.Time
+		var id []byte
+		if err := decodeCursor(cursor, &creationTime, &id); err != nil {
+			return nil, "", 0, err
+		}
+		params.CursorCreationTime = creationTime
+		params.CursorID = id
+	}
+	results, err := idx.readOnlyQuery.SearchProcesses(context.TODO(), params)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", 0, err
+	}
+	nextCursor := ""
+	if len(results) > limit {
+		results = results[:limit]
+		last := results[limit-1]
+		nextCursor = encodeCursor(last.CreationTime, last.ID)
 	}
 	list := [][]byte{}
 	for _, row := range results {
 		list = append(list, row.ID)
 	}
-	if len(results) == 0 {
-		return list, 0, nil
+	if len(results) == 0 || cursor != "" {
+		return list, nextCursor, 0, nil
+	}
+	total, err := idx.readOnlyQuery.CountSearchProcesses(context.TODO(), indexerdb.CountSearchProcessesParams{
+		EntityIDSubstr:   params.EntityIDSubstr,
+		Namespace:        params.Namespace,
+		Status:           params.Status,
+		SourceNetworkID:  params.SourceNetworkID,
+		IDSubstr:         params.IDSubstr,
+		HaveResults:      params.HaveResults,
+		FinalResults:     params.FinalResults,
+		ManuallyEnded:    params.ManuallyEnded,
+		StartDateAfter:   params.StartDateAfter,
+		StartDateBefore:  params.StartDateBefore,
+		EndDateAfter:     params.EndDateAfter,
+		EndDateBefore:    params.EndDateBefore,
+		MinTurnout:       params.MinTurnout,
+		UnlistedEntityID: params.UnlistedEntityID,
+	})
+	if err != nil {
+		return nil, "", 0, err
 	}
-	return list, uint64(results[0].TotalCount), nil
+	return list, nextCursor, uint64(total), nil
 }
 
 // ProcessExists returns whether the passed processID exists in the db.
@@ -98,7 +219,7 @@ func (idx *Indexer) ProcessExists(processID string) bool {
 	if len(processID) != 64 {
 		return false
 	}
-	_, count, err := idx.ProcessList(1, 0, "", processID, 0, 0, 0, nil, nil, nil, nil, nil, nil, nil)
+	_, count, err := idx.ProcessList(1, 0, "", processID, 0, 0, 0, nil, nil, nil, nil, nil, nil, nil, 0)
 	if err != nil {
 		log.Errorw(err, "indexer query failed")
 	}
@@ -233,6 +354,8 @@ func (idx *Indexer) newEmptyProcess(pid []byte) error {
 		Metadata:          p.GetMetadata(),
 		ResultsVotes:      indexertypes.EncodeJSON(results.NewEmptyVotes(options)),
 		ChainID:           idx.App.ChainID(),
+		Unlisted:          vochaintx.ProcessModeUnlisted(p.Mode),
+		NullifierGroup:    nonNullBytes(vochaintx.ProcessModeNullifierGroup(p.Mode)),
 	}
 
 	idx.blockMu.Lock()
@@ -288,6 +411,8 @@ func (idx *Indexer) updateProcess(ctx context.Context, queries *indexerdb.Querie
 		}); err != nil {
 			return err
 		}
+		// its votes are audited once committed
+		idx.blockEndedProcs = append(idx.blockEndedProcs, pid)
 	}
 
 	// If the process is in RESULTS status, and it was not in RESULTS status before, then finalize the results
@@ -312,3 +437,93 @@ func (idx *Indexer) updateProcess(ctx context.Context, queries *indexerdb.Querie
 	}
 	return nil
 }
+
+// indexStatusChanges indexes the pending process status and duration changes
+// made by the transaction with the given hash, which may be nil if they were not
+// made by a transaction. Must be called with blockMu held.
+func (idx *Indexer) indexStatusChanges(ctx context.Context, queries *indexerdb.Queries, txHash []byte) {
+	for _, change := range idx.blockStatusChanges {
+		change.TxHash = nonNullBytes(txHash)
+		if _, err := queries.CreateProcessStatusChange(ctx, change); err != nil {
+			log.Errorw(err, "cannot index process status change")
+		}
+	}
+	idx.blockStatusChanges = nil
+}
+
+// ProcessStatusHistory returns the status and duration changes of the process,
+// in the order they were made.
+func (idx *Indexer) ProcessStatusHistory(pid []byte) ([]*indexertypes.ProcessStatusChange, error) {
+	results, err := idx.readOnlyQuery.GetProcessStatusHistory(context.TODO(), pid)
+	if err != nil {
+		return nil, err
+	}
+	list := []*indexertypes.ProcessStatusChange{}
+	for _, row := range results {
+		change := &indexertypes.ProcessStatusChange{
+			Height:   uint32(row.BlockHeight),
+			TxIndex:  int32(row.BlockIndex),
+			TxHash:   row.TxHash,
+			Duration: uint32(row.Duration),
+		}
+		if row.Status != 0 {
+			change.Status = models.ProcessStatus_name[int32(row.Status)]
+		}
+		list = append(list, change)
+	}
+	return list, nil
+}
+
+// indexCensusHistory indexes the versions of the census history of the process
+// which are not indexed yet. Must be called with blockMu held.
+func (idx *Indexer) indexCensusHistory(ctx context.Context, queries *indexerdb.Queries,
+	pid []byte, history []state.CensusVersion,
+) error {
+	count, err := queries.CountProcessCensusVersions(ctx, pid)
+	if err != nil {
+		return err
+	}
+	for _, version := range history[min(int(count), len(history)):] {
+		if _, err := queries.CreateProcessCensusVersion(ctx, indexerdb.CreateProcessCensusVersionParams{
+			ProcessID:   pid,
+			BlockHeight: int64(version.Height),
+			CensusRoot:  nonNullBytes(version.Root),
+			CensusUri:   version.URI,
+			CensusSize:  int64(version.Size),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProcessCensusHistory returns the versions of the census of the process, from
+// the one it was created with to the current one.
+func (idx *Indexer) ProcessCensusHistory(pid []byte) ([]*indexertypes.CensusVersion, error) {
+	results, err := idx.readOnlyQuery.GetProcessCensusHistory(context.TODO(), pid)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		// the census of the process was never updated
+		proc, err := idx.ProcessInfo(pid)
+		if err != nil {
+			return nil, err
+		}
+		return []*indexertypes.CensusVersion{{
+			Root: proc.CensusRoot,
+			URI:  proc.CensusURI,
+			Size: proc.MaxCensusSize,
+		}}, nil
+	}
+	list := []*indexertypes.CensusVersion{}
+	for _, row := range results {
+		list = append(list, &indexertypes.CensusVersion{
+			Root:   row.CensusRoot,
+			URI:    row.CensusUri,
+			Size:   uint64(row.CensusSize),
+			Height: uint32(row.BlockHeight),
+		})
+	}
+	return list, nil
+}
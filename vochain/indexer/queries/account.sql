@@ -23,4 +23,118 @@ LIMIT sqlc.arg(limit)
 OFFSET sqlc.arg(offset);
 
 -- name: CountAccounts :one
-SELECT COUNT(*) FROM accounts;
\ No newline at end of file
+SELECT COUNT(*) FROM accounts;
+
+-- name: SearchAccountFeed :many
+-- The total count is not computed here, as it requires scanning all the
+-- results, see CountAccountFeed.
+WITH feed AS (
+  SELECT 'transferSent' AS kind, unixepoch(transfer_time) AS time, block_height,
+    LOWER(HEX(tx_hash)) AS ref, tx_hash, to_account AS account,
+    x'' AS process_id, x'' AS nullifier, amount, '' AS tx_type, '' AS reference
+  FROM token_transfers
+  WHERE from_account = sqlc.arg(account)
+  UNION ALL
+  SELECT 'transferReceived', unixepoch(transfer_time), block_height,
+    LOWER(HEX(tx_hash)), tx_hash, from_account,
+    x'', x'', amount, '', ''
+  FROM token_transfers
+  WHERE to_account = sqlc.arg(account)
+  UNION ALL
+  SELECT 'fee', unixepoch(spend_time), block_height,
+    PRINTF('%020d', id), x'', x'',
+    x'', x'', cost, tx_type, reference
+  FROM token_fees
+  WHERE from_account = sqlc.arg(account)
+  UNION ALL
+  SELECT 'election', unixepoch(creation_time), 0,
+    LOWER(HEX(id)), x'', x'',
+    id, x'', 0, '', ''
+  FROM processes
+  WHERE entity_id = sqlc.arg(account)
+  UNION ALL
+  -- the votes are linked to the account by the signer of their transaction,
+  -- which is the address of their voter ID
+  SELECT 'vote', unixepoch(b.time), t.block_height,
+    LOWER(HEX(v.nullifier)), t.hash, x'',
+    v.process_id, v.nullifier, 0, '', ''
+  FROM transactions AS t
+  JOIN votes AS v
+    ON v.block_height = t.block_height
+    AND v.block_index = t.block_index
+  JOIN blocks AS b
+    ON b.height = t.block_height
+  WHERE t.signer = sqlc.arg(account) AND t.type = 'vote'
+  UNION ALL
+  SELECT 'accountUpdate', unixepoch(b.time), t.block_height,
+    LOWER(HEX(t.hash)), t.hash, x'',
+    x'', x'', 0, t.subtype, ''
+  FROM transactions AS t
+  JOIN blocks AS b
+    ON b.height = t.block_height
+  WHERE t.signer = sqlc.arg(account) AND t.type = 'setAccount'
+)
+SELECT *
+FROM feed
+WHERE (
+  sqlc.arg(cursor_time) IS NULL
+  OR time < sqlc.arg(cursor_time)
+  OR (time = sqlc.arg(cursor_time) AND block_height < sqlc.arg(cursor_block_height))
+  OR (time = sqlc.arg(cursor_time) AND block_height = sqlc.arg(cursor_block_height)
+    AND (kind > sqlc.arg(cursor_kind) OR (kind = sqlc.arg(cursor_kind) AND ref > sqlc.arg(cursor_ref))))
+)
+ORDER BY time DESC, block_height DESC, kind ASC, ref ASC
+LIMIT sqlc.arg(limit)
+OFFSET sqlc.arg(offset);
+
+-- name: CountAccountFeed :one
+WITH feed AS (
+  SELECT 'transferSent' AS kind, unixepoch(transfer_time) AS time, block_height,
+    LOWER(HEX(tx_hash)) AS ref, tx_hash, to_account AS account,
+    x'' AS process_id, x'' AS nullifier, amount, '' AS tx_type, '' AS reference
+  FROM token_transfers
+  WHERE from_account = sqlc.arg(account)
+  UNION ALL
+  SELECT 'transferReceived', unixepoch(transfer_time), block_height,
+    LOWER(HEX(tx_hash)), tx_hash, from_account,
+    x'', x'', amount, '', ''
+  FROM token_transfers
+  WHERE to_account = sqlc.arg(account)
+  UNION ALL
+  SELECT 'fee', unixepoch(spend_time), block_height,
+    PRINTF('%020d', id), x'', x'',
+    x'', x'', cost, tx_type, reference
+  FROM token_fees
+  WHERE from_account = sqlc.arg(account)
+  UNION ALL
+  SELECT 'election', unixepoch(creation_time), 0,
+    LOWER(HEX(id)), x'', x'',
+    id, x'', 0, '', ''
+  FROM processes
+  WHERE entity_id = sqlc.arg(account)
+  UNION ALL
+  -- the votes are linked to the account by the signer of their transaction,
+  -- which is the address of their voter ID
+  SELECT 'vote', unixepoch(b.time), t.block_height,
+    LOWER(HEX(v.nullifier)), t.hash, x'',
+    v.process_id, v.nullifier, 0, '', ''
+  FROM transactions AS t
+  JOIN votes AS v
+    ON v.block_height = t.block_height
+    AND v.block_index = t.block_index
+  JOIN blocks AS b
+    ON b.height = t.block_height
+  WHERE t.signer = sqlc.arg(account) AND t.type = 'vote'
+  UNION ALL
+  SELECT 'accountUpdate', unixepoch(b.time), t.block_height,
+    LOWER(HEX(t.hash)), t.hash, x'',
+    x'', x'', 0, t.subtype, ''
+  FROM transactions AS t
+  JOIN blocks AS b
+    ON b.height = t.block_height
+  WHERE t.signer = sqlc.arg(account) AND t.type = 'setAccount'
+)
+SELECT COUNT(*) FROM feed;
+
+-- name: ExistsAccount :one
+SELECT EXISTS(SELECT 1 FROM accounts WHERE account = ?);
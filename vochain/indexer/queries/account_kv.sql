@@ -0,0 +1,13 @@
+-- name: SetAccountKV :execresult
+REPLACE INTO account_kv (
+    account, key, value, height
+) VALUES (?, ?, ?, ?);
+
+-- name: DeleteAccountKV :execresult
+DELETE FROM account_kv
+WHERE account = ? AND key = ?;
+
+-- name: GetAccountKV :many
+SELECT * FROM account_kv
+WHERE account = ?
+ORDER BY key ASC;
@@ -0,0 +1,12 @@
+-- name: CreateAccountRecoveryEvent :execresult
+INSERT INTO account_recovery_events (
+    account, kind, guardian, new_owner, guardians, height
+) VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: SearchAccountRecoveryEvents :many
+SELECT *, COUNT(*) OVER() AS total_count
+FROM account_recovery_events
+WHERE account = sqlc.arg(account)
+ORDER BY id DESC
+LIMIT sqlc.arg(limit)
+OFFSET sqlc.arg(offset);
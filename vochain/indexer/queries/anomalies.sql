@@ -0,0 +1,16 @@
+-- name: CreateAnomaly :execresult
+INSERT INTO indexer_anomalies (
+    process_id, block_height, kind, nullifier, details
+) VALUES (?, ?, ?, ?, ?);
+
+-- name: DeleteProcessAnomalies :execresult
+DELETE FROM indexer_anomalies
+WHERE process_id = ?;
+
+-- name: GetProcessAnomalies :many
+SELECT * FROM indexer_anomalies
+WHERE process_id = ?
+ORDER BY id ASC;
+
+-- name: CountAnomalies :one
+SELECT COUNT(*) FROM indexer_anomalies;
@@ -0,0 +1,38 @@
+-- name: ScheduleBackfill :execresult
+INSERT INTO backfills (name) VALUES (?)
+ON CONFLICT(name) DO UPDATE
+SET position  = 0,
+    processed = 0,
+    done      = FALSE;
+
+-- name: UpdateBackfill :execresult
+UPDATE backfills
+SET position  = sqlc.arg(position),
+    processed = processed + sqlc.arg(processed),
+    done      = sqlc.arg(done)
+WHERE name = sqlc.arg(name);
+
+-- name: GetBackfills :many
+SELECT * FROM backfills
+ORDER BY name ASC;
+
+-- name: GetTransactionsWithoutSigner :many
+SELECT rowid, hash, block_height, block_index FROM transactions
+WHERE rowid > sqlc.arg(after_rowid) AND signer = x'' AND signature != x''
+ORDER BY rowid ASC
+LIMIT sqlc.arg(limit);
+
+-- name: SetTransactionSigner :execresult
+UPDATE transactions
+SET signer = sqlc.arg(signer)
+WHERE hash = sqlc.arg(hash);
+
+-- name: GetProcessIDsAfterRowID :many
+SELECT rowid, id FROM processes
+WHERE rowid > sqlc.arg(after_rowid)
+ORDER BY rowid ASC
+LIMIT sqlc.arg(limit);
+
+-- name: CountProcessVotes :one
+SELECT COUNT(*) FROM votes
+WHERE process_id = ?;
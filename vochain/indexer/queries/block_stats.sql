@@ -0,0 +1,60 @@
+-- name: CreateBlockStats :execresult
+INSERT INTO block_stats (
+    height, timestamp, tx_count, fees_burned, vote_count
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+ON CONFLICT(height) DO UPDATE
+SET timestamp   = excluded.timestamp,
+    tx_count    = excluded.tx_count,
+    fees_burned = excluded.fees_burned,
+    vote_count  = excluded.vote_count;
+
+-- name: CreateBlockStatsTxType :execresult
+INSERT INTO block_stats_tx_types (
+    height, type, count
+) VALUES (
+    ?, ?, ?
+)
+ON CONFLICT(height, type) DO UPDATE
+SET count = excluded.count;
+
+-- name: DeleteBlockStatsTxTypes :execresult
+DELETE FROM block_stats_tx_types
+WHERE height = ?;
+
+-- name: GetBlockStats :one
+SELECT * FROM block_stats
+WHERE height = ?
+LIMIT 1;
+
+-- name: GetBlockStatsTxTypes :many
+SELECT type, count FROM block_stats_tx_types
+WHERE height = ?
+ORDER BY type ASC;
+
+-- name: AggregateBlockStats :many
+SELECT
+    CAST((timestamp - CAST(sqlc.arg(from_timestamp) AS INTEGER)) / CAST(sqlc.arg(interval) AS INTEGER) AS INTEGER) AS bucket,
+    COUNT(*) AS block_count,
+    CAST(SUM(tx_count) AS INTEGER) AS tx_count,
+    CAST(SUM(fees_burned) AS INTEGER) AS fees_burned,
+    CAST(SUM(vote_count) AS INTEGER) AS vote_count
+FROM block_stats
+WHERE timestamp >= sqlc.arg(from_timestamp)
+  AND timestamp < sqlc.arg(to_timestamp)
+GROUP BY bucket
+ORDER BY bucket ASC;
+
+-- name: AggregateBlockStatsTxTypes :many
+SELECT
+    CAST((s.timestamp - CAST(sqlc.arg(from_timestamp) AS INTEGER)) / CAST(sqlc.arg(interval) AS INTEGER) AS INTEGER) AS bucket,
+    t.type,
+    CAST(SUM(t.count) AS INTEGER) AS count
+FROM block_stats AS s
+JOIN block_stats_tx_types AS t
+  ON t.height = s.height
+WHERE s.timestamp >= sqlc.arg(from_timestamp)
+  AND s.timestamp < sqlc.arg(to_timestamp)
+GROUP BY bucket, t.type
+ORDER BY bucket ASC, t.type ASC;
@@ -21,6 +21,32 @@ SELECT * FROM blocks
 WHERE hash = ?
 LIMIT 1;
 
+-- name: GetBlockProposer :one
+SELECT
+    v.name,
+    v.account_address,
+    CAST(COALESCE((
+        SELECT c.power FROM validator_set_changes AS c
+        WHERE c.address = v.address AND c.height <= sqlc.arg(height)
+        ORDER BY c.height DESC
+        LIMIT 1
+    ), 0) AS INTEGER) AS power
+FROM validators AS v
+WHERE v.address = sqlc.arg(address)
+LIMIT 1;
+
+-- name: GetBlockAtTime :one
+SELECT * FROM blocks
+WHERE time <= sqlc.arg(before)
+ORDER BY time DESC, height DESC
+LIMIT 1;
+
+-- name: GetFirstBlockFromHeight :one
+SELECT * FROM blocks
+WHERE height >= ?
+ORDER BY height ASC
+LIMIT 1;
+
 -- name: LastBlockHeight :one
 SELECT height FROM blocks
 ORDER BY height DESC
@@ -29,10 +55,20 @@ LIMIT 1;
 -- name: SearchBlocks :many
 SELECT
     b.*,
-    COUNT(t.block_index) AS tx_count
+    COUNT(t.block_index) AS tx_count,
+    CAST(COALESCE(v.name, '') AS TEXT) AS proposer_name,
+    CAST(COALESCE(v.account_address, x'') AS BLOB) AS proposer_account,
+    CAST(COALESCE((
+        SELECT c.power FROM validator_set_changes AS c
+        WHERE c.address = b.proposer_address AND c.height <= b.height
+        ORDER BY c.height DESC
+        LIMIT 1
+    ), 0) AS INTEGER) AS proposer_power
 FROM blocks AS b
 LEFT JOIN transactions AS t
     ON b.height = t.block_height
+LEFT JOIN validators AS v
+    ON b.proposer_address = v.address
 WHERE (
     (sqlc.arg(chain_id) = '' OR b.chain_id = sqlc.arg(chain_id))
     AND LENGTH(sqlc.arg(hash_substr)) <= 64 -- if passed arg is longer, then just abort the query
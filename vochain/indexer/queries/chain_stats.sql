@@ -0,0 +1,59 @@
+-- name: AddStatsVotesHourly :execresult
+INSERT INTO stats_votes_hourly (
+    hour, vote_count
+) VALUES (
+    ?, ?
+)
+ON CONFLICT(hour) DO UPDATE
+SET vote_count = vote_count + excluded.vote_count;
+
+-- name: AddStatsTxsDaily :execresult
+INSERT INTO stats_txs_daily (
+    day, type, count
+) VALUES (
+    ?, ?, ?
+)
+ON CONFLICT(day, type) DO UPDATE
+SET count = count + excluded.count;
+
+-- name: AddStatsAccountsDaily :execresult
+INSERT INTO stats_accounts_daily (
+    day, new_accounts
+) VALUES (
+    ?, ?
+)
+ON CONFLICT(day) DO UPDATE
+SET new_accounts = new_accounts + excluded.new_accounts;
+
+-- name: AddStatsFeesDaily :execresult
+INSERT INTO stats_fees_daily (
+    day, fees_burned
+) VALUES (
+    ?, ?
+)
+ON CONFLICT(day) DO UPDATE
+SET fees_burned = fees_burned + excluded.fees_burned;
+
+-- name: GetStatsVotesHourly :many
+SELECT hour, vote_count FROM stats_votes_hourly
+WHERE hour >= sqlc.arg(from_timestamp)
+  AND hour < sqlc.arg(to_timestamp)
+ORDER BY hour ASC;
+
+-- name: GetStatsTxsDaily :many
+SELECT day, type, count FROM stats_txs_daily
+WHERE day >= sqlc.arg(from_timestamp)
+  AND day < sqlc.arg(to_timestamp)
+ORDER BY day ASC, type ASC;
+
+-- name: GetStatsAccountsDaily :many
+SELECT day, new_accounts FROM stats_accounts_daily
+WHERE day >= sqlc.arg(from_timestamp)
+  AND day < sqlc.arg(to_timestamp)
+ORDER BY day ASC;
+
+-- name: GetStatsFeesDaily :many
+SELECT day, fees_burned FROM stats_fees_daily
+WHERE day >= sqlc.arg(from_timestamp)
+  AND day < sqlc.arg(to_timestamp)
+ORDER BY day ASC;
@@ -0,0 +1,48 @@
+-- name: GetProcessesToCheckAvailability :many
+SELECT p.id, p.metadata, p.census_uri
+FROM processes AS p
+LEFT JOIN metadata_availability AS m
+  ON m.process_id = p.id
+WHERE p.metadata LIKE sqlc.arg(uri_prefix) || '%'
+  OR p.census_uri LIKE sqlc.arg(uri_prefix) || '%'
+ORDER BY m.last_checked IS NOT NULL, m.last_checked ASC, p.id ASC
+LIMIT sqlc.arg(limit);
+
+-- name: SetMetadataAvailability :execresult
+INSERT INTO metadata_availability (
+    process_id, metadata_uri, metadata_available, metadata_last_seen,
+    census_uri, census_available, census_last_seen, last_checked
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT(process_id) DO UPDATE
+SET metadata_uri       = excluded.metadata_uri,
+    metadata_available = excluded.metadata_available,
+    metadata_last_seen = CASE
+      WHEN NOT excluded.metadata_available AND excluded.metadata_uri = metadata_availability.metadata_uri
+      THEN metadata_availability.metadata_last_seen
+      ELSE excluded.metadata_last_seen
+    END,
+    census_uri         = excluded.census_uri,
+    census_available   = excluded.census_available,
+    census_last_seen   = CASE
+      WHEN NOT excluded.census_available AND excluded.census_uri = metadata_availability.census_uri
+      THEN metadata_availability.census_last_seen
+      ELSE excluded.census_last_seen
+    END,
+    last_checked       = excluded.last_checked;
+
+-- name: SearchUnavailableMetadata :many
+WITH results AS (
+  SELECT m.*, p.entity_id
+  FROM metadata_availability AS m
+  JOIN processes AS p
+    ON p.id = m.process_id
+  WHERE (m.metadata_uri != '' AND NOT m.metadata_available)
+    OR (m.census_uri != '' AND NOT m.census_available)
+)
+SELECT *, COUNT(*) OVER() AS total_count
+FROM results
+ORDER BY last_checked DESC, process_id ASC
+LIMIT sqlc.arg(limit)
+OFFSET sqlc.arg(offset);
@@ -0,0 +1,13 @@
+-- name: CreateProcessCensusVersion :execresult
+INSERT INTO process_census_history (
+    process_id, block_height, census_root, census_uri, census_size
+) VALUES (?, ?, ?, ?, ?);
+
+-- name: CountProcessCensusVersions :one
+SELECT COUNT(*) FROM process_census_history
+WHERE process_id = ?;
+
+-- name: GetProcessCensusHistory :many
+SELECT * FROM process_census_history
+WHERE process_id = ?
+ORDER BY id ASC;
@@ -0,0 +1,9 @@
+-- name: CreateProcessStatusChange :execresult
+INSERT INTO process_status_history (
+    process_id, block_height, block_index, tx_hash, status, duration
+) VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetProcessStatusHistory :many
+SELECT * FROM process_status_history
+WHERE process_id = ?
+ORDER BY id ASC;
@@ -8,7 +8,7 @@ INSERT INTO processes (
 	private_keys, public_keys,
 	question_index, creation_time,
 	source_block_height, source_network_id,
-	chain_id,
+	chain_id, unlisted, nullifier_group,
 
 	results_votes, results_weight, results_block_height
 ) VALUES (
@@ -20,7 +20,7 @@ INSERT INTO processes (
 	?, ?,
 	?, ?,
 	?, ?,
-	?,
+	?, ?, ?,
 
 	?, '"0"', 0
 );
@@ -30,10 +30,16 @@ SELECT * FROM processes
 WHERE id = ?
 LIMIT 1;
 
+-- name: GetNullifierGroupProcesses :many
+SELECT id FROM processes
+WHERE entity_id = sqlc.arg(entity_id) AND nullifier_group = sqlc.arg(nullifier_group)
+ORDER BY creation_time ASC, id ASC;
+
 -- name: SearchProcesses :many
+-- The total count is not computed here, as it requires scanning all the
+-- results, see CountSearchProcesses.
 WITH results AS (
-	SELECT *,
-			COUNT(*) OVER() AS total_count
+	SELECT *
 	FROM processes
 	WHERE (
 		LENGTH(sqlc.arg(entity_id_substr)) <= 40 -- if passed arg is longer, then just abort the query
@@ -72,14 +78,66 @@ WITH results AS (
 		AND (sqlc.arg(start_date_before) IS NULL OR start_date <= sqlc.arg(start_date_before))
 		AND (sqlc.arg(end_date_after) IS NULL OR end_date >= sqlc.arg(end_date_after))
 		AND (sqlc.arg(end_date_before) IS NULL OR end_date <= sqlc.arg(end_date_before))
+		AND (sqlc.arg(min_turnout) = 0 OR turnout >= sqlc.arg(min_turnout))
+		-- the unlisted processes are only listed for the given entity
+		AND (unlisted = FALSE OR entity_id = sqlc.arg(unlisted_entity_id))
 	)
 )
-SELECT id, total_count
+SELECT id, creation_time
 FROM results
+WHERE (
+	sqlc.arg(cursor_creation_time) IS NULL
+	OR creation_time < sqlc.arg(cursor_creation_time)
+	OR (creation_time = sqlc.arg(cursor_creation_time) AND id > sqlc.arg(cursor_id))
+)
 ORDER BY creation_time DESC, id ASC
 LIMIT sqlc.arg(limit)
 OFFSET sqlc.arg(offset);
 
+-- name: CountSearchProcesses :one
+SELECT COUNT(*) FROM processes
+WHERE (
+	LENGTH(sqlc.arg(entity_id_substr)) <= 40 -- if passed arg is longer, then just abort the query
+	AND (
+		sqlc.arg(entity_id_substr) = ''
+		OR (LENGTH(sqlc.arg(entity_id_substr)) = 40 AND LOWER(HEX(entity_id)) = LOWER(sqlc.arg(entity_id_substr)))
+		OR (LENGTH(sqlc.arg(entity_id_substr)) < 40 AND INSTR(LOWER(HEX(entity_id)), LOWER(sqlc.arg(entity_id_substr))) > 0)
+		-- TODO: consider keeping an entity_id_hex column for faster searches
+	)
+	AND (sqlc.arg(namespace) = 0 OR namespace = sqlc.arg(namespace))
+	AND (sqlc.arg(status) = 0 OR status = sqlc.arg(status))
+	AND (sqlc.arg(source_network_id) = 0 OR source_network_id = sqlc.arg(source_network_id))
+	AND LENGTH(sqlc.arg(id_substr)) <= 64 -- if passed arg is longer, then just abort the query
+	AND (
+		sqlc.arg(id_substr) = ''
+		OR (LENGTH(sqlc.arg(id_substr)) = 64 AND LOWER(HEX(id)) = LOWER(sqlc.arg(id_substr)))
+		OR (LENGTH(sqlc.arg(id_substr)) < 64 AND INSTR(LOWER(HEX(id)), LOWER(sqlc.arg(id_substr))) > 0)
+		-- TODO: consider keeping an id_hex column for faster searches
+	)
+	AND (
+		sqlc.arg(have_results) = -1
+		OR (sqlc.arg(have_results) = 1 AND have_results = TRUE)
+		OR (sqlc.arg(have_results) = 0 AND have_results = FALSE)
+	)
+	AND (
+		sqlc.arg(final_results) = -1
+		OR (sqlc.arg(final_results) = 1 AND final_results = TRUE)
+		OR (sqlc.arg(final_results) = 0 AND final_results = FALSE)
+	)
+	AND (
+		sqlc.arg(manually_ended) = -1
+		OR (sqlc.arg(manually_ended) = 1 AND manually_ended = TRUE)
+		OR (sqlc.arg(manually_ended) = 0 AND manually_ended = FALSE)
+	)
+	AND (sqlc.arg(start_date_after) IS NULL OR start_date >= sqlc.arg(start_date_after))
+	AND (sqlc.arg(start_date_before) IS NULL OR start_date <= sqlc.arg(start_date_before))
+	AND (sqlc.arg(end_date_after) IS NULL OR end_date >= sqlc.arg(end_date_after))
+	AND (sqlc.arg(end_date_before) IS NULL OR end_date <= sqlc.arg(end_date_before))
+	AND (sqlc.arg(min_turnout) = 0 OR turnout >= sqlc.arg(min_turnout))
+	-- the unlisted processes are only listed for the given entity
+	AND (unlisted = FALSE OR entity_id = sqlc.arg(unlisted_entity_id))
+);
+
 -- name: UpdateProcessFromState :execresult
 UPDATE processes
 SET census_root         = sqlc.arg(census_root),
@@ -125,9 +183,19 @@ UPDATE processes
 SET vote_count = (SELECT COUNT(*) FROM votes WHERE process_id = id)
 WHERE id = sqlc.arg(id);
 
+-- name: UpdateProcessTurnout :execresult
+UPDATE processes
+SET turnout = sqlc.arg(turnout),
+	weight_turnout = sqlc.arg(weight_turnout)
+WHERE id = sqlc.arg(id);
+
 -- name: GetProcessCount :one
 SELECT COUNT(*) FROM processes;
 
+-- name: GetProcessVoteCount :one
+SELECT vote_count FROM processes
+WHERE id = ?;
+
 -- name: GetEntityCount :one
 SELECT COUNT(DISTINCT entity_id) FROM processes;
 
@@ -0,0 +1,19 @@
+-- name: CreateResultsProof :execresult
+INSERT INTO results_proofs (
+    process_id, height, state_root, processes_root,
+    processes_proof, process_leaf, process_proof
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT(process_id) DO UPDATE
+SET height          = excluded.height,
+    state_root      = excluded.state_root,
+    processes_root  = excluded.processes_root,
+    processes_proof = excluded.processes_proof,
+    process_leaf    = excluded.process_leaf,
+    process_proof   = excluded.process_proof;
+
+-- name: GetResultsProof :one
+SELECT * FROM results_proofs
+WHERE process_id = ?
+LIMIT 1;
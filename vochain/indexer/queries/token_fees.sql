@@ -22,3 +22,7 @@ FROM results
 ORDER BY spend_time DESC
 LIMIT sqlc.arg(limit)
 OFFSET sqlc.arg(offset);
+
+-- name: SumTokenFeesByHeight :one
+SELECT CAST(COALESCE(SUM(cost), 0) AS INTEGER) AS fees FROM token_fees
+WHERE block_height = ?;
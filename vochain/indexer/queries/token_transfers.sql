@@ -13,24 +13,36 @@ WHERE tx_hash = ?
 LIMIT 1;
 
 -- name: SearchTokenTransfers :many
-WITH results AS (
-  SELECT *
-  FROM token_transfers
-  WHERE (
-    (sqlc.arg(from_or_to_account) = '' OR (
-		LOWER(HEX(from_account)) = LOWER(sqlc.arg(from_or_to_account))
-		OR LOWER(HEX(to_account)) = LOWER(sqlc.arg(from_or_to_account))
-	))
-    AND (sqlc.arg(from_account) = '' OR LOWER(HEX(from_account)) = LOWER(sqlc.arg(from_account)))
-    AND (sqlc.arg(to_account) = '' OR LOWER(HEX(to_account)) = LOWER(sqlc.arg(to_account)))
+-- The total count is not computed here, as it requires scanning all the
+-- results, see CountSearchTokenTransfers.
+SELECT *
+FROM token_transfers
+WHERE
+  (sqlc.arg(from_or_to_account) = '' OR (
+    LOWER(HEX(from_account)) = LOWER(sqlc.arg(from_or_to_account))
+    OR LOWER(HEX(to_account)) = LOWER(sqlc.arg(from_or_to_account))
+  ))
+  AND (sqlc.arg(from_account) = '' OR LOWER(HEX(from_account)) = LOWER(sqlc.arg(from_account)))
+  AND (sqlc.arg(to_account) = '' OR LOWER(HEX(to_account)) = LOWER(sqlc.arg(to_account)))
+  AND (
+    sqlc.arg(cursor_transfer_time) IS NULL
+    OR transfer_time < sqlc.arg(cursor_transfer_time)
+    OR (transfer_time = sqlc.arg(cursor_transfer_time) AND tx_hash > sqlc.arg(cursor_tx_hash))
   )
-)
-SELECT *, COUNT(*) OVER() AS total_count
-FROM results
-ORDER BY transfer_time DESC
+ORDER BY transfer_time DESC, tx_hash ASC
 LIMIT sqlc.arg(limit)
 OFFSET sqlc.arg(offset);
 
+-- name: CountSearchTokenTransfers :one
+SELECT COUNT(*) FROM token_transfers
+WHERE
+  (sqlc.arg(from_or_to_account) = '' OR (
+    LOWER(HEX(from_account)) = LOWER(sqlc.arg(from_or_to_account))
+    OR LOWER(HEX(to_account)) = LOWER(sqlc.arg(from_or_to_account))
+  ))
+  AND (sqlc.arg(from_account) = '' OR LOWER(HEX(from_account)) = LOWER(sqlc.arg(from_account)))
+  AND (sqlc.arg(to_account) = '' OR LOWER(HEX(to_account)) = LOWER(sqlc.arg(to_account)));
+
 -- name: CountTokenTransfersByAccount :one
 SELECT COUNT(*) FROM token_transfers
 WHERE to_account = sqlc.arg(account) OR
@@ -0,0 +1,14 @@
+-- name: CreateTransactionReceipt :execresult
+INSERT INTO transaction_receipts (
+	hash, cost, events
+) VALUES (
+	?, ?, ?
+)
+ON CONFLICT(hash) DO UPDATE
+SET cost   = excluded.cost,
+    events = excluded.events;
+
+-- name: GetTransactionReceipt :one
+SELECT * FROM transaction_receipts
+WHERE hash = ?
+LIMIT 1;
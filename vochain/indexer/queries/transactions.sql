@@ -31,8 +31,32 @@ SELECT * FROM transactions
 WHERE block_height = ? AND block_index = ?
 LIMIT 1;
 
+-- name: SearchTransactionsBySigner :many
+-- Like SearchTransactions filtering by signer, but comparing the signer as is,
+-- so that index_transactions_signer_height is used.
+SELECT *
+FROM transactions
+WHERE
+  signer = sqlc.arg(signer)
+  AND (sqlc.arg(tx_type) = '' OR LOWER(type) = LOWER(sqlc.arg(tx_type)))
+  AND (
+    sqlc.arg(cursor_block_height) IS NULL
+    OR block_height < sqlc.arg(cursor_block_height)
+    OR (block_height = sqlc.arg(cursor_block_height) AND block_index < sqlc.arg(cursor_block_index))
+  )
+ORDER BY block_height DESC, block_index DESC
+LIMIT sqlc.arg(limit)
+OFFSET sqlc.arg(offset);
+
+-- name: CountTransactionsByTypeAndHeight :many
+SELECT type, COUNT(*) AS count FROM transactions
+WHERE block_height = ?
+GROUP BY type;
+
 -- name: SearchTransactions :many
-SELECT *, COUNT(*) OVER() AS total_count
+-- The total count is not computed here, as it requires scanning all the
+-- results, see CountSearchTransactions.
+SELECT *
 FROM transactions
 WHERE
   (sqlc.arg(block_height) = 0 OR block_height = sqlc.arg(block_height))
@@ -43,8 +67,36 @@ WHERE
     sqlc.arg(hash_substr) = ''
     OR (LENGTH(sqlc.arg(hash_substr)) = 64 AND LOWER(HEX(hash)) = LOWER(sqlc.arg(hash_substr)))
     OR (LENGTH(sqlc.arg(hash_substr)) < 64 AND INSTR(LOWER(HEX(hash)), LOWER(sqlc.arg(hash_substr))) > 0)
-    -- TODO: consider keeping an hash_hex column for faster searches
+  )
+  AND (
+    sqlc.arg(cursor_block_height) IS NULL
+    OR block_height < sqlc.arg(cursor_block_height)
+    OR (block_height = sqlc.arg(cursor_block_height) AND block_index < sqlc.arg(cursor_block_index))
   )
 ORDER BY block_height DESC, block_index DESC
 LIMIT sqlc.arg(limit)
 OFFSET sqlc.arg(offset);
+
+-- name: CountSearchTransactions :one
+SELECT COUNT(*) FROM transactions
+WHERE
+  (sqlc.arg(block_height) = 0 OR block_height = sqlc.arg(block_height))
+  AND (sqlc.arg(tx_type) = '' OR LOWER(type) = LOWER(sqlc.arg(tx_type)))
+  AND (sqlc.arg(tx_subtype) = '' OR LOWER(subtype) = LOWER(sqlc.arg(tx_subtype)))
+  AND (sqlc.arg(tx_signer) = '' OR LOWER(HEX(signer)) = LOWER(sqlc.arg(tx_signer)))
+  AND (
+    sqlc.arg(hash_substr) = ''
+    OR (LENGTH(sqlc.arg(hash_substr)) = 64 AND LOWER(HEX(hash)) = LOWER(sqlc.arg(hash_substr)))
+    OR (LENGTH(sqlc.arg(hash_substr)) < 64 AND INSTR(LOWER(HEX(hash)), LOWER(sqlc.arg(hash_substr))) > 0)
+  );
+
+-- name: CountTransactionsBySigner :one
+SELECT COUNT(*) FROM transactions
+WHERE
+  signer = sqlc.arg(signer)
+  AND (sqlc.arg(tx_type) = '' OR LOWER(type) = LOWER(sqlc.arg(tx_type)));
+
+-- name: GetTransactionsMaxRowID :one
+-- The transactions are never deleted and keep their rowid when updated, so the
+-- max rowid is the number of transactions.
+SELECT CAST(COALESCE(MAX(rowid), 0) AS INTEGER) FROM transactions;
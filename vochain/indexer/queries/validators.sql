@@ -0,0 +1,84 @@
+-- name: CreateValidator :execresult
+INSERT INTO validators (
+    address, account_address, pub_key, name, power, join_height, last_change_height
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT(address) DO UPDATE
+SET account_address    = excluded.account_address,
+    pub_key            = excluded.pub_key,
+    name               = excluded.name,
+    power              = excluded.power,
+    join_height        = excluded.join_height,
+    last_change_height = excluded.last_change_height;
+
+-- name: UpdateValidatorPower :execresult
+UPDATE validators
+SET power              = sqlc.arg(power),
+    last_change_height = sqlc.arg(last_change_height)
+WHERE address = sqlc.arg(address);
+
+-- name: GetValidator :one
+SELECT * FROM validators
+WHERE address = ?
+LIMIT 1;
+
+-- name: GetValidatorPowers :many
+SELECT address, power FROM validators;
+
+-- name: SearchValidators :many
+WITH results AS (
+  SELECT *
+  FROM validators
+)
+SELECT *, COUNT(*) OVER() AS total_count
+FROM results
+ORDER BY power DESC, address ASC
+LIMIT sqlc.arg(limit)
+OFFSET sqlc.arg(offset);
+
+-- name: CreateValidatorSetChange :execresult
+INSERT INTO validator_set_changes (
+    height, address, power
+) VALUES (
+    ?, ?, ?
+)
+ON CONFLICT(height, address) DO UPDATE
+SET power = excluded.power;
+
+-- name: SearchValidatorSetChanges :many
+WITH results AS (
+  SELECT *
+  FROM validator_set_changes
+  WHERE (sqlc.arg(address) = '' OR LOWER(HEX(address)) = LOWER(sqlc.arg(address)))
+)
+SELECT *, COUNT(*) OVER() AS total_count
+FROM results
+ORDER BY height DESC, address ASC
+LIMIT sqlc.arg(limit)
+OFFSET sqlc.arg(offset);
+
+-- name: CreateValidatorSignature :execresult
+INSERT INTO validator_signatures (
+    address, height
+) VALUES (
+    ?, ?
+)
+ON CONFLICT DO NOTHING;
+
+-- name: CountValidatorSignatures :one
+SELECT COUNT(*) FROM validator_signatures
+WHERE address = sqlc.arg(address)
+  AND height >= sqlc.arg(from_height)
+  AND height <= sqlc.arg(to_height);
+
+-- name: CountValidatorProposals :one
+SELECT COUNT(*) FROM blocks
+WHERE proposer_address = sqlc.arg(address)
+  AND height >= sqlc.arg(from_height)
+  AND height <= sqlc.arg(to_height);
+
+-- name: CountBlocksInRange :one
+SELECT COUNT(*) FROM blocks
+WHERE height >= sqlc.arg(from_height)
+  AND height <= sqlc.arg(to_height);
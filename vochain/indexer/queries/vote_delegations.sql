@@ -0,0 +1,21 @@
+-- name: SetVoteDelegation :execresult
+REPLACE INTO vote_delegations (
+    process_id, delegator, delegate, weight, height
+) VALUES (?, ?, ?, ?, ?);
+
+-- name: DeleteVoteDelegation :execresult
+DELETE FROM vote_delegations
+WHERE process_id = ? AND delegator = ?;
+
+-- name: SearchVoteDelegations :many
+WITH results AS (
+  SELECT *
+  FROM vote_delegations
+  WHERE process_id = sqlc.arg(process_id)
+    AND (sqlc.arg(delegate) = '' OR LOWER(HEX(delegate)) = LOWER(sqlc.arg(delegate)))
+)
+SELECT *, COUNT(*) OVER() AS total_count
+FROM results
+ORDER BY delegate ASC, delegator ASC
+LIMIT sqlc.arg(limit)
+OFFSET sqlc.arg(offset);
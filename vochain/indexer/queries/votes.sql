@@ -2,52 +2,115 @@
 REPLACE INTO votes (
 	nullifier, process_id, block_height, block_index,
 	weight, voter_id, overwrite_count,
-	encryption_key_indexes, package
+	encryption_key_indexes, package, block_time
 ) VALUES (
 	?, ?, ?, ?,
 	?, ?, ?,
-	?, ?
+	?, ?, ?
 );
 
+-- name: SetVotesBlockTime :execresult
+-- Sets the time of the votes of the block once it is indexed, since the time
+-- the votes are indexed with is the one of the application.
+UPDATE votes
+SET block_time = sqlc.arg(block_time)
+WHERE block_height = sqlc.arg(block_height);
+
 -- name: GetVote :one
-SELECT v.*, t.hash AS tx_hash, b.time AS block_time FROM votes AS v
+SELECT v.*, t.hash AS tx_hash FROM votes AS v
 LEFT JOIN transactions AS t
 	ON v.block_height = t.block_height
 	AND v.block_index = t.block_index
-LEFT JOIN blocks AS b
-	ON v.block_height = b.height
 WHERE v.nullifier = ?
 LIMIT 1;
 
+-- name: GetProcessNullifiers :many
+SELECT nullifier, block_height FROM votes
+WHERE process_id = ? AND block_height > ?;
+
 -- name: CountVotes :one
 SELECT COUNT(*) FROM votes;
 
+-- name: CountVotesByHeight :one
+SELECT COUNT(*) FROM votes
+WHERE block_height = ?;
+
 -- name: SearchVotes :many
-WITH results AS (
-	SELECT v.*, t.hash
-	FROM votes AS v
-	LEFT JOIN transactions AS t
-		ON v.block_height = t.block_height
-		AND v.block_index = t.block_index
-	WHERE (
-		LENGTH(sqlc.arg(process_id_substr)) <= 64 -- if passed arg is longer, then just abort the query
-		AND (
-			sqlc.arg(process_id_substr) = ''
-			OR (LENGTH(sqlc.arg(process_id_substr)) = 64 AND LOWER(HEX(process_id)) = LOWER(sqlc.arg(process_id_substr)))
-			OR (LENGTH(sqlc.arg(process_id_substr)) < 64 AND INSTR(LOWER(HEX(process_id)), LOWER(sqlc.arg(process_id_substr))) > 0)
-			-- TODO: consider keeping an process_id_hex column for faster searches
-		)
-		AND LENGTH(sqlc.arg(nullifier_substr)) <= 64 -- if passed arg is longer, then just abort the query
-		AND (
-			sqlc.arg(nullifier_substr) = ''
-			OR (LENGTH(sqlc.arg(nullifier_substr)) = 64 AND LOWER(HEX(nullifier)) = LOWER(sqlc.arg(nullifier_substr)))
-			OR (LENGTH(sqlc.arg(nullifier_substr)) < 64 AND INSTR(LOWER(HEX(nullifier)), LOWER(sqlc.arg(nullifier_substr))) > 0)
-			-- TODO: consider keeping an nullifier_hex column for faster searches
-		)
+-- The total count is not computed here, as it requires scanning all the
+-- results, see CountSearchVotes.
+SELECT v.*, t.hash
+FROM votes AS v
+LEFT JOIN transactions AS t
+	ON v.block_height = t.block_height
+	AND v.block_index = t.block_index
+WHERE (
+	LENGTH(sqlc.arg(process_id_substr)) <= 64 -- if passed arg is longer, then just abort the query
+	AND (
+		sqlc.arg(process_id_substr) = ''
+		OR (LENGTH(sqlc.arg(process_id_substr)) = 64 AND LOWER(HEX(v.process_id)) = LOWER(sqlc.arg(process_id_substr)))
+		OR (LENGTH(sqlc.arg(process_id_substr)) < 64 AND INSTR(LOWER(HEX(v.process_id)), LOWER(sqlc.arg(process_id_substr))) > 0)
+	)
+	AND LENGTH(sqlc.arg(nullifier_substr)) <= 64 -- if passed arg is longer, then just abort the query
+	AND (
+		sqlc.arg(nullifier_substr) = ''
+		OR (LENGTH(sqlc.arg(nullifier_substr)) = 64 AND LOWER(HEX(v.nullifier)) = LOWER(sqlc.arg(nullifier_substr)))
+		OR (LENGTH(sqlc.arg(nullifier_substr)) < 64 AND INSTR(LOWER(HEX(v.nullifier)), LOWER(sqlc.arg(nullifier_substr))) > 0)
 	)
+	AND (sqlc.arg(from_time) IS NULL OR v.block_time >= sqlc.arg(from_time))
+	AND (sqlc.arg(to_time) IS NULL OR v.block_time < sqlc.arg(to_time))
+)
+AND (
+	sqlc.arg(cursor_block_height) IS NULL
+	OR v.block_height < sqlc.arg(cursor_block_height)
+	OR (v.block_height = sqlc.arg(cursor_block_height) AND v.nullifier > sqlc.arg(cursor_nullifier))
 )
-SELECT *, COUNT(*) OVER() AS total_count
-FROM results
-ORDER BY block_height DESC, nullifier ASC
+ORDER BY v.block_height DESC, v.nullifier ASC
 LIMIT sqlc.arg(limit)
 OFFSET sqlc.arg(offset);
+
+-- name: CountSearchVotes :one
+SELECT COUNT(*) FROM votes
+WHERE (
+	LENGTH(sqlc.arg(process_id_substr)) <= 64 -- if passed arg is longer, then just abort the query
+	AND (
+		sqlc.arg(process_id_substr) = ''
+		OR (LENGTH(sqlc.arg(process_id_substr)) = 64 AND LOWER(HEX(process_id)) = LOWER(sqlc.arg(process_id_substr)))
+		OR (LENGTH(sqlc.arg(process_id_substr)) < 64 AND INSTR(LOWER(HEX(process_id)), LOWER(sqlc.arg(process_id_substr))) > 0)
+	)
+	AND LENGTH(sqlc.arg(nullifier_substr)) <= 64 -- if passed arg is longer, then just abort the query
+	AND (
+		sqlc.arg(nullifier_substr) = ''
+		OR (LENGTH(sqlc.arg(nullifier_substr)) = 64 AND LOWER(HEX(nullifier)) = LOWER(sqlc.arg(nullifier_substr)))
+		OR (LENGTH(sqlc.arg(nullifier_substr)) < 64 AND INSTR(LOWER(HEX(nullifier)), LOWER(sqlc.arg(nullifier_substr))) > 0)
+	)
+	AND (sqlc.arg(from_time) IS NULL OR block_time >= sqlc.arg(from_time))
+	AND (sqlc.arg(to_time) IS NULL OR block_time < sqlc.arg(to_time))
+);
+
+-- name: GetVotesMaxRowID :one
+-- The votes are never deleted, but an overwritten vote gets a new rowid, so the
+-- max rowid is an upper bound of the number of votes.
+SELECT CAST(COALESCE(MAX(rowid), 0) AS INTEGER) FROM votes;
+
+-- name: GetProcessVotePackages :many
+-- Returns the votes of a process in batches, ordered by nullifier and starting
+-- after the given one.
+SELECT nullifier, weight, encryption_key_indexes, package FROM votes
+WHERE process_id = sqlc.arg(process_id) AND nullifier > sqlc.arg(after_nullifier)
+ORDER BY nullifier ASC
+LIMIT sqlc.arg(limit);
+
+-- name: GetProcessEnvelopes :many
+-- Like GetProcessVotePackages, but with all the details of the votes.
+SELECT v.*, t.hash AS tx_hash FROM votes AS v
+LEFT JOIN transactions AS t
+	ON v.block_height = t.block_height
+	AND v.block_index = t.block_index
+WHERE v.process_id = sqlc.arg(process_id) AND v.nullifier > sqlc.arg(after_nullifier)
+ORDER BY v.nullifier ASC
+LIMIT sqlc.arg(limit);
+
+-- name: SetVoteDecryptedPackage :execresult
+UPDATE votes
+SET decrypted_package = sqlc.arg(decrypted_package)
+WHERE nullifier = sqlc.arg(nullifier);
@@ -0,0 +1,126 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+)
+
+const (
+	// QueryMaxRows is the maximum number of rows returned by QueryReadOnly.
+	QueryMaxRows = 1000
+	// QueryTimeout is the maximum duration of a QueryReadOnly query.
+	QueryTimeout = 10 * time.Second
+	// queryMaxConns is the maximum number of QueryReadOnly queries running
+	// concurrently, so that they cannot exhaust the resources of the indexer.
+	queryMaxConns = 2
+	// sqliteRecursive is the SQLITE_RECURSIVE authorizer action code, of the
+	// recursive common table expressions, not defined by go-sqlite3.
+	sqliteRecursive = 33
+)
+
+// QueryAllowedTables is the list of tables which can be read by QueryReadOnly.
+// Note the unlisted processes are not filtered out, since they are only hidden
+// from the process lists and are public in the chain anyway.
+var QueryAllowedTables = []string{
+	"account_kv",
+	"accounts",
+	"block_stats",
+	"block_stats_tx_types",
+	"blocks",
+	"processes",
+	"token_fees",
+	"token_transfers",
+	"transactions",
+	"validator_set_changes",
+	"validator_signatures",
+	"validators",
+	"votes",
+}
+
+// ErrQueryNotAllowed is returned by QueryReadOnly if the query is not a SELECT
+// on the tables of QueryAllowedTables or of the extensions.
+var ErrQueryNotAllowed = errors.New("query not allowed")
+
+// queryAuthorizer is the sqlite authorizer of the QueryReadOnly connections,
+// which only allows to select from the tables of QueryAllowedTables and from
+// the tables of the extensions (see ExtensionTablePrefix).
+func queryAuthorizer(op int, arg1, _, _ string) int {
+	switch op {
+	case sqlite3.SQLITE_SELECT, sqlite3.SQLITE_FUNCTION, sqliteRecursive:
+		return sqlite3.SQLITE_OK
+	case sqlite3.SQLITE_READ:
+		if slices.Contains(QueryAllowedTables, arg1) || strings.HasPrefix(arg1, ExtensionTablePrefix) {
+			return sqlite3.SQLITE_OK
+		}
+	}
+	return sqlite3.SQLITE_DENY
+}
+
+// openQueryDB opens the read-only database used by QueryReadOnly, whose
+// connections only allow the queries accepted by queryAuthorizer.
+func (idx *Indexer) openQueryDB() {
+	idx.queryDB = openDBWithHook(fmt.Sprintf("file:%s?mode=ro", idx.dbPath), idx.encryptionKey,
+		func(conn *sqlite3.SQLiteConn) error {
+			conn.RegisterAuthorizer(queryAuthorizer)
+			return nil
+		}, "journal_mode = wal")
+	idx.queryDB.SetMaxOpenConns(queryMaxConns)
+	idx.queryDB.SetMaxIdleConns(1)
+	idx.queryDB.SetConnMaxIdleTime(10 * time.Minute)
+}
+
+// QueryReadOnly runs the SQL query with the given args for ad-hoc analytics.
+// Only the SELECT queries on the tables of QueryAllowedTables and of the
+// extensions are allowed, returning at most QueryMaxRows rows and running for
+// at most QueryTimeout.
+// The blob values are returned as types.HexBytes.
+func (idx *Indexer) QueryReadOnly(query string, args ...any) (*indexertypes.QueryResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+	rows, err := idx.queryDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrAuth {
+			return nil, fmt.Errorf("%w: %v", ErrQueryNotAllowed, err)
+		}
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &indexertypes.QueryResult{Columns: columns, Rows: [][]any{}}
+	for rows.Next() {
+		if len(result.Rows) == QueryMaxRows {
+			result.Truncated = true
+			break
+		}
+		row := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		for i, v := range row {
+			if b, ok := v.([]byte); ok {
+				row[i] = types.HexBytes(b)
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
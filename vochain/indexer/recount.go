@@ -0,0 +1,73 @@
+package indexer
+
+import (
+	"fmt"
+
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/results"
+)
+
+// ErrProcessNotFinalized is returned when recounting a process whose final
+// results are not yet stored by the indexer.
+var ErrProcessNotFinalized = fmt.Errorf("process results are not final")
+
+// Recount recomputes the results of the finalized process pid from the votes
+// in the state, and compares them with the results stored by the indexer. It
+// is meant to audit the indexer database, for instance after a crash or a
+// migration. The differences found are listed in the returned Discrepancies.
+func (idx *Indexer) Recount(pid []byte) (*indexertypes.Recount, error) {
+	process, err := idx.ProcessInfo(pid)
+	if err != nil {
+		return nil, err
+	}
+	if !process.FinalResults {
+		return nil, fmt.Errorf("%w: %x", ErrProcessNotFinalized, pid)
+	}
+	r, err := results.ComputeResults(pid, idx.App.State)
+	if err != nil {
+		return nil, fmt.Errorf("cannot recount process %x: %w", pid, err)
+	}
+	recount := &indexertypes.Recount{
+		ProcessID:     pid,
+		Height:        r.BlockHeight,
+		Votes:         r.Votes,
+		Weight:        r.Weight,
+		Discrepancies: compareResults(process.ResultsVotes, process.ResultsWeight, r.Votes, r.Weight),
+	}
+	if len(recount.Discrepancies) > 0 {
+		log.Warnw("recounted results do not match the stored ones", "processID", recount.ProcessID,
+			"discrepancies", len(recount.Discrepancies))
+	}
+	return recount, nil
+}
+
+// compareResults describes the differences between the stored and the
+// recounted results, by question and option.
+func compareResults(storedVotes [][]*types.BigInt, storedWeight *types.BigInt,
+	votes [][]*types.BigInt, weight *types.BigInt,
+) []string {
+	var diffs []string
+	// the final results set on the state do not include the weight, so it is
+	// not stored either once the process is finalized
+	if storedWeight != nil && !storedWeight.Equal(weight) {
+		diffs = append(diffs, fmt.Sprintf("weight: stored %s, recounted %s", storedWeight, weight))
+	}
+	if len(storedVotes) != len(votes) {
+		diffs = append(diffs, fmt.Sprintf("questions: stored %d, recounted %d", len(storedVotes), len(votes)))
+	}
+	for q := range min(len(storedVotes), len(votes)) {
+		if len(storedVotes[q]) != len(votes[q]) {
+			diffs = append(diffs, fmt.Sprintf("question %d options: stored %d, recounted %d",
+				q, len(storedVotes[q]), len(votes[q])))
+		}
+		for o := range min(len(storedVotes[q]), len(votes[q])) {
+			if !storedVotes[q][o].Equal(votes[q][o]) {
+				diffs = append(diffs, fmt.Sprintf("question %d option %d: stored %s, recounted %s",
+					q, o, storedVotes[q][o], votes[q][o]))
+			}
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,625 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"go.vocdoni.io/dvote/log"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+)
+
+const (
+	// DefaultReplicaInterval is the number of blocks between two replicas of
+	// the database, see Options.ReplicateTo.
+	DefaultReplicaInterval = 60
+	// DefaultReplicaBaseInterval is the number of segments shipped between two
+	// base replicas, see Options.ReplicateTo.
+	DefaultReplicaBaseInterval = 100
+	// DefaultReplicaRefresh is how often a read replica checks for a newer
+	// replica, see Options.ReadReplicaOf.
+	DefaultReplicaRefresh = 30 * time.Second
+
+	// replicasKept is the number of base replicas kept by a DirReplicaStore,
+	// along with their segments, so that the followers restoring the previous
+	// one are not left without it.
+	replicasKept = 3
+	replicaExt   = ".sqlite3"
+	segmentExt   = ".wal"
+
+	// walCheckpointSize is the size of the WAL from which it is checkpointed
+	// once its frames are shipped, as the automatic checkpoints are disabled
+	// while replicating.
+	walCheckpointSize = 64 << 20
+	// walHeaderSize and walFrameHeaderSize are the sizes of the headers of the
+	// WAL file and of each of its frames, see https://sqlite.org/fileformat.html#the_write_ahead_log.
+	walHeaderSize      = 32
+	walFrameHeaderSize = 24
+
+	// noReplicaBase is the replicaBase of an indexer without a base replica.
+	noReplicaBase = ^uint32(0)
+)
+
+var (
+	// ErrReplicaNotFound is returned by a ReplicaStore without any replica.
+	ErrReplicaNotFound = errors.New("indexer replica not found")
+	// ErrReadReplica is returned by the methods which write to the database
+	// of an indexer in read replica mode.
+	ErrReadReplica = errors.New("the indexer is a read replica")
+
+	// errWALReset is returned by walCursor.next when the WAL was restarted by
+	// a checkpoint not run by the replication, so the frames written before
+	// it may have been copied into the database file without being shipped.
+	errWALReset = errors.New("the WAL was checkpointed outside of the replication")
+)
+
+// ReplicaStore is where the replicas of the indexer database are shipped to,
+// and restored from, such as a directory shared with the followers or an
+// object storage bucket. A base replica is a copy of the database file, taken
+// once the WAL was checkpointed. The changes committed after it are shipped as
+// segments, each holding the WAL frames committed since the previous one, so
+// that the replica at the height of a segment is the base replica with all
+// its segments up to that height applied in order.
+type ReplicaStore interface {
+	// PutReplica stores the base replica taken at the given height, read from r.
+	PutReplica(ctx context.Context, height uint32, r io.Reader) error
+	// PutSegment stores the segment taken at the given height of the base
+	// replica taken at base, read from r.
+	PutSegment(ctx context.Context, base, height uint32, r io.Reader) error
+	// LatestReplica returns the height of the most recent base replica and
+	// its contents, which the caller must close, or ErrReplicaNotFound.
+	LatestReplica(ctx context.Context) (uint32, io.ReadCloser, error)
+	// Segments returns the heights of the segments of the base replica taken
+	// at base which are above after, sorted.
+	Segments(ctx context.Context, base, after uint32) ([]uint32, error)
+	// Segment returns the contents of the segment taken at the given height of
+	// the base replica taken at base, which the caller must close.
+	Segment(ctx context.Context, base, height uint32) (io.ReadCloser, error)
+}
+
+// DirReplicaStore is a ReplicaStore which keeps the base replicas as files in
+// a directory, named after their height, and their segments in a directory
+// named after the height of the base. Only the last few base replicas are kept.
+type DirReplicaStore string
+
+// PutReplica implements ReplicaStore.
+func (d DirReplicaStore) PutReplica(_ context.Context, height uint32, r io.Reader) error {
+	if err := putFile(string(d), fmt.Sprintf("%010d%s", height, replicaExt), r); err != nil {
+		return err
+	}
+	heights, err := d.heights()
+	if err != nil {
+		return err
+	}
+	for len(heights) > replicasKept {
+		if err := os.RemoveAll(d.segmentsDir(heights[0])); err != nil {
+			return err
+		}
+		if err := os.Remove(filepath.Join(string(d), fmt.Sprintf("%010d%s", heights[0], replicaExt))); err != nil {
+			return err
+		}
+		heights = heights[1:]
+	}
+	return nil
+}
+
+// PutSegment implements ReplicaStore.
+func (d DirReplicaStore) PutSegment(_ context.Context, base, height uint32, r io.Reader) error {
+	return putFile(d.segmentsDir(base), fmt.Sprintf("%010d%s", height, segmentExt), r)
+}
+
+// LatestReplica implements ReplicaStore.
+func (d DirReplicaStore) LatestReplica(context.Context) (uint32, io.ReadCloser, error) {
+	heights, err := d.heights()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil, ErrReplicaNotFound
+		}
+		return 0, nil, err
+	}
+	if len(heights) == 0 {
+		return 0, nil, ErrReplicaNotFound
+	}
+	height := heights[len(heights)-1]
+	f, err := os.Open(filepath.Join(string(d), fmt.Sprintf("%010d%s", height, replicaExt)))
+	if err != nil {
+		return 0, nil, err
+	}
+	return height, f, nil
+}
+
+// Segments implements ReplicaStore.
+func (d DirReplicaStore) Segments(_ context.Context, base, after uint32) ([]uint32, error) {
+	heights, err := listHeights(d.segmentsDir(base), segmentExt)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	i, _ := slices.BinarySearch(heights, after+1)
+	return heights[i:], nil
+}
+
+// Segment implements ReplicaStore.
+func (d DirReplicaStore) Segment(_ context.Context, base, height uint32) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(d.segmentsDir(base), fmt.Sprintf("%010d%s", height, segmentExt)))
+}
+
+// heights returns the heights of the base replicas in the directory, sorted.
+func (d DirReplicaStore) heights() ([]uint32, error) {
+	return listHeights(string(d), replicaExt)
+}
+
+// segmentsDir returns the directory of the segments of the base replica taken at base.
+func (d DirReplicaStore) segmentsDir(base uint32) string {
+	return filepath.Join(string(d), fmt.Sprintf("%010d", base))
+}
+
+// putFile writes the file name in dir, creating dir if needed. The file is
+// written to a temporary file first, so that the followers never read it
+// partially written.
+func putFile(dir, name string, r io.Reader) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, r)
+	if err2 := tmp.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+// listHeights returns the heights the files in dir with the given extension
+// are named after, sorted.
+func listHeights(dir, ext string) ([]uint32, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var heights []uint32
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ext)
+		if !ok {
+			continue
+		}
+		height, err := strconv.ParseUint(name, 10, 32)
+		if err != nil {
+			continue
+		}
+		heights = append(heights, uint32(height))
+	}
+	slices.Sort(heights)
+	return heights, nil
+}
+
+// walCursor reads the frames appended to the WAL of the database since the
+// previous read, which are shipped to the replicas as a segment.
+type walCursor struct {
+	path string
+	// salt is the salt of the WAL being read, nil if any WAL is accepted,
+	// such as once it was checkpointed by the replication.
+	salt []byte
+	// offset is where the frames which were not read yet start.
+	offset int64
+}
+
+// next returns the frames of the transactions committed since the previous
+// call, preceded by the WAL header, or nil if there are none. The frames of a
+// transaction being written are left for the next call, although the caller
+// is expected to hold the writer connection so that there are none.
+func (c *walCursor) next() ([]byte, error) {
+	f, err := os.Open(c.path)
+	if os.IsNotExist(err) {
+		return nil, c.restarted(nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	header := make([]byte, walHeaderSize)
+	if _, err := io.ReadFull(f, header); errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		// the WAL was truncated, and nothing was written to it since
+		return nil, c.restarted(nil)
+	} else if err != nil {
+		return nil, err
+	}
+	salt := header[16:24]
+	if !bytes.Equal(salt, c.salt) {
+		if err := c.restarted(salt); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := f.Seek(c.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	pageSize := int64(binary.BigEndian.Uint32(header[8:12]))
+	frame := make([]byte, walFrameHeaderSize+pageSize)
+	segment := header
+	pending := 0
+	for {
+		if _, err := io.ReadFull(f, frame); errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		// the frames left behind by a previous WAL, once restarted, have another salt
+		if !bytes.Equal(frame[8:16], salt) {
+			break
+		}
+		segment = append(segment, frame...)
+		pending++
+		// a frame with the size of the database after a commit ends a transaction
+		if binary.BigEndian.Uint32(frame[4:8]) != 0 {
+			c.offset += int64(pending * len(frame))
+			pending = 0
+		}
+	}
+	segment = segment[:len(segment)-pending*len(frame)]
+	if len(segment) == walHeaderSize {
+		return nil, nil
+	}
+	return segment, nil
+}
+
+// restarted moves the cursor to the start of the WAL with the given salt, nil
+// if it is empty, or returns errWALReset if the previous one was not expected
+// to be checkpointed.
+func (c *walCursor) restarted(salt []byte) error {
+	if c.salt != nil {
+		return errWALReset
+	}
+	c.salt = slices.Clone(salt)
+	c.offset = walHeaderSize
+	return nil
+}
+
+// checkpointed resets the cursor once the WAL was checkpointed and truncated.
+func (c *walCursor) checkpointed() {
+	c.salt = nil
+	c.offset = walHeaderSize
+}
+
+// applySegment writes the frames of a segment into the database file at path,
+// as a checkpoint does.
+func applySegment(path string, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	header := make([]byte, walHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fmt.Errorf("cannot read the segment header: %w", err)
+	}
+	pageSize := int64(binary.BigEndian.Uint32(header[8:12]))
+	frame := make([]byte, walFrameHeaderSize+pageSize)
+	for {
+		if _, err := io.ReadFull(r, frame); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("cannot read the segment frames: %w", err)
+		}
+		page := int64(binary.BigEndian.Uint32(frame[0:4]))
+		if _, err := f.WriteAt(frame[walFrameHeaderSize:], (page-1)*pageSize); err != nil {
+			return err
+		}
+		if size := int64(binary.BigEndian.Uint32(frame[4:8])); size != 0 {
+			if err := f.Truncate(size * pageSize); err != nil {
+				return err
+			}
+		}
+	}
+	return f.Sync()
+}
+
+// replicateUnsafe ships a replica of the database to the ReplicateTo store if
+// the height is a multiple of the replica interval. The frames committed to
+// the WAL since the previous replica are read right away, and then shipped in
+// the background as a segment, so the cost is proportional to the pages
+// written. A base replica is shipped instead on the first replica, after an
+// error, and every replicaBaseInterval segments. The replica is skipped if
+// the previous one was not shipped yet, so its frames are shipped with the
+// next one. It assumes that blockMu is locked, and that the block changes
+// were committed.
+func (idx *Indexer) replicateUnsafe(height uint32) {
+	if idx.replicateTo == nil || height%idx.replicaInterval != 0 {
+		return
+	}
+	if !idx.replicating.CompareAndSwap(false, true) {
+		log.Warnw("skipping indexer replica, the previous one is still in progress", "height", height)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	ship, err := idx.takeReplica(ctx, height)
+	if err != nil || ship == nil {
+		if err != nil {
+			log.Errorw(err, "cannot replicate the indexer database")
+		}
+		cancel()
+		idx.replicating.Store(false)
+		return
+	}
+	idx.shipping.Add(1)
+	go func() {
+		defer idx.shipping.Done()
+		defer idx.replicating.Store(false)
+		defer cancel()
+		startTime := time.Now()
+		if err := ship(ctx); err != nil {
+			// the followers cannot apply the next segments without this one
+			idx.replicaBase = noReplicaBase
+			log.Errorw(err, "cannot replicate the indexer database")
+			return
+		}
+		log.Infow("indexer database replicated", "height", height, "base", idx.replicaBase,
+			"elapsed", time.Since(startTime))
+	}()
+}
+
+// takeReplica reads the replica of the given height, and returns the func
+// which ships it, or nil if there is nothing to ship. It holds the writer
+// connection meanwhile, so that nothing is written to the database, nor
+// checkpointed, while the WAL is read. The replica fields are only accessed
+// while replicating is set.
+func (idx *Indexer) takeReplica(ctx context.Context, height uint32) (func(context.Context) error, error) {
+	conn, err := idx.readWriteDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if idx.replicaBase == noReplicaBase || idx.replicaSegments >= idx.replicaBaseInterval {
+		// the frames not shipped yet are checkpointed into the new base
+		truncated, err := checkpointWAL(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		if truncated {
+			idx.replicaWAL.checkpointed()
+			idx.replicaBase = height
+			idx.replicaSegments = 0
+			log.Debugw("shipping a base replica of the indexer database", "height", height)
+			return func(ctx context.Context) error {
+				// only the checkpoints run while holding the writer connection
+				// write to the database file, so it can be copied meanwhile
+				f, err := os.Open(idx.dbPath)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				return idx.replicateTo.PutReplica(ctx, height, f)
+			}, nil
+		}
+		if idx.replicaBase == noReplicaBase {
+			return nil, fmt.Errorf("cannot checkpoint the WAL for a base replica, it is being read")
+		}
+		// keep shipping segments until the base can be taken
+		log.Warnw("cannot checkpoint the WAL for a base replica, it is being read", "height", height)
+	}
+
+	segment, err := idx.replicaWAL.next()
+	if errors.Is(err, errWALReset) {
+		// take a base replica on the next interval
+		idx.replicaBase = noReplicaBase
+		idx.replicaWAL.checkpointed()
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if idx.replicaWAL.offset >= walCheckpointSize {
+		truncated, err := checkpointWAL(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		if truncated {
+			idx.replicaWAL.checkpointed()
+		}
+	}
+	if segment == nil {
+		return nil, nil
+	}
+	base := idx.replicaBase
+	idx.replicaSegments++
+	return func(ctx context.Context) error {
+		return idx.replicateTo.PutSegment(ctx, base, height, bytes.NewReader(segment))
+	}, nil
+}
+
+// checkpointWAL copies the frames of the WAL into the database file and
+// truncates the WAL, returning whether it could do so, which it cannot while
+// the WAL is being read.
+func checkpointWAL(ctx context.Context, conn *sql.Conn) (bool, error) {
+	var busy, frames, checkpointed int
+	if err := conn.QueryRowContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &frames, &checkpointed); err != nil {
+		return false, err
+	}
+	return busy == 0, nil
+}
+
+// startReplicaDB opens the database of a read replica, restoring the latest
+// replica of the ReadReplicaOf store into it. The migrations are not run, the
+// schema is the one of the replicas.
+func (idx *Indexer) startReplicaDB() error {
+	// the read-write connection is only used to restore the replicas
+	idx.readWriteDB = openDB(fmt.Sprintf("file:%s?mode=rwc&_txlock=immediate", idx.dbPath),
+		idx.encryptionKey, "journal_mode = wal")
+	if err := idx.readWriteDB.Ping(); err != nil {
+		return err
+	}
+	idx.readWriteDB.SetMaxOpenConns(1)
+	idx.readWriteDB.SetMaxIdleConns(1)
+	if _, err := idx.restoreReplica(context.TODO(), true); err != nil {
+		return fmt.Errorf("cannot restore the indexer replica: %w", err)
+	}
+
+	idx.readOnlyDB = openDB(fmt.Sprintf("file:%s?mode=ro", idx.dbPath), idx.encryptionKey, "journal_mode = wal")
+	idx.readOnlyDB.SetMaxOpenConns(16)
+	idx.readOnlyDB.SetMaxIdleConns(4)
+	idx.readOnlyDB.SetConnMaxIdleTime(30 * time.Minute)
+	idx.openQueryDB()
+
+	var err error
+	if idx.readOnlyQuery, err = indexerdb.Prepare(context.TODO(), idx.readOnlyDB); err != nil {
+		return err
+	}
+	return idx.loadAnomalyCount(context.TODO())
+}
+
+// RefreshReplica restores the latest replica of the ReadReplicaOf store, if it
+// is newer than the one being served, and returns whether it did. Only the
+// segments of the base replica which were not applied yet are downloaded,
+// unless there is a newer base replica. They are applied to a local copy of
+// the replica, which is then copied into the database with the sqlite backup
+// API, so the queries in progress keep reading the previous one until they
+// finish. That copy is a full local copy of the database, done on each refresh.
+func (idx *Indexer) RefreshReplica(ctx context.Context) (bool, error) {
+	if idx.readReplicaOf == nil {
+		return false, fmt.Errorf("the indexer is not a read replica")
+	}
+	return idx.restoreReplica(ctx, false)
+}
+
+// restoreReplica restores the latest replica of the ReadReplicaOf store, even
+// if it is not newer than the one being served if always is true.
+func (idx *Indexer) restoreReplica(ctx context.Context, always bool) (bool, error) {
+	idx.replicaMu.Lock()
+	defer idx.replicaMu.Unlock()
+	base, r, err := idx.readReplicaOf.LatestReplica(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer r.Close()
+
+	// the local copy of the replica, with the segments applied so far
+	staging := idx.dbPath + ".replica"
+	height := idx.replicaHeight.Load()
+	restored := false
+	if always || base != idx.replicaBase {
+		// until the replica is restored, the local copy does not match replicaBase
+		idx.replicaBase = noReplicaBase
+		if err := putFile(filepath.Dir(staging), filepath.Base(staging), r); err != nil {
+			return false, fmt.Errorf("cannot download the replica: %w", err)
+		}
+		height = base
+		restored = true
+	}
+	segments, err := idx.readReplicaOf.Segments(ctx, base, height)
+	if err != nil {
+		return false, err
+	}
+	for _, segment := range segments {
+		if err := idx.applyReplicaSegment(ctx, staging, base, segment); err != nil {
+			// the local copy may be partially updated, so it is downloaded again
+			idx.replicaBase = noReplicaBase
+			return false, fmt.Errorf("cannot apply the replica segment %d: %w", segment, err)
+		}
+		height = segment
+		restored = true
+	}
+	if !restored {
+		return false, nil
+	}
+
+	// the local copy is only written to while replicaMu is locked
+	src := openDB(fmt.Sprintf("file:%s?mode=ro&immutable=1", staging), idx.encryptionKey)
+	defer src.Close()
+	if err := restoreDB(ctx, idx.readWriteDB, src); err != nil {
+		idx.replicaBase = noReplicaBase
+		return false, err
+	}
+	idx.replicaBase = base
+	idx.replicaHeight.Store(height)
+	idx.versions.bump()
+	// the anomalies are seeded by startReplicaDB on the first restore
+	if idx.readOnlyQuery != nil {
+		if err := idx.loadAnomalyCount(ctx); err != nil {
+			return true, err
+		}
+	}
+	log.Debugw("indexer replica restored", "height", height, "base", base)
+	return true, nil
+}
+
+// applyReplicaSegment downloads the segment of the given height of the base
+// replica, and applies it to the local copy of the replica at path.
+func (idx *Indexer) applyReplicaSegment(ctx context.Context, path string, base, height uint32) error {
+	r, err := idx.readReplicaOf.Segment(ctx, base, height)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return applySegment(path, r)
+}
+
+// ReplicaHeight returns the height of the replica being served by a read
+// replica, which lags behind the chain.
+func (idx *Indexer) ReplicaHeight() uint32 {
+	return idx.replicaHeight.Load()
+}
+
+// followReplicas refreshes the replica every interval, until stop is closed.
+func (idx *Indexer) followReplicas(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		if _, err := idx.RefreshReplica(ctx); err != nil {
+			log.Warnw("cannot refresh the indexer replica", "err", err)
+		}
+		cancel()
+	}
+}
+
+// restoreDB copies all the pages of the src database into dst.
+func restoreDB(ctx context.Context, dst, src *sql.DB) error {
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+	return dstConn.Raw(func(dc any) error {
+		return srcConn.Raw(func(sc any) error {
+			backup, err := dc.(*sqlite3.SQLiteConn).Backup("main", sc.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+			// copy all the pages in a single step, so that the readers never see a partial copy
+			_, err = backup.Step(-1)
+			if err2 := backup.Finish(); err == nil {
+				err = err2
+			}
+			return err
+		})
+	})
+}
@@ -0,0 +1,81 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+	"go.vocdoni.io/dvote/vochain/results/resultsverify"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrResultsProofNotFound is returned if the results proof of a process is not
+// found in the indexer database.
+var ErrResultsProofNotFound = fmt.Errorf("results proof not found")
+
+// indexResultsProof stores the merkle proofs of the process pid in the last
+// committed state, which is committed as AppHash by the block at height.
+// Assumes that blockMu is locked.
+func (idx *Indexer) indexResultsProof(ctx context.Context, queries *indexerdb.Queries, pid types.ProcessID, height uint32) error {
+	proof, err := idx.App.State.ProcessProof(pid)
+	if err != nil {
+		return fmt.Errorf("cannot generate results proof of process %x: %w", pid, err)
+	}
+	if _, err := queries.CreateResultsProof(ctx, indexerdb.CreateResultsProofParams{
+		ProcessID:      pid,
+		Height:         int64(height),
+		StateRoot:      proof.StateRoot,
+		ProcessesRoot:  proof.ProcessesRoot,
+		ProcessesProof: proof.ProcessesProof,
+		ProcessLeaf:    proof.Process,
+		ProcessProof:   proof.ProcessProof,
+	}); err != nil {
+		return fmt.Errorf("cannot store results proof of process %x: %w", pid, err)
+	}
+	log.Debugw("indexed results proof", "processID", pid, "height", height)
+	return nil
+}
+
+// ResultsProof returns the verification bundle of the results of the process
+// pid, built from the proofs generated once the results were committed, and
+// the header of the block committing them.
+func (idx *Indexer) ResultsProof(pid []byte) (*resultsverify.Bundle, error) {
+	row, err := idx.readOnlyQuery.GetResultsProof(context.TODO(), pid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrResultsProofNotFound
+		}
+		return nil, err
+	}
+	block := idx.App.GetBlockByHeight(row.Height)
+	if block == nil {
+		return nil, fmt.Errorf("%w: %d", ErrBlockNotFound, row.Height)
+	}
+	var sdbProc models.StateDBProcess
+	if err := proto.Unmarshal(row.ProcessLeaf, &sdbProc); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal process: %w", err)
+	}
+	process := sdbProc.GetProcess()
+	if process == nil {
+		return nil, fmt.Errorf("process %x is nil", pid)
+	}
+	return &resultsverify.Bundle{
+		ElectionID:     process.ProcessId,
+		Results:        state.GetFriendlyResults(process.GetResults().GetVotes()),
+		EncryptionKeys: resultsverify.Keys(process),
+		CensusRoot:     process.CensusRoot,
+		Height:         row.Height,
+		Header:         &block.Header,
+		StateRoot:      row.StateRoot,
+		ProcessesRoot:  row.ProcessesRoot,
+		ProcessesProof: row.ProcessesProof,
+		Process:        row.ProcessLeaf,
+		ProcessProof:   row.ProcessProof,
+	}, nil
+}
@@ -0,0 +1,132 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakeS3 is an in-memory S3-compatible bucket, with the subset of the API used
+// by S3BackupStore, returning the lists in pages of two objects.
+type fakeS3 struct {
+	mu      sync.Mutex
+	bucket  string
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") ||
+		r.Header.Get("x-amz-date") == "" {
+		http.Error(w, "unsigned request", http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket)
+	if !ok {
+		http.Error(w, "no such bucket", http.StatusNotFound)
+		return
+	}
+	key = strings.TrimPrefix(key, "/")
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		var keys []string
+		for k := range f.objects {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		if token := r.URL.Query().Get("continuation-token"); token != "" {
+			i, _ := slices.BinarySearch(keys, token)
+			keys = keys[i:]
+		}
+		type object struct{ Key string }
+		var result struct {
+			XMLName               xml.Name `xml:"ListBucketResult"`
+			Contents              []object
+			IsTruncated           bool
+			NextContinuationToken string `xml:",omitempty"`
+		}
+		if len(keys) > 2 {
+			result.IsTruncated = true
+			result.NextContinuationToken = keys[2]
+			keys = keys[:2]
+		}
+		for _, k := range keys {
+			result.Contents = append(result.Contents, object{Key: k})
+		}
+		_ = xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodPut:
+		if r.ContentLength < 0 {
+			http.Error(w, "missing length", http.StatusLengthRequired)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "no such key", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "unsupported", http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3BackupStore(t *testing.T) {
+	c := qt.New(t)
+	fake := &fakeS3{bucket: "backups", objects: map[string][]byte{"other/file": []byte("x")}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	store := &S3BackupStore{
+		Endpoint:  srv.URL,
+		Bucket:    "backups",
+		Prefix:    "indexer/",
+		AccessKey: "key",
+		SecretKey: "secret",
+	}
+	ctx := context.Background()
+
+	names, err := store.ListBackups(ctx)
+	c.Assert(err, qt.IsNil)
+	c.Assert(names, qt.HasLen, 0)
+	for _, name := range []string{"a", "b", "c", "d e"} {
+		data := []byte("backup " + name)
+		c.Assert(store.PutBackup(ctx, name, int64(len(data)), bytes.NewReader(data)), qt.IsNil)
+	}
+	c.Assert(fake.objects["indexer/d e"], qt.DeepEquals, []byte("backup d e"))
+
+	// the list is paginated, and it skips the objects out of the prefix
+	names, err = store.ListBackups(ctx)
+	c.Assert(err, qt.IsNil)
+	c.Assert(names, qt.DeepEquals, []string{"a", "b", "c", "d e"})
+
+	r, err := store.GetBackup(ctx, "b")
+	c.Assert(err, qt.IsNil)
+	data, err := io.ReadAll(r)
+	c.Assert(err, qt.IsNil)
+	c.Assert(r.Close(), qt.IsNil)
+	c.Assert(string(data), qt.Equals, "backup b")
+
+	c.Assert(store.DeleteBackup(ctx, "b"), qt.IsNil)
+	_, err = store.GetBackup(ctx, "b")
+	c.Assert(err, qt.ErrorMatches, ".*404.*")
+	names, err = store.ListBackups(ctx)
+	c.Assert(err, qt.IsNil)
+	c.Assert(names, qt.DeepEquals, []string{"a", "c", "d e"})
+}
@@ -0,0 +1,84 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/VictoriaMetrics/metrics"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+)
+
+// Status returns the health and sync status of the indexer, so that a gateway
+// whose indexer lags behind the chain can be told apart. It does not wait for
+// the block being indexed, nor query the database.
+func (idx *Indexer) Status() *indexertypes.IndexerStatus {
+	status := &indexertypes.IndexerStatus{
+		Height:             idx.indexedHeight.Load(),
+		PendingBlockWrites: idx.blockWrites.Load(),
+	}
+	if idx.readReplicaOf != nil {
+		status.Height = idx.replicaHeight.Load()
+	}
+	if idx.App != nil {
+		status.AppHeight = idx.App.Height()
+	}
+	if status.AppHeight > status.Height {
+		status.Lag = status.AppHeight - status.Height
+	}
+	idx.liveResultsProcs.Range(func(_, _ any) bool {
+		status.LiveProcesses++
+		return true
+	})
+	status.DBSize = fileSize(idx.dbPath)
+	status.WALSize = fileSize(idx.dbPath + "-wal")
+	return status
+}
+
+// fileSize returns the size of the file at path, or zero if it does not exist.
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// loadIndexedHeight loads the last block height indexed from the database.
+func (idx *Indexer) loadIndexedHeight(ctx context.Context) error {
+	height, err := idx.readOnlyQuery.LastBlockHeight(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot get the last block height: %w", err)
+	}
+	idx.indexedHeight.Store(uint32(height))
+	return nil
+}
+
+// registerMetrics registers the gauges of the indexer status, labeled with
+// its chain ID, which are exported until the indexer is closed.
+func (idx *Indexer) registerMetrics() {
+	idx.metrics = metrics.NewSet()
+	gauge := func(name string, f func(*indexertypes.IndexerStatus) float64) {
+		idx.metrics.NewGauge(fmt.Sprintf("%s{chain=%q}", name, idx.ChainID()), func() float64 {
+			return f(idx.Status())
+		})
+	}
+	gauge("vochain_indexer_height", func(s *indexertypes.IndexerStatus) float64 { return float64(s.Height) })
+	gauge("vochain_indexer_lag", func(s *indexertypes.IndexerStatus) float64 { return float64(s.Lag) })
+	gauge("vochain_indexer_pending_block_writes", func(s *indexertypes.IndexerStatus) float64 {
+		return float64(s.PendingBlockWrites)
+	})
+	gauge("vochain_indexer_live_processes", func(s *indexertypes.IndexerStatus) float64 {
+		return float64(s.LiveProcesses)
+	})
+	gauge("vochain_indexer_db_size_bytes", func(s *indexertypes.IndexerStatus) float64 { return float64(s.DBSize) })
+	gauge("vochain_indexer_wal_size_bytes", func(s *indexertypes.IndexerStatus) float64 { return float64(s.WALSize) })
+	idx.metrics.NewGauge(fmt.Sprintf("vochain_indexer_anomalies{chain=%q}", idx.ChainID()), func() float64 {
+		return float64(idx.anomalyCount.Load())
+	})
+	metrics.RegisterSet(idx.metrics)
+}
@@ -3,10 +3,12 @@ package indexer
 import (
 	"context"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
 	"go.vocdoni.io/dvote/crypto/ethereum"
 	"go.vocdoni.io/dvote/log"
 	"go.vocdoni.io/dvote/types"
@@ -25,6 +27,22 @@ func (idx *Indexer) CountTotalTransactions() (uint64, error) {
 	return uint64(count), err
 }
 
+// CountTransactions returns the exact number of transactions matching the
+// filters of SearchTransactions.
+func (idx *Indexer) CountTransactions(blockHeight uint64, txHash, txType, txSubtype, txSigner string) (uint64, error) {
+	count, err := idx.readOnlyQuery.CountSearchTransactions(context.TODO(), indexerdb.CountSearchTransactionsParams{
+		BlockHeight: blockHeight,
+		TxType:      txType,
+		TxSubtype:   txSubtype,
+		TxSigner:    txSigner,
+		HashSubstr:  txHash,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return uint64(count), nil
+}
+
 // CountTransactionsByHeight returns the number of transactions indexed for a given height
 func (idx *Indexer) CountTransactionsByHeight(height int64) (int64, error) {
 	return idx.readOnlyQuery.CountTransactionsByHeight(context.TODO(), height)
@@ -54,6 +72,24 @@ func (idx *Indexer) GetTransactionByHash(hash types.HexBytes) (*indexertypes.Tra
 	return indexertypes.TransactionFromDB(&sqlTxRef), nil
 }
 
+// GetTransactionReceipt fetches the receipt of the tx with the given hash.
+func (idx *Indexer) GetTransactionReceipt(hash types.HexBytes) (*indexertypes.TransactionReceipt, error) {
+	sqlTx, err := idx.readOnlyQuery.GetTransactionByHash(context.TODO(), hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("tx hash %x not found: %v", hash, err)
+	}
+	sqlReceipt, err := idx.readOnlyQuery.GetTransactionReceipt(context.TODO(), hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return indexertypes.TransactionReceiptFromDB(&sqlTx, nil), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("tx receipt %x not found: %v", hash, err)
+	}
+	return indexertypes.TransactionReceiptFromDB(&sqlTx, &sqlReceipt), nil
+}
+
 // GetTransactionByHeightAndIndex fetches the full tx for the given tx height and block tx index
 func (idx *Indexer) GetTransactionByHeightAndIndex(blockHeight, blockIndex int64) (*indexertypes.Transaction, error) {
 	sqlTxRef, err := idx.readOnlyQuery.GetTransactionByHeightAndIndex(context.TODO(), indexerdb.GetTransactionByHeightAndIndexParams{
@@ -74,32 +110,156 @@ func (idx *Indexer) GetTransactionByHeightAndIndex(blockHeight, blockIndex int64
 // hash matches substrings.
 // The first one returned is the newest, so they are in descending order.
 func (idx *Indexer) SearchTransactions(limit, offset int, blockHeight uint64, txHash, txType, txSubtype, txSigner string) ([]*indexertypes.TransactionMetadata, uint64, error) {
+	list, _, total, err := idx.SearchTransactionsWithCursor(limit, offset, "", blockHeight, txHash, txType, txSubtype, txSigner)
+	return list, total, err
+}
+
+// SearchTransactionsWithCursor is like SearchTransactions, but also accepts an opaque cursor
+// returned by a previous call. If not empty, the list starts right after the last transaction
+// returned by that call, and offset is applied from there. It also returns the cursor to fetch
+// the next page, which is empty if there are no more transactions. The total number of
+// transactions is only counted when cursor is empty, as it requires scanning all of them,
+// and it is zero otherwise.
+func (idx *Indexer) SearchTransactionsWithCursor(limit, offset int, cursor string, blockHeight uint64,
+	txHash, txType, txSubtype, txSigner string,
+) ([]*indexertypes.TransactionMetadata, string, uint64, error) {
 	if offset < 0 {
-		return nil, 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
+		return nil, "", 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
 	}
 	if limit <= 0 {
-		return nil, 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
+		return nil, "", 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
 	}
-	results, err := idx.readOnlyQuery.SearchTransactions(context.TODO(), indexerdb.SearchTransactionsParams{
-		Limit:       int64(limit),
+	// filtering by a whole signer address, use the query served by its index
+	if len(txSigner) == 2*common.AddressLength && blockHeight == 0 && txHash == "" && txSubtype == "" {
+		if signer, err := hex.DecodeString(txSigner); err == nil {
+			return idx.TransactionListBySigner(limit, offset, cursor, signer, txType)
+		}
+	}
+	params := indexerdb.SearchTransactionsParams{
+		Limit:       int64(limit) + 1, // fetch one more to know if there is a next page
 		Offset:      int64(offset),
 		HashSubstr:  txHash,
 		BlockHeight: blockHeight,
 		TxType:      txType,
 		TxSubtype:   txSubtype,
 		TxSigner:    txSigner,
-	})
+	}
+	if cursor != "" {
+		var height, index int64
+		if err := decodeCursor(cursor, &height, &index); err != nil {
+			return nil, "", 0, err
+		}
+		params.CursorBlockHeight = height
+		params.CursorBlockIndex = index
+	}
+	results, err := idx.readOnlyQuery.SearchTransactions(context.TODO(), params)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	nextCursor := ""
+	if len(results) > limit {
+		results = results[:limit]
+		last := results[limit-1]
+		nextCursor = encodeCursor(last.BlockHeight, last.BlockIndex)
+	}
+	list := []*indexertypes.TransactionMetadata{}
+	for _, row := range results {
+		list = append(list, indexertypes.TransactionMetadataFromDB(&row))
+	}
+	if len(results) == 0 || cursor != "" {
+		return list, nextCursor, 0, nil
+	}
+	if !idx.estimateCounts {
+		total, err := idx.CountTransactions(blockHeight, txHash, txType, txSubtype, txSigner)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		return list, nextCursor, total, nil
+	}
+	total, err := idx.estimateTransactionsCount(blockHeight, txHash, txType, txSubtype, txSigner,
+		estimatedCountLowerBound(offset, len(results), nextCursor))
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return list, nextCursor, total, nil
+}
+
+// TransactionListBySigner returns the list of transactions signed by the given
+// address, optionally filtered by type, the newest first. If not empty, cursor
+// is the one returned by a previous call, and the list starts right after the
+// last transaction returned by that call, then offset is applied from there.
+// It also returns the cursor to fetch the next page, which is empty if there
+// are no more transactions, and the total number of transactions, which is
+// only counted when cursor is empty, as it requires scanning all of them.
+func (idx *Indexer) TransactionListBySigner(limit, offset int, cursor string, signer []byte, txType string,
+) ([]*indexertypes.TransactionMetadata, string, uint64, error) {
+	if offset < 0 {
+		return nil, "", 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
+	}
+	if limit <= 0 {
+		return nil, "", 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
+	}
+	params := indexerdb.SearchTransactionsBySignerParams{
+		Limit:  int64(limit) + 1, // fetch one more to know if there is a next page
+		Offset: int64(offset),
+		Signer: signer,
+		TxType: txType,
+	}
+	if cursor != "" {
+		var height, index int64
+		if err := decodeCursor(cursor, &height, &index); err != nil {
+			return nil, "", 0, err
+		}
+		params.CursorBlockHeight = height
+		params.CursorBlockIndex = index
+	}
+	results, err := idx.readOnlyQuery.SearchTransactionsBySigner(context.TODO(), params)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", 0, err
+	}
+	nextCursor := ""
+	if len(results) > limit {
+		results = results[:limit]
+		last := results[limit-1]
+		nextCursor = encodeCursor(last.BlockHeight, last.BlockIndex)
 	}
 	list := []*indexertypes.TransactionMetadata{}
 	for _, row := range results {
-		list = append(list, indexertypes.TransactionMetadataFromDBRow(&row))
+		list = append(list, indexertypes.TransactionMetadataFromDB(&row))
+	}
+	if len(results) == 0 || cursor != "" {
+		return list, nextCursor, 0, nil
+	}
+	total, err := idx.readOnlyQuery.CountTransactionsBySigner(context.TODO(), indexerdb.CountTransactionsBySignerParams{
+		Signer: signer,
+		TxType: txType,
+	})
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return list, nextCursor, uint64(total), nil
+}
+
+// estimateTransactionsCount returns an estimation of the number of transactions
+// matching the filters of SearchTransactions, which is at least lowerBound.
+// It is exact when there are no filters, or only the block height one.
+func (idx *Indexer) estimateTransactionsCount(blockHeight uint64, txHash, txType, txSubtype, txSigner string,
+	lowerBound uint64,
+) (uint64, error) {
+	if txHash != "" || txType != "" || txSubtype != "" || txSigner != "" {
+		return lowerBound, nil
+	}
+	var count int64
+	var err error
+	if blockHeight == 0 {
+		count, err = idx.readOnlyQuery.GetTransactionsMaxRowID(context.TODO())
+	} else {
+		count, err = idx.readOnlyQuery.CountTransactionsByHeight(context.TODO(), int64(blockHeight))
 	}
-	if len(results) == 0 {
-		return list, 0, nil
+	if err != nil {
+		return 0, err
 	}
-	return list, uint64(results[0].TotalCount), nil
+	return max(uint64(count), lowerBound), nil
 }
 
 func (idx *Indexer) OnNewTx(tx *vochaintx.Tx, blockHeight uint32, txIndex int32) {
@@ -107,6 +267,44 @@ func (idx *Indexer) OnNewTx(tx *vochaintx.Tx, blockHeight uint32, txIndex int32)
 	defer idx.blockMu.Unlock()
 
 	idx.indexTx(tx, blockHeight, txIndex)
+	idx.blockHasTxs = true
+	// the status changes of the processes made by the tx were notified before
+	idx.indexStatusChanges(context.TODO(), idx.blockTxQueries(), tx.TxID[:])
+}
+
+// OnTxReceipt indexes the receipt of the tx, with the events it emitted.
+func (idx *Indexer) OnTxReceipt(tx *vochaintx.Tx, receipt *vochaintx.Receipt, _ int32) {
+	idx.blockMu.Lock()
+	defer idx.blockMu.Unlock()
+
+	events := receipt.Events
+	if events == nil {
+		events = []vochaintx.Event{}
+	}
+	if _, err := idx.blockTxQueries().CreateTransactionReceipt(context.TODO(), indexerdb.CreateTransactionReceiptParams{
+		Hash:   tx.TxID[:],
+		Cost:   int64(receipt.Cost),
+		Events: indexertypes.EncodeJSON(events),
+	}); err != nil {
+		log.Errorw(err, "cannot index transaction receipt")
+	}
+}
+
+// txSigner returns the address of the signer of the transaction, which is the
+// account of the multisig transactions, or nil if the transaction is not signed.
+func txSigner(tx *vochaintx.Tx) ([]byte, error) {
+	if tx.Multisig != nil {
+		// the signatures of the multisig transactions were verified on CheckTx
+		return tx.Multisig.Account.Bytes(), nil
+	}
+	if len(tx.Signature) == 0 { // not all txs are signed, for example zk ones
+		return nil, nil
+	}
+	addr, err := ethereum.AddrFromSignature(tx.SignedBody, tx.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return addr.Bytes(), nil
 }
 
 func (idx *Indexer) indexTx(tx *vochaintx.Tx, blockHeight uint32, txIndex int32) {
@@ -116,14 +314,10 @@ func (idx *Indexer) indexTx(tx *vochaintx.Tx, blockHeight uint32, txIndex int32)
 		return
 	}
 
-	signer := []byte{}
-	if len(tx.Signature) > 0 { // not all txs are signed, for example zk ones
-		addr, err := ethereum.AddrFromSignature(tx.SignedBody, tx.Signature)
-		if err != nil {
-			log.Errorw(err, "indexer cannot recover signer from signature")
-			return
-		}
-		signer = addr.Bytes()
+	signer, err := txSigner(tx)
+	if err != nil {
+		log.Errorw(err, "indexer cannot recover signer from signature")
+		return
 	}
 
 	queries := idx.blockTxQueries()
@@ -0,0 +1,79 @@
+package indexer
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// updateProcessTurnout computes and stores the turnout of the process pid, as
+// the percentage of the census that has voted: by number of votes over the max
+// census size and, for weighted censuses whose total weight is known, by weight
+// of the votes over the census weight. If the turnout by number of votes
+// reaches CensusSizeAlertThreshold, the process is queued to be alerted on
+// Commit. Assumes that blockMu is locked.
+func (idx *Indexer) updateProcessTurnout(ctx context.Context, queries *indexerdb.Queries, pid types.ProcessID) error {
+	dbProc, err := queries.GetProcess(ctx, pid)
+	if err != nil {
+		return fmt.Errorf("cannot fetch process %x: %w", pid, err)
+	}
+	proc := indexertypes.ProcessFromDB(&dbProc)
+	turnout := percentage(new(big.Int).SetUint64(proc.VoteCount), new(big.Int).SetUint64(proc.MaxCensusSize))
+	weightTurnout := float64(0)
+	if state.CensusOrigins[models.CensusOrigin(proc.CensusOrigin)].WeightedSupport && proc.ResultsWeight != nil {
+		if censusWeight := idx.processCensusWeight(proc.CensusRoot); censusWeight != nil {
+			weightTurnout = percentage(proc.ResultsWeight.MathBigInt(), censusWeight)
+		}
+	}
+	if _, err := queries.UpdateProcessTurnout(ctx, indexerdb.UpdateProcessTurnoutParams{
+		ID:            pid,
+		Turnout:       turnout,
+		WeightTurnout: weightTurnout,
+	}); err != nil {
+		return fmt.Errorf("cannot update turnout of process %x: %w", pid, err)
+	}
+	// a census size increase lowers the turnout, so the process can be alerted again
+	if dbProc.Turnout < CensusSizeAlertThreshold && turnout >= CensusSizeAlertThreshold {
+		idx.blockCensusSizeAlerts = append(idx.blockCensusSizeAlerts, pid)
+	}
+	return nil
+}
+
+// processCensusWeight returns the total weight of the census with the given
+// root, or nil if it is not known. Assumes that blockMu is locked.
+func (idx *Indexer) processCensusWeight(censusRoot []byte) *big.Int {
+	if idx.censusWeight == nil || len(censusRoot) == 0 {
+		return nil
+	}
+	if weight, ok := idx.censusWeights[string(censusRoot)]; ok {
+		return weight
+	}
+	weight, err := idx.censusWeight(censusRoot)
+	if err != nil {
+		// the census might be available later, so do not cache the failure
+		log.Debugw("cannot get census weight", "root", hex.EncodeToString(censusRoot), "err", err)
+		return nil
+	}
+	idx.censusWeights[string(censusRoot)] = weight
+	return weight
+}
+
+// percentage returns part as a percentage of total, or zero if total is zero.
+func percentage(part, total *big.Int) float64 {
+	if total == nil || total.Sign() <= 0 {
+		return 0
+	}
+	p, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(new(big.Int).Mul(part, big.NewInt(100))),
+		new(big.Float).SetInt(total),
+	).Float64()
+	return p
+}
@@ -0,0 +1,226 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	comettypes "github.com/cometbft/cometbft/types"
+	"go.vocdoni.io/dvote/log"
+	indexerdb "go.vocdoni.io/dvote/vochain/indexer/db"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+)
+
+// ErrValidatorNotFound is returned if the validator is not found in the indexer database.
+var ErrValidatorNotFound = fmt.Errorf("validator not found")
+
+// indexValidatorSet compares the current validator set with the last indexed one,
+// storing the validators that joined, left or changed their voting power at the
+// given height. Assumes that blockMu is locked.
+func (idx *Indexer) indexValidatorSet(ctx context.Context, queries *indexerdb.Queries, height uint32) error {
+	if idx.validatorPowers == nil {
+		rows, err := queries.GetValidatorPowers(ctx)
+		if err != nil {
+			return err
+		}
+		idx.validatorPowers = make(map[string]int64, len(rows))
+		for _, row := range rows {
+			idx.validatorPowers[string(row.Address)] = row.Power
+		}
+	}
+	validators, err := idx.App.State.Validators(false)
+	if err != nil {
+		return err
+	}
+	current := make(map[string]bool, len(validators))
+	for _, v := range validators {
+		addr := v.GetValidatorAddress()
+		current[string(addr)] = true
+		power := int64(v.GetPower())
+		if prev, ok := idx.validatorPowers[string(addr)]; ok && prev == power {
+			continue
+		}
+		if _, err := queries.CreateValidator(ctx, indexerdb.CreateValidatorParams{
+			Address:          addr,
+			AccountAddress:   nonNullBytes(v.GetAddress()),
+			PubKey:           nonNullBytes(v.GetPubKey()),
+			Name:             v.GetName(),
+			Power:            power,
+			JoinHeight:       int64(v.GetHeight()),
+			LastChangeHeight: int64(height),
+		}); err != nil {
+			return err
+		}
+		if err := idx.setValidatorPower(ctx, queries, height, addr, power); err != nil {
+			return err
+		}
+	}
+	for addr, power := range idx.validatorPowers {
+		if current[addr] || power == 0 {
+			continue
+		}
+		// the validator was removed from the set
+		if _, err := queries.UpdateValidatorPower(ctx, indexerdb.UpdateValidatorPowerParams{
+			Power:            0,
+			LastChangeHeight: int64(height),
+			Address:          []byte(addr),
+		}); err != nil {
+			return err
+		}
+		if err := idx.setValidatorPower(ctx, queries, height, []byte(addr), 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setValidatorPower records a validator set change and updates the cached power.
+func (idx *Indexer) setValidatorPower(ctx context.Context, queries *indexerdb.Queries,
+	height uint32, addr []byte, power int64,
+) error {
+	if _, err := queries.CreateValidatorSetChange(ctx, indexerdb.CreateValidatorSetChangeParams{
+		Height:  int64(height),
+		Address: addr,
+		Power:   power,
+	}); err != nil {
+		return err
+	}
+	idx.validatorPowers[string(addr)] = power
+	return nil
+}
+
+// indexValidatorSignatures stores the validators that signed the previous block,
+// found in the last commit of the given block.
+func (idx *Indexer) indexValidatorSignatures(ctx context.Context, queries *indexerdb.Queries, b *comettypes.Block) {
+	if b.LastCommit == nil {
+		return
+	}
+	for _, sig := range b.LastCommit.Signatures {
+		if sig.BlockIDFlag != comettypes.BlockIDFlagCommit {
+			continue
+		}
+		if _, err := queries.CreateValidatorSignature(ctx, indexerdb.CreateValidatorSignatureParams{
+			Address: sig.ValidatorAddress,
+			Height:  b.LastCommit.Height,
+		}); err != nil {
+			log.Errorw(err, "cannot index validator signature")
+		}
+	}
+}
+
+// ValidatorList returns the list of validators indexed, including the ones removed
+// from the validator set (with power zero). They are sorted by descending power.
+func (idx *Indexer) ValidatorList(limit, offset int) ([]*indexertypes.Validator, uint64, error) {
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
+	}
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
+	}
+	results, err := idx.readOnlyQuery.SearchValidators(context.TODO(), indexerdb.SearchValidatorsParams{
+		Limit:  int64(limit),
+		Offset: int64(offset),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	list := []*indexertypes.Validator{}
+	for _, row := range results {
+		list = append(list, indexertypes.ValidatorFromDBRow(&row))
+	}
+	if len(results) == 0 {
+		return list, 0, nil
+	}
+	return list, uint64(results[0].TotalCount), nil
+}
+
+// ValidatorSetChanges returns the history of the validator set, as the list of
+// voting power changes. The first one returned is the newest, so they are in
+// descending order. address is optional, if declared as zero-value will be ignored.
+func (idx *Indexer) ValidatorSetChanges(limit, offset int, address string) ([]*indexertypes.ValidatorSetChange, uint64, error) {
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
+	}
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
+	}
+	results, err := idx.readOnlyQuery.SearchValidatorSetChanges(context.TODO(), indexerdb.SearchValidatorSetChangesParams{
+		Limit:   int64(limit),
+		Offset:  int64(offset),
+		Address: address,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	list := []*indexertypes.ValidatorSetChange{}
+	for _, row := range results {
+		list = append(list, &indexertypes.ValidatorSetChange{
+			Height:  uint64(row.Height),
+			Address: row.Address,
+			Power:   uint64(row.Power),
+		})
+	}
+	if len(results) == 0 {
+		return list, 0, nil
+	}
+	return list, uint64(results[0].TotalCount), nil
+}
+
+// ValidatorUptime returns the number of blocks signed and proposed by the validator
+// in the last window blocks, starting at most from its join height. Since the
+// signatures of a block are included in the next one, the range ends at the
+// height previous to the last indexed block.
+func (idx *Indexer) ValidatorUptime(address []byte, window uint64) (*indexertypes.ValidatorUptime, error) {
+	if window == 0 {
+		return nil, fmt.Errorf("invalid value: window cannot be %d", window)
+	}
+	ctx := context.TODO()
+	validator, err := idx.readOnlyQuery.GetValidator(ctx, address)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrValidatorNotFound
+		}
+		return nil, err
+	}
+	lastHeight, err := idx.readOnlyQuery.LastBlockHeight(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	uptime := &indexertypes.ValidatorUptime{Address: address}
+	toHeight := lastHeight - 1
+	fromHeight := max(toHeight-int64(window)+1, validator.JoinHeight, 1)
+	if toHeight < fromHeight {
+		return uptime, nil
+	}
+	uptime.FromHeight, uptime.ToHeight = uint64(fromHeight), uint64(toHeight)
+
+	blocks, err := idx.readOnlyQuery.CountBlocksInRange(ctx, indexerdb.CountBlocksInRangeParams{
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+	})
+	if err != nil {
+		return nil, err
+	}
+	signed, err := idx.readOnlyQuery.CountValidatorSignatures(ctx, indexerdb.CountValidatorSignaturesParams{
+		Address:    address,
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+	})
+	if err != nil {
+		return nil, err
+	}
+	proposed, err := idx.readOnlyQuery.CountValidatorProposals(ctx, indexerdb.CountValidatorProposalsParams{
+		Address:    address,
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+	})
+	if err != nil {
+		return nil, err
+	}
+	uptime.Blocks, uptime.Signed, uptime.Proposed = uint64(blocks), uint64(signed), uint64(proposed)
+	if blocks > 0 {
+		uptime.Uptime = float64(signed) / float64(blocks)
+	}
+	return uptime, nil
+}
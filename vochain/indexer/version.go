@@ -0,0 +1,59 @@
+package indexer
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// DataKind is a kind of indexed data whose changes are tracked by DataVersion.
+type DataKind int
+
+const (
+	// DataBlocks are the indexed blocks.
+	DataBlocks DataKind = iota
+	// DataTransactions are the indexed transactions.
+	DataTransactions
+	// DataVotes are the indexed votes.
+	DataVotes
+
+	dataKinds
+)
+
+// dataVersions are the version counters of the kinds of indexed data, each one
+// incremented when the data of its kind changes.
+type dataVersions struct {
+	// epoch is random for each instance, so that the versions of an indexer are
+	// not confused with the ones of a previous run or another node.
+	epoch    uint32
+	counters [dataKinds]atomic.Uint64
+}
+
+func newDataVersions() *dataVersions {
+	return &dataVersions{epoch: rand.Uint32()}
+}
+
+// bump increments the versions of the given kinds, or all of them if none.
+func (v *dataVersions) bump(kinds ...DataKind) {
+	if len(kinds) == 0 {
+		for i := range v.counters {
+			v.counters[i].Add(1)
+		}
+		return
+	}
+	for _, kind := range kinds {
+		v.counters[kind].Add(1)
+	}
+}
+
+// DataVersion returns an opaque version of the indexed data of the given kinds,
+// which changes whenever any of them changes. It is meant to be used as an
+// HTTP entity tag of the responses built from that data, so it must be read
+// before the data is queried.
+func (idx *Indexer) DataVersion(kinds ...DataKind) string {
+	version := fmt.Sprintf("%08x", idx.versions.epoch)
+	for _, kind := range kinds {
+		version += fmt.Sprintf("-%d", idx.versions.counters[kind].Load())
+	}
+	return version
+}
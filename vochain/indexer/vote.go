@@ -36,9 +36,14 @@ func (idx *Indexer) GetEnvelope(nullifier []byte) (*indexertypes.EnvelopePackage
 		}
 		return nil, err
 	}
+	return envelopeFromRow(voteRef)
+}
 
+// envelopeFromRow builds the envelope of a vote from its row in the database.
+func envelopeFromRow(voteRef indexerdb.GetVoteRow) (*indexertypes.EnvelopePackage, error) {
 	envelopePackage := &indexertypes.EnvelopePackage{
 		VotePackage:          []byte(voteRef.Package),
+		DecryptedVotePackage: nonEmptyBytes([]byte(voteRef.DecryptedPackage)),
 		EncryptionKeyIndexes: indexertypes.DecodeJSON[[]uint32](voteRef.EncryptionKeyIndexes),
 		Weight:               indexertypes.DecodeJSON[string](voteRef.Weight),
 		OverwriteCount:       uint32(voteRef.OverwriteCount),
@@ -46,37 +51,110 @@ func (idx *Indexer) GetEnvelope(nullifier []byte) (*indexertypes.EnvelopePackage
 		Meta: indexertypes.EnvelopeMetadata{
 			VoterID:   voteRef.VoterID.Address(),
 			ProcessId: voteRef.ProcessID,
-			Nullifier: nullifier,
+			Nullifier: voteRef.Nullifier,
 			TxIndex:   int32(voteRef.BlockIndex),
 			Height:    uint32(voteRef.BlockHeight),
 			TxHash:    voteRef.TxHash,
+			Date:      nullTimePtr(voteRef.BlockTime),
 		},
 	}
 	if len(envelopePackage.Meta.VoterID) > 0 {
 		if envelopePackage.Meta.VoterID = voteRef.VoterID.Address(); envelopePackage.Meta.VoterID == nil {
-			return nil, fmt.Errorf("cannot get voterID from public key: %w", err)
+			return nil, fmt.Errorf("cannot get voterID from public key")
 		}
 	}
 	return envelopePackage, nil
 }
 
+// envelopeStreamBatchSize is the number of votes read from the database at once
+// by ProcessEnvelopes.
+const envelopeStreamBatchSize = 1000
+
+// ProcessEnvelopes calls fn with the envelopes of all the votes of a process,
+// sorted by nullifier. The votes are read in batches, and the next batch is
+// not read until fn returns for all the envelopes of the previous one, so a
+// slow consumer does not make the indexer hold more than a batch in memory.
+// It stops at the first error returned by fn, or when ctx is done.
+func (idx *Indexer) ProcessEnvelopes(ctx context.Context, pid []byte,
+	fn func(*indexertypes.EnvelopePackage) error,
+) error {
+	after := types.Nullifier(zeroBytes)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rows, err := idx.readOnlyQuery.GetProcessEnvelopes(ctx, indexerdb.GetProcessEnvelopesParams{
+			ProcessID:      pid,
+			AfterNullifier: after,
+			Limit:          envelopeStreamBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot get the votes of process %x: %w", pid, err)
+		}
+		for _, row := range rows {
+			envelope, err := envelopeFromRow(indexerdb.GetVoteRow(row))
+			if err != nil {
+				return err
+			}
+			if err := fn(envelope); err != nil {
+				return err
+			}
+		}
+		if len(rows) < envelopeStreamBatchSize {
+			return nil
+		}
+		after = rows[len(rows)-1].Nullifier
+	}
+}
+
 // VoteList retrieves all envelope metadata for a processID and nullifier (both args do partial or full string match).
 func (idx *Indexer) VoteList(limit, offset int, processID string, nullifier string,
 ) ([]*indexertypes.EnvelopeMetadata, uint64, error) {
+	list, _, total, err := idx.VoteListWithCursor(limit, offset, "", processID, nullifier, nil, nil)
+	return list, total, err
+}
+
+// VoteListWithCursor is like VoteList, but also accepts an opaque cursor returned by a
+// previous call. If not empty, the list starts right after the last vote returned by that
+// call, and offset is applied from there. It also returns the cursor to fetch the next page,
+// which is empty if there are no more votes. If from or to are set, only the votes whose
+// block time is in [from, to) are listed. The total number of votes is only counted when
+// cursor is empty, as it requires scanning all of them, and it is zero otherwise.
+func (idx *Indexer) VoteListWithCursor(limit, offset int, cursor, processID, nullifier string,
+	from, to *time.Time,
+) ([]*indexertypes.EnvelopeMetadata, string, uint64, error) {
 	if offset < 0 {
-		return nil, 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
+		return nil, "", 0, fmt.Errorf("invalid value: offset cannot be %d", offset)
 	}
 	if limit <= 0 {
-		return nil, 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
+		return nil, "", 0, fmt.Errorf("invalid value: limit cannot be %d", limit)
 	}
-	results, err := idx.readOnlyQuery.SearchVotes(context.TODO(), indexerdb.SearchVotesParams{
+	params := indexerdb.SearchVotesParams{
 		ProcessIDSubstr: processID,
 		NullifierSubstr: strings.ToLower(nullifier), // we search in lowercase
-		Limit:           int64(limit),
+		Limit:           int64(limit) + 1,           // fetch one more to know if there is a next page
 		Offset:          int64(offset),
-	})
+		FromTime:        utcTimeArg(from),
+		ToTime:          utcTimeArg(to),
+	}
+	if cursor != "" {
+		var blockHeight int64
+		var voteID []byte
+		if err := decodeCursor(cursor, &blockHeight, &voteID); err != nil {
+			return nil, "", 0, err
+		}
+		params.CursorBlockHeight = blockHeight
+		params.CursorNullifier = voteID
+	}
+	results, err := idx.readOnlyQuery.SearchVotes(context.TODO(), params)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", 0, err
+	}
+	nextCursor := ""
+	if len(results) > limit {
+		results = results[:limit]
+		last := results[limit-1]
+		nextCursor = encodeCursor(last.BlockHeight, last.Nullifier)
 	}
 	list := []*indexertypes.EnvelopeMetadata{}
 	for _, txRef := range results {
@@ -86,16 +164,75 @@ func (idx *Indexer) VoteList(limit, offset int, processID string, nullifier stri
 			TxIndex:   int32(txRef.BlockIndex),
 			Height:    uint32(txRef.BlockHeight),
 			TxHash:    txRef.Hash,
+			Date:      nullTimePtr(txRef.BlockTime),
 		}
 		if len(txRef.VoterID) > 0 {
 			envelopeMetadata.VoterID = state.VoterID(txRef.VoterID).Address()
 		}
 		list = append(list, envelopeMetadata)
 	}
-	if len(results) == 0 {
-		return list, 0, nil
+	if len(results) == 0 || cursor != "" {
+		return list, nextCursor, 0, nil
+	}
+	if !idx.estimateCounts {
+		total, err := idx.CountVotes(processID, nullifier, from, to)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		return list, nextCursor, total, nil
+	}
+	lowerBound := estimatedCountLowerBound(offset, len(results), nextCursor)
+	if from != nil || to != nil {
+		return list, nextCursor, lowerBound, nil
+	}
+	total, err := idx.estimateVotesCount(processID, nullifier, lowerBound)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return list, nextCursor, total, nil
+}
+
+// estimateVotesCount returns an estimation of the number of votes matching the
+// filters of VoteList, which is at least lowerBound. It is exact when filtering
+// by a whole process ID, and an upper bound when there are no filters.
+func (idx *Indexer) estimateVotesCount(processID, nullifier string, lowerBound uint64) (uint64, error) {
+	var count int64
+	switch {
+	case processID == "" && nullifier == "":
+		var err error
+		if count, err = idx.readOnlyQuery.GetVotesMaxRowID(context.TODO()); err != nil {
+			return 0, err
+		}
+	case len(processID) == 64 && nullifier == "":
+		pid, err := hex.DecodeString(processID)
+		if err != nil {
+			return lowerBound, nil
+		}
+		if idx.backfillPending(BackfillProcessVoteCounts) {
+			count, err = idx.readOnlyQuery.CountProcessVotes(context.TODO(), pid)
+		} else {
+			count, err = idx.readOnlyQuery.GetProcessVoteCount(context.TODO(), pid)
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return 0, err
+		}
+	}
+	return max(uint64(count), lowerBound), nil
+}
+
+// CountVotes returns the exact number of votes matching the filters of
+// VoteListWithCursor.
+func (idx *Indexer) CountVotes(processID, nullifier string, from, to *time.Time) (uint64, error) {
+	count, err := idx.readOnlyQuery.CountSearchVotes(context.TODO(), indexerdb.CountSearchVotesParams{
+		ProcessIDSubstr: processID,
+		NullifierSubstr: strings.ToLower(nullifier), // we search in lowercase
+		FromTime:        utcTimeArg(from),
+		ToTime:          utcTimeArg(to),
+	})
+	if err != nil {
+		return 0, err
 	}
-	return list, uint64(results[0].TotalCount), nil
+	return uint64(count), nil
 }
 
 // CountTotalVotes returns the total number of envelopes.
@@ -125,31 +262,22 @@ func (idx *Indexer) finalizeResults(ctx context.Context, queries *indexerdb.Quer
 
 	// Remove the process from the live results
 	idx.delProcessFromLiveResults(processID)
+	idx.blockFinalizedProcs = append(idx.blockFinalizedProcs, processID)
 
 	return nil
 }
 
 // unmarshalVote decodes the base64 payload to a VotePackage struct type.
-// If the state.VotePackage is encrypted the list of keys to decrypt it should be provided.
-// The order of the Keys must be as it was encrypted.
-// The function will reverse the order and use the decryption keys starting from the
-// last one provided.
+// If the state.VotePackage is encrypted the list of keys to decrypt it should be provided,
+// see decryptVote.
 func unmarshalVote(VotePackage []byte, keys []string) (*state.VotePackage, error) {
-	var rawVote []byte
+	rawVote := VotePackage
 	// if encryption keys, decrypt the vote
 	if len(keys) > 0 {
-		rawVote = bytes.Clone(VotePackage)
-		for i, key := range slices.Backward(keys) {
-			priv, err := nacl.DecodePrivate(key)
-			if err != nil {
-				return nil, fmt.Errorf("cannot create private key cipher: (%s)", err)
-			}
-			if rawVote, err = priv.Decrypt(rawVote); err != nil {
-				return nil, fmt.Errorf("cannot decrypt vote with index key %d: %w", i, err)
-			}
+		var err error
+		if rawVote, err = decryptVote(VotePackage, keys); err != nil {
+			return nil, err
 		}
-	} else {
-		rawVote = VotePackage
 	}
 	var vote state.VotePackage
 	if err := vote.Decode(rawVote); err != nil {
@@ -158,6 +286,24 @@ func unmarshalVote(VotePackage []byte, keys []string) (*state.VotePackage, error
 	return &vote, nil
 }
 
+// decryptVote decrypts an encrypted vote package with the list of keys.
+// The order of the Keys must be as it was encrypted.
+// The function will reverse the order and use the decryption keys starting from the
+// last one provided.
+func decryptVote(VotePackage []byte, keys []string) ([]byte, error) {
+	rawVote := bytes.Clone(VotePackage)
+	for i, key := range slices.Backward(keys) {
+		priv, err := nacl.DecodePrivate(key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create private key cipher: (%s)", err)
+		}
+		if rawVote, err = priv.Decrypt(rawVote); err != nil {
+			return nil, fmt.Errorf("cannot decrypt vote with index key %d: %w", i, err)
+		}
+	}
+	return rawVote, nil
+}
+
 // addLiveVote adds the envelope vote to the results. It does not commit to the database.
 // This method is triggered by OnVote callback for each vote added to the blockchain.
 // If encrypted vote, only weight will be updated.
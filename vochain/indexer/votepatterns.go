@@ -0,0 +1,274 @@
+package indexer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/state"
+)
+
+// The kinds of the alerts raised by the voting pattern analysis.
+const (
+	// VotePatternVelocity means that a process receives votes faster than
+	// VotePatternOptions.MaxVotesPerMinute.
+	VotePatternVelocity = "velocity"
+	// VotePatternWeightConcentration means that a single vote holds more than
+	// VotePatternOptions.MaxWeightShare of the weight of the recent votes.
+	VotePatternWeightConcentration = "weight_concentration"
+	// VotePatternDuplicatePackages means that more than
+	// VotePatternOptions.MaxDuplicateShare of the recent votes repeat the vote
+	// package of another one.
+	VotePatternDuplicatePackages = "duplicate_packages"
+)
+
+// The default thresholds of VotePatternOptions.
+const (
+	DefaultVotePatternWindow   = 10 * time.Minute
+	DefaultVotePatternMinVotes = 20
+	DefaultMaxVotesPerMinute   = 600
+	DefaultMaxWeightShare      = 0.5
+	DefaultMaxDuplicateShare   = 0.5
+)
+
+// VotePatternOptions configures the analysis of the voting patterns, which
+// helps the operators spot ballot-stuffing attempts, mostly on the elections
+// with an open census. The metrics of each process are computed on Commit over
+// its votes of the last Window, and only once there are at least MinVotes of
+// them, so that the first votes of an election do not raise alerts.
+//
+// The zero value of each field means its default, and a negative threshold
+// disables its check. Note that the vote packages of the encrypted elections
+// are never identical, so their duplicates are not detected.
+type VotePatternOptions struct {
+	Window   time.Duration
+	MinVotes int
+	// MaxVotesPerMinute is the threshold of the average rate of votes.
+	MaxVotesPerMinute float64
+	// MaxWeightShare is the threshold of the share of the weight of the votes
+	// held by the heaviest one.
+	MaxWeightShare float64
+	// MaxDuplicateShare is the threshold of the share of the votes with the
+	// same vote package as a previous one.
+	MaxDuplicateShare float64
+}
+
+// VotePatternListener is an optional interface of the event listeners, to be
+// alerted when a voting pattern metric of a process exceeds its threshold, see
+// VotePatternOptions. OnVotePatternAlert is called on Commit, once per process
+// and kind until the metric goes back under its threshold, so it should not
+// block.
+type VotePatternListener interface {
+	OnVotePatternAlert(alert *indexertypes.VotePatternAlert, height uint32)
+}
+
+// VotePatterns returns the voting pattern metrics of the process pid over its
+// recent votes, or nil if it has no recent votes or the analysis is disabled.
+func (idx *Indexer) VotePatterns(pid []byte) *indexertypes.VotePatternStats {
+	if idx.votePatterns == nil {
+		return nil
+	}
+	return idx.votePatterns.stats(pid)
+}
+
+// votePatternVote is a vote kept in the window of its process.
+type votePatternVote struct {
+	time    time.Time
+	weight  *big.Int
+	pkgHash [sha256.Size]byte
+}
+
+// votePatternWindow holds the recent votes of a process.
+type votePatternWindow struct {
+	// votes are sorted by time.
+	votes []votePatternVote
+	// alerting is the set of alert kinds whose metric is over its threshold.
+	alerting map[string]bool
+}
+
+// votePatterns computes the voting pattern metrics of the processes over
+// sliding windows of their votes, kept in memory.
+type votePatterns struct {
+	opts VotePatternOptions
+
+	mu sync.Mutex
+	// pending are the votes of the current block, added to the windows on
+	// commit and discarded on rollback. Protected by mu.
+	pending []*state.Vote
+	// windows are keyed by process ID as a string. Protected by mu.
+	windows map[string]*votePatternWindow
+}
+
+func newVotePatterns(opts VotePatternOptions) *votePatterns {
+	if opts.Window <= 0 {
+		opts.Window = DefaultVotePatternWindow
+	}
+	if opts.MinVotes <= 0 {
+		opts.MinVotes = DefaultVotePatternMinVotes
+	}
+	if opts.MaxVotesPerMinute == 0 {
+		opts.MaxVotesPerMinute = DefaultMaxVotesPerMinute
+	}
+	if opts.MaxWeightShare == 0 {
+		opts.MaxWeightShare = DefaultMaxWeightShare
+	}
+	if opts.MaxDuplicateShare == 0 {
+		opts.MaxDuplicateShare = DefaultMaxDuplicateShare
+	}
+	return &votePatterns{
+		opts:    opts,
+		windows: make(map[string]*votePatternWindow),
+	}
+}
+
+// add adds a vote of the current block.
+func (vp *votePatterns) add(vote *state.Vote) {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+	vp.pending = append(vp.pending, vote)
+}
+
+// rollback discards the votes of the current block.
+func (vp *votePatterns) rollback() {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+	vp.pending = nil
+}
+
+// commit adds the votes of the current block to the windows of their
+// processes, at the block time, and removes the votes out of the windows. It
+// returns the alerts raised by the processes with new votes.
+func (vp *votePatterns) commit(blockTime time.Time) []*indexertypes.VotePatternAlert {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+	var updated []string
+	seen := make(map[string]bool)
+	for _, vote := range vp.pending {
+		pid := string(vote.ProcessID)
+		w := vp.windows[pid]
+		if w == nil {
+			w = &votePatternWindow{alerting: make(map[string]bool)}
+			vp.windows[pid] = w
+		}
+		if !seen[pid] {
+			seen[pid] = true
+			updated = append(updated, pid)
+		}
+		weight := vote.Weight
+		if weight == nil {
+			weight = big.NewInt(1)
+		}
+		w.votes = append(w.votes, votePatternVote{
+			time:    blockTime,
+			weight:  weight,
+			pkgHash: sha256.Sum256(vote.VotePackage),
+		})
+	}
+	vp.pending = nil
+
+	since := blockTime.Add(-vp.opts.Window)
+	for pid, w := range vp.windows {
+		i := 0
+		for i < len(w.votes) && !w.votes[i].time.After(since) {
+			i++
+		}
+		w.votes = w.votes[i:]
+		if len(w.votes) == 0 {
+			delete(vp.windows, pid)
+		}
+	}
+
+	var alerts []*indexertypes.VotePatternAlert
+	for _, pid := range updated {
+		w := vp.windows[pid]
+		if w == nil {
+			continue
+		}
+		if len(w.votes) < vp.opts.MinVotes {
+			clear(w.alerting)
+			continue
+		}
+		stats := vp.windowStats([]byte(pid), w)
+		for _, check := range []struct {
+			kind      string
+			value     float64
+			threshold float64
+		}{
+			{VotePatternVelocity, stats.VotesPerMinute, vp.opts.MaxVotesPerMinute},
+			{VotePatternWeightConcentration, stats.TopWeightShare, vp.opts.MaxWeightShare},
+			{VotePatternDuplicatePackages, stats.DuplicateShare, vp.opts.MaxDuplicateShare},
+		} {
+			if check.threshold < 0 || check.value <= check.threshold {
+				delete(w.alerting, check.kind)
+				continue
+			}
+			if w.alerting[check.kind] {
+				continue
+			}
+			w.alerting[check.kind] = true
+			alerts = append(alerts, &indexertypes.VotePatternAlert{
+				Kind:             check.kind,
+				Value:            check.value,
+				Threshold:        check.threshold,
+				VotePatternStats: *stats,
+			})
+		}
+	}
+	return alerts
+}
+
+// stats returns the metrics of the window of the process pid, if any.
+func (vp *votePatterns) stats(pid []byte) *indexertypes.VotePatternStats {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+	w := vp.windows[string(pid)]
+	if w == nil {
+		return nil
+	}
+	return vp.windowStats(pid, w)
+}
+
+// windowStats computes the metrics of a window, which must not be empty.
+func (vp *votePatterns) windowStats(pid []byte, w *votePatternWindow) *indexertypes.VotePatternStats {
+	total, top := new(big.Int), new(big.Int)
+	packages := make(map[[sha256.Size]byte]bool, len(w.votes))
+	for _, vote := range w.votes {
+		total.Add(total, vote.weight)
+		if vote.weight.Cmp(top) > 0 {
+			top = vote.weight
+		}
+		packages[vote.pkgHash] = true
+	}
+	stats := &indexertypes.VotePatternStats{
+		ProcessID:      pid,
+		Votes:          len(w.votes),
+		VotesPerMinute: float64(len(w.votes)) / vp.opts.Window.Minutes(),
+		DuplicateShare: float64(len(w.votes)-len(packages)) / float64(len(w.votes)),
+	}
+	if total.Sign() > 0 {
+		stats.TopWeightShare, _ = new(big.Rat).SetFrac(top, total).Float64()
+	}
+	return stats
+}
+
+// notifyVotePatternAlerts logs the alerts, counts them in the metrics and
+// notifies them to the event listeners.
+func (idx *Indexer) notifyVotePatternAlerts(alerts []*indexertypes.VotePatternAlert, height uint32) {
+	for _, alert := range alerts {
+		log.Warnw("anomalous voting pattern",
+			"processID", hex.EncodeToString(alert.ProcessID), "kind", alert.Kind,
+			"value", alert.Value, "threshold", alert.Threshold, "votes", alert.Votes)
+		metrics.GetOrCreateCounter(fmt.Sprintf("vochain_indexer_vote_pattern_alerts_total{kind=%q}", alert.Kind)).Inc()
+		for _, l := range idx.eventOnResults {
+			if l, ok := l.(VotePatternListener); ok {
+				l.OnVotePatternAlert(alert, height)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package indexer
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/state"
+)
+
+func TestVotePatterns(t *testing.T) {
+	c := qt.New(t)
+	vp := newVotePatterns(VotePatternOptions{
+		Window:            time.Minute,
+		MinVotes:          4,
+		MaxVotesPerMinute: 10,
+		MaxDuplicateShare: -1,
+	})
+	pid := []byte{1, 2}
+	start := time.Unix(1700000000, 0)
+	addVotes := func(n int, weight int64) {
+		for i := range n {
+			vp.add(&state.Vote{
+				ProcessID:   pid,
+				VotePackage: fmt.Appendf(nil, `{"votes":[%d]}`, i%2),
+				Weight:      big.NewInt(weight),
+			})
+		}
+	}
+	kinds := func(alerts []*indexertypes.VotePatternAlert) []string {
+		var kinds []string
+		for _, alert := range alerts {
+			kinds = append(kinds, alert.Kind)
+		}
+		return kinds
+	}
+
+	// no alerts until there are enough votes
+	addVotes(3, 1)
+	c.Assert(vp.commit(start), qt.HasLen, 0)
+	c.Assert(vp.stats(pid).Votes, qt.Equals, 3)
+
+	// the votes rolled back are not counted
+	addVotes(10, 1)
+	vp.rollback()
+	addVotes(1, 10)
+	c.Assert(kinds(vp.commit(start.Add(10*time.Second))), qt.DeepEquals, []string{VotePatternWeightConcentration})
+	stats := vp.stats(pid)
+	c.Assert(stats.Votes, qt.Equals, 4)
+	c.Assert(stats.TopWeightShare, qt.Equals, float64(10)/13)
+	c.Assert(stats.DuplicateShare, qt.Equals, 0.5)
+
+	// each alert is raised once while over its threshold
+	addVotes(8, 1)
+	c.Assert(kinds(vp.commit(start.Add(20*time.Second))), qt.DeepEquals, []string{VotePatternVelocity})
+	addVotes(1, 1)
+	c.Assert(vp.commit(start.Add(30*time.Second)), qt.HasLen, 0)
+
+	// the old votes leave the window, which resets the alerts
+	addVotes(4, 1)
+	c.Assert(vp.commit(start.Add(90*time.Second)), qt.HasLen, 0)
+	c.Assert(vp.stats(pid).Votes, qt.Equals, 4)
+	addVotes(7, 1)
+	c.Assert(kinds(vp.commit(start.Add(95*time.Second))), qt.DeepEquals, []string{VotePatternVelocity})
+
+	// the processes without recent votes are forgotten
+	c.Assert(vp.commit(start.Add(time.Hour)), qt.HasLen, 0)
+	c.Assert(vp.stats(pid), qt.IsNil)
+}
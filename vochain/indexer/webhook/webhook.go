@@ -0,0 +1,391 @@
+// Package webhook notifies external services about the events of the vochain,
+// such as the results of a process, by sending a signed JSON payload to a set
+// of configured URLs, so that integrators do not need to poll the API.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/results"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// Types of the events sent to the webhooks.
+const (
+	EventResults       = "results"
+	EventProcessStatus = "processStatus"
+	EventTransfer      = "transfer"
+	EventCensusSize    = "censusSize"
+	EventVotePattern   = "votePattern"
+)
+
+const (
+	// SignatureHeader is the HTTP header that carries the hex encoded
+	// HMAC-SHA256 of the request body, using the configured secret as key.
+	SignatureHeader = "X-Vocdoni-Signature"
+	// EventHeader is the HTTP header that carries the type of the event.
+	EventHeader = "X-Vocdoni-Event"
+)
+
+// Placeholders of the URL templates, replaced by the values of each event.
+const (
+	placeholderEvent     = "{event}"
+	placeholderProcessID = "{processId}"
+)
+
+const (
+	// DefaultMaxRetries is the number of times a failed delivery is retried.
+	DefaultMaxRetries = 5
+	// DefaultRetryDelay is the delay before the first retry, doubled on each one.
+	DefaultRetryDelay = 2 * time.Second
+	// DefaultTimeout is the timeout of each delivery request.
+	DefaultTimeout = 10 * time.Second
+
+	// queueSize is the number of events waiting to be delivered, once it is
+	// full the new events are dropped to the dead-letter log.
+	queueSize = 1024
+	// maxResponseSize is the max size of the response body that is logged.
+	maxResponseSize = 512
+)
+
+// Config is the configuration of the webhooks of a node.
+type Config struct {
+	// URLs are the templates of the URLs the events are posted to. The
+	// placeholders {event} and {processId} are replaced by the type of the
+	// event and the hex encoded process ID (empty for transfers).
+	URLs []string
+	// Secret is the key of the HMAC-SHA256 signature of the payloads.
+	Secret string
+	// MinTransfer is the minimum amount of a token transfer to be notified,
+	// zero disables the transfer events.
+	MinTransfer uint64
+	// MaxRetries, RetryDelay and Timeout default to DefaultMaxRetries,
+	// DefaultRetryDelay and DefaultTimeout if zero.
+	MaxRetries int
+	RetryDelay time.Duration
+	Timeout    time.Duration
+}
+
+// Event is the JSON payload posted to the webhooks.
+type Event struct {
+	Type          string              `json:"type"`
+	Height        uint32              `json:"height"`
+	ProcessID     types.HexBytes      `json:"processId,omitempty"`
+	Status        string              `json:"status,omitempty"`
+	Results       [][]*types.BigInt   `json:"results,omitempty"`
+	Weight        *types.BigInt       `json:"weight,omitempty"`
+	Votes         uint64              `json:"votes,omitempty"`
+	MaxCensusSize uint64              `json:"maxCensusSize,omitempty"`
+	Transfer      *TransferEventField `json:"transfer,omitempty"`
+	// Alert is the anomalous voting pattern of an EventVotePattern.
+	Alert *indexertypes.VotePatternAlert `json:"alert,omitempty"`
+}
+
+// TransferEventField holds the details of a token transfer event.
+type TransferEventField struct {
+	TxHash types.HexBytes `json:"txHash"`
+	From   types.HexBytes `json:"from"`
+	To     types.HexBytes `json:"to"`
+	Amount uint64         `json:"amount"`
+}
+
+// Webhooks posts the events to the configured URLs. It implements
+// state.EventListener, to notify the process status changes and the token
+// transfers once their block is committed, and indexer.EventListener and
+// indexer.CensusSizeListener and indexer.VotePatternListener, to notify the
+// results of the processes, the processes approaching their max census size,
+// and the anomalous voting patterns.
+type Webhooks struct {
+	config Config
+	client *http.Client
+	queue  chan *Event
+
+	// pending are the events of the block being executed, queued on Commit
+	// and discarded on Rollback. Protected by pendingMu.
+	pendingMu sync.Mutex
+	pending   []*Event
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// deadLetter is called when an event cannot be delivered to a URL.
+	deadLetter func(url string, event *Event, err error)
+}
+
+var _ state.EventListener = (*Webhooks)(nil)
+
+// New validates the configuration and starts delivering the events in the
+// background, until Close is called.
+func New(config Config) (*Webhooks, error) {
+	if len(config.URLs) == 0 {
+		return nil, fmt.Errorf("no webhook URLs configured")
+	}
+	if config.Secret == "" {
+		return nil, fmt.Errorf("webhook secret cannot be empty")
+	}
+	for _, tmpl := range config.URLs {
+		u, err := url.Parse(expandURL(tmpl, &Event{Type: EventResults, ProcessID: []byte{0}}))
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook URL %q: %w", tmpl, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid webhook URL %q: must be an absolute http or https URL", tmpl)
+		}
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = DefaultMaxRetries
+	}
+	if config.RetryDelay == 0 {
+		config.RetryDelay = DefaultRetryDelay
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+	w := &Webhooks{
+		config:     config,
+		client:     &http.Client{Timeout: config.Timeout},
+		queue:      make(chan *Event, queueSize),
+		deadLetter: logDeadLetter,
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	w.wg.Add(1)
+	go w.run()
+	return w, nil
+}
+
+// Close stops delivering the events. The events still queued are discarded.
+func (w *Webhooks) Close() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+// run delivers the queued events until the context is canceled.
+func (w *Webhooks) run() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case event := <-w.queue:
+			w.deliver(event)
+		}
+	}
+}
+
+// enqueue queues an event to be delivered, or sends it to the dead-letter log
+// if the queue is full.
+func (w *Webhooks) enqueue(event *Event) {
+	select {
+	case w.queue <- event:
+	default:
+		w.deadLetter("", event, fmt.Errorf("queue is full"))
+	}
+}
+
+// deliver posts an event to all the URLs, retrying the failed requests.
+func (w *Webhooks) deliver(event *Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Errorw(err, "cannot marshal webhook event")
+		return
+	}
+	mac := hmac.New(sha256.New, []byte(w.config.Secret))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+	for _, tmpl := range w.config.URLs {
+		u := expandURL(tmpl, event)
+		if err := w.post(u, event.Type, body, signature); err != nil {
+			if w.ctx.Err() != nil {
+				return
+			}
+			w.deadLetter(u, event, err)
+		}
+	}
+}
+
+// post sends the request to the URL, retrying with an exponential backoff on
+// network errors and on 429 and 5xx responses.
+func (w *Webhooks) post(u, eventType string, body []byte, signature string) error {
+	delay := w.config.RetryDelay
+	var err error
+	for attempt := 0; attempt <= w.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-w.ctx.Done():
+				return w.ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+		var retry bool
+		if retry, err = w.request(u, eventType, body, signature); err == nil || !retry {
+			return err
+		}
+		log.Debugw("webhook delivery failed", "url", u, "event", eventType, "attempt", attempt+1, "error", err)
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", w.config.MaxRetries+1, err)
+}
+
+// request sends a single request, and returns whether it should be retried if
+// it failed.
+func (w *Webhooks) request(u, eventType string, body []byte, signature string) (bool, error) {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventType)
+	req.Header.Set(SignatureHeader, signature)
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	err = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// expandURL replaces the placeholders of the URL template by the values of the event.
+func expandURL(tmpl string, event *Event) string {
+	return strings.NewReplacer(
+		placeholderEvent, url.PathEscape(event.Type),
+		placeholderProcessID, hex.EncodeToString(event.ProcessID),
+	).Replace(tmpl)
+}
+
+// logDeadLetter logs an event that could not be delivered, including its
+// payload, so it can be delivered manually.
+func logDeadLetter(u string, event *Event, err error) {
+	payload, _ := json.Marshal(event)
+	log.Warnw("webhook dead letter", "url", u, "event", event.Type,
+		"height", event.Height, "payload", string(payload), "error", err.Error())
+}
+
+// addPending adds an event of the current block, to be queued on Commit.
+func (w *Webhooks) addPending(event *Event) {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	w.pending = append(w.pending, event)
+}
+
+// OnComputeResults queues the results of a process. It implements
+// indexer.EventListener.
+func (w *Webhooks) OnComputeResults(results *results.Results, process *indexertypes.Process, height uint32) {
+	w.enqueue(&Event{
+		Type:      EventResults,
+		Height:    height,
+		ProcessID: process.ID,
+		Status:    models.ProcessStatus(process.Status).String(),
+		Results:   results.Votes,
+		Weight:    results.Weight,
+	})
+}
+
+// OnCensusSizeAlert queues the alert of a process approaching its max census
+// size. It implements indexer.CensusSizeListener.
+func (w *Webhooks) OnCensusSizeAlert(process *indexertypes.Process, height uint32) {
+	w.enqueue(&Event{
+		Type:          EventCensusSize,
+		Height:        height,
+		ProcessID:     process.ID,
+		Votes:         process.VoteCount,
+		MaxCensusSize: process.MaxCensusSize,
+	})
+}
+
+// OnVotePatternAlert queues the alert of an anomalous voting pattern of a
+// process. It implements indexer.VotePatternListener.
+func (w *Webhooks) OnVotePatternAlert(alert *indexertypes.VotePatternAlert, height uint32) {
+	w.enqueue(&Event{
+		Type:      EventVotePattern,
+		Height:    height,
+		ProcessID: alert.ProcessID,
+		Votes:     uint64(alert.Votes),
+		Alert:     alert,
+	})
+}
+
+// OnProcessStatusChange adds a process status change to the current block.
+func (w *Webhooks) OnProcessStatusChange(pid []byte, status models.ProcessStatus, _ int32) {
+	w.addPending(&Event{
+		Type:      EventProcessStatus,
+		ProcessID: pid,
+		Status:    status.String(),
+	})
+}
+
+// OnTransferTokens adds a token transfer to the current block, if its amount
+// is at least the configured MinTransfer.
+func (w *Webhooks) OnTransferTokens(tx *vochaintx.TokenTransfer) {
+	if w.config.MinTransfer == 0 || tx.Amount < w.config.MinTransfer {
+		return
+	}
+	w.addPending(&Event{
+		Type: EventTransfer,
+		Transfer: &TransferEventField{
+			TxHash: tx.TxHash,
+			From:   tx.FromAddress.Bytes(),
+			To:     tx.ToAddress.Bytes(),
+			Amount: tx.Amount,
+		},
+	})
+}
+
+// Commit queues the events of the committed block.
+func (w *Webhooks) Commit(height uint32) error {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	for _, event := range w.pending {
+		event.Height = height
+		w.enqueue(event)
+	}
+	w.pending = nil
+	return nil
+}
+
+// Rollback discards the events of the current block.
+func (w *Webhooks) Rollback() {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	w.pending = nil
+}
+
+// NOT USED but required for implementing the state.EventListener interface
+func (*Webhooks) OnVote(_ *state.Vote, _ int32)                               {}
+func (*Webhooks) OnNewTx(_ *vochaintx.Tx, _ uint32, _ int32)                  {}
+func (*Webhooks) OnTxReceipt(_ *vochaintx.Tx, _ *vochaintx.Receipt, _ int32)  {}
+func (*Webhooks) OnProcess(_ *models.Process, _ int32)                        {}
+func (*Webhooks) OnProcessDurationChange(_ []byte, _ uint32, _ int32)         {}
+func (*Webhooks) OnCancel(_ []byte, _ int32)                                  {}
+func (*Webhooks) OnProcessKeys(_ []byte, _ string, _ int32)                   {}
+func (*Webhooks) OnRevealKeys(_ []byte, _ string, _ int32)                    {}
+func (*Webhooks) OnProcessResults(_ []byte, _ *models.ProcessResult, _ int32) {}
+func (*Webhooks) OnProcessesStart(_ [][]byte)                                 {}
+func (*Webhooks) OnSetAccount(_ []byte, _ *state.Account)                     {}
+func (*Webhooks) OnSpendTokens(_ []byte, _ models.TxType, _ uint64, _ string) {}
+func (*Webhooks) OnSetAccountKV(_ []byte, _ string, _ []byte)                 {}
+func (*Webhooks) OnVoteDelegation(_, _ []byte, _ *state.VoteDelegation)       {}
+func (*Webhooks) OnAccountRecovery(_ []byte, _ *state.AccountRecoveryEvent)   {}
+func (*Webhooks) OnCensusUpdate(_, _ []byte, _ string, _ uint64)              {}
@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/indexer/indexertypes"
+	"go.vocdoni.io/dvote/vochain/results"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+const testSecret = "secret"
+
+type request struct {
+	path      string
+	eventType string
+	event     Event
+}
+
+// newTestServer returns a server that verifies the signature of the requests
+// and sends them to the returned channel. The status codes are returned in
+// order, and 200 once they are consumed.
+func newTestServer(t *testing.T, statusCodes ...int) (*httptest.Server, chan request) {
+	requests := make(chan request, 10)
+	var count atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		qt.Check(t, err, qt.IsNil)
+		mac := hmac.New(sha256.New, []byte(testSecret))
+		mac.Write(body)
+		qt.Check(t, r.Header.Get(SignatureHeader), qt.Equals, hex.EncodeToString(mac.Sum(nil)))
+		if i := int(count.Add(1)) - 1; i < len(statusCodes) {
+			w.WriteHeader(statusCodes[i])
+			return
+		}
+		req := request{path: r.URL.Path, eventType: r.Header.Get(EventHeader)}
+		qt.Check(t, json.Unmarshal(body, &req.event), qt.IsNil)
+		requests <- req
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+func receive(t *testing.T, requests chan request) request {
+	select {
+	case req := <-requests:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the webhook request")
+	}
+	return request{}
+}
+
+func TestWebhooksEvents(t *testing.T) {
+	c := qt.New(t)
+	srv, requests := newTestServer(t)
+	w, err := New(Config{
+		URLs:        []string{srv.URL + "/hooks/{event}/{processId}"},
+		Secret:      testSecret,
+		MinTransfer: 100,
+	})
+	c.Assert(err, qt.IsNil)
+	defer w.Close()
+
+	// the events of a rolled back block are discarded
+	pid := types.HexBytes{0x01, 0x02}
+	w.OnProcessStatusChange(pid, models.ProcessStatus_CANCELED, 0)
+	w.Rollback()
+	// the transfers below the minimum are ignored
+	w.OnTransferTokens(&vochaintx.TokenTransfer{Amount: 99})
+	w.OnProcessStatusChange(pid, models.ProcessStatus_ENDED, 0)
+	w.OnTransferTokens(&vochaintx.TokenTransfer{
+		FromAddress: common.Address{1},
+		ToAddress:   common.Address{2},
+		Amount:      100,
+	})
+	c.Assert(w.Commit(10), qt.IsNil)
+
+	req := receive(t, requests)
+	c.Assert(req.path, qt.Equals, "/hooks/processStatus/0102")
+	c.Assert(req.eventType, qt.Equals, EventProcessStatus)
+	c.Assert(req.event.Height, qt.Equals, uint32(10))
+	c.Assert(req.event.ProcessID, qt.DeepEquals, pid)
+	c.Assert(req.event.Status, qt.Equals, models.ProcessStatus_ENDED.String())
+
+	req = receive(t, requests)
+	c.Assert(req.path, qt.Equals, "/hooks/transfer/")
+	c.Assert(req.event.Height, qt.Equals, uint32(10))
+	c.Assert(req.event.Transfer.Amount, qt.Equals, uint64(100))
+	c.Assert([]byte(req.event.Transfer.To), qt.DeepEquals, common.Address{2}.Bytes())
+
+	// the results are sent right away, since they are already committed
+	w.OnComputeResults(&results.Results{
+		Votes:  [][]*types.BigInt{{new(types.BigInt).SetUint64(3), new(types.BigInt).SetUint64(1)}},
+		Weight: new(types.BigInt).SetUint64(4),
+	}, &indexertypes.Process{ID: pid, Status: int32(models.ProcessStatus_RESULTS)}, 12)
+	req = receive(t, requests)
+	c.Assert(req.path, qt.Equals, "/hooks/results/0102")
+	c.Assert(req.event.Height, qt.Equals, uint32(12))
+	c.Assert(req.event.Results[0][0].String(), qt.Equals, "3")
+	c.Assert(req.event.Weight.String(), qt.Equals, "4")
+
+	// and so are the census size alerts
+	w.OnCensusSizeAlert(&indexertypes.Process{ID: pid, VoteCount: 9, MaxCensusSize: 10}, 13)
+	req = receive(t, requests)
+	c.Assert(req.path, qt.Equals, "/hooks/censusSize/0102")
+	c.Assert(req.event.Votes, qt.Equals, uint64(9))
+	c.Assert(req.event.MaxCensusSize, qt.Equals, uint64(10))
+
+	// and the anomalous voting patterns
+	w.OnVotePatternAlert(&indexertypes.VotePatternAlert{
+		Kind:             "velocity",
+		Value:            900,
+		Threshold:        600,
+		VotePatternStats: indexertypes.VotePatternStats{ProcessID: pid, Votes: 9000},
+	}, 14)
+	req = receive(t, requests)
+	c.Assert(req.path, qt.Equals, "/hooks/votePattern/0102")
+	c.Assert(req.event.Votes, qt.Equals, uint64(9000))
+	c.Assert(req.event.Alert.Kind, qt.Equals, "velocity")
+	c.Assert(req.event.Alert.Value, qt.Equals, float64(900))
+
+	select {
+	case req := <-requests:
+		t.Fatalf("unexpected request %+v", req)
+	default:
+	}
+}
+
+func TestWebhooksRetry(t *testing.T) {
+	c := qt.New(t)
+	srv, requests := newTestServer(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+	w, err := New(Config{
+		URLs:       []string{srv.URL},
+		Secret:     testSecret,
+		RetryDelay: time.Millisecond,
+	})
+	c.Assert(err, qt.IsNil)
+	defer w.Close()
+	w.deadLetter = func(_ string, _ *Event, err error) {
+		t.Errorf("unexpected dead letter: %v", err)
+	}
+
+	w.OnProcessStatusChange([]byte{1}, models.ProcessStatus_ENDED, 0)
+	c.Assert(w.Commit(1), qt.IsNil)
+	c.Assert(receive(t, requests).eventType, qt.Equals, EventProcessStatus)
+}
+
+func TestWebhooksDeadLetter(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range []struct {
+		name        string
+		statusCodes []int
+		attempts    int32
+	}{
+		{"ClientError", []int{http.StatusBadRequest}, 1},
+		{"MaxRetries", []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, 3},
+	} {
+		c.Run(test.name, func(c *qt.C) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(test.statusCodes[attempts.Add(1)-1])
+			}))
+			defer srv.Close()
+			w, err := New(Config{
+				URLs:       []string{srv.URL},
+				Secret:     testSecret,
+				MaxRetries: 2,
+				RetryDelay: time.Millisecond,
+			})
+			c.Assert(err, qt.IsNil)
+			defer w.Close()
+			deadLetters := make(chan *Event, 1)
+			w.deadLetter = func(_ string, event *Event, _ error) {
+				deadLetters <- event
+			}
+
+			w.OnProcessStatusChange([]byte{1}, models.ProcessStatus_ENDED, 0)
+			c.Assert(w.Commit(1), qt.IsNil)
+			select {
+			case event := <-deadLetters:
+				c.Assert(event.Type, qt.Equals, EventProcessStatus)
+			case <-time.After(5 * time.Second):
+				c.Fatal("timeout waiting for the dead letter")
+			}
+			c.Assert(attempts.Load(), qt.Equals, test.attempts)
+		})
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	c := qt.New(t)
+	_, err := New(Config{Secret: testSecret})
+	c.Assert(err, qt.ErrorMatches, "no webhook URLs configured")
+	_, err = New(Config{URLs: []string{"https://example.com"}})
+	c.Assert(err, qt.ErrorMatches, "webhook secret cannot be empty")
+	_, err = New(Config{URLs: []string{"example.com/{event}"}, Secret: testSecret})
+	c.Assert(err, qt.ErrorMatches, "invalid webhook URL.*")
+}
@@ -34,6 +34,7 @@ import (
 	"go.vocdoni.io/dvote/util"
 	"go.vocdoni.io/dvote/vochain/results"
 	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
 )
 
@@ -199,6 +200,13 @@ func (c *Controller) Commit(height, timestamp uint32) error {
 				return fmt.Errorf("cannot commit results for election %x: %w",
 					action.ElectionID, err)
 			}
+			// refund the vote deposits of the counted votes, burn the rest
+			if _, _, err := c.state.SettleVoteDeposits(action.ElectionID, func(vote *models.StateDBVote) bool {
+				return results.CountedVote(process, vote)
+			}); err != nil {
+				return fmt.Errorf("cannot settle vote deposits for election %x: %w",
+					action.ElectionID, err)
+			}
 			// delete the IST action
 			if err := c.removeAction(action.ID); err != nil {
 				return fmt.Errorf("cannot delete IST actions: %w", err)
@@ -228,6 +236,21 @@ func (c *Controller) Commit(height, timestamp uint32) error {
 	return nil
 }
 
+// RescheduleProcessEnd moves the end of the process, scheduled when it was
+// created, to the given end time, which may be before or after the previous
+// one.
+func (c *Controller) RescheduleProcessEnd(electionID []byte, endTime uint32) error {
+	if err := c.removeAction(electionID); err != nil {
+		return fmt.Errorf("cannot remove IST action: %w", err)
+	}
+	return c.addAction(&Action{
+		TypeID:     ActionEndProcess,
+		ElectionID: electionID,
+		ID:         electionID,
+		TimeStamp:  endTime,
+	})
+}
+
 // Remove removes an scheduled IST action.
 func (c *Controller) Remove(actionID []byte) error {
 	return c.removeAction(actionID)
@@ -258,6 +281,15 @@ func (c *Controller) endElection(electionID []byte) error {
 		models.ProcessStatus_RESULTS:
 		return nil
 	}
+	// since the process end fork, the processes which opt out of the
+	// automatic end are ended by their organizer
+	forkActive, err := c.state.ForkActive(state.ForkProcessEnd)
+	if err != nil {
+		return fmt.Errorf("endElection: %w", err)
+	}
+	if forkActive && vochaintx.ProcessModeManualEnd(process.Mode) {
+		return nil
+	}
 	// set the election to ended
 	if err := c.state.SetProcessStatus(electionID, models.ProcessStatus_ENDED, true); err != nil {
 		return fmt.Errorf("endElection: cannot set election to ENDED status: %w", err)
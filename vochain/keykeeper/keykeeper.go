@@ -1,6 +1,7 @@
 package keykeeper
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"slices"
@@ -8,6 +9,7 @@ import (
 
 	"go.vocdoni.io/dvote/crypto/ethereum"
 	"go.vocdoni.io/dvote/crypto/nacl"
+	"go.vocdoni.io/dvote/crypto/shamir"
 	"go.vocdoni.io/dvote/log"
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/util"
@@ -26,13 +28,21 @@ import (
 // The key keeper is a deterministic process, meaning that the keys are deterministic and can be re-created at any time.
 // This is useful for recovering the keys in case of a failure.
 // A key is generated by hashing the signer private key, the process ID and the key index.
+//
+// So that a single offline key keeper does not block the decryption of an ended process, once all the key keepers
+// have published their public keys, each one splits its private key in shares with the Shamir scheme, and escrows
+// them on the vochain encrypted to the public keys of the other key keepers. When a majority of them reveal their
+// private keys, the shares sent to them can be decrypted, and any key keeper recovers and reveals the private keys
+// which were not revealed.
 type KeyKeeper struct {
-	vochain          *vochain.BaseApplication
-	pidsToRevealKeys []types.HexBytes
-	pidsToAddKeys    []types.HexBytes
-	signer           *ethereum.SignKeys
-	lock             sync.Mutex
-	myIndex          int8
+	vochain           *vochain.BaseApplication
+	pidsToRevealKeys  []types.HexBytes
+	pidsToAddKeys     []types.HexBytes
+	pidsToShareKeys   []types.HexBytes
+	pidsToRecoverKeys []types.HexBytes
+	signer            *ethereum.SignKeys
+	lock              sync.Mutex
+	myIndex           int8
 }
 
 type processKeys struct {
@@ -83,6 +93,17 @@ func (k *KeyKeeper) RevealUnpublished() {
 				log.Errorw(err, fmt.Sprintf("cannot reveal keys for process %x", hex.EncodeToString(pid)))
 			}
 		}
+		if process.Status == models.ProcessStatus_ENDED && process.EnvelopeType.EncryptedVotes {
+			txs, err := k.recoveryTxs(pid, true)
+			if err != nil {
+				log.Warnw("cannot recover keys", "pid", hex.EncodeToString(pid), "err", err)
+			}
+			for _, tx := range txs {
+				if err := k.signAndSendTx(tx); err != nil {
+					log.Errorw(err, fmt.Sprintf("cannot reveal recovered key %d for process %x", tx.GetKeyIndex(), pid))
+				}
+			}
+		}
 	}
 	log.Infof("keykeeper reveal recovery finished")
 }
@@ -94,6 +115,8 @@ func (k *KeyKeeper) Rollback() {
 	defer k.lock.Unlock()
 	k.pidsToRevealKeys = []types.HexBytes{}
 	k.pidsToAddKeys = []types.HexBytes{}
+	k.pidsToShareKeys = []types.HexBytes{}
+	k.pidsToRecoverKeys = []types.HexBytes{}
 }
 
 // OnProcess creates the keys and add them to the pool queue, if the process requires it
@@ -154,6 +177,36 @@ func (k *KeyKeeper) Commit(_ uint32) error {
 			}
 		}()
 	}
+	// the shares and the recovered keys are computed here, since they depend on
+	// the keys published in this block, which are not committed yet
+	for _, pid := range k.pidsToShareKeys {
+		tx, err := k.keySharesTx(pid)
+		if err != nil {
+			log.Errorw(err, fmt.Sprintf("cannot split encryption key of process %x", pid))
+			continue
+		}
+		if tx == nil {
+			continue
+		}
+		go func() {
+			if err := k.signAndSendTx(tx); err != nil {
+				log.Errorw(err, fmt.Sprintf("cannot add encryption key shares to process %x", pid))
+			}
+		}()
+	}
+	for _, pid := range k.pidsToRecoverKeys {
+		txs, err := k.recoveryTxs(pid, false)
+		if err != nil {
+			log.Errorw(err, fmt.Sprintf("cannot recover encryption keys of process %x", pid))
+		}
+		for _, tx := range txs {
+			go func() {
+				if err := k.signAndSendTx(tx); err != nil {
+					log.Errorw(err, fmt.Sprintf("cannot reveal recovered key %d for process %x", tx.GetKeyIndex(), pid))
+				}
+			}()
+		}
+	}
 	return nil
 }
 
@@ -215,6 +268,148 @@ func (k *KeyKeeper) revealKeys(pid types.HexBytes) error {
 	return k.signAndSendTx(tx)
 }
 
+// keySharesTx returns the transaction that escrows the shares of the private key
+// of the given process, each one encrypted to the public key of another key
+// keeper, or nil if there are less than two other key keepers or the
+// state.ForkKeyShares fork is not applied yet.
+func (k *KeyKeeper) keySharesTx(pid types.HexBytes) (*models.AdminTx, error) {
+	if active, err := k.vochain.State.ForkActive(state.ForkKeyShares); err != nil || !active {
+		return nil, err
+	}
+	p, err := k.vochain.State.Process(pid, false)
+	if err != nil {
+		return nil, err
+	}
+	var recipients []uint32
+	for i, pub := range p.EncryptionPublicKeys {
+		if pub != "" && i != int(k.myIndex) {
+			recipients = append(recipients, uint32(i))
+		}
+	}
+	// the key is only split among two key keepers at least, since a single
+	// share would hold the whole key
+	if len(recipients) < 2 {
+		return nil, nil
+	}
+	pk, err := k.generateKeys(pid)
+	if err != nil {
+		return nil, err
+	}
+	splits, err := shamir.Split(pk.privKey, len(recipients), vochaintx.ProcessKeySharesThreshold(len(recipients)))
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]*vochaintx.ProcessKeyShare, len(recipients))
+	for i, index := range recipients {
+		pub, err := nacl.DecodePublic(p.EncryptionPublicKeys[index])
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %d: %w", index, err)
+		}
+		cipher, err := nacl.Anonymous.Encrypt(splits[i], pub)
+		if err != nil {
+			return nil, err
+		}
+		shares[i] = &vochaintx.ProcessKeyShare{KeyIndex: index, Cipher: cipher}
+	}
+	log.Infow("sharing encryption private key", "processId", pid.String(), "keyIndex", k.myIndex,
+		"shares", len(shares))
+	tx := vochaintx.NewSetProcessKeySharesTx(pid, uint32(k.myIndex), shares)
+	tx.Nonce = uint32(util.RandomInt(0, 1000000000))
+	return tx, nil
+}
+
+// recoveryTxs returns the transactions that reveal the private keys of the given
+// process which were not revealed by their key keepers, and can be recovered
+// from the shares sent to the key keepers that revealed theirs.
+func (k *KeyKeeper) recoveryTxs(pid types.HexBytes, committed bool) ([]*models.AdminTx, error) {
+	p, err := k.vochain.State.Process(pid, committed)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := recoverKeys(p, func(keyIndex uint32) ([]*vochaintx.ProcessKeyShare, error) {
+		return k.vochain.State.ProcessKeyShares(pid, keyIndex, committed)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var txs []*models.AdminTx
+	for index, key := range keys {
+		if index == uint32(k.myIndex) {
+			// our own key is revealed by revealKeys
+			continue
+		}
+		log.Infow("revealing recovered encryption key", "processId", pid.String(), "keyIndex", index)
+		txs = append(txs, &models.AdminTx{
+			Txtype:               models.TxType_REVEAL_PROCESS_KEYS,
+			KeyIndex:             &index,
+			Nonce:                uint32(util.RandomInt(0, 1000000000)),
+			ProcessId:            pid,
+			EncryptionPrivateKey: key,
+		})
+	}
+	return txs, nil
+}
+
+// recoverKeys returns, by key index, the private keys of the process which are
+// not revealed and can be recovered from the shares, returned by getShares,
+// that were sent to the key keepers which revealed their private keys.
+func recoverKeys(p *models.Process,
+	getShares func(keyIndex uint32) ([]*vochaintx.ProcessKeyShare, error),
+) (map[uint32][]byte, error) {
+	revealed := func(index uint32) string {
+		if int(index) < len(p.EncryptionPrivateKeys) {
+			return p.EncryptionPrivateKeys[index]
+		}
+		return ""
+	}
+	keys := make(map[uint32][]byte)
+	for i, pub := range p.EncryptionPublicKeys {
+		index := uint32(i)
+		if pub == "" || revealed(index) != "" {
+			continue
+		}
+		shares, err := getShares(index)
+		if err != nil {
+			return nil, err
+		}
+		var splits [][]byte
+		for _, share := range shares {
+			if revealed(share.KeyIndex) == "" {
+				continue
+			}
+			priv, err := nacl.DecodePrivate(revealed(share.KeyIndex))
+			if err != nil {
+				continue
+			}
+			split, err := priv.Decrypt(share.Cipher)
+			if err != nil {
+				log.Warnw("cannot decrypt key share", "processId", fmt.Sprintf("%x", p.ProcessId),
+					"keyIndex", index, "shareIndex", share.KeyIndex, "err", err)
+				continue
+			}
+			splits = append(splits, split)
+		}
+		if len(shares) == 0 || len(splits) < vochaintx.ProcessKeySharesThreshold(len(shares)) {
+			continue
+		}
+		key, err := shamir.Combine(splits)
+		if err != nil {
+			log.Warnw("cannot combine key shares", "processId", fmt.Sprintf("%x", p.ProcessId),
+				"keyIndex", index, "err", err)
+			continue
+		}
+		// the shares are not verifiable, so check the recovered key instead
+		priv, err := nacl.DecodePrivate(hex.EncodeToString(key))
+		if err != nil || hex.EncodeToString(priv.Public().Bytes()) != pub {
+			log.Warnw("recovered key does not match the public key", "processId", fmt.Sprintf("%x", p.ProcessId),
+				"keyIndex", index)
+			continue
+		}
+		keys[index] = key
+	}
+	return keys, nil
+}
+
 func (k *KeyKeeper) signAndSendTx(tx *models.AdminTx) error {
 	var err error
 	stx := &models.SignedTx{}
@@ -241,11 +436,42 @@ func (k *KeyKeeper) signAndSendTx(tx *models.AdminTx) error {
 	return nil
 }
 
-// OnProcessKeys does nothing
-func (*KeyKeeper) OnProcessKeys(_ []byte, _ string, _ int32) {}
+// OnProcessKeys queues the process to share our private key, once all the key
+// keepers have published their public keys.
+func (k *KeyKeeper) OnProcessKeys(pid []byte, _ string, _ int32) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	p, err := k.vochain.State.Process(pid, false)
+	if err != nil {
+		log.Errorw(err, "cannot get process from state")
+		return
+	}
+	if len(p.EncryptionPublicKeys)-1 < int(k.myIndex) || p.EncryptionPublicKeys[k.myIndex] == "" {
+		return
+	}
+	validators, err := k.vochain.State.Validators(false)
+	if err != nil {
+		log.Errorw(err, "cannot get validators from state")
+		return
+	}
+	for _, v := range validators {
+		if v.KeyIndex > 0 && int(v.KeyIndex) < len(p.EncryptionPublicKeys) &&
+			p.EncryptionPublicKeys[v.KeyIndex] == "" {
+			return
+		}
+	}
+	k.pidsToShareKeys = append(k.pidsToShareKeys, pid)
+}
 
-// OnRevealKeys does nothing
-func (*KeyKeeper) OnRevealKeys(_ []byte, _ string, _ int32) {}
+// OnRevealKeys queues the process to recover the private keys which can be
+// recovered with the revealed ones.
+func (k *KeyKeeper) OnRevealKeys(pid []byte, _ string, _ int32) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if !slices.ContainsFunc(k.pidsToRecoverKeys, func(p types.HexBytes) bool { return bytes.Equal(p, pid) }) {
+		k.pidsToRecoverKeys = append(k.pidsToRecoverKeys, pid)
+	}
+}
 
 // OnProcessResults does nothing
 func (*KeyKeeper) OnProcessResults(_ []byte, _ *models.ProcessResult, _ int32) {}
@@ -262,12 +488,24 @@ func (*KeyKeeper) OnTransferTokens(_ *vochaintx.TokenTransfer) {}
 // OnSpendTokens does nothing
 func (*KeyKeeper) OnSpendTokens(_ []byte, _ models.TxType, _ uint64, _ string) {}
 
+// OnSetAccountKV does nothing
+func (*KeyKeeper) OnSetAccountKV(_ []byte, _ string, _ []byte) {}
+
+// OnVoteDelegation does nothing
+func (*KeyKeeper) OnVoteDelegation(_, _ []byte, _ *state.VoteDelegation) {}
+
+// OnAccountRecovery does nothing
+func (*KeyKeeper) OnAccountRecovery(_ []byte, _ *state.AccountRecoveryEvent) {}
+
 // OnVote is not used by the KeyKeeper
 func (*KeyKeeper) OnVote(_ *state.Vote, _ int32) {}
 
 // OnNewTx is not used by the KeyKeeper
 func (*KeyKeeper) OnNewTx(_ *vochaintx.Tx, _ uint32, _ int32) {}
 
+// OnTxReceipt is not used by the KeyKeeper
+func (*KeyKeeper) OnTxReceipt(_ *vochaintx.Tx, _ *vochaintx.Receipt, _ int32) {}
+
 // OnCensusUpdate is not used by the KeyKeeper
 func (*KeyKeeper) OnCensusUpdate(_, _ []byte, _ string, _ uint64) {}
 
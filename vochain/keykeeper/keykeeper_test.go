@@ -0,0 +1,158 @@
+package keykeeper
+
+import (
+	"fmt"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+func TestRecoverKeys(t *testing.T) {
+	c := qt.New(t)
+	app := vochain.TestBaseApplication(t)
+
+	// three key keepers, which are not registered as listeners so that their
+	// transactions are sent synchronously by the test
+	var kks []*KeyKeeper
+	for i := 1; i <= 3; i++ {
+		signer := ethereum.NewSignKeys()
+		c.Assert(signer.Generate(), qt.IsNil)
+		c.Assert(app.State.AddValidator(&models.Validator{
+			Address:  signer.Address().Bytes(),
+			PubKey:   signer.PublicKey(),
+			Power:    10,
+			KeyIndex: uint32(i),
+		}), qt.IsNil)
+		kks = append(kks, &KeyKeeper{vochain: app, signer: signer, myIndex: int8(i)})
+	}
+
+	pid := util.RandomBytes(32)
+	c.Assert(app.State.AddProcess(&models.Process{
+		ProcessId:             pid,
+		EnvelopeType:          &models.EnvelopeType{EncryptedVotes: true},
+		Status:                models.ProcessStatus_READY,
+		Mode:                  &models.ProcessMode{AutoStart: true, Interruptible: true},
+		StartTime:             0,
+		Duration:              3600,
+		EncryptionPrivateKeys: make([]string, 16),
+		EncryptionPublicKeys:  make([]string, 16),
+		VoteOptions:           &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+		CensusOrigin:          models.CensusOrigin_OFF_CHAIN_TREE,
+		CensusRoot:            util.RandomBytes(32),
+		MaxCensusSize:         10,
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	for _, kk := range kks {
+		c.Assert(kk.addKeys(pid), qt.IsNil)
+	}
+	app.AdvanceTestBlock()
+
+	for _, kk := range kks {
+		tx, err := kk.keySharesTx(pid)
+		c.Assert(err, qt.IsNil)
+		c.Assert(tx, qt.IsNotNil)
+		c.Assert(kk.signAndSendTx(tx), qt.IsNil)
+	}
+	app.AdvanceTestBlock()
+	shares, err := app.State.ProcessKeyShares(pid, 3, true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(shares, qt.HasLen, 2)
+
+	// the shares cannot be replaced
+	tx, err := kks[0].keySharesTx(pid)
+	c.Assert(err, qt.IsNil)
+	c.Assert(kks[0].signAndSendTx(tx), qt.ErrorMatches, ".*already added.*")
+
+	c.Assert(app.State.SetProcessStatus(pid, models.ProcessStatus_ENDED, true), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	// the third key keeper is offline, a single revealed key is not enough to
+	// recover its key
+	c.Assert(kks[0].revealKeys(pid), qt.IsNil)
+	app.AdvanceTestBlock()
+	txs, err := kks[0].recoveryTxs(pid, true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(txs, qt.HasLen, 0)
+
+	// but a majority of the shares is
+	c.Assert(kks[1].revealKeys(pid), qt.IsNil)
+	app.AdvanceTestBlock()
+	txs, err = kks[0].recoveryTxs(pid, true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(txs, qt.HasLen, 1)
+	c.Assert(txs[0].GetKeyIndex(), qt.Equals, uint32(3))
+	c.Assert(kks[0].signAndSendTx(txs[0]), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	p, err := app.State.Process(pid, true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(p.GetKeyIndex(), qt.Equals, uint32(0))
+	keys, err := kks[2].generateKeys(pid)
+	c.Assert(err, qt.IsNil)
+	c.Assert(p.EncryptionPrivateKeys[3], qt.Equals, fmt.Sprintf("%x", keys.privKey))
+
+	// nothing is left to recover
+	txs, err = kks[1].recoveryTxs(pid, true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(txs, qt.HasLen, 0)
+}
+
+func TestKeySharesFork(t *testing.T) {
+	c := qt.New(t)
+	app := vochain.TestBaseApplicationWithForks(t, map[string]uint32{state.ForkKeyShares: 3})
+
+	var kks []*KeyKeeper
+	for i := 1; i <= 3; i++ {
+		signer := ethereum.NewSignKeys()
+		c.Assert(signer.Generate(), qt.IsNil)
+		c.Assert(app.State.AddValidator(&models.Validator{
+			Address:  signer.Address().Bytes(),
+			PubKey:   signer.PublicKey(),
+			Power:    10,
+			KeyIndex: uint32(i),
+		}), qt.IsNil)
+		kks = append(kks, &KeyKeeper{vochain: app, signer: signer, myIndex: int8(i)})
+	}
+	pid := util.RandomBytes(32)
+	c.Assert(app.State.AddProcess(&models.Process{
+		ProcessId:             pid,
+		EnvelopeType:          &models.EnvelopeType{EncryptedVotes: true},
+		Status:                models.ProcessStatus_READY,
+		Mode:                  &models.ProcessMode{AutoStart: true, Interruptible: true},
+		Duration:              3600,
+		EncryptionPrivateKeys: make([]string, 16),
+		EncryptionPublicKeys:  make([]string, 16),
+		VoteOptions:           &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1},
+		CensusOrigin:          models.CensusOrigin_OFF_CHAIN_TREE,
+		CensusRoot:            util.RandomBytes(32),
+		MaxCensusSize:         10,
+	}), qt.IsNil)
+	app.AdvanceTestBlock()
+	for _, kk := range kks {
+		c.Assert(kk.addKeys(pid), qt.IsNil)
+	}
+	app.AdvanceTestBlock()
+
+	// the key keepers do not escrow their keys before the fork, and the shares are rejected
+	c.Assert(app.Height(), qt.Equals, uint32(2))
+	tx, err := kks[0].keySharesTx(pid)
+	c.Assert(err, qt.IsNil)
+	c.Assert(tx, qt.IsNil)
+	c.Assert(kks[0].signAndSendTx(vochaintx.NewSetProcessKeySharesTx(pid, 1, []*vochaintx.ProcessKeyShare{
+		{KeyIndex: 2, Cipher: util.RandomBytes(64)},
+		{KeyIndex: 3, Cipher: util.RandomBytes(64)},
+	})), qt.ErrorMatches, ".*fork not active: keyShares.*")
+
+	app.AdvanceTestBlocksUntilHeight(3)
+	tx, err = kks[0].keySharesTx(pid)
+	c.Assert(err, qt.IsNil)
+	c.Assert(tx, qt.IsNotNil)
+	c.Assert(kks[0].signAndSendTx(tx), qt.IsNil)
+}
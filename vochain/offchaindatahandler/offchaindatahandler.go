@@ -166,10 +166,14 @@ func (d *OffChainDataHandler) OnSetAccount(_ []byte, account *state.Account) {
 func (*OffChainDataHandler) OnCancel(_ []byte, _ int32)                                      {}
 func (*OffChainDataHandler) OnVote(_ *state.Vote, _ int32)                                   {}
 func (*OffChainDataHandler) OnNewTx(_ *vochaintx.Tx, _ uint32, _ int32)                      {}
+func (*OffChainDataHandler) OnTxReceipt(_ *vochaintx.Tx, _ *vochaintx.Receipt, _ int32)      {}
 func (*OffChainDataHandler) OnProcessKeys(_ []byte, _ string, _ int32)                       {}
 func (*OffChainDataHandler) OnRevealKeys(_ []byte, _ string, _ int32)                        {}
 func (*OffChainDataHandler) OnProcessStatusChange(_ []byte, _ models.ProcessStatus, _ int32) {}
 func (*OffChainDataHandler) OnTransferTokens(_ *vochaintx.TokenTransfer)                     {}
 func (*OffChainDataHandler) OnProcessResults(_ []byte, _ *models.ProcessResult, _ int32)     {}
 func (*OffChainDataHandler) OnSpendTokens(_ []byte, _ models.TxType, _ uint64, _ string)     {}
+func (*OffChainDataHandler) OnSetAccountKV(_ []byte, _ string, _ []byte)                     {}
+func (*OffChainDataHandler) OnVoteDelegation(_, _ []byte, _ *state.VoteDelegation)           {}
+func (*OffChainDataHandler) OnAccountRecovery(_ []byte, _ *state.AccountRecoveryEvent)       {}
 func (*OffChainDataHandler) OnProcessDurationChange(_ []byte, _ uint32, _ int32)             {}
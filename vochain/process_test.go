@@ -12,6 +12,7 @@ import (
 	"go.vocdoni.io/dvote/util"
 	"go.vocdoni.io/dvote/vochain/genesis"
 	vstate "go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/proto"
 )
@@ -399,6 +400,12 @@ func createTestBaseApplicationAndAccounts(t *testing.T,
 	txCostNumber uint64,
 ) (*BaseApplication, []*ethereum.SignKeys) {
 	app := TestBaseApplication(t)
+	return app, createTestAccounts(t, app, txCostNumber)
+}
+
+// createTestAccounts initializes the accounts of createTestBaseApplicationAndAccounts
+// on the given application.
+func createTestAccounts(t *testing.T, app *BaseApplication, txCostNumber uint64) []*ethereum.SignKeys {
 	keys := make([]*ethereum.SignKeys, 0)
 	for i := 0; i < 4; i++ {
 		key := &ethereum.SignKeys{}
@@ -436,7 +443,7 @@ func createTestBaseApplicationAndAccounts(t *testing.T,
 	app.State.ElectionPriceCalc.SetCapacity(2000)
 	testCommitState(t, app)
 
-	return app, keys
+	return keys
 }
 
 // testCreateProcess creates a process with the given parameters via transaction.
@@ -531,6 +538,118 @@ func TestGlobalMaxProcessSize(t *testing.T) {
 	qt.Assert(t, testCreateProcessWithErr(t, accounts[0], app, process), qt.IsNotNil)
 }
 
+func TestNewProcessNullifierGroup(t *testing.T) {
+	app, accounts := createTestBaseApplicationAndAccounts(t, 10)
+
+	// define process
+	censusURI := ipfsUrlTest
+	mode := &models.ProcessMode{Interruptible: true}
+	vochaintx.SetProcessModeNullifierGroup(mode, []byte("group"))
+	process := &models.Process{
+		StartBlock:    1,
+		EnvelopeType:  &models.EnvelopeType{},
+		Mode:          mode,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 16, MaxValue: 16},
+		Status:        models.ProcessStatus_READY,
+		EntityId:      accounts[0].Address().Bytes(),
+		CensusRoot:    util.RandomBytes(32),
+		CensusURI:     &censusURI,
+		CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+		Duration:      60,
+		MaxCensusSize: 5,
+	}
+
+	// the nullifier group requires an anonymous process
+	qt.Assert(t, testCreateProcessWithErr(t, accounts[0], app, process),
+		qt.ErrorMatches, ".*nullifier group requires an anonymous process.*")
+	// without encrypted votes
+	process.EnvelopeType = &models.EnvelopeType{Anonymous: true, EncryptedVotes: true}
+	qt.Assert(t, testCreateProcessWithErr(t, accounts[0], app, process),
+		qt.ErrorMatches, ".*nullifier group not supported for encrypted votes.*")
+	// and a short group
+	process.EnvelopeType = &models.EnvelopeType{Anonymous: true}
+	vochaintx.SetProcessModeNullifierGroup(mode, util.RandomBytes(vstate.MaxNullifierGroupSize+1))
+	qt.Assert(t, testCreateProcessWithErr(t, accounts[0], app, process),
+		qt.ErrorMatches, ".*nullifier group too long.*")
+
+	vochaintx.SetProcessModeNullifierGroup(mode, []byte("group"))
+	pid := testCreateProcess(t, accounts[0], app, process)
+	qt.Assert(t, pid, qt.IsNotNil)
+	stored, err := app.State.Process(pid, false)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, vochaintx.ProcessModeNullifierGroup(stored.Mode), qt.DeepEquals, []byte("group"))
+	qt.Assert(t, vstate.ProcessNullifierScope(stored), qt.DeepEquals,
+		vstate.NullifierGroupScope(accounts[0].Address().Bytes(), []byte("group")))
+}
+
+func TestNewProcessNullifierGroupFork(t *testing.T) {
+	app := TestBaseApplicationWithForks(t, map[string]uint32{vstate.ForkNullifierGroups: 2})
+	accounts := createTestAccounts(t, app, 10)
+
+	censusURI := ipfsUrlTest
+	mode := &models.ProcessMode{Interruptible: true}
+	vochaintx.SetProcessModeNullifierGroup(mode, []byte("group"))
+	process := &models.Process{
+		StartBlock:    1,
+		EnvelopeType:  &models.EnvelopeType{Anonymous: true},
+		Mode:          mode,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 16, MaxValue: 16},
+		Status:        models.ProcessStatus_READY,
+		EntityId:      accounts[0].Address().Bytes(),
+		CensusRoot:    util.RandomBytes(32),
+		CensusURI:     &censusURI,
+		CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+		Duration:      60,
+		MaxCensusSize: 5,
+	}
+
+	// the processes cannot join a nullifier group before the fork
+	qt.Assert(t, testCreateProcessWithErr(t, accounts[0], app, process),
+		qt.ErrorMatches, ".*fork not active: nullifierGroups")
+	// but the processes without one are not affected
+	process.Mode = &models.ProcessMode{Interruptible: true}
+	qt.Assert(t, testCreateProcessWithErr(t, accounts[0], app, process), qt.IsNil)
+
+	app.AdvanceTestBlocksUntilHeight(2)
+	process.Mode = mode
+	pid := testCreateProcess(t, accounts[0], app, process)
+	stored, err := app.State.Process(pid, false)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, vochaintx.ProcessModeNullifierGroup(stored.Mode), qt.DeepEquals, []byte("group"))
+}
+
+func TestNewProcessVoteDepositFork(t *testing.T) {
+	app := TestBaseApplicationWithForks(t, map[string]uint32{vstate.ForkVoteDeposit: 2})
+	accounts := createTestAccounts(t, app, 10)
+
+	censusURI := ipfsUrlTest
+	mode := &models.ProcessMode{Interruptible: true}
+	vochaintx.SetProcessModeVoteDeposit(mode, 10)
+	process := &models.Process{
+		StartBlock:    1,
+		EnvelopeType:  &models.EnvelopeType{},
+		Mode:          mode,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 16, MaxValue: 16},
+		Status:        models.ProcessStatus_READY,
+		EntityId:      accounts[0].Address().Bytes(),
+		CensusRoot:    util.RandomBytes(32),
+		CensusURI:     &censusURI,
+		CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+		Duration:      60,
+		MaxCensusSize: 5,
+	}
+
+	// the processes cannot require a vote deposit before the fork
+	qt.Assert(t, testCreateProcessWithErr(t, accounts[0], app, process),
+		qt.ErrorMatches, ".*fork not active: voteDeposit")
+
+	app.AdvanceTestBlocksUntilHeight(2)
+	pid := testCreateProcess(t, accounts[0], app, process)
+	stored, err := app.State.Process(pid, false)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, vochaintx.ProcessModeVoteDeposit(stored.Mode), qt.Equals, uint64(10))
+}
+
 func TestSetProcessCensusSize(t *testing.T) {
 	app, accounts := createTestBaseApplicationAndAccounts(t, 10)
 
@@ -709,3 +828,72 @@ func TestSetProcessDuration(t *testing.T) {
 	// check that newBalance is at least 30 tokens less than oldBalance
 	qt.Assert(t, oldBalance-newBalance >= 30, qt.IsTrue)
 }
+
+func TestProcessAutomaticEnd(t *testing.T) {
+	// before the process end fork, the processes end at the end time of their
+	// creation, even if they opt out of the automatic end
+	testProcessAutomaticEnd(t, false)
+	testProcessAutomaticEnd(t, true)
+}
+
+func testProcessAutomaticEnd(t *testing.T, forkActive bool) {
+	var app *BaseApplication
+	if forkActive {
+		app = TestBaseApplication(t)
+	} else {
+		app = TestBaseApplicationWithForks(t, nil)
+	}
+	accounts := createTestAccounts(t, app, 10)
+
+	newProcess := func(manualEnd bool) []byte {
+		censusURI := ipfsUrlTest
+		process := &models.Process{
+			EnvelopeType:  &models.EnvelopeType{EncryptedVotes: false},
+			Mode:          &models.ProcessMode{AutoStart: true, Interruptible: true},
+			VoteOptions:   &models.ProcessVoteOptions{MaxCount: 16, MaxValue: 16},
+			Status:        models.ProcessStatus_READY,
+			EntityId:      accounts[0].Address().Bytes(),
+			CensusRoot:    util.RandomBytes(32),
+			CensusURI:     &censusURI,
+			CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+			Duration:      5,
+			MaxCensusSize: 2,
+		}
+		vochaintx.SetProcessModeManualEnd(process.Mode, manualEnd)
+		pid := testCreateProcess(t, accounts[0], app, process)
+		qt.Assert(t, pid, qt.IsNotNil)
+		return pid
+	}
+	status := func(pid []byte) models.ProcessStatus {
+		proc, err := app.State.Process(pid, true)
+		qt.Assert(t, err, qt.IsNil)
+		return proc.Status
+	}
+
+	autoPid := newProcess(false)
+	manualPid := newProcess(true)
+	extendedPid := newProcess(false)
+	app.AdvanceTestBlock()
+
+	// extend the duration of the third process before the end of the others
+	qt.Assert(t, testSetProcessDuration(t, extendedPid, accounts[0], app, 20), qt.IsNil)
+	app.AdvanceTestBlock()
+
+	for i := 0; i < 8; i++ {
+		app.AdvanceTestBlock()
+	}
+	qt.Assert(t, status(autoPid), qt.Equals, models.ProcessStatus_RESULTS)
+	if !forkActive {
+		qt.Assert(t, status(manualPid), qt.Equals, models.ProcessStatus_RESULTS)
+		qt.Assert(t, status(extendedPid), qt.Equals, models.ProcessStatus_RESULTS)
+		return
+	}
+	qt.Assert(t, status(manualPid), qt.Equals, models.ProcessStatus_READY)
+	qt.Assert(t, status(extendedPid), qt.Equals, models.ProcessStatus_READY)
+
+	for i := 0; i < 16; i++ {
+		app.AdvanceTestBlock()
+	}
+	qt.Assert(t, status(manualPid), qt.Equals, models.ProcessStatus_READY)
+	qt.Assert(t, status(extendedPid), qt.Equals, models.ProcessStatus_RESULTS)
+}
@@ -3,6 +3,7 @@ package vochain
 import (
 	"context"
 	"testing"
+	"time"
 
 	cometabcitypes "github.com/cometbft/cometbft/abci/types"
 	"github.com/frankban/quicktest"
@@ -106,3 +107,237 @@ func TestTransactionsSorted(t *testing.T) {
 	}
 	qt.Assert(len(txs), quicktest.Equals, len(resp.Txs))
 }
+
+// To test that the scheduled transactions are only executed within their validity window
+func TestScheduledTransactions(t *testing.T) {
+	qt := quicktest.New(t)
+	app := TestBaseApplication(t)
+	keys := ethereum.NewSignKeysBatch(2)
+	err := app.State.SetAccount(keys[0].Address(), &vstate.Account{
+		Account: models.Account{Balance: 500},
+	})
+	qt.Assert(err, quicktest.IsNil)
+	err = app.State.SetAccount(keys[1].Address(), &vstate.Account{})
+	qt.Assert(err, quicktest.IsNil)
+	_, err = app.State.PrepareCommit()
+	qt.Assert(err, quicktest.IsNil)
+	_, err = app.CommitState()
+	qt.Assert(err, quicktest.IsNil)
+
+	sendTokensTx := func(from, to *ethereum.SignKeys, value uint64, validFrom, validUntil uint32) []byte {
+		txBytes, err := proto.Marshal(&models.Tx{
+			Payload: &models.Tx_SendTokens{SendTokens: &models.SendTokensTx{
+				Txtype: models.TxType_SEND_TOKENS,
+				Nonce:  0,
+				From:   from.Address().Bytes(),
+				To:     to.Address().Bytes(),
+				Value:  value,
+			}},
+		})
+		qt.Assert(err, quicktest.IsNil)
+		txBytes, err = vochaintx.WithValidHeights(txBytes, validFrom, validUntil)
+		qt.Assert(err, quicktest.IsNil)
+		signature, err := from.SignVocdoniTx(txBytes, app.chainID)
+		qt.Assert(err, quicktest.IsNil)
+		stx, err := proto.Marshal(&models.SignedTx{Tx: txBytes, Signature: signature})
+		qt.Assert(err, quicktest.IsNil)
+		return stx
+	}
+	scheduledTx := func(validFrom, validUntil uint32) []byte {
+		return sendTokensTx(keys[0], keys[1], 1, validFrom, validUntil)
+	}
+	stx := scheduledTx(10, 20)
+
+	vtx := new(vochaintx.Tx)
+	qt.Assert(vtx.Unmarshal(stx, app.chainID), quicktest.IsNil)
+	qt.Assert(vtx.ValidFromHeight, quicktest.Equals, uint32(10))
+	qt.Assert(vtx.ValidUntilHeight, quicktest.Equals, uint32(20))
+
+	// the transaction is accepted in the mempool before its window starts
+	checkResp, err := app.CheckTx(context.Background(), &cometabcitypes.CheckTxRequest{
+		Tx: stx, Type: cometabcitypes.CHECK_TX_TYPE_CHECK,
+	})
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(checkResp.Code, quicktest.Equals, uint32(0))
+
+	// but it is not included in a block until then
+	resp, err := app.PrepareProposal(context.Background(), &cometabcitypes.PrepareProposalRequest{
+		Txs: [][]byte{stx}, Height: 5,
+	})
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(resp.Txs, quicktest.HasLen, 0)
+	execResp, err := app.ExecuteBlock([][]byte{stx}, 5, time.Now())
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(execResp.InvalidTransactions, quicktest.HasLen, 1)
+
+	resp, err = app.PrepareProposal(context.Background(), &cometabcitypes.PrepareProposalRequest{
+		Txs: [][]byte{stx}, Height: 10,
+	})
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(resp.Txs, quicktest.HasLen, 1)
+
+	// once expired, it is rejected
+	app.State.SetHeight(21)
+	checkResp, err = app.CheckTx(context.Background(), &cometabcitypes.CheckTxRequest{
+		Tx: stx, Type: cometabcitypes.CHECK_TX_TYPE_CHECK,
+	})
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(checkResp.Code, quicktest.Equals, uint32(1))
+	execResp, err = app.ExecuteBlock([][]byte{stx}, 21, time.Now())
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(execResp.InvalidTransactions, quicktest.HasLen, 1)
+
+	// transactions cannot be scheduled too far ahead
+	checkResp, err = app.CheckTx(context.Background(), &cometabcitypes.CheckTxRequest{
+		Tx: scheduledTx(21+maxTxScheduleBlocks+1, 0), Type: cometabcitypes.CHECK_TX_TYPE_CHECK,
+	})
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(checkResp.Code, quicktest.Equals, uint32(1))
+
+	// they are checked as if they were executed now, so the sender without
+	// balance cannot fill the mempool with them
+	checkResp, err = app.CheckTx(context.Background(), &cometabcitypes.CheckTxRequest{
+		Tx: sendTokensTx(keys[1], keys[0], 1, 30, 0), Type: cometabcitypes.CHECK_TX_TYPE_CHECK,
+	})
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(checkResp.Code, quicktest.Equals, uint32(1))
+	qt.Assert(string(checkResp.Data), quicktest.Matches, ".*not enough balance.*")
+
+	// and each sender can only have a few of them waiting
+	for i := 0; i < maxScheduledTxsPerSender; i++ {
+		checkResp, err = app.CheckTx(context.Background(), &cometabcitypes.CheckTxRequest{
+			Tx: sendTokensTx(keys[0], keys[1], uint64(i+1), 30, 0), Type: cometabcitypes.CHECK_TX_TYPE_CHECK,
+		})
+		qt.Assert(err, quicktest.IsNil)
+		qt.Assert(checkResp.Code, quicktest.Equals, uint32(0))
+	}
+	checkResp, err = app.CheckTx(context.Background(), &cometabcitypes.CheckTxRequest{
+		Tx: sendTokensTx(keys[0], keys[1], 100, 30, 0), Type: cometabcitypes.CHECK_TX_TYPE_CHECK,
+	})
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(string(checkResp.Data), quicktest.Matches, ".*too many scheduled transactions")
+	// a transaction already waiting can be checked again
+	checkResp, err = app.CheckTx(context.Background(), &cometabcitypes.CheckTxRequest{
+		Tx: sendTokensTx(keys[0], keys[1], 1, 30, 0), Type: cometabcitypes.CHECK_TX_TYPE_RECHECK,
+	})
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(checkResp.Code, quicktest.Equals, uint32(0))
+
+	// and the window cannot be empty
+	_, err = vochaintx.WithValidHeights([]byte{}, 20, 10)
+	qt.Assert(err, quicktest.IsNotNil)
+}
+
+// To test that the validity window of the transactions is ignored before its fork
+func TestScheduledTransactionsFork(t *testing.T) {
+	qt := quicktest.New(t)
+	app := TestBaseApplicationWithForks(t, map[string]uint32{vstate.ForkTxValidity: 100})
+	keys := ethereum.NewSignKeysBatch(2)
+	err := app.State.SetAccount(keys[0].Address(), &vstate.Account{
+		Account: models.Account{Balance: 500},
+	})
+	qt.Assert(err, quicktest.IsNil)
+	err = app.State.SetAccount(keys[1].Address(), &vstate.Account{})
+	qt.Assert(err, quicktest.IsNil)
+	_, err = app.State.PrepareCommit()
+	qt.Assert(err, quicktest.IsNil)
+	_, err = app.CommitState()
+	qt.Assert(err, quicktest.IsNil)
+
+	txBytes, err := proto.Marshal(&models.Tx{
+		Payload: &models.Tx_SendTokens{SendTokens: &models.SendTokensTx{
+			Txtype: models.TxType_SEND_TOKENS,
+			From:   keys[0].Address().Bytes(),
+			To:     keys[1].Address().Bytes(),
+			Value:  1,
+		}},
+	})
+	qt.Assert(err, quicktest.IsNil)
+	txBytes, err = vochaintx.WithValidHeights(txBytes, 10, 20)
+	qt.Assert(err, quicktest.IsNil)
+	signature, err := keys[0].SignVocdoniTx(txBytes, app.chainID)
+	qt.Assert(err, quicktest.IsNil)
+	stx, err := proto.Marshal(&models.SignedTx{Tx: txBytes, Signature: signature})
+	qt.Assert(err, quicktest.IsNil)
+
+	// the nodes without the validity window execute the transaction right away
+	resp, err := app.PrepareProposal(context.Background(), &cometabcitypes.PrepareProposalRequest{
+		Txs: [][]byte{stx}, Height: 5,
+	})
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(resp.Txs, quicktest.HasLen, 1)
+	execResp, err := app.ExecuteBlock([][]byte{stx}, 5, time.Now())
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(execResp.InvalidTransactions, quicktest.HasLen, 0)
+}
+
+// To test that PrepareProposal only includes transactions up to the block gas limit
+func TestBlockGasLimit(t *testing.T) {
+	qt := quicktest.New(t)
+	app := TestBaseApplication(t)
+	keys := ethereum.NewSignKeysBatch(3)
+	for _, key := range keys {
+		err := app.State.SetAccount(key.Address(), &vstate.Account{
+			Account: models.Account{Balance: 500},
+		})
+		qt.Assert(err, quicktest.IsNil)
+	}
+	err := app.State.SetGasSchedule(&vstate.GasSchedule{
+		BlockGasLimit: 5,
+		DefaultTxGas:  1,
+		TxGas:         map[string]uint64{models.TxType_SEND_TOKENS.String(): 2},
+	})
+	qt.Assert(err, quicktest.IsNil)
+	_, err = app.State.PrepareCommit()
+	qt.Assert(err, quicktest.IsNil)
+	_, err = app.CommitState()
+	qt.Assert(err, quicktest.IsNil)
+
+	// two transactions of each account
+	txs := [][]byte{}
+	for i, key := range keys {
+		for nonce := uint32(0); nonce < 2; nonce++ {
+			txBytes, err := proto.Marshal(&models.Tx{
+				Payload: &models.Tx_SendTokens{SendTokens: &models.SendTokensTx{
+					Txtype: models.TxType_SEND_TOKENS,
+					Nonce:  nonce,
+					From:   key.Address().Bytes(),
+					To:     keys[(i+1)%len(keys)].Address().Bytes(),
+					Value:  1,
+				}},
+			})
+			qt.Assert(err, quicktest.IsNil)
+			signature, err := key.SignVocdoniTx(txBytes, app.chainID)
+			qt.Assert(err, quicktest.IsNil)
+			stx, err := proto.Marshal(&models.SignedTx{Tx: txBytes, Signature: signature})
+			qt.Assert(err, quicktest.IsNil)
+			txs = append(txs, stx)
+		}
+	}
+
+	checkResp, err := app.CheckTx(context.Background(), &cometabcitypes.CheckTxRequest{
+		Tx: txs[0], Type: cometabcitypes.CHECK_TX_TYPE_CHECK,
+	})
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(checkResp.Code, quicktest.Equals, uint32(0))
+	qt.Assert(checkResp.GasWanted, quicktest.Equals, int64(2))
+
+	// only the two transactions of the first sender fit, and the rest wait
+	// for the next blocks, without counting as failed attempts
+	resp, err := app.PrepareProposal(context.Background(), &cometabcitypes.PrepareProposalRequest{
+		Txs: txs, Height: 1,
+	})
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(resp.Txs, quicktest.HasLen, 2)
+	senders := make(map[string]bool)
+	for _, tx := range resp.Txs {
+		vtx := new(vochaintx.Tx)
+		qt.Assert(vtx.Unmarshal(tx, app.chainID), quicktest.IsNil)
+		senders[string(vtx.Tx.GetSendTokens().From)] = true
+	}
+	qt.Assert(senders, quicktest.HasLen, 1)
+	gasSchedule, err := app.State.GasSchedule(true)
+	qt.Assert(err, quicktest.IsNil)
+	qt.Assert(app.blockGas(gasSchedule, resp.Txs), quicktest.Equals, uint64(4))
+	qt.Assert(app.blockGas(gasSchedule, txs), quicktest.Equals, uint64(12))
+}
@@ -0,0 +1,86 @@
+package vochain
+
+import (
+	"sync"
+
+	vstate "go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// receiptRecorder is an event listener of the State which records the receipt
+// of the transaction being delivered, from the events notified by the State
+// while it is executed.
+type receiptRecorder struct {
+	mu        sync.Mutex
+	recording bool
+	receipt   vochaintx.Receipt
+}
+
+// start starts recording the receipt of a transaction.
+func (r *receiptRecorder) start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recording = true
+	r.receipt = vochaintx.Receipt{}
+}
+
+// stop stops recording and returns the receipt of the transaction.
+func (r *receiptRecorder) stop() *vochaintx.Receipt {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recording = false
+	receipt := r.receipt
+	r.receipt = vochaintx.Receipt{}
+	return &receipt
+}
+
+// emit adds the event to the receipt, if recording.
+func (r *receiptRecorder) emit(event vochaintx.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.recording {
+		r.receipt.Events = append(r.receipt.Events, event)
+	}
+}
+
+func (r *receiptRecorder) OnVote(vote *vstate.Vote, _ int32) {
+	weight := "1"
+	if vote.Weight != nil {
+		weight = vote.Weight.String()
+	}
+	r.emit(vochaintx.NewVoteAcceptedEvent(vote.ProcessID, vote.Nullifier, weight))
+}
+
+func (r *receiptRecorder) OnProcess(process *models.Process, _ int32) {
+	r.emit(vochaintx.NewProcessCreatedEvent(process.ProcessId, process.EntityId))
+}
+
+func (r *receiptRecorder) OnTransferTokens(tx *vochaintx.TokenTransfer) {
+	r.emit(vochaintx.NewTokensTransferredEvent(tx.FromAddress, tx.ToAddress, tx.Amount))
+}
+
+func (r *receiptRecorder) OnSpendTokens(_ []byte, _ models.TxType, cost uint64, _ string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.recording {
+		r.receipt.Cost += cost
+	}
+}
+
+func (*receiptRecorder) OnNewTx(_ *vochaintx.Tx, _ uint32, _ int32)                      {}
+func (*receiptRecorder) OnTxReceipt(_ *vochaintx.Tx, _ *vochaintx.Receipt, _ int32)      {}
+func (*receiptRecorder) OnProcessStatusChange(_ []byte, _ models.ProcessStatus, _ int32) {}
+func (*receiptRecorder) OnProcessDurationChange(_ []byte, _ uint32, _ int32)             {}
+func (*receiptRecorder) OnCancel(_ []byte, _ int32)                                      {}
+func (*receiptRecorder) OnProcessKeys(_ []byte, _ string, _ int32)                       {}
+func (*receiptRecorder) OnRevealKeys(_ []byte, _ string, _ int32)                        {}
+func (*receiptRecorder) OnProcessResults(_ []byte, _ *models.ProcessResult, _ int32)     {}
+func (*receiptRecorder) OnProcessesStart(_ [][]byte)                                     {}
+func (*receiptRecorder) OnSetAccount(_ []byte, _ *vstate.Account)                        {}
+func (*receiptRecorder) OnSetAccountKV(_ []byte, _ string, _ []byte)                     {}
+func (*receiptRecorder) OnVoteDelegation(_, _ []byte, _ *vstate.VoteDelegation)          {}
+func (*receiptRecorder) OnAccountRecovery(_ []byte, _ *vstate.AccountRecoveryEvent)      {}
+func (*receiptRecorder) OnCensusUpdate(_, _ []byte, _ string, _ uint64)                  {}
+func (*receiptRecorder) Commit(_ uint32) error                                           { return nil }
+func (*receiptRecorder) Rollback()                                                       {}
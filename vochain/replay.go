@@ -0,0 +1,255 @@
+package vochain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	dbm "github.com/cometbft/cometbft-db"
+	cometabcitypes "github.com/cometbft/cometbft/abci/types"
+	cometconfig "github.com/cometbft/cometbft/config"
+	cometstate "github.com/cometbft/cometbft/state"
+	cometstore "github.com/cometbft/cometbft/store"
+	"go.vocdoni.io/dvote/config"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/vochain/genesis"
+)
+
+// ReplayBlock is a block of the chain re-executed by ReplayChain, with the
+// outcome of its original execution.
+type ReplayBlock struct {
+	Height          uint32
+	Time            time.Time
+	Hash            []byte
+	ProposerAddress []byte
+	Txs             [][]byte
+	LastCommit      cometabcitypes.CommitInfo
+	// AppHash is the app hash of the chain after the block, nil if unknown.
+	AppHash []byte
+	// TxResults are the results of the transactions of the block in the
+	// chain, nil if unknown.
+	TxResults []*cometabcitypes.ExecTxResult
+}
+
+// ReplaySource provides the genesis and the blocks of the chain replayed by
+// ReplayChain, such as the stores of a node (see StoredChain).
+type ReplaySource interface {
+	Genesis() *genesis.Doc
+	Block(height uint32) (*ReplayBlock, error)
+}
+
+// ReplayDivergence is a block whose re-execution does not match the chain.
+type ReplayDivergence struct {
+	Height          uint32
+	AppHash         []byte
+	ExpectedAppHash []byte
+	// TxIndex is the index of the first transaction whose result differs from
+	// the one of the chain, or -1 if none does or the results of the chain are
+	// unknown. Then the divergence comes from any of the transactions or from
+	// the logic run at the end of the block.
+	TxIndex        int
+	Tx             []byte
+	Result         *cometabcitypes.ExecTxResult
+	ExpectedResult *cometabcitypes.ExecTxResult
+}
+
+// ReplayChain re-executes the blocks of the chain up to toHeight on app, which
+// must have a fresh state, as FinalizeBlock and Commit do. Since the state is
+// built from the genesis, all the blocks are executed, but the app hashes and
+// the transaction results are only compared with the ones of the chain from
+// fromHeight on. It returns the first block which does not match, or nil if
+// they all do.
+func (app *BaseApplication) ReplayChain(ctx context.Context, chain ReplaySource,
+	fromHeight, toHeight uint32,
+) (*ReplayDivergence, error) {
+	lastHeight, err := app.State.LastHeight()
+	if err != nil {
+		return nil, err
+	}
+	if lastHeight > 0 {
+		return nil, fmt.Errorf("the state is not fresh, it is at height %d", lastHeight)
+	}
+	doc := chain.Genesis()
+	app.SetChainID(doc.ChainID)
+	app.genesisDoc = doc
+	if _, err := app.InitChain(ctx, &cometabcitypes.InitChainRequest{
+		ChainId:       doc.ChainID,
+		AppStateBytes: doc.AppState,
+		InitialHeight: doc.InitialHeight,
+	}); err != nil {
+		return nil, fmt.Errorf("cannot init chain: %w", err)
+	}
+
+	startTime := time.Now()
+	for height := uint32(max(doc.InitialHeight, 1)); height <= toHeight; height++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		b, err := chain.Block(height)
+		if err != nil {
+			return nil, err
+		}
+		div, err := app.replayBlock(ctx, b, height >= fromHeight)
+		if err != nil || div != nil {
+			return div, err
+		}
+		if height%1000 == 0 {
+			log.Infow("replaying blocks", "height", height, "elapsed", time.Since(startTime))
+		}
+	}
+	return nil, nil
+}
+
+// replayBlock executes the block and, if compare is true, compares the app
+// hash and the transaction results with the ones of the chain.
+func (app *BaseApplication) replayBlock(ctx context.Context, b *ReplayBlock, compare bool) (*ReplayDivergence, error) {
+	resp, err := app.FinalizeBlock(ctx, &cometabcitypes.FinalizeBlockRequest{
+		Txs:               b.Txs,
+		DecidedLastCommit: b.LastCommit,
+		Hash:              b.Hash,
+		Height:            int64(b.Height),
+		Time:              b.Time,
+		ProposerAddress:   b.ProposerAddress,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot finalize block %d: %w", b.Height, err)
+	}
+	if _, err := app.Commit(ctx, &cometabcitypes.CommitRequest{}); err != nil {
+		return nil, fmt.Errorf("cannot commit block %d: %w", b.Height, err)
+	}
+	if !compare {
+		return nil, nil
+	}
+
+	div := &ReplayDivergence{
+		Height:          b.Height,
+		AppHash:         resp.AppHash,
+		ExpectedAppHash: b.AppHash,
+		TxIndex:         -1,
+	}
+	if b.TxResults != nil {
+		for i, result := range resp.TxResults {
+			if i < len(b.TxResults) && sameTxResult(result, b.TxResults[i]) {
+				continue
+			}
+			div.TxIndex = i
+			div.Tx = b.Txs[i]
+			div.Result = result
+			if i < len(b.TxResults) {
+				div.ExpectedResult = b.TxResults[i]
+			}
+			return div, nil
+		}
+	}
+	if b.AppHash != nil && !bytes.Equal(resp.AppHash, b.AppHash) {
+		return div, nil
+	}
+	return nil, nil
+}
+
+// sameTxResult returns whether the results match in the fields which are part
+// of the consensus, see comettypes.NewResults.
+func sameTxResult(a, b *cometabcitypes.ExecTxResult) bool {
+	return a.Code == b.Code && bytes.Equal(a.Data, b.Data) &&
+		a.GasWanted == b.GasWanted && a.GasUsed == b.GasUsed
+}
+
+// StoredChain is a ReplaySource which reads the blocks from the CometBFT stores
+// of a stopped node.
+type StoredChain struct {
+	genesis *genesis.Doc
+	blockDB dbm.DB
+	stateDB dbm.DB
+	blocks  *cometstore.BlockStore
+	states  cometstate.Store
+}
+
+// OpenStoredChain opens the CometBFT stores in the vochain data directory of a
+// stopped node, with the genesis in genesisFile, or the one in the data
+// directory if empty.
+func OpenStoredChain(dataDir, genesisFile string) (*StoredChain, error) {
+	if genesisFile == "" {
+		genesisFile = filepath.Join(dataDir, config.DefaultGenesisPath)
+	}
+	doc, err := genesis.LoadFromFile(genesisFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load genesis: %w", err)
+	}
+	dbDir := filepath.Join(dataDir, config.DefaultCometBFTPath, cometconfig.DefaultDataDir)
+	blockDB, err := dbm.NewDB("blockstore", tmdbBackend, dbDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open block store: %w", err)
+	}
+	stateDB, err := dbm.NewDB("state", tmdbBackend, dbDir)
+	if err != nil {
+		blockDB.Close()
+		return nil, fmt.Errorf("cannot open state store: %w", err)
+	}
+	c := &StoredChain{
+		genesis: doc,
+		blockDB: blockDB,
+		stateDB: stateDB,
+		blocks:  cometstore.NewBlockStore(blockDB),
+		states:  cometstate.NewStore(stateDB, cometstate.StoreOptions{}),
+	}
+	if base := c.blocks.Base(); base > max(doc.InitialHeight, 1) {
+		c.Close()
+		return nil, fmt.Errorf("the block store starts at height %d, the chain cannot be replayed from its genesis", base)
+	}
+	return c, nil
+}
+
+// Genesis implements ReplaySource.
+func (c *StoredChain) Genesis() *genesis.Doc {
+	return c.genesis
+}
+
+// Height returns the height of the last block in the store.
+func (c *StoredChain) Height() uint32 {
+	return uint32(c.blocks.Height())
+}
+
+// Block implements ReplaySource. The app hash after the block is taken from
+// its stored results, or from the header of the next block if the node does
+// not keep them.
+func (c *StoredChain) Block(height uint32) (*ReplayBlock, error) {
+	block, _ := c.blocks.LoadBlock(int64(height))
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	b := &ReplayBlock{
+		Height:          height,
+		Time:            block.Time,
+		Hash:            block.Hash(),
+		ProposerAddress: block.ProposerAddress,
+	}
+	for _, tx := range block.Txs {
+		b.Txs = append(b.Txs, tx)
+	}
+	initialHeight := max(c.genesis.InitialHeight, 1)
+	if block.Height > initialHeight {
+		validators, err := c.states.LoadValidators(block.Height - 1)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the validators of height %d: %w", block.Height-1, err)
+		}
+		b.LastCommit = cometstate.BuildLastCommitInfo(block, validators, initialHeight)
+	}
+	if resp, err := c.states.LoadFinalizeBlockResponse(block.Height); err == nil {
+		b.AppHash = resp.AppHash
+		b.TxResults = resp.TxResults
+	} else if next, _ := c.blocks.LoadBlock(block.Height + 1); next != nil {
+		b.AppHash = next.AppHash
+	}
+	return b, nil
+}
+
+// Close closes the stores.
+func (c *StoredChain) Close() error {
+	err := c.blockDB.Close()
+	if err2 := c.stateDB.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
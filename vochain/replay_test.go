@@ -0,0 +1,99 @@
+package vochain
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	cometabcitypes "github.com/cometbft/cometbft/abci/types"
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/config"
+	"go.vocdoni.io/dvote/db/metadb"
+	"go.vocdoni.io/dvote/vochain/genesis"
+)
+
+type memChain struct {
+	genesis *genesis.Doc
+	blocks  []*ReplayBlock
+}
+
+func (c *memChain) Genesis() *genesis.Doc { return c.genesis }
+
+func (c *memChain) Block(height uint32) (*ReplayBlock, error) {
+	b := *c.blocks[height-1]
+	return &b, nil
+}
+
+func newReplayApp(t *testing.T) *BaseApplication {
+	app, err := NewBaseApplication(&config.VochainCfg{
+		DBType:           metadb.ForTest(),
+		DataDir:          t.TempDir(),
+		ZkCircuitVersion: testZkCircuitVersion(),
+	})
+	qt.Assert(t, err, qt.IsNil)
+	t.Cleanup(func() { app.State.Close() })
+	return app
+}
+
+// To test that the replayed blocks are compared with the results of the chain
+func TestReplayChain(t *testing.T) {
+	c := qt.New(t)
+	ctx := context.Background()
+	doc, err := NewTemplateGenesisFile(t.TempDir(), 1)
+	c.Assert(err, qt.IsNil)
+	chain := &memChain{genesis: doc}
+
+	// the chain is built with the results of an application without them,
+	// whose transactions are rejected since they cannot be decoded
+	app := newReplayApp(t)
+	div, err := app.ReplayChain(ctx, chain, 1, 0)
+	c.Assert(err, qt.IsNil)
+	c.Assert(div, qt.IsNil)
+	for height := uint32(1); height <= 3; height++ {
+		b := &ReplayBlock{
+			Height: height,
+			Time:   time.Unix(int64(height), 0),
+			Txs:    [][]byte{bytes.Repeat([]byte{byte(height)}, 8)},
+		}
+		resp, err := app.FinalizeBlock(ctx, &cometabcitypes.FinalizeBlockRequest{
+			Txs: b.Txs, Height: int64(height), Time: b.Time,
+		})
+		c.Assert(err, qt.IsNil)
+		_, err = app.Commit(ctx, &cometabcitypes.CommitRequest{})
+		c.Assert(err, qt.IsNil)
+		c.Assert(resp.TxResults[0].Code, qt.Equals, uint32(1))
+		b.AppHash, b.TxResults = resp.AppHash, resp.TxResults
+		chain.blocks = append(chain.blocks, b)
+	}
+
+	div, err = newReplayApp(t).ReplayChain(ctx, chain, 1, 3)
+	c.Assert(err, qt.IsNil)
+	c.Assert(div, qt.IsNil)
+
+	// a transaction accepted by the chain
+	accepted := *chain.blocks[1].TxResults[0]
+	accepted.Code = 0
+	chain.blocks[1].TxResults = []*cometabcitypes.ExecTxResult{&accepted}
+	div, err = newReplayApp(t).ReplayChain(ctx, chain, 1, 3)
+	c.Assert(err, qt.IsNil)
+	c.Assert(div, qt.IsNotNil)
+	c.Assert(div.Height, qt.Equals, uint32(2))
+	c.Assert(div.TxIndex, qt.Equals, 0)
+	c.Assert(div.Tx, qt.DeepEquals, chain.blocks[1].Txs[0])
+	c.Assert(div.ExpectedResult.Code, qt.Equals, uint32(0))
+
+	// the blocks before fromHeight are not compared
+	div, err = newReplayApp(t).ReplayChain(ctx, chain, 3, 3)
+	c.Assert(err, qt.IsNil)
+	c.Assert(div, qt.IsNil)
+
+	// a different app hash, without the results of the chain
+	chain.blocks[2].AppHash = bytes.Repeat([]byte{1}, 32)
+	chain.blocks[2].TxResults = nil
+	div, err = newReplayApp(t).ReplayChain(ctx, chain, 3, 3)
+	c.Assert(err, qt.IsNil)
+	c.Assert(div, qt.IsNotNil)
+	c.Assert(div.Height, qt.Equals, uint32(3))
+	c.Assert(div.TxIndex, qt.Equals, -1)
+}
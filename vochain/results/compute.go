@@ -15,6 +15,7 @@ import (
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/vochain/state"
 	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
 )
 
 // ComputeResults walks through the envelopes of a process and computes the results.
@@ -36,29 +37,19 @@ func ComputeResults(electionID []byte, st *state.State) (*Results, error) {
 		return nil, fmt.Errorf("maxCount overflow %d", p.VoteOptions.MaxCount)
 	}
 	results := &Results{
-		Votes:        NewEmptyVotes(p.VoteOptions),
 		ProcessID:    electionID,
 		Weight:       new(types.BigInt).SetUint64(0),
 		VoteOpts:     p.VoteOptions,
 		EnvelopeType: p.EnvelopeType,
 		BlockHeight:  st.CurrentHeight(),
 	}
+	tally := results.TallyStrategy()
+	results.Votes = tally.NewVotes(p.VoteOptions)
 
 	lock := sync.Mutex{}
 	startTime := time.Now()
 	err = st.IterateVotes(electionID, true, func(vote *models.StateDBVote) bool {
-		keys := []string{}
-		if p.EnvelopeType.EncryptedVotes {
-			for _, k := range vote.EncryptionKeyIndexes {
-				if k < types.KeyKeeperMaxKeyIndex && k < uint32(len(p.EncryptionPrivateKeys)) {
-					keys = append(keys, p.EncryptionPrivateKeys[k])
-				} else {
-					log.Warn("wrong vote: key index overflow or too many fields")
-					return false
-				}
-			}
-		}
-		vp, err := unmarshalVote(vote.VotePackage, keys)
+		vp, err := decodeVote(p, vote)
 		if err != nil {
 			log.Debugf("vote invalid: %v", err)
 			return false
@@ -79,12 +70,55 @@ func ComputeResults(electionID []byte, st *state.State) (*Results, error) {
 
 	log.Infow("computed results",
 		"process", fmt.Sprintf("%x", electionID),
+		"strategy", tally.Name(),
 		"results", results.String(),
 		"elapsed", time.Since(startTime).String(),
 	)
 	return results, nil
 }
 
+// CountedVote returns whether the vote is counted in the results of the
+// process, which requires its encryption private keys if the votes are
+// encrypted. The votes which cannot be decrypted or decoded, or whose values
+// are not valid for the vote options of the process, are not counted.
+func CountedVote(p *models.Process, vote *models.StateDBVote) bool {
+	vp, err := decodeVote(p, vote)
+	if err != nil {
+		return false
+	}
+	maxCount := p.VoteOptions.MaxCount
+	if maxCount == 0 {
+		maxCount = MaxQuestions
+	}
+	if maxCount > MaxQuestions {
+		return false
+	}
+	voteOpts := proto.Clone(p.VoteOptions).(*models.ProcessVoteOptions)
+	voteOpts.MaxCount = maxCount
+	r := &Results{
+		Votes:        NewEmptyVotes(voteOpts),
+		Weight:       new(types.BigInt).SetUint64(0),
+		VoteOpts:     voteOpts,
+		EnvelopeType: p.EnvelopeType,
+	}
+	return r.AddVote(vp.Votes, new(big.Int).SetBytes(vote.Weight), nil) == nil
+}
+
+// decodeVote returns the vote package of the vote, decrypting it with the
+// encryption private keys of the process if the votes are encrypted.
+func decodeVote(p *models.Process, vote *models.StateDBVote) (*state.VotePackage, error) {
+	keys := []string{}
+	if p.EnvelopeType.EncryptedVotes {
+		for _, k := range vote.EncryptionKeyIndexes {
+			if k >= types.KeyKeeperMaxKeyIndex || k >= uint32(len(p.EncryptionPrivateKeys)) {
+				return nil, fmt.Errorf("wrong vote: key index overflow or too many fields")
+			}
+			keys = append(keys, p.EncryptionPrivateKeys[k])
+		}
+	}
+	return unmarshalVote(vote.VotePackage, keys)
+}
+
 // unmarshalVote decodes the base64 payload to a VotePackage struct type.
 // If the vochain.VotePackage is encrypted the list of keys to decrypt it should be provided.
 // The order of the Keys must be as it was encrypted.
@@ -26,6 +26,12 @@ type Results struct {
 	BlockHeight  uint32                     `json:"blockHeight"`
 }
 
+// TallyStrategy returns the tally strategy of the results, the one selected by
+// the ballot protocol of the process, see StrategyFor.
+func (r *Results) TallyStrategy() Strategy {
+	return StrategyFor(r.EnvelopeType, r.VoteOpts)
+}
+
 // String formats the results in a human-readable string
 func (r *Results) String() string {
 	results := bytes.Buffer{}
@@ -170,6 +176,8 @@ func (r *Results) AddVote(voteValues []int, weight *big.Int, mutex *sync.Mutex)
 		}
 	}
 
+	strategy := r.TallyStrategy()
+
 	// If Mutex provided, Lock it
 	if mutex != nil {
 		mutex.Lock()
@@ -181,44 +189,16 @@ func (r *Results) AddVote(voteValues []int, weight *big.Int, mutex *sync.Mutex)
 		weight = new(big.Int).SetUint64(1)
 	}
 
-	// Add the Election weight (tells how much voting power have already been processed)
-	r.Weight.Add(r.Weight, (*types.BigInt)(weight))
 	if len(r.Votes) == 0 {
-		r.Votes = NewEmptyVotes(r.VoteOpts)
+		r.Votes = strategy.NewVotes(r.VoteOpts)
 	}
-
-	// If MaxValue is zero, consider discrete value couting. So for each questoin, the value
-	// is aggregated. The weight is multiplied for the value if costFromWeight=False.
-	// This is a special case for Quadratic voting where maxValue should be 0 (no limit).
-	// The results are aggregated, so we use only the first column of the results matrix.
-	if r.VoteOpts.MaxValue == 0 {
-		// If CostFromWeight, the Weight is used for computing the cost and not as a value multiplier
-		if r.EnvelopeType.CostFromWeight {
-			weight = new(big.Int).SetUint64(1)
-		}
-		// Example if maxValue=0 and CostFromWeight=false
-		// Vote1: [1, 2, 3] w=10
-		// Vote2: [0, 3, 1] w=5
-		//  [ [1*10+0*5], [2*10+3*5], [3*10+1*5] ]
-		// Results: [ [10], [35], [35] ]
-		//
-		// If CostFromWeight=true then we assume the weight is already represented on the vote value.
-		// This is why we set weight=1.
-		for q, value := range voteValues {
-			r.Votes[q][0].Add(
-				r.Votes[q][0],
-				new(types.BigInt).Mul(
-					new(types.BigInt).SetUint64(uint64(value)),
-					(*types.BigInt)(weight)),
-			)
-		}
-	} else {
-		// For the other cases, we use the results matrix index weighted
-		// as described in the Ballot Protocol.
-		for q, opt := range voteValues {
-			r.Votes[q][opt].Add(r.Votes[q][opt], (*types.BigInt)(weight))
-		}
+	// The values are accumulated as the strategy selected by the Ballot
+	// Protocol describes, see StrategyFor.
+	if err := strategy.AddVote(r.Votes, voteValues, weight); err != nil {
+		return fmt.Errorf("addVote: %w", err)
 	}
+	// Add the Election weight (tells how much voting power have already been processed)
+	r.Weight.Add(r.Weight, (*types.BigInt)(weight))
 	return nil
 }
 
@@ -240,31 +220,18 @@ func NewEmptyVotes(voteOpts *models.ProcessVoteOptions) [][]*types.BigInt {
 	return results
 }
 
-// ResultsToProto takes the Results type and builds the protobuf type ProcessResult.
+// ResultsToProto takes the Results type and builds the protobuf type
+// ProcessResult, as serialized by its tally strategy.
 func ResultsToProto(results *Results) *models.ProcessResult {
-	// build the protobuf type for Results
-	qr := []*models.QuestionResult{}
-	for i := range results.Votes {
-		qr = append(qr, &models.QuestionResult{})
-		for j := range results.Votes[i] {
-			qr[i].Question = append(qr[i].Question, results.Votes[i][j].Bytes())
-		}
-	}
-	return &models.ProcessResult{
-		Votes: qr,
-	}
+	return results.TallyStrategy().Encode(results.Votes)
 }
 
-// ProtoToResults takes the protobuf type ProcessResult and builds the Results type.
+// ProtoToResults takes the protobuf type ProcessResult and builds the Results
+// type. The votes are decoded as a matrix, the encoding of the built-in tally
+// strategies; use the Decode method of the strategy for any other.
 func ProtoToResults(pr *models.ProcessResult) *Results {
-	r := &Results{
-		Votes: [][]*types.BigInt{},
-	}
-	for i := range pr.Votes {
-		r.Votes = append(r.Votes, []*types.BigInt{})
-		for j := range pr.Votes[i].Question {
-			r.Votes[i] = append(r.Votes[i], new(types.BigInt).SetBytes(pr.Votes[i].Question[j]))
-		}
+	votes, _ := matrixEncoding{}.Decode(pr)
+	return &Results{
+		Votes: votes,
 	}
-	return r
 }
@@ -0,0 +1,192 @@
+// Package resultsverify verifies the results proof bundles of the finished
+// elections, as served by the API. A bundle ties the final results, the
+// encryption private keys and the census root of an election to a block
+// header of the Vochain, by means of the merkle proofs of the election in the
+// state trees. It only depends on the block header and the proofs, so it can
+// be verified without running a node nor trusting the API that served it.
+package resultsverify
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	comettypes "github.com/cometbft/cometbft/types"
+	"go.vocdoni.io/dvote/crypto/nacl"
+	"go.vocdoni.io/dvote/tree/arbo"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// ProcessesTreeKey is the key of the Processes tree root in the state main tree.
+const ProcessesTreeKey = "procs"
+
+// HashFunction is the hash function of the state main and Processes trees.
+var HashFunction = arbo.HashFunctionSha256
+
+// EncryptionKey is a revealed encryption key of an election.
+type EncryptionKey struct {
+	Index      int            `json:"index"`
+	PublicKey  types.HexBytes `json:"publicKey"`
+	PrivateKey types.HexBytes `json:"privateKey"`
+}
+
+// Bundle is the self-contained verification bundle of the results of an
+// election.
+type Bundle struct {
+	ElectionID     types.HexBytes    `json:"electionId"`
+	Results        [][]*types.BigInt `json:"results"`
+	EncryptionKeys []EncryptionKey   `json:"encryptionKeys"`
+	CensusRoot     types.HexBytes    `json:"censusRoot"`
+	// Height is the height of the block whose header commits StateRoot as AppHash.
+	Height int64              `json:"height"`
+	Header *comettypes.Header `json:"header"`
+	// StateRoot is the state main tree root the proofs are generated for.
+	StateRoot types.HexBytes `json:"stateRoot"`
+	// ProcessesRoot is the Processes tree root and ProcessesProof its proof in the main tree.
+	ProcessesRoot  types.HexBytes `json:"processesRoot"`
+	ProcessesProof types.HexBytes `json:"processesProof"`
+	// Process is the election leaf (a marshaled models.StateDBProcess) and
+	// ProcessProof its proof in the Processes tree.
+	Process      types.HexBytes `json:"process"`
+	ProcessProof types.HexBytes `json:"processProof"`
+}
+
+// Verify checks that the bundle is consistent: the header commits the state
+// root, the merkle proofs are valid and the results, keys and census root match
+// the election found in the state. If trustedBlockHash is not nil, the header
+// hash must match it, otherwise the header is trusted as is. The caller should
+// get the hash from a trusted source (such as its own node or a light client).
+// It returns the election as found in the state.
+func (b *Bundle) Verify(trustedBlockHash []byte) (*models.Process, error) {
+	if b.Header == nil {
+		return nil, fmt.Errorf("missing block header")
+	}
+	if b.Header.Height != b.Height {
+		return nil, fmt.Errorf("header height %d does not match bundle height %d", b.Header.Height, b.Height)
+	}
+	if trustedBlockHash != nil && !bytes.Equal(b.Header.Hash(), trustedBlockHash) {
+		return nil, fmt.Errorf("header hash %x does not match the trusted block hash %x", b.Header.Hash(), trustedBlockHash)
+	}
+	if !bytes.Equal(b.Header.AppHash, b.StateRoot) {
+		return nil, fmt.Errorf("header app hash %x does not match state root %x", b.Header.AppHash, b.StateRoot)
+	}
+
+	if err := checkProof([]byte(ProcessesTreeKey), b.ProcessesRoot, b.StateRoot, b.ProcessesProof); err != nil {
+		return nil, fmt.Errorf("invalid processes tree proof: %w", err)
+	}
+	if err := checkProof(b.ElectionID, b.Process, b.ProcessesRoot, b.ProcessProof); err != nil {
+		return nil, fmt.Errorf("invalid election proof: %w", err)
+	}
+
+	var sdbProc models.StateDBProcess
+	if err := proto.Unmarshal(b.Process, &sdbProc); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal election: %w", err)
+	}
+	process := sdbProc.GetProcess()
+	if process == nil {
+		return nil, fmt.Errorf("election is nil")
+	}
+	if !bytes.Equal(process.ProcessId, b.ElectionID) {
+		return nil, fmt.Errorf("election id %x does not match %x", process.ProcessId, b.ElectionID)
+	}
+	if process.Status != models.ProcessStatus_RESULTS {
+		return nil, fmt.Errorf("election status is %s", process.Status)
+	}
+	if !bytes.Equal(process.CensusRoot, b.CensusRoot) {
+		return nil, fmt.Errorf("census root %x does not match %x", process.CensusRoot, b.CensusRoot)
+	}
+	if err := checkResults(process.GetResults().GetVotes(), b.Results); err != nil {
+		return nil, err
+	}
+	if err := checkKeys(process, b.EncryptionKeys); err != nil {
+		return nil, err
+	}
+	return process, nil
+}
+
+// Keys returns the revealed encryption keys of the election, as found in the
+// state, so they can be used to build a Bundle.
+func Keys(process *models.Process) []EncryptionKey {
+	keys := []EncryptionKey{}
+	for i, priv := range process.EncryptionPrivateKeys {
+		if priv == "" || i >= len(process.EncryptionPublicKeys) {
+			continue
+		}
+		keys = append(keys, EncryptionKey{
+			Index:      i,
+			PublicKey:  hexBytes(process.EncryptionPublicKeys[i]),
+			PrivateKey: hexBytes(priv),
+		})
+	}
+	return keys
+}
+
+// checkProof verifies the merkle proof of key and value for the given root.
+func checkProof(key, value, root, proof []byte) error {
+	valid, err := arbo.CheckProof(HashFunction, key, value, root, proof)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return fmt.Errorf("proof does not match root %x", root)
+	}
+	return nil
+}
+
+// checkResults compares the results stored in the state with the bundle ones.
+func checkResults(stored []*models.QuestionResult, results [][]*types.BigInt) error {
+	if len(stored) == 0 {
+		return fmt.Errorf("election has no results")
+	}
+	if len(stored) != len(results) {
+		return fmt.Errorf("results have %d questions, expected %d", len(results), len(stored))
+	}
+	for i, question := range stored {
+		if len(question.Question) != len(results[i]) {
+			return fmt.Errorf("results question %d has %d options, expected %d",
+				i, len(results[i]), len(question.Question))
+		}
+		for j, value := range question.Question {
+			if results[i][j] == nil || !results[i][j].Equal(new(types.BigInt).SetBytes(value)) {
+				return fmt.Errorf("results question %d option %d do not match", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// checkKeys checks that the bundle keys are the ones revealed in the state, and
+// that each private key corresponds to its public key.
+func checkKeys(process *models.Process, keys []EncryptionKey) error {
+	expected := Keys(process)
+	if len(keys) != len(expected) {
+		return fmt.Errorf("bundle has %d encryption keys, expected %d", len(keys), len(expected))
+	}
+	for i, key := range keys {
+		if key.Index != expected[i].Index ||
+			!bytes.Equal(key.PublicKey, expected[i].PublicKey) ||
+			!bytes.Equal(key.PrivateKey, expected[i].PrivateKey) {
+			return fmt.Errorf("encryption key %d does not match", key.Index)
+		}
+		priv, err := nacl.DecodePrivate(key.PrivateKey.String())
+		if err != nil {
+			return fmt.Errorf("cannot decode encryption key %d: %w", key.Index, err)
+		}
+		if !bytes.Equal(priv.Public().Bytes(), key.PublicKey) {
+			return fmt.Errorf("encryption private key %d does not match its public key", key.Index)
+		}
+	}
+	return nil
+}
+
+// hexBytes decodes a hex string, returning the raw string bytes if it is not
+// valid hex so that the comparison fails.
+func hexBytes(s string) types.HexBytes {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return types.HexBytes(s)
+	}
+	return b
+}
@@ -0,0 +1,156 @@
+package results
+
+import (
+	"fmt"
+	"math/big"
+
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// Names of the built-in tally strategies.
+const (
+	StrategyPlurality = "plurality"
+	StrategyQuadratic = "quadratic"
+)
+
+// Strategy is a method to tally the votes of an election. It owns the layout of
+// the votes matrix of the Results, how each vote is accumulated into it, and
+// how the matrix is serialized. The votes are checked against the ballot
+// protocol (max count, max value, unique values and cost) before they reach the
+// strategy, so it only needs to check the values it cannot accumulate.
+//
+// The matrices of the strategies are added and subtracted element-wise, as
+// Results.Add and Results.Sub do, so the tally of a set of votes must be the
+// sum of the tallies of each vote.
+type Strategy interface {
+	// Name returns the name of the strategy.
+	Name() string
+	// NewVotes returns the empty votes matrix of an election.
+	NewVotes(voteOpts *models.ProcessVoteOptions) [][]*types.BigInt
+	// AddVote adds the values of a vote, with its weight, to the votes.
+	AddVote(votes [][]*types.BigInt, values []int, weight *big.Int) error
+	// Encode and Decode serialize the votes in the results of a process.
+	Encode(votes [][]*types.BigInt) *models.ProcessResult
+	Decode(pr *models.ProcessResult) ([][]*types.BigInt, error)
+}
+
+// StrategyFor returns the strategy selected by the ballot protocol of an
+// election:
+//
+//   - quadratic, if the max value is zero, which aggregates the values of each
+//     question instead of counting them.
+//   - plurality otherwise.
+//
+// The results of the elections are part of the state, so a ballot protocol
+// must always select the same strategy. A new voting method is added as a new
+// Strategy, selected here by a ballot protocol that no other one uses, and
+// behind a fork if the existing elections may use that protocol.
+func StrategyFor(envelopeType *models.EnvelopeType, voteOpts *models.ProcessVoteOptions) Strategy {
+	switch {
+	case voteOpts == nil:
+		return pluralityStrategy{}
+	case voteOpts.MaxValue == 0:
+		return newQuadraticStrategy(envelopeType)
+	default:
+		return pluralityStrategy{}
+	}
+}
+
+// matrixEncoding serializes the votes matrix with a question result for each
+// row, which is the encoding of all the built-in strategies.
+type matrixEncoding struct{}
+
+func (matrixEncoding) Encode(votes [][]*types.BigInt) *models.ProcessResult {
+	qr := []*models.QuestionResult{}
+	for i := range votes {
+		qr = append(qr, &models.QuestionResult{})
+		for j := range votes[i] {
+			qr[i].Question = append(qr[i].Question, votes[i][j].Bytes())
+		}
+	}
+	return &models.ProcessResult{
+		Votes: qr,
+	}
+}
+
+func (matrixEncoding) Decode(pr *models.ProcessResult) ([][]*types.BigInt, error) {
+	votes := [][]*types.BigInt{}
+	for i := range pr.Votes {
+		votes = append(votes, []*types.BigInt{})
+		for j := range pr.Votes[i].Question {
+			votes[i] = append(votes[i], new(types.BigInt).SetBytes(pr.Votes[i].Question[j]))
+		}
+	}
+	return votes, nil
+}
+
+// pluralityStrategy counts the weight of the votes for each value of each
+// question, as described in the Ballot Protocol: the value of a question is
+// the index of the option chosen.
+type pluralityStrategy struct{ matrixEncoding }
+
+func (pluralityStrategy) Name() string { return StrategyPlurality }
+
+func (pluralityStrategy) NewVotes(voteOpts *models.ProcessVoteOptions) [][]*types.BigInt {
+	return NewEmptyVotes(voteOpts)
+}
+
+func (pluralityStrategy) AddVote(votes [][]*types.BigInt, values []int, weight *big.Int) error {
+	for q, opt := range values {
+		if q >= len(votes) || opt < 0 || opt >= len(votes[q]) {
+			return fmt.Errorf("value %d of question %d out of the results", opt, q)
+		}
+	}
+	for q, opt := range values {
+		votes[q][opt].Add(votes[q][opt], (*types.BigInt)(weight))
+	}
+	return nil
+}
+
+// quadraticStrategy aggregates the values of each question into the first
+// column of the matrix, multiplied by the weight unless the weight is the
+// budget of the voter (costFromWeight), in which case it is already
+// represented on the values. It is used by the quadratic voting, with a max
+// value of zero (no limit).
+//
+// Example, without costFromWeight:
+//
+//	Vote1: [1, 2, 3] w=10
+//	Vote2: [0, 3, 1] w=5
+//	[ [1*10+0*5], [2*10+3*5], [3*10+1*5] ]
+//	Results: [ [10], [35], [35] ]
+type quadraticStrategy struct {
+	matrixEncoding
+	costFromWeight bool
+}
+
+func newQuadraticStrategy(envelopeType *models.EnvelopeType) Strategy {
+	return quadraticStrategy{costFromWeight: envelopeType.GetCostFromWeight()}
+}
+
+func (quadraticStrategy) Name() string { return StrategyQuadratic }
+
+func (quadraticStrategy) NewVotes(voteOpts *models.ProcessVoteOptions) [][]*types.BigInt {
+	return NewEmptyVotes(voteOpts)
+}
+
+func (s quadraticStrategy) AddVote(votes [][]*types.BigInt, values []int, weight *big.Int) error {
+	if s.costFromWeight {
+		weight = new(big.Int).SetUint64(1)
+	}
+	for q := range values {
+		if q >= len(votes) || len(votes[q]) == 0 {
+			return fmt.Errorf("question %d out of the results", q)
+		}
+	}
+	for q, value := range values {
+		votes[q][0].Add(
+			votes[q][0],
+			new(types.BigInt).Mul(
+				new(types.BigInt).SetUint64(uint64(value)),
+				(*types.BigInt)(weight)),
+		)
+	}
+	return nil
+}
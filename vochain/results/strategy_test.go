@@ -0,0 +1,74 @@
+package results
+
+import (
+	"math/big"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+func newTestResults(envelopeType *models.EnvelopeType, voteOpts *models.ProcessVoteOptions) *Results {
+	return &Results{
+		Weight:       new(types.BigInt).SetUint64(0),
+		EnvelopeType: envelopeType,
+		VoteOpts:     voteOpts,
+	}
+}
+
+func TestStrategyFor(t *testing.T) {
+	c := qt.New(t)
+	for _, tc := range []struct {
+		envelopeType *models.EnvelopeType
+		voteOpts     *models.ProcessVoteOptions
+		want         string
+	}{
+		{&models.EnvelopeType{}, &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 3}, StrategyPlurality},
+		{&models.EnvelopeType{}, &models.ProcessVoteOptions{MaxCount: 1, MaxValue: 1}, StrategyPlurality},
+		{&models.EnvelopeType{}, &models.ProcessVoteOptions{MaxCount: 4, MaxValue: 1}, StrategyPlurality},
+		{&models.EnvelopeType{UniqueValues: true}, &models.ProcessVoteOptions{MaxCount: 2, MaxValue: 1}, StrategyPlurality},
+		{&models.EnvelopeType{CostFromWeight: true}, &models.ProcessVoteOptions{MaxCount: 3}, StrategyQuadratic},
+		{nil, nil, StrategyPlurality},
+	} {
+		c.Check(StrategyFor(tc.envelopeType, tc.voteOpts).Name(), qt.Equals, tc.want)
+	}
+}
+
+func TestStrategyTally(t *testing.T) {
+	c := qt.New(t)
+	addVotes := func(r *Results, votes [][]int, weights []int64) {
+		for i, values := range votes {
+			c.Assert(r.AddVote(values, big.NewInt(weights[i]), nil), qt.IsNil)
+		}
+	}
+
+	// plurality counts the weight of each value
+	r := newTestResults(&models.EnvelopeType{}, &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 1})
+	addVotes(r, [][]int{{1, 0, 1}, {1, 1, 0}}, []int64{2, 3})
+	c.Assert(r.String(), qt.Equals, "[0,5][2,3][3,2]")
+	c.Assert(r.Weight.String(), qt.Equals, "5")
+
+	// quadratic aggregates the values, without the weight if it is the budget
+	r = newTestResults(&models.EnvelopeType{}, &models.ProcessVoteOptions{MaxCount: 3})
+	addVotes(r, [][]int{{1, 2, 3}, {0, 3, 1}}, []int64{10, 5})
+	c.Assert(r.String(), qt.Equals, "[10][35][35]")
+	r = newTestResults(&models.EnvelopeType{CostFromWeight: true},
+		&models.ProcessVoteOptions{MaxCount: 3, CostExponent: 2})
+	addVotes(r, [][]int{{1, 2, 3}, {0, 3, 1}}, []int64{14, 10})
+	c.Assert(r.String(), qt.Equals, "[1][5][4]")
+	c.Assert(r.Weight.String(), qt.Equals, "24")
+
+	// the votes which cannot be tallied do not change the results
+	r = newTestResults(&models.EnvelopeType{}, &models.ProcessVoteOptions{MaxCount: 2, MaxValue: 3})
+	addVotes(r, [][]int{{0, 3}}, []int64{4})
+	r.VoteOpts.MaxValue = 5
+	c.Assert(r.AddVote([]int{0, 4}, nil, nil), qt.ErrorMatches, ".*value 4 of question 1 out of the results")
+	c.Assert(r.String(), qt.Equals, "[4,0,0,0][0,0,0,4]")
+	c.Assert(r.Weight.String(), qt.Equals, "4")
+
+	// all the strategies are serialized as a matrix
+	pr := ResultsToProto(r)
+	decoded := ProtoToResults(pr)
+	c.Assert(decoded.String(), qt.Equals, r.String())
+}
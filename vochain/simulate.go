@@ -0,0 +1,73 @@
+package vochain
+
+import (
+	"fmt"
+
+	"go.vocdoni.io/dvote/vochain/ist"
+	vstate "go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+)
+
+// TxSimulation is the expected outcome of a transaction, see SimulateTx.
+type TxSimulation struct {
+	TxHash []byte
+	TxType string
+	// Data is the response of the transaction, which depends on its type, see
+	// transaction.CheckTx.
+	Data []byte
+	// Cost is the amount of tokens burned by the transaction.
+	Cost uint64
+	// Err is the reason why the transaction would be rejected, nil if it
+	// would be accepted.
+	Err error
+}
+
+// SimulateTx runs a signed transaction through the same checks of CheckTx and
+// DeliverTx, on a copy of the last committed state, without broadcasting it.
+// The transactions of the block being built are not taken into account, so a
+// transaction which depends on a pending one (e.g. its nonce) is rejected.
+// The returned error is only non-nil if the simulation could not run, the
+// reason why the transaction would be rejected is in TxSimulation.Err.
+func (app *BaseApplication) SimulateTx(rawTx []byte) (*TxSimulation, error) {
+	tx := new(vochaintx.Tx)
+	if err := tx.Unmarshal(rawTx, app.ChainID()); err != nil {
+		return &TxSimulation{Err: err}, nil
+	}
+	sim := &TxSimulation{
+		TxHash: tx.TxID[:],
+		TxType: tx.TxModelType,
+	}
+	if sim.Err = app.checkValidHeight(tx, app.Height()); sim.Err != nil {
+		return sim, nil
+	}
+
+	fork, err := app.State.Fork()
+	if err != nil {
+		return nil, fmt.Errorf("cannot fork the state: %w", err)
+	}
+	defer fork.Discard()
+	handler := transaction.NewTransactionHandler(fork, ist.NewISTC(fork))
+	// as checked by the mempool
+	if _, err := handler.CheckTx(tx, false); err != nil {
+		sim.Err = fmt.Errorf("checkTx: %w", err)
+		return sim, nil
+	}
+	burnedBefore, err := fork.AccountBalance(vstate.BurnAddress, false)
+	if err != nil {
+		return nil, err
+	}
+	// as executed in a block
+	response, err := handler.CheckTx(tx, true)
+	if err != nil {
+		sim.Err = fmt.Errorf("deliverTx: %w", err)
+		return sim, nil
+	}
+	sim.Data = response.Data
+	burnedAfter, err := fork.AccountBalance(vstate.BurnAddress, false)
+	if err != nil {
+		return nil, err
+	}
+	sim.Cost = burnedAfter - burnedBefore
+	return sim, nil
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -89,13 +91,13 @@ func newTendermint(app *BaseApplication, localConfig *config.VochainCfg) (*comet
 	if localConfig.MinerTargetBlockTimeSeconds > 0 {
 		blockTime = time.Duration(localConfig.MinerTargetBlockTimeSeconds) * time.Second
 	}
-	tconfig.Consensus.TimeoutProposeDelta = time.Millisecond * 200
-	tconfig.Consensus.TimeoutPropose = blockTime * 6 / 10
-	tconfig.Consensus.TimeoutPrevoteDelta = time.Millisecond * 200
-	tconfig.Consensus.TimeoutPrevote = time.Second * 1
-	tconfig.Consensus.TimeoutPrecommitDelta = time.Millisecond * 200
-	tconfig.Consensus.TimeoutPrecommit = time.Second * 1
-	tconfig.Consensus.TimeoutCommit = blockTime
+	emptyBlocksInterval := time.Duration(localConfig.MinerEmptyBlocksIntervalSeconds) * time.Second
+	setConsensusTiming(tconfig.Consensus, blockTime, emptyBlocksInterval)
+	app.SetBlockTimeTarget(blockTime)
+	// the block timing of the network, if set, takes precedence
+	if app.State != nil {
+		app.updateBlockTiming(tconfig.Consensus)
+	}
 
 	// if seed node
 	if localConfig.IsSeedNode {
@@ -107,7 +109,8 @@ func newTendermint(app *BaseApplication, localConfig *config.VochainCfg) (*comet
 		"propose", tconfig.Consensus.TimeoutPropose.Seconds(),
 		"prevote", tconfig.Consensus.TimeoutPrevote.Seconds(),
 		"commit", tconfig.Consensus.TimeoutCommit.Seconds(),
-		"block", blockTime)
+		"emptyBlocksInterval", tconfig.Consensus.CreateEmptyBlocksInterval.Seconds(),
+		"block", app.BlockTimeTarget())
 
 	// disable transaction indexer (we don't use it)
 	tconfig.TxIndex = &cometconfig.TxIndexConfig{Indexer: "null"}
@@ -305,6 +308,27 @@ func newTendermint(app *BaseApplication, localConfig *config.VochainCfg) (*comet
 			app.ChainID(), genesis.HardcodedForChainID(app.ChainID()).EndOfChain)
 	}
 
+	// if GenesisForks cmdline flag was passed, override the genesis fork heights
+	if len(localConfig.GenesisForks) > 0 {
+		forks, err := parseForkHeights(localConfig.GenesisForks)
+		if err != nil {
+			return nil, fmt.Errorf("invalid genesis forks: %w", err)
+		}
+		g := genesis.HardcodedForChainID(app.ChainID())
+		g.Forks = maps.Clone(g.Forks)
+		if g.Forks == nil {
+			g.Forks = make(map[string]uint32)
+		}
+		maps.Copy(g.Forks, forks)
+		genesis.SetHardcodedForChainID(app.ChainID(), g)
+	}
+	if forks := genesis.HardcodedForChainID(app.ChainID()).Forks; len(forks) > 0 {
+		if err := app.State.ScheduleForks(forks); err != nil {
+			return nil, fmt.Errorf("cannot schedule forks: %w", err)
+		}
+		log.Infow("scheduled forks", "chainId", app.ChainID(), "forks", forks)
+	}
+
 	// assign the default tendermint methods
 	app.SetDefaultMethods()
 	node, err := cometnode.NewNode(
@@ -326,6 +350,24 @@ func newTendermint(app *BaseApplication, localConfig *config.VochainCfg) (*comet
 
 // migrateLegacyDirs handles the legacy paths used before commit "refactor genesis package"
 // cometbft data is now separated from vcstate and snapshots, so we need to migrate current deployments
+// parseForkHeights parses the fork heights of the GenesisForks flag, each one
+// as name=height.
+func parseForkHeights(entries []string) (map[string]uint32, error) {
+	forks := make(map[string]uint32, len(entries))
+	for _, entry := range entries {
+		name, value, found := strings.Cut(entry, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid fork %q, expected name=height", entry)
+		}
+		height, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid height of fork %s: %w", name, err)
+		}
+		forks[name] = uint32(height)
+	}
+	return forks, nil
+}
+
 func migrateLegacyDirs(dataDir string) {
 	// first move our dirs out, so they are not mixed with cometbft data
 	// * vochain/data/vcstate -> vochain/vcstate
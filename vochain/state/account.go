@@ -11,6 +11,7 @@ import (
 	"go.vocdoni.io/dvote/log"
 	"go.vocdoni.io/dvote/tree/arbo"
 	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/proto"
 )
@@ -268,7 +269,7 @@ func (v *State) BurnTxCostIncrementNonce(accountAddress common.Address, txType m
 	// get tx cost
 	if cost == 0 {
 		var err error
-		cost, err = v.TxBaseCost(txType, false)
+		cost, err = v.TxCost(txType, false)
 		if err != nil {
 			return fmt.Errorf("burnTxCostIncrementNonce: %w", err)
 		}
@@ -293,7 +294,7 @@ func (v *State) BurnTxCostIncrementNonce(accountAddress common.Address, txType m
 		if err := acc.Transfer(burnAcc, cost); err != nil {
 			return fmt.Errorf("burnTxCostIncrementNonce: %w", err)
 		}
-		log.Debugw("burning fee", "txType", txType.String(), "cost", cost, "account", accountAddress.String())
+		log.Debugw("burning fee", "txType", vochaintx.TxTypeName(txType), "cost", cost, "account", accountAddress.String())
 		if err := v.SetAccount(BurnAddress, burnAcc); err != nil {
 			return fmt.Errorf("burnTxCostIncrementNonce: %w", err)
 		}
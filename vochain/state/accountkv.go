@@ -0,0 +1,139 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/tree/arbo"
+)
+
+const (
+	// MaxAccountKVEntries is the maximum number of entries of the key-value
+	// store of an account.
+	MaxAccountKVEntries = 32
+	// MaxAccountKVKeySize is the maximum size in bytes of a key of the account
+	// key-value store.
+	MaxAccountKVKeySize = 32
+	// MaxAccountKVValueSize is the maximum size in bytes of a value of the
+	// account key-value store.
+	MaxAccountKVValueSize = 256
+)
+
+// accountKVPrefix is the prefix of the Extra tree keys that hold the key-value
+// store of each account, followed by the account address.
+var accountKVPrefix = []byte("akv/")
+
+// AccountKV is an entry of the key-value store of an account.
+type AccountKV struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// CheckAccountKV checks that the key and value fit the limits of the account
+// key-value store. An empty value deletes the entry.
+func CheckAccountKV(key string, value []byte) error {
+	if key == "" || len(key) > MaxAccountKVKeySize {
+		return fmt.Errorf("%w: length must be between 1 and %d bytes", ErrAccountKVKeyInvalid, MaxAccountKVKeySize)
+	}
+	if strings.ContainsFunc(key, func(r rune) bool { return r < 0x20 || r == 0x7f }) {
+		return fmt.Errorf("%w: cannot contain control characters", ErrAccountKVKeyInvalid)
+	}
+	if len(value) > MaxAccountKVValueSize {
+		return fmt.Errorf("%w: %d bytes, max is %d", ErrAccountKVValueTooBig, len(value), MaxAccountKVValueSize)
+	}
+	return nil
+}
+
+// AccountKV returns the entries of the key-value store of an account, sorted by key.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) AccountKV(address common.Address, committed bool) ([]AccountKV, error) {
+	if !committed {
+		v.tx.RLock()
+		defer v.tx.RUnlock()
+	}
+	extraTree, err := v.mainTreeViewer(committed).SubTree(StateTreeCfg(TreeExtra))
+	if err != nil {
+		return nil, err
+	}
+	data, err := extraTree.Get(accountKVKey(address))
+	if errors.Is(err, arbo.ErrKeyNotFound) {
+		return []AccountKV{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeAccountKV(data)
+}
+
+// SetAccountKV sets the entry key of the key-value store of an account to value,
+// or deletes it if value is empty. It fails if a new entry would exceed
+// MaxAccountKVEntries.
+func (v *State) SetAccountKV(address common.Address, key string, value []byte) error {
+	if err := CheckAccountKV(key, value); err != nil {
+		return err
+	}
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	extraTree, err := v.tx.DeepSubTree(StateTreeCfg(TreeExtra))
+	if err != nil {
+		return err
+	}
+	entries := []AccountKV{}
+	data, err := extraTree.Get(accountKVKey(address))
+	switch {
+	case errors.Is(err, arbo.ErrKeyNotFound):
+	case err != nil:
+		return err
+	default:
+		if entries, err = decodeAccountKV(data); err != nil {
+			return err
+		}
+	}
+	i, found := slices.BinarySearchFunc(entries, key, func(e AccountKV, key string) int {
+		return strings.Compare(e.Key, key)
+	})
+	switch {
+	case len(value) == 0 && !found:
+		return nil
+	case len(value) == 0:
+		entries = slices.Delete(entries, i, i+1)
+	case found:
+		entries[i].Value = value
+	case len(entries) >= MaxAccountKVEntries:
+		return fmt.Errorf("%w: max %d entries", ErrAccountKVFull, MaxAccountKVEntries)
+	default:
+		entries = slices.Insert(entries, i, AccountKV{Key: key, Value: value})
+	}
+	if len(entries) == 0 {
+		err = extraTree.Del(accountKVKey(address))
+	} else if data, err = json.Marshal(entries); err == nil {
+		err = extraTree.Set(accountKVKey(address), data)
+	}
+	if err != nil {
+		return err
+	}
+	for _, l := range v.eventListeners {
+		l.OnSetAccountKV(address.Bytes(), key, value)
+	}
+	return nil
+}
+
+// accountKVKey returns the Extra tree key of the key-value store of an account.
+func accountKVKey(address common.Address) []byte {
+	return append(append([]byte{}, accountKVPrefix...), address.Bytes()...)
+}
+
+// decodeAccountKV decodes the entries of an account key-value store.
+func decodeAccountKV(data []byte) ([]AccountKV, error) {
+	entries := []AccountKV{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("cannot decode account key-value store: %w", err)
+	}
+	return entries, nil
+}
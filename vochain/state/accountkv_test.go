@@ -0,0 +1,71 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db"
+)
+
+func TestSetAccountKV(t *testing.T) {
+	c := qt.New(t)
+	s, err := New(db.TypePebble, t.TempDir())
+	c.Assert(err, qt.IsNil)
+	defer func() { _ = s.Close() }()
+
+	addr := common.HexToAddress("0xF3668000B66c61aAa08aBC559a8C78Ae7E007C2e")
+	entries, err := s.AccountKV(addr, false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.HasLen, 0)
+
+	// entries are returned sorted by key
+	c.Assert(s.SetAccountKV(addr, "webhook", []byte("hash")), qt.IsNil)
+	c.Assert(s.SetAccountKV(addr, "delegate", addr.Bytes()), qt.IsNil)
+	entries, err = s.AccountKV(addr, false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.DeepEquals, []AccountKV{
+		{Key: "delegate", Value: addr.Bytes()},
+		{Key: "webhook", Value: []byte("hash")},
+	})
+	// not visible until committed
+	entries, err = s.AccountKV(addr, true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.HasLen, 0)
+	testSaveState(t, s)
+	entries, err = s.AccountKV(addr, true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.HasLen, 2)
+
+	// overwrite and delete
+	c.Assert(s.SetAccountKV(addr, "webhook", []byte("other")), qt.IsNil)
+	c.Assert(s.SetAccountKV(addr, "delegate", nil), qt.IsNil)
+	c.Assert(s.SetAccountKV(addr, "missing", nil), qt.IsNil)
+	entries, err = s.AccountKV(addr, false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.DeepEquals, []AccountKV{{Key: "webhook", Value: []byte("other")}})
+	c.Assert(s.SetAccountKV(addr, "webhook", nil), qt.IsNil)
+	entries, err = s.AccountKV(addr, false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.HasLen, 0)
+
+	// limits
+	c.Assert(s.SetAccountKV(addr, "", []byte("v")), qt.ErrorIs, ErrAccountKVKeyInvalid)
+	c.Assert(s.SetAccountKV(addr, strings.Repeat("k", MaxAccountKVKeySize+1), []byte("v")), qt.ErrorIs, ErrAccountKVKeyInvalid)
+	c.Assert(s.SetAccountKV(addr, "a\nb", []byte("v")), qt.ErrorIs, ErrAccountKVKeyInvalid)
+	c.Assert(s.SetAccountKV(addr, "big", bytes.Repeat([]byte{1}, MaxAccountKVValueSize+1)), qt.ErrorIs, ErrAccountKVValueTooBig)
+	for i := 0; i < MaxAccountKVEntries; i++ {
+		c.Assert(s.SetAccountKV(addr, fmt.Sprintf("key%02d", i), []byte{byte(i)}), qt.IsNil)
+	}
+	c.Assert(s.SetAccountKV(addr, "one-more", []byte("v")), qt.ErrorIs, ErrAccountKVFull)
+	// existing entries can still be updated
+	c.Assert(s.SetAccountKV(addr, "key00", []byte("v")), qt.IsNil)
+
+	// other accounts are not affected
+	entries, err = s.AccountKV(common.HexToAddress("0x01"), false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.HasLen, 0)
+}
@@ -32,6 +32,18 @@ var (
 		models.TxType_DEL_ACCOUNT_SIK:            "c_delAccountSIK",
 		models.TxType_REGISTER_SIK:               "c_registerSIK",
 		models.TxType_SET_ACCOUNT_VALIDATOR:      "c_setAccountValidator",
+		// setting an entry of the account key-value store costs the same as setting the info URI
+		vochaintx.TxTypeSetAccountKV: "c_setAccountInfoURI",
+		// creating a multisig account costs the same as creating an account
+		vochaintx.TxTypeCreateMultisigAccount: "c_createAccount",
+		// setting the block timing costs the same as setting an account validator
+		vochaintx.TxTypeSetBlockTiming: "c_setAccountValidator",
+		// delegating the vote weight in a process costs the same as registering a SIK
+		vochaintx.TxTypeDelegateVoteWeight: "c_registerSIK",
+		// the account recovery transactions cost the same as adding a delegate
+		vochaintx.TxTypeSetRecoveryGuardians:   "c_addDelegateForAccount",
+		vochaintx.TxTypeRecoverAccount:         "c_addDelegateForAccount",
+		vochaintx.TxTypeExecuteAccountRecovery: "c_addDelegateForAccount",
 	}
 	ErrTxCostNotFound = fmt.Errorf("transaction cost is not set")
 )
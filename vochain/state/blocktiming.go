@@ -0,0 +1,189 @@
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/statedb"
+	"go.vocdoni.io/dvote/tree/arbo"
+)
+
+const (
+	// MinBlockTime and MaxBlockTime are the bounds of BlockTiming.BlockTime, in seconds.
+	MinBlockTime = 1
+	MaxBlockTime = 60
+	// MaxEmptyBlocksInterval is the maximum BlockTiming.EmptyBlocksInterval, in
+	// seconds. The processes last a number of blocks, so without empty blocks
+	// they would never end on an idle network.
+	MaxEmptyBlocksInterval = 3600
+)
+
+var (
+	// blockTimingKey is the Extra tree key of the block timing of the network.
+	blockTimingKey = []byte("blockTiming")
+	// blockTimingVotesKey is the Extra tree key of the votes of the validators
+	// for a new block timing, see VoteBlockTiming.
+	blockTimingVotesKey = []byte("blockTimingVotes")
+)
+
+// BlockTiming holds the consensus timing parameters of the network, which the
+// nodes apply instead of their local configuration once set in the state.
+type BlockTiming struct {
+	// BlockTime is the target time between blocks, in seconds.
+	BlockTime uint32 `json:"blockTime"`
+	// EmptyBlocksInterval is the time, in seconds, the validators wait for new
+	// transactions before proposing an empty block. Zero means that the blocks
+	// are created every BlockTime even if there are no transactions.
+	EmptyBlocksInterval uint32 `json:"emptyBlocksInterval"`
+}
+
+// Validate checks that the block timing parameters are within their bounds.
+func (bt *BlockTiming) Validate() error {
+	if bt.BlockTime < MinBlockTime || bt.BlockTime > MaxBlockTime {
+		return fmt.Errorf("block time %ds out of bounds [%d, %d]", bt.BlockTime, MinBlockTime, MaxBlockTime)
+	}
+	if bt.EmptyBlocksInterval > 0 &&
+		(bt.EmptyBlocksInterval < bt.BlockTime || bt.EmptyBlocksInterval > MaxEmptyBlocksInterval) {
+		return fmt.Errorf("empty blocks interval %ds out of bounds [%d, %d]",
+			bt.EmptyBlocksInterval, bt.BlockTime, MaxEmptyBlocksInterval)
+	}
+	return nil
+}
+
+// SetBlockTiming sets the block timing of the network, which must be valid.
+func (v *State) SetBlockTiming(bt *BlockTiming) error {
+	if err := bt.Validate(); err != nil {
+		return err
+	}
+	b := binary.BigEndian.AppendUint32(nil, bt.BlockTime)
+	b = binary.BigEndian.AppendUint32(b, bt.EmptyBlocksInterval)
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	return v.tx.DeepSet(blockTimingKey, b, StateTreeCfg(TreeExtra))
+}
+
+// BlockTiming returns the block timing of the network, or nil if it was never
+// set, in which case the nodes use their local configuration.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) BlockTiming(committed bool) (*BlockTiming, error) {
+	if !committed {
+		v.tx.RLock()
+		defer v.tx.RUnlock()
+	}
+	extraTree, err := v.mainTreeViewer(committed).SubTree(StateTreeCfg(TreeExtra))
+	if err != nil {
+		return nil, err
+	}
+	b, err := extraTree.Get(blockTimingKey)
+	if err != nil {
+		if errors.Is(err, arbo.ErrKeyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(b) != 8 {
+		return nil, fmt.Errorf("invalid block timing length %d", len(b))
+	}
+	return &BlockTiming{
+		BlockTime:           binary.BigEndian.Uint32(b),
+		EmptyBlocksInterval: binary.BigEndian.Uint32(b[4:]),
+	}, nil
+}
+
+// BlockTimingVote is the block timing voted by a validator, see VoteBlockTiming.
+type BlockTimingVote struct {
+	Validator common.Address `json:"validator"`
+	Timing    BlockTiming    `json:"timing"`
+}
+
+// BlockTimingVotes returns the pending votes of the validators for a new block
+// timing, sorted by validator.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) BlockTimingVotes(committed bool) ([]BlockTimingVote, error) {
+	b, err := v.extraValue(blockTimingVotesKey, committed)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	var votes []BlockTimingVote
+	if err := json.Unmarshal(b, &votes); err != nil {
+		return nil, fmt.Errorf("cannot decode block timing votes: %w", err)
+	}
+	return votes, nil
+}
+
+// VoteBlockTiming registers the vote of a validator for the block timing,
+// replacing the previous vote of the validator. Once the validators voting for
+// the same block timing hold more than two thirds of the voting power of the
+// current validators, the block timing is set and the votes are cleared, so
+// that a single validator cannot change the timing of the whole network. The
+// votes of the validators which were removed are not counted. Returns whether
+// the block timing was set.
+func (v *State) VoteBlockTiming(validator common.Address, bt *BlockTiming) (bool, error) {
+	if err := bt.Validate(); err != nil {
+		return false, err
+	}
+	validators, err := v.Validators(false)
+	if err != nil {
+		return false, err
+	}
+	if validators[hex.EncodeToString(validator.Bytes())] == nil {
+		return false, fmt.Errorf("only the validators can vote the block timing, %s is not one", validator.Hex())
+	}
+	votes, err := v.BlockTimingVotes(false)
+	if err != nil {
+		return false, err
+	}
+	votes = slices.DeleteFunc(votes, func(vote BlockTimingVote) bool {
+		return vote.Validator == validator
+	})
+	votes = append(votes, BlockTimingVote{Validator: validator, Timing: *bt})
+	slices.SortFunc(votes, func(a, b BlockTimingVote) int {
+		return bytes.Compare(a.Validator.Bytes(), b.Validator.Bytes())
+	})
+
+	var totalPower, votedPower uint64
+	for _, val := range validators {
+		totalPower += val.Power
+	}
+	for _, vote := range votes {
+		if val := validators[hex.EncodeToString(vote.Validator.Bytes())]; val != nil && vote.Timing == *bt {
+			votedPower += val.Power
+		}
+	}
+	if 3*votedPower <= 2*totalPower {
+		data, err := json.Marshal(votes)
+		if err != nil {
+			return false, err
+		}
+		if err := v.updateExtra(func(extraTree *statedb.TreeUpdate) error {
+			return extraTree.Set(blockTimingVotesKey, data)
+		}); err != nil {
+			return false, err
+		}
+		log.Debugw("vote block timing", "validator", validator.Hex(), "blockTime", bt.BlockTime,
+			"emptyBlocksInterval", bt.EmptyBlocksInterval, "votedPower", votedPower, "totalPower", totalPower)
+		return false, nil
+	}
+	if err := v.updateExtra(func(extraTree *statedb.TreeUpdate) error {
+		return delExtra(extraTree, blockTimingVotesKey)
+	}); err != nil {
+		return false, err
+	}
+	if err := v.SetBlockTiming(bt); err != nil {
+		return false, err
+	}
+	log.Infow("block timing set by the validators", "blockTime", bt.BlockTime,
+		"emptyBlocksInterval", bt.EmptyBlocksInterval, "votedPower", votedPower, "totalPower", totalPower)
+	return true, nil
+}
@@ -0,0 +1,59 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+func TestVoteBlockTiming(t *testing.T) {
+	c := qt.New(t)
+	s, err := New(db.TypePebble, t.TempDir())
+	c.Assert(err, qt.IsNil)
+	defer func() { _ = s.Close() }()
+
+	validators := []common.Address{{1}, {2}, {3}, {4}}
+	for i, addr := range validators {
+		c.Assert(s.AddValidator(&models.Validator{Address: addr.Bytes(), Power: uint64(10 * (i + 1))}), qt.IsNil)
+	}
+	timing := &BlockTiming{BlockTime: 5, EmptyBlocksInterval: 60}
+	other := &BlockTiming{BlockTime: 8}
+
+	// only the validators can vote, for a valid block timing
+	_, err = s.VoteBlockTiming(common.Address{5}, timing)
+	c.Assert(err, qt.ErrorMatches, "only the validators.*")
+	_, err = s.VoteBlockTiming(validators[0], &BlockTiming{})
+	c.Assert(err, qt.ErrorMatches, "block time.*")
+
+	// the block timing is not set until more than two thirds of the voting
+	// power (100) votes for it, and the votes for another one do not count
+	set, err := s.VoteBlockTiming(validators[3], timing)
+	c.Assert(err, qt.IsNil)
+	c.Assert(set, qt.IsFalse)
+	set, err = s.VoteBlockTiming(validators[2], other)
+	c.Assert(err, qt.IsNil)
+	c.Assert(set, qt.IsFalse)
+	set, err = s.VoteBlockTiming(validators[1], timing)
+	c.Assert(err, qt.IsNil)
+	c.Assert(set, qt.IsFalse)
+	bt, err := s.BlockTiming(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(bt, qt.IsNil)
+	votes, err := s.BlockTimingVotes(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(votes, qt.HasLen, 3)
+
+	// a validator can change its vote, which completes the quorum
+	set, err = s.VoteBlockTiming(validators[2], timing)
+	c.Assert(err, qt.IsNil)
+	c.Assert(set, qt.IsTrue)
+	bt, err = s.BlockTiming(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(bt, qt.DeepEquals, timing)
+	votes, err = s.BlockTimingVotes(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(votes, qt.HasLen, 0)
+}
@@ -0,0 +1,97 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/tree/arbo"
+	"go.vocdoni.io/dvote/types"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// MaxProcessCensusUpdates is the maximum number of census updates of a process.
+const MaxProcessCensusUpdates = 128
+
+// censusHistoryPrefix is the prefix hashed along with the process id to build
+// the Extra tree key that holds the census history of the process.
+var censusHistoryPrefix = []byte("pcensus/")
+
+// CensusVersion is a version of the census of a process, which is used to
+// validate the votes from the block at Height on, until the next version.
+type CensusVersion struct {
+	Root types.HexBytes `json:"root"`
+	URI  string         `json:"uri,omitempty"`
+	Size uint64         `json:"size"`
+	// Height is the block at which the census was set, zero for the census
+	// the process was created with.
+	Height uint32 `json:"height"`
+}
+
+// ProcessCensusHistory returns the versions of the census of the process, from
+// the one it was created with to the current one.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) ProcessCensusHistory(pid []byte, committed bool) ([]CensusVersion, error) {
+	process, err := v.Process(pid, committed)
+	if err != nil {
+		return nil, err
+	}
+	if !committed {
+		v.tx.RLock()
+		defer v.tx.RUnlock()
+	}
+	extraTree, err := v.mainTreeViewer(committed).SubTree(StateTreeCfg(TreeExtra))
+	if err != nil {
+		return nil, err
+	}
+	data, err := extraTree.Get(censusHistoryKey(pid))
+	if errors.Is(err, arbo.ErrKeyNotFound) {
+		// the census of the process was never updated
+		return []CensusVersion{censusVersion(process, 0)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	history := []CensusVersion{}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("cannot decode census history: %w", err)
+	}
+	return history, nil
+}
+
+// setProcessCensusHistory appends the current census of the process to its
+// census history, whose last version is the previous census.
+func (v *State) setProcessCensusHistory(history []CensusVersion, process *models.Process) error {
+	history = append(history, censusVersion(process, v.CurrentHeight()))
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	extraTree, err := v.tx.DeepSubTree(StateTreeCfg(TreeExtra))
+	if err != nil {
+		return err
+	}
+	return extraTree.Set(censusHistoryKey(process.ProcessId), data)
+}
+
+// censusVersion returns the current census of the process as a version set at
+// the given height.
+func censusVersion(process *models.Process, height uint32) CensusVersion {
+	return CensusVersion{
+		Root:   process.CensusRoot,
+		URI:    process.GetCensusURI(),
+		Size:   process.GetMaxCensusSize(),
+		Height: height,
+	}
+}
+
+// censusHistoryKey returns the Extra tree key of the census history of a
+// process, which is hashed to fit the size of the tree keys.
+func censusHistoryKey(pid []byte) []byte {
+	return ethereum.HashRaw(append(append([]byte{}, censusHistoryPrefix...), pid...))
+}
@@ -23,4 +23,12 @@ var (
 	ErrSIKRootsGet          = fmt.Errorf("error getting current valid SIK root")
 	ErrSIKRootsSet          = fmt.Errorf("error setting new SIK roots")
 	ErrSIKRootsDelete       = fmt.Errorf("error deleting old SIK roots")
+	ErrAccountKVKeyInvalid  = fmt.Errorf("invalid account key-value store key")
+	ErrAccountKVValueTooBig = fmt.Errorf("account key-value store value too big")
+	ErrAccountKVFull        = fmt.Errorf("account key-value store is full")
+	ErrMultisigInvalid      = fmt.Errorf("invalid multisig account")
+	ErrMultisigNotFound     = fmt.Errorf("multisig account not found")
+	ErrRecoveryInvalid      = fmt.Errorf("invalid account recovery guardians")
+	ErrRecoveryNotFound     = fmt.Errorf("account recovery not found")
+	ErrAccountKeyRotated    = fmt.Errorf("the owner key of the account was rotated")
 )
@@ -17,6 +17,8 @@ import (
 type EventListener interface {
 	OnVote(vote *Vote, txIndex int32)
 	OnNewTx(tx *vochaintx.Tx, blockHeight uint32, txIndex int32)
+	// OnTxReceipt is called after OnNewTx with the receipt of the transaction.
+	OnTxReceipt(tx *vochaintx.Tx, receipt *vochaintx.Receipt, txIndex int32)
 	OnProcess(process *models.Process, txIndex int32)
 	OnProcessStatusChange(pid []byte, status models.ProcessStatus, txIndex int32)
 	OnProcessDurationChange(pid []byte, newDuration uint32, txIndex int32)
@@ -28,6 +30,9 @@ type EventListener interface {
 	OnSetAccount(addr []byte, account *Account)
 	OnTransferTokens(tx *vochaintx.TokenTransfer)
 	OnSpendTokens(addr []byte, txType models.TxType, cost uint64, reference string)
+	OnSetAccountKV(addr []byte, key string, value []byte)
+	OnVoteDelegation(pid, delegator []byte, delegation *VoteDelegation)
+	OnAccountRecovery(addr []byte, event *AccountRecoveryEvent)
 	OnCensusUpdate(pid, censusRoot []byte, censusURI string, censusSize uint64)
 	Commit(height uint32) (err error)
 	Rollback()
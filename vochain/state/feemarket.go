@@ -0,0 +1,188 @@
+package state
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/tree/arbo"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+const (
+	// FeeMultiplierUnit is the fee multiplier which leaves the transaction base
+	// costs unchanged, since the multiplier is expressed in thousandths.
+	FeeMultiplierUnit = 1000
+	// FeeMarketAdjustmentQuotient bounds the change of the fee multiplier on
+	// each block to 1/FeeMarketAdjustmentQuotient of its value, as the base fee
+	// of EIP-1559.
+	FeeMarketAdjustmentQuotient = 8
+	// MaxFeeMultiplier is the maximum FeeMarket.MaxMultiplier.
+	MaxFeeMultiplier = 1000
+)
+
+var (
+	// feeMarketKey is the Extra tree key of the fee market parameters.
+	feeMarketKey = []byte("feeMarket")
+	// feeMultiplierKey is the Extra tree key of the current fee multiplier.
+	feeMultiplierKey = []byte("feeMultiplier")
+)
+
+// FeeMarket holds the parameters of the dynamic transaction costs. Once set,
+// the base cost of each transaction type is multiplied by a fee multiplier,
+// which grows while the blocks carry more than TargetBlockTxs transactions
+// which paid a cost and decreases back to one while they carry less, from the
+// ForkFeeMarket fork. The election price is not
+// affected, since it already depends on the network capacity.
+type FeeMarket struct {
+	// TargetBlockTxs is the number of transactions per block at which the
+	// fee multiplier does not change.
+	TargetBlockTxs uint32 `json:"targetBlockTxs"`
+	// MaxMultiplier is the maximum factor applied to the base costs.
+	MaxMultiplier uint32 `json:"maxMultiplier"`
+}
+
+// Validate checks that the fee market parameters are within their bounds.
+func (fm *FeeMarket) Validate() error {
+	if fm.TargetBlockTxs == 0 {
+		return fmt.Errorf("the target block txs must be positive")
+	}
+	if fm.MaxMultiplier < 1 || fm.MaxMultiplier > MaxFeeMultiplier {
+		return fmt.Errorf("max fee multiplier %d out of bounds [1, %d]", fm.MaxMultiplier, MaxFeeMultiplier)
+	}
+	return nil
+}
+
+// NextMultiplier returns the fee multiplier of the next block, given the
+// current one and the number of transactions of the block.
+func (fm *FeeMarket) NextMultiplier(current uint64, blockTxs uint32) uint64 {
+	target := int64(fm.TargetBlockTxs)
+	delta := int64(current) * (int64(blockTxs) - target) / (target * FeeMarketAdjustmentQuotient)
+	if delta == 0 && int64(blockTxs) > target {
+		// ensure that the multiplier grows when the blocks are over the target
+		delta = 1
+	}
+	next := max(int64(current)+delta, FeeMultiplierUnit)
+	return min(uint64(next), uint64(fm.MaxMultiplier)*FeeMultiplierUnit)
+}
+
+// SetFeeMarket enables the dynamic transaction costs with the given parameters,
+// which must be valid.
+func (v *State) SetFeeMarket(fm *FeeMarket) error {
+	if err := fm.Validate(); err != nil {
+		return err
+	}
+	b := binary.BigEndian.AppendUint32(nil, fm.TargetBlockTxs)
+	b = binary.BigEndian.AppendUint32(b, fm.MaxMultiplier)
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	return v.tx.DeepSet(feeMarketKey, b, StateTreeCfg(TreeExtra))
+}
+
+// FeeMarket returns the fee market parameters, or nil if the transaction costs
+// are not dynamic.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) FeeMarket(committed bool) (*FeeMarket, error) {
+	b, err := v.extraValue(feeMarketKey, committed)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	if len(b) != 8 {
+		return nil, fmt.Errorf("invalid fee market length %d", len(b))
+	}
+	return &FeeMarket{
+		TargetBlockTxs: binary.BigEndian.Uint32(b),
+		MaxMultiplier:  binary.BigEndian.Uint32(b[4:]),
+	}, nil
+}
+
+// FeeMultiplier returns the current fee multiplier, in thousandths (see
+// FeeMultiplierUnit), applied to the base costs of the transactions.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) FeeMultiplier(committed bool) (uint64, error) {
+	b, err := v.extraValue(feeMultiplierKey, committed)
+	if err != nil {
+		return 0, err
+	}
+	if b == nil {
+		return FeeMultiplierUnit, nil
+	}
+	if len(b) != 8 {
+		return 0, fmt.Errorf("invalid fee multiplier length %d", len(b))
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// UpdateFeeMultiplier sets the fee multiplier of the next block from the number
+// of transactions of the current one which paid a cost, see CostTxCounter, so
+// that the free transactions such as the votes do not raise the costs. It does
+// nothing if the fee market is not enabled, or before the ForkFeeMarket fork.
+func (v *State) UpdateFeeMultiplier(blockTxs uint32) error {
+	if active, err := v.ForkActive(ForkFeeMarket); err != nil || !active {
+		return err
+	}
+	fm, err := v.FeeMarket(false)
+	if err != nil || fm == nil {
+		return err
+	}
+	current, err := v.FeeMultiplier(false)
+	if err != nil {
+		return err
+	}
+	next := fm.NextMultiplier(current, blockTxs)
+	if next == current {
+		return nil
+	}
+	log.Debugw("fee multiplier updated", "blockTxs", blockTxs, "multiplier", next)
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	return v.tx.DeepSet(feeMultiplierKey, binary.BigEndian.AppendUint64(nil, next), StateTreeCfg(TreeExtra))
+}
+
+// TxCost returns the current cost of a given transaction, which is its base
+// cost multiplied by the fee multiplier, rounded up.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) TxCost(txType models.TxType, committed bool) (uint64, error) {
+	cost, err := v.TxBaseCost(txType, committed)
+	if err != nil || cost == 0 {
+		return cost, err
+	}
+	multiplier, err := v.FeeMultiplier(committed)
+	if err != nil {
+		return 0, err
+	}
+	if multiplier == FeeMultiplierUnit {
+		return cost, nil
+	}
+	if cost > math.MaxUint64/multiplier {
+		return 0, fmt.Errorf("cost of %s overflows", vochaintx.TxTypeName(txType))
+	}
+	return (cost*multiplier + FeeMultiplierUnit - 1) / FeeMultiplierUnit, nil
+}
+
+// extraValue returns the value of the key of the Extra tree, or nil if it does
+// not exist.
+func (v *State) extraValue(key []byte, committed bool) ([]byte, error) {
+	if !committed {
+		v.tx.RLock()
+		defer v.tx.RUnlock()
+	}
+	extraTree, err := v.mainTreeViewer(committed).SubTree(StateTreeCfg(TreeExtra))
+	if err != nil {
+		return nil, err
+	}
+	b, err := extraTree.Get(key)
+	if errors.Is(err, arbo.ErrKeyNotFound) {
+		return nil, nil
+	}
+	return b, err
+}
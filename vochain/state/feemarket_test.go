@@ -0,0 +1,77 @@
+package state
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+func TestFeeMarketNextMultiplier(t *testing.T) {
+	c := qt.New(t)
+	fm := &FeeMarket{TargetBlockTxs: 100, MaxMultiplier: 2}
+
+	// full blocks increase the multiplier by 1/8, up to the maximum
+	c.Assert(fm.NextMultiplier(FeeMultiplierUnit, 200), qt.Equals, uint64(1125))
+	c.Assert(fm.NextMultiplier(1900, 200), qt.Equals, uint64(2000))
+	// the multiplier always grows over the target
+	c.Assert(fm.NextMultiplier(FeeMultiplierUnit, 101), qt.Equals, uint64(1001))
+	// and it does not change at the target
+	c.Assert(fm.NextMultiplier(1500, 100), qt.Equals, uint64(1500))
+	// empty blocks decrease it by 1/8, down to one
+	c.Assert(fm.NextMultiplier(1600, 0), qt.Equals, uint64(1400))
+	c.Assert(fm.NextMultiplier(1100, 0), qt.Equals, uint64(FeeMultiplierUnit))
+
+	c.Assert((&FeeMarket{TargetBlockTxs: 0, MaxMultiplier: 2}).Validate(), qt.IsNotNil)
+	c.Assert((&FeeMarket{TargetBlockTxs: 10, MaxMultiplier: 0}).Validate(), qt.IsNotNil)
+	c.Assert((&FeeMarket{TargetBlockTxs: 10, MaxMultiplier: MaxFeeMultiplier + 1}).Validate(), qt.IsNotNil)
+}
+
+func TestFeeMarketTxCost(t *testing.T) {
+	c := qt.New(t)
+	s, err := New(db.TypePebble, t.TempDir())
+	c.Assert(err, qt.IsNil)
+	defer func() { _ = s.Close() }()
+
+	c.Assert(s.SetTxBaseCost(models.TxType_SEND_TOKENS, 10), qt.IsNil)
+
+	// the costs are fixed until the fee market is enabled
+	c.Assert(s.UpdateFeeMultiplier(1000), qt.IsNil)
+	cost, err := s.TxCost(models.TxType_SEND_TOKENS, false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cost, qt.Equals, uint64(10))
+	fm, err := s.FeeMarket(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(fm, qt.IsNil)
+
+	c.Assert(s.SetFeeMarket(&FeeMarket{TargetBlockTxs: 2, MaxMultiplier: 3}), qt.IsNil)
+
+	// and the fork is applied
+	c.Assert(s.UpdateFeeMultiplier(4), qt.IsNil)
+	multiplier, err := s.FeeMultiplier(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(multiplier, qt.Equals, uint64(FeeMultiplierUnit))
+	c.Assert(s.SetForkHeight(ForkFeeMarket, 0), qt.IsNil)
+	c.Assert(s.UpdateFeeMultiplier(4), qt.IsNil)
+	multiplier, err = s.FeeMultiplier(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(multiplier, qt.Equals, uint64(1125))
+	// the cost is rounded up
+	cost, err = s.TxCost(models.TxType_SEND_TOKENS, false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cost, qt.Equals, uint64(12))
+	// the base cost does not change
+	cost, err = s.TxBaseCost(models.TxType_SEND_TOKENS, false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cost, qt.Equals, uint64(10))
+
+	// not visible until committed
+	multiplier, err = s.FeeMultiplier(true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(multiplier, qt.Equals, uint64(FeeMultiplierUnit))
+	testSaveState(t, s)
+	cost, err = s.TxCost(models.TxType_SEND_TOKENS, true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cost, qt.Equals, uint64(12))
+}
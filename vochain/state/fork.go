@@ -0,0 +1,56 @@
+package state
+
+import (
+	"fmt"
+	"slices"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.vocdoni.io/dvote/statedb"
+)
+
+// forkVoteCacheSize is the size of the vote cache of a forked State, which
+// only lives for a few transactions.
+const forkVoteCacheSize = 16
+
+// Fork returns a throwaway copy of the last committed State, whose changes are
+// never saved, so that transactions can be executed without affecting the
+// chain. The transactions of the block being built are not included. The
+// event listeners are not copied. The fork must be released with Discard, and
+// not with Close, since it shares the database of the State.
+func (v *State) Fork() (*State, error) {
+	// a StateDB of its own keeps the NoState writes of the fork apart
+	sdb := statedb.New(v.db)
+	tx, err := sdb.BeginTx()
+	if err != nil {
+		return nil, fmt.Errorf("cannot begin statedb tx: %w", err)
+	}
+	voteCache, err := lru.New[string, *Vote](forkVoteCacheSize)
+	if err != nil {
+		tx.Discard()
+		return nil, err
+	}
+	fork := &State{
+		db:                v.db,
+		store:             sdb,
+		tx:                treeTxWithMutex{TreeTx: tx},
+		voteCache:         voteCache,
+		chainID:           v.chainID,
+		ElectionPriceCalc: v.ElectionPriceCalc,
+		validSIKRoots:     slices.Clone(v.ValidSIKRoots()),
+		scheduledForks:    v.ScheduledForks(),
+	}
+	fork.currentHeight.Store(v.CurrentHeight())
+	fork.setMainTreeView(v.MainTreeView())
+	fork.ProcessBlockRegistry = &ProcessBlockRegistry{
+		db:    fork.NoState(true),
+		state: fork,
+	}
+	return fork, nil
+}
+
+// Discard releases a State returned by Fork, dropping its changes.
+func (v *State) Discard() {
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	v.tx.Discard()
+}
@@ -0,0 +1,164 @@
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// The consensus changes which are only applied from a fork height, so that the
+// existing chains are replayed with the rules they were created with. The
+// height of a fork is either set in the genesis of a new chain (see
+// SetForkHeight), or scheduled by the node release for a running chain (see
+// ScheduleForks).
+const (
+	// ForkProcessEnd makes the processes which opt out of the automatic end
+	// stay open once their duration elapses, and reschedules the end of the
+	// processes whose duration changes, see vochaintx.ProcessModeManualEnd.
+	ForkProcessEnd = "processEnd"
+	// ForkAccountKV enables the transactions which set the entries of the
+	// account key-value store, see vochaintx.TxTypeSetAccountKV.
+	ForkAccountKV = "accountKV"
+	// ForkTxValidity enforces the validity window of the transactions, which
+	// is ignored before, see vochaintx.WithValidHeights.
+	ForkTxValidity = "txValidity"
+	// ForkMultisig enables the multisig accounts and the transactions signed
+	// on their behalf, whose signatures are ignored before, see
+	// vochaintx.TxTypeCreateMultisigAccount.
+	ForkMultisig = "multisig"
+	// ForkBlockTiming enables the transactions which vote for the block
+	// timing of the network, see vochaintx.TxTypeSetBlockTiming.
+	ForkBlockTiming = "blockTiming"
+	// ForkKeyShares enables the transactions which escrow the shares of the
+	// keys of the keykeepers, and the recovery of the keys not revealed from
+	// them, see vochaintx.TxTypeSetProcessKeyShares.
+	ForkKeyShares = "keyShares"
+	// ForkFeeMarket enables the fee market, whose fee multiplier stays at one
+	// before, see FeeMarket.
+	ForkFeeMarket = "feeMarket"
+	// ForkNullifierGroups enables the processes whose anonymous voters share
+	// their nullifiers, see vochaintx.ProcessModeNullifierGroup.
+	ForkNullifierGroups = "nullifierGroups"
+	// ForkVoteDeposit enables the processes which require their voters to
+	// lock a deposit, see vochaintx.ProcessModeVoteDeposit.
+	ForkVoteDeposit = "voteDeposit"
+	// ForkVoteDelegation enables the processes whose census members can
+	// delegate their vote weight, and the transactions which delegate it, see
+	// vochaintx.TxTypeDelegateVoteWeight.
+	ForkVoteDelegation = "voteDelegation"
+	// ForkRecovery enables the transactions which rotate the owner key of the
+	// accounts through their guardians, see vochaintx.TxTypeSetRecoveryGuardians.
+	ForkRecovery = "recovery"
+)
+
+// forks are the names of all the known forks.
+var forks = []string{
+	ForkProcessEnd,
+	ForkAccountKV,
+	ForkTxValidity,
+	ForkMultisig,
+	ForkBlockTiming,
+	ForkKeyShares,
+	ForkFeeMarket,
+	ForkNullifierGroups,
+	ForkVoteDeposit,
+	ForkVoteDelegation,
+	ForkRecovery,
+}
+
+// Forks returns the names of all the known forks.
+func Forks() []string {
+	return slices.Clone(forks)
+}
+
+// forkHeightPrefix is the Extra tree prefix of the heights of the forks.
+var forkHeightPrefix = []byte("fork/")
+
+// SetForkHeight sets the height from which the fork is applied, zero to apply
+// it from the first block. It is meant to be called on InitChain, with the
+// forks of the genesis.
+func (v *State) SetForkHeight(fork string, height uint32) error {
+	if err := CheckFork(fork); err != nil {
+		return err
+	}
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	return v.tx.DeepSet(forkHeightKey(fork), binary.BigEndian.AppendUint32(nil, height), StateTreeCfg(TreeExtra))
+}
+
+// ScheduleForks sets the heights from which the forks are applied on a running
+// chain, whose genesis did not set them. The heights are not stored in the
+// state, so every node of the chain must schedule the same ones before they
+// are reached, usually through the hardcoded genesis of the chain (see
+// genesis.Doc.Forks). The heights set in the genesis take precedence.
+func (v *State) ScheduleForks(heights map[string]uint32) error {
+	for _, fork := range slices.Sorted(maps.Keys(heights)) {
+		if err := CheckFork(fork); err != nil {
+			return err
+		}
+		if heights[fork] == 0 {
+			return fmt.Errorf("invalid height 0 for fork %s, which is already running", fork)
+		}
+	}
+	v.mtxScheduledForks.Lock()
+	defer v.mtxScheduledForks.Unlock()
+	v.scheduledForks = maps.Clone(heights)
+	return nil
+}
+
+// ScheduledForks returns a copy of the fork heights set by ScheduleForks.
+func (v *State) ScheduledForks() map[string]uint32 {
+	v.mtxScheduledForks.RLock()
+	defer v.mtxScheduledForks.RUnlock()
+	return maps.Clone(v.scheduledForks)
+}
+
+// ForkHeight returns the height from which the fork is applied, and whether it
+// is applied at all.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) ForkHeight(fork string, committed bool) (uint32, bool, error) {
+	b, err := v.extraValue(forkHeightKey(fork), committed)
+	if err != nil {
+		return 0, false, err
+	}
+	if b == nil {
+		v.mtxScheduledForks.RLock()
+		defer v.mtxScheduledForks.RUnlock()
+		height, ok := v.scheduledForks[fork]
+		return height, ok, nil
+	}
+	if len(b) != 4 {
+		return 0, false, fmt.Errorf("invalid height length %d of fork %s", len(b), fork)
+	}
+	return binary.BigEndian.Uint32(b), true, nil
+}
+
+// ForkActive returns whether the fork is applied at the current height.
+func (v *State) ForkActive(fork string) (bool, error) {
+	return v.ForkActiveAt(fork, v.CurrentHeight())
+}
+
+// ForkActiveAt returns whether the fork is applied at the given height.
+func (v *State) ForkActiveAt(fork string, height uint32) (bool, error) {
+	forkHeight, ok, err := v.ForkHeight(fork, false)
+	if err != nil {
+		return false, err
+	}
+	return ok && height >= forkHeight, nil
+}
+
+// CheckFork returns an error if the fork is unknown.
+func CheckFork(fork string) error {
+	if !slices.Contains(forks, fork) {
+		return fmt.Errorf("unknown fork %s", fork)
+	}
+	return nil
+}
+
+// forkHeightKey returns the Extra tree key of the height of the fork.
+func forkHeightKey(fork string) []byte {
+	return append(append([]byte{}, forkHeightPrefix...), fork...)
+}
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db"
+)
+
+func TestForkHeight(t *testing.T) {
+	c := qt.New(t)
+	s, err := New(db.TypePebble, t.TempDir())
+	c.Assert(err, qt.IsNil)
+	defer s.Close()
+
+	// the forks without height are never active
+	active, err := s.ForkActive(ForkProcessEnd)
+	c.Assert(err, qt.IsNil)
+	c.Assert(active, qt.IsFalse)
+	c.Assert(s.SetForkHeight("unknown", 1), qt.ErrorMatches, "unknown fork unknown")
+
+	// the forks at height zero are applied from the first block
+	c.Assert(s.SetForkHeight(ForkProcessEnd, 0), qt.IsNil)
+	active, err = s.ForkActive(ForkProcessEnd)
+	c.Assert(err, qt.IsNil)
+	c.Assert(active, qt.IsTrue)
+
+	c.Assert(s.SetForkHeight(ForkProcessEnd, 2), qt.IsNil)
+	height, ok, err := s.ForkHeight(ForkProcessEnd, false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(height, qt.Equals, uint32(2))
+
+	s.SetHeight(1)
+	active, err = s.ForkActive(ForkProcessEnd)
+	c.Assert(err, qt.IsNil)
+	c.Assert(active, qt.IsFalse)
+	s.SetHeight(2)
+	active, err = s.ForkActive(ForkProcessEnd)
+	c.Assert(err, qt.IsNil)
+	c.Assert(active, qt.IsTrue)
+}
+
+func TestScheduleForks(t *testing.T) {
+	c := qt.New(t)
+	s, err := New(db.TypePebble, t.TempDir())
+	c.Assert(err, qt.IsNil)
+	defer s.Close()
+
+	c.Assert(s.ScheduleForks(map[string]uint32{"unknown": 1}), qt.ErrorMatches, "unknown fork unknown")
+	c.Assert(s.ScheduleForks(map[string]uint32{ForkProcessEnd: 0}), qt.ErrorMatches, "invalid height 0 .*")
+
+	// the running chains can schedule the forks their genesis did not set
+	c.Assert(s.ScheduleForks(map[string]uint32{ForkProcessEnd: 5}), qt.IsNil)
+	s.SetHeight(4)
+	active, err := s.ForkActive(ForkProcessEnd)
+	c.Assert(err, qt.IsNil)
+	c.Assert(active, qt.IsFalse)
+	s.SetHeight(5)
+	active, err = s.ForkActive(ForkProcessEnd)
+	c.Assert(err, qt.IsNil)
+	c.Assert(active, qt.IsTrue)
+
+	// the forks of the state see the same heights
+	fork, err := s.Fork()
+	c.Assert(err, qt.IsNil)
+	defer fork.Discard()
+	height, ok, err := fork.ForkHeight(ForkProcessEnd, true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(height, qt.Equals, uint32(5))
+
+	// but the height set in the genesis takes precedence
+	c.Assert(s.SetForkHeight(ForkProcessEnd, 10), qt.IsNil)
+	active, err = s.ForkActive(ForkProcessEnd)
+	c.Assert(err, qt.IsNil)
+	c.Assert(active, qt.IsFalse)
+}
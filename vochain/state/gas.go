@@ -0,0 +1,114 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// GasZkVote is the gas schedule key of the votes with a zk-SNARK proof, whose
+// verification is the most expensive part of the execution of a block, so they
+// are metered apart from the rest of the votes (models.TxType_VOTE).
+const GasZkVote = "ZK_VOTE"
+
+// gasScheduleKey is the Extra tree key of the gas schedule.
+var gasScheduleKey = []byte("gasSchedule")
+
+// GasSchedule holds the gas of each transaction type and the gas limit of the
+// blocks. The gas measures the work required to execute a transaction, unlike
+// its cost, which is paid in tokens. Once set, the block proposers only include
+// transactions up to BlockGasLimit, so that a few expensive transactions cannot
+// make the execution of a block exceed the consensus timeouts.
+type GasSchedule struct {
+	// BlockGasLimit is the maximum gas of the transactions of a block.
+	BlockGasLimit uint64 `json:"blockGasLimit"`
+	// DefaultTxGas is the gas of the transaction types not in TxGas.
+	DefaultTxGas uint64 `json:"defaultTxGas"`
+	// TxGas is the gas of each transaction type, keyed by its name (see
+	// vochaintx.TxTypeName) or GasZkVote.
+	TxGas map[string]uint64 `json:"txGas,omitempty"`
+}
+
+// Validate checks that the gas schedule parameters are within their bounds.
+func (gs *GasSchedule) Validate() error {
+	if gs.BlockGasLimit == 0 {
+		return fmt.Errorf("the block gas limit must be positive")
+	}
+	if gs.DefaultTxGas == 0 || gs.DefaultTxGas > gs.BlockGasLimit {
+		return fmt.Errorf("default tx gas %d out of bounds [1, %d]", gs.DefaultTxGas, gs.BlockGasLimit)
+	}
+	names := make([]string, 0, len(gs.TxGas))
+	for name := range gs.TxGas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name != GasZkVote && !isTxTypeName(name) {
+			return fmt.Errorf("unknown tx type %q", name)
+		}
+		if gas := gs.TxGas[name]; gas == 0 || gas > gs.BlockGasLimit {
+			return fmt.Errorf("gas of %s %d out of bounds [1, %d]", name, gas, gs.BlockGasLimit)
+		}
+	}
+	return nil
+}
+
+// Gas returns the gas of the transaction.
+func (gs *GasSchedule) Gas(vtx *vochaintx.Tx) uint64 {
+	name := vtx.TxSubtype()
+	if vote := vtx.Tx.GetVote(); vote != nil {
+		name = models.TxType_VOTE.String()
+		if vote.GetProof().GetZkSnark() != nil {
+			name = GasZkVote
+		}
+	}
+	if gas, ok := gs.TxGas[name]; ok {
+		return gas
+	}
+	return gs.DefaultTxGas
+}
+
+// isTxTypeName reports whether name is the name of a transaction type,
+// including the ones not defined in models.TxType.
+func isTxTypeName(name string) bool {
+	for txType := models.TxType(0); txType < 256; txType++ {
+		if vochaintx.TxTypeName(txType) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SetGasSchedule enables the gas metering with the given schedule, which must
+// be valid.
+func (v *State) SetGasSchedule(gs *GasSchedule) error {
+	if err := gs.Validate(); err != nil {
+		return err
+	}
+	b, err := json.Marshal(gs)
+	if err != nil {
+		return err
+	}
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	return v.tx.DeepSet(gasScheduleKey, b, StateTreeCfg(TreeExtra))
+}
+
+// GasSchedule returns the gas schedule, or nil if the blocks are not metered.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) GasSchedule(committed bool) (*GasSchedule, error) {
+	b, err := v.extraValue(gasScheduleKey, committed)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	gs := &GasSchedule{}
+	if err := json.Unmarshal(b, gs); err != nil {
+		return nil, fmt.Errorf("invalid gas schedule: %w", err)
+	}
+	return gs, nil
+}
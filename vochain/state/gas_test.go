@@ -0,0 +1,62 @@
+package state
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+func TestGasSchedule(t *testing.T) {
+	c := qt.New(t)
+	gs := &GasSchedule{
+		BlockGasLimit: 100,
+		DefaultTxGas:  1,
+		TxGas: map[string]uint64{
+			models.TxType_VOTE.String():      2,
+			GasZkVote:                        40,
+			vochaintx.TxTypeSetAccountKVName: 5,
+		},
+	}
+	c.Assert(gs.Validate(), qt.IsNil)
+
+	vote := &vochaintx.Tx{Tx: &models.Tx{Payload: &models.Tx_Vote{Vote: &models.VoteEnvelope{}}}}
+	c.Assert(gs.Gas(vote), qt.Equals, uint64(2))
+	zkVote := &vochaintx.Tx{Tx: &models.Tx{Payload: &models.Tx_Vote{Vote: &models.VoteEnvelope{
+		Proof: &models.Proof{Payload: &models.Proof_ZkSnark{ZkSnark: &models.ProofZkSNARK{}}},
+	}}}}
+	c.Assert(gs.Gas(zkVote), qt.Equals, uint64(40))
+	sendTokens := &vochaintx.Tx{Tx: &models.Tx{Payload: &models.Tx_SendTokens{SendTokens: &models.SendTokensTx{
+		Txtype: models.TxType_SEND_TOKENS,
+	}}}}
+	c.Assert(gs.Gas(sendTokens), qt.Equals, uint64(1))
+
+	c.Assert((&GasSchedule{BlockGasLimit: 0, DefaultTxGas: 1}).Validate(), qt.IsNotNil)
+	c.Assert((&GasSchedule{BlockGasLimit: 10, DefaultTxGas: 0}).Validate(), qt.IsNotNil)
+	c.Assert((&GasSchedule{BlockGasLimit: 10, DefaultTxGas: 11}).Validate(), qt.IsNotNil)
+	c.Assert((&GasSchedule{BlockGasLimit: 10, DefaultTxGas: 1,
+		TxGas: map[string]uint64{GasZkVote: 11}}).Validate(), qt.IsNotNil)
+	c.Assert((&GasSchedule{BlockGasLimit: 10, DefaultTxGas: 1,
+		TxGas: map[string]uint64{"NOT_A_TX": 1}}).Validate(), qt.IsNotNil)
+
+	s, err := New(db.TypePebble, t.TempDir())
+	c.Assert(err, qt.IsNil)
+	defer func() { _ = s.Close() }()
+
+	got, err := s.GasSchedule(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.IsNil)
+	c.Assert(s.SetGasSchedule(&GasSchedule{BlockGasLimit: 10}), qt.IsNotNil)
+	c.Assert(s.SetGasSchedule(gs), qt.IsNil)
+
+	// not visible until committed
+	got, err = s.GasSchedule(true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.IsNil)
+	testSaveState(t, s)
+	got, err = s.GasSchedule(true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.DeepEquals, gs)
+}
@@ -0,0 +1,69 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/tree/arbo"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+)
+
+// processKeySharesPrefix is the prefix hashed with the process id and the key
+// index to get the Extra tree key of the escrowed shares of a keykeeper key,
+// since both would not fit in a key of the tree.
+var processKeySharesPrefix = []byte("ksh/")
+
+// processKeySharesKey returns the Extra tree key of the shares of the private
+// key with keyIndex of the process.
+func processKeySharesKey(pid []byte, keyIndex uint32) []byte {
+	key := append(append([]byte{}, processKeySharesPrefix...), pid...)
+	return ethereum.HashRaw(append(key, byte(keyIndex)))
+}
+
+// SetProcessKeyShares stores the encrypted shares of the private key with
+// keyIndex of the process, sent by its keykeeper.
+func (v *State) SetProcessKeyShares(pid []byte, keyIndex uint32, shares []*vochaintx.ProcessKeyShare) error {
+	data, err := json.Marshal(shares)
+	if err != nil {
+		return err
+	}
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	if err := v.tx.DeepSet(processKeySharesKey(pid, keyIndex), data, StateTreeCfg(TreeExtra)); err != nil {
+		return err
+	}
+	log.Debugw("stored process key shares", "processId", fmt.Sprintf("%x", pid), "keyIndex", keyIndex,
+		"shares", len(shares))
+	return nil
+}
+
+// ProcessKeyShares returns the encrypted shares of the private key with keyIndex
+// of the process, or nil if its keykeeper did not send them.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) ProcessKeyShares(pid []byte, keyIndex uint32, committed bool) ([]*vochaintx.ProcessKeyShare, error) {
+	if !committed {
+		v.tx.RLock()
+		defer v.tx.RUnlock()
+	}
+	extraTree, err := v.mainTreeViewer(committed).SubTree(StateTreeCfg(TreeExtra))
+	if err != nil {
+		return nil, err
+	}
+	data, err := extraTree.Get(processKeySharesKey(pid, keyIndex))
+	if errors.Is(err, arbo.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var shares []*vochaintx.ProcessKeyShare
+	if err := json.Unmarshal(data, &shares); err != nil {
+		return nil, fmt.Errorf("cannot decode process key shares: %w", err)
+	}
+	return shares, nil
+}
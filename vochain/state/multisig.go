@@ -0,0 +1,145 @@
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/tree/arbo"
+)
+
+// MaxMultisigSigners is the maximum number of signers of a multisig account.
+const MaxMultisigSigners = 16
+
+// multisigPrefix is the prefix of the Extra tree keys that hold the signers of
+// each multisig account, followed by the account address.
+var multisigPrefix = []byte("msig/")
+
+// MultisigAccount holds the signers of a multisig account and the number of
+// their signatures (Threshold) required by its transactions.
+type MultisigAccount struct {
+	Signers   []common.Address `json:"signers"`
+	Threshold uint32           `json:"threshold"`
+}
+
+// Check checks that the signers are sorted and unique, and that the threshold
+// can be reached.
+func (m *MultisigAccount) Check() error {
+	if len(m.Signers) == 0 || len(m.Signers) > MaxMultisigSigners {
+		return fmt.Errorf("%w: the number of signers must be between 1 and %d", ErrMultisigInvalid, MaxMultisigSigners)
+	}
+	if m.Threshold == 0 || int(m.Threshold) > len(m.Signers) {
+		return fmt.Errorf("%w: the threshold must be between 1 and the number of signers", ErrMultisigInvalid)
+	}
+	for i := 1; i < len(m.Signers); i++ {
+		if bytes.Compare(m.Signers[i-1].Bytes(), m.Signers[i].Bytes()) >= 0 {
+			return fmt.Errorf("%w: the signers must be sorted and unique", ErrMultisigInvalid)
+		}
+	}
+	return nil
+}
+
+// Address returns the address of the multisig account, derived from its
+// signers and threshold.
+func (m *MultisigAccount) Address() common.Address {
+	data := []byte("vocdoni multisig account")
+	for _, signer := range m.Signers {
+		data = append(data, signer.Bytes()...)
+	}
+	data = binary.BigEndian.AppendUint32(data, m.Threshold)
+	return common.BytesToAddress(ethereum.HashRaw(data))
+}
+
+// VerifySignatures checks that the signatures of the message were made by at
+// least Threshold different signers of the account.
+func (m *MultisigAccount) VerifySignatures(message []byte, signatures [][]byte) error {
+	signed := make(map[common.Address]bool, len(signatures))
+	for _, signature := range signatures {
+		addr, err := ethereum.AddrFromSignature(message, signature)
+		if err != nil {
+			return fmt.Errorf("invalid multisig signature: %w", err)
+		}
+		if !slices.Contains(m.Signers, addr) {
+			return fmt.Errorf("%s is not a signer of the multisig account", addr.Hex())
+		}
+		if signed[addr] {
+			return fmt.Errorf("duplicated signature of %s", addr.Hex())
+		}
+		signed[addr] = true
+	}
+	if len(signed) < int(m.Threshold) {
+		return fmt.Errorf("not enough signatures for the multisig account, got %d want %d", len(signed), m.Threshold)
+	}
+	return nil
+}
+
+// MultisigAccount returns the multisig account with the given address, or
+// ErrMultisigNotFound if the account is not a multisig one.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) MultisigAccount(address common.Address, committed bool) (*MultisigAccount, error) {
+	if !committed {
+		v.tx.RLock()
+		defer v.tx.RUnlock()
+	}
+	extraTree, err := v.mainTreeViewer(committed).SubTree(StateTreeCfg(TreeExtra))
+	if err != nil {
+		return nil, err
+	}
+	data, err := extraTree.Get(multisigKey(address))
+	if errors.Is(err, arbo.ErrKeyNotFound) {
+		return nil, ErrMultisigNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &MultisigAccount{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("cannot decode multisig account: %w", err)
+	}
+	return m, nil
+}
+
+// CreateMultisigAccount creates the account of the multisig, with the address
+// returned by its Address method. It fails if the account already exists.
+func (v *State) CreateMultisigAccount(m *MultisigAccount) (common.Address, error) {
+	if err := m.Check(); err != nil {
+		return common.Address{}, err
+	}
+	address := m.Address()
+	acc, err := v.GetAccount(address, false)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if acc != nil {
+		return common.Address{}, ErrAccountAlreadyExists
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return common.Address{}, err
+	}
+	v.tx.Lock()
+	err = func() error {
+		extraTree, err := v.tx.DeepSubTree(StateTreeCfg(TreeExtra))
+		if err != nil {
+			return err
+		}
+		return extraTree.Set(multisigKey(address), data)
+	}()
+	v.tx.Unlock()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return address, v.CreateAccount(address, "", nil, 0)
+}
+
+// multisigKey returns the Extra tree key of the signers of a multisig account.
+func multisigKey(address common.Address) []byte {
+	return append(append([]byte{}, multisigPrefix...), address.Bytes()...)
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// MaxNullifierGroupSize is the maximum size of the nullifier group of a process.
+const MaxNullifierGroupSize = 32
+
+var (
+	// nullifierGroupScopePrefix is the prefix hashed with the organization and
+	// the nullifier group to get the scope of the nullifiers of the group.
+	nullifierGroupScopePrefix = []byte("nfs/")
+	// groupNullifierPrefix is the prefix hashed with the scope of a nullifier
+	// group and a nullifier to get the Extra tree key of the process where
+	// the nullifier was used.
+	groupNullifierPrefix = []byte("nfg/")
+)
+
+// NullifierGroupScope returns the scope of the nullifiers of the processes of
+// the organization with the given nullifier group.
+func NullifierGroupScope(entityID, group []byte) []byte {
+	scope := append(append([]byte{}, nullifierGroupScopePrefix...), entityID...)
+	return ethereum.HashRaw(append(scope, group...))
+}
+
+// ProcessNullifierScope returns the scope of the nullifiers of the anonymous
+// votes of the process, which is the process ID, or the scope of its nullifier
+// group if it is part of one, so that a voter gets the same nullifier in all
+// the processes of the group.
+func ProcessNullifierScope(process *models.Process) []byte {
+	group := vochaintx.ProcessModeNullifierGroup(process.GetMode())
+	if len(group) == 0 {
+		return process.ProcessId
+	}
+	return NullifierGroupScope(process.EntityId, group)
+}
+
+// ProcessNullifierElectionID returns the election ID the zk proofs of the
+// anonymous votes of the process are generated for, which is the hash of the
+// scope of its nullifiers.
+func ProcessNullifierElectionID(process *models.Process) []byte {
+	electionID := sha256.Sum256(ProcessNullifierScope(process))
+	return electionID[:]
+}
+
+// groupNullifierKey returns the Extra tree key of the nullifier of the scope of
+// a nullifier group.
+func groupNullifierKey(scope, nullifier []byte) []byte {
+	key := append(append([]byte{}, groupNullifierPrefix...), scope...)
+	return ethereum.HashRaw(append(key, nullifier...))
+}
+
+// SetGroupNullifier records that the nullifier of the scope of a nullifier
+// group has been used to vote in the process.
+func (v *State) SetGroupNullifier(scope, nullifier, pid []byte) error {
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	if err := v.tx.DeepSet(groupNullifierKey(scope, nullifier), pid, StateTreeCfg(TreeExtra)); err != nil {
+		return err
+	}
+	log.Debugw("stored group nullifier", "scope", fmt.Sprintf("%x", scope),
+		"nullifier", fmt.Sprintf("%x", nullifier), "processId", fmt.Sprintf("%x", pid))
+	return nil
+}
+
+// GroupNullifierProcess returns the ID of the process where the nullifier of
+// the scope of a nullifier group has been used to vote, or nil if it has not
+// been used yet.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) GroupNullifierProcess(scope, nullifier []byte, committed bool) ([]byte, error) {
+	return v.extraValue(groupNullifierKey(scope, nullifier), committed)
+}
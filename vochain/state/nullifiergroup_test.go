@@ -0,0 +1,66 @@
+package state
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/db"
+	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+func TestProcessNullifierScope(t *testing.T) {
+	c := qt.New(t)
+	eid := util.RandomBytes(20)
+	newProcess := func(group []byte) *models.Process {
+		mode := &models.ProcessMode{AutoStart: true}
+		vochaintx.SetProcessModeNullifierGroup(mode, group)
+		return &models.Process{ProcessId: util.RandomBytes(32), EntityId: eid, Mode: mode}
+	}
+
+	// without group, the scope is the process
+	p := newProcess(nil)
+	c.Assert(ProcessNullifierScope(p), qt.DeepEquals, p.ProcessId)
+
+	// the processes of a group share the scope
+	p1, p2 := newProcess([]byte("group")), newProcess([]byte("group"))
+	c.Assert(vochaintx.ProcessModeNullifierGroup(p1.Mode), qt.DeepEquals, []byte("group"))
+	c.Assert(ProcessNullifierScope(p1), qt.DeepEquals, ProcessNullifierScope(p2))
+	c.Assert(ProcessNullifierElectionID(p1), qt.DeepEquals, ProcessNullifierElectionID(p2))
+	c.Assert(ProcessNullifierScope(p1), qt.Not(qt.DeepEquals), ProcessNullifierScope(newProcess([]byte("other"))))
+	// but not with the groups of other entities
+	p2.EntityId = util.RandomBytes(20)
+	c.Assert(ProcessNullifierScope(p1), qt.Not(qt.DeepEquals), ProcessNullifierScope(p2))
+}
+
+func TestGroupNullifier(t *testing.T) {
+	c := qt.New(t)
+	s, err := New(db.TypePebble, t.TempDir())
+	c.Assert(err, qt.IsNil)
+	defer func() { _ = s.Close() }()
+
+	scope := NullifierGroupScope(util.RandomBytes(20), []byte("group"))
+	nullifier, pid := util.RandomBytes(32), util.RandomBytes(32)
+	got, err := s.GroupNullifierProcess(scope, nullifier, false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.IsNil)
+
+	c.Assert(s.SetGroupNullifier(scope, nullifier, pid), qt.IsNil)
+	got, err = s.GroupNullifierProcess(scope, nullifier, false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.DeepEquals, pid)
+	// the nullifier is only registered for its scope
+	got, err = s.GroupNullifierProcess(NullifierGroupScope(util.RandomBytes(20), []byte("group")), nullifier, false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.IsNil)
+
+	// not visible until committed
+	got, err = s.GroupNullifierProcess(scope, nullifier, true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.IsNil)
+	testSaveState(t, s)
+	got, err = s.GroupNullifierProcess(scope, nullifier, true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.DeepEquals, pid)
+}
@@ -106,6 +106,55 @@ func (v *State) Process(pid []byte, committed bool) (*models.Process, error) {
 	return getProcess(v.mainTreeViewer(committed), pid)
 }
 
+// ProcessProof holds the merkle proofs that tie a process leaf to the state root.
+type ProcessProof struct {
+	// StateRoot is the root of the main tree.
+	StateRoot []byte
+	// ProcessesRoot is the root of the Processes tree, which is the leaf
+	// value of the Processes key in the main tree.
+	ProcessesRoot []byte
+	// ProcessesProof is the proof of ProcessesRoot in the main tree.
+	ProcessesProof []byte
+	// Process is the leaf value of the process in the Processes tree
+	// (a marshaled models.StateDBProcess).
+	Process []byte
+	// ProcessProof is the proof of Process in the Processes tree.
+	ProcessProof []byte
+}
+
+// ProcessProof returns the merkle proofs of the process pid in the last committed
+// state. Note that the state root of the proofs is the AppHash of the next block.
+func (v *State) ProcessProof(pid []byte) (*ProcessProof, error) {
+	mainTree := v.MainTreeView()
+	stateRoot, err := mainTree.Root()
+	if err != nil {
+		return nil, err
+	}
+	processesCfg := StateTreeCfg(TreeProcess)
+	processesRoot, processesProof, err := mainTree.GenProof(processesCfg.Key())
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate processes tree proof: %w", err)
+	}
+	processesTree, err := mainTree.SubTree(processesCfg)
+	if err != nil {
+		return nil, err
+	}
+	process, processProof, err := processesTree.GenProof(pid)
+	if err != nil {
+		if _, err := processesTree.Get(pid); errors.Is(err, arbo.ErrKeyNotFound) {
+			return nil, ErrProcessNotFound
+		}
+		return nil, fmt.Errorf("cannot generate process proof: %w", err)
+	}
+	return &ProcessProof{
+		StateRoot:      stateRoot,
+		ProcessesRoot:  processesRoot,
+		ProcessesProof: processesProof,
+		Process:        process,
+		ProcessProof:   processProof,
+	}, nil
+}
+
 // CountProcesses returns the overall number of processes the vochain has
 func (v *State) CountProcesses(committed bool) (uint64, error) {
 	// TODO: Once statedb.TreeView.Size() works, replace this by that.
@@ -267,6 +316,13 @@ func (v *State) SetProcessStatus(pid []byte, newstatus models.ProcessStatus, com
 		for _, l := range v.eventListeners {
 			l.OnProcessStatusChange(process.ProcessId, newstatus, v.txCounter.Load())
 		}
+		// the votes of a canceled process are not counted, refund all
+		// their deposits
+		if newstatus == models.ProcessStatus_CANCELED {
+			if _, _, err := v.SettleVoteDeposits(process.ProcessId, nil); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -398,6 +454,14 @@ func (v *State) SetProcessCensus(pid, censusRoot []byte, censusURI string, censu
 		}
 	}
 
+	history, err := v.ProcessCensusHistory(pid, false)
+	if err != nil {
+		return fmt.Errorf("cannot get census history: %w", err)
+	}
+	if len(history) > MaxProcessCensusUpdates {
+		return fmt.Errorf("cannot update census, max %d updates reached", MaxProcessCensusUpdates)
+	}
+
 	// commit the change
 	if commit {
 		if censusRoot != nil {
@@ -412,6 +476,9 @@ func (v *State) SetProcessCensus(pid, censusRoot []byte, censusURI string, censu
 		if err := v.UpdateProcess(process, process.ProcessId); err != nil {
 			return err
 		}
+		if err := v.setProcessCensusHistory(history, process); err != nil {
+			return fmt.Errorf("cannot set census history: %w", err)
+		}
 		for _, l := range v.eventListeners {
 			l.OnCensusUpdate(process.ProcessId, process.CensusRoot, censusURI, censusSize)
 		}
@@ -0,0 +1,330 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/statedb"
+	"go.vocdoni.io/dvote/tree/arbo"
+)
+
+// MaxRecoveryGuardians is the maximum number of guardians of an account.
+const MaxRecoveryGuardians = 16
+
+var (
+	// recoveryGuardiansPrefix is the prefix of the Extra tree keys that hold
+	// the guardians of each account, followed by the account address.
+	recoveryGuardiansPrefix = []byte("rgrd/")
+	// accountRecoveryPrefix is the prefix of the Extra tree keys that hold the
+	// pending recovery of each account, followed by the account address.
+	accountRecoveryPrefix = []byte("rrec/")
+	// accountOwnerPrefix is the prefix of the Extra tree keys that hold the
+	// owner of each account whose key was rotated, followed by the account
+	// address.
+	accountOwnerPrefix = []byte("rown/")
+)
+
+// The kinds of AccountRecoveryEvent.
+const (
+	// AccountRecoveryGuardiansSet is the update of the guardians of an account,
+	// which also cancels its pending recovery.
+	AccountRecoveryGuardiansSet = "guardians"
+	// AccountRecoveryApproved is the approval of a new owner by a guardian.
+	AccountRecoveryApproved = "approval"
+	// AccountRecoveryExecuted is the rotation of the owner key of an account.
+	AccountRecoveryExecuted = "execution"
+)
+
+// RecoveryGuardians holds the guardians of an account, Quorum of which can
+// rotate its owner key once Timelock blocks have elapsed since they approved it.
+type RecoveryGuardians struct {
+	Guardians []common.Address `json:"guardians"`
+	Quorum    uint32           `json:"quorum"`
+	Timelock  uint32           `json:"timelock"`
+}
+
+// Check checks that the guardians are sorted and unique, that the account is
+// not one of them, that the quorum can be reached and that the timelock is set.
+func (g *RecoveryGuardians) Check(account common.Address) error {
+	if len(g.Guardians) == 0 || len(g.Guardians) > MaxRecoveryGuardians {
+		return fmt.Errorf("%w: the number of guardians must be between 1 and %d", ErrRecoveryInvalid, MaxRecoveryGuardians)
+	}
+	if g.Quorum == 0 || int(g.Quorum) > len(g.Guardians) {
+		return fmt.Errorf("%w: the quorum must be between 1 and the number of guardians", ErrRecoveryInvalid)
+	}
+	if g.Timelock == 0 {
+		return fmt.Errorf("%w: the timelock cannot be zero", ErrRecoveryInvalid)
+	}
+	for i := 1; i < len(g.Guardians); i++ {
+		if bytes.Compare(g.Guardians[i-1].Bytes(), g.Guardians[i].Bytes()) >= 0 {
+			return fmt.Errorf("%w: the guardians must be sorted and unique", ErrRecoveryInvalid)
+		}
+	}
+	if slices.Contains(g.Guardians, account) {
+		return fmt.Errorf("%w: the account cannot be its own guardian", ErrRecoveryInvalid)
+	}
+	return nil
+}
+
+// RecoveryApproval is the new owner of an account approved by one of its
+// guardians.
+type RecoveryApproval struct {
+	Guardian common.Address `json:"guardian"`
+	NewOwner common.Address `json:"newOwner"`
+}
+
+// AccountRecovery is the pending recovery of an account: the new owners
+// approved by its guardians and, once a quorum of them approve the same one,
+// the height from which the recovery can be executed.
+type AccountRecovery struct {
+	Approvals []RecoveryApproval `json:"approvals"`
+	// NewOwner is the new owner approved by the quorum of guardians, nil if
+	// there is none yet.
+	NewOwner         *common.Address `json:"newOwner,omitempty"`
+	ExecutableHeight uint32          `json:"executableHeight,omitempty"`
+}
+
+// AccountRecoveryEvent is a change of the recovery of an account, see the
+// AccountRecovery kinds.
+type AccountRecoveryEvent struct {
+	Kind string
+	// Guardians are the new guardians of a AccountRecoveryGuardiansSet event,
+	// nil if they were removed.
+	Guardians *RecoveryGuardians
+	// Guardian is the approver of a AccountRecoveryApproved event.
+	Guardian common.Address
+	// NewOwner is the new owner approved or executed.
+	NewOwner common.Address
+}
+
+// RecoveryGuardians returns the guardians of the account, or nil if it has none.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) RecoveryGuardians(account common.Address, committed bool) (*RecoveryGuardians, error) {
+	b, err := v.extraValue(recoveryKey(recoveryGuardiansPrefix, account), committed)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	g := &RecoveryGuardians{}
+	if err := json.Unmarshal(b, g); err != nil {
+		return nil, fmt.Errorf("cannot decode recovery guardians: %w", err)
+	}
+	return g, nil
+}
+
+// AccountRecovery returns the pending recovery of the account, or nil if there
+// is none.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) AccountRecovery(account common.Address, committed bool) (*AccountRecovery, error) {
+	b, err := v.extraValue(recoveryKey(accountRecoveryPrefix, account), committed)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	r := &AccountRecovery{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, fmt.Errorf("cannot decode account recovery: %w", err)
+	}
+	return r, nil
+}
+
+// AccountOwner returns the owner of the account whose key was rotated by its
+// guardians, or nil if it was never rotated. The transactions of such accounts
+// must be signed by their owner, see vochaintx.NewRecoveredAccountSignedTx.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) AccountOwner(account common.Address, committed bool) (*common.Address, error) {
+	b, err := v.extraValue(recoveryKey(accountOwnerPrefix, account), committed)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	if len(b) != common.AddressLength {
+		return nil, fmt.Errorf("cannot decode account owner: invalid length %d", len(b))
+	}
+	owner := common.BytesToAddress(b)
+	return &owner, nil
+}
+
+// SetRecoveryGuardians sets the guardians of the account, or removes them if
+// guardians is nil. The pending recovery of the account, if any, is cancelled.
+func (v *State) SetRecoveryGuardians(account common.Address, guardians *RecoveryGuardians) error {
+	var data []byte
+	if guardians != nil {
+		if err := guardians.Check(account); err != nil {
+			return err
+		}
+		var err error
+		if data, err = json.Marshal(guardians); err != nil {
+			return err
+		}
+	}
+	err := v.updateExtra(func(extraTree *statedb.TreeUpdate) error {
+		if err := delExtra(extraTree, recoveryKey(accountRecoveryPrefix, account)); err != nil {
+			return err
+		}
+		if data == nil {
+			return delExtra(extraTree, recoveryKey(recoveryGuardiansPrefix, account))
+		}
+		return extraTree.Set(recoveryKey(recoveryGuardiansPrefix, account), data)
+	})
+	if err != nil {
+		return err
+	}
+	log.Debugw("set recovery guardians", "account", account.Hex(), "removed", guardians == nil)
+	for _, l := range v.eventListeners {
+		l.OnAccountRecovery(account.Bytes(), &AccountRecoveryEvent{
+			Kind:      AccountRecoveryGuardiansSet,
+			Guardians: guardians,
+		})
+	}
+	return nil
+}
+
+// ApproveAccountRecovery registers the approval of newOwner by a guardian of the
+// account, replacing the previous approval of the guardian. Once a quorum of
+// guardians approve the same new owner, the recovery can be executed after the
+// timelock of the account. Returns the updated pending recovery.
+func (v *State) ApproveAccountRecovery(account, guardian, newOwner common.Address) (*AccountRecovery, error) {
+	guardians, err := v.RecoveryGuardians(account, false)
+	if err != nil {
+		return nil, err
+	}
+	if guardians == nil {
+		return nil, fmt.Errorf("%w: %s has no guardians", ErrRecoveryNotFound, account.Hex())
+	}
+	if !slices.Contains(guardians.Guardians, guardian) {
+		return nil, fmt.Errorf("%s is not a guardian of %s", guardian.Hex(), account.Hex())
+	}
+	recovery, err := v.AccountRecovery(account, false)
+	if err != nil {
+		return nil, err
+	}
+	if recovery == nil {
+		recovery = &AccountRecovery{}
+	}
+	recovery.Approvals = slices.DeleteFunc(recovery.Approvals, func(a RecoveryApproval) bool {
+		return a.Guardian == guardian
+	})
+	recovery.Approvals = append(recovery.Approvals, RecoveryApproval{Guardian: guardian, NewOwner: newOwner})
+	slices.SortFunc(recovery.Approvals, func(a, b RecoveryApproval) int {
+		return bytes.Compare(a.Guardian.Bytes(), b.Guardian.Bytes())
+	})
+
+	// the timelock starts when the quorum is reached, and restarts if the
+	// approved new owner changes
+	var approved *common.Address
+	for _, a := range recovery.Approvals {
+		if recovery.approvals(a.NewOwner) >= int(guardians.Quorum) {
+			approved = &a.NewOwner
+			break
+		}
+	}
+	switch {
+	case approved == nil:
+		recovery.NewOwner, recovery.ExecutableHeight = nil, 0
+	case recovery.NewOwner == nil || *recovery.NewOwner != *approved:
+		recovery.NewOwner = approved
+		recovery.ExecutableHeight = v.CurrentHeight() + guardians.Timelock
+	}
+	data, err := json.Marshal(recovery)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.updateExtra(func(extraTree *statedb.TreeUpdate) error {
+		return extraTree.Set(recoveryKey(accountRecoveryPrefix, account), data)
+	}); err != nil {
+		return nil, err
+	}
+	log.Debugw("approve account recovery", "account", account.Hex(), "guardian", guardian.Hex(),
+		"newOwner", newOwner.Hex(), "executableHeight", recovery.ExecutableHeight)
+	for _, l := range v.eventListeners {
+		l.OnAccountRecovery(account.Bytes(), &AccountRecoveryEvent{
+			Kind:     AccountRecoveryApproved,
+			Guardian: guardian,
+			NewOwner: newOwner,
+		})
+	}
+	return recovery, nil
+}
+
+// ExecuteAccountRecovery rotates the owner key of the account to the new owner
+// approved by its guardians, once the timelock has elapsed. The pending
+// recovery is removed, while the guardians are kept. Returns the new owner.
+func (v *State) ExecuteAccountRecovery(account common.Address) (common.Address, error) {
+	recovery, err := v.AccountRecovery(account, false)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if recovery == nil || recovery.NewOwner == nil {
+		return common.Address{}, fmt.Errorf("%w: no new owner approved for %s", ErrRecoveryNotFound, account.Hex())
+	}
+	if height := v.CurrentHeight(); height < recovery.ExecutableHeight {
+		return common.Address{}, fmt.Errorf("the recovery of %s cannot be executed until height %d, current %d",
+			account.Hex(), recovery.ExecutableHeight, height)
+	}
+	newOwner := *recovery.NewOwner
+	if err := v.updateExtra(func(extraTree *statedb.TreeUpdate) error {
+		if err := delExtra(extraTree, recoveryKey(accountRecoveryPrefix, account)); err != nil {
+			return err
+		}
+		// recovering the original key of the account restores it
+		if newOwner == account {
+			return delExtra(extraTree, recoveryKey(accountOwnerPrefix, account))
+		}
+		return extraTree.Set(recoveryKey(accountOwnerPrefix, account), newOwner.Bytes())
+	}); err != nil {
+		return common.Address{}, err
+	}
+	log.Infow("account owner key rotated", "account", account.Hex(), "newOwner", newOwner.Hex())
+	for _, l := range v.eventListeners {
+		l.OnAccountRecovery(account.Bytes(), &AccountRecoveryEvent{
+			Kind:     AccountRecoveryExecuted,
+			NewOwner: newOwner,
+		})
+	}
+	return newOwner, nil
+}
+
+// approvals returns the number of guardians that approved the new owner.
+func (r *AccountRecovery) approvals(newOwner common.Address) int {
+	n := 0
+	for _, a := range r.Approvals {
+		if a.NewOwner == newOwner {
+			n++
+		}
+	}
+	return n
+}
+
+// updateExtra calls f with the Extra tree of the currently open StateDB
+// transaction.
+func (v *State) updateExtra(f func(extraTree *statedb.TreeUpdate) error) error {
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	extraTree, err := v.tx.DeepSubTree(StateTreeCfg(TreeExtra))
+	if err != nil {
+		return err
+	}
+	return f(extraTree)
+}
+
+// delExtra deletes the key of the Extra tree, if it exists.
+func delExtra(extraTree *statedb.TreeUpdate, key []byte) error {
+	if err := extraTree.Del(key); err != nil && !errors.Is(err, arbo.ErrKeyNotFound) {
+		return err
+	}
+	return nil
+}
+
+// recoveryKey returns the Extra tree key of the account with the given prefix.
+func recoveryKey(prefix []byte, account common.Address) []byte {
+	return append(append([]byte{}, prefix...), account.Bytes()...)
+}
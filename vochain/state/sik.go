@@ -86,7 +86,7 @@ func (v *State) SetAddressSIK(address common.Address, newSIK SIK) error {
 		if err := siksTree.Add(address.Bytes(), newSIK); err != nil {
 			return fmt.Errorf("%w: %w", ErrSIKSet, err)
 		}
-		return nil
+		return v.setSIKExpiration(address.Bytes())
 	}
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrSIKGet, err)
@@ -102,7 +102,7 @@ func (v *State) SetAddressSIK(address common.Address, newSIK SIK) error {
 	if err := siksTree.Set(address.Bytes(), newSIK); err != nil {
 		return fmt.Errorf("%w: %w", ErrSIKSet, err)
 	}
-	return nil
+	return v.setSIKExpiration(address.Bytes())
 }
 
 // InvalidateSIK function removes logically the registered SIK for the address
@@ -130,7 +130,8 @@ func (v *State) InvalidateSIK(address common.Address) error {
 	if err := siksTree.Set(address.Bytes(), invalidatedSIK); err != nil {
 		return fmt.Errorf("%w: %w", ErrSIKDelete, err)
 	}
-	return nil
+	// the invalidated SIK is kept until it is replaced, it does not expire
+	return v.delSIKExpiration(address.Bytes())
 }
 
 // ValidSIKRoots method returns the current valid SIK roots that are cached in
@@ -362,10 +363,13 @@ func (v *State) PurgeSIKsByElection(pid []byte) error {
 		return fmt.Errorf("%w: %w", ErrSIKDelete, err)
 	}
 	for _, address := range toPurge {
-		// remove the SIK by the address
-		if err := siksTree.Del(address); err != nil {
+		// remove the SIK by the address, unless it already expired
+		if err := siksTree.Del(address); err != nil && !errors.Is(err, arbo.ErrKeyNotFound) {
 			return fmt.Errorf("%w: %w", ErrSIKDelete, err)
 		}
+		if err := v.delSIKExpiration(address); err != nil {
+			return err
+		}
 		// remove the relation between process and address
 		if err := sikNoStateDB.Delete(toPrefixKey(pid, address)); err != nil {
 			return fmt.Errorf("%w: %w", ErrSIKDelete, err)
@@ -177,6 +177,56 @@ func TestAssignSIKToElectionAndPurge(t *testing.T) {
 	c.Assert(err, qt.IsNotNil, qt.Commentf("SIK should be deleted"))
 }
 
+func TestSIKExpiration(t *testing.T) {
+	c := qt.New(t)
+	s, err := New(db.TypePebble, t.TempDir())
+	qt.Assert(t, err, qt.IsNil)
+	addr1 := common.HexToAddress("0xF3668000B66c61aAa08aBC559a8C78Ae7E007C2e")
+	addr2 := common.HexToAddress("0x5B38Da6a701c568545dCfcB03FcB875f56beddC4")
+	sik, _ := hex.DecodeString("3a7806f4e0b5bda625d465abf5639ba42ac9b91bafea3b800a4a")
+
+	// without validity the SIKs do not expire
+	c.Assert(s.SetAddressSIK(addr1, sik), qt.IsNil)
+	expiration, err := s.SIKExpiration(addr1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(expiration, qt.Equals, uint32(0))
+
+	c.Assert(s.SetSIKValidity(10), qt.IsNil)
+	validity, err := s.SIKValidity()
+	c.Assert(err, qt.IsNil)
+	c.Assert(validity, qt.Equals, uint32(10))
+	s.SetHeight(5)
+	c.Assert(s.SetAddressSIK(addr2, sik), qt.IsNil)
+	expiration, err = s.SIKExpiration(addr2)
+	c.Assert(err, qt.IsNil)
+	c.Assert(expiration, qt.Equals, uint32(15))
+
+	// the SIK is kept until its expiration height
+	c.Assert(s.PurgeExpiredSIKs(14), qt.IsNil)
+	_, err = s.SIKFromAddress(addr2)
+	c.Assert(err, qt.IsNil)
+	c.Assert(s.PurgeExpiredSIKs(15), qt.IsNil)
+	_, err = s.SIKFromAddress(addr2)
+	c.Assert(err, qt.ErrorIs, ErrSIKNotFound)
+	_, err = s.SIKExpiration(addr2)
+	c.Assert(err, qt.ErrorIs, ErrSIKNotFound)
+	_, err = s.SIKFromAddress(addr1)
+	c.Assert(err, qt.IsNil)
+
+	// a new SIK gets a new expiration, replacing the previous one
+	c.Assert(s.SetAddressSIK(addr2, sik), qt.IsNil)
+	c.Assert(s.InvalidateSIK(addr2), qt.IsNil)
+	s.SetHeight(20)
+	c.Assert(s.SetAddressSIK(addr2, sik), qt.IsNil)
+	c.Assert(s.PurgeExpiredSIKs(29), qt.IsNil)
+	expiration, err = s.SIKExpiration(addr2)
+	c.Assert(err, qt.IsNil)
+	c.Assert(expiration, qt.Equals, uint32(30))
+	c.Assert(s.PurgeExpiredSIKs(40), qt.IsNil)
+	_, err = s.SIKFromAddress(addr2)
+	c.Assert(err, qt.ErrorIs, ErrSIKNotFound)
+}
+
 func Test_heightEncoding(t *testing.T) {
 	c := qt.New(t)
 	height := uint32(3498223)
@@ -0,0 +1,175 @@
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/db"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/tree/arbo"
+)
+
+var (
+	// sikExpirationDBPrefix is the NoState prefix of the expiration height of
+	// the SIK of each address.
+	sikExpirationDBPrefix = []byte("sikexp/")
+	// sikExpiringDBPrefix is the NoState prefix of the SIKs indexed by their
+	// expiration height, encoded in big-endian before the address so that they
+	// are iterated in expiration order.
+	sikExpiringDBPrefix = []byte("sikexph/")
+	// sikValidityKey is the Extra tree key of the validity of the SIKs.
+	sikValidityKey = []byte("sikValidity")
+)
+
+// SetSIKValidity sets the number of blocks a SIK stays in the state since it
+// is set, after which it is purged by PurgeExpiredSIKs. Zero means that the
+// SIKs do not expire. It only applies to the SIKs set afterwards.
+func (v *State) SetSIKValidity(blocks uint32) error {
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	return v.tx.DeepSet(sikValidityKey, []byte(strconv.FormatUint(uint64(blocks), 10)), StateTreeCfg(TreeExtra))
+}
+
+// SIKValidity returns the number of blocks a SIK stays in the state since it is
+// set, zero if the SIKs do not expire.
+func (v *State) SIKValidity() (uint32, error) {
+	v.tx.RLock()
+	defer v.tx.RUnlock()
+	return v.sikValidity()
+}
+
+// sikValidity is the unlocked version of SIKValidity.
+func (v *State) sikValidity() (uint32, error) {
+	blocks, err := v.tx.DeepGet(sikValidityKey, StateTreeCfg(TreeExtra))
+	if err != nil {
+		if errors.Is(err, arbo.ErrKeyNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	validity, err := strconv.ParseUint(string(blocks), 10, 32)
+	return uint32(validity), err
+}
+
+// SIKExpiration returns the height at which the SIK of the address expires,
+// or zero if it does not expire. It returns ErrSIKNotFound if the address has
+// no SIK.
+func (v *State) SIKExpiration(address common.Address) (uint32, error) {
+	v.tx.RLock()
+	defer v.tx.RUnlock()
+	siksTree, err := v.tx.DeepSubTree(StateTreeCfg(TreeSIK))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrSIKSubTree, err)
+	}
+	if _, err := siksTree.Get(address.Bytes()); err != nil {
+		if errors.Is(err, arbo.ErrKeyNotFound) {
+			return 0, fmt.Errorf("%w: %w", ErrSIKNotFound, err)
+		}
+		return 0, fmt.Errorf("%w: %w", ErrSIKGet, err)
+	}
+	rawHeight, err := v.NoState(false).Get(toPrefixKey(sikExpirationDBPrefix, address.Bytes()))
+	if err != nil {
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%w: %w", ErrSIKGet, err)
+	}
+	return binary.LittleEndian.Uint32(rawHeight), nil
+}
+
+// setSIKExpiration schedules the expiration of the SIK of the address, which
+// has just been set, replacing the previous one. Assumes that the State.tx
+// lock is held for writing.
+func (v *State) setSIKExpiration(address []byte) error {
+	if err := v.delSIKExpiration(address); err != nil {
+		return err
+	}
+	validity, err := v.sikValidity()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSIKGet, err)
+	}
+	if validity == 0 {
+		return nil
+	}
+	expiration := v.CurrentHeight() + validity
+	sikNoStateDB := v.NoState(false)
+	rawHeight := binary.LittleEndian.AppendUint32(nil, expiration)
+	if err := sikNoStateDB.Set(toPrefixKey(sikExpirationDBPrefix, address), rawHeight); err != nil {
+		return fmt.Errorf("%w: %w", ErrSIKSet, err)
+	}
+	if err := sikNoStateDB.Set(sikExpiringKey(expiration, address), nil); err != nil {
+		return fmt.Errorf("%w: %w", ErrSIKSet, err)
+	}
+	return nil
+}
+
+// delSIKExpiration removes the scheduled expiration of the SIK of the
+// address, if any. Assumes that the State.tx lock is held for writing.
+func (v *State) delSIKExpiration(address []byte) error {
+	sikNoStateDB := v.NoState(false)
+	key := toPrefixKey(sikExpirationDBPrefix, address)
+	rawHeight, err := sikNoStateDB.Get(key)
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSIKGet, err)
+	}
+	if err := sikNoStateDB.Delete(key); err != nil {
+		return fmt.Errorf("%w: %w", ErrSIKDelete, err)
+	}
+	expiringKey := sikExpiringKey(binary.LittleEndian.Uint32(rawHeight), address)
+	if err := sikNoStateDB.Delete(expiringKey); err != nil {
+		return fmt.Errorf("%w: %w", ErrSIKDelete, err)
+	}
+	return nil
+}
+
+// PurgeExpiredSIKs removes from the SIK tree the SIKs which expire at the
+// given height or before, so that the tree does not grow unbounded with the
+// SIKs of one-off voters. It must be called on every block, before the state
+// is committed.
+func (v *State) PurgeExpiredSIKs(height uint32) error {
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	siksTree, err := v.tx.DeepSubTree(StateTreeCfg(TreeSIK))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSIKSubTree, err)
+	}
+	sikNoStateDB := v.NoState(false)
+	// the keys are sorted by expiration height, so stop at the first one which
+	// has not expired yet
+	var toPurge [][]byte
+	if err := sikNoStateDB.Iterate(sikExpiringDBPrefix, func(key, _ []byte) bool {
+		if binary.BigEndian.Uint32(key) > height {
+			return false
+		}
+		toPurge = append(toPurge, bytes.Clone(key))
+		return true
+	}); err != nil {
+		return fmt.Errorf("%w: %w", ErrSIKIterate, err)
+	}
+	for _, key := range toPurge {
+		address := key[4:]
+		if err := siksTree.Del(address); err != nil && !errors.Is(err, arbo.ErrKeyNotFound) {
+			return fmt.Errorf("%w: %w", ErrSIKDelete, err)
+		}
+		if err := v.delSIKExpiration(address); err != nil {
+			return err
+		}
+		log.Debugw("expired SIK purged", "address", hex.EncodeToString(address), "height", height)
+	}
+	return nil
+}
+
+// sikExpiringKey returns the NoState key of the SIK of the address in the
+// index by expiration height.
+func sikExpiringKey(expiration uint32, address []byte) []byte {
+	key := binary.BigEndian.AppendUint32(bytes.Clone(sikExpiringDBPrefix), expiration)
+	return append(key, address...)
+}
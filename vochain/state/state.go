@@ -61,6 +61,9 @@ type State struct {
 	DisableVoteCache  atomic.Bool
 	voteCache         *lru.Cache[string, *Vote]
 	txCounter         atomic.Int32
+	// costTxCounter counts the transactions of the block which paid a cost,
+	// see UpdateFeeMultiplier
+	costTxCounter atomic.Int32
 	// currentHeight is the height of the current started block
 	currentHeight atomic.Uint32
 	// chainID identifies the blockchain
@@ -71,6 +74,11 @@ type State struct {
 
 	validSIKRoots    [][]byte
 	mtxValidSIKRoots sync.Mutex
+
+	// scheduledForks are the fork heights scheduled by the node release, see
+	// ScheduleForks
+	scheduledForks    map[string]uint32
+	mtxScheduledForks sync.RWMutex
 }
 
 // NewState creates a new State
@@ -305,6 +313,7 @@ func (v *State) Save() ([]byte, error) {
 			return fmt.Errorf("cannot begin statedb tx: %w", err)
 		}
 		v.txCounter.Store(0)
+		v.costTxCounter.Store(0)
 		return nil
 	}()
 	if err != nil {
@@ -335,6 +344,7 @@ func (v *State) Rollback() {
 		return
 	}
 	v.txCounter.Store(0)
+	v.costTxCounter.Store(0)
 }
 
 // Close closes the vochain StateDB.
@@ -407,3 +417,13 @@ func (v *State) TxCounterAdd() {
 func (v *State) TxCounter() int32 {
 	return v.txCounter.Load()
 }
+
+// CostTxCounterAdd adds to the atomic counter of the transactions which paid a cost
+func (v *State) CostTxCounterAdd() {
+	v.costTxCounter.Add(1)
+}
+
+// CostTxCounter returns the current count of the transactions which paid a cost
+func (v *State) CostTxCounter() int32 {
+	return v.costTxCounter.Load()
+}
@@ -182,6 +182,7 @@ type Listener struct {
 
 func (*Listener) OnVote(_ *Vote, _ int32)                                         {}
 func (*Listener) OnNewTx(_ *vochaintx.Tx, _ uint32, _ int32)                      {}
+func (*Listener) OnTxReceipt(_ *vochaintx.Tx, _ *vochaintx.Receipt, _ int32)      {}
 func (*Listener) OnProcess(_ *models.Process, _ int32)                            {}
 func (*Listener) OnProcessStatusChange(_ []byte, _ models.ProcessStatus, _ int32) {}
 func (*Listener) OnProcessDurationChange(_ []byte, _ uint32, _ int32)             {}
@@ -193,6 +194,9 @@ func (*Listener) OnCensusUpdate(_, _ []byte, _ string, _ uint64)
 func (*Listener) OnSetAccount(_ []byte, _ *Account)                               {}
 func (*Listener) OnTransferTokens(_ *vochaintx.TokenTransfer)                     {}
 func (*Listener) OnSpendTokens(_ []byte, _ models.TxType, _ uint64, _ string)     {}
+func (*Listener) OnSetAccountKV(_ []byte, _ string, _ []byte)                     {}
+func (*Listener) OnVoteDelegation(_, _ []byte, _ *VoteDelegation)                 {}
+func (*Listener) OnAccountRecovery(_ []byte, _ *AccountRecoveryEvent)             {}
 func (l *Listener) OnProcessesStart(pids [][]byte) {
 	l.processStart = append(l.processStart, pids)
 }
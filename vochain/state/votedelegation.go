@@ -0,0 +1,190 @@
+package state
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/tree/arbo"
+)
+
+var (
+	// voteDelegationPrefix is the prefix hashed with the process id and the
+	// address of a delegator to get the Extra tree key of its delegation.
+	voteDelegationPrefix = []byte("vdlg/")
+	// delegatedVoteWeightPrefix is the prefix hashed with the process id and the
+	// address of a delegate to get the Extra tree key of the weight delegated
+	// to it.
+	delegatedVoteWeightPrefix = []byte("vdlw/")
+)
+
+// VoteDelegation is the delegation of the vote weight of a census member of a
+// process to another member, see vochaintx.ProcessModeVoteDelegation.
+type VoteDelegation struct {
+	Delegate common.Address
+	// Weight is the census weight of the delegator when the delegation was
+	// registered.
+	Weight *big.Int
+}
+
+// DelegatedVoteWeight is the vote weight delegated to a census member of a
+// process, which is added to the weight of its vote.
+type DelegatedVoteWeight struct {
+	Delegators uint32
+	Weight     *big.Int
+}
+
+// voteDelegationKey returns the Extra tree key of the delegation of the
+// delegator in the process.
+func voteDelegationKey(pid []byte, delegator common.Address) []byte {
+	key := append(append([]byte{}, voteDelegationPrefix...), pid...)
+	return ethereum.HashRaw(append(key, delegator.Bytes()...))
+}
+
+// delegatedVoteWeightKey returns the Extra tree key of the weight delegated to
+// the delegate in the process.
+func delegatedVoteWeightKey(pid []byte, delegate common.Address) []byte {
+	key := append(append([]byte{}, delegatedVoteWeightPrefix...), pid...)
+	return ethereum.HashRaw(append(key, delegate.Bytes()...))
+}
+
+// VoteDelegation returns the delegation of the vote weight of the delegator in
+// the process, or nil if it did not delegate it.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) VoteDelegation(pid []byte, delegator common.Address, committed bool) (*VoteDelegation, error) {
+	b, err := v.extraValue(voteDelegationKey(pid, delegator), committed)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return decodeVoteDelegation(b)
+}
+
+// DelegatedVoteWeight returns the vote weight delegated to the delegate in the
+// process, which is zero if nobody delegated to it.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) DelegatedVoteWeight(pid []byte, delegate common.Address, committed bool) (*DelegatedVoteWeight, error) {
+	b, err := v.extraValue(delegatedVoteWeightKey(pid, delegate), committed)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDelegatedVoteWeight(b)
+}
+
+// SetVoteDelegation registers the delegation of the vote weight of the
+// delegator in the process, replacing the previous one, or revokes it if
+// delegation is nil. The weight delegated to the previous and the new delegate
+// is updated accordingly.
+func (v *State) SetVoteDelegation(pid []byte, delegator common.Address, delegation *VoteDelegation) error {
+	v.tx.Lock()
+	defer v.tx.Unlock()
+	extraTree, err := v.tx.DeepSubTree(StateTreeCfg(TreeExtra))
+	if err != nil {
+		return err
+	}
+	get := func(key []byte) ([]byte, error) {
+		b, err := extraTree.Get(key)
+		if errors.Is(err, arbo.ErrKeyNotFound) {
+			return nil, nil
+		}
+		return b, err
+	}
+	// addWeight adds the weight of a delegator to the delegated weight of the
+	// delegate, or subtracts it if remove is true
+	addWeight := func(delegate common.Address, weight *big.Int, remove bool) error {
+		key := delegatedVoteWeightKey(pid, delegate)
+		b, err := get(key)
+		if err != nil {
+			return err
+		}
+		delegated, err := decodeDelegatedVoteWeight(b)
+		if err != nil {
+			return err
+		}
+		if remove {
+			if delegated.Delegators == 0 {
+				return fmt.Errorf("no weight delegated to %s", delegate.Hex())
+			}
+			delegated.Delegators--
+			delegated.Weight.Sub(delegated.Weight, weight)
+		} else {
+			delegated.Delegators++
+			delegated.Weight.Add(delegated.Weight, weight)
+		}
+		if delegated.Delegators == 0 {
+			return extraTree.Del(key)
+		}
+		return extraTree.Set(key, append(binary.BigEndian.AppendUint32(nil, delegated.Delegators),
+			delegated.Weight.Bytes()...))
+	}
+
+	key := voteDelegationKey(pid, delegator)
+	b, err := get(key)
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		previous, err := decodeVoteDelegation(b)
+		if err != nil {
+			return err
+		}
+		if err := addWeight(previous.Delegate, previous.Weight, true); err != nil {
+			return err
+		}
+	}
+	if delegation == nil {
+		if b == nil {
+			return nil
+		}
+		err = extraTree.Del(key)
+	} else {
+		if err := addWeight(delegation.Delegate, delegation.Weight, false); err != nil {
+			return err
+		}
+		err = extraTree.Set(key, append(delegation.Delegate.Bytes(), delegation.Weight.Bytes()...))
+	}
+	if err != nil {
+		return err
+	}
+	log.Debugw("set vote delegation", "processId", fmt.Sprintf("%x", pid), "delegator", delegator.Hex(),
+		"revoked", delegation == nil)
+	for _, l := range v.eventListeners {
+		l.OnVoteDelegation(pid, delegator.Bytes(), delegation)
+	}
+	return nil
+}
+
+// decodeVoteDelegation decodes a delegation, stored as the address of the
+// delegate followed by the big-endian weight.
+func decodeVoteDelegation(b []byte) (*VoteDelegation, error) {
+	if len(b) < common.AddressLength {
+		return nil, fmt.Errorf("cannot decode vote delegation: invalid length %d", len(b))
+	}
+	return &VoteDelegation{
+		Delegate: common.BytesToAddress(b[:common.AddressLength]),
+		Weight:   new(big.Int).SetBytes(b[common.AddressLength:]),
+	}, nil
+}
+
+// decodeDelegatedVoteWeight decodes a delegated weight, stored as the
+// big-endian number of delegators followed by the big-endian weight. A nil
+// value is no weight delegated.
+func decodeDelegatedVoteWeight(b []byte) (*DelegatedVoteWeight, error) {
+	if b == nil {
+		return &DelegatedVoteWeight{Weight: new(big.Int)}, nil
+	}
+	if len(b) < 4 {
+		return nil, fmt.Errorf("cannot decode delegated vote weight: invalid length %d", len(b))
+	}
+	return &DelegatedVoteWeight{
+		Delegators: binary.BigEndian.Uint32(b[:4]),
+		Weight:     new(big.Int).SetBytes(b[4:]),
+	}, nil
+}
@@ -0,0 +1,188 @@
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/db"
+	"go.vocdoni.io/dvote/log"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// voteDepositDBPrefix is the NoState prefix of the vote deposits locked by the
+// voters of each process, keyed by process ID and vote nullifier.
+var voteDepositDBPrefix = []byte("vdep/")
+
+// VoteDeposit is a deposit locked by a voter to vote in a process which
+// requires it, see vochaintx.ProcessModeVoteDeposit.
+type VoteDeposit struct {
+	Voter  common.Address
+	Amount uint64
+}
+
+// ProcessDepositAddress returns the address of the account that holds the vote
+// deposits of the process until they are refunded or burned. The account is
+// created on the first deposit and nobody holds its key.
+func ProcessDepositAddress(pid []byte) common.Address {
+	return common.BytesToAddress(ethereum.HashRaw(append([]byte("vocdoni process vote deposit"), pid...)))
+}
+
+// ProcessVoteDeposits returns the amount of tokens currently locked by the
+// voters of the process.
+// When committed is false, the operation is executed also on not yet committed
+// data from the currently open StateDB transaction.
+// When committed is true, the operation is executed on the last committed version.
+func (v *State) ProcessVoteDeposits(pid []byte, committed bool) (uint64, error) {
+	acc, err := v.GetAccount(ProcessDepositAddress(pid), committed)
+	if err != nil || acc == nil {
+		return 0, err
+	}
+	return acc.Balance, nil
+}
+
+// VoteDeposit returns the deposit locked by the vote with the nullifier in the
+// process, or nil if there is none.
+func (v *State) VoteDeposit(pid, nullifier []byte) (*VoteDeposit, error) {
+	b, err := v.NoState(true).Get(voteDepositKey(pid, nullifier))
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeVoteDeposit(b)
+}
+
+// LockVoteDeposit transfers the deposit required by the process of the vote,
+// if any, from the voter to the deposit account of the process. The deposit is
+// only locked once per vote, so the overwrites of a vote do not lock it again.
+func (v *State) LockVoteDeposit(vote *Vote, voter common.Address, txHash []byte) error {
+	process, err := v.Process(vote.ProcessID, false)
+	if err != nil {
+		return err
+	}
+	amount := vochaintx.ProcessModeVoteDeposit(process.Mode)
+	if amount == 0 {
+		return nil
+	}
+	deposit, err := v.VoteDeposit(vote.ProcessID, vote.Nullifier)
+	if err != nil || deposit != nil {
+		return err
+	}
+	depositAddress := ProcessDepositAddress(vote.ProcessID)
+	acc, err := v.GetAccount(depositAddress, false)
+	if err != nil {
+		return err
+	}
+	if acc == nil {
+		if err := v.CreateAccount(depositAddress, "", nil, 0); err != nil {
+			return err
+		}
+	}
+	if err := v.TransferBalance(&vochaintx.TokenTransfer{
+		FromAddress: voter,
+		ToAddress:   depositAddress,
+		Amount:      amount,
+		TxHash:      txHash,
+	}, false); err != nil {
+		return fmt.Errorf("cannot lock vote deposit: %w", err)
+	}
+	b := binary.BigEndian.AppendUint64(voter.Bytes(), amount)
+	return v.NoState(true).Set(voteDepositKey(vote.ProcessID, vote.Nullifier), b)
+}
+
+// SettleVoteDeposits releases the vote deposits of the process: the deposit of
+// each vote for which counted returns true is refunded to its voter, while the
+// rest are burned. A nil counted refunds all of them, as done when a process is
+// canceled. It returns the amounts refunded and burned.
+func (v *State) SettleVoteDeposits(pid []byte, counted func(vote *models.StateDBVote) bool) (uint64, uint64, error) {
+	type lockedDeposit struct {
+		nullifier []byte
+		deposit   *VoteDeposit
+	}
+	var deposits []lockedDeposit
+	prefix := toPrefixKey(bytes.Clone(voteDepositDBPrefix), pid)
+	var decodeErr error
+	if err := v.NoState(true).Iterate(prefix, func(key, value []byte) bool {
+		deposit, err := decodeVoteDeposit(value)
+		if err != nil {
+			decodeErr = err
+			return false
+		}
+		deposits = append(deposits, lockedDeposit{nullifier: bytes.Clone(key), deposit: deposit})
+		return true
+	}); err != nil {
+		return 0, 0, err
+	}
+	if decodeErr != nil {
+		return 0, 0, decodeErr
+	}
+
+	depositAddress := ProcessDepositAddress(pid)
+	var refunded, burned uint64
+	for _, d := range deposits {
+		refund := counted == nil
+		if !refund {
+			vote, err := v.Vote(pid, d.nullifier, false)
+			if err != nil && !errors.Is(err, ErrVoteNotFound) {
+				return 0, 0, err
+			}
+			refund = vote != nil && counted(vote)
+		}
+		if refund {
+			if err := v.TransferBalance(&vochaintx.TokenTransfer{
+				FromAddress: depositAddress,
+				ToAddress:   d.deposit.Voter,
+				Amount:      d.deposit.Amount,
+				TxHash:      voteDepositRefundHash(pid, d.nullifier),
+			}, false); err != nil {
+				return 0, 0, fmt.Errorf("cannot refund vote deposit: %w", err)
+			}
+			refunded += d.deposit.Amount
+		} else {
+			if err := v.BurnTxCost(depositAddress, d.deposit.Amount); err != nil {
+				return 0, 0, fmt.Errorf("cannot burn vote deposit: %w", err)
+			}
+			for _, l := range v.eventListeners {
+				l.OnSpendTokens(depositAddress.Bytes(), vochaintx.TxTypeVoteDeposit,
+					d.deposit.Amount, fmt.Sprintf("%x", pid))
+			}
+			burned += d.deposit.Amount
+		}
+		if err := v.NoState(true).Delete(voteDepositKey(pid, d.nullifier)); err != nil {
+			return 0, 0, err
+		}
+	}
+	if len(deposits) > 0 {
+		log.Infow("vote deposits settled", "processId", fmt.Sprintf("%x", pid),
+			"deposits", len(deposits), "refunded", refunded, "burned", burned)
+	}
+	return refunded, burned, nil
+}
+
+// voteDepositKey returns the NoState key of the deposit of the vote with the
+// nullifier in the process.
+func voteDepositKey(pid, nullifier []byte) []byte {
+	return append(toPrefixKey(bytes.Clone(voteDepositDBPrefix), pid), nullifier...)
+}
+
+// voteDepositRefundHash returns the reference of the token transfer refunding
+// the deposit of a vote, since it is not made by any transaction.
+func voteDepositRefundHash(pid, nullifier []byte) []byte {
+	return ethereum.HashRaw(append(append([]byte("vote deposit refund"), pid...), nullifier...))
+}
+
+func decodeVoteDeposit(b []byte) (*VoteDeposit, error) {
+	if len(b) != common.AddressLength+8 {
+		return nil, fmt.Errorf("invalid vote deposit")
+	}
+	return &VoteDeposit{
+		Voter:  common.BytesToAddress(b[:common.AddressLength]),
+		Amount: binary.BigEndian.Uint64(b[common.AddressLength:]),
+	}, nil
+}
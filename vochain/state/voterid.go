@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 
 	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/censustree"
 	"go.vocdoni.io/dvote/crypto/ethereum"
 )
 
@@ -22,6 +23,7 @@ const (
 	VoterIDTypeZkSnark   VoterIDType = 2
 	VoterIDTypeEd25519   VoterIDType = 3
 	VoterIDTypeFarcaster VoterIDType = 4
+	VoterIDTypeP256      VoterIDType = 5
 )
 
 // Enum value map for VoterIDType.
@@ -31,6 +33,7 @@ var voterIDTypeName = map[VoterIDType]string{
 	VoterIDTypeZkSnark:   "ZKSNARK",
 	VoterIDTypeEd25519:   "ED25519",
 	VoterIDTypeFarcaster: "FARCASTER",
+	VoterIDTypeP256:      "P256",
 }
 
 // NewVoterID creates a new VoterID from a VoterIDType and a key.
@@ -94,6 +97,12 @@ func (v VoterID) Address() []byte {
 		return common.BytesToAddress(ethereum.HashRaw(v[1:])).Bytes()
 	case VoterIDTypeFarcaster:
 		return common.BytesToAddress(v[1:]).Bytes()
+	case VoterIDTypeP256:
+		addr, err := censustree.ParticipantKey(censustree.KeyTypeP256, v[1:])
+		if err != nil {
+			return nil
+		}
+		return addr
 	default:
 		return nil
 	}
@@ -1,9 +1,14 @@
 package state
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/dvote/censustree"
 	"go.vocdoni.io/dvote/crypto/ethereum"
 )
 
@@ -26,3 +31,27 @@ func TestVoterID(t *testing.T) {
 	// check VoterID type to string
 	qt.Assert(t, vID2.VoterIDTypeToString(), qt.Equals, "ECDSA")
 }
+
+func TestVoterIDCensusKey(t *testing.T) {
+	// the address of the voterIDs must match the key they are enrolled with
+	signKey := &ethereum.SignKeys{}
+	qt.Assert(t, signKey.Generate(), qt.IsNil)
+	key, err := censustree.ParticipantKey(censustree.KeyTypeSecp256k1, signKey.PublicKey())
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, NewVoterID(VoterIDTypeECDSA, signKey.PublicKey()).Address(), qt.DeepEquals, key)
+
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	qt.Assert(t, err, qt.IsNil)
+	key, err = censustree.ParticipantKey(censustree.KeyTypeEd25519, edPub)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, NewVoterID(VoterIDTypeEd25519, edPub).Address(), qt.DeepEquals, key)
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	qt.Assert(t, err, qt.IsNil)
+	p256Pub := elliptic.MarshalCompressed(elliptic.P256(), p256Key.X, p256Key.Y)
+	key, err = censustree.ParticipantKey(censustree.KeyTypeP256, p256Pub)
+	qt.Assert(t, err, qt.IsNil)
+	vID := NewVoterID(VoterIDTypeP256, p256Pub)
+	qt.Assert(t, vID.Address(), qt.DeepEquals, key)
+	qt.Assert(t, vID.VoterIDTypeToString(), qt.Equals, "P256")
+}
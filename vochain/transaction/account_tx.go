@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"go.vocdoni.io/dvote/crypto/ethereum"
@@ -50,7 +51,7 @@ func (t *TransactionHandler) CreateAccountTxCheck(vtx *vochaintx.Tx) error {
 	if err := vstate.CheckDuplicateDelegates(tx.GetDelegates(), &txSenderAddress); err != nil {
 		return fmt.Errorf("invalid delegates: %w", err)
 	}
-	txCost, err := t.state.TxBaseCost(models.TxType_CREATE_ACCOUNT, false)
+	txCost, err := t.state.TxCost(models.TxType_CREATE_ACCOUNT, false)
 	if err != nil {
 		return fmt.Errorf("cannot get tx cost: %w", err)
 	}
@@ -160,6 +161,63 @@ func (t *TransactionHandler) SetAccountInfoTxCheck(vtx *vochaintx.Tx) error {
 	return nil
 }
 
+// SetAccountKVTxCheck checks if a set account key-value tx is valid. It returns
+// the address of the account whose key-value store is modified, and the key and
+// value of the entry.
+func (t *TransactionHandler) SetAccountKVTxCheck(vtx *vochaintx.Tx) (common.Address, string, []byte, error) {
+	if vtx == nil || vtx.Signature == nil || vtx.SignedBody == nil || vtx.Tx == nil {
+		return common.Address{}, "", nil, ErrNilTx
+	}
+	if err := t.requireFork(vstate.ForkAccountKV); err != nil {
+		return common.Address{}, "", nil, err
+	}
+	tx := vtx.Tx.GetSetAccount()
+	if tx == nil {
+		return common.Address{}, "", nil, fmt.Errorf("invalid transaction")
+	}
+	key, value, err := vochaintx.AccountKVEntry(tx)
+	if err != nil {
+		return common.Address{}, "", nil, err
+	}
+	if err := vstate.CheckAccountKV(key, value); err != nil {
+		return common.Address{}, "", nil, err
+	}
+	_, txSenderAddress, err := t.checkAccountCanPayCost(vochaintx.TxTypeSetAccountKV, vtx, 0)
+	if err != nil {
+		return common.Address{}, "", nil, err
+	}
+	txAccountAddress := common.BytesToAddress(tx.GetAccount())
+	if txAccountAddress == (common.Address{}) {
+		txAccountAddress = *txSenderAddress
+	}
+	if txAccountAddress != *txSenderAddress {
+		// if txSender != txAccount only delegate operations
+		txAccountAccount, err := t.state.GetAccount(txAccountAddress, false)
+		if err != nil {
+			return common.Address{}, "", nil, fmt.Errorf("cannot get tx account: %w", err)
+		}
+		if txAccountAccount == nil {
+			return common.Address{}, "", nil, vstate.ErrAccountNotExist
+		}
+		if !txAccountAccount.IsDelegate(*txSenderAddress) {
+			return common.Address{}, "", nil, fmt.Errorf("tx sender is not a delegate")
+		}
+	}
+	// check that a new entry fits in the key-value store
+	if len(value) > 0 {
+		entries, err := t.state.AccountKV(txAccountAddress, false)
+		if err != nil {
+			return common.Address{}, "", nil, fmt.Errorf("cannot get account key-value store: %w", err)
+		}
+		if len(entries) >= vstate.MaxAccountKVEntries && !slices.ContainsFunc(entries, func(e vstate.AccountKV) bool {
+			return e.Key == key
+		}) {
+			return common.Address{}, "", nil, fmt.Errorf("%w: max %d entries", vstate.ErrAccountKVFull, vstate.MaxAccountKVEntries)
+		}
+	}
+	return txAccountAddress, key, value, nil
+}
+
 // DelSIKTxCheck checks if a delete SIK tx is valid
 func (t *TransactionHandler) DelSIKTxCheck(vtx *vochaintx.Tx) (common.Address, error) {
 	if vtx == nil || vtx.Signature == nil || vtx.SignedBody == nil || vtx.Tx == nil {
@@ -5,6 +5,7 @@ import (
 
 	"go.vocdoni.io/dvote/crypto/ethereum"
 	"go.vocdoni.io/dvote/log"
+	vstate "go.vocdoni.io/dvote/vochain/state"
 	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
 )
@@ -31,7 +32,12 @@ func (t *TransactionHandler) AdminTxCheck(vtx *vochaintx.Tx) (ethereum.Address,
 	log.Debugw("checking admin tx", "addr", addr.Hex(), "tx", log.FormatProto(tx))
 
 	switch tx.Txtype {
-	case models.TxType_ADD_PROCESS_KEYS, models.TxType_REVEAL_PROCESS_KEYS:
+	case models.TxType_ADD_PROCESS_KEYS, models.TxType_REVEAL_PROCESS_KEYS, vochaintx.TxTypeSetProcessKeyShares:
+		if tx.Txtype == vochaintx.TxTypeSetProcessKeyShares {
+			if err := t.requireFork(vstate.ForkKeyShares); err != nil {
+				return ethereum.Address{}, err
+			}
+		}
 		if tx.ProcessId == nil {
 			return ethereum.Address{}, fmt.Errorf("missing processId on adminTx")
 		}
@@ -70,9 +76,20 @@ func (t *TransactionHandler) AdminTxCheck(vtx *vochaintx.Tx) (ethereum.Address,
 			return ethereum.Address{}, fmt.Errorf("missing keyIndex on adminTx")
 		}
 
-		// check keyIndex in the transaction is correct for the validator
+		// check keyIndex in the transaction is correct for the validator, unless
+		// it reveals the key of another keykeeper recovered from its shares
 		if *tx.KeyIndex != validator.KeyIndex {
-			return ethereum.Address{}, fmt.Errorf("transaction key index does not match with validator index")
+			recovery := false
+			if tx.Txtype == models.TxType_REVEAL_PROCESS_KEYS {
+				shares, err := t.state.ProcessKeyShares(tx.ProcessId, *tx.KeyIndex, false)
+				if err != nil {
+					return ethereum.Address{}, err
+				}
+				recovery = shares != nil
+			}
+			if !recovery {
+				return ethereum.Address{}, fmt.Errorf("transaction key index does not match with validator index")
+			}
 		}
 
 		// get current timestamp
@@ -110,6 +127,22 @@ func (t *TransactionHandler) AdminTxCheck(vtx *vochaintx.Tx) (ethereum.Address,
 			if err := checkRevealProcessKeys(tx, process); err != nil {
 				return ethereum.Address{}, err
 			}
+		case vochaintx.TxTypeSetProcessKeyShares:
+			if process.Status == models.ProcessStatus_CANCELED ||
+				process.Status == models.ProcessStatus_ENDED ||
+				process.Status == models.ProcessStatus_RESULTS {
+				return ethereum.Address{}, fmt.Errorf("cannot add process key shares to a %s process", process.Status)
+			}
+			shares, err := t.state.ProcessKeyShares(tx.ProcessId, tx.GetKeyIndex(), false)
+			if err != nil {
+				return ethereum.Address{}, err
+			}
+			if shares != nil {
+				return ethereum.Address{}, fmt.Errorf("key shares for process %x already added", tx.ProcessId)
+			}
+			if err := checkProcessKeyShares(tx, process); err != nil {
+				return ethereum.Address{}, err
+			}
 		}
 	default:
 		return ethereum.Address{}, fmt.Errorf("tx not supported")
@@ -0,0 +1,49 @@
+package transaction
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	vstate "go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+)
+
+// SetBlockTimingTxCheck checks if a set block timing tx is valid, returning the
+// new block timing and the address of the tx sender, who must be a validator.
+// The tx is the vote of the validator for the block timing, which is only set
+// once a quorum of validators vote for it, see state.VoteBlockTiming.
+func (t *TransactionHandler) SetBlockTimingTxCheck(vtx *vochaintx.Tx) (*vstate.BlockTiming, common.Address, error) {
+	if vtx == nil || vtx.Signature == nil || vtx.SignedBody == nil || vtx.Tx == nil {
+		return nil, common.Address{}, ErrNilTx
+	}
+	tx := vtx.Tx.GetSetAccount()
+	if tx == nil {
+		return nil, common.Address{}, fmt.Errorf("invalid transaction")
+	}
+	if err := t.requireFork(vstate.ForkBlockTiming); err != nil {
+		return nil, common.Address{}, err
+	}
+	blockTime, emptyBlocksInterval, err := vochaintx.BlockTimingParams(tx)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	timing := &vstate.BlockTiming{
+		BlockTime:           blockTime,
+		EmptyBlocksInterval: emptyBlocksInterval,
+	}
+	if err := timing.Validate(); err != nil {
+		return nil, common.Address{}, err
+	}
+	_, txSenderAddress, err := t.checkAccountCanPayCost(vochaintx.TxTypeSetBlockTiming, vtx, 0)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	validator, err := t.state.Validator(*txSenderAddress, false)
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("cannot get validator: %w", err)
+	}
+	if validator == nil {
+		return nil, common.Address{}, fmt.Errorf("only the validators can set the block timing, %s is not one", txSenderAddress)
+	}
+	return timing, *txSenderAddress, nil
+}
@@ -0,0 +1,156 @@
+package transaction
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	"go.vocdoni.io/dvote/util"
+	vstate "go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// DelegateVoteWeightTxCheck checks if a delegate vote weight tx is valid,
+// returning its parameters, the address of the tx sender, who is the delegator,
+// and the weight delegated, which is nil for a revocation. The delegations are
+// only allowed before the process starts, and they cannot be chained: a
+// delegate cannot delegate its weight, nor can a delegator receive delegations.
+func (t *TransactionHandler) DelegateVoteWeightTxCheck(vtx *vochaintx.Tx) (*vochaintx.VoteDelegation, common.Address, *big.Int, error) {
+	if vtx == nil || vtx.Signature == nil || vtx.SignedBody == nil || vtx.Tx == nil {
+		return nil, common.Address{}, nil, ErrNilTx
+	}
+	if err := t.requireFork(vstate.ForkVoteDelegation); err != nil {
+		return nil, common.Address{}, nil, err
+	}
+	tx := vtx.Tx.GetSetAccount()
+	if tx == nil {
+		return nil, common.Address{}, nil, fmt.Errorf("invalid transaction")
+	}
+	delegation, err := vochaintx.VoteDelegationParams(tx)
+	if err != nil {
+		return nil, common.Address{}, nil, err
+	}
+	process, err := t.state.Process(delegation.ProcessID, false)
+	if err != nil {
+		return nil, common.Address{}, nil, fmt.Errorf("cannot get process %x: %w", delegation.ProcessID, err)
+	}
+	if !vochaintx.ProcessModeVoteDelegation(process.Mode) {
+		return nil, common.Address{}, nil, fmt.Errorf("process %x does not accept vote delegations", delegation.ProcessID)
+	}
+	if err := t.checkProcessNotStarted(process); err != nil {
+		return nil, common.Address{}, nil, err
+	}
+	_, txSenderAddress, err := t.checkAccountCanPayCost(vochaintx.TxTypeDelegateVoteWeight, vtx, 0)
+	if err != nil {
+		return nil, common.Address{}, nil, err
+	}
+	delegator := *txSenderAddress
+	if delegation.Delegate == nil {
+		previous, err := t.state.VoteDelegation(process.ProcessId, delegator, false)
+		if err != nil {
+			return nil, common.Address{}, nil, fmt.Errorf("cannot get vote delegation: %w", err)
+		}
+		if previous == nil {
+			return nil, common.Address{}, nil, fmt.Errorf("%s has no vote delegation to revoke", delegator.Hex())
+		}
+		return delegation, delegator, nil, nil
+	}
+
+	delegate := *delegation.Delegate
+	if delegate == delegator {
+		return nil, common.Address{}, nil, fmt.Errorf("cannot delegate the vote weight to oneself")
+	}
+	delegated, err := t.state.DelegatedVoteWeight(process.ProcessId, delegator, false)
+	if err != nil {
+		return nil, common.Address{}, nil, fmt.Errorf("cannot get delegated vote weight: %w", err)
+	}
+	if delegated.Delegators > 0 {
+		return nil, common.Address{}, nil, fmt.Errorf("%s cannot delegate the vote weight delegated to it", delegator.Hex())
+	}
+	delegateDelegation, err := t.state.VoteDelegation(process.ProcessId, delegate, false)
+	if err != nil {
+		return nil, common.Address{}, nil, fmt.Errorf("cannot get vote delegation: %w", err)
+	}
+	if delegateDelegation != nil {
+		return nil, common.Address{}, nil, fmt.Errorf("the delegate %s delegated its vote weight to %s",
+			delegate.Hex(), delegateDelegation.Delegate.Hex())
+	}
+
+	// the census proof of the delegator is verified as the proof of a vote,
+	// and its whole weight is delegated
+	if delegation.Proof.GetArbo().GetVoteWeight() != nil {
+		return nil, common.Address{}, nil, fmt.Errorf("the census proof cannot set a vote weight")
+	}
+	pubKey, err := ethereum.PubKeyFromSignature(vtx.SignedBody, vtx.Signature)
+	if err != nil {
+		return nil, common.Address{}, nil, fmt.Errorf("cannot extract public key from signature: %w", err)
+	}
+	valid, weight, err := VerifyProof(process, &models.VoteEnvelope{
+		ProcessId: process.ProcessId,
+		Proof:     delegation.Proof,
+	}, append([]byte{vstate.VoterIDTypeECDSA}, pubKey...))
+	if err != nil {
+		return nil, common.Address{}, nil, err
+	}
+	if !valid {
+		return nil, common.Address{}, nil, fmt.Errorf("merkle proof verification failed")
+	}
+	return delegation, delegator, weight, nil
+}
+
+// checkProcessNotStarted checks that the process did not start yet, so that its
+// census members can still delegate their vote weight.
+func (t *TransactionHandler) checkProcessNotStarted(process *models.Process) error {
+	if process.Status != models.ProcessStatus_READY && process.Status != models.ProcessStatus_PAUSED {
+		return fmt.Errorf("process %x has status %s", process.ProcessId, process.Status)
+	}
+	if process.Duration == 0 { // block count based processes
+		if height := t.state.CurrentHeight(); height >= process.StartBlock {
+			return fmt.Errorf("process %x started at height %d, current height is %d",
+				process.ProcessId, process.StartBlock, height)
+		}
+		return nil
+	}
+	currentTime, err := t.state.Timestamp(false)
+	if err != nil {
+		return fmt.Errorf("cannot get current time: %w", err)
+	}
+	if currentTime >= process.StartTime {
+		return fmt.Errorf("process %x started at time %s, current time is %s",
+			process.ProcessId, util.TimestampToTime(process.StartTime).String(),
+			util.TimestampToTime(currentTime).String())
+	}
+	return nil
+}
+
+// checkVoteDelegation checks that the voter of a signed vote did not delegate
+// its vote weight, if the process accepts vote delegations.
+func (t *TransactionHandler) checkVoteDelegation(vote *vstate.Vote, process *models.Process) error {
+	if !vochaintx.ProcessModeVoteDelegation(process.Mode) {
+		return nil
+	}
+	voter := ethereum.AddrFromBytes(vote.VoterID.Address())
+	delegation, err := t.state.VoteDelegation(process.ProcessId, voter, false)
+	if err != nil {
+		return fmt.Errorf("cannot get vote delegation: %w", err)
+	}
+	if delegation != nil {
+		return fmt.Errorf("%s delegated its vote weight to %s", voter.Hex(), delegation.Delegate.Hex())
+	}
+	return nil
+}
+
+// delegatedVoteWeight returns the vote weight delegated to the voter of a
+// signed vote, which is zero if the process does not accept vote delegations.
+func (t *TransactionHandler) delegatedVoteWeight(vote *vstate.Vote, process *models.Process) (*big.Int, error) {
+	if !vochaintx.ProcessModeVoteDelegation(process.Mode) {
+		return new(big.Int), nil
+	}
+	delegated, err := t.state.DelegatedVoteWeight(process.ProcessId, ethereum.AddrFromBytes(vote.VoterID.Address()), false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get delegated vote weight: %w", err)
+	}
+	return delegated.Weight, nil
+}
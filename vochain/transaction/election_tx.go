@@ -12,6 +12,7 @@ import (
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/vochain/processid"
 	"go.vocdoni.io/dvote/vochain/results"
+	vstate "go.vocdoni.io/dvote/vochain/state"
 	"go.vocdoni.io/dvote/vochain/state/electionprice"
 	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
@@ -65,6 +66,52 @@ func (t *TransactionHandler) NewProcessTxCheck(vtx *vochaintx.Tx) (*models.Proce
 		}
 	}
 
+	// the processes of a nullifier group share the nullifiers of their voters
+	if group := vochaintx.ProcessModeNullifierGroup(tx.Process.Mode); len(group) > 0 {
+		if err := t.requireFork(vstate.ForkNullifierGroups); err != nil {
+			return nil, ethereum.Address{}, err
+		}
+		if len(group) > vstate.MaxNullifierGroupSize {
+			return nil, ethereum.Address{}, fmt.Errorf("nullifier group too long (%d > %d)",
+				len(group), vstate.MaxNullifierGroupSize)
+		}
+		if !tx.Process.EnvelopeType.Anonymous {
+			return nil, ethereum.Address{}, fmt.Errorf("nullifier group requires an anonymous process")
+		}
+		if tx.Process.EnvelopeType.EncryptedVotes {
+			return nil, ethereum.Address{}, fmt.Errorf("nullifier group not supported for encrypted votes")
+		}
+	}
+
+	// the vote deposits are locked from and refunded to the account of the voter
+	if vochaintx.ProcessModeVoteDeposit(tx.Process.Mode) > 0 {
+		if err := t.requireFork(vstate.ForkVoteDeposit); err != nil {
+			return nil, ethereum.Address{}, err
+		}
+		if tx.Process.EnvelopeType.Anonymous {
+			return nil, ethereum.Address{}, fmt.Errorf("vote deposit not supported for anonymous voting")
+		}
+		if tx.Process.CensusOrigin == models.CensusOrigin_FARCASTER_FRAME {
+			return nil, ethereum.Address{}, fmt.Errorf("vote deposit not supported for Farcaster voting")
+		}
+	}
+
+	// the delegations are proven with the census proofs of the voters, and
+	// bound to their accounts
+	if vochaintx.ProcessModeVoteDelegation(tx.Process.Mode) {
+		if err := t.requireFork(vstate.ForkVoteDelegation); err != nil {
+			return nil, ethereum.Address{}, err
+		}
+		if tx.Process.EnvelopeType.Anonymous {
+			return nil, ethereum.Address{}, fmt.Errorf("vote delegation not supported for anonymous voting")
+		}
+		if tx.Process.CensusOrigin != models.CensusOrigin_OFF_CHAIN_TREE &&
+			tx.Process.CensusOrigin != models.CensusOrigin_OFF_CHAIN_TREE_WEIGHTED {
+			return nil, ethereum.Address{}, fmt.Errorf("vote delegation not supported for census origin %s",
+				tx.Process.CensusOrigin)
+		}
+	}
+
 	// get current timestamp from state
 	currentTimestamp, err := t.state.Timestamp(false)
 	if err != nil {
@@ -115,6 +162,10 @@ func (t *TransactionHandler) NewProcessTxCheck(vtx *vochaintx.Tx) (*models.Proce
 		return nil, ethereum.Address{}, err
 	}
 
+	// a multisig account can only create the processes it organizes
+	if err := checkMultisigOrganizer(vtx, tx.Process.EntityId); err != nil {
+		return nil, ethereum.Address{}, err
+	}
 	// if organization ID is not set, use the sender address
 	if tx.Process.EntityId == nil {
 		tx.Process.EntityId = addr.Bytes()
@@ -167,7 +218,7 @@ func (t *TransactionHandler) SetProcessTxCheck(vtx *vochaintx.Tx) (ethereum.Addr
 	tx := vtx.Tx.GetSetProcess()
 
 	// get tx base cost
-	cost, err := t.state.TxBaseCost(tx.Txtype, false)
+	cost, err := t.state.TxCost(tx.Txtype, false)
 	if err != nil {
 		return ethereum.Address{}, fmt.Errorf("cannot get %s transaction cost: %w", tx.Txtype, err)
 	}
@@ -183,6 +234,9 @@ func (t *TransactionHandler) SetProcessTxCheck(vtx *vochaintx.Tx) (ethereum.Addr
 	if err != nil {
 		return ethereum.Address{}, fmt.Errorf("cannot get process %x: %w", tx.ProcessId, err)
 	}
+	if err := checkMultisigOrganizer(vtx, process.EntityId); err != nil {
+		return ethereum.Address{}, err
+	}
 	// check process entityID matches tx sender
 	if !bytes.Equal(process.EntityId, addr.Bytes()) {
 		// check if delegate
@@ -320,6 +374,50 @@ func checkRevealProcessKeys(tx *models.AdminTx, process *models.Process) error {
 	return nil
 }
 
+// maxProcessKeyShareSize is the maximum size of an encrypted key share, which
+// is a share of a nacl private key encrypted with a nacl anonymous box.
+const maxProcessKeyShareSize = 128
+
+func checkProcessKeyShares(tx *models.AdminTx, process *models.Process) error {
+	if tx == nil {
+		return ErrNilTx
+	}
+	if tx.KeyIndex == nil {
+		return fmt.Errorf("key index is nil")
+	}
+	if tx.GetKeyIndex() < 1 || int(tx.GetKeyIndex()) >= len(process.EncryptionPublicKeys) {
+		return fmt.Errorf("invalid key index")
+	}
+	// the shares can only be sent for a published key
+	if len(process.EncryptionPublicKeys[tx.GetKeyIndex()]) < 1 {
+		return fmt.Errorf("key index %d does not exist", tx.GetKeyIndex())
+	}
+	shares, err := vochaintx.ProcessKeyShares(tx)
+	if err != nil {
+		return err
+	}
+	// a single share would hold the whole key
+	if len(shares) < 2 {
+		return fmt.Errorf("at least two key shares must be provided")
+	}
+	seen := make(map[uint32]bool, len(shares))
+	for _, share := range shares {
+		// each share is sent to another keykeeper, which published its key
+		if share.KeyIndex < 1 || int(share.KeyIndex) >= len(process.EncryptionPublicKeys) ||
+			share.KeyIndex == tx.GetKeyIndex() || seen[share.KeyIndex] {
+			return fmt.Errorf("invalid or duplicated key share index %d", share.KeyIndex)
+		}
+		seen[share.KeyIndex] = true
+		if len(process.EncryptionPublicKeys[share.KeyIndex]) < 1 {
+			return fmt.Errorf("key share index %d does not exist", share.KeyIndex)
+		}
+		if len(share.Cipher) > maxProcessKeyShareSize {
+			return fmt.Errorf("key share %d too big", share.KeyIndex)
+		}
+	}
+	return nil
+}
+
 // txElectionCostFromProcess calculates the cost of a new process based on the election price calculator.
 func (t *TransactionHandler) txElectionCostFromProcess(process *models.Process) uint64 {
 	return t.state.ElectionPriceCalc.Price(&electionprice.ElectionParameters{
@@ -340,7 +438,7 @@ func (t *TransactionHandler) txCostIncreaseCensusSize(process *models.Process, n
 	newCost := t.txElectionCostFromProcess(process)
 	process.MaxCensusSize = oldSize
 
-	baseCost, err := t.state.TxBaseCost(models.TxType_SET_PROCESS_CENSUS, false)
+	baseCost, err := t.state.TxCost(models.TxType_SET_PROCESS_CENSUS, false)
 	if err != nil {
 		log.Errorw(err, "txCostIncreaseCensusSize: cannot get transaction base cost")
 		return 0
@@ -360,7 +458,7 @@ func (t *TransactionHandler) txCostIncreaseDuration(process *models.Process, new
 	newCost := t.txElectionCostFromProcess(process)
 	process.Duration = oldDuration
 
-	baseCost, err := t.state.TxBaseCost(models.TxType_SET_PROCESS_DURATION, false)
+	baseCost, err := t.state.TxCost(models.TxType_SET_PROCESS_DURATION, false)
 	if err != nil {
 		log.Errorw(err, "txCostIncreaseDuration: cannot get transaction base cost")
 		return 0
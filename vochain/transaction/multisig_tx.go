@@ -0,0 +1,152 @@
+package transaction
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/dvote/crypto/ethereum"
+	vstate "go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// CreateMultisigAccountTxCheck checks if a create multisig account tx is valid,
+// returning the multisig account to create and the address of the tx sender,
+// who pays for the transaction.
+func (t *TransactionHandler) CreateMultisigAccountTxCheck(vtx *vochaintx.Tx) (*vstate.MultisigAccount, common.Address, error) {
+	if vtx == nil || vtx.Signature == nil || vtx.SignedBody == nil || vtx.Tx == nil {
+		return nil, common.Address{}, ErrNilTx
+	}
+	if err := t.requireFork(vstate.ForkMultisig); err != nil {
+		return nil, common.Address{}, err
+	}
+	tx := vtx.Tx.GetSetAccount()
+	if tx == nil {
+		return nil, common.Address{}, fmt.Errorf("invalid transaction")
+	}
+	signers, threshold, err := vochaintx.MultisigAccountParams(tx)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	multisig := &vstate.MultisigAccount{Signers: signers, Threshold: threshold}
+	if err := multisig.Check(); err != nil {
+		return nil, common.Address{}, err
+	}
+	acc, err := t.state.GetAccount(multisig.Address(), false)
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("cannot get multisig account: %w", err)
+	}
+	if acc != nil {
+		return nil, common.Address{}, vstate.ErrAccountAlreadyExists
+	}
+	_, txSenderAddress, err := t.checkAccountCanPayCost(vochaintx.TxTypeCreateMultisigAccount, vtx, 0)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	return multisig, *txSenderAddress, nil
+}
+
+// checkMultisigFork returns the error decoding the multisig signatures of the
+// transaction once the ForkMultisig fork is applied, and drops them before, as
+// the nodes without multisig accounts ignore them.
+func (t *TransactionHandler) checkMultisigFork(vtx *vochaintx.Tx) error {
+	if vtx.Multisig == nil && vtx.MultisigErr() == nil {
+		return nil
+	}
+	active, err := t.state.ForkActive(vstate.ForkMultisig)
+	if err != nil {
+		return err
+	}
+	if !active {
+		vtx.DropMultisig()
+		return nil
+	}
+	return vtx.MultisigErr()
+}
+
+// txSenderAddress returns the address of the account that sends the transaction,
+// which is the multisig account for the multisig transactions, once their
+// signatures are verified, or the signer otherwise. The transactions sent on
+// behalf of an account whose owner key was rotated by its guardians must be
+// signed by its new owner, and the ones signed by its previous key are rejected.
+func (t *TransactionHandler) txSenderAddress(vtx *vochaintx.Tx) (common.Address, error) {
+	if vtx.Multisig == nil {
+		pubKey, err := ethereum.PubKeyFromSignature(vtx.SignedBody, vtx.Signature)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("cannot extract public key from vtx.Signature: %w", err)
+		}
+		txSenderAddress, err := ethereum.AddrFromPublicKey(pubKey)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("cannot extract address from public key: %w", err)
+		}
+		owner, err := t.accountOwner(txSenderAddress)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("cannot get account owner: %w", err)
+		}
+		if owner != nil {
+			return common.Address{}, fmt.Errorf("%w: %s is owned by %s", vstate.ErrAccountKeyRotated,
+				txSenderAddress.Hex(), owner.Hex())
+		}
+		return txSenderAddress, nil
+	}
+	if err := checkMultisigTxType(vtx); err != nil {
+		return common.Address{}, err
+	}
+	owner, err := t.accountOwner(vtx.Multisig.Account)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("cannot get account owner: %w", err)
+	}
+	if owner != nil {
+		if len(vtx.Multisig.Signatures) != 1 {
+			return common.Address{}, fmt.Errorf("the transactions of %s must be signed only by its owner",
+				vtx.Multisig.Account.Hex())
+		}
+		signer, err := ethereum.AddrFromSignature(vtx.SignedBody, vtx.Multisig.Signatures[0])
+		if err != nil {
+			return common.Address{}, fmt.Errorf("invalid owner signature: %w", err)
+		}
+		if signer != *owner {
+			return common.Address{}, fmt.Errorf("%s is not the owner of %s", signer.Hex(), vtx.Multisig.Account.Hex())
+		}
+		return vtx.Multisig.Account, nil
+	}
+	multisig, err := t.state.MultisigAccount(vtx.Multisig.Account, false)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("cannot get multisig account %s: %w", vtx.Multisig.Account.Hex(), err)
+	}
+	if err := multisig.VerifySignatures(vtx.SignedBody, vtx.Multisig.Signatures); err != nil {
+		return common.Address{}, err
+	}
+	return vtx.Multisig.Account, nil
+}
+
+// checkMultisigTxType checks that the multisig transaction is supported. Only the
+// transactions of the processes organized by the multisig account are, and the
+// ones setting its recovery guardians, since the account is not part of the
+// signed transaction and must be identified by it.
+func checkMultisigTxType(vtx *vochaintx.Tx) error {
+	switch payload := vtx.Tx.Payload.(type) {
+	case *models.Tx_NewProcess, *models.Tx_SetProcess:
+		return nil
+	case *models.Tx_SetAccount:
+		// the recovery guardians transactions must set the account
+		if payload.SetAccount.GetTxtype() == vochaintx.TxTypeSetRecoveryGuardians {
+			return nil
+		}
+		return fmt.Errorf("multisig accounts cannot send %s transactions",
+			vochaintx.TxTypeName(payload.SetAccount.GetTxtype()))
+	default:
+		return fmt.Errorf("multisig accounts cannot send %s transactions", vtx.TxModelType)
+	}
+}
+
+// checkMultisigOrganizer checks that the multisig account of the transaction,
+// if any, is the organizer of the process with the given entityID, so that the
+// signatures cannot be replayed on behalf of a different account.
+func checkMultisigOrganizer(vtx *vochaintx.Tx, entityID []byte) error {
+	if vtx.Multisig != nil && !bytes.Equal(entityID, vtx.Multisig.Account.Bytes()) {
+		return fmt.Errorf("the multisig account %s must be the organizer of the process", vtx.Multisig.Account.Hex())
+	}
+	return nil
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
-	"go.vocdoni.io/dvote/crypto/ethereum"
 	"go.vocdoni.io/dvote/log"
 	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
@@ -12,7 +11,8 @@ import (
 
 // ExtractNonceAndSender extracts the nonce and sender address from a given Vochain transaction.
 // The function uses the signature of the transaction to derive the sender's public key and subsequently
-// the Ethereum address. The nonce is extracted based on the specific payload type of the transaction.
+// the Ethereum address (or the multisig account address for the multisig transactions).
+// The nonce is extracted based on the specific payload type of the transaction.
 // If the transaction does not contain signature or nonce, it returns the default values (nil and 0).
 func (t *TransactionHandler) ExtractNonceAndSender(vtx *vochaintx.Tx) (*common.Address, uint32, error) {
 	var ptx interface {
@@ -46,14 +46,13 @@ func (t *TransactionHandler) ExtractNonceAndSender(vtx *vochaintx.Tx) (*common.A
 	if ptx == nil {
 		return nil, 0, fmt.Errorf("payload is nil")
 	}
-
-	pubKey, err := ethereum.PubKeyFromSignature(vtx.SignedBody, vtx.Signature)
-	if err != nil {
-		return nil, 0, fmt.Errorf("cannot extract public key from vtx.Signature: %w", err)
+	if err := t.checkMultisigFork(vtx); err != nil {
+		return nil, 0, err
 	}
-	addr, err := ethereum.AddrFromPublicKey(pubKey)
+
+	addr, err := t.txSenderAddress(vtx)
 	if err != nil {
-		return nil, 0, fmt.Errorf("cannot extract address from public key: %w", err)
+		return nil, 0, err
 	}
 
 	return &addr, ptx.GetNonce(), nil
@@ -19,51 +19,67 @@ type ProofVerifierZk struct{}
 // Verify verifies a proof with census origin ZK. It returns the voting weight included in the proof.
 // Note that SIK root is not verified here, the caller should verify it separately.
 func (*ProofVerifierZk) Verify(process *models.Process, envelope *models.VoteEnvelope, _ state.VoterID) (bool, *big.Int, error) {
-	if !circuit.IsLoaded() {
-		return false, nil, fmt.Errorf("anonymous voting not supported, missing zk circuits data")
-	}
 	// get snark proof from vote envelope
 	proof, err := proofFromEnvelope(envelope)
 	if err != nil {
 		return false, nil, err
 	}
+	weight, err := VerifyProof(process, proof, envelope.VotePackage)
+	if err != nil {
+		return false, nil, err
+	}
+	return true, weight, nil
+}
+
+// VerifyProof checks that the public signals of the proof match the process and the
+// vote package provided, and verifies the proof with the circuit verification key.
+// It returns the voting weight included in the proof.
+// Note that SIK root and nullifier are not verified here, the caller should verify them separately.
+func VerifyProof(process *models.Process, proof *prover.Proof, votePackage []byte) (*big.Int, error) {
+	if !circuit.IsLoaded() {
+		return nil, fmt.Errorf("anonymous voting not supported, missing zk circuits data")
+	}
 	// verify the process id
 	proofProcessID, err := proof.ElectionID()
 	if err != nil {
-		return false, nil, fmt.Errorf("failed on parsing process id from public inputs provided: %w", err)
+		return nil, fmt.Errorf("failed on parsing process id from public inputs provided: %w", err)
 	}
-	hashedPid := sha256.Sum256(process.ProcessId)
-	if !bytes.Equal(hashedPid[:], proofProcessID) {
-		return false, nil, fmt.Errorf("process id mismatch %x != %x", process.ProcessId, proofProcessID)
+	// the processes of a nullifier group share the election ID of the proofs
+	if !bytes.Equal(state.ProcessNullifierElectionID(process), proofProcessID) {
+		return nil, fmt.Errorf("process id mismatch %x != %x", process.ProcessId, proofProcessID)
 	}
 	// verify the census root
 	proofCensusRoot, err := proof.CensusRoot()
 	if err != nil {
-		return false, nil, fmt.Errorf("failed on parsing census root from public inputs provided: %w", err)
+		return nil, fmt.Errorf("failed on parsing census root from public inputs provided: %w", err)
 	}
 	if !bytes.Equal(process.CensusRoot, proofCensusRoot) {
-		return false, nil, fmt.Errorf("census root mismatch")
+		return nil, fmt.Errorf("census root mismatch")
 	}
 	// verify the votePackage hash
 	proofVoteHash, err := proof.VoteHash()
 	if err != nil {
-		return false, nil, fmt.Errorf("failed on parsing vote hash from public inputs provided: %w", err)
+		return nil, fmt.Errorf("failed on parsing vote hash from public inputs provided: %w", err)
 	}
-	hashedVotePackage := sha256.Sum256(envelope.VotePackage)
+	hashedVotePackage := sha256.Sum256(votePackage)
 	if !bytes.Equal(hashedVotePackage[:], proofVoteHash) {
-		return false, nil, fmt.Errorf("vote hash mismatch")
+		return nil, fmt.Errorf("vote hash mismatch")
+	}
+	// the proofs of the circuits without vote weight cannot be votes
+	if !circuit.Global().Config.SupportsAnonymousVotes() {
+		return nil, fmt.Errorf("anonymous voting not supported by zk circuit %s", circuit.Global().Version())
 	}
 	// get vote weight from proof publicSignals
 	weight, err := proof.VoteWeight()
 	if err != nil {
-		return false, nil, fmt.Errorf("failed on parsing vote weight from public inputs provided: %w", err)
+		return nil, fmt.Errorf("failed on parsing vote weight from public inputs provided: %w", err)
 	}
 
 	// verify the proof with the circuit verification key
 	if err := proof.Verify(circuit.Global().VerificationKey); err != nil {
-		return false, nil, fmt.Errorf("zkSNARK proof verification failed: %w", err)
+		return nil, fmt.Errorf("zkSNARK proof verification failed: %w", err)
 	}
-	return true, weight, nil
+	return weight, nil
 }
 
 // proofFromEnvelope returns the parsed ZkProof from the vote envelope.
@@ -0,0 +1,160 @@
+package transaction
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	vstate "go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
+	"go.vocdoni.io/proto/build/go/models"
+)
+
+// SetRecoveryGuardiansTxCheck checks if a set recovery guardians tx is valid,
+// returning the guardians to set, nil if they are removed, and the address of
+// the tx sender, whose guardians are set.
+func (t *TransactionHandler) SetRecoveryGuardiansTxCheck(vtx *vochaintx.Tx) (*vstate.RecoveryGuardians, common.Address, error) {
+	tx, err := t.recoveryTx(vtx)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	guardians, quorum, timelock, err := vochaintx.RecoveryGuardiansParams(tx)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	_, txSenderAddress, err := t.checkAccountCanPayCost(vochaintx.TxTypeSetRecoveryGuardians, vtx, 0)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	account := *txSenderAddress
+	// the account is not part of the signed transaction sent on behalf of a
+	// multisig or a recovered account, so it must be set explicitly
+	if vtx.Multisig != nil && tx.Account == nil {
+		return nil, common.Address{}, fmt.Errorf("the account must be set when sent on behalf of %s", account.Hex())
+	}
+	if tx.Account != nil && !bytes.Equal(tx.Account, account.Bytes()) {
+		return nil, common.Address{}, fmt.Errorf("the account %x is not the tx sender", tx.Account)
+	}
+	if len(guardians) == 0 {
+		current, err := t.state.RecoveryGuardians(account, false)
+		if err != nil {
+			return nil, common.Address{}, fmt.Errorf("cannot get recovery guardians: %w", err)
+		}
+		if current == nil {
+			return nil, common.Address{}, fmt.Errorf("%s has no guardians to remove", account.Hex())
+		}
+		return nil, account, nil
+	}
+	recoveryGuardians := &vstate.RecoveryGuardians{Guardians: guardians, Quorum: quorum, Timelock: timelock}
+	if err := recoveryGuardians.Check(account); err != nil {
+		return nil, common.Address{}, err
+	}
+	return recoveryGuardians, account, nil
+}
+
+// RecoverAccountTxCheck checks if a recover account tx is valid, returning the
+// account to recover, the new owner approved and the address of the tx sender,
+// who must be one of the guardians of the account.
+func (t *TransactionHandler) RecoverAccountTxCheck(vtx *vochaintx.Tx) (common.Address, common.Address, common.Address, error) {
+	tx, err := t.recoveryTx(vtx)
+	if err != nil {
+		return common.Address{}, common.Address{}, common.Address{}, err
+	}
+	account, err := recoveryAccount(tx)
+	if err != nil {
+		return common.Address{}, common.Address{}, common.Address{}, err
+	}
+	newOwner, err := vochaintx.RecoverAccountNewOwner(tx)
+	if err != nil {
+		return common.Address{}, common.Address{}, common.Address{}, err
+	}
+	if newOwner == (common.Address{}) {
+		return common.Address{}, common.Address{}, common.Address{}, fmt.Errorf("invalid new owner %s", newOwner.Hex())
+	}
+	guardians, err := t.state.RecoveryGuardians(account, false)
+	if err != nil {
+		return common.Address{}, common.Address{}, common.Address{}, fmt.Errorf("cannot get recovery guardians: %w", err)
+	}
+	if guardians == nil {
+		return common.Address{}, common.Address{}, common.Address{}, fmt.Errorf("%w: %s has no guardians",
+			vstate.ErrRecoveryNotFound, account.Hex())
+	}
+	_, txSenderAddress, err := t.checkAccountCanPayCost(vochaintx.TxTypeRecoverAccount, vtx, 0)
+	if err != nil {
+		return common.Address{}, common.Address{}, common.Address{}, err
+	}
+	if !slices.Contains(guardians.Guardians, *txSenderAddress) {
+		return common.Address{}, common.Address{}, common.Address{}, fmt.Errorf("%s is not a guardian of %s",
+			txSenderAddress.Hex(), account.Hex())
+	}
+	return account, newOwner, *txSenderAddress, nil
+}
+
+// ExecuteAccountRecoveryTxCheck checks if an execute account recovery tx is
+// valid, returning the account to recover and the address of the tx sender. The
+// new owner of the account must be approved by the quorum of its guardians, and
+// the timelock elapsed.
+func (t *TransactionHandler) ExecuteAccountRecoveryTxCheck(vtx *vochaintx.Tx) (common.Address, common.Address, error) {
+	tx, err := t.recoveryTx(vtx)
+	if err != nil {
+		return common.Address{}, common.Address{}, err
+	}
+	account, err := recoveryAccount(tx)
+	if err != nil {
+		return common.Address{}, common.Address{}, err
+	}
+	recovery, err := t.state.AccountRecovery(account, false)
+	if err != nil {
+		return common.Address{}, common.Address{}, fmt.Errorf("cannot get account recovery: %w", err)
+	}
+	if recovery == nil || recovery.NewOwner == nil {
+		return common.Address{}, common.Address{}, fmt.Errorf("%w: no new owner approved for %s",
+			vstate.ErrRecoveryNotFound, account.Hex())
+	}
+	if height := t.state.CurrentHeight(); height < recovery.ExecutableHeight {
+		return common.Address{}, common.Address{}, fmt.Errorf("the recovery of %s cannot be executed until height %d, current %d",
+			account.Hex(), recovery.ExecutableHeight, height)
+	}
+	_, txSenderAddress, err := t.checkAccountCanPayCost(vochaintx.TxTypeExecuteAccountRecovery, vtx, 0)
+	if err != nil {
+		return common.Address{}, common.Address{}, err
+	}
+	return account, *txSenderAddress, nil
+}
+
+// recoveryTx returns the SetAccountTx of an account recovery transaction,
+// which are only accepted since the ForkRecovery fork.
+func (t *TransactionHandler) recoveryTx(vtx *vochaintx.Tx) (*models.SetAccountTx, error) {
+	if vtx == nil || vtx.Signature == nil || vtx.SignedBody == nil || vtx.Tx == nil {
+		return nil, ErrNilTx
+	}
+	if err := t.requireFork(vstate.ForkRecovery); err != nil {
+		return nil, err
+	}
+	tx := vtx.Tx.GetSetAccount()
+	if tx == nil {
+		return nil, fmt.Errorf("invalid transaction")
+	}
+	return tx, nil
+}
+
+// recoveryAccount returns the account to recover of an account recovery
+// transaction.
+func recoveryAccount(tx *models.SetAccountTx) (common.Address, error) {
+	if len(tx.Account) != common.AddressLength {
+		return common.Address{}, fmt.Errorf("invalid account %x", tx.Account)
+	}
+	return common.BytesToAddress(tx.Account), nil
+}
+
+// accountOwner returns the owner of the account, if its owner key was rotated
+// by its guardians, or nil otherwise. The owners are ignored before the
+// ForkRecovery fork, since the keys of the accounts cannot be rotated yet.
+func (t *TransactionHandler) accountOwner(address common.Address) (*common.Address, error) {
+	active, err := t.state.ForkActive(vstate.ForkRecovery)
+	if err != nil || !active {
+		return nil, err
+	}
+	return t.state.AccountOwner(address, false)
+}
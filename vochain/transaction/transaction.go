@@ -30,6 +30,9 @@ var (
 	// ErrorAlreadyExistInCache is returned if the transaction has been already processed
 	// and stored in the vote cache.
 	ErrorAlreadyExistInCache = fmt.Errorf("transaction already exist in cache")
+	// ErrForkNotActive is returned if the transaction depends on a consensus
+	// change whose fork height has not been reached yet.
+	ErrForkNotActive = fmt.Errorf("fork not active")
 )
 
 // TransactionResponse is the response of a transaction check.
@@ -55,6 +58,20 @@ func NewTransactionHandler(state *vstate.State, istc *ist.Controller) *Transacti
 	}
 }
 
+// requireFork returns ErrForkNotActive if the fork is not applied at the
+// current height, so the nodes reject the transactions that the nodes without
+// the consensus change reject as well.
+func (t *TransactionHandler) requireFork(fork string) error {
+	active, err := t.state.ForkActive(fork)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return fmt.Errorf("%w: %s", ErrForkNotActive, fork)
+	}
+	return nil
+}
+
 // CheckTx check the validity of a transaction and adds it to the state if forCommit=true.
 // It returns a bytes value which depends on the transaction type:
 //
@@ -67,6 +84,14 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 	response := &TransactionResponse{
 		TxHash: vtx.TxID[:],
 	}
+	if err := t.checkMultisigFork(vtx); err != nil {
+		return nil, err
+	}
+	if vtx.Multisig != nil {
+		if err := checkMultisigTxType(vtx); err != nil {
+			return nil, err
+		}
+	}
 	if forCommit {
 		if err := t.checkAccountNonce(vtx); err != nil {
 			return nil, fmt.Errorf("checkAccountNonce: %w", err)
@@ -80,7 +105,13 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 		}
 		response.Data = v.Nullifier
 		if forCommit {
-			return response, t.state.AddVote(v)
+			if err := t.lockVoteDeposit(v, vtx.TxID[:]); err != nil {
+				return nil, fmt.Errorf("voteTx: %w", err)
+			}
+			if err := t.state.AddVote(v); err != nil {
+				return nil, err
+			}
+			return response, t.setGroupNullifier(v)
 		}
 
 	case *models.Tx_Admin:
@@ -101,6 +132,14 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 				if err := t.state.RevealProcessKeys(tx); err != nil {
 					return nil, fmt.Errorf("revealProcessKeys: %w", err)
 				}
+			case vochaintx.TxTypeSetProcessKeyShares:
+				shares, err := vochaintx.ProcessKeyShares(tx)
+				if err != nil {
+					return nil, fmt.Errorf("setProcessKeyShares: %w", err)
+				}
+				if err := t.state.SetProcessKeyShares(tx.ProcessId, tx.GetKeyIndex(), shares); err != nil {
+					return nil, fmt.Errorf("setProcessKeyShares: %w", err)
+				}
 			default:
 				return nil, fmt.Errorf("tx not supported")
 			}
@@ -212,6 +251,16 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 				if err := t.state.SetProcessDuration(tx.ProcessId, tx.GetDuration(), true); err != nil {
 					return nil, fmt.Errorf("setProcessCensus: %s", err)
 				}
+				// since the process end fork, the process ends at its new end time
+				forkActive, err := t.state.ForkActive(vstate.ForkProcessEnd)
+				if err != nil {
+					return nil, fmt.Errorf("setProcessDuration: %w", err)
+				}
+				if forkActive {
+					if err := t.istc.RescheduleProcessEnd(tx.ProcessId, process.StartTime+tx.GetDuration()); err != nil {
+						return nil, fmt.Errorf("setProcessDuration: cannot reschedule end process: %w", err)
+					}
+				}
 			default:
 				return nil, fmt.Errorf("unknown set process tx type")
 			}
@@ -247,6 +296,207 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 			if err := t.SetAccountValidatorTxCheck(vtx); err != nil {
 				return nil, fmt.Errorf("setAccountValidatorTx: %w", err)
 			}
+		case vochaintx.TxTypeSetAccountKV:
+			txAccount, key, value, err := t.SetAccountKVTxCheck(vtx)
+			if err != nil {
+				return nil, fmt.Errorf("setAccountKVTx: %w", err)
+			}
+			if forCommit {
+				txSenderAddress, err := ethereum.AddrFromSignature(vtx.SignedBody, vtx.Signature)
+				if err != nil {
+					return nil, fmt.Errorf("setAccountKV: txSenderAddress %w", err)
+				}
+				txCost, err := t.state.TxCost(vochaintx.TxTypeSetAccountKV, false)
+				if err != nil {
+					return nil, fmt.Errorf("setAccountKV: txCost: %w", err)
+				}
+				// check for a faucet package and if exist, transfer the amount to the tx sender
+				if _, err := t.checkFaucetPackageAndTransfer(tx.FaucetPackage, txCost, txSenderAddress, vtx.TxID[:], true); err != nil {
+					return nil, err
+				}
+				if err := t.state.BurnTxCostIncrementNonce(
+					txSenderAddress,
+					vochaintx.TxTypeSetAccountKV,
+					txCost,
+					key,
+				); err != nil {
+					return nil, fmt.Errorf("setAccountKV: burnTxCostIncrementNonce %w", err)
+				}
+				if err := t.state.SetAccountKV(txAccount, key, value); err != nil {
+					return nil, fmt.Errorf("setAccountKV: %w", err)
+				}
+			}
+			return response, nil
+		case vochaintx.TxTypeCreateMultisigAccount:
+			multisig, txSenderAddress, err := t.CreateMultisigAccountTxCheck(vtx)
+			if err != nil {
+				return nil, fmt.Errorf("createMultisigAccountTx: %w", err)
+			}
+			response.Data = multisig.Address().Bytes()
+			if forCommit {
+				txCost, err := t.state.TxCost(vochaintx.TxTypeCreateMultisigAccount, false)
+				if err != nil {
+					return nil, fmt.Errorf("createMultisigAccount: txCost: %w", err)
+				}
+				// check for a faucet package and if exist, transfer the amount to the tx sender
+				if _, err := t.checkFaucetPackageAndTransfer(tx.FaucetPackage, txCost, txSenderAddress, vtx.TxID[:], true); err != nil {
+					return nil, err
+				}
+				if err := t.state.BurnTxCostIncrementNonce(
+					txSenderAddress,
+					vochaintx.TxTypeCreateMultisigAccount,
+					txCost,
+					multisig.Address().Hex(),
+				); err != nil {
+					return nil, fmt.Errorf("createMultisigAccount: burnTxCostIncrementNonce %w", err)
+				}
+				if _, err := t.state.CreateMultisigAccount(multisig); err != nil {
+					return nil, fmt.Errorf("createMultisigAccount: %w", err)
+				}
+			}
+			return response, nil
+		case vochaintx.TxTypeSetBlockTiming:
+			timing, txSenderAddress, err := t.SetBlockTimingTxCheck(vtx)
+			if err != nil {
+				return nil, fmt.Errorf("setBlockTimingTx: %w", err)
+			}
+			if forCommit {
+				txCost, err := t.state.TxCost(vochaintx.TxTypeSetBlockTiming, false)
+				if err != nil {
+					return nil, fmt.Errorf("setBlockTiming: txCost: %w", err)
+				}
+				// check for a faucet package and if exist, transfer the amount to the tx sender
+				if _, err := t.checkFaucetPackageAndTransfer(tx.FaucetPackage, txCost, txSenderAddress, vtx.TxID[:], true); err != nil {
+					return nil, err
+				}
+				if err := t.state.BurnTxCostIncrementNonce(
+					txSenderAddress,
+					vochaintx.TxTypeSetBlockTiming,
+					txCost,
+					fmt.Sprintf("%d/%d", timing.BlockTime, timing.EmptyBlocksInterval),
+				); err != nil {
+					return nil, fmt.Errorf("setBlockTiming: burnTxCostIncrementNonce %w", err)
+				}
+				// the block timing is only set once a quorum of validators vote for it
+				if _, err := t.state.VoteBlockTiming(txSenderAddress, timing); err != nil {
+					return nil, fmt.Errorf("setBlockTiming: %w", err)
+				}
+			}
+			return response, nil
+		case vochaintx.TxTypeDelegateVoteWeight:
+			delegation, txSenderAddress, weight, err := t.DelegateVoteWeightTxCheck(vtx)
+			if err != nil {
+				return nil, fmt.Errorf("delegateVoteWeightTx: %w", err)
+			}
+			if forCommit {
+				txCost, err := t.state.TxCost(vochaintx.TxTypeDelegateVoteWeight, false)
+				if err != nil {
+					return nil, fmt.Errorf("delegateVoteWeight: txCost: %w", err)
+				}
+				// check for a faucet package and if exist, transfer the amount to the tx sender
+				if _, err := t.checkFaucetPackageAndTransfer(tx.FaucetPackage, txCost, txSenderAddress, vtx.TxID[:], true); err != nil {
+					return nil, err
+				}
+				if err := t.state.BurnTxCostIncrementNonce(
+					txSenderAddress,
+					vochaintx.TxTypeDelegateVoteWeight,
+					txCost,
+					fmt.Sprintf("%x", delegation.ProcessID),
+				); err != nil {
+					return nil, fmt.Errorf("delegateVoteWeight: burnTxCostIncrementNonce %w", err)
+				}
+				var stateDelegation *vstate.VoteDelegation
+				if delegation.Delegate != nil {
+					stateDelegation = &vstate.VoteDelegation{Delegate: *delegation.Delegate, Weight: weight}
+				}
+				if err := t.state.SetVoteDelegation(delegation.ProcessID, txSenderAddress, stateDelegation); err != nil {
+					return nil, fmt.Errorf("delegateVoteWeight: %w", err)
+				}
+			}
+			return response, nil
+		case vochaintx.TxTypeSetRecoveryGuardians:
+			guardians, txSenderAddress, err := t.SetRecoveryGuardiansTxCheck(vtx)
+			if err != nil {
+				return nil, fmt.Errorf("setRecoveryGuardiansTx: %w", err)
+			}
+			if forCommit {
+				txCost, err := t.state.TxCost(vochaintx.TxTypeSetRecoveryGuardians, false)
+				if err != nil {
+					return nil, fmt.Errorf("setRecoveryGuardians: txCost: %w", err)
+				}
+				// check for a faucet package and if exist, transfer the amount to the tx sender
+				if _, err := t.checkFaucetPackageAndTransfer(tx.FaucetPackage, txCost, txSenderAddress, vtx.TxID[:], true); err != nil {
+					return nil, err
+				}
+				if err := t.state.BurnTxCostIncrementNonce(
+					txSenderAddress,
+					vochaintx.TxTypeSetRecoveryGuardians,
+					txCost,
+					txSenderAddress.Hex(),
+				); err != nil {
+					return nil, fmt.Errorf("setRecoveryGuardians: burnTxCostIncrementNonce %w", err)
+				}
+				if err := t.state.SetRecoveryGuardians(txSenderAddress, guardians); err != nil {
+					return nil, fmt.Errorf("setRecoveryGuardians: %w", err)
+				}
+			}
+			return response, nil
+		case vochaintx.TxTypeRecoverAccount:
+			account, newOwner, txSenderAddress, err := t.RecoverAccountTxCheck(vtx)
+			if err != nil {
+				return nil, fmt.Errorf("recoverAccountTx: %w", err)
+			}
+			if forCommit {
+				txCost, err := t.state.TxCost(vochaintx.TxTypeRecoverAccount, false)
+				if err != nil {
+					return nil, fmt.Errorf("recoverAccount: txCost: %w", err)
+				}
+				// check for a faucet package and if exist, transfer the amount to the tx sender
+				if _, err := t.checkFaucetPackageAndTransfer(tx.FaucetPackage, txCost, txSenderAddress, vtx.TxID[:], true); err != nil {
+					return nil, err
+				}
+				if err := t.state.BurnTxCostIncrementNonce(
+					txSenderAddress,
+					vochaintx.TxTypeRecoverAccount,
+					txCost,
+					account.Hex(),
+				); err != nil {
+					return nil, fmt.Errorf("recoverAccount: burnTxCostIncrementNonce %w", err)
+				}
+				if _, err := t.state.ApproveAccountRecovery(account, txSenderAddress, newOwner); err != nil {
+					return nil, fmt.Errorf("recoverAccount: %w", err)
+				}
+			}
+			return response, nil
+		case vochaintx.TxTypeExecuteAccountRecovery:
+			account, txSenderAddress, err := t.ExecuteAccountRecoveryTxCheck(vtx)
+			if err != nil {
+				return nil, fmt.Errorf("executeAccountRecoveryTx: %w", err)
+			}
+			if forCommit {
+				txCost, err := t.state.TxCost(vochaintx.TxTypeExecuteAccountRecovery, false)
+				if err != nil {
+					return nil, fmt.Errorf("executeAccountRecovery: txCost: %w", err)
+				}
+				// check for a faucet package and if exist, transfer the amount to the tx sender
+				if _, err := t.checkFaucetPackageAndTransfer(tx.FaucetPackage, txCost, txSenderAddress, vtx.TxID[:], true); err != nil {
+					return nil, err
+				}
+				if err := t.state.BurnTxCostIncrementNonce(
+					txSenderAddress,
+					vochaintx.TxTypeExecuteAccountRecovery,
+					txCost,
+					account.Hex(),
+				); err != nil {
+					return nil, fmt.Errorf("executeAccountRecovery: burnTxCostIncrementNonce %w", err)
+				}
+				newOwner, err := t.state.ExecuteAccountRecovery(account)
+				if err != nil {
+					return nil, fmt.Errorf("executeAccountRecovery: %w", err)
+				}
+				response.Data = newOwner.Bytes()
+			}
+			return response, nil
 		default:
 			return nil, fmt.Errorf("setAccount: invalid transaction type")
 		}
@@ -272,7 +522,7 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 						return nil, fmt.Errorf("setAccountTx: SetAddressSIK %w", err)
 					}
 				}
-				txCost, err := t.state.TxBaseCost(models.TxType_CREATE_ACCOUNT, false)
+				txCost, err := t.state.TxCost(models.TxType_CREATE_ACCOUNT, false)
 				if err != nil {
 					return nil, fmt.Errorf("createAccountTx: txCost %w", err)
 				}
@@ -297,7 +547,7 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 				if err != nil {
 					return nil, fmt.Errorf("setAccountInfo: txSenderAddress %w", err)
 				}
-				txCost, err := t.state.TxBaseCost(models.TxType_SET_ACCOUNT_INFO_URI, false)
+				txCost, err := t.state.TxCost(models.TxType_SET_ACCOUNT_INFO_URI, false)
 				if err != nil {
 					return nil, fmt.Errorf("setAccountInfoUriTx: txCost: %w", err)
 				}
@@ -331,7 +581,7 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 				if err != nil {
 					return nil, fmt.Errorf("addDelegate: txSenderAddress %w", err)
 				}
-				txCost, err := t.state.TxBaseCost(models.TxType_ADD_DELEGATE_FOR_ACCOUNT, false)
+				txCost, err := t.state.TxCost(models.TxType_ADD_DELEGATE_FOR_ACCOUNT, false)
 				if err != nil {
 					return nil, fmt.Errorf("addDelegate: txCost: %w", err)
 				}
@@ -359,7 +609,7 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 				if err != nil {
 					return nil, fmt.Errorf("delDelegate: txSenderAddress %w", err)
 				}
-				txCost, err := t.state.TxBaseCost(models.TxType_DEL_DELEGATE_FOR_ACCOUNT, false)
+				txCost, err := t.state.TxCost(models.TxType_DEL_DELEGATE_FOR_ACCOUNT, false)
 				if err != nil {
 					return nil, fmt.Errorf("delDelegate: txCost: %w", err)
 				}
@@ -387,7 +637,7 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 				if err != nil {
 					return nil, fmt.Errorf("setValidator: txSenderAddress %w", err)
 				}
-				txCost, err := t.state.TxBaseCost(models.TxType_SET_ACCOUNT_VALIDATOR, false)
+				txCost, err := t.state.TxCost(models.TxType_SET_ACCOUNT_VALIDATOR, false)
 				if err != nil {
 					return nil, fmt.Errorf("setValidator: txCost: %w", err)
 				}
@@ -490,7 +740,7 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 			return nil, fmt.Errorf("setSIKTx: %w", err)
 		}
 		if forCommit {
-			txCost, err := t.state.TxBaseCost(models.TxType_SET_ACCOUNT_SIK, false)
+			txCost, err := t.state.TxCost(models.TxType_SET_ACCOUNT_SIK, false)
 			if err != nil {
 				return nil, fmt.Errorf("setAccountInfoUriTx: txCost: %w", err)
 			}
@@ -518,7 +768,7 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 			return nil, fmt.Errorf("delSIKTx: %w", err)
 		}
 		if forCommit {
-			txCost, err := t.state.TxBaseCost(models.TxType_DEL_ACCOUNT_SIK, false)
+			txCost, err := t.state.TxCost(models.TxType_DEL_ACCOUNT_SIK, false)
 			if err != nil {
 				return nil, fmt.Errorf("delSIKTx: txCost: %w", err)
 			}
@@ -575,14 +825,10 @@ func (t *TransactionHandler) CheckTx(vtx *vochaintx.Tx, forCommit bool) (*Transa
 // It also checks if a faucet package is available in the transaction and can pay for it.
 // The cost parameter is optional, if not provided, the transaction base cost for the txType is used.
 func (t *TransactionHandler) checkAccountCanPayCost(txType models.TxType, vtx *vochaintx.Tx, cost uint64) (*vstate.Account, *common.Address, error) {
-	// extract sender address from signature
-	pubKey, err := ethereum.PubKeyFromSignature(vtx.SignedBody, vtx.Signature)
-	if err != nil {
-		return nil, nil, fmt.Errorf("cannot extract public key from vtx.Signature: %w", err)
-	}
-	txSenderAddress, err := ethereum.AddrFromPublicKey(pubKey)
+	// extract sender address from signature, or signatures if multisig
+	txSenderAddress, err := t.txSenderAddress(vtx)
 	if err != nil {
-		return nil, nil, fmt.Errorf("cannot extract address from public key: %w", err)
+		return nil, nil, err
 	}
 	txSenderAcc, err := t.state.GetAccount(txSenderAddress, false)
 	if err != nil {
@@ -593,9 +839,9 @@ func (t *TransactionHandler) checkAccountCanPayCost(txType models.TxType, vtx *v
 	}
 	// get setAccount tx cost
 	if cost == 0 {
-		cost, err = t.state.TxBaseCost(txType, false)
+		cost, err = t.state.TxCost(txType, false)
 		if err != nil {
-			return nil, nil, fmt.Errorf("cannot get tx cost for %s: %w", txType.String(), err)
+			return nil, nil, fmt.Errorf("cannot get tx cost for %s: %w", vochaintx.TxTypeName(txType), err)
 		}
 	}
 
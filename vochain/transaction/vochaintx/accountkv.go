@@ -0,0 +1,119 @@
+package vochaintx
+
+import (
+	"fmt"
+
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// TxTypeSetAccountKV is the txtype of the SetAccountTx transactions that set an
+// entry of the account key-value store. Since it is not part of models.TxType,
+// the entry is encoded as two fields of the SetAccountTx which are not part of
+// its protobuf definition (see NewSetAccountKVTx). The transactions are only
+// accepted once the state.ForkAccountKV fork is applied.
+const TxTypeSetAccountKV models.TxType = 29
+
+// TxTypeSetAccountKVName is the name of TxTypeSetAccountKV, as it would be
+// defined in models.TxType.
+const TxTypeSetAccountKVName = "SET_ACCOUNT_KV"
+
+const (
+	accountKVKeyField   protowire.Number = 1000
+	accountKVValueField protowire.Number = 1001
+)
+
+// TxTypeName returns the name of the transaction type, including the ones not
+// defined in models.TxType. These transaction types, and the fields which are
+// not part of the protobuf definitions, must be moved to go.vocdoni.io/proto
+// with the same numbers, so the encoded transactions stay compatible; until
+// then TestProtoExtensions checks that they do not collide with the upstream
+// ones.
+func TxTypeName(txType models.TxType) string {
+	switch txType {
+	case TxTypeSetAccountKV:
+		return TxTypeSetAccountKVName
+	case TxTypeCreateMultisigAccount:
+		return TxTypeCreateMultisigAccountName
+	case TxTypeSetBlockTiming:
+		return TxTypeSetBlockTimingName
+	case TxTypeSetProcessKeyShares:
+		return TxTypeSetProcessKeySharesName
+	case TxTypeVoteDeposit:
+		return TxTypeVoteDepositName
+	case TxTypeDelegateVoteWeight:
+		return TxTypeDelegateVoteWeightName
+	case TxTypeSetRecoveryGuardians:
+		return TxTypeSetRecoveryGuardiansName
+	case TxTypeRecoverAccount:
+		return TxTypeRecoverAccountName
+	case TxTypeExecuteAccountRecovery:
+		return TxTypeExecuteAccountRecoveryName
+	}
+	return txType.String()
+}
+
+// NewSetAccountKVTx returns a SetAccountTx that sets the entry key of the
+// account key-value store to value, or deletes it if value is empty. The
+// account is the one of the tx signer, unless account is set, in which case
+// the signer must be one of its delegates.
+func NewSetAccountKVTx(nonce uint32, account []byte, key string, value []byte) *models.SetAccountTx {
+	tx := &models.SetAccountTx{
+		Txtype:  TxTypeSetAccountKV,
+		Nonce:   &nonce,
+		Account: account,
+	}
+	var b []byte
+	b = protowire.AppendTag(b, accountKVKeyField, protowire.BytesType)
+	b = protowire.AppendString(b, key)
+	if len(value) > 0 {
+		b = protowire.AppendTag(b, accountKVValueField, protowire.BytesType)
+		b = protowire.AppendBytes(b, value)
+	}
+	tx.ProtoReflect().SetUnknown(b)
+	return tx
+}
+
+// AccountKVEntry decodes the key and value of a SetAccountTx of type
+// TxTypeSetAccountKV. The value is nil if the entry is deleted.
+func AccountKVEntry(tx *models.SetAccountTx) (string, []byte, error) {
+	if tx.GetTxtype() != TxTypeSetAccountKV {
+		return "", nil, fmt.Errorf("invalid txtype %s", TxTypeName(tx.GetTxtype()))
+	}
+	var key string
+	var value []byte
+	hasKey := false
+	b := tx.ProtoReflect().GetUnknown()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return "", nil, fmt.Errorf("invalid transaction field: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		if num == accountKVKeyField || num == accountKVValueField {
+			if typ != protowire.BytesType {
+				return "", nil, fmt.Errorf("invalid wire type %d for field %d", typ, num)
+			}
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return "", nil, fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+			}
+			if num == accountKVKeyField {
+				key, hasKey = string(v), true
+			} else {
+				value = append([]byte{}, v...)
+			}
+			b = b[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return "", nil, fmt.Errorf("invalid transaction field %d: %w", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+	}
+	if !hasKey {
+		return "", nil, fmt.Errorf("missing key")
+	}
+	return key, value, nil
+}
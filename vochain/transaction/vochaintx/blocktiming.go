@@ -0,0 +1,73 @@
+package vochaintx
+
+import (
+	"fmt"
+
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// TxTypeSetBlockTiming is the txtype of the SetAccountTx transactions, sent by
+// the validators, that vote the block time and the empty blocks interval of
+// the network, which are set once more than two thirds of the voting power
+// vote for them. Each node applies the new block timing once restarted, since
+// the consensus timeouts cannot be changed on a running node. The transaction
+// is only accepted from the state.ForkBlockTiming fork. As TxTypeSetAccountKV, the parameters are encoded as fields which
+// are not part of the SetAccountTx protobuf definition (see NewSetBlockTimingTx).
+const TxTypeSetBlockTiming models.TxType = 32
+
+// TxTypeSetBlockTimingName is the name of TxTypeSetBlockTiming, as it would be
+// defined in models.TxType.
+const TxTypeSetBlockTimingName = "SET_BLOCK_TIMING"
+
+const (
+	blockTimeField           protowire.Number = 1007
+	emptyBlocksIntervalField protowire.Number = 1008
+)
+
+// NewSetBlockTimingTx returns a SetAccountTx that sets the block time and the
+// empty blocks interval of the network, both in seconds.
+func NewSetBlockTimingTx(nonce, blockTime, emptyBlocksInterval uint32) *models.SetAccountTx {
+	tx := &models.SetAccountTx{
+		Txtype: TxTypeSetBlockTiming,
+		Nonce:  &nonce,
+	}
+	var b []byte
+	b = protowire.AppendTag(b, blockTimeField, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(blockTime))
+	b = protowire.AppendTag(b, emptyBlocksIntervalField, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(emptyBlocksInterval))
+	tx.ProtoReflect().SetUnknown(b)
+	return tx
+}
+
+// BlockTimingParams decodes the block time and the empty blocks interval of a
+// SetAccountTx of type TxTypeSetBlockTiming.
+func BlockTimingParams(tx *models.SetAccountTx) (blockTime, emptyBlocksInterval uint32, err error) {
+	if tx.GetTxtype() != TxTypeSetBlockTiming {
+		return 0, 0, fmt.Errorf("invalid txtype %s", TxTypeName(tx.GetTxtype()))
+	}
+	err = consumeUnknownFields(tx.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case blockTimeField, emptyBlocksIntervalField:
+			if typ != protowire.VarintType {
+				return 0, fmt.Errorf("invalid wire type %d for field %d", typ, num)
+			}
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return 0, fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+			}
+			if v > uint64(^uint32(0)) {
+				return 0, fmt.Errorf("invalid field %d value %d", num, v)
+			}
+			if num == blockTimeField {
+				blockTime = uint32(v)
+			} else {
+				emptyBlocksInterval = uint32(v)
+			}
+			return n, nil
+		}
+		return -1, nil
+	})
+	return blockTime, emptyBlocksInterval, err
+}
@@ -0,0 +1,128 @@
+package vochaintx
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// TxTypeDelegateVoteWeight is the txtype of the SetAccountTx transactions that
+// delegate the vote weight of a census member of a process to another member,
+// or revoke the delegation, before the process starts. As TxTypeSetAccountKV,
+// the process, the delegate and the census proof of the sender are encoded as
+// fields which are not part of the SetAccountTx protobuf definition (see
+// NewDelegateVoteWeightTx).
+const TxTypeDelegateVoteWeight models.TxType = 35
+
+// TxTypeDelegateVoteWeightName is the name of TxTypeDelegateVoteWeight, as it
+// would be defined in models.TxType.
+const TxTypeDelegateVoteWeightName = "DELEGATE_VOTE_WEIGHT"
+
+const (
+	delegationProcessIDField protowire.Number = 1015
+	delegationDelegateField  protowire.Number = 1016
+	delegationProofField     protowire.Number = 1017
+)
+
+// VoteDelegation holds the parameters of a TxTypeDelegateVoteWeight transaction.
+type VoteDelegation struct {
+	ProcessID []byte
+	// Delegate is the census member the weight is delegated to, or nil if the
+	// delegation of the sender is revoked.
+	Delegate *common.Address
+	// Proof is the census proof of the sender, which sets the delegated weight.
+	// It is not required to revoke a delegation.
+	Proof *models.Proof
+}
+
+// NewDelegateVoteWeightTx returns a SetAccountTx that delegates the vote weight
+// of the sender in the process to the delegate, proving it with the census
+// proof of the sender.
+func NewDelegateVoteWeightTx(nonce uint32, processID []byte, delegate common.Address,
+	proof *models.Proof,
+) (*models.SetAccountTx, error) {
+	return newDelegateVoteWeightTx(nonce, &VoteDelegation{ProcessID: processID, Delegate: &delegate, Proof: proof})
+}
+
+// NewRevokeVoteDelegationTx returns a SetAccountTx that revokes the delegation
+// of the vote weight of the sender in the process.
+func NewRevokeVoteDelegationTx(nonce uint32, processID []byte) (*models.SetAccountTx, error) {
+	return newDelegateVoteWeightTx(nonce, &VoteDelegation{ProcessID: processID})
+}
+
+func newDelegateVoteWeightTx(nonce uint32, delegation *VoteDelegation) (*models.SetAccountTx, error) {
+	tx := &models.SetAccountTx{
+		Txtype: TxTypeDelegateVoteWeight,
+		Nonce:  &nonce,
+	}
+	var b []byte
+	b = protowire.AppendTag(b, delegationProcessIDField, protowire.BytesType)
+	b = protowire.AppendBytes(b, delegation.ProcessID)
+	if delegation.Delegate != nil {
+		b = protowire.AppendTag(b, delegationDelegateField, protowire.BytesType)
+		b = protowire.AppendBytes(b, delegation.Delegate.Bytes())
+	}
+	if delegation.Proof != nil {
+		proof, err := proto.Marshal(delegation.Proof)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode census proof: %w", err)
+		}
+		b = protowire.AppendTag(b, delegationProofField, protowire.BytesType)
+		b = protowire.AppendBytes(b, proof)
+	}
+	tx.ProtoReflect().SetUnknown(b)
+	return tx, nil
+}
+
+// VoteDelegationParams decodes the parameters of a SetAccountTx of type
+// TxTypeDelegateVoteWeight. A delegation requires the census proof, while a
+// revocation must not carry any.
+func VoteDelegationParams(tx *models.SetAccountTx) (*VoteDelegation, error) {
+	if tx.GetTxtype() != TxTypeDelegateVoteWeight {
+		return nil, fmt.Errorf("invalid txtype %s", TxTypeName(tx.GetTxtype()))
+	}
+	delegation := &VoteDelegation{}
+	err := consumeUnknownFields(tx.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case delegationProcessIDField, delegationDelegateField, delegationProofField:
+		default:
+			return -1, nil
+		}
+		if typ != protowire.BytesType {
+			return 0, fmt.Errorf("invalid wire type %d for field %d", typ, num)
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+		}
+		switch num {
+		case delegationProcessIDField:
+			delegation.ProcessID = append([]byte{}, v...)
+		case delegationDelegateField:
+			if len(v) != common.AddressLength {
+				return 0, fmt.Errorf("invalid delegate address length %d", len(v))
+			}
+			delegate := common.BytesToAddress(v)
+			delegation.Delegate = &delegate
+		case delegationProofField:
+			delegation.Proof = &models.Proof{}
+			if err := proto.Unmarshal(v, delegation.Proof); err != nil {
+				return 0, fmt.Errorf("cannot decode census proof: %w", err)
+			}
+		}
+		return n, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(delegation.ProcessID) == 0 {
+		return nil, fmt.Errorf("missing process id")
+	}
+	if (delegation.Delegate != nil) != (delegation.Proof != nil) {
+		return nil, fmt.Errorf("a delegation requires both the delegate and the census proof, a revocation none of them")
+	}
+	return delegation, nil
+}
@@ -0,0 +1,82 @@
+package vochaintx
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The types of the events emitted by the transactions, see Event.
+const (
+	// EventProcessCreated is emitted when a process is created, with the
+	// attributes processId and entityId.
+	EventProcessCreated = "process_created"
+	// EventVoteAccepted is emitted when a vote is added to a process, with
+	// the attributes processId, nullifier and weight.
+	EventVoteAccepted = "vote_accepted"
+	// EventTokensTransferred is emitted when tokens are transferred between
+	// two accounts, with the attributes from, to and amount.
+	EventTokensTransferred = "tokens_transferred"
+)
+
+// Event is a structured event emitted by a transaction when it is delivered,
+// which is recorded in its result as an ABCI event.
+type Event struct {
+	Type       string           `json:"type"`
+	Attributes []EventAttribute `json:"attributes"`
+}
+
+// EventAttribute is a key-value attribute of an Event. The byte values are hex
+// encoded, without prefix, and the numbers in decimal.
+type EventAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Attribute returns the value of the attribute with the given key, or an empty
+// string if not found.
+func (e *Event) Attribute(key string) string {
+	for _, a := range e.Attributes {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Receipt holds the outcome of a transaction delivered in a block.
+type Receipt struct {
+	// Cost is the amount of tokens spent by the transaction fees.
+	Cost uint64
+	// Events are the events emitted by the transaction, in order.
+	Events []Event
+}
+
+// NewProcessCreatedEvent returns the EventProcessCreated of a process.
+func NewProcessCreatedEvent(processID, entityID []byte) Event {
+	return Event{Type: EventProcessCreated, Attributes: []EventAttribute{
+		{Key: "processId", Value: fmt.Sprintf("%x", processID)},
+		{Key: "entityId", Value: fmt.Sprintf("%x", entityID)},
+	}}
+}
+
+// NewVoteAcceptedEvent returns the EventVoteAccepted of a vote. The weight is
+// the decimal representation of the vote weight.
+func NewVoteAcceptedEvent(processID, nullifier []byte, weight string) Event {
+	return Event{Type: EventVoteAccepted, Attributes: []EventAttribute{
+		{Key: "processId", Value: fmt.Sprintf("%x", processID)},
+		{Key: "nullifier", Value: fmt.Sprintf("%x", nullifier)},
+		{Key: "weight", Value: weight},
+	}}
+}
+
+// NewTokensTransferredEvent returns the EventTokensTransferred of a token
+// transfer.
+func NewTokensTransferredEvent(from, to common.Address, amount uint64) Event {
+	return Event{Type: EventTokensTransferred, Attributes: []EventAttribute{
+		{Key: "from", Value: fmt.Sprintf("%x", from)},
+		{Key: "to", Value: fmt.Sprintf("%x", to)},
+		{Key: "amount", Value: strconv.FormatUint(amount, 10)},
+	}}
+}
@@ -0,0 +1,68 @@
+package vochaintx
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// TestProtoExtensions checks that the transaction types and the fields which
+// are not part of the go.vocdoni.io/proto definitions do not collide with the
+// ones defined there, nor with each other, until they are moved upstream.
+func TestProtoExtensions(t *testing.T) {
+	c := qt.New(t)
+
+	txTypes := map[models.TxType]string{
+		TxTypeSetAccountKV:           TxTypeSetAccountKVName,
+		TxTypeCreateMultisigAccount:  TxTypeCreateMultisigAccountName,
+		TxTypeSetBlockTiming:         TxTypeSetBlockTimingName,
+		TxTypeSetProcessKeyShares:    TxTypeSetProcessKeySharesName,
+		TxTypeVoteDeposit:            TxTypeVoteDepositName,
+		TxTypeDelegateVoteWeight:     TxTypeDelegateVoteWeightName,
+		TxTypeSetRecoveryGuardians:   TxTypeSetRecoveryGuardiansName,
+		TxTypeRecoverAccount:         TxTypeRecoverAccountName,
+		TxTypeExecuteAccountRecovery: TxTypeExecuteAccountRecoveryName,
+	}
+	for txType, name := range txTypes {
+		_, defined := models.TxType_name[int32(txType)]
+		c.Assert(defined, qt.IsFalse, qt.Commentf("txtype %d is defined upstream", txType))
+		_, defined = models.TxType_value[name]
+		c.Assert(defined, qt.IsFalse, qt.Commentf("txtype %s is defined upstream", name))
+		c.Assert(TxTypeName(txType), qt.Equals, name)
+	}
+
+	fields := []struct {
+		msg    protoreflect.ProtoMessage
+		fields []protowire.Number
+	}{
+		{&models.Tx{}, []protowire.Number{validFromHeightField, validUntilHeightField}},
+		{&models.SignedTx{}, []protowire.Number{multisigAccountField, multisigSignatureField}},
+		{&models.SetAccountTx{}, []protowire.Number{
+			accountKVKeyField, accountKVValueField,
+			multisigSignerField, multisigThresholdField,
+			blockTimeField, emptyBlocksIntervalField,
+			delegationProcessIDField, delegationDelegateField, delegationProofField,
+			recoveryGuardianField, recoveryQuorumField, recoveryTimelockField, recoveryNewOwnerField,
+		}},
+		{&models.AdminTx{}, []protowire.Number{processKeyShareField}},
+		{&models.ProcessMode{}, []protowire.Number{
+			processModeUnlistedField, processModeNullifierGroupField, processModeVoteDepositField,
+			processModeManualEndField, processModeVoteDelegationField,
+		}},
+	}
+	for _, m := range fields {
+		desc := m.msg.ProtoReflect().Descriptor()
+		seen := make(map[protowire.Number]bool)
+		for _, num := range m.fields {
+			c.Assert(seen[num], qt.IsFalse, qt.Commentf("field %d of %s is used twice", num, desc.FullName()))
+			seen[num] = true
+			c.Assert(desc.Fields().ByNumber(num), qt.IsNil,
+				qt.Commentf("field %d of %s is defined upstream", num, desc.FullName()))
+			c.Assert(desc.ReservedRanges().Has(num), qt.IsFalse,
+				qt.Commentf("field %d of %s is reserved upstream", num, desc.FullName()))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package vochaintx
+
+import (
+	"fmt"
+
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// TxTypeSetProcessKeyShares is the txtype of the AdminTx transactions, sent by
+// the keykeepers, that escrow the shares of their encryption private key of a
+// process, each one encrypted to the public key of another keykeeper of the
+// process. Once a quorum of keykeepers reveal their private keys, the shares
+// sent to them can be decrypted and the private key of a keykeeper which never
+// revealed it can be recovered. The shares are encoded as fields which are not
+// part of the AdminTx protobuf definition (see NewSetProcessKeySharesTx).
+const TxTypeSetProcessKeyShares models.TxType = 33
+
+// TxTypeSetProcessKeySharesName is the name of TxTypeSetProcessKeyShares, as it
+// would be defined in models.TxType.
+const TxTypeSetProcessKeySharesName = "SET_PROCESS_KEY_SHARES"
+
+const processKeyShareField protowire.Number = 1009
+
+// ProcessKeyShare is a share of the encryption private key of a keykeeper for a
+// process, encrypted to the public key of the keykeeper with KeyIndex.
+type ProcessKeyShare struct {
+	KeyIndex uint32 `json:"keyIndex"`
+	Cipher   []byte `json:"cipher"`
+}
+
+// ProcessKeySharesThreshold returns the number of shares needed to recover a
+// private key split into n shares, which is the majority of them.
+func ProcessKeySharesThreshold(n int) int {
+	return n/2 + 1
+}
+
+// NewSetProcessKeySharesTx returns an AdminTx that escrows the shares of the
+// private key of the keykeeper with keyIndex for the process.
+func NewSetProcessKeySharesTx(pid []byte, keyIndex uint32, shares []*ProcessKeyShare) *models.AdminTx {
+	tx := &models.AdminTx{
+		Txtype:    TxTypeSetProcessKeyShares,
+		ProcessId: pid,
+		KeyIndex:  &keyIndex,
+	}
+	var b []byte
+	for _, share := range shares {
+		b = protowire.AppendTag(b, processKeyShareField, protowire.BytesType)
+		b = protowire.AppendBytes(b, append([]byte{byte(share.KeyIndex)}, share.Cipher...))
+	}
+	tx.ProtoReflect().SetUnknown(b)
+	return tx
+}
+
+// ProcessKeyShares decodes the shares of an AdminTx of type
+// TxTypeSetProcessKeyShares.
+func ProcessKeyShares(tx *models.AdminTx) ([]*ProcessKeyShare, error) {
+	if tx.GetTxtype() != TxTypeSetProcessKeyShares {
+		return nil, fmt.Errorf("invalid txtype %s", TxTypeName(tx.GetTxtype()))
+	}
+	var shares []*ProcessKeyShare
+	err := consumeUnknownFields(tx.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != processKeyShareField {
+			return -1, nil
+		}
+		if typ != protowire.BytesType {
+			return 0, fmt.Errorf("invalid wire type %d for field %d", typ, num)
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, fmt.Errorf("invalid key share: %w", protowire.ParseError(n))
+		}
+		if len(v) < 2 {
+			return 0, fmt.Errorf("invalid key share length %d", len(v))
+		}
+		shares = append(shares, &ProcessKeyShare{
+			KeyIndex: uint32(v[0]),
+			Cipher:   append([]byte{}, v[1:]...),
+		})
+		return n, nil
+	})
+	return shares, err
+}
@@ -0,0 +1,204 @@
+package vochaintx
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// TxTypeCreateMultisigAccount is the txtype of the SetAccountTx transactions
+// that create a multisig account. As TxTypeSetAccountKV, the signers and the
+// threshold are encoded as fields which are not part of the SetAccountTx
+// protobuf definition (see NewCreateMultisigAccountTx).
+const TxTypeCreateMultisigAccount models.TxType = 30
+
+// TxTypeCreateMultisigAccountName is the name of TxTypeCreateMultisigAccount,
+// as it would be defined in models.TxType.
+const TxTypeCreateMultisigAccountName = "CREATE_MULTISIG_ACCOUNT"
+
+const (
+	multisigSignerField    protowire.Number = 1002
+	multisigThresholdField protowire.Number = 1003
+)
+
+// The fields of the SignedTx of a multisig transaction, which are not part of
+// its protobuf definition (see NewMultisigSignedTx).
+const (
+	multisigAccountField   protowire.Number = 1000
+	multisigSignatureField protowire.Number = 1001
+)
+
+// Multisig holds the signatures of a transaction sent on behalf of a multisig
+// account.
+type Multisig struct {
+	// Account is the address of the multisig account.
+	Account common.Address
+	// Signatures are the signatures of the signers of the account, the first
+	// one being also the signature of the SignedTx.
+	Signatures [][]byte
+}
+
+// NewCreateMultisigAccountTx returns a SetAccountTx that creates a multisig
+// account of the signers, which requires the signatures of threshold of them.
+func NewCreateMultisigAccountTx(nonce uint32, signers []common.Address, threshold uint32) *models.SetAccountTx {
+	tx := &models.SetAccountTx{
+		Txtype: TxTypeCreateMultisigAccount,
+		Nonce:  &nonce,
+	}
+	var b []byte
+	for _, signer := range signers {
+		b = protowire.AppendTag(b, multisigSignerField, protowire.BytesType)
+		b = protowire.AppendBytes(b, signer.Bytes())
+	}
+	b = protowire.AppendTag(b, multisigThresholdField, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(threshold))
+	tx.ProtoReflect().SetUnknown(b)
+	return tx
+}
+
+// MultisigAccountParams decodes the signers and the threshold of a SetAccountTx
+// of type TxTypeCreateMultisigAccount.
+func MultisigAccountParams(tx *models.SetAccountTx) ([]common.Address, uint32, error) {
+	if tx.GetTxtype() != TxTypeCreateMultisigAccount {
+		return nil, 0, fmt.Errorf("invalid txtype %s", TxTypeName(tx.GetTxtype()))
+	}
+	var signers []common.Address
+	threshold := uint64(0)
+	err := consumeUnknownFields(tx.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case multisigSignerField:
+			if typ != protowire.BytesType {
+				return 0, fmt.Errorf("invalid wire type %d for field %d", typ, num)
+			}
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return 0, fmt.Errorf("invalid signer: %w", protowire.ParseError(n))
+			}
+			if len(v) != common.AddressLength {
+				return 0, fmt.Errorf("invalid signer address %x", v)
+			}
+			signers = append(signers, common.BytesToAddress(v))
+			return n, nil
+		case multisigThresholdField:
+			if typ != protowire.VarintType {
+				return 0, fmt.Errorf("invalid wire type %d for field %d", typ, num)
+			}
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return 0, fmt.Errorf("invalid threshold: %w", protowire.ParseError(n))
+			}
+			threshold = v
+			return n, nil
+		}
+		return -1, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if threshold > uint64(^uint32(0)) {
+		return nil, 0, fmt.Errorf("invalid threshold %d", threshold)
+	}
+	return signers, uint32(threshold), nil
+}
+
+// NewMultisigSignedTx returns the SignedTx of the transaction tx sent on behalf
+// of the multisig account, signed by its signers with ethereum.SignKeys.SignVocdoniTx.
+// The first signature is set as the SignedTx signature, and the account and the
+// rest of signatures are encoded as fields which are not part of the SignedTx
+// protobuf definition.
+func NewMultisigSignedTx(tx []byte, account common.Address, signatures [][]byte) (*models.SignedTx, error) {
+	if len(signatures) == 0 {
+		return nil, fmt.Errorf("no signatures provided")
+	}
+	stx := &models.SignedTx{Tx: tx, Signature: signatures[0]}
+	var b []byte
+	b = protowire.AppendTag(b, multisigAccountField, protowire.BytesType)
+	b = protowire.AppendBytes(b, account.Bytes())
+	for _, signature := range signatures[1:] {
+		b = protowire.AppendTag(b, multisigSignatureField, protowire.BytesType)
+		b = protowire.AppendBytes(b, signature)
+	}
+	stx.ProtoReflect().SetUnknown(b)
+	return stx, nil
+}
+
+// MultisigErr returns the error decoding the multisig signatures of the
+// transaction, which is not returned by Unmarshal since the nodes without
+// multisig accounts ignore the signatures.
+func (tx *Tx) MultisigErr() error {
+	return tx.multisigErr
+}
+
+// DropMultisig discards the multisig signatures of the transaction and the
+// error decoding them, so it is handled as a single signed transaction.
+func (tx *Tx) DropMultisig() {
+	tx.Multisig = nil
+	tx.multisigErr = nil
+}
+
+// multisigSignatures decodes the multisig account and signatures of a SignedTx,
+// returning nil if it is not a multisig transaction.
+func multisigSignatures(stx *models.SignedTx) (*Multisig, error) {
+	var account []byte
+	var signatures [][]byte
+	err := consumeUnknownFields(stx.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != multisigAccountField && num != multisigSignatureField {
+			return -1, nil
+		}
+		if typ != protowire.BytesType {
+			return 0, fmt.Errorf("invalid wire type %d for field %d", typ, num)
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+		}
+		if num == multisigAccountField {
+			account = v
+		} else {
+			signatures = append(signatures, append([]byte{}, v...))
+		}
+		return n, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		if len(signatures) > 0 {
+			return nil, fmt.Errorf("multisig signatures without account")
+		}
+		return nil, nil
+	}
+	if len(account) != common.AddressLength {
+		return nil, fmt.Errorf("invalid multisig account %x", account)
+	}
+	return &Multisig{
+		Account:    common.BytesToAddress(account),
+		Signatures: append([][]byte{stx.GetSignature()}, signatures...),
+	}, nil
+}
+
+// consumeUnknownFields iterates over the unknown fields b of a protobuf message,
+// calling f with the content of each field. f returns the length of the field
+// content consumed, or -1 to skip it.
+func consumeUnknownFields(b []byte, f func(num protowire.Number, typ protowire.Type, b []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("invalid transaction field: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		n, err := f(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return fmt.Errorf("invalid transaction field %d: %w", num, protowire.ParseError(n))
+			}
+		}
+		b = b[n:]
+	}
+	return nil
+}
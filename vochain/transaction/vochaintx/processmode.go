@@ -0,0 +1,221 @@
+package vochaintx
+
+import (
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The fields of the ProcessMode not part of its protobuf definition. The state
+// keeps them since the processes are stored as encoded protobuf.
+const (
+	// processModeUnlistedField marks the process as unlisted.
+	processModeUnlistedField protowire.Number = 1010
+	// processModeNullifierGroupField is the nullifier group of the process.
+	processModeNullifierGroupField protowire.Number = 1011
+	// processModeVoteDepositField is the deposit required to vote.
+	processModeVoteDepositField protowire.Number = 1012
+	// processModeManualEndField marks the process as ended only by its organizer.
+	processModeManualEndField protowire.Number = 1013
+	// processModeVoteDelegationField enables the vote weight delegation.
+	processModeVoteDelegationField protowire.Number = 1014
+)
+
+// TxTypeVoteDeposit is not the txtype of any transaction, but the one the
+// deposits of the votes which are not counted are burned with (see
+// ProcessModeVoteDeposit), so that they are indexed apart from the fees.
+const TxTypeVoteDeposit models.TxType = 34
+
+// TxTypeVoteDepositName is the name of TxTypeVoteDeposit, as it would be
+// defined in models.TxType.
+const TxTypeVoteDepositName = "VOTE_DEPOSIT"
+
+// ProcessModeUnlisted returns whether the process mode marks the process as
+// unlisted, which the indexer excludes from the public process lists, so that
+// it can only be found by its ID or by its organization.
+func ProcessModeUnlisted(mode *models.ProcessMode) bool {
+	if mode == nil {
+		return false
+	}
+	unlisted := false
+	if err := consumeUnknownFields(mode.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != processModeUnlistedField || typ != protowire.VarintType {
+			return -1, nil
+		}
+		v, n := protowire.ConsumeVarint(b)
+		if n >= 0 {
+			unlisted = v != 0
+		}
+		return n, nil
+	}); err != nil {
+		return false
+	}
+	return unlisted
+}
+
+// SetProcessModeUnlisted marks the process mode as unlisted, or clears the mark.
+func SetProcessModeUnlisted(mode *models.ProcessMode, unlisted bool) {
+	b := processModeUnknownFieldsWithout(mode, processModeUnlistedField)
+	if unlisted {
+		b = protowire.AppendTag(b, processModeUnlistedField, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+	mode.ProtoReflect().SetUnknown(b)
+}
+
+// ProcessModeNullifierGroup returns the nullifier group of the process mode, or
+// nil if the process is not part of any. The anonymous processes of the same
+// organization and nullifier group share the nullifiers of their voters, so
+// that an identity can only vote in one of them. The processes can only join a
+// nullifier group since the state.ForkNullifierGroups fork.
+func ProcessModeNullifierGroup(mode *models.ProcessMode) []byte {
+	if mode == nil {
+		return nil
+	}
+	var group []byte
+	if err := consumeUnknownFields(mode.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != processModeNullifierGroupField || typ != protowire.BytesType {
+			return -1, nil
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n >= 0 {
+			group = append([]byte{}, v...)
+		}
+		return n, nil
+	}); err != nil {
+		return nil
+	}
+	return group
+}
+
+// SetProcessModeNullifierGroup sets the nullifier group of the process mode, or
+// clears it if group is empty.
+func SetProcessModeNullifierGroup(mode *models.ProcessMode, group []byte) {
+	b := processModeUnknownFieldsWithout(mode, processModeNullifierGroupField)
+	if len(group) > 0 {
+		b = protowire.AppendTag(b, processModeNullifierGroupField, protowire.BytesType)
+		b = protowire.AppendBytes(b, group)
+	}
+	mode.ProtoReflect().SetUnknown(b)
+}
+
+// ProcessModeVoteDeposit returns the number of tokens each voter of the process
+// must lock to cast a vote, or zero if voting does not require a deposit. The
+// deposits are refunded once the results of the process are committed, except
+// for the votes which are not counted, and on cancellation. The processes can
+// only require a deposit since the state.ForkVoteDeposit fork.
+func ProcessModeVoteDeposit(mode *models.ProcessMode) uint64 {
+	if mode == nil {
+		return 0
+	}
+	var deposit uint64
+	if err := consumeUnknownFields(mode.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != processModeVoteDepositField || typ != protowire.VarintType {
+			return -1, nil
+		}
+		v, n := protowire.ConsumeVarint(b)
+		if n >= 0 {
+			deposit = v
+		}
+		return n, nil
+	}); err != nil {
+		return 0
+	}
+	return deposit
+}
+
+// SetProcessModeVoteDeposit sets the deposit required to vote in the process,
+// or clears it if deposit is zero.
+func SetProcessModeVoteDeposit(mode *models.ProcessMode, deposit uint64) {
+	b := processModeUnknownFieldsWithout(mode, processModeVoteDepositField)
+	if deposit > 0 {
+		b = protowire.AppendTag(b, processModeVoteDepositField, protowire.VarintType)
+		b = protowire.AppendVarint(b, deposit)
+	}
+	mode.ProtoReflect().SetUnknown(b)
+}
+
+// ProcessModeManualEnd returns whether the process mode opts out of the
+// automatic end of the process once its duration elapses, so that it stays
+// open until its organizer sets it as ended. The votes are not accepted after
+// the end time in any case. The opt-out is only applied since the
+// state.ForkProcessEnd fork.
+func ProcessModeManualEnd(mode *models.ProcessMode) bool {
+	if mode == nil {
+		return false
+	}
+	manualEnd := false
+	if err := consumeUnknownFields(mode.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != processModeManualEndField || typ != protowire.VarintType {
+			return -1, nil
+		}
+		v, n := protowire.ConsumeVarint(b)
+		if n >= 0 {
+			manualEnd = v != 0
+		}
+		return n, nil
+	}); err != nil {
+		return false
+	}
+	return manualEnd
+}
+
+// SetProcessModeManualEnd opts the process mode out of the automatic end, or
+// clears the opt-out.
+func SetProcessModeManualEnd(mode *models.ProcessMode, manualEnd bool) {
+	b := processModeUnknownFieldsWithout(mode, processModeManualEndField)
+	if manualEnd {
+		b = protowire.AppendTag(b, processModeManualEndField, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+	mode.ProtoReflect().SetUnknown(b)
+}
+
+// ProcessModeVoteDelegation returns whether the census members of the process
+// can delegate their vote weight to another member before the process starts,
+// see TxTypeDelegateVoteWeight. The processes can only accept delegations
+// since the state.ForkVoteDelegation fork.
+func ProcessModeVoteDelegation(mode *models.ProcessMode) bool {
+	if mode == nil {
+		return false
+	}
+	delegation := false
+	if err := consumeUnknownFields(mode.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != processModeVoteDelegationField || typ != protowire.VarintType {
+			return -1, nil
+		}
+		v, n := protowire.ConsumeVarint(b)
+		if n >= 0 {
+			delegation = v != 0
+		}
+		return n, nil
+	}); err != nil {
+		return false
+	}
+	return delegation
+}
+
+// SetProcessModeVoteDelegation enables the vote weight delegation in the
+// process mode, or disables it.
+func SetProcessModeVoteDelegation(mode *models.ProcessMode, delegation bool) {
+	b := processModeUnknownFieldsWithout(mode, processModeVoteDelegationField)
+	if delegation {
+		b = protowire.AppendTag(b, processModeVoteDelegationField, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+	mode.ProtoReflect().SetUnknown(b)
+}
+
+// processModeUnknownFieldsWithout returns the unknown fields of the process
+// mode, except the given one.
+func processModeUnknownFieldsWithout(mode *models.ProcessMode, field protowire.Number) []byte {
+	var b []byte
+	_ = consumeUnknownFields(mode.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+		n := protowire.ConsumeFieldValue(num, typ, v)
+		if n >= 0 && num != field {
+			b = protowire.AppendTag(b, num, typ)
+			b = append(b, v[:n]...)
+		}
+		return n, nil
+	})
+	return b
+}
@@ -0,0 +1,193 @@
+package vochaintx
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// TxTypeSetRecoveryGuardians is the txtype of the SetAccountTx transactions that
+// set the guardians of an account, a quorum of which can rotate its owner key
+// (see TxTypeRecoverAccount), or remove them if there are none. It also cancels
+// the pending recovery of the account, if any. As TxTypeSetAccountKV, the
+// guardians, the quorum and the timelock are encoded as fields which are not
+// part of the SetAccountTx protobuf definition (see NewSetRecoveryGuardiansTx).
+const TxTypeSetRecoveryGuardians models.TxType = 36
+
+// TxTypeSetRecoveryGuardiansName is the name of TxTypeSetRecoveryGuardians, as
+// it would be defined in models.TxType.
+const TxTypeSetRecoveryGuardiansName = "SET_RECOVERY_GUARDIANS"
+
+// TxTypeRecoverAccount is the txtype of the SetAccountTx transactions that
+// approve, on behalf of one of the guardians of an account, the rotation of its
+// owner key to a new owner. Once a quorum of guardians approve the same new
+// owner, the recovery can be executed after the timelock of the account (see
+// TxTypeExecuteAccountRecovery).
+const TxTypeRecoverAccount models.TxType = 37
+
+// TxTypeRecoverAccountName is the name of TxTypeRecoverAccount, as it would be
+// defined in models.TxType.
+const TxTypeRecoverAccountName = "RECOVER_ACCOUNT"
+
+// TxTypeExecuteAccountRecovery is the txtype of the SetAccountTx transactions
+// that rotate the owner key of an account to the new owner approved by its
+// guardians, once the timelock has elapsed. It can be sent by anyone.
+const TxTypeExecuteAccountRecovery models.TxType = 38
+
+// TxTypeExecuteAccountRecoveryName is the name of TxTypeExecuteAccountRecovery,
+// as it would be defined in models.TxType.
+const TxTypeExecuteAccountRecoveryName = "EXECUTE_ACCOUNT_RECOVERY"
+
+const (
+	recoveryGuardianField protowire.Number = 1018
+	recoveryQuorumField   protowire.Number = 1019
+	recoveryTimelockField protowire.Number = 1020
+	recoveryNewOwnerField protowire.Number = 1021
+)
+
+// NewSetRecoveryGuardiansTx returns a SetAccountTx that sets the guardians of
+// the account, quorum of which can rotate its owner key once timelock blocks
+// have elapsed since they approved it. No guardians remove them. The account is
+// the one of the tx signer, which is also set explicitly if account is not nil,
+// as required for the multisig and the recovered accounts.
+func NewSetRecoveryGuardiansTx(nonce uint32, account []byte, guardians []common.Address,
+	quorum, timelock uint32,
+) *models.SetAccountTx {
+	tx := &models.SetAccountTx{
+		Txtype:  TxTypeSetRecoveryGuardians,
+		Nonce:   &nonce,
+		Account: account,
+	}
+	var b []byte
+	for _, guardian := range guardians {
+		b = protowire.AppendTag(b, recoveryGuardianField, protowire.BytesType)
+		b = protowire.AppendBytes(b, guardian.Bytes())
+	}
+	b = protowire.AppendTag(b, recoveryQuorumField, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(quorum))
+	b = protowire.AppendTag(b, recoveryTimelockField, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(timelock))
+	tx.ProtoReflect().SetUnknown(b)
+	return tx
+}
+
+// NewRecoverAccountTx returns a SetAccountTx that approves, as a guardian of the
+// account, the rotation of its owner key to newOwner.
+func NewRecoverAccountTx(nonce uint32, account, newOwner common.Address) *models.SetAccountTx {
+	tx := &models.SetAccountTx{
+		Txtype:  TxTypeRecoverAccount,
+		Nonce:   &nonce,
+		Account: account.Bytes(),
+	}
+	var b []byte
+	b = protowire.AppendTag(b, recoveryNewOwnerField, protowire.BytesType)
+	b = protowire.AppendBytes(b, newOwner.Bytes())
+	tx.ProtoReflect().SetUnknown(b)
+	return tx
+}
+
+// NewExecuteAccountRecoveryTx returns a SetAccountTx that rotates the owner key
+// of the account to the new owner approved by its guardians.
+func NewExecuteAccountRecoveryTx(nonce uint32, account common.Address) *models.SetAccountTx {
+	return &models.SetAccountTx{
+		Txtype:  TxTypeExecuteAccountRecovery,
+		Nonce:   &nonce,
+		Account: account.Bytes(),
+	}
+}
+
+// RecoveryGuardiansParams decodes the guardians, the quorum and the timelock of
+// a SetAccountTx of type TxTypeSetRecoveryGuardians.
+func RecoveryGuardiansParams(tx *models.SetAccountTx) ([]common.Address, uint32, uint32, error) {
+	if tx.GetTxtype() != TxTypeSetRecoveryGuardians {
+		return nil, 0, 0, fmt.Errorf("invalid txtype %s", TxTypeName(tx.GetTxtype()))
+	}
+	var guardians []common.Address
+	quorum, timelock := uint64(0), uint64(0)
+	err := consumeUnknownFields(tx.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case recoveryGuardianField:
+			if typ != protowire.BytesType {
+				return 0, fmt.Errorf("invalid wire type %d for field %d", typ, num)
+			}
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return 0, fmt.Errorf("invalid guardian: %w", protowire.ParseError(n))
+			}
+			if len(v) != common.AddressLength {
+				return 0, fmt.Errorf("invalid guardian address %x", v)
+			}
+			guardians = append(guardians, common.BytesToAddress(v))
+			return n, nil
+		case recoveryQuorumField, recoveryTimelockField:
+			if typ != protowire.VarintType {
+				return 0, fmt.Errorf("invalid wire type %d for field %d", typ, num)
+			}
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return 0, fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+			}
+			if num == recoveryQuorumField {
+				quorum = v
+			} else {
+				timelock = v
+			}
+			return n, nil
+		}
+		return -1, nil
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if quorum > uint64(^uint32(0)) {
+		return nil, 0, 0, fmt.Errorf("invalid quorum %d", quorum)
+	}
+	if timelock > uint64(^uint32(0)) {
+		return nil, 0, 0, fmt.Errorf("invalid timelock %d", timelock)
+	}
+	return guardians, uint32(quorum), uint32(timelock), nil
+}
+
+// RecoverAccountNewOwner decodes the new owner of a SetAccountTx of type
+// TxTypeRecoverAccount.
+func RecoverAccountNewOwner(tx *models.SetAccountTx) (common.Address, error) {
+	if tx.GetTxtype() != TxTypeRecoverAccount {
+		return common.Address{}, fmt.Errorf("invalid txtype %s", TxTypeName(tx.GetTxtype()))
+	}
+	var newOwner []byte
+	err := consumeUnknownFields(tx.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != recoveryNewOwnerField {
+			return -1, nil
+		}
+		if typ != protowire.BytesType {
+			return 0, fmt.Errorf("invalid wire type %d for field %d", typ, num)
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, fmt.Errorf("invalid new owner: %w", protowire.ParseError(n))
+		}
+		newOwner = v
+		return n, nil
+	})
+	if err != nil {
+		return common.Address{}, err
+	}
+	if newOwner == nil {
+		return common.Address{}, fmt.Errorf("missing new owner")
+	}
+	if len(newOwner) != common.AddressLength {
+		return common.Address{}, fmt.Errorf("invalid new owner address %x", newOwner)
+	}
+	return common.BytesToAddress(newOwner), nil
+}
+
+// NewRecoveredAccountSignedTx returns the SignedTx of the transaction tx sent on
+// behalf of an account whose owner key was rotated by its guardians, signed by
+// its new owner with ethereum.SignKeys.SignVocdoniTx. As for the multisig
+// transactions (see NewMultisigSignedTx), the account is encoded as a field
+// which is not part of the SignedTx protobuf definition.
+func NewRecoveredAccountSignedTx(tx []byte, account common.Address, signature []byte) (*models.SignedTx, error) {
+	return NewMultisigSignedTx(tx, account, [][]byte{signature})
+}
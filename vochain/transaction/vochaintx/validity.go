@@ -0,0 +1,118 @@
+package vochaintx
+
+import (
+	"errors"
+	"fmt"
+
+	"go.vocdoni.io/proto/build/go/models"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// The validity window of a transaction is defined by two optional fields of the
+// marshaled models.Tx, which are not part of its protobuf definition. They are
+// appended to the Tx bytes before signing them (see WithValidHeights), so they
+// are covered by the signature, and decoded as unknown fields by Unmarshal.
+// The nodes without the validity window ignore the unknown fields, so it is only
+// enforced once the state.ForkTxValidity fork is applied.
+const (
+	validFromHeightField  protowire.Number = 1000
+	validUntilHeightField protowire.Number = 1001
+)
+
+var (
+	// ErrTxNotYetValid is returned if the transaction cannot be executed yet,
+	// since its validFromHeight is greater than the current height.
+	ErrTxNotYetValid = errors.New("transaction not valid yet")
+	// ErrTxExpired is returned if the transaction cannot be executed anymore,
+	// since its validUntilHeight is lower than the current height.
+	ErrTxExpired = errors.New("transaction expired")
+)
+
+// WithValidHeights appends the validity window to a marshaled models.Tx, so the
+// transaction can only be executed from the block validFrom to the block
+// validUntil (both included). A zero value leaves that end of the window open.
+// The returned bytes must be signed as any other marshaled transaction.
+func WithValidHeights(marshaledTx []byte, validFrom, validUntil uint32) ([]byte, error) {
+	if validUntil > 0 && validFrom > validUntil {
+		return nil, fmt.Errorf("validFromHeight %d is greater than validUntilHeight %d", validFrom, validUntil)
+	}
+	tx := &models.Tx{}
+	if err := proto.Unmarshal(marshaledTx, tx); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Tx: %w", err)
+	}
+	from, until, err := validHeights(tx)
+	if err != nil {
+		return nil, err
+	}
+	if from > 0 || until > 0 {
+		return nil, fmt.Errorf("transaction already has a validity window")
+	}
+	b := append([]byte{}, marshaledTx...)
+	if validFrom > 0 {
+		b = protowire.AppendTag(b, validFromHeightField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(validFrom))
+	}
+	if validUntil > 0 {
+		b = protowire.AppendTag(b, validUntilHeightField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(validUntil))
+	}
+	return b, nil
+}
+
+// CheckValidHeight returns ErrTxNotYetValid or ErrTxExpired if the transaction
+// cannot be executed at the given height, according to its validity window, or
+// the error decoding the window.
+func (tx *Tx) CheckValidHeight(height uint32) error {
+	if tx.validHeightsErr != nil {
+		return tx.validHeightsErr
+	}
+	if tx.ValidFromHeight > 0 && height < tx.ValidFromHeight {
+		return fmt.Errorf("%w: valid from height %d, current height %d", ErrTxNotYetValid, tx.ValidFromHeight, height)
+	}
+	if tx.ValidUntilHeight > 0 && height > tx.ValidUntilHeight {
+		return fmt.Errorf("%w: valid until height %d, current height %d", ErrTxExpired, tx.ValidUntilHeight, height)
+	}
+	return nil
+}
+
+// validHeights decodes the validity window from the unknown fields of the tx.
+func validHeights(tx *models.Tx) (uint32, uint32, error) {
+	var from, until uint32
+	b := tx.ProtoReflect().GetUnknown()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return 0, 0, fmt.Errorf("invalid transaction field: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		if num == validFromHeightField || num == validUntilHeightField {
+			if typ != protowire.VarintType {
+				return 0, 0, fmt.Errorf("invalid wire type %d for field %d", typ, num)
+			}
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return 0, 0, fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+			}
+			if v > uint64(^uint32(0)) {
+				return 0, 0, fmt.Errorf("field %d overflows: %d", num, v)
+			}
+			if num == validFromHeightField {
+				from = uint32(v)
+			} else {
+				until = uint32(v)
+			}
+			b = b[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return 0, 0, fmt.Errorf("invalid transaction field %d: %w", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+	}
+	if until > 0 && from > until {
+		return 0, 0, fmt.Errorf("validFromHeight %d is greater than validUntilHeight %d", from, until)
+	}
+	return from, until, nil
+}
@@ -18,6 +18,20 @@ type Tx struct {
 	Signature   []byte
 	TxID        [32]byte
 	TxModelType string
+	// ValidFromHeight and ValidUntilHeight define the optional window of
+	// blocks in which the transaction can be executed, zero if unset.
+	ValidFromHeight  uint32
+	ValidUntilHeight uint32
+	// validHeightsErr is the error decoding the validity window, returned by
+	// CheckValidHeight, as the window is ignored until it is enforced.
+	validHeightsErr error
+	// Multisig holds the signatures of the transactions sent on behalf of a
+	// multisig account, or of an account whose owner key was rotated by its
+	// guardians, nil otherwise.
+	Multisig *Multisig
+	// multisigErr is the error decoding the multisig signatures, see
+	// MultisigErr.
+	multisigErr error
 }
 
 // Unmarshal decodes the content of a serialized transaction into the Tx struct.
@@ -25,7 +39,8 @@ type Tx struct {
 // The function determines the type of the transaction using Protocol Buffers
 // reflection and sets it to the TxModelType field.
 // Extracts the signature. Prepares the signed body (ready to be checked) and
-// computes the transaction ID (a hash of the data).
+// computes the transaction ID (a hash of the data) and decodes the validity window
+// and the multisig signatures.
 func (tx *Tx) Unmarshal(content []byte, chainID string) error {
 	stx := new(models.SignedTx)
 	if err := proto.Unmarshal(content, stx); err != nil {
@@ -45,7 +60,13 @@ func (tx *Tx) Unmarshal(content []byte, chainID string) error {
 		return fmt.Errorf("failed to determine transaction type")
 	}
 	tx.TxModelType = string(whichOneTxModelType.Name())
+	if tx.ValidFromHeight, tx.ValidUntilHeight, err = validHeights(tx.Tx); err != nil {
+		tx.validHeightsErr = fmt.Errorf("failed to decode transaction validity window: %w", err)
+	}
 	tx.Signature = stx.GetSignature()
+	if tx.Multisig, err = multisigSignatures(stx); err != nil {
+		tx.multisigErr = fmt.Errorf("failed to decode multisig signatures: %w", err)
+	}
 	tx.TxID = TxKey(content)
 	return nil
 }
@@ -73,7 +94,12 @@ func (tx *Tx) TxSubtype() string {
 	// Get the integer value of txtype as protoreflect.EnumNumber
 	enumNumber := fieldValue.Message().Get(txtypeFieldDescriptor).Enum()
 	// Convert the EnumNumber to a string using the EnumType descriptor
-	return string(txtypeFieldDescriptor.Enum().Values().ByNumber(enumNumber).Name())
+	value := txtypeFieldDescriptor.Enum().Values().ByNumber(enumNumber)
+	if value == nil {
+		// the txtype is not defined in the models.TxType enum
+		return TxTypeName(models.TxType(enumNumber))
+	}
+	return string(value.Name())
 }
 
 // TxKey computes the checksum of the tx
@@ -1,9 +1,12 @@
 package transaction
 
 import (
+	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 
 	"go.vocdoni.io/dvote/crypto/ethereum"
 	"go.vocdoni.io/dvote/log"
@@ -149,6 +152,11 @@ func (t *TransactionHandler) VoteTxCheck(vtx *vochaintx.Tx, forCommit bool) (*vs
 		return nil, err
 	}
 
+	// Check the nullifier was not used in another process of the nullifier group
+	if err := t.checkGroupNullifier(vote.Nullifier, process); err != nil {
+		return nil, err
+	}
+
 	// Check if maxCensusSize is reached
 	votesCount, err := t.state.CountVotes(process.ProcessId, false)
 	if err != nil {
@@ -159,6 +167,16 @@ func (t *TransactionHandler) VoteTxCheck(vtx *vochaintx.Tx, forCommit bool) (*vs
 		return nil, fmt.Errorf("maxCensusSize reached %d/%d", votesCount, process.GetMaxCensusSize())
 	}
 
+	// Check the voter can lock the vote deposit, if the process requires it
+	if err := t.checkVoteDeposit(vote, process); err != nil {
+		return nil, err
+	}
+
+	// Check the voter did not delegate its weight, if the process accepts delegations
+	if err := t.checkVoteDelegation(vote, process); err != nil {
+		return nil, err
+	}
+
 	// if vote was from cache, we already checked the proof, so we can return
 	if fromCache {
 		return vote, nil
@@ -171,6 +189,10 @@ func (t *TransactionHandler) VoteTxCheck(vtx *vochaintx.Tx, forCommit bool) (*vs
 		if t.state.ExpiredSIKRoot(sikRoot) {
 			return nil, fmt.Errorf("expired sik root provided, generate the proof again")
 		}
+		// the proofs of a nullifier group are not bound to the process
+		if err := checkGroupVotePackage(process, voteEnvelope.VotePackage); err != nil {
+			return nil, err
+		}
 		// verify the proof
 		valid := false
 		valid, vote.Weight, err = VerifyProof(process, voteEnvelope, vote.VoterID)
@@ -210,7 +232,12 @@ func (t *TransactionHandler) VoteTxCheck(vtx *vochaintx.Tx, forCommit bool) (*vs
 		if !valid {
 			return nil, fmt.Errorf("merkle proof verification failed")
 		}
-		vote.Weight = weight
+		// add the weight delegated to the voter, if the process accepts delegations
+		delegated, err := t.delegatedVoteWeight(vote, process)
+		if err != nil {
+			return nil, err
+		}
+		vote.Weight = new(big.Int).Add(weight, delegated)
 	}
 
 	// If not forCommit, add the vote to the cache
@@ -244,3 +271,91 @@ func (t *TransactionHandler) checkVoteCanBeCasted(nullifier []byte, process *mod
 	}
 	return true, nil
 }
+
+// checkGroupNullifier checks that the nullifier of an anonymous vote was not
+// used to vote in another process of the nullifier group of the process, if it
+// is part of one.
+func (t *TransactionHandler) checkGroupNullifier(nullifier []byte, process *models.Process) error {
+	group := vochaintx.ProcessModeNullifierGroup(process.Mode)
+	if !process.EnvelopeType.Anonymous || len(group) == 0 {
+		return nil
+	}
+	pid, err := t.state.GroupNullifierProcess(vstate.NullifierGroupScope(process.EntityId, group), nullifier, false)
+	if err != nil {
+		return fmt.Errorf("cannot get group nullifier %x: %w", nullifier, err)
+	}
+	if pid != nil && !bytes.Equal(pid, process.ProcessId) {
+		return fmt.Errorf("nullifier %x already used in process %x of the same nullifier group", nullifier, pid)
+	}
+	return nil
+}
+
+// checkGroupVotePackage checks that the vote package of an anonymous vote of a
+// process which is part of a nullifier group has the process ID as nonce. The
+// election ID of the proofs of the processes of a group is the same, so the
+// vote package binds the vote to the process instead, and a vote cannot be
+// replayed to another process of the group.
+func checkGroupVotePackage(process *models.Process, votePackage []byte) error {
+	if len(vochaintx.ProcessModeNullifierGroup(process.Mode)) == 0 {
+		return nil
+	}
+	vp := &vstate.VotePackage{}
+	if err := json.Unmarshal(votePackage, vp); err != nil {
+		return fmt.Errorf("cannot decode vote package: %w", err)
+	}
+	if vp.Nonce != hex.EncodeToString(process.ProcessId) {
+		return fmt.Errorf("vote package nonce must be the process id for a nullifier group")
+	}
+	return nil
+}
+
+// setGroupNullifier records the nullifier of an anonymous vote of a process
+// which is part of a nullifier group, so that it cannot be used to vote in the
+// other processes of the group.
+func (t *TransactionHandler) setGroupNullifier(vote *vstate.Vote) error {
+	process, err := t.state.Process(vote.ProcessID, false)
+	if err != nil {
+		return fmt.Errorf("cannot fetch processId: %w", err)
+	}
+	group := vochaintx.ProcessModeNullifierGroup(process.Mode)
+	if !process.GetEnvelopeType().GetAnonymous() || len(group) == 0 {
+		return nil
+	}
+	return t.state.SetGroupNullifier(vstate.NullifierGroupScope(process.EntityId, group), vote.Nullifier, vote.ProcessID)
+}
+
+// checkVoteDeposit checks that the voter has enough balance to lock the deposit
+// required to vote in the process, if any. The overwrites of a vote which
+// already locked its deposit do not require it again.
+func (t *TransactionHandler) checkVoteDeposit(vote *vstate.Vote, process *models.Process) error {
+	amount := vochaintx.ProcessModeVoteDeposit(process.Mode)
+	if amount == 0 {
+		return nil
+	}
+	deposit, err := t.state.VoteDeposit(process.ProcessId, vote.Nullifier)
+	if err != nil {
+		return fmt.Errorf("cannot get vote deposit: %w", err)
+	}
+	if deposit != nil {
+		return nil
+	}
+	voter := ethereum.AddrFromBytes(vote.VoterID.Address())
+	acc, err := t.state.GetAccount(voter, false)
+	if err != nil {
+		return fmt.Errorf("cannot get voter account: %w", err)
+	}
+	if acc == nil {
+		return fmt.Errorf("%w: the vote requires a deposit of %d tokens", vstate.ErrAccountNotExist, amount)
+	}
+	if acc.Balance < amount {
+		return fmt.Errorf("%w: the vote requires a deposit of %d tokens, the balance is %d",
+			vstate.ErrNotEnoughBalance, amount, acc.Balance)
+	}
+	return nil
+}
+
+// lockVoteDeposit locks the deposit required to vote in the process of the
+// vote, if any, from the account of the voter.
+func (t *TransactionHandler) lockVoteDeposit(vote *vstate.Vote, txHash []byte) error {
+	return t.state.LockVoteDeposit(vote, ethereum.AddrFromBytes(vote.VoterID.Address()), txHash)
+}
@@ -0,0 +1,158 @@
+// Package txaudit keeps a bounded, in-memory history of the nonce-bearing
+// transactions seen by the local mempool, grouped by sender account. It is used
+// to detect nonce gaps, as well as replayed or dropped transactions, by comparing
+// what the mempool received with what ended up being included in a block.
+package txaudit
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.vocdoni.io/dvote/types"
+)
+
+const (
+	// DefaultMaxAccounts is the default number of accounts tracked by the auditor.
+	// When the limit is reached, the least recently used account is evicted.
+	DefaultMaxAccounts = 10000
+	// DefaultMaxTxsPerAccount is the default number of transactions kept for each account.
+	DefaultMaxTxsPerAccount = 64
+)
+
+// TxRecord is a transaction seen by the mempool.
+type TxRecord struct {
+	Hash            types.HexBytes `json:"hash"`
+	Nonce           uint32         `json:"nonce"`
+	TxType          string         `json:"txType"`
+	FirstSeenHeight uint32         `json:"firstSeenHeight"`
+	LastSeenHeight  uint32         `json:"lastSeenHeight"`
+	SeenCount       uint32         `json:"seenCount"`
+}
+
+// AccountAudit is the audit report of a single account.
+type AccountAudit struct {
+	Address types.HexBytes `json:"address"`
+	// Nonce is the current nonce of the account in the committed state.
+	Nonce uint32 `json:"nonce"`
+	// Txs contains the recently seen transactions, sorted by nonce.
+	Txs []*TxRecord `json:"transactions"`
+	// NonceGaps contains the nonces missing between the account nonce and the highest
+	// pending nonce seen by the mempool. A transaction with a nonce above a gap cannot
+	// be executed until the gap is filled.
+	NonceGaps []uint32 `json:"nonceGaps"`
+}
+
+// accountHistory holds the recently seen transactions of a single account,
+// indexed by transaction hash.
+type accountHistory struct {
+	txs map[string]*TxRecord
+}
+
+// Auditor tracks the transactions received by the mempool. It is safe for concurrent use.
+type Auditor struct {
+	mu               sync.Mutex
+	accounts         *lru.Cache[common.Address, *accountHistory]
+	maxTxsPerAccount int
+}
+
+// New creates a new Auditor that tracks up to maxAccounts accounts and keeps
+// up to maxTxsPerAccount transactions for each of them.
+func New(maxAccounts, maxTxsPerAccount int) (*Auditor, error) {
+	if maxTxsPerAccount <= 0 {
+		return nil, fmt.Errorf("invalid value: maxTxsPerAccount cannot be %d", maxTxsPerAccount)
+	}
+	accounts, err := lru.New[common.Address, *accountHistory](maxAccounts)
+	if err != nil {
+		return nil, err
+	}
+	return &Auditor{
+		accounts:         accounts,
+		maxTxsPerAccount: maxTxsPerAccount,
+	}, nil
+}
+
+// AddTx registers a transaction from addr with the given nonce, seen by the mempool at height.
+// If the transaction was already seen, its last seen height and counter are updated.
+func (a *Auditor) AddTx(addr common.Address, hash []byte, nonce uint32, txType string, height uint32) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	history, ok := a.accounts.Get(addr)
+	if !ok {
+		history = &accountHistory{txs: make(map[string]*TxRecord)}
+		a.accounts.Add(addr, history)
+	}
+	if rec, ok := history.txs[string(hash)]; ok {
+		rec.LastSeenHeight = height
+		rec.SeenCount++
+		return
+	}
+	if len(history.txs) >= a.maxTxsPerAccount {
+		history.evictOldest()
+	}
+	history.txs[string(hash)] = &TxRecord{
+		Hash:            types.HexBytes(hash),
+		Nonce:           nonce,
+		TxType:          txType,
+		FirstSeenHeight: height,
+		LastSeenHeight:  height,
+		SeenCount:       1,
+	}
+}
+
+// Account returns the audit report for addr, given its current committed nonce.
+// Returns nil if no transaction from addr has been seen.
+func (a *Auditor) Account(addr common.Address, nonce uint32) *AccountAudit {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	history, ok := a.accounts.Peek(addr)
+	if !ok {
+		return nil
+	}
+	audit := &AccountAudit{
+		Address:   addr.Bytes(),
+		Nonce:     nonce,
+		Txs:       make([]*TxRecord, 0, len(history.txs)),
+		NonceGaps: []uint32{},
+	}
+	pending := make(map[uint32]bool)
+	maxPending := nonce
+	for _, rec := range history.txs {
+		r := *rec
+		audit.Txs = append(audit.Txs, &r)
+		if rec.Nonce >= nonce {
+			pending[rec.Nonce] = true
+			maxPending = max(maxPending, rec.Nonce)
+		}
+	}
+	slices.SortFunc(audit.Txs, func(a, b *TxRecord) int {
+		if a.Nonce != b.Nonce {
+			return cmp.Compare(a.Nonce, b.Nonce)
+		}
+		return cmp.Compare(a.FirstSeenHeight, b.FirstSeenHeight)
+	})
+	if len(pending) > 0 {
+		for n := nonce; n < maxPending; n++ {
+			if !pending[n] {
+				audit.NonceGaps = append(audit.NonceGaps, n)
+			}
+		}
+	}
+	return audit
+}
+
+// evictOldest removes the transaction with the lowest first seen height.
+func (h *accountHistory) evictOldest() {
+	var oldest string
+	var oldestHeight uint32
+	first := true
+	for k, rec := range h.txs {
+		if first || rec.FirstSeenHeight < oldestHeight {
+			oldest, oldestHeight, first = k, rec.FirstSeenHeight, false
+		}
+	}
+	delete(h.txs, oldest)
+}
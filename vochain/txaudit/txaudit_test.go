@@ -0,0 +1,57 @@
+package txaudit
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	qt "github.com/frankban/quicktest"
+)
+
+func TestAuditorNonceGaps(t *testing.T) {
+	c := qt.New(t)
+	a, err := New(10, 10)
+	c.Assert(err, qt.IsNil)
+
+	addr := common.HexToAddress("0xF3668000B66c61aAa08aBC559a8C78Ae7E007C2e")
+	c.Assert(a.Account(addr, 0), qt.IsNil)
+
+	a.AddTx(addr, []byte{1}, 2, "sendTokens", 10)
+	a.AddTx(addr, []byte{2}, 3, "sendTokens", 10)
+	a.AddTx(addr, []byte{3}, 6, "sendTokens", 11)
+	// the same transaction is received again
+	a.AddTx(addr, []byte{3}, 6, "sendTokens", 12)
+
+	audit := a.Account(addr, 2)
+	c.Assert(audit, qt.IsNotNil)
+	c.Assert(audit.Txs, qt.HasLen, 3)
+	c.Assert(audit.NonceGaps, qt.DeepEquals, []uint32{4, 5})
+	c.Assert(audit.Txs[2].SeenCount, qt.Equals, uint32(2))
+	c.Assert(audit.Txs[2].FirstSeenHeight, qt.Equals, uint32(11))
+	c.Assert(audit.Txs[2].LastSeenHeight, qt.Equals, uint32(12))
+
+	// once the account nonce moves past all pending transactions, there are no gaps
+	audit = a.Account(addr, 7)
+	c.Assert(audit.NonceGaps, qt.HasLen, 0)
+}
+
+func TestAuditorEviction(t *testing.T) {
+	c := qt.New(t)
+	a, err := New(1, 2)
+	c.Assert(err, qt.IsNil)
+
+	addr1 := common.HexToAddress("0x01")
+	addr2 := common.HexToAddress("0x02")
+	a.AddTx(addr1, []byte{1}, 0, "setAccount", 1)
+	a.AddTx(addr1, []byte{2}, 1, "setAccount", 2)
+	a.AddTx(addr1, []byte{3}, 2, "setAccount", 3)
+
+	// only the two most recent transactions are kept
+	audit := a.Account(addr1, 0)
+	c.Assert(audit.Txs, qt.HasLen, 2)
+	c.Assert(audit.Txs[0].Nonce, qt.Equals, uint32(1))
+
+	// a new account evicts the least recently used one
+	a.AddTx(addr2, []byte{4}, 0, "setAccount", 4)
+	c.Assert(a.Account(addr1, 0), qt.IsNil)
+	c.Assert(a.Account(addr2, 0), qt.IsNotNil)
+}
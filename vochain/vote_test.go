@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	cometabcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/ethereum/go-ethereum/common"
 	qt "github.com/frankban/quicktest"
 	"go.vocdoni.io/dvote/censustree"
 	"go.vocdoni.io/dvote/crypto/ethereum"
@@ -14,6 +15,9 @@ import (
 	"go.vocdoni.io/dvote/tree/arbo"
 	"go.vocdoni.io/dvote/types"
 	"go.vocdoni.io/dvote/util"
+	"go.vocdoni.io/dvote/vochain/results"
+	"go.vocdoni.io/dvote/vochain/state"
+	"go.vocdoni.io/dvote/vochain/transaction/vochaintx"
 	"go.vocdoni.io/proto/build/go/models"
 	"google.golang.org/protobuf/proto"
 )
@@ -285,3 +289,272 @@ func TestMaxCensusSize(t *testing.T) {
 	// the 11th vote should fail
 	qt.Check(t, vote(10), qt.Equals, uint32(1))
 }
+
+func TestVoteDeposit(t *testing.T) {
+	app := TestBaseApplication(t)
+	keys, root, proofs := testCreateKeysAndBuildCensus(t, 3)
+	for i, k := range keys[:2] {
+		qt.Assert(t, app.State.CreateAccount(k.Address(), "", nil, 0), qt.IsNil)
+		qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+			ToAddress: k.Address(),
+			Amount:    uint64(100 * (i + 1)),
+		}), qt.IsNil)
+	}
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	censusURI := ipfsUrlTest
+	newProcess := func() *models.Process {
+		mode := &models.ProcessMode{AutoStart: true, Interruptible: true}
+		vochaintx.SetProcessModeVoteDeposit(mode, 10)
+		p := &models.Process{
+			ProcessId:     util.RandomBytes(types.ProcessIDsize),
+			EnvelopeType:  &models.EnvelopeType{},
+			Mode:          mode,
+			VoteOptions:   &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 3, MaxVoteOverwrites: 1},
+			Status:        models.ProcessStatus_READY,
+			EntityId:      util.RandomBytes(types.EntityIDsize),
+			CensusRoot:    root,
+			CensusURI:     &censusURI,
+			CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+			BlockCount:    1024,
+			MaxCensusSize: 3,
+		}
+		qt.Assert(t, app.State.AddProcess(p), qt.IsNil)
+		testCommitState(t, app)
+		return p
+	}
+	balance := func(addr common.Address) uint64 {
+		acc, err := app.State.GetAccount(addr, true)
+		qt.Assert(t, err, qt.IsNil)
+		return acc.Balance
+	}
+	// the votes are counted by the results, so they need a proper vote package
+	vote := func(pid []byte, i int, choices []int) error {
+		stx := testBuildSignedVote(t, pid, keys[i], proofs[i], choices, app.ChainID())
+		tx := &models.Tx{}
+		qt.Assert(t, proto.Unmarshal(stx.Tx, tx), qt.IsNil)
+		vp, err := state.NewVotePackage(choices).Encode()
+		qt.Assert(t, err, qt.IsNil)
+		tx.GetVote().VotePackage = vp
+		stx.Tx, err = proto.Marshal(tx)
+		qt.Assert(t, err, qt.IsNil)
+		stx.Signature, err = keys[i].SignVocdoniTx(stx.Tx, app.ChainID())
+		qt.Assert(t, err, qt.IsNil)
+		_, err = testCheckTxDeliverTxCommit(t, app, stx)
+		return err
+	}
+
+	process := newProcess()
+	pid := process.ProcessId
+	qt.Assert(t, vote(pid, 0, []int{1, 0, 1}), qt.IsNil)
+	// a vote which is not counted, since the values are out of range
+	qt.Assert(t, vote(pid, 1, []int{5, 0, 1}), qt.IsNil)
+	// should fail without an account to lock the deposit from
+	qt.Assert(t, vote(pid, 2, []int{1, 0, 1}), qt.ErrorMatches, ".*requires a deposit.*")
+	qt.Assert(t, balance(keys[0].Address()), qt.Equals, uint64(90))
+	qt.Assert(t, balance(keys[1].Address()), qt.Equals, uint64(190))
+	deposits, err := app.State.ProcessVoteDeposits(pid, true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, deposits, qt.Equals, uint64(20))
+
+	// the overwrites do not lock the deposit again
+	qt.Assert(t, vote(pid, 0, []int{0, 0, 1}), qt.IsNil)
+	qt.Assert(t, balance(keys[0].Address()), qt.Equals, uint64(90))
+
+	// the deposits of the counted votes are refunded, the rest are burned
+	refunded, burned, err := app.State.SettleVoteDeposits(pid, func(vote *models.StateDBVote) bool {
+		return results.CountedVote(process, vote)
+	})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, refunded, qt.Equals, uint64(10))
+	qt.Assert(t, burned, qt.Equals, uint64(10))
+	testCommitState(t, app)
+	qt.Assert(t, balance(keys[0].Address()), qt.Equals, uint64(100))
+	qt.Assert(t, balance(keys[1].Address()), qt.Equals, uint64(190))
+	qt.Assert(t, balance(state.BurnAddress), qt.Equals, uint64(10))
+	qt.Assert(t, balance(state.ProcessDepositAddress(pid)), qt.Equals, uint64(0))
+	deposit, err := app.State.VoteDeposit(pid, state.GenerateNullifier(keys[0].Address(), pid))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, deposit, qt.IsNil)
+
+	// all the deposits are refunded if the process is canceled
+	pid = newProcess().ProcessId
+	qt.Assert(t, vote(pid, 1, []int{5, 0, 1}), qt.IsNil)
+	qt.Assert(t, balance(keys[1].Address()), qt.Equals, uint64(180))
+	qt.Assert(t, app.State.SetProcessStatus(pid, models.ProcessStatus_CANCELED, true), qt.IsNil)
+	testCommitState(t, app)
+	qt.Assert(t, balance(keys[1].Address()), qt.Equals, uint64(190))
+	qt.Assert(t, balance(state.ProcessDepositAddress(pid)), qt.Equals, uint64(0))
+}
+
+func TestVoteDelegation(t *testing.T) {
+	app := TestBaseApplication(t)
+	keys, root, proofs := testCreateKeysAndBuildCensus(t, 4)
+	for _, k := range keys[:3] {
+		qt.Assert(t, app.State.CreateAccount(k.Address(), "", nil, 0), qt.IsNil)
+		qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+			ToAddress: k.Address(),
+			Amount:    100,
+		}), qt.IsNil)
+	}
+	qt.Assert(t, app.State.SetAccount(state.BurnAddress, &state.Account{}), qt.IsNil)
+	qt.Assert(t, app.State.SetTxBaseCost(vochaintx.TxTypeDelegateVoteWeight, 1), qt.IsNil)
+	censusURI := ipfsUrlTest
+	newProcess := func(delegation bool) []byte {
+		mode := &models.ProcessMode{AutoStart: true, Interruptible: true}
+		vochaintx.SetProcessModeVoteDelegation(mode, delegation)
+		p := &models.Process{
+			ProcessId:     util.RandomBytes(types.ProcessIDsize),
+			EnvelopeType:  &models.EnvelopeType{},
+			Mode:          mode,
+			VoteOptions:   &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 3},
+			Status:        models.ProcessStatus_READY,
+			EntityId:      util.RandomBytes(types.EntityIDsize),
+			CensusRoot:    root,
+			CensusURI:     &censusURI,
+			CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+			StartBlock:    10,
+			BlockCount:    1024,
+			MaxCensusSize: 4,
+		}
+		qt.Assert(t, app.State.AddProcess(p), qt.IsNil)
+		testCommitState(t, app)
+		return p.ProcessId
+	}
+	nonces := make(map[common.Address]uint32)
+	sendDelegationTx := func(i int, newTx func(nonce uint32) (*models.SetAccountTx, error)) error {
+		tx, err := newTx(nonces[keys[i].Address()])
+		qt.Assert(t, err, qt.IsNil)
+		txb, err := proto.Marshal(&models.Tx{Payload: &models.Tx_SetAccount{SetAccount: tx}})
+		qt.Assert(t, err, qt.IsNil)
+		if err := sendTx(app, keys[i], &models.SignedTx{Tx: txb}); err != nil {
+			return err
+		}
+		testCommitState(t, app)
+		nonces[keys[i].Address()]++
+		return nil
+	}
+	delegate := func(pid []byte, i, j int) error {
+		return sendDelegationTx(i, func(nonce uint32) (*models.SetAccountTx, error) {
+			return vochaintx.NewDelegateVoteWeightTx(nonce, pid, keys[j].Address(), &models.Proof{
+				Payload: &models.Proof_Arbo{
+					Arbo: &models.ProofArbo{
+						Type:     models.ProofArbo_BLAKE2B,
+						Siblings: proofs[i],
+						KeyType:  models.ProofArbo_ADDRESS,
+					},
+				},
+			})
+		})
+	}
+	revoke := func(pid []byte, i int) error {
+		return sendDelegationTx(i, func(nonce uint32) (*models.SetAccountTx, error) {
+			return vochaintx.NewRevokeVoteDelegationTx(nonce, pid)
+		})
+	}
+	vote := func(pid []byte, i int) error {
+		_, err := testCheckTxDeliverTxCommit(t, app, testBuildSignedVote(t, pid, keys[i], proofs[i], []int{1, 0, 1}, app.ChainID()))
+		return err
+	}
+
+	// should fail if the process does not accept delegations
+	qt.Assert(t, delegate(newProcess(false), 0, 2), qt.ErrorMatches, ".*does not accept vote delegations.*")
+
+	pid := newProcess(true)
+	qt.Assert(t, delegate(pid, 0, 0), qt.ErrorMatches, ".*to oneself.*")
+	qt.Assert(t, delegate(pid, 0, 2), qt.IsNil)
+	// the delegations cannot be chained
+	qt.Assert(t, delegate(pid, 1, 0), qt.ErrorMatches, ".*delegated its vote weight.*")
+	qt.Assert(t, delegate(pid, 2, 3), qt.ErrorMatches, ".*delegated to it.*")
+	// the delegations can be revoked or replaced until the process starts
+	qt.Assert(t, delegate(pid, 1, 3), qt.IsNil)
+	qt.Assert(t, revoke(pid, 1), qt.IsNil)
+	qt.Assert(t, revoke(pid, 1), qt.ErrorMatches, ".*no vote delegation to revoke.*")
+	qt.Assert(t, delegate(pid, 1, 3), qt.IsNil)
+	qt.Assert(t, delegate(pid, 1, 2), qt.IsNil)
+	delegated, err := app.State.DelegatedVoteWeight(pid, keys[2].Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, delegated.Delegators, qt.Equals, uint32(2))
+	qt.Assert(t, delegated.Weight.String(), qt.Equals, "2")
+	delegated, err = app.State.DelegatedVoteWeight(pid, keys[3].Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, delegated.Delegators, qt.Equals, uint32(0))
+
+	// once the process starts, the delegations are frozen and the delegators
+	// cannot vote
+	app.AdvanceTestBlocksUntilHeight(10)
+	qt.Assert(t, revoke(pid, 0), qt.ErrorMatches, ".*started at height.*")
+	qt.Assert(t, vote(pid, 0), qt.ErrorMatches, ".*delegated its vote weight.*")
+
+	// the delegated weight is added to the vote of the delegate
+	qt.Assert(t, vote(pid, 2), qt.IsNil)
+	qt.Assert(t, vote(pid, 3), qt.IsNil)
+	for i, weight := range map[int]string{2: "3", 3: "1"} {
+		v, err := app.State.Vote(pid, state.GenerateNullifier(keys[i].Address(), pid), true)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, new(big.Int).SetBytes(v.Weight).String(), qt.Equals, weight)
+	}
+	acc, err := app.State.GetAccount(keys[1].Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, acc.Balance, qt.Equals, uint64(96))
+}
+
+func TestVoteDelegationFork(t *testing.T) {
+	app := TestBaseApplicationWithForks(t, map[string]uint32{state.ForkVoteDelegation: 2})
+	accounts := createTestAccounts(t, app, 10)
+	keys, root, proofs := testCreateKeysAndBuildCensus(t, 2)
+	qt.Assert(t, app.State.CreateAccount(keys[0].Address(), "", nil, 0), qt.IsNil)
+	qt.Assert(t, app.State.MintBalance(&vochaintx.TokenTransfer{
+		ToAddress: keys[0].Address(),
+		Amount:    100,
+	}), qt.IsNil)
+	qt.Assert(t, app.State.SetTxBaseCost(vochaintx.TxTypeDelegateVoteWeight, 1), qt.IsNil)
+
+	censusURI := ipfsUrlTest
+	mode := &models.ProcessMode{AutoStart: true, Interruptible: true}
+	vochaintx.SetProcessModeVoteDelegation(mode, true)
+	process := &models.Process{
+		EnvelopeType:  &models.EnvelopeType{},
+		Mode:          mode,
+		VoteOptions:   &models.ProcessVoteOptions{MaxCount: 3, MaxValue: 3},
+		Status:        models.ProcessStatus_READY,
+		EntityId:      accounts[0].Address().Bytes(),
+		CensusRoot:    root,
+		CensusURI:     &censusURI,
+		CensusOrigin:  models.CensusOrigin_OFF_CHAIN_TREE,
+		StartTime:     100,
+		Duration:      60,
+		MaxCensusSize: 2,
+	}
+	delegate := func(pid []byte, nonce uint32) error {
+		tx, err := vochaintx.NewDelegateVoteWeightTx(nonce, pid, keys[1].Address(), &models.Proof{
+			Payload: &models.Proof_Arbo{
+				Arbo: &models.ProofArbo{
+					Type:     models.ProofArbo_BLAKE2B,
+					Siblings: proofs[0],
+					KeyType:  models.ProofArbo_ADDRESS,
+				},
+			},
+		})
+		qt.Assert(t, err, qt.IsNil)
+		txb, err := proto.Marshal(&models.Tx{Payload: &models.Tx_SetAccount{SetAccount: tx}})
+		qt.Assert(t, err, qt.IsNil)
+		return sendTx(app, keys[0], &models.SignedTx{Tx: txb})
+	}
+
+	// neither the processes nor the census members can use the delegations
+	// before the fork, even for a process which was stored with the mode set
+	qt.Assert(t, testCreateProcessWithErr(t, accounts[0], app, process),
+		qt.ErrorMatches, ".*fork not active: voteDelegation")
+	stored := proto.Clone(process).(*models.Process)
+	stored.ProcessId = util.RandomBytes(types.ProcessIDsize)
+	qt.Assert(t, app.State.AddProcess(stored), qt.IsNil)
+	qt.Assert(t, delegate(stored.ProcessId, 0), qt.ErrorMatches, ".*fork not active: voteDelegation")
+
+	app.AdvanceTestBlocksUntilHeight(2)
+	pid := testCreateProcess(t, accounts[0], app, process)
+	qt.Assert(t, delegate(pid, 0), qt.IsNil)
+	testCommitState(t, app)
+	delegated, err := app.State.DelegatedVoteWeight(pid, keys[1].Address(), true)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, delegated.Delegators, qt.Equals, uint32(1))
+}
@@ -156,6 +156,7 @@ func (vc *Vocone) EnableAPI(host string, port int, URLpath string) (*api.API, er
 		api.AccountHandler,
 		api.CensusHandler,
 		api.SIKHandler,
+		api.BatchHandler,
 	)
 }
 
@@ -169,6 +170,7 @@ func (vc *Vocone) Start() {
 			MaxElectionSize: 1000000,
 			NetworkCapacity: uint64(vc.txsPerBlock),
 			TxCost:          defaultTxCosts(),
+			Forks:           genesis.AllForks(),
 		})
 		if err != nil {
 			panic(err)